@@ -0,0 +1,67 @@
+package ai_player
+
+import (
+	"math"
+	"math/rand"
+
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// adaptiveStep is how many difficulty levels one game moves an adaptive
+// opponent: up half a level when the human wins, down when they lose
+const adaptiveStep = 0.5
+
+// AdaptDifficulty returns the level an opponent starting at the named
+// difficulty ("" for medium) should play at after the human scored scores
+// against it, oldest first, each 1 for a win, 0.5 for a draw and 0 for a
+// loss. Wins make it stronger and losses weaker, so the human's results
+// settle near 50%. Between two levels, the temperature and blunder rate
+// are blended; the prompt and engine depth are the nearer level's.
+func AdaptDifficulty(start string, scores []float64) Difficulty {
+	level := 1.0
+	for i, d := range Difficulties {
+		if d.Name == start {
+			level = float64(i)
+		}
+	}
+	top := float64(len(Difficulties) - 1)
+	for _, score := range scores {
+		level = min(max(level+(score-0.5)*2*adaptiveStep, 0), top)
+	}
+
+	lower := Difficulties[int(math.Floor(level))]
+	upper := Difficulties[int(math.Ceil(level))]
+	weight := level - math.Floor(level)
+	adapted := Difficulties[int(math.Round(level))]
+	adapted.Temperature = lower.Temperature + (upper.Temperature-lower.Temperature)*weight
+	adapted.BlunderRate = lower.BlunderRate + (upper.BlunderRate-lower.BlunderRate)*weight
+	return adapted
+}
+
+// blunder swaps move for a random other legal move in the FEN position at
+// the player's BlunderRate
+func (ai *AIPlayer) blunder(boardState string, move *ChessMove) *ChessMove {
+	if ai.BlunderRate <= 0 || rand.Float64() >= ai.BlunderRate {
+		return move
+	}
+	fen, err := chess.FEN(boardState)
+	if err != nil {
+		return move
+	}
+	position := chess.NewGame(fen).Position()
+
+	var others []string
+	for _, legal := range position.ValidMoves() {
+		if san := notation.Encode(position, legal); san != move.Notation {
+			others = append(others, san)
+		}
+	}
+	if len(others) == 0 {
+		return move
+	}
+	san := others[rand.Intn(len(others))]
+	ai.Logger.Debug("🎲 %sBlundering with %s instead of %s%s", ColorYellow, san, move.Notation, ColorReset)
+	return &ChessMove{Notation: san, Provider: move.Provider}
+}
@@ -0,0 +1,78 @@
+package ai_player
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAdaptDifficulty(t *testing.T) {
+	if got := AdaptDifficulty("", nil); got.Name != "medium" {
+		t.Errorf("Expected a new opponent to start at medium, got %s", got.Name)
+	}
+	if got := AdaptDifficulty("easy", []float64{1, 1}); got.Name != "medium" || got.Temperature != 0.6 {
+		t.Errorf("Expected two wins to move easy up to medium, got %+v", got)
+	}
+	if got := AdaptDifficulty("medium", []float64{0, 0, 0, 0, 0, 0}); got.Name != "easy" {
+		t.Errorf("Expected a losing streak to bottom out at easy, got %s", got.Name)
+	}
+	if got := AdaptDifficulty("hard", []float64{1, 0.5, 1}); got.Name != "hard" {
+		t.Errorf("Expected hard to stay hard, got %s", got.Name)
+	}
+
+	// Half a level up from easy blends easy and medium
+	got := AdaptDifficulty("easy", []float64{1})
+	if got.Temperature != 0.8 || got.BlunderRate != 0.125 {
+		t.Errorf("Expected temperature 0.8 and blunder rate 0.125, got %v and %v", got.Temperature, got.BlunderRate)
+	}
+}
+
+func TestOpponentConfigUsesAdaptedDifficulty(t *testing.T) {
+	adapted := AdaptDifficulty("hard", []float64{0, 0, 0, 0})
+	config, err := Opponent{Name: "Gus", Difficulty: "hard", Adapted: &adapted}.Config(DefaultConfig())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.Temperature != 1.0 || config.BlunderRate != 0.2 || config.EngineDepth != 1 {
+		t.Errorf("Expected the easy level's settings, got temperature %v, blunder rate %v, depth %d",
+			config.Temperature, config.BlunderRate, config.EngineDepth)
+	}
+}
+
+func TestEngineDepthOneTakesDefendedPawn(t *testing.T) {
+	// The pawn on d5 is defended by the pawn on e6
+	fen := "4k3/8/4p3/3p4/8/8/8/3QK3 w - - 0 1"
+	shallow := &EngineProvider{Depth: 1}
+	move, err := shallow.SelectMove(context.Background(), MoveRequest{FEN: fen})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Notation != "Qxd5" {
+		t.Errorf("Expected depth 1 to grab the pawn, got %s", move.Notation)
+	}
+
+	move, err = NewEngineProvider().SelectMove(context.Background(), MoveRequest{FEN: fen})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Notation == "Qxd5" {
+		t.Errorf("Expected the default depth to keep the queen, got %s", move.Notation)
+	}
+}
+
+func TestBlunderRatePlaysAnotherMove(t *testing.T) {
+	fen := "rnb1kbnr/pppp1ppp/8/4p3/3q4/5N2/PPPPPPPP/RNBQKB1R w KQkq - 0 3"
+	player := NewAIPlayer("", "m", "white", nil)
+	player.Provider = NewEngineProvider()
+	player.BlunderRate = 1
+
+	move, err := player.GetMove(fen, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Notation == "Nxd4" || move.Notation == "" {
+		t.Errorf("Expected a blunder instead of Nxd4, got %q", move.Notation)
+	}
+	if move.Provider != ProviderEngine {
+		t.Errorf("Expected the move credited to the engine, got %s", move.Provider)
+	}
+}
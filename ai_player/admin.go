@@ -0,0 +1,195 @@
+package ai_player
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AdminUpdate is a change to the server's AI settings sent to /admin. Fields
+// left out keep their current values.
+type AdminUpdate struct {
+	Model         *string           `json:"model,omitempty"`
+	Temperature   *float64          `json:"temperature,omitempty"`
+	TopP          *float64          `json:"top_p,omitempty"`
+	CustomPrompts map[string]string `json:"custom_prompts,omitempty"` // merged into the current prompts; "" deletes a key
+	Personality   *string           `json:"personality,omitempty"`    // a built-in preset, applied before CustomPrompts
+}
+
+// AdminSettings is the AI configuration reported by /admin
+type AdminSettings struct {
+	Provider      string            `json:"provider"`
+	Model         string            `json:"model"`
+	Temperature   float64           `json:"temperature"`
+	TopP          float64           `json:"top_p"`
+	CustomPrompts map[string]string `json:"custom_prompts,omitempty"`
+	Changes       []string          `json:"changes,omitempty"`
+}
+
+// AdminSession is a game in progress, as listed by /admin/sessions
+type AdminSession struct {
+	ID          string    `json:"id"` // the game's A2A context ID
+	FEN         string    `json:"fen"`
+	Moves       int       `json:"moves"`
+	PlayerColor string    `json:"player_color,omitempty"`
+	Personality string    `json:"personality,omitempty"`
+	Model       string    `json:"model,omitempty"`
+	Updated     time.Time `json:"updated"`
+}
+
+// apply returns a copy of config with the update's changes
+func (u AdminUpdate) apply(config Config) (*Config, error) {
+	config.CustomPrompts = maps.Clone(config.CustomPrompts)
+	if u.Model != nil {
+		config.Model = *u.Model
+	}
+	if u.Temperature != nil {
+		config.Temperature = *u.Temperature
+	}
+	if u.TopP != nil {
+		config.TopP = *u.TopP
+	}
+	if u.Personality != nil {
+		if err := config.ApplyPersonality(*u.Personality); err != nil {
+			return nil, err
+		}
+	}
+	for key, prompt := range u.CustomPrompts {
+		if config.CustomPrompts == nil {
+			config.CustomPrompts = make(map[string]string)
+		}
+		if prompt == "" {
+			delete(config.CustomPrompts, key)
+		} else {
+			config.CustomPrompts[key] = prompt
+		}
+	}
+	return &config, nil
+}
+
+// handleAdmin lets operators read (GET) and change (POST) the AI player's
+// model, sampling settings and prompts on a running server. Requests must
+// carry the admin token as a bearer token; without a token the endpoint is
+// disabled.
+func handleAdmin(aiPlayer *AIPlayer, token string, logger *ColoredLogger) http.HandlerFunc {
+	return requireAdminToken(token, logger, func(w http.ResponseWriter, r *http.Request) {
+		var changes []string
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var update AdminUpdate
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				http.Error(w, fmt.Sprintf("invalid update: %v", err), http.StatusBadRequest)
+				return
+			}
+			config, err := update.apply(aiPlayer.Config())
+			if err == nil {
+				changes, err = aiPlayer.ApplyConfig(config)
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			logger.Info("🛠️ %sAdmin updated AI settings: %s%s", ColorCyan, strings.Join(changes, ", "), ColorReset)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		config := aiPlayer.Config()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(AdminSettings{
+			Provider:      aiPlayer.ProviderName(),
+			Model:         config.Model,
+			Temperature:   config.Temperature,
+			TopP:          config.TopP,
+			CustomPrompts: config.CustomPrompts,
+			Changes:       changes,
+		})
+	})
+}
+
+// handleAdminEndpoints adds the admin endpoints to mux, all needing token:
+//
+//	GET, POST /admin                 read or change the AI settings
+//	GET       /admin/sessions        list the games in progress
+//	DELETE    /admin/sessions/{id}   kick a session
+//	GET       /admin/metrics         live request, latency and queue figures
+func handleAdminEndpoints(mux *http.ServeMux, aiPlayer *AIPlayer, sessions *SessionStore, metrics *serverMetrics, token string, logger *ColoredLogger) {
+	mux.HandleFunc("/admin", handleAdmin(aiPlayer, token, logger))
+	mux.HandleFunc("GET /admin/sessions", requireAdminToken(token, logger, handleAdminSessions(sessions)))
+	mux.HandleFunc("DELETE /admin/sessions/{id}", requireAdminToken(token, logger, handleAdminKick(sessions, logger)))
+	mux.HandleFunc("GET /admin/metrics", requireAdminToken(token, logger, handleAdminMetrics(aiPlayer, sessions, metrics)))
+}
+
+// requireAdminToken wraps an admin endpoint so it needs token as a bearer
+// token, and is disabled without one
+func requireAdminToken(token string, logger *ColoredLogger, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.Error(w, "admin endpoint disabled; start the server with an admin token", http.StatusNotFound)
+			return
+		}
+		given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			logger.Warn("⚠️ %sRejected unauthorized admin request from %s%s", ColorYellow, r.RemoteAddr, ColorReset)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="bubblechess admin"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleAdminSessions lists the games in progress, most recently moved first
+func handleAdminSessions(sessions *SessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list := []AdminSession{}
+		for id, session := range sessions.List() {
+			list = append(list, AdminSession{
+				ID:          id,
+				FEN:         session.FEN,
+				Moves:       len(session.History),
+				PlayerColor: session.PlayerColor,
+				Personality: session.Personality,
+				Model:       session.Model,
+				Updated:     session.Updated,
+			})
+		}
+		slices.SortFunc(list, func(a, b AdminSession) int { return b.Updated.Compare(a.Updated) })
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	}
+}
+
+// handleAdminKick ends a session, so its game gets no more moves
+func handleAdminKick(sessions *SessionStore, logger *ColoredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		found, err := sessions.Kick(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.Error(w, "no such session", http.StatusNotFound)
+			return
+		}
+		logger.Info("🛠️ %sAdmin kicked session %s%s", ColorCyan, id, ColorReset)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleAdminMetrics reports the server's live figures
+func handleAdminMetrics(aiPlayer *AIPlayer, sessions *SessionStore, metrics *serverMetrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(metrics.snapshot(aiPlayer, sessions))
+	}
+}
@@ -0,0 +1,116 @@
+package ai_player
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"chess-tui/netconfig"
+)
+
+// AdminClient talks to the admin endpoints of a running A2A server
+type AdminClient struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+// NewAdminClient creates a client for the server at url, e.g.
+// "http://localhost:8080", authenticating with the admin token
+func NewAdminClient(url, token string) *AdminClient {
+	return &AdminClient{
+		url:   strings.TrimRight(url, "/"),
+		token: token,
+		http:  netconfig.NewClient(10 * time.Second),
+	}
+}
+
+// URL returns the server's address
+func (c *AdminClient) URL() string {
+	return c.url
+}
+
+// Settings returns the AI player's current settings
+func (c *AdminClient) Settings(ctx context.Context) (AdminSettings, error) {
+	var settings AdminSettings
+	if err := c.do(ctx, http.MethodGet, "/admin", nil, &settings); err != nil {
+		return AdminSettings{}, fmt.Errorf("failed to get settings: %w", err)
+	}
+	return settings, nil
+}
+
+// Update changes the AI player's settings and returns the result
+func (c *AdminClient) Update(ctx context.Context, update AdminUpdate) (AdminSettings, error) {
+	var settings AdminSettings
+	if err := c.do(ctx, http.MethodPost, "/admin", update, &settings); err != nil {
+		return AdminSettings{}, fmt.Errorf("failed to update settings: %w", err)
+	}
+	return settings, nil
+}
+
+// Sessions lists the games in progress, most recently moved first
+func (c *AdminClient) Sessions(ctx context.Context) ([]AdminSession, error) {
+	var sessions []AdminSession
+	if err := c.do(ctx, http.MethodGet, "/admin/sessions", nil, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// Kick ends the session with context ID id
+func (c *AdminClient) Kick(ctx context.Context, id string) error {
+	if err := c.do(ctx, http.MethodDelete, "/admin/sessions/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("failed to kick session: %w", err)
+	}
+	return nil
+}
+
+// Metrics returns the server's live figures
+func (c *AdminClient) Metrics(ctx context.Context) (AdminMetrics, error) {
+	var metrics AdminMetrics
+	if err := c.do(ctx, http.MethodGet, "/admin/metrics", nil, &metrics); err != nil {
+		return AdminMetrics{}, fmt.Errorf("failed to get metrics: %w", err)
+	}
+	return metrics, nil
+}
+
+// do sends a request with the admin token and decodes the JSON reply into out
+func (c *AdminClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package ai_player
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// adminRequest sends a request to an admin handler and returns the recorder
+func adminRequest(handler http.HandlerFunc, method, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/admin", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler(recorder, req)
+	return recorder
+}
+
+func TestAdminUpdatesSettings(t *testing.T) {
+	player, err := NewAIPlayerFromConfig(DefaultConfig(), "black", quietLogger())
+	if err != nil {
+		t.Fatalf("Failed to create player: %v", err)
+	}
+	handler := handleAdmin(player, "s3cret", quietLogger())
+
+	recorder := adminRequest(handler, http.MethodPost, "s3cret",
+		`{"model": "qwen3:8b", "temperature": 0.5, "personality": "gambiteer", "custom_prompts": {"commentary_tone": "Be terse."}}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", recorder.Code, recorder.Body)
+	}
+
+	var settings AdminSettings
+	if err := json.NewDecoder(recorder.Body).Decode(&settings); err != nil {
+		t.Fatalf("Failed to decode settings: %v", err)
+	}
+	if settings.Model != "qwen3:8b" || settings.Temperature != 0.5 || len(settings.Changes) == 0 {
+		t.Errorf("Unexpected settings: %+v", settings)
+	}
+	if player.Model != "qwen3:8b" || player.CustomPrompts[PromptCommentaryTone] != "Be terse." ||
+		player.CustomPrompts[PromptPersonality] != "gambiteer" {
+		t.Errorf("Expected the player to be updated, got model %s with prompts %v", player.Model, player.CustomPrompts)
+	}
+
+	// Invalid values are rejected and leave the player alone
+	recorder = adminRequest(handler, http.MethodPost, "s3cret", `{"temperature": 9}`)
+	if recorder.Code != http.StatusBadRequest || player.Temperature != 0.5 {
+		t.Errorf("Expected a rejected update, got %d with temperature %v", recorder.Code, player.Temperature)
+	}
+}
+
+func TestAdminRequiresToken(t *testing.T) {
+	player := NewAIPlayer("", "", "black", quietLogger())
+
+	tests := []struct {
+		name, serverToken, token string
+		want                     int
+	}{
+		{"disabled", "", "anything", http.StatusNotFound},
+		{"missing", "s3cret", "", http.StatusUnauthorized},
+		{"wrong", "s3cret", "guess", http.StatusUnauthorized},
+		{"right", "s3cret", "s3cret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		recorder := adminRequest(handleAdmin(player, tt.serverToken, quietLogger()), http.MethodGet, tt.token, "")
+		if recorder.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, recorder.Code)
+		}
+	}
+}
+
+func TestAdminClientSessionsKickAndMetrics(t *testing.T) {
+	logger := quietLogger()
+	config := DefaultConfig()
+	config.Provider = ProviderEngine
+	player, err := NewAIPlayerFromConfig(config, "black", logger)
+	if err != nil {
+		t.Fatalf("Failed to create player: %v", err)
+	}
+	sessions, _ := LoadSessionStore("")
+	metrics := newServerMetrics()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a2a", jsonrpcEndpoint(player, sessions, metrics, logger))
+	handleAdminEndpoints(mux, player, sessions, metrics, "s3cret", logger)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	for _, id := range []string{"game_1", "game_2"} {
+		postChessRequest(t, server.URL+"/a2a", id, ChessRequest{
+			BoardState:  "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			PlayerColor: "black",
+			GameHistory: []string{"e4"},
+		})
+	}
+
+	client := NewAdminClient(server.URL, "s3cret")
+	ctx := context.Background()
+	list, err := client.Sessions(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list sessions: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != "game_2" || list[0].Moves != 2 {
+		t.Fatalf("Expected both games, the latest first, got %+v", list)
+	}
+
+	got, err := client.Metrics(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get metrics: %v", err)
+	}
+	if got.Requests != 2 || got.Errors != 0 || got.Sessions != 2 || got.ActiveSessions != 2 || got.Provider != ProviderEngine {
+		t.Errorf("Expected 2 requests and 2 active sessions, got %+v", got)
+	}
+
+	// A kicked game is dropped and gets no more moves
+	if err := client.Kick(ctx, "game_1"); err != nil {
+		t.Fatalf("Failed to kick: %v", err)
+	}
+	if err := client.Kick(ctx, "game_1"); err == nil {
+		t.Error("Expected an error kicking a session twice")
+	}
+	reply := postChessRequest(t, server.URL+"/a2a", "game_1", ChessRequest{})
+	if !strings.Contains(string(reply), "-32011") {
+		t.Errorf("Expected the kicked session refused, got %s", reply)
+	}
+	if list, _ := client.Sessions(ctx); len(list) != 1 {
+		t.Errorf("Expected one session left, got %+v", list)
+	}
+
+	// The model can be changed through the client too
+	model := "qwen3:8b"
+	if settings, err := client.Update(ctx, AdminUpdate{Model: &model}); err != nil || settings.Model != model {
+		t.Errorf("Expected the model changed, got %+v, %v", settings, err)
+	}
+	if _, err := NewAdminClient(server.URL, "wrong").Metrics(ctx); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected a wrong token refused, got %v", err)
+	}
+}
@@ -1,7 +1,6 @@
 package ai_player
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -9,7 +8,11 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
+
+	"chess-tui/errkind"
+	"chess-tui/notation"
 )
 
 // OllamaRequest represents the request sent to Ollama
@@ -18,6 +21,19 @@ type OllamaRequest struct {
 	Prompt  string                 `json:"prompt"`
 	Stream  bool                   `json:"stream"`
 	Options map[string]interface{} `json:"options,omitempty"`
+	Think   *bool                  `json:"think,omitempty"` // false turns off a reasoning model's thinking
+
+	// Phase is the game phase of the position asked about, for providers
+	// that prompt by phase; it isn't sent to Ollama
+	Phase string `json:"-"`
+
+	// maxThinking, when positive, cuts a thinking trace short after that
+	// many tokens and asks again with thinking off
+	maxThinking int
+
+	// stop, when set, ends a streamed response early once the text so far
+	// satisfies it, cancelling the rest of the generation
+	stop func(response string) (string, bool)
 }
 
 // OllamaResponse represents the response from Ollama
@@ -33,6 +49,11 @@ type OllamaResponse struct {
 	PromptEvalDuration int64  `json:"prompt_eval_duration,omitempty"`
 	EvalCount          int    `json:"eval_count,omitempty"`
 	EvalDuration       int64  `json:"eval_duration,omitempty"`
+	Thinking           string `json:"thinking,omitempty"`
+
+	// Logprobs are the reply's tokens with their log probabilities, from
+	// providers asked for them
+	Logprobs []TokenLogprob `json:"-"`
 }
 
 // ChessMove represents a chess move in standard notation
@@ -44,6 +65,20 @@ type ChessMove struct {
 	Check     bool   `json:"check,omitempty"`
 	Checkmate bool   `json:"checkmate,omitempty"`
 	Notation  string `json:"notation"`
+	Reasoning string `json:"reasoning,omitempty"`
+	Provider  string `json:"provider,omitempty"` // backend that produced the move
+	Eval      *int   `json:"eval,omitempty"`     // centipawns for the mover, if the backend reports one
+	Votes     []Vote `json:"votes,omitempty"`    // how a vote chess panel chose the move
+	Spare     bool   `json:"spare,omitempty"`    // the spare model answered, the primary having timed out
+
+	// Confidence is how sure the backend is of the move, from 0 to 1, if
+	// it says: from token probabilities, a vote's margin or the model's
+	// own report
+	Confidence *float64 `json:"confidence,omitempty"`
+
+	// Token usage reported by the model for this move
+	PromptTokens     int `json:"prompt_tokens,omitempty"`
+	CompletionTokens int `json:"completion_tokens,omitempty"`
 }
 
 // AIPlayer represents an AI chess player
@@ -53,6 +88,66 @@ type AIPlayer struct {
 	Client    *http.Client
 	Color     string // "white" or "black"
 	Logger    *ColoredLogger
+	Provider  Provider // nil uses Ollama at OllamaURL
+	Tracer    *Tracer  // when set, every backend call is written to a trace file
+
+	// Context, when set, bounds every backend call: once it ends, calls in
+	// flight are cancelled, e.g. when the game using the player is closed
+	Context context.Context
+
+	// Temperature and TopP tune move requests; zero keeps the built-in values
+	Temperature float64
+	TopP        float64
+
+	// Think turns a reasoning model's thinking on or off; nil leaves the
+	// model's default. MaxThinkingTokens caps thinking, 0 for no cap.
+	Think             *bool
+	MaxThinkingTokens int
+
+	config     *Config    // the configuration the player was built from, if any
+	settingsMu sync.Mutex // serializes config reloads and admin changes
+
+	// PromptTokenBudget caps the move prompts of chat providers, which are
+	// sent the whole game; older moves are summarized to stay under it.
+	// 0 uses DefaultPromptTokenBudget.
+	PromptTokenBudget int
+
+	// ResignThreshold and DrawMargin steer Decide, in centipawns of
+	// material: the AI resigns that far behind and takes draws within the
+	// margin. 0 uses the defaults; a negative value never resigns or draws.
+	ResignThreshold int
+	DrawMargin      int
+
+	// Reviewer, when set, is a second agent the A2A server asks to review
+	// each move before replying with it
+	Reviewer *Reviewer
+
+	// BlunderRate is the chance GetMove plays a random legal move instead
+	// of the chosen one
+	BlunderRate float64
+
+	// Bullet plays for speed: book moves in the opening, remembered moves
+	// in positions seen before, no thinking and a short answer from the
+	// model (see Config.Bullet)
+	Bullet    bool
+	responses responseCache
+
+	// repertoire is the opening lines the player follows before asking
+	// the model, if any
+	repertoire *repertoireBook
+
+	// Personality names a built-in preset for the current game; when empty
+	// the move_style and commentary_tone CustomPrompts are used
+	Personality   string
+	CustomPrompts map[string]string
+}
+
+// baseContext returns the context backend calls are made under
+func (ai *AIPlayer) baseContext() context.Context {
+	if ai.Context == nil {
+		return context.Background()
+	}
+	return ai.Context
 }
 
 // NewAIPlayer creates a new AI player
@@ -70,40 +165,81 @@ func NewAIPlayer(ollamaURL, model, color string, logger *ColoredLogger) *AIPlaye
 	return &AIPlayer{
 		OllamaURL: ollamaURL,
 		Model:     model,
-		Client: &http.Client{
-			Timeout: 60 * time.Second, // Reduced timeout to 1 minute for faster responses
-		},
-		Color:  color,
-		Logger: logger,
+		Client:    newHTTPClient(60 * time.Second), // 1 minute keeps moves responsive
+		Color:     color,
+		Logger:    logger,
 	}
 }
 
 // GetMove gets the next move from the AI player
 func (ai *AIPlayer) GetMove(boardState string, gameHistory []string) (*ChessMove, error) {
+	move, err := ai.ReviseMove(boardState, gameHistory, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ai.blunder(boardState, move), nil
+}
+
+// ReviseMove gets a move like GetMove after a reviewer has vetoed earlier
+// proposals, telling the model which moves were rejected and why
+func (ai *AIPlayer) ReviseMove(boardState string, gameHistory []string, vetoes []Veto) (*ChessMove, error) {
 	ai.Logger.Debug("🎯 %sAI GetMove called - Color: %s, Board: %d chars, History: %d moves%s",
 		ColorBlue, ai.Color, len(boardState), len(gameHistory), ColorReset)
 
-	prompt := ai.buildPrompt(boardState, gameHistory)
+	// The repertoire is played without the model while the game is in it
+	if len(vetoes) == 0 {
+		if move, ok := ai.fromRepertoire(boardState); ok {
+			return move, nil
+		}
+	}
+
+	// Bullet mode skips the model where it can; a vetoed move needs a new one
+	if ai.Bullet && len(vetoes) == 0 {
+		if move, ok := ai.bulletMove(boardState); ok {
+			return move, nil
+		}
+	}
+
+	prompt := withVetoes(ai.buildPrompt(boardState, gameHistory), vetoes)
 	ai.Logger.Debug("📝 %sGenerated prompt: %d chars%s", ColorCyan, len(prompt), ColorReset)
 
 	request := OllamaRequest{
-		Model:  ai.Model,
-		Prompt: prompt,
-		Stream: false,
-		Options: map[string]interface{}{
-			"temperature":    0.3, // Slightly higher for faster decisions
-			"top_p":          0.8, // Lower for more focused responses
-			"top_k":          20,  // Limit vocabulary for faster generation
-			"repeat_penalty": 1.1, // Prevent repetitive thinking
-		},
+		Model:   ai.Model,
+		Prompt:  prompt,
+		Stream:  false,
+		Options: ai.moveOptions(),
+		Think:   ai.Think,
+		Phase:   DetectPhase(boardState),
+
+		maxThinking: ai.MaxThinkingTokens,
+		stop:        completeMove,
+	}
+	if ai.Bullet {
+		think := false
+		request.Think = &think
+		request.maxThinking = 0
 	}
 
-	ai.Logger.Debug("🚀 %sCalling Ollama API - Model: %s%s", ColorGreen, ai.Model, ColorReset)
+	ai.Logger.Debug("🚀 %sCalling %s API - Model: %s%s", ColorGreen, ai.ProviderName(), ai.Model, ColorReset)
+
+	if move, ok, err := ai.selectMove(request, boardState, vetoedMoves(vetoes)); ok {
+		if err != nil {
+			ai.Logger.Error("❌ %s%s move selection failed: %v%s", ColorRed, ai.ProviderName(), err, ColorReset)
+			return nil, fmt.Errorf("failed to call %s: %w", ai.ProviderName(), err)
+		}
+		if move.Provider == "" {
+			move.Provider = ai.ProviderName()
+		}
+		if ai.Bullet && len(vetoes) == 0 {
+			ai.rememberMove(boardState, move)
+		}
+		return move, nil
+	}
 
-	response, err := ai.callOllama(request)
+	response, err := ai.generate(request)
 	if err != nil {
-		ai.Logger.Error("❌ %sOllama API call failed: %v%s", ColorRed, err, ColorReset)
-		return nil, fmt.Errorf("failed to call Ollama: %w", err)
+		ai.Logger.Error("❌ %s%s API call failed: %v%s", ColorRed, ai.ProviderName(), err, ColorReset)
+		return nil, fmt.Errorf("failed to call %s: %w", ai.ProviderName(), err)
 	}
 
 	ai.Logger.Debug("✅ %sOllama API call successful - Response: %d chars%s", ColorGreen, len(response.Response), ColorReset)
@@ -114,10 +250,48 @@ func (ai *AIPlayer) GetMove(boardState string, gameHistory []string) (*ChessMove
 		return nil, fmt.Errorf("failed to parse AI response: %w", err)
 	}
 
+	move.Reasoning = summarizeThinking(response.Thinking)
+	move.PromptTokens = response.PromptEvalCount
+	move.CompletionTokens = response.EvalCount
+	move.Confidence = moveConfidence(response.Logprobs, move.Notation)
+	move.Provider = ai.ProviderName()
+	if ai.Bullet && len(vetoes) == 0 {
+		ai.rememberMove(boardState, move)
+	}
+
 	ai.Logger.Debug("🎉 %sSuccessfully parsed AI move: %s%s", ColorGreen, move.Notation, ColorReset)
 	return move, nil
 }
 
+// moveOptions returns the sampling options for a move request
+func (ai *AIPlayer) moveOptions() map[string]interface{} {
+	options := map[string]interface{}{
+		"temperature":    0.3, // Slightly higher for faster decisions
+		"top_p":          0.8, // Lower for more focused responses
+		"top_k":          20,  // Limit vocabulary for faster generation
+		"repeat_penalty": 1.1, // Prevent repetitive thinking
+	}
+	if ai.Temperature > 0 {
+		options["temperature"] = ai.Temperature
+	}
+	if ai.TopP > 0 {
+		options["top_p"] = ai.TopP
+	}
+	if ai.Bullet {
+		options["num_predict"] = BulletNumPredict
+	}
+	return options
+}
+
+// Prompt returns the prompt the player would send for a position without
+// calling the model. task is "" for a move or TaskSuggest for teach mode.
+func (ai *AIPlayer) Prompt(boardState string, gameHistory []string, task string) string {
+	if task == TaskSuggest {
+		return ai.buildTeachPrompt(boardState, gameHistory)
+	}
+	return ai.buildPrompt(boardState, gameHistory)
+}
+
 // buildPrompt creates a prompt for the AI to generate a chess move
 func (ai *AIPlayer) buildPrompt(boardState string, gameHistory []string) string {
 	var prompt strings.Builder
@@ -126,11 +300,38 @@ func (ai *AIPlayer) buildPrompt(boardState string, gameHistory []string) string
 	prompt.WriteString(ai.Color)
 	prompt.WriteString(". Make a quick, solid move.\n\n")
 
+	phase := DetectPhase(boardState)
+	if moveStyle, commentary := ai.personalityPrompts(phase); moveStyle != "" || commentary != "" {
+		prompt.WriteString("PERSONALITY:\n")
+		if moveStyle != "" {
+			prompt.WriteString(moveStyle + "\n")
+		}
+		if commentary != "" {
+			prompt.WriteString("When you explain your move: " + commentary + "\n")
+		}
+		prompt.WriteString("\n")
+	}
+
+	if guidance := ai.CustomPrompts[PromptPhaseGuidance+"."+phase]; guidance != "" && phase != "" {
+		prompt.WriteString("GAME PHASE: " + phase + "\n" + guidance + "\n\n")
+	}
+
 	prompt.WriteString("Current board position:\n")
 	prompt.WriteString(boardState)
 	prompt.WriteString("\n\n")
 
-	if len(gameHistory) > 0 {
+	// Chat models get the whole game, summarized once it outgrows the budget
+	var instructions strings.Builder
+	ai.writeInstructions(&instructions)
+	if len(gameHistory) > 0 && ai.chatProvider() {
+		budget := ai.promptTokenBudget() - estimateTokens(prompt.String()) - estimateTokens(instructions.String())
+		record, summarized := gameRecord(gameHistory, budget)
+		if summarized > 0 {
+			ai.Logger.Debug("🗜️ %sSummarized the first %d of %d moves to stay under %d prompt tokens%s",
+				ColorCyan, summarized, len(gameHistory), ai.promptTokenBudget(), ColorReset)
+		}
+		prompt.WriteString(record)
+	} else if len(gameHistory) > 0 {
 		prompt.WriteString("Game history (last 3 moves):\n")
 		start := len(gameHistory) - 3
 		if start < 0 {
@@ -141,7 +342,17 @@ func (ai *AIPlayer) buildPrompt(boardState string, gameHistory []string) string
 		}
 		prompt.WriteString("\n")
 	}
+	prompt.WriteString(instructions.String())
+
+	finalPrompt := prompt.String()
+	ai.Logger.Debug("📝 %sPrompt construction complete - Length: %d chars, Speed: fast_thinking%s",
+		ColorCyan, len(finalPrompt), ColorReset)
 
+	return finalPrompt
+}
+
+// writeInstructions writes the closing instructions of a move prompt
+func (ai *AIPlayer) writeInstructions(prompt *strings.Builder) {
 	prompt.WriteString("SPEED INSTRUCTIONS:\n")
 	prompt.WriteString("1. Think FAST - spend no more than 10-15 seconds analyzing\n")
 	prompt.WriteString("2. Look for obvious tactics (checks, captures, threats) first\n")
@@ -159,16 +370,11 @@ func (ai *AIPlayer) buildPrompt(boardState string, gameHistory []string) string
 	prompt.WriteString("7. Your response must be exactly one move in short algebraic notation\n\n")
 
 	prompt.WriteString("Your move (short algebraic notation only): ")
-
-	finalPrompt := prompt.String()
-	ai.Logger.Debug("📝 %sPrompt construction complete - Length: %d chars, Speed: fast_thinking%s",
-		ColorCyan, len(finalPrompt), ColorReset)
-
-	return finalPrompt
 }
 
-// callOllama makes an HTTP request to the Ollama API with streaming support
-func (ai *AIPlayer) callOllama(request OllamaRequest) (*OllamaResponse, error) {
+// callOllama makes an HTTP request to the Ollama API with streaming support.
+// When raw is not nil the response body is copied to it as it is read.
+func (ai *AIPlayer) callOllama(request OllamaRequest, raw io.Writer) (*OllamaResponse, error) {
 	// Enable streaming for better progress tracking
 	request.Stream = true
 
@@ -181,7 +387,7 @@ func (ai *AIPlayer) callOllama(request OllamaRequest) (*OllamaResponse, error) {
 		ColorGreen, request.Model, len(request.Prompt), ColorReset)
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second) // Reduced timeout to 1 minute for faster responses
+	ctx, cancel := context.WithTimeout(ai.baseContext(), 60*time.Second) // Reduced timeout to 1 minute for faster responses
 	defer cancel()
 
 	// Create request with context
@@ -198,121 +404,105 @@ func (ai *AIPlayer) callOllama(request OllamaRequest) (*OllamaResponse, error) {
 	}
 	defer resp.Body.Close()
 
+	var body io.Reader = resp.Body
+	if raw != nil {
+		body = io.TeeReader(resp.Body, raw)
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(body))
+		body, _ := io.ReadAll(body)
+		if isModelNotFound(resp.StatusCode, body) {
+			return nil, ai.modelNotFound(request.Model)
+		}
+		return nil, statusError("Ollama API", resp.StatusCode, body)
 	}
 
 	// Handle streaming response
-	var fullResponse strings.Builder
-	var thinkingBuffer strings.Builder
 	var lastProgressTime time.Time
 	startTime := time.Now()
-	lineCount := 0
-
-	ai.Logger.Info("📖 %sStarting to read streaming response%s", ColorBlue, ColorReset)
-
-	scanner := bufio.NewScanner(resp.Body)
-	for scanner.Scan() {
-		line := scanner.Text()
-		lineCount++
-
-		if line == "" {
-			continue
-		}
-
-		// Parse streaming response - handle both "thinking" and "response" fields
-		var streamResp struct {
-			Response string `json:"response"`
-			Thinking string `json:"thinking"`
-			Done     bool   `json:"done"`
-		}
-
-		if err := json.Unmarshal([]byte(line), &streamResp); err != nil {
-			ai.Logger.Debug("⚠️ %sFailed to parse streaming response line: %s - Error: %v%s",
-				ColorYellow, line, err, ColorReset)
-			continue
-		}
-
-		// Capture thinking content (this is where Ollama shows its analysis)
-		if streamResp.Thinking != "" {
-			thinkingBuffer.WriteString(streamResp.Thinking)
-
-			// Log thinking progress every 15 seconds
-			if time.Since(lastProgressTime) > 15*time.Second {
-				elapsed := time.Since(startTime)
-				currentThinking := thinkingBuffer.String()
-				// Show last 100 characters of thinking to avoid log spam
-				if len(currentThinking) > 100 {
-					currentThinking = "..." + currentThinking[len(currentThinking)-100:]
-				}
-				ai.Logger.Info("🧠 %sOllama thinking progress - Elapsed: %v, Length: %d chars, Current: %s%s",
-					ColorPurple, elapsed.Round(time.Second), thinkingBuffer.Len(), currentThinking, ColorReset)
-				lastProgressTime = time.Now()
+	parser := &streamParser{Stop: request.stop, MaxThinking: request.maxThinking}
+	parser.OnChunk = func(chunk streamChunk) {
+		// Log thinking progress every 15 seconds
+		if chunk.Thinking != "" && time.Since(lastProgressTime) > 15*time.Second {
+			currentThinking := parser.thinking.String()
+			// Show last 100 characters of thinking to avoid log spam
+			if len(currentThinking) > 100 {
+				currentThinking = "..." + currentThinking[len(currentThinking)-100:]
 			}
+			ai.Logger.Info("🧠 %sOllama thinking progress - Elapsed: %v, Length: %d chars, Current: %s%s",
+				ColorPurple, time.Since(startTime).Round(time.Second), parser.thinking.Len(), currentThinking, ColorReset)
+			lastProgressTime = time.Now()
 		}
-
-		// Add to full response (this is the actual move when done)
-		if streamResp.Response != "" {
-			fullResponse.WriteString(streamResp.Response)
-			ai.Logger.Info("📝 %sResponse content received: %s%s", ColorCyan, streamResp.Response, ColorReset)
-		}
-
-		// Check if done
-		if streamResp.Done {
-			elapsed := time.Since(startTime)
-			ai.Logger.Info("✅ %sOllama response completed - Time: %v, Response: %d chars, Thinking: %d chars, Lines: %d%s",
-				ColorGreen, elapsed.Round(100*time.Millisecond), fullResponse.Len(), thinkingBuffer.Len(), lineCount, ColorReset)
-			break
+		if chunk.Response != "" {
+			ai.Logger.Info("📝 %sResponse content received: %s%s", ColorCyan, chunk.Response, ColorReset)
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		ai.Logger.Error("❌ %sScanner error: %v - Lines processed: %d%s", ColorRed, err, lineCount, ColorReset)
-		return nil, fmt.Errorf("failed to read streaming response: %w", err)
-	}
+	ai.Logger.Info("📖 %sStarting to read streaming response%s", ColorBlue, ColorReset)
 
-	// Log final response details
-	ai.Logger.Info("📊 %sStreaming response summary - Lines: %d, Response: %d chars, Thinking: %d chars, Final: %s%s",
-		ColorBlue, lineCount, fullResponse.Len(), thinkingBuffer.Len(), fullResponse.String(), ColorReset)
+	if err := parser.Read(body); err != nil {
+		ai.Logger.Error("❌ %sScanner error: %v - Lines processed: %d%s", ColorRed, err, parser.Lines(), ColorReset)
+		return nil, err
+	}
+	response := parser.Result()
+	if !parser.Stopped() && !parser.Capped() {
+		// Read the end of the stream so the connection can be reused
+		drain(resp.Body)
+	}
 
-	// Create final response
-	response := &OllamaResponse{
-		Response: fullResponse.String(),
+	elapsed := time.Since(startTime).Round(100 * time.Millisecond)
+	if parser.Capped() {
+		ai.Logger.Warn("🧠 %sThinking passed %d tokens after %v; asking again with thinking off%s",
+			ColorYellow, request.maxThinking, elapsed, ColorReset)
+		// End this generation before starting the next
+		cancel()
+		resp.Body.Close()
+		off := false
+		request.Think = &off
+		request.maxThinking = 0
+		return ai.callOllama(request, raw)
+	}
+	ai.Logger.Info("⏱️ %sThinking latency - Think: %s, Thinking: %d tokens, First response token after: %v%s",
+		ColorBlue, thinkSetting(request.Think), parser.ThinkingTokens(), parser.FirstResponse().Round(100*time.Millisecond), ColorReset)
+	if parser.Stopped() {
+		// Returning cancels the request, which ends the generation
+		ai.Logger.Info("✂️ %sMove complete, cancelling the rest of the generation - Time: %v, Response: %s%s",
+			ColorGreen, elapsed, response.Response, ColorReset)
+	} else {
+		ai.Logger.Info("✅ %sOllama response completed - Time: %v, Response: %d chars, Thinking: %d chars, Lines: %d%s",
+			ColorGreen, elapsed, len(response.Response), len(response.Thinking), parser.Lines(), ColorReset)
 	}
 
 	return response, nil
 }
 
+// thinkSetting describes a request's think parameter for the logs
+func thinkSetting(think *bool) string {
+	switch {
+	case think == nil:
+		return "model default"
+	case *think:
+		return "on"
+	default:
+		return "off"
+	}
+}
+
 // parseMove parses the AI's response and extracts the chess move
 func (ai *AIPlayer) parseMove(response string) (*ChessMove, error) {
 	ai.Logger.Debug("🔍 %sParsing AI response - Raw: %s, Length: %d chars%s",
 		ColorBlue, response, len(response), ColorReset)
 
-	// Clean up the response
-	response = strings.TrimSpace(response)
-	response = strings.Split(response, "\n")[0] // Take only the first line
-	ai.Logger.Debug("🧹 %sCleaned response: %s%s", ColorCyan, response, ColorReset)
-
-	// Remove common prefixes/suffixes that AI might add
+	// Take only the first line, without the wrappers models add
 	originalResponse := response
-	response = strings.TrimPrefix(response, "Move: ")
-	response = strings.TrimPrefix(response, "The best move is ")
-	response = strings.TrimPrefix(response, "I suggest ")
-	response = strings.TrimSuffix(response, ".")
-	response = strings.TrimSuffix(response, "!")
-	response = strings.TrimSuffix(response, "?")
-
-	if originalResponse != response {
-		ai.Logger.Debug("✂️ %sRemoved prefixes/suffixes - Original: %s, Cleaned: %s%s",
-			ColorYellow, originalResponse, response, ColorReset)
-	}
+	response = cleanMoveLine(response)
+	ai.Logger.Debug("🧹 %sCleaned response: %s%s", ColorCyan, response, ColorReset)
 
 	// Validate that it looks like a chess move
-	if !ai.isValidMoveNotation(response) {
+	if !notation.IsMove(response) {
 		ai.Logger.Error("❌ %sInvalid move notation - Cleaned: %s, Original: %s%s",
 			ColorRed, response, originalResponse, ColorReset)
-		return nil, fmt.Errorf("invalid move notation: %s", response)
+		return nil, errkind.Errorf(errkind.IllegalMove, "invalid move notation: %s", response)
 	}
 
 	ai.Logger.Debug("✅ %sMove notation validated: %s%s", ColorGreen, response, ColorReset)
@@ -322,47 +512,12 @@ func (ai *AIPlayer) parseMove(response string) (*ChessMove, error) {
 	}, nil
 }
 
-// isValidMoveNotation checks if the move notation looks valid
-func (ai *AIPlayer) isValidMoveNotation(move string) bool {
-	if move == "" {
-		return false
-	}
-
-	// Check for castling
-	if move == "O-O" || move == "0-0" || move == "O-O-O" || move == "0-0-0" {
-		return true
-	}
-
-	// Check for long algebraic notation (e2e4)
-	if len(move) == 4 {
-		if (move[0] >= 'a' && move[0] <= 'h') &&
-			(move[1] >= '1' && move[1] <= '8') &&
-			(move[2] >= 'a' && move[2] <= 'h') &&
-			(move[3] >= '1' && move[3] <= '8') {
-			return true
-		}
-	}
-
-	// Check for short algebraic notation (Nc6, Kxe5, etc.)
-	if len(move) >= 2 {
-		// First character should be a piece or file
-		if (move[0] >= 'A' && move[0] <= 'Z') || (move[0] >= 'a' && move[0] <= 'h') {
-			// Last two characters should be coordinates
-			if len(move) >= 2 {
-				lastTwo := move[len(move)-2:]
-				if (lastTwo[0] >= 'a' && lastTwo[0] <= 'h') &&
-					(lastTwo[1] >= '1' && lastTwo[1] <= '8') {
-					return true
-				}
-			}
-		}
+// TestConnection tests the connection to Ollama or the configured provider
+func (ai *AIPlayer) TestConnection() error {
+	if ai.Provider != nil {
+		return ai.Provider.TestConnection()
 	}
 
-	return false
-}
-
-// TestConnection tests the connection to Ollama
-func (ai *AIPlayer) TestConnection() error {
 	ai.Logger.Info("🔍 %sTesting Ollama connection - URL: %s%s", ColorBlue, ai.OllamaURL, ColorReset)
 
 	// Test basic connectivity
@@ -376,6 +531,14 @@ func (ai *AIPlayer) TestConnection() error {
 		return fmt.Errorf("Ollama returned status %d", resp.StatusCode)
 	}
 
+	// Catch a model that isn't pulled now rather than on every move
+	var tags tagsResponse
+	if json.NewDecoder(resp.Body).Decode(&tags) == nil && tags.Models != nil {
+		if available := tags.names(); !hasModel(available, ai.Model) {
+			return &ModelNotFoundError{Model: ai.Model, OllamaURL: ai.OllamaURL, Available: available}
+		}
+	}
+
 	ai.Logger.Info("✅ %sOllama connection test successful%s", ColorGreen, ColorReset)
 	return nil
 }
@@ -395,6 +558,25 @@ func (ai *AIPlayer) TestModelResponse() error {
 		},
 	}
 
+	// The built-in engine has no model to ask
+	if _, isEngine := ai.Provider.(*EngineProvider); isEngine {
+		return nil
+	}
+
+	if ai.Provider != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		startTime := time.Now()
+		testResponse, err := ai.Provider.Generate(ctx, testRequest)
+		if err != nil {
+			return fmt.Errorf("test request failed: %w", err)
+		}
+		ai.Logger.Info("✅ %sModel test successful - Model: %s, Time: %v, Response: %s%s",
+			ColorGreen, ai.Model, time.Since(startTime).Round(100*time.Millisecond), testResponse.Response, ColorReset)
+		return nil
+	}
+
 	jsonData, err := json.Marshal(testRequest)
 	if err != nil {
 		return fmt.Errorf("failed to marshal test request: %w", err)
@@ -421,6 +603,9 @@ func (ai *AIPlayer) TestModelResponse() error {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
+		if isModelNotFound(resp.StatusCode, body) {
+			return ai.modelNotFound(ai.Model)
+		}
 		return fmt.Errorf("test request returned status %d: %s", resp.StatusCode, string(body))
 	}
 
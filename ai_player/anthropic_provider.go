@@ -0,0 +1,283 @@
+package ai_player
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"chess-tui/errkind"
+)
+
+// anthropicVersion is the Messages API version the provider speaks
+const anthropicVersion = "2023-06-01"
+
+// makeMoveTool is the name of the tool Claude must call to play a move
+const makeMoveTool = "make_move"
+
+// AnthropicProvider talks to the Anthropic Messages API. Moves are selected
+// through a tool call whose schema only admits legal moves, so no text
+// parsing is needed.
+type AnthropicProvider struct {
+	BaseURL   string
+	APIKey    string
+	Model     string
+	MaxTokens int
+	Client    *http.Client
+	Logger    *ColoredLogger
+}
+
+// anthropicMessage is a single message in a Messages API request
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// anthropicTool describes a tool the model may call
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// anthropicRequest is the body of a Messages API request
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	Messages    []anthropicMessage `json:"messages"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	ToolChoice  map[string]string  `json:"tool_choice,omitempty"`
+}
+
+// anthropicResponse is the subset of a Messages API response we use
+type anthropicResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Name  string          `json:"name"`
+		Input json.RawMessage `json:"input"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// makeMoveInput is the input Claude passes to the make_move tool
+type makeMoveInput struct {
+	Move       string   `json:"move"`
+	Reasoning  string   `json:"reasoning"`
+	Confidence *float64 `json:"confidence"`
+}
+
+// NewAnthropicProvider creates a provider for the Anthropic Messages API
+func NewAnthropicProvider(baseURL, apiKey, model string, logger *ColoredLogger) *AnthropicProvider {
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com"
+	}
+	if logger == nil {
+		logger = NewAIPlayerLogger()
+	}
+
+	return &AnthropicProvider{
+		BaseURL:   strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v1"),
+		APIKey:    apiKey,
+		Model:     model,
+		MaxTokens: 1024,
+		Client:    newHTTPClient(60 * time.Second),
+		Logger:    logger,
+	}
+}
+
+// Name identifies the provider
+func (p *AnthropicProvider) Name() string {
+	return ProviderAnthropic
+}
+
+// newRequest builds an authenticated request against the API
+func (p *AnthropicProvider) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.BaseURL+"/v1"+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("anthropic-version", anthropicVersion)
+	if p.APIKey != "" {
+		req.Header.Set("x-api-key", p.APIKey)
+	}
+	return req, nil
+}
+
+// send posts a Messages API request and decodes the response
+func (p *AnthropicProvider) send(ctx context.Context, body anthropicRequest) (*anthropicResponse, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := p.newRequest(ctx, "POST", "/messages", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, statusError("Anthropic API", resp.StatusCode, respBody)
+	}
+
+	var response anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &response, nil
+}
+
+// messagesRequest converts a generate request into a Messages API request
+func (p *AnthropicProvider) messagesRequest(request OllamaRequest) anthropicRequest {
+	body := anthropicRequest{
+		Model:     p.Model,
+		MaxTokens: p.MaxTokens,
+		Messages: []anthropicMessage{
+			{Role: "user", Content: request.Prompt},
+		},
+	}
+	if temperature, ok := request.Options["temperature"].(float64); ok {
+		body.Temperature = &temperature
+	}
+	return body
+}
+
+// Generate sends the prompt as a single user message and returns the text reply
+func (p *AnthropicProvider) Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error) {
+	response, err := p.send(ctx, p.messagesRequest(request))
+	if err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+
+	return &OllamaResponse{
+		Model:           response.Model,
+		Response:        text.String(),
+		Done:            true,
+		PromptEvalCount: response.Usage.InputTokens,
+		EvalCount:       response.Usage.OutputTokens,
+	}, nil
+}
+
+// SelectMove forces a make_move tool call whose move must be one of the legal moves
+func (p *AnthropicProvider) SelectMove(ctx context.Context, request MoveRequest) (*ChessMove, error) {
+	legalMoves := request.LegalMoves
+	body := p.messagesRequest(request.Generate)
+	body.Messages[0].Content += "\n\nCall the make_move tool with your move."
+	body.Tools = []anthropicTool{{
+		Name:        makeMoveTool,
+		Description: "Play a chess move. The move must be one of the legal moves in short algebraic notation.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"move": map[string]interface{}{
+					"type":        "string",
+					"enum":        legalMoves,
+					"description": "The move in short algebraic notation",
+				},
+				"reasoning": map[string]interface{}{
+					"type":        "string",
+					"description": "One or two sentences explaining the move, in the tone the prompt asks for",
+				},
+				"confidence": map[string]interface{}{
+					"type":        "number",
+					"minimum":     0,
+					"maximum":     1,
+					"description": "How sure you are this is the best move, from 0 (a guess) to 1 (certain)",
+				},
+			},
+			"required": []string{"move"},
+		},
+	}}
+	body.ToolChoice = map[string]string{"type": "tool", "name": makeMoveTool}
+
+	startTime := time.Now()
+	response, err := p.send(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, block := range response.Content {
+		if block.Type != "tool_use" || block.Name != makeMoveTool {
+			continue
+		}
+
+		var input makeMoveInput
+		if err := json.Unmarshal(block.Input, &input); err != nil {
+			return nil, fmt.Errorf("failed to decode make_move input: %w", err)
+		}
+		if !containsMove(legalMoves, input.Move) {
+			return nil, errkind.Errorf(errkind.IllegalMove, "make_move returned illegal move: %s", input.Move)
+		}
+
+		p.Logger.Info("✅ %sClaude selected %s - Time: %v, Tokens: %d/%d%s",
+			ColorGreen, input.Move, time.Since(startTime).Round(100*time.Millisecond),
+			response.Usage.InputTokens, response.Usage.OutputTokens, ColorReset)
+
+		return &ChessMove{
+			Notation:         input.Move,
+			Reasoning:        summarizeThinking(input.Reasoning),
+			Confidence:       clampConfidence(input.Confidence),
+			PromptTokens:     response.Usage.InputTokens,
+			CompletionTokens: response.Usage.OutputTokens,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("Anthropic API response did not call %s", makeMoveTool)
+}
+
+// TestConnection checks the API by listing its models
+func (p *AnthropicProvider) TestConnection() error {
+	p.Logger.Info("🔍 %sTesting Anthropic connection - URL: %s%s", ColorBlue, p.BaseURL, ColorReset)
+
+	req, err := p.newRequest(context.Background(), "GET", "/models", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Anthropic API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Anthropic API returned status %d", resp.StatusCode)
+	}
+
+	p.Logger.Info("✅ %sAnthropic connection test successful%s", ColorGreen, ColorReset)
+	return nil
+}
+
+// containsMove reports whether move is in moves
+func containsMove(moves []string, move string) bool {
+	for _, m := range moves {
+		if m == move {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package ai_player
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"chess-tui/notation"
+)
+
+func TestAnthropicProviderSelectMove(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get("x-api-key"); key != "secret" {
+			t.Errorf("Expected x-api-key header, got %q", key)
+		}
+
+		var request anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if request.ToolChoice["name"] != makeMoveTool || len(request.Tools) != 1 {
+			t.Errorf("Expected make_move to be forced, got %+v", request.ToolChoice)
+		}
+
+		w.Write([]byte(`{"model":"claude","content":[{"type":"tool_use","name":"make_move","input":{"move":"Nf3","reasoning":"Develops a piece."}}],"usage":{"input_tokens":40,"output_tokens":9}}`))
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(server.URL, "secret", "claude", nil)
+	move, err := provider.SelectMove(context.Background(), MoveRequest{
+		Generate:   OllamaRequest{Prompt: "Your move"},
+		LegalMoves: []string{"e4", "Nf3"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Notation != "Nf3" {
+		t.Errorf("Expected move Nf3, got %s", move.Notation)
+	}
+	if move.Reasoning != "Develops a piece." {
+		t.Errorf("Expected reasoning to be passed through, got %q", move.Reasoning)
+	}
+	if move.PromptTokens != 40 || move.CompletionTokens != 9 {
+		t.Errorf("Expected 40/9 tokens, got %d/%d", move.PromptTokens, move.CompletionTokens)
+	}
+
+	if _, err := provider.SelectMove(context.Background(), MoveRequest{LegalMoves: []string{"e4"}}); err == nil {
+		t.Errorf("Expected error for a move outside the legal list")
+	}
+}
+
+func TestLegalMoves(t *testing.T) {
+	moves, err := notation.LegalMoves("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(moves) != 20 {
+		t.Errorf("Expected 20 legal moves, got %d", len(moves))
+	}
+	if !containsMove(moves, "Nf3") {
+		t.Errorf("Expected Nf3 among legal moves, got %v", moves)
+	}
+}
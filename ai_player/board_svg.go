@@ -0,0 +1,138 @@
+package ai_player
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// Board image geometry: the image is drawn on a boardSVGSquare grid and
+// scaled to the size asked for, within minBoardSVGSize and maxBoardSVGSize
+const (
+	boardSVGSquare      = 45
+	defaultBoardSVGSize = 360
+	minBoardSVGSize     = 64
+	maxBoardSVGSize     = 2048
+)
+
+// Board image colors
+const (
+	svgLightSquare = "#F0D9B5"
+	svgDarkSquare  = "#B58863"
+)
+
+// svgPieceGlyphs are the pieces, drawn with the filled chess symbols for
+// both sides and colored by fill
+var svgPieceGlyphs = map[chess.PieceType]string{
+	chess.King:   "♚",
+	chess.Queen:  "♛",
+	chess.Rook:   "♜",
+	chess.Bishop: "♝",
+	chess.Knight: "♞",
+	chess.Pawn:   "♟",
+}
+
+// BoardSVG draws the FEN position as an SVG image size pixels a side, from
+// orientation's side of the board, with the files and ranks marked on the
+// edge squares
+func BoardSVG(fen string, orientation chess.Color, size int) (string, error) {
+	option, err := chess.FEN(fen)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse FEN: %w", err)
+	}
+	board := chess.NewGame(option).Position().Board()
+	view := 8 * boardSVGSquare
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", size, size, view, view)
+	fmt.Fprintf(&sb, "<title>%s</title>\n", html.EscapeString(fen))
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			file, rank := chess.File(col), chess.Rank(7-row)
+			if orientation == chess.Black {
+				file, rank = chess.File(7-col), chess.Rank(row)
+			}
+			x, y := col*boardSVGSquare, row*boardSVGSquare
+			light := (int(file)+int(rank))%2 == 1
+			fill, ink := svgDarkSquare, svgLightSquare
+			if light {
+				fill, ink = svgLightSquare, svgDarkSquare
+			}
+			fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", x, y, boardSVGSquare, boardSVGSquare, fill)
+
+			// Files along the bottom edge, ranks along the left
+			if row == 7 {
+				fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="9" font-family="sans-serif" fill="%s">%s</text>`+"\n", x+boardSVGSquare-8, y+boardSVGSquare-3, ink, file)
+			}
+			if col == 0 {
+				fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="9" font-family="sans-serif" fill="%s">%s</text>`+"\n", x+2, y+10, ink, rank)
+			}
+
+			piece := board.Piece(chess.NewSquare(file, rank))
+			if piece == chess.NoPiece {
+				continue
+			}
+			fill, stroke := "#FFFFFF", "#000000"
+			if piece.Color() == chess.Black {
+				fill, stroke = "#000000", "#FFFFFF"
+			}
+			fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="38" text-anchor="middle" dominant-baseline="central" font-family="DejaVu Sans, Segoe UI Symbol, Noto Sans Symbols 2, serif" fill="%s" stroke="%s" stroke-width="1">%s</text>`+"\n",
+				x+boardSVGSquare/2, y+boardSVGSquare/2+2, fill, stroke, svgPieceGlyphs[piece.Type()])
+		}
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String(), nil
+}
+
+// handleBoardSVG serves the current position of a game in progress as an
+// SVG image, for chat bots and dashboards to embed. The board is seen from
+// the AI's opponent's side unless ?orientation=white or black says
+// otherwise, and ?size sets its width and height in pixels.
+func handleBoardSVG(sessions *SessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session, ok := sessions.Get(r.PathValue("id"))
+		if !ok {
+			http.Error(w, "no such game", http.StatusNotFound)
+			return
+		}
+
+		orientation := chess.White
+		if session.PlayerColor == "white" {
+			orientation = chess.Black
+		}
+		switch r.URL.Query().Get("orientation") {
+		case "":
+		case "white":
+			orientation = chess.White
+		case "black":
+			orientation = chess.Black
+		default:
+			http.Error(w, "orientation must be white or black", http.StatusBadRequest)
+			return
+		}
+
+		size := defaultBoardSVGSize
+		if value := r.URL.Query().Get("size"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < minBoardSVGSize || n > maxBoardSVGSize {
+				http.Error(w, fmt.Sprintf("size must be a number of pixels from %d to %d", minBoardSVGSize, maxBoardSVGSize), http.StatusBadRequest)
+				return
+			}
+			size = n
+		}
+
+		svg, err := BoardSVG(session.FEN, orientation, size)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// The game moves on, so the image mustn't be cached
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Header().Set("Cache-Control", "no-cache")
+		fmt.Fprint(w, svg)
+	}
+}
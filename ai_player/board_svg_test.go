@@ -0,0 +1,80 @@
+package ai_player
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestBoardSVGDrawsPosition(t *testing.T) {
+	svg, err := BoardSVG(startFEN, chess.White, 200)
+	if err != nil {
+		t.Fatalf("Failed to draw board: %v", err)
+	}
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, `width="200"`) {
+		t.Errorf("Expected a 200 pixel SVG, got %q", svg[:80])
+	}
+	if n := strings.Count(svg, "<rect"); n != 64 {
+		t.Errorf("Expected 64 squares, got %d", n)
+	}
+	if n := strings.Count(svg, "♟"); n != 16 {
+		t.Errorf("Expected 16 pawns, got %d", n)
+	}
+
+	// From White's side the first rank is at the bottom, from Black's at the top
+	white, _ := BoardSVG(startFEN, chess.White, 200)
+	black, _ := BoardSVG(startFEN, chess.Black, 200)
+	if strings.Index(white, ">8</text>") > strings.Index(white, ">1</text>") {
+		t.Error("Expected rank 8 above rank 1 from White's side")
+	}
+	if strings.Index(black, ">1</text>") > strings.Index(black, ">8</text>") {
+		t.Error("Expected rank 1 above rank 8 from Black's side")
+	}
+
+	if _, err := BoardSVG("not a fen", chess.White, 200); err == nil {
+		t.Error("Expected an invalid FEN to fail")
+	}
+}
+
+func TestBoardSVGEndpoint(t *testing.T) {
+	sessions, _ := LoadSessionStore("")
+	sessions.Put("game_1", Session{FEN: startFEN, PlayerColor: "white"})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /games/{id}/board.svg", handleBoardSVG(sessions))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	for path, want := range map[string]int{
+		"/games/game_1/board.svg":                   http.StatusOK,
+		"/games/game_1/board.svg?orientation=white": http.StatusOK,
+		"/games/game_1/board.svg?size=10":           http.StatusBadRequest,
+		"/games/game_1/board.svg?orientation=up":    http.StatusBadRequest,
+		"/games/game_2/board.svg":                   http.StatusNotFound,
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Expected status %d for %s, got %d", want, path, resp.StatusCode)
+			continue
+		}
+		if want != http.StatusOK {
+			continue
+		}
+		if got := resp.Header.Get("Content-Type"); got != "image/svg+xml" {
+			t.Errorf("Expected an SVG for %s, got %s", path, got)
+		}
+		// The AI plays White, so the board is seen from Black's side unless asked
+		fromBlack := strings.Index(string(body), ">1</text>") < strings.Index(string(body), ">8</text>")
+		if fromBlack != !strings.Contains(path, "orientation=white") {
+			t.Errorf("Expected %s seen from the right side", path)
+		}
+	}
+}
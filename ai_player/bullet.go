@@ -0,0 +1,148 @@
+package ai_player
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+
+	"github.com/notnil/chess"
+)
+
+// BulletNumPredict caps the tokens of a move in bullet mode, enough for a
+// move in SAN and little else
+const BulletNumPredict = 16
+
+// bulletCacheSize is how many positions the bullet response cache remembers
+const bulletCacheSize = 512
+
+// Providers named on moves bullet mode plays without asking the model
+const (
+	ProviderBook  = "book"
+	ProviderCache = "cache"
+)
+
+// bookLines are the opening lines the bullet book plays from, in SAN
+var bookLines = []string{
+	"e4 e5 Nf3 Nc6 Bb5 a6 Ba4 Nf6 O-O Be7 Re1 b5 Bb3 d6",
+	"e4 e5 Nf3 Nc6 Bc4 Bc5 c3 Nf6 d4 exd4 cxd4 Bb4+",
+	"e4 e5 Nf3 Nf6 Nxe5 d6 Nf3 Nxe4 d4 d5",
+	"e4 c5 Nf3 d6 d4 cxd4 Nxd4 Nf6 Nc3 a6 Be3 e5",
+	"e4 c5 Nf3 Nc6 d4 cxd4 Nxd4 Nf6 Nc3 e5 Ndb5 d6",
+	"e4 c5 Nc3 Nc6 g3 g6 Bg2 Bg7 d3 d6",
+	"e4 e6 d4 d5 Nc3 Nf6 Bg5 Be7 e5 Nfd7",
+	"e4 c6 d4 d5 Nc3 dxe4 Nxe4 Bf5 Ng3 Bg6",
+	"e4 d5 exd5 Qxd5 Nc3 Qa5 d4 Nf6 Nf3 c6",
+	"d4 d5 c4 e6 Nc3 Nf6 Bg5 Be7 e3 O-O Nf3 h6",
+	"d4 d5 c4 c6 Nf3 Nf6 Nc3 dxc4 a4 Bf5",
+	"d4 d5 Nf3 Nf6 Bf4 e6 e3 c5 c3 Nc6",
+	"d4 Nf6 c4 e6 Nc3 Bb4 e3 O-O Bd3 d5 Nf3 c5",
+	"d4 Nf6 c4 g6 Nc3 Bg7 e4 d6 Nf3 O-O Be2 e5",
+	"d4 Nf6 Nf3 e6 c4 b6 g3 Bb7 Bg2 Be7",
+	"c4 e5 Nc3 Nf6 Nf3 Nc6 g3 d5 cxd5 Nxd5",
+	"Nf3 d5 g3 Nf6 Bg2 e6 O-O Be7 d3 O-O",
+}
+
+var (
+	openingBook     map[string][]string
+	openingBookOnce sync.Once
+)
+
+// bookKey identifies a position for the book and the response cache: the
+// FEN without its move counters
+func bookKey(fen string) string {
+	fields := strings.Fields(fen)
+	if len(fields) > 4 {
+		fields = fields[:4]
+	}
+	return strings.Join(fields, " ")
+}
+
+// loadOpeningBook replays the book lines into a map from position to the
+// moves played from it
+func loadOpeningBook() map[string][]string {
+	openingBookOnce.Do(func() {
+		openingBook = make(map[string][]string)
+		for _, line := range bookLines {
+			game := chess.NewGame(chess.UseNotation(chess.AlgebraicNotation{}))
+			for _, san := range strings.Fields(line) {
+				key := bookKey(game.Position().String())
+				if err := game.MoveStr(san); err != nil {
+					break
+				}
+				if !containsMove(openingBook[key], san) {
+					openingBook[key] = append(openingBook[key], san)
+				}
+			}
+		}
+	})
+	return openingBook
+}
+
+// bookMove picks one of the book's moves for the FEN position, if it has any
+func bookMove(fen string) (string, bool) {
+	moves := loadOpeningBook()[bookKey(fen)]
+	if len(moves) == 0 {
+		return "", false
+	}
+	return moves[rand.Intn(len(moves))], true
+}
+
+// responseCache remembers the moves a player chose by position, so bullet
+// mode answers a position it has seen before without asking the model
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]ChessMove
+	order   []string // keys, oldest first, for eviction
+}
+
+// get returns the move remembered for key
+func (c *responseCache) get(key string) (ChessMove, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	move, ok := c.entries[key]
+	return move, ok
+}
+
+// put remembers a move for key, dropping the oldest beyond bulletCacheSize
+func (c *responseCache) put(key string, move ChessMove) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]ChessMove)
+	}
+	if _, ok := c.entries[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = move
+	for len(c.order) > bulletCacheSize {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// responseKey identifies a move request for the response cache: the same
+// position, side, model and personality get the same move
+func (ai *AIPlayer) responseKey(fen string) string {
+	return strings.Join([]string{ai.Model, ai.Color, ai.Personality, bookKey(fen)}, "|")
+}
+
+// bulletMove answers from the opening book or the response cache, if
+// either knows the position
+func (ai *AIPlayer) bulletMove(boardState string) (*ChessMove, bool) {
+	if san, ok := bookMove(boardState); ok {
+		ai.Logger.Debug("📖 %sBook move: %s%s", ColorGreen, san, ColorReset)
+		return &ChessMove{Notation: san, Reasoning: "Book move", Provider: ProviderBook}, true
+	}
+	if cached, ok := ai.responses.get(ai.responseKey(boardState)); ok {
+		ai.Logger.Debug("♻️ %sCached move: %s%s", ColorGreen, cached.Notation, ColorReset)
+		cached.Provider = ProviderCache
+		cached.PromptTokens, cached.CompletionTokens = 0, 0
+		return &cached, true
+	}
+	return nil, false
+}
+
+// rememberMove keeps a move the model chose for the response cache
+func (ai *AIPlayer) rememberMove(boardState string, move *ChessMove) {
+	ai.responses.put(ai.responseKey(boardState), *move)
+}
@@ -0,0 +1,72 @@
+package ai_player
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestBookLinesAreLegal(t *testing.T) {
+	for _, line := range bookLines {
+		game := chess.NewGame(chess.UseNotation(chess.AlgebraicNotation{}))
+		for _, san := range strings.Fields(line) {
+			if err := game.MoveStr(san); err != nil {
+				t.Errorf("Expected %q to be legal in %q, got %v", san, line, err)
+				break
+			}
+		}
+	}
+}
+
+func TestBulletPlaysFromBookAndCache(t *testing.T) {
+	model := &fakeProvider{name: "model", reply: "e5"}
+	player := NewAIPlayer("", "m", "white", quietLogger())
+	player.Provider = model
+	player.Bullet = true
+
+	move, err := player.GetMove(startFEN, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Provider != ProviderBook || !containsMove([]string{"e4", "d4", "c4", "Nf3"}, move.Notation) {
+		t.Errorf("Expected a book move, got %s from %s", move.Notation, move.Provider)
+	}
+	if model.calls != 0 {
+		t.Errorf("Expected the model not to be asked, got %d calls", model.calls)
+	}
+
+	// Out of book the model is asked once per position
+	player.Color = "black"
+	afterA3 := "rnbqkbnr/pppppppp/8/8/8/P7/1PPPPPPP/RNBQKBNR b KQkq - 0 1"
+	for i := 0; i < 2; i++ {
+		if move, err = player.GetMove(afterA3, []string{"a3"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if model.calls != 1 {
+		t.Errorf("Expected one model call, got %d", model.calls)
+	}
+	if move.Notation != "e5" || move.Provider != ProviderCache {
+		t.Errorf("Expected the cached e5, got %s from %s", move.Notation, move.Provider)
+	}
+}
+
+func TestBulletOptions(t *testing.T) {
+	player := NewAIPlayer("", "m", "white", quietLogger())
+	if _, ok := player.moveOptions()["num_predict"]; ok {
+		t.Error("Expected no num_predict outside bullet mode")
+	}
+	player.Bullet = true
+	if got := player.moveOptions()["num_predict"]; got != BulletNumPredict {
+		t.Errorf("Expected num_predict %d, got %v", BulletNumPredict, got)
+	}
+
+	voters := []Config{{Model: "a"}, {Model: "b"}}
+	if got := bestOf(voters, true); len(got) != 1 || got[0].Model != "a" {
+		t.Errorf("Expected only the first voter, got %v", got)
+	}
+	if got := bestOf(voters, false); len(got) != 2 {
+		t.Errorf("Expected the whole panel, got %v", got)
+	}
+}
@@ -0,0 +1,52 @@
+package ai_player
+
+import (
+	"math"
+	"strings"
+)
+
+// TokenLogprob is one token of a reply with its log probability
+type TokenLogprob struct {
+	Token   string
+	Logprob float64
+}
+
+// moveConfidence returns the probability the model gave the tokens that
+// spell move in its reply, or nil when there are no log probabilities or
+// the move can't be found among them
+func moveConfidence(tokens []TokenLogprob, move string) *float64 {
+	if len(tokens) == 0 || move == "" {
+		return nil
+	}
+
+	var text strings.Builder
+	starts := make([]int, len(tokens))
+	for i, token := range tokens {
+		starts[i] = text.Len()
+		text.WriteString(token.Token)
+	}
+	begin := strings.Index(text.String(), move)
+	if begin < 0 {
+		return nil
+	}
+	end := begin + len(move)
+
+	sum := 0.0
+	for i, token := range tokens {
+		if starts[i] < end && starts[i]+len(token.Token) > begin {
+			sum += token.Logprob
+		}
+	}
+	confidence := math.Exp(sum)
+	return &confidence
+}
+
+// clampConfidence keeps a self-reported confidence between 0 and 1, or
+// returns nil for one that wasn't given
+func clampConfidence(reported *float64) *float64 {
+	if reported == nil {
+		return nil
+	}
+	confidence := min(max(*reported, 0), 1)
+	return &confidence
+}
@@ -0,0 +1,79 @@
+package ai_player
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMoveConfidence(t *testing.T) {
+	tokens := []TokenLogprob{
+		{Token: "MOVE", Logprob: -0.01},
+		{Token: ":", Logprob: 0},
+		{Token: " N", Logprob: math.Log(0.8)},
+		{Token: "f3", Logprob: math.Log(0.5)},
+	}
+	confidence := moveConfidence(tokens, "Nf3")
+	if confidence == nil || math.Abs(*confidence-0.4) > 1e-9 {
+		t.Errorf("Expected 0.4 from the move's two tokens, got %v", confidence)
+	}
+	if moveConfidence(tokens, "e4") != nil || moveConfidence(nil, "Nf3") != nil {
+		t.Error("Expected no confidence without the move's tokens")
+	}
+}
+
+func TestOpenAILogprobsConfidence(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"logprobs":true`) {
+			t.Errorf("Expected logprobs requested, got %s", body)
+		}
+		w.Write([]byte(`{"model":"m","choices":[{"message":{"content":"e4"},"logprobs":{"content":[{"token":"e","logprob":-0.1},{"token":"4","logprob":-0.2}]}}]}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.Provider = ProviderOpenAI
+	config.APIBaseURL = server.URL
+	config.Logprobs = true
+	player, err := NewAIPlayerFromConfig(config, "white", nil)
+	if err != nil {
+		t.Fatalf("Expected a player, got %v", err)
+	}
+	move, err := player.GetMove(startFEN, nil)
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if move.Confidence == nil || math.Abs(*move.Confidence-math.Exp(-0.3)) > 1e-9 {
+		t.Errorf("Expected the move's token probability, got %v", move.Confidence)
+	}
+}
+
+func TestVoteMarginConfidence(t *testing.T) {
+	player := NewAIPlayer("", "m", "white", nil)
+	panel := NewVoteProvider([]Provider{
+		&fakeProvider{name: "a", reply: "e4"},
+		&fakeProvider{name: "b", reply: "e4"},
+		&fakeProvider{name: "c", reply: "d4"},
+	}, nil, []float64{1, 2, 1}, nil, player.Logger)
+	panel.Parse = player.parseMove
+	player.Provider = panel
+
+	move, err := player.GetMove(startFEN, nil)
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if move.Confidence == nil || *move.Confidence != 0.75 {
+		t.Errorf("Expected 3 of 4 weight behind e4, got %v", move.Confidence)
+	}
+}
+
+func TestClampConfidence(t *testing.T) {
+	high, low := 1.7, -0.2
+	if *clampConfidence(&high) != 1 || *clampConfidence(&low) != 0 || clampConfidence(nil) != nil {
+		t.Error("Expected reported confidence kept between 0 and 1")
+	}
+}
@@ -3,12 +3,25 @@ package ai_player
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"chess-tui/plugins"
 )
 
 // Config holds the configuration for the AI player
 type Config struct {
+	// Version is the file's schema version, ConfigVersion when saved;
+	// older files are migrated when loaded
+	Version int `json:"version,omitempty"`
+
+	Provider      string            `json:"provider,omitempty"`
+	APIBaseURL    string            `json:"api_base_url,omitempty"`
+	APIKey        string            `json:"api_key,omitempty"`
+	ModelPath     string            `json:"model_path,omitempty"`
+	ScriptPath    string            `json:"script_path,omitempty"`
 	OllamaURL     string            `json:"ollama_url"`
 	Model         string            `json:"model"`
 	Timeout       int               `json:"timeout_seconds"`
@@ -18,11 +31,122 @@ type Config struct {
 	RetryDelay    int               `json:"retry_delay_seconds"`
 	MoveHistory   int               `json:"move_history_length"`
 	CustomPrompts map[string]string `json:"custom_prompts,omitempty"`
+
+	// Logprobs asks the openai provider for token probabilities, which
+	// give each move a confidence; leave it off for models that reject it
+	Logprobs bool `json:"logprobs,omitempty"`
+
+	// SessionsFile is where the A2A server saves game sessions so they
+	// survive restarts; empty means ~/.bubblechess/sessions.json
+	SessionsFile string `json:"sessions_file,omitempty"`
+
+	// AdminToken enables the A2A server's /admin endpoint for requests that
+	// send it as a bearer token
+	AdminToken string `json:"admin_token,omitempty"`
+
+	// TraceDir, when set, receives a file per backend call with the exact
+	// request and raw response; TraceRedact lists regular expressions to
+	// blank out of those files
+	TraceDir    string   `json:"trace_dir,omitempty"`
+	TraceRedact []string `json:"trace_redact,omitempty"`
+
+	// Think and MaxThinkingTokens control the thinking traces of reasoning
+	// models; ModelOptions overrides them per model (see ModelOptions)
+	Think             *bool                   `json:"think,omitempty"`
+	MaxThinkingTokens int                     `json:"max_thinking_tokens,omitempty"`
+	ModelOptions      map[string]ModelOptions `json:"model_options,omitempty"`
+
+	// OllamaHosts, when set, lists several Ollama servers; match games are
+	// spread across them instead of all going to OllamaURL
+	OllamaHosts []string `json:"ollama_hosts,omitempty"`
+
+	// Providers, when set, is an ordered failover chain used instead of
+	// Provider; each entry needs only its provider fields and timeout
+	Providers []Config `json:"providers,omitempty"`
+
+	// Voters, when set, is a panel of providers that each propose a move,
+	// with the move with the most Weight behind it played; it takes
+	// precedence over Provider and Providers. Weight only applies to
+	// entries of Voters, 0 counting as 1.
+	Voters []Config `json:"voters,omitempty"`
+	Weight float64  `json:"weight,omitempty"`
+
+	// Repertoire, when set, pins the AI to opening lines, played without
+	// asking the model until the game leaves them
+	Repertoire *Repertoire `json:"repertoire,omitempty"`
+
+	// Spare, when set, is a small fast model kept loaded to answer the
+	// moves the primary takes longer than timeout_seconds over; it needs
+	// only its provider fields and model
+	Spare *Config `json:"spare,omitempty"`
+
+	// PromptTokenBudget caps the move prompts of the openai and anthropic
+	// providers, which see the whole game; once it grows past the budget,
+	// the early moves are summarized. 0 means DefaultPromptTokenBudget.
+	PromptTokenBudget int `json:"prompt_token_budget,omitempty"`
+
+	// ResignThreshold is how many centipawns of material behind the AI
+	// resigns, and DrawMargin how close to level it offers and accepts
+	// draws, for clients that handle those actions. 0 means
+	// DefaultResignThreshold and DefaultDrawMargin; a negative value
+	// turns the decision off.
+	ResignThreshold int `json:"resign_threshold,omitempty"`
+	DrawMargin      int `json:"draw_margin,omitempty"`
+
+	// ReviewerURL, when set, is the A2A endpoint of a second agent the
+	// server asks to review each move before playing it; MaxVetoes is how
+	// many times the reviewer may send a move back, 0 for the default of 2
+	ReviewerURL string `json:"reviewer_url,omitempty"`
+	MaxVetoes   int    `json:"max_vetoes,omitempty"`
+
+	// Bullet turns on every latency optimization at once: book moves in
+	// the opening, remembered moves in positions seen before, thinking off,
+	// a BulletNumPredict token answer, only the first of the Voters and no
+	// reviewer
+	Bullet bool `json:"bullet,omitempty"`
+
+	// BlunderRate is the chance, from 0 to 1, that the AI plays a random
+	// legal move instead of the one it chose, to make it easier to beat.
+	// EngineDepth is how many plies the built-in engine looks ahead, 1 or
+	// 2; 0 means 2.
+	BlunderRate float64 `json:"blunder_rate,omitempty"`
+	EngineDepth int     `json:"engine_depth,omitempty"`
+}
+
+// ModelOptions tunes how one model is asked for moves
+type ModelOptions struct {
+	// Think turns the model's thinking on or off, for models Ollama supports
+	// it for; unset leaves the model's default
+	Think *bool `json:"think,omitempty"`
+
+	// MaxThinkingTokens cuts a thinking trace short after this many tokens
+	// and asks again with thinking off; 0 means no cap
+	MaxThinkingTokens int `json:"max_thinking_tokens,omitempty"`
+}
+
+// OptionsFor returns the thinking options for model: the entry in
+// ModelOptions for the full name ("qwen3:8b") or else its family ("qwen3"),
+// with unset fields taken from the config's own Think and MaxThinkingTokens
+func (c *Config) OptionsFor(model string) ModelOptions {
+	options, ok := c.ModelOptions[model]
+	if !ok {
+		family, _, _ := strings.Cut(model, ":")
+		options = c.ModelOptions[family]
+	}
+	if options.Think == nil {
+		options.Think = c.Think
+	}
+	if options.MaxThinkingTokens == 0 {
+		options.MaxThinkingTokens = c.MaxThinkingTokens
+	}
+	return options
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
+		Version:       ConfigVersion,
+		Provider:      ProviderOllama,
 		OllamaURL:     "http://localhost:11434",
 		Model:         "llama3.2:3b",
 		Timeout:       30,
@@ -35,7 +159,10 @@ func DefaultConfig() *Config {
 	}
 }
 
-// LoadConfig loads configuration from a file
+// LoadConfig loads configuration from a file, creating it with the
+// defaults if it doesn't exist. A file from an older version that needs
+// migrating is saved back migrated, the original kept alongside as
+// <file>.v<version>.bak.
 func LoadConfig(configPath string) (*Config, error) {
 	if configPath == "" {
 		configPath = "ai_config.json"
@@ -52,21 +179,30 @@ func LoadConfig(configPath string) (*Config, error) {
 	}
 
 	// Load existing config
-	file, err := os.Open(configPath)
+	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open config file: %w", err)
 	}
-	defer file.Close()
-
-	config := DefaultConfig()
-	if err := json.NewDecoder(file).Decode(config); err != nil {
-		return nil, fmt.Errorf("failed to decode config file: %w", err)
+	config, version, err := decodeConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	if version < ConfigVersion {
+		backup := fmt.Sprintf("%s.v%d.bak", configPath, version)
+		if err := writeFileAtomic(backup, data, 0600); err != nil {
+			return nil, fmt.Errorf("failed to back up config before migrating it: %w", err)
+		}
+		if err := SaveConfig(config, configPath); err != nil {
+			return nil, fmt.Errorf("failed to save migrated config: %w", err)
+		}
+		slog.Info("Migrated config file", "path", configPath, "from", version, "to", ConfigVersion, "backup", backup)
 	}
 
 	return config, nil
 }
 
-// SaveConfig saves configuration to a file
+// SaveConfig saves configuration to a file at the current ConfigVersion,
+// replacing it atomically so a crash can't leave it half-written
 func SaveConfig(config *Config, configPath string) error {
 	if configPath == "" {
 		configPath = "ai_config.json"
@@ -78,25 +214,49 @@ func SaveConfig(config *Config, configPath string) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	file, err := os.Create(configPath)
+	versioned := *config
+	versioned.Version = ConfigVersion
+	data, err := json.MarshalIndent(&versioned, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to create config file: %w", err)
-	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(config); err != nil {
 		return fmt.Errorf("failed to encode config: %w", err)
 	}
+	if err := writeFileAtomic(configPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
 
 	return nil
 }
 
 // ValidateConfig validates the configuration
 func (c *Config) ValidateConfig() error {
-	if c.OllamaURL == "" {
-		return fmt.Errorf("ollama_url cannot be empty")
+	if err := c.validateProvider(); err != nil {
+		return err
+	}
+	for i := range c.Providers {
+		if err := c.Providers[i].validateProvider(); err != nil {
+			return fmt.Errorf("providers[%d]: %w", i, err)
+		}
+	}
+	if c.Repertoire != nil {
+		if err := c.Repertoire.validate(); err != nil {
+			return fmt.Errorf("repertoire: %w", err)
+		}
+	}
+	if c.Spare != nil {
+		if err := c.Spare.validateProvider(); err != nil {
+			return fmt.Errorf("spare: %w", err)
+		}
+		if c.Spare.Spare != nil {
+			return fmt.Errorf("spare: a spare cannot have a spare")
+		}
+	}
+	for i := range c.Voters {
+		if err := c.Voters[i].validateProvider(); err != nil {
+			return fmt.Errorf("voters[%d]: %w", i, err)
+		}
+		if c.Voters[i].Weight < 0 {
+			return fmt.Errorf("voters[%d]: weight cannot be negative", i)
+		}
 	}
 
 	if c.Model == "" {
@@ -127,5 +287,52 @@ func (c *Config) ValidateConfig() error {
 		return fmt.Errorf("move_history_length cannot be negative")
 	}
 
+	if c.PromptTokenBudget < 0 {
+		return fmt.Errorf("prompt_token_budget cannot be negative")
+	}
+
+	if c.MaxVetoes < 0 {
+		return fmt.Errorf("max_vetoes cannot be negative")
+	}
+
+	if c.BlunderRate < 0 || c.BlunderRate > 1 {
+		return fmt.Errorf("blunder_rate must be between 0 and 1")
+	}
+
+	if c.EngineDepth < 0 || c.EngineDepth > 2 {
+		return fmt.Errorf("engine_depth must be 1 or 2")
+	}
+
+	return nil
+}
+
+// validateProvider checks the settings the selected provider needs
+func (c *Config) validateProvider() error {
+	switch c.Provider {
+	case "", ProviderOllama:
+		if c.OllamaURL == "" {
+			return fmt.Errorf("ollama_url cannot be empty")
+		}
+	case ProviderOpenAI:
+		if c.APIBaseURL == "" {
+			return fmt.Errorf("api_base_url cannot be empty for the openai provider")
+		}
+	case ProviderAnthropic:
+		// api_base_url defaults to the public API
+	case ProviderGGUF:
+		if c.ModelPath == "" {
+			return fmt.Errorf("model_path cannot be empty for the gguf provider")
+		}
+	case ProviderEngine:
+		// The built-in engine needs no settings
+	case ProviderScript:
+		if c.ScriptPath == "" {
+			return fmt.Errorf("script_path cannot be empty for the script provider")
+		}
+	default:
+		if _, ok := plugins.LookupMoveProvider(c.Provider); !ok {
+			return fmt.Errorf("unknown provider: %s", c.Provider)
+		}
+	}
 	return nil
 }
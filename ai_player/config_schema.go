@@ -0,0 +1,132 @@
+package ai_player
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ConfigVersion is the schema version of the config files SaveConfig
+// writes. Files without a version are version 0.
+const ConfigVersion = 1
+
+// configMigrations upgrade a config file's fields from the version they are
+// keyed by to the next one, e.g. by renaming a field. Configs nested in
+// providers, voters and spare share the file's version, so a migration
+// renaming one of their fields has to rename it there too.
+var configMigrations = map[int]func(fields map[string]json.RawMessage) error{}
+
+// decodeConfig reads a config file's contents over the defaults, first
+// migrating them to ConfigVersion. It returns the version a migration
+// changed them from, or ConfigVersion if none had to.
+func decodeConfig(data []byte) (*Config, int, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode config file: %w", err)
+	}
+
+	version := 0
+	if raw, ok := fields["version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode config file: invalid version: %w", err)
+		}
+	}
+	if version > ConfigVersion {
+		return nil, version, fmt.Errorf("config file is version %d, newer than this program's %d; upgrade to use it", version, ConfigVersion)
+	}
+	migrated := false
+	for from := version; from < ConfigVersion; from++ {
+		if migrate := configMigrations[from]; migrate != nil {
+			if err := migrate(fields); err != nil {
+				return nil, version, fmt.Errorf("failed to migrate config from version %d: %w", from, err)
+			}
+			migrated = true
+		}
+	}
+	if !migrated {
+		version = ConfigVersion
+	}
+	if unknown := unknownConfigFields(fields); len(unknown) > 0 {
+		slog.Warn("Ignoring unknown config fields", "fields", strings.Join(unknown, ", "))
+	}
+
+	fields["version"] = json.RawMessage(fmt.Sprint(ConfigVersion))
+	current, err := json.Marshal(fields)
+	if err != nil {
+		return nil, version, fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+	config := DefaultConfig()
+	if err := json.Unmarshal(current, config); err != nil {
+		return nil, version, fmt.Errorf("failed to decode config file: %w", err)
+	}
+	return config, version, nil
+}
+
+// renameConfigField moves a field to a new name, leaving one already set
+// under the new name alone. Migrations use it.
+func renameConfigField(fields map[string]json.RawMessage, from, to string) {
+	value, ok := fields[from]
+	if !ok {
+		return
+	}
+	delete(fields, from)
+	if _, taken := fields[to]; !taken {
+		fields[to] = value
+	}
+}
+
+// unknownConfigFields lists the fields Config has no place for, which
+// would be lost when the config is saved
+func unknownConfigFields(fields map[string]json.RawMessage) []string {
+	known := map[string]bool{}
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		name, _, _ := strings.Cut(configType.Field(i).Tag.Get("json"), ",")
+		known[name] = true
+	}
+	var unknown []string
+	for name := range fields {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// writeFileAtomic replaces the file at path with data through a temporary
+// file in the same directory, so a crash mid-write leaves either the old
+// file or the new one. An existing file keeps its permissions, such as
+// 0600 for a config holding an API key.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,97 @@
+package ai_player
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveConfigIsAtomicAndVersioned(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ai_config.json")
+	if err := os.WriteFile(path, []byte(`{"model": "old"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultConfig()
+	config.Version = 0
+	config.Model = "new"
+	if err := SaveConfig(config, path); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"version": 1`) || !strings.Contains(string(data), `"model": "new"`) {
+		t.Errorf("Expected the new config at version 1, got %s", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the file to stay private, got %v", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected no temporary files left behind, got %v", entries)
+	}
+	if config.Version != 0 {
+		t.Error("Expected the caller's config to be left alone")
+	}
+}
+
+func TestLoadConfigMigrates(t *testing.T) {
+	configMigrations[0] = func(fields map[string]json.RawMessage) error {
+		renameConfigField(fields, "timeout", "timeout_seconds")
+		return nil
+	}
+	defer delete(configMigrations, 0)
+
+	path := filepath.Join(t.TempDir(), "ai_config.json")
+	original := `{"model": "llama3.2:3b", "timeout": 7}`
+	os.WriteFile(path, []byte(original), 0644)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Timeout != 7 || config.Version != ConfigVersion {
+		t.Errorf("Expected the renamed timeout at the current version, got %d at %d", config.Timeout, config.Version)
+	}
+	saved, _ := os.ReadFile(path)
+	if !strings.Contains(string(saved), `"timeout_seconds": 7`) {
+		t.Errorf("Expected the migrated config saved back, got %s", saved)
+	}
+	if backup, err := os.ReadFile(path + ".v0.bak"); err != nil || string(backup) != original {
+		t.Errorf("Expected the original kept as a backup, got %q (%v)", backup, err)
+	}
+
+	// Loading it again finds nothing to migrate
+	if again, err := LoadConfig(path); err != nil || again.Timeout != 7 {
+		t.Errorf("Expected the migrated config to load as is, got %+v (%v)", again, err)
+	}
+}
+
+func TestLoadConfigLeavesCurrentFilesAlone(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ai_config.json")
+	os.WriteFile(path, []byte(`{"model": "m", "temprature": 0.5}`), 0644)
+
+	if _, err := LoadConfig(path); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected no rewrite without a migration, got %v", entries)
+	}
+
+	os.WriteFile(path, []byte(`{"version": 99, "model": "m"}`), 0644)
+	if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Expected a config from a newer version to be refused, got %v", err)
+	}
+	if _, err := ReadConfig(path); err == nil {
+		t.Error("Expected hot reload to refuse it too")
+	}
+}
+
+func TestUnknownConfigFields(t *testing.T) {
+	fields := map[string]json.RawMessage{"model": nil, "temprature": nil, "version": nil, "voters": nil, "zzz": nil}
+	if got := strings.Join(unknownConfigFields(fields), " "); got != "temprature zzz" {
+		t.Errorf("Expected the misspelt fields, got %s", got)
+	}
+}
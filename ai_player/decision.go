@@ -0,0 +1,95 @@
+package ai_player
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/notnil/chess"
+)
+
+// Actions the AI can take instead of, or along with, a move. Clients list
+// those they handle in ChessRequest.Actions; others only ever get moves.
+const (
+	ActionMove       = "move"
+	ActionResign     = "resign"
+	ActionOfferDraw  = "offer_draw" // a move, with a draw offered alongside it
+	ActionAcceptDraw = "accept_draw"
+)
+
+// Defaults for the decision thresholds, in centipawns of material
+const (
+	DefaultResignThreshold = 1500
+	DefaultDrawMargin      = 100
+)
+
+// drawOfferQuietMoves is how many full moves without a capture or pawn move
+// pass before the AI offers a draw in a level position, and between offers
+const drawOfferQuietMoves = 20
+
+// Decision is what the AI does in a position before choosing a move
+type Decision struct {
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+	Eval   int    `json:"eval"` // the AI's material balance in centipawns
+}
+
+// Decide chooses between playing on, resigning and drawing by the material
+// balance in the position given as FEN, with the AI to move. allowed lists
+// the actions the client handles; drawOffered says the opponent has offered
+// a draw. Anything unclear, such as an invalid FEN, plays on.
+func (ai *AIPlayer) Decide(fen string, allowed []string, drawOffered bool) Decision {
+	fenOption, err := chess.FEN(fen)
+	if err != nil {
+		return Decision{Action: ActionMove}
+	}
+	position := chess.NewGame(fenOption).Position()
+	moves := position.ValidMoves()
+	eval := MaterialBalance(position, position.Turn())
+	decision := Decision{Action: ActionMove, Eval: eval}
+	if len(moves) == 0 {
+		return decision
+	}
+
+	resign := ai.ResignThreshold
+	if resign == 0 {
+		resign = DefaultResignThreshold
+	}
+	margin := ai.DrawMargin
+	if margin == 0 {
+		margin = DefaultDrawMargin
+	}
+
+	switch {
+	case resign > 0 && eval <= -resign && slices.Contains(allowed, ActionResign) && !hasMate(position, moves):
+		decision.Action = ActionResign
+		decision.Reason = fmt.Sprintf("down %d centipawns of material", -eval)
+	case drawOffered && margin > 0 && eval <= margin && slices.Contains(allowed, ActionAcceptDraw):
+		decision.Action = ActionAcceptDraw
+		decision.Reason = "no winning chances"
+		if eval < -margin {
+			decision.Reason = "behind on material"
+		}
+	case !drawOffered && margin > 0 && eval >= -margin && eval <= margin && quietFor(position, drawOfferQuietMoves) &&
+		slices.Contains(allowed, ActionOfferDraw):
+		decision.Action = ActionOfferDraw
+		decision.Reason = fmt.Sprintf("level material and no progress in %d moves", drawOfferQuietMoves)
+	}
+	return decision
+}
+
+// hasMate reports whether one of moves mates
+func hasMate(position *chess.Position, moves []*chess.Move) bool {
+	for _, move := range moves {
+		if position.Update(move).Status() == chess.Checkmate {
+			return true
+		}
+	}
+	return false
+}
+
+// quietFor reports whether the position has just reached another n full
+// moves without a capture or pawn move, so a draw is offered once per stretch
+func quietFor(position *chess.Position, n int) bool {
+	clock := position.HalfMoveClock()
+	return clock >= 2*n && clock%(2*n) < 2
+}
@@ -0,0 +1,42 @@
+package ai_player
+
+import "testing"
+
+func TestDecide(t *testing.T) {
+	all := []string{ActionResign, ActionOfferDraw, ActionAcceptDraw}
+	start := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	hopeless := "k7/8/8/8/8/8/PP6/KQR5 b - - 0 40"
+	tests := []struct {
+		name        string
+		fen         string
+		allowed     []string
+		drawOffered bool
+		threshold   int
+		want        string
+	}{
+		{"hopeless", hopeless, all, false, 0, ActionResign},
+		{"hopeless, client can't resign", hopeless, []string{ActionAcceptDraw}, false, 0, ActionMove},
+		{"hopeless, resigning off", hopeless, all, false, -1, ActionMove},
+		{"hopeless but higher threshold", hopeless, all, false, 2000, ActionMove},
+		{"level and offered", start, all, true, 0, ActionAcceptDraw},
+		{"queen up and offered", "k7/8/8/8/8/8/8/KQ6 w - - 0 40", all, true, 0, ActionMove},
+		{"quiet level endgame", "k7/p7/8/8/8/8/P7/K7 w - - 40 60", all, false, 0, ActionOfferDraw},
+		{"offered already this stretch", "k7/p7/8/8/8/8/P7/K7 w - - 42 61", all, false, 0, ActionMove},
+		{"start position", start, all, false, 0, ActionMove},
+		{"invalid FEN", "not a fen", all, true, 0, ActionMove},
+	}
+	for _, tt := range tests {
+		ai := &AIPlayer{ResignThreshold: tt.threshold}
+		if got := ai.Decide(tt.fen, tt.allowed, tt.drawOffered); got.Action != tt.want {
+			t.Errorf("%s: expected %s, got %+v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestDecideKeepsPlayingWithMate(t *testing.T) {
+	// Black is two queens down but mates on the back rank
+	fen := "4r2k/QQ6/8/8/8/8/5PPP/6K1 b - - 0 30"
+	if got := (&AIPlayer{}).Decide(fen, []string{ActionResign}, false); got.Action != ActionMove {
+		t.Errorf("Expected to play the mate, got %+v", got)
+	}
+}
@@ -0,0 +1,90 @@
+package ai_player
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/notnil/chess"
+
+	"chess-tui/errkind"
+	"chess-tui/notation"
+)
+
+// ErrCodeDesync is the JSON-RPC error code returned when the client's board
+// doesn't match the position replayed from its game history
+const ErrCodeDesync = errkind.CodeDesync
+
+// DesyncError describes where the client's board and its history disagree.
+// It is sent as the data of an ErrCodeDesync error so the client can resync.
+type DesyncError struct {
+	ClientFEN string `json:"client_fen"`
+	ServerFEN string `json:"server_fen"`
+	Ply       int    `json:"ply"`            // plies replayed before the mismatch
+	Move      string `json:"move,omitempty"` // history move that failed to replay, if any
+}
+
+// Error describes the desync
+func (d *DesyncError) Error() string {
+	if d.Move != "" {
+		return fmt.Sprintf("board desync: history move %d (%s) is illegal in %s", d.Ply+1, d.Move, d.ServerFEN)
+	}
+	return fmt.Sprintf("board desync after %d plies: client has %s, history gives %s", d.Ply, d.ClientFEN, d.ServerFEN)
+}
+
+// Is makes the desync of kind errkind.Desync
+func (d *DesyncError) Is(target error) bool {
+	return target == errkind.Desync
+}
+
+// checkBoardState replays the request's history from its start position and
+// compares the result with the client's FEN. It returns nil when they agree.
+func checkBoardState(req ChessRequest) *DesyncError {
+	game, desync := replayHistory(req.StartFEN, req.GameHistory, req.FEN)
+	if desync != nil {
+		return desync
+	}
+
+	serverFEN := game.Position().String()
+	if !samePosition(serverFEN, req.FEN) {
+		return &DesyncError{ClientFEN: req.FEN, ServerFEN: serverFEN, Ply: len(req.GameHistory)}
+	}
+	return nil
+}
+
+// replayHistory plays a move list from startFEN, or the standard position
+// when it is empty. clientFEN is only reported in the desync error returned
+// when the start position or a move is invalid.
+func replayHistory(startFEN string, history []string, clientFEN string) (*chess.Game, *DesyncError) {
+	game := chess.NewGame()
+	if startFEN != "" {
+		fenOption, err := chess.FEN(startFEN)
+		if err != nil {
+			return nil, &DesyncError{ClientFEN: clientFEN, ServerFEN: startFEN}
+		}
+		game = chess.NewGame(fenOption)
+	}
+
+	for ply, text := range history {
+		move, err := notation.Decode(game.Position(), text)
+		if err != nil {
+			return nil, &DesyncError{ClientFEN: clientFEN, ServerFEN: game.Position().String(), Ply: ply, Move: text}
+		}
+		game.Move(move)
+	}
+	return game, nil
+}
+
+// samePosition compares two FENs on placement, side to move, castling rights
+// and en passant square, ignoring the move counters
+func samePosition(a, b string) bool {
+	fieldsA, fieldsB := strings.Fields(a), strings.Fields(b)
+	if len(fieldsA) < 4 || len(fieldsB) < 4 {
+		return false
+	}
+	for i := 0; i < 4; i++ {
+		if fieldsA[i] != fieldsB[i] {
+			return false
+		}
+	}
+	return true
+}
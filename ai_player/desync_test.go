@@ -0,0 +1,74 @@
+package ai_player
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckBoardState(t *testing.T) {
+	const afterE4E5 = "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2"
+
+	tests := []struct {
+		name    string
+		req     ChessRequest
+		desync  bool
+		ply     int
+		badMove string
+	}{
+		{"consistent", ChessRequest{FEN: afterE4E5, GameHistory: []string{"e4", "e7e5"}}, false, 0, ""},
+		{"stale board", ChessRequest{FEN: startFEN, GameHistory: []string{"e4", "e5"}}, true, 2, ""},
+		{"missing move", ChessRequest{FEN: afterE4E5, GameHistory: []string{"e4"}}, true, 1, ""},
+		{"illegal history", ChessRequest{FEN: afterE4E5, GameHistory: []string{"e4", "e4"}}, true, 1, "e4"},
+		{"custom start", ChessRequest{
+			FEN:         "4k3/8/8/8/8/8/4K3/8 b - - 1 1",
+			StartFEN:    "4k3/8/8/8/8/8/8/4K3 w - - 0 1",
+			GameHistory: []string{"Ke2"},
+		}, false, 0, ""},
+	}
+
+	for _, tt := range tests {
+		desync := checkBoardState(tt.req)
+		if !tt.desync {
+			if desync != nil {
+				t.Errorf("%s: expected no desync, got %v", tt.name, desync)
+			}
+			continue
+		}
+		if desync == nil {
+			t.Errorf("%s: expected a desync", tt.name)
+			continue
+		}
+		if desync.Ply != tt.ply || desync.Move != tt.badMove {
+			t.Errorf("%s: expected ply %d move %q, got %+v", tt.name, tt.ply, tt.badMove, desync)
+		}
+	}
+}
+
+func TestServerRejectsDesyncedRequest(t *testing.T) {
+	server := httptest.NewServer(handleJSONRPCEndpoint(nil, nil, quietLogger()))
+	defer server.Close()
+
+	reply := postChessRequest(t, server.URL, "", ChessRequest{
+		BoardState:  startFEN,
+		FEN:         startFEN,
+		PlayerColor: "black",
+		GameHistory: []string{"e4"},
+	})
+
+	var response struct {
+		Error struct {
+			Code int         `json:"code"`
+			Data DesyncError `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(reply, &response); err != nil {
+		t.Fatalf("Failed to decode reply: %v", err)
+	}
+	if response.Error.Code != ErrCodeDesync {
+		t.Fatalf("Expected error code %d, got %d", ErrCodeDesync, response.Error.Code)
+	}
+	if response.Error.Data.ClientFEN != startFEN || response.Error.Data.Ply != 1 {
+		t.Errorf("Unexpected desync data: %+v", response.Error.Data)
+	}
+}
@@ -0,0 +1,154 @@
+package ai_player
+
+import (
+	"context"
+	"fmt"
+
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// ProviderEngine is the built-in engine, which needs no model at all
+const ProviderEngine = "engine"
+
+// engineMateScore outranks any material balance
+const engineMateScore = 100000
+
+// pieceValues are the material values the built-in engine plays by
+var pieceValues = map[chess.PieceType]int{
+	chess.Pawn:   100,
+	chess.Knight: 320,
+	chess.Bishop: 330,
+	chess.Rook:   500,
+	chess.Queen:  900,
+}
+
+// EngineProvider is a small built-in engine. It plays mates in one, wins
+// material and avoids hanging pieces, which makes it a dependable last
+// resort when every model backend is down.
+type EngineProvider struct {
+	// Depth is how many plies the engine looks ahead: at 1 it grabs
+	// material without checking the reply, at 2 (or 0) it also avoids
+	// hanging pieces
+	Depth int
+}
+
+// NewEngineProvider creates the built-in engine
+func NewEngineProvider() *EngineProvider {
+	return &EngineProvider{}
+}
+
+// Name identifies the provider
+func (e *EngineProvider) Name() string {
+	return ProviderEngine
+}
+
+// Generate is unsupported; the engine only selects moves
+func (e *EngineProvider) Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error) {
+	return nil, fmt.Errorf("the built-in engine does not generate text")
+}
+
+// TestConnection always succeeds
+func (e *EngineProvider) TestConnection() error {
+	return nil
+}
+
+// SelectMove picks the move with the best material outcome after the
+// opponent's best capture in reply, from request.LegalMoves when given
+func (e *EngineProvider) SelectMove(ctx context.Context, request MoveRequest) (*ChessMove, error) {
+	if request.Variant != "" {
+		return nil, fmt.Errorf("the built-in engine only plays standard chess, not %s", request.Variant)
+	}
+	fenOption, err := chess.FEN(request.FEN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FEN: %w", err)
+	}
+	position := chess.NewGame(fenOption).Position()
+
+	moves := position.ValidMoves()
+	if len(request.LegalMoves) > 0 {
+		allowed := make(map[string]bool, len(request.LegalMoves))
+		for _, san := range request.LegalMoves {
+			allowed[san] = true
+		}
+		var kept []*chess.Move
+		for _, move := range moves {
+			if allowed[notation.Encode(position, move)] {
+				kept = append(kept, move)
+			}
+		}
+		moves = kept
+	}
+	if len(moves) == 0 {
+		return nil, fmt.Errorf("no legal moves")
+	}
+
+	best, bestScore := moves[0], -engineMateScore*2
+	for _, move := range moves {
+		if score := scoreMove(position, move, e.Depth != 1); score > bestScore {
+			best, bestScore = move, score
+		}
+	}
+
+	return &ChessMove{
+		Notation: notation.Encode(position, best),
+	}, nil
+}
+
+// scoreMove evaluates move for the side to move in position, allowing for
+// the opponent's best capture in reply when reply is set
+func scoreMove(position *chess.Position, move *chess.Move, reply bool) int {
+	me := position.Turn()
+	next := position.Update(move)
+
+	switch next.Status() {
+	case chess.Checkmate:
+		return engineMateScore
+	case chess.Stalemate:
+		return 0
+	}
+
+	// Assume the opponent takes the most valuable piece it can
+	bestReply := 0
+	if reply {
+		for _, answer := range next.ValidMoves() {
+			if !answer.HasTag(chess.Capture) {
+				continue
+			}
+			if value := pieceValues[next.Board().Piece(answer.S2()).Type()]; value > bestReply {
+				bestReply = value
+			}
+		}
+	}
+
+	score := material(next.Board(), me) - material(next.Board(), me.Other()) - bestReply
+	if move.HasTag(chess.Check) {
+		score += 10
+	}
+	return score
+}
+
+// ScoreMove rates move for the side to move in position as the built-in
+// engine does: the material balance after the opponent's best capture in
+// reply, in centipawns, or a mate's score
+func ScoreMove(position *chess.Position, move *chess.Move) int {
+	return scoreMove(position, move, true)
+}
+
+// MaterialBalance returns color's material advantage in centipawns
+func MaterialBalance(position *chess.Position, color chess.Color) int {
+	board := position.Board()
+	return material(board, color) - material(board, color.Other())
+}
+
+// material totals the piece values of color on board
+func material(board *chess.Board, color chess.Color) int {
+	total := 0
+	for _, piece := range board.SquareMap() {
+		if piece.Color() == color {
+			total += pieceValues[piece.Type()]
+		}
+	}
+	return total
+}
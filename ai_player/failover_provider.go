@@ -0,0 +1,251 @@
+package ai_player
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"chess-tui/errkind"
+	"chess-tui/notation"
+)
+
+// defaultMaxIllegalMoves is how many illegal moves in a row a provider may
+// return before the next one takes over
+const defaultMaxIllegalMoves = 2
+
+// FailoverProvider tries an ordered list of providers. When the active one
+// times out, fails or keeps returning illegal moves, the next takes over for
+// the rest of the session.
+type FailoverProvider struct {
+	Members         []Provider
+	Timeouts        []time.Duration
+	MaxIllegalMoves int
+	Logger          *ColoredLogger
+
+	// Parse turns a text response into a move; set by NewAIPlayerFromConfig
+	Parse func(response string) (*ChessMove, error)
+
+	mu      sync.Mutex
+	active  int
+	illegal int
+}
+
+// NewFailoverProvider creates a chain of providers, each with its own timeout
+func NewFailoverProvider(members []Provider, timeouts []time.Duration, logger *ColoredLogger) *FailoverProvider {
+	if logger == nil {
+		logger = NewAIPlayerLogger()
+	}
+	return &FailoverProvider{
+		Members:         members,
+		Timeouts:        timeouts,
+		MaxIllegalMoves: defaultMaxIllegalMoves,
+		Logger:          logger,
+	}
+}
+
+// Name returns the name of the active provider
+func (f *FailoverProvider) Name() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Members[f.active].Name()
+}
+
+// current returns the index of the active provider
+func (f *FailoverProvider) current() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.active
+}
+
+// failover hands over from provider i to the next one
+func (f *FailoverProvider) failover(i int, reason error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.active != i || i+1 >= len(f.Members) {
+		return
+	}
+	f.active = i + 1
+	f.illegal = 0
+	f.Logger.Warn("🔀 %sProvider %s failed (%v), switching to %s%s",
+		ColorYellow, f.Members[i].Name(), reason, f.Members[f.active].Name(), ColorReset)
+}
+
+// memberContext bounds a call to provider i by its configured timeout
+func (f *FailoverProvider) memberContext(ctx context.Context, i int) (context.Context, context.CancelFunc) {
+	if i < len(f.Timeouts) && f.Timeouts[i] > 0 {
+		return context.WithTimeout(ctx, f.Timeouts[i])
+	}
+	return context.WithCancel(ctx)
+}
+
+// Generate sends the request to the active provider, failing over on errors
+func (f *FailoverProvider) Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error) {
+	var lastErr error
+	for i := f.current(); i < len(f.Members); i++ {
+		memberCtx, cancel := f.memberContext(ctx, i)
+		response, err := f.Members[i].Generate(memberCtx, request)
+		cancel()
+		if err == nil {
+			return response, nil
+		}
+		lastErr = err
+		f.failover(i, err)
+	}
+	return nil, fmt.Errorf("all providers failed: %w", lastErr)
+}
+
+// SelectMove asks the active provider for a legal move. Text providers'
+// replies are parsed and checked against the legal moves; a provider that
+// repeatedly answers with illegal moves is replaced by the next one.
+func (f *FailoverProvider) SelectMove(ctx context.Context, request MoveRequest) (*ChessMove, error) {
+	var lastErr error
+	for i := f.current(); i < len(f.Members); i = f.current() {
+		move, err := f.selectWith(ctx, i, request)
+		if err == nil {
+			f.mu.Lock()
+			f.illegal = 0
+			f.mu.Unlock()
+			return move, nil
+		}
+		lastErr = err
+
+		if _, illegal := err.(*illegalMoveError); illegal {
+			f.mu.Lock()
+			f.illegal++
+			exhausted := f.illegal >= f.MaxIllegalMoves
+			f.mu.Unlock()
+			if !exhausted {
+				continue
+			}
+		}
+
+		if i+1 >= len(f.Members) {
+			break
+		}
+		f.failover(i, err)
+	}
+	return nil, fmt.Errorf("all providers failed: %w", lastErr)
+}
+
+// selectWith gets a move from provider i
+func (f *FailoverProvider) selectWith(ctx context.Context, i int, request MoveRequest) (*ChessMove, error) {
+	memberCtx, cancel := f.memberContext(ctx, i)
+	defer cancel()
+	return selectFrom(memberCtx, f.Members[i], f.Parse, request)
+}
+
+// selectFrom gets a move from provider, asking for a structured move if it
+// can give one, or else parsing its reply with parse and checking it
+// against the legal moves. In a variant the reply must name one of
+// request.LegalMoves, as standard chess can't check it.
+func selectFrom(ctx context.Context, provider Provider, parse func(string) (*ChessMove, error), request MoveRequest) (*ChessMove, error) {
+	if selector, ok := provider.(MoveSelector); ok {
+		return selector.SelectMove(ctx, request)
+	}
+
+	response, err := provider.Generate(ctx, request.Generate)
+	if err != nil {
+		return nil, err
+	}
+	if parse == nil {
+		return nil, fmt.Errorf("no move parser configured")
+	}
+	if request.Variant != "" {
+		san, ok := listedMove(response.Response, request.LegalMoves)
+		if !ok {
+			return nil, &illegalMoveError{move: response.Response}
+		}
+		return &ChessMove{
+			Notation:         san,
+			Reasoning:        summarizeThinking(response.Thinking),
+			PromptTokens:     response.PromptEvalCount,
+			CompletionTokens: response.EvalCount,
+		}, nil
+	}
+	move, err := parse(response.Response)
+	if err != nil {
+		return nil, &illegalMoveError{move: response.Response}
+	}
+
+	san, err := notation.Normalize(request.FEN, move.Notation)
+	if err != nil {
+		return nil, &illegalMoveError{move: move.Notation}
+	}
+	move.Confidence = moveConfidence(response.Logprobs, move.Notation)
+	move.Notation = san
+	move.Reasoning = summarizeThinking(response.Thinking)
+	move.PromptTokens = response.PromptEvalCount
+	move.CompletionTokens = response.EvalCount
+	return move, nil
+}
+
+// TestConnection succeeds if any provider is reachable, starting the chain
+// at the first one that is
+func (f *FailoverProvider) TestConnection() error {
+	var lastErr error
+	for i := f.current(); i < len(f.Members); i++ {
+		err := f.Members[i].TestConnection()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		f.failover(i, err)
+	}
+	return fmt.Errorf("no provider reachable: %w", lastErr)
+}
+
+// illegalMoveError reports a reply that isn't a legal move in the position
+type illegalMoveError struct {
+	move string
+}
+
+func (e *illegalMoveError) Error() string {
+	return fmt.Sprintf("illegal move: %s", e.move)
+}
+
+// Is makes the error of kind errkind.IllegalMove
+func (e *illegalMoveError) Is(target error) bool {
+	return target == errkind.IllegalMove
+}
+
+// ollamaProvider adapts the AI player's built-in Ollama client to the
+// Provider interface so Ollama can take part in a failover chain
+type ollamaProvider struct {
+	player *AIPlayer
+	model  string // asked instead of the request's model, if set
+}
+
+// Name identifies the provider
+func (p *ollamaProvider) Name() string {
+	return ProviderOllama
+}
+
+// Generate calls Ollama
+func (p *ollamaProvider) Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error) {
+	type result struct {
+		response *OllamaResponse
+		err      error
+	}
+	if p.model != "" {
+		request.Model = p.model
+	}
+	done := make(chan result, 1)
+	go func() {
+		response, err := p.player.callOllama(request, nil)
+		done <- result{response, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("ollama request timed out: %w", ctx.Err())
+	case r := <-done:
+		return r.response, r.err
+	}
+}
+
+// TestConnection checks that Ollama is reachable
+func (p *ollamaProvider) TestConnection() error {
+	return p.player.TestConnection()
+}
@@ -0,0 +1,88 @@
+package ai_player
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// fakeProvider replies with a fixed text or error
+type fakeProvider struct {
+	name  string
+	reply string
+	err   error
+	calls int
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &OllamaResponse{Response: p.reply}, nil
+}
+
+func (p *fakeProvider) TestConnection() error { return p.err }
+
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+func TestFailoverOnError(t *testing.T) {
+	down := &fakeProvider{name: "down", err: fmt.Errorf("connection refused")}
+	up := &fakeProvider{name: "up", reply: "e2e4"}
+
+	player := NewAIPlayer("", "m", "white", nil)
+	chain := NewFailoverProvider([]Provider{down, up}, nil, player.Logger)
+	chain.Parse = player.parseMove
+	player.Provider = chain
+
+	move, err := player.GetMove(startFEN, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Notation != "e4" {
+		t.Errorf("Expected the long move normalized to e4, got %s", move.Notation)
+	}
+	if move.Provider != "up" {
+		t.Errorf("Expected provider 'up', got %s", move.Provider)
+	}
+
+	// The failed provider isn't retried once the chain has moved on
+	player.GetMove(startFEN, nil)
+	if down.calls != 1 {
+		t.Errorf("Expected the failed provider to be called once, got %d", down.calls)
+	}
+}
+
+func TestFailoverOnRepeatedIllegalMoves(t *testing.T) {
+	confused := &fakeProvider{name: "confused", reply: "Qh5"}
+
+	player := NewAIPlayer("", "m", "white", nil)
+	chain := NewFailoverProvider([]Provider{confused, NewEngineProvider()}, nil, player.Logger)
+	chain.Parse = player.parseMove
+	player.Provider = chain
+
+	move, err := player.GetMove(startFEN, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if confused.calls != defaultMaxIllegalMoves {
+		t.Errorf("Expected %d attempts before failing over, got %d", defaultMaxIllegalMoves, confused.calls)
+	}
+	if move.Provider != ProviderEngine {
+		t.Errorf("Expected the engine to take over, got %s", move.Provider)
+	}
+}
+
+func TestEngineTakesHangingQueen(t *testing.T) {
+	// Black's queen on d4 is attacked by the knight on f3
+	fen := "rnb1kbnr/pppp1ppp/8/4p3/3q4/5N2/PPPPPPPP/RNBQKB1R w KQkq - 0 3"
+	move, err := NewEngineProvider().SelectMove(context.Background(), MoveRequest{FEN: fen})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Notation != "Nxd4" {
+		t.Errorf("Expected Nxd4, got %s", move.Notation)
+	}
+}
@@ -0,0 +1,287 @@
+package ai_player
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// Board formats a move request can send the position in, named by the
+// message's "board_format" metadata. Without it the format is detected.
+const (
+	BoardFormatFEN   = "fen"   // board_state is a FEN
+	BoardFormatASCII = "ascii" // board_state is a diagram, rank 8 first
+	BoardFormatJSON  = "json"  // pieces maps squares to pieces, e.g. {"e1": "K"}
+)
+
+// Output modes a reply can be sent in
+const (
+	OutputModeText = "text/plain"       // "Generated move: e4"
+	OutputModeJSON = "application/json" // the move and its details as data
+)
+
+// ErrCodeContentTypeNotSupported is the A2A error code returned when the
+// client accepts none of the output modes the server can reply in
+const ErrCodeContentTypeNotSupported = -32005
+
+// pieceLetters maps the figurines of board diagrams to FEN letters
+var pieceLetters = map[rune]byte{
+	'♔': 'K', '♕': 'Q', '♖': 'R', '♗': 'B', '♘': 'N', '♙': 'P',
+	'♚': 'k', '♛': 'q', '♜': 'r', '♝': 'b', '♞': 'n', '♟': 'p',
+}
+
+// emptySquares are the characters diagrams draw empty squares with
+const emptySquares = ".-_·"
+
+// normalizeBoard turns the request's position into a FEN in BoardState, from
+// the format the client named or, without one, the format it looks like.
+// A board state that is none of the formats is left for the AI to read as is.
+func normalizeBoard(req *ChessRequest, format, field string) *ValidationError {
+	if format == "" {
+		switch {
+		case len(req.Pieces) > 0:
+			format = BoardFormatJSON
+		case strings.Contains(strings.TrimSpace(req.BoardState), "\n"):
+			if _, err := boardFromASCII(req.BoardState, req.PlayerColor); err == nil {
+				format = BoardFormatASCII
+			}
+		}
+	}
+
+	switch format {
+	case "", BoardFormatFEN:
+		if format == BoardFormatFEN {
+			if _, err := chess.FEN(req.BoardState); err != nil {
+				return invalidField(field+".board_state", "is not a valid FEN: %v", err)
+			}
+		}
+	case BoardFormatASCII:
+		fen, err := boardFromASCII(req.BoardState, req.PlayerColor)
+		if err != nil {
+			return invalidField(field+".board_state", "is not a board diagram: %v", err)
+		}
+		req.BoardState = fen
+	case BoardFormatJSON:
+		fen, err := boardFromPieces(req.Pieces, req.PlayerColor)
+		if err != nil {
+			return invalidField(field+".pieces", "%v", err)
+		}
+		req.BoardState = fen
+	default:
+		return invalidField("params.message.metadata.board_format", "must be %q, %q or %q, got %q", BoardFormatFEN, BoardFormatASCII, BoardFormatJSON, format)
+	}
+	return nil
+}
+
+// boardFromASCII reads a board diagram, one rank per line with rank 8
+// first unless the lines are numbered otherwise. Squares are FEN letters or
+// figurines, with empty ones drawn as '.', '-', '_' or '·'; rank numbers,
+// spaces and '|' borders are ignored. color is the side to move.
+func boardFromASCII(diagram, color string) (string, error) {
+	var ranks []string
+	var labels []int
+	for _, line := range strings.Split(diagram, "\n") {
+		if row, label, ok := asciiRank(line); ok {
+			ranks = append(ranks, row)
+			labels = append(labels, label)
+		}
+	}
+	if len(ranks) != 8 {
+		return "", fmt.Errorf("found %d ranks of 8 squares, expected 8", len(ranks))
+	}
+	if labels[0] == 1 && labels[7] == 8 {
+		// Drawn from Black's side
+		for i, j := 0, 7; i < j; i, j = i+1, j-1 {
+			ranks[i], ranks[j] = ranks[j], ranks[i]
+		}
+	}
+	return placementFEN(ranks, color)
+}
+
+// asciiRank reads one line of a diagram as eight squares, returning its
+// rank number if it has one. Lines of something else, such as the file
+// letters or a border, aren't ranks.
+func asciiRank(line string) (string, int, bool) {
+	if strings.Trim(line, "-_=+| \t\r") == "" {
+		// A border, or an unnumbered rank of dashes, which looks the same
+		return "", 0, false
+	}
+	var row strings.Builder
+	label := 0
+	for _, r := range line {
+		switch {
+		case r >= '1' && r <= '8':
+			label = int(r - '0')
+		case r == ' ' || r == '\t' || r == '|' || r == '+' || r == '\r':
+		case strings.ContainsRune(emptySquares, r):
+			row.WriteByte('.')
+		case strings.ContainsRune("KQRBNPkqrbnp", r):
+			row.WriteRune(r)
+		case pieceLetters[r] != 0:
+			row.WriteByte(pieceLetters[r])
+		default:
+			return "", 0, false
+		}
+	}
+	squares := row.String()
+	return squares, label, len(squares) == 8
+}
+
+// boardFromPieces builds a FEN from a map of squares to pieces, e.g.
+// {"e1": "K", "e8": "k"}. color is the side to move.
+func boardFromPieces(pieces map[string]string, color string) (string, error) {
+	grid := make([][]byte, 8)
+	for i := range grid {
+		grid[i] = []byte("........")
+	}
+	for square, piece := range pieces {
+		s := strings.ToLower(strings.TrimSpace(square))
+		if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+			return "", fmt.Errorf("has %q, which isn't a square like \"e4\"", square)
+		}
+		letter, ok := pieceLetter(piece)
+		if !ok {
+			return "", fmt.Errorf("has %q on %s, which isn't a piece like \"K\" or \"p\"", piece, s)
+		}
+		grid['8'-s[1]][s[0]-'a'] = letter
+	}
+	ranks := make([]string, 8)
+	for i, row := range grid {
+		ranks[i] = string(row)
+	}
+	return placementFEN(ranks, color)
+}
+
+// pieceLetter returns the FEN letter of a piece given as a letter or figurine
+func pieceLetter(piece string) (byte, bool) {
+	runes := []rune(strings.TrimSpace(piece))
+	if len(runes) != 1 {
+		return 0, false
+	}
+	if strings.ContainsRune("KQRBNPkqrbnp", runes[0]) {
+		return byte(runes[0]), true
+	}
+	letter, ok := pieceLetters[runes[0]]
+	return letter, ok
+}
+
+// placementFEN turns eight ranks of FEN letters and '.', rank 8 first, into
+// a FEN with color to move. Castling rights are assumed wherever the king
+// and rook are on their starting squares; there's no en passant square.
+func placementFEN(ranks []string, color string) (string, error) {
+	var placement strings.Builder
+	for i, rank := range ranks {
+		if i > 0 {
+			placement.WriteByte('/')
+		}
+		empty := 0
+		for j := 0; j < len(rank); j++ {
+			if rank[j] == '.' {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				placement.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+			placement.WriteByte(rank[j])
+		}
+		if empty > 0 {
+			placement.WriteByte(byte('0' + empty))
+		}
+	}
+
+	board := strings.Join(ranks, "")
+	if strings.Count(board, "K") != 1 || strings.Count(board, "k") != 1 {
+		return "", fmt.Errorf("needs exactly one king of each color")
+	}
+
+	castling := ""
+	for _, right := range []struct {
+		letter               string
+		king, rook           int // indexes into board, rank 8 first
+		kingPiece, rookPiece byte
+	}{
+		{"K", 60, 63, 'K', 'R'}, {"Q", 60, 56, 'K', 'R'},
+		{"k", 4, 7, 'k', 'r'}, {"q", 4, 0, 'k', 'r'},
+	} {
+		if board[right.king] == right.kingPiece && board[right.rook] == right.rookPiece {
+			castling += right.letter
+		}
+	}
+	if castling == "" {
+		castling = "-"
+	}
+
+	turn := "w"
+	if color == "black" {
+		turn = "b"
+	}
+	fen := fmt.Sprintf("%s %s %s - 0 1", placement.String(), turn, castling)
+	if _, err := chess.FEN(fen); err != nil {
+		return "", fmt.Errorf("doesn't make a valid position: %w", err)
+	}
+	return fen, nil
+}
+
+// boardFormat returns the board format named in a message's metadata, or
+// in the request's
+func boardFormat(params MessageSendParams) string {
+	for _, metadata := range []map[string]interface{}{params.Message.Metadata, params.Metadata} {
+		if format, ok := metadata["board_format"].(string); ok {
+			return strings.ToLower(format)
+		}
+	}
+	return ""
+}
+
+// negotiateOutputModes returns the output modes to reply in, of those the
+// client accepts; none accepted means any. It fails when the client accepts
+// none the server supports.
+func negotiateOutputModes(accepted []string) ([]string, error) {
+	if len(accepted) == 0 {
+		return nil, nil
+	}
+	var modes []string
+	for _, mode := range accepted {
+		mediaType, _, err := mime.ParseMediaType(mode)
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "*/*":
+			return nil, nil
+		case "text/*":
+			modes = append(modes, OutputModeText)
+		case OutputModeText, OutputModeJSON:
+			modes = append(modes, mediaType)
+		}
+	}
+	if len(modes) == 0 {
+		return nil, fmt.Errorf("accepted output modes %v include neither %s nor %s", accepted, OutputModeText, OutputModeJSON)
+	}
+	return modes, nil
+}
+
+// filterParts keeps the reply parts of the output modes; nil modes keep all
+func filterParts(parts []MessagePartsElem, modes []string) []MessagePartsElem {
+	if modes == nil {
+		return parts
+	}
+	var kept []MessagePartsElem
+	for _, part := range parts {
+		mode := OutputModeJSON
+		if _, ok := part.(TextPart); ok {
+			mode = OutputModeText
+		}
+		for _, m := range modes {
+			if m == mode {
+				kept = append(kept, part)
+				break
+			}
+		}
+	}
+	return kept
+}
@@ -0,0 +1,129 @@
+package ai_player
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestBoardFromASCII(t *testing.T) {
+	after := "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1"
+	position := chess.NewGame()
+	position.MoveStr("e4")
+
+	tests := []struct {
+		name, diagram string
+	}{
+		{"letters", `
+r n b q k b n r
+p p p p p p p p
+. . . . . . . .
+. . . . . . . .
+. . . . P . . .
+. . . . . . . .
+P P P P . P P P
+R N B Q K B N R`},
+		{"figurines with labels", position.Position().Board().Draw()},
+		{"from black's side", `
+1 |R|N|B|Q|K|B|N|R|
+2 |P|P|P|P|.|P|P|P|
+3 |.|.|.|.|.|.|.|.|
+4 |.|.|.|.|P|.|.|.|
+5 |.|.|.|.|.|.|.|.|
+6 |.|.|.|.|.|.|.|.|
+7 |p|p|p|p|p|p|p|p|
+8 |r|n|b|q|k|b|n|r|
+  +---------------+
+   a b c d e f g h`},
+	}
+	for _, tt := range tests {
+		fen, err := boardFromASCII(tt.diagram, "black")
+		if err != nil {
+			t.Errorf("%s: expected a board, got %v", tt.name, err)
+			continue
+		}
+		if fen != after {
+			t.Errorf("%s: expected %s, got %s", tt.name, after, fen)
+		}
+	}
+
+	if _, err := boardFromASCII("r n b q k b n r\np p p p p p p p", "white"); err == nil {
+		t.Error("Expected two ranks to be refused")
+	}
+}
+
+func TestBoardFromPieces(t *testing.T) {
+	fen, err := boardFromPieces(map[string]string{"e1": "K", "h1": "R", "E8": "♚", "d7": "p"}, "white")
+	if err != nil {
+		t.Fatalf("Expected a board, got %v", err)
+	}
+	if fen != "4k3/3p4/8/8/8/8/8/4K2R w K - 0 1" {
+		t.Errorf("Unexpected FEN %s", fen)
+	}
+	if _, err := boardFromPieces(map[string]string{"e9": "K"}, "white"); err == nil || !strings.Contains(err.Error(), `"e9"`) {
+		t.Errorf("Expected the bad square named, got %v", err)
+	}
+	if _, err := boardFromPieces(map[string]string{"e1": "K"}, "white"); err == nil {
+		t.Error("Expected a board without a black king to be refused")
+	}
+}
+
+func TestNegotiatedFormats(t *testing.T) {
+	logger := quietLogger()
+	player := &AIPlayer{Provider: NewEngineProvider(), Logger: logger, Model: "engine"}
+	server := httptest.NewServer(handleJSONRPCEndpoint(player, nil, logger))
+	defer server.Close()
+
+	send := func(part map[string]interface{}, metadata map[string]interface{}, accepted []string) map[string]interface{} {
+		return postJSONRPC(t, server.URL, map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "message/send",
+			"id":      1,
+			"params": map[string]interface{}{
+				"message": map[string]interface{}{
+					"kind": "message", "messageId": "msg_1", "role": "user",
+					"parts": []interface{}{part}, "metadata": metadata,
+				},
+				"configuration": map[string]interface{}{"acceptedOutputModes": accepted},
+			},
+		})
+	}
+	parts := func(reply map[string]interface{}) []interface{} {
+		result, _ := reply["result"].(map[string]interface{})
+		list, _ := result["parts"].([]interface{})
+		return list
+	}
+
+	// A JSON piece placement in a data part, answered as data only
+	reply := send(map[string]interface{}{"kind": "data", "data": map[string]interface{}{
+		"pieces": map[string]string{"e1": "K", "e8": "k", "a7": "Q", "b6": "Q"}, "player_color": "white",
+	}}, map[string]interface{}{"board_format": "json"}, []string{"application/json"})
+	got := parts(reply)
+	if len(got) != 1 || got[0].(map[string]interface{})["kind"] != "data" {
+		t.Fatalf("Expected a single data part, got %v", reply)
+	}
+
+	// An ASCII diagram, answered as text only
+	reply = send(map[string]interface{}{"kind": "text", "text": chess.StartingPosition().Board().Draw()}, nil, []string{"text/plain"})
+	got = parts(reply)
+	if len(got) != 1 || !strings.Contains(got[0].(map[string]interface{})["text"].(string), "Generated move") {
+		t.Fatalf("Expected a single text part, got %v", reply)
+	}
+
+	// Output modes the server can't produce
+	reply = send(map[string]interface{}{"kind": "text", "text": chess.StartingPosition().String()}, nil, []string{"image/png"})
+	data, _ := json.Marshal(reply["error"])
+	if !strings.Contains(string(data), `"code":-32005`) {
+		t.Errorf("Expected an incompatible content types error, got %s", data)
+	}
+
+	// A named format the board isn't in
+	reply = send(map[string]interface{}{"kind": "text", "text": "not a board"}, map[string]interface{}{"board_format": "fen"}, nil)
+	data, _ = json.Marshal(reply["error"])
+	if !strings.Contains(string(data), "board_state") {
+		t.Errorf("Expected the board state named, got %s", data)
+	}
+}
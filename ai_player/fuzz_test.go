@@ -0,0 +1,53 @@
+package ai_player
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"chess-tui/notation"
+)
+
+func FuzzParseMove(f *testing.F) {
+	for _, seed := range []string{
+		"e4", "Nbd7", "exd8=Q+", "e7e8q", "O-O-O+", "Move: Nc6", "The best move is e4.",
+		"Kxe5!", "", "=", "+", "e8=", "Nf3\nbecause", "♘f3", "e2-e4",
+	} {
+		f.Add(seed)
+	}
+
+	logger := NewColoredLogger(LevelError)
+	logger.SetOutput(io.Discard)
+	ai := &AIPlayer{Logger: logger}
+
+	f.Fuzz(func(t *testing.T, response string) {
+		move, err := ai.parseMove(response)
+		if err != nil {
+			return
+		}
+		if move.Notation == "" || strings.ContainsAny(move.Notation, "\n") {
+			t.Errorf("parseMove(%q) accepted %q", response, move.Notation)
+		}
+		if !notation.IsMove(move.Notation) {
+			t.Errorf("parseMove(%q) returned %q, which fails validation", response, move.Notation)
+		}
+	})
+}
+
+func FuzzParseCandidates(f *testing.F) {
+	f.Add("1. e4 - grabs the center\n2. Nf3: develops\n3. **d4** – also good")
+	f.Add("- e7e8q - promote\n- zz - nonsense\n- e4 - duplicate\n- e2e4 - duplicate")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, response string) {
+		candidates := parseCandidates(response, startFEN)
+		if len(candidates) > maxCandidates {
+			t.Errorf("Expected at most %d candidates, got %d", maxCandidates, len(candidates))
+		}
+		for _, candidate := range candidates {
+			if _, err := notation.Normalize(startFEN, candidate.Move); err != nil {
+				t.Errorf("parseCandidates kept illegal move %q", candidate.Move)
+			}
+		}
+	})
+}
@@ -40,10 +40,10 @@ func NewAIGame(mode GameMode, config *Config) *AIGame {
 	logger := NewAIPlayerLogger()
 	switch mode {
 	case ModeHumanVsAI:
-		game.AIBlack = NewAIPlayer(config.OllamaURL, config.Model, "black", logger)
+		game.AIBlack = newConfiguredPlayer(config, "black", logger)
 	case ModeAIvsAI:
-		game.AIWhite = NewAIPlayer(config.OllamaURL, config.Model, "white", logger)
-		game.AIBlack = NewAIPlayer(config.OllamaURL, config.Model, "black", logger)
+		game.AIWhite = newConfiguredPlayer(config, "white", logger)
+		game.AIBlack = newConfiguredPlayer(config, "black", logger)
 	case ModeHumanVsHuman:
 		// No AI players needed
 	}
@@ -51,6 +51,17 @@ func NewAIGame(mode GameMode, config *Config) *AIGame {
 	return game
 }
 
+// newConfiguredPlayer creates an AI player for the configured provider,
+// falling back to Ollama if the provider is unknown
+func newConfiguredPlayer(config *Config, color string, logger *ColoredLogger) *AIPlayer {
+	player, err := NewAIPlayerFromConfig(config, color, logger)
+	if err != nil {
+		logger.Warn("⚠️ %sFalling back to Ollama: %v%s", ColorYellow, err, ColorReset)
+		return NewAIPlayer(config.OllamaURL, config.Model, color, logger)
+	}
+	return player
+}
+
 // GetAIMove gets the next move from the appropriate AI player
 func (g *AIGame) GetAIMove(boardState string) (*ChessMove, error) {
 	var aiPlayer *AIPlayer
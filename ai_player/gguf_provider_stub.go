@@ -0,0 +1,11 @@
+//go:build !llama
+
+package ai_player
+
+import "fmt"
+
+// NewGGUFProvider reports that in-process inference wasn't compiled in. Build
+// with -tags llama to enable it.
+func NewGGUFProvider(modelPath string, logger *ColoredLogger) (Provider, error) {
+	return nil, fmt.Errorf("gguf provider not available: rebuild with -tags llama")
+}
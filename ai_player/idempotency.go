@@ -0,0 +1,92 @@
+package ai_player
+
+import "sync"
+
+// moveCacheSize is how many replies the server remembers for idempotent retries
+const moveCacheSize = 256
+
+// moveCache remembers the reply to each move request that carried an
+// idempotency key, so a client retrying the same request gets the same move
+// instead of a second, different one. Concurrent duplicates wait for the
+// first request to finish.
+type moveCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedMove
+	order   []string // keys, oldest first, for eviction
+	size    int
+}
+
+// cachedMove is a reply that is being computed or is done
+type cachedMove struct {
+	done     chan struct{}
+	response *ChessResponse
+	err      error
+}
+
+// newMoveCache creates a cache holding up to size replies
+func newMoveCache(size int) *moveCache {
+	return &moveCache{entries: make(map[string]*cachedMove), size: size}
+}
+
+// cacheKey ties an idempotency key to the position it was sent for, so a
+// reused key can't return a move for another board
+func cacheKey(req ChessRequest) string {
+	return req.IdempotencyKey + "|" + req.BoardState
+}
+
+// do returns the cached reply for the request's key, or computes it with fn.
+// Failed replies aren't kept, so a retry after an error asks the AI again.
+// The second result reports whether the reply came from the cache.
+func (c *moveCache) do(req ChessRequest, fn func() (*ChessResponse, error)) (*ChessResponse, bool, error) {
+	if req.IdempotencyKey == "" {
+		response, err := fn()
+		return response, false, err
+	}
+	key := cacheKey(req)
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		<-entry.done
+		if entry.err == nil {
+			return entry.response, true, nil
+		}
+		// The first attempt failed; fall through to another
+		return c.do(req, fn)
+	}
+	entry := &cachedMove{done: make(chan struct{})}
+	c.entries[key] = entry
+	c.order = append(c.order, key)
+	c.evict()
+	c.mu.Unlock()
+
+	entry.response, entry.err = fn()
+	if entry.err != nil {
+		c.mu.Lock()
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+			c.forget(key)
+		}
+		c.mu.Unlock()
+	}
+	close(entry.done)
+	return entry.response, false, entry.err
+}
+
+// evict drops the oldest entries beyond the cache size. The caller holds c.mu.
+func (c *moveCache) evict() {
+	for len(c.order) > c.size {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// forget removes a key from the eviction order. The caller holds c.mu.
+func (c *moveCache) forget(key string) {
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			return
+		}
+	}
+}
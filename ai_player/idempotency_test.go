@@ -0,0 +1,92 @@
+package ai_player
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMoveCacheReplaysRetries(t *testing.T) {
+	cache := newMoveCache(2)
+	calls := 0
+	next := func() (*ChessResponse, error) {
+		calls++
+		return &ChessResponse{Move: fmt.Sprintf("move%d", calls)}, nil
+	}
+
+	req := ChessRequest{BoardState: startFEN, IdempotencyKey: "game_1:0"}
+	first, _, _ := cache.do(req, next)
+	again, cached, _ := cache.do(req, next)
+	if !cached || again.Move != first.Move {
+		t.Errorf("Expected the retry to replay %s, got %s (cached %v)", first.Move, again.Move, cached)
+	}
+
+	// The same key for another board is a different request
+	other := req
+	other.BoardState = "4k3/8/8/8/8/8/8/4K3 w - - 0 1"
+	if response, cached, _ := cache.do(other, next); cached || response.Move == first.Move {
+		t.Errorf("Expected a new move for another board, got %s (cached %v)", response.Move, cached)
+	}
+
+	// Requests without a key are never cached
+	unkeyed := ChessRequest{BoardState: startFEN}
+	a, _, _ := cache.do(unkeyed, next)
+	b, _, _ := cache.do(unkeyed, next)
+	if a.Move == b.Move {
+		t.Errorf("Expected unkeyed requests to get new moves, got %s twice", a.Move)
+	}
+
+	// The oldest entry is evicted beyond the cache size
+	cache.do(ChessRequest{BoardState: startFEN, IdempotencyKey: "game_1:2"}, next)
+	if response, cached, _ := cache.do(req, next); cached {
+		t.Errorf("Expected the oldest entry to be evicted, got cached %s", response.Move)
+	}
+}
+
+func TestMoveCacheDoesNotKeepErrors(t *testing.T) {
+	cache := newMoveCache(moveCacheSize)
+	req := ChessRequest{BoardState: startFEN, IdempotencyKey: "game_1:0"}
+
+	if _, _, err := cache.do(req, func() (*ChessResponse, error) { return nil, errors.New("backend down") }); err == nil {
+		t.Fatal("Expected the error to be returned")
+	}
+	response, cached, err := cache.do(req, func() (*ChessResponse, error) { return &ChessResponse{Move: "e4"}, nil })
+	if err != nil || cached || response.Move != "e4" {
+		t.Errorf("Expected a fresh e4 after an error, got %v (cached %v, err %v)", response, cached, err)
+	}
+}
+
+func TestMoveCacheConcurrentDuplicates(t *testing.T) {
+	cache := newMoveCache(moveCacheSize)
+	req := ChessRequest{BoardState: startFEN, IdempotencyKey: "game_1:0"}
+	var calls int32
+
+	var wg sync.WaitGroup
+	moves := make([]string, 4)
+	for i := range moves {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, _, _ := cache.do(req, func() (*ChessResponse, error) {
+				n := atomic.AddInt32(&calls, 1)
+				time.Sleep(10 * time.Millisecond)
+				return &ChessResponse{Move: fmt.Sprintf("move%d", n)}, nil
+			})
+			moves[i] = response.Move
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("Expected the AI to be asked once, got %d", calls)
+	}
+	for _, move := range moves {
+		if move != moves[0] {
+			t.Errorf("Expected every duplicate to get %s, got %v", moves[0], moves)
+			break
+		}
+	}
+}
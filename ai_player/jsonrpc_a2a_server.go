@@ -7,6 +7,10 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"chess-tui/errkind"
+	"chess-tui/jsonrpc"
+	"chess-tui/variant"
 )
 
 // ChessRequest represents a chess move request from the A2A client
@@ -14,29 +18,87 @@ type ChessRequest struct {
 	BoardState  string   `json:"board_state,omitempty"`
 	PlayerColor string   `json:"player_color,omitempty"`
 	GameHistory []string `json:"game_history,omitempty"`
+	Personality string   `json:"personality,omitempty"`
+	Bullet      bool     `json:"bullet,omitempty"`    // play for speed, as Config.Bullet does
+	Task        string   `json:"task,omitempty"`      // "" for a move, TaskSuggest for teach mode, TaskReview to judge Move
+	Move        string   `json:"move,omitempty"`      // the proposed move, for TaskReview
+	FEN         string   `json:"fen,omitempty"`       // client's position, checked against GameHistory
+	StartFEN    string   `json:"start_fen,omitempty"` // position GameHistory starts from, if not the standard one
+
+	// Pieces is the position as a map of squares to pieces, e.g. {"e1": "K"},
+	// for clients that don't speak FEN
+	Pieces map[string]string `json:"pieces,omitempty"`
+
+	// IdempotencyKey identifies a move request; a retry with the same key
+	// and board gets the same move back instead of a new one
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// Actions lists what the client handles besides moves (ActionResign,
+	// ActionOfferDraw, ActionAcceptDraw); DrawOffered says its player has
+	// offered the AI a draw
+	Actions     []string `json:"actions,omitempty"`
+	DrawOffered bool     `json:"draw_offered,omitempty"`
+
+	// Priority is PriorityLow for requests that may wait behind all others,
+	// such as a kibitzer's remarks; "" is normal priority
+	Priority string `json:"priority,omitempty"`
+
+	// Variant names the chess variant played, e.g. "crazyhouse"; the board
+	// is then in the variant's FEN and the game isn't kept as a session
+	Variant string `json:"variant,omitempty"`
 }
 
+// PriorityLow queues a request behind every request of normal priority
+const PriorityLow = "low"
+
+// TaskSuggest asks for candidate moves for the human instead of a move
+const TaskSuggest = "suggest"
+
 // ChessResponse represents a chess move response from the AI
 type ChessResponse struct {
-	Move string `json:"move"`
+	Move             string `json:"move"`
+	Reasoning        string `json:"reasoning,omitempty"`
+	PromptTokens     int    `json:"prompt_tokens,omitempty"`
+	CompletionTokens int    `json:"completion_tokens,omitempty"`
+	Provider         string `json:"provider,omitempty"`
+	Vetoes           []Veto `json:"vetoes,omitempty"` // moves the reviewer sent back first
+	Votes            []Vote `json:"votes,omitempty"`  // how a vote chess panel chose the move
+
+	Confidence *float64 `json:"confidence,omitempty"` // how sure the AI is of the move, 0 to 1
+
+	// Spare is set when the spare model answered, the primary having timed out
+	Spare bool `json:"spare,omitempty"`
 }
 
 // JSONRPCA2AServer represents an A2A server using the generated JSON-RPC spec
 type JSONRPCA2AServer struct {
 	aiPlayer *AIPlayer
+	sessions *SessionStore
 	server   *http.Server
 	logger   *ColoredLogger
 }
 
 // NewJSONRPCA2AServer creates a new A2A server using the generated JSON-RPC spec
 func NewJSONRPCA2AServer(ollamaURL, model string, port int, logger *ColoredLogger) (*JSONRPCA2AServer, error) {
+	config := DefaultConfig()
+	config.OllamaURL = ollamaURL
+	config.Model = model
+	return NewJSONRPCA2AServerWithConfig(config, port, logger)
+}
+
+// NewJSONRPCA2AServerWithConfig creates a new A2A server whose AI player uses
+// the provider selected in the configuration
+func NewJSONRPCA2AServerWithConfig(config *Config, port int, logger *ColoredLogger) (*JSONRPCA2AServer, error) {
 	// Create AI player
-	aiPlayer := NewAIPlayer(ollamaURL, model, "black", logger)
+	aiPlayer, err := NewAIPlayerFromConfig(config, "black", logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AI player: %w", err)
+	}
 
-	// Test connection to Ollama
-	logger.Info("🔍 %sTesting Ollama connection...%s", ColorBlue, ColorReset)
+	// Test connection to the provider
+	logger.Info("🔍 %sTesting %s connection...%s", ColorBlue, aiPlayer.ProviderName(), ColorReset)
 	if err := aiPlayer.TestConnection(); err != nil {
-		return nil, fmt.Errorf("failed to test Ollama connection: %w", err)
+		return nil, fmt.Errorf("failed to test %s connection: %w", aiPlayer.ProviderName(), err)
 	}
 
 	// Test model response
@@ -45,13 +107,31 @@ func NewJSONRPCA2AServer(ollamaURL, model string, port int, logger *ColoredLogge
 		return nil, fmt.Errorf("failed to test model response: %w", err)
 	}
 
+	// Reload the sessions of games in progress before the last restart
+	sessionsPath := config.SessionsFile
+	if sessionsPath == "" {
+		sessionsPath = DefaultSessionsPath()
+	}
+	sessions, err := LoadSessionStore(sessionsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sessions: %w", err)
+	}
+	if n := sessions.Len(); n > 0 {
+		logger.Info("💾 %sRestored %d sessions from %s%s", ColorCyan, n, sessionsPath, ColorReset)
+	}
+
 	// Create HTTP server
 	mux := http.NewServeMux()
 
 	// Add A2A endpoints
 	mux.HandleFunc("/", handleJSONRPCRoot)
 	mux.HandleFunc("/.well-known/agent.json", handleJSONRPCAgentCard)
-	mux.HandleFunc("/a2a", handleJSONRPCEndpoint(aiPlayer, logger))
+	metrics := newServerMetrics()
+	mux.HandleFunc("/a2a", jsonrpcEndpoint(aiPlayer, sessions, metrics, logger))
+	mux.HandleFunc("GET /games/{id}/board.svg", handleBoardSVG(sessions))
+	mux.HandleFunc("GET /games/{id}/fen", handleGameFEN(sessions))
+	mux.HandleFunc("GET /games/{id}/pgn", handleGamePGN(sessions))
+	handleAdminEndpoints(mux, aiPlayer, sessions, metrics, config.AdminToken, logger)
 
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
@@ -60,6 +140,7 @@ func NewJSONRPCA2AServer(ollamaURL, model string, port int, logger *ColoredLogge
 
 	return &JSONRPCA2AServer{
 		aiPlayer: aiPlayer,
+		sessions: sessions,
 		server:   httpServer,
 		logger:   logger,
 	}, nil
@@ -68,15 +149,43 @@ func NewJSONRPCA2AServer(ollamaURL, model string, port int, logger *ColoredLogge
 // Start starts the JSON-RPC A2A server
 func (s *JSONRPCA2AServer) Start() error {
 	s.logger.Info("🚀 %sStarting JSON-RPC A2A Chess Server on :8080%s", ColorGreen, ColorReset)
-	s.logger.Info("🤖 %sAI Model: %s%s", ColorCyan, s.aiPlayer.Model, ColorReset)
-	s.logger.Info("🔗 %sOllama URL: %s%s", ColorBlue, s.aiPlayer.OllamaURL, ColorReset)
+	s.logger.Info("🤖 %sAI Model: %s (%s)%s", ColorCyan, s.aiPlayer.Model, s.aiPlayer.ProviderName(), ColorReset)
+	if s.aiPlayer.Provider == nil {
+		s.logger.Info("🔗 %sOllama URL: %s%s", ColorBlue, s.aiPlayer.OllamaURL, ColorReset)
+	}
 
 	return s.server.ListenAndServe()
 }
 
-// Stop stops the JSON-RPC A2A server gracefully
+// WatchConfig reloads the AI player's settings whenever the config file at
+// path changes, until ctx is done. override, if set, is applied to each
+// loaded config first, e.g. to keep command-line flags in effect.
+func (s *JSONRPCA2AServer) WatchConfig(ctx context.Context, path string, interval time.Duration, override func(*Config)) {
+	WatchConfig(ctx, path, interval, s.logger, func(config *Config) error {
+		if override != nil {
+			override(config)
+		}
+		changes, err := s.aiPlayer.ApplyConfig(config)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			s.logger.Info("🔄 %sReloaded AI config from %s (no changes)%s", ColorCyan, path, ColorReset)
+		} else {
+			s.logger.Info("🔄 %sReloaded AI config from %s: %s%s", ColorCyan, path, strings.Join(changes, ", "), ColorReset)
+		}
+		return nil
+	})
+}
+
+// Stop stops the JSON-RPC A2A server gracefully, letting requests in
+// flight finish until ctx ends and then dropping them
 func (s *JSONRPCA2AServer) Stop(ctx context.Context) error {
-	return s.server.Shutdown(ctx)
+	err := s.server.Shutdown(ctx)
+	if err != nil {
+		s.server.Close()
+	}
+	return err
 }
 
 // handleJSONRPCRoot handles the root endpoint
@@ -111,17 +220,30 @@ func handleJSONRPCAgentCard(w http.ResponseWriter, r *http.Request) {
 		ProtocolVersion:    "1.0.0",
 		PreferredTransport: "JSONRPC",
 		Capabilities: AgentCapabilities{
-			Streaming:         &[]bool{false}[0],
-			PushNotifications: &[]bool{false}[0],
+			Streaming:         &[]bool{true}[0],
+			PushNotifications: &[]bool{true}[0],
 		},
 		DefaultInputModes:  []string{"text/plain", "application/json"},
 		DefaultOutputModes: []string{"text/plain", "application/json"},
 		Skills: []AgentSkill{
 			{
-				Name:        "chess_move_generation",
-				Description: "Generate chess moves using AI analysis",
-				InputModes:  []string{"text/plain", "application/json"},
-				OutputModes: []string{"text/plain", "application/json"},
+				Id:   "chess_move_generation",
+				Name: "chess_move_generation",
+				Description: "Generate chess moves using AI analysis. The board can be sent as a FEN, an ASCII " +
+					"diagram or a JSON map of squares to pieces, named by the message's board_format metadata " +
+					"(fen, ascii or json); the move is returned in the accepted output modes.",
+				Tags:        []string{"chess", "fen", "ascii", "json"},
+				InputModes:  []string{OutputModeText, OutputModeJSON},
+				OutputModes: []string{OutputModeText, OutputModeJSON},
+			},
+			{
+				Id:   "chess_move_review",
+				Name: "chess_move_review",
+				Description: "Review a move another agent proposes: send task \"review\" with the position and the " +
+					"move, and get back whether it is approved and why, so the proposer can try again when it is vetoed.",
+				Tags:        []string{"chess", "review", "multi-agent"},
+				InputModes:  []string{OutputModeText, OutputModeJSON},
+				OutputModes: []string{OutputModeText, OutputModeJSON},
 			},
 		},
 	}
@@ -131,142 +253,495 @@ func handleJSONRPCAgentCard(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleJSONRPCEndpoint handles A2A JSON-RPC protocol requests
-func handleJSONRPCEndpoint(aiPlayer *AIPlayer, logger *ColoredLogger) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			sendJSONRPCError(w, -32600, "Method Not Allowed", "Only POST method is supported", nil)
-			return
-		}
+func handleJSONRPCEndpoint(aiPlayer *AIPlayer, sessions *SessionStore, logger *ColoredLogger) http.HandlerFunc {
+	return jsonrpcEndpoint(aiPlayer, sessions, nil, logger)
+}
 
-		// Parse the request body to determine the method
-		var rawRequest map[string]interface{}
-		if err := json.NewDecoder(r.Body).Decode(&rawRequest); err != nil {
-			sendJSONRPCError(w, -32700, "Parse error", err.Error(), nil)
-			return
-		}
+// jsonrpcEndpoint handles A2A JSON-RPC protocol requests, counting them in
+// metrics if set
+func jsonrpcEndpoint(aiPlayer *AIPlayer, sessions *SessionStore, metrics *serverMetrics, logger *ColoredLogger) http.HandlerFunc {
+	moves := newMoveCache(moveCacheSize)
+	// The AI player works on one request at a time; the rest wait their turn
+	workers := newWorkerPool(1)
+	pushes := newPushRegistry(logger)
+	if metrics != nil {
+		metrics.mu.Lock()
+		metrics.workers = workers
+		metrics.mu.Unlock()
+	}
 
-		// Extract method and ID for error handling
-		method, _ := rawRequest["method"].(string)
-		requestID := rawRequest["id"]
-
-		// Handle different A2A methods
-		switch method {
-		case "message/send":
-			handleJSONRPCMessageSend(w, r, rawRequest, aiPlayer, logger)
-		case "tasks/send":
-			handleJSONRPCTasksSend(w, r, rawRequest, aiPlayer, logger)
-		default:
-			sendJSONRPCError(w, -32601, "Method not found", fmt.Sprintf("Method '%s' not found", method), requestID)
-		}
+	server := jsonrpc.NewServer()
+	server.Handle("message/send", func(call *jsonrpc.Call) (interface{}, error) {
+		reply := newJSONRPCReply(call, false)
+		handleJSONRPCMessageSend(reply, call, aiPlayer, sessions, moves, workers, pushes, metrics, logger)
+		return reply.outcome()
+	})
+	server.Handle("message/stream", func(call *jsonrpc.Call) (interface{}, error) {
+		return handleJSONRPCMessageStream(call, aiPlayer, sessions, moves, workers, pushes, metrics, logger)
+	})
+	server.Handle("tasks/send", func(call *jsonrpc.Call) (interface{}, error) {
+		return handleJSONRPCTasksSend(call, aiPlayer, sessions, moves, workers, pushes, metrics, logger)
+	})
+	for _, method := range []string{"set", "get", "list", "delete"} {
+		server.Handle("tasks/pushNotificationConfig/"+method, func(call *jsonrpc.Call) (interface{}, error) {
+			return handleJSONRPCPushConfig(call, pushes, logger)
+		})
 	}
+	server.Handle("session/replay", func(call *jsonrpc.Call) (interface{}, error) {
+		return handleJSONRPCSessionReplay(call, aiPlayer, sessions, logger)
+	})
+	server.Handle("session/resume", func(call *jsonrpc.Call) (interface{}, error) {
+		return handleJSONRPCSessionResume(call, aiPlayer, sessions, logger)
+	})
+	return server.ServeHTTP
+}
+
+// handleJSONRPCMessageStream handles the message/stream method, which works
+// like message/send but streams status updates, such as the request's place
+// in the queue, as server-sent events before the reply
+func handleJSONRPCMessageStream(call *jsonrpc.Call, aiPlayer *AIPlayer, sessions *SessionStore, moves *moveCache, workers *workerPool, pushes *pushRegistry, metrics *serverMetrics, logger *ColoredLogger) (interface{}, error) {
+	logger.Info("📡 %sReceived A2A message/stream request%s", ColorBlue, ColorReset)
+	reply := newJSONRPCReply(call, true)
+	handleJSONRPCMessageSend(reply, call, aiPlayer, sessions, moves, workers, pushes, metrics, logger)
+	return reply.outcome()
 }
 
 // handleJSONRPCMessageSend handles the message/send method for JSON-RPC
-func handleJSONRPCMessageSend(w http.ResponseWriter, r *http.Request, request map[string]interface{}, aiPlayer *AIPlayer, logger *ColoredLogger) {
-	logger.Info("📨 %sReceived A2A message/send request%s", ColorBlue, ColorReset)
-	logger.Debug("📋 %sRaw request: %+v%s", ColorGray, request, ColorReset)
-
-	// Extract ID for error handling
-	requestID := request["id"]
-
-	// Parse the request using the generated spec
-	var requestSendMessage SendMessageRequest
-	requestBytes, _ := json.Marshal(request)
-	logger.Debug("📄 %sRequest bytes: %s%s", ColorGray, string(requestBytes), ColorReset)
-	if err := json.Unmarshal(requestBytes, &requestSendMessage); err != nil {
-		logger.Error("❌ %sFailed to parse SendMessageRequest: %v%s", ColorRed, err, ColorReset)
-		sendJSONRPCError(w, -32602, "Invalid params", fmt.Sprintf("Failed to parse request: %v", err), requestID)
+func handleJSONRPCMessageSend(reply *jsonrpcReply, call *jsonrpc.Call, aiPlayer *AIPlayer, sessions *SessionStore, moves *moveCache, workers *workerPool, pushes *pushRegistry, metrics *serverMetrics, logger *ColoredLogger) {
+	if !reply.stream {
+		logger.Info("📨 %sReceived A2A message/send request%s", ColorBlue, ColorReset)
+	}
+	logger.Debug("📋 %sRaw params: %s%s", ColorGray, call.Params, ColorReset)
+
+	// Parse the request using the generated spec, first naming any field
+	// that is missing or malformed
+	if invalid := validateMessageSendParams(call.Params); invalid != nil {
+		logger.Warn("⚠️ %sInvalid message/send params: %v%s", ColorYellow, invalid, ColorReset)
+		reply.error(jsonrpc.CodeInvalidParams, "Invalid params", invalid)
+		return
+	}
+	var params MessageSendParams
+	if err := call.DecodeParams(&params); err != nil {
+		logger.Error("❌ %sFailed to parse MessageSendParams: %v%s", ColorRed, err, ColorReset)
+		reply.fail(err)
 		return
 	}
-	logger.Debug("✅ %sParsed request: %+v%s", ColorGreen, requestSendMessage, ColorReset)
+	logger.Debug("✅ %sParsed params: %+v%s", ColorGreen, params, ColorReset)
 
 	// Parse chess request from message
 	var chessReq ChessRequest
-	if err := parseChessRequestFromJSONRPCMessage(requestSendMessage.Params.Message, &chessReq); err != nil {
-		sendJSONRPCError(w, -32602, "Invalid params", fmt.Sprintf("Failed to parse chess request: %v", err), requestID)
+	field, err := parseChessRequestFromJSONRPCMessage(params.Message, &chessReq)
+	if err != nil {
+		logger.Warn("⚠️ %sInvalid chess request: %v%s", ColorYellow, err, ColorReset)
+		reply.error(jsonrpc.CodeInvalidParams, "Invalid params", err)
 		return
 	}
 
-	// Process chess request
-	result, err := processChessRequest(chessReq, aiPlayer, logger)
-	if err != nil {
-		sendJSONRPCError(w, -32603, "Internal error", fmt.Sprintf("Chess processing failed: %v", err), requestID)
+	// Pick up where the session left off, for clients that send only their context ID
+	contextID := ""
+	if params.Message.ContextId != nil {
+		contextID = *params.Message.ContextId
+	}
+	if contextID != "" {
+		logger = logger.WithGame(contextID)
+	}
+	if sessions != nil && contextID != "" {
+		if sessions.Kicked(contextID) {
+			logger.Warn("⚠️ %sRefused request for kicked session %s%s", ColorYellow, contextID, ColorReset)
+			reply.error(ErrCodeSessionEnded, "Session ended", "the server's operator ended this game")
+			return
+		}
+		if session, ok := sessions.Get(contextID); ok {
+			session.restore(&chessReq)
+		}
+	}
+
+	if chessReq.BoardState == "" {
+		chessReq.BoardState = chessReq.FEN
+	}
+	if chessReq.Variant != "" {
+		// A variant's FEN and moves are its own, so it checks the board itself
+		if invalid := validateVariantRequest(chessReq, field); invalid != nil {
+			logger.Warn("⚠️ %sInvalid variant request: %v%s", ColorYellow, invalid, ColorReset)
+			reply.error(jsonrpc.CodeInvalidParams, "Invalid params", invalid)
+			return
+		}
+	} else {
+		if invalid := normalizeBoard(&chessReq, boardFormat(params), field); invalid != nil {
+			logger.Warn("⚠️ %sInvalid chess request: %v%s", ColorYellow, invalid, ColorReset)
+			reply.error(jsonrpc.CodeInvalidParams, "Invalid params", invalid)
+			return
+		}
+		if invalid := validateChessRequest(chessReq, field); invalid != nil {
+			logger.Warn("⚠️ %sInvalid chess request: %v%s", ColorYellow, invalid, ColorReset)
+			reply.error(jsonrpc.CodeInvalidParams, "Invalid params", invalid)
+			return
+		}
+
+		// Refuse to reason about a board the client's history doesn't lead to
+		if chessReq.FEN != "" {
+			if desync := checkBoardState(chessReq); desync != nil {
+				logger.Warn("⚠️ %s%v%s", ColorYellow, desync, ColorReset)
+				reply.error(ErrCodeDesync, "Board desync", desync)
+				return
+			}
+		}
+	}
+
+	// Reply in the output modes the client accepts
+	config := params.Configuration
+	if config != nil {
+		modes, err := negotiateOutputModes(config.AcceptedOutputModes)
+		if err != nil {
+			reply.error(ErrCodeContentTypeNotSupported, "Incompatible content types", err.Error())
+			return
+		}
+		reply.outputModes = modes
+	}
+
+	// Register the client's callback, and push the outcome to it
+	taskID := params.Message.MessageId
+	reply.pushes, reply.taskID, reply.contextID = pushes, taskID, contextID
+	if config != nil && config.PushNotificationConfig != nil {
+		if _, err := pushes.set(taskID, *config.PushNotificationConfig); err != nil {
+			reply.error(jsonrpc.CodeInvalidParams, "Invalid params", err.Error())
+			return
+		}
+	}
+
+	// A client that won't wait gets the submitted task now and the move by push
+	if config != nil && config.Blocking != nil && !*config.Blocking && !reply.stream && len(pushes.list(taskID)) > 0 {
+		logger.Info("📬 %sAnswering task %s by push notification%s", ColorCyan, taskID, ColorReset)
+		reply.task(TaskStateSubmitted)
+		detached := &jsonrpcReply{id: reply.id, outputModes: reply.outputModes, pushes: pushes, taskID: taskID, contextID: contextID, detached: true}
+		go answerChessRequest(context.WithoutCancel(call.Context()), detached, chessReq, aiPlayer, sessions, moves, workers, metrics, logger)
 		return
 	}
+	answerChessRequest(call.Context(), reply, chessReq, aiPlayer, sessions, moves, workers, metrics, logger)
+}
 
-	// Create A2A message response
-	responseMessage := Message{
-		Kind:      "message",
-		MessageId: fmt.Sprintf("msg_%d", time.Now().Unix()),
-		Role:      MessageRoleAgent,
-		Parts: []MessagePartsElem{
+// answerChessRequest asks the AI for a move, or candidate moves in teach
+// mode, and replies with it
+func answerChessRequest(ctx context.Context, reply *jsonrpcReply, chessReq ChessRequest, aiPlayer *AIPlayer, sessions *SessionStore, moves *moveCache, workers *workerPool, metrics *serverMetrics, logger *ColoredLogger) {
+	// Wait for the AI to be free, telling streaming clients where they are in the queue
+	taskID, contextID := reply.taskID, reply.contextID
+	withWorker := func(fn func() error) error {
+		release, err := workers.acquirePriority(ctx, chessReq.Priority == PriorityLow, func(position int) {
+			logger.Info("⏳ %sRequest %s queued at position %d%s", ColorYellow, taskID, position, ColorReset)
+			reply.status(taskID, contextID, TaskStateSubmitted, map[string]interface{}{"queue_position": position})
+		})
+		if err != nil {
+			metrics.record(0, err)
+			return fmt.Errorf("gave up waiting for the AI: %w", err)
+		}
+		defer release()
+		reply.status(taskID, contextID, TaskStateWorking, nil)
+		started := time.Now()
+		err = fn()
+		metrics.record(time.Since(started), err)
+		return err
+	}
+
+	// Teach mode asks for candidate moves rather than a move
+	if chessReq.Task == TaskSuggest {
+		var candidates []CandidateMove
+		err := withWorker(func() (err error) {
+			candidates, err = processSuggestRequest(chessReq, aiPlayer, logger)
+			return err
+		})
+		if err != nil {
+			reply.fail(fmt.Errorf("Suggestion failed: %w", err))
+			return
+		}
+
+		moves := make([]string, len(candidates))
+		for i, candidate := range candidates {
+			moves[i] = candidate.Move
+		}
+		reply.message([]MessagePartsElem{
 			TextPart{
 				Kind: "text",
-				Text: fmt.Sprintf("Generated move: %s", result.Move),
+				Text: fmt.Sprintf("Candidate moves: %s", strings.Join(moves, ", ")),
 			},
-		},
+			DataPart{
+				Kind: "data",
+				Data: map[string]interface{}{
+					"candidates": candidates,
+				},
+			},
+		})
+		return
 	}
 
-	// Create A2A success response
-	response := SendMessageSuccessResponse{
-		Jsonrpc: "2.0",
-		Id:      requestID,
-		Result: SendMessageSuccessResponseResult{
-			Kind:      "message",
-			MessageId: responseMessage.MessageId,
-			Role:      responseMessage.Role,
-			Parts:     responseMessage.Parts,
-		},
+	// Review mode judges another agent's move rather than choosing one
+	if chessReq.Task == TaskReview {
+		var review *Review
+		err := withWorker(func() (err error) {
+			review, err = processReviewRequest(chessReq, aiPlayer, logger)
+			return err
+		})
+		if err != nil {
+			reply.fail(fmt.Errorf("Review failed: %w", err))
+			return
+		}
+
+		verdict := "Approved"
+		if !review.Approved {
+			verdict = "Vetoed"
+		}
+		reply.message([]MessagePartsElem{
+			TextPart{
+				Kind: "text",
+				Text: fmt.Sprintf("%s %s: %s", verdict, chessReq.Move, review.Reason),
+			},
+			DataPart{
+				Kind: "data",
+				Data: map[string]interface{}{
+					"approved": review.Approved,
+					"reason":   review.Reason,
+				},
+			},
+		})
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	// A variant's moves come from its own rules
+	if chessReq.Variant != "" {
+		var result *ChessResponse
+		err := withWorker(func() (err error) {
+			result, err = processVariantRequest(chessReq, aiPlayer, logger)
+			return err
+		})
+		if err != nil {
+			reply.fail(fmt.Errorf("Chess processing failed: %w", err))
+			return
+		}
+		reply.message([]MessagePartsElem{
+			TextPart{Kind: "text", Text: fmt.Sprintf("Generated move: %s", result.Move)},
+			DataPart{Kind: "data", Data: map[string]interface{}{
+				"move":              result.Move,
+				"reasoning":         result.Reasoning,
+				"prompt_tokens":     result.PromptTokens,
+				"completion_tokens": result.CompletionTokens,
+				"provider":          result.Provider,
+				"variant":           chessReq.Variant,
+			}},
+		})
+		return
+	}
+
+	// Resigning or taking a draw needs no move, for clients that handle them
+	decision := aiPlayer.Decide(chessReq.BoardState, chessReq.Actions, chessReq.DrawOffered)
+	if decision.Action == ActionResign || decision.Action == ActionAcceptDraw {
+		text := "Resigns: " + decision.Reason
+		if decision.Action == ActionAcceptDraw {
+			text = "Accepts the draw: " + decision.Reason
+		}
+		logger.Info("🏳️ %s%s%s", ColorYellow, text, ColorReset)
+		reply.message([]MessagePartsElem{
+			TextPart{Kind: "text", Text: text},
+			DataPart{Kind: "data", Data: map[string]interface{}{
+				"action": decision.Action,
+				"reason": decision.Reason,
+				"eval":   decision.Eval,
+			}},
+		})
+		return
+	}
+
+	// Process chess request, replaying the earlier reply to a retried one
+	result, cached, err := moves.do(chessReq, func() (result *ChessResponse, err error) {
+		err = withWorker(func() error {
+			result, err = processChessRequest(chessReq, aiPlayer, logger)
+			return err
+		})
+		return result, err
+	})
+	if err != nil {
+		reply.fail(fmt.Errorf("Chess processing failed: %w", err))
+		return
+	}
+	if cached {
+		logger.Info("♻️ %sReplaying cached move %s for request %s%s", ColorCyan, result.Move, chessReq.IdempotencyKey, ColorReset)
+	}
+
+	if sessions != nil && contextID != "" && !cached {
+		if session, ok := sessionAfterMove(chessReq, result.Move, aiPlayer); ok {
+			if err := sessions.Put(contextID, session); err != nil {
+				logger.Warn("⚠️ %sFailed to save session %s: %v%s", ColorYellow, contextID, err, ColorReset)
+			}
+		}
+	}
+
+	data := map[string]interface{}{
+		"move":              result.Move,
+		"reasoning":         result.Reasoning,
+		"prompt_tokens":     result.PromptTokens,
+		"completion_tokens": result.CompletionTokens,
+		"provider":          result.Provider,
+		"vetoes":            result.Vetoes,
+		"votes":             result.Votes,
+		"confidence":        result.Confidence,
+		"action":            decision.Action,
+	}
+	if result.Spare {
+		data["spare"] = true
+	}
+	if decision.Action == ActionOfferDraw {
+		logger.Info("🤝 %sOffering a draw with %s: %s%s", ColorCyan, result.Move, decision.Reason, ColorReset)
+		data["reason"] = decision.Reason
+	}
+	// Say so when the move ends the game; the text stays the bare move,
+	// which older clients parse
+	if outcome := moveOutcome(chessReq.BoardState, result.Move); outcome != nil {
+		logger.Info("🏁 %sMove %s ends the game: %s (%s)%s", ColorGreen, result.Move, outcome.Result, outcome.Reason, ColorReset)
+		data["outcome"] = outcome
+	}
+	reply.message([]MessagePartsElem{
+		TextPart{Kind: "text", Text: fmt.Sprintf("Generated move: %s", result.Move)},
+		DataPart{Kind: "data", Data: data},
+	})
+}
+
+// agentMessage builds the agent message a reply carries
+func agentMessage(parts []MessagePartsElem) SendMessageSuccessResponseResult {
+	return SendMessageSuccessResponseResult{
+		Kind:      "message",
+		MessageId: fmt.Sprintf("msg_%d", time.Now().Unix()),
+		Role:      MessageRoleAgent,
+		Parts:     parts,
+	}
 }
 
 // handleJSONRPCTasksSend handles the A2A tasks/send method
-func handleJSONRPCTasksSend(w http.ResponseWriter, r *http.Request, rawRequest map[string]interface{}, aiPlayer *AIPlayer, logger *ColoredLogger) {
+func handleJSONRPCTasksSend(call *jsonrpc.Call, aiPlayer *AIPlayer, sessions *SessionStore, moves *moveCache, workers *workerPool, pushes *pushRegistry, metrics *serverMetrics, logger *ColoredLogger) (interface{}, error) {
 	logger.Info("📋 %sReceived A2A tasks/send request%s", ColorPurple, ColorReset)
 
 	// For now, we'll handle this the same as message/send
 	// In a full implementation, this would create a task and return task status
-	handleJSONRPCMessageSend(w, r, rawRequest, aiPlayer, logger)
+	reply := newJSONRPCReply(call, false)
+	handleJSONRPCMessageSend(reply, call, aiPlayer, sessions, moves, workers, pushes, metrics, logger)
+	return reply.outcome()
 }
 
-// parseChessRequestFromJSONRPCMessage parses chess request from JSON-RPC A2A message
-func parseChessRequestFromJSONRPCMessage(message Message, req *ChessRequest) error {
-	for _, part := range message.Parts {
+// handleJSONRPCSessionReplay handles the session/replay method, which
+// rebuilds a session from its whole move list at once instead of one
+// position per move request
+func handleJSONRPCSessionReplay(call *jsonrpc.Call, aiPlayer *AIPlayer, sessions *SessionStore, logger *ColoredLogger) (interface{}, error) {
+	var replay SessionReplay
+	if err := call.DecodeParams(&replay); err != nil {
+		return nil, err
+	}
+	if replay.ContextID == "" {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Invalid params", "contextId is required")
+	}
+	if sessions != nil && sessions.Kicked(replay.ContextID) {
+		return nil, jsonrpc.NewError(ErrCodeSessionEnded, "Session ended", "the server's operator ended this game")
+	}
+	logger = logger.WithGame(replay.ContextID)
+	logger.Info("🔁 %sReplaying %d moves%s", ColorBlue, len(replay.Moves), ColorReset)
+
+	session, desync := replaySession(replay, aiPlayer)
+	if desync != nil {
+		logger.Warn("⚠️ %s%v%s", ColorYellow, desync, ColorReset)
+		return nil, jsonrpc.NewError(ErrCodeDesync, "Board desync", desync)
+	}
+	if sessions != nil {
+		if err := sessions.Put(replay.ContextID, session); err != nil {
+			logger.Warn("⚠️ %sFailed to save session %s: %v%s", ColorYellow, replay.ContextID, err, ColorReset)
+		}
+	}
+	return SessionReplayResult{FEN: session.FEN, Ply: len(session.History), History: session.History}, nil
+}
+
+// handleJSONRPCSessionResume handles the session/resume method, which
+// reconstructs a session from a client's snapshot of its game and confirms
+// what the server now holds
+func handleJSONRPCSessionResume(call *jsonrpc.Call, aiPlayer *AIPlayer, sessions *SessionStore, logger *ColoredLogger) (interface{}, error) {
+	var snapshot SessionSnapshot
+	if err := call.DecodeParams(&snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.ContextID == "" {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Invalid params", "contextId is required")
+	}
+	if snapshot.FEN == "" {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Invalid params", "fen is required")
+	}
+	if sessions != nil && sessions.Kicked(snapshot.ContextID) {
+		return nil, jsonrpc.NewError(ErrCodeSessionEnded, "Session ended", "the server's operator ended this game")
+	}
+	logger = logger.WithGame(snapshot.ContextID)
+	logger.Info("⏯️ %sResuming game at ply %d%s", ColorBlue, len(snapshot.History), ColorReset)
+
+	session, desync := resumeSession(snapshot, aiPlayer)
+	if desync != nil {
+		logger.Warn("⚠️ %s%v%s", ColorYellow, desync, ColorReset)
+		return nil, jsonrpc.NewError(ErrCodeDesync, "Board desync", desync)
+	}
+	if snapshot.Model != "" && snapshot.Model != session.Model {
+		logger.Info("🔀 %sGame played by %s so far, %s takes over%s", ColorYellow, snapshot.Model, session.Model, ColorReset)
+	}
+
+	result := SessionResumeResult{
+		ContextID: snapshot.ContextID,
+		Resumed:   true,
+		FEN:       session.FEN,
+		Ply:       len(session.History),
+		History:   session.History,
+		Provider:  session.Provider,
+		Model:     session.Model,
+	}
+	if sessions != nil {
+		_, result.Replaced = sessions.Get(snapshot.ContextID)
+		if err := sessions.Put(snapshot.ContextID, session); err != nil {
+			logger.Warn("⚠️ %sFailed to save session %s: %v%s", ColorYellow, snapshot.ContextID, err, ColorReset)
+		}
+	}
+	return result, nil
+}
+
+// parseChessRequestFromJSONRPCMessage parses chess request from JSON-RPC A2A
+// message: the first text part, or else the first data part. It returns the
+// field the request was read from, for validation errors.
+func parseChessRequestFromJSONRPCMessage(message Message, req *ChessRequest) (string, error) {
+	for i, part := range message.Parts {
 		// Try to convert to TextPart
 		partBytes, _ := json.Marshal(part)
 		var textPart TextPart
 		if err := json.Unmarshal(partBytes, &textPart); err == nil && textPart.Kind == "text" {
-			// Try to parse as JSON first
-			if err := json.Unmarshal([]byte(textPart.Text), req); err == nil {
-				return nil
+			field := fmt.Sprintf("params.message.parts[%d].text", i)
+			text := strings.TrimSpace(textPart.Text)
+
+			// A JSON object must be a chess request; anything else is a board state
+			if strings.HasPrefix(text, "{") {
+				if err := json.Unmarshal([]byte(text), req); err != nil {
+					return field, chessPayloadError(field, err)
+				}
+				return field, nil
 			}
-
-			// If not JSON, try to parse as simple board state
-			req.BoardState = strings.TrimSpace(textPart.Text)
-			return nil
+			req.BoardState = text
+			return field, nil
 		}
 	}
 
-	return fmt.Errorf("no text part found in message")
-}
-
-// sendJSONRPCError sends a JSON-RPC error response
-func sendJSONRPCError(w http.ResponseWriter, code int, message, data string, id interface{}) {
-	response := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"error": map[string]interface{}{
-			"code":    code,
-			"message": message,
-			"data":    data,
-		},
-		"id": id,
+	// Clients sending application/json put the request in a data part
+	for i, part := range message.Parts {
+		partBytes, _ := json.Marshal(part)
+		var dataPart struct {
+			Kind string          `json:"kind"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(partBytes, &dataPart); err == nil && dataPart.Kind == "data" {
+			field := fmt.Sprintf("params.message.parts[%d].data", i)
+			if err := json.Unmarshal(dataPart.Data, req); err != nil {
+				return field, chessPayloadError(field, err)
+			}
+			return field, nil
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return "params.message.parts", invalidField("params.message.parts", "needs a text part holding the chess request as JSON or a board, or a data part holding it as an object")
 }
 
 // processChessRequest processes a chess request and returns a move
@@ -278,6 +753,15 @@ func processChessRequest(req ChessRequest, aiPlayer *AIPlayer, logger *ColoredLo
 	aiPlayer.Color = req.PlayerColor
 	logger.Info("🎨 %sAI player color set to: %s%s", ColorPurple, aiPlayer.Color, ColorReset)
 
+	// Apply the game's personality preset, if any
+	aiPlayer.Personality = req.Personality
+	if req.Personality != "" {
+		logger.Info("🎭 %sAI personality: %s%s", ColorPurple, req.Personality, ColorReset)
+	}
+
+	// A bullet game gets the fast path even if the server's config is slow
+	aiPlayer.Bullet = req.Bullet || aiPlayer.currentConfig().Bullet
+
 	// Log board state for debugging
 	logger.Debug("📊 %sBoard state: %s%s", ColorCyan, req.BoardState, ColorReset)
 	if len(req.GameHistory) > 0 {
@@ -307,7 +791,8 @@ func processChessRequest(req ChessRequest, aiPlayer *AIPlayer, logger *ColoredLo
 		}
 	}()
 
-	aiMove, err := aiPlayer.GetMove(req.BoardState, req.GameHistory)
+	// The reviewer, if there is one, may send the move back for another
+	aiMove, vetoes, err := reviewedMove(req, aiPlayer, logger)
 	cancelProgress() // Stop progress logging
 
 	elapsed := time.Since(startTime)
@@ -320,15 +805,86 @@ func processChessRequest(req ChessRequest, aiPlayer *AIPlayer, logger *ColoredLo
 	logger.Info("✅ %sAI move generated successfully in %v: %s%s", ColorGreen, elapsed, aiMove.Notation, ColorReset)
 
 	return &ChessResponse{
-		Move: aiMove.Notation,
+		Move:             aiMove.Notation,
+		Reasoning:        aiMove.Reasoning,
+		PromptTokens:     aiMove.PromptTokens,
+		CompletionTokens: aiMove.CompletionTokens,
+		Provider:         aiMove.Provider,
+		Vetoes:           vetoes,
+		Votes:            aiMove.Votes,
+		Confidence:       aiMove.Confidence,
+		Spare:            aiMove.Spare,
+	}, nil
+}
+
+// processSuggestRequest asks the AI for candidate moves for the human in teach mode
+func processSuggestRequest(req ChessRequest, aiPlayer *AIPlayer, logger *ColoredLogger) ([]CandidateMove, error) {
+	logger.Info("🎓 %sProcessing teach request - Player: %s%s", ColorBlue, req.PlayerColor, ColorReset)
+
+	aiPlayer.Color = req.PlayerColor
+	candidates, err := aiPlayer.SuggestMoves(req.BoardState, req.GameHistory)
+	if err != nil {
+		logger.Error("❌ %sCandidate move generation failed: %v%s", ColorRed, err, ColorReset)
+		return nil, fmt.Errorf("candidate move generation failed: %w", err)
+	}
+
+	logger.Info("✅ %sSuggested %d candidate moves%s", ColorGreen, len(candidates), ColorReset)
+	return candidates, nil
+}
+
+// processReviewRequest asks the AI to approve or veto another agent's move
+func processReviewRequest(req ChessRequest, aiPlayer *AIPlayer, logger *ColoredLogger) (*Review, error) {
+	logger.Info("🧐 %sReviewing move %s for %s%s", ColorBlue, req.Move, req.PlayerColor, ColorReset)
+
+	aiPlayer.Color = req.PlayerColor
+	review, err := aiPlayer.ReviewMove(req.BoardState, req.GameHistory, req.Move)
+	if err != nil {
+		logger.Error("❌ %sMove review failed: %v%s", ColorRed, err, ColorReset)
+		return nil, fmt.Errorf("move review failed: %w", err)
+	}
+
+	logger.Info("✅ %sReview of %s: approved=%t%s", ColorGreen, req.Move, review.Approved, ColorReset)
+	return review, nil
+}
+
+// processVariantRequest asks the AI for a move in a chess variant
+func processVariantRequest(req ChessRequest, aiPlayer *AIPlayer, logger *ColoredLogger) (*ChessResponse, error) {
+	logger.Info("🎲 %sProcessing %s request - Player: %s%s", ColorBlue, req.Variant, req.PlayerColor, ColorReset)
+
+	v, err := variant.Lookup(req.Variant)
+	if err != nil {
+		return nil, errkind.Wrap(errkind.ProtocolError, err)
+	}
+	aiPlayer.Color = req.PlayerColor
+	move, err := aiPlayer.VariantMove(v, req.BoardState, req.GameHistory)
+	if err != nil {
+		logger.Error("❌ %s%s move generation failed: %v%s", ColorRed, v.Name(), err, ColorReset)
+		return nil, fmt.Errorf("AI move generation failed: %w", err)
+	}
+
+	logger.Info("✅ %s%s move generated: %s%s", ColorGreen, v.Name(), move.Notation, ColorReset)
+	return &ChessResponse{
+		Move:             move.Notation,
+		Reasoning:        move.Reasoning,
+		PromptTokens:     move.PromptTokens,
+		CompletionTokens: move.CompletionTokens,
+		Provider:         move.Provider,
 	}, nil
 }
 
 // StartJSONRPCA2AServer starts the JSON-RPC A2A server
 func StartJSONRPCA2AServer(ollamaURL, model string, port int) error {
+	config := DefaultConfig()
+	config.OllamaURL = ollamaURL
+	config.Model = model
+	return StartJSONRPCA2AServerWithConfig(config, port)
+}
+
+// StartJSONRPCA2AServerWithConfig starts the JSON-RPC A2A server using the given configuration
+func StartJSONRPCA2AServerWithConfig(config *Config, port int) error {
 	logger := NewA2ALogger()
 
-	server, err := NewJSONRPCA2AServer(ollamaURL, model, port, logger)
+	server, err := NewJSONRPCA2AServerWithConfig(config, port, logger)
 	if err != nil {
 		return fmt.Errorf("failed to create JSON-RPC A2A server: %w", err)
 	}
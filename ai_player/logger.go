@@ -35,6 +35,7 @@ const (
 type ColoredLogger struct {
 	level  LogLevel
 	logger *log.Logger
+	game   string // the game the lines are about, if any
 }
 
 // NewColoredLogger creates a new colored logger
@@ -45,6 +46,14 @@ func NewColoredLogger(level LogLevel) *ColoredLogger {
 	}
 }
 
+// WithGame returns a logger that tags its lines with the ID of the game
+// they are about, to follow one game among concurrent ones
+func (cl *ColoredLogger) WithGame(id string) *ColoredLogger {
+	tagged := *cl
+	tagged.game = id
+	return &tagged
+}
+
 // formatTime returns a shorter timestamp format
 func (cl *ColoredLogger) formatTime() string {
 	return time.Now().Format("15:04:05")
@@ -95,6 +104,9 @@ func (cl *ColoredLogger) log(level LogLevel, format string, args ...interface{})
 
 	// Format the message
 	message := fmt.Sprintf(format, args...)
+	if cl.game != "" {
+		message = "[" + cl.game + "] " + message
+	}
 
 	// Create the final log line with color
 	logLine := fmt.Sprintf("%s%s %s%s %s%s",
@@ -132,7 +144,7 @@ func (cl *ColoredLogger) Printf(format string, args ...interface{}) {
 
 // Print logs a message at info level (for compatibility with log.Logger)
 func (cl *ColoredLogger) Print(v ...interface{}) {
-	cl.Info(fmt.Sprint(v...))
+	cl.Info("%s", fmt.Sprint(v...))
 }
 
 // SetOutput sets the output destination (for compatibility with log.Logger)
@@ -0,0 +1,199 @@
+package ai_player
+
+import (
+	"fmt"
+	"strings"
+
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// DefaultPromptTokenBudget is how many tokens a chat provider's move prompt
+// may take when the config doesn't say
+const DefaultPromptTokenBudget = 2000
+
+// minRecentPlies is how many of the latest moves are always written out in
+// full, however small the budget
+const minRecentPlies = 6
+
+// estimateTokens approximates the tokens of a text at about four characters
+// a token, which is close enough for English and move lists
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// chatProvider reports whether the player's provider is a chat model that
+// is sent the whole game rather than its last few moves
+func (ai *AIPlayer) chatProvider() bool {
+	switch ai.Provider.(type) {
+	case *OpenAIProvider, *AnthropicProvider:
+		return true
+	}
+	return false
+}
+
+// promptTokenBudget returns the token budget for move prompts
+func (ai *AIPlayer) promptTokenBudget() int {
+	if ai.PromptTokenBudget > 0 {
+		return ai.PromptTokenBudget
+	}
+	return DefaultPromptTokenBudget
+}
+
+// gameRecord writes the game for a chat provider's prompt in at most budget
+// tokens: every move when they fit, or else a summary of the early moves
+// followed by as many of the latest moves as fit
+func gameRecord(gameHistory []string, budget int) (record string, summarized int) {
+	full := "Game so far:\n" + movetext(gameHistory, 0) + "\n\n"
+	if estimateTokens(full) <= budget {
+		return full, 0
+	}
+
+	// Keep a quarter of the budget for the summary, and whole move pairs
+	recentBudget := budget - budget/4
+	keep := minRecentPlies
+	for keep+2 <= len(gameHistory) && estimateTokens(movetext(gameHistory, len(gameHistory)-keep-2)) <= recentBudget {
+		keep += 2
+	}
+	if keep >= len(gameHistory) {
+		return full, 0
+	}
+
+	// Give back moves while the summary pushes the record over the budget
+	for {
+		split := len(gameHistory) - keep
+		record := summarizedRecord(gameHistory, split)
+		if estimateTokens(record) <= budget || keep <= minRecentPlies {
+			return record, split
+		}
+		keep -= 2
+	}
+}
+
+// summarizedRecord writes a summary of the moves before ply split and the
+// moves from it on in full
+func summarizedRecord(gameHistory []string, split int) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Summary of moves 1-%d:\n", (split+1)/2))
+	b.WriteString(summarizeMoves(gameHistory[:split]))
+	b.WriteString("\n\nRecent moves:\n")
+	b.WriteString(movetext(gameHistory, split))
+	b.WriteString("\n\n")
+	return b.String()
+}
+
+// movetext numbers the moves of a game from ply from on, e.g.
+// "21. Nf3 Nc6 22. Bb5", assuming White moved first
+func movetext(gameHistory []string, from int) string {
+	var b strings.Builder
+	for ply := from; ply < len(gameHistory); ply++ {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		switch {
+		case ply%2 == 0:
+			b.WriteString(fmt.Sprintf("%d. ", ply/2+1))
+		case ply == from:
+			b.WriteString(fmt.Sprintf("%d... ", ply/2+1))
+		}
+		b.WriteString(gameHistory[ply])
+	}
+	return b.String()
+}
+
+// summarizeMoves condenses the opening part of a game into what matters
+// later: how each side castled, the captures and promotions, how often each
+// side gave check and the material left. The material is only known when
+// the moves replay from the standard starting position.
+func summarizeMoves(moves []string) string {
+	var lines []string
+	castled := map[string]string{}
+	var captures, promotions []string
+	checks := map[string]int{}
+
+	for ply, move := range moves {
+		side := "White"
+		if ply%2 == 1 {
+			side = "Black"
+		}
+		label := fmt.Sprintf("%d.", ply/2+1)
+		if side == "Black" {
+			label += ".."
+		}
+		switch {
+		case strings.HasPrefix(move, "O-O-O"):
+			castled[side] = fmt.Sprintf("castled queenside on move %d", ply/2+1)
+		case strings.HasPrefix(move, "O-O"):
+			castled[side] = fmt.Sprintf("castled kingside on move %d", ply/2+1)
+		}
+		if strings.Contains(move, "x") {
+			captures = append(captures, label+" "+move)
+		}
+		if strings.Contains(move, "=") {
+			promotions = append(promotions, label+" "+move)
+		}
+		if strings.ContainsAny(move, "+#") {
+			checks[side]++
+		}
+	}
+
+	for _, side := range []string{"White", "Black"} {
+		if how, ok := castled[side]; ok {
+			lines = append(lines, fmt.Sprintf("- %s %s", side, how))
+		} else {
+			lines = append(lines, fmt.Sprintf("- %s has not castled", side))
+		}
+	}
+	if len(captures) > 0 {
+		lines = append(lines, "- Captures: "+strings.Join(captures, ", "))
+	}
+	if len(promotions) > 0 {
+		lines = append(lines, "- Promotions: "+strings.Join(promotions, ", "))
+	}
+	if checks["White"]+checks["Black"] > 0 {
+		lines = append(lines, fmt.Sprintf("- Checks given: White %d, Black %d", checks["White"], checks["Black"]))
+	}
+	if material, ok := materialAfter(moves); ok {
+		lines = append(lines, "- Material then: "+material)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// materialAfter replays moves from the starting position and describes the
+// material each side has left, or reports false if they don't replay
+func materialAfter(moves []string) (string, bool) {
+	game := chess.NewGame()
+	for _, move := range moves {
+		decoded, err := notation.Decode(game.Position(), move)
+		if err != nil {
+			return "", false
+		}
+		if err := game.Move(decoded); err != nil {
+			return "", false
+		}
+	}
+
+	position := game.Position()
+	describe := func(color chess.Color) string {
+		counts := map[chess.PieceType]int{}
+		for _, piece := range position.Board().SquareMap() {
+			if piece.Color() == color {
+				counts[piece.Type()]++
+			}
+		}
+		var parts []string
+		for _, piece := range []struct {
+			kind chess.PieceType
+			name string
+		}{{chess.Queen, "Q"}, {chess.Rook, "R"}, {chess.Bishop, "B"}, {chess.Knight, "N"}, {chess.Pawn, "P"}} {
+			if n := counts[piece.kind]; n > 0 {
+				parts = append(parts, fmt.Sprintf("%d%s", n, piece.name))
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+
+	balance := MaterialBalance(position, chess.White)
+	return fmt.Sprintf("White %s, Black %s (White %+d centipawns)", describe(chess.White), describe(chess.Black), balance), true
+}
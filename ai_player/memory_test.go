@@ -0,0 +1,73 @@
+package ai_player
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChatPromptSummarizesLongGames(t *testing.T) {
+	// A 65-move game of knights shuffling back and forth
+	var history []string
+	for len(history) < 130 {
+		history = append(history, "Nf3", "Nf6", "Ng1", "Ng8")
+	}
+	history = append(history[:128], "d4", "d5")
+	fen := "rnbqkbnr/ppp1pppp/8/3p4/3P4/8/PPP1PPPP/RNBQKBNR w KQkq - 0 66"
+	player := &AIPlayer{Provider: NewOpenAIProvider("", "", "m", quietLogger()), Logger: quietLogger(), Color: "white", PromptTokenBudget: 350}
+
+	prompt := player.Prompt(fen, history, "")
+	if tokens := estimateTokens(prompt); tokens > 350 {
+		t.Errorf("Expected the prompt under 350 tokens, got %d", tokens)
+	}
+	if !strings.Contains(prompt, "Summary of moves 1-") || !strings.Contains(prompt, "Recent moves:") {
+		t.Errorf("Expected the early moves summarized, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "castled") && !strings.Contains(prompt, "has not castled") {
+		t.Errorf("Expected the summary to say how each side castled, got:\n%s", prompt)
+	}
+	if last := history[len(history)-1]; !strings.Contains(prompt, last) {
+		t.Errorf("Expected the latest move %s written out, got:\n%s", last, prompt)
+	}
+
+	// A short game fits whole
+	prompt = player.Prompt(startFEN, []string{"e4", "e5", "Nf3"}, "")
+	if !strings.Contains(prompt, "Game so far:\n1. e4 e5 2. Nf3") || strings.Contains(prompt, "Summary") {
+		t.Errorf("Expected the whole short game, got:\n%s", prompt)
+	}
+
+	// Other providers keep their last few moves
+	player.Provider = nil
+	if prompt := player.Prompt(fen, history, ""); strings.Contains(prompt, "Game so far") || strings.Contains(prompt, "Summary") {
+		t.Errorf("Expected no game record for Ollama, got:\n%s", prompt)
+	}
+}
+
+func TestSummarizeMoves(t *testing.T) {
+	moves := []string{"e4", "d5", "exd5", "Qxd5", "Nc3", "Qa5", "Nf3", "Nf6", "Bc4", "Bg4", "O-O", "e6", "d3", "Bb4", "Bd2", "Nc6", "Re1", "O-O-O", "Bb5", "Qxb5"}
+	summary := summarizeMoves(moves)
+	for _, want := range []string{
+		"White castled kingside on move 6",
+		"Black castled queenside on move 9",
+		"Captures: 2. exd5, 2... Qxd5, 10... Qxb5",
+		"White 1Q 2R 1B 2N 7P, Black 1Q 2R 2B 2N 7P (White -330 centipawns)",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected %q in the summary, got:\n%s", want, summary)
+		}
+	}
+
+	// Moves that don't replay from the start still get a summary, without material
+	if summary := summarizeMoves([]string{"Kh1", "Rxa2+"}); strings.Contains(summary, "Material") || !strings.Contains(summary, "Checks given: White 0, Black 1") {
+		t.Errorf("Expected a summary without material, got:\n%s", summary)
+	}
+}
+
+func TestMovetext(t *testing.T) {
+	moves := []string{"e4", "e5", "Nf3", "Nc6", "Bb5"}
+	if got := movetext(moves, 0); got != "1. e4 e5 2. Nf3 Nc6 3. Bb5" {
+		t.Errorf("Expected numbered moves, got %q", got)
+	}
+	if got := movetext(moves, 3); got != "2... Nc6 3. Bb5" {
+		t.Errorf("Expected to start at Black's move, got %q", got)
+	}
+}
@@ -0,0 +1,89 @@
+package ai_player
+
+import (
+	"sync"
+	"time"
+)
+
+// activeSessionWindow is how recently a session must have moved to count as active
+const activeSessionWindow = 30 * time.Minute
+
+// AdminMetrics are the live figures reported by /admin/metrics
+type AdminMetrics struct {
+	Provider         string `json:"provider"`
+	Model            string `json:"model"`
+	UptimeSeconds    int64  `json:"uptime_s"`
+	Requests         int64  `json:"requests"` // moves, suggestions and reviews answered or failed
+	Errors           int64  `json:"errors"`
+	AverageLatencyMs int64  `json:"average_latency_ms"` // of the answered requests
+	LastLatencyMs    int64  `json:"last_latency_ms"`
+	QueueLength      int    `json:"queue_length"` // requests waiting for the AI
+	Sessions         int    `json:"sessions"`
+	ActiveSessions   int    `json:"active_sessions"` // sessions that moved in the last 30 minutes
+}
+
+// serverMetrics counts the requests the server answers, for /admin/metrics
+type serverMetrics struct {
+	started time.Time
+
+	mu       sync.Mutex
+	requests int64
+	errors   int64
+	latency  time.Duration // total over the answered requests
+	last     time.Duration
+	workers  *workerPool // set once the A2A endpoint is created
+}
+
+// newServerMetrics starts counting from now
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{started: time.Now()}
+}
+
+// record counts a request that took elapsed, and failed if err is set
+func (m *serverMetrics) record(elapsed time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests++
+	if err != nil {
+		m.errors++
+		return
+	}
+	m.latency += elapsed
+	m.last = elapsed
+}
+
+// snapshot returns the figures as of now
+func (m *serverMetrics) snapshot(aiPlayer *AIPlayer, sessions *SessionStore) AdminMetrics {
+	m.mu.Lock()
+	metrics := AdminMetrics{
+		UptimeSeconds: int64(time.Since(m.started).Seconds()),
+		Requests:      m.requests,
+		Errors:        m.errors,
+		LastLatencyMs: m.last.Milliseconds(),
+	}
+	if answered := m.requests - m.errors; answered > 0 {
+		metrics.AverageLatencyMs = (m.latency / time.Duration(answered)).Milliseconds()
+	}
+	workers := m.workers
+	m.mu.Unlock()
+
+	if workers != nil {
+		metrics.QueueLength = workers.queueLength()
+	}
+	if aiPlayer != nil {
+		metrics.Provider = aiPlayer.ProviderName()
+		metrics.Model = aiPlayer.Config().Model
+	}
+	if sessions != nil {
+		for _, session := range sessions.List() {
+			metrics.Sessions++
+			if time.Since(session.Updated) < activeSessionWindow {
+				metrics.ActiveSessions++
+			}
+		}
+	}
+	return metrics
+}
@@ -0,0 +1,172 @@
+package ai_player
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ModelNotFoundError reports a model Ollama hasn't pulled, with the models
+// it has, so the player can switch to one or pull the missing one instead
+// of retrying a request that can't succeed
+type ModelNotFoundError struct {
+	Model     string
+	OllamaURL string
+	Available []string // the models Ollama has, empty if none or unknown
+}
+
+func (e *ModelNotFoundError) Error() string {
+	message := fmt.Sprintf("model %q is not pulled in Ollama", e.Model)
+	if len(e.Available) > 0 {
+		message += "; available: " + strings.Join(e.Available, ", ")
+	} else {
+		message += "; no models are pulled"
+	}
+	return message + "; " + e.PullCommand()
+}
+
+// PullCommand returns the command that pulls the missing model
+func (e *ModelNotFoundError) PullCommand() string {
+	return fmt.Sprintf("run \"ollama pull %s\"", e.Model)
+}
+
+// tagsResponse is Ollama's /api/tags reply; Models is nil when the reply
+// doesn't list models at all
+type tagsResponse struct {
+	Models *[]struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+// ListModels returns the models Ollama has pulled, by name, e.g. "llama3.2:latest"
+func (ai *AIPlayer) ListModels() ([]string, error) {
+	resp, err := ai.Client.Get(ai.OllamaURL + "/api/tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama returned status %d", resp.StatusCode)
+	}
+	var tags tagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil || tags.Models == nil {
+		return nil, fmt.Errorf("failed to decode Ollama's models")
+	}
+	return tags.names(), nil
+}
+
+// names returns the names of the models listed
+func (t tagsResponse) names() []string {
+	names := make([]string, len(*t.Models))
+	for i, model := range *t.Models {
+		names[i] = model.Name
+	}
+	return names
+}
+
+// hasModel reports whether model is among the pulled models; a name
+// without a tag means its "latest" tag, as in Ollama
+func hasModel(models []string, model string) bool {
+	for _, name := range models {
+		if name == model || name == model+":latest" {
+			return true
+		}
+	}
+	return false
+}
+
+// isModelNotFound reports whether an Ollama error reply says the model
+// isn't pulled, e.g. 404 {"error":"model \"llama3\" not found, try pulling it first"}
+func isModelNotFound(status int, body []byte) bool {
+	return status == http.StatusNotFound && strings.Contains(string(body), "not found")
+}
+
+// modelNotFound builds the error for a model Ollama doesn't have, listing
+// the ones it does
+func (ai *AIPlayer) modelNotFound(model string) *ModelNotFoundError {
+	available, _ := ai.ListModels()
+	return &ModelNotFoundError{Model: model, OllamaURL: ai.OllamaURL, Available: available}
+}
+
+// PullProgress is one step of a model pull, as Ollama streams them:
+// "pulling manifest", then each layer downloading, then "success"
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`     // bytes of the layer downloading
+	Completed int64  `json:"completed,omitempty"` // bytes of it downloaded so far
+	Error     string `json:"error,omitempty"`
+}
+
+// Percent returns how much of the layer has downloaded, or -1 if the step
+// isn't a download
+func (p PullProgress) Percent() int {
+	if p.Total <= 0 {
+		return -1
+	}
+	return int(p.Completed * 100 / p.Total)
+}
+
+// PullModel has Ollama pull model, calling progress for each step it
+// streams. The pull isn't bound by the client's timeout for moves, only by ctx.
+func (ai *AIPlayer) PullModel(ctx context.Context, model string, progress func(PullProgress)) error {
+	body, err := json.Marshal(map[string]interface{}{"model": model, "name": model, "stream": true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", ai.OllamaURL+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Transport: ai.Client.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to pull %s: %w", model, err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	success := false
+	for scanner.Scan() {
+		var step PullProgress
+		if err := json.Unmarshal(scanner.Bytes(), &step); err != nil {
+			continue
+		}
+		if step.Error != "" {
+			return fmt.Errorf("failed to pull %s: %s", model, step.Error)
+		}
+		if progress != nil {
+			progress(step)
+		}
+		success = success || step.Status == "success"
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to pull %s: %w", model, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to pull %s: Ollama returned status %d", model, resp.StatusCode)
+	}
+	if !success {
+		return fmt.Errorf("failed to pull %s: the pull ended before it finished", model)
+	}
+	return nil
+}
+
+// EnsureModel pulls the player's model if Ollama doesn't have it yet,
+// reporting the pull's progress; pulled says whether it had to
+func (ai *AIPlayer) EnsureModel(ctx context.Context, progress func(PullProgress)) (pulled bool, err error) {
+	models, err := ai.ListModels()
+	if err != nil {
+		return false, err
+	}
+	if hasModel(models, ai.Model) {
+		return false, nil
+	}
+	return true, ai.PullModel(ctx, ai.Model, progress)
+}
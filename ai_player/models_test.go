@@ -0,0 +1,113 @@
+package ai_player
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ollamaWithModels fakes an Ollama server that has pulled only models
+func ollamaWithModels(t *testing.T, models ...string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			var entries []string
+			for _, model := range models {
+				entries = append(entries, `{"name":"`+model+`"}`)
+			}
+			w.Write([]byte(`{"models":[` + strings.Join(entries, ",") + `]}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model \"llama3\" not found, try pulling it first"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestModelNotFound(t *testing.T) {
+	server := ollamaWithModels(t, "qwen2.5:7b", "phi3:latest")
+	player := NewAIPlayer(server.URL, "llama3", "white", quietLogger())
+
+	var missing *ModelNotFoundError
+	if err := player.TestConnection(); !errors.As(err, &missing) {
+		t.Fatalf("Expected the connection test to find the model missing, got %v", err)
+	}
+	if missing.Model != "llama3" || len(missing.Available) != 2 || missing.Available[0] != "qwen2.5:7b" {
+		t.Errorf("Expected llama3 missing and the 2 pulled models, got %+v", missing)
+	}
+	if !strings.Contains(missing.Error(), `ollama pull llama3`) || !strings.Contains(missing.Error(), "phi3:latest") {
+		t.Errorf("Expected the error to suggest the pull and list the models, got %q", missing.Error())
+	}
+
+	_, err := player.GetMove(startFEN, nil)
+	if !errors.As(err, &missing) || len(missing.Available) != 2 {
+		t.Errorf("Expected a move request to report the model missing, got %v", err)
+	}
+
+	// A name without a tag is its latest tag
+	player.Model = "phi3"
+	if err := player.TestConnection(); err != nil {
+		t.Errorf("Expected phi3 to be found as phi3:latest, got %v", err)
+	}
+}
+
+func TestNoModelsPulled(t *testing.T) {
+	player := NewAIPlayer(ollamaWithModels(t).URL, "llama3", "white", quietLogger())
+	var missing *ModelNotFoundError
+	if err := player.TestConnection(); !errors.As(err, &missing) || len(missing.Available) != 0 {
+		t.Fatalf("Expected the model missing with none pulled, got %v", err)
+	}
+	if !strings.Contains(missing.Error(), "no models are pulled") {
+		t.Errorf("Expected the error to say no models are pulled, got %q", missing.Error())
+	}
+}
+
+func TestEnsureModelPulls(t *testing.T) {
+	var pulled []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"phi3:latest"}]}`))
+		case "/api/pull":
+			var request struct{ Model string }
+			json.NewDecoder(r.Body).Decode(&request)
+			pulled = append(pulled, request.Model)
+			if request.Model == "nosuch" {
+				w.Write([]byte(`{"status":"pulling manifest"}` + "\n" + `{"error":"pull model manifest: file does not exist"}` + "\n"))
+				return
+			}
+			w.Write([]byte(`{"status":"pulling manifest"}
+{"status":"pulling abc","digest":"sha256:abc","total":200,"completed":50}
+{"status":"pulling abc","digest":"sha256:abc","total":200,"completed":200}
+{"status":"success"}
+`))
+		}
+	}))
+	defer server.Close()
+
+	player := NewAIPlayer(server.URL, "phi3", "white", quietLogger())
+	if ok, err := player.EnsureModel(context.Background(), nil); ok || err != nil {
+		t.Errorf("Expected phi3 to be there already, got pulled=%v and %v", ok, err)
+	}
+
+	player.Model = "llama3"
+	var steps []PullProgress
+	ok, err := player.EnsureModel(context.Background(), func(step PullProgress) { steps = append(steps, step) })
+	if !ok || err != nil {
+		t.Fatalf("Expected llama3 to be pulled, got pulled=%v and %v", ok, err)
+	}
+	if len(steps) != 4 || steps[1].Percent() != 25 || steps[0].Percent() != -1 || steps[3].Status != "success" {
+		t.Errorf("Expected the 4 steps of the pull, got %+v", steps)
+	}
+
+	if err := player.PullModel(context.Background(), "nosuch", nil); err == nil || !strings.Contains(err.Error(), "file does not exist") {
+		t.Errorf("Expected Ollama's pull error, got %v", err)
+	}
+	if len(pulled) != 2 || pulled[0] != "llama3" {
+		t.Errorf("Expected llama3 and nosuch pulled, got %v", pulled)
+	}
+}
@@ -0,0 +1,203 @@
+package ai_player
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIProvider talks to any OpenAI-compatible /v1/chat/completions endpoint,
+// such as llama.cpp server, vLLM or OpenRouter
+type OpenAIProvider struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+	Client  *http.Client
+	Logger  *ColoredLogger
+
+	// Logprobs asks for the reply's token probabilities, which set the
+	// moves' confidence; not every endpoint or model supports them
+	Logprobs bool
+}
+
+// openAIChatMessage is a single message in a chat completion request
+type openAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAIChatRequest is the body of a chat completion request
+type openAIChatRequest struct {
+	Model       string              `json:"model"`
+	Messages    []openAIChatMessage `json:"messages"`
+	Temperature *float64            `json:"temperature,omitempty"`
+	TopP        *float64            `json:"top_p,omitempty"`
+	MaxTokens   int                 `json:"max_tokens,omitempty"`
+	Stream      bool                `json:"stream"`
+	Logprobs    bool                `json:"logprobs,omitempty"`
+}
+
+// openAIChatResponse is the subset of a chat completion response we use
+type openAIChatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+			// Reasoning models expose their thinking under one of these
+			ReasoningContent string `json:"reasoning_content"`
+			Reasoning        string `json:"reasoning"`
+		} `json:"message"`
+		Logprobs *struct {
+			Content []struct {
+				Token   string  `json:"token"`
+				Logprob float64 `json:"logprob"`
+			} `json:"content"`
+		} `json:"logprobs"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// NewOpenAIProvider creates a provider for an OpenAI-compatible endpoint
+func NewOpenAIProvider(baseURL, apiKey, model string, logger *ColoredLogger) *OpenAIProvider {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com"
+	}
+	if logger == nil {
+		logger = NewAIPlayerLogger()
+	}
+
+	return &OpenAIProvider{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		APIKey:  apiKey,
+		Model:   model,
+		Client:  newHTTPClient(60 * time.Second),
+		Logger:  logger,
+	}
+}
+
+// Name identifies the provider
+func (p *OpenAIProvider) Name() string {
+	return ProviderOpenAI
+}
+
+// endpoint builds a URL under the /v1 API root, tolerating base URLs that
+// already include it
+func (p *OpenAIProvider) endpoint(path string) string {
+	base := strings.TrimSuffix(p.BaseURL, "/v1")
+	return base + "/v1" + path
+}
+
+// Generate sends the prompt as a single user message
+func (p *OpenAIProvider) Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error) {
+	chatRequest := openAIChatRequest{
+		Model: p.Model,
+		Messages: []openAIChatMessage{
+			{Role: "user", Content: request.Prompt},
+		},
+		Stream:   false,
+		Logprobs: p.Logprobs,
+	}
+	if temperature, ok := request.Options["temperature"].(float64); ok {
+		chatRequest.Temperature = &temperature
+	}
+	if topP, ok := request.Options["top_p"].(float64); ok {
+		chatRequest.TopP = &topP
+	}
+	if numPredict, ok := intOption(request.Options, "num_predict"); ok {
+		chatRequest.MaxTokens = numPredict
+	}
+
+	jsonData, err := json.Marshal(chatRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint("/chat/completions"), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	startTime := time.Now()
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, statusError("OpenAI API", resp.StatusCode, body)
+	}
+
+	var chatResponse openAIChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(chatResponse.Choices) == 0 {
+		return nil, fmt.Errorf("OpenAI API returned no choices")
+	}
+
+	message := chatResponse.Choices[0].Message
+	var logprobs []TokenLogprob
+	if chatResponse.Choices[0].Logprobs != nil {
+		for _, token := range chatResponse.Choices[0].Logprobs.Content {
+			logprobs = append(logprobs, TokenLogprob{Token: token.Token, Logprob: token.Logprob})
+		}
+	}
+	thinking := message.ReasoningContent
+	if thinking == "" {
+		thinking = message.Reasoning
+	}
+
+	p.Logger.Info("✅ %sOpenAI response completed - Time: %v, Response: %d chars, Tokens: %d/%d%s",
+		ColorGreen, time.Since(startTime).Round(100*time.Millisecond), len(message.Content),
+		chatResponse.Usage.PromptTokens, chatResponse.Usage.CompletionTokens, ColorReset)
+
+	return &OllamaResponse{
+		Model:           chatResponse.Model,
+		Response:        message.Content,
+		Thinking:        thinking,
+		Done:            true,
+		PromptEvalCount: chatResponse.Usage.PromptTokens,
+		EvalCount:       chatResponse.Usage.CompletionTokens,
+		Logprobs:        logprobs,
+	}, nil
+}
+
+// TestConnection checks the endpoint by listing its models
+func (p *OpenAIProvider) TestConnection() error {
+	p.Logger.Info("🔍 %sTesting OpenAI-compatible connection - URL: %s%s", ColorBlue, p.BaseURL, ColorReset)
+
+	req, err := http.NewRequest("GET", p.endpoint("/models"), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to OpenAI-compatible API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("OpenAI-compatible API returned status %d", resp.StatusCode)
+	}
+
+	p.Logger.Info("✅ %sOpenAI-compatible connection test successful%s", ColorGreen, ColorReset)
+	return nil
+}
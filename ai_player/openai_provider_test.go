@@ -0,0 +1,140 @@
+package ai_player
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"chess-tui/errkind"
+	"chess-tui/jsonrpc"
+)
+
+func TestOpenAIProviderGenerate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Expected path /v1/chat/completions, got %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Expected bearer auth, got %q", auth)
+		}
+
+		var request openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if len(request.Messages) != 1 || request.Messages[0].Content != "Your move" {
+			t.Errorf("Expected the prompt as a single user message, got %+v", request.Messages)
+		}
+
+		w.Write([]byte(`{"model":"m","choices":[{"message":{"content":"MOVE: e4","reasoning_content":"Center."}}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`))
+	}))
+	defer server.Close()
+
+	// A trailing /v1 on the base URL should not be doubled
+	provider := NewOpenAIProvider(server.URL+"/v1", "secret", "m", nil)
+	response, err := provider.Generate(context.Background(), OllamaRequest{
+		Prompt:  "Your move",
+		Options: map[string]interface{}{"temperature": 0.1},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if response.Response != "MOVE: e4" {
+		t.Errorf("Expected response 'MOVE: e4', got %q", response.Response)
+	}
+	if response.Thinking != "Center." {
+		t.Errorf("Expected thinking 'Center.', got %q", response.Thinking)
+	}
+	if response.PromptEvalCount != 12 || response.EvalCount != 3 {
+		t.Errorf("Expected 12/3 tokens, got %d/%d", response.PromptEvalCount, response.EvalCount)
+	}
+}
+
+func TestOpenAIProviderSendsZeroTemperature(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.Write([]byte(`{"model":"m","choices":[{"message":{"content":"MOVE: e4"}}]}`))
+	}))
+	defer server.Close()
+
+	// Options loaded from JSON hold numbers as float64
+	provider := NewOpenAIProvider(server.URL, "", "m", nil)
+	_, err := provider.Generate(context.Background(), OllamaRequest{
+		Prompt:  "Your move",
+		Options: map[string]interface{}{"temperature": 0.0, "num_predict": 64.0},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(body, `"temperature":0`) {
+		t.Errorf("Expected a temperature of 0 to be sent, got %s", body)
+	}
+	if strings.Contains(body, `"top_p"`) {
+		t.Errorf("Expected no top_p without the option, got %s", body)
+	}
+	if !strings.Contains(body, `"max_tokens":64`) {
+		t.Errorf("Expected num_predict sent as max_tokens, got %s", body)
+	}
+}
+
+func TestOpenAIProviderErrorKinds(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	provider := NewOpenAIProvider(server.URL, "", "m", nil)
+
+	for _, tt := range []struct {
+		status int
+		want   errkind.Kind
+	}{
+		{http.StatusServiceUnavailable, errkind.ProviderUnavailable},
+		{http.StatusTooManyRequests, errkind.ProviderUnavailable},
+		{http.StatusGatewayTimeout, errkind.ProviderTimeout},
+		{http.StatusUnauthorized, ""},
+	} {
+		status = tt.status
+		_, err := provider.Generate(context.Background(), OllamaRequest{Prompt: "Your move"})
+		if err == nil || errkind.Of(err) != tt.want {
+			t.Errorf("Expected status %d to be %q, got %v", tt.status, tt.want, err)
+		}
+	}
+
+	// The A2A server sends the kind's code, for the client to tell apart
+	status = http.StatusServiceUnavailable
+	player := &AIPlayer{Provider: provider, Logger: quietLogger(), Model: "m"}
+	a2a := httptest.NewServer(handleJSONRPCEndpoint(player, nil, quietLogger()))
+	defer a2a.Close()
+	reply := string(postChessRequest(t, a2a.URL, "", ChessRequest{BoardState: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", PlayerColor: "white"}))
+	if !strings.Contains(reply, `"code":-32014`) || !strings.Contains(reply, `"message":"Provider unavailable"`) {
+		t.Errorf("Expected a provider unavailable error, got %s", reply)
+	}
+}
+
+func TestKindErrorCodes(t *testing.T) {
+	err := kindError(fmt.Errorf("no move: %w", context.DeadlineExceeded))
+	var rpcErr *jsonrpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != errkind.CodeProviderTimeout || rpcErr.Message != "Provider timeout" {
+		t.Errorf("Expected a provider timeout error object, got %v", err)
+	}
+
+	// Error objects keep their code, and errors of no kind are left for the
+	// server to send as internal errors
+	desync := jsonrpc.NewError(ErrCodeDesync, "Board desync", "ply 2")
+	if kindError(desync) != error(desync) {
+		t.Errorf("Expected the error object unchanged, got %v", kindError(desync))
+	}
+	if err := kindError(errors.New("boom")); errors.As(err, &rpcErr) {
+		t.Errorf("Expected an error of no kind left as it is, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package ai_player
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+)
+
+// Opponent is a named AI opponent: a model, prompt and difficulty with a
+// face, so games against the AI feel like games against someone
+type Opponent struct {
+	Name        string `json:"name"`
+	Avatar      string `json:"avatar,omitempty"`      // an emoji shown next to the name
+	Model       string `json:"model,omitempty"`       // model to play with, instead of the base config's
+	Personality string `json:"personality,omitempty"` // a built-in preset, applied before Prompt
+	Prompt      string `json:"prompt,omitempty"`      // how the opponent plays, added to the move prompt
+	Difficulty  string `json:"difficulty,omitempty"`  // one of Difficulties; "" keeps the base config's sampling
+
+	// Adapted, when set, replaces Difficulty with a level tuned to the
+	// human's results, see AdaptDifficulty
+	Adapted *Difficulty `json:"-"`
+}
+
+// Difficulty tunes how carefully an opponent plays
+type Difficulty struct {
+	Name        string
+	Temperature float64 // higher plays looser, more varied moves
+	MoveStyle   string  // added to the move-selection prompt
+	BlunderRate float64 // chance of playing a random legal move instead
+	EngineDepth int     // plies the built-in engine looks ahead
+}
+
+// Difficulties are the built-in difficulty levels, easiest first
+var Difficulties = []Difficulty{
+	{
+		Name:        "easy",
+		Temperature: 1.0,
+		MoveStyle:   "Play like a casual beginner: make natural-looking moves quickly and don't look deeply for tactics.",
+		BlunderRate: 0.2,
+		EngineDepth: 1,
+	},
+	{
+		Name:        "medium",
+		Temperature: 0.6,
+		MoveStyle:   "Play like a club player: look for simple tactics but don't calculate long lines.",
+		BlunderRate: 0.05,
+		EngineDepth: 2,
+	},
+	{
+		Name:        "hard",
+		Temperature: 0.1,
+		MoveStyle:   "Play as strongly as you can: check every forcing move and calculate carefully.",
+		EngineDepth: 2,
+	},
+}
+
+// DifficultyByName looks up a built-in difficulty level
+func DifficultyByName(name string) (Difficulty, bool) {
+	for _, d := range Difficulties {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Difficulty{}, false
+}
+
+// Label returns the opponent's avatar and name for menus
+func (o Opponent) Label() string {
+	if o.Avatar == "" {
+		return o.Name
+	}
+	return o.Avatar + " " + o.Name
+}
+
+// Validate checks the opponent has a name and known presets
+func (o Opponent) Validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("opponent has no name")
+	}
+	if _, ok := PersonalityByName(o.Personality); o.Personality != "" && !ok {
+		return fmt.Errorf("opponent %s: unknown personality: %s", o.Name, o.Personality)
+	}
+	if _, ok := DifficultyByName(o.Difficulty); o.Difficulty != "" && !ok {
+		return fmt.Errorf("opponent %s: unknown difficulty: %s", o.Name, o.Difficulty)
+	}
+	return nil
+}
+
+// Config returns a copy of base set up to play as the opponent
+func (o Opponent) Config(base *Config) (*Config, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	config := *base
+	config.CustomPrompts = maps.Clone(base.CustomPrompts)
+	if o.Model != "" {
+		config.Model = o.Model
+	}
+	if o.Personality != "" {
+		config.ApplyPersonality(o.Personality)
+	}
+	if config.CustomPrompts == nil {
+		config.CustomPrompts = make(map[string]string)
+	}
+
+	moveStyle := config.CustomPrompts[PromptMoveStyle]
+	addStyle := func(style string) {
+		if moveStyle != "" {
+			moveStyle += " "
+		}
+		moveStyle += style
+	}
+	if difficulty, ok := o.difficulty(); ok {
+		config.Temperature = difficulty.Temperature
+		config.BlunderRate = difficulty.BlunderRate
+		config.EngineDepth = difficulty.EngineDepth
+		addStyle(difficulty.MoveStyle)
+	}
+	if o.Prompt != "" {
+		addStyle(o.Prompt)
+	}
+	if moveStyle != "" {
+		config.CustomPrompts[PromptMoveStyle] = moveStyle
+	}
+	return &config, nil
+}
+
+// difficulty returns the level the opponent plays at, adapted or named
+func (o Opponent) difficulty() (Difficulty, bool) {
+	if o.Adapted != nil {
+		return *o.Adapted, true
+	}
+	return DifficultyByName(o.Difficulty)
+}
+
+// DefaultOpponentsPath returns the opponents file location in the user's config directory
+func DefaultOpponentsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "opponents.json"
+	}
+	return filepath.Join(home, ".bubblechess", "opponents.json")
+}
+
+// LoadOpponents reads a JSON list of opponents, returning none if the file
+// doesn't exist
+func LoadOpponents(path string) ([]Opponent, error) {
+	if path == "" {
+		path = DefaultOpponentsPath()
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read opponents file: %w", err)
+	}
+
+	var opponents []Opponent
+	if err := json.Unmarshal(data, &opponents); err != nil {
+		return nil, fmt.Errorf("failed to decode opponents file: %w", err)
+	}
+	seen := make(map[string]bool)
+	for _, opponent := range opponents {
+		if err := opponent.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[opponent.Name] {
+			return nil, fmt.Errorf("opponent %s is defined twice", opponent.Name)
+		}
+		seen[opponent.Name] = true
+	}
+	return opponents, nil
+}
@@ -0,0 +1,68 @@
+package ai_player
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpponentConfig(t *testing.T) {
+	base := DefaultConfig()
+	opponent := Opponent{
+		Name:        "Gambit Gus",
+		Avatar:      "🦊",
+		Model:       "qwen3:8b",
+		Personality: "gambiteer",
+		Prompt:      "Always play the King's Gambit as White.",
+		Difficulty:  "easy",
+	}
+
+	config, err := opponent.Config(base)
+	if err != nil {
+		t.Fatalf("Failed to build config: %v", err)
+	}
+	if config.Model != "qwen3:8b" {
+		t.Errorf("Expected model qwen3:8b, got %s", config.Model)
+	}
+	if config.Temperature != 1.0 {
+		t.Errorf("Expected easy temperature 1.0, got %v", config.Temperature)
+	}
+	style := config.CustomPrompts[PromptMoveStyle]
+	if !strings.HasPrefix(style, "Play aggressively") || !strings.HasSuffix(style, "King's Gambit as White.") {
+		t.Errorf("Expected personality, difficulty and prompt in move style, got %q", style)
+	}
+	if config.CustomPrompts[PromptPersonality] != "gambiteer" {
+		t.Errorf("Expected gambiteer personality, got %q", config.CustomPrompts[PromptPersonality])
+	}
+	if len(base.CustomPrompts) != 0 || base.Model == "qwen3:8b" {
+		t.Error("Expected base config to be left unchanged")
+	}
+	if opponent.Label() != "🦊 Gambit Gus" {
+		t.Errorf("Expected label with avatar, got %q", opponent.Label())
+	}
+}
+
+func TestLoadOpponents(t *testing.T) {
+	dir := t.TempDir()
+
+	opponents, err := LoadOpponents(filepath.Join(dir, "missing.json"))
+	if err != nil || len(opponents) != 0 {
+		t.Errorf("Expected no opponents for a missing file, got %d and %v", len(opponents), err)
+	}
+
+	path := filepath.Join(dir, "opponents.json")
+	os.WriteFile(path, []byte(`[{"name":"Gus","avatar":"🦊","difficulty":"easy"},{"name":"Ada","difficulty":"hard"}]`), 0644)
+	opponents, err = LoadOpponents(path)
+	if err != nil {
+		t.Fatalf("Failed to load opponents: %v", err)
+	}
+	if len(opponents) != 2 || opponents[1].Name != "Ada" {
+		t.Errorf("Expected Gus and Ada, got %+v", opponents)
+	}
+
+	os.WriteFile(path, []byte(`[{"name":"Gus","difficulty":"impossible"}]`), 0644)
+	if _, err := LoadOpponents(path); err == nil {
+		t.Error("Expected an unknown difficulty to be rejected")
+	}
+}
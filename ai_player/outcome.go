@@ -0,0 +1,53 @@
+package ai_player
+
+import (
+	"github.com/notnil/chess"
+
+	"chess-tui/notation"
+)
+
+// Reasons a move can end the game, as reported in MoveOutcome
+const (
+	ReasonCheckmate            = "checkmate"
+	ReasonStalemate            = "stalemate"
+	ReasonInsufficientMaterial = "insufficient_material"
+	ReasonSeventyFiveMoveRule  = "seventy_five_move_rule"
+)
+
+// MoveOutcome describes how the AI's move ended the game, so clients don't
+// have to work it out with their own validator
+type MoveOutcome struct {
+	Result string `json:"result"` // "1-0", "0-1" or "1/2-1/2"
+	Reason string `json:"reason"`
+	FEN    string `json:"fen"` // the final position
+}
+
+// moveOutcome returns how playing move in the position given as FEN ends
+// the game, or nil if the game goes on. Repetitions can't be told from a
+// single position, so they're left to the client.
+func moveOutcome(fen, move string) *MoveOutcome {
+	fenOption, err := chess.FEN(fen)
+	if err != nil {
+		return nil
+	}
+	game := chess.NewGame(fenOption)
+	decoded, err := notation.Decode(game.Position(), move)
+	if err != nil || game.Move(decoded) != nil {
+		return nil
+	}
+
+	var reason string
+	switch game.Method() {
+	case chess.Checkmate:
+		reason = ReasonCheckmate
+	case chess.Stalemate:
+		reason = ReasonStalemate
+	case chess.InsufficientMaterial:
+		reason = ReasonInsufficientMaterial
+	case chess.SeventyFiveMoveRule:
+		reason = ReasonSeventyFiveMoveRule
+	default:
+		return nil
+	}
+	return &MoveOutcome{Result: game.Outcome().String(), Reason: reason, FEN: game.Position().String()}
+}
@@ -0,0 +1,34 @@
+package ai_player
+
+import "testing"
+
+func TestMoveOutcome(t *testing.T) {
+	tests := []struct {
+		name, fen, move string
+		want            *MoveOutcome
+	}{
+		{
+			"scholar's mate",
+			"r1bqkbnr/pppp1ppp/2n5/4p3/2B1P3/5Q2/PPPP1PPP/RNB1K1NR w KQkq - 2 4", "Qxf7#",
+			&MoveOutcome{Result: "1-0", Reason: ReasonCheckmate, FEN: "r1bqkbnr/pppp1Qpp/2n5/4p3/2B1P3/8/PPPP1PPP/RNB1K1NR b KQkq - 0 4"},
+		},
+		{
+			"stalemate",
+			"7k/8/6Q1/8/8/8/8/K7 w - - 0 1", "Qf7",
+			&MoveOutcome{Result: "1/2-1/2", Reason: ReasonStalemate, FEN: "7k/5Q2/8/8/8/8/8/K7 b - - 1 1"},
+		},
+		{
+			"bare kings",
+			"7k/8/8/8/3q4/4K3/8/8 w - - 0 1", "Kxd4",
+			&MoveOutcome{Result: "1/2-1/2", Reason: ReasonInsufficientMaterial, FEN: "7k/8/8/8/3K4/8/8/8 b - - 0 1"},
+		},
+		{"game goes on", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e4", nil},
+		{"illegal move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e5", nil},
+	}
+	for _, tt := range tests {
+		got := moveOutcome(tt.fen, tt.move)
+		if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package ai_player
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/notnil/chess"
+
+	"chess-tui/locale"
+	"chess-tui/notation"
+)
+
+// handleGameFEN serves a session's current position as plain-text FEN, for
+// stream overlays and board widgets that poll it
+func handleGameFEN(sessions *SessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session, ok := sessions.Get(r.PathValue("id"))
+		if !ok {
+			http.Error(w, "no such game", http.StatusNotFound)
+			return
+		}
+		servePlainText(w, r, session.FEN)
+	}
+}
+
+// handleGamePGN serves a session's game so far as PGN
+func handleGamePGN(sessions *SessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		session, ok := sessions.Get(id)
+		if !ok {
+			http.Error(w, "no such game", http.StatusNotFound)
+			return
+		}
+		pgn, err := sessionPGN(id, session)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		servePlainText(w, r, pgn)
+	}
+}
+
+// servePlainText writes text for a poller: it is never cached, but its ETag
+// lets a poller skip the body while the game hasn't moved, and any page may
+// read it
+func servePlainText(w http.ResponseWriter, r *http.Request, text string) {
+	sum := sha256.Sum256([]byte(text))
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:8])+`"`)
+	http.ServeContent(w, r, "", time.Time{}, strings.NewReader(text))
+}
+
+// sessionPGN writes a session's game as PGN, the AI's side named after its
+// model. A game still being played has the result "*".
+func sessionPGN(id string, session Session) (string, error) {
+	game := chess.NewGame()
+	if session.StartFEN != "" {
+		fen, err := chess.FEN(session.StartFEN)
+		if err != nil {
+			return "", fmt.Errorf("invalid start position: %w", err)
+		}
+		game = chess.NewGame(fen)
+	}
+	// Number the moves on from the start position's full-move number
+	number := 1
+	if fields := strings.Fields(game.Position().String()); len(fields) == 6 {
+		if n, err := strconv.Atoi(fields[5]); err == nil && n > 0 {
+			number = n
+		}
+	}
+
+	var tokens []string
+	for ply, san := range session.History {
+		move, err := notation.Decode(game.Position(), san)
+		if err != nil {
+			return "", fmt.Errorf("invalid move %d (%s): %w", ply+1, san, err)
+		}
+		turn := game.Position().Turn()
+		switch {
+		case turn == chess.White:
+			tokens = append(tokens, strconv.Itoa(number)+".")
+		case ply == 0:
+			tokens = append(tokens, strconv.Itoa(number)+"...")
+		}
+		tokens = append(tokens, san)
+		game.Move(move)
+		if turn == chess.Black {
+			number++
+		}
+	}
+	result := string(game.Outcome())
+	tokens = append(tokens, result)
+
+	white, black := "Opponent", "Opponent"
+	ai := session.Model
+	if ai == "" {
+		ai = "AI"
+	}
+	if session.PlayerColor == "white" {
+		white = ai
+	} else {
+		black = ai
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[Event \"bubblechess A2A game\"]\n")
+	fmt.Fprintf(&sb, "[Site \"bubblechess\"]\n")
+	fmt.Fprintf(&sb, "[Date \"%s\"]\n", locale.PGNDate(session.Updated))
+	fmt.Fprintf(&sb, "[Round \"%s\"]\n", locale.PGNRound(0))
+	fmt.Fprintf(&sb, "[White \"%s\"]\n", white)
+	fmt.Fprintf(&sb, "[Black \"%s\"]\n", black)
+	fmt.Fprintf(&sb, "[Result \"%s\"]\n", result)
+	fmt.Fprintf(&sb, "[GameId \"%s\"]\n", id)
+	if session.StartFEN != "" {
+		fmt.Fprintf(&sb, "[SetUp \"1\"]\n")
+		fmt.Fprintf(&sb, "[FEN \"%s\"]\n", session.StartFEN)
+	}
+	sb.WriteString("\n" + strings.Join(tokens, " ") + "\n")
+	return sb.String(), nil
+}
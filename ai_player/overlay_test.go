@@ -0,0 +1,89 @@
+package ai_player
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSessionPGN(t *testing.T) {
+	pgn, err := sessionPGN("game_1", Session{FEN: "x", History: []string{"e4", "e5", "Nf3"}, PlayerColor: "black", Model: "llama3"})
+	if err != nil {
+		t.Fatalf("Failed to write PGN: %v", err)
+	}
+	for _, want := range []string{`[White "Opponent"]`, `[Black "llama3"]`, `[Result "*"]`, `[GameId "game_1"]`, "\n1. e4 e5 2. Nf3 *\n"} {
+		if !strings.Contains(pgn, want) {
+			t.Errorf("Expected %q in the PGN, got:\n%s", want, pgn)
+		}
+	}
+
+	// A game from a set-up position with Black to move numbers on from it
+	start := "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 7"
+	pgn, err = sessionPGN("game_2", Session{History: []string{"e5", "Nf3"}, StartFEN: start, PlayerColor: "white"})
+	if err != nil {
+		t.Fatalf("Failed to write PGN: %v", err)
+	}
+	for _, want := range []string{`[White "AI"]`, `[SetUp "1"]`, `[FEN "` + start + `"]`, "\n7... e5 8. Nf3 *\n"} {
+		if !strings.Contains(pgn, want) {
+			t.Errorf("Expected %q in the PGN, got:\n%s", want, pgn)
+		}
+	}
+
+	if _, err := sessionPGN("game_3", Session{History: []string{"e5"}}); err == nil {
+		t.Error("Expected an illegal move to fail")
+	}
+}
+
+func TestGameFENAndPGNEndpoints(t *testing.T) {
+	sessions, _ := LoadSessionStore("")
+	sessions.Put("game_1", Session{FEN: startFEN, PlayerColor: "white"})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /games/{id}/fen", handleGameFEN(sessions))
+	mux.HandleFunc("GET /games/{id}/pgn", handleGamePGN(sessions))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func(path, etag string) (*http.Response, string) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return resp, string(body)
+	}
+
+	resp, body := get("/games/game_1/fen", "")
+	if resp.StatusCode != http.StatusOK || body != startFEN {
+		t.Fatalf("Expected the position, got %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("Access-Control-Allow-Origin") != "*" || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Errorf("Expected plain text any page can read, got %v", resp.Header)
+	}
+
+	// Polling again is cheap until the game moves on
+	etag := resp.Header.Get("ETag")
+	if resp, _ := get("/games/game_1/fen", etag); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected the unchanged position not to be sent again, got %d", resp.StatusCode)
+	}
+	afterE4 := "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"
+	sessions.Put("game_1", Session{FEN: afterE4, History: []string{"e4"}, PlayerColor: "white"})
+	if resp, body := get("/games/game_1/fen", etag); resp.StatusCode != http.StatusOK || body != afterE4 {
+		t.Errorf("Expected the new position, got %d %q", resp.StatusCode, body)
+	}
+
+	if resp, body := get("/games/game_1/pgn", ""); resp.StatusCode != http.StatusOK || !strings.Contains(body, "1. e4 *") {
+		t.Errorf("Expected the game's PGN, got %d %q", resp.StatusCode, body)
+	}
+	for _, path := range []string{"/games/game_2/fen", "/games/game_2/pgn"} {
+		if resp, _ := get(path, ""); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected no game at %s, got %d", path, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package ai_player
+
+import "fmt"
+
+// Keys of the CustomPrompts config map used by personalities
+const (
+	PromptPersonality    = "personality"
+	PromptMoveStyle      = "move_style"
+	PromptCommentaryTone = "commentary_tone"
+)
+
+// Personality is a prompt preset that shapes how the AI plays and talks
+type Personality struct {
+	Name        string
+	Label       string
+	Description string
+	MoveStyle   string // added to the move-selection prompt
+	Commentary  string // tone for the AI's explanation of its move
+}
+
+// Personalities are the built-in presets, in menu order
+var Personalities = []Personality{
+	{
+		Name:        "positional",
+		Label:       "Solid positional",
+		Description: "Calm, strategic play",
+		MoveStyle:   "Play solid positional chess: improve your worst piece, keep a sound pawn structure and avoid unclear sacrifices.",
+		Commentary:  "Explain your move calmly and concisely, like a strong club player annotating a game.",
+	},
+	{
+		Name:        "gambiteer",
+		Label:       "Aggressive gambiteer",
+		Description: "Sacrifices for the initiative",
+		MoveStyle:   "Play aggressively: prefer gambits, open lines toward the enemy king and sacrifice material for the initiative when it is reasonable.",
+		Commentary:  "Explain your move with swagger, relishing the attack.",
+	},
+	{
+		Name:        "trash-talker",
+		Label:       "Trash-talking commentator",
+		Description: "Plays normally, talks a lot",
+		MoveStyle:   "Play sound, practical moves.",
+		Commentary:  "Explain your move as a cheeky commentator who playfully trash-talks the opponent. Keep it good-natured and never insulting.",
+	},
+	{
+		Name:        "teacher",
+		Label:       "Beginner teacher",
+		Description: "Plays simply and explains ideas",
+		MoveStyle:   "Play clear, principled moves a beginner can learn from: develop pieces, control the center and castle early. Avoid tricky traps.",
+		Commentary:  "Explain your move in simple words for a beginner, naming the principle behind it.",
+	},
+}
+
+// PersonalityByName looks up a built-in personality
+func PersonalityByName(name string) (Personality, bool) {
+	for _, p := range Personalities {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Personality{}, false
+}
+
+// ApplyPersonality stores a preset's prompts in the CustomPrompts map, where
+// they can be edited further
+func (c *Config) ApplyPersonality(name string) error {
+	personality, ok := PersonalityByName(name)
+	if !ok {
+		return fmt.Errorf("unknown personality: %s", name)
+	}
+	if c.CustomPrompts == nil {
+		c.CustomPrompts = make(map[string]string)
+	}
+	c.CustomPrompts[PromptPersonality] = personality.Name
+	c.CustomPrompts[PromptMoveStyle] = personality.MoveStyle
+	c.CustomPrompts[PromptCommentaryTone] = personality.Commentary
+	return nil
+}
+
+// personalityPrompts returns the move style and commentary tone for the next
+// move, in phase: the per-game personality if one is set, otherwise the
+// custom prompts
+func (ai *AIPlayer) personalityPrompts(phase string) (moveStyle, commentary string) {
+	if personality, ok := PersonalityByName(ai.Personality); ok {
+		return personality.MoveStyle, personality.Commentary
+	}
+	return ai.phasePrompt(PromptMoveStyle, phase), ai.phasePrompt(PromptCommentaryTone, phase)
+}
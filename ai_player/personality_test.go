@@ -0,0 +1,35 @@
+package ai_player
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPersonalityPrompt(t *testing.T) {
+	player := NewAIPlayer("", "m", "black", nil)
+	if prompt := player.buildPrompt(startFEN, nil); strings.Contains(prompt, "PERSONALITY") {
+		t.Errorf("Expected no personality section by default")
+	}
+
+	player.Personality = "gambiteer"
+	gambiteer, _ := PersonalityByName("gambiteer")
+	if prompt := player.buildPrompt(startFEN, nil); !strings.Contains(prompt, gambiteer.MoveStyle) {
+		t.Errorf("Expected the gambiteer move style in the prompt")
+	}
+
+	// Without a per-game preset, the config's custom prompts apply
+	config := DefaultConfig()
+	if err := config.ApplyPersonality("teacher"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	player.Personality = ""
+	player.CustomPrompts = config.CustomPrompts
+	teacher, _ := PersonalityByName("teacher")
+	if prompt := player.buildPrompt(startFEN, nil); !strings.Contains(prompt, teacher.Commentary) {
+		t.Errorf("Expected the teacher commentary tone in the prompt")
+	}
+
+	if err := config.ApplyPersonality("grandmaster"); err == nil {
+		t.Errorf("Expected error for an unknown personality")
+	}
+}
@@ -0,0 +1,68 @@
+package ai_player
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// Game phases, as DetectPhase tells them; they also suffix the keys of
+// CustomPrompts that apply in one phase only, e.g. "move_style.endgame"
+const (
+	PhaseOpening    = "opening"
+	PhaseMiddlegame = "middlegame"
+	PhaseEndgame    = "endgame"
+)
+
+// PromptPhaseGuidance is the CustomPrompts key of advice for one phase, as
+// "guidance.opening", "guidance.middlegame" or "guidance.endgame"
+const PromptPhaseGuidance = "guidance"
+
+// Phase detection: the opening lasts through openingMoves while no more
+// than a pair of minor pieces has been traded, and the endgame starts once
+// the pieces other than pawns and kings are worth endgameMaterial or less,
+// both sides together in centipawns, e.g. a queen and a bishop each
+const (
+	openingMoves    = 10
+	openingMaterial = 6400 - 2*330
+	endgameMaterial = 2 * 1300
+)
+
+// DetectPhase tells the game phase of the FEN position from its move
+// number and the material left, or "" if the FEN is invalid
+func DetectPhase(fen string) string {
+	option, err := chess.FEN(fen)
+	if err != nil {
+		return ""
+	}
+	pieces := 0
+	for _, piece := range chess.NewGame(option).Position().Board().SquareMap() {
+		if piece.Type() != chess.Pawn {
+			pieces += pieceValues[piece.Type()]
+		}
+	}
+
+	number := 1
+	if fields := strings.Fields(fen); len(fields) == 6 {
+		if n, err := strconv.Atoi(fields[5]); err == nil {
+			number = n
+		}
+	}
+	switch {
+	case pieces <= endgameMaterial:
+		return PhaseEndgame
+	case number <= openingMoves && pieces >= openingMaterial:
+		return PhaseOpening
+	}
+	return PhaseMiddlegame
+}
+
+// phasePrompt returns the custom prompt for key in phase: the one for the
+// phase if set, else the one for every phase
+func (ai *AIPlayer) phasePrompt(key, phase string) string {
+	if prompt, ok := ai.CustomPrompts[key+"."+phase]; ok && phase != "" {
+		return prompt
+	}
+	return ai.CustomPrompts[key]
+}
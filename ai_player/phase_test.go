@@ -0,0 +1,45 @@
+package ai_player
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectPhase(t *testing.T) {
+	tests := []struct {
+		fen  string
+		want string
+	}{
+		{startFEN, PhaseOpening},
+		// Every piece still on the board, but past move 10
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p3/4P3/2N2N2/PPPP1PPP/R1BQKB1R w KQkq - 4 12", PhaseMiddlegame},
+		// Queens traded early
+		{"rnb1kbnr/ppp2ppp/8/4p3/4P3/8/PPP2PPP/RNB1KBNR w KQkq - 0 5", PhaseMiddlegame},
+		{"8/5k2/8/8/8/8/4K3/R7 w - - 0 50", PhaseEndgame},
+		{"not a fen", ""},
+	}
+	for _, test := range tests {
+		if got := DetectPhase(test.fen); got != test.want {
+			t.Errorf("Expected %q for %s, got %q", test.want, test.fen, got)
+		}
+	}
+}
+
+func TestPromptByPhase(t *testing.T) {
+	player := NewAIPlayer("", "m", "white", nil)
+	player.CustomPrompts = map[string]string{
+		PromptMoveStyle:                  "Play sound moves.",
+		PromptMoveStyle + ".opening":     "Develop quickly.",
+		PromptPhaseGuidance + ".endgame": "Activate your king.",
+	}
+
+	opening := player.Prompt(startFEN, nil, "")
+	if !strings.Contains(opening, "Develop quickly.") || strings.Contains(opening, "Play sound moves.") || strings.Contains(opening, "GAME PHASE") {
+		t.Errorf("Expected the opening's move style and no guidance, got:\n%s", opening)
+	}
+
+	endgame := player.Prompt("8/5k2/8/8/8/8/4K3/R7 w - - 0 50", nil, "")
+	if !strings.Contains(endgame, "Play sound moves.") || !strings.Contains(endgame, "GAME PHASE: endgame\nActivate your king.") {
+		t.Errorf("Expected the plain move style and the endgame guidance, got:\n%s", endgame)
+	}
+}
@@ -0,0 +1,64 @@
+package ai_player
+
+import (
+	"context"
+	"fmt"
+
+	"chess-tui/notation"
+	"chess-tui/plugins"
+)
+
+// PluginProvider plays the moves of a move provider from the plugins
+// registry, picked by its name in the "provider" config field
+type PluginProvider struct {
+	name   string
+	plugin plugins.MoveProvider
+}
+
+// newPluginProvider returns the registered move provider called name, if any
+func newPluginProvider(name string) (*PluginProvider, bool) {
+	plugin, ok := plugins.LookupMoveProvider(name)
+	if !ok {
+		return nil, false
+	}
+	return &PluginProvider{name: name, plugin: plugin}, true
+}
+
+// Name identifies the provider
+func (p *PluginProvider) Name() string {
+	return p.name
+}
+
+// Generate is unsupported; plugins only select moves
+func (p *PluginProvider) Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error) {
+	return nil, fmt.Errorf("the %s plugin does not generate text", p.name)
+}
+
+// TestConnection always succeeds; a plugin is asked for nothing until it moves
+func (p *PluginProvider) TestConnection() error {
+	return nil
+}
+
+// SelectMove asks the plugin for one of the legal moves
+func (p *PluginProvider) SelectMove(ctx context.Context, request MoveRequest) (*ChessMove, error) {
+	move, err := p.plugin.SelectMove(ctx, plugins.MoveRequest{FEN: request.FEN, LegalMoves: request.LegalMoves, Phase: request.Phase, Variant: request.Variant})
+	if err != nil {
+		return nil, err
+	}
+	if request.Variant != "" {
+		// The variant's moves are only known from the list
+		san, ok := listedMove(move, request.LegalMoves)
+		if !ok {
+			return nil, fmt.Errorf("the %s plugin chose %s, which isn't allowed in %s", p.name, move, request.Variant)
+		}
+		return &ChessMove{Notation: san, Provider: p.name}, nil
+	}
+	san, err := notation.Normalize(request.FEN, move)
+	if err != nil {
+		return nil, fmt.Errorf("the %s plugin chose %q: %w", p.name, move, err)
+	}
+	if len(request.LegalMoves) > 0 && !containsMove(request.LegalMoves, san) {
+		return nil, fmt.Errorf("the %s plugin chose %s, which isn't allowed", p.name, san)
+	}
+	return &ChessMove{Notation: san, Provider: p.name}, nil
+}
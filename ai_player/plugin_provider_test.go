@@ -0,0 +1,41 @@
+package ai_player
+
+import (
+	"context"
+	"testing"
+
+	"chess-tui/plugins"
+)
+
+// uciPlugin answers with a fixed move in UCI
+type uciPlugin string
+
+func (p uciPlugin) SelectMove(ctx context.Context, request plugins.MoveRequest) (string, error) {
+	return string(p), nil
+}
+
+func TestPluginProviderFromConfig(t *testing.T) {
+	plugins.RegisterMoveProvider("test-uci", uciPlugin("g1f3"))
+	config := DefaultConfig()
+	config.Provider = "test-uci"
+	if err := config.ValidateConfig(); err != nil {
+		t.Fatalf("Expected a registered plugin to be a valid provider, got %v", err)
+	}
+
+	player, err := NewAIPlayerFromConfig(config, "white", nil)
+	if err != nil {
+		t.Fatalf("Expected the plugin provider to be created, got %v", err)
+	}
+	move, err := player.GetMove(startFEN, nil)
+	if err != nil {
+		t.Fatalf("Expected the plugin's move, got %v", err)
+	}
+	if move.Notation != "Nf3" || move.Provider != "test-uci" {
+		t.Errorf("Expected Nf3 from test-uci, got %s from %s", move.Notation, move.Provider)
+	}
+
+	config.Provider = "not-registered"
+	if err := config.ValidateConfig(); err == nil {
+		t.Error("Expected an unknown provider to be rejected")
+	}
+}
@@ -0,0 +1,324 @@
+package ai_player
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"chess-tui/errkind"
+	"chess-tui/notation"
+)
+
+// Provider names accepted in the "provider" config field
+const (
+	ProviderOllama    = "ollama"
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderGGUF      = "gguf"
+)
+
+// Provider generates completions for the AI player's prompts. The Ollama
+// backend is built into AIPlayer; other backends implement this interface.
+type Provider interface {
+	// Name identifies the provider in logs and status lines
+	Name() string
+
+	// Generate sends the prompt and options in request to the backend
+	Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error)
+
+	// TestConnection checks that the backend is reachable
+	TestConnection() error
+}
+
+// MoveRequest is what a MoveSelector needs to pick a move
+type MoveRequest struct {
+	Generate   OllamaRequest // the prompt, for model-backed selectors
+	FEN        string
+	LegalMoves []string // in SAN
+	Phase      string   // PhaseOpening, PhaseMiddlegame or PhaseEndgame
+	Variant    string   // the variant's lowercase name, e.g. "atomic", or "" for standard chess
+}
+
+// MoveSelector is implemented by providers that can return a structured move
+// chosen from the legal moves, skipping text parsing entirely
+type MoveSelector interface {
+	SelectMove(ctx context.Context, request MoveRequest) (*ChessMove, error)
+}
+
+// NewProvider creates the provider selected in the configuration. It returns
+// nil for Ollama, which AIPlayer talks to directly.
+func NewProvider(config *Config, logger *ColoredLogger) (Provider, error) {
+	if config.Spare != nil {
+		return newSparePair(config, logger)
+	}
+	if voters := bestOf(config.Voters, config.Bullet); len(voters) > 0 {
+		return newVotePanel(voters, logger)
+	}
+	if len(config.Providers) > 0 {
+		return newFailoverChain(config.Providers, logger)
+	}
+
+	switch config.Provider {
+	case "", ProviderOllama:
+		return nil, nil
+	case ProviderOpenAI:
+		apiKey := config.APIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("OPENAI_API_KEY")
+		}
+		provider := NewOpenAIProvider(config.APIBaseURL, apiKey, config.Model, logger)
+		provider.Logprobs = config.Logprobs
+		return provider, nil
+	case ProviderAnthropic:
+		apiKey := config.APIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("ANTHROPIC_API_KEY")
+		}
+		return NewAnthropicProvider(config.APIBaseURL, apiKey, config.Model, logger), nil
+	case ProviderGGUF:
+		return NewGGUFProvider(config.ModelPath, logger)
+	case ProviderEngine:
+		engine := NewEngineProvider()
+		engine.Depth = config.EngineDepth
+		return engine, nil
+	case ProviderScript:
+		return NewScriptProvider(config.ScriptPath)
+	default:
+		if provider, ok := newPluginProvider(config.Provider); ok {
+			return provider, nil
+		}
+		return nil, fmt.Errorf("unknown provider: %s", config.Provider)
+	}
+}
+
+// NewAIPlayerFromConfig creates an AI player using the configured provider
+func NewAIPlayerFromConfig(config *Config, color string, logger *ColoredLogger) (*AIPlayer, error) {
+	player := NewAIPlayer(config.OllamaURL, config.Model, color, logger)
+	player.applySettings(config)
+
+	provider, err := NewProvider(config, player.Logger)
+	if err != nil {
+		return nil, err
+	}
+	setParser(provider, player.parseMove)
+	player.Provider = provider
+	if player.repertoire, err = newRepertoireBook(config.Repertoire); err != nil {
+		return nil, err
+	}
+
+	if config.TraceDir != "" {
+		tracer, err := NewTracer(config.TraceDir, config.TraceRedact, traceSecrets(config)...)
+		if err != nil {
+			return nil, err
+		}
+		player.Tracer = tracer
+	}
+
+	return player, nil
+}
+
+// newFailoverChain creates a failover provider from the "providers" list
+func newFailoverChain(configs []Config, logger *ColoredLogger) (Provider, error) {
+	members := make([]Provider, 0, len(configs))
+	timeouts := make([]time.Duration, 0, len(configs))
+
+	for i := range configs {
+		member, err := newMember(configs[i], logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create provider %d: %w", i+1, err)
+		}
+		members = append(members, member)
+		timeouts = append(timeouts, time.Duration(configs[i].Timeout)*time.Second)
+	}
+
+	return NewFailoverProvider(members, timeouts, logger), nil
+}
+
+// newSparePair creates a spare provider from a config and its "spare"
+func newSparePair(config *Config, logger *ColoredLogger) (Provider, error) {
+	primaryConfig := *config
+	primaryConfig.Spare = nil
+	primary, err := NewProvider(&primaryConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+	if primary == nil {
+		primary, _ = newMember(primaryConfig, logger)
+	}
+	spare, err := newMember(*config.Spare, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spare: %w", err)
+	}
+	return NewSpareProvider(primary, spare, voterLabel(*config.Spare), time.Duration(config.Timeout)*time.Second, logger), nil
+}
+
+// newVotePanel creates a vote provider from the "voters" list
+func newVotePanel(configs []Config, logger *ColoredLogger) (Provider, error) {
+	members := make([]Provider, 0, len(configs))
+	labels := make([]string, 0, len(configs))
+	weights := make([]float64, 0, len(configs))
+	timeouts := make([]time.Duration, 0, len(configs))
+
+	for i := range configs {
+		member, err := newMember(configs[i], logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create voter %d: %w", i+1, err)
+		}
+		members = append(members, member)
+		labels = append(labels, voterLabel(configs[i]))
+		weights = append(weights, configs[i].Weight)
+		timeouts = append(timeouts, time.Duration(configs[i].Timeout)*time.Second)
+	}
+
+	return NewVoteProvider(members, labels, weights, timeouts, logger), nil
+}
+
+// newMember creates one provider of a failover chain or vote panel
+func newMember(config Config, logger *ColoredLogger) (Provider, error) {
+	config.Providers = nil
+	config.Voters = nil
+	if config.Provider == "" || config.Provider == ProviderOllama {
+		return &ollamaProvider{player: NewAIPlayer(config.OllamaURL, config.Model, "", logger), model: config.Model}, nil
+	}
+	return NewProvider(&config, logger)
+}
+
+// voterLabel names a voter by its provider and model, e.g. "openai gpt-4o"
+func voterLabel(config Config) string {
+	provider := config.Provider
+	if provider == "" {
+		provider = ProviderOllama
+	}
+	switch {
+	case config.Model != "":
+		return provider + " " + config.Model
+	case config.ModelPath != "":
+		return provider + " " + filepath.Base(config.ModelPath)
+	}
+	return provider
+}
+
+// setParser gives the providers that parse text replies themselves the
+// player's parser
+func setParser(provider Provider, parse func(string) (*ChessMove, error)) {
+	switch p := provider.(type) {
+	case *FailoverProvider:
+		p.Parse = parse
+	case *VoteProvider:
+		p.Parse = parse
+	case *SpareProvider:
+		p.Parse = parse
+		setParser(p.Primary, parse)
+	}
+}
+
+// ProviderName returns the name of the backend the player uses
+func (ai *AIPlayer) ProviderName() string {
+	if ai.Provider != nil {
+		return ai.Provider.Name()
+	}
+	return ProviderOllama
+}
+
+// generate sends a request to the configured provider, defaulting to Ollama
+func (ai *AIPlayer) generate(request OllamaRequest) (*OllamaResponse, error) {
+	start := time.Now()
+	var raw *bytes.Buffer
+	if ai.Tracer != nil {
+		raw = &bytes.Buffer{}
+		if ai.Provider == nil {
+			request.Stream = true // as callOllama sends it
+		}
+	}
+
+	response, err := ai.generateWith(request, raw)
+	if ai.Tracer != nil {
+		ai.trace(traceRecord{Request: request, Raw: raw.String(), Response: response, Err: err, Duration: time.Since(start)})
+	}
+	return response, err
+}
+
+// generateWith sends a request to the provider, copying Ollama's raw
+// response to raw when it is not nil
+func (ai *AIPlayer) generateWith(request OllamaRequest, raw *bytes.Buffer) (*OllamaResponse, error) {
+	if ai.Provider == nil {
+		if raw == nil {
+			return ai.callOllama(request, nil)
+		}
+		return ai.callOllama(request, raw)
+	}
+
+	ai.Logger.Info("🚀 %sStarting %s API call - Model: %s, Prompt: %d chars%s",
+		ColorGreen, ai.Provider.Name(), request.Model, len(request.Prompt), ColorReset)
+
+	ctx, cancel := context.WithTimeout(ai.baseContext(), 60*time.Second)
+	defer cancel()
+
+	return ai.Provider.Generate(ctx, request)
+}
+
+// statusError reports an API's reply with an error status, e.g. from
+// "OpenAI API". A gateway timeout is a provider timeout, and a server error
+// or rate limit leaves the provider unavailable for now.
+func statusError(api string, status int, body []byte) error {
+	err := fmt.Errorf("%s returned status %d: %s", api, status, string(body))
+	switch {
+	case status == http.StatusGatewayTimeout || status == http.StatusRequestTimeout:
+		return errkind.Wrap(errkind.ProviderTimeout, err)
+	case status == http.StatusTooManyRequests || status >= 500:
+		return errkind.Wrap(errkind.ProviderUnavailable, err)
+	}
+	return err
+}
+
+// intOption returns a whole-number option such as "top_k", which is an int
+// when set in code and a float64 when loaded from JSON
+func intOption(options map[string]interface{}, key string) (int, bool) {
+	switch value := options[key].(type) {
+	case int:
+		return value, true
+	case float64:
+		return int(value), true
+	}
+	return 0, false
+}
+
+// selectMove asks a MoveSelector provider for one of the legal moves in the
+// FEN position, leaving out the excluded ones, such as moves a reviewer
+// vetoed, unless that leaves none. ok is false when the provider or
+// position doesn't support it.
+func (ai *AIPlayer) selectMove(request OllamaRequest, boardState string, excluded []string) (move *ChessMove, ok bool, err error) {
+	selector, isSelector := ai.Provider.(MoveSelector)
+	if !isSelector {
+		return nil, false, nil
+	}
+
+	moves, err := notation.LegalMoves(boardState)
+	if err != nil || len(moves) == 0 {
+		ai.Logger.Debug("⚠️ %sNo legal moves from board state, falling back to text: %v%s", ColorYellow, err, ColorReset)
+		return nil, false, nil
+	}
+	moves = withoutMoves(moves, excluded)
+
+	// A failover chain bounds each member by its own timeout
+	timeout := 60 * time.Second
+	if chain, isChain := ai.Provider.(*FailoverProvider); isChain {
+		timeout = time.Duration(len(chain.Members)) * timeout
+	}
+	ctx, cancel := context.WithTimeout(ai.baseContext(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	move, err = selector.SelectMove(ctx, MoveRequest{
+		Generate:   request,
+		FEN:        boardState,
+		LegalMoves: moves,
+		Phase:      request.Phase,
+	})
+	ai.trace(traceRecord{Request: request, Move: move, Err: err, Duration: time.Since(start)})
+	return move, true, err
+}
@@ -0,0 +1,25 @@
+package ai_player
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIntOptionFromJSON(t *testing.T) {
+	var options map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"top_k": 20, "num_predict": 64.0, "stop": "x"}`), &options); err != nil {
+		t.Fatal(err)
+	}
+	options["seed"] = 7
+
+	for key, want := range map[string]int{"top_k": 20, "num_predict": 64, "seed": 7} {
+		if got, ok := intOption(options, key); !ok || got != want {
+			t.Errorf("Expected %s to be %d, got %d (%v)", key, want, got, ok)
+		}
+	}
+	for _, key := range []string{"stop", "missing"} {
+		if _, ok := intOption(options, key); ok {
+			t.Errorf("Expected no whole number for %s", key)
+		}
+	}
+}
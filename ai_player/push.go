@@ -0,0 +1,294 @@
+package ai_player
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"chess-tui/jsonrpc"
+)
+
+// ErrCodeTaskNotFound is the A2A error code returned when a task has no
+// push notification config with the given ID
+const ErrCodeTaskNotFound = -32001
+
+// Push notification tuning
+const (
+	pushTimeout      = 10 * time.Second
+	pushAttempts     = 3
+	pushRegistrySize = 256 // tasks whose configs are remembered
+)
+
+// pushTokenHeader carries the client's token so it can tell the server's
+// notifications from forged ones
+const pushTokenHeader = "X-A2A-Notification-Token"
+
+// pushRegistry holds the callbacks clients registered for their tasks and
+// POSTs each task's outcome to them, so correspondence-style clients don't
+// have to hold a request open or poll for the move
+type pushRegistry struct {
+	mu      sync.Mutex
+	configs map[string][]PushNotificationConfig // by task ID
+	order   []string                            // task IDs, oldest first, for eviction
+	size    int
+
+	client  *http.Client
+	backoff time.Duration // wait before the first retry, doubling after
+	logger  *ColoredLogger
+}
+
+// newPushRegistry creates an empty registry
+func newPushRegistry(logger *ColoredLogger) *pushRegistry {
+	return &pushRegistry{
+		configs: make(map[string][]PushNotificationConfig),
+		size:    pushRegistrySize,
+		client:  newHTTPClient(pushTimeout),
+		backoff: time.Second,
+		logger:  logger,
+	}
+}
+
+// set registers a callback for a task, replacing one with the same ID.
+// Configs without an ID are given the task's.
+func (p *pushRegistry) set(taskID string, config PushNotificationConfig) (PushNotificationConfig, error) {
+	if taskID == "" {
+		return config, fmt.Errorf("task ID is required")
+	}
+	if u, err := url.Parse(config.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return config, fmt.Errorf("invalid push notification URL %q", config.Url)
+	}
+	if config.Id == nil || *config.Id == "" {
+		id := taskID
+		config.Id = &id
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	list, ok := p.configs[taskID]
+	if !ok {
+		p.order = append(p.order, taskID)
+	}
+	for i, existing := range list {
+		if *existing.Id == *config.Id {
+			list[i] = config
+			return config, nil
+		}
+	}
+	p.configs[taskID] = append(list, config)
+	p.evict()
+	return config, nil
+}
+
+// get returns a task's callback by ID, or its first one when id is empty
+func (p *pushRegistry) get(taskID, id string) (PushNotificationConfig, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, config := range p.configs[taskID] {
+		if id == "" || *config.Id == id {
+			return config, true
+		}
+	}
+	return PushNotificationConfig{}, false
+}
+
+// list returns a task's callbacks
+func (p *pushRegistry) list(taskID string) []PushNotificationConfig {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PushNotificationConfig(nil), p.configs[taskID]...)
+}
+
+// delete removes a task's callback, reporting whether it was there
+func (p *pushRegistry) delete(taskID, id string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	list := p.configs[taskID]
+	for i, config := range list {
+		if *config.Id == id {
+			p.configs[taskID] = append(list[:i:i], list[i+1:]...)
+			if len(p.configs[taskID]) == 0 {
+				p.forget(taskID)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// notify POSTs a task's state to its callbacks in the background. A task
+// that is done won't be heard of again, so its callbacks are dropped.
+func (p *pushRegistry) notify(task Task) {
+	p.mu.Lock()
+	configs := p.configs[task.Id]
+	if final(task.Status.State) {
+		p.forget(task.Id)
+	}
+	p.mu.Unlock()
+
+	for _, config := range configs {
+		go func() {
+			if err := p.post(config, task); err != nil {
+				p.logger.Warn("⚠️ %sPush notification for task %s failed: %v%s", ColorYellow, task.Id, err, ColorReset)
+				return
+			}
+			p.logger.Info("📬 %sPushed task %s (%s) to %s%s", ColorCyan, task.Id, task.Status.State, config.Url, ColorReset)
+		}()
+	}
+}
+
+// post sends a task to one callback, retrying with backoff when the client
+// can't be reached or doesn't accept it
+func (p *pushRegistry) post(config PushNotificationConfig, task Task) error {
+	body, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+
+	wait := p.backoff
+	for attempt := 1; ; attempt++ {
+		err = p.send(config, body)
+		if err == nil || attempt == pushAttempts {
+			return err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// send makes one notification request
+func (p *pushRegistry) send(config PushNotificationConfig, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, config.Url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if config.Token != nil {
+		req.Header.Set(pushTokenHeader, *config.Token)
+	}
+	if auth := config.Authentication; auth != nil && auth.Credentials != nil {
+		for _, scheme := range auth.Schemes {
+			if strings.EqualFold(scheme, "Bearer") {
+				req.Header.Set("Authorization", "Bearer "+*auth.Credentials)
+			}
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", config.Url, err)
+	}
+	defer resp.Body.Close()
+	drain(resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s answered %s", config.Url, resp.Status)
+	}
+	return nil
+}
+
+// evict drops the oldest tasks beyond the registry size. The caller holds p.mu.
+func (p *pushRegistry) evict() {
+	for len(p.order) > p.size {
+		delete(p.configs, p.order[0])
+		p.order = p.order[1:]
+	}
+}
+
+// forget removes a task's callbacks. The caller holds p.mu.
+func (p *pushRegistry) forget(taskID string) {
+	delete(p.configs, taskID)
+	for i, id := range p.order {
+		if id == taskID {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// final reports whether a task in this state is done
+func final(state TaskState) bool {
+	switch state {
+	case TaskStateCompleted, TaskStateFailed, TaskStateCanceled, TaskStateRejected:
+		return true
+	}
+	return false
+}
+
+// agentTask builds the task the server reports for a request: its state,
+// with the reply's parts as the status message once there is one
+func agentTask(taskID, contextID string, state TaskState, parts []MessagePartsElem) Task {
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	task := Task{
+		Kind:      "task",
+		Id:        taskID,
+		ContextId: contextID,
+		Status:    TaskStatus{State: state, Timestamp: &timestamp},
+	}
+	if parts != nil {
+		task.Status.Message = &Message{
+			Kind:      "message",
+			MessageId: fmt.Sprintf("msg_%d", time.Now().Unix()),
+			Role:      MessageRoleAgent,
+			Parts:     parts,
+			TaskId:    &taskID,
+			ContextId: &contextID,
+		}
+	}
+	return task
+}
+
+// handleJSONRPCPushConfig handles the tasks/pushNotificationConfig methods,
+// which manage a task's callbacks outside of message/send
+func handleJSONRPCPushConfig(call *jsonrpc.Call, pushes *pushRegistry, logger *ColoredLogger) (interface{}, error) {
+	logger.Info("📬 %sReceived A2A %s request%s", ColorCyan, call.Method, ColorReset)
+
+	switch call.Method {
+	case "tasks/pushNotificationConfig/set":
+		var p TaskPushNotificationConfig
+		if err := call.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		config, err := pushes.set(p.TaskId, p.PushNotificationConfig)
+		if err != nil {
+			return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "Invalid params", err.Error())
+		}
+		return TaskPushNotificationConfig{TaskId: p.TaskId, PushNotificationConfig: config}, nil
+	case "tasks/pushNotificationConfig/get":
+		var p GetTaskPushNotificationConfigParams
+		if err := call.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		configID := ""
+		if p.PushNotificationConfigId != nil {
+			configID = *p.PushNotificationConfigId
+		}
+		config, ok := pushes.get(p.Id, configID)
+		if !ok {
+			return nil, jsonrpc.NewError(ErrCodeTaskNotFound, "Task not found", fmt.Sprintf("No push notification config for task %s", p.Id))
+		}
+		return TaskPushNotificationConfig{TaskId: p.Id, PushNotificationConfig: config}, nil
+	case "tasks/pushNotificationConfig/list":
+		var p ListTaskPushNotificationConfigParams
+		if err := call.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		configs := []TaskPushNotificationConfig{}
+		for _, config := range pushes.list(p.Id) {
+			configs = append(configs, TaskPushNotificationConfig{TaskId: p.Id, PushNotificationConfig: config})
+		}
+		return configs, nil
+	default: // delete
+		var p DeleteTaskPushNotificationConfigParams
+		if err := call.DecodeParams(&p); err != nil {
+			return nil, err
+		}
+		if !pushes.delete(p.Id, p.PushNotificationConfigId) {
+			return nil, jsonrpc.NewError(ErrCodeTaskNotFound, "Task not found", fmt.Sprintf("No push notification config %s for task %s", p.PushNotificationConfigId, p.Id))
+		}
+		return nil, nil
+	}
+}
@@ -0,0 +1,132 @@
+package ai_player
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pushCallback is a client's notification endpoint that hands each task it
+// receives, with its token header, to the test
+func pushCallback(t *testing.T) (*httptest.Server, chan Task, chan string) {
+	tasks, tokens := make(chan Task, 4), make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var task Task
+		if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
+			t.Errorf("Failed to decode pushed task: %v", err)
+		}
+		tokens <- r.Header.Get(pushTokenHeader)
+		tasks <- task
+	}))
+	t.Cleanup(server.Close)
+	return server, tasks, tokens
+}
+
+func postJSONRPC(t *testing.T, url string, request map[string]interface{}) map[string]interface{} {
+	body, _ := json.Marshal(request)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	var reply map[string]interface{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		t.Fatalf("Failed to decode reply %s: %v", data, err)
+	}
+	return reply
+}
+
+func TestNonBlockingMoveIsPushed(t *testing.T) {
+	logger := quietLogger()
+	player := &AIPlayer{Provider: NewEngineProvider(), Logger: logger, Model: "engine"}
+	server := httptest.NewServer(handleJSONRPCEndpoint(player, nil, logger))
+	defer server.Close()
+	callback, tasks, tokens := pushCallback(t)
+
+	text, _ := json.Marshal(ChessRequest{BoardState: startFEN, PlayerColor: "white"})
+	reply := postJSONRPC(t, server.URL, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "message/send",
+		"id":      1,
+		"params": map[string]interface{}{
+			"message": map[string]interface{}{
+				"kind":      "message",
+				"messageId": "msg_1",
+				"role":      "user",
+				"parts":     []interface{}{map[string]interface{}{"kind": "text", "text": string(text)}},
+			},
+			"configuration": map[string]interface{}{
+				"blocking":               false,
+				"pushNotificationConfig": map[string]interface{}{"url": callback.URL, "token": "secret"},
+			},
+		},
+	})
+	result, _ := reply["result"].(map[string]interface{})
+	status, _ := result["status"].(map[string]interface{})
+	if result["kind"] != "task" || status["state"] != "submitted" {
+		t.Fatalf("Expected a submitted task right away, got %v", reply)
+	}
+
+	select {
+	case task := <-tasks:
+		if task.Id != "msg_1" || task.Status.State != TaskStateCompleted || task.Status.Message == nil {
+			t.Fatalf("Expected the completed task to be pushed, got %+v", task)
+		}
+		parts, _ := json.Marshal(task.Status.Message.Parts)
+		if !strings.Contains(string(parts), `"move":`) {
+			t.Errorf("Expected the move in the pushed task, got %s", parts)
+		}
+		if token := <-tokens; token != "secret" {
+			t.Errorf("Expected the client's token, got %q", token)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a push notification")
+	}
+}
+
+func TestPushNotificationConfigMethods(t *testing.T) {
+	server := httptest.NewServer(handleJSONRPCEndpoint(nil, nil, quietLogger()))
+	defer server.Close()
+	call := func(method string, params map[string]interface{}) map[string]interface{} {
+		return postJSONRPC(t, server.URL, map[string]interface{}{"jsonrpc": "2.0", "method": method, "id": 1, "params": params})
+	}
+
+	reply := call("tasks/pushNotificationConfig/set", map[string]interface{}{
+		"taskId":                 "task_1",
+		"pushNotificationConfig": map[string]interface{}{"url": "https://client.example/hook"},
+	})
+	if reply["error"] != nil {
+		t.Fatalf("Expected the config to be set, got %v", reply["error"])
+	}
+
+	reply = call("tasks/pushNotificationConfig/get", map[string]interface{}{"id": "task_1"})
+	result, _ := reply["result"].(map[string]interface{})
+	config, _ := result["pushNotificationConfig"].(map[string]interface{})
+	if config["url"] != "https://client.example/hook" || config["id"] != "task_1" {
+		t.Errorf("Expected the config back with the task's ID, got %v", reply)
+	}
+
+	reply = call("tasks/pushNotificationConfig/set", map[string]interface{}{
+		"taskId":                 "task_1",
+		"pushNotificationConfig": map[string]interface{}{"url": "file:///etc/passwd"},
+	})
+	if reply["error"] == nil {
+		t.Error("Expected a non-HTTP callback to be refused")
+	}
+
+	call("tasks/pushNotificationConfig/delete", map[string]interface{}{"id": "task_1", "pushNotificationConfigId": "task_1"})
+	reply = call("tasks/pushNotificationConfig/list", map[string]interface{}{"id": "task_1"})
+	if list, _ := reply["result"].([]interface{}); len(list) != 0 {
+		t.Errorf("Expected no configs after delete, got %v", list)
+	}
+	reply = call("tasks/pushNotificationConfig/get", map[string]interface{}{"id": "task_1"})
+	if e, _ := reply["error"].(map[string]interface{}); e == nil || e["code"] != float64(ErrCodeTaskNotFound) {
+		t.Errorf("Expected task not found, got %v", reply)
+	}
+}
@@ -0,0 +1,137 @@
+package ai_player
+
+import (
+	"context"
+	"sync"
+)
+
+// workerPool limits how many requests the AI works on at once. Requests
+// beyond that wait in a first-come, first-served queue and are told their
+// place in it as it changes. Low-priority requests, such as a kibitzer's,
+// wait behind every other request, including those queued after them.
+type workerPool struct {
+	mu      sync.Mutex
+	free    int
+	waiting []*queuedRequest
+}
+
+// queuedRequest is a request waiting for a worker
+type queuedRequest struct {
+	ready    chan struct{} // closed when the request is given a worker
+	position chan int      // receives the request's new place in the queue
+	low      bool          // waits behind requests that aren't low priority
+}
+
+// newWorkerPool creates a pool of n workers, at least one
+func newWorkerPool(n int) *workerPool {
+	if n < 1 {
+		n = 1
+	}
+	return &workerPool{free: n}
+}
+
+// acquire waits for a worker, calling notify with the request's 1-based
+// place in the queue whenever it changes. It returns a function that gives
+// the worker back, or an error if ctx ends first.
+func (p *workerPool) acquire(ctx context.Context, notify func(position int)) (func(), error) {
+	return p.acquirePriority(ctx, false, notify)
+}
+
+// acquirePriority is acquire for a request that may be low priority
+func (p *workerPool) acquirePriority(ctx context.Context, low bool, notify func(position int)) (func(), error) {
+	p.mu.Lock()
+	if p.free > 0 && len(p.waiting) == 0 {
+		p.free--
+		p.mu.Unlock()
+		return p.release, nil
+	}
+	request := &queuedRequest{ready: make(chan struct{}), position: make(chan int, 1), low: low}
+	p.enqueue(request)
+	p.mu.Unlock()
+
+	for {
+		select {
+		case <-request.ready:
+			return p.release, nil
+		case position := <-request.position:
+			if notify != nil {
+				notify(position)
+			}
+		case <-ctx.Done():
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			select {
+			case <-request.ready:
+				// Given a worker just as ctx ended; pass it on
+				p.releaseLocked()
+			default:
+				p.remove(request)
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// release gives a worker back, handing it to the longest-waiting request
+func (p *workerPool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.releaseLocked()
+}
+
+// releaseLocked is release for callers holding p.mu
+func (p *workerPool) releaseLocked() {
+	if len(p.waiting) == 0 {
+		p.free++
+		return
+	}
+	next := p.waiting[0]
+	p.waiting = p.waiting[1:]
+	close(next.ready)
+	p.renumber()
+}
+
+// enqueue adds a request to the queue: at the back if it is low priority,
+// else ahead of the low-priority requests waiting. The caller holds p.mu.
+func (p *workerPool) enqueue(request *queuedRequest) {
+	at := len(p.waiting)
+	if !request.low {
+		for at > 0 && p.waiting[at-1].low {
+			at--
+		}
+	}
+	p.waiting = append(p.waiting, nil)
+	copy(p.waiting[at+1:], p.waiting[at:])
+	p.waiting[at] = request
+	p.renumber()
+}
+
+// remove drops a request that gave up waiting. The caller holds p.mu.
+func (p *workerPool) remove(request *queuedRequest) {
+	for i, waiting := range p.waiting {
+		if waiting == request {
+			p.waiting = append(p.waiting[:i], p.waiting[i+1:]...)
+			p.renumber()
+			return
+		}
+	}
+}
+
+// renumber tells every waiting request its place in the queue, replacing
+// any update it hasn't read yet. The caller holds p.mu.
+func (p *workerPool) renumber() {
+	for i, request := range p.waiting {
+		select {
+		case <-request.position:
+		default:
+		}
+		request.position <- i + 1
+	}
+}
+
+// queueLength returns the number of requests waiting for a worker
+func (p *workerPool) queueLength() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.waiting)
+}
@@ -0,0 +1,151 @@
+package ai_player
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolQueuePositions(t *testing.T) {
+	pool := newWorkerPool(1)
+	release, err := pool.acquire(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to acquire free worker: %v", err)
+	}
+
+	// Queue two requests behind the busy worker
+	positions := [2]chan int{make(chan int, 4), make(chan int, 4)}
+	acquired := make(chan int, 2)
+	for i := range positions {
+		go func() {
+			release, err := pool.acquire(context.Background(), func(position int) { positions[i] <- position })
+			if err != nil {
+				t.Errorf("Queued request %d failed: %v", i, err)
+				return
+			}
+			acquired <- i
+			release()
+		}()
+		if got := <-positions[i]; got != i+1 {
+			t.Errorf("Expected request %d queued at position %d, got %d", i, i+1, got)
+		}
+	}
+
+	// Freeing the worker serves the first request and moves the second up
+	release()
+	if got := <-acquired; got != 0 {
+		t.Errorf("Expected first queued request to run first, got request %d", got)
+	}
+	if got := <-acquired; got != 1 {
+		t.Errorf("Expected second queued request to run next, got request %d", got)
+	}
+	if got := <-positions[1]; got != 1 {
+		t.Errorf("Expected second request to move to position 1, got %d", got)
+	}
+	if n := pool.queueLength(); n != 0 {
+		t.Errorf("Expected empty queue, got %d waiting", n)
+	}
+}
+
+func TestWorkerPoolCancelLeavesQueue(t *testing.T) {
+	pool := newWorkerPool(1)
+	release, _ := pool.acquire(context.Background(), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.acquire(ctx, nil); err == nil {
+		t.Fatal("Expected acquire to fail when its context ends")
+	}
+	if n := pool.queueLength(); n != 0 {
+		t.Errorf("Expected cancelled request to leave the queue, got %d waiting", n)
+	}
+
+	// The worker is still handed out once freed
+	release()
+	if _, err := pool.acquire(context.Background(), nil); err != nil {
+		t.Errorf("Expected free worker after release, got %v", err)
+	}
+}
+
+func TestWorkerPoolLowPriorityWaitsBehind(t *testing.T) {
+	pool := newWorkerPool(1)
+	release, _ := pool.acquire(context.Background(), nil)
+
+	// A low-priority request queues first, then a normal one overtakes it
+	acquired := make(chan string, 2)
+	queued := make(chan int, 4)
+	go func() {
+		release, err := pool.acquirePriority(context.Background(), true, func(position int) { queued <- position })
+		if err == nil {
+			acquired <- "low"
+			release()
+		}
+	}()
+	if got := <-queued; got != 1 {
+		t.Fatalf("Expected low-priority request at position 1, got %d", got)
+	}
+	go func() {
+		release, err := pool.acquire(context.Background(), nil)
+		if err == nil {
+			acquired <- "normal"
+			release()
+		}
+	}()
+	if got := <-queued; got != 2 {
+		t.Errorf("Expected low-priority request moved back to position 2, got %d", got)
+	}
+
+	release()
+	if got := <-acquired; got != "normal" {
+		t.Errorf("Expected the normal request served first, got %s", got)
+	}
+	if got := <-acquired; got != "low" {
+		t.Errorf("Expected the low-priority request served last, got %s", got)
+	}
+}
+
+func TestMessageStreamSendsStatusThenReply(t *testing.T) {
+	logger := quietLogger()
+	player := &AIPlayer{Provider: NewEngineProvider(), Logger: logger, Model: "engine"}
+	server := httptest.NewServer(handleJSONRPCEndpoint(player, nil, logger))
+	defer server.Close()
+
+	text, _ := json.Marshal(ChessRequest{BoardState: startFEN, PlayerColor: "white"})
+	body, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "message/stream",
+		"id":      1,
+		"params": map[string]interface{}{"message": map[string]interface{}{
+			"kind":      "message",
+			"messageId": "msg_1",
+			"role":      "user",
+			"parts":     []interface{}{map[string]interface{}{"kind": "text", "text": string(text)}},
+		}},
+	})
+	resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected event stream, got %q", ct)
+	}
+	reply, _ := io.ReadAll(resp.Body)
+
+	events := strings.Split(strings.TrimSpace(string(reply)), "\n\n")
+	if len(events) != 2 {
+		t.Fatalf("Expected status and reply events, got %d: %s", len(events), reply)
+	}
+	if !strings.Contains(events[0], `"kind":"status-update"`) || !strings.Contains(events[0], `"state":"working"`) {
+		t.Errorf("Expected working status first, got %s", events[0])
+	}
+	if !strings.Contains(events[1], "Generated move") {
+		t.Errorf("Expected move reply last, got %s", events[1])
+	}
+}
@@ -0,0 +1,46 @@
+package ai_player
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maxReasoningLength caps the reasoning summary returned to clients
+const maxReasoningLength = 400
+
+// summarizeThinking condenses the model's captured thinking into a single
+// trimmed paragraph. Models usually conclude at the end of their trace, so the
+// last paragraph is kept and cut back to whole sentences.
+func summarizeThinking(thinking string) string {
+	thinking = strings.TrimSpace(thinking)
+	if thinking == "" {
+		return ""
+	}
+
+	// Pick the last non-empty paragraph
+	paragraphs := strings.Split(thinking, "\n\n")
+	var paragraph string
+	for i := len(paragraphs) - 1; i >= 0; i-- {
+		if strings.TrimSpace(paragraphs[i]) != "" {
+			paragraph = paragraphs[i]
+			break
+		}
+	}
+
+	// Collapse whitespace into a single line
+	summary := strings.Join(strings.Fields(paragraph), " ")
+	if len(summary) <= maxReasoningLength {
+		return summary
+	}
+
+	// Keep the trailing sentences that fit, since they hold the conclusion
+	start := len(summary) - maxReasoningLength
+	for start < len(summary) && !utf8.RuneStart(summary[start]) {
+		start++ // don't cut a multi-byte rune in half
+	}
+	tail := summary[start:]
+	if idx := strings.Index(tail, ". "); idx >= 0 && idx+2 < len(tail) {
+		return tail[idx+2:]
+	}
+	return "..." + strings.TrimSpace(tail)
+}
@@ -0,0 +1,39 @@
+package ai_player
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSummarizeThinking(t *testing.T) {
+	if summary := summarizeThinking("   "); summary != "" {
+		t.Errorf("Expected empty summary, got %q", summary)
+	}
+
+	thinking := "Let me look at the position.\n\nThe knight on f3 is\nattacked.  Nd4 centralizes it."
+	expected := "The knight on f3 is attacked. Nd4 centralizes it."
+	if summary := summarizeThinking(thinking); summary != expected {
+		t.Errorf("Expected %q, got %q", expected, summary)
+	}
+
+	long := strings.Repeat("This line is filler. ", 40) + "So I play e4."
+	summary := summarizeThinking(long)
+	if len(summary) > maxReasoningLength {
+		t.Errorf("Expected summary of at most %d chars, got %d", maxReasoningLength, len(summary))
+	}
+	if !strings.HasSuffix(summary, "So I play e4.") {
+		t.Errorf("Expected summary to keep the conclusion, got %q", summary)
+	}
+}
+
+func TestSummarizeThinkingCutsWholeRunes(t *testing.T) {
+	// 600 bytes of em-dashes: the cut at 400 from the end falls mid-rune
+	summary := summarizeThinking(strings.Repeat("—", 200))
+	if !utf8.ValidString(summary) {
+		t.Errorf("Expected valid UTF-8, got %q", summary)
+	}
+	if !strings.HasPrefix(summary, "...—") {
+		t.Errorf("Expected the summary to start at a whole rune, got %q", summary)
+	}
+}
@@ -0,0 +1,223 @@
+package ai_player
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+)
+
+// DefaultReloadInterval is how often a watched config file is checked for changes
+const DefaultReloadInterval = 2 * time.Second
+
+// ReadConfig loads a configuration file. Unlike LoadConfig it
+// never creates the file, so a config that disappears is reported as an error,
+// and migrates an older file only in memory.
+func ReadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	config, _, err := decodeConfig(data)
+	return config, err
+}
+
+// ApplyConfig switches the player to a new configuration's model, URL,
+// sampling settings and custom prompts, rebuilding the provider when its
+// settings changed. The player is left unchanged if the config is invalid.
+// It returns a description of what changed.
+func (ai *AIPlayer) ApplyConfig(config *Config) ([]string, error) {
+	if err := config.ValidateConfig(); err != nil {
+		return nil, fmt.Errorf("invalid AI config: %w", err)
+	}
+
+	ai.settingsMu.Lock()
+	defer ai.settingsMu.Unlock()
+
+	previous := ai.currentConfig()
+
+	provider := ai.Provider
+	if !reflect.DeepEqual(providerSettings(previous), providerSettings(config)) {
+		var err error
+		if provider, err = NewProvider(config, ai.Logger); err != nil {
+			return nil, fmt.Errorf("failed to create provider: %w", err)
+		}
+		setParser(provider, ai.parseMove)
+	}
+	repertoire := ai.repertoire
+	if !reflect.DeepEqual(previous.Repertoire, config.Repertoire) {
+		var err error
+		if repertoire, err = newRepertoireBook(config.Repertoire); err != nil {
+			return nil, err
+		}
+	}
+
+	ai.Provider = provider
+	ai.repertoire = repertoire
+	ai.applySettings(config)
+	return configChanges(previous, config), nil
+}
+
+// Config returns a copy of the player's current configuration
+func (ai *AIPlayer) Config() Config {
+	ai.settingsMu.Lock()
+	defer ai.settingsMu.Unlock()
+	return *ai.currentConfig()
+}
+
+// currentConfig returns the config the player was built from or last
+// switched to, or one describing its settings if it was built directly
+func (ai *AIPlayer) currentConfig() *Config {
+	if ai.config != nil {
+		return ai.config
+	}
+	return &Config{
+		OllamaURL:     ai.OllamaURL,
+		Model:         ai.Model,
+		Temperature:   ai.Temperature,
+		TopP:          ai.TopP,
+		CustomPrompts: ai.CustomPrompts,
+	}
+}
+
+// applySettings copies the per-request settings from a config to the player
+func (ai *AIPlayer) applySettings(config *Config) {
+	ai.config = config
+	if config.OllamaURL != "" {
+		ai.OllamaURL = config.OllamaURL
+	}
+	if config.Model != "" {
+		ai.Model = config.Model
+	}
+	ai.Temperature = config.Temperature
+	ai.TopP = config.TopP
+	ai.CustomPrompts = config.CustomPrompts
+
+	options := config.OptionsFor(ai.Model)
+	ai.Think = options.Think
+	ai.MaxThinkingTokens = options.MaxThinkingTokens
+
+	ai.PromptTokenBudget = config.PromptTokenBudget
+	ai.ResignThreshold = config.ResignThreshold
+	ai.DrawMargin = config.DrawMargin
+
+	ai.BlunderRate = config.BlunderRate
+	ai.Bullet = config.Bullet
+	ai.Reviewer = nil
+	if config.ReviewerURL != "" && !config.Bullet {
+		ai.Reviewer = NewReviewer(config.ReviewerURL, config.MaxVetoes)
+	}
+}
+
+// providerSettings returns the parts of a config a provider is built from
+func providerSettings(c *Config) Config {
+	return Config{
+		Provider:   c.Provider,
+		APIBaseURL: c.APIBaseURL,
+		APIKey:     c.APIKey,
+		ModelPath:  c.ModelPath,
+		OllamaURL:  c.OllamaURL,
+		Model:      c.Model,
+		Timeout:    c.Timeout,
+		Logprobs:   c.Logprobs,
+		Providers:  c.Providers,
+		Voters:     bestOf(c.Voters, c.Bullet),
+		Spare:      c.Spare,
+	}
+}
+
+// bestOf returns the vote panel a config plays with: just its first voter
+// in bullet mode
+func bestOf(voters []Config, bullet bool) []Config {
+	if bullet && len(voters) > 1 {
+		return voters[:1]
+	}
+	return voters
+}
+
+// configChanges describes the settings that differ between two configs
+func configChanges(previous, next *Config) []string {
+	var changes []string
+	changed := func(name string, from, to interface{}) {
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, fmt.Sprintf("%s %v → %v", name, from, to))
+		}
+	}
+
+	changed("provider", previous.Provider, next.Provider)
+	changed("model", previous.Model, next.Model)
+	changed("ollama_url", previous.OllamaURL, next.OllamaURL)
+	changed("api_base_url", previous.APIBaseURL, next.APIBaseURL)
+	changed("temperature", previous.Temperature, next.Temperature)
+	changed("top_p", previous.TopP, next.TopP)
+	changed("prompt_token_budget", previous.PromptTokenBudget, next.PromptTokenBudget)
+	changed("resign_threshold", previous.ResignThreshold, next.ResignThreshold)
+	changed("draw_margin", previous.DrawMargin, next.DrawMargin)
+	changed("reviewer_url", previous.ReviewerURL, next.ReviewerURL)
+	changed("max_vetoes", previous.MaxVetoes, next.MaxVetoes)
+	changed("bullet", previous.Bullet, next.Bullet)
+	if !reflect.DeepEqual(previous.CustomPrompts, next.CustomPrompts) {
+		changes = append(changes, "custom prompts")
+	}
+	if !reflect.DeepEqual(previous.Providers, next.Providers) {
+		changes = append(changes, "providers")
+	}
+	if !reflect.DeepEqual(previous.Voters, next.Voters) {
+		changes = append(changes, "voters")
+	}
+	if !reflect.DeepEqual(previous.Repertoire, next.Repertoire) {
+		changes = append(changes, "repertoire")
+	}
+	if !reflect.DeepEqual(previous.OptionsFor(previous.Model), next.OptionsFor(next.Model)) {
+		changes = append(changes, "thinking options")
+	}
+	return changes
+}
+
+// WatchConfig polls a config file and calls apply with each new version
+// until ctx is done. Files that fail to load are logged and skipped, so the
+// last good settings stay in effect.
+func WatchConfig(ctx context.Context, path string, interval time.Duration, logger *ColoredLogger, apply func(*Config) error) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+
+	var lastMod time.Time
+	var lastSize int64
+	if info, err := os.Stat(path); err == nil {
+		lastMod, lastSize = info.ModTime(), info.Size()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			if lastSize != -1 {
+				logger.Warn("⚠️ %sCan't read AI config %s: %v%s", ColorYellow, path, err, ColorReset)
+				lastSize = -1
+			}
+			continue
+		}
+		if info.ModTime().Equal(lastMod) && info.Size() == lastSize {
+			continue
+		}
+		lastMod, lastSize = info.ModTime(), info.Size()
+
+		config, err := ReadConfig(path)
+		if err == nil {
+			err = apply(config)
+		}
+		if err != nil {
+			logger.Error("❌ %sAI config reload failed, keeping previous settings: %v%s", ColorRed, err, ColorReset)
+		}
+	}
+}
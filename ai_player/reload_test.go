@@ -0,0 +1,83 @@
+package ai_player
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyConfig(t *testing.T) {
+	config := DefaultConfig()
+	player, err := NewAIPlayerFromConfig(config, "black", quietLogger())
+	if err != nil {
+		t.Fatalf("Failed to create player: %v", err)
+	}
+
+	next := DefaultConfig()
+	next.Model = "qwen3:8b"
+	next.Temperature = 0.7
+	changes, err := player.ApplyConfig(next)
+	if err != nil {
+		t.Fatalf("Expected the config to apply, got %v", err)
+	}
+	if player.Model != "qwen3:8b" || player.moveOptions()["temperature"] != 0.7 {
+		t.Errorf("Expected model qwen3:8b at temperature 0.7, got %s at %v", player.Model, player.moveOptions()["temperature"])
+	}
+	if got := strings.Join(changes, ", "); got != "model llama3.2:3b → qwen3:8b, temperature 0.1 → 0.7" {
+		t.Errorf("Unexpected changes: %s", got)
+	}
+
+	// Switching provider rebuilds it
+	engine := DefaultConfig()
+	engine.Provider = ProviderEngine
+	if _, err := player.ApplyConfig(engine); err != nil {
+		t.Fatalf("Expected the engine config to apply, got %v", err)
+	}
+	if player.ProviderName() != ProviderEngine {
+		t.Errorf("Expected the engine provider, got %s", player.ProviderName())
+	}
+
+	// An invalid config leaves the player alone
+	invalid := DefaultConfig()
+	invalid.Temperature = 5
+	if _, err := player.ApplyConfig(invalid); err == nil {
+		t.Error("Expected an error for an invalid config")
+	}
+	if player.Temperature != engine.Temperature || player.ProviderName() != ProviderEngine {
+		t.Errorf("Expected the player to keep its settings, got temperature %v with %s", player.Temperature, player.ProviderName())
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ai_config.json")
+	if err := SaveConfig(DefaultConfig(), path); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	models := make(chan string, 4)
+	go WatchConfig(ctx, path, 5*time.Millisecond, quietLogger(), func(config *Config) error {
+		models <- config.Model
+		return nil
+	})
+
+	// Let the watcher note the original file, then change its size as
+	// mtimes can be coarse
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, []byte(`{"model": "a-much-longer-model-name:latest"}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	select {
+	case model := <-models:
+		if model != "a-much-longer-model-name:latest" {
+			t.Errorf("Expected the new model, got %s", model)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the change to be picked up")
+	}
+}
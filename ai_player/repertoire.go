@@ -0,0 +1,257 @@
+package ai_player
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// ProviderRepertoire is named on moves the AI plays from its repertoire
+const ProviderRepertoire = "repertoire"
+
+// Repertoire pins the AI to opening lines, for practicing them against it:
+// while the game is in one of the lines the AI plays its next move without
+// asking the model, and once out of them, or past Moves, the model takes over
+type Repertoire struct {
+	Lines    []string `json:"lines,omitempty"`     // lines in SAN, e.g. "e4 e5 Nf3 Nc6 Bb5"
+	ECO      []string `json:"eco,omitempty"`       // ECO codes of built-in lines, e.g. "C60"
+	PGNFiles []string `json:"pgn_files,omitempty"` // PGN files whose games' moves are lines
+
+	// Moves is how many full moves the repertoire is followed for; 0
+	// follows it to the end of its lines
+	Moves int `json:"moves,omitempty"`
+}
+
+// ecoLine is a named opening line
+type ecoLine struct {
+	name  string
+	moves string
+}
+
+// ecoLines are the openings a repertoire can name by their ECO code
+var ecoLines = map[string]ecoLine{
+	"A04": {"Réti Opening", "Nf3"},
+	"A09": {"Réti Opening", "Nf3 d5 c4"},
+	"A10": {"English Opening", "c4"},
+	"A80": {"Dutch Defence", "d4 f5"},
+	"B01": {"Scandinavian Defence", "e4 d5 exd5 Qxd5 Nc3 Qa5"},
+	"B07": {"Pirc Defence", "e4 d6 d4 Nf6 Nc3 g6"},
+	"B12": {"Caro-Kann, Advance Variation", "e4 c6 d4 d5 e5 Bf5"},
+	"B18": {"Caro-Kann, Classical Variation", "e4 c6 d4 d5 Nc3 dxe4 Nxe4 Bf5"},
+	"B20": {"Sicilian Defence", "e4 c5"},
+	"B33": {"Sicilian, Sveshnikov Variation", "e4 c5 Nf3 Nc6 d4 cxd4 Nxd4 Nf6 Nc3 e5"},
+	"B70": {"Sicilian, Dragon Variation", "e4 c5 Nf3 d6 d4 cxd4 Nxd4 Nf6 Nc3 g6"},
+	"B90": {"Sicilian, Najdorf Variation", "e4 c5 Nf3 d6 d4 cxd4 Nxd4 Nf6 Nc3 a6"},
+	"C00": {"French Defence", "e4 e6"},
+	"C02": {"French, Advance Variation", "e4 e6 d4 d5 e5"},
+	"C11": {"French, Classical Variation", "e4 e6 d4 d5 Nc3 Nf6"},
+	"C30": {"King's Gambit", "e4 e5 f4"},
+	"C42": {"Petrov's Defence", "e4 e5 Nf3 Nf6"},
+	"C45": {"Scotch Game", "e4 e5 Nf3 Nc6 d4 exd4 Nxd4"},
+	"C50": {"Italian Game", "e4 e5 Nf3 Nc6 Bc4 Bc5"},
+	"C54": {"Giuoco Piano", "e4 e5 Nf3 Nc6 Bc4 Bc5 c3 Nf6 d4"},
+	"C60": {"Ruy Lopez", "e4 e5 Nf3 Nc6 Bb5"},
+	"C65": {"Ruy Lopez, Berlin Defence", "e4 e5 Nf3 Nc6 Bb5 Nf6"},
+	"C84": {"Ruy Lopez, Closed", "e4 e5 Nf3 Nc6 Bb5 a6 Ba4 Nf6 O-O Be7"},
+	"D02": {"London System", "d4 d5 Nf3 Nf6 Bf4"},
+	"D06": {"Queen's Gambit", "d4 d5 c4"},
+	"D10": {"Slav Defence", "d4 d5 c4 c6"},
+	"D20": {"Queen's Gambit Accepted", "d4 d5 c4 dxc4"},
+	"D30": {"Queen's Gambit Declined", "d4 d5 c4 e6"},
+	"D80": {"Grünfeld Defence", "d4 Nf6 c4 g6 Nc3 d5"},
+	"E12": {"Queen's Indian Defence", "d4 Nf6 c4 e6 Nf3 b6"},
+	"E20": {"Nimzo-Indian Defence", "d4 Nf6 c4 e6 Nc3 Bb4"},
+	"E60": {"King's Indian Defence", "d4 Nf6 c4 g6"},
+	"E90": {"King's Indian, Classical Variation", "d4 Nf6 c4 g6 Nc3 Bg7 e4 d6 Nf3 O-O"},
+}
+
+// ECOCodes returns the ECO codes a repertoire can name, in order
+func ECOCodes() []string {
+	codes := make([]string, 0, len(ecoLines))
+	for code := range ecoLines {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// repertoireMove is a move of a repertoire line, with the line's name
+type repertoireMove struct {
+	san  string
+	line string
+}
+
+// repertoireBook maps positions, by bookKey, to the repertoire's moves
+// from them
+type repertoireBook struct {
+	moves    map[string][]repertoireMove
+	maxMoves int
+}
+
+// validate checks that the repertoire's ECO codes are known
+func (r *Repertoire) validate() error {
+	for _, code := range r.ECO {
+		if _, ok := ecoLines[strings.ToUpper(code)]; !ok {
+			return fmt.Errorf("unknown ECO code %q", code)
+		}
+	}
+	if r.Moves < 0 {
+		return fmt.Errorf("moves cannot be negative")
+	}
+	return nil
+}
+
+// loadRepertoire replays a repertoire's lines into a book
+func loadRepertoire(r *Repertoire) (*repertoireBook, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	book := &repertoireBook{moves: make(map[string][]repertoireMove), maxMoves: r.Moves}
+	for i, line := range r.Lines {
+		if err := book.add(chess.NewGame(), strings.Fields(line), ""); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	for _, code := range r.ECO {
+		code = strings.ToUpper(code)
+		opening := ecoLines[code]
+		if err := book.add(chess.NewGame(), strings.Fields(opening.moves), code+" "+opening.name); err != nil {
+			return nil, fmt.Errorf("%s: %w", code, err)
+		}
+	}
+	for _, path := range r.PGNFiles {
+		if err := book.addPGN(path); err != nil {
+			return nil, err
+		}
+	}
+	return book, nil
+}
+
+// add plays a line in SAN from the game's position, adding each move
+func (b *repertoireBook) add(game *chess.Game, line []string, name string) error {
+	for _, text := range line {
+		move, err := notation.Decode(game.Position(), text)
+		if err != nil {
+			return fmt.Errorf("%s: %w", text, err)
+		}
+		key := bookKey(game.Position().String())
+		san := notation.Encode(game.Position(), move)
+		if !b.has(key, san) {
+			b.moves[key] = append(b.moves[key], repertoireMove{san: san, line: name})
+		}
+		game.Move(move)
+	}
+	return nil
+}
+
+// addPGN adds the moves of every game in a PGN file, each named by its
+// ECO and Opening tags if it has them
+func (b *repertoireBook) addPGN(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open repertoire PGN: %w", err)
+	}
+	defer file.Close()
+
+	scanner := chess.NewScanner(file)
+	for scanner.Scan() {
+		played := scanner.Next()
+		var name []string
+		for _, tag := range []string{"ECO", "Opening"} {
+			if pair := played.GetTagPair(tag); pair != nil && pair.Value != "" && pair.Value != "?" {
+				name = append(name, pair.Value)
+			}
+		}
+		start := chess.NewGame()
+		if fen := played.GetTagPair("FEN"); fen != nil {
+			option, err := chess.FEN(fen.Value)
+			if err != nil {
+				return fmt.Errorf("invalid FEN in repertoire PGN: %w", err)
+			}
+			start = chess.NewGame(option)
+		}
+		if err := b.add(start, sanMovesOf(played), strings.Join(name, " ")); err != nil {
+			return fmt.Errorf("repertoire PGN: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read repertoire PGN: %w", err)
+	}
+	return nil
+}
+
+// sanMovesOf returns a game's moves in SAN
+func sanMovesOf(game *chess.Game) []string {
+	positions := game.Positions()
+	sans := make([]string, len(game.Moves()))
+	for i, move := range game.Moves() {
+		sans[i] = notation.Encode(positions[i], move)
+	}
+	return sans
+}
+
+// has reports whether san is already a repertoire move from key
+func (b *repertoireBook) has(key, san string) bool {
+	for _, move := range b.moves[key] {
+		if move.san == san {
+			return true
+		}
+	}
+	return false
+}
+
+// move picks one of the repertoire's moves for the FEN position, if it is
+// in the repertoire and within its moves
+func (b *repertoireBook) move(fen string) (repertoireMove, bool) {
+	if b.maxMoves > 0 {
+		fields := strings.Fields(fen)
+		if len(fields) == 6 {
+			if number, err := strconv.Atoi(fields[5]); err == nil && number > b.maxMoves {
+				return repertoireMove{}, false
+			}
+		}
+	}
+	moves := b.moves[bookKey(fen)]
+	if len(moves) == 0 {
+		return repertoireMove{}, false
+	}
+	return moves[rand.Intn(len(moves))], true
+}
+
+// fromRepertoire answers from the player's repertoire, if the game is
+// still in it
+func (ai *AIPlayer) fromRepertoire(boardState string) (*ChessMove, bool) {
+	if ai.repertoire == nil {
+		return nil, false
+	}
+	move, ok := ai.repertoire.move(boardState)
+	if !ok {
+		return nil, false
+	}
+	ai.Logger.Debug("📖 %sRepertoire move: %s%s", ColorGreen, move.san, ColorReset)
+	reasoning := "Repertoire move"
+	if move.line != "" {
+		reasoning += " (" + move.line + ")"
+	}
+	return &ChessMove{Notation: move.san, Reasoning: reasoning, Provider: ProviderRepertoire}, true
+}
+
+// newRepertoireBook loads a config's repertoire, nil for none
+func newRepertoireBook(r *Repertoire) (*repertoireBook, error) {
+	if r == nil {
+		return nil, nil
+	}
+	book, err := loadRepertoire(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repertoire: %w", err)
+	}
+	return book, nil
+}
@@ -0,0 +1,99 @@
+package ai_player
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+// fenAfter returns the FEN after playing moves in SAN from the start
+func fenAfter(t *testing.T, moves ...string) string {
+	t.Helper()
+	game := chess.NewGame(chess.UseNotation(chess.AlgebraicNotation{}))
+	for _, move := range moves {
+		if err := game.MoveStr(move); err != nil {
+			t.Fatalf("Failed to play %s: %v", move, err)
+		}
+	}
+	return game.Position().String()
+}
+
+func TestRepertoireFollowedUntilOutOfBook(t *testing.T) {
+	model := &fakeProvider{name: "model", reply: "a7a6"}
+	player := NewAIPlayer("", "m", "black", nil)
+	player.Provider = model
+	var err error
+	if player.repertoire, err = newRepertoireBook(&Repertoire{ECO: []string{"b90"}}); err != nil {
+		t.Fatalf("Failed to load repertoire: %v", err)
+	}
+
+	move, err := player.GetMove(fenAfter(t, "e4", "c5", "Nf3"), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Notation != "d6" || move.Provider != ProviderRepertoire || !strings.Contains(move.Reasoning, "Najdorf") {
+		t.Errorf("Expected the Najdorf's d6 from the repertoire, got %s from %s (%s)", move.Notation, move.Provider, move.Reasoning)
+	}
+	if model.calls != 0 {
+		t.Errorf("Expected the model not to be asked in book, got %d calls", model.calls)
+	}
+
+	// Out of book the model answers
+	move, err = player.GetMove(fenAfter(t, "e4", "c5", "b4"), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Provider != "model" || model.calls != 1 {
+		t.Errorf("Expected the model to answer out of book, got %s after %d calls", move.Provider, model.calls)
+	}
+}
+
+func TestRepertoireMovesLimit(t *testing.T) {
+	book, err := newRepertoireBook(&Repertoire{Lines: []string{"e4 e5 Nf3 Nc6 Bb5 a6"}, Moves: 2})
+	if err != nil {
+		t.Fatalf("Failed to load repertoire: %v", err)
+	}
+	if move, ok := book.move(fenAfter(t, "e4", "e5")); !ok || move.san != "Nf3" {
+		t.Errorf("Expected Nf3 on move 2, got %q", move.san)
+	}
+	if _, ok := book.move(fenAfter(t, "e4", "e5", "Nf3", "Nc6")); ok {
+		t.Error("Expected the repertoire to end after 2 moves")
+	}
+}
+
+func TestRepertoireFromPGN(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repertoire.pgn")
+	pgn := `[Event "Practice"]
+[ECO "C65"]
+[Opening "Berlin Defence"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 Nf6 4. O-O Nxe4 *
+`
+	if err := os.WriteFile(path, []byte(pgn), 0644); err != nil {
+		t.Fatal(err)
+	}
+	book, err := newRepertoireBook(&Repertoire{PGNFiles: []string{path}})
+	if err != nil {
+		t.Fatalf("Failed to load repertoire: %v", err)
+	}
+	move, ok := book.move(fenAfter(t, "e4", "e5", "Nf3", "Nc6", "Bb5", "Nf6", "O-O"))
+	if !ok || move.san != "Nxe4" || move.line != "C65 Berlin Defence" {
+		t.Errorf("Expected Nxe4 from the C65 Berlin Defence, got %+v", move)
+	}
+}
+
+func TestRepertoireValidation(t *testing.T) {
+	if _, err := newRepertoireBook(&Repertoire{ECO: []string{"Z99"}}); err == nil {
+		t.Error("Expected an unknown ECO code to be refused")
+	}
+	if _, err := newRepertoireBook(&Repertoire{Lines: []string{"e4 e5 Ke3"}}); err == nil {
+		t.Error("Expected an illegal line to be refused")
+	}
+	// Every built-in line is legal
+	if _, err := newRepertoireBook(&Repertoire{ECO: ECOCodes()}); err != nil {
+		t.Errorf("Expected the built-in lines to load, got %v", err)
+	}
+}
@@ -0,0 +1,345 @@
+package ai_player
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"chess-tui/jsonrpc"
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// TaskReview asks the agent to judge a move another agent proposed instead
+// of choosing one itself
+const TaskReview = "review"
+
+// defaultMaxVetoes is how many times a reviewer may send a move back when
+// the config doesn't say
+const defaultMaxVetoes = 2
+
+// reviewTimeout bounds one call to the reviewer; a reviewer that doesn't
+// answer in time is skipped and the move played as is
+const reviewTimeout = 30 * time.Second
+
+// engineReviewMargin is how many centipawns worse than the best move the
+// built-in engine lets a move be before vetoing it
+const engineReviewMargin = 100
+
+// Review is a reviewer's verdict on a proposed move
+type Review struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Veto is a proposed move a reviewer rejected, and why
+type Veto struct {
+	Move   string `json:"move"`
+	Reason string `json:"reason"`
+}
+
+// Reviewer is a second A2A agent consulted about each move before it is
+// played. It can veto the move, sending the player back for another.
+type Reviewer struct {
+	URL       string // the reviewer's A2A endpoint, e.g. http://localhost:8081/a2a
+	MaxVetoes int
+	Client    *http.Client
+}
+
+// NewReviewer creates a client for the reviewer agent at url. maxVetoes of
+// 0 uses the default.
+func NewReviewer(url string, maxVetoes int) *Reviewer {
+	if maxVetoes <= 0 {
+		maxVetoes = defaultMaxVetoes
+	}
+	return &Reviewer{
+		URL:       url,
+		MaxVetoes: maxVetoes,
+		Client:    newHTTPClient(reviewTimeout),
+	}
+}
+
+// Review asks the reviewer agent whether move is a good one for the side to
+// move in req's position
+func (r *Reviewer) Review(ctx context.Context, req ChessRequest, move string) (*Review, error) {
+	request := ChessRequest{
+		BoardState:  req.BoardState,
+		PlayerColor: req.PlayerColor,
+		GameHistory: req.GameHistory,
+		Task:        TaskReview,
+		Move:        move,
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode review request: %w", err)
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": jsonrpc.Version,
+		"id":      fmt.Sprintf("review_%d", time.Now().UnixNano()),
+		"method":  "message/send",
+		"params": map[string]interface{}{
+			"message": map[string]interface{}{
+				"kind":      "message",
+				"messageId": fmt.Sprintf("review_%d", time.Now().UnixNano()),
+				"role":      "user",
+				"parts":     []interface{}{map[string]interface{}{"kind": "data", "data": json.RawMessage(payload)}},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode review request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reviewTimeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create review request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach reviewer: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		Result struct {
+			Parts []struct {
+				Kind string          `json:"kind"`
+				Data json.RawMessage `json:"data"`
+			} `json:"parts"`
+		} `json:"result"`
+		Error *jsonrpc.Error `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return nil, fmt.Errorf("failed to decode reviewer reply: %w", err)
+	}
+	if reply.Error != nil {
+		return nil, fmt.Errorf("reviewer refused the review: %w", reply.Error)
+	}
+	for _, part := range reply.Result.Parts {
+		if part.Kind != "data" {
+			continue
+		}
+		var review Review
+		if err := json.Unmarshal(part.Data, &review); err == nil {
+			return &review, nil
+		}
+	}
+	return nil, fmt.Errorf("reviewer reply has no verdict")
+}
+
+// reviewedMove asks the player for a move and the reviewer, if there is
+// one, whether to play it. A vetoed move is sent back to the player with
+// the reviewer's reason until the reviewer approves or runs out of vetoes,
+// when the last proposal is played. A reviewer that can't be reached is
+// skipped.
+func reviewedMove(req ChessRequest, aiPlayer *AIPlayer, logger *ColoredLogger) (*ChessMove, []Veto, error) {
+	reviewer := aiPlayer.Reviewer
+	var vetoes []Veto
+	for {
+		move, err := aiPlayer.ReviseMove(req.BoardState, req.GameHistory, vetoes)
+		if err != nil {
+			return nil, vetoes, err
+		}
+
+		// Reply in SAN, and refuse illegal moves so the client asks again
+		san, err := notation.Normalize(req.BoardState, move.Notation)
+		switch {
+		case err == nil:
+			move.Notation = san
+		case !errors.Is(err, notation.ErrFEN):
+			logger.Warn("⚠️ %sAI move %s rejected: %v%s", ColorYellow, move.Notation, err, ColorReset)
+			return nil, vetoes, err
+		}
+
+		if reviewer == nil || len(vetoes) >= reviewer.MaxVetoes {
+			return move, vetoes, nil
+		}
+		review, err := reviewer.Review(aiPlayer.baseContext(), req, move.Notation)
+		if err != nil {
+			logger.Warn("⚠️ %sSkipping review of %s: %v%s", ColorYellow, move.Notation, err, ColorReset)
+			return move, vetoes, nil
+		}
+		if review.Approved {
+			logger.Info("👍 %sReviewer approved %s%s", ColorGreen, move.Notation, ColorReset)
+			return move, vetoes, nil
+		}
+		logger.Info("✋ %sReviewer vetoed %s: %s%s", ColorYellow, move.Notation, review.Reason, ColorReset)
+		vetoes = append(vetoes, Veto{Move: move.Notation, Reason: review.Reason})
+	}
+}
+
+// ReviewMove judges a move proposed for the side to move. The built-in
+// engine reviews by material; a model is asked to approve or veto it.
+func (ai *AIPlayer) ReviewMove(boardState string, gameHistory []string, move string) (*Review, error) {
+	san, err := notation.Normalize(boardState, move)
+	if err != nil {
+		if errors.Is(err, notation.ErrFEN) {
+			return nil, err
+		}
+		return &Review{Reason: fmt.Sprintf("%s is not a legal move here", move)}, nil
+	}
+
+	if _, isEngine := ai.Provider.(*EngineProvider); isEngine {
+		return engineReview(boardState, san)
+	}
+
+	request := OllamaRequest{
+		Model:  ai.Model,
+		Prompt: ai.buildReviewPrompt(boardState, gameHistory, san),
+		Stream: false,
+		Options: map[string]interface{}{
+			"temperature": 0.2,
+			"top_p":       0.9,
+		},
+		Think: ai.Think,
+
+		maxThinking: ai.MaxThinkingTokens,
+	}
+	response, err := ai.generate(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", ai.ProviderName(), err)
+	}
+	return parseReview(response.Response)
+}
+
+// buildReviewPrompt creates the prompt asking the model to approve or veto a move
+func (ai *AIPlayer) buildReviewPrompt(boardState string, gameHistory []string, move string) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("You are a strong chess coach reviewing a move your student, playing ")
+	prompt.WriteString(ai.Color)
+	prompt.WriteString(", wants to play.\n\n")
+
+	prompt.WriteString("Current board position (FEN):\n")
+	prompt.WriteString(boardState)
+	prompt.WriteString("\n\n")
+
+	if len(gameHistory) > 0 {
+		start := len(gameHistory) - 6
+		if start < 0 {
+			start = 0
+		}
+		prompt.WriteString("Recent moves: ")
+		prompt.WriteString(strings.Join(gameHistory[start:], " "))
+		prompt.WriteString("\n\n")
+	}
+
+	prompt.WriteString("Proposed move: ")
+	prompt.WriteString(move)
+	prompt.WriteString("\n\n")
+
+	prompt.WriteString("INSTRUCTIONS:\n")
+	prompt.WriteString("1. Check whether the move hangs a piece, walks into mate or misses a mate or free capture\n")
+	prompt.WriteString("2. Approve any reasonable move; veto only clear mistakes\n")
+	prompt.WriteString("3. Answer on one line, either APPROVE: <reason> or VETO: <reason>\n\n")
+
+	prompt.WriteString("Verdict: ")
+	return prompt.String()
+}
+
+// parseReview reads an APPROVE or VETO verdict from a model's reply
+func parseReview(response string) (*Review, error) {
+	for _, line := range strings.Split(response, "\n") {
+		verdict, reason, _ := strings.Cut(line, ":")
+		switch strings.ToUpper(strings.Trim(verdict, "* \t")) {
+		case "APPROVE", "APPROVED":
+			return &Review{Approved: true, Reason: strings.TrimSpace(reason)}, nil
+		case "VETO", "VETOED":
+			return &Review{Reason: strings.TrimSpace(reason)}, nil
+		}
+	}
+	return nil, fmt.Errorf("no APPROVE or VETO verdict in response: %s", response)
+}
+
+// engineReview vetoes a move the built-in engine scores well below its own choice
+func engineReview(boardState, san string) (*Review, error) {
+	fenOption, err := chess.FEN(boardState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FEN: %w", err)
+	}
+	position := chess.NewGame(fenOption).Position()
+
+	var proposed *chess.Move
+	best, bestScore := "", -engineMateScore*2
+	for _, move := range position.ValidMoves() {
+		score := scoreMove(position, move, true)
+		if score > bestScore {
+			best, bestScore = notation.Encode(position, move), score
+		}
+		if notation.Encode(position, move) == san {
+			proposed = move
+		}
+	}
+	if proposed == nil {
+		return &Review{Reason: fmt.Sprintf("%s is not a legal move here", san)}, nil
+	}
+
+	if loss := bestScore - scoreMove(position, proposed, true); loss > engineReviewMargin {
+		return &Review{Reason: fmt.Sprintf("%s gives up %d centipawns compared with %s", san, loss, best)}, nil
+	}
+	return &Review{Approved: true, Reason: "no material is lost"}, nil
+}
+
+// withVetoes adds the moves a reviewer rejected to a move prompt, ahead of
+// its closing line
+func withVetoes(prompt string, vetoes []Veto) string {
+	if len(vetoes) == 0 {
+		return prompt
+	}
+	var note strings.Builder
+	note.WriteString("REJECTED MOVES (a reviewer vetoed these; choose a different move):\n")
+	for _, veto := range vetoes {
+		note.WriteString(fmt.Sprintf("- %s: %s\n", veto.Move, veto.Reason))
+	}
+	note.WriteString("\n")
+
+	i := strings.LastIndex(prompt, "\n\n")
+	if i < 0 {
+		return prompt + "\n\n" + note.String()
+	}
+	return prompt[:i+2] + note.String() + prompt[i+2:]
+}
+
+// vetoedMoves returns the moves of vetoes
+func vetoedMoves(vetoes []Veto) []string {
+	moves := make([]string, len(vetoes))
+	for i, veto := range vetoes {
+		moves[i] = veto.Move
+	}
+	return moves
+}
+
+// withoutMoves removes the excluded moves from moves, unless that would
+// leave none
+func withoutMoves(moves, excluded []string) []string {
+	if len(excluded) == 0 {
+		return moves
+	}
+	var kept []string
+	for _, move := range moves {
+		skip := false
+		for _, e := range excluded {
+			if move == e {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			kept = append(kept, move)
+		}
+	}
+	if len(kept) == 0 {
+		return moves
+	}
+	return kept
+}
@@ -0,0 +1,145 @@
+package ai_player
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// hangingQueenFEN has White's queen able to step to d4, where the knight takes it
+const hangingQueenFEN = "4k3/8/2n5/8/8/8/8/3QK3 w - - 0 1"
+
+// scriptedProvider replies with each of its replies in turn, keeping the prompts
+type scriptedProvider struct {
+	replies []string
+	prompts []string
+}
+
+func (p *scriptedProvider) Name() string { return "scripted" }
+
+func (p *scriptedProvider) Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error) {
+	p.prompts = append(p.prompts, request.Prompt)
+	reply := p.replies[0]
+	if len(p.replies) > 1 {
+		p.replies = p.replies[1:]
+	}
+	return &OllamaResponse{Response: reply}, nil
+}
+
+func (p *scriptedProvider) TestConnection() error { return nil }
+
+// startReviewer serves an A2A agent that reviews moves with the built-in engine
+func startReviewer(t *testing.T) *httptest.Server {
+	reviewer := &AIPlayer{Provider: NewEngineProvider(), Logger: quietLogger(), Model: "engine"}
+	server := httptest.NewServer(handleJSONRPCEndpoint(reviewer, nil, quietLogger()))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReviewerVetoSendsPlayerBack(t *testing.T) {
+	reviewer := startReviewer(t)
+	provider := &scriptedProvider{replies: []string{"Qd4", "Qd2"}}
+	player := &AIPlayer{Provider: provider, Logger: quietLogger(), Model: "scripted", Reviewer: NewReviewer(reviewer.URL, 0)}
+
+	result, err := processChessRequest(ChessRequest{BoardState: hangingQueenFEN, PlayerColor: "white"}, player, quietLogger())
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if result.Move != "Qd2" {
+		t.Errorf("Expected the revised move Qd2, got %s", result.Move)
+	}
+	if len(result.Vetoes) != 1 || result.Vetoes[0].Move != "Qd4" || result.Vetoes[0].Reason == "" {
+		t.Fatalf("Expected one veto of Qd4 with a reason, got %+v", result.Vetoes)
+	}
+	if len(provider.prompts) != 2 || !strings.Contains(provider.prompts[1], "REJECTED MOVES") || !strings.Contains(provider.prompts[1], "- Qd4: ") {
+		t.Errorf("Expected the second prompt to name the vetoed move, got %q", provider.prompts)
+	}
+}
+
+func TestReviewerOutOfVetoesPlaysLastProposal(t *testing.T) {
+	reviewer := startReviewer(t)
+	provider := &scriptedProvider{replies: []string{"Qd4"}}
+	player := &AIPlayer{Provider: provider, Logger: quietLogger(), Model: "scripted", Reviewer: NewReviewer(reviewer.URL, 1)}
+
+	result, err := processChessRequest(ChessRequest{BoardState: hangingQueenFEN, PlayerColor: "white"}, player, quietLogger())
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if result.Move != "Qd4" || len(result.Vetoes) != 1 {
+		t.Errorf("Expected Qd4 played after one veto, got %s with %+v", result.Move, result.Vetoes)
+	}
+
+	// A reviewer that can't be reached doesn't hold up the game
+	player.Reviewer = NewReviewer("http://127.0.0.1:1/a2a", 0)
+	result, err = processChessRequest(ChessRequest{BoardState: hangingQueenFEN, PlayerColor: "white"}, player, quietLogger())
+	if err != nil || result.Move != "Qd4" || len(result.Vetoes) != 0 {
+		t.Errorf("Expected the move unreviewed, got %+v, %v", result, err)
+	}
+}
+
+func TestEngineSelectorAvoidsVetoedMoves(t *testing.T) {
+	player := &AIPlayer{Provider: NewEngineProvider(), Logger: quietLogger(), Model: "engine"}
+	first, err := player.GetMove(startFEN, nil)
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	second, err := player.ReviseMove(startFEN, nil, []Veto{{Move: first.Notation, Reason: "too slow"}})
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if second.Notation == first.Notation {
+		t.Errorf("Expected a move other than the vetoed %s", first.Notation)
+	}
+}
+
+func TestReviewTaskOverA2A(t *testing.T) {
+	reviewer := startReviewer(t)
+	for _, tt := range []struct {
+		move     string
+		approved bool
+	}{
+		{"Qd4", false},
+		{"Qd2", true},
+		{"Qh8", false}, // not legal
+	} {
+		review, err := NewReviewer(reviewer.URL, 0).Review(context.Background(), ChessRequest{BoardState: hangingQueenFEN, PlayerColor: "white"}, tt.move)
+		if err != nil {
+			t.Fatalf("%s: expected a verdict, got %v", tt.move, err)
+		}
+		if review.Approved != tt.approved || review.Reason == "" {
+			t.Errorf("%s: expected approved=%t with a reason, got %+v", tt.move, tt.approved, review)
+		}
+	}
+
+	reply := string(postChessRequest(t, reviewer.URL, "", ChessRequest{BoardState: hangingQueenFEN, Task: TaskReview}))
+	if !strings.Contains(reply, `"field":"params.message.parts[0].text.move"`) {
+		t.Errorf("Expected a review without a move to be refused, got %s", reply)
+	}
+}
+
+func TestParseReview(t *testing.T) {
+	tests := []struct {
+		response string
+		approved bool
+		reason   string
+	}{
+		{"APPROVE: develops a piece", true, "develops a piece"},
+		{"**VETO**: hangs the queen to Nxd4", false, "hangs the queen to Nxd4"},
+		{"Let me look.\nveto: walks into mate", false, "walks into mate"},
+		{"Approved", true, ""},
+	}
+	for _, tt := range tests {
+		review, err := parseReview(tt.response)
+		if err != nil {
+			t.Errorf("%q: expected a verdict, got %v", tt.response, err)
+			continue
+		}
+		if review.Approved != tt.approved || review.Reason != tt.reason {
+			t.Errorf("%q: expected %t %q, got %t %q", tt.response, tt.approved, tt.reason, review.Approved, review.Reason)
+		}
+	}
+	if _, err := parseReview("Looks fine to me"); err == nil {
+		t.Errorf("Expected an error for a reply without a verdict")
+	}
+}
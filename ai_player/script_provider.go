@@ -0,0 +1,285 @@
+package ai_player
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// ProviderScript is a bot written in Starlark, picked by "script_path"
+const ProviderScript = "script"
+
+// scriptMaxSteps bounds the work of one call into a script, so that a
+// runaway loop can't hang the game
+const scriptMaxSteps = 10_000_000
+
+// ScriptProvider plays the moves of a bot written in Starlark, a small
+// Python dialect. The script defines
+//
+//	def move(fen, legal_moves): return "Nf3"
+//
+// returning one of the legal moves in SAN or UCI, and may define
+//
+//	def filter(fen, legal_moves): return [m for m in legal_moves if ...]
+//
+// to narrow the moves before move is called. A script with only a filter
+// has the built-in engine choose among the moves it keeps. The chess module
+// offers helpers: chess.random, chess.material, chess.after, chess.turn,
+// chess.is_capture and chess.is_check.
+type ScriptProvider struct {
+	path   string
+	move   starlark.Callable // nil if the script has only a filter
+	filter starlark.Callable // nil if the script has none
+}
+
+// NewScriptProvider loads the bot script at path
+func NewScriptProvider(path string) (*ScriptProvider, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bot script: %w", err)
+	}
+	provider := &ScriptProvider{path: path}
+	thread := provider.thread()
+	globals, err := starlark.ExecFile(thread, path, source, starlark.StringDict{"chess": scriptChessModule})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bot script: %w", err)
+	}
+	provider.move, _ = globals["move"].(starlark.Callable)
+	provider.filter, _ = globals["filter"].(starlark.Callable)
+	if provider.move == nil && provider.filter == nil {
+		return nil, fmt.Errorf("bot script %s defines neither move(fen, legal_moves) nor filter(fen, legal_moves)", path)
+	}
+	return provider, nil
+}
+
+// Name identifies the provider
+func (s *ScriptProvider) Name() string {
+	return ProviderScript
+}
+
+// Generate is unsupported; scripts only select moves
+func (s *ScriptProvider) Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error) {
+	return nil, fmt.Errorf("a bot script does not generate text")
+}
+
+// TestConnection always succeeds; the script was checked when loaded
+func (s *ScriptProvider) TestConnection() error {
+	return nil
+}
+
+// SelectMove runs the script's filter and move functions on the position
+func (s *ScriptProvider) SelectMove(ctx context.Context, request MoveRequest) (*ChessMove, error) {
+	if request.Variant != "" {
+		return nil, fmt.Errorf("bot scripts only play standard chess, not %s", request.Variant)
+	}
+	moves := request.LegalMoves
+	if len(moves) == 0 {
+		var err error
+		if moves, err = notation.LegalMoves(request.FEN); err != nil {
+			return nil, err
+		}
+	}
+
+	thread := s.thread()
+	stop := context.AfterFunc(ctx, func() { thread.Cancel(ctx.Err().Error()) })
+	defer stop()
+
+	if s.filter != nil {
+		value, err := starlark.Call(thread, s.filter, starlark.Tuple{starlark.String(request.FEN), stringList(moves)}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("bot script filter failed: %w", err)
+		}
+		kept, err := s.keptMoves(request.FEN, value, moves)
+		if err != nil {
+			return nil, err
+		}
+		// A filter that rules out every move is ignored
+		if len(kept) > 0 {
+			moves = kept
+		}
+	}
+
+	if s.move == nil {
+		return NewEngineProvider().SelectMove(ctx, MoveRequest{FEN: request.FEN, LegalMoves: moves})
+	}
+	value, err := starlark.Call(thread, s.move, starlark.Tuple{starlark.String(request.FEN), stringList(moves)}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("bot script move failed: %w", err)
+	}
+	text, ok := starlark.AsString(value)
+	if !ok {
+		return nil, fmt.Errorf("bot script move returned %s, want a string", value.Type())
+	}
+	san, err := notation.Normalize(request.FEN, text)
+	if err != nil {
+		return nil, fmt.Errorf("bot script chose %q: %w", text, err)
+	}
+	if !containsMove(moves, san) {
+		return nil, fmt.Errorf("bot script chose %s, which isn't allowed", san)
+	}
+	return &ChessMove{Notation: san}, nil
+}
+
+// keptMoves reads the moves a filter kept, in SAN
+func (s *ScriptProvider) keptMoves(fen string, value starlark.Value, allowed []string) ([]string, error) {
+	iterable, ok := value.(starlark.Iterable)
+	if !ok {
+		return nil, fmt.Errorf("bot script filter returned %s, want a list of moves", value.Type())
+	}
+	iter := iterable.Iterate()
+	defer iter.Done()
+	var kept []string
+	var item starlark.Value
+	for iter.Next(&item) {
+		text, ok := starlark.AsString(item)
+		if !ok {
+			return nil, fmt.Errorf("bot script filter returned %s in its list, want moves", item.Type())
+		}
+		if san, err := notation.Normalize(fen, text); err == nil && containsMove(allowed, san) {
+			kept = append(kept, san)
+		}
+	}
+	return kept, nil
+}
+
+// thread returns a Starlark thread for one call into the script
+func (s *ScriptProvider) thread() *starlark.Thread {
+	thread := &starlark.Thread{
+		Name:  s.path,
+		Print: func(_ *starlark.Thread, msg string) { fmt.Fprintln(os.Stderr, msg) },
+	}
+	thread.SetMaxExecutionSteps(scriptMaxSteps)
+	return thread
+}
+
+// scriptChessModule is the chess module scripts are given
+var scriptChessModule = &starlarkstruct.Module{
+	Name: "chess",
+	Members: starlark.StringDict{
+		"random":     starlark.NewBuiltin("random", scriptRandom),
+		"material":   starlark.NewBuiltin("material", scriptMaterial),
+		"after":      starlark.NewBuiltin("after", scriptAfter),
+		"turn":       starlark.NewBuiltin("turn", scriptTurn),
+		"phase":      starlark.NewBuiltin("phase", scriptPhase),
+		"is_capture": starlark.NewBuiltin("is_capture", scriptMoveTag(chess.Capture)),
+		"is_check":   starlark.NewBuiltin("is_check", scriptMoveTag(chess.Check)),
+	},
+}
+
+// scriptRandom is chess.random(list): one of its items, picked at random
+func scriptRandom(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var items starlark.Indexable
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &items); err != nil {
+		return nil, err
+	}
+	if items.Len() == 0 {
+		return nil, fmt.Errorf("%s: empty list", fn.Name())
+	}
+	return items.Index(rand.Intn(items.Len())), nil
+}
+
+// scriptMaterial is chess.material(fen): White's material advantage in
+// centipawns
+func scriptMaterial(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var fen string
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &fen); err != nil {
+		return nil, err
+	}
+	position, err := scriptPosition(fen)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	return starlark.MakeInt(MaterialBalance(position, chess.White)), nil
+}
+
+// scriptAfter is chess.after(fen, move): the FEN after the move
+func scriptAfter(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	position, move, err := scriptMoveArgs(fn, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(position.Update(move).String()), nil
+}
+
+// scriptTurn is chess.turn(fen): "white" or "black"
+func scriptTurn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var fen string
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &fen); err != nil {
+		return nil, err
+	}
+	position, err := scriptPosition(fen)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	if position.Turn() == chess.Black {
+		return starlark.String("black"), nil
+	}
+	return starlark.String("white"), nil
+}
+
+// scriptPhase is chess.phase(fen): "opening", "middlegame" or "endgame"
+func scriptPhase(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var fen string
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &fen); err != nil {
+		return nil, err
+	}
+	phase := DetectPhase(fen)
+	if phase == "" {
+		return nil, fmt.Errorf("%s: invalid FEN", fn.Name())
+	}
+	return starlark.String(phase), nil
+}
+
+// scriptMoveTag returns chess.is_capture or chess.is_check: whether the
+// move in the position has the tag
+func scriptMoveTag(tag chess.MoveTag) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		_, move, err := scriptMoveArgs(fn, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.Bool(move.HasTag(tag)), nil
+	}
+}
+
+// scriptMoveArgs unpacks the (fen, move) arguments of a builtin
+func scriptMoveArgs(fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (*chess.Position, *chess.Move, error) {
+	var fen, text string
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 2, &fen, &text); err != nil {
+		return nil, nil, err
+	}
+	position, err := scriptPosition(fen)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	move, err := notation.Decode(position, text)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	return position, move, nil
+}
+
+// scriptPosition parses a FEN given to a builtin
+func scriptPosition(fen string) (*chess.Position, error) {
+	option, err := chess.FEN(fen)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FEN: %w", err)
+	}
+	return chess.NewGame(option).Position(), nil
+}
+
+// stringList converts moves to a Starlark list
+func stringList(moves []string) *starlark.List {
+	values := make([]starlark.Value, len(moves))
+	for i, move := range moves {
+		values[i] = starlark.String(move)
+	}
+	return starlark.NewList(values)
+}
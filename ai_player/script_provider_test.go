@@ -0,0 +1,95 @@
+package ai_player
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeScript saves a bot script in a temporary directory
+func writeScript(t *testing.T, source string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bot.star")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestScriptProviderPlaysScriptMove(t *testing.T) {
+	path := writeScript(t, `
+def move(fen, legal_moves):
+    captures = [m for m in legal_moves if chess.is_capture(fen, m)]
+    return captures[0] if captures else "g1f3"
+`)
+	config := DefaultConfig()
+	config.Provider = ProviderScript
+	config.ScriptPath = path
+	player, err := NewAIPlayerFromConfig(config, "white", nil)
+	if err != nil {
+		t.Fatalf("Expected the script to load, got %v", err)
+	}
+
+	move, err := player.GetMove(startFEN, nil)
+	if err != nil || move.Notation != "Nf3" {
+		t.Errorf("Expected the script's Nf3, got %v (%v)", move, err)
+	}
+	hanging := "rnb1kbnr/pppp1ppp/8/4p3/3P3q/8/PPP1PPPP/RNBQKBNR w KQkq - 0 1"
+	move, err = player.GetMove(hanging, nil)
+	if err != nil || move.Notation != "dxe5" {
+		t.Errorf("Expected the script to capture with dxe5, got %v (%v)", move, err)
+	}
+}
+
+func TestScriptProviderFilterNarrowsEngine(t *testing.T) {
+	// The engine would take the queen; the filter allows pawn moves only
+	path := writeScript(t, `
+def filter(fen, legal_moves):
+    return [m for m in legal_moves if m[0].islower()]
+`)
+	provider, err := NewScriptProvider(path)
+	if err != nil {
+		t.Fatalf("Expected the script to load, got %v", err)
+	}
+	fen := "rnb1kbnr/pppp1ppp/8/4p3/6q1/5N2/PPPPPPPP/RNBQKB1R w KQkq - 0 1"
+	move, err := provider.SelectMove(context.Background(), MoveRequest{FEN: fen})
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if move.Notation[0] < 'a' || move.Notation[0] > 'h' {
+		t.Errorf("Expected a pawn move, got %s", move.Notation)
+	}
+}
+
+func TestScriptProviderRejectsBadScripts(t *testing.T) {
+	if _, err := NewScriptProvider(writeScript(t, "x = 1\n")); err == nil {
+		t.Error("Expected a script without move or filter to be rejected")
+	}
+
+	provider, _ := NewScriptProvider(writeScript(t, "def move(fen, legal_moves):\n    return \"e2e5\"\n"))
+	if _, err := provider.SelectMove(context.Background(), MoveRequest{FEN: startFEN}); err == nil {
+		t.Error("Expected an illegal move from the script to be rejected")
+	}
+
+	provider, err := NewScriptProvider(writeScript(t, "def move(fen, legal_moves):\n    for i in range(1000000000):\n        pass\n"))
+	if err != nil {
+		t.Fatalf("Expected the script to load, got %v", err)
+	}
+	if _, err := provider.SelectMove(context.Background(), MoveRequest{FEN: startFEN}); err == nil || !strings.Contains(err.Error(), "steps") {
+		t.Errorf("Expected a runaway script to be stopped, got %v", err)
+	}
+}
+
+func TestGreedyExampleBot(t *testing.T) {
+	provider, err := NewScriptProvider(filepath.Join("..", "examples", "bots", "greedy.star"))
+	if err != nil {
+		t.Fatalf("Expected the example bot to load, got %v", err)
+	}
+	hanging := "rnb1kbnr/pppp1ppp/8/4p3/3P3q/8/PPP1PPPP/RNBQKBNR w KQkq - 0 1"
+	move, err := provider.SelectMove(context.Background(), MoveRequest{FEN: hanging})
+	if err != nil || move.Notation != "dxe5" {
+		t.Errorf("Expected the greedy bot to win the pawn, got %v (%v)", move, err)
+	}
+}
@@ -0,0 +1,302 @@
+package ai_player
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/notnil/chess"
+
+	"chess-tui/notation"
+)
+
+// sessionTTL is how long an idle session is kept on disk
+const sessionTTL = 7 * 24 * time.Hour
+
+// ErrCodeSessionEnded is the JSON-RPC error code returned for requests in a
+// session an operator has kicked
+const ErrCodeSessionEnded = -32011
+
+// Session is the state of one game played against the server, keyed by the
+// A2A context ID of its messages
+type Session struct {
+	FEN         string    `json:"fen"`
+	History     []string  `json:"history"`
+	StartFEN    string    `json:"start_fen,omitempty"` // where History starts, if not the standard position
+	PlayerColor string    `json:"player_color,omitempty"`
+	Personality string    `json:"personality,omitempty"`
+	Provider    string    `json:"provider,omitempty"`
+	Model       string    `json:"model,omitempty"`
+	Updated     time.Time `json:"updated"`
+}
+
+// SessionStore keeps sessions in memory and, when it has a path, saves them
+// to disk after every change so games survive server restarts
+type SessionStore struct {
+	path     string
+	mu       sync.Mutex
+	sessions map[string]Session
+	kicked   map[string]time.Time // sessions an operator ended, refused until sessionTTL passes
+}
+
+// DefaultSessionsPath returns the sessions file location in the user's config directory
+func DefaultSessionsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "sessions.json"
+	}
+	return filepath.Join(home, ".bubblechess", "sessions.json")
+}
+
+// LoadSessionStore loads saved sessions from path, dropping any idle for
+// longer than a week. An empty path keeps sessions in memory only.
+func LoadSessionStore(path string) (*SessionStore, error) {
+	store := &SessionStore{path: path, sessions: make(map[string]Session), kicked: make(map[string]time.Time)}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions file: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode sessions file: %w", err)
+	}
+
+	for id, session := range store.sessions {
+		if time.Since(session.Updated) > sessionTTL {
+			delete(store.sessions, id)
+		}
+	}
+	return store, nil
+}
+
+// Len returns the number of sessions in the store
+func (s *SessionStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// Get returns the session with the given context ID
+func (s *SessionStore) Get(id string) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	return session, ok
+}
+
+// Put stores a session and saves the store
+func (s *SessionStore) Put(id string, session Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session.Updated = time.Now()
+	s.sessions[id] = session
+	return s.save()
+}
+
+// List returns the sessions by context ID
+func (s *SessionStore) List() map[string]Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.sessions)
+}
+
+// Kick ends a session: it is dropped, and further requests under its
+// context ID are refused. It reports whether the session existed.
+func (s *SessionStore) Kick(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[id]; !ok {
+		return false, nil
+	}
+	delete(s.sessions, id)
+	now := time.Now()
+	s.kicked[id] = now
+	for kicked, at := range s.kicked {
+		if now.Sub(at) > sessionTTL {
+			delete(s.kicked, kicked)
+		}
+	}
+	return true, s.save()
+}
+
+// Kicked reports whether an operator ended the session with context ID id
+func (s *SessionStore) Kicked(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.kicked[id]
+	return ok
+}
+
+// save writes the sessions to disk through a temporary file so a crash
+// mid-write can't corrupt them. The caller holds s.mu.
+func (s *SessionStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s.sessions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sessions: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write sessions file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace sessions file: %w", err)
+	}
+	return nil
+}
+
+// restore fills in the board, history and settings a request left out from
+// its saved session, so a client can carry on after a server restart by
+// sending only its context ID
+func (session Session) restore(req *ChessRequest) {
+	if req.BoardState == "" && len(req.Pieces) == 0 && len(req.GameHistory) == 0 {
+		req.BoardState = session.FEN
+		req.GameHistory = append([]string(nil), session.History...)
+		req.StartFEN = session.StartFEN
+	}
+	if req.PlayerColor == "" {
+		req.PlayerColor = session.PlayerColor
+	}
+	if req.Personality == "" {
+		req.Personality = session.Personality
+	}
+}
+
+// sessionAfterMove returns the session for a request once the AI's move has
+// been played, or false if the request's board isn't a valid position
+func sessionAfterMove(req ChessRequest, move string, aiPlayer *AIPlayer) (Session, bool) {
+	fenOption, err := chess.FEN(req.BoardState)
+	if err != nil {
+		return Session{}, false
+	}
+	position := chess.NewGame(fenOption).Position()
+	decoded, err := notation.Decode(position, move)
+	if err != nil {
+		return Session{}, false
+	}
+
+	return Session{
+		FEN:         position.Update(decoded).String(),
+		History:     append(append([]string(nil), req.GameHistory...), move),
+		StartFEN:    req.StartFEN,
+		PlayerColor: req.PlayerColor,
+		Personality: req.Personality,
+		Provider:    aiPlayer.ProviderName(),
+		Model:       aiPlayer.Model,
+	}, true
+}
+
+// SessionReplay is the params of the session/replay method: a game's whole
+// move list, from which the server rebuilds the session of its context ID in
+// one request, e.g. when a client resyncs or resumes a game
+type SessionReplay struct {
+	ContextID   string   `json:"contextId"`
+	Moves       []string `json:"moves"`
+	StartFEN    string   `json:"start_fen,omitempty"`
+	FEN         string   `json:"fen,omitempty"` // client's position, checked against the moves
+	PlayerColor string   `json:"player_color,omitempty"`
+	Personality string   `json:"personality,omitempty"`
+}
+
+// SessionReplayResult is the session/replay reply: the position the moves
+// lead to and the moves in SAN
+type SessionReplayResult struct {
+	FEN     string   `json:"fen"`
+	Ply     int      `json:"ply"`
+	History []string `json:"history"`
+}
+
+// replaySession plays a move list and returns the session it leads to. The
+// error is set when a move is illegal or the client's FEN doesn't match.
+func replaySession(replay SessionReplay, aiPlayer *AIPlayer) (Session, *DesyncError) {
+	game, desync := replayHistory(replay.StartFEN, replay.Moves, replay.FEN)
+	if desync != nil {
+		return Session{}, desync
+	}
+	fen := game.Position().String()
+	if replay.FEN != "" && !samePosition(fen, replay.FEN) {
+		return Session{}, &DesyncError{ClientFEN: replay.FEN, ServerFEN: fen, Ply: len(replay.Moves)}
+	}
+
+	positions := game.Positions()
+	history := make([]string, len(game.Moves()))
+	for i, move := range game.Moves() {
+		history[i] = notation.Encode(positions[i], move)
+	}
+	session := Session{
+		FEN:         fen,
+		History:     history,
+		StartFEN:    replay.StartFEN,
+		PlayerColor: replay.PlayerColor,
+		Personality: replay.Personality,
+	}
+	if aiPlayer != nil {
+		session.Provider, session.Model = aiPlayer.ProviderName(), aiPlayer.Model
+	}
+	return session, nil
+}
+
+// SessionSnapshot is the params of the session/resume method: all a client
+// saved of a game, from which the server reconstructs the session of its
+// context ID, e.g. when a saved game is resumed, after the server crashed
+// without a sessions file, or when the game moves to another server
+type SessionSnapshot struct {
+	ContextID   string   `json:"contextId"`
+	FEN         string   `json:"fen"` // the position the game has reached
+	History     []string `json:"history"`
+	StartFEN    string   `json:"start_fen,omitempty"`
+	PlayerColor string   `json:"player_color,omitempty"`
+	Personality string   `json:"personality,omitempty"`
+	Provider    string   `json:"provider,omitempty"` // who played the AI's moves so far
+	Model       string   `json:"model,omitempty"`
+}
+
+// SessionResumeResult confirms a session/resume: the session the server
+// now holds, and who plays the AI's moves from here, which after a move to
+// another server may not be who played them before
+type SessionResumeResult struct {
+	ContextID string   `json:"contextId"`
+	Resumed   bool     `json:"resumed"`
+	Replaced  bool     `json:"replaced"` // the server had a session under the context ID, which the snapshot overwrote
+	FEN       string   `json:"fen"`
+	Ply       int      `json:"ply"`
+	History   []string `json:"history"`
+	Provider  string   `json:"provider,omitempty"`
+	Model     string   `json:"model,omitempty"`
+}
+
+// resumeSession reconstructs the session a snapshot describes, checking the
+// history leads to its position. A snapshot without a history starts from
+// its position.
+func resumeSession(snapshot SessionSnapshot, aiPlayer *AIPlayer) (Session, *DesyncError) {
+	startFEN := snapshot.StartFEN
+	if startFEN == "" && len(snapshot.History) == 0 {
+		startFEN = snapshot.FEN
+	}
+	return replaySession(SessionReplay{
+		ContextID:   snapshot.ContextID,
+		Moves:       snapshot.History,
+		StartFEN:    startFEN,
+		FEN:         snapshot.FEN,
+		PlayerColor: snapshot.PlayerColor,
+		Personality: snapshot.Personality,
+	}, aiPlayer)
+}
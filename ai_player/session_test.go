@@ -0,0 +1,225 @@
+package ai_player
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postChessRequest sends a chess request as an A2A message/send and returns
+// the raw JSON-RPC reply
+func postChessRequest(t *testing.T, url, contextID string, req ChessRequest) []byte {
+	t.Helper()
+	text, _ := json.Marshal(req)
+	message := map[string]interface{}{
+		"kind":      "message",
+		"messageId": "msg_1",
+		"role":      "user",
+		"parts":     []interface{}{map[string]interface{}{"kind": "text", "text": string(text)}},
+	}
+	if contextID != "" {
+		message["contextId"] = contextID
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "message/send",
+		"id":      1,
+		"params":  map[string]interface{}{"message": message},
+	})
+
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	reply, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read reply: %v", err)
+	}
+	return reply
+}
+
+// quietLogger returns a logger that discards its output
+func quietLogger() *ColoredLogger {
+	logger := NewColoredLogger(LevelError)
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestSessionsSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	logger := quietLogger()
+	player := &AIPlayer{Provider: NewEngineProvider(), Logger: logger, Model: "engine"}
+
+	sessions, err := LoadSessionStore(path)
+	if err != nil {
+		t.Fatalf("Failed to load sessions: %v", err)
+	}
+	server := httptest.NewServer(handleJSONRPCEndpoint(player, sessions, logger))
+	postChessRequest(t, server.URL, "game_1", ChessRequest{
+		BoardState:  "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		PlayerColor: "black",
+		GameHistory: []string{"e4"},
+		Personality: "aggressive",
+	})
+	server.Close()
+
+	// A new server reloads the session and continues from the context ID alone
+	sessions, err = LoadSessionStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reload sessions: %v", err)
+	}
+	session, ok := sessions.Get("game_1")
+	if !ok {
+		t.Fatal("Expected the session to be saved")
+	}
+	if len(session.History) != 2 || session.PlayerColor != "black" || session.Personality != "aggressive" || session.Model != "engine" {
+		t.Fatalf("Unexpected session: %+v", session)
+	}
+
+	server = httptest.NewServer(handleJSONRPCEndpoint(player, sessions, logger))
+	defer server.Close()
+	reply := postChessRequest(t, server.URL, "game_1", ChessRequest{})
+
+	var response struct {
+		Error interface{} `json:"error"`
+	}
+	if err := json.Unmarshal(reply, &response); err != nil || response.Error != nil {
+		t.Fatalf("Expected a move, got %s", reply)
+	}
+	if session, _ := sessions.Get("game_1"); len(session.History) != 3 {
+		t.Errorf("Expected 3 plies after resuming, got %v", session.History)
+	}
+}
+
+func TestLoadSessionStoreDropsIdleSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	store, _ := LoadSessionStore(path)
+	if err := store.Put("fresh", Session{FEN: startFEN}); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	store.sessions["stale"] = Session{FEN: startFEN, Updated: time.Now().Add(-2 * sessionTTL)}
+	if err := store.save(); err != nil {
+		t.Fatalf("Failed to save sessions: %v", err)
+	}
+
+	reloaded, err := LoadSessionStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reload sessions: %v", err)
+	}
+	if _, ok := reloaded.Get("stale"); ok {
+		t.Error("Expected the idle session to be dropped")
+	}
+	if _, ok := reloaded.Get("fresh"); !ok {
+		t.Error("Expected the fresh session to be kept")
+	}
+}
+
+func TestSessionReplayRebuildsSession(t *testing.T) {
+	logger := quietLogger()
+	player := &AIPlayer{Provider: NewEngineProvider(), Logger: logger, Model: "engine"}
+	sessions, _ := LoadSessionStore("")
+	server := httptest.NewServer(handleJSONRPCEndpoint(player, sessions, logger))
+	defer server.Close()
+
+	replay := func(params SessionReplay) map[string]interface{} {
+		body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "method": "session/replay", "id": 1, "params": params})
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var reply map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&reply)
+		return reply
+	}
+
+	reply := replay(SessionReplay{ContextID: "game_1", Moves: []string{"e2e4", "e5", "Nf3"}, PlayerColor: "black"})
+	result, _ := reply["result"].(map[string]interface{})
+	if result["ply"] != float64(3) {
+		t.Fatalf("Expected 3 plies replayed, got %v", reply)
+	}
+	session, ok := sessions.Get("game_1")
+	if !ok || strings.Join(session.History, " ") != "e4 e5 Nf3" || session.PlayerColor != "black" {
+		t.Fatalf("Expected the session rebuilt in SAN, got %+v", session)
+	}
+
+	// The next move request continues from the context ID alone
+	reply2 := postChessRequest(t, server.URL, "game_1", ChessRequest{})
+	if strings.Contains(string(reply2), `"error"`) {
+		t.Fatalf("Expected a move, got %s", reply2)
+	}
+
+	reply = replay(SessionReplay{ContextID: "game_2", Moves: []string{"e4", "e4"}})
+	e, _ := reply["error"].(map[string]interface{})
+	if e == nil || e["code"] != float64(ErrCodeDesync) {
+		t.Fatalf("Expected a desync error for an illegal move, got %v", reply)
+	}
+	if data, _ := e["data"].(map[string]interface{}); data["ply"] != float64(1) || data["move"] != "e4" {
+		t.Errorf("Expected the second move reported, got %v", data)
+	}
+}
+
+func TestSessionResumeReconstructsSession(t *testing.T) {
+	logger := quietLogger()
+	player := &AIPlayer{Provider: NewEngineProvider(), Logger: logger, Model: "engine"}
+	sessions, _ := LoadSessionStore("")
+	server := httptest.NewServer(handleJSONRPCEndpoint(player, sessions, logger))
+	defer server.Close()
+
+	resume := func(params SessionSnapshot) map[string]interface{} {
+		body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "method": "session/resume", "id": 1, "params": params})
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var reply map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&reply)
+		return reply
+	}
+
+	afterE4E5 := "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2"
+	snapshot := SessionSnapshot{ContextID: "game_1", FEN: afterE4E5, History: []string{"e2e4", "e5"}, PlayerColor: "white", Model: "other-model"}
+	reply := resume(snapshot)
+	result, _ := reply["result"].(map[string]interface{})
+	if result["resumed"] != true || result["replaced"] != false || result["ply"] != float64(2) || result["model"] != "engine" {
+		t.Fatalf("Expected the session resumed with this server's model, got %v", reply)
+	}
+	session, ok := sessions.Get("game_1")
+	if !ok || strings.Join(session.History, " ") != "e4 e5" || session.PlayerColor != "white" {
+		t.Fatalf("Expected the session reconstructed in SAN, got %+v", session)
+	}
+
+	// Resuming again replaces the session, e.g. after a failover and back
+	result, _ = resume(snapshot)["result"].(map[string]interface{})
+	if result["replaced"] != true {
+		t.Errorf("Expected the existing session to be replaced, got %v", result)
+	}
+
+	// A snapshot without moves starts from its position
+	sicilian := "rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2"
+	result, _ = resume(SessionSnapshot{ContextID: "game_2", FEN: sicilian})["result"].(map[string]interface{})
+	if result["fen"] != sicilian || result["ply"] != float64(0) {
+		t.Errorf("Expected the game resumed from its position, got %v", result)
+	}
+
+	for name, params := range map[string]SessionSnapshot{
+		"mismatched position": {ContextID: "game_3", FEN: sicilian, History: []string{"e4", "e5"}},
+		"missing position":    {ContextID: "game_3", History: []string{"e4"}},
+		"missing context ID":  {FEN: sicilian},
+	} {
+		if e, _ := resume(params)["error"].(map[string]interface{}); e == nil {
+			t.Errorf("Expected an error for a %s", name)
+		}
+	}
+	if _, ok := sessions.Get("game_3"); ok {
+		t.Error("Expected no session for a rejected snapshot")
+	}
+}
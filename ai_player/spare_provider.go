@@ -0,0 +1,178 @@
+package ai_player
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Keeping the spare model loaded: Ollama unloads a model spareKeepAlive
+// after its last request, and the spare is asked to load again whenever it
+// was last warmed over spareWarmInterval ago
+const (
+	spareKeepAlive    = "30m"
+	spareWarmInterval = 10 * time.Minute
+)
+
+// warmer is implemented by providers that can load their model ahead of
+// its first request
+type warmer interface {
+	Warm(ctx context.Context) error
+}
+
+// SpareProvider asks the primary provider for each move and, when it times
+// out, a small fast spare model kept loaded for it, so the move comes at
+// once instead of after a cold start. Unlike a failover chain the primary
+// stays in charge: the spare only answers the move the primary missed.
+type SpareProvider struct {
+	Primary    Provider
+	Spare      Provider
+	SpareLabel string        // how the spare's moves are marked, e.g. "ollama llama3.2:1b"
+	Timeout    time.Duration // how long the primary has for each move
+	Logger     *ColoredLogger
+
+	// Parse turns a text response into a move; set by NewAIPlayerFromConfig
+	Parse func(response string) (*ChessMove, error)
+
+	mu     sync.Mutex
+	warmed time.Time // when the spare was last asked to load
+}
+
+// NewSpareProvider pairs a primary provider with a spare that answers when
+// the primary takes longer than timeout
+func NewSpareProvider(primary, spare Provider, spareLabel string, timeout time.Duration, logger *ColoredLogger) *SpareProvider {
+	if logger == nil {
+		logger = NewAIPlayerLogger()
+	}
+	return &SpareProvider{
+		Primary:    primary,
+		Spare:      spare,
+		SpareLabel: spareLabel,
+		Timeout:    timeout,
+		Logger:     logger,
+	}
+}
+
+// Name returns the name of the primary provider
+func (s *SpareProvider) Name() string {
+	return s.Primary.Name()
+}
+
+// primaryContext bounds a call to the primary by its timeout
+func (s *SpareProvider) primaryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.Timeout > 0 {
+		return context.WithTimeout(ctx, s.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// timedOut reports whether the primary ran out of its time under ctx while
+// the request itself is still wanted
+func timedOut(ctx, primaryCtx context.Context) bool {
+	return primaryCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+}
+
+// Generate sends the request to the primary, and to the spare if the
+// primary times out
+func (s *SpareProvider) Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error) {
+	s.warmIfDue()
+	primaryCtx, cancel := s.primaryContext(ctx)
+	defer cancel()
+	response, err := s.Primary.Generate(primaryCtx, request)
+	if err == nil || !timedOut(ctx, primaryCtx) {
+		return response, err
+	}
+	s.Logger.Warn("⏱️ %s%s timed out after %v, the spare %s answers%s", ColorYellow, s.Primary.Name(), s.Timeout, s.SpareLabel, ColorReset)
+	return s.Spare.Generate(ctx, request)
+}
+
+// SelectMove asks the primary for a legal move, and the spare if the
+// primary times out. The spare's moves are marked as its own.
+func (s *SpareProvider) SelectMove(ctx context.Context, request MoveRequest) (*ChessMove, error) {
+	s.warmIfDue()
+	primaryCtx, cancel := s.primaryContext(ctx)
+	defer cancel()
+	move, err := selectFrom(primaryCtx, s.Primary, s.Parse, request)
+	if err == nil || !timedOut(ctx, primaryCtx) {
+		return move, err
+	}
+
+	s.Logger.Warn("⏱️ %s%s timed out after %v, the spare %s answers%s", ColorYellow, s.Primary.Name(), s.Timeout, s.SpareLabel, ColorReset)
+	move, err = selectFrom(ctx, s.Spare, s.Parse, request)
+	if err != nil {
+		return nil, fmt.Errorf("primary timed out and the spare failed: %w", err)
+	}
+	move.Provider = s.SpareLabel
+	move.Spare = true
+	return move, nil
+}
+
+// TestConnection checks the primary, then loads the spare's model so it is
+// warm for the first move
+func (s *SpareProvider) TestConnection() error {
+	if err := s.Primary.TestConnection(); err != nil {
+		return err
+	}
+	if err := s.Spare.TestConnection(); err != nil {
+		return fmt.Errorf("spare: %w", err)
+	}
+	s.warmIfDue()
+	return nil
+}
+
+// warmIfDue asks the spare to load its model in the background, unless it
+// was asked within spareWarmInterval
+func (s *SpareProvider) warmIfDue() {
+	spare, ok := s.Spare.(warmer)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	due := time.Since(s.warmed) > spareWarmInterval
+	if due {
+		s.warmed = time.Now()
+	}
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		if err := spare.Warm(ctx); err != nil {
+			s.Logger.Warn("⚠️ %sFailed to warm the spare %s: %v%s", ColorYellow, s.SpareLabel, err, ColorReset)
+			s.mu.Lock()
+			s.warmed = time.Time{}
+			s.mu.Unlock()
+		}
+	}()
+}
+
+// Warm loads the model into Ollama's memory and keeps it there for
+// spareKeepAlive, by a request without a prompt
+func (p *ollamaProvider) Warm(ctx context.Context) error {
+	body, err := json.Marshal(map[string]string{"model": p.model, "keep_alive": spareKeepAlive})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", p.player.OllamaURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.player.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Ollama API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package ai_player
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// slowProvider answers e4 only once it is told to, until then waiting out
+// the caller's deadline
+type slowProvider struct {
+	fast bool
+}
+
+func (p *slowProvider) Name() string { return "slow" }
+
+func (p *slowProvider) Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error) {
+	if p.fast {
+		return &OllamaResponse{Response: "e2e4"}, nil
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (p *slowProvider) TestConnection() error { return nil }
+
+func TestSpareAnswersWhenPrimaryTimesOut(t *testing.T) {
+	primary := &slowProvider{}
+	spare := &fakeProvider{name: "spare", reply: "d2d4"}
+
+	player := NewAIPlayer("", "m", "white", nil)
+	pair := NewSpareProvider(primary, spare, "ollama llama3.2:1b", 50*time.Millisecond, player.Logger)
+	pair.Parse = player.parseMove
+	player.Provider = pair
+
+	move, err := player.GetMove(startFEN, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Notation != "d4" || !move.Spare || move.Provider != "ollama llama3.2:1b" {
+		t.Errorf("Expected the spare's d4 marked as its own, got %s from %s (spare %v)", move.Notation, move.Provider, move.Spare)
+	}
+
+	// The primary stays in charge for the next move
+	primary.fast = true
+	move, err = player.GetMove(startFEN, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Notation != "e4" || move.Spare || move.Provider != "slow" {
+		t.Errorf("Expected the primary's e4, got %s from %s (spare %v)", move.Notation, move.Provider, move.Spare)
+	}
+	if spare.calls != 1 {
+		t.Errorf("Expected the spare to be asked once, got %d", spare.calls)
+	}
+}
+
+func TestOllamaSpareWarms(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"model":"llama3.2:1b","done":true,"done_reason":"load"}`))
+	}))
+	defer server.Close()
+
+	spare := &ollamaProvider{player: NewAIPlayer(server.URL, "llama3.2:1b", "", nil), model: "llama3.2:1b"}
+	if err := spare.Warm(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if body["model"] != "llama3.2:1b" || body["keep_alive"] != spareKeepAlive {
+		t.Errorf("Expected a load request keeping the model for %s, got %v", spareKeepAlive, body)
+	}
+	if _, ok := body["prompt"]; ok {
+		t.Errorf("Expected no prompt, got %v", body["prompt"])
+	}
+}
+
+func TestSpareConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.Spare = &Config{Model: "llama3.2:1b", OllamaURL: "http://localhost:11434"}
+	if err := config.ValidateConfig(); err != nil {
+		t.Fatalf("Expected a valid config, got %v", err)
+	}
+	provider, err := NewProvider(config, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	pair, ok := provider.(*SpareProvider)
+	if !ok {
+		t.Fatalf("Expected a spare provider, got %T", provider)
+	}
+	if pair.SpareLabel != "ollama llama3.2:1b" || pair.Timeout != 30*time.Second {
+		t.Errorf("Expected the spare labelled ollama llama3.2:1b after 30s, got %q after %v", pair.SpareLabel, pair.Timeout)
+	}
+
+	config.Spare.Spare = &Config{Model: "tiny"}
+	if err := config.ValidateConfig(); err == nil {
+		t.Error("Expected a spare's spare to be refused")
+	}
+}
@@ -0,0 +1,144 @@
+package ai_player
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"chess-tui/errkind"
+	"chess-tui/jsonrpc"
+)
+
+// jsonrpcReply answers one JSON-RPC request, either with a single JSON
+// response or, for message/stream, with server-sent events ending in the
+// response. The outcome is also pushed to the callbacks the client
+// registered for the task, if any.
+type jsonrpcReply struct {
+	w      http.ResponseWriter // the event stream, when streaming
+	id     json.RawMessage
+	stream bool
+
+	started bool // whether the event stream's headers have been sent
+
+	// The outcome of a reply that isn't streamed, returned by outcome
+	result interface{}
+	err    *jsonrpc.Error
+
+	outputModes []string // the parts a message keeps; nil keeps all
+
+	// Where the outcome is pushed, for the task and context it belongs to
+	pushes            *pushRegistry
+	taskID, contextID string
+	detached          bool // the client was already answered; only push
+}
+
+// newJSONRPCReply creates the reply to a call. A streamed reply falls back
+// to a plain one when the call can't stream, e.g. inside a batch.
+func newJSONRPCReply(call *jsonrpc.Call, stream bool) *jsonrpcReply {
+	reply := &jsonrpcReply{id: call.ID}
+	if stream {
+		reply.w = call.Stream()
+		reply.stream = reply.w != nil
+	}
+	return reply
+}
+
+// outcome returns the result or error of a reply that isn't streamed, for
+// the JSON-RPC server to send
+func (r *jsonrpcReply) outcome() (interface{}, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.result, nil
+}
+
+// error replies with a JSON-RPC error. data is usually a string, or a value
+// such as *DesyncError for structured errors.
+func (r *jsonrpcReply) error(code int, message string, data interface{}) {
+	r.fail(jsonrpc.NewError(code, message, data))
+}
+
+// fail replies with an error; a *jsonrpc.Error keeps its code
+func (r *jsonrpcReply) fail(err error) {
+	response := jsonrpc.NewErrorResponse(r.id, kindError(err))
+	r.push(TaskStateFailed, []MessagePartsElem{TextPart{Kind: "text", Text: fmt.Sprintf("%s: %v", response.Error.Message, response.Error.Data)}})
+	switch {
+	case r.detached:
+	case r.stream:
+		r.event(response)
+	default:
+		r.err = response.Error
+	}
+}
+
+// kindError gives an error of an errkind.Kind the kind's code, so the client
+// gets the kind back; a *jsonrpc.Error or an error of no kind is left as it is
+func kindError(err error) error {
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+	if kind := errkind.Of(err); kind != "" {
+		return jsonrpc.NewError(kind.Code(), kind.Message(), err.Error())
+	}
+	return err
+}
+
+// message replies with an agent message with the given parts
+func (r *jsonrpcReply) message(parts []MessagePartsElem) {
+	parts = filterParts(parts, r.outputModes)
+	r.push(TaskStateCompleted, parts)
+	switch {
+	case r.detached:
+	case r.stream:
+		r.event(jsonrpc.NewResponse(r.id, agentMessage(parts)))
+	default:
+		r.result = agentMessage(parts)
+	}
+}
+
+// task replies with the task in the given state instead of a message, for
+// non-blocking requests whose outcome will be pushed
+func (r *jsonrpcReply) task(state TaskState) {
+	r.result = agentTask(r.taskID, r.contextID, state, nil)
+}
+
+// push sends the task's outcome to its registered callbacks
+func (r *jsonrpcReply) push(state TaskState, parts []MessagePartsElem) {
+	if r.pushes == nil || r.taskID == "" {
+		return
+	}
+	r.pushes.notify(agentTask(r.taskID, r.contextID, state, parts))
+}
+
+// status sends a task status update ahead of the reply. Plain message/send
+// replies have nowhere to put one, so it is dropped for them.
+func (r *jsonrpcReply) status(taskID, contextID string, state TaskState, metadata map[string]interface{}) {
+	if !r.stream {
+		return
+	}
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	r.event(jsonrpc.NewResponse(r.id, TaskStatusUpdateEvent{
+		Kind:      "status-update",
+		TaskId:    taskID,
+		ContextId: contextID,
+		Status:    TaskStatus{State: state, Timestamp: &timestamp},
+		Metadata:  metadata,
+	}))
+}
+
+// event writes one server-sent event and flushes it to the client
+func (r *jsonrpcReply) event(v interface{}) {
+	if !r.started {
+		r.w.Header().Set("Content-Type", "text/event-stream")
+		r.w.Header().Set("Cache-Control", "no-cache")
+		r.started = true
+	}
+	data, _ := json.Marshal(v)
+	fmt.Fprintf(r.w, "data: %s\n\n", data)
+	if flusher, ok := r.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
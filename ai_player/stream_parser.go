@@ -0,0 +1,190 @@
+package ai_player
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"chess-tui/notation"
+)
+
+// streamChunk is one line of Ollama's streaming response
+type streamChunk struct {
+	Response        string `json:"response"`
+	Thinking        string `json:"thinking"`
+	Done            bool   `json:"done"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
+
+// streamParser reads Ollama's streaming response a line at a time,
+// accumulating the response and thinking. With a stop condition it ends as
+// soon as the accumulated response satisfies it, so the caller can cancel the
+// rest of the generation.
+type streamParser struct {
+	// Stop, when set, is checked against the accumulated response after each
+	// chunk; reading ends early once it returns true, keeping only the part
+	// of the response it returns
+	Stop func(response string) (keep string, stop bool)
+
+	// MaxThinking, when positive, ends reading once the thinking trace
+	// passes that many tokens without any response
+	MaxThinking int
+
+	// OnChunk, when set, is called with each parsed chunk, e.g. for progress logs
+	OnChunk func(chunk streamChunk)
+
+	response strings.Builder
+	thinking strings.Builder
+	lines    int
+	done     bool
+	stopped  bool
+	capped   bool
+
+	// Ollama streams one token per chunk, so chunks count thinking tokens
+	thinkingTokens int
+	start          time.Time
+	firstResponse  time.Duration // from the first line to the first response token
+
+	promptTokens, completionTokens int
+}
+
+// Read consumes the stream until Ollama reports it is done, the stop
+// condition matches or the stream ends. Lines that aren't JSON are skipped.
+// The stream is read only as fast as its lines are handled, so nothing past
+// the stopping point is buffered.
+func (p *streamParser) Read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if p.Feed(scanner.Bytes()) {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read streaming response: %w", err)
+	}
+	return nil
+}
+
+// Feed handles one line of the stream and reports whether reading can stop
+func (p *streamParser) Feed(line []byte) bool {
+	p.lines++
+	if p.start.IsZero() {
+		p.start = time.Now()
+	}
+	if len(line) == 0 {
+		return false
+	}
+
+	var chunk streamChunk
+	if err := json.Unmarshal(line, &chunk); err != nil {
+		return false
+	}
+	if chunk.Thinking != "" {
+		p.thinking.WriteString(chunk.Thinking)
+		p.thinkingTokens++
+	}
+	if chunk.Response != "" && p.response.Len() == 0 {
+		p.firstResponse = time.Since(p.start)
+	}
+	p.response.WriteString(chunk.Response)
+	if p.OnChunk != nil {
+		p.OnChunk(chunk)
+	}
+
+	if chunk.Done {
+		// The final chunk carries the token counts
+		p.done = true
+		p.promptTokens = chunk.PromptEvalCount
+		p.completionTokens = chunk.EvalCount
+		return true
+	}
+	if p.MaxThinking > 0 && p.thinkingTokens > p.MaxThinking && p.response.Len() == 0 {
+		p.capped = true
+		return true
+	}
+	if chunk.Response != "" && p.Stop != nil {
+		if keep, stop := p.Stop(p.response.String()); stop {
+			p.response.Reset()
+			p.response.WriteString(keep)
+			p.stopped = true
+			return true
+		}
+	}
+	return false
+}
+
+// Stopped reports whether reading ended early on the stop condition
+func (p *streamParser) Stopped() bool {
+	return p.stopped
+}
+
+// Capped reports whether reading ended because thinking ran past MaxThinking
+func (p *streamParser) Capped() bool {
+	return p.capped
+}
+
+// ThinkingTokens returns how many tokens of thinking have been read
+func (p *streamParser) ThinkingTokens() int {
+	return p.thinkingTokens
+}
+
+// FirstResponse returns how long the response took to start after the
+// stream did, which is mostly time spent thinking
+func (p *streamParser) FirstResponse() time.Duration {
+	return p.firstResponse
+}
+
+// Lines returns how many lines have been read
+func (p *streamParser) Lines() int {
+	return p.lines
+}
+
+// Result returns the response accumulated so far. Token counts are only
+// known when the stream ran to the end.
+func (p *streamParser) Result() *OllamaResponse {
+	return &OllamaResponse{
+		Response:        p.response.String(),
+		Thinking:        p.thinking.String(),
+		Done:            p.done,
+		PromptEvalCount: p.promptTokens,
+		EvalCount:       p.completionTokens,
+	}
+}
+
+// completeMove reports whether a streamed move response already holds the
+// move, and returns the text to parse it from: the first line once it has
+// ended, or the start of the line once it reads as a move followed by a space
+func completeMove(response string) (string, bool) {
+	response = strings.TrimLeft(response, " \t\r\n")
+	if line, _, ended := strings.Cut(response, "\n"); ended {
+		return line, true
+	}
+	for i, r := range response {
+		// Only text followed by a space is known to be complete; "e2" may
+		// be the start of "e2e4"
+		if (r == ' ' || r == '\t') && notation.IsMove(cleanMoveLine(response[:i])) {
+			return response[:i], true
+		}
+	}
+	return "", false
+}
+
+// cleanMoveLine strips the first line of a response down to the move,
+// dropping the wrappers models like to add
+func cleanMoveLine(response string) string {
+	response = strings.TrimSpace(response)
+	response = strings.Split(response, "\n")[0]
+	response = strings.TrimSpace(response)
+	response = strings.TrimPrefix(response, "Move: ")
+	response = strings.TrimPrefix(response, "The best move is ")
+	response = strings.TrimPrefix(response, "I suggest ")
+	response = strings.TrimSuffix(response, ".")
+	response = strings.TrimSuffix(response, "!")
+	response = strings.TrimSuffix(response, "?")
+	return response
+}
@@ -0,0 +1,98 @@
+package ai_player
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompleteMove(t *testing.T) {
+	tests := []struct {
+		response string
+		keep     string
+		complete bool
+	}{
+		{"", "", false},
+		{"  ", "", false},
+		{"e2", "", false}, // may be the start of e2e4
+		{"e2e4", "", false},
+		{"e2e4 ", "e2e4", true},
+		{"Nf3 develops", "Nf3", true},
+		{"The best move is ", "", false},
+		{"The best move is Nf3. Because", "The best move is Nf3.", true},
+		{"I think\n", "I think", true}, // parseMove only reads the first line
+		{"\nNf3", "", false},
+	}
+	for _, test := range tests {
+		keep, complete := completeMove(test.response)
+		if keep != test.keep || complete != test.complete {
+			t.Errorf("completeMove(%q): expected %q, %v, got %q, %v", test.response, test.keep, test.complete, keep, complete)
+		}
+	}
+}
+
+func TestStreamParserStopsEarly(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"thinking":"The knight "}`,
+		`not json`,
+		`{"response":"N"}`,
+		`{"response":"f3"}`,
+		`{"response":" develops"}`,
+		`{"response":" a piece"}`,
+		`{"response":"","done":true,"eval_count":9}`,
+	}, "\n")
+
+	parser := &streamParser{Stop: completeMove}
+	if err := parser.Read(strings.NewReader(stream)); err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+	result := parser.Result()
+	if !parser.Stopped() || result.Response != "Nf3" || result.Thinking != "The knight " {
+		t.Errorf("Expected to stop after the move, got %+v (stopped %v)", result, parser.Stopped())
+	}
+
+	parser = &streamParser{}
+	parser.Read(strings.NewReader(stream))
+	if result := parser.Result(); parser.Stopped() || !result.Done || result.EvalCount != 9 || result.Response != "Nf3 develops a piece" {
+		t.Errorf("Expected the whole stream without a stop condition, got %+v", result)
+	}
+}
+
+func TestGetMoveCancelsGenerationOnceMoveArrives(t *testing.T) {
+	cancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		for _, token := range []string{"e", "4", " ", "opens"} {
+			fmt.Fprintf(w, `{"response":%q}`+"\n", token)
+			flusher.Flush()
+		}
+		// A long explanation would follow; the client should hang up instead
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	player := NewAIPlayer(server.URL, "test-model", "white", quietLogger())
+	start := time.Now()
+	move, err := player.GetMove("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", nil)
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if move.Notation != "e4" {
+		t.Errorf("Expected e4, got %s", move.Notation)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the move without waiting for the generation to end, took %v", elapsed)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected the rest of the generation to be cancelled")
+	}
+}
@@ -0,0 +1,120 @@
+package ai_player
+
+import (
+	"fmt"
+	"strings"
+
+	"chess-tui/notation"
+)
+
+// maxCandidates is how many moves teach mode suggests at most
+const maxCandidates = 3
+
+// CandidateMove is a move suggested to the human in teach mode
+type CandidateMove struct {
+	Move        string `json:"move"`
+	Explanation string `json:"explanation"`
+}
+
+// SuggestMoves asks the model for two or three candidate moves for the side
+// to move, each with a short explanation aimed at beginners
+func (ai *AIPlayer) SuggestMoves(boardState string, gameHistory []string) ([]CandidateMove, error) {
+	request := OllamaRequest{
+		Model:  ai.Model,
+		Prompt: ai.buildTeachPrompt(boardState, gameHistory),
+		Stream: false,
+		Options: map[string]interface{}{
+			"temperature": 0.4,
+			"top_p":       0.9,
+		},
+		Think: ai.Think,
+
+		maxThinking: ai.MaxThinkingTokens,
+	}
+
+	response, err := ai.generate(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", ai.ProviderName(), err)
+	}
+
+	candidates := parseCandidates(response.Response, boardState)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no legal candidate moves in response: %s", response.Response)
+	}
+	return candidates, nil
+}
+
+// buildTeachPrompt creates the coaching prompt for teach mode
+func (ai *AIPlayer) buildTeachPrompt(boardState string, gameHistory []string) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("You are a friendly chess coach helping a beginner who plays ")
+	prompt.WriteString(ai.Color)
+	prompt.WriteString(".\n\n")
+
+	prompt.WriteString("Current board position (FEN):\n")
+	prompt.WriteString(boardState)
+	prompt.WriteString("\n\n")
+
+	if moves, err := notation.LegalMoves(boardState); err == nil {
+		prompt.WriteString("Legal moves: ")
+		prompt.WriteString(strings.Join(moves, ", "))
+		prompt.WriteString("\n\n")
+	}
+
+	if len(gameHistory) > 0 {
+		start := len(gameHistory) - 6
+		if start < 0 {
+			start = 0
+		}
+		prompt.WriteString("Recent moves: ")
+		prompt.WriteString(strings.Join(gameHistory[start:], " "))
+		prompt.WriteString("\n\n")
+	}
+
+	prompt.WriteString("INSTRUCTIONS:\n")
+	prompt.WriteString("1. Suggest 2 or 3 reasonable candidate moves from the legal moves\n")
+	prompt.WriteString("2. Explain the idea behind each in one short sentence a beginner understands\n")
+	prompt.WriteString("3. Do NOT say which move is best and do NOT reveal forced wins or mating sequences\n")
+	prompt.WriteString("4. Describe ideas and principles, not long variations\n\n")
+
+	prompt.WriteString("FORMAT (one per line, nothing else):\n")
+	prompt.WriteString("<move in short algebraic notation> - <explanation>\n")
+
+	return prompt.String()
+}
+
+// parseCandidates reads "move - explanation" lines, keeping only legal moves
+func parseCandidates(response, fen string) []CandidateMove {
+	var candidates []CandidateMove
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimLeft(line, "-*•0123456789. ")
+		if line == "" {
+			continue
+		}
+
+		move, explanation := line, ""
+		for _, separator := range []string{" - ", " – ", ": "} {
+			if before, after, found := strings.Cut(line, separator); found {
+				move, explanation = before, after
+				break
+			}
+		}
+		move = strings.Trim(strings.TrimSpace(move), "*`")
+
+		san, err := notation.Normalize(fen, move)
+		if err != nil || seen[san] {
+			continue
+		}
+		seen[san] = true
+		candidates = append(candidates, CandidateMove{Move: san, Explanation: strings.TrimSpace(explanation)})
+
+		if len(candidates) == maxCandidates {
+			break
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,23 @@
+package ai_player
+
+import "testing"
+
+func TestParseCandidates(t *testing.T) {
+	response := "1. e4 - Grabs the center.\n2. **Nf3** - Develops a knight.\nKe2 - Walks the king.\n3. e2e4 - Duplicate.\nd4: Also claims the center.\nc4 - Too many."
+
+	candidates := parseCandidates(response, startFEN)
+	if len(candidates) != maxCandidates {
+		t.Fatalf("Expected %d candidates, got %d: %+v", maxCandidates, len(candidates), candidates)
+	}
+
+	expected := []CandidateMove{
+		{Move: "e4", Explanation: "Grabs the center."},
+		{Move: "Nf3", Explanation: "Develops a knight."},
+		{Move: "d4", Explanation: "Also claims the center."},
+	}
+	for i, candidate := range candidates {
+		if candidate != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], candidate)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package ai_player
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestOptionsFor(t *testing.T) {
+	off, on := false, true
+	config := &Config{
+		Think:             &on,
+		MaxThinkingTokens: 500,
+		ModelOptions: map[string]ModelOptions{
+			"qwen3":          {Think: &off},
+			"deepseek-r1:7b": {MaxThinkingTokens: 100},
+		},
+	}
+
+	if options := config.OptionsFor("qwen3:8b"); *options.Think || options.MaxThinkingTokens != 500 {
+		t.Errorf("Expected the qwen3 family to have thinking off with the default cap, got %+v", options)
+	}
+	if options := config.OptionsFor("deepseek-r1:7b"); !*options.Think || options.MaxThinkingTokens != 100 {
+		t.Errorf("Expected the exact model's cap, got %+v", options)
+	}
+	if options := config.OptionsFor("llama3.2:3b"); !*options.Think || options.MaxThinkingTokens != 500 {
+		t.Errorf("Expected the config defaults, got %+v", options)
+	}
+}
+
+func TestThinkOptionSentToOllama(t *testing.T) {
+	var think *bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Think *bool `json:"think"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		think = request.Think
+		w.Write([]byte(`{"response":"e4","done":true}` + "\n"))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaURL = server.URL
+	config.Model = "qwen3:8b"
+	off := false
+	config.ModelOptions = map[string]ModelOptions{"qwen3": {Think: &off}}
+	player, err := NewAIPlayerFromConfig(config, "white", quietLogger())
+	if err != nil {
+		t.Fatalf("Failed to create player: %v", err)
+	}
+
+	if _, err := player.GetMove("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", nil); err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if think == nil || *think {
+		t.Errorf("Expected think=false in the request, got %v", think)
+	}
+}
+
+func TestThinkingCapAsksAgainWithoutThinking(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Think *bool `json:"think"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		mu.Lock()
+		requests = append(requests, request.Think)
+		mu.Unlock()
+
+		if request.Think == nil {
+			// Think at length, then answer
+			for i := 0; i < 50; i++ {
+				fmt.Fprintf(w, `{"thinking":"hmm %d "}`+"\n", i)
+			}
+			w.Write([]byte(`{"response":"d4","done":true}` + "\n"))
+			return
+		}
+		w.Write([]byte(`{"response":"e4","done":true}` + "\n"))
+	}))
+	defer server.Close()
+
+	player := NewAIPlayer(server.URL, "test-model", "white", quietLogger())
+	player.MaxThinkingTokens = 10
+
+	move, err := player.GetMove("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", nil)
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if move.Notation != "e4" {
+		t.Errorf("Expected the move from the request without thinking, got %s", move.Notation)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 || requests[1] == nil || *requests[1] {
+		t.Errorf("Expected a second request with think=false, got %v", requests)
+	}
+}
@@ -0,0 +1,156 @@
+package ai_player
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redactedText replaces secrets and redacted patterns in traces
+const redactedText = "[REDACTED]"
+
+// Tracer writes the exact request sent to the backend and its raw response
+// to a file per call, for debugging prompts without editing code
+type Tracer struct {
+	dir     string
+	secrets []string
+	redact  []*regexp.Regexp
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewTracer creates a tracer writing to dir. Each regular expression in
+// patterns is redacted from the traces, as is each non-empty secret, such as
+// the API keys in use.
+func NewTracer(dir string, patterns []string, secrets ...string) (*Tracer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trace directory: %w", err)
+	}
+
+	tracer := &Tracer{dir: dir}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trace redaction pattern %q: %w", pattern, err)
+		}
+		tracer.redact = append(tracer.redact, re)
+	}
+	for _, secret := range secrets {
+		if secret != "" {
+			tracer.secrets = append(tracer.secrets, secret)
+		}
+	}
+	return tracer, nil
+}
+
+// traceRecord is one call to the backend
+type traceRecord struct {
+	Provider string
+	Model    string
+	Color    string
+	Request  OllamaRequest
+	Raw      string          // the response body as received, when available
+	Response *OllamaResponse // the decoded response, when there's no raw body
+	Move     *ChessMove      // the move chosen, for move-selecting providers
+	Err      error
+	Duration time.Duration
+}
+
+// write saves a record to a new timestamped file and returns its path
+func (t *Tracer) write(record traceRecord) (string, error) {
+	t.mu.Lock()
+	t.seq++
+	seq := t.seq
+	t.mu.Unlock()
+
+	color := record.Color
+	if color == "" {
+		color = "ai"
+	}
+	now := time.Now()
+	name := fmt.Sprintf("%s-%04d-%s.txt", now.Format("20060102-150405.000"), seq, color)
+	path := filepath.Join(t.dir, name)
+
+	if err := os.WriteFile(path, []byte(t.redactText(record.format(now))), 0600); err != nil {
+		return "", fmt.Errorf("failed to write AI trace: %w", err)
+	}
+	return path, nil
+}
+
+// redactText removes secrets and redacted patterns from text
+func (t *Tracer) redactText(text string) string {
+	for _, secret := range t.secrets {
+		text = strings.ReplaceAll(text, secret, redactedText)
+	}
+	for _, re := range t.redact {
+		text = re.ReplaceAllString(text, redactedText)
+	}
+	return text
+}
+
+// format lays out a record as plain text, with the prompt and response
+// verbatim so they can be read or replayed as sent
+func (r traceRecord) format(now time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Time: %s\n", now.Format(time.RFC3339Nano))
+	fmt.Fprintf(&sb, "Provider: %s\n", r.Provider)
+	fmt.Fprintf(&sb, "Model: %s\n", r.Model)
+	fmt.Fprintf(&sb, "Color: %s\n", r.Color)
+	fmt.Fprintf(&sb, "Duration: %s\n", r.Duration.Round(time.Millisecond))
+	if r.Err != nil {
+		fmt.Fprintf(&sb, "Error: %v\n", r.Err)
+	}
+
+	request := r.Request
+	request.Prompt = ""
+	requestJSON, _ := json.MarshalIndent(request, "", "  ")
+	fmt.Fprintf(&sb, "\n=== Request (prompt below) ===\n%s\n", requestJSON)
+	fmt.Fprintf(&sb, "\n=== Prompt ===\n%s\n", r.Request.Prompt)
+
+	switch {
+	case r.Raw != "":
+		fmt.Fprintf(&sb, "\n=== Raw response ===\n%s\n", r.Raw)
+	case r.Response != nil:
+		responseJSON, _ := json.MarshalIndent(r.Response, "", "  ")
+		fmt.Fprintf(&sb, "\n=== Response ===\n%s\n", responseJSON)
+	}
+	if r.Move != nil {
+		moveJSON, _ := json.MarshalIndent(r.Move, "", "  ")
+		fmt.Fprintf(&sb, "\n=== Move ===\n%s\n", moveJSON)
+	}
+	return sb.String()
+}
+
+// trace records a backend call when tracing is on
+func (ai *AIPlayer) trace(record traceRecord) {
+	if ai.Tracer == nil {
+		return
+	}
+	record.Provider = ai.ProviderName()
+	record.Model = ai.Model
+	record.Color = ai.Color
+
+	path, err := ai.Tracer.write(record)
+	if err != nil {
+		ai.Logger.Warn("⚠️ %s%v%s", ColorYellow, err, ColorReset)
+		return
+	}
+	ai.Logger.Debug("🧾 %sAI trace written to %s%s", ColorGray, path, ColorReset)
+}
+
+// traceSecrets returns the API keys a configuration may send, so traces
+// never contain them
+func traceSecrets(config *Config) []string {
+	secrets := []string{config.APIKey, os.Getenv("OPENAI_API_KEY"), os.Getenv("ANTHROPIC_API_KEY")}
+	for _, member := range slices.Concat(config.Providers, config.Voters) {
+		secrets = append(secrets, member.APIKey)
+	}
+	return secrets
+}
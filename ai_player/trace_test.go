@@ -0,0 +1,78 @@
+package ai_player
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readTraces returns the contents of the trace files in dir
+func readTraces(t *testing.T, dir string) []string {
+	t.Helper()
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.txt"))
+	traces := make([]string, len(paths))
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read trace: %v", err)
+		}
+		traces[i] = string(data)
+	}
+	return traces
+}
+
+func TestTraceOllamaCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response":"Nf3","done":false}` + "\n" + `{"response":"","done":true,"eval_count":3}` + "\n"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	tracer, err := NewTracer(dir, []string{`secret-\d+`}, "sk-live")
+	if err != nil {
+		t.Fatalf("Failed to create tracer: %v", err)
+	}
+	player := NewAIPlayer(server.URL, "test-model", "white", quietLogger())
+	player.Tracer = tracer
+
+	if _, err := player.generate(OllamaRequest{Model: "test-model", Prompt: "Play secret-42 with key sk-live"}); err != nil {
+		t.Fatalf("Expected a response, got %v", err)
+	}
+
+	traces := readTraces(t, dir)
+	if len(traces) != 1 {
+		t.Fatalf("Expected 1 trace file, got %d", len(traces))
+	}
+	trace := traces[0]
+	for _, want := range []string{"Model: test-model", "Color: white", "Play [REDACTED] with key [REDACTED]", `{"response":"Nf3","done":false}`, `"stream": true`} {
+		if !strings.Contains(trace, want) {
+			t.Errorf("Expected trace to contain %q, got:\n%s", want, trace)
+		}
+	}
+	if strings.Contains(trace, "secret-42") || strings.Contains(trace, "sk-live") {
+		t.Errorf("Expected secrets to be redacted, got:\n%s", trace)
+	}
+}
+
+func TestTraceSelectedMove(t *testing.T) {
+	dir := t.TempDir()
+	tracer, _ := NewTracer(dir, nil)
+	player := &AIPlayer{Provider: NewEngineProvider(), Logger: quietLogger(), Color: "black", Tracer: tracer}
+
+	if _, err := player.GetMove(startFEN, nil); err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	traces := readTraces(t, dir)
+	if len(traces) != 1 || !strings.Contains(traces[0], "=== Move ===") || !strings.Contains(traces[0], "Provider: engine") {
+		t.Errorf("Expected a move trace, got %q", traces)
+	}
+}
+
+func TestNewTracerRejectsBadPattern(t *testing.T) {
+	if _, err := NewTracer(t.TempDir(), []string{"("}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
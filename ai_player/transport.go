@@ -0,0 +1,47 @@
+package ai_player
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"chess-tui/netconfig"
+)
+
+// Connection pool tuning. Every player in a game or match talks to the same
+// one or few backends, so keeping connections open saves a TCP handshake,
+// and a TLS one for hosted APIs, on every move.
+const (
+	maxIdleConns        = 64
+	maxIdleConnsPerHost = 16
+	idleConnTimeout     = 90 * time.Second
+)
+
+// drainLimit is how much of an unread response body is read before closing
+// it so its connection can be reused; anything longer isn't worth waiting for
+const drainLimit = 64 * 1024
+
+// sharedTransport is the connection pool shared by every player and provider
+var sharedTransport = newTransport()
+
+// newTransport creates a transport tuned for many requests to a few hosts,
+// on the program's network settings
+func newTransport() *http.Transport {
+	transport := netconfig.NewTransport()
+	transport.MaxIdleConns = maxIdleConns
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.IdleConnTimeout = idleConnTimeout
+	transport.ExpectContinueTimeout = time.Second
+	return transport
+}
+
+// newHTTPClient returns a client on the shared connection pool
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: sharedTransport, Timeout: timeout}
+}
+
+// drain reads what is left of a response body, so closing it returns the
+// connection to the pool instead of dropping it
+func drain(body io.Reader) {
+	io.Copy(io.Discard, io.LimitReader(body, drainLimit))
+}
@@ -0,0 +1,70 @@
+package ai_player
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingOllama is a fake Ollama that counts the connections made to it
+func countingOllama(t testing.TB) (*httptest.Server, *atomic.Int64) {
+	var connections atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response":"e4"}` + "\n" + `{"response":"","done":true,"eval_count":1}` + "\n"))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, &connections
+}
+
+func TestPlayersShareConnections(t *testing.T) {
+	server, connections := countingOllama(t)
+	white := NewAIPlayer(server.URL, "test-model", "white", quietLogger())
+	black := NewAIPlayer(server.URL, "test-model", "black", quietLogger())
+
+	for i := 0; i < 3; i++ {
+		for _, player := range []*AIPlayer{white, black} {
+			if _, err := player.GetMove(startFEN, nil); err != nil {
+				t.Fatalf("Expected a move, got %v", err)
+			}
+		}
+	}
+	if n := connections.Load(); n != 1 {
+		t.Errorf("Expected both players to reuse one connection for 6 moves, got %d connections", n)
+	}
+}
+
+// BenchmarkOllamaMove compares moves over the shared connection pool with a
+// new connection per move, as an AI vs AI match makes them
+func BenchmarkOllamaMove(b *testing.B) {
+	run := func(b *testing.B, client *http.Client) {
+		server, connections := countingOllama(b)
+		player := NewAIPlayer(server.URL, "test-model", "white", quietLogger())
+		player.Client = client
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := player.GetMove(startFEN, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(connections.Load())/float64(b.N), "conns/op")
+	}
+
+	b.Run("pooled", func(b *testing.B) {
+		run(b, newHTTPClient(time.Minute))
+	})
+	b.Run("new-connection", func(b *testing.B) {
+		transport := newTransport()
+		transport.DisableKeepAlives = true
+		run(b, &http.Client{Transport: transport, Timeout: time.Minute})
+	})
+}
@@ -0,0 +1,182 @@
+package ai_player
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"chess-tui/variant"
+
+	"github.com/notnil/chess"
+)
+
+// ValidationError names the field of a malformed A2A request and what is
+// wrong with it. It is sent as the data of an invalid params error, with an
+// example of a valid request, so agent developers can see how to fix theirs.
+type ValidationError struct {
+	Field   string      `json:"field"` // e.g. "params.message.parts[0].text.player_color"
+	Problem string      `json:"problem"`
+	Example interface{} `json:"example"` // valid message/send params
+}
+
+// Error describes the problem
+func (v *ValidationError) Error() string {
+	return fmt.Sprintf("%s %s", v.Field, v.Problem)
+}
+
+// invalidField returns a validation error for a field, with the example
+func invalidField(field, format string, args ...interface{}) *ValidationError {
+	return &ValidationError{Field: field, Problem: fmt.Sprintf(format, args...), Example: exampleMessageSendParams()}
+}
+
+// exampleMessageSendParams returns the params of a valid move request
+func exampleMessageSendParams() map[string]interface{} {
+	fen := chess.StartingPosition().String()
+	request, _ := json.Marshal(ChessRequest{
+		BoardState:  fen,
+		FEN:         fen,
+		PlayerColor: "white",
+		GameHistory: []string{},
+	})
+	return map[string]interface{}{
+		"message": map[string]interface{}{
+			"kind":      "message",
+			"messageId": "msg_1",
+			"role":      "user",
+			"contextId": "game_1",
+			"parts":     []interface{}{map[string]interface{}{"kind": "text", "text": string(request)}},
+		},
+	}
+}
+
+// validateMessageSendParams checks the shape of message/send params before
+// they are decoded, so the error names the field instead of quoting the
+// decoder
+func validateMessageSendParams(raw json.RawMessage) *ValidationError {
+	var params map[string]interface{}
+	if err := json.Unmarshal(raw, &params); err != nil || params == nil {
+		return invalidField("params", "must be an object with a message")
+	}
+	message, ok := params["message"].(map[string]interface{})
+	if !ok {
+		return invalidField("params.message", "is required and must be an object")
+	}
+
+	if kind, _ := message["kind"].(string); kind != "message" {
+		return invalidField("params.message.kind", `must be "message"`)
+	}
+	if id, _ := message["messageId"].(string); id == "" {
+		return invalidField("params.message.messageId", "is required and must be a non-empty string")
+	}
+	if role, _ := message["role"].(string); role != string(MessageRoleUser) && role != string(MessageRoleAgent) {
+		return invalidField("params.message.role", `must be "user" or "agent"`)
+	}
+	if id, ok := message["contextId"]; ok {
+		if _, isString := id.(string); !isString {
+			return invalidField("params.message.contextId", "must be a string")
+		}
+	}
+
+	parts, ok := message["parts"].([]interface{})
+	if !ok || len(parts) == 0 {
+		return invalidField("params.message.parts", "must be a non-empty array of parts")
+	}
+	for i, part := range parts {
+		field := fmt.Sprintf("params.message.parts[%d]", i)
+		part, ok := part.(map[string]interface{})
+		if !ok {
+			return invalidField(field, "must be an object")
+		}
+		switch kind, _ := part["kind"].(string); kind {
+		case "text":
+			if _, ok := part["text"].(string); !ok {
+				return invalidField(field+".text", "is required in a text part and must be a string")
+			}
+		case "data":
+			if _, ok := part["data"].(map[string]interface{}); !ok {
+				return invalidField(field+".data", "is required in a data part and must be an object")
+			}
+		case "file":
+		default:
+			return invalidField(field+".kind", `must be "text", "data" or "file"`)
+		}
+	}
+	return nil
+}
+
+// validateChessRequest checks a chess request's fields once any saved
+// session has filled in what the client left out. field is where the
+// request was found, e.g. "params.message.parts[0].text".
+func validateChessRequest(req ChessRequest, field string) *ValidationError {
+	if req.BoardState == "" {
+		return invalidField(field+".board_state", "is required: send the position as FEN, or the contextId of a game in progress")
+	}
+	switch req.PlayerColor {
+	case "", "white", "black":
+	default:
+		return invalidField(field+".player_color", `must be "white" or "black", got %q`, req.PlayerColor)
+	}
+	switch req.Task {
+	case "", TaskSuggest:
+	case TaskReview:
+		if strings.TrimSpace(req.Move) == "" {
+			return invalidField(field+".move", "is required to review a move, e.g. \"e4\"")
+		}
+	default:
+		return invalidField(field+".task", `must be empty for a move, %q for candidate moves or %q to review a move, got %q`, TaskSuggest, TaskReview, req.Task)
+	}
+	for _, fen := range []struct{ name, value string }{{"fen", req.FEN}, {"start_fen", req.StartFEN}} {
+		if fen.value == "" {
+			continue
+		}
+		if _, err := chess.FEN(fen.value); err != nil {
+			return invalidField(field+"."+fen.name, "is not a valid FEN: %v", err)
+		}
+	}
+	for i, move := range req.GameHistory {
+		if strings.TrimSpace(move) == "" {
+			return invalidField(fmt.Sprintf("%s.game_history[%d]", field, i), "is empty; moves are SAN or UCI strings such as \"e4\" or \"e2e4\"")
+		}
+	}
+	return nil
+}
+
+// validateVariantRequest checks a move request in a chess variant, whose
+// board is in the variant's FEN
+func validateVariantRequest(req ChessRequest, field string) *ValidationError {
+	if _, err := variant.Lookup(req.Variant); err != nil {
+		return invalidField(field+".variant", "%v", err)
+	}
+	if req.BoardState == "" {
+		return invalidField(field+".board_state", "is required: send the position in the variant's FEN, e.g. with the reserves in brackets")
+	}
+	if req.Task != "" {
+		return invalidField(field+".task", "must be empty in a variant game, which only asks for moves, got %q", req.Task)
+	}
+	if _, err := variant.ParseFEN(req.BoardState); err != nil {
+		return invalidField(field+".board_state", "is not a valid %s position: %v", req.Variant, err)
+	}
+	return nil
+}
+
+// chessPayloadError describes why a text part's JSON isn't a chess request,
+// naming the field whose value has the wrong type when there is one
+func chessPayloadError(field string, err error) *ValidationError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return invalidField(field+"."+typeErr.Field, "must be %s, got %s", jsonTypeName(typeErr.Type.String()), typeErr.Value)
+	}
+	return invalidField(field, "is not a valid JSON chess request: %v", err)
+}
+
+// jsonTypeName names a Go type the way a JSON payload would
+func jsonTypeName(goType string) string {
+	switch goType {
+	case "string":
+		return "a string"
+	case "[]string":
+		return "an array of strings"
+	}
+	return goType
+}
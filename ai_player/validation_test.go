@@ -0,0 +1,73 @@
+package ai_player
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMalformedRequestsNameTheField(t *testing.T) {
+	server := httptest.NewServer(handleJSONRPCEndpoint(nil, nil, quietLogger()))
+	defer server.Close()
+
+	message := func(parts ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"message": map[string]interface{}{
+			"kind": "message", "messageId": "msg_1", "role": "user", "parts": parts,
+		}}
+	}
+	text := func(s string) map[string]interface{} { return map[string]interface{}{"kind": "text", "text": s} }
+
+	tests := []struct {
+		name   string
+		params interface{}
+		field  string
+	}{
+		{"no message", map[string]interface{}{}, "params.message"},
+		{"no parts", message(), "params.message.parts"},
+		{"bad part kind", message(map[string]interface{}{"kind": "txt", "text": "x"}), "params.message.parts[0].kind"},
+		{"no text or data part", message(map[string]interface{}{"kind": "file", "file": map[string]interface{}{}}), "params.message.parts"},
+		{"empty data part", message(map[string]interface{}{"kind": "data", "data": map[string]interface{}{}}), "params.message.parts[0].data.board_state"},
+		{"broken JSON", message(text(`{"board_state": `)), "params.message.parts[0].text"},
+		{"history as a string", message(text(`{"board_state": "x", "game_history": "e4 e5"}`)), "params.message.parts[0].text.game_history"},
+		{"bad color", message(text(`{"board_state": "x", "player_color": "red"}`)), "params.message.parts[0].text.player_color"},
+		{"bad fen", message(text(`{"fen": "not a fen"}`)), "params.message.parts[0].text.fen"},
+		{"no board", message(text(`{"player_color": "white"}`)), "params.message.parts[0].text.board_state"},
+	}
+	for _, tt := range tests {
+		reply := postJSONRPC(t, server.URL, map[string]interface{}{"jsonrpc": "2.0", "method": "message/send", "id": 1, "params": tt.params})
+		var response struct {
+			Error struct {
+				Code int             `json:"code"`
+				Data ValidationError `json:"data"`
+			} `json:"error"`
+		}
+		data, _ := json.Marshal(reply)
+		if err := json.Unmarshal(data, &response); err != nil {
+			t.Fatalf("%s: failed to decode reply: %v", tt.name, err)
+		}
+		if response.Error.Code != -32602 || response.Error.Data.Field != tt.field {
+			t.Errorf("%s: expected invalid params for %s, got %s", tt.name, tt.field, data)
+			continue
+		}
+		if response.Error.Data.Problem == "" || response.Error.Data.Example == nil {
+			t.Errorf("%s: expected a problem and an example, got %+v", tt.name, response.Error.Data)
+		}
+	}
+}
+
+func TestExampleRequestIsValid(t *testing.T) {
+	raw, _ := json.Marshal(exampleMessageSendParams())
+	if invalid := validateMessageSendParams(raw); invalid != nil {
+		t.Fatalf("Expected the example params to be valid, got %v", invalid)
+	}
+	var params MessageSendParams
+	json.Unmarshal(raw, &params)
+	var req ChessRequest
+	field, err := parseChessRequestFromJSONRPCMessage(params.Message, &req)
+	if err != nil {
+		t.Fatalf("Expected the example chess request to parse, got %v", err)
+	}
+	if invalid := validateChessRequest(req, field); invalid != nil {
+		t.Errorf("Expected the example chess request to be valid, got %v", invalid)
+	}
+}
@@ -0,0 +1,172 @@
+package ai_player
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"chess-tui/variant"
+
+	"github.com/notnil/chess"
+)
+
+// VariantMove chooses a move in a chess variant, fen being the variant's
+// FEN, e.g. with Crazyhouse reserves. The model is told the rules and
+// every legal move, as the standard move pipeline knows none of the
+// variant's moves, and providers that select moves are told the variant;
+// when the reply names none of the moves, or the backend can't give one,
+// the move that wins the most material is played instead.
+func (ai *AIPlayer) VariantMove(v variant.Variant, fen string, gameHistory []string) (*ChessMove, error) {
+	position, err := variant.ParseFEN(fen)
+	if err != nil {
+		return nil, err
+	}
+	moves := v.Moves(position)
+	if len(moves) == 0 {
+		return nil, fmt.Errorf("no legal moves: the game is over")
+	}
+	legal := make([]string, len(moves))
+	for i, move := range moves {
+		legal[i] = variant.Encode(v, position, move)
+	}
+
+	request := OllamaRequest{
+		Model:   ai.Model,
+		Prompt:  ai.buildVariantPrompt(v, position, gameHistory, legal),
+		Stream:  false,
+		Options: ai.moveOptions(),
+		Think:   ai.Think,
+
+		maxThinking: ai.MaxThinkingTokens,
+	}
+	if selector, ok := ai.Provider.(MoveSelector); ok {
+		ctx, cancel := context.WithTimeout(ai.baseContext(), 60*time.Second)
+		move, err := selector.SelectMove(ctx, MoveRequest{
+			Generate:   request,
+			FEN:        position.String(),
+			LegalMoves: legal,
+			Variant:    strings.ToLower(v.Name()),
+		})
+		cancel()
+		if err == nil && slices.Contains(legal, move.Notation) {
+			return move, nil
+		}
+		ai.Logger.Warn("⚠️ %s%s couldn't play %s, counting material instead: %v%s", ColorYellow, ai.ProviderName(), v.Name(), err, ColorReset)
+	} else if response, err := ai.generate(request); err != nil {
+		ai.Logger.Warn("⚠️ %s%s couldn't play %s, counting material instead: %v%s", ColorYellow, ai.ProviderName(), v.Name(), err, ColorReset)
+	} else if san, ok := listedMove(response.Response, legal); ok {
+		return &ChessMove{
+			Notation:         san,
+			Reasoning:        summarizeThinking(response.Thinking),
+			PromptTokens:     response.PromptEvalCount,
+			CompletionTokens: response.EvalCount,
+			Provider:         ai.ProviderName(),
+		}, nil
+	} else {
+		ai.Logger.Warn("⚠️ %sNo legal %s move in response, counting material instead: %s%s", ColorYellow, v.Name(), response.Response, ColorReset)
+	}
+
+	best := 0
+	bestScore := variantMoveScore(v, position, moves[0])
+	for i, move := range moves[1:] {
+		if score := variantMoveScore(v, position, move); score > bestScore {
+			best, bestScore = i+1, score
+		}
+	}
+	return &ChessMove{
+		Notation:  legal[best],
+		Reasoning: "chosen by material, the model having named no legal move",
+		Provider:  "engine",
+	}, nil
+}
+
+// buildVariantPrompt creates the prompt for a move in a variant: its rules,
+// the position with the reserves, and the legal moves to choose from
+func (ai *AIPlayer) buildVariantPrompt(v variant.Variant, position *variant.Position, gameHistory []string, legal []string) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("You are a chess AI playing ")
+	prompt.WriteString(v.Name())
+	prompt.WriteString(" as ")
+	prompt.WriteString(position.Turn().Name())
+	prompt.WriteString(".\n\n")
+	prompt.WriteString(v.Rules())
+	prompt.WriteString("\n\n")
+
+	prompt.WriteString("Current position (FEN, reserves in brackets):\n")
+	prompt.WriteString(position.String())
+	prompt.WriteString("\n")
+	if position.Reserves != nil {
+		for _, color := range []chess.Color{chess.White, chess.Black} {
+			prompt.WriteString(color.Name())
+			prompt.WriteString(" holds in reserve: ")
+			prompt.WriteString(position.Reserves[color].String())
+			prompt.WriteString("\n")
+		}
+	}
+	prompt.WriteString("\n")
+
+	if len(gameHistory) > 0 {
+		start := max(len(gameHistory)-10, 0)
+		prompt.WriteString("Recent moves: ")
+		prompt.WriteString(strings.Join(gameHistory[start:], " "))
+		prompt.WriteString("\n\n")
+	}
+
+	prompt.WriteString("Legal moves: ")
+	prompt.WriteString(strings.Join(legal, ", "))
+	prompt.WriteString("\n\n")
+
+	prompt.WriteString("Choose the strongest move from the legal moves. Reply with the move only, exactly as listed.\n")
+	return prompt.String()
+}
+
+// listedMove returns the first word of response that is one of the legal
+// moves, check markers aside
+func listedMove(response string, legal []string) (string, bool) {
+	bare := make(map[string]string, len(legal))
+	for _, san := range legal {
+		bare[strings.TrimRight(san, "+#")] = san
+	}
+	for _, word := range strings.Fields(response) {
+		word = strings.TrimRight(strings.Trim(word, "\"'`*.,;:()[]"), "+#!?")
+		if san, ok := bare[word]; ok {
+			return san, true
+		}
+	}
+	return "", false
+}
+
+// variantMoveScore rates a move by what it wins: the game, or the material
+// it captures less any of its own pieces an explosion takes, with checks
+// breaking ties
+func variantMoveScore(v variant.Variant, position *variant.Position, move variant.Move) int {
+	mover := position.Turn()
+	next := v.Play(position, move)
+	if outcome, _ := v.Outcome(next); (outcome == chess.WhiteWon && mover == chess.White) || (outcome == chess.BlackWon && mover == chess.Black) {
+		return engineMateScore
+	}
+	score := 0
+	if !move.IsDrop() {
+		board := position.Board.Board()
+		lost := []chess.Square{move.Board.S2()}
+		if exploder, ok := v.(variant.Exploder); ok {
+			lost = exploder.Explosion(position, move)
+		}
+		for _, square := range lost {
+			switch piece := board.Piece(square); {
+			case piece == chess.NoPiece:
+			case piece.Color() == mover:
+				score -= pieceValues[piece.Type()]
+			default:
+				score += pieceValues[piece.Type()]
+			}
+		}
+	}
+	if v.InCheck(next) {
+		score += 10
+	}
+	return score
+}
@@ -0,0 +1,96 @@
+package ai_player
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"chess-tui/errkind"
+	"chess-tui/jsonrpc"
+	"chess-tui/variant"
+)
+
+// backRankFEN has White able to mate by dropping the rook it holds on the
+// back rank, Black holding nothing to block with
+const backRankFEN = "6k1/5ppp/8/8/8/8/8/6K1[R] w - - 0 1"
+
+func TestVariantMoveFromModel(t *testing.T) {
+	provider := &scriptedProvider{replies: []string{"Dropping the rook mates: R@e8#"}}
+	ai := &AIPlayer{Provider: provider, Logger: quietLogger(), Model: "scripted"}
+
+	move, err := ai.VariantMove(variant.Crazyhouse{}, backRankFEN, []string{"e4", "e5"})
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if move.Notation != "R@e8#" || move.Provider != "scripted" {
+		t.Errorf("Expected the model's R@e8#, got %+v", move)
+	}
+
+	prompt := provider.prompts[0]
+	for _, want := range []string{"Crazyhouse", "White holds in reserve: rook", "Black holds in reserve: nothing", "R@e8#", "Recent moves: e4 e5"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to mention %q, got:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestVariantMoveFallsBackToMaterial(t *testing.T) {
+	ai := &AIPlayer{Provider: &scriptedProvider{replies: []string{"Qh5 looks strong"}}, Logger: quietLogger(), Model: "scripted"}
+
+	move, err := ai.VariantMove(variant.Crazyhouse{}, backRankFEN, nil)
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if !strings.HasPrefix(move.Notation, "R@") || !strings.HasSuffix(move.Notation, "#") || move.Provider != "engine" {
+		t.Errorf("Expected a mating rook drop from the fallback, got %+v", move)
+	}
+
+	if _, err := ai.VariantMove(variant.Crazyhouse{}, "6k1/5ppp/8/8/8/8/8/6K1[R w - - 0 1", nil); err == nil {
+		t.Errorf("Expected a bad FEN to be refused")
+	}
+}
+
+func TestAtomicMoveBlowsUpTheKing(t *testing.T) {
+	// In check from the h1 rook, White wins by exploding the knight beside
+	// Black's king; the built-in engine only plays standard chess
+	ai := &AIPlayer{Provider: NewEngineProvider(), Logger: quietLogger()}
+
+	move, err := ai.VariantMove(variant.Atomic{}, "k7/1n6/8/8/8/8/8/1R2K2r w - - 0 1", nil)
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if move.Notation != "Rxb7" || move.Provider != "engine" {
+		t.Errorf("Expected Rxb7 from the fallback, got %+v", move)
+	}
+}
+
+func TestVariantRequestOverA2A(t *testing.T) {
+	server := startReviewer(t)
+
+	reply := string(postChessRequest(t, server.URL, "", ChessRequest{Variant: "crazyhouse", BoardState: backRankFEN, PlayerColor: "white"}))
+	if !strings.Contains(reply, "Generated move: R@") || !strings.Contains(reply, `"variant":"crazyhouse"`) {
+		t.Errorf("Expected a rook drop, got %s", reply)
+	}
+
+	reply = string(postChessRequest(t, server.URL, "", ChessRequest{Variant: "bughouse", BoardState: backRankFEN}))
+	if !strings.Contains(reply, `"field":"params.message.parts[0].text.variant"`) {
+		t.Errorf("Expected an unknown variant to be refused, got %s", reply)
+	}
+
+	reply = string(postChessRequest(t, server.URL, "", ChessRequest{Variant: "crazyhouse", BoardState: backRankFEN, Task: TaskSuggest}))
+	if !strings.Contains(reply, `"field":"params.message.parts[0].text.task"`) {
+		t.Errorf("Expected a variant suggest task to be refused, got %s", reply)
+	}
+}
+
+func TestUnknownVariantIsAnInvalidRequest(t *testing.T) {
+	player := &AIPlayer{Provider: &scriptedProvider{}, Logger: quietLogger(), Model: "scripted"}
+	_, err := processVariantRequest(ChessRequest{Variant: "bughouse", BoardState: backRankFEN, PlayerColor: "white"}, player, quietLogger())
+	if kind := errkind.Of(err); kind != errkind.ProtocolError {
+		t.Fatalf("Expected a protocol error, got %q (%v)", kind, err)
+	}
+	var rpcErr *jsonrpc.Error
+	if !errors.As(kindError(err), &rpcErr) || rpcErr.Code != errkind.CodeProtocolError {
+		t.Errorf("Expected the invalid request code, got %v", kindError(err))
+	}
+}
@@ -0,0 +1,176 @@
+package ai_player
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Vote is one voter's proposal in a vote chess panel
+type Vote struct {
+	Voter  string  `json:"voter"`
+	Move   string  `json:"move,omitempty"` // in SAN; empty if the voter failed
+	Weight float64 `json:"weight"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// VoteProvider asks every member for a move to the same position and plays
+// the one with the most weight behind it. Ties go to the move of the
+// earliest member proposing one of them.
+type VoteProvider struct {
+	Members  []Provider
+	Labels   []string // how each member is shown in the breakdown
+	Weights  []float64
+	Timeouts []time.Duration
+	Logger   *ColoredLogger
+
+	// Parse turns a text response into a move; set by NewAIPlayerFromConfig
+	Parse func(response string) (*ChessMove, error)
+}
+
+// NewVoteProvider creates a panel of voting providers. Members without a
+// positive weight get a weight of 1.
+func NewVoteProvider(members []Provider, labels []string, weights []float64, timeouts []time.Duration, logger *ColoredLogger) *VoteProvider {
+	if logger == nil {
+		logger = NewAIPlayerLogger()
+	}
+	return &VoteProvider{
+		Members:  members,
+		Labels:   labels,
+		Weights:  weights,
+		Timeouts: timeouts,
+		Logger:   logger,
+	}
+}
+
+// Name describes the panel
+func (v *VoteProvider) Name() string {
+	return fmt.Sprintf("vote of %d", len(v.Members))
+}
+
+// label returns how member i is shown
+func (v *VoteProvider) label(i int) string {
+	if i < len(v.Labels) && v.Labels[i] != "" {
+		return v.Labels[i]
+	}
+	return v.Members[i].Name()
+}
+
+// weight returns the weight of member i's vote
+func (v *VoteProvider) weight(i int) float64 {
+	if i < len(v.Weights) && v.Weights[i] > 0 {
+		return v.Weights[i]
+	}
+	return 1
+}
+
+// memberContext bounds a call to member i by its configured timeout
+func (v *VoteProvider) memberContext(ctx context.Context, i int) (context.Context, context.CancelFunc) {
+	if i < len(v.Timeouts) && v.Timeouts[i] > 0 {
+		return context.WithTimeout(ctx, v.Timeouts[i])
+	}
+	return context.WithCancel(ctx)
+}
+
+// Generate sends a text request, such as for teach mode's candidates, to
+// the members in order until one answers
+func (v *VoteProvider) Generate(ctx context.Context, request OllamaRequest) (*OllamaResponse, error) {
+	var lastErr error
+	for i, member := range v.Members {
+		memberCtx, cancel := v.memberContext(ctx, i)
+		response, err := member.Generate(memberCtx, request)
+		cancel()
+		if err == nil {
+			return response, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("all voters failed: %w", lastErr)
+}
+
+// SelectMove asks every member for a move at once and returns the winner
+// of the weighted vote, with the breakdown in its Votes. Members that fail
+// or answer with an illegal move abstain.
+func (v *VoteProvider) SelectMove(ctx context.Context, request MoveRequest) (*ChessMove, error) {
+	moves := make([]*ChessMove, len(v.Members))
+	errs := make([]error, len(v.Members))
+	var wg sync.WaitGroup
+	for i := range v.Members {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			memberCtx, cancel := v.memberContext(ctx, i)
+			defer cancel()
+			moves[i], errs[i] = selectFrom(memberCtx, v.Members[i], v.Parse, request)
+		}(i)
+	}
+	wg.Wait()
+
+	votes := make([]Vote, len(v.Members))
+	tally := make(map[string]float64)
+	var winner *ChessMove
+	var lastErr error
+	promptTokens, completionTokens := 0, 0
+	for i := range v.Members {
+		votes[i] = Vote{Voter: v.label(i), Weight: v.weight(i)}
+		if errs[i] != nil {
+			votes[i].Error = errs[i].Error()
+			lastErr = errs[i]
+			v.Logger.Warn("🗳️ %sVoter %s abstains: %v%s", ColorYellow, votes[i].Voter, errs[i], ColorReset)
+			continue
+		}
+		votes[i].Move = moves[i].Notation
+		tally[moves[i].Notation] += votes[i].Weight
+		promptTokens += moves[i].PromptTokens
+		completionTokens += moves[i].CompletionTokens
+	}
+	for i, move := range moves {
+		if errs[i] == nil && (winner == nil || tally[move.Notation] > tally[winner.Notation]) {
+			winner = move
+		}
+	}
+	if winner == nil {
+		return nil, fmt.Errorf("no voter proposed a legal move: %w", lastErr)
+	}
+
+	// The vote's margin is its confidence: the share of the weight cast
+	// that backs the winner
+	cast := 0.0
+	for _, vote := range votes {
+		if vote.Move != "" {
+			cast += vote.Weight
+		}
+	}
+	confidence := tally[winner.Notation] / cast
+
+	result := *winner
+	result.Confidence = &confidence
+	result.PromptTokens = promptTokens
+	result.CompletionTokens = completionTokens
+	result.Votes = votes
+	v.Logger.Info("🗳️ %sVote chose %s with %.1f of %.1f%s", ColorCyan, result.Notation, tally[result.Notation], v.totalWeight(), ColorReset)
+	return &result, nil
+}
+
+// totalWeight sums the weights of every member
+func (v *VoteProvider) totalWeight() float64 {
+	total := 0.0
+	for i := range v.Members {
+		total += v.weight(i)
+	}
+	return total
+}
+
+// TestConnection succeeds if any member is reachable, as the others abstain
+func (v *VoteProvider) TestConnection() error {
+	var lastErr error
+	for _, member := range v.Members {
+		err := member.TestConnection()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("no voter reachable: %w", lastErr)
+}
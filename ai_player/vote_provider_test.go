@@ -0,0 +1,88 @@
+package ai_player
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestVoteWeightedMajority(t *testing.T) {
+	player := NewAIPlayer("", "m", "white", nil)
+	panel := NewVoteProvider([]Provider{
+		&fakeProvider{name: "a", reply: "e2e4"},
+		&fakeProvider{name: "b", reply: "d2d4"},
+		&fakeProvider{name: "c", reply: "d4"},
+		&fakeProvider{name: "d", err: fmt.Errorf("connection refused")},
+	}, []string{"ollama llama3.2:3b"}, []float64{1.5, 1, 1, 5}, nil, player.Logger)
+	panel.Parse = player.parseMove
+	player.Provider = panel
+
+	move, err := player.GetMove(startFEN, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Notation != "d4" {
+		t.Errorf("Expected d4 with 2 of the votes' weight, got %s", move.Notation)
+	}
+	if move.Provider != "vote of 4" {
+		t.Errorf("Expected the panel as provider, got %s", move.Provider)
+	}
+	if len(move.Votes) != 4 || move.Votes[0].Voter != "ollama llama3.2:3b" || move.Votes[0].Move != "e4" || move.Votes[1].Voter != "b" {
+		t.Errorf("Expected every voter's proposal in order, got %+v", move.Votes)
+	}
+	if move.Votes[3].Move != "" || move.Votes[3].Error == "" {
+		t.Errorf("Expected the unreachable voter to abstain, got %+v", move.Votes[3])
+	}
+}
+
+func TestVoteTieGoesToEarliestVoter(t *testing.T) {
+	player := NewAIPlayer("", "m", "white", nil)
+	panel := NewVoteProvider([]Provider{
+		&fakeProvider{name: "a", reply: "Nf3"},
+		&fakeProvider{name: "b", reply: "e4"},
+	}, nil, nil, nil, player.Logger)
+	panel.Parse = player.parseMove
+	player.Provider = panel
+
+	move, err := player.GetMove(startFEN, nil)
+	if err != nil || move.Notation != "Nf3" {
+		t.Errorf("Expected the first voter's Nf3 to win the tie, got %v, %v", move, err)
+	}
+}
+
+func TestVoteWithNoLegalProposal(t *testing.T) {
+	player := NewAIPlayer("", "m", "white", nil)
+	panel := NewVoteProvider([]Provider{&fakeProvider{name: "a", reply: "Qh5"}}, nil, nil, nil, player.Logger)
+	panel.Parse = player.parseMove
+	player.Provider = panel
+
+	if _, err := player.GetMove(startFEN, nil); err == nil {
+		t.Error("Expected an error when no voter proposes a legal move")
+	}
+}
+
+func TestVotersConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.Voters = []Config{
+		{Provider: ProviderEngine, Weight: 2},
+		{Model: "qwen3:8b", OllamaURL: "http://localhost:11434"},
+	}
+	if err := config.ValidateConfig(); err != nil {
+		t.Fatalf("Expected a valid config, got %v", err)
+	}
+	player, err := NewAIPlayerFromConfig(config, "white", nil)
+	if err != nil {
+		t.Fatalf("Expected a player, got %v", err)
+	}
+	panel, ok := player.Provider.(*VoteProvider)
+	if !ok {
+		t.Fatalf("Expected a vote provider, got %T", player.Provider)
+	}
+	if panel.Parse == nil || panel.Labels[0] != "engine" || panel.Labels[1] != "ollama qwen3:8b" || panel.weight(0) != 2 || panel.weight(1) != 1 {
+		t.Errorf("Expected labelled, weighted voters, got %+v", panel)
+	}
+
+	config.Voters[0].Weight = -1
+	if err := config.ValidateConfig(); err == nil {
+		t.Error("Expected a negative weight to be rejected")
+	}
+}
@@ -0,0 +1,198 @@
+package cast
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Header is the first line of an asciicast v2 file
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is a single output event in an asciicast v2 file
+type Event struct {
+	Time float64
+	Type string
+	Data string
+}
+
+// MarshalJSON encodes the event as the [time, type, data] tuple used by asciicast
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.Time, e.Type, e.Data})
+}
+
+// UnmarshalJSON decodes the [time, type, data] tuple used by asciicast
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var tuple []json.RawMessage
+	if err := json.Unmarshal(data, &tuple); err != nil {
+		return err
+	}
+	if len(tuple) != 3 {
+		return fmt.Errorf("event must have 3 elements, got %d", len(tuple))
+	}
+	if err := json.Unmarshal(tuple[0], &e.Time); err != nil {
+		return fmt.Errorf("invalid event time: %w", err)
+	}
+	if err := json.Unmarshal(tuple[1], &e.Type); err != nil {
+		return fmt.Errorf("invalid event type: %w", err)
+	}
+	if err := json.Unmarshal(tuple[2], &e.Data); err != nil {
+		return fmt.Errorf("invalid event data: %w", err)
+	}
+	return nil
+}
+
+// Recorder tees terminal output into an asciicast v2 recording.
+// It wraps the real terminal so Bubble Tea still detects a TTY.
+type Recorder struct {
+	term  *os.File
+	file  *os.File
+	enc   *json.Encoder
+	start time.Time
+	mu    sync.Mutex
+}
+
+// NewRecorder creates the recording file and writes the asciicast header
+func NewRecorder(path string, term *os.File, width, height int) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording file: %w", err)
+	}
+
+	start := time.Now()
+	header := Header{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Title:     "bubblechess",
+		Env: map[string]string{
+			"TERM":  os.Getenv("TERM"),
+			"SHELL": os.Getenv("SHELL"),
+		},
+	}
+
+	enc := json.NewEncoder(file)
+	if err := enc.Encode(header); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write recording header: %w", err)
+	}
+
+	return &Recorder{
+		term:  term,
+		file:  file,
+		enc:   enc,
+		start: start,
+	}, nil
+}
+
+// Write writes to the terminal and records the output as an event
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event := Event{
+		Time: time.Since(r.start).Seconds(),
+		Type: "o",
+		Data: string(p),
+	}
+	if err := r.enc.Encode(event); err != nil {
+		return 0, fmt.Errorf("failed to record output: %w", err)
+	}
+	return r.term.Write(p)
+}
+
+// Read reads from the wrapped terminal
+func (r *Recorder) Read(p []byte) (int, error) {
+	return r.term.Read(p)
+}
+
+// Fd returns the file descriptor of the wrapped terminal
+func (r *Recorder) Fd() uintptr {
+	return r.term.Fd()
+}
+
+// Close closes the recording file; the terminal is left open
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// Decode parses an asciicast v2 recording
+func Decode(rd io.Reader) (*Header, []Event, error) {
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read recording: %w", err)
+		}
+		return nil, nil, fmt.Errorf("recording is empty")
+	}
+
+	var header Header
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode recording header: %w", err)
+	}
+	if header.Version != 2 {
+		return nil, nil, fmt.Errorf("unsupported asciicast version: %d", header.Version)
+	}
+
+	var events []Event
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode event %d: %w", len(events)+1, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	return &header, events, nil
+}
+
+// Play writes the recorded output events to w, preserving their timing.
+// speed scales playback (2 plays twice as fast) and maxIdle caps pauses
+// between events when positive.
+func Play(w io.Writer, events []Event, speed float64, maxIdle time.Duration) error {
+	if speed <= 0 {
+		speed = 1
+	}
+
+	var last float64
+	for _, event := range events {
+		delay := time.Duration((event.Time - last) / speed * float64(time.Second))
+		if maxIdle > 0 && delay > maxIdle {
+			delay = maxIdle
+		}
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+		last = event.Time
+
+		if event.Type != "o" {
+			continue
+		}
+		if _, err := io.WriteString(w, event.Data); err != nil {
+			return fmt.Errorf("failed to write event: %w", err)
+		}
+	}
+	return nil
+}
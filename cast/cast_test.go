@@ -0,0 +1,64 @@
+package cast
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAndDecode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.cast")
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", os.DevNull, err)
+	}
+	defer devNull.Close()
+
+	recorder, err := NewRecorder(path, devNull, 100, 30)
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %v", err)
+	}
+	if _, err := recorder.Write([]byte("hello ")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if _, err := recorder.Write([]byte("world\r\n")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Failed to close recorder: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open recording: %v", err)
+	}
+	defer file.Close()
+
+	header, events, err := Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode recording: %v", err)
+	}
+	if header.Version != 2 || header.Width != 100 || header.Height != 30 {
+		t.Errorf("Unexpected header: %+v", header)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+
+	var out bytes.Buffer
+	if err := Play(&out, events, 1000, 0); err != nil {
+		t.Fatalf("Failed to play recording: %v", err)
+	}
+	if out.String() != "hello world\r\n" {
+		t.Errorf("Expected replayed output 'hello world\\r\\n', got %q", out.String())
+	}
+}
+
+func TestDecodeRejectsUnknownVersion(t *testing.T) {
+	_, _, err := Decode(bytes.NewBufferString(`{"version":1,"width":80,"height":24}` + "\n"))
+	if err == nil {
+		t.Error("Expected error for asciicast version 1")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"chess-tui/ai_player"
+	"chess-tui/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var adminCmd = &cobra.Command{
+	Use:   "admin <url>",
+	Short: "Manage a running A2A server",
+	Long: `Open the admin screen of the A2A server at url (e.g.
+http://localhost:8080), which must be started with an admin token.
+
+The screen shows the model being served and live metrics: requests,
+errors, response times and the queue. It lists the games in progress,
+and lets you kick a session, which refuses its game any more moves, or
+switch the model every game plays with from its next move.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := openAdmin(cmd, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening admin screen: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(adminCmd)
+
+	adminCmd.Flags().String("token", "", "The server's admin token (default $BUBBLECHESS_ADMIN_TOKEN)")
+}
+
+// openAdmin runs the admin screen until the operator quits
+func openAdmin(cmd *cobra.Command, url string) error {
+	token, _ := cmd.Flags().GetString("token")
+	if !cmd.Flags().Changed("token") {
+		token = os.Getenv("BUBBLECHESS_ADMIN_TOKEN")
+	}
+	if token == "" {
+		return fmt.Errorf("an admin token is required: pass --token or set $BUBBLECHESS_ADMIN_TOKEN")
+	}
+
+	ctx, cancel := programContext(cmd)
+	defer cancel()
+	screen := game.NewAdmin(ai_player.NewAdminClient(url, token))
+	screen.SetContext(ctx)
+	if _, err := runProgram(tea.NewProgram(screen, tea.WithContext(ctx), tea.WithReportFocus()), cancel); err != nil {
+		return fmt.Errorf("failed to run admin screen: %w", err)
+	}
+	return nil
+}
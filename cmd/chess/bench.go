@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"chess-tui/gamedb"
+	"chess-tui/tournament"
+
+	"github.com/spf13/cobra"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Estimate an AI config's Elo against a calibrated UCI engine",
+	Long: `Play the AI config against a UCI engine such as Stockfish limited to
+several known Elo levels, then estimate the config's rating from the results.
+
+With --dataset, also ask it for a move in the held-out positions of a
+dataset collected with "chess --collect-data", and report how often it
+matches the move played and how often it answers with an illegal move. Use
+--games 0 to evaluate a fine-tuned model on the dataset alone.
+
+The estimate is saved to the ratings file and shown in the TUI menu.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		defer interruptible(cmd)()
+		if err := runBench(cmd); err != nil {
+			exitWithError("Error running bench", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().StringP("config", "c", "ai_config.json", "AI config to rate")
+	benchCmd.Flags().String("engine", "stockfish", "UCI engine executable")
+	benchCmd.Flags().IntSlice("levels", []int{1350, 1600, 1900}, "Engine Elo levels to play (UCI_Elo)")
+	benchCmd.Flags().IntP("games", "g", 2, "Games per level; colors alternate")
+	benchCmd.Flags().Duration("engine-time", 100*time.Millisecond, "Engine thinking time per move")
+	benchCmd.Flags().String("ratings", "", "Ratings file (default ~/.bubblechess/ratings.json)")
+	benchCmd.Flags().String("pgn", "", "Append the games to this PGN file")
+	benchCmd.Flags().String("dataset", "", "Also evaluate the config on the held-out positions of this dataset (see --collect-data)")
+	benchCmd.Flags().Float64("holdout", defaultHoldout, "Fraction of the dataset's positions held out for evaluation; 1 evaluates all of them")
+	addRefereeFlags(benchCmd)
+	addHandicapFlags(benchCmd, "player", "the AI config")
+	addHandicapFlags(benchCmd, "engine", "the engine")
+	addTraceFlags(benchCmd)
+}
+
+// benchLevel is the score against one engine level
+type benchLevel struct {
+	elo   int
+	score float64
+	games int
+}
+
+func runBench(cmd *cobra.Command) error {
+	configPath, _ := cmd.Flags().GetString("config")
+	enginePath, _ := cmd.Flags().GetString("engine")
+	levels, _ := cmd.Flags().GetIntSlice("levels")
+	games, _ := cmd.Flags().GetInt("games")
+	engineTime, _ := cmd.Flags().GetDuration("engine-time")
+	ratingsPath, _ := cmd.Flags().GetString("ratings")
+	pgnPath, _ := cmd.Flags().GetString("pgn")
+	timeControl, err := matchTimeControl(cmd)
+	if err != nil {
+		return err
+	}
+
+	player, err := loadEntrant(cmd, configPath)
+	if err != nil {
+		return err
+	}
+	if player.Limits, err = handicapLimits(cmd, "player"); err != nil {
+		return err
+	}
+	engineLimits, err := handicapLimits(cmd, "engine")
+	if err != nil {
+		return err
+	}
+
+	match := tournament.NewMatch(matchAdjudication(cmd))
+	match.TimeControl = timeControl
+	match.Context = cmd.Context()
+
+	var rated []tournament.RatedGame
+	var report []benchLevel
+	if games <= 0 {
+		levels = nil // evaluating on the dataset alone
+	}
+	ctx := cmd.Context()
+	for _, elo := range levels {
+		if ctx.Err() != nil {
+			break
+		}
+		engine, err := tournament.NewUCIEngine(enginePath, elo)
+		if err != nil {
+			return fmt.Errorf("failed to start %s at Elo %d: %w", enginePath, elo, err)
+		}
+		engine.MoveTime = engineTime
+		opponent := tournament.Entrant{Name: fmt.Sprintf("%s (Elo %d)", enginePath, elo), Player: engine, Limits: engineLimits}
+
+		level := benchLevel{elo: elo}
+		for i := 0; i < games; i++ {
+			white, black := player, opponent
+			if i%2 == 1 {
+				white, black = opponent, player
+			}
+			if err := engine.NewGame(); err != nil {
+				engine.Close()
+				return err
+			}
+
+			fmt.Printf("Elo %d, game %d: %s vs %s\n", elo, i+1, white.Name, black.Name)
+			result, err := match.PlayGame(white, black)
+			if errors.Is(err, tournament.ErrInterrupted) {
+				break
+			}
+			if err != nil {
+				engine.Close()
+				return fmt.Errorf("game %d at Elo %d failed: %w", i+1, elo, err)
+			}
+			fmt.Printf("  %s in %d plies (%s)\n", result.Outcome, len(result.Moves), result.Reason)
+
+			score := result.ScoreFor(player.Name)
+			level.score += score
+			level.games++
+			rated = append(rated, tournament.RatedGame{OpponentElo: elo, Score: score})
+
+			if pgnPath != "" {
+				if err := appendPGN(pgnPath, result.PGN); err != nil {
+					engine.Close()
+					return err
+				}
+			}
+		}
+		engine.Close()
+		if level.games > 0 {
+			report = append(report, level)
+		}
+	}
+
+	var dataset *tournament.DatasetScore
+	if datasetPath, _ := cmd.Flags().GetString("dataset"); datasetPath != "" && ctx.Err() == nil {
+		holdout, _ := cmd.Flags().GetFloat64("holdout")
+		score, err := benchDataset(player, datasetPath, holdout)
+		if err != nil {
+			return err
+		}
+		// A score cut short by an interruption would understate the player
+		if ctx.Err() == nil {
+			dataset = &score
+		}
+	}
+	interrupted := context.Cause(ctx)
+	if len(rated) == 0 && dataset == nil {
+		if interrupted != nil {
+			return interrupted
+		}
+		return fmt.Errorf("nothing to bench: no games and no --dataset")
+	}
+
+	ratings, err := tournament.LoadRatings(ratingsPath)
+	if err != nil {
+		return err
+	}
+	rating := ratings[player.Name]
+	fmt.Printf("\nBench report: %s\n", player.Name)
+	if len(rated) > 0 {
+		estimate, err := tournament.EstimateElo(rated)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %-8s %-8s %s\n", "Level", "Score", "Games")
+		for _, level := range report {
+			fmt.Printf("  %-8d %-8.1f %d\n", level.elo, level.score, level.games)
+		}
+		fmt.Printf("Estimated Elo: %d (%d games)\n", estimate, len(rated))
+		rating.Elo, rating.Games = estimate, len(rated)
+	}
+	if dataset != nil {
+		fmt.Printf("Dataset: %.1f%% of %d held-out moves matched, %.1f%% illegal, %d failed\n",
+			100*dataset.Accuracy(), dataset.Positions, 100*dataset.IllegalRate(), dataset.Failed)
+		rating.Dataset = dataset
+	}
+
+	rating.Updated = time.Now()
+	ratings[player.Name] = rating
+	if err := tournament.SaveRatings(ratings, ratingsPath); err != nil {
+		return err
+	}
+	if interrupted != nil {
+		fmt.Printf("Interrupted: the estimate covers the %d games played\n", len(rated))
+	}
+	return interrupted
+}
+
+// benchDataset evaluates player on the held-out positions of the dataset at path
+func benchDataset(player tournament.Entrant, path string, holdout float64) (tournament.DatasetScore, error) {
+	samples, err := gamedb.OpenDataset(path).Samples()
+	if err != nil {
+		return tournament.DatasetScore{}, err
+	}
+	_, heldOut := gamedb.SplitSamples(samples, holdout)
+	if len(heldOut) == 0 {
+		return tournament.DatasetScore{}, fmt.Errorf("no held-out positions in %s (%d samples)", path, len(samples))
+	}
+
+	fmt.Printf("Dataset %s: %d held-out positions of %d\n", path, len(heldOut), len(samples))
+	return tournament.EvaluateDataset(player.Player, heldOut, func(done int, score tournament.DatasetScore) {
+		if done%50 == 0 || done == len(heldOut) {
+			fmt.Printf("  %d/%d: %.1f%% matched, %.1f%% illegal\n", done, len(heldOut), 100*score.Accuracy(), 100*score.IllegalRate())
+		}
+	}), nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"chess-tui/crash"
+	"chess-tui/game"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var reportBugCmd = &cobra.Command{
+	Use:   "report-bug",
+	Short: "Bundle the last session's transcript and setup for a bug report",
+	Long: `Bundle everything a bug report needs into one archive: the transcript of
+the last TUI session, the settings and AI config with API keys and tokens
+redacted, the versions of the game and its libraries, and the terminal's
+type and size.
+
+With --open, a new GitHub issue is opened in the browser, prefilled with the
+versions and terminal; attach the archive to it.`,
+	Example: `  chess report-bug
+  chess report-bug --open --title "AI keeps playing illegal castling moves"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := reportBug(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportBugCmd)
+
+	reportBugCmd.Flags().String("settings", "", "Display settings file (default ~/.bubblechess/settings.json)")
+	reportBugCmd.Flags().StringP("config", "c", "ai_config.json", "AI config file to include, if it exists")
+	reportBugCmd.Flags().String("transcript", "", "Transcript to include (default the settings' transcript, else the last session's)")
+	reportBugCmd.Flags().StringP("output", "o", "", "Archive to write (default bubblechess-bug-<time>.tar.gz)")
+	reportBugCmd.Flags().Bool("open", false, "Open a prefilled GitHub issue in the browser")
+	reportBugCmd.Flags().String("title", "", "Title of the issue --open prefills")
+}
+
+func reportBug(cmd *cobra.Command) error {
+	settingsPath, _ := cmd.Flags().GetString("settings")
+	if settingsPath == "" {
+		settingsPath = game.DefaultSettingsPath()
+	}
+	settings, err := game.LoadSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	report := &crash.BugReport{Configs: map[string][]byte{}, Environment: bugEnvironment()}
+	transcriptPath, _ := cmd.Flags().GetString("transcript")
+	if transcriptPath == "" {
+		transcriptPath = settings.Transcript
+	}
+	if transcriptPath == "" {
+		transcriptPath = game.DefaultTranscriptPath()
+	}
+	if report.Transcript, err = os.ReadFile(transcriptPath); err != nil {
+		fmt.Printf("No transcript included: %v\n", err)
+	}
+	configPath, _ := cmd.Flags().GetString("config")
+	for name, path := range map[string]string{"settings.json": settingsPath, "ai_config.json": configPath} {
+		if data, err := os.ReadFile(path); err == nil {
+			report.Configs[name] = data
+		}
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		output = fmt.Sprintf("bubblechess-bug-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+	if err := report.WriteArchive(output); err != nil {
+		return err
+	}
+	fmt.Printf("Bug report written to %s\n", output)
+
+	title, _ := cmd.Flags().GetString("title")
+	if title == "" {
+		title = "Bug report"
+	}
+	issueURL := report.IssueURL(title, filepath.Base(output))
+	if open, _ := cmd.Flags().GetBool("open"); open {
+		if err := openBrowser(issueURL); err == nil {
+			fmt.Println("Attach the archive to the issue opened in your browser.")
+			return nil
+		}
+	}
+	fmt.Printf("File an issue and attach the archive:\n%s\n", issueURL)
+	return nil
+}
+
+// bugEnvironment describes the versions and the terminal for a bug report
+func bugEnvironment() map[string]string {
+	env := map[string]string{
+		"go":       runtime.Version(),
+		"platform": runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		env["version"] = info.Main.Version
+		for _, dep := range info.Deps {
+			switch dep.Path {
+			case "github.com/charmbracelet/bubbletea", "github.com/charmbracelet/lipgloss", "github.com/notnil/chess":
+				env[dep.Path[strings.LastIndex(dep.Path, "/")+1:]] = dep.Version
+			}
+		}
+	}
+	for _, key := range []string{"TERM", "COLORTERM", "TERM_PROGRAM", "LANG", "TMUX"} {
+		if value := os.Getenv(key); value != "" {
+			env[key] = value
+		}
+	}
+	if columns, rows, err := term.GetSize(os.Stdout.Fd()); err == nil {
+		env["terminal"] = fmt.Sprintf("%dx%d", columns, rows)
+	}
+	env["ssh"] = fmt.Sprint(game.DetectLowBandwidth())
+	return env
+}
+
+// openBrowser opens url in the default browser
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
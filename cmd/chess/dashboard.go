@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"chess-tui/game"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+// watchMatch plays the match with the dashboard following it. Quitting the
+// dashboard stops the match like Ctrl+C; what the match printed is shown
+// once the dashboard closes.
+func watchMatch(cmd *cobra.Command) error {
+	games, _ := cmd.Flags().GetInt("games")
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+	cmd.SetContext(ctx)
+
+	p := tea.NewProgram(game.NewDashboard(games), tea.WithContext(ctx), tea.WithAltScreen())
+	var transcript bytes.Buffer
+	played := make(chan error, 1)
+	go func() {
+		err := runMatch(cmd, io.MultiWriter(&transcript, &dashboardLog{send: p.Send}), p.Send)
+		status := err
+		if errors.Is(err, context.Canceled) {
+			status = nil // the games played are kept, which the dashboard shows
+		}
+		p.Send(game.DashboardDoneMsg{Err: status})
+		played <- err
+	}()
+
+	_, err := runProgram(p, cancel)
+	matchErr := <-played
+	fmt.Print(transcript.String())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to run dashboard: %v\n", err)
+	}
+	return matchErr
+}
+
+// dashboardLog passes what the match prints to the dashboard a line at a time
+type dashboardLog struct {
+	send func(tea.Msg)
+
+	mu      sync.Mutex
+	partial string
+}
+
+func (l *dashboardLog) Write(b []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lines := strings.Split(l.partial+string(b), "\n")
+	l.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		if strings.TrimSpace(line) != "" {
+			l.send(game.DashboardLogMsg(line))
+		}
+	}
+	return len(b), nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"chess-tui/gamedb"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultHoldout is the fraction of a dataset's positions kept out of
+// training for evaluation
+const defaultHoldout = 0.1
+
+var datasetCmd = &cobra.Command{
+	Use:   "dataset <file>",
+	Short: "Summarize a training dataset and export its training split",
+	Long: `Summarize a dataset collected with "chess --collect-data": how many
+samples it holds, how they end for the AI and how many positions are held
+out for "chess bench --dataset".
+
+With --training, write the samples that are not held out to a file to
+fine-tune on, so the model is never evaluated on positions it trained on.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runDataset(cmd, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(datasetCmd)
+	datasetCmd.Flags().Float64("holdout", defaultHoldout, "Fraction of positions held out for evaluation")
+	datasetCmd.Flags().String("training", "", "Write the training split to this JSONL file")
+}
+
+func runDataset(cmd *cobra.Command, path string) error {
+	holdout, _ := cmd.Flags().GetFloat64("holdout")
+	trainingPath, _ := cmd.Flags().GetString("training")
+
+	samples, err := gamedb.OpenDataset(path).Samples()
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return fmt.Errorf("no samples in %s", path)
+	}
+	outcomes := make(map[string]int)
+	for _, sample := range samples {
+		outcomes[sample.Outcome]++
+	}
+	training, heldOut := gamedb.SplitSamples(samples, holdout)
+
+	fmt.Printf("%s: %d samples (%d from won games, %d drawn, %d lost)\n", path, len(samples),
+		outcomes[gamedb.OutcomeWin], outcomes[gamedb.OutcomeDraw], outcomes[gamedb.OutcomeLoss])
+	fmt.Printf("Training: %d, held out: %d\n", len(training), len(heldOut))
+
+	if trainingPath == "" {
+		return nil
+	}
+	if err := os.Remove(trainingPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace %s: %w", trainingPath, err)
+	}
+	if err := gamedb.OpenDataset(trainingPath).Add(training); err != nil {
+		return err
+	}
+	fmt.Printf("Training split written to %s\n", trainingPath)
+	return nil
+}
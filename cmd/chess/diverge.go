@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"chess-tui/gamedb"
+	"chess-tui/tournament"
+
+	"github.com/notnil/chess"
+	"github.com/spf13/cobra"
+)
+
+var divergeCmd = &cobra.Command{
+	Use:   "diverge [game.pgn ...]",
+	Short: "Replay stored games through an AI config and report where its moves differ",
+	Long: `Ask the AI config for a move in each position of stored games and report
+where it now chooses differently than in the game, to catch changes in
+behavior after upgrading a prompt or model.
+
+Games come from PGN files or, with --game or --all, from the game log. In
+games from the log only the AI's side is replayed; use --side to pick the
+side in PGN games (both by default). Set the config's temperature to 0 so
+that differences come from the upgrade rather than from sampling.
+
+With --strict the command fails if any move diverged, for use in CI.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runDiverge(cmd, args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking divergence: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(divergeCmd)
+
+	divergeCmd.Flags().StringP("config", "c", "ai_config.json", "AI config to replay the games through")
+	divergeCmd.Flags().Int("game", 0, "Replay a game from the game log, counting back from the latest (1 is the last game)")
+	divergeCmd.Flags().Bool("all", false, "Replay every game in the game log")
+	divergeCmd.Flags().String("games", "", "Game log to replay from (default ~/.bubblechess/games.jsonl)")
+	divergeCmd.Flags().String("side", "", "Side to replay: white, black or both (default the AI's side, or both in PGN games)")
+	divergeCmd.Flags().Bool("strict", false, "Fail if any move diverged")
+	addTraceFlags(divergeCmd)
+}
+
+// storedGame is a game to look for divergences in
+type storedGame struct {
+	title    string
+	startFEN string
+	moves    []string
+	side     chess.Color // the AI's side, or chess.NoColor for both
+}
+
+func runDiverge(cmd *cobra.Command, args []string) error {
+	configPath, _ := cmd.Flags().GetString("config")
+	strict, _ := cmd.Flags().GetBool("strict")
+	sideName, _ := cmd.Flags().GetString("side")
+	side, sideSet, err := divergeSide(sideName)
+	if err != nil {
+		return err
+	}
+
+	games, err := divergeGames(cmd, args)
+	if err != nil {
+		return err
+	}
+	if len(games) == 0 {
+		return fmt.Errorf("no games to replay: give PGN files, --game or --all")
+	}
+
+	player, err := loadEntrant(cmd, configPath)
+	if err != nil {
+		return err
+	}
+
+	positions, diverged, failed := 0, 0, 0
+	for i, stored := range games {
+		if sideSet {
+			stored.side = side
+		}
+		fmt.Printf("Game %d: %s (%s)\n", i+1, stored.title, sideLabel(stored.side))
+		report, err := tournament.FindDivergences(player.Player, stored.startFEN, stored.moves, stored.side, nil)
+		if err != nil {
+			return fmt.Errorf("game %d: %w", i+1, err)
+		}
+		for _, divergence := range report.Divergences {
+			fmt.Printf("  ply %d, %s: played %s, now %s\n", divergence.Ply+1, divergence.Color, divergence.Played, divergenceAnswer(divergence))
+		}
+		fmt.Printf("  %d of %d moves diverged (%.1f%%)", len(report.Divergences), report.Positions-report.Failed, 100*report.Rate())
+		if report.Failed > 0 {
+			fmt.Printf(", %d positions failed", report.Failed)
+		}
+		fmt.Println()
+		positions += report.Positions - report.Failed
+		diverged += len(report.Divergences)
+		failed += report.Failed
+	}
+
+	if len(games) > 1 {
+		fmt.Printf("\n%s: %d of %d moves diverged in %d games\n", player.Name, diverged, positions, len(games))
+	}
+	if positions == 0 && failed > 0 {
+		return fmt.Errorf("the AI answered in none of the %d positions", failed)
+	}
+	if strict && diverged > 0 {
+		return fmt.Errorf("%d moves diverged", diverged)
+	}
+	return nil
+}
+
+// divergeSide parses --side; set is false when it wasn't given
+func divergeSide(name string) (side chess.Color, set bool, err error) {
+	switch name {
+	case "":
+		return chess.NoColor, false, nil
+	case "both":
+		return chess.NoColor, true, nil
+	case "white":
+		return chess.White, true, nil
+	case "black":
+		return chess.Black, true, nil
+	}
+	return chess.NoColor, false, fmt.Errorf("invalid --side %q: want white, black or both", name)
+}
+
+// sideLabel describes which side of a game is replayed
+func sideLabel(side chess.Color) string {
+	if side == chess.NoColor {
+		return "both sides replayed"
+	}
+	return side.Name() + " replayed"
+}
+
+// divergenceAnswer describes the move the AI chose instead
+func divergenceAnswer(divergence tournament.Divergence) string {
+	if divergence.Error == "" {
+		return divergence.Chosen
+	}
+	return fmt.Sprintf("%s (%s)", divergence.Chosen, divergence.Error)
+}
+
+// divergeGames collects the games to replay from the PGN files and the game log
+func divergeGames(cmd *cobra.Command, paths []string) ([]storedGame, error) {
+	var games []storedGame
+	for _, path := range paths {
+		fromFile, err := pgnGames(path)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, fromFile...)
+	}
+
+	back, _ := cmd.Flags().GetInt("game")
+	all, _ := cmd.Flags().GetBool("all")
+	if back <= 0 && !all {
+		return games, nil
+	}
+	gamesPath, _ := cmd.Flags().GetString("games")
+	records, err := gamedb.Open(gamesPath).Games()
+	if err != nil {
+		return nil, err
+	}
+	if back > len(records) {
+		return nil, fmt.Errorf("the game log has %d games", len(records))
+	}
+	if !all {
+		records = records[len(records)-back : len(records)-back+1]
+	}
+	for _, record := range records {
+		games = append(games, loggedGame(record))
+	}
+	return games, nil
+}
+
+// pgnGames reads every game of a PGN file
+func pgnGames(path string) ([]storedGame, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PGN: %w", err)
+	}
+	defer file.Close()
+
+	var games []storedGame
+	scanner := chess.NewScanner(file)
+	for scanner.Scan() {
+		played := scanner.Next()
+		if len(played.Moves()) == 0 {
+			continue
+		}
+		stored := storedGame{title: fmt.Sprintf("%s game %d", path, len(games)+1), side: chess.NoColor}
+		if white, black := played.GetTagPair("White"), played.GetTagPair("Black"); white != nil && black != nil {
+			stored.title = white.Value + " vs " + black.Value
+		}
+		if fen := played.GetTagPair("FEN"); fen != nil {
+			stored.startFEN = fen.Value
+		}
+		positions := played.Positions()
+		for i, move := range played.Moves() {
+			stored.moves = append(stored.moves, chess.AlgebraicNotation{}.Encode(positions[i], move))
+		}
+		games = append(games, stored)
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read PGN: %w", err)
+	}
+	return games, nil
+}
+
+// loggedGame is a game from the game log, replaying the AI's side in games
+// against it
+func loggedGame(record gamedb.Record) storedGame {
+	stored := storedGame{
+		title:    fmt.Sprintf("%s vs %s, %s", record.White, record.Black, record.Played.Format("Jan 2, 2006")),
+		startFEN: record.StartFEN,
+		moves:    record.Moves,
+		side:     chess.NoColor,
+	}
+	switch record.HumanColor {
+	case "white":
+		stored.side = chess.Black
+	case "black":
+		stored.side = chess.White
+	}
+	return stored
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"chess-tui/game"
+	"chess-tui/rules"
+
+	"github.com/spf13/cobra"
+)
+
+// houseRuleFlags are the flags addHouseRuleFlags adds
+var houseRuleFlags = []string{"max-moves", "must-capture", "material-move", "material-margin"}
+
+// addHouseRuleFlags adds the flags that play a game by house rules
+func addHouseRuleFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("max-moves", 0, "House rule: draw the game after this many moves (0 disables)")
+	cmd.Flags().Bool("must-capture", false, "House rule: captures are compulsory")
+	cmd.Flags().Int("material-move", 0, "House rule: after this move the side ahead on material wins, and otherwise it's a draw (0 disables)")
+	cmd.Flags().Int("material-margin", 1, "Points (pawn 1, knight and bishop 3, rook 5, queen 9) --material-move needs a side to be ahead by")
+}
+
+// houseRules reads the house rules from the flags
+func houseRules(cmd *cobra.Command) (rules.Rules, error) {
+	var house rules.Rules
+	house.MaxMoves, _ = cmd.Flags().GetInt("max-moves")
+	house.MustCapture, _ = cmd.Flags().GetBool("must-capture")
+	house.MaterialMove, _ = cmd.Flags().GetInt("material-move")
+	if house.MaterialMove > 0 {
+		house.MaterialMargin, _ = cmd.Flags().GetInt("material-margin")
+	}
+	return house, house.Validate()
+}
+
+// applyHouseRuleFlags sets the house rules of TUI games from the flags,
+// leaving the settings' rules alone if none was given
+func applyHouseRuleFlags(cmd *cobra.Command, settings *game.Settings) error {
+	changed := false
+	for _, name := range houseRuleFlags {
+		changed = changed || cmd.Flags().Changed(name)
+	}
+	if !changed {
+		return nil
+	}
+	house, err := houseRules(cmd)
+	if err != nil {
+		return err
+	}
+	settings.Rules = nil
+	if house.Active() {
+		settings.Rules = &house
+	}
+	return nil
+}
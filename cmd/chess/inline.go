@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"chess-tui/ai_player"
+	"chess-tui/game"
+
+	"github.com/notnil/chess"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.Flags().Bool("no-tui", false, "Play line by line on stdin and stdout instead of the TUI, for piping and scripting")
+	rootCmd.Flags().Bool("json-io", false, `Like --no-tui, but each line in is a JSON command ({"move":"e4"} or {"cmd":"fen"}) and each line out a JSON event`)
+	rootCmd.Flags().String("ai-color", "", "With --no-tui or --json-io, the color the AI plays: white or black (default: two players)")
+}
+
+// playInline runs the line-based game the root command's flags describe, in
+// text or JSON, with the AI from --bot-script, --gguf, the built-in engine
+// --offline or the A2A server of the settings
+func playInline(cmd *cobra.Command) error {
+	inline := game.NewInline(os.Stdin, os.Stdout)
+	jsonIO, _ := cmd.Flags().GetBool("json-io")
+	inline.SetJSON(jsonIO)
+
+	if value, _ := cmd.Flags().GetString("position"); value != "" {
+		scramble, stop, err := newScrambler(cmd)
+		if err != nil {
+			return err
+		}
+		defer stop()
+		fen, err := startPosition(value, scramble)
+		if err != nil {
+			return err
+		}
+		if err := inline.SetStartPosition(fen); err != nil {
+			return err
+		}
+	}
+
+	aiColor, _ := cmd.Flags().GetString("ai-color")
+	switch aiColor {
+	case "":
+	case "white", "black":
+		generator, err := inlineAI(cmd)
+		if err != nil {
+			return err
+		}
+		color := chess.White
+		if aiColor == "black" {
+			color = chess.Black
+		}
+		inline.SetAI(generator, color)
+	default:
+		return fmt.Errorf("--ai-color must be white or black")
+	}
+	return inline.Run()
+}
+
+// inlineAI returns the AI of a line-based game: the bot of --bot-script, a
+// local GGUF model if --gguf names one, the built-in engine --offline, or
+// else the A2A server of the settings
+func inlineAI(cmd *cobra.Command) (game.MoveGenerator, error) {
+	if bot, ok, err := botScriptAI(cmd); ok {
+		return bot, err
+	}
+	if modelPath, _ := cmd.Flags().GetString("gguf"); modelPath != "" {
+		config := ai_player.DefaultConfig()
+		config.Provider = ai_player.ProviderGGUF
+		config.ModelPath = modelPath
+		applyTraceFlags(cmd, config)
+		localAI, err := game.NewLocalAI(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load local model: %w", err)
+		}
+		return localAI, nil
+	}
+	if offline() {
+		return offlineAI()
+	}
+	settingsPath, _ := cmd.Flags().GetString("settings")
+	settings, err := game.LoadSettings(settingsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	return game.NewAIClient(settings.AIServer), nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// interruptedStatus is the exit status of a command stopped by Ctrl+C, as
+// shells report it
+const interruptedStatus = 130
+
+// interruptible makes the command's context end on Ctrl+C or SIGTERM
+// instead of the process, so a long-running command can cancel its AI
+// requests and save what it has. A second Ctrl+C exits at once. Call the
+// returned function when the command is done.
+func interruptible(cmd *cobra.Command) context.CancelFunc {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	cmd.SetContext(ctx)
+	return stop
+}
+
+// exitWithError reports a command's error and exits, quietly with
+// interruptedStatus when the command was interrupted
+func exitWithError(prefix string, err error) {
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "Interrupted")
+		os.Exit(interruptedStatus)
+	}
+	fmt.Fprintf(os.Stderr, "%s: %v\n", prefix, err)
+	os.Exit(1)
+}
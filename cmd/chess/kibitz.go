@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"chess-tui/ai_player"
+	"chess-tui/game"
+
+	"github.com/spf13/cobra"
+)
+
+// addKibitzFlag adds the flag that has an AI comment on Human vs Human games
+func addKibitzFlag(cmd *cobra.Command) {
+	cmd.Flags().String("kibitz", "", "Have a kibitzer comment on Human vs Human games in a side panel: \"ai\" asks the A2A server at low priority, \"engine\" uses the built-in engine (off by default)")
+}
+
+// kibitzer returns the analyst picked with --kibitz, nil if none. The AI
+// kibitzer's requests wait behind every move request on the server, and end
+// with ctx; offline the engine stands in for it.
+func kibitzer(ctx context.Context, cmd *cobra.Command, settings *game.Settings) (game.Analyst, error) {
+	kind, _ := cmd.Flags().GetString("kibitz")
+	switch kind {
+	case "":
+		return nil, nil
+	case ai_player.ProviderEngine:
+		return game.EngineAnalyst(), nil
+	case "ai":
+		if offline() {
+			return game.EngineAnalyst(), nil
+		}
+		client := game.NewAIClient(settings.AIServer)
+		client.SetContext(ctx)
+		client.SetLowPriority(true)
+		return game.NewSuggestionAnalyst(client), nil
+	}
+	return nil, fmt.Errorf("unknown --kibitz %q: use \"ai\" or \"engine\"", kind)
+}
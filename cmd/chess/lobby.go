@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"chess-tui/fairplay"
+	"chess-tui/game"
+	"chess-tui/gamedb"
+	"chess-tui/lobby"
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var lobbyServerCmd = &cobra.Command{
+	Use:   "lobby-server",
+	Short: "Run a matchmaking lobby for networked games",
+	Long: `Run a lobby where players list the networked games they host and
+find games to join with "chess lobby <url>".
+
+Open games are dropped when their host stops sending heartbeats, and
+unlisted as soon as someone joins.
+
+Hosts report how their games end, and the results, kept in --results,
+make up a leaderboard served as JSON at /leaderboard?period=week|all.
+
+Reports include the moves and how long each took. With an admin token,
+every game is reviewed for fair play: a side whose moves match the
+--engine's too often, or whose move times are suspiciously even, is
+flagged at /admin/fairplay for an operator to look at.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := startLobbyServer(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running lobby: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var lobbyCmd = &cobra.Command{
+	Use:   "lobby <url>",
+	Short: "Find or host a networked game through a lobby",
+	Long: `Open the lobby at url (e.g. http://lobby.example.com:7070) to list
+open games and join one, or host a game for others to find.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := openLobby(cmd, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening lobby: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lobbyServerCmd)
+	rootCmd.AddCommand(lobbyCmd)
+
+	lobbyServerCmd.Flags().IntP("port", "p", 7070, "Port to listen on")
+	lobbyServerCmd.Flags().Duration("ttl", lobby.DefaultTTL, "How long an open game stays listed without a heartbeat from its host")
+	lobbyServerCmd.Flags().String("admin-token", "", "Bearer token that enables the /admin endpoints and fair play reviews (default $BUBBLECHESS_ADMIN_TOKEN)")
+	lobbyServerCmd.Flags().String("engine", "", "UCI engine to review games against, e.g. stockfish (default the built-in engine)")
+	lobbyServerCmd.Flags().String("results", "", "Where the results of games are kept for the leaderboard (default ~/.bubblechess/lobby-results.jsonl)")
+
+	lobbyCmd.Flags().String("name", os.Getenv("USER"), "Name shown to other players and on the leaderboard")
+	lobbyCmd.Flags().String("settings", "", "Display settings file (default ~/.bubblechess/settings.json)")
+	addSSHFlag(lobbyCmd)
+	addPhoneFlag(lobbyCmd)
+}
+
+// startLobbyServer runs the lobby until the process is stopped
+func startLobbyServer(cmd *cobra.Command) error {
+	port, _ := cmd.Flags().GetInt("port")
+	ttl, _ := cmd.Flags().GetDuration("ttl")
+	results, _ := cmd.Flags().GetString("results")
+	if results == "" {
+		results = lobby.DefaultResultsPath()
+	}
+	token, _ := cmd.Flags().GetString("admin-token")
+	if !cmd.Flags().Changed("admin-token") {
+		token = os.Getenv("BUBBLECHESS_ADMIN_TOKEN")
+	}
+	enginePath, _ := cmd.Flags().GetString("engine")
+
+	server := lobby.NewServer(ttl)
+	server.SetResults(gamedb.Open(results))
+	if token != "" {
+		server.SetAdminToken(token)
+		engine := fairplay.BuiltinEngine()
+		if enginePath != "" {
+			uci, err := tournament.NewUCIEngine(enginePath, 0)
+			if err != nil {
+				return fmt.Errorf("failed to start engine: %w", err)
+			}
+			defer uci.Close()
+			engine = uci
+		}
+		server.SetFairPlay(engine)
+	}
+
+	addr := fmt.Sprintf(":%d", port)
+	fmt.Printf("Lobby listening on %s\n", addr)
+	return http.ListenAndServe(addr, server.Handler())
+}
+
+// openLobby runs the lobby screen, and the game picked there, until the player quits
+func openLobby(cmd *cobra.Command, url string) error {
+	name, _ := cmd.Flags().GetString("name")
+	settingsPath, _ := cmd.Flags().GetString("settings")
+	settings, err := game.LoadSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	opts := lowBandwidthOptions(cmd, settings)
+	phoneLayout(cmd, settings)
+	opts = append(opts, screenOptions(settings)...)
+	defer restoreTitle(settings)
+	ctx, cancel := programContext(cmd)
+	defer cancel()
+	screen := game.NewLobby(lobby.NewClient(url), name, settings)
+	screen.SetContext(ctx)
+	opts = append(opts, tea.WithContext(ctx), tea.WithReportFocus())
+	if _, err := runProgram(tea.NewProgram(screen, opts...), cancel); err != nil {
+		return fmt.Errorf("failed to run lobby: %w", err)
+	}
+	return nil
+}
@@ -1,16 +1,30 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"chess-tui/ai_player"
+	"chess-tui/cast"
+	"chess-tui/crash"
 	"chess-tui/game"
+	"chess-tui/gamedb"
+	"chess-tui/plugins"
+	"chess-tui/tournament"
 
 	"log/slog"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
 	"github.com/spf13/cobra"
 )
 
@@ -25,9 +39,20 @@ var rootCmd = &cobra.Command{
 
 The root command starts the TUI version of the game.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		// Play line by line for scripts, with nothing but the game on stdout
+		noTUI, _ := cmd.Flags().GetBool("no-tui")
+		jsonIO, _ := cmd.Flags().GetBool("json-io")
+		if noTUI || jsonIO {
+			if err := playInline(cmd); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		// Start the TUI chess game
 		fmt.Println("Starting TUI Chess Game...")
-		if err := startTUIGame(); err != nil {
+		if err := startTUIGame(cmd); err != nil {
 			fmt.Fprintf(os.Stderr, "Error starting TUI game: %v\n", err)
 			os.Exit(1)
 		}
@@ -44,9 +69,9 @@ agent-to-agent communication. It integrates with Ollama for AI move generation.`
 	Run: func(cmd *cobra.Command, args []string) {
 		// Start the A2A server
 		fmt.Println("Starting A2A Chess Server...")
+		defer interruptible(cmd)()
 		if err := startA2AServer(cmd); err != nil {
-			fmt.Fprintf(os.Stderr, "Error starting A2A server: %v\n", err)
-			os.Exit(1)
+			exitWithError("Error starting A2A server", err)
 		}
 	},
 }
@@ -59,54 +84,716 @@ func init() {
 	serverCmd.Flags().StringP("ollama-url", "u", "http://localhost:11434", "Ollama server URL")
 	serverCmd.Flags().StringP("model", "m", "gpt-oss:20b", "Ollama model to use")
 	serverCmd.Flags().IntP("port", "p", 8080, "Port to listen on")
+	serverCmd.Flags().StringP("config", "c", "", "AI config file (e.g. ai_config.json); flags override its values")
+	serverCmd.Flags().String("provider", "ollama", "AI provider: ollama, openai, anthropic or gguf")
+	serverCmd.Flags().String("api-base-url", "", "Base URL of an OpenAI-compatible or Anthropic API")
+	serverCmd.Flags().String("api-key", "", "API key for the provider (default $OPENAI_API_KEY or $ANTHROPIC_API_KEY)")
+	addTraceFlags(serverCmd)
+	addPprofFlag(serverCmd)
+	serverCmd.Flags().Bool("pull", false, "Pull the model into Ollama if it isn't there yet, instead of failing")
+	serverCmd.Flags().String("admin-token", "", "Bearer token that enables the /admin endpoint (default $BUBBLECHESS_ADMIN_TOKEN)")
+	serverCmd.Flags().Duration("reload-interval", ai_player.DefaultReloadInterval, "How often to check the --config file for changes (0 disables hot reload)")
+	serverCmd.Flags().String("reviewer", "", "A2A endpoint of a second agent that reviews each move and can veto it (e.g. http://localhost:8081/a2a)")
+	serverCmd.Flags().StringSlice("repertoire", nil, "Opening lines the AI plays before asking the model: ECO codes (e.g. C60,B90) or PGN files")
+	serverCmd.Flags().Int("repertoire-moves", 0, "Full moves to follow the --repertoire for (0 follows its lines to the end)")
+	serverCmd.Flags().String("sessions", "", "File to save game sessions to so they survive restarts (default ~/.bubblechess/sessions.json)")
+
+	// Add flags for the TUI
+	rootCmd.PersistentFlags().String("plugins-dir", "", "Directory of executable move providers (default ~/.bubblechess/plugins)")
+	rootCmd.Flags().String("record", "", "Record the TUI session to an asciicast file (e.g. session.cast)")
+	rootCmd.Flags().String("settings", "", "Display settings file (default ~/.bubblechess/settings.json)")
+	rootCmd.Flags().String("opponents", "", "Named AI opponents file (default ~/.bubblechess/opponents.json)")
+	rootCmd.Flags().String("opponent-config", "ai_config.json", "AI config that named opponents build on, if it exists")
+	rootCmd.Flags().String("games", "", "Log of finished games (default ~/.bubblechess/games.jsonl)")
+	rootCmd.Flags().String("collect-data", "", "Append the AI's moves in finished games to this JSONL file as training data (off by default)")
+	rootCmd.Flags().Int("hints", game.DefaultHintBudget, "Hints the two players of a consultation game share")
+	rootCmd.Flags().String("preferences", "", "File remembering the last mode, color, model and palette (default ~/.bubblechess/preferences.json)")
+	rootCmd.Flags().String("profile", game.DefaultProfile, "Profile whose tutorial progress is saved and shown")
+	rootCmd.Flags().String("gguf", "", "Play against a local GGUF model in-process (requires a build with -tags llama); remembered for next time, --gguf \"\" forgets it")
+	rootCmd.Flags().String("transcript", "", "Append every event of the session's games to this JSON Lines file: moves, the AI's thinking, rejected moves, failed requests and timings, for bug reports")
+	rootCmd.Flags().String("bot-script", "", "Play against a bot written in Starlark (e.g. mybot.star) that picks from the legal moves")
+	addTraceFlags(rootCmd)
+	addPprofFlag(rootCmd)
+	addKibitzFlag(rootCmd)
+	rootCmd.Flags().Bool("pull", false, "Pull the named opponents' models into Ollama before the game starts, if they aren't there yet")
+	addSSHFlag(rootCmd)
+	addPhoneFlag(rootCmd)
+	addClockFlags(rootCmd)
+	addHouseRuleFlags(rootCmd)
+	rootCmd.Flags().Bool("bullet", false, "Bullet preset: every AI latency optimization at once, and moves played as soon as the typed text matches only one legal move")
+}
+
+// addSSHFlag adds the flag that turns low-bandwidth mode on or off
+func addSSHFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("ssh", false, "Low-bandwidth rendering for slow SSH links: plain board, drawn only when it changes, at 10 frames a second (default on when run over SSH)")
+}
+
+// lowBandwidthOptions settles whether the TUI runs in low-bandwidth mode,
+// from --ssh, the settings or the environment, and returns the program
+// options the mode needs
+func lowBandwidthOptions(cmd *cobra.Command, settings *game.Settings) []tea.ProgramOption {
+	on := settings.LowBandwidth || game.DetectLowBandwidth()
+	if cmd.Flags().Changed("ssh") {
+		on, _ = cmd.Flags().GetBool("ssh")
+	}
+	settings.LowBandwidth = on
+	if !on {
+		return nil
+	}
+	return []tea.ProgramOption{tea.WithFPS(game.LowBandwidthFPS)}
+}
+
+// addPhoneFlag adds the flag that turns the phone layout on or off
+func addPhoneFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("phone", false, "Phone layout for narrow screens: compact board on top, one status line, panels paged with [ and ] (default on in Termux or under 60 columns)")
 }
 
-func startTUIGame() error {
+// phoneLayout settles whether the TUI uses the phone layout, from --phone,
+// the settings, Termux or the terminal's width
+func phoneLayout(cmd *cobra.Command, settings *game.Settings) {
+	columns, _, err := term.GetSize(os.Stdout.Fd())
+	if err != nil {
+		columns = 0
+	}
+	on := settings.Phone || game.DetectPhone(columns)
+	if cmd.Flags().Changed("phone") {
+		on, _ = cmd.Flags().GetBool("phone")
+	}
+	settings.Phone = on
+}
+
+// addClockFlags adds the flags that put TUI games on the clock
+func addClockFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("move-time", 0, "Play on the clock: time limit per move, e.g. 30s (0 disables)")
+	cmd.Flags().Duration("game-time", 0, "Play on the clock: total thinking time per side, e.g. 10m (0 disables)")
+	cmd.Flags().String("on-timeout", tournament.TimeoutFallback, "When the AI runs out of time: fallback (built-in engine move) or forfeit; players always lose on time")
+	addHandicapFlags(cmd, "white", "White")
+	addHandicapFlags(cmd, "black", "Black")
+}
+
+// applyClockFlags sets the time control of TUI games from the clock flags,
+// leaving the settings' clock alone if none was given
+func applyClockFlags(cmd *cobra.Command, settings *game.Settings) error {
+	changed := false
+	for _, name := range []string{"move-time", "game-time", "on-timeout", "white-move-time", "white-game-time", "black-move-time", "black-game-time"} {
+		changed = changed || cmd.Flags().Changed(name)
+	}
+	if !changed {
+		return nil
+	}
+
+	var control tournament.TimeControl
+	control.PerMove, _ = cmd.Flags().GetDuration("move-time")
+	control.PerGame, _ = cmd.Flags().GetDuration("game-time")
+	control.OnTimeout, _ = cmd.Flags().GetString("on-timeout")
+	switch control.OnTimeout {
+	case tournament.TimeoutFallback, tournament.TimeoutForfeit:
+	default:
+		return fmt.Errorf("--on-timeout must be %s or %s", tournament.TimeoutFallback, tournament.TimeoutForfeit)
+	}
+	if control.PerMove < 0 || control.PerGame < 0 {
+		return fmt.Errorf("time limits cannot be negative")
+	}
+	var err error
+	if control.White, err = handicapLimits(cmd, "white"); err != nil {
+		return err
+	}
+	if control.Black, err = handicapLimits(cmd, "black"); err != nil {
+		return err
+	}
+	settings.Clock = nil
+	if control.Limited() {
+		settings.Clock = &control
+	}
+	return nil
+}
+
+// screenOptions runs the TUI in the alternate screen unless the settings
+// opt out, and saves the terminal title for restoreTitle to put back
+func screenOptions(settings *game.Settings) []tea.ProgramOption {
+	if !settings.NoTitle {
+		// Push the title onto the terminal's title stack
+		fmt.Fprint(os.Stdout, "\x1b[22;0t")
+	}
+	if settings.NoAltScreen {
+		return nil
+	}
+	return []tea.ProgramOption{tea.WithAltScreen()}
+}
+
+// restoreTitle puts back the terminal title screenOptions saved
+func restoreTitle(settings *game.Settings) {
+	if !settings.NoTitle {
+		fmt.Fprint(os.Stdout, "\x1b[23;0t")
+	}
+}
+
+// addTraceFlags adds the flags that dump AI prompts and responses to files
+func addTraceFlags(cmd *cobra.Command) {
+	cmd.Flags().String("trace-ai", "", "Write the exact prompt and raw response of every AI call to timestamped files in this directory")
+	cmd.Flags().StringArray("trace-redact", nil, "Regular expression to redact from AI traces (repeatable); API keys are always redacted")
+}
+
+// applyTraceFlags turns on AI tracing in config when --trace-ai is given
+func applyTraceFlags(cmd *cobra.Command, config *ai_player.Config) {
+	if dir, _ := cmd.Flags().GetString("trace-ai"); dir != "" {
+		config.TraceDir = dir
+	}
+	if patterns, _ := cmd.Flags().GetStringArray("trace-redact"); len(patterns) > 0 {
+		config.TraceRedact = append(config.TraceRedact, patterns...)
+	}
+}
+
+func startTUIGame(cmd *cobra.Command) error {
 	// Start the TUI chess game
 	fmt.Println("Starting TUI Chess Game...")
+	if err := startPprof(cmd); err != nil {
+		return err
+	}
+
+	var opts []tea.ProgramOption
 
-	p := tea.NewProgram(game.NewMenu())
-	if _, err := p.Run(); err != nil {
+	// Optionally tee the terminal output into an asciicast recording
+	recordPath, _ := cmd.Flags().GetString("record")
+	if recordPath != "" {
+		width, height, err := term.GetSize(os.Stdout.Fd())
+		if err != nil {
+			width, height = 80, 24
+		}
+		recorder, err := cast.NewRecorder(recordPath, os.Stdout, width, height)
+		if err != nil {
+			return fmt.Errorf("failed to start recording: %w", err)
+		}
+		defer func() {
+			recorder.Close()
+			fmt.Printf("Session recorded to %s\n", recordPath)
+		}()
+		opts = append(opts, tea.WithOutput(recorder))
+	}
+
+	// Load display settings
+	settingsPath, _ := cmd.Flags().GetString("settings")
+	settings, err := game.LoadSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	// Preselect what was chosen last time
+	prefsPath, _ := cmd.Flags().GetString("preferences")
+	prefs, err := game.LoadPreferences(prefsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load preferences: %w", err)
+	}
+	if prefs.Palette != "" {
+		settings.Palette = prefs.Palette
+	}
+	if err := applyClockFlags(cmd, settings); err != nil {
+		return err
+	}
+	if err := applyHouseRuleFlags(cmd, settings); err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("bullet") {
+		settings.Bullet, _ = cmd.Flags().GetBool("bullet")
+	}
+	if cmd.Flags().Changed("transcript") {
+		settings.Transcript, _ = cmd.Flags().GetString("transcript")
+	}
+	if settings.Transcript == "" {
+		// Keep this session's transcript for chess report-bug
+		settings.Transcript = game.DefaultTranscriptPath()
+		os.Remove(settings.Transcript)
+	}
+	opts = append(opts, lowBandwidthOptions(cmd, settings)...)
+	phoneLayout(cmd, settings)
+	opts = append(opts, screenOptions(settings)...)
+
+	menu := game.NewMenuWithSettings(settings)
+
+	// Record finished games, and list named opponents with the record against each
+	gamesPath, _ := cmd.Flags().GetString("games")
+	db := gamedb.Open(gamesPath)
+	menu.SetGameDB(db)
+	if !settings.Archive.Disabled {
+		menu.SetArchive(gamedb.OpenArchive(settings.Archive))
+	}
+	if datasetPath, _ := cmd.Flags().GetString("collect-data"); datasetPath != "" {
+		menu.SetDataset(gamedb.OpenDataset(datasetPath))
+	}
+	hints, _ := cmd.Flags().GetInt("hints")
+	menu.SetHintBudget(hints)
+	menu.SetDailyPath(game.DefaultDailyPath())
+	profile, _ := cmd.Flags().GetString("profile")
+	menu.SetTutorial(game.DefaultTutorialPath(), profile)
+	if err := setupOpponents(cmd, menu, db); err != nil {
+		return err
+	}
+	menu.SetPreferences(prefs)
+	scramble, stopScrambler, err := newScrambler(cmd)
+	if err != nil {
+		return err
+	}
+	defer stopScrambler()
+	menu.SetScrambler(scramble)
+	if value, _ := cmd.Flags().GetString("position"); value != "" {
+		fen, err := startPosition(value, scramble)
+		if err != nil {
+			return err
+		}
+		menu.SetStartPosition(fen)
+	}
+
+	// Show any bench Elo estimates next to the game modes
+	if ratings, err := tournament.LoadRatings(""); err == nil {
+		menu.SetRatings(ratings)
+	}
+	// and the Glicko-2 ratings from the game log next to the opponents,
+	// falling back to the last saved ones if the log can't be read
+	if ratings, err := updateGlicko(db); err == nil {
+		menu.SetGlicko(ratings)
+	} else if ratings, err := tournament.LoadGlicko(""); err == nil {
+		menu.SetGlicko(ratings)
+	}
+
+	// Offline the built-in engine plays in-process instead of the A2A server
+	if offline() {
+		engine, err := offlineAI()
+		if err != nil {
+			return err
+		}
+		menu.SetMoveGenerator(engine)
+	}
+
+	// Optionally run the AI in-process from a GGUF model instead of the A2A
+	// server, defaulting to the model used last time
+	modelPath, _ := cmd.Flags().GetString("gguf")
+	remembered := !cmd.Flags().Changed("gguf")
+	if remembered {
+		modelPath = prefs.Model
+	}
+	prefs.Model = ""
+	if modelPath != "" {
+		config := ai_player.DefaultConfig()
+		config.Provider = ai_player.ProviderGGUF
+		config.ModelPath = modelPath
+		applyTraceFlags(cmd, config)
+
+		fmt.Printf("Loading local model %s...\n", modelPath)
+		localAI, err := game.NewLocalAI(config)
+		switch {
+		case err == nil:
+			menu.SetMoveGenerator(localAI)
+			prefs.Model = modelPath
+		case remembered:
+			// Don't let a model that has since gone away block the game
+			fmt.Printf("Couldn't load last used model, using the A2A server instead: %v\n", err)
+		default:
+			return fmt.Errorf("failed to load local model: %w", err)
+		}
+	}
+
+	// A bot script takes the AI's place
+	if bot, ok, err := botScriptAI(cmd); ok {
+		if err != nil {
+			return err
+		}
+		menu.SetMoveGenerator(bot)
+	}
+
+	// Everything the games start ends with the program
+	ctx, cancel := programContext(cmd)
+	defer cancel()
+	menu.SetContext(ctx)
+	analyst, err := kibitzer(ctx, cmd, settings)
+	if err != nil {
+		return err
+	}
+	menu.SetKibitzer(analyst)
+
+	// Guard the program so a panic leaves a bug-report bundle behind
+	guard := crash.NewGuard(menu)
+	p := tea.NewProgram(guard, append(opts, tea.WithContext(ctx), tea.WithReportFocus())...)
+	_, err = runProgram(p, cancel)
+	restoreTitle(settings)
+	if report := guard.Report(); report != nil {
+		writeCrashBundle(report)
+		os.Exit(2)
+	}
+	if err != nil {
 		fmt.Printf("Error running game: %v\n", err)
 		os.Exit(1)
 	}
 
+	prefs.Palette = settings.Palette
+	if err := game.SavePreferences(prefs, prefsPath); err != nil {
+		slog.Warn("Failed to save preferences", "error", err)
+	}
+
+	return nil
+}
+
+// programContext returns the context a TUI runs under, within the
+// command's. It ends when the process is asked to terminate, or with cancel
+// once the TUI has exited.
+func programContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(cmd.Context(), syscall.SIGTERM, syscall.SIGHUP)
+}
+
+// runProgram runs a TUI, then cancels its context so AI requests, network
+// connections and timers it left behind stop. Being terminated is a clean exit.
+func runProgram(p *tea.Program, cancel context.CancelFunc) (tea.Model, error) {
+	model, err := p.Run()
+	cancel()
+	if errors.Is(err, tea.ErrProgramKilled) {
+		err = nil
+	}
+	return model, err
+}
+
+// setupOpponents lists the named opponents in the menu. Each plays
+// in-process with the opponent config, adjusted to its model and prompt.
+func setupOpponents(cmd *cobra.Command, menu *game.Menu, db *gamedb.DB) error {
+	opponentsPath, _ := cmd.Flags().GetString("opponents")
+	opponents, err := ai_player.LoadOpponents(opponentsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load opponents: %w", err)
+	}
+	if len(opponents) == 0 {
+		return nil
+	}
+
+	base := ai_player.DefaultConfig()
+	if configPath, _ := cmd.Flags().GetString("opponent-config"); configPath != "" {
+		if _, err := os.Stat(configPath); err == nil {
+			if base, err = ai_player.ReadConfig(configPath); err != nil {
+				return fmt.Errorf("failed to load opponent config: %w", err)
+			}
+		}
+	}
+	applyTraceFlags(cmd, base)
+	offlineConfig(base)
+	if pull, _ := cmd.Flags().GetBool("pull"); pull && !offline() {
+		if err := pullOpponentModels(opponents, base); err != nil {
+			return err
+		}
+	}
+
+	games, err := db.Games()
+	if err != nil {
+		slog.Warn("Failed to read game log", "error", err)
+	}
+	menu.SetOpponents(opponents, gamedb.HeadToHead(games), func(opponent ai_player.Opponent) (game.MoveGenerator, error) {
+		config, err := opponent.Config(base)
+		if err != nil {
+			return nil, err
+		}
+		return game.NewLocalAI(config)
+	})
+	return nil
+}
+
+// pullOpponentModels pulls the Ollama models of the named opponents that
+// Ollama doesn't have yet
+func pullOpponentModels(opponents []ai_player.Opponent, base *ai_player.Config) error {
+	checked := make(map[string]bool)
+	for _, opponent := range opponents {
+		config, err := opponent.Config(base)
+		if err != nil {
+			return err
+		}
+		if config.Provider != "" && config.Provider != ai_player.ProviderOllama || checked[config.Model] {
+			continue
+		}
+		checked[config.Model] = true
+		player := ai_player.NewAIPlayer(config.OllamaURL, config.Model, "", nil)
+		pulled, err := player.EnsureModel(context.Background(), printPullProgress(config.OllamaURL, config.Model))
+		if err != nil {
+			return err
+		}
+		if pulled {
+			fmt.Printf("Pulled %s\n", config.Model)
+		}
+	}
 	return nil
 }
 
+// botScriptAI returns the bot scripted in the --bot-script file; ok is false
+// when none is given
+func botScriptAI(cmd *cobra.Command) (bot game.MoveGenerator, ok bool, err error) {
+	path, _ := cmd.Flags().GetString("bot-script")
+	if path == "" {
+		return nil, false, nil
+	}
+	config := ai_player.DefaultConfig()
+	config.Provider = ai_player.ProviderScript
+	config.ScriptPath = path
+	applyTraceFlags(cmd, config)
+	localAI, err := game.NewLocalAI(config)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to load bot script: %w", err)
+	}
+	return localAI, true, nil
+}
+
+// writeCrashBundle writes the crash report bundle and tells the user where it is
+func writeCrashBundle(report *crash.Report) {
+	config, _ := os.ReadFile("ai_config.json")
+	bundle := &crash.Bundle{
+		Report: report,
+		Logs:   recentLogs.Lines(),
+		Config: config,
+	}
+
+	dir, err := bundle.Write()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "The game crashed and the bug report could not be written: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\nThe game crashed: %v\n", report.Value)
+	fmt.Fprintf(os.Stderr, "A bug report bundle was written to %s\n", dir)
+	fmt.Fprintf(os.Stderr, "Please attach it when filing an issue.\n")
+}
+
+// serverShutdownTimeout is how long the A2A server waits for requests in
+// flight when it is stopped
+const serverShutdownTimeout = 10 * time.Second
+
 func startA2AServer(cmd *cobra.Command) error {
 	// Get flags from the command that was executed
-	ollamaURL, _ := cmd.Flags().GetString("ollama-url")
-	model, _ := cmd.Flags().GetString("model")
 	port, _ := cmd.Flags().GetInt("port")
+	config, err := serverConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if err := startPprof(cmd); err != nil {
+		return err
+	}
 
-	slog.Debug("🔌 Starting A2A server", "ollama_url", ollamaURL, "model", model, "port", port)
+	slog.Debug("🔌 Starting A2A server", "provider", config.Provider, "ollama_url", config.OllamaURL, "model", config.Model, "port", port)
 
 	fmt.Printf("Starting A2A server with:\n")
-	fmt.Printf("  Ollama URL: %s\n", ollamaURL)
-	fmt.Printf("  Model: %s\n", model)
+	fmt.Printf("  Provider: %s\n", config.Provider)
+	switch config.Provider {
+	case ai_player.ProviderOpenAI, ai_player.ProviderAnthropic:
+		if config.APIBaseURL != "" {
+			fmt.Printf("  API URL: %s\n", config.APIBaseURL)
+		}
+	default:
+		fmt.Printf("  Ollama URL: %s\n", config.OllamaURL)
+	}
+	fmt.Printf("  Model: %s\n", config.Model)
 	fmt.Printf("  Port: %d\n", port)
+	if config.ReviewerURL != "" {
+		fmt.Printf("  Reviewer: %s\n", config.ReviewerURL)
+	}
+	if config.AdminToken != "" {
+		fmt.Printf("  Admin endpoint: http://localhost:%d/admin\n", port)
+	}
 
 	// Start the actual A2A server
 	fmt.Println("Starting A2A server...")
 
+	server, err := ai_player.NewJSONRPCA2AServerWithConfig(config, port, ai_player.NewA2ALogger())
+	var missing *ai_player.ModelNotFoundError
+	// With --pull, pull each missing model (the spare's too) and try again
+	pull, _ := cmd.Flags().GetBool("pull")
+	pulled := make(map[string]bool)
+	for pull && errors.As(err, &missing) && !pulled[missing.Model] {
+		pulled[missing.Model] = true
+		if err := pullModel(missing.OllamaURL, missing.Model); err != nil {
+			return err
+		}
+		server, err = ai_player.NewJSONRPCA2AServerWithConfig(config, port, ai_player.NewA2ALogger())
+	}
+	chosen := ""
+	if errors.As(err, &missing) {
+		if model, ok := chooseModel(missing); ok {
+			chosen = model
+			config.Model = model
+			server, err = ai_player.NewJSONRPCA2AServerWithConfig(config, port, ai_player.NewA2ALogger())
+		}
+	}
+	if err != nil {
+		slog.Error("❌ Failed to start A2A server", "error", err)
+		return fmt.Errorf("failed to start A2A server: %w", err)
+	}
+
+	// Apply edits to the config file without a restart, keeping flag overrides
+	configPath, _ := cmd.Flags().GetString("config")
+	interval, _ := cmd.Flags().GetDuration("reload-interval")
+	ctx := cmd.Context()
+	if configPath != "" && interval > 0 {
+		fmt.Printf("  Watching %s for changes\n", configPath)
+		go server.WatchConfig(ctx, configPath, interval, func(config *ai_player.Config) {
+			applyServerFlags(cmd, config)
+			// Keep the model switched to while the file names the missing one
+			if chosen != "" && config.Model == missing.Model {
+				config.Model = chosen
+			}
+		})
+	}
+
+	// On Ctrl+C, finish the requests in flight before exiting
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		fmt.Println("Shutting down A2A server...")
+		shutdown, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+		defer cancel()
+		if err := server.Stop(shutdown); err != nil {
+			slog.Warn("Dropped requests still in flight at shutdown", "error", err)
+		}
+	}()
+
 	// Start the JSON-RPC A2A server
 	// This will block and keep the server running
-	if err := ai_player.StartJSONRPCA2AServer(ollamaURL, model, port); err != nil {
+	if err := server.Start(); !errors.Is(err, http.ErrServerClosed) {
 		slog.Error("❌ Failed to start A2A server", "error", err)
 		return fmt.Errorf("failed to start A2A server: %w", err)
 	}
+	<-stopped
+
+	slog.Debug("✅ A2A server stopped")
+	return nil
+}
+
+// chooseModel offers to switch to one of the models Ollama has when the
+// configured one isn't pulled. Without a terminal to ask on, or with no
+// models to offer, it says how to pull the missing one.
+func chooseModel(missing *ai_player.ModelNotFoundError) (string, bool) {
+	fmt.Printf("\nModel %q is not pulled in Ollama.\n", missing.Model)
+	if len(missing.Available) == 0 || !term.IsTerminal(os.Stdin.Fd()) {
+		fmt.Printf("Pull it with: ollama pull %s\n", missing.Model)
+		if len(missing.Available) > 0 {
+			fmt.Printf("Or start with --model set to one of: %s\n", strings.Join(missing.Available, ", "))
+		}
+		return "", false
+	}
 
-	slog.Debug("✅ A2A server started successfully")
+	fmt.Println("Available models:")
+	for i, model := range missing.Available {
+		fmt.Printf("  %d. %s\n", i+1, model)
+	}
+	fmt.Printf("Switch to [1-%d], or press Enter to quit and run \"ollama pull %s\": ", len(missing.Available), missing.Model)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	choice, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || choice < 1 || choice > len(missing.Available) {
+		return "", false
+	}
+	model := missing.Available[choice-1]
+	fmt.Printf("Using %s; pass --model %s to skip this next time\n\n", model, model)
+	return model, true
+}
 
-	// Note: The server is now running and this function won't return
-	// until the server is stopped (e.g., by Ctrl+C)
+// pullModel has the Ollama server at url pull model, printing its progress
+func pullModel(url, model string) error {
+	player := ai_player.NewAIPlayer(url, model, "", nil)
+	if err := player.PullModel(context.Background(), model, printPullProgress(url, model)); err != nil {
+		return err
+	}
+	fmt.Printf("Pulled %s\n", model)
 	return nil
 }
 
+// printPullProgress returns a progress callback that announces the pull of
+// model, then prints each new step of it, and a download's progress every
+// tenth of the way
+func printPullProgress(url, model string) func(ai_player.PullProgress) {
+	status, shown := "", -1
+	return func(step ai_player.PullProgress) {
+		if status == "" {
+			fmt.Printf("Pulling %s into Ollama at %s...\n", model, url)
+		}
+		percent := step.Percent()
+		if step.Status == status && (percent < 0 || percent/10 == shown/10) {
+			return
+		}
+		status, shown = step.Status, percent
+		if percent < 0 {
+			fmt.Printf("  %s\n", step.Status)
+			return
+		}
+		fmt.Printf("  %s: %d%% of %.1f MB\n", step.Status, percent, float64(step.Total)/1e6)
+	}
+}
+
+// serverConfig builds the AI configuration from the config file, if given,
+// with any explicitly set flags taking precedence
+func serverConfig(cmd *cobra.Command) (*ai_player.Config, error) {
+	config := ai_player.DefaultConfig()
+	config.Model = "gpt-oss:20b"
+
+	if path, _ := cmd.Flags().GetString("config"); path != "" {
+		loaded, err := ai_player.LoadConfig(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AI config: %w", err)
+		}
+		config = loaded
+	}
+
+	applyServerFlags(cmd, config)
+	if err := config.ValidateConfig(); err != nil {
+		return nil, fmt.Errorf("invalid AI config: %w", err)
+	}
+	return config, nil
+}
+
+// applyServerFlags overrides config with the server flags that were set
+func applyServerFlags(cmd *cobra.Command, config *ai_player.Config) {
+	flags := cmd.Flags()
+	if flags.Changed("ollama-url") || config.OllamaURL == "" {
+		config.OllamaURL, _ = flags.GetString("ollama-url")
+	}
+	if flags.Changed("model") || config.Model == "" {
+		config.Model, _ = flags.GetString("model")
+	}
+	if flags.Changed("provider") || config.Provider == "" {
+		config.Provider, _ = flags.GetString("provider")
+	}
+	if flags.Changed("api-base-url") {
+		config.APIBaseURL, _ = flags.GetString("api-base-url")
+	}
+	if flags.Changed("api-key") {
+		config.APIKey, _ = flags.GetString("api-key")
+	}
+	if flags.Changed("admin-token") {
+		config.AdminToken, _ = flags.GetString("admin-token")
+	} else if token := os.Getenv("BUBBLECHESS_ADMIN_TOKEN"); token != "" && config.AdminToken == "" {
+		config.AdminToken = token
+	}
+	if flags.Changed("sessions") {
+		config.SessionsFile, _ = flags.GetString("sessions")
+	}
+	if flags.Changed("reviewer") {
+		config.ReviewerURL, _ = flags.GetString("reviewer")
+	}
+	if flags.Changed("repertoire") {
+		config.Repertoire = repertoireFlag(cmd)
+	}
+	applyTraceFlags(cmd, config)
+}
+
+// repertoireFlag builds the repertoire given with --repertoire, whose
+// values are ECO codes or PGN files
+func repertoireFlag(cmd *cobra.Command) *ai_player.Repertoire {
+	values, _ := cmd.Flags().GetStringSlice("repertoire")
+	repertoire := &ai_player.Repertoire{}
+	repertoire.Moves, _ = cmd.Flags().GetInt("repertoire-moves")
+	for _, value := range values {
+		if strings.HasSuffix(strings.ToLower(value), ".pgn") {
+			repertoire.PGNFiles = append(repertoire.PGNFiles, value)
+		} else {
+			repertoire.ECO = append(repertoire.ECO, value)
+		}
+	}
+	return repertoire
+}
+
 func main() {
 	// Configure slog level based on environment variables
 	configureLogging()
+	cobra.OnInitialize(loadPlugins)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -114,6 +801,9 @@ func main() {
 	}
 }
 
+// recentLogs keeps the latest log records for crash reports
+var recentLogs *crash.LogBuffer
+
 // configureLogging sets up the slog level based on environment variables
 func configureLogging() {
 	logLevel := strings.ToUpper(os.Getenv("LOG_LEVEL"))
@@ -140,11 +830,27 @@ func configureLogging() {
 		level = slog.LevelInfo
 	}
 
-	// Create a new handler with the configured level
+	// Create a new handler with the configured level, keeping recent
+	// records in memory for crash reports
 	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: level,
 	})
-	slog.SetDefault(slog.New(handler))
+	recentLogs = crash.NewLogBuffer(handler, 200)
+	slog.SetDefault(slog.New(recentLogs))
 
 	slog.Debug("Logging configured", "level", logLevel)
 }
+
+// loadPlugins registers the executable move providers in the plugins
+// directory, alongside the extensions compiled in
+func loadPlugins() {
+	dir, _ := rootCmd.PersistentFlags().GetString("plugins-dir")
+	loaded, err := plugins.LoadDir(dir)
+	if err != nil {
+		slog.Warn("Failed to load plugins", "error", err)
+		return
+	}
+	if len(loaded) > 0 {
+		slog.Debug("Loaded plugins", "move_providers", loaded)
+	}
+}
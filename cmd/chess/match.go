@@ -0,0 +1,439 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"chess-tui/ai_player"
+	"chess-tui/game"
+	"chess-tui/gamedb"
+	"chess-tui/share"
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var matchCmd = &cobra.Command{
+	Use:   "match",
+	Short: "Play AI vs AI games between two configurations",
+	Long: `Play a match between two AI configurations, alternating colors.
+
+Games are refereed locally and adjudicated so they can't run forever: drawn
+after a long stretch without progress or when both sides report a level
+position, and resigned when one side's eval stays hopeless.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		defer interruptible(cmd)()
+		run := func(cmd *cobra.Command) error { return runMatch(cmd, cmd.OutOrStdout(), nil) }
+		if dashboard, _ := cmd.Flags().GetBool("dashboard"); dashboard {
+			run = watchMatch
+		}
+		if err := run(cmd); err != nil {
+			exitWithError("Error running match", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(matchCmd)
+
+	matchCmd.Flags().String("white", "ai_config.json", "AI config for the first player")
+	matchCmd.Flags().String("black", "ai_config.json", "AI config for the second player")
+	matchCmd.Flags().IntP("games", "g", 2, "Number of games; colors alternate")
+	matchCmd.Flags().String("pgn", "", "Append the games to this PGN file")
+	matchCmd.Flags().StringSlice("ollama-hosts", nil, "Spread the games across these Ollama servers (default: ollama_hosts from the first config)")
+	matchCmd.Flags().Int("per-host", 1, "Games played at once on each Ollama server")
+	matchCmd.Flags().Bool("times", false, "Print a chart of the time each move took after every game")
+	matchCmd.Flags().String("report", "", "Write an HTML report of the match (standings, crosstable, time charts and each game's PGN) to this directory")
+	matchCmd.Flags().String("game-log", "", "Record the games in this game log, where they count towards the Glicko-2 ratings (default ~/.bubblechess/games.jsonl)")
+	matchCmd.Flags().Bool("no-log", false, "Don't record the games in the game log")
+	matchCmd.Flags().Bool("dashboard", false, "Follow the match on a live dashboard: a mini board for each game in progress, the standings and the queue")
+	matchCmd.Flags().Bool("share", false, "Upload the games' PGN to the paste service or gist set under \"share\" in the settings file and print the link")
+	addRefereeFlags(matchCmd)
+	addHouseRuleFlags(matchCmd)
+	addHandicapFlags(matchCmd, "white", "the --white config's player, whichever color it plays")
+	addHandicapFlags(matchCmd, "black", "the --black config's player, whichever color it plays")
+	addTraceFlags(matchCmd)
+}
+
+// addRefereeFlags adds the adjudication and time limit flags to a command
+func addRefereeFlags(cmd *cobra.Command) {
+	defaults := tournament.DefaultAdjudication()
+	cmd.Flags().Int("no-progress-moves", defaults.NoProgressMoves, "Draw after this many moves without a capture or pawn move (0 disables)")
+	cmd.Flags().Int("draw-after-move", defaults.DrawAfterMove, "Earliest move for eval-based draws")
+	cmd.Flags().Int("draw-eval", defaults.DrawEval, "Evals within this many centipawns of zero count as level")
+	cmd.Flags().Int("draw-eval-moves", defaults.DrawEvalMoves, "Draw when both sides are level for this many moves (0 disables)")
+	cmd.Flags().Int("resign-eval", defaults.ResignEval, "Evals this many centipawns down count as hopeless")
+	cmd.Flags().Int("resign-moves", defaults.ResignMoves, "Resign after this many hopeless moves in a row (0 disables)")
+	cmd.Flags().Duration("move-time", 0, "Time limit per move, e.g. 30s (0 disables)")
+	cmd.Flags().Duration("game-time", 0, "Total thinking time per side per game, e.g. 10m (0 disables)")
+	cmd.Flags().String("on-timeout", tournament.TimeoutFallback, "When a limit is exceeded: fallback (built-in engine moves) or forfeit")
+	cmd.Flags().String("on-suspend", tournament.SuspendForgive, "Time the machine spends asleep mid-move: forgive (not counted) or count")
+}
+
+// matchAdjudication reads the adjudication rules from the flags
+func matchAdjudication(cmd *cobra.Command) tournament.Adjudication {
+	var rules tournament.Adjudication
+	rules.NoProgressMoves, _ = cmd.Flags().GetInt("no-progress-moves")
+	rules.DrawAfterMove, _ = cmd.Flags().GetInt("draw-after-move")
+	rules.DrawEval, _ = cmd.Flags().GetInt("draw-eval")
+	rules.DrawEvalMoves, _ = cmd.Flags().GetInt("draw-eval-moves")
+	rules.ResignEval, _ = cmd.Flags().GetInt("resign-eval")
+	rules.ResignMoves, _ = cmd.Flags().GetInt("resign-moves")
+	return rules
+}
+
+// matchTimeControl reads the time limits from the flags
+func matchTimeControl(cmd *cobra.Command) (tournament.TimeControl, error) {
+	var control tournament.TimeControl
+	control.PerMove, _ = cmd.Flags().GetDuration("move-time")
+	control.PerGame, _ = cmd.Flags().GetDuration("game-time")
+	control.OnTimeout, _ = cmd.Flags().GetString("on-timeout")
+	control.OnSuspend, _ = cmd.Flags().GetString("on-suspend")
+
+	switch control.OnTimeout {
+	case tournament.TimeoutFallback, tournament.TimeoutForfeit:
+	default:
+		return control, fmt.Errorf("--on-timeout must be %s or %s", tournament.TimeoutFallback, tournament.TimeoutForfeit)
+	}
+	switch control.OnSuspend {
+	case tournament.SuspendForgive, tournament.SuspendCount:
+	default:
+		return control, fmt.Errorf("--on-suspend must be %s or %s", tournament.SuspendForgive, tournament.SuspendCount)
+	}
+	if control.PerMove < 0 || control.PerGame < 0 {
+		return control, fmt.Errorf("time limits cannot be negative")
+	}
+	return control, nil
+}
+
+// addHandicapFlags adds --<player>-move-time and --<player>-game-time, which
+// give one player time limits of its own in place of --move-time and
+// --game-time
+func addHandicapFlags(cmd *cobra.Command, player, description string) {
+	cmd.Flags().Duration(player+"-move-time", 0, "Handicap: time limit per move for "+description)
+	cmd.Flags().Duration(player+"-game-time", 0, "Handicap: total thinking time per game for "+description)
+}
+
+// handicapLimits reads a player's handicap flags. It returns nil if neither
+// was given, leaving the player the match's limits.
+func handicapLimits(cmd *cobra.Command, player string) (*tournament.Limits, error) {
+	if !cmd.Flags().Changed(player+"-move-time") && !cmd.Flags().Changed(player+"-game-time") {
+		return nil, nil
+	}
+	var limits tournament.Limits
+	limits.PerMove, _ = cmd.Flags().GetDuration(player + "-move-time")
+	limits.PerGame, _ = cmd.Flags().GetDuration(player + "-game-time")
+	if limits.PerMove < 0 || limits.PerGame < 0 {
+		return nil, fmt.Errorf("time limits cannot be negative")
+	}
+	return &limits, nil
+}
+
+// loadEntrant creates a match entrant from an AI config file, applying the
+// command's tracing flags
+func loadEntrant(cmd *cobra.Command, path string) (tournament.Entrant, error) {
+	return loadEntrantOn(cmd, path, "")
+}
+
+// loadEntrantOn creates a match entrant from an AI config file whose Ollama
+// calls go to host, or to the config's own ollama_url if host is empty.
+// Its requests end with the command's context.
+func loadEntrantOn(cmd *cobra.Command, path, host string) (tournament.Entrant, error) {
+	config, err := ai_player.LoadConfig(path)
+	if err != nil {
+		return tournament.Entrant{}, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	applyTraceFlags(cmd, config)
+	if host != "" {
+		config.OllamaURL = host
+	}
+	player, err := ai_player.NewAIPlayerFromConfig(config, "white", nil)
+	if err != nil {
+		return tournament.Entrant{}, fmt.Errorf("failed to create player from %s: %w", path, err)
+	}
+	player.Context = cmd.Context()
+	return tournament.Entrant{
+		Name:   entrantName(player, config),
+		Player: player,
+	}, nil
+}
+
+// entrantName identifies a player by provider and model, plus a short hash
+// of any custom prompts so differently prompted configs are told apart
+func entrantName(player *ai_player.AIPlayer, config *ai_player.Config) string {
+	name := player.ProviderName() + ":" + config.Model
+	if len(config.CustomPrompts) == 0 {
+		return name
+	}
+
+	keys := make([]string, 0, len(config.CustomPrompts))
+	for key := range config.CustomPrompts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", key, config.CustomPrompts[key])
+	}
+	return fmt.Sprintf("%s #%x", name, hash.Sum(nil)[:4])
+}
+
+// runMatch plays the match, printing its progress to out. When send is set,
+// the games are also reported to the dashboard through it as they go.
+func runMatch(cmd *cobra.Command, out io.Writer, send func(tea.Msg)) error {
+	whitePath, _ := cmd.Flags().GetString("white")
+	blackPath, _ := cmd.Flags().GetString("black")
+	games, _ := cmd.Flags().GetInt("games")
+	pgnPath, _ := cmd.Flags().GetString("pgn")
+	showTimes, _ := cmd.Flags().GetBool("times")
+	var db *gamedb.DB
+	if noLog, _ := cmd.Flags().GetBool("no-log"); !noLog {
+		logPath, _ := cmd.Flags().GetString("game-log")
+		db = gamedb.Open(logPath)
+	}
+	timeControl, err := matchTimeControl(cmd)
+	if err != nil {
+		return err
+	}
+
+	first, err := loadEntrant(cmd, whitePath)
+	if err != nil {
+		return err
+	}
+	second, err := loadEntrant(cmd, blackPath)
+	if err != nil {
+		return err
+	}
+	if first.Name == second.Name {
+		first.Name += " (1)"
+		second.Name += " (2)"
+	}
+	if first.Limits, err = handicapLimits(cmd, "white"); err != nil {
+		return err
+	}
+	if second.Limits, err = handicapLimits(cmd, "black"); err != nil {
+		return err
+	}
+
+	match := tournament.NewMatch(matchAdjudication(cmd))
+	match.TimeControl = timeControl
+	match.Context = cmd.Context()
+	if send != nil {
+		match.OnMove = func(board tournament.Board) { send(game.DashboardMoveMsg(board)) }
+	}
+	if match.HouseRules, err = houseRules(cmd); err != nil {
+		return err
+	}
+	scores := map[string]float64{first.Name: 0, second.Name: 0}
+	pgns := make([]string, games)
+	results := make([]*tournament.GameResult, games)
+
+	// record prints and scores a finished game
+	record := func(i int, host string, result *tournament.GameResult) error {
+		if send != nil {
+			send(game.DashboardResultMsg{Round: i + 1, Result: result})
+		}
+		white, black := result.White, result.Black
+		if host != "" {
+			fmt.Fprintf(out, "Game %d on %s: %s vs %s\n", i+1, host, white, black)
+		}
+		fmt.Fprintf(out, "  %s in %d plies (%s)\n", result.Outcome, len(result.Moves), result.Reason)
+		for _, violation := range result.Violations {
+			fmt.Fprintf(out, "  ⏱ move %d: %s (%s)\n", violation.Ply/2+1, violation, violation.Player)
+		}
+		if showTimes {
+			for _, line := range strings.Split(result.MoveTimes.Chart(result.TimeControl), "\n") {
+				fmt.Fprintln(out, "  "+line)
+			}
+		}
+
+		scores[white] += result.ScoreFor(white)
+		scores[black] += result.ScoreFor(black)
+		pgns[i] = result.PGN
+		results[i] = result
+		if db != nil {
+			if err := db.Add(result.Record()); err != nil {
+				return err
+			}
+		}
+		if pgnPath != "" {
+			return appendPGN(pgnPath, result.PGN)
+		}
+		return nil
+	}
+
+	// pairing returns the entrants for game i; colors alternate
+	pairing := func(i int) (white, black tournament.Entrant) {
+		if i%2 == 1 {
+			return second, first
+		}
+		return first, second
+	}
+
+	if hosts := matchHosts(cmd, whitePath); len(hosts) > 0 {
+		perHost, _ := cmd.Flags().GetInt("per-host")
+		pool := tournament.NewHostPool(hosts)
+		paths := map[string]string{first.Name: whitePath, second.Name: blackPath}
+		fmt.Fprintf(out, "Playing %d games across %d Ollama hosts\n", games, len(hosts))
+
+		var mu sync.Mutex
+		var firstErr error
+		match.PlayOnPool(pool, games, perHost, func(i int, host string) (tournament.Entrant, tournament.Entrant, error) {
+			// Each game gets its own players, talking to its host
+			white, black := pairing(i)
+			whiteOn, err := loadEntrantOn(cmd, paths[white.Name], host)
+			if err != nil {
+				return whiteOn, whiteOn, err
+			}
+			blackOn, err := loadEntrantOn(cmd, paths[black.Name], host)
+			whiteOn.Name, blackOn.Name = white.Name, black.Name
+			whiteOn.Limits, blackOn.Limits = white.Limits, black.Limits
+			return whiteOn, blackOn, err
+		}, func(finished tournament.HostGame) {
+			mu.Lock()
+			defer mu.Unlock()
+			err := finished.Err
+			if errors.Is(err, tournament.ErrInterrupted) {
+				return
+			}
+			if err != nil && send != nil {
+				send(game.DashboardResultMsg{Round: finished.Index + 1, Err: err})
+			}
+			if err == nil {
+				err = record(finished.Index, finished.Host, finished.Result)
+			}
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("game %d failed: %w", finished.Index+1, err)
+			}
+		})
+		fmt.Fprintf(out, "\nHosts:\n%s", pool.Report())
+		if firstErr != nil {
+			return firstErr
+		}
+	} else {
+		for i := 0; i < games; i++ {
+			white, black := pairing(i)
+			fmt.Fprintf(out, "Game %d: %s vs %s\n", i+1, white.Name, black.Name)
+			result, err := match.PlayRound(i+1, white, black)
+			if errors.Is(err, tournament.ErrInterrupted) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("game %d failed: %w", i+1, err)
+			}
+			if err := record(i, "", result); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Whatever was played before an interruption is still scored and saved
+	interrupted := context.Cause(cmd.Context())
+	if interrupted != nil {
+		played := len(slices.DeleteFunc(slices.Clone(pgns), func(pgn string) bool { return pgn == "" }))
+		fmt.Fprintf(out, "\nInterrupted after %d of %d games\n", played, games)
+	}
+
+	fmt.Fprintf(out, "\nFinal score: %s %.1f – %.1f %s\n", first.Name, scores[first.Name], scores[second.Name], second.Name)
+	if db != nil {
+		ratings, err := updateGlicko(db)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Glicko-2: %s %s, %s %s\n", first.Name, ratings[first.Name], second.Name, ratings[second.Name])
+	}
+
+	if reportDir, _ := cmd.Flags().GetString("report"); reportDir != "" {
+		title := fmt.Sprintf("%s vs %s", first.Name, second.Name)
+		settings, err := game.LoadSettings("")
+		if err != nil {
+			return fmt.Errorf("failed to load settings: %w", err)
+		}
+		played := slices.DeleteFunc(results, func(result *tournament.GameResult) bool { return result == nil })
+		if err := tournament.WriteReport(reportDir, title, played, settings.Formatting()); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Report: %s\n", filepath.Join(reportDir, "index.html"))
+	}
+
+	if shareGames, _ := cmd.Flags().GetBool("share"); shareGames {
+		url, err := sharePGN(strings.Join(slices.DeleteFunc(pgns, func(pgn string) bool { return pgn == "" }), "\n\n"))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Shared: %s\n", url)
+	}
+	return interrupted
+}
+
+// matchHosts returns the Ollama servers to spread games across: the
+// --ollama-hosts flag, or else the first config's ollama_hosts
+func matchHosts(cmd *cobra.Command, configPath string) []string {
+	if hosts, _ := cmd.Flags().GetStringSlice("ollama-hosts"); len(hosts) > 0 {
+		return hosts
+	}
+	config, err := ai_player.LoadConfig(configPath)
+	if err != nil {
+		return nil
+	}
+	return config.OllamaHosts
+}
+
+// sharePGN uploads PGN where the settings file's "share" section says
+func sharePGN(pgn string) (string, error) {
+	settings, err := game.LoadSettings("")
+	if err != nil {
+		return "", fmt.Errorf("failed to load settings: %w", err)
+	}
+	uploader, err := share.New(settings.Share)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	url, err := uploader.Upload(ctx, fmt.Sprintf("bubblechess-match-%s.pgn", time.Now().Format("20060102-150405")), pgn)
+	if err != nil {
+		return "", fmt.Errorf("failed to share games: %w", err)
+	}
+	return url, nil
+}
+
+// appendPGN appends a game to a PGN file
+func appendPGN(path, pgn string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open PGN file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintf(file, "%s\n\n", pgn); err != nil {
+		return fmt.Errorf("failed to write PGN: %w", err)
+	}
+	return nil
+}
+
+// updateGlicko rates every player in the game log with Glicko-2 and saves
+// the ratings for the menu
+func updateGlicko(db *gamedb.DB) (tournament.GlickoRatings, error) {
+	records, err := db.Games()
+	if err != nil {
+		return nil, err
+	}
+	ratings := tournament.RateGames(records, time.Now())
+	if err := tournament.SaveGlicko(ratings, ""); err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
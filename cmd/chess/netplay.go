@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"chess-tui/game"
+	"chess-tui/netplay"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var hostCmd = &cobra.Command{
+	Use:   "host",
+	Short: "Host a networked Human vs Human game",
+	Long: `Host a networked game and wait for an opponent to join with
+"chess join <host>:<port>". The host plays White.
+
+Moves are acknowledged and resent if lost, and a dropped connection is
+picked up again where it left off when the opponent reconnects.
+
+With --hints the game is a consultation: both players can ask the AI
+advisor for ideas with [t], sharing the budget of hints set by the host.
+The advisor never moves. --kibitz adds an AI that comments on the game in
+a side panel on your screen only, without ever holding the game up.
+
+Put the game on the clock with the same flags as a TUI game, e.g.
+--game-time 10m, or "clock" in the settings file. The host's clock counts
+for both players: each move is charged the time its player spent on it,
+not the time it spent crossing the network.
+
+With --password the game is private: only a joiner or spectator giving
+the same password is let in, so friends can play without strangers
+taking the seat.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		port, _ := cmd.Flags().GetInt("port")
+		peer, err := netplay.HostPrivate(fmt.Sprintf(":%d", port), gamePassword(cmd))
+		if err == nil {
+			err = playNetworkGame(cmd, peer)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error hosting game: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var joinCmd = &cobra.Command{
+	Use:   "join <host[:port]>",
+	Short: "Join a networked Human vs Human game",
+	Long: `Join a game hosted with "chess host", playing Black. If the
+connection drops, the game reconnects automatically.
+
+The port defaults to 7000. IPv6 addresses go in brackets with a port, e.g.
+[2001:db8::1]:7000, or bare without one. Behind a proxy the connection is
+tunneled through $HTTPS_PROXY.
+
+When the game starts the host gives a resume token. If your program
+closes mid-game, rejoin within two minutes with --resume <token>; the
+host keeps your seat that long and turns away anyone without the token.
+
+A private game needs the host's --password.
+
+Pass --hints to use the AI advisor when the host has made the game a
+consultation; the host's budget of hints counts.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var peer *netplay.Peer
+		var err error
+		if token, _ := cmd.Flags().GetString("resume"); token != "" {
+			peer, err = netplay.Resume(gameAddr(args[0]), token)
+		} else {
+			peer, err = netplay.JoinPrivate(gameAddr(args[0]), gamePassword(cmd))
+		}
+		if err == nil {
+			err = playNetworkGame(cmd, peer)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error joining game: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var spectateCmd = &cobra.Command{
+	Use:   "spectate <host[:port]>",
+	Short: "Watch a networked game",
+	Long: `Watch a game hosted with "chess host" from the sidelines: the board
+follows the moves as they are played, with the clock if the game has one.
+The players see how many are watching. A private game needs the host's
+--password.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		peer, err := netplay.WatchPrivate(gameAddr(args[0]), gamePassword(cmd))
+		if err == nil {
+			err = watchNetworkGame(cmd, peer)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error watching game: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hostCmd)
+	rootCmd.AddCommand(joinCmd)
+	rootCmd.AddCommand(spectateCmd)
+	spectateCmd.Flags().String("settings", "", "Display settings file (default ~/.bubblechess/settings.json)")
+	addKibitzFlag(spectateCmd)
+
+	hostCmd.Flags().IntP("port", "p", defaultGamePort, "Port to listen for the opponent on")
+	addClockFlags(hostCmd)
+	joinCmd.Flags().String("resume", "", "Rejoin a game in progress with the resume token the host gave")
+	hostCmd.Flags().String("password", "", "Make the game private, open only to players and spectators with this password (default $BUBBLECHESS_GAME_PASSWORD)")
+	for _, cmd := range []*cobra.Command{joinCmd, spectateCmd} {
+		cmd.Flags().String("password", "", "The private game's password (default $BUBBLECHESS_GAME_PASSWORD)")
+	}
+	for _, cmd := range []*cobra.Command{hostCmd, joinCmd} {
+		cmd.Flags().String("settings", "", "Display settings file (default ~/.bubblechess/settings.json)")
+		cmd.Flags().Int("hints", 0, "Share this many hints from the AI advisor with the opponent (0 for no advisor)")
+		addKibitzFlag(cmd)
+		addSSHFlag(cmd)
+		addPhoneFlag(cmd)
+	}
+}
+
+// gamePassword returns the private game's password from --password or,
+// kept off the command line, $BUBBLECHESS_GAME_PASSWORD
+func gamePassword(cmd *cobra.Command) string {
+	if cmd.Flags().Changed("password") {
+		password, _ := cmd.Flags().GetString("password")
+		return password
+	}
+	return os.Getenv("BUBBLECHESS_GAME_PASSWORD")
+}
+
+// playNetworkGame runs the TUI for a networked game until the player quits
+func playNetworkGame(cmd *cobra.Command, peer *netplay.Peer) error {
+	defer peer.Close()
+
+	settingsPath, _ := cmd.Flags().GetString("settings")
+	settings, err := game.LoadSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+	if err := applyClockFlags(cmd, settings); err != nil {
+		return err
+	}
+
+	ctx, cancel := programContext(cmd)
+	defer cancel()
+	opts := lowBandwidthOptions(cmd, settings)
+	phoneLayout(cmd, settings)
+	opts = append(opts, screenOptions(settings)...)
+	defer restoreTitle(settings)
+	g := game.NewNetworkGame(peer, settings)
+	g.SetContext(ctx)
+	if hints, _ := cmd.Flags().GetInt("hints"); hints > 0 {
+		g.SetAdvisor(game.NewAIClient(settings.AIServer), hints)
+	}
+	analyst, err := kibitzer(ctx, cmd, settings)
+	if err != nil {
+		return err
+	}
+	g.SetKibitzer(analyst)
+	opts = append(opts, tea.WithContext(ctx), tea.WithReportFocus())
+	if _, err := runProgram(tea.NewProgram(g, opts...), cancel); err != nil {
+		return fmt.Errorf("failed to run game: %w", err)
+	}
+	if token := peer.Token(); token != "" && peer.Color() == "black" {
+		fmt.Printf("If the game isn't over, rejoin it within %s with:\n  chess join %s --resume %s\n", netplay.ResumeGrace, peer.Addr(), token)
+	}
+	return nil
+}
+
+// watchNetworkGame runs the TUI for a spectator until they quit
+func watchNetworkGame(cmd *cobra.Command, peer *netplay.Peer) error {
+	defer peer.Close()
+
+	settingsPath, _ := cmd.Flags().GetString("settings")
+	settings, err := game.LoadSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	ctx, cancel := programContext(cmd)
+	defer cancel()
+	opts := screenOptions(settings)
+	defer restoreTitle(settings)
+	g := game.NewSpectatorGame(peer, settings)
+	g.SetContext(ctx)
+	analyst, err := kibitzer(ctx, cmd, settings)
+	if err != nil {
+		return err
+	}
+	g.SetKibitzer(analyst)
+	opts = append(opts, tea.WithContext(ctx))
+	if _, err := runProgram(tea.NewProgram(g, opts...), cancel); err != nil {
+		return fmt.Errorf("failed to run game: %w", err)
+	}
+	return nil
+}
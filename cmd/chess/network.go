@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"chess-tui/netconfig"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultGamePort is where "chess host" listens unless told otherwise, and
+// the port join and spectate assume when the address has none
+const defaultGamePort = 7000
+
+func init() {
+	rootCmd.PersistentFlags().Duration("dial-timeout", 0, "How long every network client waits for a connection, through the proxy if there is one (default $BUBBLECHESS_DIAL_TIMEOUT or 5s)")
+	cobra.OnInitialize(configureNetwork)
+}
+
+// configureNetwork applies --dial-timeout, or $BUBBLECHESS_DIAL_TIMEOUT, to
+// every network client. Proxies come from $HTTP_PROXY, $HTTPS_PROXY and
+// $NO_PROXY as usual.
+func configureNetwork() {
+	timeout, _ := rootCmd.PersistentFlags().GetDuration("dial-timeout")
+	if !rootCmd.PersistentFlags().Changed("dial-timeout") {
+		if value := os.Getenv("BUBBLECHESS_DIAL_TIMEOUT"); value != "" {
+			var err error
+			if timeout, err = time.ParseDuration(value); err != nil {
+				slog.Warn("Ignoring invalid BUBBLECHESS_DIAL_TIMEOUT", "value", value, "error", err)
+			}
+		}
+	}
+	netconfig.SetDialTimeout(timeout)
+}
+
+// gameAddr returns the host address given to join or spectate, with the
+// default game port if it has none, e.g. "2001:db8::1" becomes
+// "[2001:db8::1]:7000"
+func gameAddr(addr string) string {
+	return netconfig.HostPort(addr, defaultGamePort)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"chess-tui/ai_player"
+	"chess-tui/game"
+	"chess-tui/netconfig"
+
+	"github.com/spf13/cobra"
+)
+
+// localProviders are the AI providers that play without the network
+var localProviders = map[string]bool{
+	ai_player.ProviderEngine: true,
+	ai_player.ProviderGGUF:   true,
+	ai_player.ProviderScript: true,
+}
+
+func init() {
+	rootCmd.PersistentFlags().Bool("offline", offlineBuild, "Never touch the network: the built-in engine plays and kibitzes instead of the A2A server or Ollama, and every connection is refused; builds with -tags offline default to it")
+	cobra.OnInitialize(configureOffline)
+}
+
+// configureOffline refuses every connection when --offline is set
+func configureOffline() {
+	on, _ := rootCmd.PersistentFlags().GetBool("offline")
+	netconfig.SetOffline(on)
+}
+
+// offline reports whether the program runs without the network
+func offline() bool {
+	return netconfig.Offline()
+}
+
+// offlineConfig has config play with the built-in engine when offline,
+// unless it already plays without the network
+func offlineConfig(config *ai_player.Config) {
+	if offline() && !localProviders[config.Provider] {
+		config.Provider = ai_player.ProviderEngine
+	}
+}
+
+// offlineAI returns the built-in engine as the TUI's AI opponent
+func offlineAI() (game.MoveGenerator, error) {
+	config := ai_player.DefaultConfig()
+	config.Provider = ai_player.ProviderEngine
+	return game.NewLocalAI(config)
+}
@@ -0,0 +1,7 @@
+//go:build offline
+
+package main
+
+// offlineBuild makes --offline the default, for a single binary that plays
+// without the network
+const offlineBuild = true
@@ -0,0 +1,6 @@
+//go:build !offline
+
+package main
+
+// offlineBuild makes --offline the default in builds with -tags offline
+const offlineBuild = false
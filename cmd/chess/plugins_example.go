@@ -0,0 +1,8 @@
+//go:build example_plugins
+
+package main
+
+// Compile the example extensions in with -tags example_plugins. Extensions
+// of your own are added the same way: import their package for its side
+// effects from a file like this one.
+import _ "chess-tui/plugins/example"
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"chess-tui/positions"
+	"chess-tui/tournament"
+
+	"github.com/notnil/chess"
+	"github.com/spf13/cobra"
+)
+
+var positionsCmd = &cobra.Command{
+	Use:   "positions [name]",
+	Short: "List the named test positions",
+	Long: `List the library of named positions: endgame technique such as the
+Lucena and Philidor positions, tactics and stalemate traps. Give a name to
+see its FEN, what it teaches and a line that shows the idea.
+
+Start a game from one with "chess --position lucena", or from a random
+level middlegame with "chess --position scramble".`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listPositions(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(positionsCmd)
+	rootCmd.Flags().String("position", "", "Start games from a named position (see \"chess positions\"), a FEN or \"scramble\" for a random one")
+	rootCmd.Flags().String("scramble-engine", "", "UCI engine, e.g. stockfish, that checks scrambled positions are level (default material only)")
+}
+
+// listPositions prints every position, or the details of the one named
+func listPositions(args []string) error {
+	if len(args) == 0 {
+		for _, position := range positions.All() {
+			fmt.Printf("%-16s %-10s %s\n", position.Name, position.Category, position.Title)
+		}
+		return nil
+	}
+
+	position, ok := positions.Get(args[0])
+	if !ok {
+		return unknownPosition(args[0])
+	}
+	fmt.Printf("%s (%s)\n\n%s\n\nFEN: %s\n", position.Title, position.Category, position.Description, position.FEN)
+	if len(position.Line) > 0 {
+		fmt.Printf("Line: %s\n", strings.Join(position.Line, " "))
+	}
+	if position.Avoid != "" {
+		fmt.Printf("Avoid: %s\n", position.Avoid)
+	}
+	return nil
+}
+
+// scramblePosition is the --position value that starts from a random position
+const scramblePosition = "scramble"
+
+// startPosition resolves --position, a library name, a FEN or "scramble", to
+// a FEN
+func startPosition(value string, scramble func() (string, error)) (string, error) {
+	if value == scramblePosition {
+		return scramble()
+	}
+	if position, ok := positions.Get(value); ok {
+		return position.FEN, nil
+	}
+	if _, err := chess.FEN(value); err == nil && strings.Contains(value, "/") {
+		return value, nil
+	}
+	return "", unknownPosition(value)
+}
+
+// newScrambler returns the generator of scrambled positions, checked by
+// --scramble-engine if given, and a function that stops the engine
+func newScrambler(cmd *cobra.Command) (func() (string, error), func(), error) {
+	var options positions.ScrambleOptions
+	stop := func() {}
+	if path, _ := cmd.Flags().GetString("scramble-engine"); path != "" {
+		engine, err := tournament.NewUCIEngine(path, 0)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to start engine: %w", err)
+		}
+		options.Evaluate = engine.Evaluate
+		stop = func() { engine.Close() }
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	scramble := func() (string, error) {
+		return positions.Scramble(rng, options)
+	}
+	return scramble, stop, nil
+}
+
+// unknownPosition is the error for a name that isn't in the library
+func unknownPosition(name string) error {
+	return fmt.Errorf("unknown position %q; known positions: %s", name, strings.Join(positions.Names(), ", "))
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/spf13/cobra"
+)
+
+// addPprofFlag adds the flag that serves the Go profiler's endpoints
+func addPprofFlag(cmd *cobra.Command) {
+	cmd.Flags().String("pprof", "", "Serve CPU, heap and allocation profiles at this address, e.g. :6060 or localhost:6060 (off by default)")
+}
+
+// startPprof serves net/http/pprof at the --pprof address, if given, on a
+// mux of its own so the profiles never show up on the A2A server's port
+func startPprof(cmd *cobra.Command) error {
+	addr, _ := cmd.Flags().GetString("pprof")
+	if addr == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to start pprof server: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	fmt.Printf("Profiling at http://%s/debug/pprof/\n", listener.Addr())
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			slog.Warn("pprof server stopped", "error", err)
+		}
+	}()
+	return nil
+}
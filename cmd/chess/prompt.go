@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"chess-tui/ai_player"
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+	"github.com/spf13/cobra"
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print the prompt the AI would be sent for a position",
+	Long: `Render the prompt for a position without calling the model, so prompt
+templates and custom prompts can be iterated on quickly.
+
+The position is given with --fen, or reached by playing --history from the
+starting position when --fen is omitted.`,
+	Example: `  chess prompt --history e4,e5,Nf3
+  chess prompt --fen "r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3" --config ai_config.json
+  chess prompt --history e4 --task suggest --personality gambiteer`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := printPrompt(cmd, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(promptCmd)
+
+	promptCmd.Flags().String("fen", "", "Position to prompt for (default: the position after --history)")
+	promptCmd.Flags().StringSlice("history", nil, "Moves played so far, e.g. e4,e5,Nf3")
+	promptCmd.Flags().StringP("config", "c", "", "AI config file whose custom prompts are used")
+	promptCmd.Flags().String("color", "", "Color the AI plays (default: the side to move)")
+	promptCmd.Flags().String("personality", "", "Personality preset, e.g. gambiteer")
+	promptCmd.Flags().String("task", "", `Prompt to render: "" for a move or "suggest" for teach mode`)
+}
+
+// printPrompt writes the prompt described by the command's flags to w
+func printPrompt(cmd *cobra.Command, w io.Writer) error {
+	fen, _ := cmd.Flags().GetString("fen")
+	history, _ := cmd.Flags().GetStringSlice("history")
+	color, _ := cmd.Flags().GetString("color")
+	personality, _ := cmd.Flags().GetString("personality")
+	task, _ := cmd.Flags().GetString("task")
+	configPath, _ := cmd.Flags().GetString("config")
+
+	if task != "" && task != ai_player.TaskSuggest {
+		return fmt.Errorf("--task must be empty or %q", ai_player.TaskSuggest)
+	}
+	if _, ok := ai_player.PersonalityByName(personality); personality != "" && !ok {
+		return fmt.Errorf("unknown personality %q", personality)
+	}
+
+	position, err := promptPosition(fen, history)
+	if err != nil {
+		return err
+	}
+	switch color {
+	case "":
+		color = "white"
+		if position.Turn() == chess.Black {
+			color = "black"
+		}
+	case "white", "black":
+	default:
+		return fmt.Errorf("--color must be white or black")
+	}
+
+	config := ai_player.DefaultConfig()
+	if configPath != "" {
+		if config, err = ai_player.LoadConfig(configPath); err != nil {
+			return fmt.Errorf("failed to load AI config: %w", err)
+		}
+	}
+
+	// Build the player directly so no provider or model is loaded
+	logger := ai_player.NewColoredLogger(ai_player.LevelError)
+	logger.SetOutput(io.Discard)
+	player := ai_player.NewAIPlayer(config.OllamaURL, config.Model, color, logger)
+	player.CustomPrompts = config.CustomPrompts
+	player.Personality = personality
+
+	_, err = fmt.Fprintln(w, player.Prompt(position.String(), history, task))
+	return err
+}
+
+// promptPosition returns the position given as FEN, or the one reached by
+// playing history from the start when fen is empty
+func promptPosition(fen string, history []string) (*chess.Position, error) {
+	if fen != "" {
+		option, err := chess.FEN(fen)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --fen: %w", err)
+		}
+		return chess.NewGame(option).Position(), nil
+	}
+
+	game := chess.NewGame()
+	for i, text := range history {
+		move, err := notation.Decode(game.Position(), text)
+		if err != nil {
+			return nil, fmt.Errorf("history move %d: %w", i+1, err)
+		}
+		game.Move(move)
+	}
+	return game.Position(), nil
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"chess-tui/ai_player"
+	"chess-tui/cast"
+	"chess-tui/game"
+	"chess-tui/gamedb"
+	"chess-tui/notation"
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay [session.cast | game.pgn]",
+	Short: "Replay a recorded TUI session or a game",
+	Long: `Replay an asciicast recording made with --record, or step through a
+game from a PGN file or the game log on a board with auto-play.
+
+Recordings are asciinema-compatible, so they can also be played with
+"asciinema play" or uploaded for bug reports and demos of AI games.
+
+In the board replay, space plays and pauses, +/- change the speed (0.5x to
+8x), the arrow keys step, g and G jump to the start and end, and : jumps to
+a move number. / searches the moves by SAN (e.g. "Nxf7") or the positions by
+a FEN fragment, and n/N step through the matches.
+
+The position shown is analyzed in the background once the replay rests on
+it, by the built-in engine unless --analysis-engine names a UCI engine or
+--analysis-config an AI config to ask for candidate moves; a toggles the
+analysis.
+
+With no file or --game, the game log is listed to pick a game from; / there
+searches the games by player or opponent.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := replay(cmd, args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error replaying: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().Float64P("speed", "s", 1.0, "Playback speed multiplier")
+	replayCmd.Flags().Duration("max-idle", 2*time.Second, "Cap pauses between frames (0 to disable)")
+	replayCmd.Flags().Int("game", 0, "Replay a game from the game log, counting back from the latest (1 is the last game)")
+	replayCmd.Flags().String("games", "", "Game log to replay from (default ~/.bubblechess/games.jsonl)")
+	replayCmd.Flags().Bool("play", false, "Start auto-play right away")
+	replayCmd.Flags().String("settings", "", "Display settings file (default ~/.bubblechess/settings.json)")
+	replayCmd.Flags().String("analysis-engine", "", "UCI engine, e.g. stockfish, that analyzes the positions shown")
+	replayCmd.Flags().String("analysis-config", "", "AI config whose candidate moves analyze the positions shown")
+	replayCmd.Flags().Bool("no-analysis", false, "Don't analyze the positions shown")
+}
+
+// replay picks the session or game replay for the arguments
+func replay(cmd *cobra.Command, args []string) error {
+	back, _ := cmd.Flags().GetInt("game")
+	switch {
+	case back > 0:
+		return replayLoggedGame(cmd, back)
+	case len(args) == 0:
+		return browseGames(cmd)
+	case strings.EqualFold(filepath.Ext(args[0]), ".pgn"):
+		return replayPGN(cmd, args[0])
+	default:
+		return replaySession(cmd, args[0])
+	}
+}
+
+func replaySession(cmd *cobra.Command, path string) error {
+	speed, _ := cmd.Flags().GetFloat64("speed")
+	maxIdle, _ := cmd.Flags().GetDuration("max-idle")
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	_, events, err := cast.Decode(file)
+	if err != nil {
+		return err
+	}
+
+	return cast.Play(os.Stdout, events, speed, maxIdle)
+}
+
+// replayPGN steps through the first game of a PGN file
+func replayPGN(cmd *cobra.Command, path string) error {
+	settings, err := replaySettings(cmd)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open PGN: %w", err)
+	}
+	defer file.Close()
+
+	viewer, err := game.NewReplayFromPGN(file, settings)
+	if err != nil {
+		return err
+	}
+	return runReplay(cmd, viewer)
+}
+
+// replayLoggedGame steps through a game from the game log, back games from the latest
+func replayLoggedGame(cmd *cobra.Command, back int) error {
+	settings, err := replaySettings(cmd)
+	if err != nil {
+		return err
+	}
+	gamesPath, _ := cmd.Flags().GetString("games")
+	records, err := gamedb.Open(gamesPath).Games()
+	if err != nil {
+		return err
+	}
+	if back > len(records) {
+		return fmt.Errorf("the game log has %d games", len(records))
+	}
+	record := records[len(records)-back]
+
+	viewer, err := game.NewReplayFromRecord(record, settings)
+	if err != nil {
+		return err
+	}
+	return runReplay(cmd, viewer)
+}
+
+// browseGames lists the game log to pick a game to replay from
+func browseGames(cmd *cobra.Command) error {
+	settings, err := replaySettings(cmd)
+	if err != nil {
+		return err
+	}
+	gamesPath, _ := cmd.Flags().GetString("games")
+	browser := game.NewGameBrowser(gamedb.Open(gamesPath), settings)
+	analyst, stop, err := replayAnalyst(cmd)
+	if err != nil {
+		return err
+	}
+	defer stop()
+	browser.SetAnalyst(analyst)
+
+	ctx, cancel := programContext(cmd)
+	defer cancel()
+	if _, err := runProgram(tea.NewProgram(browser, tea.WithContext(ctx), tea.WithReportFocus()), cancel); err != nil {
+		return fmt.Errorf("failed to run game browser: %w", err)
+	}
+	return nil
+}
+
+// replaySettings loads the display settings for a board replay
+func replaySettings(cmd *cobra.Command) (*game.Settings, error) {
+	settingsPath, _ := cmd.Flags().GetString("settings")
+	settings, err := game.LoadSettings(settingsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	return settings, nil
+}
+
+// runReplay runs a board replay at the speed asked for
+func runReplay(cmd *cobra.Command, viewer *game.Replay) error {
+	speed, _ := cmd.Flags().GetFloat64("speed")
+	viewer.SetSpeed(speed)
+	if play, _ := cmd.Flags().GetBool("play"); play {
+		viewer.Play()
+	}
+	analyst, stop, err := replayAnalyst(cmd)
+	if err != nil {
+		return err
+	}
+	defer stop()
+	viewer.SetAnalyst(analyst)
+
+	ctx, cancel := programContext(cmd)
+	defer cancel()
+	if _, err := runProgram(tea.NewProgram(viewer, tea.WithContext(ctx), tea.WithReportFocus()), cancel); err != nil {
+		return fmt.Errorf("failed to run replay: %w", err)
+	}
+	return nil
+}
+
+// replayAnalyst returns who analyzes the positions of a board replay, as
+// picked by the flags, and a function that stops it
+func replayAnalyst(cmd *cobra.Command) (game.Analyst, func(), error) {
+	stop := func() {}
+	if off, _ := cmd.Flags().GetBool("no-analysis"); off {
+		return nil, stop, nil
+	}
+	if path, _ := cmd.Flags().GetString("analysis-engine"); path != "" {
+		engine, err := tournament.NewUCIEngine(path, 0)
+		if err != nil {
+			return nil, stop, fmt.Errorf("failed to start engine: %w", err)
+		}
+		return uciAnalyst{engine: engine, name: filepath.Base(path)}, func() { engine.Close() }, nil
+	}
+	if path, _ := cmd.Flags().GetString("analysis-config"); path != "" {
+		config, err := ai_player.LoadConfig(path)
+		if err != nil {
+			return nil, stop, fmt.Errorf("failed to load AI config: %w", err)
+		}
+		localAI, err := game.NewLocalAI(config)
+		if err != nil {
+			return nil, stop, err
+		}
+		return game.NewSuggestionAnalyst(localAI), stop, nil
+	}
+	return game.EngineAnalyst(), stop, nil
+}
+
+// uciAnalyst analyzes replay positions with a UCI engine
+type uciAnalyst struct {
+	engine *tournament.UCIEngine
+	name   string
+}
+
+func (u uciAnalyst) Name() string {
+	return u.name
+}
+
+func (u uciAnalyst) Analyze(fen string) (game.Evaluation, error) {
+	if moves, err := notation.LegalMoves(fen); err != nil || len(moves) == 0 {
+		// The game is over, or the position can't be analyzed
+		return game.EngineAnalyst().Analyze(fen)
+	}
+	score, err := u.engine.Evaluate(fen)
+	if err != nil {
+		return game.Evaluation{}, err
+	}
+	if strings.Fields(fen)[1] == "b" {
+		// The engine scores for the side to move
+		score = -score
+	}
+	evaluation := game.Evaluation{Score: score}
+	move, err := u.engine.GetMove(fen, nil)
+	if err != nil {
+		return game.Evaluation{}, err
+	}
+	if evaluation.Best, err = notation.Normalize(fen, move.Notation); err != nil {
+		return game.Evaluation{}, err
+	}
+	return evaluation, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"chess-tui/game"
+	"chess-tui/gamedb"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume [adjourned game]",
+	Short: "Resume a game adjourned with a sealed move",
+	Long: `Resume a game adjourned with ctrl+a in the TUI. The player who sealed the
+move types the seal code they were given, the sealed move is opened and
+played, and the game carries on.
+
+The game is given as the file it was saved to or its ID. With no game, the
+adjourned games are listed.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := resume(cmd, args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error resuming: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resumeCmd)
+
+	resumeCmd.Flags().String("settings", "", "Display settings file (default ~/.bubblechess/settings.json)")
+	resumeCmd.Flags().String("games", "", "Game log to record the finished game in (default ~/.bubblechess/games.jsonl)")
+}
+
+func resume(cmd *cobra.Command, args []string) error {
+	settingsPath, _ := cmd.Flags().GetString("settings")
+	settings, err := game.LoadSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+	if len(args) == 0 {
+		return listAdjournments(settings.AdjournedDir)
+	}
+
+	path := args[0]
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		dir := settings.AdjournedDir
+		if dir == "" {
+			dir = gamedb.DefaultAdjournedDir()
+		}
+		path = filepath.Join(dir, args[0]+".json")
+	}
+	resumed, err := game.ResumeAdjournment(path, settings)
+	if err != nil {
+		return err
+	}
+	gamesPath, _ := cmd.Flags().GetString("games")
+	resumed.SetGameDB(gamedb.Open(gamesPath))
+	if !settings.Archive.Disabled {
+		resumed.SetArchive(gamedb.OpenArchive(settings.Archive))
+	}
+
+	ctx, cancel := programContext(cmd)
+	defer cancel()
+	defer restoreTitle(settings)
+	resumed.SetContext(ctx)
+	opts := append(screenOptions(settings), tea.WithContext(ctx), tea.WithReportFocus())
+	if _, err := runProgram(tea.NewProgram(resumed, opts...), cancel); err != nil {
+		return fmt.Errorf("failed to run game: %w", err)
+	}
+	return nil
+}
+
+// listAdjournments prints the adjourned games in dir, most recent first
+func listAdjournments(dir string) error {
+	paths, err := gamedb.Adjournments(dir)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		fmt.Println("No adjourned games")
+		return nil
+	}
+	for _, path := range paths {
+		adjournment, err := gamedb.LoadAdjournment(path)
+		if err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			continue
+		}
+		fmt.Printf("%s  %s vs %s, adjourned %s after %d moves, %s sealed\n", adjournment.ID,
+			adjournment.White, adjournment.Black, adjournment.Adjourned.Format("Jan 2, 2006 15:04"),
+			len(adjournment.Moves), adjournment.SealedBy)
+	}
+	fmt.Println("\nResume one with: chess resume <id>")
+	return nil
+}
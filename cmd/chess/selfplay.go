@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"chess-tui/gamedb"
+	"chess-tui/tournament"
+
+	"github.com/spf13/cobra"
+)
+
+var selfplayCmd = &cobra.Command{
+	Use:   "selfplay",
+	Short: "Play many AI vs AI games at once to generate a training dataset",
+	Long: `Play AI vs AI games (or engine vs engine, with provider "engine" configs)
+several at a time, writing each finished game to a PGN file and every move
+of it, for both sides, to a JSONL training dataset in the format of
+"chess --collect-data".
+
+Runs can be resumed: the games already in the PGN file count towards
+--games, so running the same command again after an interruption plays only
+the games still missing. Samples of a game that was interrupted before it
+reached the PGN file are dropped from the dataset.`,
+	Example: `  chess selfplay --games 1000 --parallel 8
+  chess selfplay --white engine.json --black engine.json --pgn engine.pgn --dataset engine.jsonl`,
+	Run: func(cmd *cobra.Command, args []string) {
+		defer interruptible(cmd)()
+		if err := runSelfplay(cmd); err != nil {
+			exitWithError("Error", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(selfplayCmd)
+
+	selfplayCmd.Flags().String("white", "ai_config.json", "AI config for the first player")
+	selfplayCmd.Flags().String("black", "ai_config.json", "AI config for the second player")
+	selfplayCmd.Flags().IntP("games", "g", 100, "Number of games in all, including those already in --pgn; colors alternate")
+	selfplayCmd.Flags().IntP("parallel", "p", 4, "Games played at once")
+	selfplayCmd.Flags().String("pgn", "selfplay.pgn", "PGN file the games are appended to")
+	selfplayCmd.Flags().String("dataset", "selfplay.jsonl", "JSONL file the training samples are appended to")
+	addRefereeFlags(selfplayCmd)
+	addTraceFlags(selfplayCmd)
+}
+
+func runSelfplay(cmd *cobra.Command) error {
+	whitePath, _ := cmd.Flags().GetString("white")
+	blackPath, _ := cmd.Flags().GetString("black")
+	games, _ := cmd.Flags().GetInt("games")
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	pgnPath, _ := cmd.Flags().GetString("pgn")
+	datasetPath, _ := cmd.Flags().GetString("dataset")
+	if parallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	timeControl, err := matchTimeControl(cmd)
+	if err != nil {
+		return err
+	}
+
+	played, err := playedGameIDs(pgnPath)
+	if err != nil {
+		return err
+	}
+	dataset := gamedb.OpenDataset(datasetPath)
+	if err := trimDataset(dataset, played); err != nil {
+		return err
+	}
+	done := len(played)
+	if done >= games {
+		fmt.Printf("%s already holds %d games\n", pgnPath, done)
+		return nil
+	}
+	if done > 0 {
+		fmt.Printf("Resuming: %d of %d games already in %s\n", done, games, pgnPath)
+	}
+
+	// Fail on a bad config before starting any workers
+	first, err := loadEntrant(cmd, whitePath)
+	if err != nil {
+		return err
+	}
+	second, err := loadEntrant(cmd, blackPath)
+	if err != nil {
+		return err
+	}
+	paths := [2]string{whitePath, blackPath}
+	names := [2]string{first.Name, second.Name}
+	if first.Name == second.Name {
+		names = [2]string{first.Name + " (1)", second.Name + " (2)"}
+	}
+
+	match := tournament.NewMatch(matchAdjudication(cmd))
+	match.TimeControl = timeControl
+	match.Context = cmd.Context()
+	remaining := games - done
+	if parallel > remaining {
+		parallel = remaining
+	}
+	fmt.Printf("Playing %d games, %d at a time: %s vs %s\n", remaining, parallel, names[0], names[1])
+
+	// Games stop being handed out once the command is interrupted
+	ctx := cmd.Context()
+	indices := make(chan int)
+	go func() {
+		defer close(indices)
+		for i := done; i < games; i++ {
+			select {
+			case indices <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var mu sync.Mutex
+	var firstErr error
+	var failed int
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each worker has players of its own, as a player plays one
+			// game at a time
+			var players [2]tournament.Entrant
+			for p, path := range paths {
+				entrant, err := loadEntrant(cmd, path)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				entrant.Name = names[p]
+				players[p] = entrant
+			}
+
+			for i := range indices {
+				white, black := players[0], players[1]
+				if i%2 == 1 {
+					white, black = black, white
+				}
+				result, err := match.PlayGame(white, black)
+				if errors.Is(err, tournament.ErrInterrupted) {
+					return // left for the next run, like any unplayed game
+				}
+
+				mu.Lock()
+				if err == nil {
+					err = saveSelfplayGame(dataset, pgnPath, result)
+				}
+				if err != nil {
+					failed++
+					fmt.Printf("Game %d failed: %v\n", i+1, err)
+				} else {
+					done++
+					rate := float64(done-(games-remaining)) / time.Since(start).Minutes()
+					fmt.Printf("[%d/%d] %s vs %s: %s in %d plies (%s) — %.1f games/min\n",
+						done, games, result.White, result.Black, result.Outcome, len(result.Moves), result.Reason, rate)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	fmt.Printf("\n%d games in %s, training samples in %s\n", done, pgnPath, datasetPath)
+	if err := context.Cause(ctx); err != nil {
+		fmt.Printf("Stopped at %d of %d games; run the command again to resume\n", done, games)
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d games failed; run the command again to replay them", failed)
+	}
+	return nil
+}
+
+// saveSelfplayGame appends a game's samples to the dataset, then the game
+// to the PGN file, which is what marks it as played
+func saveSelfplayGame(dataset *gamedb.Dataset, pgnPath string, result *tournament.GameResult) error {
+	if err := dataset.Add(result.Samples()); err != nil {
+		return err
+	}
+	return appendPGN(pgnPath, result.PGN)
+}
+
+// playedGameIDs returns the GameId tags of the games in a PGN file, none if
+// it doesn't exist yet
+func playedGameIDs(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PGN file: %w", err)
+	}
+	defer file.Close()
+
+	ids := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if id, ok := strings.CutPrefix(line, `[GameId "`); ok {
+			ids[strings.TrimSuffix(id, `"]`)] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read PGN file: %w", err)
+	}
+	return ids, nil
+}
+
+// trimDataset drops the samples of games that aren't among the played
+// ones, left behind by a run interrupted between writing a game's samples
+// and its PGN
+func trimDataset(dataset *gamedb.Dataset, played map[string]bool) error {
+	samples, err := dataset.Samples()
+	if err != nil {
+		return err
+	}
+	kept := make([]gamedb.Sample, 0, len(samples))
+	for _, sample := range samples {
+		if played[sample.GameID] {
+			kept = append(kept, sample)
+		}
+	}
+	if len(kept) == len(samples) {
+		return nil
+	}
+	fmt.Printf("Dropping %d samples of unfinished games\n", len(samples)-len(kept))
+	return dataset.Replace(kept)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"chess-tui/game"
+	"chess-tui/gamedb"
+	"chess-tui/sshserver"
+	"chess-tui/tournament"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+)
+
+var sshServerCmd = &cobra.Command{
+	Use:   "ssh-server",
+	Short: "Host the TUI over SSH",
+	Long: `Host the TUI over SSH so anyone can play with "ssh -p 2222 <host>".
+
+Every connection gets its own menu and games. Human vs AI games play
+against the A2A server given with --ai-url, which can be shared by all
+sessions. The host key is generated on first run.
+
+Players who connect with an SSH key keep their games, rating and
+preferences between connections, under --data-dir; anyone else plays as
+a guest. The menu ranks them on a leaderboard, which --http-port also
+serves as JSON at /leaderboard?period=week|all for embedding elsewhere.
+
+Every finished game is archived as PGN under --archive-dir, and --http-port
+lists them as JSON at /archive, each downloadable at /archive/{id}.pgn.
+--archive-keep-games and --archive-keep-days limit how many are kept.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := startSSHServer(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running SSH server: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sshServerCmd)
+
+	sshServerCmd.Flags().String("host", "", "Address to listen on (default all interfaces)")
+	sshServerCmd.Flags().IntP("port", "p", 2222, "Port to listen on")
+	sshServerCmd.Flags().String("host-key", "", "Server's private key, created if missing (default ~/.bubblechess/ssh_host_ed25519)")
+	sshServerCmd.Flags().String("ai-url", "", "A2A server for Human vs AI games (default http://localhost:8080)")
+	sshServerCmd.Flags().String("settings", "", "Display settings every session starts with (default ~/.bubblechess/settings.json)")
+	sshServerCmd.Flags().Duration("idle-timeout", sshserver.DefaultIdleTimeout, "Close sessions idle this long")
+	sshServerCmd.Flags().Int("http-port", 0, "Port to serve the leaderboard as JSON on, 0 for none")
+	sshServerCmd.Flags().String("data-dir", "", "Where players' profiles are kept, one directory per SSH key (default ~/.bubblechess/ssh-players)")
+	sshServerCmd.Flags().String("archive-dir", "", "Where finished games are archived as PGN (default ssh-archive beside --data-dir)")
+	sshServerCmd.Flags().Int("archive-keep-games", 0, "Keep only this many of the newest archived games, 0 for all")
+	sshServerCmd.Flags().Int("archive-keep-days", 0, "Drop archived games older than this many days, 0 to keep them")
+	sshServerCmd.Flags().Bool("no-archive", false, "Don't archive finished games")
+}
+
+// startSSHServer serves the TUI over SSH until the process is stopped
+func startSSHServer(cmd *cobra.Command) error {
+	settingsPath, _ := cmd.Flags().GetString("settings")
+	settings, err := game.LoadSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	// Sessions share the process's renderer, whose output isn't their
+	// terminal, so pick colors nearly every terminal shows, and leave the
+	// image board to local play
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	os.Setenv("BUBBLECHESS_GRAPHICS", game.GraphicsNone)
+
+	host, _ := cmd.Flags().GetString("host")
+	port, _ := cmd.Flags().GetInt("port")
+	hostKey, _ := cmd.Flags().GetString("host-key")
+	aiURL, _ := cmd.Flags().GetString("ai-url")
+	idle, _ := cmd.Flags().GetDuration("idle-timeout")
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	httpPort, _ := cmd.Flags().GetInt("http-port")
+	var archive gamedb.ArchivePolicy
+	archive.Dir, _ = cmd.Flags().GetString("archive-dir")
+	archive.KeepGames, _ = cmd.Flags().GetInt("archive-keep-games")
+	archive.KeepDays, _ = cmd.Flags().GetInt("archive-keep-days")
+	archive.Disabled, _ = cmd.Flags().GetBool("no-archive")
+
+	// Players are rated against the AI opponents' bench ratings, if any
+	ratings, err := tournament.LoadRatings("")
+	if err != nil {
+		return fmt.Errorf("failed to load ratings: %w", err)
+	}
+	server, err := sshserver.New(sshserver.Config{
+		Addr:        fmt.Sprintf("%s:%d", host, port),
+		HostKeyPath: hostKey,
+		Settings:    settings,
+		AIServer:    aiURL,
+		IdleTimeout: idle,
+		DataDir:     dataDir,
+		Ratings:     ratings,
+		Archive:     archive,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := programContext(cmd)
+	defer cancel()
+
+	var web *http.Server
+	if httpPort != 0 {
+		web = &http.Server{Addr: fmt.Sprintf("%s:%d", host, httpPort), Handler: server.Handler()}
+		go func() {
+			if err := web.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Fprintf(os.Stderr, "Error serving leaderboard: %v\n", err)
+			}
+		}()
+		fmt.Printf("Leaderboard at http://%s:%d/leaderboard\n", host, httpPort)
+		if !archive.Disabled {
+			fmt.Printf("Game archive at http://%s:%d/archive\n", host, httpPort)
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdown, done := context.WithTimeout(context.Background(), 10*time.Second)
+		defer done()
+		if web != nil {
+			web.Shutdown(shutdown)
+		}
+		server.Shutdown(shutdown)
+	}()
+
+	fmt.Printf("SSH server listening on %s:%d\n", host, port)
+	return server.ListenAndServe()
+}
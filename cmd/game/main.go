@@ -10,8 +10,12 @@ import (
 )
 
 func main() {
-	p := tea.NewProgram(game.NewMenu())
-	if _, err := p.Run(); err != nil {
+	// Save the terminal title the game changes, and put it back on exit
+	fmt.Print("\x1b[22;0t")
+	p := tea.NewProgram(game.NewMenu(), tea.WithAltScreen())
+	_, err := p.Run()
+	fmt.Print("\x1b[23;0t")
+	if err != nil {
 		fmt.Printf("Error running game: %v\n", err)
 		os.Exit(1)
 	}
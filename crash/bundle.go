@@ -0,0 +1,107 @@
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Bundle is the set of files written to a bug-report directory after a crash
+type Bundle struct {
+	Report *Report
+	Logs   []string
+	Config []byte
+}
+
+// secretKeys are substrings of config keys whose values are redacted
+var secretKeys = []string{"key", "token", "secret", "password", "auth"}
+
+// Write writes the bundle to a new temporary directory and returns its path
+func (b *Bundle) Write() (string, error) {
+	dir, err := os.MkdirTemp("", "bubblechess-crash-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create crash directory: %w", err)
+	}
+
+	var panicText strings.Builder
+	panicText.WriteString(fmt.Sprintf("Time: %s\n", time.Now().Format(time.RFC3339)))
+	panicText.WriteString(fmt.Sprintf("Go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH))
+	if b.Report != nil {
+		panicText.WriteString(fmt.Sprintf("Panic: %v\n\n", b.Report.Value))
+		panicText.Write(b.Report.Stack)
+	}
+
+	files := map[string][]byte{
+		"panic.txt": []byte(panicText.String()),
+		"logs.txt":  []byte(strings.Join(b.Logs, "\n") + "\n"),
+	}
+	if b.Report != nil && b.Report.State != "" {
+		files["state.txt"] = []byte(b.Report.State + "\n")
+	}
+	if len(b.Config) > 0 {
+		files["config.json"] = RedactJSON(b.Config)
+	}
+
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			return dir, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	return dir, nil
+}
+
+// RedactJSON replaces the values of secret-looking keys in a JSON document.
+// Input that is not valid JSON is dropped entirely rather than leaked.
+func RedactJSON(data []byte) []byte {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return []byte(`"<unparseable config omitted>"`)
+	}
+
+	redacted, err := json.MarshalIndent(redactValue(doc), "", "  ")
+	if err != nil {
+		return []byte(`"<unparseable config omitted>"`)
+	}
+	return redacted
+}
+
+// redactValue walks a decoded JSON value redacting secret keys
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if isSecretKey(key) {
+				if s, ok := inner.(string); ok && s == "" {
+					continue
+				}
+				v[key] = "<redacted>"
+				continue
+			}
+			v[key] = redactValue(inner)
+		}
+		return v
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = redactValue(inner)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// isSecretKey reports whether a config key looks like it holds a credential
+func isSecretKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if strings.Contains(lower, secret) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package crash
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type panickyModel struct{}
+
+func (m panickyModel) Init() tea.Cmd { return nil }
+
+func (m panickyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	return m, func() tea.Msg { panic("boom") }
+}
+
+func (m panickyModel) View() string { return "" }
+
+func (m panickyModel) CrashState() string { return "FEN: start" }
+
+func TestRedactJSON(t *testing.T) {
+	input := []byte(`{"model":"llama3.2:3b","api_key":"sk-123","providers":[{"auth_token":"abc"}]}`)
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(RedactJSON(input), &out); err != nil {
+		t.Fatalf("Redacted config is not valid JSON: %v", err)
+	}
+	if out["model"] != "llama3.2:3b" {
+		t.Errorf("Expected model to be kept, got %v", out["model"])
+	}
+	if out["api_key"] != "<redacted>" {
+		t.Errorf("Expected api_key to be redacted, got %v", out["api_key"])
+	}
+	provider := out["providers"].([]interface{})[0].(map[string]interface{})
+	if provider["auth_token"] != "<redacted>" {
+		t.Errorf("Expected nested auth_token to be redacted, got %v", provider["auth_token"])
+	}
+}
+
+func TestGuardRecordsCommandPanic(t *testing.T) {
+	guard := NewGuard(panickyModel{})
+	_, cmd := guard.Update(tea.KeyMsg{})
+
+	msg := cmd()
+	if _, ok := msg.(crashMsg); !ok {
+		t.Fatalf("Expected crashMsg from panicking command, got %T", msg)
+	}
+
+	report := guard.Report()
+	if report == nil {
+		t.Fatal("Expected a crash report")
+	}
+	if report.State != "FEN: start" {
+		t.Errorf("Expected state to be captured, got %q", report.State)
+	}
+}
+
+// sequencedModel panics in a command nested in a sequence and a batch
+type sequencedModel struct{ panickyModel }
+
+func (m sequencedModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	_, boom := m.panickyModel.Update(msg)
+	ok := func() tea.Msg { return "ok" }
+	return m, tea.Sequence(ok, tea.Batch(ok, boom))
+}
+
+// runAll runs cmd and every command in the batches and sequences it returns
+func runAll(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	cmds := reflect.ValueOf(msg)
+	if cmds.Kind() != reflect.Slice || cmds.Type().Elem() != reflect.TypeOf(tea.Cmd(nil)) {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for i := range cmds.Len() {
+		msgs = append(msgs, runAll(cmds.Index(i).Interface().(tea.Cmd))...)
+	}
+	return msgs
+}
+
+func TestGuardRecordsPanicInSequence(t *testing.T) {
+	guard := NewGuard(sequencedModel{})
+	_, cmd := guard.Update(tea.KeyMsg{})
+
+	msgs := runAll(cmd)
+	if len(msgs) != 3 || msgs[0] != "ok" || msgs[1] != "ok" {
+		t.Fatalf("Expected both commands that don't panic to run, got %v", msgs)
+	}
+	if _, ok := msgs[2].(crashMsg); !ok {
+		t.Fatalf("Expected crashMsg from the nested command, got %T", msgs[2])
+	}
+	if report := guard.Report(); report == nil || report.State != "FEN: start" {
+		t.Errorf("Expected a crash report with the state, got %+v", report)
+	}
+}
+
+func TestBundleWrite(t *testing.T) {
+	logs := NewLogBuffer(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}), 2)
+	logger := slog.New(logs)
+	logger.Debug("first")
+	logger.Debug("second")
+	logger.Debug("third", "move", "e4")
+
+	if lines := logs.Lines(); len(lines) != 2 {
+		t.Fatalf("Expected 2 buffered lines, got %d", len(lines))
+	}
+
+	bundle := &Bundle{
+		Report: &Report{Value: "boom", Stack: []byte("stack"), State: "FEN: start"},
+		Logs:   logs.Lines(),
+		Config: []byte(`{"api_key":"secret"}`),
+	}
+	dir, err := bundle.Write()
+	if err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"panic.txt", "logs.txt", "state.txt", "config.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s in bundle: %v", name, err)
+		}
+	}
+}
+
+func TestBugReportArchive(t *testing.T) {
+	report := &BugReport{
+		Transcript:  []byte(`{"kind":"ai_error","error":"timeout"}` + "\n"),
+		Configs:     map[string][]byte{"ai_config.json": []byte(`{"model":"llama3.2:3b","api_key":"sk-123"}`)},
+		Environment: map[string]string{"go": "go1.24.5", "TERM": "xterm-256color"},
+	}
+	path := filepath.Join(t.TempDir(), "report.tar.gz")
+	if err := report.WriteArchive(path); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	defer file.Close()
+	compressed, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Archive is not gzipped: %v", err)
+	}
+	files := map[string]string{}
+	archive := tar.NewReader(compressed)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read archive: %v", err)
+		}
+		data, _ := io.ReadAll(archive)
+		files[header.Name] = string(data)
+	}
+
+	if files["environment.txt"] != "TERM: xterm-256color\ngo: go1.24.5\n" {
+		t.Errorf("Expected the sorted environment, got %q", files["environment.txt"])
+	}
+	if !strings.Contains(files["transcript.jsonl"], "ai_error") {
+		t.Errorf("Expected the transcript, got %q", files["transcript.jsonl"])
+	}
+	if config := files["ai_config.json"]; strings.Contains(config, "sk-123") || !strings.Contains(config, "llama3.2:3b") {
+		t.Errorf("Expected the config with its key redacted, got %q", config)
+	}
+
+	link := report.IssueURL("Bug report", "report.tar.gz")
+	if !strings.HasPrefix(link, IssuesURL+"?") || !strings.Contains(link, "xterm-256color") {
+		t.Errorf("Expected an issue link prefilled with the environment, got %s", link)
+	}
+}
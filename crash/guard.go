@@ -0,0 +1,138 @@
+package crash
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// StateReporter is implemented by models that can describe their state for a crash report
+type StateReporter interface {
+	CrashState() string
+}
+
+// Report describes a recovered panic
+type Report struct {
+	Value interface{}
+	Stack []byte
+	State string
+}
+
+// crashMsg is sent when a command panics in its goroutine
+type crashMsg struct{}
+
+// Guard wraps a Bubble Tea model and records any panic raised by the model
+// or by the commands it returns, along with the model's last known state.
+type Guard struct {
+	model tea.Model
+	state string
+
+	mu     sync.Mutex
+	report *Report
+}
+
+// NewGuard wraps the given model
+func NewGuard(model tea.Model) *Guard {
+	return &Guard{model: model}
+}
+
+// Report returns the recorded panic, or nil if the program did not crash
+func (g *Guard) Report() *Report {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.report
+}
+
+// Init initializes the wrapped model
+func (g *Guard) Init() tea.Cmd {
+	defer g.recoverAndRepanic()
+	return g.wrapCmd(g.model.Init())
+}
+
+// Update forwards messages to the wrapped model
+func (g *Guard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(crashMsg); ok {
+		return g, tea.Quit
+	}
+
+	defer g.recoverAndRepanic()
+
+	model, cmd := g.model.Update(msg)
+	g.model = model
+	g.snapshot()
+	return g, g.wrapCmd(cmd)
+}
+
+// View renders the wrapped model
+func (g *Guard) View() string {
+	defer g.recoverAndRepanic()
+	return g.model.View()
+}
+
+// snapshot stores the wrapped model's state so it survives a later panic
+func (g *Guard) snapshot() {
+	if reporter, ok := g.model.(StateReporter); ok {
+		state := reporter.CrashState()
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.state = state
+	}
+}
+
+// record stores the panic details, keeping only the first panic
+func (g *Guard) record(r interface{}) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.report != nil {
+		return
+	}
+	g.report = &Report{
+		Value: r,
+		Stack: debug.Stack(),
+		State: g.state,
+	}
+}
+
+// recoverAndRepanic records a panic on the program goroutine and re-raises it
+// so Bubble Tea restores the terminal
+func (g *Guard) recoverAndRepanic() {
+	if r := recover(); r != nil {
+		g.record(r)
+		panic(r)
+	}
+}
+
+// cmdsType is the element type of the messages Bubble Tea runs commands
+// from: tea.BatchMsg, and the unexported message tea.Sequence returns
+var cmdsType = reflect.TypeOf(tea.Cmd(nil))
+
+// wrapCmd recovers panics in a command's goroutine and turns them into a
+// quit, wrapping the commands of batches and sequences it returns as well
+func (g *Guard) wrapCmd(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				g.record(fmt.Sprintf("%v (in command goroutine)", r))
+				msg = crashMsg{}
+			}
+		}()
+
+		msg = cmd()
+		cmds := reflect.ValueOf(msg)
+		if cmds.Kind() != reflect.Slice || cmds.Type().Elem() != cmdsType {
+			return msg
+		}
+		wrapped := reflect.MakeSlice(cmds.Type(), cmds.Len(), cmds.Len())
+		for i := range cmds.Len() {
+			c, _ := cmds.Index(i).Interface().(tea.Cmd)
+			wrapped.Index(i).Set(reflect.ValueOf(g.wrapCmd(c)))
+		}
+		return wrapped.Interface()
+	}
+}
@@ -0,0 +1,113 @@
+package crash
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogBuffer is a slog.Handler that keeps the most recent log records in memory
+// while forwarding them to another handler
+type LogBuffer struct {
+	next  slog.Handler
+	ring  *ring
+	attrs []slog.Attr
+	group string
+}
+
+// ring is the shared fixed-size buffer behind a LogBuffer and its derived handlers
+type ring struct {
+	mu    sync.Mutex
+	lines []string
+	size  int
+}
+
+// NewLogBuffer creates a handler that remembers the last size records
+func NewLogBuffer(next slog.Handler, size int) *LogBuffer {
+	return &LogBuffer{
+		next: next,
+		ring: &ring{size: size},
+	}
+}
+
+// Enabled reports whether the wrapped handler handles the level.
+// Debug records are always captured so crash reports have full context.
+func (b *LogBuffer) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+// Handle records the log line and forwards it to the wrapped handler
+func (b *LogBuffer) Handle(ctx context.Context, record slog.Record) error {
+	var sb strings.Builder
+	sb.WriteString(record.Time.Format(time.TimeOnly))
+	sb.WriteString(" ")
+	sb.WriteString(record.Level.String())
+	sb.WriteString(" ")
+	sb.WriteString(record.Message)
+	for _, attr := range b.attrs {
+		b.writeAttr(&sb, attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		b.writeAttr(&sb, attr)
+		return true
+	})
+	b.ring.add(sb.String())
+
+	if !b.next.Enabled(ctx, record.Level) {
+		return nil
+	}
+	return b.next.Handle(ctx, record)
+}
+
+// WithAttrs returns a handler sharing the same buffer with extra attributes
+func (b *LogBuffer) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogBuffer{
+		next:  b.next.WithAttrs(attrs),
+		ring:  b.ring,
+		attrs: append(append([]slog.Attr{}, b.attrs...), attrs...),
+		group: b.group,
+	}
+}
+
+// WithGroup returns a handler sharing the same buffer within a group
+func (b *LogBuffer) WithGroup(name string) slog.Handler {
+	group := name
+	if b.group != "" {
+		group = b.group + "." + name
+	}
+	return &LogBuffer{
+		next:  b.next.WithGroup(name),
+		ring:  b.ring,
+		attrs: b.attrs,
+		group: group,
+	}
+}
+
+// Lines returns the buffered log lines, oldest first
+func (b *LogBuffer) Lines() []string {
+	b.ring.mu.Lock()
+	defer b.ring.mu.Unlock()
+	return append([]string{}, b.ring.lines...)
+}
+
+// writeAttr appends a key=value pair, qualified by the handler's group
+func (b *LogBuffer) writeAttr(sb *strings.Builder, attr slog.Attr) {
+	key := attr.Key
+	if b.group != "" {
+		key = b.group + "." + key
+	}
+	sb.WriteString(fmt.Sprintf(" %s=%v", key, attr.Value))
+}
+
+// add appends a line, dropping the oldest when full
+func (r *ring) add(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, line)
+	if len(r.lines) > r.size {
+		r.lines = r.lines[len(r.lines)-r.size:]
+	}
+}
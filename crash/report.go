@@ -0,0 +1,89 @@
+package crash
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IssuesURL is where new issues are filed
+const IssuesURL = "https://github.com/jshiv/bubblechess/issues/new"
+
+// BugReport is what chess report-bug bundles for an issue: the last
+// session's transcript, the config files and the environment
+type BugReport struct {
+	Transcript  []byte            // the last session's transcript, if there is one
+	Configs     map[string][]byte // config files by name, redacted when written
+	Environment map[string]string // versions and terminal info
+}
+
+// WriteArchive writes the report to path as a gzipped tar archive
+func (r *BugReport) WriteArchive(path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bug report: %w", err)
+	}
+	defer file.Close()
+
+	files := map[string][]byte{"environment.txt": []byte(r.Summary())}
+	if len(r.Transcript) > 0 {
+		files["transcript.jsonl"] = r.Transcript
+	}
+	for name, config := range r.Configs {
+		files[name] = RedactJSON(config)
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	compressed := gzip.NewWriter(file)
+	archive := tar.NewWriter(compressed)
+	now := time.Now()
+	for _, name := range names {
+		header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(files[name])), ModTime: now}
+		if err := archive.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := archive.Write(files[name]); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write bug report: %w", err)
+	}
+	if err := compressed.Close(); err != nil {
+		return fmt.Errorf("failed to write bug report: %w", err)
+	}
+	return file.Close()
+}
+
+// Summary lists the environment as sorted "name: value" lines
+func (r *BugReport) Summary() string {
+	names := make([]string, 0, len(r.Environment))
+	for name := range r.Environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", name, r.Environment[name]))
+	}
+	return sb.String()
+}
+
+// IssueURL returns a link to a new issue titled title, its body prefilled
+// with the environment and a reminder to attach the archive
+func (r *BugReport) IssueURL(title, archive string) string {
+	body := fmt.Sprintf("**What happened**\n\n\n**What you expected**\n\n\n**Environment**\n\n```\n%s```\n\nPlease attach %s, the bug report archive.\n",
+		r.Summary(), archive)
+	query := url.Values{"title": {title}, "body": {body}}
+	return IssuesURL + "?" + query.Encode()
+}
@@ -0,0 +1,179 @@
+// Package errkind sorts the errors of a game against the AI into the few
+// kinds a player can do something about: an illegal move, a board that
+// drifted out of sync with the server's, a model that took too long or
+// couldn't be reached, and a reply that wasn't understood. The engine, the
+// AI server and its client all mark their errors with a kind, and the A2A
+// server sends each kind with its own JSON-RPC code, so the client gets the
+// same kind back and the TUI can say what to do about it.
+package errkind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// Kind is a category of error. A Kind is itself an error, so
+// errors.Is(err, errkind.Desync) reports whether err is of that kind.
+type Kind string
+
+// The kinds of error
+const (
+	IllegalMove         Kind = "illegal_move"         // a move the position doesn't allow
+	Desync              Kind = "desync"               // the client's and server's boards differ
+	ProviderTimeout     Kind = "provider_timeout"     // the model didn't answer in time
+	ProviderUnavailable Kind = "provider_unavailable" // the server or its model couldn't be reached
+	ProtocolError       Kind = "protocol_error"       // a request or reply that wasn't understood
+)
+
+// Kinds lists every kind
+var Kinds = []Kind{IllegalMove, Desync, ProviderTimeout, ProviderUnavailable, ProtocolError}
+
+// JSON-RPC error codes of the kinds; a protocol error is sent as the
+// standard invalid request error
+const (
+	CodeDesync              = -32010
+	CodeIllegalMove         = -32012
+	CodeProviderTimeout     = -32013
+	CodeProviderUnavailable = -32014
+	CodeProtocolError       = -32600
+)
+
+// Error describes the kind, e.g. "provider timeout"
+func (k Kind) Error() string {
+	switch k {
+	case IllegalMove:
+		return "illegal move"
+	case Desync:
+		return "board desync"
+	case ProviderTimeout:
+		return "provider timeout"
+	case ProviderUnavailable:
+		return "provider unavailable"
+	case ProtocolError:
+		return "protocol error"
+	}
+	return string(k)
+}
+
+// Message returns the kind's JSON-RPC error message, e.g. "Board desync"
+func (k Kind) Message() string {
+	text := k.Error()
+	if text == "" {
+		return text
+	}
+	return strings.ToUpper(text[:1]) + text[1:]
+}
+
+// Code returns the kind's JSON-RPC error code, or 0 for an unknown kind
+func (k Kind) Code() int {
+	switch k {
+	case IllegalMove:
+		return CodeIllegalMove
+	case Desync:
+		return CodeDesync
+	case ProviderTimeout:
+		return CodeProviderTimeout
+	case ProviderUnavailable:
+		return CodeProviderUnavailable
+	case ProtocolError:
+		return CodeProtocolError
+	}
+	return 0
+}
+
+// FromCode returns the kind a JSON-RPC error code stands for. The standard
+// parse, request, method and params errors are protocol errors; the
+// internal error and codes of no kind return "".
+func FromCode(code int) Kind {
+	switch code {
+	case CodeIllegalMove:
+		return IllegalMove
+	case CodeDesync:
+		return Desync
+	case CodeProviderTimeout:
+		return ProviderTimeout
+	case CodeProviderUnavailable:
+		return ProviderUnavailable
+	case -32700, -32600, -32601, -32602:
+		return ProtocolError
+	}
+	return ""
+}
+
+// Error is an error marked with its kind
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+// Error describes the error, without its kind
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error marked
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the error's kind
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(Kind)
+	return ok && kind == e.Kind
+}
+
+// New returns an error of kind with text, for sentinel errors such as
+// notation.ErrIllegal
+func New(kind Kind, text string) error {
+	return &Error{Kind: kind, Err: errors.New(text)}
+}
+
+// Errorf formats an error of kind; %w wraps as in fmt.Errorf
+func Errorf(kind Kind, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap marks err with kind. A nil err, or an empty kind, leaves err as it is.
+func Wrap(kind Kind, err error) error {
+	if err == nil || kind == "" {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// Of returns err's kind: the kind it was marked with, or for errors from
+// the network and contexts, a provider timeout or an unreachable provider.
+// Errors of no kind, including nil and cancellations, return "".
+func Of(err error) Kind {
+	if err == nil {
+		return ""
+	}
+	var marked *Error
+	if errors.As(err, &marked) {
+		return marked.Kind
+	}
+	for _, kind := range Kinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ProviderTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ProviderTimeout
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.As(err, &dnsErr) || (errors.As(err, &opErr) && opErr.Op == "dial") {
+		return ProviderUnavailable
+	}
+	return ""
+}
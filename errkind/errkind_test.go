@@ -0,0 +1,62 @@
+package errkind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	dial := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	tests := []struct {
+		name string
+		err  error
+		want Kind
+	}{
+		{"nil", nil, ""},
+		{"plain", errors.New("boom"), ""},
+		{"marked", fmt.Errorf("move: %w", New(IllegalMove, "illegal move")), IllegalMove},
+		{"kind", fmt.Errorf("resync: %w", Desync), Desync},
+		{"deadline", fmt.Errorf("generate: %w", context.DeadlineExceeded), ProviderTimeout},
+		{"canceled", context.Canceled, ""},
+		{"dial", fmt.Errorf("request: %w", dial), ProviderUnavailable},
+		{"wrapped twice", Wrap(ProtocolError, Wrap(ProviderTimeout, errors.New("x"))), ProtocolError},
+	}
+	for _, tt := range tests {
+		if got := Of(tt.err); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestIs(t *testing.T) {
+	err := fmt.Errorf("failed to call ollama: %w", Errorf(ProviderUnavailable, "status %d", 503))
+	if !errors.Is(err, ProviderUnavailable) || errors.Is(err, ProviderTimeout) {
+		t.Errorf("Expected only ProviderUnavailable, got %q", Of(err))
+	}
+	if err.Error() != "failed to call ollama: status 503" {
+		t.Errorf("Expected the kind left out of the text, got %q", err.Error())
+	}
+	if Wrap(IllegalMove, nil) != nil {
+		t.Errorf("Expected wrapping nil to give nil")
+	}
+}
+
+func TestCodes(t *testing.T) {
+	for _, kind := range Kinds {
+		if got := FromCode(kind.Code()); got != kind {
+			t.Errorf("Expected code %d to stand for %s, got %q", kind.Code(), kind, got)
+		}
+	}
+	if FromCode(-32603) != "" {
+		t.Errorf("Expected the internal error to have no kind")
+	}
+	if FromCode(-32601) != ProtocolError {
+		t.Errorf("Expected method not found to be a protocol error")
+	}
+	if ProviderTimeout.Message() != "Provider timeout" {
+		t.Errorf("Expected Provider timeout, got %q", ProviderTimeout.Message())
+	}
+}
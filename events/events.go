@@ -0,0 +1,136 @@
+// Package events is a small in-process event bus. A game publishes what
+// happens in it, such as moves, checks and its end, and features like the
+// game log, webhooks and sound notifications subscribe to the events they
+// need instead of being called from the TUI's Update function.
+package events
+
+import (
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Kind names what happened
+type Kind string
+
+const (
+	// MoveMade is published after every move played on the board
+	MoveMade Kind = "move_made"
+	// CheckGiven is published after a move that puts the other king in check
+	CheckGiven Kind = "check_given"
+	// GameEnded is published once when the game reaches a result
+	GameEnded Kind = "game_ended"
+	// ClockExpired is published when a side runs out of time
+	ClockExpired Kind = "clock_expired"
+	// AIThinkingStarted is published when the AI is asked for its move
+	AIThinkingStarted Kind = "ai_thinking_started"
+	// AIMoveRejected is published when the AI answers with a move that
+	// can't be played; it is asked once more
+	AIMoveRejected Kind = "ai_move_rejected"
+	// AIError is published when a request for the AI's move fails
+	AIError Kind = "ai_error"
+)
+
+// Event is one thing that happened in a game. Fields that don't apply to
+// the kind are left empty.
+type Event struct {
+	Kind   Kind      `json:"kind"`
+	Time   time.Time `json:"time"`
+	GameID string    `json:"game_id,omitempty"` // the game it happened in, to tell concurrent games apart
+	Color  string    `json:"color,omitempty"`   // the side that moved, gave check, ran out of time or is thinking
+	Move   string    `json:"move,omitempty"`    // the move in SAN
+	FEN    string    `json:"fen,omitempty"`     // the position after the event
+
+	// Remote is set for moves made by the AI or a networked opponent
+	Remote bool `json:"remote,omitempty"`
+
+	// Result and Method describe how the game ended, e.g. "1-0" by "checkmate"
+	Result string `json:"result,omitempty"`
+	Method string `json:"method,omitempty"`
+
+	// Reasoning is the AI's summary of its thinking, on its moves
+	Reasoning string `json:"reasoning,omitempty"`
+
+	// Error says why the AI's move was rejected or its request failed, and
+	// ErrorKind what kind of error it was, e.g. "provider_timeout"
+	Error     string `json:"error,omitempty"`
+	ErrorKind string `json:"error_kind,omitempty"`
+}
+
+// Handler receives events. It is called on the publisher's goroutine, so a
+// handler with slow work to do, such as a network call, hands it off.
+type Handler func(Event)
+
+// subscription is a handler and the kinds it receives; none means all
+type subscription struct {
+	id      int
+	kinds   []Kind
+	handler Handler
+}
+
+// Bus delivers published events to its subscribers
+type Bus struct {
+	mu     sync.Mutex
+	nextID int
+	subs   []subscription
+	gameID string
+}
+
+// NewGameID returns a random version 4 UUID to identify a game by in logs,
+// events, AI requests and saved games
+func NewGameID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// NewBus creates a bus with no subscribers
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe calls handler with each published event of the given kinds, or
+// of every kind if none are given. The returned function unsubscribes.
+func (b *Bus) Subscribe(handler Handler, kinds ...Kind) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, subscription{id: id, kinds: kinds, handler: handler})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.subs = slices.DeleteFunc(b.subs, func(sub subscription) bool { return sub.id == id })
+	}
+}
+
+// SetGameID sets the ID of the game the bus's events are published for
+func (b *Bus) SetGameID(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.gameID = id
+}
+
+// Publish delivers event to its subscribers in the order they subscribed,
+// stamping it with the current time and the game's ID if it has none
+func (b *Bus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	b.mu.Lock()
+	if event.GameID == "" {
+		event.GameID = b.gameID
+	}
+	subs := slices.Clone(b.subs)
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		if len(sub.kinds) == 0 || slices.Contains(sub.kinds, event.Kind) {
+			sub.handler(event)
+		}
+	}
+}
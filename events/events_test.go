@@ -0,0 +1,151 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBusDeliversSubscribedKinds(t *testing.T) {
+	bus := NewBus()
+	var all, ends []Kind
+	bus.Subscribe(func(e Event) { all = append(all, e.Kind) })
+	unsubscribe := bus.Subscribe(func(e Event) { ends = append(ends, e.Kind) }, GameEnded)
+
+	bus.Publish(Event{Kind: MoveMade})
+	bus.Publish(Event{Kind: GameEnded})
+	unsubscribe()
+	bus.Publish(Event{Kind: GameEnded})
+
+	if len(all) != 3 {
+		t.Errorf("Expected 3 events for the catch-all subscriber, got %v", all)
+	}
+	if len(ends) != 1 || ends[0] != GameEnded {
+		t.Errorf("Expected one GameEnded before unsubscribing, got %v", ends)
+	}
+}
+
+func TestBusStampsTime(t *testing.T) {
+	bus := NewBus()
+	var got Event
+	bus.Subscribe(func(e Event) { got = e })
+	bus.Publish(Event{Kind: MoveMade})
+	if got.Time.IsZero() {
+		t.Error("Expected the event to be stamped with the time")
+	}
+}
+
+func TestBellRingsForOpponent(t *testing.T) {
+	var out bytes.Buffer
+	bell := Bell(&out)
+	bell(Event{Kind: MoveMade})
+	bell(Event{Kind: AIThinkingStarted})
+	if out.Len() != 0 {
+		t.Errorf("Expected no bell for the player's own move, got %q", out.String())
+	}
+	bell(Event{Kind: MoveMade, Remote: true})
+	bell(Event{Kind: GameEnded})
+	if out.String() != "\a\a" {
+		t.Errorf("Expected 2 bells, got %q", out.String())
+	}
+}
+
+func TestWebhookPostsEvent(t *testing.T) {
+	received := make(chan Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("Expected a JSON event, got %v", err)
+		}
+		received <- event
+	}))
+	defer server.Close()
+
+	Webhook(server.URL, nil)(Event{Kind: GameEnded, Result: "1-0", Method: "checkmate"})
+
+	select {
+	case event := <-received:
+		if event.Kind != GameEnded || event.Result != "1-0" {
+			t.Errorf("Expected the game's end, got %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the webhook to receive the event")
+	}
+}
+
+func TestBusStampsGameID(t *testing.T) {
+	bus := NewBus()
+	id := NewGameID()
+	if len(id) != 36 || id[14] != '4' || id == NewGameID() {
+		t.Fatalf("Expected a fresh version 4 UUID, got %q", id)
+	}
+	bus.SetGameID(id)
+	var got []string
+	bus.Subscribe(func(e Event) { got = append(got, e.GameID) })
+	bus.Publish(Event{Kind: MoveMade})
+	bus.Publish(Event{Kind: MoveMade, GameID: "other"})
+	if len(got) != 2 || got[0] != id || got[1] != "other" {
+		t.Errorf("Expected [%s other], got %v", id, got)
+	}
+}
+
+func TestCommandHookGetsEventOnStdin(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "events.jsonl")
+	bus := NewBus()
+	Hooks{OnMove: "cat >> " + out + "; echo $BUBBLECHESS_EVENT >> " + out}.Subscribe(context.Background(), bus)
+
+	bus.Publish(Event{Kind: MoveMade, Move: "e4"})
+	bus.Publish(Event{Kind: GameEnded, Result: "1-0"})
+	bus.Publish(Event{Kind: MoveMade, Move: "e5"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	var lines []string
+	for time.Now().Before(deadline) {
+		data, _ := os.ReadFile(out)
+		if lines = strings.Fields(string(data)); len(lines) == 4 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(lines) != 4 {
+		t.Fatalf("Expected two moves and their kinds, got %q", lines)
+	}
+	var first Event
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil || first.Move != "e4" {
+		t.Errorf("Expected e4 first as JSON, got %s (%v)", lines[0], err)
+	}
+	if lines[1] != "move_made" || !strings.Contains(lines[2], `"e5"`) {
+		t.Errorf("Expected the hook to run in order for moves only, got %q", lines)
+	}
+}
+
+func TestUnsubscribedHooksRunQueuedEventsOnly(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "moves")
+	bus := NewBus()
+	unsubscribe := Hooks{OnMove: "sleep 0.05; cat >> " + out}.Subscribe(context.Background(), bus)
+
+	bus.Publish(Event{Kind: MoveMade, Move: "e4"})
+	unsubscribe()
+	unsubscribe()
+	bus.Publish(Event{Kind: MoveMade, Move: "e5"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if data, _ := os.ReadFile(out); len(data) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	data, _ := os.ReadFile(out)
+	if !strings.Contains(string(data), `"e4"`) || strings.Contains(string(data), `"e5"`) {
+		t.Errorf("Expected only the move queued before unsubscribing, got %s", data)
+	}
+}
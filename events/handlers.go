@@ -0,0 +1,77 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"chess-tui/netconfig"
+)
+
+// webhookTimeout bounds each webhook delivery
+const webhookTimeout = 10 * time.Second
+
+// Log returns a handler that writes each event to logger at debug level,
+// which keeps it out of the way of the TUI unless debugging
+func Log(logger *slog.Logger) Handler {
+	return func(event Event) {
+		logger.Debug("Game event", "game_id", event.GameID, "kind", event.Kind, "color", event.Color, "move", event.Move,
+			"result", event.Result, "method", event.Method, "fen", event.FEN, "error", event.Error, "error_kind", event.ErrorKind)
+	}
+}
+
+// Bell returns a handler that rings the terminal bell on w when the player's
+// attention is needed: after the opponent's move, a check, a flag fall, a
+// failed AI request or the end of the game
+func Bell(w io.Writer) Handler {
+	return func(event Event) {
+		if event.Kind == AIThinkingStarted || event.Kind == AIMoveRejected || (event.Kind == MoveMade && !event.Remote) {
+			return
+		}
+		io.WriteString(w, "\a")
+	}
+}
+
+// Webhook returns a handler that posts each event as JSON to url. Posts are
+// made in the background; failures are logged and not retried.
+func Webhook(url string, client *http.Client) Handler {
+	if client == nil {
+		client = netconfig.NewClient(webhookTimeout)
+	}
+	return func(event Event) {
+		go func() {
+			if err := postEvent(client, url, event); err != nil {
+				slog.Warn("Failed to deliver game event to webhook", "url", url, "kind", event.Kind, "error", err)
+			}
+		}()
+	}
+}
+
+// postEvent posts one event to a webhook
+func postEvent(client *http.Client, url string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post event: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
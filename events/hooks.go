@@ -0,0 +1,126 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hookTimeout bounds each run of a hook's command
+const hookTimeout = 10 * time.Second
+
+// hookQueue is how many events a hook holds while its command is still
+// running; events beyond it are dropped rather than holding up the game
+const hookQueue = 64
+
+// Hooks are shell commands run on game events, for integrations such as
+// sounds, stream overlays and loggers. Each is given the event as JSON on
+// stdin and its kind in $BUBBLECHESS_EVENT.
+type Hooks struct {
+	OnMove       string `json:"on_move,omitempty"`        // after every move
+	OnGameEnd    string `json:"on_game_end,omitempty"`    // once the game has a result
+	OnAIThinking string `json:"on_ai_thinking,omitempty"` // when the AI is asked for its move
+}
+
+// Subscribe subscribes the hooks that are set to bus, running their
+// commands until ctx ends. The returned function unsubscribes them and
+// stops their commands once the events already queued have run.
+func (h Hooks) Subscribe(ctx context.Context, bus *Bus) (unsubscribe func()) {
+	var stops []func()
+	for kind, command := range map[Kind]string{
+		MoveMade:          h.OnMove,
+		GameEnded:         h.OnGameEnd,
+		AIThinkingStarted: h.OnAIThinking,
+	} {
+		if strings.TrimSpace(command) != "" {
+			handler, stop := Command(ctx, command)
+			stops = append(stops, bus.Subscribe(handler, kind), stop)
+		}
+	}
+	return func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}
+}
+
+// Command returns a handler that runs command in the shell for each event,
+// with the event as JSON on its stdin. The commands run in the background,
+// one at a time and in the order of the events; failures are logged. They
+// stop when ctx ends, or when stop is called and the queued events have run;
+// events handled after that are dropped.
+func Command(ctx context.Context, command string) (handler Handler, stop func()) {
+	queue := make(chan Event, hookQueue)
+	stopped := make(chan struct{})
+	run := func(event Event) {
+		if err := runCommand(ctx, command, event); err != nil {
+			slog.Warn("Game event hook failed", "command", command, "kind", event.Kind, "error", err)
+		}
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-queue:
+				run(event)
+			case <-stopped:
+				for {
+					select {
+					case event := <-queue:
+						run(event)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+	handler = func(event Event) {
+		select {
+		case <-stopped:
+			return
+		default:
+		}
+		select {
+		case queue <- event:
+		default:
+			slog.Warn("Game event hook is falling behind, dropping event", "command", command, "kind", event.Kind)
+		}
+	}
+	return handler, sync.OnceFunc(func() { close(stopped) })
+}
+
+// runCommand runs a hook's command for one event, killing it if ctx ends
+func runCommand(ctx context.Context, command string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	cmd.Stdin = bytes.NewReader(append(body, '\n'))
+	cmd.Env = append(os.Environ(), "BUBBLECHESS_EVENT="+string(event.Kind))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Package fairplay looks for signs of outside help in finished games: how
+// often a player's moves match an engine's, and how evenly they spend their
+// time. Neither proves anything; a flag asks an operator to look at the game.
+package fairplay
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"chess-tui/ai_player"
+	"chess-tui/gamedb"
+	"chess-tui/notation"
+	"chess-tui/tournament"
+
+	"github.com/notnil/chess"
+)
+
+// Heuristics behind the flags
+const (
+	// OpeningMoves of each side are skipped, since they're often known by heart
+	OpeningMoves = 8
+	// MinMoves is how many moves a side must have counted before it's judged
+	MinMoves = 15
+	// MatchThreshold is the share of engine moves that flags a side
+	MatchThreshold = 0.9
+	// SpreadThreshold flags a side whose move times vary less than this,
+	// as a fraction of their mean. People think longer about hard moves;
+	// a program relaying an engine's moves often doesn't.
+	SpreadThreshold = 0.2
+)
+
+// Flags a side can be given
+const (
+	FlagEngineMatch   = "engine_match"
+	FlagUniformTiming = "uniform_timing"
+)
+
+// Side is how one player's moves looked
+type Side struct {
+	Player     string   `json:"player"`
+	Moves      int      `json:"moves"`   // counted: past the opening, with more than one legal move
+	Matches    int      `json:"matches"` // of the counted moves, those the engine would have played
+	MatchRate  float64  `json:"match_rate"`
+	MeanTimeMs int64    `json:"mean_time_ms,omitempty"`
+	TimeSpread float64  `json:"time_spread,omitempty"` // standard deviation of move times over their mean
+	Flags      []string `json:"flags,omitempty"`
+}
+
+// Report is the review of one game
+type Report struct {
+	Game   string    `json:"game"`
+	Played time.Time `json:"played"`
+	Result string    `json:"result"`
+	White  Side      `json:"white"`
+	Black  Side      `json:"black"`
+}
+
+// Flagged reports whether either side looked suspicious
+func (r Report) Flagged() bool {
+	return len(r.White.Flags) > 0 || len(r.Black.Flags) > 0
+}
+
+// BuiltinEngine returns the built-in engine, which needs nothing installed.
+// It only sees one move ahead, so it flags little beyond the crudest engine
+// use; a UCI engine such as Stockfish is a far better reference.
+func BuiltinEngine() tournament.Player {
+	return builtin{}
+}
+
+// builtin adapts the built-in engine to tournament.Player
+type builtin struct{}
+
+// GetMove returns the built-in engine's move in the FEN position
+func (builtin) GetMove(boardState string, gameHistory []string) (*ai_player.ChessMove, error) {
+	return ai_player.NewEngineProvider().SelectMove(context.Background(), ai_player.MoveRequest{FEN: boardState})
+}
+
+// Analyze reviews a finished game: it replays the moves, asks engine for
+// its move in each counted position, and compares the move times in
+// record.MoveTimes, where known
+func Analyze(id string, record gamedb.Record, engine tournament.Player) (Report, error) {
+	report := Report{
+		Game:   id,
+		Played: record.Played,
+		Result: record.Result,
+		White:  Side{Player: record.White},
+		Black:  Side{Player: record.Black},
+	}
+	var times [2][]time.Duration
+
+	game := chess.NewGame()
+	if record.StartFEN != "" {
+		fen, err := chess.FEN(record.StartFEN)
+		if err != nil {
+			return Report{}, fmt.Errorf("invalid start position: %w", err)
+		}
+		game = chess.NewGame(fen)
+	}
+	for ply, san := range record.Moves {
+		position := game.Position()
+		move, err := notation.Decode(position, san)
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to replay move %d %q: %w", ply+1, san, err)
+		}
+
+		side := &report.White
+		if ply%2 == 1 {
+			side = &report.Black
+		}
+		if ply/2 >= OpeningMoves && len(position.ValidMoves()) > 1 {
+			best, err := engine.GetMove(position.String(), record.Moves[:ply])
+			if err != nil {
+				return Report{}, fmt.Errorf("failed to get engine move at ply %d: %w", ply+1, err)
+			}
+			side.Moves++
+			if played, err := notation.Normalize(position.String(), best.Notation); err == nil && played == notation.Encode(position, move) {
+				side.Matches++
+			}
+			if ply < len(record.MoveTimes) {
+				times[ply%2] = append(times[ply%2], time.Duration(record.MoveTimes[ply])*time.Millisecond)
+			}
+		}
+
+		if err := game.Move(move); err != nil {
+			return Report{}, fmt.Errorf("failed to replay move %d %q: %w", ply+1, san, err)
+		}
+	}
+
+	judge(&report.White, times[0])
+	judge(&report.Black, times[1])
+	return report, nil
+}
+
+// judge fills in a side's rates from its counted moves and times, and flags
+// it if they cross the thresholds
+func judge(side *Side, times []time.Duration) {
+	if side.Moves > 0 {
+		side.MatchRate = float64(side.Matches) / float64(side.Moves)
+	}
+	if len(times) > 0 {
+		mean, spread := timeStats(times)
+		side.MeanTimeMs = mean.Milliseconds()
+		side.TimeSpread = spread
+	}
+
+	if side.Moves < MinMoves {
+		return
+	}
+	if side.MatchRate >= MatchThreshold {
+		side.Flags = append(side.Flags, FlagEngineMatch)
+	}
+	if len(times) >= MinMoves && side.TimeSpread < SpreadThreshold {
+		side.Flags = append(side.Flags, FlagUniformTiming)
+	}
+}
+
+// timeStats returns the mean of times and their coefficient of variation
+func timeStats(times []time.Duration) (time.Duration, float64) {
+	var sum float64
+	for _, t := range times {
+		sum += t.Seconds()
+	}
+	mean := sum / float64(len(times))
+	if mean == 0 {
+		return 0, 0
+	}
+	var squares float64
+	for _, t := range times {
+		squares += (t.Seconds() - mean) * (t.Seconds() - mean)
+	}
+	return time.Duration(mean * float64(time.Second)), math.Sqrt(squares/float64(len(times))) / mean
+}
@@ -0,0 +1,120 @@
+package fairplay
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"chess-tui/ai_player"
+	"chess-tui/gamedb"
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// selfPlay plays plies moves, White the built-in engine's and Black the
+// last legal move in each position, timing White at a steady 2 seconds and
+// Black anywhere from 1 to 30
+func selfPlay(t *testing.T, plies int) gamedb.Record {
+	t.Helper()
+	record := gamedb.Record{White: "alex", Black: "sam", Result: "*"}
+	game := chess.NewGame()
+	for ply := 0; ply < plies && game.Outcome() == chess.NoOutcome; ply++ {
+		position := game.Position()
+		var move *chess.Move
+		if ply%2 == 0 {
+			best, err := BuiltinEngine().GetMove(position.String(), record.Moves)
+			if err != nil {
+				t.Fatalf("Failed to get engine move: %v", err)
+			}
+			if move, err = notation.Decode(position, best.Notation); err != nil {
+				t.Fatalf("Failed to decode engine move: %v", err)
+			}
+			record.MoveTimes = append(record.MoveTimes, 2000)
+		} else {
+			moves := position.ValidMoves()
+			move = moves[len(moves)-1]
+			record.MoveTimes = append(record.MoveTimes, int64(1000+(ply*7919)%29000))
+		}
+		record.Moves = append(record.Moves, notation.Encode(position, move))
+		game.Move(move)
+	}
+	return record
+}
+
+func TestAnalyzeFlagsEngineMoves(t *testing.T) {
+	record := selfPlay(t, 60)
+	report, err := Analyze("g1", record, BuiltinEngine())
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+
+	white := report.White
+	if white.Moves < MinMoves || white.MatchRate != 1 {
+		t.Errorf("Expected every counted White move to match the engine, got %+v", white)
+	}
+	if !slices.Equal(white.Flags, []string{FlagEngineMatch, FlagUniformTiming}) {
+		t.Errorf("Expected White flagged for engine moves and steady timing, got %v", white.Flags)
+	}
+	if white.MeanTimeMs != 2000 || white.TimeSpread != 0 {
+		t.Errorf("Expected a steady 2s a move, got %dms spread %.2f", white.MeanTimeMs, white.TimeSpread)
+	}
+	if black := report.Black; len(black.Flags) != 0 || black.TimeSpread < SpreadThreshold {
+		t.Errorf("Expected Black unflagged, got %+v", black)
+	}
+	if !report.Flagged() {
+		t.Error("Expected the game flagged")
+	}
+}
+
+func TestAnalyzeShortGames(t *testing.T) {
+	// Too few moves past the opening to judge
+	record := selfPlay(t, 30)
+	report, err := Analyze("g1", record, BuiltinEngine())
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	if report.Flagged() || report.White.Moves != 30/2-OpeningMoves {
+		t.Errorf("Expected %d counted moves and no flags, got %+v", 30/2-OpeningMoves, report.White)
+	}
+
+	// Untimed games are judged on their moves alone
+	record = selfPlay(t, 60)
+	record.MoveTimes = nil
+	if report, _ := Analyze("g2", record, BuiltinEngine()); !slices.Equal(report.White.Flags, []string{FlagEngineMatch}) || report.White.MeanTimeMs != 0 {
+		t.Errorf("Expected only the engine match flagged, got %+v", report.White)
+	}
+
+	if _, err := Analyze("g3", gamedb.Record{Moves: []string{"e4", "Ke7", "Qh5", "Kxh5"}}, BuiltinEngine()); err == nil {
+		t.Error("Expected an error for an illegal move")
+	}
+}
+
+// engineFunc makes a function an engine
+type engineFunc func(fen string) string
+
+// GetMove returns the function's move
+func (f engineFunc) GetMove(boardState string, gameHistory []string) (*ai_player.ChessMove, error) {
+	return &ai_player.ChessMove{Notation: f(boardState)}, nil
+}
+
+func TestAnalyzeAcceptsUCIMoves(t *testing.T) {
+	record := gamedb.Record{Moves: []string{"Nf3", "Nf6", "Ng1", "Ng8"}}
+	for len(record.Moves) < 2*(OpeningMoves+2) {
+		record.Moves = append(record.Moves, record.Moves[:4]...)
+	}
+	engine := engineFunc(func(fen string) string {
+		if strings.Fields(fen)[1] == "w" {
+			return "g1f3"
+		}
+		return "g8f6"
+	})
+	report, err := Analyze("g1", record, engine)
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	// Only the knights' trips out match
+	if report.White.Moves != 2 || report.White.Matches != 1 {
+		t.Errorf("Expected 1 of 2 White moves matched, got %+v", report.White)
+	}
+}
@@ -0,0 +1,82 @@
+package game
+
+import (
+	"chess-tui/netplay"
+
+	"github.com/notnil/chess"
+)
+
+// Terminations recorded in the game log besides the chess.Method names,
+// e.g. "Checkmate"
+const (
+	terminationTimeForfeit = "TimeForfeit"
+	terminationAbandoned   = "Abandoned"  // the loser left a networked game for good
+	terminationAborted     = "Aborted"    // a networked game ended without a result
+	terminationHouseRules  = "HouseRules" // a house rule such as a move limit decided the game
+)
+
+// pgnTerminations are the PGN Termination tag values of the game log's
+// terminations; the rest are "normal"
+var pgnTerminations = map[string]string{
+	terminationTimeForfeit: "time forfeit",
+	terminationAbandoned:   "abandoned",
+	terminationAborted:     "abandoned",
+	terminationHouseRules:  "adjudication",
+}
+
+// adjudicateAbandonment settles a networked game the opponent left for
+// good: before netplay.AbortPlies moves it is aborted, and after that the
+// opponent forfeits
+func (g *Game) adjudicateAbandonment() {
+	if g.spectating() || g.over() {
+		return
+	}
+	leaver := g.humanColor.Other()
+	if len(g.chessGame.Moves()) < netplay.AbortPlies {
+		g.abort(leaver.Name() + " left before the game got going")
+		return
+	}
+	g.abandoned = leaver
+	g.chessGame.Resign(leaver)
+	g.updateStatus()
+}
+
+// abort ends a networked game without a result, saying why
+func (g *Game) abort(reason string) {
+	g.log.Info("Game aborted", "reason", reason)
+	g.aborted = reason
+	g.updateStatus()
+}
+
+// over reports whether the game has ended, with a result or aborted
+func (g *Game) over() bool {
+	return g.chessGame.Outcome() != chess.NoOutcome || g.aborted != ""
+}
+
+// termination says how the game ended, as the game log records it, e.g.
+// "Checkmate", "TimeForfeit" or "Abandoned"
+func (g *Game) termination() string {
+	switch {
+	case g.aborted != "":
+		return terminationAborted
+	case g.flagged != chess.NoColor:
+		return terminationTimeForfeit
+	case g.abandoned != chess.NoColor:
+		return terminationAbandoned
+	case g.houseRule != "":
+		return terminationHouseRules
+	}
+	return g.chessGame.Method().String()
+}
+
+// pgnTermination returns the PGN Termination tag of the game, or "" while
+// it is in progress
+func (g *Game) pgnTermination() string {
+	if !g.over() {
+		return ""
+	}
+	if tag, ok := pgnTerminations[g.termination()]; ok {
+		return tag
+	}
+	return "normal"
+}
@@ -0,0 +1,174 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// pieceNames maps piece types to their spoken names
+var pieceNames = map[chess.PieceType]string{
+	chess.King:   "king",
+	chess.Queen:  "queen",
+	chess.Rook:   "rook",
+	chess.Bishop: "bishop",
+	chess.Knight: "knight",
+	chess.Pawn:   "pawn",
+}
+
+// describeMove announces a move in words, e.g. "White knight from g1 to f3, check".
+// pos is the position before the move was played.
+func describeMove(pos *chess.Position, move *chess.Move, outcome chess.Method) string {
+	board := pos.Board()
+	piece := board.Piece(move.S1())
+	side := pos.Turn().Name()
+
+	var sb strings.Builder
+	switch {
+	case move.HasTag(chess.KingSideCastle):
+		sb.WriteString(side + " castles kingside")
+	case move.HasTag(chess.QueenSideCastle):
+		sb.WriteString(side + " castles queenside")
+	case move.HasTag(chess.EnPassant):
+		sb.WriteString(fmt.Sprintf("%s pawn from %s takes pawn on %s en passant",
+			side, move.S1(), move.S2()))
+	case move.HasTag(chess.Capture):
+		captured := board.Piece(move.S2())
+		sb.WriteString(fmt.Sprintf("%s %s from %s takes %s on %s",
+			side, pieceNames[piece.Type()], move.S1(), pieceNames[captured.Type()], move.S2()))
+	default:
+		sb.WriteString(fmt.Sprintf("%s %s from %s to %s",
+			side, pieceNames[piece.Type()], move.S1(), move.S2()))
+	}
+
+	if move.Promo() != chess.NoPieceType {
+		sb.WriteString(", promotes to " + pieceNames[move.Promo()])
+	}
+
+	switch {
+	case outcome == chess.Checkmate:
+		sb.WriteString(", checkmate")
+	case move.HasTag(chess.Check):
+		sb.WriteString(", check")
+	}
+
+	return sb.String()
+}
+
+// lastMoveDescription announces the most recent move of the game, if any
+func (g *Game) lastMoveDescription() string {
+	moves := g.chessGame.Moves()
+	if len(moves) == 0 {
+		return ""
+	}
+	positions := g.chessGame.Positions()
+	before := positions[len(positions)-2]
+	return describeMove(before, moves[len(moves)-1], g.chessGame.Method())
+}
+
+// renderTextBoard renders the board with letters only, so no information is
+// conveyed by color: uppercase is White, lowercase is Black, "." is empty
+func (g *Game) renderTextBoard() string {
+	board := g.chessGame.Position().Board()
+	var sb strings.Builder
+
+	sb.WriteString("  a b c d e f g h\n")
+	for rank := 7; rank >= 0; rank-- {
+		sb.WriteString(fmt.Sprintf("%d ", rank+1))
+		for file := 0; file < 8; file++ {
+			piece := board.Piece(chess.Square(rank*8 + file))
+			switch {
+			case piece == chess.NoPiece:
+				sb.WriteString(".")
+			case piece.Color() == chess.White:
+				sb.WriteString(strings.ToUpper(piece.Type().String()))
+			default:
+				sb.WriteString(piece.Type().String())
+			}
+			if file < 7 {
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString(fmt.Sprintf(" %d\n", rank+1))
+	}
+	sb.WriteString("  a b c d e f g h")
+
+	return sb.String()
+}
+
+// renderBoardSummary renders a linear, screen-reader friendly list of pieces
+func (g *Game) renderBoardSummary() string {
+	board := g.chessGame.Position().Board()
+	order := []chess.PieceType{chess.King, chess.Queen, chess.Rook, chess.Bishop, chess.Knight, chess.Pawn}
+
+	var sb strings.Builder
+	for _, color := range []chess.Color{chess.White, chess.Black} {
+		var parts []string
+		for _, pieceType := range order {
+			var squares []string
+			for sq := 0; sq < 64; sq++ {
+				if board.Piece(chess.Square(sq)) == chess.NewPiece(pieceType, color) {
+					squares = append(squares, chess.Square(sq).String())
+				}
+			}
+			if len(squares) == 0 {
+				continue
+			}
+			name := pieceNames[pieceType]
+			if len(squares) > 1 {
+				name += "s"
+			}
+			parts = append(parts, fmt.Sprintf("%s on %s", name, strings.Join(squares, ", ")))
+		}
+		sb.WriteString(fmt.Sprintf("%s: %s.\n", color.Name(), strings.Join(parts, "; ")))
+	}
+	sb.WriteString(fmt.Sprintf("%s to move.", g.chessGame.Position().Turn().Name()))
+
+	return sb.String()
+}
+
+// accessibleView renders the game as plain text for screen readers
+func (g *Game) accessibleView() string {
+	var sb strings.Builder
+
+	sb.WriteString("Chess TUI\n\n")
+
+	if g.showSummary {
+		sb.WriteString(g.renderBoardSummary())
+	} else {
+		sb.WriteString(g.renderTextBoard())
+	}
+	sb.WriteString("\n\n")
+
+	if description := g.lastMoveDescription(); description != "" {
+		sb.WriteString("Last move: " + description + ".\n")
+	}
+	sb.WriteString("Status: " + g.status + "\n")
+	if accuracy := g.accuracyText(false); accuracy != "" {
+		sb.WriteString("Accuracy: " + accuracy + "\n")
+	}
+	if clock := g.clockText(); clock != "" {
+		sb.WriteString("Clock: " + strings.TrimPrefix(clock, "⏱ ") + "\n")
+	}
+	if g.aiReasoning != "" {
+		sb.WriteString("AI reasoning: " + g.aiReasoning + "\n")
+	}
+	if len(g.aiVotes) > 0 {
+		sb.WriteString("AI vote: " + voteSummary(g.aiVotes) + "\n")
+	}
+	if g.err != "" {
+		sb.WriteString("Error: " + g.err + "\n")
+	}
+
+	if g.isAITurn {
+		sb.WriteString("\n" + g.aiWaitingText())
+	} else {
+		sb.WriteString("\nEnter move (e.g., e4): ")
+		sb.WriteString(g.input.View())
+	}
+
+	sb.WriteString("\n\nCommands: [q]uit, [r]eset, [H]elp, [l]ist pieces or board")
+
+	return sb.String()
+}
@@ -0,0 +1,65 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLastMoveDescription(t *testing.T) {
+	g := NewGame()
+
+	if description := g.lastMoveDescription(); description != "" {
+		t.Errorf("Expected no description before any move, got '%s'", description)
+	}
+
+	moves := []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "a6", "Bxc6"}
+	for _, move := range moves {
+		if err := g.chessGame.MoveStr(move); err != nil {
+			t.Fatalf("Failed to make move '%s': %v", move, err)
+		}
+	}
+
+	expected := "White bishop from b5 takes knight on c6"
+	if description := g.lastMoveDescription(); description != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, description)
+	}
+}
+
+func TestLastMoveDescriptionCheckAndCastle(t *testing.T) {
+	g := NewGame()
+
+	for _, move := range []string{"e4", "f5", "Qh5+"} {
+		if err := g.chessGame.MoveStr(move); err != nil {
+			t.Fatalf("Failed to make move '%s': %v", move, err)
+		}
+	}
+	expected := "White queen from d1 to h5, check"
+	if description := g.lastMoveDescription(); description != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, description)
+	}
+
+	g = NewGame()
+	for _, move := range []string{"e4", "e5", "Nf3", "Nc6", "Bc4", "Bc5", "O-O"} {
+		if err := g.chessGame.MoveStr(move); err != nil {
+			t.Fatalf("Failed to make move '%s': %v", move, err)
+		}
+	}
+	if description := g.lastMoveDescription(); description != "White castles kingside" {
+		t.Errorf("Expected 'White castles kingside', got '%s'", description)
+	}
+}
+
+func TestAccessibleViewAvoidsColorOnlyBoard(t *testing.T) {
+	g := NewGameWithSettings(ModeHumanVsHuman, &Settings{Accessible: true})
+
+	view := g.View()
+	if !strings.Contains(view, "8 r n b q k b n r 8") {
+		t.Errorf("Expected letter board in accessible view, got:\n%s", view)
+	}
+
+	g.showSummary = true
+	view = g.View()
+	if !strings.Contains(view, "White: king on e1;") {
+		t.Errorf("Expected linear board summary, got:\n%s", view)
+	}
+}
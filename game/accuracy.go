@@ -0,0 +1,140 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"chess-tui/ai_player"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// accuracyWindow is how many of a side's latest moves its rolling accuracy
+// averages
+const accuracyWindow = 10
+
+// accuracyBars is how many of a side's latest moves the status bar graphs
+const accuracyBars = 6
+
+// moveAccuracy rates a move from 0 to 100 by the winning chances it gives
+// up next to the built-in engine's best move, by the formula Lichess uses
+// for its accuracy: a move as good as the best scores 100
+func moveAccuracy(position *chess.Position, move *chess.Move) float64 {
+	best := -math.MaxInt
+	for _, candidate := range position.ValidMoves() {
+		best = max(best, ai_player.ScoreMove(position, candidate))
+	}
+	played := ai_player.ScoreMove(position, move)
+	lost := 100 * (winProbability(best) - winProbability(played))
+	accuracy := 103.1668*math.Exp(-0.04354*lost) - 3.1669
+	return math.Max(0, math.Min(100, accuracy))
+}
+
+// accuracyTracker keeps the accuracy of each move of a game, rating only
+// the moves played since it last looked; a different game starts it over
+type accuracyTracker struct {
+	game  *chess.Game
+	plies []float64
+}
+
+// update rates the game's new moves and returns every move's accuracy
+func (a *accuracyTracker) update(game *chess.Game) []float64 {
+	moves := game.Moves()
+	if a.game != game || len(a.plies) > len(moves) {
+		a.game, a.plies = game, nil
+	}
+	positions := game.Positions()
+	for ply := len(a.plies); ply < len(moves); ply++ {
+		a.plies = append(a.plies, moveAccuracy(positions[ply], moves[ply]))
+	}
+	return a.plies
+}
+
+// sideAccuracy returns color's accuracies among plies, oldest first. White
+// makes the even plies, unless the game started with Black to move.
+func sideAccuracy(plies []float64, color chess.Color, firstMover chess.Color) []float64 {
+	var side []float64
+	for ply, accuracy := range plies {
+		mover := firstMover
+		if ply%2 == 1 {
+			mover = firstMover.Other()
+		}
+		if mover == color {
+			side = append(side, accuracy)
+		}
+	}
+	return side
+}
+
+// rollingAccuracy averages the last accuracyWindow accuracies
+func rollingAccuracy(accuracies []float64) float64 {
+	recent := accuracies[max(len(accuracies)-accuracyWindow, 0):]
+	total := 0.0
+	for _, accuracy := range recent {
+		total += accuracy
+	}
+	return total / float64(len(recent))
+}
+
+// accuracyBarsOf draws the last accuracyBars accuracies as a sparkline, a
+// full bar for a perfect move
+func accuracyBarsOf(accuracies []float64) string {
+	var sb strings.Builder
+	for _, accuracy := range accuracies[max(len(accuracies)-accuracyBars, 0):] {
+		level := int(accuracy / 100 * float64(len(sparklineLevels)))
+		sb.WriteRune(sparklineLevels[min(level, len(sparklineLevels)-1)])
+	}
+	return sb.String()
+}
+
+// accuracies returns each side's accuracies so far, or false before either
+// side has moved
+func (g *Game) accuracies() (map[chess.Color][]float64, bool) {
+	plies := g.accuracy.update(g.chessGame)
+	if len(plies) == 0 {
+		return nil, false
+	}
+	first := g.chessGame.Positions()[0].Turn()
+	return map[chess.Color][]float64{
+		chess.White: sideAccuracy(plies, chess.White, first),
+		chess.Black: sideAccuracy(plies, chess.Black, first),
+	}, true
+}
+
+// accuracyText states each side's rolling accuracy, e.g. "White 94%, Black
+// 81%", or "" before the first move. With bars, a sparkline of each side's
+// latest moves follows its accuracy: "White 94% ▇█▆█ · Black 81% ▅▃█▇".
+func (g *Game) accuracyText(bars bool) string {
+	sides, ok := g.accuracies()
+	if !ok {
+		return ""
+	}
+	var parts []string
+	for _, color := range []chess.Color{chess.White, chess.Black} {
+		accuracies := sides[color]
+		if len(accuracies) == 0 {
+			continue
+		}
+		part := fmt.Sprintf("%s %.0f%%", color.Name(), rollingAccuracy(accuracies))
+		if bars {
+			part += " " + accuracyBarsOf(accuracies)
+		}
+		parts = append(parts, part)
+	}
+	if bars {
+		return strings.Join(parts, " · ")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderAccuracy shows the rolling accuracies with their sparklines for the
+// status bar, or "" before the first move
+func (g *Game) renderAccuracy() string {
+	text := g.accuracyText(true)
+	if text == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render("Accuracy: " + text)
+}
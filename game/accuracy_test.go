@@ -0,0 +1,73 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestMoveAccuracy(t *testing.T) {
+	// White's queen on d4 is attacked by the knight on c6
+	option, _ := chess.FEN("r1bqkbnr/pppp1ppp/2n5/4p3/3Q4/8/PPP1PPPP/RNB1KBNR w KQkq - 0 3")
+	position := chess.NewGame(option).Position()
+	accuracy := func(uci string) float64 {
+		move, err := chess.UCINotation{}.Decode(position, uci)
+		if err != nil {
+			t.Fatalf("Expected %s to be legal, got %v", uci, err)
+		}
+		return moveAccuracy(position, move)
+	}
+
+	if got := accuracy("d4e3"); got < 99 {
+		t.Errorf("Expected saving the queen to score 100, got %.1f", got)
+	}
+	if got := accuracy("a2a3"); got > 20 {
+		t.Errorf("Expected leaving the queen to be taken to score low, got %.1f", got)
+	}
+}
+
+func TestAccuracyInStatusBar(t *testing.T) {
+	g := NewGame()
+	if g.accuracyText(true) != "" {
+		t.Errorf("Expected no accuracy before the first move, got %q", g.accuracyText(true))
+	}
+	for _, move := range []string{"e4", "e5", "Nf3", "Qg5", "Nxg5"} {
+		if err := g.applyMove(move); err != nil {
+			t.Fatalf("Expected %s to be legal, got %v", move, err)
+		}
+	}
+
+	text := g.accuracyText(true)
+	if !strings.HasPrefix(text, "White 100% ███ · Black ") {
+		t.Errorf("Expected White's three perfect moves graphed, got %q", text)
+	}
+	if sides, _ := g.accuracies(); len(sides[chess.Black]) != 2 || sides[chess.Black][1] > 50 {
+		t.Errorf("Expected Black's Qg5, hanging the queen, to score low, got %v", sides[chess.Black])
+	}
+	if !strings.Contains(g.View(), "Accuracy: White 100%") {
+		t.Errorf("Expected the accuracy in the status bar, got:\n%s", g.View())
+	}
+
+	// A new game starts the count over
+	g.resetGame()
+	if g.accuracyText(false) != "" {
+		t.Errorf("Expected no accuracy after a reset, got %q", g.accuracyText(false))
+	}
+}
+
+func TestSideAccuracy(t *testing.T) {
+	plies := []float64{100, 50, 90, 40}
+	if got := sideAccuracy(plies, chess.Black, chess.White); len(got) != 2 || got[0] != 50 || got[1] != 40 {
+		t.Errorf("Expected Black's 50 and 40, got %v", got)
+	}
+	if got := sideAccuracy(plies, chess.Black, chess.Black); len(got) != 2 || got[0] != 100 || got[1] != 90 {
+		t.Errorf("Expected Black's 100 and 90 when Black moved first, got %v", got)
+	}
+	if got := rollingAccuracy([]float64{0, 0, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100}); got != 100 {
+		t.Errorf("Expected the oldest moves to drop out of the average, got %.1f", got)
+	}
+	if got := accuracyBarsOf([]float64{0, 50, 100}); got != "▁▅█" {
+		t.Errorf("Expected ▁▅█, got %q", got)
+	}
+}
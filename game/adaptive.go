@@ -0,0 +1,91 @@
+package game
+
+import (
+	"chess-tui/ai_player"
+
+	"github.com/notnil/chess"
+)
+
+// adaptiveOpponent is a named opponent whose difficulty is retuned between
+// games so the human wins about half of them
+type adaptiveOpponent struct {
+	base   ai_player.Opponent                              // the opponent as defined
+	scores []float64                                       // the human's scores against it, oldest first
+	newAI  func(ai_player.Opponent) (MoveGenerator, error) // builds its AI at a level
+	stale  bool                                            // a game has ended since the AI was built
+}
+
+// opponent returns the opponent tuned to the human's results so far
+func (a *adaptiveOpponent) opponent() ai_player.Opponent {
+	opponent := a.base
+	difficulty := ai_player.AdaptDifficulty(a.base.Difficulty, a.scores)
+	opponent.Adapted = &difficulty
+	return opponent
+}
+
+// SetAdaptiveOpponent makes the game a match against a named opponent
+// whose difficulty follows the human's results: scores are those so far,
+// oldest first, as gamedb.HumanScores returns them, and newAI builds the
+// opponent's AI now and again at its new level before each later game
+func (g *Game) SetAdaptiveOpponent(opponent ai_player.Opponent, scores []float64, newAI func(ai_player.Opponent) (MoveGenerator, error)) error {
+	adaptive := &adaptiveOpponent{base: opponent, scores: scores, newAI: newAI}
+	tuned := adaptive.opponent()
+	generator, err := newAI(tuned)
+	if err != nil {
+		return err
+	}
+	g.SetOpponent(tuned, generator)
+	g.adaptive = adaptive
+	return nil
+}
+
+// scoreAdaptive counts the finished game towards the adaptive opponent's
+// next level, on GameEnded
+func (g *Game) scoreAdaptive() {
+	if g.adaptive == nil || g.aborted != "" {
+		return
+	}
+	score := 0.5
+	switch g.chessGame.Outcome() {
+	case chess.NoOutcome:
+		return
+	case chess.WhiteWon:
+		score = 0
+		if g.humanColor == chess.White {
+			score = 1
+		}
+	case chess.BlackWon:
+		score = 0
+		if g.humanColor == chess.Black {
+			score = 1
+		}
+	}
+	g.adaptive.scores = append(g.adaptive.scores, score)
+	g.adaptive.stale = true
+}
+
+// readapt rebuilds the adaptive opponent's AI at its new level once a game
+// has ended, keeping the old one if that fails
+func (g *Game) readapt() {
+	if g.adaptive == nil || !g.adaptive.stale {
+		return
+	}
+	tuned := g.adaptive.opponent()
+	generator, err := g.adaptive.newAI(tuned)
+	if err != nil {
+		g.log.Warn("Failed to adapt the opponent's difficulty", "error", err)
+		return
+	}
+	g.adaptive.stale = false
+	g.SetOpponent(tuned, generator)
+}
+
+// opponentLabel names the named opponent for the mode line, with the level
+// it plays at when that adapts, e.g. "🦊 Gus (adaptive: medium)"
+func (g *Game) opponentLabel() string {
+	label := g.opponent.Label()
+	if g.adaptive != nil && g.opponent.Adapted != nil {
+		label += " (adaptive: " + g.opponent.Adapted.Name + ")"
+	}
+	return label
+}
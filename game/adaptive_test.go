@@ -0,0 +1,103 @@
+package game
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chess-tui/ai_player"
+	"chess-tui/gamedb"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func TestAdaptiveOpponentGetsStrongerAfterALoss(t *testing.T) {
+	var built []ai_player.Opponent
+	newAI := func(opponent ai_player.Opponent) (MoveGenerator, error) {
+		built = append(built, opponent)
+		return &historyCheckingGenerator{}, nil
+	}
+	g := NewGameWithMode(ModeHumanVsAI)
+	if err := g.SetAdaptiveOpponent(ai_player.Opponent{Name: "Gus", Difficulty: "easy"}, nil, newAI); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	g.SetHumanColor(chess.Black)
+	if !strings.Contains(g.modeText(), "Gus (adaptive: easy)") {
+		t.Errorf("Expected the adaptive level in the mode line, got %q", g.modeText())
+	}
+
+	for _, move := range []string{"f3", "e5", "g4", "Qh4#"} {
+		if err := g.applyMove(move); err != nil {
+			t.Fatalf("Expected %s to be legal, got %v", move, err)
+		}
+		g.updateStatus()
+	}
+	g.resetGame()
+
+	if len(built) != 2 {
+		t.Fatalf("Expected the AI rebuilt for the next game, got %d builds", len(built))
+	}
+	if got := built[1].Adapted; got == nil || got.Temperature != 0.8 {
+		t.Errorf("Expected the opponent half a level stronger, got %+v", got)
+	}
+
+	g.resetGame()
+	if len(built) != 2 {
+		t.Errorf("Expected no rebuild without a game played, got %d builds", len(built))
+	}
+}
+
+func TestMenuStartsAdaptiveOpponentFromGameLog(t *testing.T) {
+	db := gamedb.Open(filepath.Join(t.TempDir(), "games.jsonl"))
+	for range 2 {
+		if err := db.Add(gamedb.Record{Opponent: "Gus", HumanColor: "white", Result: gamedb.BlackWon}); err != nil {
+			t.Fatalf("Failed to add game: %v", err)
+		}
+	}
+
+	var built ai_player.Opponent
+	menu := NewMenu()
+	menu.SetGameDB(db)
+	menu.SetOpponents([]ai_player.Opponent{{Name: "Gus", Difficulty: "medium"}}, nil,
+		func(opponent ai_player.Opponent) (MoveGenerator, error) {
+			built = opponent
+			return &historyCheckingGenerator{}, nil
+		})
+	if view := menu.View(); !strings.Contains(view, "Gus (medium) — no games yet — adaptive") {
+		t.Errorf("Expected the opponent marked adaptive, got:\n%s", view)
+	}
+	for range menu.modes {
+		menu.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if model, _ := menu.Update(tea.KeyMsg{Type: tea.KeyEnter}); model == menu {
+		t.Fatal("Expected a game to start")
+	}
+	if built.Adapted == nil || built.Adapted.Name != "easy" {
+		t.Errorf("Expected two losses to bring Gus down to easy, got %+v", built.Adapted)
+	}
+}
+
+func TestFixedDifficultyOptsOut(t *testing.T) {
+	var built ai_player.Opponent
+	menu := NewMenuWithSettings(&Settings{FixedDifficulty: true})
+	menu.SetOpponents([]ai_player.Opponent{{Name: "Gus", Difficulty: "hard"}}, nil,
+		func(opponent ai_player.Opponent) (MoveGenerator, error) {
+			built = opponent
+			return &historyCheckingGenerator{}, nil
+		})
+	if view := menu.View(); strings.Contains(view, "adaptive") {
+		t.Errorf("Expected no adaptive marker, got:\n%s", view)
+	}
+	for range menu.modes {
+		menu.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	model, _ := menu.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	g, ok := model.(*Game)
+	if !ok {
+		t.Fatalf("Expected a game to start, got %T", model)
+	}
+	if built.Adapted != nil || g.adaptive != nil {
+		t.Errorf("Expected Gus to play at the fixed difficulty, got %+v", built.Adapted)
+	}
+}
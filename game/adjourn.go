@@ -0,0 +1,233 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"chess-tui/gamedb"
+	"chess-tui/notation"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// adjournedGame is what the player who sealed a move needs to resume the game
+type adjournedGame struct {
+	path     string
+	code     string
+	sealedBy chess.Color
+}
+
+// startAdjourn opens the prompt for the move the side to move seals to
+// adjourn the game
+func (g *Game) startAdjourn() tea.Cmd {
+	switch {
+	case g.settings.Remote:
+		g.status = "Adjourning isn't available over SSH"
+		return nil
+	case g.peer != nil:
+		g.status = "A networked game can't be adjourned"
+		return nil
+	case g.chessGame.Outcome() != chess.NoOutcome:
+		g.status = "The game is over"
+		return nil
+	case g.isAITurn:
+		g.status = "Only a player whose move it is can adjourn"
+		return nil
+	}
+	g.sealInput = newSealInput("move to seal")
+	return textinput.Blink
+}
+
+// newSealInput returns a prompt that hides what is typed, for the sealed
+// move and its code
+func newSealInput(placeholder string) *textinput.Model {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.EchoMode = textinput.EchoPassword
+	input.CharLimit = 24
+	input.Width = 24
+	input.Focus()
+	return &input
+}
+
+// updateSeal handles a key while the sealed move or the seal code is being
+// typed. Adjourning can be called off with esc; a resumed game waits for
+// the code.
+func (g *Game) updateSeal(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		g.shutdown()
+		return tea.Quit
+	case "esc":
+		if g.sealed == nil {
+			g.sealInput = nil
+			g.status = "Adjournment called off"
+		}
+		return nil
+	case "enter":
+		value := strings.TrimSpace(g.sealInput.Value())
+		g.sealInput.SetValue("")
+		if g.sealed != nil {
+			return g.openSeal(value)
+		}
+		g.sealMove(value)
+		return nil
+	}
+	var cmd tea.Cmd
+	*g.sealInput, cmd = g.sealInput.Update(msg)
+	return cmd
+}
+
+// sealMove seals the side to move's move and saves the adjourned game
+func (g *Game) sealMove(text string) {
+	san, err := notation.Normalize(g.getBoardState(), text)
+	if err != nil {
+		g.err = notation.Explain(err)
+		return
+	}
+	seal, code, err := gamedb.SealMove(san)
+	if err != nil {
+		g.err = err.Error()
+		return
+	}
+
+	mover := g.chessGame.Position().Turn()
+	white, black := g.playerNames()
+	adjournment := gamedb.Adjournment{
+		ID:       g.id,
+		White:    white,
+		Black:    black,
+		StartFEN: g.startFEN,
+		Moves:    g.sanMoves(),
+		SealedBy: colorName(mover),
+		Seal:     seal,
+	}
+	if g.gameMode == ModeHumanVsAI {
+		adjournment.HumanColor = colorName(g.humanColor)
+	}
+	path, err := gamedb.SaveAdjournment(g.settings.AdjournedDir, adjournment)
+	if err != nil {
+		g.err = err.Error()
+		return
+	}
+
+	g.log.Info("Game adjourned", "path", path, "sealed_by", colorName(mover))
+	g.err = ""
+	g.sealInput = nil
+	g.adjourned = &adjournedGame{path: path, code: code, sealedBy: mover}
+}
+
+// ResumeAdjournment loads a game adjourned with a sealed move. It starts by
+// asking for the seal code, then plays the sealed move and carries on as
+// the game did, keeping its ID. The file is removed once the move is opened.
+func ResumeAdjournment(path string, settings *Settings) (*Game, error) {
+	adjournment, err := gamedb.LoadAdjournment(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := ModeHumanVsHuman
+	if adjournment.HumanColor != "" {
+		mode = ModeHumanVsAI
+	}
+	g := NewGameWithSettings(mode, settings)
+	if adjournment.HumanColor == "black" {
+		g.humanColor = chess.Black
+	}
+	if adjournment.StartFEN != "" {
+		if err := g.SetStartPosition(adjournment.StartFEN); err != nil {
+			return nil, err
+		}
+	}
+	for i, san := range adjournment.Moves {
+		if err := g.applyMove(san); err != nil {
+			return nil, fmt.Errorf("failed to replay move %d (%s): %w", i+1, san, err)
+		}
+		g.gameHistory = append(g.gameHistory, san)
+	}
+	if colorName(g.chessGame.Position().Turn()) != adjournment.SealedBy {
+		return nil, fmt.Errorf("the sealed move is %s's but %s is to move", adjournment.SealedBy, colorName(g.chessGame.Position().Turn()))
+	}
+	if adjournment.ID != "" {
+		g.setGameID(adjournment.ID)
+	}
+
+	g.isAITurn = false
+	g.aiMovePending = false
+	g.sealed = &adjournment
+	g.sealedPath = path
+	g.sealInput = newSealInput("seal code")
+	g.startClock()
+	g.updateStatus()
+	g.status = fmt.Sprintf("Resuming: %s's sealed move waits for the seal code", capitalize(adjournment.SealedBy))
+	return g, nil
+}
+
+// openSeal opens the sealed move of a resumed game with the code and plays it
+func (g *Game) openSeal(code string) tea.Cmd {
+	move, err := g.sealed.Seal.Open(code)
+	if err != nil {
+		g.err = err.Error()
+		return nil
+	}
+
+	g.err = ""
+	g.sealed = nil
+	g.sealInput = nil
+	if err := os.Remove(g.sealedPath); err != nil && !os.IsNotExist(err) {
+		g.log.Warn("Failed to remove adjourned game", "path", g.sealedPath, "error", err)
+	}
+	g.makeMove(move)
+	if g.err != "" {
+		return nil
+	}
+	sans := g.sanMoves()
+	g.status = "Sealed move opened: " + plyLabel(len(sans), sans) + " — " + g.status
+	return g.takeAITurn()
+}
+
+// sealView is shown while a move is being sealed or opened, and once the
+// game is adjourned
+func (g *Game) sealView() string {
+	var sb strings.Builder
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00AAFF"))
+	noteStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	if g.adjourned != nil {
+		sb.WriteString(headerStyle.Render(fmt.Sprintf("Game adjourned — %s's move is sealed", g.adjourned.sealedBy.Name())) + "\n\n")
+		sb.WriteString("Seal code: " + lipgloss.NewStyle().Bold(true).Render(g.adjourned.code) + "\n")
+		sb.WriteString(noteStyle.Render("Keep it to yourself: the move can only be opened with it") + "\n\n")
+		sb.WriteString("Saved to " + g.adjourned.path + "\n")
+		sb.WriteString("Resume with: chess resume " + g.adjourned.path + "\n\n")
+		sb.WriteString(noteStyle.Render("Press any key to quit"))
+		return sb.String()
+	}
+
+	switch {
+	case g.settings.Accessible:
+	case g.settings.Phone:
+		sb.WriteString(g.renderCompactBoard() + "\n\n")
+	default:
+		sb.WriteString(g.renderBoard() + "\n\n")
+	}
+	if g.sealed != nil {
+		sb.WriteString(headerStyle.Render(g.status) + "\n")
+		sb.WriteString(noteStyle.Render(fmt.Sprintf("%s, type the seal code you were given when adjourning", capitalize(g.sealed.SealedBy))) + "\n")
+	} else {
+		sb.WriteString(headerStyle.Render(fmt.Sprintf("%s seals a move to adjourn the game", g.chessGame.Position().Turn().Name())) + "\n")
+		sb.WriteString(noteStyle.Render("Your opponent should look away; the move is hidden as you type it") + "\n")
+	}
+	if g.err != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+g.err) + "\n")
+	}
+	prompt := "Sealed move (enter to seal, esc to cancel): "
+	if g.sealed != nil {
+		prompt = "Seal code: "
+	}
+	sb.WriteString("\n" + prompt + g.sealInput.View())
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package game
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func TestAdjournAndResume(t *testing.T) {
+	settings := DefaultSettings()
+	settings.AdjournedDir = t.TempDir()
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+	g.makeMove("e4")
+	g.makeMove("e5")
+
+	g.Update(tea.KeyMsg{Type: tea.KeyCtrlA})
+	if g.sealInput == nil {
+		t.Fatal("Expected ctrl+a to open the sealed move prompt")
+	}
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Nf6")})
+	g.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if g.adjourned != nil || g.err == "" {
+		t.Fatalf("Expected an illegal move not to be sealed, got %q", g.err)
+	}
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g1f3")})
+	if strings.Contains(g.View(), "g1f3") {
+		t.Error("Expected the sealed move to be hidden as it is typed")
+	}
+	g.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if g.adjourned == nil {
+		t.Fatalf("Expected the game to be adjourned, got %q", g.err)
+	}
+	if !strings.Contains(g.View(), g.adjourned.code) {
+		t.Error("Expected the seal code to be shown")
+	}
+	data, err := os.ReadFile(g.adjourned.path)
+	if err != nil || strings.Contains(string(data), "Nf3") {
+		t.Errorf("Expected the saved game not to reveal the sealed move, got %s, %v", data, err)
+	}
+	if _, cmd := g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}); cmd == nil {
+		t.Error("Expected any key to quit an adjourned game")
+	}
+
+	resumed, err := ResumeAdjournment(g.adjourned.path, settings)
+	if err != nil {
+		t.Fatalf("Expected the game to resume, got %v", err)
+	}
+	if resumed.ID() != g.ID() || len(resumed.sanMoves()) != 2 {
+		t.Errorf("Expected the same game after 2 moves, got %s after %v", resumed.ID(), resumed.sanMoves())
+	}
+	resumed.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e4")})
+	if resumed.chessGame.Position().Turn() != chess.White || resumed.sealInput == nil {
+		t.Fatal("Expected the board to wait for the seal code")
+	}
+	resumed.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if resumed.err != "wrong seal code" {
+		t.Errorf("Expected a wrong code to be refused, got %q", resumed.err)
+	}
+	resumed.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(strings.ToUpper(g.adjourned.code))})
+	resumed.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if moves := resumed.sanMoves(); len(moves) != 3 || moves[2] != "Nf3" || resumed.sealInput != nil {
+		t.Fatalf("Expected the sealed Nf3 to be played, got %v (%q)", moves, resumed.err)
+	}
+	if _, err := os.Stat(g.adjourned.path); !os.IsNotExist(err) {
+		t.Error("Expected the adjourned game to be removed once resumed")
+	}
+}
+
+func TestAdjournCalledOff(t *testing.T) {
+	g := NewGame()
+	g.Update(tea.KeyMsg{Type: tea.KeyCtrlA})
+	g.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if g.sealInput != nil || g.adjourned != nil {
+		t.Error("Expected esc to call off the adjournment")
+	}
+}
@@ -0,0 +1,275 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"chess-tui/ai_player"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// adminRefreshInterval is how often the admin screen reloads the server's figures
+const adminRefreshInterval = 2 * time.Second
+
+// adminRows is how many sessions the admin screen lists
+const adminRows = 15
+
+// adminStatusMsg carries the server's state fetched for the admin screen
+type adminStatusMsg struct {
+	settings ai_player.AdminSettings
+	metrics  ai_player.AdminMetrics
+	sessions []ai_player.AdminSession
+	err      error
+}
+
+// adminDoneMsg reports how an operator's change went
+type adminDoneMsg struct {
+	status string
+	err    error
+}
+
+// adminTickMsg asks the admin screen to refresh
+type adminTickMsg struct{}
+
+// Admin is the operator's screen for a hosted A2A server: it shows the
+// served model and live metrics, lists the games in progress, and kicks
+// sessions and switches the model through the admin endpoints
+type Admin struct {
+	client *ai_player.AdminClient
+	ctx    context.Context
+
+	settings ai_player.AdminSettings
+	metrics  ai_player.AdminMetrics
+	sessions []ai_player.AdminSession
+	loaded   bool
+	cursor   int
+	status   string
+	err      string
+
+	confirm string          // the session to kick once confirmed, if asked
+	model   textinput.Model // the new model's name, while being typed
+	editing bool
+
+	blurred bool // the terminal is in the background, so refreshes wait
+	stale   bool // a refresh came due while the terminal was in the background
+}
+
+// NewAdmin creates the admin screen for the server behind client
+func NewAdmin(client *ai_player.AdminClient) *Admin {
+	model := textinput.New()
+	model.Prompt = "Model: "
+	model.Placeholder = "e.g. qwen3:8b"
+	model.CharLimit = 120
+	model.Width = 40
+	return &Admin{client: client, ctx: context.Background(), model: model}
+}
+
+// SetContext ends the screen's requests with ctx
+func (a *Admin) SetContext(ctx context.Context) {
+	a.ctx = ctx
+}
+
+// Init loads the server's state
+func (a *Admin) Init() tea.Cmd {
+	return a.refresh()
+}
+
+// refresh fetches the settings, metrics and sessions
+func (a *Admin) refresh() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(a.ctx, 5*time.Second)
+		defer cancel()
+		var msg adminStatusMsg
+		if msg.settings, msg.err = a.client.Settings(ctx); msg.err != nil {
+			return msg
+		}
+		if msg.metrics, msg.err = a.client.Metrics(ctx); msg.err != nil {
+			return msg
+		}
+		msg.sessions, msg.err = a.client.Sessions(ctx)
+		return msg
+	}
+}
+
+// kick ends the session id on the server
+func (a *Admin) kick(id string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(a.ctx, 5*time.Second)
+		defer cancel()
+		if err := a.client.Kick(ctx, id); err != nil {
+			return adminDoneMsg{err: err}
+		}
+		return adminDoneMsg{status: "Kicked " + id}
+	}
+}
+
+// switchModel has the server play with model from now on
+func (a *Admin) switchModel(model string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(a.ctx, 5*time.Second)
+		defer cancel()
+		settings, err := a.client.Update(ctx, ai_player.AdminUpdate{Model: &model})
+		if err != nil {
+			return adminDoneMsg{err: err}
+		}
+		return adminDoneMsg{status: "Now serving " + settings.Model}
+	}
+}
+
+// Update handles the server's state and the operator's keys
+func (a *Admin) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case adminStatusMsg:
+		a.loaded = true
+		a.err = ""
+		if msg.err != nil {
+			a.err = msg.err.Error()
+		} else {
+			a.settings, a.metrics, a.sessions = msg.settings, msg.metrics, msg.sessions
+			a.cursor = min(a.cursor, max(len(a.sessions)-1, 0))
+		}
+		return a, tea.Tick(adminRefreshInterval, func(time.Time) tea.Msg { return adminTickMsg{} })
+	case adminTickMsg:
+		if a.blurred {
+			a.stale = true
+			return a, nil
+		}
+		return a, a.refresh()
+	case adminDoneMsg:
+		a.status, a.err = msg.status, ""
+		if msg.err != nil {
+			a.status, a.err = "", msg.err.Error()
+		}
+	case tea.BlurMsg:
+		a.blurred = true
+	case tea.FocusMsg:
+		a.blurred = false
+		if a.stale {
+			a.stale = false
+			return a, a.refresh()
+		}
+	case tea.KeyMsg:
+		return a.handleKey(msg)
+	}
+	return a, nil
+}
+
+// handleKey moves through the sessions, kicks one after confirming, and
+// switches the model
+func (a *Admin) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.editing {
+		switch msg.String() {
+		case "enter":
+			a.editing = false
+			a.model.Blur()
+			if model := strings.TrimSpace(a.model.Value()); model != "" {
+				return a, a.switchModel(model)
+			}
+			return a, nil
+		case "esc":
+			a.editing = false
+			a.model.Blur()
+			return a, nil
+		}
+		var cmd tea.Cmd
+		a.model, cmd = a.model.Update(msg)
+		return a, cmd
+	}
+
+	if a.confirm != "" {
+		id := a.confirm
+		a.confirm = ""
+		if msg.String() == "y" {
+			return a, a.kick(id)
+		}
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if a.cursor > 0 {
+			a.cursor--
+		}
+	case "down", "j":
+		if a.cursor < len(a.sessions)-1 {
+			a.cursor++
+		}
+	case "x", "delete":
+		if len(a.sessions) > 0 {
+			a.confirm = a.sessions[a.cursor].ID
+		}
+	case "m":
+		a.editing = true
+		a.model.SetValue(a.settings.Model)
+		a.model.CursorEnd()
+		a.model.Focus()
+		return a, textinput.Blink
+	case "r":
+		return a, a.refresh()
+	case "q", "esc", "ctrl+c":
+		return a, tea.Quit
+	}
+	return a, nil
+}
+
+// View renders the server's model, metrics and sessions
+func (a *Admin) View() string {
+	var sb strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700"))
+	headingStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00AAFF"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	sb.WriteString(titleStyle.Render("♔ Admin ♛") + " " + dim.Render(a.client.URL()) + "\n\n")
+
+	if !a.loaded {
+		sb.WriteString(dim.Render("Connecting...") + "\n")
+	} else {
+		m := a.metrics
+		sb.WriteString(fmt.Sprintf("Serving %s (%s), temperature %.2f\n", a.settings.Model, a.settings.Provider, a.settings.Temperature))
+		sb.WriteString(fmt.Sprintf("Up %s · %d requests · %d errors · %s average, %s last · %d queued\n",
+			time.Duration(m.UptimeSeconds)*time.Second, m.Requests, m.Errors,
+			time.Duration(m.AverageLatencyMs)*time.Millisecond, time.Duration(m.LastLatencyMs)*time.Millisecond, m.QueueLength))
+
+		sb.WriteString("\n" + headingStyle.Render(fmt.Sprintf("Sessions (%d active of %d)", m.ActiveSessions, m.Sessions)) + "\n")
+		if len(a.sessions) == 0 {
+			sb.WriteString(dim.Render("  No games in progress") + "\n")
+		}
+		for i, session := range a.sessions {
+			if i == adminRows {
+				sb.WriteString(dim.Render(fmt.Sprintf("  … and %d more", len(a.sessions)-adminRows)) + "\n")
+				break
+			}
+			cursor, style := " ", dim
+			if i == a.cursor {
+				cursor, style = ">", lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Bold(true)
+			}
+			idle := time.Since(session.Updated).Round(time.Second)
+			line := fmt.Sprintf("%s %-24s %-5s %3d moves  %-16s idle %s", cursor, session.ID, session.PlayerColor, session.Moves, session.Model, idle)
+			sb.WriteString(style.Render(line) + "\n")
+		}
+	}
+
+	if a.status != "" {
+		sb.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Render(a.status) + "\n")
+	}
+	if a.err != "" {
+		sb.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+a.err) + "\n")
+	}
+
+	sb.WriteString("\n")
+	switch {
+	case a.editing:
+		sb.WriteString(a.model.View() + "\n" + dim.Render("Enter to switch, Esc to cancel"))
+	case a.confirm != "":
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAA00")).Render(fmt.Sprintf("Kick %s? Its game gets no more moves. y/n", a.confirm)))
+	default:
+		sb.WriteString(dim.Render("↑/↓ to choose, x to kick, m to change the model, r to refresh, q to quit"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,98 @@
+package game
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"chess-tui/ai_player"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeAdminServer serves the admin endpoints over two sessions, recording
+// the sessions kicked and the models switched to
+func fakeAdminServer(t *testing.T, kicked, models *[]string) *httptest.Server {
+	t.Helper()
+	model := "llama3"
+	sessions := []ai_player.AdminSession{
+		{ID: "game_2", PlayerColor: "black", Moves: 12, Model: model, Updated: time.Now()},
+		{ID: "game_1", PlayerColor: "white", Moves: 30, Model: model, Updated: time.Now().Add(-time.Hour)},
+	}
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var update ai_player.AdminUpdate
+			json.NewDecoder(r.Body).Decode(&update)
+			model = *update.Model
+			*models = append(*models, model)
+		}
+		writeJSON(w, ai_player.AdminSettings{Provider: "ollama", Model: model, Temperature: 0.7})
+	})
+	mux.HandleFunc("GET /admin/metrics", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, ai_player.AdminMetrics{Requests: 42, Errors: 1, AverageLatencyMs: 1500, QueueLength: 2, Sessions: len(sessions), ActiveSessions: 1})
+	})
+	mux.HandleFunc("GET /admin/sessions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, sessions)
+	})
+	mux.HandleFunc("DELETE /admin/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		*kicked = append(*kicked, r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAdminScreen(t *testing.T) {
+	var kicked, models []string
+	server := fakeAdminServer(t, &kicked, &models)
+	admin := NewAdmin(ai_player.NewAdminClient(server.URL, "s3cret"))
+
+	admin.Update(admin.refresh()())
+	view := admin.View()
+	for _, want := range []string{"Serving llama3 (ollama)", "42 requests", "1 errors", "2 queued", "Sessions (1 active of 2)", "game_2", "game_1"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q on the admin screen, got:\n%s", want, view)
+		}
+	}
+
+	// Kicking asks first, and n keeps the session
+	key := func(s string) tea.Cmd {
+		_, cmd := admin.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+		return cmd
+	}
+	key("j")
+	key("x")
+	if !strings.Contains(admin.View(), "Kick game_1?") {
+		t.Errorf("Expected a confirmation for game_1, got:\n%s", admin.View())
+	}
+	if cmd := key("n"); cmd != nil {
+		t.Error("Expected n to cancel the kick")
+	}
+	key("x")
+	admin.Update(key("y")())
+	if len(kicked) != 1 || kicked[0] != "game_1" || !strings.Contains(admin.View(), "Kicked game_1") {
+		t.Errorf("Expected game_1 kicked, got %v", kicked)
+	}
+
+	// m edits the model, starting from the current one
+	key("m")
+	for range "llama3" {
+		admin.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	key("qwen3:8b")
+	_, cmd := admin.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	admin.Update(cmd())
+	if len(models) != 1 || models[0] != "qwen3:8b" || !strings.Contains(admin.View(), "Now serving qwen3:8b") {
+		t.Errorf("Expected the model switched to qwen3:8b, got %v", models)
+	}
+}
@@ -1,20 +1,85 @@
 package game
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
+
+	"chess-tui/ai_player"
+	"chess-tui/errkind"
+	"chess-tui/netconfig"
 )
 
 // AIClient represents a client for communicating with the a2a server
 type AIClient struct {
-	serverURL string
-	client    *http.Client
+	serverURL   string
+	client      *http.Client
+	personality string
+	bullet      bool   // ask the server to play for speed
+	priority    string // the server queue priority of the client's requests, "" for normal
+
+	// The session is changed by the TUI while a request may be running
+	mu        sync.Mutex
+	contextID string          // groups a game's requests into one server session
+	ctx       context.Context // cancels requests in flight when it ends
+	onStatus  func(AIStatus)  // called with the server's progress on a request
+
+	drawOffered bool   // the player has offered the AI a draw
+	startFEN    string // the position the game started from, if not the standard one
+
+	streamDisabled bool // the server doesn't support message/stream
+}
+
+// aiSession is a snapshot of the client's session, taken for one request
+type aiSession struct {
+	ctx       context.Context
+	contextID string
+	onStatus  func(AIStatus)
+	startFEN  string
+}
+
+// log returns a logger that tags lines with the session's game
+func (s aiSession) log() *slog.Logger {
+	return slog.With("game_id", s.contextID)
+}
+
+// session returns the session a request is made under
+func (ac *AIClient) session() aiSession {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	return aiSession{ctx: ac.ctx, contextID: ac.contextID, onStatus: ac.onStatus, startFEN: ac.startFEN}
+}
+
+// AIStatus is the server's progress on a request, reported while the
+// client waits for the reply
+type AIStatus struct {
+	State         string // "submitted" while queued, "working" once the AI has started
+	QueuePosition int    // 1-based place in the server's queue while queued
+}
+
+// Queued reports whether the request is waiting behind others
+func (s AIStatus) Queued() bool {
+	return s.State == "submitted" && s.QueuePosition > 0
+}
+
+// SetStatusHandler sets a function called with the server's progress while
+// a request waits, such as its place in the server's queue. nil stops updates.
+func (ac *AIClient) SetStatusHandler(handler func(AIStatus)) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.onStatus = handler
 }
 
 // NewAIClient creates a new AI client
@@ -25,12 +90,42 @@ func NewAIClient(serverURL string) *AIClient {
 
 	return &AIClient{
 		serverURL: serverURL,
-		client: &http.Client{
-			Timeout: 600 * time.Second, // Increased timeout to 10 minutes for longer AI thinking
-		},
+		client:    netconfig.NewClient(600 * time.Second), // Increased timeout to 10 minutes for longer AI thinking
+		contextID: newContextID(),
+		ctx:       context.Background(),
 	}
 }
 
+// SetContext ties the client's requests to ctx: once it ends, a request in
+// flight is abandoned and new ones fail straight away
+func (ac *AIClient) SetContext(ctx context.Context) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.ctx = ctx
+}
+
+// newContextID returns a random A2A context ID for a game
+func newContextID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "game_" + hex.EncodeToString(b)
+}
+
+// SetSession starts a new server session under the game's ID, for when a
+// new game begins
+func (ac *AIClient) SetSession(id string) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.contextID = id
+}
+
+// ContextID returns the A2A context ID of the current game's session
+func (ac *AIClient) ContextID() string {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	return ac.contextID
+}
+
 // JSONRPCRequest represents a JSON-RPC request
 type JSONRPCRequest struct {
 	Jsonrpc string      `json:"jsonrpc"`
@@ -46,6 +141,7 @@ type MessageSendParams struct {
 
 // Message represents an A2A message
 type Message struct {
+	ContextID string             `json:"contextId,omitempty"`
 	Kind      string             `json:"kind"`
 	MessageID string             `json:"messageId"`
 	Role      string             `json:"role"`
@@ -63,9 +159,73 @@ type TextPart struct {
 
 // ChessRequest represents a chess move request to the AI
 type ChessRequest struct {
-	BoardState  string   `json:"board_state"`
-	PlayerColor string   `json:"player_color"`
-	GameHistory []string `json:"game_history"`
+	BoardState    string   `json:"board_state"`
+	PlayerColor   string   `json:"player_color"`
+	GameHistory   []string `json:"game_history"`
+	LastMoveError string   `json:"last_move_error,omitempty"`
+	Personality   string   `json:"personality,omitempty"`
+	Bullet        bool     `json:"bullet,omitempty"`
+	Task          string   `json:"task,omitempty"`
+	FEN           string   `json:"fen,omitempty"`       // lets the server check GameHistory leads to this position
+	StartFEN      string   `json:"start_fen,omitempty"` // where GameHistory starts, if not the standard position
+
+	// IdempotencyKey names the move request so a retry of it gets the same
+	// move back; it is the game's context ID and ply
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// Actions lists what the TUI handles besides moves, so the AI may
+	// resign and offer or accept draws; DrawOffered says the player has
+	// offered one
+	Actions     []string `json:"actions,omitempty"`
+	DrawOffered bool     `json:"draw_offered,omitempty"`
+
+	// Priority is ai_player.PriorityLow for requests that may wait behind
+	// the server's other requests
+	Priority string `json:"priority,omitempty"`
+
+	// Variant names the chess variant played, e.g. "crazyhouse", whose FEN
+	// BoardState is in
+	Variant string `json:"variant,omitempty"`
+}
+
+// aiActions are the AI's decisions the TUI handles besides moves
+var aiActions = []string{ai_player.ActionResign, ai_player.ActionOfferDraw, ai_player.ActionAcceptDraw}
+
+// methodNotFoundCode is the JSON-RPC error code for a method the server
+// doesn't have
+const methodNotFoundCode = -32601
+
+// ErrMethodNotFound is returned for a request an older server has no
+// method for
+var ErrMethodNotFound = errkind.New(errkind.ProtocolError, "the AI server does not support this request")
+
+// DesyncError reports that the server replayed the game history to a
+// different position than the client's board
+type DesyncError struct {
+	ClientFEN string `json:"client_fen"`
+	ServerFEN string `json:"server_fen"`
+	Ply       int    `json:"ply"`
+	Move      string `json:"move,omitempty"`
+}
+
+// Error describes the desync
+func (d *DesyncError) Error() string {
+	if d.Move != "" {
+		return fmt.Sprintf("board desync: history move %d (%s) is illegal in %s", d.Ply+1, d.Move, d.ServerFEN)
+	}
+	return fmt.Sprintf("board desync after %d plies: client has %s, server has %s", d.Ply, d.ClientFEN, d.ServerFEN)
+}
+
+// Is makes the desync of kind errkind.Desync
+func (d *DesyncError) Is(target error) bool {
+	return target == errkind.Desync
+}
+
+// jsonrpcError is the error object of a JSON-RPC response
+type jsonrpcError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
 }
 
 // ChessResponse represents a chess move response from the AI
@@ -73,6 +233,32 @@ type ChessResponse struct {
 	Move string `json:"move"`
 }
 
+// AIMoveResult is a move returned by the AI along with its supporting details
+type AIMoveResult struct {
+	Move             string
+	Reasoning        string
+	PromptTokens     int
+	CompletionTokens int
+	Provider         string     // backend that produced the move, if reported
+	Outcome          *AIOutcome // how the move ends the game, if the server says it does
+	Votes            []Vote     // how a panel of models voted on the move, if it did
+	Confidence       *float64   // how sure the AI is of the move, 0 to 1, if it says
+	Spare            bool       // the server's spare model answered, its primary having timed out
+
+	// Action is ai_player.ActionResign or ActionAcceptDraw when the AI
+	// played no move, ActionOfferDraw when it offers a draw with Move, and
+	// empty or ActionMove otherwise; ActionReason says why
+	Action       string
+	ActionReason string
+}
+
+// AIOutcome is the server's account of how its move ended the game
+type AIOutcome struct {
+	Result string // "1-0", "0-1" or "1/2-1/2"
+	Reason string // such as "checkmate" or "stalemate"
+	FEN    string // the final position
+}
+
 // JSONRPCResponse represents a JSON-RPC response
 type JSONRPCResponse struct {
 	Jsonrpc string      `json:"jsonrpc"`
@@ -83,30 +269,233 @@ type JSONRPCResponse struct {
 
 // GetAIMove requests a move from the AI via the a2a server
 func (ac *AIClient) GetAIMove(boardState string, gameHistory []string, playerColor string) (string, error) {
-	return ac.getAIMoveInternal(boardState, gameHistory, "", playerColor)
+	result, err := ac.getAIMoveInternal(boardState, gameHistory, "", playerColor)
+	if err != nil {
+		return "", err
+	}
+	return result.Move, nil
 }
 
 // GetAIMoveWithError requests a move from the AI with error information from the previous attempt
 func (ac *AIClient) GetAIMoveWithError(boardState string, gameHistory []string, errorMsg string, playerColor string) (string, error) {
+	result, err := ac.getAIMoveInternal(boardState, gameHistory, errorMsg, playerColor)
+	if err != nil {
+		return "", err
+	}
+	return result.Move, nil
+}
+
+// GetAIMoveResult requests a move from the AI and returns it with the AI's
+// reasoning summary. errorMsg may describe why the previous attempt failed.
+func (ac *AIClient) GetAIMoveResult(boardState string, gameHistory []string, errorMsg string, playerColor string) (*AIMoveResult, error) {
 	return ac.getAIMoveInternal(boardState, gameHistory, errorMsg, playerColor)
 }
 
-// getAIMoveInternal is the internal implementation for getting AI moves
-func (ac *AIClient) getAIMoveInternal(boardState string, gameHistory []string, errorMsg string, playerColor string) (string, error) {
+// GetVariantMove asks the AI for a move in a chess variant, fen being the
+// variant's FEN, e.g. with Crazyhouse reserves
+func (ac *AIClient) GetVariantMove(variantName, fen string, gameHistory []string, playerColor string) (*AIMoveResult, error) {
+	if gameHistory == nil {
+		gameHistory = []string{}
+	}
+	requestText, err := json.Marshal(ChessRequest{
+		BoardState:  fen,
+		PlayerColor: playerColor,
+		GameHistory: gameHistory,
+		Personality: ac.personality,
+		Priority:    ac.priority,
+		Variant:     variantName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal variant request: %w", err)
+	}
+
+	parts, err := ac.sendMessage(string(requestText))
+	if err != nil {
+		return nil, err
+	}
+	firstPart, ok := parts[0].(map[string]interface{})
+	if !ok {
+		return nil, errkind.New(errkind.ProtocolError, "first part is not a map")
+	}
+	text, ok := firstPart["text"].(string)
+	if !ok {
+		return nil, errkind.New(errkind.ProtocolError, "text field not found in part")
+	}
+
+	result := &AIMoveResult{}
+	extractMoveData(parts, result)
+	if result.Move, err = extractMove(text); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CandidateMove is a move the AI suggests to the human in teach mode
+type CandidateMove struct {
+	Move        string `json:"move"`
+	Explanation string `json:"explanation"`
+}
+
+// SuggestMoves asks the AI for a few candidate moves for the human, each with
+// a short explanation
+func (ac *AIClient) SuggestMoves(boardState string, gameHistory []string, playerColor string) ([]CandidateMove, error) {
+	requestText, err := json.Marshal(ChessRequest{
+		BoardState:  boardState,
+		PlayerColor: playerColor,
+		GameHistory: gameHistory,
+		Personality: ac.personality,
+		Task:        "suggest",
+		FEN:         boardState,
+		Priority:    ac.priority,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal suggest request: %w", err)
+	}
+
+	parts, err := ac.sendMessage(string(requestText))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, part := range parts {
+		partMap, ok := part.(map[string]interface{})
+		if !ok || partMap["kind"] != "data" {
+			continue
+		}
+		data, ok := partMap["data"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		// Round-trip through JSON to decode the candidate list
+		candidatesJSON, err := json.Marshal(data["candidates"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read candidates: %w", err)
+		}
+		var candidates []CandidateMove
+		if err := json.Unmarshal(candidatesJSON, &candidates); err != nil {
+			return nil, errkind.Errorf(errkind.ProtocolError, "failed to decode candidates: %w", err)
+		}
+		return candidates, nil
+	}
+
+	return nil, errkind.New(errkind.ProtocolError, "no candidates found in response")
+}
+
+// ReplaySession sends the game's whole move list to the server so it can
+// rebuild its session in one request, e.g. after the boards drifted apart.
+// fen is the client's position, which the server checks the moves against.
+func (ac *AIClient) ReplaySession(moves []string, fen string, playerColor string) error {
+	session := ac.session()
+	return ac.callSession(session, "session/replay", map[string]interface{}{
+		"contextId":    session.contextID,
+		"moves":        moves,
+		"fen":          fen,
+		"start_fen":    session.startFEN,
+		"player_color": playerColor,
+		"personality":  ac.personality,
+	}, nil)
+}
+
+// ResumedSession is the server's confirmation that it has reconstructed a
+// game's session
+type ResumedSession struct {
+	Replaced bool   `json:"replaced"` // the server already had a session for the game
+	FEN      string `json:"fen"`
+	Ply      int    `json:"ply"`
+	Provider string `json:"provider"` // who plays the AI's moves from here
+	Model    string `json:"model"`
+}
+
+// ResumeSession hands the server a snapshot of the game, its position,
+// moves and settings, so it can reconstruct the game's session, e.g. on a
+// server that restarted without its sessions or took over from another.
+// Servers without the method return ErrMethodNotFound.
+func (ac *AIClient) ResumeSession(moves []string, fen string, playerColor string) (*ResumedSession, error) {
+	session := ac.session()
+	var resumed ResumedSession
+	err := ac.callSession(session, "session/resume", map[string]interface{}{
+		"contextId":    session.contextID,
+		"fen":          fen,
+		"history":      moves,
+		"start_fen":    session.startFEN,
+		"player_color": playerColor,
+		"personality":  ac.personality,
+	}, &resumed)
+	if err != nil {
+		return nil, err
+	}
+	return &resumed, nil
+}
+
+// callSession calls a session method on the server and decodes its result
+// into result, unless it is nil
+func (ac *AIClient) callSession(session aiSession, method string, params map[string]interface{}, result interface{}) error {
+	jsonData, err := json.Marshal(JSONRPCRequest{
+		Jsonrpc: "2.0",
+		Method:  method,
+		ID:      1,
+		Params:  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", method, err)
+	}
+
+	bodyBytes, _, err := ac.post(session, jsonData)
+	if err != nil {
+		return err
+	}
+	var jsonrpcResponse struct {
+		Result json.RawMessage `json:"result"`
+		Error  *jsonrpcError   `json:"error"`
+	}
+	if err := json.Unmarshal(bodyBytes, &jsonrpcResponse); err != nil {
+		return errkind.Errorf(errkind.ProtocolError, "failed to decode JSON-RPC response: %w", err)
+	}
+	if jsonrpcResponse.Error != nil {
+		errorBytes, _ := json.Marshal(jsonrpcResponse.Error)
+		if desync := decodeDesyncError(errorBytes); desync != nil {
+			return desync
+		}
+		if jsonrpcResponse.Error.Code == methodNotFoundCode {
+			return fmt.Errorf("%s: %w", method, ErrMethodNotFound)
+		}
+		return decodeJSONRPCError(errorBytes)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(jsonrpcResponse.Result, result); err != nil {
+		return errkind.Errorf(errkind.ProtocolError, "failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// sendMessage sends request text to the a2a server and returns the parts of
+// its reply. It streams the reply when the server supports it, so status
+// updates such as the request's place in the queue arrive while it waits.
+func (ac *AIClient) sendMessage(text string) ([]interface{}, error) {
+	session := ac.session()
+	method := "message/stream"
+	if ac.streamDisabled {
+		method = "message/send"
+	}
+
 	// Create the JSON-RPC request
 	jsonrpcRequest := JSONRPCRequest{
 		Jsonrpc: "2.0",
-		Method:  "message/send",
+		Method:  method,
 		ID:      1,
 		Params: MessageSendParams{
 			Message: Message{
+				ContextID: session.contextID,
 				Kind:      "message",
 				MessageID: fmt.Sprintf("msg_%d", time.Now().Unix()),
 				Role:      "user",
 				Parts: []MessagePartsElem{
 					TextPart{
 						Kind: "text",
-						Text: ac.buildRequestText(boardState, gameHistory, errorMsg, playerColor),
+						Text: text,
 					},
 				},
 			},
@@ -115,127 +504,413 @@ func (ac *AIClient) getAIMoveInternal(boardState string, gameHistory []string, e
 
 	jsonData, err := json.Marshal(jsonrpcRequest)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal JSON-RPC request: %w", err)
+		return nil, fmt.Errorf("failed to marshal JSON-RPC request: %w", err)
 	}
 
 	// Debug output
-	slog.Debug("Making request to AI server", "url", ac.serverURL+"/a2a")
-	slog.Debug("Request data", "data", string(jsonData))
-
-	// Make request to the a2a endpoint
-	resp, err := ac.client.Post(ac.serverURL+"/a2a", "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		slog.Debug("Request failed", "error", err)
-		return "", fmt.Errorf("failed to make request to a2a server: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("a2a server returned status: %d", resp.StatusCode)
-	}
-
-	// Read the full response body for debugging
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+	session.log().Debug("Making request to AI server", "url", ac.serverURL+"/a2a")
+	session.log().Debug("Request data", "data", string(jsonData))
+
+	// Make request to the a2a endpoint, retrying once if the connection
+	// drops; the request's idempotency key makes the retry safe
+	var bodyBytes []byte
+	for attempt := 1; ; attempt++ {
+		var retryable bool
+		bodyBytes, retryable, err = ac.post(session, jsonData)
+		if err == nil {
+			break
+		}
+		if !retryable || attempt >= sendAttempts || session.ctx.Err() != nil {
+			return nil, err
+		}
+		session.log().Warn("Retrying request to a2a server", "error", err, "attempt", attempt+1)
+		select {
+		case <-session.ctx.Done():
+			return nil, fmt.Errorf("failed to make request to a2a server: %w", session.ctx.Err())
+		case <-time.After(sendRetryDelay):
+		}
 	}
 
 	// Debug output
-	slog.Debug("Response received", "status", resp.StatusCode)
-	slog.Debug("Response body", "body", string(bodyBytes))
+	session.log().Debug("Response received")
+	session.log().Debug("Response body", "body", string(bodyBytes))
 
 	// Parse the JSON-RPC response
 	var jsonrpcResponse JSONRPCResponse
 	if err := json.Unmarshal(bodyBytes, &jsonrpcResponse); err != nil {
-		return "", fmt.Errorf("failed to decode JSON-RPC response: %w", err)
+		return nil, errkind.Errorf(errkind.ProtocolError, "failed to decode JSON-RPC response: %w", err)
 	}
 
 	// Debug output removed for production
 
 	// Enhanced debug output for response parsing
-	slog.Debug("Parsing AI response", "has_result", jsonrpcResponse.Result != nil, "has_error", jsonrpcResponse.Error != nil)
+	session.log().Debug("Parsing AI response", "has_result", jsonrpcResponse.Result != nil, "has_error", jsonrpcResponse.Error != nil)
 
 	// Check for JSON-RPC errors
 	if jsonrpcResponse.Error != nil {
 		errorBytes, _ := json.Marshal(jsonrpcResponse.Error)
-		slog.Debug("JSON-RPC error received", "error", string(errorBytes))
-		return "", fmt.Errorf("JSON-RPC error: %s", string(errorBytes))
+		session.log().Debug("JSON-RPC error received", "error", string(errorBytes))
+		if desync := decodeDesyncError(errorBytes); desync != nil {
+			return nil, desync
+		}
+		if method == "message/stream" && isMethodNotFound(errorBytes) {
+			// An older server; wait for the plain reply instead
+			session.log().Debug("a2a server doesn't support message/stream, falling back to message/send")
+			ac.streamDisabled = true
+			return ac.sendMessage(text)
+		}
+		return nil, decodeJSONRPCError(errorBytes)
 	}
 
 	// Extract the result from the JSON-RPC response
 	// The result contains a message with parts
 	resultMap, ok := jsonrpcResponse.Result.(map[string]interface{})
 	if !ok {
-		return "", fmt.Errorf("result is not a map")
+		return nil, errkind.New(errkind.ProtocolError, "result is not a map")
 	}
 
 	// Extract the parts from the result
 	parts, ok := resultMap["parts"].([]interface{})
 	if !ok || len(parts) == 0 {
-		return "", fmt.Errorf("no parts found in result")
+		return nil, errkind.New(errkind.ProtocolError, "no parts found in result")
+	}
+
+	return parts, nil
+}
+
+// Retry settings for requests to the a2a server
+const (
+	sendAttempts   = 2
+	sendRetryDelay = 500 * time.Millisecond
+)
+
+// post sends a JSON-RPC request body and returns the response body. It
+// reports whether a failure is worth retrying: a dropped connection or a
+// gateway error, but not a timeout, which would only wait as long again.
+func (ac *AIClient) post(session aiSession, jsonData []byte) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(session.ctx, http.MethodPost, ac.serverURL+"/a2a", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := ac.client.Do(req)
+	if err != nil {
+		session.log().Debug("Request failed", "error", err)
+		var netErr net.Error
+		retryable := !(errors.As(err, &netErr) && netErr.Timeout())
+		return nil, retryable, fmt.Errorf("failed to make request to a2a server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return nil, true, errkind.Errorf(errkind.ProviderUnavailable, "a2a server returned status: %d", resp.StatusCode)
+	case http.StatusGatewayTimeout:
+		return nil, true, errkind.Errorf(errkind.ProviderTimeout, "a2a server returned status: %d", resp.StatusCode)
+	default:
+		return nil, false, errkind.Errorf(errkind.ProtocolError, "a2a server returned status: %d", resp.StatusCode)
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		bodyBytes, err := ac.readEventStream(resp.Body, session)
+		if err != nil {
+			return nil, true, err
+		}
+		return bodyBytes, false, nil
+	}
+
+	// Read the full response body for debugging
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return bodyBytes, false, nil
+}
+
+// readEventStream reads a message/stream reply, passing status updates to
+// the session's onStatus, and returns the final JSON-RPC response
+func (ac *AIClient) readEventStream(body io.Reader, session aiSession) ([]byte, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var data []byte
+	for scanner.Scan() {
+		line := scanner.Text()
+		if payload, ok := strings.CutPrefix(line, "data:"); ok {
+			if len(data) > 0 {
+				data = append(data, '\n')
+			}
+			data = append(data, strings.TrimPrefix(payload, " ")...)
+			continue
+		}
+		if line != "" || len(data) == 0 {
+			continue
+		}
+
+		// A blank line ends an event
+		var event struct {
+			Result struct {
+				Kind   string `json:"kind"`
+				Status struct {
+					State string `json:"state"`
+				} `json:"status"`
+				Metadata struct {
+					QueuePosition int `json:"queue_position"`
+				} `json:"metadata"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(data, &event); err == nil && event.Result.Kind == "status-update" {
+			status := AIStatus{State: event.Result.Status.State, QueuePosition: event.Result.Metadata.QueuePosition}
+			session.log().Debug("AI server status", "state", status.State, "queue_position", status.QueuePosition)
+			if session.onStatus != nil {
+				session.onStatus(status)
+			}
+			data = nil
+			continue
+		}
+		return data, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response stream: %w", err)
+	}
+	if len(data) > 0 {
+		return data, nil
+	}
+	return nil, errkind.New(errkind.ProviderUnavailable, "a2a server closed the stream without a reply")
+}
+
+// isMethodNotFound reports whether a JSON-RPC error object says the method
+// doesn't exist
+func isMethodNotFound(errorBytes []byte) bool {
+	var rpcErr jsonrpcError
+	return json.Unmarshal(errorBytes, &rpcErr) == nil && rpcErr.Code == -32601
+}
+
+// decodeJSONRPCError returns the error a JSON-RPC error object reports, of
+// the kind its code stands for
+func decodeJSONRPCError(errorBytes []byte) error {
+	var rpcErr jsonrpcError
+	err := fmt.Errorf("JSON-RPC error: %s", string(errorBytes))
+	if json.Unmarshal(errorBytes, &rpcErr) != nil {
+		return errkind.Wrap(errkind.ProtocolError, err)
+	}
+	return errkind.Wrap(errkind.FromCode(rpcErr.Code), err)
+}
+
+// decodeDesyncError returns the desync described by a JSON-RPC error object,
+// or nil if it is some other error
+func decodeDesyncError(errorBytes []byte) *DesyncError {
+	var rpcErr jsonrpcError
+	if err := json.Unmarshal(errorBytes, &rpcErr); err != nil || rpcErr.Code != errkind.CodeDesync {
+		return nil
+	}
+	desync := &DesyncError{}
+	if err := json.Unmarshal(rpcErr.Data, desync); err != nil {
+		return &DesyncError{}
+	}
+	return desync
+}
+
+// getAIMoveInternal is the internal implementation for getting AI moves
+func (ac *AIClient) getAIMoveInternal(boardState string, gameHistory []string, errorMsg string, playerColor string) (*AIMoveResult, error) {
+	log := ac.session().log()
+	parts, err := ac.sendMessage(ac.buildRequestText(boardState, gameHistory, errorMsg, playerColor))
+	if err != nil {
+		return nil, err
 	}
 
 	// Get the first part (should be text)
 	firstPart, ok := parts[0].(map[string]interface{})
 	if !ok {
-		return "", fmt.Errorf("first part is not a map")
+		return nil, errkind.New(errkind.ProtocolError, "first part is not a map")
 	}
 
 	// Extract the text from the part
 	text, ok := firstPart["text"].(string)
 	if !ok {
-		return "", fmt.Errorf("text field not found in part")
+		return nil, errkind.New(errkind.ProtocolError, "text field not found in part")
+	}
+
+	log.Debug("📝 AI response text received", "text", text, "text_length", len(text))
+
+	// Resigning or taking a draw comes without a move
+	result := &AIMoveResult{}
+	extractMoveData(parts, result)
+	if result.Action == ai_player.ActionResign || result.Action == ai_player.ActionAcceptDraw {
+		log.Debug("🏳️ AI chose not to move", "action", result.Action, "reason", result.ActionReason)
+		return result, nil
 	}
 
-	slog.Debug("📝 AI response text received", "text", text, "text_length", len(text))
+	move, err := extractMove(text)
+	if err != nil {
+		return nil, err
+	}
 
-	// Try to extract the move from various possible response formats
+	log.Debug("🎯 Successfully extracted AI move", "move", move, "original_text", text)
+	result.Move = move
+	return result, nil
+}
+
+// extractMove pulls the move out of the server's reply text
+func extractMove(text string) (string, error) {
 	var move string
 
 	// Format 1: "Generated move: <move>"
 	if len(text) > 16 && text[:16] == "Generated move: " {
-		move = text[16:]
+		move = strings.TrimSpace(text[16:])
 		slog.Debug("✅ Extracted move using 'Generated move:' format", "move", move)
-	} else if len(text) > 7 && text[:7] == "Move: " {
+	} else if len(text) > 6 && text[:6] == "Move: " {
 		// Format 2: "Move: <move>"
-		move = text[7:]
+		move = strings.TrimSpace(text[6:])
 		slog.Debug("✅ Extracted move using 'Move:' format", "move", move)
 	} else if len(text) > 0 {
 		// Format 3: Just the move itself (clean response)
 		// Check if it looks like a valid chess move
 		cleanedText := strings.TrimSpace(text)
-		if len(cleanedText) >= 2 && len(cleanedText) <= 5 {
-			// Basic validation - should be 2-5 characters for chess moves
+		if len(cleanedText) >= 2 && len(cleanedText) <= 7 {
+			// Basic validation - should be 2-7 characters for chess moves (exd8=Q+)
 			move = cleanedText
 			slog.Debug("✅ Extracted move as direct response", "move", move)
 		} else {
 			slog.Debug("❌ Response doesn't match expected move format", "text", text)
-			return "", fmt.Errorf("unexpected text format: %s", text)
+			return "", errkind.Errorf(errkind.ProtocolError, "unexpected text format: %s", text)
 		}
 	} else {
 		slog.Debug("❌ Empty or invalid response text", "text", text)
-		return "", fmt.Errorf("empty or invalid response text")
+		return "", errkind.New(errkind.ProtocolError, "empty or invalid response text")
 	}
 
 	// Validate that we extracted a move
 	if move == "" {
 		slog.Debug("❌ No move extracted from response", "text", text)
-		return "", fmt.Errorf("no move extracted from response: %s", text)
+		return "", errkind.Errorf(errkind.ProtocolError, "no move extracted from response: %s", text)
 	}
 
-	slog.Debug("🎯 Successfully extracted AI move", "move", move, "original_text", text)
 	return move, nil
 }
 
+// extractMoveData fills in the reasoning, token usage, provider and outcome from the
+// response's data part, if the server sent one
+func extractMoveData(parts []interface{}, result *AIMoveResult) {
+	for _, part := range parts {
+		partMap, ok := part.(map[string]interface{})
+		if !ok || partMap["kind"] != "data" {
+			continue
+		}
+		data, ok := partMap["data"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if reasoning, ok := data["reasoning"].(string); ok {
+			result.Reasoning = reasoning
+		}
+		if provider, ok := data["provider"].(string); ok {
+			result.Provider = provider
+		}
+		// JSON numbers decode as float64
+		if tokens, ok := data["prompt_tokens"].(float64); ok {
+			result.PromptTokens = int(tokens)
+		}
+		if tokens, ok := data["completion_tokens"].(float64); ok {
+			result.CompletionTokens = int(tokens)
+		}
+		if action, ok := data["action"].(string); ok {
+			result.Action = action
+		}
+		if reason, ok := data["reason"].(string); ok {
+			result.ActionReason = reason
+		}
+		if spare, ok := data["spare"].(bool); ok {
+			result.Spare = spare
+		}
+		if confidence, ok := data["confidence"].(float64); ok {
+			result.Confidence = &confidence
+		}
+		if votes, ok := data["votes"].([]interface{}); ok {
+			result.Votes = parseVotes(votes)
+		}
+		if outcome, ok := data["outcome"].(map[string]interface{}); ok {
+			result.Outcome = &AIOutcome{}
+			result.Outcome.Result, _ = outcome["result"].(string)
+			result.Outcome.Reason, _ = outcome["reason"].(string)
+			result.Outcome.FEN, _ = outcome["fen"].(string)
+		}
+	}
+}
+
 // buildRequestText builds the request text for the AI
 func (ac *AIClient) buildRequestText(boardState string, gameHistory []string, errorMsg string, playerColor string) string {
-	// Convert game history to proper JSON array format
-	historyJSON, _ := json.Marshal(gameHistory)
+	if gameHistory == nil {
+		gameHistory = []string{}
+	}
+	requestText, _ := json.Marshal(ChessRequest{
+		BoardState:     boardState,
+		PlayerColor:    playerColor,
+		GameHistory:    gameHistory,
+		LastMoveError:  errorMsg,
+		Personality:    ac.personality,
+		Bullet:         ac.bullet,
+		FEN:            boardState,
+		IdempotencyKey: ac.idempotencyKey(gameHistory, errorMsg),
+		Actions:        aiActions,
+		DrawOffered:    ac.drawOfferPending(),
+		StartFEN:       ac.session().startFEN,
+		Priority:       ac.priority,
+	})
+	return string(requestText)
+}
+
+// idempotencyKey returns the key for a move request at this point in the game.
+// Asking again after a rejected move is a new request and gets no key.
+func (ac *AIClient) idempotencyKey(gameHistory []string, errorMsg string) string {
+	if errorMsg != "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", ac.ContextID(), len(gameHistory))
+}
+
+// SetDrawOffered tells the AI, with the requests that follow, whether the
+// player has offered it a draw
+func (ac *AIClient) SetDrawOffered(offered bool) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.drawOffered = offered
+}
+
+// SetStartFEN tells the server where the game's history starts, for games
+// set up from a position rather than the standard one
+func (ac *AIClient) SetStartFEN(fen string) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.startFEN = fen
+}
+
+// drawOfferPending reports whether the player has offered the AI a draw
+func (ac *AIClient) drawOfferPending() bool {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	return ac.drawOffered
+}
+
+// SetPersonality selects the AI personality preset sent with each request
+func (ac *AIClient) SetPersonality(name string) {
+	ac.personality = name
+}
 
-	if errorMsg == "" {
-		return fmt.Sprintf(`{"board_state":"%s","player_color":"%s","game_history":%s}`, boardState, playerColor, string(historyJSON))
+// SetBullet asks the server, with each move request, to play for speed:
+// book moves, remembered moves, no thinking and short answers
+func (ac *AIClient) SetBullet(on bool) {
+	ac.bullet = on
+}
+
+// SetLowPriority has the server queue the client's requests behind those of
+// other clients, for requests nothing waits on, such as a kibitzer's
+func (ac *AIClient) SetLowPriority(low bool) {
+	ac.priority = ""
+	if low {
+		ac.priority = ai_player.PriorityLow
 	}
-	return fmt.Sprintf(`{"board_state":"%s","player_color":"%s","game_history":%s,"last_move_error":"%s"}`, boardState, playerColor, string(historyJSON), errorMsg)
 }
 
 // TestConnection tests the connection to the a2a server
@@ -252,3 +927,21 @@ func (ac *AIClient) TestConnection() error {
 
 	return nil
 }
+
+// parseVotes reads the vote breakdown from a response's data part
+func parseVotes(votes []interface{}) []Vote {
+	var parsed []Vote
+	for _, item := range votes {
+		vote, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var v Vote
+		v.Voter, _ = vote["voter"].(string)
+		v.Move, _ = vote["move"].(string)
+		v.Weight, _ = vote["weight"].(float64)
+		v.Error, _ = vote["error"].(string)
+		parsed = append(parsed, v)
+	}
+	return parsed
+}
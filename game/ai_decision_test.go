@@ -0,0 +1,84 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"chess-tui/ai_player"
+
+	"github.com/notnil/chess"
+)
+
+// decidingGenerator answers with a canned result and records the draw
+// offers it's told of
+type decidingGenerator struct {
+	result      AIMoveResult
+	drawOffered bool
+}
+
+func (d *decidingGenerator) GetAIMoveResult(boardState string, gameHistory []string, errorMsg string, playerColor string) (*AIMoveResult, error) {
+	result := d.result
+	return &result, nil
+}
+
+func (d *decidingGenerator) SetDrawOffered(offered bool) {
+	d.drawOffered = offered
+}
+
+func (d *decidingGenerator) SetPersonality(name string) {}
+
+func (d *decidingGenerator) SuggestMoves(boardState string, gameHistory []string, playerColor string) ([]CandidateMove, error) {
+	return nil, errors.New("not used")
+}
+
+func TestAIResigns(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	g.SetMoveGenerator(&decidingGenerator{result: AIMoveResult{Action: ai_player.ActionResign, ActionReason: "down 1600 centipawns of material"}})
+	g.makeMove("e4")
+
+	playAIMove(g)
+
+	if g.chessGame.Outcome() != chess.WhiteWon || g.chessGame.Method() != chess.Resignation {
+		t.Errorf("Expected White to win by resignation, got %v by %v", g.chessGame.Outcome(), g.chessGame.Method())
+	}
+	if !strings.HasPrefix(g.status, "White wins by resignation!") {
+		t.Errorf("Expected the resignation in the status, got %q", g.status)
+	}
+}
+
+func TestAIAcceptsDrawOffer(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	generator := &decidingGenerator{result: AIMoveResult{Action: ai_player.ActionAcceptDraw}}
+	g.SetMoveGenerator(generator)
+	g.offerDraw()
+	g.makeMove("e4")
+
+	playAIMove(g)
+
+	if !generator.drawOffered {
+		t.Errorf("Expected the AI to be told of the draw offer")
+	}
+	if g.chessGame.Outcome() != chess.Draw || g.chessGame.Method() != chess.DrawOffer {
+		t.Errorf("Expected a draw by agreement, got %v by %v", g.chessGame.Outcome(), g.chessGame.Method())
+	}
+}
+
+func TestAIDeclinesAndOffersDraw(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	g.SetMoveGenerator(&decidingGenerator{result: AIMoveResult{Move: "e5", Action: ai_player.ActionOfferDraw}})
+	g.offerDraw()
+	g.makeMove("e4")
+
+	// Moving declines White's offer, and Black offers one of its own
+	playAIMove(g)
+	if g.drawOffer != chess.Black {
+		t.Fatalf("Expected Black's draw offer, got %v", g.drawOffer)
+	}
+
+	// White accepts
+	g.offerDraw()
+	if g.chessGame.Outcome() != chess.Draw {
+		t.Errorf("Expected a draw by agreement, got %v", g.chessGame.Outcome())
+	}
+}
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"chess-tui/errkind"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// aiRecovery says what went wrong with the AI's move, for each kind of
+// error, and what the player can do about it
+var aiRecovery = map[errkind.Kind]string{
+	errkind.IllegalMove:         "the AI couldn't find a legal move; press enter to ask again",
+	errkind.Desync:              "the AI server's board doesn't match this one; press enter to send it the game and ask again",
+	errkind.ProviderTimeout:     "the AI's model took too long to answer; press enter to ask again",
+	errkind.ProviderUnavailable: "the AI server or its model can't be reached; check it's running, then press enter to ask again",
+	errkind.ProtocolError:       "the AI server's reply wasn't understood; check the client and server are the same version, then press enter to ask again",
+}
+
+// describeAIError explains a failed AI move by its kind, with the error
+// itself for the details, e.g. "the AI's model took too long to answer;
+// press enter to ask again (context deadline exceeded)"
+func describeAIError(err error) string {
+	recovery, ok := aiRecovery[errkind.Of(err)]
+	if !ok {
+		return "AI error: " + err.Error() + "; press enter to ask again"
+	}
+	return recovery + " (" + err.Error() + ")"
+}
+
+// failAIMove shows why the AI's move failed and lets enter ask again
+func (g *Game) failAIMove(err error) {
+	g.aiFailure = err
+	g.err = describeAIError(err)
+}
+
+// retryAIMove asks the AI again after its move failed: after a desync with
+// the history rebuilt from the board and the whole game sent to the server
+func (g *Game) retryAIMove() tea.Cmd {
+	failure := g.aiFailure
+	g.aiFailure = nil
+	g.err = ""
+	if errkind.Of(failure) == errkind.Desync {
+		g.resyncHistory()
+		return g.requestAIMove(aiMoveRequest{resynced: true, replay: true})
+	}
+	return g.getAIMove()
+}
@@ -0,0 +1,121 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"chess-tui/errkind"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// failingGenerator fails with its errors in turn, then plays e5
+type failingGenerator struct {
+	errs  []error
+	calls int
+}
+
+func (f *failingGenerator) GetAIMoveResult(boardState string, gameHistory []string, errorMsg string, playerColor string) (*AIMoveResult, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return nil, err
+	}
+	return &AIMoveResult{Move: "e5"}, nil
+}
+
+func (f *failingGenerator) SetPersonality(name string) {}
+
+func (f *failingGenerator) SuggestMoves(boardState string, gameHistory []string, playerColor string) ([]CandidateMove, error) {
+	return nil, errors.New("not used")
+}
+
+func TestAIFailureOffersRecovery(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	generator := &failingGenerator{errs: []error{fmt.Errorf("failed to call ollama: %w", context.DeadlineExceeded)}}
+	g.SetMoveGenerator(generator)
+	if err := g.applyMove("e4"); err != nil {
+		t.Fatalf("Expected e4 to be legal, got %v", err)
+	}
+	g.isAITurn = true
+
+	playAIMove(g)
+	if !strings.Contains(g.err, "took too long to answer; press enter to ask again") {
+		t.Fatalf("Expected the timeout explained, got %q", g.err)
+	}
+
+	_, cmd := g.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected enter to ask the AI again")
+	}
+	for cmd != nil {
+		_, cmd = g.Update(cmd())
+	}
+	if g.err != "" || g.aiFailure != nil {
+		t.Errorf("Expected the second try to be played, got %q", g.err)
+	}
+	if generator.calls != 2 || len(g.chessGame.Moves()) != 2 {
+		t.Errorf("Expected the AI's e5 played on its second try, got %d calls and %v", generator.calls, g.chessGame.Moves())
+	}
+}
+
+func TestDescribeAIError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{errkind.Errorf(errkind.IllegalMove, "AI failed to make valid move after retry"), "couldn't find a legal move"},
+		{&DesyncError{Ply: 2}, "send it the game and ask again"},
+		{errkind.New(errkind.ProviderUnavailable, "a2a server closed the stream without a reply"), "can't be reached"},
+		{ErrMethodNotFound, "same version"},
+		{errors.New("boom"), "AI error: boom; press enter to ask again"},
+	}
+	for _, tt := range tests {
+		if got := describeAIError(tt.err); !strings.Contains(got, tt.want) {
+			t.Errorf("Expected %v explained with %q, got %q", tt.err, tt.want, got)
+		}
+	}
+}
+
+func TestAIClientErrorKinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   errkind.Kind
+	}{
+		{"timeout", http.StatusOK, `{"jsonrpc":"2.0","id":1,"error":{"code":-32013,"message":"Provider timeout","data":"context deadline exceeded"}}`, errkind.ProviderTimeout},
+		{"illegal", http.StatusOK, `{"jsonrpc":"2.0","id":1,"error":{"code":-32012,"message":"Illegal move","data":"invalid move notation: hello"}}`, errkind.IllegalMove},
+		{"invalid params", http.StatusOK, `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Invalid params"}}`, errkind.ProtocolError},
+		{"desync", http.StatusOK, `{"jsonrpc":"2.0","id":1,"error":{"code":-32010,"message":"Board desync","data":{"ply":1}}}`, errkind.Desync},
+		{"garbled", http.StatusOK, `not json`, errkind.ProtocolError},
+		{"unavailable", http.StatusServiceUnavailable, ``, errkind.ProviderUnavailable},
+		{"not found", http.StatusNotFound, ``, errkind.ProtocolError},
+	}
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+		_, err := NewAIClient(server.URL).GetAIMoveResult("a", []string{"e4"}, "", "white")
+		server.Close()
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected a %s, got %v (%q)", tt.name, tt.want, err, errkind.Of(err))
+		}
+	}
+
+	// Nothing listening leaves the server unavailable
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+	_, err := NewAIClient(url).GetAIMoveResult("a", nil, "", "white")
+	if errkind.Of(err) != errkind.ProviderUnavailable {
+		t.Errorf("Expected an unreachable server to be unavailable, got %v (%q)", err, errkind.Of(err))
+	}
+}
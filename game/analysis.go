@@ -0,0 +1,193 @@
+package game
+
+import (
+	"strings"
+
+	"chess-tui/notation"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// variationNode is a move in the analysis tree. The root has no move.
+type variationNode struct {
+	move     string // SAN
+	parent   *variationNode
+	children []*variationNode
+}
+
+// child returns the child playing san, adding it if it doesn't exist yet
+func (n *variationNode) child(san string) *variationNode {
+	for _, c := range n.children {
+		if c.move == san {
+			return c
+		}
+	}
+	c := &variationNode{move: san, parent: n}
+	n.children = append(n.children, c)
+	return c
+}
+
+// path returns the moves from the root to n
+func (n *variationNode) path() []string {
+	var moves []string
+	for node := n; node.parent != nil; node = node.parent {
+		moves = append([]string{node.move}, moves...)
+	}
+	return moves
+}
+
+// merge adds the lines under other to n, sharing common moves
+func (n *variationNode) merge(other *variationNode) {
+	for _, c := range other.children {
+		n.child(c.move).merge(c)
+	}
+}
+
+// analysisBoard is a scratch board for exploring variations away from the
+// live game, which is set aside untouched until analysis ends
+type analysisBoard struct {
+	live   *chess.Game
+	status string
+	input  string
+
+	ply     int // number of live moves when analysis started
+	root    *variationNode
+	current *variationNode
+}
+
+// enterAnalysis leaves the live game for a scratch board at the same position
+func (g *Game) enterAnalysis() {
+	if g.analysis != nil || g.split != nil || g.isAITurn {
+		return
+	}
+
+	ply := len(g.chessGame.Moves())
+	root := &variationNode{}
+	if saved, ok := g.variations[ply]; ok {
+		// Continue the analysis saved earlier from this position
+		root.merge(saved)
+	}
+
+	g.analysis = &analysisBoard{
+		live:    g.chessGame,
+		status:  g.status,
+		input:   g.input.Value(),
+		ply:     ply,
+		root:    root,
+		current: root,
+	}
+	g.err = ""
+	g.input.SetValue("")
+	g.rebuildAnalysisBoard()
+}
+
+// exitAnalysis returns to the live game, keeping the variation tree if save is set
+func (g *Game) exitAnalysis(save bool) {
+	a := g.analysis
+	if a == nil {
+		return
+	}
+
+	if save && len(a.root.children) > 0 {
+		if g.variations == nil {
+			g.variations = make(map[int]*variationNode)
+		}
+		g.variations[a.ply] = a.root
+	}
+
+	g.chessGame = a.live
+	g.analysis = nil
+	g.err = ""
+	g.input.SetValue(a.input)
+	g.status = a.status
+}
+
+// rebuildAnalysisBoard replays the live game and the current variation onto
+// the scratch board
+func (g *Game) rebuildAnalysisBoard() {
+	a := g.analysis
+	scratch := chess.NewGame(chess.UseNotation(chess.AlgebraicNotation{}))
+	for _, move := range a.live.Moves()[:a.ply] {
+		scratch.Move(move)
+	}
+	for _, san := range a.current.path() {
+		if move, err := notation.Decode(scratch.Position(), san); err == nil {
+			scratch.Move(move)
+		}
+	}
+	g.chessGame = scratch
+	g.updateAnalysisStatus()
+}
+
+// updateAnalysis handles keys while the analysis board is shown
+func (g *Game) updateAnalysis(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	a := g.analysis
+	switch msg.String() {
+	case "v":
+		g.exitAnalysis(true)
+		return g, nil, true
+	case "esc":
+		g.exitAnalysis(false)
+		return g, nil, true
+	case "u":
+		// Step back to the parent position
+		if a.current.parent != nil {
+			a.current = a.current.parent
+			g.rebuildAnalysisBoard()
+		}
+		return g, nil, true
+	case "enter":
+		if g.input.Value() != "" {
+			g.analysisMove(g.input.Value())
+		}
+		return g, nil, true
+	case "r", "o", "t":
+		// Game actions don't apply to the scratch board
+		return g, nil, true
+	}
+	return g, nil, false
+}
+
+// analysisMove plays a move on the scratch board, extending the tree
+func (g *Game) analysisMove(moveStr string) {
+	position := g.chessGame.Position()
+	move, err := notation.Decode(position, moveStr)
+	if err != nil {
+		g.err = "can't play " + moveStr + " in analysis: " + notation.Explain(err)
+		return
+	}
+
+	g.err = ""
+	g.input.SetValue("")
+	a := g.analysis
+	a.current = a.current.child(notation.Encode(position, move))
+	g.rebuildAnalysisBoard()
+}
+
+// updateAnalysisStatus describes the line being analysed
+func (g *Game) updateAnalysisStatus() {
+	line := formatLine(g.analysis.current.path(), g.analysis.ply)
+	if line == "" {
+		line = "start"
+	}
+	g.status = "Analysis — line: " + line + " — [u] back, [v] save & return, [esc] discard"
+}
+
+// formatLine numbers a sequence of SAN moves starting at the given ply
+func formatLine(moves []string, ply int) string {
+	var sb strings.Builder
+	for i, san := range moves {
+		p := ply + i
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		if p%2 == 0 {
+			sb.WriteString(moveNumber(p) + ". ")
+		} else if i == 0 {
+			sb.WriteString(moveNumber(p) + "... ")
+		}
+		sb.WriteString(san)
+	}
+	return sb.String()
+}
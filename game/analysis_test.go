@@ -0,0 +1,82 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pressKey sends a key to the game
+func pressKey(g *Game, key string) {
+	switch key {
+	case "enter":
+		g.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	case "esc":
+		g.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	default:
+		g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+}
+
+// analyse plays a move on the analysis board
+func analyse(g *Game, move string) {
+	g.input.SetValue(move)
+	pressKey(g, "enter")
+}
+
+func TestAnalysisLeavesLiveGameUnchanged(t *testing.T) {
+	g := NewGame()
+	g.makeMove("e4")
+
+	pressKey(g, "v")
+	analyse(g, "e5")
+	analyse(g, "Nf3")
+	if g.err != "" {
+		t.Fatalf("Expected no error, got %s", g.err)
+	}
+	if len(g.chessGame.Moves()) != 3 {
+		t.Errorf("Expected the scratch board to show 3 moves, got %d", len(g.chessGame.Moves()))
+	}
+
+	pressKey(g, "esc")
+	if len(g.chessGame.Moves()) != 1 {
+		t.Errorf("Expected the live game to have 1 move, got %d", len(g.chessGame.Moves()))
+	}
+	if len(g.variations) != 0 {
+		t.Errorf("Expected discarded analysis not to be saved")
+	}
+}
+
+func TestAnalysisSavedAsPGNVariations(t *testing.T) {
+	g := NewGame()
+	g.makeMove("e4")
+
+	pressKey(g, "v")
+	analyse(g, "c5")
+	analyse(g, "Nf3")
+	pressKey(g, "u")
+	analyse(g, "c3")
+	pressKey(g, "u")
+	pressKey(g, "u")
+	analyse(g, "e5")
+	pressKey(g, "v")
+
+	// Before Black moves, the analysis can only be a comment
+	if pgn := g.PGN(); !strings.Contains(pgn, "1. e4 {Analysis: 1... c5 2. Nf3 (2. c3)} {Analysis: 1... e5} *") {
+		t.Errorf("Expected analysis comments, got %s", pgn)
+	}
+
+	g.makeMove("e5")
+	g.makeMove("Nf3")
+	pgn := g.PGN()
+	if !strings.Contains(pgn, "1. e4 e5 (1... c5 2. Nf3 (2. c3)) 2. Nf3 *") {
+		t.Errorf("Expected variations in the PGN, got %s", pgn)
+	}
+
+	// Returning to the saved position continues the same tree
+	pressKey(g, "v")
+	if g.analysis.root != nil && len(g.analysis.root.children) != 0 {
+		t.Errorf("Expected a fresh tree at a new position")
+	}
+}
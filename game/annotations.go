@@ -0,0 +1,297 @@
+package game
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// penColor is a color arrows and highlights can be drawn in
+type penColor struct {
+	letter byte // how PGN's %cal and %csl commands name it
+	name   string
+	hex    string
+}
+
+// annotationColors are the colors of the drawing mode, in the order g, r, y
+// and b pick them
+var annotationColors = []penColor{
+	{'G', "green", "#15781B"},
+	{'R', "red", "#882020"},
+	{'Y', "yellow", "#E68F00"},
+	{'B', "blue", "#003088"},
+}
+
+// annotation is an arrow drawn on the board, or a highlighted square when
+// from and to are the same
+type annotation struct {
+	color    byte // one of annotationColors' letters
+	from, to chess.Square
+}
+
+// isSquare reports whether the annotation highlights a square rather than
+// drawing an arrow
+func (a annotation) isSquare() bool {
+	return a.from == a.to
+}
+
+// String writes the annotation as in %cal and %csl, e.g. "Ge2e4" or "Rd5"
+func (a annotation) String() string {
+	if a.isSquare() {
+		return string(a.color) + a.from.String()
+	}
+	return string(a.color) + a.from.String() + a.to.String()
+}
+
+// annotator is the keyboard-driven drawing mode: a cursor moves over the
+// board, and marking a square then another draws an arrow between them
+type annotator struct {
+	cursor   chess.Square
+	from     chess.Square // where the arrow being drawn starts
+	anchored bool         // from is set
+	color    int          // index into annotationColors
+}
+
+// startAnnotating enters the drawing mode on the current position
+func (g *Game) startAnnotating() {
+	switch {
+	case g.analysis != nil:
+		g.status = "Annotations are drawn on the game, not the analysis board"
+		return
+	case len(g.chessGame.Moves()) == 0:
+		// PGN has no move to hang the annotations on yet
+		g.status = "Make a move before annotating"
+		return
+	}
+	cursor := chess.E4
+	if moves := g.chessGame.Moves(); len(moves) > 0 {
+		cursor = moves[len(moves)-1].S2()
+	}
+	g.annotating = &annotator{cursor: cursor}
+	g.updateAnnotatorStatus()
+}
+
+// updateAnnotator handles a key in the drawing mode
+func (g *Game) updateAnnotator(msg tea.KeyMsg) tea.Cmd {
+	a := g.annotating
+	switch key := msg.String(); key {
+	case "up", "k", "down", "j", "left", "h", "right", "l":
+		a.cursor = g.moveCursor(a.cursor, key)
+	case " ", "enter":
+		switch {
+		case !a.anchored:
+			a.from, a.anchored = a.cursor, true
+		default:
+			g.toggleAnnotation(annotation{color: annotationColors[a.color].letter, from: a.from, to: a.cursor})
+			a.anchored = false
+		}
+	case "g", "r", "y", "b":
+		for i, color := range annotationColors {
+			if color.letter == strings.ToUpper(key)[0] {
+				a.color = i
+			}
+		}
+	case "c":
+		delete(g.annotations, len(g.chessGame.Moves()))
+		a.anchored = false
+	case "esc", "A":
+		if a.anchored {
+			a.anchored = false
+			break
+		}
+		g.annotating = nil
+		g.updateStatus()
+		return nil
+	case "ctrl+c":
+		g.shutdown()
+		return tea.Quit
+	}
+	g.updateAnnotatorStatus()
+	return nil
+}
+
+// moveCursor moves a square one step in the direction of an arrow or vi
+// key, as the board is drawn
+func (g *Game) moveCursor(square chess.Square, key string) chess.Square {
+	rank, file := int(square.Rank()), int(square.File())
+	step := 1
+	if g.flipped() {
+		step = -1
+	}
+	switch key {
+	case "up", "k":
+		rank += step
+	case "down", "j":
+		rank -= step
+	case "left", "h":
+		file -= step
+	case "right", "l":
+		file += step
+	}
+	rank, file = min(max(rank, 0), 7), min(max(file, 0), 7)
+	return chess.NewSquare(chess.File(file), chess.Rank(rank))
+}
+
+// toggleAnnotation draws an annotation on the current position, or erases
+// it when it is already there in the same color. Drawing over one in
+// another color recolors it.
+func (g *Game) toggleAnnotation(drawn annotation) {
+	ply := len(g.chessGame.Moves())
+	if g.annotations == nil {
+		g.annotations = make(map[int][]annotation)
+	}
+	list := g.annotations[ply]
+	for i, existing := range list {
+		if existing.from == drawn.from && existing.to == drawn.to {
+			if existing.color == drawn.color {
+				list = slices.Delete(list, i, i+1)
+			} else {
+				list[i] = drawn
+			}
+			g.setAnnotations(ply, list)
+			return
+		}
+	}
+	g.setAnnotations(ply, append(list, drawn))
+}
+
+// setAnnotations replaces the annotations of a position
+func (g *Game) setAnnotations(ply int, list []annotation) {
+	if len(list) == 0 {
+		delete(g.annotations, ply)
+		return
+	}
+	g.annotations[ply] = list
+}
+
+// updateAnnotatorStatus explains the drawing mode in the status line
+func (g *Game) updateAnnotatorStatus() {
+	a := g.annotating
+	color := annotationColors[a.color].name
+	if a.anchored {
+		g.status = fmt.Sprintf("Annotate — %s arrow from %s: move to the end and press space (same square for a highlight)", color, a.from)
+		return
+	}
+	g.status = fmt.Sprintf("Annotate — %s at %s: arrows/hjkl move, space marks, g/r/y/b color, c clear, esc done", color, a.cursor)
+}
+
+// currentAnnotations returns the annotations of the position on the board
+func (g *Game) currentAnnotations() []annotation {
+	if g.analysis != nil {
+		return nil
+	}
+	return g.annotations[len(g.chessGame.Moves())]
+}
+
+// annotationColor returns the color a square is tinted by the annotations:
+// highlighted squares and the ends of arrows
+func (g *Game) annotationColor(square chess.Square) (string, bool) {
+	color, found := "", false
+	for _, a := range g.currentAnnotations() {
+		if a.from == square || a.to == square {
+			color, found = annotationHex(a.color), true
+		}
+	}
+	return color, found
+}
+
+// annotationHex returns the color of a %cal/%csl color letter
+func annotationHex(letter byte) string {
+	for _, color := range annotationColors {
+		if color.letter == letter {
+			return color.hex
+		}
+	}
+	return annotationColors[0].hex
+}
+
+// renderAnnotationPanel lists the arrows and highlights of the position,
+// which the board can only show as tinted squares
+func (g *Game) renderAnnotationPanel() string {
+	list := g.currentAnnotations()
+	if len(list) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Render("Annotations"))
+	for _, a := range list {
+		swatch := lipgloss.NewStyle().Foreground(lipgloss.Color(annotationHex(a.color))).Render("■")
+		if a.isSquare() {
+			sb.WriteString(fmt.Sprintf("\n%s %s", swatch, a.from))
+		} else {
+			sb.WriteString(fmt.Sprintf("\n%s %s→%s", swatch, a.from, a.to))
+		}
+	}
+	return lipgloss.NewStyle().PaddingLeft(3).Render(sb.String())
+}
+
+// annotationComment writes annotations as a PGN comment with %csl and %cal
+// commands, e.g. "{[%csl Gd5][%cal Ge2e4,Rg8f6]}"
+func annotationComment(list []annotation) string {
+	var squares, arrows []string
+	for _, a := range list {
+		if a.isSquare() {
+			squares = append(squares, a.String())
+		} else {
+			arrows = append(arrows, a.String())
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString("{")
+	if len(squares) > 0 {
+		sb.WriteString("[%csl " + strings.Join(squares, ",") + "]")
+	}
+	if len(arrows) > 0 {
+		sb.WriteString("[%cal " + strings.Join(arrows, ",") + "]")
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// annotationCommandRe finds %csl and %cal commands in a PGN comment
+var annotationCommandRe = regexp.MustCompile(`\[%(csl|cal)\s+([^\]]*)\]`)
+
+// annotationsFromComments reads the %csl and %cal annotations from a game's
+// comments, indexed by move as chess.Game.Comments returns them
+func annotationsFromComments(comments [][]string) map[int][]annotation {
+	annotations := make(map[int][]annotation)
+	for i, moveComments := range comments {
+		for _, comment := range moveComments {
+			for _, command := range annotationCommandRe.FindAllStringSubmatch(comment, -1) {
+				for _, field := range strings.Split(command[2], ",") {
+					if a, ok := parseAnnotation(strings.TrimSpace(field), command[1] == "cal"); ok {
+						annotations[i+1] = append(annotations[i+1], a)
+					}
+				}
+			}
+		}
+	}
+	return annotations
+}
+
+// parseAnnotation reads one %csl square, e.g. "Gd5", or %cal arrow, e.g. "Ge2e4"
+func parseAnnotation(field string, arrow bool) (annotation, bool) {
+	if (arrow && len(field) != 5) || (!arrow && len(field) != 3) {
+		return annotation{}, false
+	}
+	a := annotation{color: field[0]}
+	if !slices.ContainsFunc(annotationColors, func(c penColor) bool { return c.letter == a.color }) {
+		return annotation{}, false
+	}
+	var ok bool
+	if a.from, ok = parseSquare(field[1:3]); !ok {
+		return annotation{}, false
+	}
+	a.to = a.from
+	if arrow {
+		if a.to, ok = parseSquare(field[3:5]); !ok {
+			return annotation{}, false
+		}
+	}
+	return a, true
+}
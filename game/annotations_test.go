@@ -0,0 +1,99 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func annotateKeys(g *Game, keys ...string) {
+	for _, key := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		switch key {
+		case "space":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		g.Update(msg)
+	}
+}
+
+func TestAnnotationMode(t *testing.T) {
+	g := NewGame()
+	annotateKeys(g, "A")
+	if g.annotating != nil {
+		t.Fatal("Expected no drawing mode before the first move")
+	}
+
+	g.makeMove("e4")
+	annotateKeys(g, "A")
+	if g.annotating == nil || g.annotating.cursor != chess.E4 {
+		t.Fatal("Expected the drawing mode to start on the last move's square")
+	}
+
+	// A red arrow from e4 to e6, then a green highlight on d5
+	annotateKeys(g, "r", "space", "k", "k", "space")
+	annotateKeys(g, "g", "j", "h", "space", "space")
+	want := []annotation{{'R', chess.E4, chess.E6}, {'G', chess.D5, chess.D5}}
+	if got := g.annotations[1]; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("Expected annotations %v, got %v", want, got)
+	}
+	if !strings.Contains(g.View(), "e4→e6") {
+		t.Error("Expected the panel to list the arrow")
+	}
+
+	// Drawing the same highlight again erases it
+	annotateKeys(g, "space", "space")
+	if len(g.annotations[1]) != 1 {
+		t.Errorf("Expected the highlight to be erased, got %v", g.annotations[1])
+	}
+
+	annotateKeys(g, "esc")
+	if g.annotating != nil {
+		t.Error("Expected esc to leave the drawing mode")
+	}
+
+	// The annotations belong to the position they were drawn on
+	g.makeMove("e5")
+	if len(g.currentAnnotations()) != 0 {
+		t.Error("Expected no annotations on the next position")
+	}
+}
+
+func TestAnnotationsRoundTripThroughPGN(t *testing.T) {
+	g := NewGame()
+	g.makeMove("e4")
+	g.toggleAnnotation(annotation{'G', chess.D5, chess.D5})
+	g.toggleAnnotation(annotation{'G', chess.G1, chess.F3})
+	g.toggleAnnotation(annotation{'B', chess.E4, chess.E5})
+	g.makeMove("e5")
+
+	pgn := g.PGN()
+	if !strings.Contains(pgn, "1. e4 {[%csl Gd5][%cal Gg1f3,Be4e5]} 1... e5") {
+		t.Fatalf("Expected the annotations as %%csl and %%cal, got %s", pgn)
+	}
+
+	r, err := NewReplayFromPGN(strings.NewReader(pgn), DefaultSettings())
+	if err != nil {
+		t.Fatalf("Expected the PGN to load, got %v", err)
+	}
+	if got := r.game.annotations[1]; len(got) != 3 || got[2] != (annotation{'B', chess.E4, chess.E5}) {
+		t.Errorf("Expected the annotations to be read back, got %v", got)
+	}
+}
+
+func TestAnnotationsFromLichessComments(t *testing.T) {
+	got := annotationsFromComments([][]string{{}, {"Good move [%csl Rf7][%cal Gd1h5, Xa1a2,Gf1c4]"}})
+	want := []annotation{{'R', chess.F7, chess.F7}, {'G', chess.D1, chess.H5}, {'G', chess.F1, chess.C4}}
+	if len(got[2]) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[2][i] != want[i] {
+			t.Errorf("Expected %v, got %v", want[i], got[2][i])
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package game
+
+import (
+	"fmt"
+	"os"
+)
+
+// LowBandwidthFPS is the frame rate of low-bandwidth mode: updates made
+// between frames go out together, in one write
+const LowBandwidthFPS = 10
+
+// DetectLowBandwidth reports whether the TUI seems to be running over SSH,
+// where low-bandwidth mode keeps a slow link responsive
+func DetectLowBandwidth() bool {
+	return detectLowBandwidth(os.Getenv)
+}
+
+// detectLowBandwidth tells from the environment whether the terminal is at
+// the far end of an SSH connection, or is a serial console
+func detectLowBandwidth(getenv func(string) string) bool {
+	for _, key := range []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"} {
+		if getenv(key) != "" {
+			return true
+		}
+	}
+	switch getenv("TERM") {
+	case "vt100", "vt102", "vt220", "dumb":
+		return true
+	}
+	return false
+}
+
+// graphics returns the graphics protocol to draw the image board with, none
+// in low-bandwidth mode
+func (g *Game) graphics() string {
+	if g.settings.LowBandwidth {
+		return GraphicsNone
+	}
+	return graphicsProtocol()
+}
+
+// boardColored reports whether the board is drawn in color
+func (g *Game) boardColored() bool {
+	return colorEnabled() && !g.settings.LowBandwidth
+}
+
+// renderCachedBoard draws the board only when something on it has changed,
+// so typing a move doesn't redraw it
+func (g *Game) renderCachedBoard() string {
+	key := fmt.Sprint(g.settings.BoardStyle, g.settings.Figurine, g.boardRanks(), g.boardFiles(), g.boardKey())
+	if g.boardText.key != key {
+		g.boardText = boardCache{key: key, data: g.drawBoard()}
+	}
+	return g.boardText.data
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestDetectLowBandwidth(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want bool
+	}{
+		{map[string]string{"TERM": "xterm-256color"}, false},
+		{map[string]string{"TERM": "xterm-256color", "SSH_CONNECTION": "10.0.0.2 51000 10.0.0.1 22"}, true},
+		{map[string]string{"SSH_TTY": "/dev/pts/3"}, true},
+		{map[string]string{"TERM": "vt220"}, true},
+	}
+	for _, tt := range tests {
+		if got := detectLowBandwidth(func(key string) string { return tt.env[key] }); got != tt.want {
+			t.Errorf("%v: expected %v, got %v", tt.env, tt.want, got)
+		}
+	}
+}
+
+func TestLowBandwidthBoard(t *testing.T) {
+	previous := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(func() { lipgloss.SetColorProfile(previous) })
+	withGraphics(t, GraphicsKitty)
+
+	g := NewGameWithSettings(ModeHumanVsHuman, &Settings{BoardStyle: BoardStyleImage, LowBandwidth: true})
+	board := g.renderBoard()
+	if strings.Contains(board, "\x1b") {
+		t.Errorf("Expected a plain board without escapes, got %q", board)
+	}
+	if !strings.Contains(board, "·") {
+		t.Errorf("Expected dark squares dotted on the plain board, got:\n%s", board)
+	}
+
+	// Typing a move doesn't draw the board again
+	key := g.boardText.key
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	g.View()
+	if g.boardText.key != key {
+		t.Error("Expected the board kept while a move is typed")
+	}
+
+	g.makeMove("e4")
+	if g.renderBoard() == board {
+		t.Error("Expected the board drawn again after a move")
+	}
+	if got := nextBoardStyle(BoardStyleLarge, g.graphics()); got != BoardStyleStandard {
+		t.Errorf("Expected the image style skipped in low-bandwidth mode, got %s", got)
+	}
+}
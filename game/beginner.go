@@ -0,0 +1,51 @@
+package game
+
+import (
+	"fmt"
+
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// hintedPiece returns the piece the beginner hints explain: the one under
+// the move cursor, while no piece is picked up
+func (g *Game) hintedPiece() (chess.Piece, chess.Square, bool) {
+	p := g.picking
+	if !g.settings.BeginnerHints || p == nil || p.holding {
+		return chess.NoPiece, chess.NoSquare, false
+	}
+	piece := g.chessGame.Position().Board().Piece(p.cursor)
+	return piece, p.cursor, piece != chess.NoPiece
+}
+
+// hinting reports whether the board shows a piece's movement pattern
+func (g *Game) hinting() bool {
+	_, _, ok := g.hintedPiece()
+	return ok
+}
+
+// hintPattern returns the squares the hinted piece's moves reach on an
+// empty board, blocked or not
+func (g *Game) hintPattern() []chess.Square {
+	piece, square, ok := g.hintedPiece()
+	if !ok {
+		return nil
+	}
+	return notation.Pattern(piece, square)
+}
+
+// hintStatus names the hinted piece and says how it moves, e.g.
+// "White knight on g1: The knight jumps in an L, ..."
+func (g *Game) hintStatus() string {
+	piece, square, _ := g.hintedPiece()
+	name := pieceNames[piece.Type()]
+	return fmt.Sprintf("%s %s on %s: %s (? hides hints)", piece.Color().Name(), name, square, notation.Rule(piece.Type()))
+}
+
+// dimColor darkens a "#RRGGBB" color to half its brightness, for the squares
+// around a hinted piece's pattern
+func dimColor(hex string) string {
+	c := parseHexColor(hex)
+	return fmt.Sprintf("#%02X%02X%02X", c.R/2, c.G/2, c.B/2)
+}
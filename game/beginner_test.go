@@ -0,0 +1,53 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func TestBeginnerHintsShowBlockedPattern(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsHuman)
+	keyHints := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")}
+
+	palette := paletteFor("")
+	undimmed := g.squareColor(chess.A1, palette, markNone)
+
+	// The cursor starts on e2; the bishop on f1 is hemmed in by its own pawns
+	pressKeys(g, keyTab, keyDown, keyRight, keyHints)
+	if !g.settings.BeginnerHints || !g.hinting() {
+		t.Fatalf("Expected hints for the bishop on f1, got cursor at %s", g.picking.cursor)
+	}
+	if !strings.Contains(g.status, "White bishop on f1: The bishop moves") {
+		t.Errorf("Expected the bishop's rule in the status line, got %q", g.status)
+	}
+
+	marks := g.squareMarks()
+	for _, square := range []chess.Square{chess.E2, chess.A6, chess.H3} {
+		if marks[square] != markPattern {
+			t.Errorf("Expected %s in the bishop's pattern, got mark %d", square, marks[square])
+		}
+	}
+	if got, want := g.squareColor(chess.A1, palette, marks[chess.A1]), dimColor(undimmed); got != want {
+		t.Errorf("Expected a1 dimmed to %s, got %s", want, got)
+	}
+
+	// Picking a piece up shows its legal moves instead
+	pressKeys(g, keyLeft, keyLeft, keyLeft, keyLeft, keyLeft, keyRight, keySpace)
+	if g.hinting() {
+		t.Errorf("Expected no hints while holding a piece")
+	}
+
+	pressKeys(g, keyEsc, keyHints)
+	if g.settings.BeginnerHints || g.hinting() {
+		t.Errorf("Expected ? to turn the hints off")
+	}
+}
+
+func TestDimColor(t *testing.T) {
+	if got := dimColor("#F0D9B5"); got != "#786C5A" {
+		t.Errorf("Expected #786C5A, got %s", got)
+	}
+}
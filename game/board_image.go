@@ -0,0 +1,270 @@
+package game
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/notnil/chess"
+)
+
+// Geometry of the image board: each square takes imageSquareCols by
+// imageSquareRows terminal cells, which is close to square in most fonts,
+// and imageSquarePixels pixels a side
+const (
+	imageSquareCols   = 6
+	imageSquareRows   = 3
+	imageSquarePixels = spriteSize * 3
+)
+
+// kittyImageID identifies the board among the kitty terminal's images, so
+// each new board replaces the last
+const kittyImageID = 4242
+
+// kittyChunkSize is the most base64 the kitty protocol takes per escape
+const kittyChunkSize = 4096
+
+// spriteOutline is the color pieces are outlined in
+var spriteOutline = color.RGBA{0x20, 0x20, 0x20, 0xff}
+
+// sixelFrame changes with every image board drawn with sixel; see renderImageBoard
+var sixelFrame atomic.Uint64
+
+// boardCache keeps the last board drawn, so an unchanged board isn't drawn
+// again on every frame
+type boardCache struct {
+	key  string
+	data string
+}
+
+// renderImageBoard draws the board as a bitmap with the terminal's graphics
+// protocol. The text it returns reserves the board's cells, with rank and
+// file labels around them, and carries the image in an escape sequence that
+// saves and restores the cursor, so the layout around it is undisturbed.
+func (g *Game) renderImageBoard(protocol string) string {
+	width := 8 * imageSquareCols
+	height := 8 * imageSquareRows
+	ranks, files := g.boardRanks(), g.boardFiles()
+
+	key := protocol + " " + g.boardKey()
+	if g.boardImage.key != key {
+		img := g.drawBoardImage()
+		if protocol == GraphicsKitty {
+			g.boardImage.data = encodeKitty(img, width, height)
+		} else {
+			g.boardImage.data = encodeSixel(img)
+		}
+		g.boardImage.key = key
+	}
+
+	blank := strings.Repeat(" ", width)
+	lines := make([]string, 0, height+1)
+	for i := 0; i < height; i++ {
+		label := "   "
+		if i%imageSquareRows == imageSquareRows/2 {
+			label = fmt.Sprintf(" %d ", ranks[i/imageSquareRows]+1)
+		}
+		lines = append(lines, label+blank)
+	}
+
+	if protocol == GraphicsKitty {
+		// Kitty draws images over text, so the blank cells don't hide it
+		lines[0] = "   \x1b7" + g.boardImage.data + "\x1b8" + blank
+	} else {
+		// Sixel pixels are erased by any text written over them, and the
+		// renderer rewrites every changed line, so the image goes on the
+		// last line, drawn upward, and changes every frame to be redrawn
+		up := fmt.Sprintf("\x1b[%dA", height-1)
+		nonce := strings.Repeat("\x1b[0m", int(sixelFrame.Add(1)%2)+1)
+		lines[height-1] = lines[height-1][:3] + "\x1b7" + up + g.boardImage.data + "\x1b8" + nonce + blank
+	}
+
+	var footer strings.Builder
+	footer.WriteString("   ")
+	for _, file := range files {
+		footer.WriteString(fmt.Sprintf("%-*c", imageSquareCols, 'a'+file))
+	}
+	lines = append(lines, strings.TrimRight(footer.String(), " "))
+	return strings.Join(lines, "\n")
+}
+
+// boardKey describes everything the board shows, to tell when it needs
+// drawing again
+func (g *Game) boardKey() string {
+	palette := paletteFor(g.settings.Palette)
+	marks := g.squareMarks()
+	var key strings.Builder
+	key.WriteString(g.chessGame.Position().Board().String())
+	for _, rank := range g.boardRanks() {
+		for _, file := range g.boardFiles() {
+			square := chess.Square(rank*8 + file)
+			key.WriteString(g.squareColor(square, palette, marks[square]))
+		}
+	}
+	return key.String()
+}
+
+// drawBoardImage paints the board, squares and pieces, as the player sees it
+func (g *Game) drawBoardImage() *image.RGBA {
+	size := 8 * imageSquarePixels
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	board := g.chessGame.Position().Board()
+	palette := paletteFor(g.settings.Palette)
+	marks := g.squareMarks()
+	scale := imageSquarePixels / spriteSize
+
+	for row, rank := range g.boardRanks() {
+		for col, file := range g.boardFiles() {
+			square := chess.Square(rank*8 + file)
+			background := parseHexColor(g.squareColor(square, palette, marks[square]))
+			x0, y0 := col*imageSquarePixels, row*imageSquarePixels
+			for y := y0; y < y0+imageSquarePixels; y++ {
+				for x := x0; x < x0+imageSquarePixels; x++ {
+					img.SetRGBA(x, y, background)
+				}
+			}
+
+			piece := board.Piece(square)
+			if piece == chess.NoPiece {
+				continue
+			}
+			fill := parseHexColor(palette.WhitePiece)
+			if piece.Color() == chess.Black {
+				fill = parseHexColor(palette.BlackPiece)
+			}
+			sprite := pieceSprites[piece.Type()]
+			for sy, line := range sprite {
+				for sx := 0; sx < len(line); sx++ {
+					var c color.RGBA
+					switch line[sx] {
+					case '#':
+						c = fill
+					case 'o':
+						c = spriteOutline
+					default:
+						continue
+					}
+					for dy := 0; dy < scale; dy++ {
+						for dx := 0; dx < scale; dx++ {
+							img.SetRGBA(x0+sx*scale+dx, y0+sy*scale+dy, c)
+						}
+					}
+				}
+			}
+		}
+	}
+	return img
+}
+
+// parseHexColor reads a "#RRGGBB" color, or returns gray for anything else
+func parseHexColor(hex string) color.RGBA {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(strings.TrimPrefix(hex, "#")) != 6 {
+		return color.RGBA{0x80, 0x80, 0x80, 0xff}
+	}
+	return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff}
+}
+
+// encodeKitty encodes an image for the kitty graphics protocol as a PNG
+// scaled to cols by rows cells, replacing the previous board and leaving the
+// cursor where it was
+func encodeKitty(img image.Image, cols, rows int) string {
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", kittyImageID))
+	for first := true; first || data != ""; first = false {
+		chunk := data
+		if len(chunk) > kittyChunkSize {
+			chunk = chunk[:kittyChunkSize]
+		}
+		data = data[len(chunk):]
+		more := 0
+		if data != "" {
+			more = 1
+		}
+		if first {
+			sb.WriteString(fmt.Sprintf("\x1b_Ga=T,f=100,i=%d,c=%d,r=%d,C=1,q=2,m=%d;%s\x1b\\", kittyImageID, cols, rows, more, chunk))
+		} else {
+			sb.WriteString(fmt.Sprintf("\x1b_Gm=%d;%s\x1b\\", more, chunk))
+		}
+	}
+	return sb.String()
+}
+
+// encodeSixel encodes an image of up to 256 colors as sixel graphics
+func encodeSixel(img *image.RGBA) string {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// Number the image's colors
+	index := make(map[color.RGBA]int)
+	var colors []color.RGBA
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := img.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+			if _, ok := index[c]; !ok && len(colors) < 256 {
+				index[c] = len(colors)
+				colors = append(colors, c)
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\x1bPq")
+	sb.WriteString(fmt.Sprintf("\"1;1;%d;%d", width, height))
+	for i, c := range colors {
+		sb.WriteString(fmt.Sprintf("#%d;2;%d;%d;%d", i, int(c.R)*100/255, int(c.G)*100/255, int(c.B)*100/255))
+	}
+
+	// Each band is six rows; each color in it is one pass over the columns
+	row := make([]byte, width)
+	for band := 0; band < height; band += 6 {
+		for i, c := range colors {
+			used := false
+			for x := 0; x < width; x++ {
+				bits := byte(0)
+				for dy := 0; dy < 6 && band+dy < height; dy++ {
+					if img.RGBAAt(bounds.Min.X+x, bounds.Min.Y+band+dy) == c {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+				used = used || bits != 0
+			}
+			if !used {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("#%d", i))
+			writeSixelRow(&sb, row)
+			sb.WriteByte('$')
+		}
+		sb.WriteByte('-')
+	}
+	sb.WriteString("\x1b\\")
+	return sb.String()
+}
+
+// writeSixelRow writes a pass of sixel characters, run-length encoded
+func writeSixelRow(sb *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			sb.WriteString(fmt.Sprintf("!%d%c", n, row[i]))
+		} else {
+			sb.WriteString(strings.Repeat(string(row[i]), n))
+		}
+		i = j
+	}
+}
@@ -0,0 +1,151 @@
+package game
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// withGraphics pretends the terminal speaks protocol for the rest of the test
+func withGraphics(t *testing.T, protocol string) {
+	previous := graphicsProtocol()
+	graphicsDetected = protocol
+	t.Cleanup(func() { graphicsDetected = previous })
+}
+
+func TestDetectGraphics(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"TERM": "xterm-kitty"}, GraphicsKitty},
+		{map[string]string{"TERM": "xterm-256color", "KITTY_WINDOW_ID": "1"}, GraphicsKitty},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, GraphicsKitty},
+		{map[string]string{"TERM": "foot"}, GraphicsSixel},
+		{map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app"}, GraphicsSixel},
+		{map[string]string{"TERM": "xterm-256color"}, GraphicsNone},
+		{map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux-1000/default,1,0"}, GraphicsNone},
+		{map[string]string{"TERM": "screen-256color", "BUBBLECHESS_GRAPHICS": "Sixel"}, GraphicsSixel},
+		{map[string]string{"TERM": "xterm-kitty", "BUBBLECHESS_GRAPHICS": "none"}, GraphicsNone},
+	}
+	for _, tt := range tests {
+		if got := detectGraphics(func(key string) string { return tt.env[key] }); got != tt.want {
+			t.Errorf("%v: expected %s, got %s", tt.env, tt.want, got)
+		}
+	}
+}
+
+func TestPieceSpritesAreSquare(t *testing.T) {
+	for piece, sprite := range pieceSprites {
+		for i, line := range sprite {
+			if len(line) != spriteSize || strings.Trim(line, ".#o") != "" {
+				t.Errorf("Piece %v row %d: expected %d of '.', '#' and 'o', got %q", piece, i, spriteSize, line)
+			}
+		}
+	}
+	if len(pieceSprites) != 6 {
+		t.Errorf("Expected a sprite for each of the 6 piece types, got %d", len(pieceSprites))
+	}
+}
+
+func TestImageBoardFallsBackToText(t *testing.T) {
+	withGraphics(t, GraphicsNone)
+	g := NewGameWithSettings(ModeHumanVsHuman, &Settings{BoardStyle: BoardStyleImage})
+	if board := g.renderBoard(); strings.Contains(board, "\x1b_G") || strings.Contains(board, "\x1bP") || !strings.Contains(board, "a  b  c") {
+		t.Errorf("Expected the text board without graphics, got:\n%s", board)
+	}
+
+	if got := nextBoardStyle(BoardStyleLarge, GraphicsNone); got != BoardStyleStandard {
+		t.Errorf("Expected the image style skipped without graphics, got %s", got)
+	}
+	for style, want := range map[string]string{BoardStyleStandard: BoardStyleLarge, BoardStyleLarge: BoardStyleImage, BoardStyleImage: BoardStyleStandard} {
+		if got := nextBoardStyle(style, GraphicsKitty); got != want {
+			t.Errorf("Expected %s after %s, got %s", want, style, got)
+		}
+	}
+}
+
+func TestKittyImageBoard(t *testing.T) {
+	withGraphics(t, GraphicsKitty)
+	g := NewGameWithSettings(ModeHumanVsHuman, &Settings{BoardStyle: BoardStyleImage})
+	board := g.renderBoard()
+
+	lines := strings.Split(board, "\n")
+	if len(lines) != 8*imageSquareRows+1 {
+		t.Fatalf("Expected %d lines, got %d", 8*imageSquareRows+1, len(lines))
+	}
+	for i, line := range lines[:8*imageSquareRows] {
+		if w := lipgloss.Width(line); w != 3+8*imageSquareCols {
+			t.Errorf("Line %d: expected the board to take %d cells, got %d", i, 3+8*imageSquareCols, w)
+		}
+	}
+	if !strings.Contains(lines[1], " 8 ") || !strings.HasPrefix(lines[len(lines)-1], "   a     b") {
+		t.Errorf("Expected rank and file labels, got %q and %q", lines[1], lines[len(lines)-1])
+	}
+
+	// The image is a PNG of the whole board, sent in chunks
+	chunks := regexp.MustCompile(`\x1b_G[^;\x1b]*;([^\x1b]*)\x1b\\`).FindAllStringSubmatch(lines[0], -1)
+	var data strings.Builder
+	for _, chunk := range chunks {
+		data.WriteString(chunk[1])
+	}
+	raw, err := base64.StdEncoding.DecodeString(data.String())
+	if err != nil {
+		t.Fatalf("Expected base64 image data, got %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("Expected a PNG, got %v", err)
+	}
+	if size := img.Bounds().Dx(); size != 8*imageSquarePixels {
+		t.Errorf("Expected a %dpx board, got %dpx", 8*imageSquarePixels, size)
+	}
+	// a8 holds a rook; its corner shows the square's color as on the text board
+	want := parseHexColor(g.squareColor(chess.A8, paletteFor(""), markNone))
+	if got := color.RGBAModel.Convert(img.At(0, 0)).(color.RGBA); got != want {
+		t.Errorf("Expected a8's corner in %v, got %v", want, got)
+	}
+
+	// An unchanged board isn't encoded again
+	cached := g.boardImage.data
+	g.renderBoard()
+	if g.boardImage.data != cached {
+		t.Errorf("Expected the cached image for an unchanged board")
+	}
+}
+
+func TestSixelImageBoardRedrawsEveryFrame(t *testing.T) {
+	withGraphics(t, GraphicsSixel)
+	g := NewGameWithSettings(ModeHumanVsHuman, &Settings{BoardStyle: BoardStyleImage})
+	first, second := g.renderBoard(), g.renderBoard()
+	if first == second {
+		t.Errorf("Expected successive sixel boards to differ so the renderer redraws them")
+	}
+	last := strings.Split(first, "\n")[8*imageSquareRows-1]
+	if !strings.Contains(last, "\x1bPq") || lipgloss.Width(last) != 3+8*imageSquareCols {
+		t.Errorf("Expected the sixel image on the board's last line, got %q", last)
+	}
+}
+
+func TestEncodeSixel(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 5, 2))
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	for x := 0; x < 5; x++ {
+		img.SetRGBA(x, 0, red)
+		img.SetRGBA(x, 1, red)
+	}
+	img.SetRGBA(4, 1, color.RGBA{0, 0, 0xff, 0xff})
+
+	want := "\x1bPq\"1;1;5;2#0;2;100;0;0#1;2;0;0;100#0!4B@$#1!4?A$-\x1b\\"
+	if got := encodeSixel(img); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
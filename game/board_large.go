@@ -0,0 +1,65 @@
+package game
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// Board render styles
+const (
+	BoardStyleStandard = "standard"
+	BoardStyleLarge    = "large"
+	BoardStyleImage    = "image" // a bitmap, on terminals with graphics
+)
+
+// largeCellWidth is the width of a square in the large board style
+const largeCellWidth = 7
+
+// renderLargeBoard renders the board using two terminal rows per rank and
+// wide cells, with each square's coordinate embedded in its top-left corner
+func (g *Game) renderLargeBoard() string {
+	board := g.chessGame.Position().Board()
+	palette := paletteFor(g.settings.Palette)
+	marks := g.squareMarks()
+	colored := g.boardColored()
+
+	var sb strings.Builder
+	for i, rank := range g.boardRanks() {
+		var top, bottom strings.Builder
+		for _, file := range g.boardFiles() {
+			square := chess.Square(rank*8 + file)
+			piece := board.Piece(square)
+			style, symbol := g.squareStyle(square, piece, palette, marks[square], colored)
+
+			// The coordinate row is dimmer so it reads as a label, not a piece
+			label := style.Bold(false).Faint(true).Width(largeCellWidth).Align(lipgloss.Left)
+			top.WriteString(label.Render(square.String()))
+
+			cell := style.Width(largeCellWidth).Align(lipgloss.Center)
+			bottom.WriteString(cell.Render(marks[square].decorate(symbol)))
+		}
+		sb.WriteString(top.String() + "\n")
+		sb.WriteString(bottom.String())
+		if i < 7 {
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// nextBoardStyle cycles through the board styles, then those of the
+// registered board renderers, skipping the image board when the terminal
+// has no graphics protocol
+func nextBoardStyle(style, protocol string) string {
+	styles := []string{BoardStyleStandard, BoardStyleLarge}
+	if protocol != GraphicsNone {
+		styles = append(styles, BoardStyleImage)
+	}
+	styles = append(styles, boardRenderers()...)
+	i := max(slices.Index(styles, style), 0)
+	return styles[(i+1)%len(styles)]
+}
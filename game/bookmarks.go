@@ -0,0 +1,130 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"chess-tui/gamedb"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bookmarkPrefix starts the PGN comments that hold bookmarks
+const bookmarkPrefix = "Bookmark"
+
+// startBookmark opens the prompt for the note of a bookmark on the current position
+func (g *Game) startBookmark() tea.Cmd {
+	if len(g.chessGame.Moves()) == 0 {
+		// PGN has no move to hang the comment on yet
+		g.status = "Make a move before bookmarking"
+		return nil
+	}
+	input := textinput.New()
+	input.Placeholder = "optional note"
+	input.CharLimit = 80
+	input.Width = 40
+	input.Focus()
+	g.bookmarkInput = &input
+	return textinput.Blink
+}
+
+// updateBookmark handles a key while the note prompt is open: enter saves
+// the bookmark and esc drops it
+func (g *Game) updateBookmark(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		g.addBookmark(strings.TrimSpace(g.bookmarkInput.Value()))
+		g.bookmarkInput = nil
+		return nil
+	case "esc":
+		g.bookmarkInput = nil
+		return nil
+	}
+	var cmd tea.Cmd
+	*g.bookmarkInput, cmd = g.bookmarkInput.Update(msg)
+	return cmd
+}
+
+// addBookmark bookmarks the current position, replacing the note of an
+// earlier bookmark on it
+func (g *Game) addBookmark(note string) {
+	ply := len(g.chessGame.Moves())
+	for i, bookmark := range g.bookmarks {
+		if bookmark.Ply == ply {
+			g.bookmarks[i].Note = note
+			g.status = "Bookmark updated"
+			return
+		}
+	}
+	g.bookmarks = append(g.bookmarks, gamedb.Bookmark{Ply: ply, Note: note})
+	g.status = "Bookmarked " + plyLabel(ply, g.sanMoves())
+}
+
+// Bookmarks returns the positions bookmarked in the game, in the order they were reached
+func (g *Game) Bookmarks() []gamedb.Bookmark {
+	return append([]gamedb.Bookmark(nil), g.bookmarks...)
+}
+
+// renderBookmarkPanel lists the bookmarks, if there are any
+func (g *Game) renderBookmarkPanel() string {
+	if len(g.bookmarks) == 0 {
+		return ""
+	}
+	sans := g.sanMoves()
+	var sb strings.Builder
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Render("Bookmarks"))
+	for _, bookmark := range g.bookmarks {
+		sb.WriteString("\n" + bookmarkLine(bookmark, sans))
+	}
+	return lipgloss.NewStyle().PaddingLeft(3).Render(sb.String())
+}
+
+// bookmarkLine describes a bookmark, e.g. "12. Nf3 — sharp line"
+func bookmarkLine(bookmark gamedb.Bookmark, sans []string) string {
+	line := plyLabel(bookmark.Ply, sans)
+	if bookmark.Note != "" {
+		line += " — " + bookmark.Note
+	}
+	return line
+}
+
+// plyLabel names the position after ply moves by the move that reached it,
+// e.g. "3... Nc6"
+func plyLabel(ply int, sans []string) string {
+	if ply == 0 || ply > len(sans) {
+		return "start"
+	}
+	if ply%2 == 1 {
+		return fmt.Sprintf("%s. %s", moveNumber(ply-1), sans[ply-1])
+	}
+	return fmt.Sprintf("%s... %s", moveNumber(ply-1), sans[ply-1])
+}
+
+// bookmarkComment writes a bookmark as a PGN comment
+func bookmarkComment(bookmark gamedb.Bookmark) string {
+	if bookmark.Note == "" {
+		return "{" + bookmarkPrefix + "}"
+	}
+	// Braces would end the comment early
+	note := strings.NewReplacer("{", "(", "}", ")").Replace(bookmark.Note)
+	return "{" + bookmarkPrefix + ": " + note + "}"
+}
+
+// bookmarksFromComments reads the bookmarks back from a game's comments,
+// indexed by move as chess.Game.Comments returns them
+func bookmarksFromComments(comments [][]string) []gamedb.Bookmark {
+	var bookmarks []gamedb.Bookmark
+	for i, moveComments := range comments {
+		for _, comment := range moveComments {
+			rest, ok := strings.CutPrefix(strings.TrimSpace(comment), bookmarkPrefix)
+			if !ok || (rest != "" && !strings.HasPrefix(rest, ":")) {
+				continue
+			}
+			note := strings.TrimSpace(strings.TrimPrefix(rest, ":"))
+			bookmarks = append(bookmarks, gamedb.Bookmark{Ply: i + 1, Note: note})
+		}
+	}
+	return bookmarks
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"chess-tui/gamedb"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBookmarkWithNote(t *testing.T) {
+	g := NewGame()
+	g.Update(tea.KeyMsg{Type: tea.KeyCtrlB})
+	if g.bookmarkInput != nil {
+		t.Fatal("Expected no bookmark prompt before the first move")
+	}
+
+	g.makeMove("e4")
+	g.makeMove("e5")
+	g.Update(tea.KeyMsg{Type: tea.KeyCtrlB})
+	if g.bookmarkInput == nil {
+		t.Fatal("Expected ctrl+b to open the bookmark prompt")
+	}
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("open game")})
+	g.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if len(g.bookmarks) != 1 || g.bookmarks[0] != (gamedb.Bookmark{Ply: 2, Note: "open game"}) {
+		t.Fatalf("Expected a bookmark after 1... e5, got %v", g.bookmarks)
+	}
+	if !strings.Contains(g.View(), "1... e5 — open game") {
+		t.Error("Expected the bookmark panel to list the bookmark")
+	}
+
+}
+
+func TestBFileMovesCanBeTyped(t *testing.T) {
+	g := NewGame()
+	typeText(g, "b4")
+	if g.bookmarkInput != nil {
+		t.Fatal("Expected b to start a move, not a bookmark")
+	}
+	g.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if moves := g.sanMoves(); len(moves) != 1 || moves[0] != "b4" {
+		t.Errorf("Expected b4 to be played, got %v (err %q)", moves, g.err)
+	}
+}
+
+func TestBookmarksRoundTripThroughPGN(t *testing.T) {
+	g := NewGame()
+	for _, move := range []string{"e4", "e5", "Nf3"} {
+		g.makeMove(move)
+		if move != "e5" {
+			g.addBookmark("")
+		}
+	}
+	g.bookmarks[1].Note = "develops {the} knight"
+
+	pgn := g.PGN()
+	if !strings.Contains(pgn, "1. e4 {Bookmark} 1... e5 2. Nf3 {Bookmark: develops (the) knight}") {
+		t.Errorf("Expected the bookmarks as comments, got %s", pgn)
+	}
+
+	r, err := NewReplayFromPGN(strings.NewReader(pgn), DefaultSettings())
+	if err != nil {
+		t.Fatalf("Expected the PGN to load, got %v", err)
+	}
+	want := []gamedb.Bookmark{{Ply: 1}, {Ply: 3, Note: "develops (the) knight"}}
+	if len(r.bookmarks) != len(want) || r.bookmarks[0] != want[0] || r.bookmarks[1] != want[1] {
+		t.Fatalf("Expected bookmarks %v, got %v", want, r.bookmarks)
+	}
+
+	replayKey(r, "b")
+	if r.ply != 1 {
+		t.Errorf("Expected b to jump to the first bookmark, got ply %d", r.ply)
+	}
+	replayKey(r, "b")
+	if r.ply != 3 || !strings.Contains(r.View(), "develops (the) knight") {
+		t.Errorf("Expected b to jump to the second bookmark and show its note, got ply %d", r.ply)
+	}
+	replayKey(r, "B")
+	if r.ply != 1 {
+		t.Errorf("Expected B to jump back, got ply %d", r.ply)
+	}
+}
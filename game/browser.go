@@ -0,0 +1,174 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"chess-tui/gamedb"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// browserRows is how many games the browser lists at once
+const browserRows = 15
+
+// GameBrowser lists the games in the game log, newest first, and opens the
+// one picked in a replay. / searches the games by player or opponent.
+type GameBrowser struct {
+	records  []gamedb.Record
+	cursor   int
+	search   search
+	settings *Settings
+	analyst  Analyst // analyzes the positions of the games opened
+	err      string
+}
+
+// NewGameBrowser creates the browser for the games recorded in db
+func NewGameBrowser(db *gamedb.DB, settings *Settings) *GameBrowser {
+	b := &GameBrowser{settings: settings, analyst: EngineAnalyst(), search: newSearch("player or opponent")}
+	records, err := db.Games()
+	if err != nil {
+		b.err = err.Error()
+		return b
+	}
+	slices.Reverse(records)
+	b.records = records
+	return b
+}
+
+// SetAnalyst picks who analyzes the positions of the games opened, or turns
+// the analysis off if analyst is nil
+func (b *GameBrowser) SetAnalyst(analyst Analyst) {
+	b.analyst = analyst
+}
+
+// Init does nothing; the games are loaded when the browser is created
+func (b *GameBrowser) Init() tea.Cmd {
+	return nil
+}
+
+// Update moves through the list, searches it and opens games
+func (b *GameBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return b, nil
+	}
+	if b.search.active {
+		entered, cmd := b.search.update(key)
+		if entered {
+			b.findGames()
+		}
+		return b, cmd
+	}
+
+	switch key.String() {
+	case "up", "k":
+		b.cursor = max(0, b.cursor-1)
+	case "down", "j":
+		b.cursor = min(len(b.records)-1, b.cursor+1)
+	case "/":
+		return b, b.search.open()
+	case "n", "N":
+		dir := 1
+		if key.String() == "N" {
+			dir = -1
+		}
+		if i, ok := b.search.step(dir); ok {
+			b.cursor = i
+		}
+	case "enter":
+		if b.cursor >= len(b.records) {
+			return b, nil
+		}
+		replay, err := NewReplayFromRecord(b.records[b.cursor], b.settings)
+		if err != nil {
+			b.err = err.Error()
+			return b, nil
+		}
+		b.err = ""
+		replay.SetAnalyst(b.analyst)
+		replay.parent = b
+		return replay, replay.Init()
+	case "q", "esc", "ctrl+c":
+		return b, tea.Quit
+	}
+	return b, nil
+}
+
+// findGames runs the search over the games, moving to the first match from
+// the cursor on. A game matches if either player or the AI opponent contains
+// the query, ignoring case.
+func (b *GameBrowser) findGames() {
+	query := strings.ToLower(b.search.query)
+	i, ok := b.search.find(len(b.records), b.cursor, func(i int) bool {
+		record := b.records[i]
+		for _, name := range []string{record.White, record.Black, record.Opponent} {
+			if strings.Contains(strings.ToLower(name), query) {
+				return true
+			}
+		}
+		return false
+	})
+	if ok {
+		b.cursor = i
+	}
+}
+
+// View lists the games around the cursor, highlighting the search matches
+func (b *GameBrowser) View() string {
+	var sb strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700"))
+	infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	current := lipgloss.NewStyle().Reverse(true)
+	match := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700"))
+
+	sb.WriteString(titleStyle.Render("♔ Game Log ♛") + "\n\n")
+
+	if len(b.records) == 0 && b.err == "" {
+		sb.WriteString(helpStyle.Render("No games recorded yet.") + "\n")
+	}
+	first := 0
+	if len(b.records) > browserRows {
+		// Keep the cursor in view
+		first = min(max(0, b.cursor-browserRows/2), len(b.records)-browserRows)
+	}
+	for i := first; i < min(len(b.records), first+browserRows); i++ {
+		record := b.records[i]
+		line := fmt.Sprintf("%-12s %-20s %-20s %-7s %3d moves",
+			record.Played.Format("Jan 2, 2006"), truncate(record.White, 20), truncate(record.Black, 20), record.Result, (len(record.Moves)+1)/2)
+		switch {
+		case i == b.cursor:
+			line = current.Render(line)
+		case b.search.isMatch(i):
+			line = match.Render(line)
+		}
+		sb.WriteString("  " + line + "\n")
+	}
+
+	if status := b.search.status(); status != "" {
+		sb.WriteString("\n" + infoStyle.Render(status) + "\n")
+	}
+	if b.err != "" {
+		sb.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+b.err) + "\n")
+	}
+
+	if b.search.active {
+		sb.WriteString("\n" + b.search.input.View() + "\n\n")
+		sb.WriteString(helpStyle.Render("Enter to search, esc to cancel"))
+		return sb.String()
+	}
+	sb.WriteString("\n" + helpStyle.Render("↑/↓ move, enter replay, / search, n/N next/previous match, q quit"))
+	return sb.String()
+}
+
+// truncate shortens s to width runes, ending it with … when cut
+func truncate(s string, width int) string {
+	if len([]rune(s)) <= width {
+		return s
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
@@ -0,0 +1,152 @@
+package game
+
+import (
+	"context"
+	"strings"
+
+	"chess-tui/events"
+	"chess-tui/notation"
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// bulletReceiver is a MoveGenerator that can be asked to play for speed
+type bulletReceiver interface {
+	SetBullet(on bool)
+}
+
+// bindBullet passes the bullet preset on to the AI
+func (g *Game) bindBullet() {
+	if receiver, ok := g.ai.(bulletReceiver); ok {
+		receiver.SetBullet(g.settings.Bullet)
+	}
+}
+
+// uniqueMove returns the one legal move that text starts, in SAN or UCI,
+// for bullet mode to play without waiting for enter
+func (g *Game) uniqueMove(text string) (string, bool) {
+	if text == "" || g.pendingPromotion != "" || g.chessGame.Outcome() != chess.NoOutcome || g.awaitingPeer() {
+		return "", false
+	}
+	position := g.chessGame.Position()
+	match := ""
+	for _, move := range position.ValidMoves() {
+		san := notation.Encode(position, move)
+		if !strings.HasPrefix(san, text) && !strings.HasPrefix(notation.EncodeUCI(move), text) {
+			continue
+		}
+		if match != "" {
+			return "", false
+		}
+		match = san
+	}
+	return match, match != ""
+}
+
+// autoSubmit plays the typed move in bullet mode as soon as only one legal
+// move matches it
+func (g *Game) autoSubmit() (tea.Cmd, bool) {
+	if !g.settings.Bullet || g.isAITurn {
+		return nil, false
+	}
+	move, ok := g.uniqueMove(strings.TrimSpace(g.input.Value()))
+	if !ok {
+		return nil, false
+	}
+	g.log.Debug("Auto-submitting unique move", "typed", g.input.Value(), "move", move)
+	return g.submitMove(move, false), true
+}
+
+// pondering is the AI's answer, worked out in bullet mode while the player
+// thinks, to the reply the built-in engine expects from the player
+type pondering struct {
+	fen     string // the position after the expected reply
+	result  *AIMoveResult
+	err     error
+	done    bool
+	waiting bool // the player made the expected reply; the AI's turn waits for the answer
+}
+
+// ponderMsg carries the AI's pondered answer
+type ponderMsg struct {
+	ctx    context.Context
+	fen    string
+	result *AIMoveResult
+	err    error
+}
+
+// startPonder asks the AI, in bullet mode, for its answer to the player's
+// expected reply, so it can be played at once if the player makes it
+func (g *Game) startPonder() tea.Cmd {
+	g.ponder = nil
+	if !g.settings.Bullet || g.gameMode != ModeHumanVsAI || g.ai == nil || g.peer != nil {
+		return nil
+	}
+	position := g.chessGame.Position()
+	if g.chessGame.Outcome() != chess.NoOutcome || position.Turn() != g.humanColor {
+		return nil
+	}
+	expected, err := tournament.FallbackMove(position)
+	if err != nil {
+		return nil
+	}
+	after := position.Update(expected)
+	if after.Status() != chess.NoMethod {
+		return nil
+	}
+
+	fen := after.String()
+	history := append(append([]string(nil), g.gameHistory...), notation.Encode(position, expected))
+	g.ponder = &pondering{fen: fen}
+	g.log.Debug("Pondering", "expected", history[len(history)-1], "fen", fen)
+
+	ai := g.ai
+	ctx := g.ctx
+	color := colorName(g.humanColor.Other())
+	return func() tea.Msg {
+		result, err := ai.GetAIMoveResult(fen, history, "", color)
+		return ponderMsg{ctx: ctx, fen: fen, result: result, err: err}
+	}
+}
+
+// applyPonder keeps the pondered answer, playing it if the AI's turn has
+// been waiting for it
+func (g *Game) applyPonder(msg ponderMsg) tea.Cmd {
+	if msg.ctx != g.ctx || g.ponder == nil || g.ponder.fen != msg.fen {
+		return nil
+	}
+	g.ponder.result, g.ponder.err, g.ponder.done = msg.result, msg.err, true
+	if !g.ponder.waiting {
+		return nil
+	}
+	return g.playPonder()
+}
+
+// usePonder starts the AI's turn from the pondered answer when the player
+// made the expected reply. ok is false when the AI must be asked as usual.
+func (g *Game) usePonder() (cmd tea.Cmd, ok bool) {
+	if g.ponder == nil || g.ponder.fen != g.getBoardState() {
+		g.ponder = nil
+		return nil, false
+	}
+	g.log.Debug("Expected reply played, using the pondered answer", "done", g.ponder.done)
+	g.bus.Publish(events.Event{Kind: events.AIThinkingStarted, Color: colorName(g.chessGame.Position().Turn()), FEN: g.getBoardState()})
+	if !g.ponder.done {
+		g.ponder.waiting = true
+		return nil, true
+	}
+	return g.playPonder(), true
+}
+
+// playPonder plays the pondered answer, asking the AI again if pondering failed
+func (g *Game) playPonder() tea.Cmd {
+	ponder := g.ponder
+	g.ponder = nil
+	if ponder.err != nil {
+		g.log.Debug("Pondering failed, asking again", "error", ponder.err)
+		return g.requestAIMove(aiMoveRequest{})
+	}
+	return g.applyAIMove(aiMoveMsg{ctx: g.ctx, result: ponder.result})
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"testing"
+
+	"chess-tui/notation"
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBulletUniqueMove(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsHuman)
+	for text, want := range map[string]string{"N": "", "e": "", "Nf": "Nf3", "g1f": "Nf3", "e2e4": "e4", "Qh5": ""} {
+		got, ok := g.uniqueMove(text)
+		if got != want || ok != (want != "") {
+			t.Errorf("Expected %q to match %q, got %q", text, want, got)
+		}
+	}
+}
+
+func TestBulletAutoSubmit(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Bullet = true
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	if len(g.gameHistory) != 0 {
+		t.Fatalf("Expected N to wait for more, got %v", g.gameHistory)
+	}
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if len(g.gameHistory) != 1 || g.gameHistory[0] != "Nf3" {
+		t.Fatalf("Expected Nf3 to be played at once, got %v", g.gameHistory)
+	}
+	if g.input.Value() != "" {
+		t.Errorf("Expected the input cleared, got %q", g.input.Value())
+	}
+
+	// Without the preset the move waits for enter
+	g = NewGameWithMode(ModeHumanVsHuman)
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Nf")})
+	if len(g.gameHistory) != 0 {
+		t.Errorf("Expected no move before enter, got %v", g.gameHistory)
+	}
+}
+
+func TestBulletPonder(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Bullet = true
+	g := NewGameWithSettings(ModeHumanVsAI, settings)
+	generator := &replyGenerator{moves: []string{"e5", "h6", "a6"}}
+	g.SetMoveGenerator(generator)
+
+	g.makeMove("e4")
+	ponder := g.applyAIMove(g.takeAITurn()().(aiMoveMsg))
+	if ponder == nil || g.ponder == nil {
+		t.Fatal("Expected the AI to ponder on the player's time")
+	}
+	g.Update(ponder())
+	if !g.ponder.done {
+		t.Fatal("Expected the pondered answer to be kept")
+	}
+
+	// The expected reply is answered without asking the AI again
+	expected, err := tournament.FallbackMove(g.chessGame.Position())
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.makeMove(notation.Encode(g.chessGame.Position(), expected))
+	g.takeAITurn()
+	if len(generator.histories) != 2 {
+		t.Errorf("Expected two requests, got %d", len(generator.histories))
+	}
+	if last := g.gameHistory[len(g.gameHistory)-1]; last != "h6" || g.isAITurn {
+		t.Errorf("Expected the pondered h6 to be played, got %s", last)
+	}
+	if g.ponder == nil {
+		t.Error("Expected the AI to ponder again")
+	}
+
+	// Any other reply is asked about as usual
+	g.ponder.done = true
+	g.makeMove("a3")
+	if cmd := g.takeAITurn(); cmd == nil || g.ponder != nil {
+		t.Error("Expected a fresh request after an unexpected reply")
+	}
+}
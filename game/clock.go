@@ -0,0 +1,216 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"chess-tui/events"
+	"chess-tui/netplay"
+	"chess-tui/notation"
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// clockTick is how often a game on the clock checks for a flag fall
+const clockTick = time.Second
+
+// clockTickMsg asks a game on the clock to check the time of the side to move
+type clockTickMsg struct{}
+
+// peerFlagGrace is how long past their limit a networked opponent's clock
+// runs before their flag falls here, so that a move made just in time can
+// still arrive, even if it has to be resent
+const peerFlagGrace = netplay.AckTimeout
+
+// clockControl returns the time control the game is played under, if any.
+// Networked games are played under the host's.
+func (g *Game) clockControl() (tournament.TimeControl, bool) {
+	clock := g.settings.Clock
+	if g.peer != nil {
+		clock = g.peer.Clock()
+	}
+	if clock == nil || !clock.Limited() {
+		return tournament.TimeControl{}, false
+	}
+	return *clock, true
+}
+
+// clockCmd starts the clock ticking, unless it already is or the game has
+// no clock
+func (g *Game) clockCmd() tea.Cmd {
+	if _, ok := g.clockControl(); !ok || g.ticking {
+		return nil
+	}
+	g.ticking = true
+	return tickClock()
+}
+
+// tickClock waits for the clock's next tick
+func tickClock() tea.Cmd {
+	return tea.Tick(clockTick, func(time.Time) tea.Msg { return clockTickMsg{} })
+}
+
+// thinking returns how long the side to move has been thinking about its move
+func (g *Game) thinking() time.Duration {
+	switch {
+	case g.paused:
+		return g.pausedAt.Sub(g.lastMoveAt)
+	case g.focus.blurred && g.casual():
+		return g.focus.blurredAt.Sub(g.lastMoveAt)
+	case g.awaitingPeer():
+		// The opponent's clock started when our move reached them
+		return max(time.Since(g.lastMoveAt)-g.peer.Latency(), 0)
+	}
+	return time.Since(g.lastMoveAt)
+}
+
+// sideLimit returns how long color may think about its move, counted from
+// the start of the move, and which budget applies
+func (g *Game) sideLimit(control tournament.TimeControl, color chess.Color) (time.Duration, string, bool) {
+	return control.Limit(color, g.moveTimes.Total(color))
+}
+
+// checkClock ends the move of a side that has run out of time. A player
+// loses on time; the AI, depending on the time control, has the built-in
+// engine move for it or loses too.
+func (g *Game) checkClock() tea.Cmd {
+	control, ok := g.clockControl()
+	if !ok {
+		g.ticking = false
+		return nil
+	}
+	if g.over() || g.adjourned != nil {
+		return tickClock()
+	}
+
+	mover := g.chessGame.Position().Turn()
+	limit, budget, limited := g.sideLimit(control, mover)
+	if g.awaitingPeer() {
+		limit += peerFlagGrace
+	}
+	if g.spectating() {
+		// The players' flags fall on their own boards
+		return tickClock()
+	}
+	if !limited || g.thinking() < limit {
+		return tickClock()
+	}
+
+	violation := tournament.Violation{Player: mover.Name(), Color: mover, Budget: budget, Action: tournament.TimeoutForfeit}
+	violation.Limit = control.For(mover).PerMove
+	if budget == "per-game" {
+		violation.Limit = control.For(mover).PerGame
+	}
+	if g.isAITurn && control.OnTimeout != tournament.TimeoutForfeit {
+		violation.Action = tournament.TimeoutFallback
+		g.log.Info("AI out of time", "violation", violation.String())
+		g.playFallback(violation)
+		return tickClock()
+	}
+	g.flag(violation)
+	return tickClock()
+}
+
+// flag ends the game with the side to move losing on time, or aborts a
+// networked game that hadn't got going
+func (g *Game) flag(violation tournament.Violation) {
+	g.log.Info("Flag fell", "violation", violation.String())
+	if g.isAITurn {
+		// Abandon the AI's request, which can no longer be played
+		g.restartContext()
+		g.isAITurn = false
+		g.aiMovePending = false
+	}
+	if g.peer != nil && len(g.chessGame.Moves()) < netplay.AbortPlies {
+		g.bus.Publish(events.Event{Kind: events.ClockExpired, Color: colorName(violation.Color), FEN: g.getBoardState()})
+		g.abort(violation.Color.Name() + " didn't move in time")
+		return
+	}
+	g.flagged = violation.Color
+	g.chessGame.Resign(violation.Color)
+	g.bus.Publish(events.Event{Kind: events.ClockExpired, Color: colorName(violation.Color), FEN: g.getBoardState()})
+	g.updateStatus()
+}
+
+// playFallback abandons the AI's request and plays the built-in engine's
+// move in its place
+func (g *Game) playFallback(violation tournament.Violation) {
+	g.restartContext()
+	move, err := tournament.FallbackMove(g.chessGame.Position())
+	if err == nil {
+		err = g.applyMove(notation.Encode(g.chessGame.Position(), move))
+	}
+	if err != nil {
+		g.log.Warn("Failed to play a fallback move", "error", err)
+		g.flag(violation)
+		return
+	}
+
+	g.declineDrawOffer(violation.Color)
+	g.gameHistory = append(g.gameHistory, g.lastMoveSAN())
+	g.aiReasoning = ""
+	g.aiVotes = nil
+	g.publishMove(true, "")
+	g.updateStatus()
+	g.status = violation.String() + " — " + g.status
+	g.isAITurn = false
+	g.aiMovePending = false
+}
+
+// clockText shows each side's time, e.g. "⏱ White 9:42 — Black 0:37 per move"
+func (g *Game) clockText() string {
+	control, ok := g.clockControl()
+	if !ok {
+		return ""
+	}
+	var parts []string
+	for _, color := range []chess.Color{chess.White, chess.Black} {
+		limit, budget, limited := g.sideLimit(control, color)
+		if !limited {
+			parts = append(parts, color.Name()+" untimed")
+			continue
+		}
+		if color == g.chessGame.Position().Turn() && g.chessGame.Outcome() == chess.NoOutcome {
+			limit -= g.thinking()
+		}
+		text := color.Name() + " " + formatClock(limit)
+		if budget == "per-move" {
+			text += " per move"
+		}
+		parts = append(parts, text)
+	}
+	return "⏱ " + strings.Join(parts, " — ")
+}
+
+// endMethod names how the game ended, counting a flag fall as time and a
+// networked opponent leaving as abandonment
+func (g *Game) endMethod() string {
+	switch {
+	case g.aborted != "":
+		return "abort"
+	case g.flagged != chess.NoColor:
+		return "time"
+	case g.abandoned != chess.NoColor:
+		return "abandonment"
+	case g.houseRule != "":
+		return g.houseRule
+	}
+	return methodName(g.chessGame.Method())
+}
+
+// endPhrase says how the game was won, e.g. "by checkmate" or "on time"
+func (g *Game) endPhrase() string {
+	if g.flagged != chess.NoColor {
+		return "on time"
+	}
+	return "by " + g.endMethod()
+}
+
+// formatClock shows time left as minutes and seconds, e.g. "9:42"
+func formatClock(left time.Duration) string {
+	left = max(left, 0).Truncate(time.Second)
+	return fmt.Sprintf("%d:%02d", int(left.Minutes()), int(left.Seconds())%60)
+}
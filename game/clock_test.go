@@ -0,0 +1,118 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"chess-tui/events"
+	"chess-tui/tournament"
+
+	"github.com/notnil/chess"
+)
+
+func TestClockFlagsPlayerOutOfTime(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Clock = &tournament.TimeControl{
+		PerMove: time.Minute,
+		White:   &tournament.Limits{PerGame: 10 * time.Minute},
+	}
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+
+	var expired events.Event
+	g.Events().Subscribe(func(e events.Event) { expired = e }, events.ClockExpired)
+
+	g.lastMoveAt = time.Now().Add(-2 * time.Minute)
+	g.checkClock()
+	if g.chessGame.Outcome() != chess.NoOutcome {
+		t.Fatalf("Expected white's 10 minutes to outlast black's 1 minute per move, got %s", g.chessGame.Outcome())
+	}
+
+	g.makeMove("e4")
+	g.lastMoveAt = time.Now().Add(-2 * time.Minute)
+	g.checkClock()
+	if g.chessGame.Outcome() != chess.WhiteWon {
+		t.Fatalf("Expected black to lose on time, got %s", g.chessGame.Outcome())
+	}
+	if g.flagged != chess.Black || expired.Color != "black" {
+		t.Errorf("Expected black's flag to fall, got %v and event color %q", g.flagged, expired.Color)
+	}
+	if !strings.Contains(g.status, "on time") {
+		t.Errorf("Expected the status to say on time, got %q", g.status)
+	}
+}
+
+func TestClockPlaysFallbackForAI(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Clock = &tournament.TimeControl{Black: &tournament.Limits{PerMove: time.Second}}
+	g := NewGameWithSettings(ModeHumanVsAI, settings)
+	g.makeMove("e4")
+	g.isAITurn = true
+	g.aiMovePending = true
+
+	g.lastMoveAt = time.Now().Add(-2 * time.Second)
+	g.checkClock()
+	if len(g.gameHistory) != 2 || g.chessGame.Position().Turn() != chess.White {
+		t.Fatalf("Expected the fallback to play black's move, got %v", g.gameHistory)
+	}
+	if g.isAITurn || g.aiMovePending {
+		t.Error("Expected the AI's turn to be over")
+	}
+	if g.chessGame.Outcome() != chess.NoOutcome {
+		t.Errorf("Expected the game to go on, got %s", g.chessGame.Outcome())
+	}
+}
+
+func TestClockText(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Clock = &tournament.TimeControl{
+		White: &tournament.Limits{PerGame: 10 * time.Minute},
+		Black: &tournament.Limits{PerMove: time.Minute},
+	}
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+	g.paused = true
+	g.pausedAt = g.lastMoveAt.Add(18 * time.Second)
+
+	if want := "⏱ White 9:42 — Black 1:00 per move"; g.clockText() != want {
+		t.Errorf("Expected %q, got %q", want, g.clockText())
+	}
+
+	g.settings.Clock = nil
+	if g.clockText() != "" {
+		t.Errorf("Expected no clock, got %q", g.clockText())
+	}
+}
+
+func TestClockCommentsRoundTripThroughPGN(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Clock = &tournament.TimeControl{PerGame: 10 * time.Minute}
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+	g.makeMove("e4")
+	g.makeMove("e5")
+	g.makeMove("Nf3")
+	g.moveTimes = tournament.TimeUsage{18 * time.Second, 5 * time.Second, 42 * time.Second}
+
+	pgn := g.PGN()
+	if !strings.Contains(pgn, "1. e4 {[%clk 0:09:42]} 1... e5 {[%clk 0:09:55]} 2. Nf3 {[%clk 0:09:00]}") {
+		t.Errorf("Expected a %%clk comment after every move, got %s", pgn)
+	}
+
+	r, err := NewReplayFromPGN(strings.NewReader(pgn), DefaultSettings())
+	if err != nil {
+		t.Fatalf("Expected the PGN to load, got %v", err)
+	}
+	r.seek(2)
+	if got := r.clockText(); got != "⏱ White 9:42 — Black 9:55" {
+		t.Errorf("Expected both clocks after 1... e5, got %q", got)
+	}
+	r.seek(3)
+	if got := r.clockText(); got != "⏱ White 9:00 — Black 9:55" {
+		t.Errorf("Expected White's clock to move on after 2. Nf3, got %q", got)
+	}
+
+	// Games without a clock keep their PGN as it was
+	g.settings.Clock = nil
+	if strings.Contains(g.PGN(), "%clk") {
+		t.Error("Expected no clock comments in a game without a clock")
+	}
+}
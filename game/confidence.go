@@ -0,0 +1,70 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// uncertainMoves is how many of the AI's least confident moves the
+// post-game report lists
+const uncertainMoves = 3
+
+// recordConfidence keeps the AI's confidence in the move just played, if
+// it gave one
+func (g *Game) recordConfidence(confidence *float64) {
+	if confidence == nil {
+		return
+	}
+	if g.confidence == nil {
+		g.confidence = make(map[int]float64)
+	}
+	g.confidence[len(g.chessGame.Moves())-1] = *confidence
+}
+
+// confidenceMark returns the AI's confidence in the move at ply as a
+// percentage to show beside it, or "" if it gave none
+func (g *Game) confidenceMark(ply int) string {
+	confidence, ok := g.confidence[ply]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" %d%%", int(100*confidence+0.5))
+}
+
+// renderUncertaintyPanel lists the AI's least confident moves, once the
+// game is over
+func (g *Game) renderUncertaintyPanel() string {
+	if g.chessGame.Outcome() == chess.NoOutcome || len(g.confidence) == 0 {
+		return ""
+	}
+
+	plies := make([]int, 0, len(g.confidence))
+	for ply := range g.confidence {
+		plies = append(plies, ply)
+	}
+	sort.Slice(plies, func(i, j int) bool {
+		if g.confidence[plies[i]] != g.confidence[plies[j]] {
+			return g.confidence[plies[i]] < g.confidence[plies[j]]
+		}
+		return plies[i] < plies[j]
+	})
+	if len(plies) > uncertainMoves {
+		plies = plies[:uncertainMoves]
+	}
+
+	sans := g.sanMoves()
+	var lines []string
+	for _, ply := range plies {
+		number := fmt.Sprintf("%d.", ply/2+1)
+		if ply%2 == 1 {
+			number += ".."
+		}
+		lines = append(lines, fmt.Sprintf("%s %s —%s sure", number, sans[ply], g.confidenceMark(ply)))
+	}
+	title := lipgloss.NewStyle().Bold(true).Render("Most uncertain moves")
+	return title + "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF")).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfidenceInMoveListAndReport(t *testing.T) {
+	g := NewGame()
+	for i, move := range []string{"f3", "e5", "g4", "Qh4#"} {
+		g.makeMove(move)
+		if i%2 == 1 {
+			confidence := 0.9 - 0.5*float64(i/2)
+			g.recordConfidence(&confidence)
+		}
+	}
+	g.recordConfidence(nil)
+
+	list := g.renderMoveList()
+	if !strings.Contains(list, "e5 90%") || !strings.Contains(list, "Qh4# 40%") {
+		t.Errorf("Expected the AI's confidence beside its moves, got %q", list)
+	}
+
+	report := g.renderUncertaintyPanel()
+	if !strings.Contains(report, "Most uncertain moves") || strings.Index(report, "2...Qh4#") > strings.Index(report, "1...e5") {
+		t.Errorf("Expected the least confident move first, got %q", report)
+	}
+	if !strings.Contains(report, "40% sure") {
+		t.Errorf("Expected the confidence in the report, got %q", report)
+	}
+}
+
+func TestUncertaintyPanelWaitsForGameEnd(t *testing.T) {
+	g := NewGame()
+	g.makeMove("e4")
+	confidence := 0.5
+	g.recordConfidence(&confidence)
+	if g.renderUncertaintyPanel() != "" {
+		t.Error("Expected no report while the game is going")
+	}
+}
+
+func TestSpareMovesMarkedInMoveList(t *testing.T) {
+	g := NewGame()
+	g.makeMove("e4")
+	g.makeMove("e5")
+	g.recordSpare(&AIMoveResult{Move: "e5", Provider: "ollama llama3.2:1b", Spare: true})
+	g.makeMove("Nf3")
+	g.makeMove("Nc6")
+	g.recordSpare(&AIMoveResult{Move: "Nc6", Provider: "ollama"})
+
+	list := g.renderMoveList()
+	if !strings.Contains(list, "e5"+spareMark) || strings.Contains(list, "Nc6"+spareMark) {
+		t.Errorf("Expected only the spare's move marked, got %q", list)
+	}
+	if !strings.Contains(list, "answered by the spare ollama llama3.2:1b") {
+		t.Errorf("Expected the spare named under the moves, got %q", list)
+	}
+}
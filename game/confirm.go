@@ -0,0 +1,108 @@
+package game
+
+import (
+	"strings"
+
+	"chess-tui/notation"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// Settings.Confirm values
+const (
+	ConfirmCaptures = "captures" // confirm captures
+	ConfirmAll      = "all"      // confirm every move
+)
+
+// serious reports whether the game is one confirmation prompts guard: a
+// networked game or one played on the clock
+func (g *Game) serious() bool {
+	_, clocked := g.clockControl()
+	return !g.casual() || clocked
+}
+
+// submitMove plays the move typed by the player, promoting to a queen when
+// auto-queen is on and no piece was given, unless choosePiece asks for the
+// piece as the alt+enter modifier does. In serious games the move may wait
+// for a confirmation first.
+func (g *Game) submitMove(text string, choosePiece bool) tea.Cmd {
+	if g.pendingPromotion != "" {
+		piece, ok := promotionPiece(text)
+		if !ok {
+			g.makeMove(text)
+			return nil
+		}
+		text = withPromotion(g.chessGame.Position(), g.pendingPromotion, piece)
+		g.pendingPromotion = ""
+	} else if g.settings.AutoQueen && !choosePiece && g.needsPromotionPiece(text) {
+		text = withPromotion(g.chessGame.Position(), text, "Q")
+	}
+
+	if g.needsConfirmation(text) {
+		g.confirming = text
+		g.input.SetValue("")
+		g.err = ""
+		g.status = "Play " + g.confirmingSAN() + "? Press y or enter to confirm, any other key to take it back"
+		return nil
+	}
+	g.makeMove(text)
+	return g.takeAITurn()
+}
+
+// withPromotion completes a promotion missing its piece, in the notation
+// it was typed in: SAN or UCI
+func withPromotion(position *chess.Position, text, piece string) string {
+	for _, suffix := range []string{"=" + piece, strings.ToLower(piece)} {
+		if _, err := notation.Decode(position, text+suffix); err == nil {
+			return text + suffix
+		}
+	}
+	return text
+}
+
+// needsConfirmation reports whether the settings ask to confirm the move
+func (g *Game) needsConfirmation(text string) bool {
+	if g.settings.Confirm == "" || !g.serious() {
+		return false
+	}
+	move, err := notation.Decode(g.chessGame.Position(), text)
+	if err != nil {
+		// Let makeMove explain what is wrong with it
+		return false
+	}
+	switch g.settings.Confirm {
+	case ConfirmAll:
+		return true
+	case ConfirmCaptures:
+		return move.HasTag(chess.Capture) || move.HasTag(chess.EnPassant)
+	}
+	return false
+}
+
+// confirmingSAN returns the move waiting for confirmation in SAN
+func (g *Game) confirmingSAN() string {
+	san, err := notation.Normalize(g.getBoardState(), g.confirming)
+	if err != nil {
+		return g.confirming
+	}
+	return san
+}
+
+// updateConfirm plays the move waiting for confirmation on y or enter and
+// takes it back on any other key
+func (g *Game) updateConfirm(msg tea.KeyMsg) tea.Cmd {
+	move := g.confirming
+	g.confirming = ""
+	switch strings.ToLower(msg.String()) {
+	case "ctrl+c":
+		g.shutdown()
+		return tea.Quit
+	case "y", "enter":
+		g.makeMove(move)
+		return g.takeAITurn()
+	}
+	g.updateStatus()
+	g.status = "Move taken back — " + g.status
+	return nil
+}
@@ -0,0 +1,70 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAutoQueen(t *testing.T) {
+	settings := DefaultSettings()
+	settings.AutoQueen = true
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+	if err := g.SetStartPosition("8/4P3/8/8/8/8/k7/4K3 w - - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	g.submitMove("e8", false)
+	if last := g.lastMoveSAN(); last != "e8=Q" {
+		t.Errorf("Expected e8=Q, got %q", last)
+	}
+
+	// The modifier asks for the piece
+	g.resetGame()
+	g.submitMove("e7e8", true)
+	if g.pendingPromotion == "" {
+		t.Fatal("Expected a prompt for the promotion piece")
+	}
+	g.submitMove("n", false)
+	if last := g.lastMoveSAN(); last != "e8=N" {
+		t.Errorf("Expected e8=N, got %q", last)
+	}
+}
+
+func TestConfirmCapturesInSeriousGames(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Confirm = ConfirmCaptures
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+	g.submitMove("e4", false)
+	g.submitMove("d5", false)
+	g.submitMove("exd5", false)
+	if g.confirming != "" || len(g.gameHistory) != 3 {
+		t.Fatalf("Expected no confirmation in a casual game, got %q", g.confirming)
+	}
+
+	settings.Clock = &tournament.TimeControl{PerGame: 10 * time.Minute}
+	g = NewGameWithSettings(ModeHumanVsHuman, settings)
+	g.submitMove("e4", false)
+	g.submitMove("d5", false)
+	if len(g.gameHistory) != 2 {
+		t.Fatalf("Expected quiet moves to be played at once, got %v", g.gameHistory)
+	}
+
+	g.submitMove("exd5", false)
+	if g.confirming == "" || len(g.gameHistory) != 2 {
+		t.Fatalf("Expected the capture to wait for confirmation, got %v", g.gameHistory)
+	}
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if g.confirming != "" || len(g.gameHistory) != 2 {
+		t.Fatalf("Expected the capture taken back, got %v", g.gameHistory)
+	}
+
+	g.submitMove("exd5", false)
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if last := g.lastMoveSAN(); last != "exd5" {
+		t.Errorf("Expected the confirmed exd5, got %q", last)
+	}
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"fmt"
+
+	"chess-tui/netplay"
+
+	"github.com/notnil/chess"
+)
+
+// DefaultHintBudget is the hints the players of a consultation game share
+// unless told otherwise
+const DefaultHintBudget = 5
+
+// consultation is the shared AI advisor of a game between two humans
+type consultation struct {
+	budget int                 // hints the players share
+	spent  map[chess.Color]int // hints taken by each side
+}
+
+// left returns the hints still to be had
+func (c *consultation) left() int {
+	return max(c.budget-c.spent[chess.White]-c.spent[chess.Black], 0)
+}
+
+// SetAdvisor makes a Human vs Human game a consultation: either player can
+// ask generator for hints with [t], until the budget they share runs out.
+// The advisor never moves. In a networked game the host's budget counts.
+func (g *Game) SetAdvisor(generator MoveGenerator, budget int) {
+	g.SetMoveGenerator(generator)
+	g.consult = &consultation{budget: budget, spent: make(map[chess.Color]int)}
+	if g.peer != nil {
+		g.peer.SetHintBudget(budget)
+	}
+}
+
+// refuseHint explains why the advisor can't be asked now, or returns ""
+func (g *Game) refuseHint() string {
+	switch {
+	case g.consult == nil:
+		return ""
+	case g.awaitingPeer():
+		return "The advisor only helps on your turn"
+	case g.teachPending:
+		return "The advisor is still thinking"
+	case len(g.teachCandidates) > 0:
+		return "The advisor's ideas for this position are already shown"
+	case g.consult.left() == 0:
+		return fmt.Sprintf("No hints left: all %d have been used", g.consult.budget)
+	}
+	return ""
+}
+
+// spendHint charges a hint to the side to move and, in a networked game,
+// tells the opponent
+func (g *Game) spendHint() {
+	if g.consult == nil {
+		return
+	}
+	g.consult.spent[g.chessGame.Position().Turn()]++
+	if g.peer != nil {
+		g.peer.SpendHint()
+	}
+}
+
+// applyHintEvent takes the networked opponent's tally of hints spent
+func (g *Game) applyHintEvent(event netplay.Event) {
+	if g.consult == nil {
+		return
+	}
+	if event.Budget > 0 {
+		g.consult.budget = event.Budget
+	}
+	for name, spent := range event.Hints {
+		color := chess.White
+		if name == "black" {
+			color = chess.Black
+		}
+		g.consult.spent[color] = max(g.consult.spent[color], spent)
+	}
+}
+
+// hintsText summarizes the shared hint budget
+func (g *Game) hintsText() string {
+	return fmt.Sprintf("Advisor: %d of %d hints left (White used %d, Black %d)",
+		g.consult.left(), g.consult.budget, g.consult.spent[chess.White], g.consult.spent[chess.Black])
+}
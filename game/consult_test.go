@@ -0,0 +1,100 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"chess-tui/netplay"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func TestConsultationHints(t *testing.T) {
+	g := NewGame()
+	g.SetAdvisor(&fakeGenerator{candidates: []CandidateMove{{Move: "e4", Explanation: "Claims the center."}}}, 2)
+
+	g.Update(g.requestCandidates()())
+	if !strings.Contains(g.renderTeachPanel(), "Advisor") || g.consult.spent[chess.White] != 1 {
+		t.Errorf("Expected White's hint shown and charged, got %v", g.consult.spent)
+	}
+	if g.requestCandidates() != nil {
+		t.Error("Expected no second charge for the ideas already shown")
+	}
+
+	g.makeMove("e4")
+	if g.isAITurn || g.aiMovePending {
+		t.Error("Expected the advisor never to move")
+	}
+	g.Update(g.requestCandidates()())
+	if g.consult.spent[chess.Black] != 1 || g.consult.left() != 0 {
+		t.Errorf("Expected Black's hint to use up the budget, got %v", g.consult.spent)
+	}
+
+	g.makeMove("e5")
+	if g.requestCandidates() != nil || !strings.Contains(g.status, "No hints left") {
+		t.Errorf("Expected the advisor refused once the budget is spent, got %q", g.status)
+	}
+	if view := g.render(); !strings.Contains(view, "0 of 2 hints left (White used 1, Black 1)") {
+		t.Errorf("Expected the budget in the view, got %q", view)
+	}
+}
+
+func TestConsultationFailedHintIsFree(t *testing.T) {
+	g := NewGame()
+	g.SetAdvisor(&fakeGenerator{err: errors.New("advisor offline")}, 1)
+	g.Update(g.requestCandidates()())
+	if g.consult.left() != 1 {
+		t.Errorf("Expected a failed hint not to be charged, got %d left", g.consult.left())
+	}
+}
+
+func TestConsultationMenu(t *testing.T) {
+	menu := NewMenu()
+	menu.SetMoveGenerator(&fakeGenerator{})
+	menu.SetHintBudget(3)
+	menu.cursor = 6
+	model, _ := menu.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	g, ok := model.(*Game)
+	if !ok {
+		t.Fatalf("Expected a game, got %T", model)
+	}
+	if g.gameMode != ModeHumanVsHuman || g.consult == nil || g.consult.budget != 3 {
+		t.Errorf("Expected a Human vs Human game with 3 hints, got mode %d and %+v", g.gameMode, g.consult)
+	}
+}
+
+func TestNetworkConsultationSharesBudget(t *testing.T) {
+	hostPeer, err := netplay.Host("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to host: %v", err)
+	}
+	defer hostPeer.Close()
+	joinPeer, err := netplay.Join(hostPeer.Addr())
+	if err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	defer joinPeer.Close()
+
+	advisor := &fakeGenerator{candidates: []CandidateMove{{Move: "e4"}}}
+	host := NewNetworkGame(hostPeer, DefaultSettings())
+	host.SetAdvisor(advisor, 1)
+	joiner := NewNetworkGame(joinPeer, DefaultSettings())
+	joiner.SetAdvisor(advisor, 5)
+	for nextPeerEvent(t, joiner).Kind != netplay.EventHint {
+	}
+	if joiner.consult.budget != 1 {
+		t.Errorf("Expected the joiner to take the host's budget, got %d", joiner.consult.budget)
+	}
+	if joiner.requestCandidates() != nil {
+		t.Error("Expected the advisor to wait for the joiner's turn")
+	}
+
+	host.Update(host.requestCandidates()())
+	for nextPeerEvent(t, joiner).Kind != netplay.EventHint {
+	}
+	if joiner.consult.left() != 0 {
+		t.Errorf("Expected the host's hint to use up the shared budget, got %d left", joiner.consult.left())
+	}
+}
@@ -0,0 +1,52 @@
+package game
+
+import (
+	"context"
+)
+
+// contextSetter is implemented by move generators whose requests can be
+// cancelled, such as the AIClient and LocalAI
+type contextSetter interface {
+	SetContext(ctx context.Context)
+}
+
+// SetContext ties the game to ctx, usually the program's: when it ends, the
+// AI's request in flight is abandoned and the connection to the networked
+// opponent is closed. Each game played gets a context of its own under ctx,
+// which also ends on reset and on quit. The settings' hooks run until ctx
+// ends.
+func (g *Game) SetContext(ctx context.Context) {
+	g.root = ctx
+	g.restartContext()
+	g.subscribeHooks()
+	if peer := g.peer; peer != nil {
+		context.AfterFunc(ctx, func() { peer.Close() })
+	}
+}
+
+// restartContext ends the current game's context and starts a new one, so
+// no request left over from the old game lands in the new one
+func (g *Game) restartContext() {
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.ctx, g.cancel = context.WithCancel(g.root)
+	g.bindContext()
+}
+
+// bindContext makes the AI's requests end with the game's context
+func (g *Game) bindContext() {
+	if setter, ok := g.ai.(contextSetter); ok {
+		setter.SetContext(g.ctx)
+	}
+}
+
+// shutdown ends the game's context and closes the connection to the
+// networked opponent, for when the player quits
+func (g *Game) shutdown() {
+	g.cancel()
+	g.unsubscribeHooks()
+	if g.peer != nil {
+		g.peer.Close()
+	}
+}
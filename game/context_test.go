@@ -0,0 +1,125 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chess-tui/netplay"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// blockingGenerator thinks until its context ends, then answers anyway
+type blockingGenerator struct {
+	ctx     context.Context
+	started chan struct{}
+}
+
+func (b *blockingGenerator) SetContext(ctx context.Context) {
+	b.ctx = ctx
+}
+
+func (b *blockingGenerator) GetAIMoveResult(boardState string, gameHistory []string, errorMsg string, playerColor string) (*AIMoveResult, error) {
+	close(b.started)
+	<-b.ctx.Done()
+	return &AIMoveResult{Move: "e5"}, nil
+}
+
+func (b *blockingGenerator) SetPersonality(name string) {}
+
+func (b *blockingGenerator) SuggestMoves(boardState string, gameHistory []string, playerColor string) ([]CandidateMove, error) {
+	return nil, errors.New("not used")
+}
+
+func TestAIClientCancelledWithContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := NewAIClient(server.URL)
+	client.SetContext(ctx)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.GetAIMoveResult(NewGame().GetBoardState(), nil, "", "white")
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected a cancelled request, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the request to end with its context")
+	}
+}
+
+func TestResetDropsAIMoveInFlight(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	generator := &blockingGenerator{started: make(chan struct{})}
+	g.SetMoveGenerator(generator)
+	g.makeMove("e4")
+
+	move := g.takeAITurn()
+	done := make(chan tea.Msg, 1)
+	go func() { done <- move() }()
+	<-generator.started
+
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if _, cmd := g.Update(<-done); cmd != nil {
+		t.Errorf("Expected no follow-up for the abandoned move")
+	}
+	if moves := len(g.chessGame.Moves()); moves != 0 {
+		t.Errorf("Expected the abandoned move not to be played, got %d moves", moves)
+	}
+	if generator.ctx.Err() != nil {
+		t.Errorf("Expected the new game's context to be live, got %v", generator.ctx.Err())
+	}
+}
+
+func TestCancelledContextClosesPeer(t *testing.T) {
+	peer, err := netplay.Host("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected to host, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g := NewNetworkGame(peer, DefaultSettings())
+	g.SetContext(ctx)
+	cancel()
+
+	events := peer.Events()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Expected the peer to close with the program's context")
+		}
+	}
+}
+
+func TestQuitEndsGameContext(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	generator := &blockingGenerator{started: make(chan struct{})}
+	g.SetMoveGenerator(generator)
+
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+
+	if generator.ctx.Err() == nil {
+		t.Error("Expected quitting to end the AI's context")
+	}
+}
@@ -0,0 +1,280 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"chess-tui/notation"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dailyMaxMisses is how many wrong moves fail the daily puzzle
+const dailyMaxMisses = 3
+
+// DailyStats is the user's daily puzzle history
+type DailyStats struct {
+	Streak     int               `json:"streak"`
+	Best       int               `json:"best"`
+	LastSolved string            `json:"last_solved,omitempty"` // date, YYYY-MM-DD
+	Results    map[string]string `json:"results,omitempty"`     // date → attempt grid, e.g. "🟥🟩"
+}
+
+// DefaultDailyPath returns the daily puzzle stats location in the user's config directory
+func DefaultDailyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "daily.json"
+	}
+	return filepath.Join(home, ".bubblechess", "daily.json")
+}
+
+// LoadDailyStats loads daily puzzle stats, returning empty stats if the file doesn't exist
+func LoadDailyStats(path string) (*DailyStats, error) {
+	stats := &DailyStats{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read daily stats: %w", err)
+	}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("failed to decode daily stats: %w", err)
+	}
+	return stats, nil
+}
+
+// SaveDailyStats saves daily puzzle stats
+func SaveDailyStats(stats *DailyStats, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create daily stats directory: %w", err)
+	}
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode daily stats: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write daily stats: %w", err)
+	}
+	return nil
+}
+
+// record stores the day's first result, extending the streak if the puzzle
+// was solved the day after the last solve. Replays of a day don't count.
+func (s *DailyStats) record(day time.Time, grid string, solved bool) {
+	date := day.Format(time.DateOnly)
+	if _, played := s.Results[date]; played {
+		return
+	}
+	if s.Results == nil {
+		s.Results = make(map[string]string)
+	}
+	s.Results[date] = grid
+
+	if !solved {
+		s.Streak = 0
+		return
+	}
+	if s.LastSolved == day.AddDate(0, 0, -1).Format(time.DateOnly) {
+		s.Streak++
+	} else {
+		s.Streak = 1
+	}
+	s.LastSolved = date
+	s.Best = max(s.Best, s.Streak)
+}
+
+// Daily is the screen for the puzzle of the day
+type Daily struct {
+	game   *Game // shows the puzzle's board
+	input  textinput.Model
+	puzzle Puzzle
+	number int
+	day    time.Time
+
+	stats     *DailyStats
+	statsPath string // "" keeps the stats in memory only
+
+	ply     int    // next solution move to play
+	grid    string // one square per attempt: 🟩 right, 🟥 wrong
+	misses  int
+	message string
+	done    bool
+	solved  bool
+	err     string
+}
+
+// NewDaily opens the puzzle for day. Stats are read from and saved to
+// statsPath, or kept in memory if it is empty.
+func NewDaily(day time.Time, settings *Settings, statsPath string) (*Daily, error) {
+	puzzle, number := DailyPuzzle(day)
+	position, err := puzzle.newGame()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &DailyStats{}
+	if statsPath != "" {
+		if stats, err = LoadDailyStats(statsPath); err != nil {
+			return nil, err
+		}
+	}
+
+	game := NewGameWithSettings(ModeHumanVsHuman, settings)
+	game.chessGame = position
+	game.humanColor = position.Position().Turn()
+
+	input := textinput.New()
+	input.Placeholder = "e.g. Qh5"
+	input.Focus()
+	input.CharLimit = 10
+	input.Width = 20
+
+	d := &Daily{
+		game:      game,
+		input:     input,
+		puzzle:    puzzle,
+		number:    number,
+		day:       day,
+		stats:     stats,
+		statsPath: statsPath,
+	}
+	if grid, played := stats.Results[day.Format(time.DateOnly)]; played {
+		d.message = "You've played today's puzzle (" + grid + "); replays don't change your streak."
+	}
+	return d, nil
+}
+
+// Init initializes the daily puzzle screen
+func (d *Daily) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles the solver's moves
+func (d *Daily) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "ctrl+c":
+			return d, tea.Quit
+		case "q":
+			if d.done {
+				return d, tea.Quit
+			}
+		case "enter":
+			if !d.done && d.input.Value() != "" {
+				d.tryMove(strings.TrimSpace(d.input.Value()))
+				d.input.SetValue("")
+			}
+			return d, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	if !d.done {
+		d.input, cmd = d.input.Update(msg)
+	}
+	return d, cmd
+}
+
+// tryMove checks the solver's move against the solution, playing the
+// opponent's reply when it is right
+func (d *Daily) tryMove(input string) {
+	d.err = ""
+	position := d.game.chessGame
+	move, err := notation.Decode(position.Position(), input)
+	if err != nil {
+		d.err = notation.Explain(err)
+		return
+	}
+
+	if !d.puzzle.solves(position, d.ply, move) {
+		d.grid += "🟥"
+		d.misses++
+		if d.misses >= dailyMaxMisses {
+			d.message = "Out of tries. The solution was " + strings.Join(d.puzzle.Solution, " ") + "."
+			d.finish(false)
+		} else {
+			d.message = fmt.Sprintf("%s isn't it. %d tries left.", input, dailyMaxMisses-d.misses)
+		}
+		return
+	}
+
+	d.grid += "🟩"
+	position.Move(move)
+	d.ply++
+	if d.ply < len(d.puzzle.Solution) {
+		reply, err := notation.Decode(position.Position(), d.puzzle.Solution[d.ply])
+		if err == nil {
+			position.Move(reply)
+		}
+		d.ply++
+	}
+	if d.ply >= len(d.puzzle.Solution) {
+		d.message = "Solved!"
+		d.finish(true)
+		return
+	}
+	d.message = "Correct! Keep going."
+}
+
+// finish records the result and saves the stats
+func (d *Daily) finish(solved bool) {
+	d.done = true
+	d.solved = solved
+	d.stats.record(d.day, d.grid, solved)
+	if d.statsPath != "" {
+		if err := SaveDailyStats(d.stats, d.statsPath); err != nil {
+			d.err = err.Error()
+		}
+	}
+}
+
+// ShareText is the result as emoji text for sharing, e.g.
+//
+//	bubblechess daily #42 🟥🟩
+//	Streak: 3 🔥
+func (d *Daily) ShareText() string {
+	result := d.grid
+	if !d.solved {
+		result += " ❌"
+	}
+	return fmt.Sprintf("bubblechess daily #%d %s\nStreak: %d 🔥", d.number, result, d.stats.Streak)
+}
+
+// View renders the daily puzzle
+func (d *Daily) View() string {
+	var sb strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700")).
+		Render(fmt.Sprintf("♔ Daily Puzzle #%d — %s ♛", d.number, d.day.Format("Jan 2, 2006")))
+	sb.WriteString(title + "\n\n")
+	sb.WriteString(d.game.renderBoard() + "\n\n")
+
+	infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF"))
+	moves := (len(d.puzzle.Solution) + 1) / 2
+	sb.WriteString(infoStyle.Render(fmt.Sprintf("%s — %s to play and mate in %d", d.puzzle.Title, d.game.humanColor.Name(), moves)) + "\n")
+	sb.WriteString(infoStyle.Render(fmt.Sprintf("Streak: %d (best %d)", d.stats.Streak, d.stats.Best)) + "\n")
+	if d.message != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Render(d.message) + "\n")
+	}
+	if d.err != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+d.err) + "\n")
+	}
+
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	if d.done {
+		sb.WriteString("\nShare your result:\n\n" + d.ShareText() + "\n\n")
+		sb.WriteString(helpStyle.Render("Press q to quit"))
+	} else {
+		sb.WriteString("\nYour move: " + d.input.View() + "\n\n")
+		sb.WriteString(helpStyle.Render("Enter a move in algebraic notation, ctrl+c to quit"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dayOfPuzzle returns a day whose daily puzzle has the given title
+func dayOfPuzzle(t *testing.T, title string) time.Time {
+	t.Helper()
+	for i := range puzzles {
+		day := dailyEpoch.AddDate(0, 0, i)
+		if puzzle, _ := DailyPuzzle(day); puzzle.Title == title {
+			return day
+		}
+	}
+	t.Fatalf("No puzzle titled %s", title)
+	return time.Time{}
+}
+
+// typeMove enters a move on the daily screen
+func typeMove(d *Daily, move string) {
+	d.input.SetValue(move)
+	d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestDailySolveWithReply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daily.json")
+	day := dayOfPuzzle(t, "Philidor's legacy")
+	d, err := NewDaily(day, DefaultSettings(), path)
+	if err != nil {
+		t.Fatalf("Failed to open daily puzzle: %v", err)
+	}
+
+	typeMove(d, "Nf7+") // wrong
+	typeMove(d, "Qg8+")
+	if d.done {
+		t.Fatal("Expected the puzzle to continue after the first move")
+	}
+	if got := d.game.sanMoves(); len(got) != 2 || got[1] != "Rxg8" {
+		t.Errorf("Expected the opponent's reply Rxg8 to be played, got %v", got)
+	}
+	typeMove(d, "Nf7#")
+
+	if !d.done || !d.solved {
+		t.Fatal("Expected the puzzle to be solved")
+	}
+	if d.grid != "🟥🟩🟩" {
+		t.Errorf("Expected grid 🟥🟩🟩, got %s", d.grid)
+	}
+	if !strings.Contains(d.ShareText(), "🟥🟩🟩") || !strings.Contains(d.ShareText(), "Streak: 1") {
+		t.Errorf("Expected grid and streak in share text, got %q", d.ShareText())
+	}
+
+	stats, err := LoadDailyStats(path)
+	if err != nil {
+		t.Fatalf("Failed to load stats: %v", err)
+	}
+	if stats.Streak != 1 || stats.Results[day.Format(time.DateOnly)] != "🟥🟩🟩" {
+		t.Errorf("Expected streak 1 and today's grid saved, got %+v", stats)
+	}
+}
+
+func TestDailyFailsAfterMisses(t *testing.T) {
+	d, err := NewDaily(dayOfPuzzle(t, "Back-rank mate"), DefaultSettings(), "")
+	if err != nil {
+		t.Fatalf("Failed to open daily puzzle: %v", err)
+	}
+	d.stats.Streak = 4
+	for i := 0; i < dailyMaxMisses; i++ {
+		typeMove(d, "h3")
+	}
+	if !d.done || d.solved {
+		t.Fatal("Expected the puzzle to be failed")
+	}
+	if d.stats.Streak != 0 {
+		t.Errorf("Expected a failure to reset the streak, got %d", d.stats.Streak)
+	}
+}
+
+func TestDailyStreak(t *testing.T) {
+	stats := &DailyStats{}
+	day := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	stats.record(day, "🟩", true)
+	stats.record(day.AddDate(0, 0, 1), "🟩", true)
+	stats.record(day.AddDate(0, 0, 1), "🟥🟥🟥", false) // replay, ignored
+	if stats.Streak != 2 || stats.Best != 2 {
+		t.Errorf("Expected streak 2, got %d (best %d)", stats.Streak, stats.Best)
+	}
+
+	stats.record(day.AddDate(0, 0, 3), "🟩", true) // skipped a day
+	if stats.Streak != 1 || stats.Best != 2 {
+		t.Errorf("Expected the streak to restart at 1 with best 2, got %d (best %d)", stats.Streak, stats.Best)
+	}
+}
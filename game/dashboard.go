@@ -0,0 +1,255 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// dashboardLogLines is how many of the match's latest log lines the
+// dashboard shows
+const dashboardLogLines = 8
+
+// dashboardColumns is how many mini boards fit side by side before they
+// wrap, unless the terminal's width says otherwise
+const dashboardColumns = 4
+
+// DashboardMoveMsg reports a move, or the start of a game, to the dashboard
+type DashboardMoveMsg tournament.Board
+
+// DashboardResultMsg reports a game that ended, with a result or with an
+// error when it could not be finished
+type DashboardResultMsg struct {
+	Round  int
+	Result *tournament.GameResult
+	Err    error
+}
+
+// DashboardLogMsg is a line the match printed
+type DashboardLogMsg string
+
+// DashboardDoneMsg tells the dashboard the match is over
+type DashboardDoneMsg struct {
+	Err error
+}
+
+// Dashboard is a live view of a headless match: a mini board for each game
+// in progress, the standings and how many games are still queued
+type Dashboard struct {
+	games int
+
+	boards   map[int]tournament.Board // the games in progress by round
+	names    []string                 // the players in the order they first played
+	scores   map[string]float64
+	finished int
+	failed   int
+	log      []string
+
+	done  bool
+	err   error
+	width int
+}
+
+// NewDashboard creates the dashboard for a match of games games
+func NewDashboard(games int) *Dashboard {
+	return &Dashboard{
+		games:  games,
+		boards: make(map[int]tournament.Board),
+		scores: make(map[string]float64),
+	}
+}
+
+// Init does nothing; the match sends what there is to show
+func (d *Dashboard) Init() tea.Cmd {
+	return nil
+}
+
+// Update follows the match and quits on q
+func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+	case DashboardMoveMsg:
+		board := tournament.Board(msg)
+		d.boards[board.Round] = board
+		d.addPlayer(board.White)
+		d.addPlayer(board.Black)
+	case DashboardResultMsg:
+		delete(d.boards, msg.Round)
+		if msg.Err != nil {
+			d.failed++
+			break
+		}
+		d.finished++
+		for _, name := range []string{msg.Result.White, msg.Result.Black} {
+			d.addPlayer(name)
+			d.scores[name] += msg.Result.ScoreFor(name)
+		}
+	case DashboardLogMsg:
+		d.log = append(d.log, string(msg))
+		if len(d.log) > dashboardLogLines {
+			d.log = d.log[len(d.log)-dashboardLogLines:]
+		}
+	case DashboardDoneMsg:
+		d.done, d.err = true, msg.Err
+		clear(d.boards)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return d, tea.Quit
+		}
+	}
+	return d, nil
+}
+
+// addPlayer lists a player in the standings
+func (d *Dashboard) addPlayer(name string) {
+	if _, ok := d.scores[name]; !ok {
+		d.names = append(d.names, name)
+		d.scores[name] = 0
+	}
+}
+
+// queued returns how many games haven't started yet
+func (d *Dashboard) queued() int {
+	if d.done {
+		return 0
+	}
+	return max(d.games-d.finished-d.failed-len(d.boards), 0)
+}
+
+// View renders the boards in progress, the standings, the queue and the
+// latest log lines
+func (d *Dashboard) View() string {
+	var sb strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700"))
+	headingStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00AAFF"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	sb.WriteString(titleStyle.Render("♔ Match ♛") + " " + dim.Render(strings.Join(d.names, " vs ")) + "\n\n")
+	sb.WriteString(fmt.Sprintf("%d of %d games played · %d in progress · %d queued", d.finished, d.games, len(d.boards), d.queued()))
+	if d.failed > 0 {
+		sb.WriteString(fmt.Sprintf(" · %d failed", d.failed))
+	}
+	sb.WriteString("\n\n")
+
+	if len(d.boards) > 0 {
+		sb.WriteString(d.renderBoards() + "\n\n")
+	}
+
+	sb.WriteString(headingStyle.Render("Standings") + "\n")
+	if len(d.names) == 0 {
+		sb.WriteString(dim.Render("  No games yet") + "\n")
+	}
+	for i, name := range d.standings() {
+		sb.WriteString(fmt.Sprintf("  %d. %-32s %5.1f\n", i+1, name, d.scores[name]))
+	}
+
+	if len(d.log) > 0 {
+		sb.WriteString("\n" + headingStyle.Render("Log") + "\n")
+		for _, line := range d.log {
+			sb.WriteString(dim.Render("  "+line) + "\n")
+		}
+	}
+
+	sb.WriteString("\n")
+	switch {
+	case d.err != nil:
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+d.err.Error()) + "\n")
+		sb.WriteString(dim.Render("q to quit"))
+	case d.done:
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Render("Match over") + "\n")
+		sb.WriteString(dim.Render("q to quit"))
+	default:
+		sb.WriteString(dim.Render("q to stop the match, keeping the games played"))
+	}
+	return sb.String()
+}
+
+// standings returns the players with the highest score first
+func (d *Dashboard) standings() []string {
+	names := append([]string(nil), d.names...)
+	sort.SliceStable(names, func(i, j int) bool {
+		return d.scores[names[i]] > d.scores[names[j]]
+	})
+	return names
+}
+
+// renderBoards lays the games in progress out side by side, in round order
+func (d *Dashboard) renderBoards() string {
+	rounds := make([]int, 0, len(d.boards))
+	for round := range d.boards {
+		rounds = append(rounds, round)
+	}
+	sort.Ints(rounds)
+
+	columns := dashboardColumns
+	if d.width > 0 {
+		columns = max(d.width/(miniBoardWidth+2), 1)
+	}
+	var rows []string
+	var row []string
+	for _, round := range rounds {
+		row = append(row, lipgloss.NewStyle().MarginRight(2).Render(renderMiniBoard(d.boards[round])))
+		if len(row) == columns {
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	}
+	return strings.Join(rows, "\n\n")
+}
+
+// miniBoardWidth is how many columns a mini board and its captions take
+const miniBoardWidth = 18
+
+// renderMiniBoard draws a game in progress as a small board from White's
+// side, with its round, players and last move
+func renderMiniBoard(board tournament.Board) string {
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	caption := func(s string) string {
+		if len([]rune(s)) > miniBoardWidth {
+			s = string([]rune(s)[:miniBoardWidth-1]) + "…"
+		}
+		return s
+	}
+
+	var sb strings.Builder
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Game %d", board.Round)) + "\n")
+	sb.WriteString(caption("♔ "+board.White) + "\n")
+	sb.WriteString(caption("♚ "+board.Black) + "\n")
+
+	fen, err := chess.FEN(board.FEN)
+	if err != nil {
+		sb.WriteString(dim.Render("(no position)"))
+		return sb.String()
+	}
+	position := chess.NewGame(fen).Position().Board()
+	for rank := 7; rank >= 0; rank-- {
+		for file := 0; file < 8; file++ {
+			piece := position.Piece(chess.Square(rank*8 + file))
+			if piece == chess.NoPiece {
+				sb.WriteString(dim.Render("·") + " ")
+				continue
+			}
+			sb.WriteString(piece.String() + " ")
+		}
+		sb.WriteString("\n")
+	}
+
+	last := "start"
+	if board.LastMove != "" {
+		last = numberToken(board.Plies-1, true)[0] + board.LastMove
+	}
+	sb.WriteString(dim.Render(last))
+	return sb.String()
+}
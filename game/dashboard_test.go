@@ -0,0 +1,68 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+const afterE4 = "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"
+
+func TestDashboardFollowsMatch(t *testing.T) {
+	d := NewDashboard(4)
+	d.Update(DashboardMoveMsg{Round: 1, White: "alpha", Black: "beta", FEN: afterE4, LastMove: "e4", Plies: 1})
+	d.Update(DashboardMoveMsg{Round: 2, White: "beta", Black: "alpha", FEN: afterE4, LastMove: "e4", Plies: 1})
+
+	view := d.View()
+	for _, want := range []string{"0 of 4 games played · 2 in progress · 2 queued", "Game 1", "Game 2", "1.e4", "alpha vs beta"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the dashboard, got:\n%s", want, view)
+		}
+	}
+
+	d.Update(DashboardResultMsg{Round: 1, Result: &tournament.GameResult{White: "alpha", Black: "beta", Outcome: chess.BlackWon}})
+	d.Update(DashboardResultMsg{Round: 2, Err: errors.New("host down")})
+	d.Update(DashboardLogMsg("  0-1 in 40 plies (checkmate)"))
+	view = d.View()
+	for _, want := range []string{"1 of 4 games played · 0 in progress · 2 queued · 1 failed", "0-1 in 40 plies"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the dashboard, got:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "Game 1") {
+		t.Error("Expected the finished game's board to be gone")
+	}
+	if standings := d.standings(); standings[0] != "beta" || d.scores["beta"] != 1 {
+		t.Errorf("Expected beta to lead with 1 point, got %v %v", standings, d.scores)
+	}
+
+	d.Update(DashboardDoneMsg{})
+	if view := d.View(); !strings.Contains(view, "Match over") || !strings.Contains(view, "0 queued") {
+		t.Errorf("Expected the match to be over, got:\n%s", view)
+	}
+	if _, cmd := d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil {
+		t.Error("Expected q to quit")
+	}
+}
+
+func TestRenderMiniBoard(t *testing.T) {
+	board := renderMiniBoard(tournament.Board{Round: 3, White: "a very long player name indeed", Black: "b", FEN: afterE4, LastMove: "e4", Plies: 1})
+	lines := strings.Split(board, "\n")
+	if len(lines) != 12 {
+		t.Fatalf("Expected 3 captions, 8 ranks and the last move, got %d lines:\n%s", len(lines), board)
+	}
+	if !strings.HasSuffix(lines[1], "…") {
+		t.Errorf("Expected a long name to be cut short, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[3], "♜ ♞ ♝ ♛ ♚") {
+		t.Errorf("Expected Black's pieces on the top rank, got %q", lines[3])
+	}
+	if !strings.Contains(lines[7], "♙") {
+		t.Errorf("Expected White's e-pawn on the fourth rank, got %q", lines[7])
+	}
+}
@@ -0,0 +1,61 @@
+package game
+
+import (
+	"chess-tui/gamedb"
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// SetDataset appends the AI's moves in each finished game to dataset as
+// training samples
+func (g *Game) SetDataset(dataset *gamedb.Dataset) {
+	g.dataset = dataset
+}
+
+// collectSamples adds the finished game's AI moves to the dataset, on GameEnded
+func (g *Game) collectSamples() {
+	if g.dataset == nil || g.gameMode != ModeHumanVsAI || g.chessGame.Outcome() == chess.NoOutcome {
+		return
+	}
+	if err := g.dataset.Add(g.trainingSamples()); err != nil {
+		g.log.Warn("Failed to save training samples", "error", err)
+	}
+}
+
+// trainingSamples returns a sample for each move the AI made
+func (g *Game) trainingSamples() []gamedb.Sample {
+	aiColor := g.humanColor.Other()
+	side := colorName(aiColor)
+	result := g.chessGame.Outcome().String()
+	positions := g.chessGame.Positions()
+
+	var samples []gamedb.Sample
+	for i, move := range g.chessGame.Moves() {
+		position := positions[i]
+		if position.Turn() != aiColor {
+			continue
+		}
+		legal := position.ValidMoves()
+		legalMoves := make([]string, len(legal))
+		for j, m := range legal {
+			legalMoves[j] = notation.Encode(position, m)
+		}
+		san := notation.Encode(position, move)
+		fen := position.String()
+		samples = append(samples, gamedb.Sample{
+			FEN:        fen,
+			LegalMoves: legalMoves,
+			Move:       san,
+			MoveUCI:    notation.EncodeUCI(move),
+			Side:       side,
+			Player:     g.aiName(),
+			Outcome:    gamedb.OutcomeFor(result, side),
+			Result:     result,
+			GameID:     g.id,
+			Prompt:     gamedb.SamplePrompt(fen, legalMoves),
+			Completion: " " + san,
+		})
+	}
+	return samples
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"chess-tui/ai_player"
+	"chess-tui/gamedb"
+
+	"github.com/notnil/chess"
+)
+
+func TestFinishedGameCollectsSamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dataset.jsonl")
+	g := NewGameWithMode(ModeHumanVsAI)
+	g.SetOpponent(ai_player.Opponent{Name: "Gus"}, &historyCheckingGenerator{})
+	g.SetDataset(gamedb.OpenDataset(path))
+	g.SetHumanColor(chess.Black)
+
+	for _, move := range []string{"f3", "e5", "g4", "Qh4#"} {
+		if err := g.applyMove(move); err != nil {
+			t.Fatalf("Expected %s to be legal, got %v", move, err)
+		}
+		g.updateStatus()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Expected a dataset file, got %v", err)
+	}
+	defer file.Close()
+	var samples []gamedb.Sample
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var sample gamedb.Sample
+		if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+			t.Fatalf("Expected a JSON sample per line, got %q: %v", scanner.Text(), err)
+		}
+		samples = append(samples, sample)
+	}
+
+	// Only the AI's moves, White's here, are samples
+	if len(samples) != 2 {
+		t.Fatalf("Expected 2 samples, got %d", len(samples))
+	}
+	first := samples[0]
+	if first.FEN != "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1" || first.Move != "f3" || first.MoveUCI != "f2f3" {
+		t.Errorf("Expected f3 from the start, got %s from %s", first.Move, first.FEN)
+	}
+	if len(first.LegalMoves) != 20 || !slices.Contains(first.LegalMoves, "Nf3") {
+		t.Errorf("Expected the 20 legal moves, got %v", first.LegalMoves)
+	}
+	if first.Side != "white" || first.Player != "Gus" || first.Outcome != gamedb.OutcomeLoss || first.Result != gamedb.BlackWon {
+		t.Errorf("Expected Gus's lost game as White, got %+v", first)
+	}
+	if !strings.Contains(first.Prompt, first.FEN) || first.Completion != " f3" {
+		t.Errorf("Expected a prompt with the position and the move as completion, got %q / %q", first.Prompt, first.Completion)
+	}
+}
+
+func TestHumanGamesCollectNoSamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dataset.jsonl")
+	g := NewGame()
+	g.SetDataset(gamedb.OpenDataset(path))
+	for _, move := range []string{"f3", "e5", "g4", "Qh4#"} {
+		g.makeMove(move)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no samples from a Human vs Human game, got %v", err)
+	}
+}
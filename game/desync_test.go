@@ -0,0 +1,141 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// historyCheckingGenerator answers with a DesyncError unless the history
+// matches the moves on the board, like the A2A server does
+type historyCheckingGenerator struct {
+	want  []string
+	calls int
+}
+
+func (h *historyCheckingGenerator) GetAIMoveResult(boardState string, gameHistory []string, errorMsg string, playerColor string) (*AIMoveResult, error) {
+	h.calls++
+	if strings.Join(gameHistory, " ") != strings.Join(h.want, " ") {
+		return nil, &DesyncError{ClientFEN: boardState, Ply: len(gameHistory)}
+	}
+	return &AIMoveResult{Move: "e5"}, nil
+}
+
+func (h *historyCheckingGenerator) SetPersonality(name string) {}
+
+func (h *historyCheckingGenerator) SuggestMoves(boardState string, gameHistory []string, playerColor string) ([]CandidateMove, error) {
+	return nil, errors.New("not used")
+}
+
+// playAIMove runs the AI's turn the way the program does, passing each
+// command's message back to Update until no more commands follow
+func playAIMove(g *Game) {
+	for cmd := g.getAIMove(); cmd != nil; {
+		_, cmd = g.Update(cmd())
+	}
+}
+
+func TestAIMoveResyncsHistory(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	generator := &historyCheckingGenerator{want: []string{"e4"}}
+	g.SetMoveGenerator(generator)
+
+	if err := g.applyMove("e4"); err != nil {
+		t.Fatalf("Expected e4 to be legal, got %v", err)
+	}
+	g.gameHistory = []string{"e2e4", "d5"} // stale history
+
+	playAIMove(g)
+
+	if generator.calls != 2 {
+		t.Errorf("Expected 2 requests, got %d", generator.calls)
+	}
+	if got := strings.Join(g.gameHistory, " "); got != "e4 e5" {
+		t.Errorf("Expected history e4 e5, got %s", got)
+	}
+	if g.err != "" {
+		t.Errorf("Expected no error, got %s", g.err)
+	}
+}
+
+func TestAIClientDesyncError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32010,"message":"Board desync",` +
+			`"data":{"client_fen":"a","server_fen":"b","ply":3}}}`))
+	}))
+	defer server.Close()
+
+	_, err := NewAIClient(server.URL).GetAIMoveResult("a", []string{"e4"}, "", "white")
+	var desync *DesyncError
+	if !errors.As(err, &desync) {
+		t.Fatalf("Expected a DesyncError, got %v", err)
+	}
+	if desync.ServerFEN != "b" || desync.Ply != 3 {
+		t.Errorf("Unexpected desync: %+v", desync)
+	}
+}
+
+func TestAIClientReplaySession(t *testing.T) {
+	var request struct {
+		Method string `json:"method"`
+		Params struct {
+			ContextID string   `json:"contextId"`
+			Moves     []string `json:"moves"`
+		} `json:"params"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"fen":"x","ply":2,"history":["e4","e5"]}}`))
+	}))
+	defer server.Close()
+
+	client := NewAIClient(server.URL)
+	if err := client.ReplaySession([]string{"e4", "e5"}, "x", "white"); err != nil {
+		t.Fatalf("Expected the replay to succeed, got %v", err)
+	}
+	if request.Method != "session/replay" || request.Params.ContextID != client.ContextID() || len(request.Params.Moves) != 2 {
+		t.Errorf("Expected the move list sent for the game's session, got %+v", request)
+	}
+}
+
+func TestAIClientResumeSession(t *testing.T) {
+	var request struct {
+		Method string `json:"method"`
+		Params struct {
+			ContextID string   `json:"contextId"`
+			FEN       string   `json:"fen"`
+			History   []string `json:"history"`
+		} `json:"params"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"contextId":"g","resumed":true,"replaced":true,"fen":"x","ply":2,"model":"llama3"}}`))
+	}))
+	defer server.Close()
+
+	client := NewAIClient(server.URL)
+	resumed, err := client.ResumeSession([]string{"e4", "e5"}, "x", "white")
+	if err != nil {
+		t.Fatalf("Expected the resume to succeed, got %v", err)
+	}
+	if request.Method != "session/resume" || request.Params.ContextID != client.ContextID() || request.Params.FEN != "x" || len(request.Params.History) != 2 {
+		t.Errorf("Expected the game's snapshot sent for its session, got %+v", request)
+	}
+	if !resumed.Replaced || resumed.Ply != 2 || resumed.Model != "llama3" {
+		t.Errorf("Expected the server's confirmation, got %+v", resumed)
+	}
+}
+
+func TestAIClientResumeSessionOnOlderServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewAIClient(server.URL).ResumeSession(nil, "x", "white"); !errors.Is(err, ErrMethodNotFound) {
+		t.Errorf("Expected ErrMethodNotFound, got %v", err)
+	}
+}
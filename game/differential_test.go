@@ -0,0 +1,43 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+// TestDifferentialMakeMove plays random games through the TUI's move path
+// alongside notnil/chess and flags any divergence in legality or FEN
+func TestDifferentialMakeMove(t *testing.T) {
+	rng := rand.New(rand.NewSource(4932))
+
+	for i := 0; i < 3; i++ {
+		reference := chess.NewGame()
+		g := NewGame()
+
+		for ply := 0; ply < 100 && reference.Outcome() == chess.NoOutcome; ply++ {
+			position := reference.Position()
+			moves := position.ValidMoves()
+			move := moves[rng.Intn(len(moves))]
+			san := chess.AlgebraicNotation{}.Encode(position, move)
+
+			// The AI client must pass the server's reply through unchanged
+			for _, text := range []string{san, "Move: " + san, "Generated move: " + san} {
+				if extracted, err := extractMove(text); err != nil || extracted != san {
+					t.Fatalf("extractMove(%q) = %q, %v", text, extracted, err)
+				}
+			}
+
+			if err := g.MakeMove(san); err != nil {
+				t.Fatalf("MakeMove rejected legal move %s in %s: %v", san, position, err)
+			}
+			if err := reference.Move(move); err != nil {
+				t.Fatalf("Reference rejected its own move: %v", err)
+			}
+			if got, want := g.GetBoardState(), reference.Position().String(); got != want {
+				t.Fatalf("FEN diverged after %s: expected %s, got %s", san, want, got)
+			}
+		}
+	}
+}
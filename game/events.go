@@ -0,0 +1,118 @@
+package game
+
+import (
+	"log/slog"
+	"os"
+
+	"chess-tui/errkind"
+	"chess-tui/events"
+)
+
+// subscribe wires the game's features to its events: the debug log, the
+// game database, adaptive difficulty, archive and training dataset, the time chart, the terminal bell, webhooks,
+// transcript from the settings, the plugin commentators, and the TUI's own status line.
+// The settings' hooks are subscribed for each game by subscribeHooks.
+func (g *Game) subscribe() {
+	g.bus.Subscribe(events.Log(slog.Default()))
+	g.bus.Subscribe(func(events.Event) { g.recordResult() }, events.GameEnded)
+	g.bus.Subscribe(func(events.Event) { g.scoreAdaptive() }, events.GameEnded)
+	g.bus.Subscribe(func(events.Event) { g.archiveGame() }, events.GameEnded)
+	g.bus.Subscribe(func(events.Event) { g.collectSamples() }, events.GameEnded)
+	g.bus.Subscribe(g.recordMoveTime, events.MoveMade)
+	g.bus.Subscribe(g.showEvent, events.AIThinkingStarted, events.ClockExpired)
+	if g.settings.Bell {
+		g.bus.Subscribe(events.Bell(os.Stderr))
+	}
+	for _, url := range g.settings.Webhooks {
+		g.bus.Subscribe(events.Webhook(url, nil))
+	}
+	if g.settings.Transcript != "" {
+		g.transcript = &transcript{path: g.settings.Transcript}
+		g.bus.Subscribe(g.transcribe)
+	}
+	g.subscribeCommentators()
+}
+
+// subscribeHooks subscribes the settings' hooks for the game about to be
+// played, with their commands ending with the program's context
+func (g *Game) subscribeHooks() {
+	g.unsubscribeHooks()
+	g.unhook = g.settings.Hooks.Subscribe(g.root, g.bus)
+}
+
+// unsubscribeHooks stops the hooks once the events already sent to them
+// have run, for when the game has ended, is reset or is quit
+func (g *Game) unsubscribeHooks() {
+	if g.unhook != nil {
+		g.unhook()
+		g.unhook = nil
+	}
+}
+
+// Events returns the bus the game publishes its events on, for subscribing
+// to what happens in it
+func (g *Game) Events() *events.Bus {
+	return g.bus
+}
+
+// showEvent updates the status line for events the TUI reports
+func (g *Game) showEvent(event events.Event) {
+	switch event.Kind {
+	case events.AIThinkingStarted:
+		g.status = "🤖 " + g.aiName() + " is thinking..."
+	case events.ClockExpired:
+		g.status = event.Color + " ran out of time"
+	}
+}
+
+// publishMove announces the move just played, and the check it gives.
+// remote is set for the AI's and the networked opponent's moves, and
+// reasoning is the AI's summary of its thinking, if any.
+func (g *Game) publishMove(remote bool, reasoning string) {
+	san := g.lastMoveSAN()
+	if san == "" {
+		return
+	}
+	event := events.Event{
+		Kind:      events.MoveMade,
+		Color:     colorName(g.chessGame.Position().Turn().Other()),
+		Move:      san,
+		FEN:       g.getBoardState(),
+		Remote:    remote,
+		Reasoning: reasoning,
+	}
+	g.bus.Publish(event)
+	if g.inCheck() {
+		event.Kind = events.CheckGiven
+		event.Reasoning = ""
+		g.bus.Publish(event)
+	}
+}
+
+// publishEnd announces the result once the game has ended
+func (g *Game) publishEnd() {
+	if g.ended || !g.over() {
+		return
+	}
+	g.ended = true
+	g.bus.Publish(events.Event{
+		Kind:   events.GameEnded,
+		Result: g.chessGame.Outcome().String(),
+		Method: g.endMethod(),
+		FEN:    g.getBoardState(),
+	})
+	g.unsubscribeHooks()
+}
+
+// publishAIFailure announces that the AI's answer was rejected, with kind
+// events.AIMoveRejected, or that its request failed, with events.AIError
+func (g *Game) publishAIFailure(kind events.Kind, move string, err error) {
+	g.bus.Publish(events.Event{
+		Kind:      kind,
+		Color:     colorName(g.chessGame.Position().Turn()),
+		Move:      move,
+		FEN:       g.getBoardState(),
+		Error:     err.Error(),
+		ErrorKind: string(errkind.Of(err)),
+	})
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"chess-tui/events"
+)
+
+func TestGamePublishesEvents(t *testing.T) {
+	g := NewGame()
+	var kinds []events.Kind
+	g.Events().Subscribe(func(e events.Event) { kinds = append(kinds, e.Kind) })
+
+	// Fool's mate: the last move checks and ends the game
+	for _, move := range []string{"f3", "e5", "g4", "Qh4#"} {
+		g.makeMove(move)
+	}
+	g.updateStatus() // the end is only published once
+
+	want := []events.Kind{events.MoveMade, events.MoveMade, events.MoveMade, events.MoveMade, events.CheckGiven, events.GameEnded}
+	if len(kinds) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, kinds)
+			break
+		}
+	}
+}
+
+func TestAIMovePublishesThinking(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	g.SetMoveGenerator(&historyCheckingGenerator{want: []string{"e4"}})
+	var moves []events.Event
+	thinking := 0
+	g.Events().Subscribe(func(e events.Event) { thinking++ }, events.AIThinkingStarted)
+	g.Events().Subscribe(func(e events.Event) { moves = append(moves, e) }, events.MoveMade)
+
+	g.makeMove("e4")
+	playAIMove(g)
+
+	if thinking != 1 {
+		t.Errorf("Expected 1 AIThinkingStarted, got %d", thinking)
+	}
+	if len(moves) != 2 || moves[0].Remote || !moves[1].Remote || moves[1].Move != "e5" || moves[1].Color != "black" {
+		t.Errorf("Expected the human's e4 and the AI's e5, got %+v", moves)
+	}
+}
+
+func TestHooksFollowOneGameAtATime(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "moves")
+	settings := DefaultSettings()
+	settings.Hooks = events.Hooks{OnMove: "cat >> " + out}
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	g.SetContext(ctx)
+
+	// Fool's mate ends the game, and the hook with it
+	for _, move := range []string{"f3", "e5", "g4", "Qh4#"} {
+		g.makeMove(move)
+	}
+	if g.unhook != nil {
+		t.Error("Expected the hooks unsubscribed once the game ended")
+	}
+	g.resetGame()
+	g.makeMove("d4")
+
+	var lines []string
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		data, _ := os.ReadFile(out)
+		if lines = strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) >= 5 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	data, _ := os.ReadFile(out)
+	if lines = strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 5 {
+		t.Errorf("Expected each move to run the hook once, got %d runs", len(lines))
+	}
+}
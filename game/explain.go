@@ -0,0 +1,229 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"chess-tui/notation"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// squareExplanation lists a piece's legal moves and why some tempting moves
+// aren't allowed
+type squareExplanation struct {
+	square  chess.Square
+	piece   chess.Piece
+	legal   []string // SAN
+	targets []chess.Square
+	illegal []illegalMove
+	note    string
+}
+
+// illegalMove is a square a piece seems able to reach but can't move to
+type illegalMove struct {
+	to     chess.Square
+	reason string
+}
+
+// explainSquare explains the moves of the piece on square. A piece of the
+// side not to move is explained as if it were its turn.
+func explainSquare(pos *chess.Position, square chess.Square) (*squareExplanation, error) {
+	piece := pos.Board().Piece(square)
+	if piece == chess.NoPiece {
+		return nil, fmt.Errorf("no piece on %s", square)
+	}
+
+	explanation := &squareExplanation{square: square, piece: piece}
+	if piece.Color() != pos.Turn() {
+		explanation.note = fmt.Sprintf("It's %s's turn; showing the moves this piece would have on %s's turn.",
+			pos.Turn().Name(), piece.Color().Name())
+		flipped, err := withTurn(pos, piece.Color())
+		if err != nil {
+			return nil, err
+		}
+		pos = flipped
+	}
+
+	legal := make(map[chess.Square]bool)
+	for _, move := range pos.ValidMoves() {
+		if move.S1() != square {
+			continue
+		}
+		explanation.legal = append(explanation.legal, chess.AlgebraicNotation{}.Encode(pos, move))
+		if !legal[move.S2()] {
+			legal[move.S2()] = true
+			explanation.targets = append(explanation.targets, move.S2())
+		}
+	}
+
+	for _, candidate := range reachableSquares(pos, square, piece) {
+		if legal[candidate] {
+			continue
+		}
+		if reason, message := notation.Why(pos, square, candidate); reason != "" {
+			explanation.illegal = append(explanation.illegal, illegalMove{to: candidate, reason: message})
+		}
+	}
+	return explanation, nil
+}
+
+// withTurn returns the position with the other side to move
+func withTurn(pos *chess.Position, turn chess.Color) (*chess.Position, error) {
+	fields := strings.Fields(pos.String())
+	fields[1] = "w"
+	if turn == chess.Black {
+		fields[1] = "b"
+	}
+	fields[3] = "-"
+	fen, err := chess.FEN(strings.Join(fields, " "))
+	if err != nil {
+		return nil, fmt.Errorf("failed to switch sides: %w", err)
+	}
+	return chess.NewGame(fen).Position(), nil
+}
+
+// reachableSquares returns the squares a piece's movement pattern reaches
+// from square, stopping each line at the first piece in the way
+func reachableSquares(pos *chess.Position, square chess.Square, piece chess.Piece) []chess.Square {
+	board := pos.Board()
+	file, rank := int(square.File()), int(square.Rank())
+	var squares []chess.Square
+	add := func(df, dr int) bool {
+		f, r := file+df, rank+dr
+		if f < 0 || f > 7 || r < 0 || r > 7 {
+			return false
+		}
+		squares = append(squares, chess.NewSquare(chess.File(f), chess.Rank(r)))
+		return board.Piece(chess.NewSquare(chess.File(f), chess.Rank(r))) == chess.NoPiece
+	}
+	slide := func(directions [][2]int) {
+		for _, d := range directions {
+			for step := 1; add(d[0]*step, d[1]*step); step++ {
+			}
+		}
+	}
+
+	straight := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	diagonal := [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+	switch piece.Type() {
+	case chess.Pawn:
+		forward := 1
+		if piece.Color() == chess.Black {
+			forward = -1
+		}
+		if add(0, forward) && (rank == 1 && forward == 1 || rank == 6 && forward == -1) {
+			add(0, 2*forward)
+		}
+		add(-1, forward)
+		add(1, forward)
+	case chess.Knight:
+		for _, d := range [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}} {
+			add(d[0], d[1])
+		}
+	case chess.Bishop:
+		slide(diagonal)
+	case chess.Rook:
+		slide(straight)
+	case chess.Queen:
+		slide(append(straight, diagonal...))
+	case chess.King:
+		for _, d := range append(straight, diagonal...) {
+			add(d[0], d[1])
+		}
+		if square == homeKingSquare(piece.Color()) {
+			add(2, 0)
+			add(-2, 0)
+		}
+	}
+	return squares
+}
+
+// homeKingSquare returns the square a side's king starts on
+func homeKingSquare(color chess.Color) chess.Square {
+	if color == chess.Black {
+		return chess.E8
+	}
+	return chess.E1
+}
+
+// parseSquare returns the square named by a coordinate such as "e4"
+func parseSquare(name string) (chess.Square, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for sq := chess.A1; sq <= chess.H8; sq++ {
+		if sq.String() == name {
+			return sq, true
+		}
+	}
+	return chess.NoSquare, false
+}
+
+// explainInput explains the square typed in the move input, or hides the
+// explanation if the input is empty
+func (g *Game) explainInput() {
+	g.err = ""
+	name := g.input.Value()
+	g.input.SetValue("")
+	if name == "" {
+		g.clearExplanation()
+		return
+	}
+	square, ok := parseSquare(name)
+	if !ok {
+		g.err = fmt.Sprintf("type a square such as e2, then press ? (got %q)", name)
+		return
+	}
+	explanation, err := explainSquare(g.chessGame.Position(), square)
+	if err != nil {
+		g.err = err.Error()
+		return
+	}
+	g.explanation = explanation
+	g.selected = square.String()
+	g.targets = explanation.targets
+}
+
+// clearExplanation hides the explained square and its highlights
+func (g *Game) clearExplanation() {
+	if g.explanation == nil {
+		return
+	}
+	g.explanation = nil
+	g.selected = ""
+	g.targets = nil
+}
+
+// renderExplainPanel renders the explained square's moves as a side panel
+func (g *Game) renderExplainPanel() string {
+	e := g.explanation
+	if e == nil {
+		return ""
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00AAFF"))
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA")).Width(teachPanelWidth - 2)
+	illegalStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF8888")).Width(teachPanelWidth - 2)
+
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render(fmt.Sprintf("%s %s on %s", e.piece.Color().Name(), pieceNames[e.piece.Type()], e.square)) + "\n")
+	if e.note != "" {
+		sb.WriteString(textStyle.Render(e.note) + "\n")
+	}
+	if len(e.legal) == 0 {
+		sb.WriteString(textStyle.Render("No legal moves") + "\n")
+	} else {
+		sb.WriteString(textStyle.Render("Legal: "+strings.Join(e.legal, ", ")) + "\n")
+	}
+	if len(e.illegal) > 0 {
+		sb.WriteString("\n" + headerStyle.Render("Not allowed") + "\n")
+		for _, move := range e.illegal {
+			sb.WriteString(illegalStyle.Render(move.to.String()+": "+move.reason) + "\n")
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Width(teachPanelWidth).
+		MarginLeft(2).
+		Render(strings.TrimRight(sb.String(), "\n"))
+}
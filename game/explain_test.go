@@ -0,0 +1,138 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// positionFromFEN returns the position described by fen
+func positionFromFEN(t *testing.T, fen string) *chess.Position {
+	t.Helper()
+	option, err := chess.FEN(fen)
+	if err != nil {
+		t.Fatalf("Invalid FEN %s: %v", fen, err)
+	}
+	return chess.NewGame(option).Position()
+}
+
+// reasonFor returns why a move to square is illegal, or "" if it isn't listed
+func reasonFor(e *squareExplanation, square string) string {
+	for _, move := range e.illegal {
+		if move.to.String() == square {
+			return move.reason
+		}
+	}
+	return ""
+}
+
+func TestExplainSquare(t *testing.T) {
+	tests := []struct {
+		name    string
+		fen     string
+		square  chess.Square
+		legal   []string
+		illegal map[string]string // square → part of the reason
+	}{
+		{
+			name:    "pawn",
+			fen:     chess.StartingPosition().String(),
+			square:  chess.E2,
+			legal:   []string{"e3", "e4"},
+			illegal: map[string]string{"d3": "only to capture", "f3": "only to capture"},
+		},
+		{
+			name:    "pinned knight",
+			fen:     "4k3/8/8/1b6/8/3N4/8/5K2 w - - 0 1",
+			square:  chess.D3,
+			illegal: map[string]string{"b4": "pinned", "e5": "bishop on b5"},
+		},
+		{
+			name:    "king",
+			fen:     "4k3/8/8/8/8/8/5r2/4K2R w K - 0 1",
+			square:  chess.E1,
+			legal:   []string{"Kxf2"},
+			illegal: map[string]string{"e2": "attacked by the rook on f2", "g1": "f1 is attacked", "f1": "attacked by the rook on f2"},
+		},
+		{
+			name:    "blocked castling",
+			fen:     chess.StartingPosition().String(),
+			square:  chess.E1,
+			illegal: map[string]string{"g1": "bishop on f1 is in the way"},
+		},
+		{
+			name:    "check not answered",
+			fen:     "4k3/8/8/8/7q/8/8/4K1B1 w - - 0 1",
+			square:  chess.G1,
+			legal:   []string{"Bf2"},
+			illegal: map[string]string{"h2": "doesn't stop the queen on h4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := explainSquare(positionFromFEN(t, tt.fen), tt.square)
+			if err != nil {
+				t.Fatalf("Failed to explain %s: %v", tt.square, err)
+			}
+			for _, want := range tt.legal {
+				found := false
+				for _, move := range e.legal {
+					found = found || strings.TrimSuffix(move, "+") == want
+				}
+				if !found {
+					t.Errorf("Expected %s among legal moves, got %v", want, e.legal)
+				}
+			}
+			if tt.legal == nil && len(e.legal) > 0 {
+				t.Errorf("Expected no legal moves, got %v", e.legal)
+			}
+			for square, want := range tt.illegal {
+				if reason := reasonFor(e, square); !strings.Contains(reason, want) {
+					t.Errorf("Expected the reason for %s to mention %q, got %q", square, want, reason)
+				}
+			}
+		})
+	}
+}
+
+func TestExplainOpponentPiece(t *testing.T) {
+	e, err := explainSquare(positionFromFEN(t, chess.StartingPosition().String()), chess.G8)
+	if err != nil {
+		t.Fatalf("Failed to explain g8: %v", err)
+	}
+	if e.note == "" {
+		t.Error("Expected a note that it isn't Black's turn")
+	}
+	if len(e.targets) != 2 {
+		t.Errorf("Expected the knight's 2 moves, got %v", e.legal)
+	}
+
+	if _, err := explainSquare(positionFromFEN(t, chess.StartingPosition().String()), chess.E4); err == nil {
+		t.Error("Expected an error for an empty square")
+	}
+}
+
+func TestExplainKeyHighlightsMoves(t *testing.T) {
+	g := NewGame()
+	g.input.SetValue("g1")
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+
+	if g.selected != "g1" || len(g.targets) != 2 {
+		t.Fatalf("Expected g1 selected with 2 targets, got %q and %v", g.selected, g.targets)
+	}
+	marks := g.squareMarks()
+	if marks[chess.F3] != markTarget || marks[chess.H3] != markTarget {
+		t.Error("Expected f3 and h3 to be marked as targets")
+	}
+	if !strings.Contains(g.View(), "Legal: Nf3, Nh3") && !strings.Contains(g.View(), "Legal: Nh3, Nf3") {
+		t.Error("Expected the legal moves in the view")
+	}
+
+	g.makeMove("Nf3")
+	if g.explanation != nil || g.selected != "" || len(g.targets) != 0 {
+		t.Error("Expected the explanation to clear after a move")
+	}
+}
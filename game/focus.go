@@ -0,0 +1,53 @@
+package game
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// focus follows whether the terminal has focus, for terminals that report
+// it. While the terminal is in the background the screen is frozen at its
+// last frame and, in casual games, the clock stops.
+type focus struct {
+	blurred   bool
+	blurredAt time.Time
+	frame     string // the screen as it was when focus was lost
+}
+
+// updateFocus handles the terminal gaining or losing focus, reporting
+// whether msg was a focus event
+func (g *Game) updateFocus(msg tea.Msg) bool {
+	switch msg.(type) {
+	case tea.BlurMsg:
+		if !g.focus.blurred {
+			g.focus = focus{blurred: true, blurredAt: time.Now()}
+		}
+		return true
+	case tea.FocusMsg:
+		if g.focus.blurred && g.casual() && !g.lastMoveAt.IsZero() {
+			// Time away from the game doesn't count against the move
+			g.lastMoveAt = g.lastMoveAt.Add(time.Since(g.focus.blurredAt))
+		}
+		g.focus = focus{}
+		return true
+	}
+	return false
+}
+
+// casual reports whether the game is played without an opponent waiting on
+// the other end, so its clock can stop while the player is away
+func (g *Game) casual() bool {
+	return g.peer == nil
+}
+
+// View renders the game, or the frame it showed when the terminal lost focus
+func (g *Game) View() string {
+	if !g.focus.blurred {
+		return g.render()
+	}
+	if g.focus.frame == "" {
+		g.focus.frame = g.render()
+	}
+	return g.focus.frame
+}
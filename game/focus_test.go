@@ -0,0 +1,54 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBlurFreezesScreenAndClock(t *testing.T) {
+	g := NewGame()
+	g.makeMove("e4")
+	g.Update(tea.BlurMsg{})
+	frozen := g.View()
+
+	// Moves made while away show once focus returns
+	g.makeMove("e5")
+	if g.View() != frozen {
+		t.Error("Expected the screen frozen while the terminal is unfocused")
+	}
+
+	// The time away doesn't count against the move
+	g.focus.blurredAt = g.focus.blurredAt.Add(-time.Minute)
+	before := g.lastMoveAt
+	g.Update(tea.FocusMsg{})
+	if away := g.lastMoveAt.Sub(before); away < time.Minute {
+		t.Errorf("Expected the clock moved on by the minute away, got %v", away)
+	}
+	if view := g.View(); view == frozen || !strings.Contains(view, "e5") {
+		t.Error("Expected the screen drawn again on focus")
+	}
+}
+
+func TestReplayHoldsWhileBlurred(t *testing.T) {
+	r, err := NewReplay("test", replayMoves, DefaultSettings())
+	if err != nil {
+		t.Fatalf("Expected the game to load, got %v", err)
+	}
+	replayKey(r, " ")
+	stale := r.generation
+
+	if _, cmd := r.Update(tea.BlurMsg{}); cmd != nil {
+		t.Error("Expected no auto-play step while unfocused")
+	}
+	r.Update(replayTickMsg{generation: stale})
+	if r.ply != 0 {
+		t.Errorf("Expected auto-play held while unfocused, got ply %d", r.ply)
+	}
+
+	if _, cmd := r.Update(tea.FocusMsg{}); cmd == nil || !r.playing {
+		t.Error("Expected auto-play to resume on focus")
+	}
+}
@@ -0,0 +1,55 @@
+package game
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func FuzzExtractMove(f *testing.F) {
+	for _, seed := range []string{
+		"Generated move: Nbd7", "Generated move: exd8=Q+", "Move: e7e8q", "O-O-O+",
+		"e4", "", "Move: ", "Generated move: ", "a very long reply that is not a move",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		move, err := extractMove(text)
+		if err != nil {
+			return
+		}
+		if move == "" {
+			t.Errorf("extractMove(%q) accepted an empty move", text)
+		}
+		if !strings.Contains(text, move) {
+			t.Errorf("extractMove(%q) returned %q, which is not in the reply", text, move)
+		}
+	})
+}
+
+func FuzzExtractMoveData(f *testing.F) {
+	f.Add(`[{"kind":"text","text":"Generated move: e4"},{"kind":"data","data":{"reasoning":"center","provider":"openai","prompt_tokens":12,"completion_tokens":3}}]`)
+	f.Add(`[{"kind":"data","data":{"prompt_tokens":"12","reasoning":7}}]`)
+	f.Add(`[{"kind":"data","data":null},null,1,"x"]`)
+
+	f.Fuzz(func(t *testing.T, reply string) {
+		var parts []interface{}
+		if err := json.Unmarshal([]byte(reply), &parts); err != nil {
+			return
+		}
+		// Untrusted server data must never panic the client
+		extractMoveData(parts, &AIMoveResult{})
+	})
+}
+
+func TestExtractMoveTrimsWhitespace(t *testing.T) {
+	for _, text := range []string{"Generated move: Nbd7\n", "Move:  Nbd7 ", " Nbd7\n"} {
+		if move, err := extractMove(text); err != nil || move != "Nbd7" {
+			t.Errorf("extractMove(%q) = %q, %v; expected Nbd7", text, move, err)
+		}
+	}
+	if _, err := extractMove("Generated move:  \n"); err == nil {
+		t.Error("Expected an error for a reply without a move")
+	}
+}
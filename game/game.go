@@ -1,10 +1,22 @@
 package game
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
+	"chess-tui/ai_player"
+	"chess-tui/errkind"
+	"chess-tui/events"
+	"chess-tui/gamedb"
+	"chess-tui/netplay"
+	"chess-tui/notation"
+	"chess-tui/tournament"
+
+	"github.com/charmbracelet/bubbles/cursor"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -16,22 +28,113 @@ type Game struct {
 	chessGame     *chess.Game
 	input         textinput.Model
 	status        string
+	commentary    string // the plugin commentators' latest remark
 	err           string
 	selected      string
+	targets       []chess.Square // squares highlighted as places to move to
+	exploded      []chess.Square // squares an Atomic capture just blew up
+	explanation   *squareExplanation
+	handoff       bool      // the privacy screen is up between hot-seat turns
+	paused        bool      // the game is paused, its board hidden and timer stopped
+	phonePage     int       // the page of panels the phone layout shows
+	title         string    // the terminal title last set
+	pausedAt      time.Time // when the game was paused
+	showHeatmap   bool
+	heatmapKind   gamedb.HeatmapKind
 	validMoves    []chess.Move
 	gameMode      GameMode
+	humanColor    chess.Color         // side the human plays against the AI
+	opponent      *ai_player.Opponent // named AI opponent, if any
+	adaptive      *adaptiveOpponent   // how the opponent's difficulty adapts, if it does
 	aiClient      *AIClient
+	ai            MoveGenerator
 	gameHistory   []string
 	isAITurn      bool
 	aiMovePending bool
+	settings      *Settings
+	showSummary   bool
+
+	drawOffer        chess.Color
+	pendingPromotion string
+	confirming       string // a move waiting for the player to confirm it
+	startFEN         string // the position the game started from, if not the standard one
+
+	aiReasoning   string
+	aiVotes       []Vote          // how a panel of models voted on the AI's last move, if it did
+	confidence    map[int]float64 // the AI's confidence in its moves, keyed by ply
+	spareMoves    map[int]string  // the AI's moves its server's spare model answered, by ply, naming it
+	showReasoning bool
+	tokenUsage    TokenUsage
+	aiProvider    string
+	aiStatus      AIStatus // the server's progress on the pending AI move
+	aiFailure     error    // why the AI's last move failed, until enter asks again
+	accuracy      accuracyTracker
+
+	teachCandidates []CandidateMove
+	teachPending    bool
+	teachErr        string
+	consult         *consultation // the advisor two humans share, if any
+
+	analysis   *analysisBoard
+	variations map[int]*variationNode // saved analysis, keyed by the ply it starts from
+
+	split *splitView // the analysis board beside the game, if open
+
+	kibitz *kibitzer // the AI commenting on a Human vs Human game, if any
+
+	bookmarks     []gamedb.Bookmark
+	bookmarkInput *textinput.Model // the note prompt, while a bookmark is being added
+
+	sealInput  *textinput.Model    // the sealed move or seal code prompt
+	sealed     *gamedb.Adjournment // the resumed game whose sealed move is still closed
+	sealedPath string              // the file the resumed game was adjourned to
+	adjourned  *adjournedGame      // set once the game is adjourned
+
+	annotations map[int][]annotation // arrows and highlights, keyed by the ply of their position
+	annotating  *annotator           // the drawing mode, while on
+	picking     *picker              // the cursor move mode, while on
+
+	moveTimes  tournament.TimeUsage // how long each move took, for the post-game chart
+	lastMoveAt time.Time            // when the last move was made, or the game started
+	startedAt  time.Time            // when the game started, for its PGN date
+	ticking    bool                 // the clock's next tick is on its way
+	flagged    chess.Color          // the side that lost on time, if one did
+	abandoned  chess.Color          // the networked opponent who left for good and forfeited, if one did
+	aborted    string               // why a networked game was aborted without a result, if it was
+	houseRule  string               // the house rule that decided the game, e.g. "the 40-move limit"
+
+	ponder *pondering // the AI's answer to the player's expected reply, in bullet mode
+
+	db      *gamedb.DB      // where finished games are recorded, if set
+	archive *gamedb.Archive // where finished games' PGN is kept, if set
+	dataset *gamedb.Dataset // where the AI's moves are collected as training data, if set
+	bus     *events.Bus     // what happens in the game, for the features that follow it
+	ended   bool            // whether the end of this game has been published
+	unhook  func()          // unsubscribes the settings' hooks, if subscribed
+
+	transcript *transcript // where the session's events are written, if set
+
+	peer         *netplay.Peer // the networked opponent, if any
+	netStatus    string        // the state of the connection to the opponent
+	netConnected bool
+	watchers     int    // spectators watching the networked game
+	invite       string // a private lobby game's invite code, shown until the opponent joins
+
+	root   context.Context    // the program's context, set with SetContext
+	ctx    context.Context    // the current game's; ends on reset and quit
+	cancel context.CancelFunc // ends ctx
+
+	boardImage boardCache // the last encoded image board
+	boardText  boardCache // the last board drawn in low-bandwidth mode
+	focus      focus      // whether the terminal is in the foreground
+
+	id  string       // identifies the game in events, logs, AI requests and saved games
+	log *slog.Logger // logs with the game's ID
 }
 
 // aiMoveRequestedMsg is a message that signals the AI move should be requested
 type aiMoveRequestedMsg struct{}
 
-// aiMoveCompletedMsg is a message that signals the AI move has been completed
-type aiMoveCompletedMsg struct{}
-
 // NewGame creates a new chess game
 func NewGame() *Game {
 	return NewGameWithMode(ModeHumanVsHuman)
@@ -39,27 +142,46 @@ func NewGame() *Game {
 
 // NewGameWithMode creates a new chess game with a specific mode
 func NewGameWithMode(mode GameMode) *Game {
+	return NewGameWithSettings(mode, DefaultSettings())
+}
+
+// NewGameWithSettings creates a new chess game with a specific mode and display settings
+func NewGameWithSettings(mode GameMode, settings *Settings) *Game {
 	input := textinput.New()
 	input.Placeholder = "e4"
 	input.Focus()
 	input.CharLimit = 10
 	input.Width = 20
+	if settings.LowBandwidth {
+		// A blinking cursor is a redraw twice a second
+		input.Cursor.SetMode(cursor.CursorStatic)
+	}
 
 	game := &Game{
 		chessGame:     chess.NewGame(chess.UseNotation(chess.AlgebraicNotation{})),
 		input:         input,
-		status:        "White's turn",
+		status:        "White to move",
 		validMoves:    []chess.Move{},
 		gameMode:      mode,
+		humanColor:    chess.White,
 		gameHistory:   []string{},
 		isAITurn:      false,
 		aiMovePending: false,
+		settings:      settings,
+		bus:           events.NewBus(),
 	}
+	game.subscribe()
+	game.startClock()
 
 	// Initialize AI client if playing against AI
 	if mode == ModeHumanVsAI {
-		game.aiClient = NewAIClient("")
+		game.aiClient = NewAIClient(settings.AIServer)
+		game.ai = game.aiClient
+		game.ai.SetPersonality(settings.Personality)
+		game.bindBullet()
 	}
+	game.newGameID()
+	game.SetContext(context.Background())
 
 	return game
 }
@@ -69,60 +191,290 @@ func (g *Game) Init() tea.Cmd {
 	return tea.Batch(
 		textinput.Blink,
 		g.input.Cursor.BlinkCmd(),
+		g.waitForPeer(),
+		g.titleCmd(),
+		g.clockCmd(),
+		g.kibitzCmd(),
 	)
 }
 
-// Update handles game updates
+// Update handles game updates, and keeps the terminal title up to date
 func (g *Game) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := g.update(msg)
+	if g.title == "" {
+		// The game was never shown, so the title isn't ours to change
+		return model, cmd
+	}
+	if title := g.titleCmd(); title != nil {
+		cmd = tea.Batch(cmd, title)
+	}
+	return model, cmd
+}
+
+// update handles game updates
+func (g *Game) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if g.updateFocus(msg) {
+		return g, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		// Handle global keyboard shortcuts
-		switch msg.String() {
+		// Any key lifts the privacy screen for the next player
+		if g.handoff && msg.String() != "ctrl+c" {
+			g.handoff = false
+			return g, nil
+		}
+		// and resumes a paused game
+		if g.paused && msg.String() != "ctrl+c" && msg.String() != "q" {
+			return g, g.resume()
+		}
+		// An adjourned game quits on any key
+		if g.adjourned != nil {
+			g.shutdown()
+			return g, tea.Quit
+		}
+		if g.sealInput != nil {
+			return g, g.updateSeal(msg)
+		}
+
+		if g.confirming != "" {
+			return g, g.updateConfirm(msg)
+		}
+		if g.bookmarkInput != nil {
+			return g, g.updateBookmark(msg)
+		}
+		if g.annotating != nil {
+			return g, g.updateAnnotator(msg)
+		}
+		if g.picking != nil {
+			return g, g.updatePicker(msg)
+		}
+
+		// The analysis board handles its own keys
+		if g.analysis != nil {
+			if model, cmd, handled := g.updateAnalysis(msg); handled {
+				return model, cmd
+			}
+		}
+		if g.split != nil && g.updateSplit(msg) {
+			return g, nil
+		}
+
+		// Handle global keyboard shortcuts. Single characters are typed into
+		// a move already started, such as the promotion in e7e8q, or into
+		// the promotion prompt; square names still explain with ?
+		key := msg.String()
+		if msg.Type == tea.KeyRunes && key != "?" && (g.input.Value() != "" || g.pendingPromotion != "") {
+			key = ""
+		}
+		switch key {
 		case "q", "ctrl+c":
+			g.shutdown()
 			return g, tea.Quit
 		case "r":
-			return g, g.resetGame()
-		case "h":
-			return g, g.showHelp()
+			if g.peer != nil {
+				g.status = "A networked game can't be reset"
+				return g, nil
+			}
+			g.resetGame()
+			return g, g.takeAITurn()
+		case "H":
+			// Help; h starts h-file moves
+			g.showHelp()
+			return g, nil
+		case "[", "]":
+			// Page through the panels of the phone layout
+			if g.settings.Phone {
+				by := 1
+				if msg.String() == "[" {
+					by = -1
+				}
+				g.turnPhonePage(by)
+				return g, nil
+			}
+		case "P":
+			// Pause the game, hiding the board and stopping the clock
+			g.pause()
+			return g, nil
+		case "ctrl+a":
+			// Adjourn the game with a sealed move
+			return g, g.startAdjourn()
+		case "tab":
+			// Move a piece with the cursor
+			g.startPicking()
+			return g, nil
+		case "?":
+			// Explain the moves of the piece on the typed square
+			g.explainInput()
+			return g, nil
+		case "p":
+			// Cycle through the board palettes
+			g.settings.Palette = nextPalette(g.settings.Palette)
+			g.status = "Palette: " + g.settings.Palette
+			return g, nil
+		case "o":
+			// Offer a draw, or accept the opponent's offer
+			g.offerDraw()
+			return g, nil
+		case "v":
+			// Explore variations on a scratch board
+			g.enterAnalysis()
+			return g, nil
+		case "|":
+			// Open or close the analysis board beside the game
+			g.toggleSplit()
+			return g, nil
+		case "ctrl+s":
+			// Save the game and analysis as PGN
+			g.savePGN()
+			return g, nil
+		case "ctrl+g":
+			// Upload the PGN and show a link to share
+			return g, g.sharePGN()
+		case "ctrl+p":
+			// Save the screen as shown to .ans and .txt files
+			g.screenshot()
+			return g, nil
+		case "ctrl+f":
+			// Toggle flipping the board to the side to move in hot-seat games
+			g.settings.AutoFlip = !g.settings.AutoFlip
+			if g.settings.AutoFlip {
+				g.status = "Auto-flip on: the board turns to face the side to move"
+			} else {
+				g.status = "Auto-flip off"
+			}
+			return g, nil
+		case "m":
+			// Cycle the heatmaps of the moves played
+			nextHeatmap(&g.showHeatmap, &g.heatmapKind)
+			return g, nil
+		case "z":
+			// Toggle the distraction-free zen view
+			g.settings.Zen = !g.settings.Zen
+			return g, nil
+		case "t":
+			// Teach mode: ask the AI for candidate moves
+			return g, g.requestCandidates()
+		case "w":
+			// Expand or collapse the AI reasoning panel
+			g.showReasoning = !g.showReasoning
+			return g, nil
+		case "s":
+			// Cycle the board styles
+			g.settings.BoardStyle = nextBoardStyle(g.settings.BoardStyle, g.graphics())
+			return g, nil
+		case "n":
+			// Toggle figurine notation in the move list
+			g.settings.Figurine = !g.settings.Figurine
+			return g, nil
+		case "A":
+			// Draw arrows and highlights on the position
+			g.startAnnotating()
+			return g, nil
+		case "ctrl+b":
+			// Bookmark the position; b itself starts b-file moves
+			return g, g.startBookmark()
+		case "k":
+			// Quiz the player on the key moves of the finished game
+			if g.chessGame.Outcome() != chess.NoOutcome {
+				return g.startQuiz()
+			}
+		case "l":
+			// Toggle the linear board summary in accessibility mode
+			if g.settings.Accessible {
+				g.showSummary = !g.showSummary
+				return g, nil
+			}
 		case "enter":
+			// Ask the AI again after its move failed
+			if g.input.Value() == "" && g.aiFailure != nil && g.isAITurn {
+				return g, g.retryAIMove()
+			}
 			// Only handle enter if we have input to process and it's not AI's turn
 			if g.input.Value() != "" && !g.isAITurn {
-				slog.Debug("Enter pressed", "input_value", g.input.Value())
-				return g, g.makeMove(g.input.Value())
+				g.log.Debug("Enter pressed", "input_value", g.input.Value())
+				g.makeMove(g.input.Value())
+				return g, g.takeAITurn()
 			}
 		}
+	case clockTickMsg:
+		return g, g.checkClock()
+	case kibitzTickMsg, kibitzMsg:
+		return g, g.updateKibitz(msg)
+	case teachResultMsg:
+		g.applyCandidates(msg)
+		return g, nil
+	case shareResultMsg:
+		g.applyShareResult(msg)
+		return g, nil
+	case peerEventMsg:
+		// Show the networked opponent's move and wait for the next
+		g.applyPeerEvent(msg.event)
+		return g, tea.Batch(g.waitForPeer(), g.clockCmd())
+	case aiStatusMsg:
+		// Show the AI server's progress and wait for the next update
+		if g.isAITurn {
+			g.aiStatus = msg.status
+		}
+		return g, msg.feed.wait()
 	case aiMoveRequestedMsg:
 		// AI move was requested, execute it
-		slog.Debug("Received aiMoveRequestedMsg, executing getAIMove")
+		g.log.Debug("Received aiMoveRequestedMsg, executing getAIMove")
 		return g, g.getAIMove()
-	case aiMoveCompletedMsg:
-		// AI move completed, refresh the TUI
-		slog.Debug("Received aiMoveCompletedMsg, refreshing TUI")
-		return g, nil
+	case aiMoveMsg:
+		// Play the AI's answer, or ask again if it was rejected
+		return g, g.applyAIMove(msg)
+	case ponderMsg:
+		// Keep the answer pondered in bullet mode, or play it if it's due
+		return g, g.applyPonder(msg)
 	default:
 		// Check if AI move is pending
-		if g.aiMovePending {
-			slog.Debug("AI move pending, executing getAIMove")
-			g.aiMovePending = false
-			return g, g.getAIMove()
+		if g.aiMovePending && !g.paused {
+			g.log.Debug("AI move pending, executing getAIMove")
+			return g, g.takeAITurn()
 		}
 	}
 
-	// Only update text input if it's not AI's turn
+	// Only update text input if it's not AI's turn, unless the moves typed
+	// are for the analysis board
 	var cmd tea.Cmd
-	if !g.isAITurn {
-		slog.Debug("Updating text input", "isAITurn", g.isAITurn)
+	if !g.isAITurn || g.split != nil && g.split.focused {
+		g.log.Debug("Updating text input", "isAITurn", g.isAITurn)
 		g.input, cmd = g.input.Update(msg)
+		if _, typed := msg.(tea.KeyMsg); typed {
+			if move, ok := g.autoSubmit(); ok {
+				return g, tea.Batch(cmd, move)
+			}
+		}
 	} else {
-		slog.Debug("Skipping text input update", "isAITurn", g.isAITurn)
+		g.log.Debug("Skipping text input update", "isAITurn", g.isAITurn)
 	}
 	return g, cmd
 }
 
-// View renders the game
-func (g *Game) View() string {
+// render draws the game
+func (g *Game) render() string {
 	var sb strings.Builder
 
+	if g.paused {
+		return g.pauseView()
+	}
+	if g.sealInput != nil || g.adjourned != nil {
+		return g.sealView()
+	}
+	if g.settings.Accessible {
+		return g.accessibleView()
+	}
+	if g.handoff {
+		return g.privacyView()
+	}
+	if g.settings.Zen {
+		return g.zenView()
+	}
+	if g.settings.Phone {
+		return g.phoneView()
+	}
+
 	// Title
 	title := lipgloss.NewStyle().
 		Bold(true).
@@ -130,30 +482,49 @@ func (g *Game) View() string {
 		Render("♔ Chess TUI ♛")
 	sb.WriteString(title + "\n\n")
 
-	// Board
-	sb.WriteString(g.renderBoard())
+	// Board and move list
+	sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, g.renderBoard(), g.renderSplitPanel(), g.renderMoveList(), g.renderKibitzPanel(), g.renderBookmarkPanel(), g.renderAnnotationPanel(), g.renderTeachPanel(), g.renderVotePanel(), g.renderExplainPanel(), g.renderHeatmapPanel()))
 	sb.WriteString("\n\n")
 
 	// Game mode
 	modeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF"))
-	var modeText string
-	switch g.gameMode {
-	case ModeHumanVsHuman:
-		modeText = "Human vs Human"
-	case ModeHumanVsAI:
-		modeText = "Human vs AI"
+	sb.WriteString(modeStyle.Render("Mode: "+g.modeText()) + "\n")
+	if g.peer != nil {
+		icon := "🟢 "
+		if !g.netConnected {
+			icon = "🔄 "
+		}
+		sb.WriteString(modeStyle.Render(icon+g.netText()) + "\n")
+	}
+	if g.consult != nil {
+		sb.WriteString(modeStyle.Render(g.hintsText()) + "\n")
+	}
+	if clock := g.clockText(); clock != "" {
+		sb.WriteString(modeStyle.Render(clock) + "\n")
+	}
+	if rules := g.houseRulesText(); rules != "" {
+		sb.WriteString(modeStyle.Render(rules) + "\n")
+	}
+	if g.tokenUsage.Moves > 0 {
+		sb.WriteString(modeStyle.Render(g.tokenUsage.Summary(g.settings)) + "\n")
 	}
-	sb.WriteString(modeStyle.Render("Mode: "+modeText) + "\n")
 
 	// Debug info
-	slog.Debug("Game state", "gameMode", g.gameMode, "isAITurn", g.isAITurn, "turn", g.chessGame.Position().Turn())
+	g.log.Debug("Game state", "gameMode", g.gameMode, "isAITurn", g.isAITurn, "turn", g.chessGame.Position().Turn())
 	sb.WriteString(fmt.Sprintf("DEBUG: gameMode=%d, isAITurn=%t, turn=%s\n",
 		g.gameMode, g.isAITurn, g.chessGame.Position().Turn()))
 
 	// Additional debug info
-	slog.Debug("View function state", "status", g.status, "err", g.err, "input_focused", !g.isAITurn)
+	g.log.Debug("View function state", "status", g.status, "err", g.err, "input_focused", !g.isAITurn)
 	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
-	sb.WriteString(statusStyle.Render(g.status) + "\n")
+	sb.WriteString(statusStyle.Render(g.status))
+	if accuracy := g.renderAccuracy(); accuracy != "" {
+		sb.WriteString("  " + accuracy)
+	}
+	sb.WriteString("\n")
+	if g.commentary != "" {
+		sb.WriteString(modeStyle.Render(g.formatText(g.commentary, g.chessGame.Position().Turn().Other())) + "\n")
+	}
 
 	// Error message
 	if g.err != "" {
@@ -161,72 +532,147 @@ func (g *Game) View() string {
 		sb.WriteString(errStyle.Render("Error: "+g.err) + "\n")
 	}
 
-	// Input
-	if g.isAITurn {
-		sb.WriteString("\n🤖 AI is thinking...")
-	} else {
-		sb.WriteString("\nEnter move (e.g., e4): ")
-		sb.WriteString(g.input.View())
+	// How White's chances went, through the game and after it
+	if panel := g.renderWinProbabilityPanel(sparklineWidth); panel != "" {
+		sb.WriteString("\n" + panel + "\n")
 	}
 
+	// Where the time went, once the game is over
+	if panel := g.renderTimePanel(); panel != "" {
+		sb.WriteString("\n" + panel + "\n")
+	}
+	if panel := g.renderUncertaintyPanel(); panel != "" {
+		sb.WriteString("\n" + panel + "\n")
+	}
+
+	// AI reasoning panel
+	if panel := g.renderReasoningPanel(); panel != "" {
+		sb.WriteString(panel + "\n")
+	}
+
+	// Input
+	sb.WriteString("\n" + g.inputLine())
+
 	// Help
 	sb.WriteString("\n\n")
 	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
-	sb.WriteString(helpStyle.Render("Commands: [q]uit, [r]eset, [h]elp"))
+	sb.WriteString(helpStyle.Render(g.helpText()))
 
 	return sb.String()
 }
 
+// modeText describes the game mode and who is playing
+func (g *Game) modeText() string {
+	var modeText string
+	switch g.gameMode {
+	case ModeHumanVsHuman:
+		modeText = "Human vs Human"
+		if g.spectating() {
+			modeText = "Network — watching"
+		} else if g.peer != nil {
+			modeText = "Network — you play " + g.humanColor.Name()
+		}
+		if g.consult != nil {
+			modeText += " — consulting an AI advisor"
+		}
+	case ModeHumanVsAI:
+		modeText = "Human vs AI"
+		if g.opponent != nil {
+			modeText = "Human vs " + g.opponentLabel()
+		}
+	}
+	if g.aiProvider != "" {
+		// Show which backend is playing, as a failover chain may switch
+		modeText += " — AI: " + g.aiProvider
+	}
+	return modeText
+}
+
+// inputLine renders the move input, the bookmark note being typed, or what
+// the AI is doing while it thinks
+func (g *Game) inputLine() string {
+	if g.bookmarkInput != nil {
+		return "Bookmark note (enter to save, esc to cancel): " + g.bookmarkInput.View()
+	}
+	if g.split != nil && g.split.focused {
+		return "Explore move (ctrl+w for the game): " + g.input.View()
+	}
+	if g.isAITurn {
+		icon := "🤖 "
+		if g.aiStatus.Queued() {
+			icon = "⏳ "
+		}
+		return icon + g.aiWaitingText()
+	}
+	return "Enter move (e.g., e4): " + g.input.View()
+}
+
+// helpText lists the game's keys
+func (g *Game) helpText() string {
+	help := "Commands: [q]uit, [r]eset, [H]elp, [p]alette, [n]otation, board [s]tyle, [z]en, [v]ariations, [|] analysis board, [P]ause, [o]ffer/accept draw, heat[m]aps, ctrl+b bookmark, [A]nnotate, square then [?] to explain its moves, ctrl+s save PGN, ctrl+g share PGN, ctrl+p screenshot, ctrl+a adjourn"
+	if g.consult != nil {
+		help += ", [t] ask the advisor"
+	} else if g.ai != nil {
+		help += ", [t]each me"
+	}
+	if g.chessGame.Outcome() != chess.NoOutcome {
+		help += ", [k]ey move quiz"
+	}
+	return help
+}
+
 // renderBoard renders the chess board
 func (g *Game) renderBoard() string {
+	if g.settings.LowBandwidth {
+		return g.renderCachedBoard()
+	}
+	return g.drawBoard()
+}
+
+// drawBoard draws the board in the chosen style
+func (g *Game) drawBoard() string {
+	switch g.settings.BoardStyle {
+	case BoardStyleLarge:
+		return g.renderLargeBoard()
+	case BoardStyleImage:
+		// Terminals without graphics get the text board
+		if protocol := g.graphics(); protocol != GraphicsNone {
+			return g.renderImageBoard(protocol)
+		}
+	default:
+		if board, ok := g.renderPluginBoard(); ok {
+			return board
+		}
+	}
+
 	board := g.chessGame.Position().Board()
 	var sb strings.Builder
 
 	// File labels (a-h)
 	sb.WriteString("   ")
-	for file := 0; file < 8; file++ {
+	for _, file := range g.boardFiles() {
 		sb.WriteString(fmt.Sprintf(" %c ", 'a'+file))
 	}
 	sb.WriteString("\n")
 
+	palette := paletteFor(g.settings.Palette)
+	marks := g.squareMarks()
+	colored := g.boardColored()
+
 	// Board squares
-	for rank := 7; rank >= 0; rank-- {
+	for _, rank := range g.boardRanks() {
 		// Rank label (1-8)
 		sb.WriteString(fmt.Sprintf(" %d ", rank+1))
 
-		for file := 0; file < 8; file++ {
+		for _, file := range g.boardFiles() {
 			square := chess.Square(rank*8 + file)
 			piece := board.Piece(square)
 
-			// Determine square color
-			isLight := (rank+file)%2 == 0
-			var bgColor string
-			if isLight {
-				bgColor = "#F0D9B5" // Light square
-			} else {
-				bgColor = "#B58863" // Dark square
-			}
-
-			// Determine piece color
-			var fgColor string
-			if piece.Color() == chess.White {
-				fgColor = "#FFFFFF"
-			} else {
-				fgColor = "#000000"
-			}
-
-			// Get piece symbol
-			symbol := g.getPieceSymbol(piece)
+			style, symbol := g.squareStyle(square, piece, palette, marks[square], colored)
 
 			// Style the square
-			style := lipgloss.NewStyle().
-				Background(lipgloss.Color(bgColor)).
-				Foreground(lipgloss.Color(fgColor)).
-				Bold(true).
-				Width(3).
-				Align(lipgloss.Center)
-
-			sb.WriteString(style.Render(symbol))
+			style = style.Width(3).Align(lipgloss.Center)
+			sb.WriteString(style.Render(marks[square].decorate(symbol)))
 		}
 
 		// Rank label (1-8)
@@ -236,13 +682,75 @@ func (g *Game) renderBoard() string {
 
 	// File labels (a-h)
 	sb.WriteString("   ")
-	for file := 0; file < 8; file++ {
+	for _, file := range g.boardFiles() {
 		sb.WriteString(fmt.Sprintf(" %c ", 'a'+file))
 	}
 
 	return sb.String()
 }
 
+// squareColor returns the background color of a board square
+func (g *Game) squareColor(square chess.Square, palette Palette, mark squareMark) string {
+	isLight := (int(square.Rank())+int(square.File()))%2 == 0
+	var bgColor string
+	if isLight {
+		bgColor = palette.LightSquare
+	} else {
+		bgColor = palette.DarkSquare
+	}
+
+	// Highlighted squares get their own background plus a symbol cue
+	switch mark {
+	case markLastMove:
+		bgColor = palette.LastMove
+	case markSelected, markTarget, markPattern:
+		bgColor = palette.Selected
+	case markCheck, markExploded:
+		bgColor = palette.Check
+	}
+	if mark == markNone || mark == markLastMove || mark == markAnnotated {
+		// Drawn annotations show over the last move
+		if color, ok := g.annotationColor(square); ok {
+			bgColor = color
+		}
+	}
+	if mark != markPattern && mark != markSelected && g.hinting() {
+		bgColor = dimColor(bgColor)
+	}
+	return bgColor
+}
+
+// squareStyle returns the colors and symbol for a board square
+func (g *Game) squareStyle(square chess.Square, piece chess.Piece, palette Palette, mark squareMark, colored bool) (lipgloss.Style, string) {
+	isLight := (int(square.Rank())+int(square.File()))%2 == 0
+	bgColor := g.squareColor(square, palette, mark)
+
+	// Determine piece color
+	var fgColor string
+	if piece.Color() == chess.White {
+		fgColor = palette.WhitePiece
+	} else {
+		fgColor = palette.BlackPiece
+	}
+
+	// Get piece symbol; without color, dark squares are dotted so
+	// the checkerboard stays readable
+	symbol := g.getPieceSymbol(piece)
+	if !colored && !isLight && piece == chess.NoPiece {
+		symbol = "·"
+	}
+
+	if !colored {
+		return lipgloss.NewStyle(), symbol
+	}
+	style := lipgloss.NewStyle().
+		Background(lipgloss.Color(bgColor)).
+		Foreground(lipgloss.Color(fgColor)).
+		Bold(true)
+
+	return style, symbol
+}
+
 // getPieceSymbol returns the Unicode symbol for a chess piece
 func (g *Game) getPieceSymbol(piece chess.Piece) string {
 	if piece == chess.NoPiece {
@@ -270,185 +778,422 @@ func (g *Game) getPieceSymbol(piece chess.Piece) string {
 	return "?"
 }
 
-// convertLongToShortNotation is no longer needed - we use long notation directly
-func (g *Game) convertLongToShortNotation(moveStr string) string {
-	return moveStr // No conversion needed - we use long notation directly
+// applyMove plays a move given in SAN or UCI on the game
+func (g *Game) applyMove(moveStr string) error {
+	move, err := notation.Decode(g.chessGame.Position(), moveStr)
+	if err != nil {
+		return err
+	}
+	if err := g.houseRules().Check(g.chessGame.Position(), move); err != nil {
+		return err
+	}
+	if err := g.chessGame.Move(move); err != nil {
+		return err
+	}
+	g.applyHouseRules()
+	g.clearExplanation()
+	return nil
 }
 
 // makeMove attempts to make a move
-func (g *Game) makeMove(moveStr string) tea.Cmd {
-	return func() tea.Msg {
-		slog.Debug("makeMove function started", "move", moveStr)
-
-		// Clear previous error
-		g.err = ""
-
-		// Try to make the move
-		err := g.chessGame.MoveStr(moveStr)
-		if err != nil {
-			slog.Debug("Move failed", "error", err)
-			g.err = err.Error()
-			return nil
+func (g *Game) makeMove(moveStr string) {
+	g.log.Debug("makeMove function started", "move", moveStr)
+
+	// Clear previous error
+	g.err = ""
+
+	// Complete a pending promotion with the chosen piece
+	if g.pendingPromotion != "" {
+		piece, ok := promotionPiece(moveStr)
+		if !ok {
+			g.err = "choose a promotion piece: Q, R, B or N"
+			g.input.SetValue("")
+			return
 		}
-		slog.Debug("Move successful", "current_turn", g.chessGame.Position().Turn())
+		moveStr = g.pendingPromotion + "=" + piece
+		g.pendingPromotion = ""
+	}
 
-		// Add move to history
-		g.gameHistory = append(g.gameHistory, moveStr)
-		slog.Debug("Move added to history", "history_length", len(g.gameHistory))
+	// In a networked game only the side to move may play
+	if g.spectating() {
+		g.err = "you are watching this game"
+		g.input.SetValue("")
+		return
+	}
+	if g.awaitingPeer() {
+		g.err = "wait for your opponent's move"
+		g.input.SetValue("")
+		return
+	}
+	if g.peer != nil && g.over() {
+		g.err = "the game is over"
+		g.input.SetValue("")
+		return
+	}
 
-		// Update status
-		g.updateStatus()
-		slog.Debug("Status updated", "new_status", g.status)
+	// Try to make the move
+	mover := g.chessGame.Position().Turn()
+	err := g.applyMove(moveStr)
+	if err != nil {
+		g.log.Debug("Move failed", "error", err)
+
+		// A pawn reaching the last rank without a piece asks for one
+		if g.needsPromotionPiece(moveStr) {
+			g.pendingPromotion = moveStr
+			g.input.SetValue("")
+			g.updateStatus()
+			return
+		}
 
-		// Clear input
-		g.input.SetValue("")
+		g.err = notation.Explain(err)
+		return
+	}
 
-		// If playing against AI and it's now AI's turn, get AI move
-		slog.Debug("Checking AI turn", "gameMode", g.gameMode, "turn", g.chessGame.Position().Turn())
-		if g.gameMode == ModeHumanVsAI {
-			// In Human vs AI mode, after the human makes a move, it's the AI's turn to respond
-			// The AI will play as the opposite color of the current turn
-			slog.Debug("AI turn detected, setting aiMovePending flag")
-			g.isAITurn = true
-			g.aiMovePending = true
-			g.status = "🤖 AI is thinking..."
-			slog.Debug("aiMovePending set to true")
-		} else {
-			slog.Debug("Not AI turn", "gameMode", g.gameMode, "turn", g.chessGame.Position().Turn())
-		}
-
-		slog.Debug("makeMove returning nil")
-		return nil
+	// Moving instead of accepting declines the opponent's draw offer
+	g.declineDrawOffer(mover)
+	if g.peer != nil {
+		moves := g.chessGame.Moves()
+		g.peer.Send(moves[len(moves)-1].String(), time.Since(g.lastMoveAt))
 	}
+	g.publishMove(false, "")
+	g.log.Debug("Move successful", "current_turn", g.chessGame.Position().Turn())
+
+	// Add move to history
+	g.gameHistory = append(g.gameHistory, moveStr)
+	g.log.Debug("Move added to history", "history_length", len(g.gameHistory))
+	g.clearCandidates()
+
+	// Update status
+	g.updateStatus()
+	g.log.Debug("Status updated", "new_status", g.status)
+	g.passKeyboard()
+
+	// Clear input
+	g.input.SetValue("")
+
+	// If playing against AI and it's now AI's turn, get AI move
+	g.log.Debug("Checking AI turn", "gameMode", g.gameMode, "turn", g.chessGame.Position().Turn())
+	if g.gameMode == ModeHumanVsAI {
+		// In Human vs AI mode, after the human makes a move, it's the AI's turn to respond
+		// The AI will play as the opposite color of the current turn
+		g.log.Debug("AI turn detected, setting aiMovePending flag")
+		g.isAITurn = true
+		g.aiMovePending = true
+		g.status = "🤖 AI is thinking..."
+		g.log.Debug("aiMovePending set to true")
+	} else {
+		g.log.Debug("Not AI turn", "gameMode", g.gameMode, "turn", g.chessGame.Position().Turn())
+	}
+
 }
 
 // resetGame resets the game to starting position
-func (g *Game) resetGame() tea.Cmd {
-	return func() tea.Msg {
-		g.chessGame = chess.NewGame(chess.UseNotation(chess.AlgebraicNotation{}))
-		g.status = "White's turn"
-		g.err = ""
-		g.input.SetValue("")
-		g.gameHistory = []string{}
-		g.isAITurn = false
-		g.aiMovePending = false
-		return nil
+func (g *Game) resetGame() {
+	// Abandon the AI's move in flight, which was for the old game
+	g.restartContext()
+	g.subscribeHooks()
+	g.readapt()
+	g.chessGame = g.newChessGame()
+	g.err = ""
+	g.drawOffer = chess.NoColor
+	g.pendingPromotion = ""
+	g.confirming = ""
+	g.clearExplanation()
+	g.input.SetValue("")
+	g.gameHistory = []string{}
+	g.newGameID()
+	g.isAITurn = false
+	g.aiMovePending = false
+	g.aiFailure = nil
+	g.aiReasoning = ""
+	g.commentary = ""
+	g.aiVotes = nil
+	g.confidence = nil
+	g.spareMoves = nil
+	g.tokenUsage = TokenUsage{}
+	g.aiProvider = ""
+	g.clearCandidates()
+	if g.consult != nil {
+		g.consult.spent = make(map[chess.Color]int)
 	}
+	g.variations = nil
+	g.bookmarks = nil
+	g.bookmarkInput = nil
+	g.annotations = nil
+	g.annotating = nil
+	g.picking = nil
+	g.startClock()
+	g.flagged = chess.NoColor
+	g.abandoned = chess.NoColor
+	g.aborted = ""
+	g.houseRule = ""
+	g.ponder = nil
+	g.ended = false
+	g.updateStatus()
+	g.startAITurnIfDue()
 }
 
-// showHelp shows help information
-func (g *Game) showHelp() tea.Cmd {
-	return func() tea.Msg {
-		g.status = "Help: Use algebraic notation (e.g., e4, Nf3, O-O)"
-		return nil
+// SetStartPosition starts the game, and every game after a reset, from the
+// position given as FEN. Call it after SetHumanColor; the AI moves first
+// when the position has it to move.
+func (g *Game) SetStartPosition(fen string) error {
+	if _, err := startingBoard(fen); err != nil {
+		return err
+	}
+	g.startFEN = fen
+	if g.aiClient != nil {
+		g.aiClient.SetStartFEN(fen)
+	}
+	g.resetGame()
+	return nil
+}
+
+// newChessGame returns a board at the game's start position
+func (g *Game) newChessGame() *chess.Game {
+	board, err := startingBoard(g.startFEN)
+	if err != nil {
+		board, _ = startingBoard("")
 	}
+	return board
+}
+
+// SetHumanColor picks the side the human plays against the AI. When the
+// human takes Black, the AI makes the first move.
+func (g *Game) SetHumanColor(color chess.Color) {
+	g.humanColor = color
+	g.startAITurnIfDue()
+}
+
+// startAITurnIfDue hands the move to the AI when it is the AI's side to move
+func (g *Game) startAITurnIfDue() {
+	if g.gameMode != ModeHumanVsAI || g.chessGame.Outcome() != chess.NoOutcome {
+		return
+	}
+	if g.chessGame.Position().Turn() != g.humanColor {
+		g.isAITurn = true
+		g.aiMovePending = true
+		g.status = "🤖 AI is thinking..."
+	}
+}
+
+// showHelp shows help information
+func (g *Game) showHelp() {
+	g.status = "Help: Use algebraic notation (e.g., e4, Nf3, O-O)"
 }
 
 // updateStatus updates the game status
 func (g *Game) updateStatus() {
-	if g.chessGame.Outcome() != chess.NoOutcome {
+	var parts []string
+
+	if g.aborted != "" {
+		parts = append(parts, "Game aborted: "+g.aborted)
+	} else if g.chessGame.Outcome() != chess.NoOutcome {
 		switch g.chessGame.Outcome() {
 		case chess.WhiteWon:
-			g.status = "White wins!"
+			parts = append(parts, "White wins "+g.endPhrase()+"!")
 		case chess.BlackWon:
-			g.status = "Black wins!"
+			parts = append(parts, "Black wins "+g.endPhrase()+"!")
 		case chess.Draw:
-			g.status = "Draw!"
+			parts = append(parts, "Draw by "+g.endMethod()+"!")
 		}
 	} else {
-		if g.chessGame.Position().Turn() == chess.White {
-			g.status = "White's turn"
-		} else {
-			g.status = "Black's turn"
+		turn := g.chessGame.Position().Turn()
+		parts = append(parts, turn.Name()+" to move")
+
+		if g.inCheck() {
+			parts = append(parts, turn.Name()+" is in check")
+		}
+		if g.drawOffer != chess.NoColor {
+			parts = append(parts, "Draw offer pending")
+		}
+		if g.pendingPromotion != "" {
+			parts = append(parts, "Promotion: choose piece (Q, R, B, N)")
 		}
 	}
-}
 
-// getAIMove gets a move from the AI
-func (g *Game) getAIMove() tea.Cmd {
-	return func() tea.Msg {
-		slog.Debug("getAIMove function called")
+	if last := g.lastMoveSAN(); last != "" {
+		parts = append(parts, "Last move: "+g.formatMove(last, g.chessGame.Position().Turn().Other()))
+	}
 
-		if g.aiClient == nil {
-			slog.Debug("AI client is nil")
-			g.err = "AI client not initialized"
-			return nil
-		}
+	g.status = strings.Join(parts, " — ")
+	g.publishEnd()
+}
 
-		slog.Debug("AI client found, getting board state")
-		// Get current board state
-		boardState := g.getBoardState()
+// aiMoveMsg carries the AI's answer to a move request
+type aiMoveMsg struct {
+	ctx     context.Context // the game's context when the move was requested
+	request aiMoveRequest
+	result  *AIMoveResult
+	err     error
+}
 
-		slog.Debug("Board state", "board", boardState)
-		slog.Debug("Game history", "history", g.gameHistory)
-		slog.Debug("Calling AI client GetAIMove")
+// aiMoveRequest describes one request for the AI's move
+type aiMoveRequest struct {
+	errorMsg string // why the AI's last move was rejected, for a retry
+	resynced bool   // the history has been rebuilt after a desync
+	replay   bool   // send the server the whole game before asking
+}
 
-		// Get AI move (this will block, but it's the only way to make it work)
-		playerColor := "white"
-		if g.chessGame.Position().Turn() == chess.Black {
-			playerColor = "black"
-		}
-		aiMove, err := g.aiClient.GetAIMove(boardState, g.gameHistory, playerColor)
-		if err != nil {
-			slog.Debug("AI error", "error", err)
-			g.err = "AI error: " + err.Error()
-			return nil
-		}
+// getAIMove asks the AI for its move. The request runs on a copy of the
+// position and history; the answer comes back to Update as an aiMoveMsg.
+func (g *Game) getAIMove() tea.Cmd {
+	return g.requestAIMove(aiMoveRequest{})
+}
 
-		slog.Debug("AI move received", "move", aiMove)
+// requestAIMove sends one move request to the AI
+func (g *Game) requestAIMove(request aiMoveRequest) tea.Cmd {
+	if g.ai == nil {
+		g.log.Debug("AI client is nil")
+		g.err = "AI client not initialized"
+		return nil
+	}
 
-		// Convert AI move from long to short notation if needed
-		convertedMove := g.convertLongToShortNotation(aiMove)
-		slog.Debug("Converted AI move", "original", aiMove, "converted", convertedMove)
+	// Report the server's queue position while the move is pending
+	g.aiStatus = AIStatus{}
+	var feed *aiStatusFeed
+	if g.aiClient != nil && g.ai == g.aiClient {
+		feed = newAIStatusFeed()
+		g.aiClient.SetStatusHandler(feed.send)
+	}
 
-		// Apply AI move
-		err = g.chessGame.MoveStr(convertedMove)
-		if err != nil {
-			slog.Debug("Invalid AI move error", "error", err)
-			g.err = "Invalid AI move: " + err.Error()
+	ai := g.ai
+	ctx := g.ctx
+	log := g.log
+	boardState := g.getBoardState()
+	history := append([]string(nil), g.gameHistory...)
+	playerColor := "white"
+	if g.chessGame.Position().Turn() == chess.Black {
+		playerColor = "black"
+	}
+	// Let the AI know whether the player's draw offer stands
+	if offers, ok := ai.(drawOfferReceiver); ok {
+		offers.SetDrawOffered(g.drawOffer == g.chessGame.Position().Turn().Other())
+	}
+	g.log.Debug("Requesting AI move", "board", boardState, "history", history, "error", request.errorMsg)
+	if request == (aiMoveRequest{}) {
+		g.bus.Publish(events.Event{Kind: events.AIThinkingStarted, Color: playerColor, FEN: boardState})
+	}
 
-			// Send error back to AI server and request a new move
-			slog.Debug("Sending error to AI server and requesting new move")
-			newMove, retryErr := g.retryAIMoveWithError(boardState, g.gameHistory, err.Error(), playerColor)
-			if retryErr != nil {
-				slog.Debug("Retry failed", "error", retryErr)
-				return nil
+	move := func() tea.Msg {
+		if feed != nil {
+			defer feed.close()
+		}
+		if client, ok := ai.(*AIClient); ok && request.replay {
+			// Rebuild the server's session from the whole game at once;
+			// servers without either method still get the history below
+			resumed, err := client.ResumeSession(history, boardState, playerColor)
+			if errors.Is(err, ErrMethodNotFound) {
+				err = client.ReplaySession(history, boardState, playerColor)
 			}
-
-			// Convert the retry move as well
-			convertedRetryMove := g.convertLongToShortNotation(newMove)
-			slog.Debug("Converted retry move", "original", newMove, "converted", convertedRetryMove)
-
-			// Try to apply the new move
-			err = g.chessGame.MoveStr(convertedRetryMove)
 			if err != nil {
-				slog.Debug("Second AI move also failed", "error", err)
-				g.err = "AI failed to make valid move after retry"
-				return nil
+				log.Debug("Failed to replay the game to the AI server", "error", err)
+			} else if resumed != nil {
+				log.Debug("AI server resumed the game", "ply", resumed.Ply, "model", resumed.Model)
 			}
+		}
+		result, err := ai.GetAIMoveResult(boardState, history, request.errorMsg, playerColor)
+		return aiMoveMsg{ctx: ctx, request: request, result: result, err: err}
+	}
+	if feed == nil {
+		return move
+	}
+	return tea.Batch(move, feed.wait())
+}
+
+// applyAIMove plays the AI's answer, asking again once when the server's
+// history has drifted or the move is illegal
+func (g *Game) applyAIMove(msg aiMoveMsg) tea.Cmd {
+	if msg.ctx != g.ctx {
+		// The game was reset or closed while the AI thought
+		g.log.Debug("Dropping AI move for an ended game", "error", msg.err)
+		return nil
+	}
+
+	var desync *DesyncError
+	if errors.As(msg.err, &desync) && !msg.request.resynced {
+		// The server replayed our history to another position; rebuild
+		// the history from the board and ask once more
+		g.log.Warn("AI server board desync, resyncing history", "error", desync)
+		g.publishAIFailure(events.AIError, "", msg.err)
+		g.resyncHistory()
+		return g.requestAIMove(aiMoveRequest{errorMsg: msg.request.errorMsg, resynced: true, replay: true})
+	}
+	if msg.err != nil {
+		g.log.Debug("AI error", "error", msg.err)
+		g.publishAIFailure(events.AIError, "", msg.err)
+		g.failAIMove(msg.err)
+		return nil
+	}
 
-			aiMove = newMove // Use the successful move
-		} else {
-			slog.Debug("✅ AI move applied successfully", "move", convertedMove, "position_after", g.chessGame.Position().String())
+	result := msg.result
+	if result.Action == ai_player.ActionResign || result.Action == ai_player.ActionAcceptDraw {
+		return g.applyAIDecision(result)
+	}
+	if err := g.applyMove(result.Move); err != nil {
+		g.log.Debug("Invalid AI move error", "error", err)
+		g.publishAIFailure(events.AIMoveRejected, result.Move, err)
+		if msg.request.errorMsg != "" {
+			g.log.Debug("Second AI move also failed", "error", err)
+			g.failAIMove(errkind.Errorf(errkind.IllegalMove, "AI failed to make valid move after retry: %w", err))
+			return nil
 		}
 
-		// Add AI move to history
-		g.gameHistory = append(g.gameHistory, aiMove)
-		slog.Debug("📝 AI move added to history", "history_length", len(g.gameHistory), "full_history", g.gameHistory)
+		// Send the error back to the AI and request a new move
+		g.err = "Invalid AI move: " + err.Error()
+		return g.requestAIMove(aiMoveRequest{errorMsg: err.Error(), resynced: msg.request.resynced})
+	}
+	g.aiFailure = nil
+	g.log.Debug("✅ AI move applied successfully", "move", result.Move, "position_after", g.chessGame.Position().String())
+
+	// Moving declines the player's draw offer; the AI may make its own
+	aiColor := g.chessGame.Position().Turn().Other()
+	g.declineDrawOffer(aiColor)
+	if result.Action == ai_player.ActionOfferDraw && g.chessGame.Outcome() == chess.NoOutcome {
+		g.log.Debug("AI offers a draw", "reason", result.ActionReason)
+		g.drawOffer = aiColor
+	}
 
-		// Update status and clear AI turn flags
-		g.updateStatus()
-		g.isAITurn = false
-		g.aiMovePending = false // Reset the pending flag
+	// Keep the AI's reasoning for the "why" panel and tally its tokens
+	g.aiReasoning = result.Reasoning
+	g.aiVotes = result.Votes
+	g.recordConfidence(result.Confidence)
+	g.recordSpare(result)
+	g.tokenUsage.Add(result)
+	if result.Provider != "" {
+		g.aiProvider = result.Provider
+	}
+	// Our own validator has the last word; a disagreement points at a desync
+	if result.Outcome != nil && result.Outcome.Result != g.chessGame.Outcome().String() {
+		g.log.Warn("AI server reported a different outcome", "move", result.Move,
+			"server_result", result.Outcome.Result, "server_reason", result.Outcome.Reason, "result", g.chessGame.Outcome().String())
+	}
+
+	g.gameHistory = append(g.gameHistory, result.Move)
+	g.log.Debug("📝 AI move added to history", "history_length", len(g.gameHistory), "full_history", g.gameHistory)
+	g.publishMove(true, result.Reasoning)
+
+	g.updateStatus()
+	g.isAITurn = false
+	g.aiMovePending = false
+	return g.startPonder()
+}
 
-		slog.Debug("🎉 AI move completed successfully",
-			"new_turn", g.chessGame.Position().Turn(),
-			"isAITurn", g.isAITurn,
-			"aiMovePending", g.aiMovePending,
-			"status", g.status,
-			"position_after", g.chessGame.Position().String())
-		return aiMoveCompletedMsg{}
+// takeAITurn requests the AI's move when one is due
+func (g *Game) takeAITurn() tea.Cmd {
+	if !g.aiMovePending {
+		return nil
 	}
+	g.aiMovePending = false
+	if cmd, ok := g.usePonder(); ok {
+		return cmd
+	}
+	return g.getAIMove()
+}
+
+// resyncHistory rebuilds the history sent to the AI from the moves actually
+// played on the board
+func (g *Game) resyncHistory() {
+	g.gameHistory = g.sanMoves()
 }
 
 // getBoardState returns the current board state as a string
@@ -457,14 +1202,6 @@ func (g *Game) getBoardState() string {
 	return g.chessGame.Position().String()
 }
 
-// retryAIMoveWithError sends the error back to the AI server and requests a new move
-func (g *Game) retryAIMoveWithError(boardState string, gameHistory []string, errorMsg string, playerColor string) (string, error) {
-	slog.Debug("Retrying AI move with error", "error", errorMsg)
-
-	// Use the AI client to make the retry request
-	return g.aiClient.GetAIMoveWithError(boardState, gameHistory, errorMsg, playerColor)
-}
-
 // Public methods for external access
 
 // AIClient returns the AI client instance
@@ -472,6 +1209,15 @@ func (g *Game) AIClient() *AIClient {
 	return g.aiClient
 }
 
+// SetMoveGenerator replaces the A2A client as the source of AI moves
+func (g *Game) SetMoveGenerator(generator MoveGenerator) {
+	g.ai = generator
+	g.ai.SetPersonality(g.settings.Personality)
+	g.bindBullet()
+	g.bindContext()
+	g.bindSession()
+}
+
 // GetBoardState returns the current board state as a string (public version)
 func (g *Game) GetBoardState() string {
 	return g.getBoardState()
@@ -492,11 +1238,8 @@ func (g *Game) GetCurrentTurn() string {
 
 // MakeMove makes a move and returns an error if it fails
 func (g *Game) MakeMove(moveStr string) error {
-	// Convert long notation to short if needed
-	convertedMove := g.convertLongToShortNotation(moveStr)
-
 	// Try to make the move
-	err := g.chessGame.MoveStr(convertedMove)
+	err := g.applyMove(moveStr)
 	if err != nil {
 		return fmt.Errorf("invalid move: %w", err)
 	}
@@ -509,3 +1252,15 @@ func (g *Game) MakeMove(moveStr string) error {
 
 	return nil
 }
+
+// CrashState describes the game for crash reports
+func (g *Game) CrashState() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Mode: %d\n", g.gameMode))
+	sb.WriteString(fmt.Sprintf("FEN: %s\n", g.getBoardState()))
+	sb.WriteString(fmt.Sprintf("History: %s\n", strings.Join(g.gameHistory, " ")))
+	sb.WriteString(fmt.Sprintf("Status: %s\n", g.status))
+	sb.WriteString(fmt.Sprintf("Error: %s\n", g.err))
+	sb.WriteString(fmt.Sprintf("AI turn: %t, AI move pending: %t\n", g.isAITurn, g.aiMovePending))
+	return sb.String()
+}
@@ -1,8 +1,14 @@
 package game
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
+	"chess-tui/positions"
+
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/notnil/chess"
 )
 
@@ -11,8 +17,8 @@ func TestNewGame(t *testing.T) {
 	if g.chessGame == nil {
 		t.Error("Expected chess game to be initialized")
 	}
-	if g.status != "White's turn" {
-		t.Errorf("Expected status 'White's turn', got '%s'", g.status)
+	if g.status != "White to move" {
+		t.Errorf("Expected status 'White to move', got '%s'", g.status)
 	}
 }
 
@@ -46,8 +52,8 @@ func TestUpdateStatus(t *testing.T) {
 
 	// Test initial status
 	g.updateStatus()
-	if g.status != "White's turn" {
-		t.Errorf("Expected status 'White's turn', got '%s'", g.status)
+	if g.status != "White to move" {
+		t.Errorf("Expected status 'White to move', got '%s'", g.status)
 	}
 
 	// Test after a move
@@ -56,11 +62,180 @@ func TestUpdateStatus(t *testing.T) {
 		t.Fatalf("Failed to make move: %v", err)
 	}
 	g.updateStatus()
-	if g.status != "Black's turn" {
-		t.Errorf("Expected status 'Black's turn', got '%s'", g.status)
+	if g.status != "Black to move — Last move: e4" {
+		t.Errorf("Expected status 'Black to move — Last move: e4', got '%s'", g.status)
+	}
+
+	// Test check indicator
+	for _, move := range []string{"f5", "Qh5+"} {
+		if err := g.chessGame.MoveStr(move); err != nil {
+			t.Fatalf("Failed to make move: %v", err)
+		}
+	}
+	g.updateStatus()
+	if g.status != "Black to move — Black is in check — Last move: Qh5+" {
+		t.Errorf("Expected check status, got '%s'", g.status)
+	}
+}
+
+func TestPromotionPrompt(t *testing.T) {
+	g := NewGame()
+	g.chessGame = newGameFromFEN(t, "8/4P3/8/8/8/8/k7/4K3 w - - 0 1")
+
+	g.makeMove("e8")
+	if g.pendingPromotion != "e8" {
+		t.Fatalf("Expected pending promotion for 'e8', got '%s' (err: %s)", g.pendingPromotion, g.err)
+	}
+	if g.status != "White to move — Promotion: choose piece (Q, R, B, N)" {
+		t.Errorf("Expected promotion prompt, got '%s'", g.status)
+	}
+
+	g.makeMove("n")
+	if g.pendingPromotion != "" || g.err != "" {
+		t.Fatalf("Expected promotion to complete, got pending '%s', err '%s'", g.pendingPromotion, g.err)
+	}
+	if g.lastMoveSAN() != "e8=N" {
+		t.Errorf("Expected last move 'e8=N', got '%s'", g.lastMoveSAN())
+	}
+}
+
+func TestTypedPromotionsAreNotShortcuts(t *testing.T) {
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	for _, tt := range []struct{ typed, expected string }{
+		{"e7e8q", "e8=Q"},
+		{"e7e8r", "e8=R"},
+		{"e7e8n", "e8=N"},
+	} {
+		g := NewGame()
+		g.chessGame = newGameFromFEN(t, "8/4P3/8/8/8/8/k7/4K3 w - - 0 1")
+		typeText(g, tt.typed)
+		if _, cmd := g.Update(enter); cmd != nil && isQuit(cmd) {
+			t.Fatalf("Expected %s not to quit", tt.typed)
+		}
+		if g.lastMoveSAN() != tt.expected || g.settings.Figurine {
+			t.Errorf("Expected %s to play %s, got %q (err %q)", tt.typed, tt.expected, g.lastMoveSAN(), g.err)
+		}
+	}
+
+	// The lowercase piece chosen at the promotion prompt is played too
+	g := NewGame()
+	g.chessGame = newGameFromFEN(t, "8/4P3/8/8/8/8/k7/4K3 w - - 0 1")
+	typeText(g, "e8")
+	g.Update(enter)
+	if g.pendingPromotion == "" {
+		t.Fatal("Expected a prompt for the promotion piece")
+	}
+	typeText(g, "q")
+	g.Update(enter)
+	if g.lastMoveSAN() != "e8=Q" {
+		t.Errorf("Expected q at the prompt to play e8=Q, got %q (err %q)", g.lastMoveSAN(), g.err)
+	}
+}
+
+// isQuit reports whether cmd quits the program
+func isQuit(cmd tea.Cmd) bool {
+	_, ok := cmd().(tea.QuitMsg)
+	return ok
+}
+
+func TestDrawOffer(t *testing.T) {
+	g := NewGame()
+
+	g.offerDraw()
+	if g.status != "White to move — Draw offer pending" {
+		t.Errorf("Expected draw offer pending, got '%s'", g.status)
+	}
+
+	// White's own move keeps the offer open for Black
+	g.makeMove("e4")
+	if g.drawOffer != chess.White {
+		t.Fatalf("Expected White's draw offer to stay open")
+	}
+
+	// Black accepts
+	g.offerDraw()
+	if g.chessGame.Outcome() != chess.Draw {
+		t.Errorf("Expected game drawn by agreement, got %v", g.chessGame.Outcome())
+	}
+}
+
+func TestStartPosition(t *testing.T) {
+	lucena := positions.MustGet("lucena")
+	g := NewGameWithMode(ModeHumanVsAI)
+	g.SetHumanColor(chess.Black)
+	if err := g.SetStartPosition(lucena.FEN); err != nil {
+		t.Fatalf("Expected the Lucena position to be accepted, got %v", err)
+	}
+	if g.getBoardState() != lucena.FEN {
+		t.Errorf("Expected the board at %s, got %s", lucena.FEN, g.getBoardState())
+	}
+	if !g.isAITurn {
+		t.Errorf("Expected the AI, playing White, to move first")
+	}
+
+	// The server is told where the history starts
+	var req ChessRequest
+	json.Unmarshal([]byte(g.aiClient.buildRequestText(g.getBoardState(), nil, "", "white")), &req)
+	if req.StartFEN != lucena.FEN {
+		t.Errorf("Expected start_fen %s, got %q", lucena.FEN, req.StartFEN)
+	}
+
+	// Resetting goes back to the position, and the PGN says where it started
+	g.applyMove("Rd1+")
+	g.resetGame()
+	if g.getBoardState() != lucena.FEN {
+		t.Errorf("Expected a reset to return to the start position, got %s", g.getBoardState())
+	}
+	if pgn := g.PGN(); !strings.Contains(pgn, `[FEN "`+lucena.FEN+`"]`) || !strings.Contains(pgn, `[SetUp "1"]`) {
+		t.Errorf("Expected SetUp and FEN tags, got:\n%s", pgn)
+	}
+
+	if err := g.SetStartPosition("not a position"); err == nil {
+		t.Errorf("Expected an invalid FEN to be refused")
+	}
+}
+
+func TestIllegalMoveExplained(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsHuman)
+	g.makeMove("Bc4")
+	if g.err != "the bishop on f1 is blocked by the pawn on e2" {
+		t.Errorf("Expected the blocked bishop explained, got %q", g.err)
+	}
+	g.makeMove("e7e5")
+	if !strings.Contains(g.err, "it's White to move") {
+		t.Errorf("Expected the wrong turn explained, got %q", g.err)
+	}
+}
+
+func TestScrambleMode(t *testing.T) {
+	const scrambled = "r1bqkb1r/pppp1ppp/2n2n2/4p3/2B1P3/5N2/PPPP1PPP/RNBQK2R w KQkq - 4 4"
+	menu := NewMenu()
+	menu.SetScrambler(func() (string, error) { return scrambled, nil })
+	menu.cursor = 5
+	model, _ := menu.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	g, ok := model.(*Game)
+	if !ok {
+		t.Fatalf("Expected a game, got %T", model)
+	}
+	if g.gameMode != ModeHumanVsAI || g.getBoardState() != scrambled {
+		t.Errorf("Expected a Human vs AI game from the scrambled position, got %s", g.getBoardState())
+	}
+
+	menu.SetScrambler(func() (string, error) { return "", positions.ErrNoScramble })
+	if model, _ := menu.Update(tea.KeyMsg{Type: tea.KeyEnter}); model != menu || !strings.Contains(menu.View(), "no balanced position") {
+		t.Errorf("Expected the menu to report the failure, got %T", model)
 	}
 }
 
+func newGameFromFEN(t *testing.T, fen string) *chess.Game {
+	t.Helper()
+	fenOption, err := chess.FEN(fen)
+	if err != nil {
+		t.Fatalf("Invalid FEN: %v", err)
+	}
+	return chess.NewGame(fenOption, chess.UseNotation(chess.AlgebraicNotation{}))
+}
+
 func TestMoveNotationHandling(t *testing.T) {
 	g := NewGame()
 
@@ -128,3 +303,99 @@ func TestNotationRequirements(t *testing.T) {
 		t.Errorf("Expected position %s, got %s", expectedFEN, g.chessGame.Position().String())
 	}
 }
+
+// TestAIMoveLeavesStateToUpdate renders the game while the AI's move is
+// requested, as the program does; run with -race to catch a command that
+// touches the game's state
+func TestAIMoveLeavesStateToUpdate(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	g.SetMoveGenerator(&historyCheckingGenerator{want: []string{"e4"}})
+	g.makeMove("e4")
+
+	cmd := g.takeAITurn()
+	if cmd == nil {
+		t.Fatal("Expected a command requesting the AI's move")
+	}
+	done := make(chan tea.Msg)
+	go func() { done <- cmd() }()
+
+	var msg tea.Msg
+	for msg == nil {
+		select {
+		case msg = <-done:
+		default:
+			g.View()
+		}
+	}
+	if moves := len(g.chessGame.Moves()); moves != 1 {
+		t.Errorf("Expected the command not to play the move itself, got %d moves", moves)
+	}
+
+	g.Update(msg)
+	if moves := len(g.chessGame.Moves()); moves != 2 {
+		t.Errorf("Expected Update to play the AI's move, got %d moves", moves)
+	}
+	if g.isAITurn {
+		t.Error("Expected the human to move next")
+	}
+}
+
+// midgameMoves reach a middlegame position with most pieces still on, for
+// the render benchmarks
+var midgameMoves = []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "a6", "Ba4", "Nf6", "O-O", "Be7"}
+
+// BenchmarkRenderBoard measures drawing the board in each style, the work
+// of every frame the render loop draws
+func BenchmarkRenderBoard(b *testing.B) {
+	for _, settings := range []struct {
+		name     string
+		settings Settings
+	}{
+		{"standard", Settings{BoardStyle: BoardStyleStandard}},
+		{"large", Settings{BoardStyle: BoardStyleLarge}},
+		{"low-bandwidth", Settings{BoardStyle: BoardStyleStandard, LowBandwidth: true}},
+	} {
+		b.Run(settings.name, func(b *testing.B) {
+			g := NewGameWithSettings(ModeHumanVsHuman, &settings.settings)
+			for _, move := range midgameMoves {
+				if err := g.MakeMove(move); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				g.renderBoard()
+			}
+		})
+	}
+}
+
+// BenchmarkView measures a whole frame: board, move list and panels
+func BenchmarkView(b *testing.B) {
+	g := NewGameWithSettings(ModeHumanVsHuman, &Settings{})
+	g.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	for _, move := range midgameMoves {
+		if err := g.MakeMove(move); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.View()
+	}
+}
+
+func TestPGNDatesTheGameFromItsStart(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsHuman)
+	g.startedAt = time.Date(2026, time.March, 7, 23, 59, 0, 0, time.Local)
+	g.makeMove("e4")
+
+	pgn := g.PGN()
+	for _, want := range []string{"[Date \"2026.03.07\"]\n[Round \"-\"]\n[White ", "[Site \"bubblechess\"]\n[Date "} {
+		if !strings.Contains(pgn, want) {
+			t.Errorf("Expected %q in the PGN, got:\n%s", want, pgn)
+		}
+	}
+}
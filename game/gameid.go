@@ -0,0 +1,39 @@
+package game
+
+import (
+	"log/slog"
+
+	"chess-tui/events"
+)
+
+// sessionSetter is implemented by AI backends that group a game's requests
+// into one session, such as the A2A client
+type sessionSetter interface {
+	SetSession(id string)
+}
+
+// newGameID gives the game a new ID, for when it starts: its events, log
+// lines, AI requests and saved records from now on carry it
+func (g *Game) newGameID() {
+	g.setGameID(events.NewGameID())
+}
+
+// setGameID gives the game the ID, such as when an adjourned game resumes
+func (g *Game) setGameID(id string) {
+	g.id = id
+	g.bus.SetGameID(id)
+	g.log = slog.Default().With("game_id", id)
+	g.bindSession()
+}
+
+// bindSession has the AI group its requests under the game's ID
+func (g *Game) bindSession() {
+	if setter, ok := g.ai.(sessionSetter); ok {
+		setter.SetSession(g.id)
+	}
+}
+
+// ID returns the ID of the game, which changes when it is reset
+func (g *Game) ID() string {
+	return g.id
+}
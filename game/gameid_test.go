@@ -0,0 +1,36 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"chess-tui/events"
+)
+
+func TestGameIDThreadsThroughGame(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	client := NewAIClient("http://localhost:1")
+	g.SetMoveGenerator(client)
+	first := g.ID()
+	if len(first) != 36 {
+		t.Fatalf("Expected a UUID, got %q", first)
+	}
+	if client.ContextID() != first {
+		t.Errorf("Expected the AI session %q, got %q", first, client.ContextID())
+	}
+
+	var got events.Event
+	g.Events().Subscribe(func(e events.Event) { got = e }, events.MoveMade)
+	g.makeMove("e4")
+	if got.GameID != first {
+		t.Errorf("Expected the event to carry %q, got %q", first, got.GameID)
+	}
+	if want := `[GameId "` + first + `"]`; !strings.Contains(g.PGN(), want) {
+		t.Errorf("Expected the PGN to contain %s, got:\n%s", want, g.PGN())
+	}
+
+	g.resetGame()
+	if g.ID() == first || client.ContextID() != g.ID() {
+		t.Errorf("Expected a new ID shared with the AI after a reset, got %q and %q", g.ID(), client.ContextID())
+	}
+}
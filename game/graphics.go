@@ -0,0 +1,63 @@
+package game
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// Terminal graphics protocols the image board can be drawn with
+const (
+	GraphicsNone  = "none"
+	GraphicsKitty = "kitty" // the kitty graphics protocol
+	GraphicsSixel = "sixel"
+)
+
+// graphicsEnv overrides detection with one of the protocols
+const graphicsEnv = "BUBBLECHESS_GRAPHICS"
+
+var (
+	graphicsOnce     sync.Once
+	graphicsDetected string
+)
+
+// graphicsProtocol returns the graphics protocol of the terminal the TUI
+// runs in, detected once
+func graphicsProtocol() string {
+	graphicsOnce.Do(func() {
+		graphicsDetected = detectGraphics(os.Getenv)
+	})
+	return graphicsDetected
+}
+
+// detectGraphics tells from the environment which graphics protocol the
+// terminal speaks. Multiplexers pass neither through reliably, so inside
+// tmux or screen only the override turns graphics on.
+func detectGraphics(getenv func(string) string) string {
+	switch override := strings.ToLower(getenv(graphicsEnv)); override {
+	case GraphicsKitty, GraphicsSixel, GraphicsNone:
+		return override
+	}
+
+	term := getenv("TERM")
+	if getenv("TMUX") != "" || strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux") {
+		return GraphicsNone
+	}
+
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty", term == "xterm-ghostty":
+		return GraphicsKitty
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "WezTerm", "ghostty":
+		return GraphicsKitty
+	case "iTerm.app", "mlterm", "contour":
+		return GraphicsSixel
+	}
+	switch {
+	case strings.Contains(term, "sixel"), term == "foot", strings.HasPrefix(term, "foot-"),
+		term == "mlterm", term == "contour", strings.HasPrefix(term, "yaft"):
+		return GraphicsSixel
+	}
+	return GraphicsNone
+}
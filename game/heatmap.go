@@ -0,0 +1,83 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"chess-tui/gamedb"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// heatColors shade squares from cold to hot; the first is for squares with no events
+var heatColors = []string{"#1E1E1E", "#4A1486", "#B63679", "#FB8861", "#FCFDBF"}
+
+// heatShades stand in for heatColors when the terminal has no color
+var heatShades = []string{"··", "░░", "▒▒", "▓▓", "██"}
+
+// heatLevel maps a count to an index into heatColors, relative to the hottest square
+func heatLevel(count, most int) int {
+	if count <= 0 || most <= 0 {
+		return 0
+	}
+	levels := len(heatColors) - 1
+	return 1 + (count*levels-1)/most
+}
+
+// renderHeatmap draws a heatmap on the board grid, ranks and files in the
+// given drawing order
+func renderHeatmap(kind gamedb.HeatmapKind, heatmap gamedb.Heatmap, ranks, files []int) string {
+	var sb strings.Builder
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00AAFF")).Render("Heatmap: "+kind.String()) + "\n")
+
+	most := heatmap.Max()
+	color := colorEnabled()
+	for _, rank := range ranks {
+		sb.WriteString(fmt.Sprintf("%d ", rank+1))
+		for _, file := range files {
+			level := heatLevel(heatmap[chess.NewSquare(chess.File(file), chess.Rank(rank))], most)
+			if color {
+				sb.WriteString(lipgloss.NewStyle().Background(lipgloss.Color(heatColors[level])).Render("  "))
+			} else {
+				sb.WriteString(heatShades[level])
+			}
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("  ")
+	for _, file := range files {
+		sb.WriteString(string(rune('a'+file)) + " ")
+	}
+	sb.WriteString("\n")
+
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	if square, ok := heatmap.Hottest(); ok {
+		sb.WriteString(dim.Render(fmt.Sprintf("Hottest: %s (%d)", square, heatmap[square])))
+	} else {
+		sb.WriteString(dim.Render("Nothing to show yet"))
+	}
+	return sb.String()
+}
+
+// nextHeatmap cycles a heatmap view through each kind and then off
+func nextHeatmap(show *bool, kind *gamedb.HeatmapKind) {
+	switch {
+	case !*show:
+		*show = true
+		*kind = gamedb.HeatmapKinds[0]
+	case int(*kind) == len(gamedb.HeatmapKinds)-1:
+		*show = false
+	default:
+		*kind++
+	}
+}
+
+// renderHeatmapPanel draws the heatmap of the moves played so far, if shown
+func (g *Game) renderHeatmapPanel() string {
+	if !g.showHeatmap {
+		return ""
+	}
+	heatmap := gamedb.BuildHeatmap(g.heatmapKind, g.sanMoves())
+	return lipgloss.NewStyle().MarginLeft(2).Render(renderHeatmap(g.heatmapKind, heatmap, g.boardRanks(), g.boardFiles()))
+}
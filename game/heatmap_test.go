@@ -0,0 +1,56 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"chess-tui/gamedb"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHeatLevel(t *testing.T) {
+	tests := []struct {
+		count, most, want int
+	}{
+		{0, 10, 0},
+		{1, 10, 1},
+		{5, 10, 2},
+		{10, 10, 4},
+		{3, 0, 0},
+	}
+	for _, test := range tests {
+		if got := heatLevel(test.count, test.most); got != test.want {
+			t.Errorf("Expected level %d for %d of %d, got %d", test.want, test.count, test.most, got)
+		}
+	}
+}
+
+func TestHeatmapKeyCycles(t *testing.T) {
+	g := NewGame()
+	g.makeMove("e4")
+
+	var titles []string
+	for range len(gamedb.HeatmapKinds) + 1 {
+		g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+		panel := g.renderHeatmapPanel()
+		if panel == "" {
+			titles = append(titles, "off")
+			continue
+		}
+		titles = append(titles, strings.SplitN(panel, "\n", 2)[0])
+	}
+
+	if !strings.Contains(titles[0], "Move destinations") || !strings.Contains(titles[1], "Captures") ||
+		!strings.Contains(titles[2], "King positions") || titles[3] != "off" {
+		t.Errorf("Expected m to cycle through each heatmap and off, got %q", titles)
+	}
+}
+
+func TestRenderHeatmap(t *testing.T) {
+	heatmap := gamedb.BuildHeatmap(gamedb.Destinations, []string{"e4", "e5", "Nf3"})
+	view := renderHeatmap(gamedb.Destinations, heatmap, []int{7, 6, 5, 4, 3, 2, 1, 0}, []int{0, 1, 2, 3, 4, 5, 6, 7})
+	if !strings.Contains(view, "a b c d e f g h") || !strings.Contains(view, "Hottest: ") {
+		t.Errorf("Expected a labelled grid with the hottest square, got:\n%s", view)
+	}
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// flipped reports whether the board is drawn from Black's side: in
+// networked games when playing Black, and in hot-seat games with auto-flip
+// on whenever Black is to move
+func (g *Game) flipped() bool {
+	if g.peer != nil {
+		return g.humanColor == chess.Black
+	}
+	return g.gameMode == ModeHumanVsHuman && g.settings.AutoFlip &&
+		g.chessGame.Position().Turn() == chess.Black
+}
+
+// boardRanks returns the ranks in drawing order, top to bottom
+func (g *Game) boardRanks() []int {
+	if g.flipped() {
+		return []int{0, 1, 2, 3, 4, 5, 6, 7}
+	}
+	return []int{7, 6, 5, 4, 3, 2, 1, 0}
+}
+
+// boardFiles returns the files in drawing order, left to right
+func (g *Game) boardFiles() []int {
+	if g.flipped() {
+		return []int{7, 6, 5, 4, 3, 2, 1, 0}
+	}
+	return []int{0, 1, 2, 3, 4, 5, 6, 7}
+}
+
+// passKeyboard hides the board until the next player presses a key, when
+// the privacy screen is on in a hot-seat game that's still going
+func (g *Game) passKeyboard() {
+	if g.gameMode == ModeHumanVsHuman && g.peer == nil && g.settings.PrivacyScreen && g.chessGame.Outcome() == chess.NoOutcome {
+		g.handoff = true
+	}
+}
+
+// privacyView is shown between hot-seat turns so neither player sees the
+// board from the other's side
+func (g *Game) privacyView() string {
+	var sb strings.Builder
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700")).Render("♔ Chess TUI ♛")
+	sb.WriteString(title + "\n\n")
+
+	next := g.chessGame.Position().Turn().Name()
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00AAFF")).
+		Render("Pass the keyboard to "+next+" — press any key") + "\n\n")
+	if last := g.lastMoveSAN(); last != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render("Last move: "+last) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,62 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAutoFlipDrawsBoardForSideToMove(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	g := NewGame()
+	g.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	if !g.settings.AutoFlip {
+		t.Fatal("Expected ctrl+f to turn auto-flip on")
+	}
+
+	firstLine := func() string { return strings.SplitN(g.renderBoard(), "\n", 2)[0] }
+	if got := strings.Fields(firstLine()); got[0] != "a" {
+		t.Errorf("Expected White's view with the a-file first, got %v", got)
+	}
+
+	g.makeMove("e4")
+	if got := strings.Fields(firstLine()); got[0] != "h" {
+		t.Errorf("Expected Black's view with the h-file first, got %v", got)
+	}
+	rows := strings.Split(g.renderBoard(), "\n")
+	if !strings.HasPrefix(strings.TrimSpace(rows[1]), "1") {
+		t.Errorf("Expected rank 1 at the top for Black, got %q", rows[1])
+	}
+}
+
+func TestAutoFlipOnlyInHotSeat(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	g.settings.AutoFlip = true
+	g.chessGame.Move(g.chessGame.ValidMoves()[0])
+	if g.flipped() {
+		t.Error("Expected the board not to flip in Human vs AI games")
+	}
+}
+
+func TestPrivacyScreenBetweenTurns(t *testing.T) {
+	g := NewGame()
+	g.settings.PrivacyScreen = true
+
+	g.makeMove("e4")
+	view := g.View()
+	if !strings.Contains(view, "Pass the keyboard to Black") {
+		t.Errorf("Expected the privacy screen after a move, got %q", view)
+	}
+	if strings.Contains(view, "♟") || strings.Contains(view, "♙") {
+		t.Error("Expected the board to be hidden")
+	}
+
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if g.handoff {
+		t.Error("Expected any key to lift the privacy screen")
+	}
+	if g.input.Value() != "" {
+		t.Errorf("Expected the key lifting the screen not to be typed, got %q", g.input.Value())
+	}
+}
@@ -0,0 +1,46 @@
+package game
+
+import (
+	"chess-tui/rules"
+
+	"github.com/notnil/chess"
+)
+
+// houseRules returns the rules the game is played under from the settings.
+// Networked games play standard chess, as the two sides' settings may differ.
+func (g *Game) houseRules() rules.Rules {
+	if g.peer != nil || g.settings == nil || g.settings.Rules == nil {
+		return rules.Rules{}
+	}
+	return *g.settings.Rules
+}
+
+// applyHouseRules ends the game if the house rules decide it after the move
+// just played
+func (g *Game) applyHouseRules() {
+	if g.chessGame.Outcome() != chess.NoOutcome {
+		return
+	}
+	outcome, why := g.houseRules().Verdict(g.chessGame.Position(), len(g.chessGame.Moves()))
+	switch outcome {
+	case chess.NoOutcome:
+		return
+	case chess.WhiteWon:
+		g.chessGame.Resign(chess.Black)
+	case chess.BlackWon:
+		g.chessGame.Resign(chess.White)
+	case chess.Draw:
+		g.chessGame.Draw(chess.DrawOffer)
+	}
+	g.log.Info("Game decided by house rules", "outcome", outcome, "rule", why)
+	g.houseRule = why
+}
+
+// houseRulesText describes the house rules for the status area, or "" for
+// standard chess
+func (g *Game) houseRulesText() string {
+	if rules := g.houseRules(); rules.Active() {
+		return "House rules: " + rules.String()
+	}
+	return ""
+}
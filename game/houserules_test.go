@@ -0,0 +1,35 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"chess-tui/rules"
+
+	"github.com/notnil/chess"
+)
+
+func TestHouseRules(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Rules = &rules.Rules{MustCapture: true, MaxMoves: 2}
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+	if !strings.Contains(g.View(), "House rules: captures are compulsory, the game ends after move 2") {
+		t.Errorf("Expected the house rules on screen, got:\n%s", g.View())
+	}
+
+	g.makeMove("e4")
+	g.makeMove("d5")
+	g.makeMove("Nf3")
+	if !strings.Contains(g.err, "captures are compulsory") {
+		t.Errorf("Expected Nf3 refused while exd5 is possible, got %q", g.err)
+	}
+	g.makeMove("exd5")
+	g.makeMove("Qxd5")
+
+	if g.chessGame.Outcome() != chess.Draw || !strings.HasPrefix(g.status, "Draw by the 2-move limit!") {
+		t.Errorf("Expected a draw at the move limit, got %s (%s)", g.chessGame.Outcome(), g.status)
+	}
+	if pgn := g.PGN(); !strings.Contains(pgn, `[Termination "adjudication"]`) {
+		t.Errorf("Expected an adjudication termination, got:\n%s", pgn)
+	}
+}
@@ -0,0 +1,62 @@
+package game
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// requestKey returns the idempotency key of an A2A request body
+func requestKey(t *testing.T, body []byte) string {
+	t.Helper()
+	var request struct {
+		Params struct {
+			Message struct {
+				Parts []TextPart `json:"parts"`
+			} `json:"message"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil || len(request.Params.Message.Parts) == 0 {
+		t.Fatalf("Bad request body %s: %v", body, err)
+	}
+	var chessReq ChessRequest
+	if err := json.Unmarshal([]byte(request.Params.Message.Parts[0].Text), &chessReq); err != nil {
+		t.Fatalf("Bad chess request: %v", err)
+	}
+	return chessReq.IdempotencyKey
+}
+
+func TestAIClientRetriesWithSameKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		keys = append(keys, requestKey(t, body))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"parts":[{"kind":"text","text":"Generated move: e5"}]}}`))
+	}))
+	defer server.Close()
+
+	client := NewAIClient(server.URL)
+	result, err := client.GetAIMoveResult("fen", []string{"e4"}, "", "black")
+	if err != nil || result.Move != "e5" {
+		t.Fatalf("Expected e5 after a retry, got %v, %v", result, err)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("Expected two requests with the same key, got %q", keys)
+	}
+	if want := client.ContextID() + ":1"; keys[0] != want {
+		t.Errorf("Expected key %s, got %s", want, keys[0])
+	}
+
+	// Asking again after a rejected move is a new request
+	keys = nil
+	client.GetAIMoveResult("fen", []string{"e4"}, "illegal move", "black")
+	if len(keys) == 0 || keys[len(keys)-1] != "" {
+		t.Errorf("Expected no key when retrying a rejected move, got %q", keys)
+	}
+}
@@ -0,0 +1,391 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"chess-tui/ai_player"
+	"chess-tui/errkind"
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// inlineHelp lists the commands of the line-based mode
+const inlineHelp = "Type a move (e4, Nf3, O-O, e2e4) or one of: board, fen, pgn, moves, undo, resign, help, quit"
+
+// inlineCommands are the words the line-based mode reads as commands
+// rather than moves
+var inlineCommands = []string{"board", "fen", "pgn", "moves", "undo", "resign", "help", "quit", "exit"}
+
+// Kinds of InlineEvent
+const (
+	InlineBoard  = "board"   // the position after a move, or on request
+	InlineMove   = "move"    // a move read from the input was played
+	InlineAIMove = "ai_move" // the AI played a move
+	InlineResign = "resign"  // the AI resigned
+	InlineError  = "error"   // a move or command was refused
+	InlineFEN    = "fen"
+	InlinePGN    = "pgn"
+	InlineMoves  = "moves" // the legal moves
+	InlineHelp   = "help"
+)
+
+// InlineEvent is one line of output of the JSON protocol. Fields that
+// don't apply to the kind of event are left out.
+type InlineEvent struct {
+	Type string `json:"type"`
+
+	// Board events
+	FEN    string   `json:"fen,omitempty"`
+	Board  []string `json:"board,omitempty"` // ranks 8 to 1, white in capitals and "." for empty squares
+	Turn   string   `json:"turn,omitempty"`  // "white" or "black", while the game goes on
+	Check  bool     `json:"check,omitempty"`
+	Status string   `json:"status,omitempty"` // as the text mode's status line
+	Result string   `json:"result,omitempty"` // "1-0", "0-1" or "1/2-1/2" once the game is over
+	Method string   `json:"method,omitempty"` // how the game ended, e.g. "checkmate"
+
+	// Move, ai_move and resign events
+	Color      string   `json:"color,omitempty"`
+	Move       string   `json:"move,omitempty"` // in SAN
+	UCI        string   `json:"uci,omitempty"`
+	Reasoning  string   `json:"reasoning,omitempty"`
+	Confidence *float64 `json:"confidence,omitempty"`
+
+	Error string   `json:"error,omitempty"`
+	PGN   string   `json:"pgn,omitempty"`
+	Moves []string `json:"moves,omitempty"`
+	Help  string   `json:"help,omitempty"`
+}
+
+// InlineCommand is one line of input of the JSON protocol: a move, such as
+// {"move":"e4"}, or a command, such as {"cmd":"fen"}
+type InlineCommand struct {
+	Move string `json:"move,omitempty"`
+	Cmd  string `json:"cmd,omitempty"`
+}
+
+// Inline plays a game line by line on plain streams instead of the TUI: it
+// reads moves and commands from in, one per line, and writes the board
+// after each move to out, so it can be piped or driven by another program.
+// In JSON mode each line in is an InlineCommand and each line out an
+// InlineEvent.
+type Inline struct {
+	in   *bufio.Scanner
+	out  io.Writer
+	json bool
+
+	game    *chess.Game
+	history []string // moves in SAN, as the AI is sent them
+
+	ai      MoveGenerator
+	aiColor chess.Color // the side the AI plays, or NoColor for two players
+}
+
+// NewInline creates a line-based game of two players from the starting
+// position
+func NewInline(in io.Reader, out io.Writer) *Inline {
+	return &Inline{
+		in:      bufio.NewScanner(in),
+		out:     out,
+		game:    chess.NewGame(),
+		aiColor: chess.NoColor,
+	}
+}
+
+// SetJSON switches the input and output to the JSON protocol
+func (l *Inline) SetJSON(on bool) {
+	l.json = on
+}
+
+// SetAI has generator play color, answering each move of the other side
+func (l *Inline) SetAI(generator MoveGenerator, color chess.Color) {
+	l.ai = generator
+	l.aiColor = color
+}
+
+// SetStartPosition starts the game from a FEN position instead
+func (l *Inline) SetStartPosition(fen string) error {
+	option, err := chess.FEN(fen)
+	if err != nil {
+		return fmt.Errorf("invalid start position: %w", err)
+	}
+	l.game = chess.NewGame(option)
+	l.history = nil
+	return nil
+}
+
+// Run plays until the game ends, the input runs out or "quit" is read.
+// Refused moves and unknown commands are reported, with an "Error:" line or
+// an error event, and the game carries on.
+func (l *Inline) Run() error {
+	l.emitBoard()
+	if err := l.playAI(); err != nil {
+		return err
+	}
+	for l.game.Outcome() == chess.NoOutcome && l.in.Scan() {
+		line := strings.TrimSpace(l.in.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		command, move, err := l.parse(line)
+		if err != nil {
+			l.emitError(err.Error())
+			continue
+		}
+		quit, err := l.handle(command, move)
+		if err != nil || quit {
+			return err
+		}
+	}
+	if err := l.in.Err(); err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+	return nil
+}
+
+// parse reads a line of input as either a command or a move
+func (l *Inline) parse(line string) (command, move string, err error) {
+	if !l.json {
+		if word := strings.ToLower(line); slices.Contains(inlineCommands, word) {
+			return word, "", nil
+		}
+		return "", line, nil
+	}
+
+	var input InlineCommand
+	if err := json.Unmarshal([]byte(line), &input); err != nil {
+		return "", "", fmt.Errorf("invalid command %s: %w", line, err)
+	}
+	command = strings.ToLower(input.Cmd)
+	switch {
+	case command != "" && !slices.Contains(inlineCommands, command):
+		return "", "", fmt.Errorf("unknown command %q", input.Cmd)
+	case command == "" && input.Move == "":
+		return "", "", fmt.Errorf(`expected {"move": ...} or {"cmd": ...}, got %s`, line)
+	}
+	return command, input.Move, nil
+}
+
+// handle runs a command, or plays a move if command is empty, and reports
+// whether it ends the session
+func (l *Inline) handle(command, text string) (bool, error) {
+	switch command {
+	case "quit", "exit":
+		return true, nil
+	case "help":
+		l.emit(InlineEvent{Type: InlineHelp, Help: inlineHelp})
+	case "board":
+		l.emitBoard()
+	case "fen":
+		l.emit(InlineEvent{Type: InlineFEN, FEN: l.game.Position().String()})
+	case "pgn":
+		l.emit(InlineEvent{Type: InlinePGN, PGN: l.game.String()})
+	case "moves":
+		var moves []string
+		for _, move := range l.game.ValidMoves() {
+			moves = append(moves, notation.Encode(l.game.Position(), move))
+		}
+		l.emit(InlineEvent{Type: InlineMoves, Moves: moves})
+	case "undo":
+		l.undo()
+	case "resign":
+		l.game.Resign(l.game.Position().Turn())
+		l.emitBoard()
+	default:
+		event, err := l.move(text)
+		if err != nil {
+			l.emitError(notation.Explain(err))
+			return false, nil
+		}
+		l.emit(event)
+		l.emitBoard()
+		return false, l.playAI()
+	}
+	return false, nil
+}
+
+// move plays text, in SAN or UCI, for the side to move, and returns the
+// event telling of it
+func (l *Inline) move(text string) (InlineEvent, error) {
+	move, err := notation.Decode(l.game.Position(), text)
+	if err != nil {
+		return InlineEvent{}, err
+	}
+	color := l.game.Position().Turn()
+	san := notation.Encode(l.game.Position(), move)
+	if err := l.game.Move(move); err != nil {
+		return InlineEvent{}, err
+	}
+	l.history = append(l.history, san)
+	return InlineEvent{Type: InlineMove, Color: colorName(color), Move: san, UCI: notation.EncodeUCI(move)}, nil
+}
+
+// undo takes back the last move, and the AI's reply before it so the same
+// side is to move again
+func (l *Inline) undo() {
+	plies := 1
+	if l.ai != nil && l.game.Position().Turn() != l.aiColor {
+		plies = 2
+	}
+	moves := l.game.Moves()
+	if len(moves) < plies {
+		l.emitError("no move to take back")
+		return
+	}
+
+	// Replay the game without the moves taken back
+	start, _ := chess.FEN(l.game.Positions()[0].String())
+	replay := chess.NewGame(start)
+	for _, move := range moves[:len(moves)-plies] {
+		replay.Move(move)
+	}
+	l.game = replay
+	l.history = l.history[:len(l.history)-plies]
+	l.emitBoard()
+}
+
+// playAI has the AI move when it is its turn, asking once more if its
+// first answer isn't a legal move
+func (l *Inline) playAI() error {
+	if l.ai == nil || l.game.Outcome() != chess.NoOutcome || l.game.Position().Turn() != l.aiColor {
+		return nil
+	}
+	errorMsg := ""
+	for attempt := 0; attempt < 2; attempt++ {
+		result, err := l.ai.GetAIMoveResult(l.game.Position().String(), l.history, errorMsg, colorName(l.aiColor))
+		if err != nil {
+			return fmt.Errorf("failed to get AI move: %w", err)
+		}
+		if result.Action == ai_player.ActionResign {
+			l.game.Resign(l.aiColor)
+			l.emit(InlineEvent{Type: InlineResign, Color: colorName(l.aiColor), Reasoning: result.ActionReason})
+			l.emitBoard()
+			return nil
+		}
+		event, err := l.move(result.Move)
+		if err != nil {
+			errorMsg = err.Error()
+			continue
+		}
+		event.Type = InlineAIMove
+		event.Reasoning = result.Reasoning
+		event.Confidence = result.Confidence
+		l.emit(event)
+		l.emitBoard()
+		return nil
+	}
+	return errkind.Errorf(errkind.IllegalMove, "AI failed to make valid move after retry: %s", errorMsg)
+}
+
+// emitBoard tells of the position: the board, and whose move it is or how
+// the game ended
+func (l *Inline) emitBoard() {
+	board := l.game.Position().Board()
+	event := InlineEvent{Type: InlineBoard, FEN: l.game.Position().String(), Status: l.statusLine()}
+	for rank := 7; rank >= 0; rank-- {
+		var row strings.Builder
+		for file := 0; file < 8; file++ {
+			piece := board.Piece(chess.Square(rank*8 + file))
+			if piece == chess.NoPiece {
+				row.WriteString(".")
+			} else {
+				row.WriteString(pieceLetter(piece))
+			}
+		}
+		event.Board = append(event.Board, row.String())
+	}
+	if l.game.Outcome() == chess.NoOutcome {
+		event.Turn = colorName(l.game.Position().Turn())
+		moves := l.game.Moves()
+		event.Check = len(moves) > 0 && moves[len(moves)-1].HasTag(chess.Check)
+	} else {
+		event.Result = l.game.Outcome().String()
+		event.Method = methodName(l.game.Method())
+	}
+	l.emit(event)
+}
+
+// emitError reports a refused move or command
+func (l *Inline) emitError(message string) {
+	l.emit(InlineEvent{Type: InlineError, Error: message})
+}
+
+// emit writes an event as a line of JSON, or as text for people
+func (l *Inline) emit(event InlineEvent) {
+	if l.json {
+		data, err := json.Marshal(event)
+		if err != nil {
+			data, _ = json.Marshal(InlineEvent{Type: InlineError, Error: err.Error()})
+		}
+		fmt.Fprintln(l.out, string(data))
+		return
+	}
+
+	switch event.Type {
+	case InlineBoard:
+		var sb strings.Builder
+		for i, row := range event.Board {
+			sb.WriteString(fmt.Sprintf("%d", 8-i))
+			for _, square := range row {
+				sb.WriteString(" " + string(square))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("  a b c d e f g h\n")
+		sb.WriteString(event.Status + "\n")
+		fmt.Fprint(l.out, sb.String())
+	case InlineMove, InlineAIMove:
+		fmt.Fprintf(l.out, "%s played %s\n", capitalize(event.Color), event.Move)
+	case InlineResign:
+		fmt.Fprintf(l.out, "%s resigns\n", capitalize(event.Color))
+	case InlineError:
+		fmt.Fprintln(l.out, "Error: "+event.Error)
+	case InlineFEN:
+		fmt.Fprintln(l.out, event.FEN)
+	case InlinePGN:
+		fmt.Fprintln(l.out, event.PGN)
+	case InlineMoves:
+		fmt.Fprintln(l.out, strings.Join(event.Moves, " "))
+	case InlineHelp:
+		fmt.Fprintln(l.out, event.Help)
+	}
+}
+
+// capitalize upper-cases the first letter of word
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+	return strings.ToUpper(word[:1]) + word[1:]
+}
+
+// statusLine says whose move it is, or gives the result and how it came about
+func (l *Inline) statusLine() string {
+	switch l.game.Outcome() {
+	case chess.WhiteWon:
+		return "Result: 1-0, White wins by " + methodName(l.game.Method())
+	case chess.BlackWon:
+		return "Result: 0-1, Black wins by " + methodName(l.game.Method())
+	case chess.Draw:
+		return "Result: 1/2-1/2, draw by " + methodName(l.game.Method())
+	}
+	status := l.game.Position().Turn().Name() + " to move"
+	if moves := l.game.Moves(); len(moves) > 0 && moves[len(moves)-1].HasTag(chess.Check) {
+		status += ", in check"
+	}
+	return status
+}
+
+// pieceLetter is the FEN letter of a piece: capitals for white
+func pieceLetter(piece chess.Piece) string {
+	letter := piece.Type().String()
+	if piece.Color() == chess.White {
+		return strings.ToUpper(letter)
+	}
+	return letter
+}
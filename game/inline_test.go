@@ -0,0 +1,128 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+// replyGenerator answers with its moves in turn, recording the history and
+// errors it is sent
+type replyGenerator struct {
+	moves      []string
+	confidence *float64
+	histories  [][]string
+	errors     []string
+}
+
+func (r *replyGenerator) GetAIMoveResult(boardState string, gameHistory []string, errorMsg string, playerColor string) (*AIMoveResult, error) {
+	r.histories = append(r.histories, gameHistory)
+	r.errors = append(r.errors, errorMsg)
+	move := r.moves[0]
+	r.moves = r.moves[1:]
+	return &AIMoveResult{Move: move, Confidence: r.confidence}, nil
+}
+
+func (r *replyGenerator) SetPersonality(name string) {}
+
+func (r *replyGenerator) SuggestMoves(boardState string, gameHistory []string, playerColor string) ([]CandidateMove, error) {
+	return nil, errors.New("not used")
+}
+
+func TestInlineTwoPlayers(t *testing.T) {
+	var out strings.Builder
+	input := "e4\n# a comment\n\nKe2\ne5\nundo\nfen\ng5\nNc3\nf5\nQh5#\nd5\n"
+	if err := NewInline(strings.NewReader(input), &out).Run(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := out.String()
+
+	if !strings.Contains(text, "4 . . . . P . . .\n") || !strings.Contains(text, "White played e4\n") {
+		t.Errorf("Expected the board after e4, got:\n%s", text)
+	}
+	if !strings.Contains(text, "Error: ") {
+		t.Errorf("Expected the illegal Ke2 refused, got:\n%s", text)
+	}
+	if !strings.Contains(text, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq") {
+		t.Errorf("Expected undo to take back e5, got:\n%s", text)
+	}
+	if !strings.HasSuffix(text, "Result: 1-0, White wins by checkmate\n") {
+		t.Errorf("Expected the game to end in mate, got:\n%s", text)
+	}
+	if strings.Contains(text, "played d5") {
+		t.Error("Expected input after the game's end to be ignored")
+	}
+}
+
+func TestInlineAgainstAI(t *testing.T) {
+	ai := &replyGenerator{moves: []string{"e4", "e2e5", "Nf3", "Nf3"}}
+	var out strings.Builder
+	inline := NewInline(strings.NewReader("e4\ne5\nundo\nc5\nquit\nd6\n"), &out)
+	inline.SetAI(ai, chess.White)
+	if err := inline.Run(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := out.String()
+
+	if !strings.HasPrefix(text, "8 r n b q k b n r\n") || !strings.Contains(text, "White played e4\n") {
+		t.Errorf("Expected the AI to open as white, got:\n%s", text)
+	}
+	if ai.errors[1] != "" || ai.errors[2] == "" {
+		t.Errorf("Expected the AI asked again after its illegal e2e5, got errors %q", ai.errors)
+	}
+	// undo took back the AI's Nf3 and black's e5 together
+	if got := strings.Join(ai.histories[3], " "); got != "e4 c5" {
+		t.Errorf("Expected the AI sent e4 c5 after the undo, got %q", got)
+	}
+	if strings.Contains(text, "played d6") {
+		t.Error("Expected nothing played after quit")
+	}
+}
+
+func TestInlineJSON(t *testing.T) {
+	confidence := 0.8
+	ai := &replyGenerator{moves: []string{"e5"}, confidence: &confidence}
+	var out strings.Builder
+	inline := NewInline(strings.NewReader(`{"move":"e4"}`+"\nnot json\n"+`{"cmd":"dance"}`+"\n"+`{"cmd":"fen"}`+"\n"), &out)
+	inline.SetJSON(true)
+	inline.SetAI(ai, chess.Black)
+	if err := inline.Run(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var events []InlineEvent
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var event InlineEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Expected every line to be JSON, got %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+
+	var types []string
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	want := "board move board ai_move board error error fen"
+	if got := strings.Join(types, " "); got != want {
+		t.Fatalf("Expected events %s, got %s", want, got)
+	}
+	if events[1].Move != "e4" || events[1].UCI != "e2e4" || events[1].Color != "white" {
+		t.Errorf("Expected white's e4, got %+v", events[1])
+	}
+	if events[2].Turn != "black" || events[2].Board[4] != "....P..." {
+		t.Errorf("Expected the board after e4 with black to move, got %+v", events[2])
+	}
+	if events[3].Move != "e5" || events[3].Confidence == nil || *events[3].Confidence != 0.8 {
+		t.Errorf("Expected the AI's e5 with its confidence, got %+v", events[3])
+	}
+	if !strings.Contains(events[6].Error, "unknown command") {
+		t.Errorf("Expected the unknown command refused, got %+v", events[6])
+	}
+	if !strings.HasPrefix(events[7].FEN, "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w") {
+		t.Errorf("Expected the FEN after e4 e5, got %q", events[7].FEN)
+	}
+}
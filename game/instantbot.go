@@ -0,0 +1,191 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"chess-tui/ai_player"
+
+	"github.com/notnil/chess"
+)
+
+// Instant bot styles
+const (
+	BotRandom = "random" // any legal move
+	BotGreedy = "greedy" // the move winning the most material, else any legal move
+)
+
+// InstantBotName is the instant bot's name in the menu and the game log
+const InstantBotName = "Instant Bot"
+
+// InstantBotSettings configures the instant bot, an opponent that answers
+// at once without any AI, for trying out and demoing the Human vs AI flow.
+// The latency and failures it can simulate exercise the TUI's pending
+// state, clock, cancellation and retry; with a seed they repeat exactly.
+type InstantBotSettings struct {
+	Style    string  `json:"style,omitempty"`     // BotRandom or BotGreedy, the default
+	DelayMS  int     `json:"delay_ms,omitempty"`  // how long it "thinks", to show the pending state
+	JitterMS int     `json:"jitter_ms,omitempty"` // up to this much more, at random
+	Illegal  float64 `json:"illegal,omitempty"`   // the share of moves first answered illegally, to exercise the retry
+
+	// Every FailEvery-th request fails with an error, and every
+	// HangEvery-th never answers until the game is reset or quit; 0 never
+	FailEvery int `json:"fail_every,omitempty"`
+	HangEvery int `json:"hang_every,omitempty"`
+
+	// Seed fixes the bot's random choices, delays and illegal answers, so a
+	// session can be played again the same way; 0 picks a new seed each time
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// InstantBot is a MoveGenerator that needs no network or model: it plays a
+// random legal move, or the one winning the most material
+type InstantBot struct {
+	settings InstantBotSettings
+
+	mu       sync.Mutex
+	rng      *rand.Rand
+	ctx      context.Context // the game's; a delay or hang ends with it
+	requests int             // move requests so far, counting retries
+}
+
+// NewInstantBot creates an instant bot; nil settings give a greedy bot that
+// answers at once
+func NewInstantBot(settings *InstantBotSettings) *InstantBot {
+	bot := &InstantBot{ctx: context.Background()}
+	if settings != nil {
+		bot.settings = *settings
+	}
+	seed := bot.settings.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	bot.rng = rand.New(rand.NewSource(seed))
+	if bot.settings.Style == "" {
+		bot.settings.Style = BotGreedy
+	}
+	return bot
+}
+
+// Label describes the bot for the menu, e.g. "Human vs Instant Bot (greedy)"
+func (b *InstantBot) Label() string {
+	return fmt.Sprintf("Human vs %s (%s, no AI needed)", InstantBotName, b.settings.Style)
+}
+
+// SetContext ends the bot's delays and hangs with ctx, as a cancelled
+// request to the AI would end
+func (b *InstantBot) SetContext(ctx context.Context) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ctx = ctx
+}
+
+// GetAIMoveResult picks the bot's move in the position given as FEN, after
+// the delay and with the failures the settings ask for. Unless errorMsg
+// says the last answer was refused, it answers with an illegal move as
+// often as the settings ask.
+func (b *InstantBot) GetAIMoveResult(boardState string, gameHistory []string, errorMsg string, playerColor string) (*AIMoveResult, error) {
+	ctx, request, delay := b.nextRequest()
+	switch {
+	case every(request, b.settings.HangEvery):
+		<-ctx.Done()
+		return nil, fmt.Errorf("simulated hang on request %d: %w", request, ctx.Err())
+	case delay > 0:
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if every(request, b.settings.FailEvery) {
+		return nil, fmt.Errorf("simulated failure on request %d", request)
+	}
+
+	position, err := positionOf(boardState)
+	if err != nil {
+		return nil, err
+	}
+	if errorMsg == "" && b.chance() < b.settings.Illegal {
+		return &AIMoveResult{Move: "a1a1", Reasoning: "An illegal move, on purpose", Provider: InstantBotName}, nil
+	}
+	move, reason, err := b.pick(position)
+	if err != nil {
+		return nil, err
+	}
+	return &AIMoveResult{Move: move.String(), Reasoning: reason, Provider: InstantBotName}, nil
+}
+
+// pick chooses a move in the bot's style, saying why
+func (b *InstantBot) pick(position *chess.Position) (*chess.Move, string, error) {
+	moves := position.ValidMoves()
+	if len(moves) == 0 {
+		return nil, "", fmt.Errorf("no legal moves")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.settings.Style == BotGreedy {
+		var winning []*chess.Move
+		best := 0
+		before := ai_player.MaterialBalance(position, position.Turn())
+		for _, move := range moves {
+			value := ai_player.MaterialBalance(position.Update(move), position.Turn()) - before
+			switch {
+			case value > best:
+				winning, best = []*chess.Move{move}, value
+			case value == best && value > 0:
+				winning = append(winning, move)
+			}
+		}
+		if len(winning) > 0 {
+			return winning[b.rng.Intn(len(winning))], "The move winning the most material", nil
+		}
+	}
+	return moves[b.rng.Intn(len(moves))], "A random legal move", nil
+}
+
+// nextRequest counts a move request, returning the context it runs under,
+// its number from 1 and how long to delay it
+func (b *InstantBot) nextRequest() (context.Context, int, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.requests++
+	delay := b.settings.DelayMS
+	if b.settings.JitterMS > 0 {
+		delay += b.rng.Intn(b.settings.JitterMS + 1)
+	}
+	return b.ctx, b.requests, time.Duration(delay) * time.Millisecond
+}
+
+// every reports whether request n is one of every k-th, for k above 0
+func every(n, k int) bool {
+	return k > 0 && n%k == 0
+}
+
+// chance returns a random number in [0, 1)
+func (b *InstantBot) chance() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rng.Float64()
+}
+
+// SetPersonality is ignored; the bot has none
+func (b *InstantBot) SetPersonality(name string) {}
+
+// SuggestMoves offers the move the bot would play itself
+func (b *InstantBot) SuggestMoves(boardState string, gameHistory []string, playerColor string) ([]CandidateMove, error) {
+	position, err := positionOf(boardState)
+	if err != nil {
+		return nil, err
+	}
+	move, reason, err := b.pick(position)
+	if err != nil {
+		return nil, err
+	}
+	return []CandidateMove{{Move: move.String(), Explanation: reason}}, nil
+}
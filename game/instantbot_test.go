@@ -0,0 +1,149 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"chess-tui/notation"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func TestInstantBotGreedyTakesMaterial(t *testing.T) {
+	// Black's queen hangs on h4
+	fen := "rnb1kbnr/pppp1ppp/8/4p3/7q/5PP1/PPPPP2P/RNBQKBNR w KQkq - 1 3"
+	bot := NewInstantBot(nil)
+	result, err := bot.GetAIMoveResult(fen, nil, "", "white")
+	if err != nil || result.Move != "g3h4" {
+		t.Errorf("Expected the greedy bot to take the queen, got %+v (%v)", result, err)
+	}
+}
+
+func TestInstantBotRandomPlaysLegalMoves(t *testing.T) {
+	bot := NewInstantBot(&InstantBotSettings{Style: BotRandom})
+	game := chess.NewGame()
+	for range 20 {
+		if game.Outcome() != chess.NoOutcome {
+			break
+		}
+		result, err := bot.GetAIMoveResult(game.Position().String(), nil, "", "white")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		move, err := notation.Decode(game.Position(), result.Move)
+		if err == nil {
+			err = game.Move(move)
+		}
+		if err != nil {
+			t.Fatalf("Expected a legal move, got %s: %v", result.Move, err)
+		}
+	}
+}
+
+func TestInstantBotExercisesRetry(t *testing.T) {
+	g := NewGame()
+	g.gameMode = ModeHumanVsAI
+	g.SetMoveGenerator(NewInstantBot(&InstantBotSettings{Illegal: 1}))
+	g.makeMove("e4")
+	cmd := g.takeAITurn()
+	if cmd == nil {
+		t.Fatal("Expected the bot to be asked for its move")
+	}
+	msg := cmd().(aiMoveMsg)
+	if msg.result.Move != "a1a1" {
+		t.Fatalf("Expected an illegal first answer, got %s", msg.result.Move)
+	}
+	retry := g.applyAIMove(msg)
+	if retry == nil || g.err == "" {
+		t.Fatalf("Expected the illegal move refused and retried, got %q", g.err)
+	}
+	g.applyAIMove(retry().(aiMoveMsg))
+	if len(g.chessGame.Moves()) != 2 {
+		t.Errorf("Expected the retry to play a legal move, got %v", g.sanMoves())
+	}
+}
+
+func TestMenuStartsInstantBotGame(t *testing.T) {
+	menu := NewMenu()
+	prefs := &Preferences{}
+	menu.SetPreferences(prefs)
+	for range instantBotMode {
+		menu.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	model, _ := menu.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	g, ok := model.(*Game)
+	if !ok {
+		t.Fatalf("Expected a game, got %T", model)
+	}
+	if _, ok := g.ai.(*InstantBot); !ok || g.aiName() != InstantBotName || prefs.Opponent != InstantBotName {
+		t.Errorf("Expected a remembered game against the instant bot, got %T %s", g.ai, g.aiName())
+	}
+
+	again := NewMenu()
+	again.SetPreferences(prefs)
+	if again.cursor != instantBotMode {
+		t.Errorf("Expected the instant bot preselected, got cursor %d", again.cursor)
+	}
+}
+
+func TestInstantBotSimulatesFailures(t *testing.T) {
+	bot := NewInstantBot(&InstantBotSettings{FailEvery: 2})
+	fen := chess.NewGame().Position().String()
+	if _, err := bot.GetAIMoveResult(fen, nil, "", "white"); err != nil {
+		t.Errorf("Expected the first request answered, got %v", err)
+	}
+	if _, err := bot.GetAIMoveResult(fen, nil, "", "white"); err == nil || !strings.Contains(err.Error(), "simulated failure on request 2") {
+		t.Errorf("Expected the second request to fail, got %v", err)
+	}
+}
+
+func TestInstantBotDelayAndHangEndWithTheGame(t *testing.T) {
+	fen := chess.NewGame().Position().String()
+	for _, settings := range []InstantBotSettings{{DelayMS: 60_000}, {HangEvery: 1}} {
+		bot := NewInstantBot(&settings)
+		ctx, cancel := context.WithCancel(context.Background())
+		bot.SetContext(ctx)
+		done := make(chan error)
+		go func() {
+			_, err := bot.GetAIMoveResult(fen, nil, "", "white")
+			done <- err
+		}()
+		cancel()
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected the request cancelled, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected %+v to end with the game", settings)
+		}
+	}
+}
+
+func TestInstantBotSeedRepeats(t *testing.T) {
+	play := func() []string {
+		bot := NewInstantBot(&InstantBotSettings{Style: BotRandom, Seed: 42, Illegal: 0.3})
+		game := chess.NewGame()
+		var moves []string
+		for range 10 {
+			result, err := bot.GetAIMoveResult(game.Position().String(), nil, "", "white")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			moves = append(moves, result.Move)
+			if move, err := notation.Decode(game.Position(), result.Move); err == nil {
+				game.Move(move)
+			}
+		}
+		return moves
+	}
+	first, second := play(), play()
+	if !slices.Equal(first, second) {
+		t.Errorf("Expected the same seed to play the same moves, got %v and %v", first, second)
+	}
+}
@@ -0,0 +1,149 @@
+package game
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// kibitzInterval is how often the kibitzer looks at the board for a new
+// position to comment on
+const kibitzInterval = 15 * time.Second
+
+// kibitzer is an AI watching a Human vs Human game that comments on the
+// position now and then. It never plays or holds the game up: it asks in
+// the background, one position at a time, and its remarks are only shown
+// on this screen.
+type kibitzer struct {
+	analyst Analyst
+	ticking bool
+	running bool // a remark is being worked out
+	asked   int  // the ply last asked about, -1 before the first
+
+	answered bool // the kibitzer has made a remark
+	ply      int  // the ply the remark is about
+	remark   Evaluation
+	err      string
+}
+
+// kibitzTickMsg is the kibitzer's periodic look at the board
+type kibitzTickMsg struct{}
+
+// kibitzMsg carries the kibitzer's remark on the position after ply moves
+type kibitzMsg struct {
+	ply        int
+	evaluation Evaluation
+	err        error
+}
+
+// SetKibitzer has analyst comment on the game in a side panel. Only Human
+// vs Human games have a kibitzer; nil removes it.
+func (g *Game) SetKibitzer(analyst Analyst) {
+	if analyst == nil || g.gameMode != ModeHumanVsHuman {
+		g.kibitz = nil
+		return
+	}
+	g.kibitz = &kibitzer{analyst: analyst, asked: -1}
+}
+
+// kibitzCmd starts the kibitzer's ticks, asking about the position at once
+func (g *Game) kibitzCmd() tea.Cmd {
+	k := g.kibitz
+	if k == nil || k.ticking {
+		return nil
+	}
+	k.ticking = true
+	return tea.Batch(g.askKibitzer(), tickKibitzer())
+}
+
+// tickKibitzer waits for the kibitzer's next look at the board
+func tickKibitzer() tea.Cmd {
+	return tea.Tick(kibitzInterval, func(time.Time) tea.Msg { return kibitzTickMsg{} })
+}
+
+// askKibitzer asks the kibitzer about the position if it has changed since
+// it was last asked and the kibitzer isn't busy with another
+func (g *Game) askKibitzer() tea.Cmd {
+	k := g.kibitz
+	ply := len(g.chessGame.Moves())
+	if k.running || k.asked == ply || g.paused || g.chessGame.Outcome() != chess.NoOutcome {
+		return nil
+	}
+	k.running = true
+	k.asked = ply
+	fen := g.getBoardState()
+	analyst := k.analyst
+	return func() tea.Msg {
+		evaluation, err := analyst.Analyze(fen)
+		return kibitzMsg{ply: ply, evaluation: evaluation, err: err}
+	}
+}
+
+// updateKibitz handles the kibitzer's messages
+func (g *Game) updateKibitz(msg tea.Msg) tea.Cmd {
+	k := g.kibitz
+	if k == nil {
+		return nil
+	}
+	switch msg := msg.(type) {
+	case kibitzTickMsg:
+		return tea.Batch(g.askKibitzer(), tickKibitzer())
+	case kibitzMsg:
+		k.running = false
+		k.err = ""
+		if msg.err != nil {
+			k.err = msg.err.Error()
+			return nil
+		}
+		k.answered = true
+		k.ply = msg.ply
+		k.remark = msg.evaluation
+	}
+	return nil
+}
+
+// kibitzText is the kibitzer's last remark, e.g. "After 12. Nf3: +0.3,
+// I'd play Nc6 — develops with tempo"
+func (k *kibitzer) kibitzText(moves []string) string {
+	if k.err != "" {
+		return "Lost for words: " + k.err
+	}
+	if !k.answered {
+		return "Watching the game…"
+	}
+	text := "At the start: "
+	if k.ply > 0 && k.ply <= len(moves) {
+		text = "After " + formatLine(moves[k.ply-1:k.ply], k.ply-1) + ": "
+	}
+	text += formatScore(k.remark.Score)
+	if k.remark.Best != "" {
+		text += ", I'd play " + k.remark.Best
+	}
+	if k.remark.Comment != "" {
+		text += " — " + k.remark.Comment
+	}
+	return text
+}
+
+// renderKibitzPanel draws the kibitzer's last remark beside the board
+func (g *Game) renderKibitzPanel() string {
+	k := g.kibitz
+	if k == nil {
+		return ""
+	}
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00AAFF"))
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA")).Width(teachPanelWidth - 2)
+
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("Kibitzer ("+k.analyst.Name()+")") + "\n")
+	// Remarks name the move they follow, so the first move is White's
+	sb.WriteString(textStyle.Render(g.formatText(k.kibitzText(g.sanMoves()), chess.White)))
+
+	return lipgloss.NewStyle().
+		Width(teachPanelWidth).
+		MarginLeft(2).
+		Render(sb.String())
+}
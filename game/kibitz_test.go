@@ -0,0 +1,63 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKibitzerCommentsOnNewPositionsOnly(t *testing.T) {
+	g := NewGame()
+	analyst := &countingAnalyst{}
+	g.SetKibitzer(analyst)
+	if !strings.Contains(g.renderKibitzPanel(), "Watching the game") {
+		t.Errorf("Expected the kibitzer watching before its first remark, got %q", g.renderKibitzPanel())
+	}
+
+	// Asking is left to a command, so the game goes on while it runs
+	g.makeMove("e4")
+	cmd := g.askKibitzer()
+	if cmd == nil {
+		t.Fatal("Expected the kibitzer asked about the new position")
+	}
+	if g.askKibitzer() != nil {
+		t.Error("Expected no second request while the first is running")
+	}
+	g.makeMove("e5")
+	g.Update(cmd())
+	if len(analyst.asked) != 1 {
+		t.Fatalf("Expected 1 position analyzed, got %d", len(analyst.asked))
+	}
+	panel := g.renderKibitzPanel()
+	if !strings.Contains(panel, "After 1. e4") || !strings.Contains(panel, "Nf3") {
+		t.Errorf("Expected the remark on 1. e4, got %q", panel)
+	}
+
+	// The tick asks about the position the game has reached since
+	g.Update(kibitzTickMsg{})
+	if cmd := g.askKibitzer(); cmd != nil {
+		t.Error("Expected the tick to have asked about the position already")
+	}
+	if g.kibitz.asked != 2 {
+		t.Errorf("Expected the kibitzer asked about ply 2, got %d", g.kibitz.asked)
+	}
+}
+
+func TestKibitzerFigurines(t *testing.T) {
+	g := NewGameWithSettings(ModeHumanVsHuman, &Settings{Figurine: true})
+	g.SetKibitzer(&countingAnalyst{})
+	g.makeMove("e4")
+	g.Update(g.askKibitzer()())
+
+	panel := g.renderKibitzPanel()
+	if !strings.Contains(panel, "After 1. e4") || !strings.Contains(panel, "♞f3") {
+		t.Errorf("Expected the remark in figurines, got %q", panel)
+	}
+}
+
+func TestKibitzerOnlyInHumanVsHuman(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	g.SetKibitzer(&countingAnalyst{})
+	if g.kibitz != nil || g.renderKibitzPanel() != "" {
+		t.Error("Expected no kibitzer in a game against the AI")
+	}
+}
@@ -0,0 +1,157 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"chess-tui/leaderboard"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// leaderboardRows is how many players the leaderboard screen lists
+const leaderboardRows = 20
+
+// leaderboardMsg carries a leaderboard loaded for the screen
+type leaderboardMsg struct {
+	board leaderboard.Board
+	err   error
+}
+
+// Leaderboard is the screen ranking every player of a hosted instance, for
+// the past week or all time
+type Leaderboard struct {
+	load   func(period string) (leaderboard.Board, error)
+	parent tea.Model // the screen to go back to, or nil to quit
+
+	period string
+	board  leaderboard.Board
+	loaded bool
+	err    string
+}
+
+// NewLeaderboard creates the leaderboard screen, loading each period's
+// board with load. Leaving it returns to parent, or quits if it is nil.
+func NewLeaderboard(load func(period string) (leaderboard.Board, error), parent tea.Model) *Leaderboard {
+	return &Leaderboard{load: load, parent: parent, period: leaderboard.PeriodWeek}
+}
+
+// Init loads the week's leaderboard
+func (l *Leaderboard) Init() tea.Cmd {
+	return l.fetch()
+}
+
+// fetch loads the leaderboard for the current period in the background
+func (l *Leaderboard) fetch() tea.Cmd {
+	load, period := l.load, l.period
+	return func() tea.Msg {
+		board, err := load(period)
+		return leaderboardMsg{board: board, err: err}
+	}
+}
+
+// Update switches between the week and all time on tab, w and a, and goes
+// back on q or esc. Other messages go to the screen underneath.
+func (l *Leaderboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case leaderboardMsg:
+		// A slow load for the period switched away from is stale
+		if msg.err == nil && msg.board.Period != l.period {
+			return l, nil
+		}
+		l.loaded = true
+		l.board = msg.board
+		l.err = ""
+		if msg.err != nil {
+			l.err = msg.err.Error()
+		}
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "tab":
+			if l.period == leaderboard.PeriodWeek {
+				return l.show(leaderboard.PeriodAll)
+			}
+			return l.show(leaderboard.PeriodWeek)
+		case "w":
+			return l.show(leaderboard.PeriodWeek)
+		case "a":
+			return l.show(leaderboard.PeriodAll)
+		case "r":
+			return l, l.fetch()
+		case "q", "esc":
+			if l.parent != nil {
+				return l.parent, nil
+			}
+			return l, tea.Quit
+		case "ctrl+c":
+			return l, tea.Quit
+		}
+	default:
+		// Keep the screen underneath up to date, such as the lobby's list
+		if l.parent != nil {
+			var cmd tea.Cmd
+			l.parent, cmd = l.parent.Update(msg)
+			return l, cmd
+		}
+	}
+	return l, nil
+}
+
+// show switches to period's leaderboard
+func (l *Leaderboard) show(period string) (tea.Model, tea.Cmd) {
+	if period == l.period {
+		return l, nil
+	}
+	l.period = period
+	l.loaded = false
+	return l, l.fetch()
+}
+
+// View renders the leaderboard as a table
+func (l *Leaderboard) View() string {
+	var sb strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700"))
+	headingStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00AAFF"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	sb.WriteString(titleStyle.Render("♔ Leaderboard ♛") + "\n\n")
+
+	week, all := helpStyle.Render("This week"), helpStyle.Render("All time")
+	if l.period == leaderboard.PeriodWeek {
+		week = headingStyle.Render("[This week]")
+	} else {
+		all = headingStyle.Render("[All time]")
+	}
+	sb.WriteString(week + "  " + all + "\n\n")
+
+	switch {
+	case l.err != "":
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+l.err) + "\n")
+	case !l.loaded:
+		sb.WriteString(helpStyle.Render("Loading...") + "\n")
+	case len(l.board.Entries) == 0:
+		sb.WriteString(helpStyle.Render("No games played yet.") + "\n")
+	default:
+		sb.WriteString(headingStyle.Render(fmt.Sprintf("%4s  %-20s %5s %6s %14s", "#", "Player", "Elo", "Games", "W / L / D")) + "\n")
+		for i, entry := range l.board.Entries {
+			if i == leaderboardRows {
+				sb.WriteString(helpStyle.Render(fmt.Sprintf("  … and %d more", len(l.board.Entries)-leaderboardRows)) + "\n")
+				break
+			}
+			name := entry.Name
+			if len([]rune(name)) > 20 {
+				name = string([]rune(name)[:19]) + "…"
+			}
+			elo := "-"
+			if entry.Elo != 0 {
+				elo = fmt.Sprint(entry.Elo)
+			}
+			sb.WriteString(fmt.Sprintf("%4d  %-20s %5s %6d %14s\n", entry.Rank, name, elo, entry.Games, fmt.Sprintf("%d / %d / %d", entry.Wins, entry.Losses, entry.Draws)))
+		}
+	}
+
+	sb.WriteString("\n" + helpStyle.Render("Press tab to switch between this week and all time, r to reload, q to go back"))
+	return sb.String()
+}
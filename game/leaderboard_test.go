@@ -0,0 +1,48 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"chess-tui/leaderboard"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLeaderboardScreen(t *testing.T) {
+	var asked []string
+	load := func(period string) (leaderboard.Board, error) {
+		asked = append(asked, period)
+		board := leaderboard.Board{Period: period, Entries: []leaderboard.Entry{{Rank: 1, Name: "alex", Elo: 1620, Games: 3, Wins: 2, Draws: 1}}}
+		if period == leaderboard.PeriodAll {
+			board.Entries = append(board.Entries, leaderboard.Entry{Rank: 2, Name: "sam", Games: 1})
+		}
+		return board, nil
+	}
+
+	menu := NewMenu()
+	menu.SetLeaderboard(load)
+	menu.cursor = len(menu.modes) - 1
+	model, cmd := menu.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	screen, ok := model.(*Leaderboard)
+	if !ok {
+		t.Fatalf("Expected the leaderboard screen, got %T", model)
+	}
+	screen.Update(cmd())
+	if view := screen.View(); !strings.Contains(view, "[This week]") || !strings.Contains(view, "alex") || !strings.Contains(view, "1620") || strings.Contains(view, "sam") {
+		t.Errorf("Expected the week's leaderboard, got:\n%s", view)
+	}
+
+	_, cmd = screen.Update(tea.KeyMsg{Type: tea.KeyTab})
+	screen.Update(cmd())
+	if view := screen.View(); !strings.Contains(view, "[All time]") || !strings.Contains(view, "sam") {
+		t.Errorf("Expected the all-time leaderboard, got:\n%s", view)
+	}
+	if strings.Join(asked, ",") != "week,all" {
+		t.Errorf("Expected the week then all time loaded, got %v", asked)
+	}
+
+	if model, _ := screen.Update(tea.KeyMsg{Type: tea.KeyEsc}); model != menu {
+		t.Errorf("Expected esc to go back to the menu, got %T", model)
+	}
+}
@@ -0,0 +1,201 @@
+package game
+
+import (
+	"fmt"
+
+	"chess-tui/notation"
+	"chess-tui/positions"
+
+	"github.com/notnil/chess"
+)
+
+// Lesson is a tutorial lesson: a position and the moves the learner makes in it
+type Lesson struct {
+	ID    string
+	Title string
+	FEN   string
+	Setup []string // SAN moves played from FEN before the first step, e.g. to allow en passant
+	Steps []LessonStep
+}
+
+// LessonStep asks the learner for one move
+type LessonStep struct {
+	Prompt  string
+	Targets []string // squares highlighted on the board
+	Accept  []string // SAN moves that complete the step
+	Mate    bool     // any checkmate completes the step
+	Reply   string   // SAN move the opponent answers with, if any
+}
+
+// lessons is the bundled tutorial, from how pawns move to mating patterns
+var lessons = []Lesson{
+	{
+		ID:    "pawn-moves",
+		Title: "Pawn moves",
+		FEN:   chess.StartingPosition().String(),
+		Steps: []LessonStep{
+			{
+				Prompt:  "Pawns move straight ahead. On its first move a pawn may go two squares. Push the e-pawn two squares.",
+				Targets: []string{"e4"},
+				Accept:  []string{"e4"},
+				Reply:   "e5",
+			},
+			{
+				Prompt:  "After that a pawn goes one square at a time. Push the d-pawn one square.",
+				Targets: []string{"d3"},
+				Accept:  []string{"d3"},
+			},
+		},
+	},
+	{
+		ID:    "pawn-captures",
+		Title: "Pawn captures",
+		FEN:   "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1",
+		Steps: []LessonStep{
+			{
+				Prompt:  "Pawns capture one square diagonally forward. Take the black pawn.",
+				Targets: []string{"d5"},
+				Accept:  []string{"exd5"},
+			},
+		},
+	},
+	{
+		ID:    "knight",
+		Title: "The knight",
+		FEN:   "4k3/8/8/8/8/8/8/1N2K3 w - - 0 1",
+		Steps: []LessonStep{
+			{
+				Prompt:  "Knights jump in an L: two squares one way, then one square to the side. Jump to any highlighted square.",
+				Targets: []string{"a3", "c3", "d2"},
+				Accept:  []string{"Na3", "Nc3", "Nd2"},
+			},
+		},
+	},
+	{
+		ID:    "castling",
+		Title: "Castling",
+		FEN:   "r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3K2R w KQkq - 0 1",
+		Steps: []LessonStep{
+			{
+				Prompt:  "Castling moves the king two squares toward a rook, and the rook jumps over it. Castle kingside with O-O.",
+				Targets: []string{"g1"},
+				Accept:  []string{"O-O"},
+				Reply:   "O-O-O",
+			},
+		},
+	},
+	{
+		ID:    "en-passant",
+		Title: "En passant",
+		FEN:   "4k3/3p4/8/4P3/8/8/8/4K3 b - - 0 1",
+		Setup: []string{"d5"},
+		Steps: []LessonStep{
+			{
+				Prompt:  "Black's pawn just went two squares, passing yours. For this move only, you may capture it as if it had gone one: exd6.",
+				Targets: []string{"d6"},
+				Accept:  []string{"exd6"},
+			},
+		},
+	},
+	{
+		ID:    "promotion",
+		Title: "Promotion",
+		FEN:   "4k3/P7/8/8/8/8/8/4K3 w - - 0 1",
+		Steps: []LessonStep{
+			{
+				Prompt:  "A pawn that reaches the last rank becomes another piece, almost always a queen. Promote with a8=Q.",
+				Targets: []string{"a8"},
+				Accept:  []string{"a8=Q"},
+			},
+		},
+	},
+	{
+		ID:    "back-rank-mate",
+		Title: "Back-rank mate",
+		FEN:   positions.MustGet("back-rank").FEN,
+		Steps: []LessonStep{
+			{
+				Prompt:  "A king walled in by its own pawns can be mated on the back rank. Deliver checkmate.",
+				Targets: []string{"d8"},
+				Mate:    true,
+			},
+		},
+	},
+	{
+		ID:    "lawnmower-mate",
+		Title: "Two-rook mate",
+		FEN:   "7k/R7/8/8/8/8/8/1R4K1 w - - 0 1",
+		Steps: []LessonStep{
+			{
+				Prompt:  "One rook cuts the king off while the other gives check. Deliver checkmate.",
+				Targets: []string{"b8"},
+				Mate:    true,
+			},
+		},
+	},
+	{
+		ID:    "avoid-stalemate",
+		Title: "Avoiding stalemate",
+		FEN:   positions.MustGet("queen-stalemate").FEN,
+		Steps: []LessonStep{
+			{
+				Prompt:  "A king with no legal move that isn't in check is stalemated, and the game is drawn. Qb6 would do just that. Bring your king closer first.",
+				Targets: []string{"b2"},
+				Accept:  []string{"Kb2"},
+			},
+		},
+	},
+}
+
+// newGame returns a game at the lesson's starting position, after its setup moves
+func (l Lesson) newGame() (*chess.Game, error) {
+	fen, err := chess.FEN(l.FEN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lesson position: %w", err)
+	}
+	game := chess.NewGame(fen, chess.UseNotation(chess.AlgebraicNotation{}))
+	for _, san := range l.Setup {
+		if err := playSAN(game, san); err != nil {
+			return nil, fmt.Errorf("invalid lesson setup: %w", err)
+		}
+	}
+	return game, nil
+}
+
+// accepts reports whether a move completes the step
+func (s LessonStep) accepts(game *chess.Game, move *chess.Move) bool {
+	for _, san := range s.Accept {
+		expected, err := notation.Decode(game.Position(), san)
+		if err == nil && expected.String() == move.String() {
+			return true
+		}
+	}
+	if !s.Mate {
+		return false
+	}
+	after := game.Clone()
+	if err := after.Move(move); err != nil {
+		return false
+	}
+	return after.Method() == chess.Checkmate
+}
+
+// targetSquares returns the step's highlighted squares
+func (s LessonStep) targetSquares() []chess.Square {
+	var squares []chess.Square
+	for _, name := range s.Targets {
+		if sq, ok := parseSquare(name); ok {
+			squares = append(squares, sq)
+		}
+	}
+	return squares
+}
+
+// playSAN plays a move given in algebraic notation
+func playSAN(game *chess.Game, san string) error {
+	move, err := notation.Decode(game.Position(), san)
+	if err != nil {
+		return err
+	}
+	return game.Move(move)
+}
@@ -0,0 +1,287 @@
+package game
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"chess-tui/events"
+	"chess-tui/leaderboard"
+	"chess-tui/lobby"
+	"chess-tui/netplay"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// lobbyRefreshInterval is how often the lobby screen reloads the open games
+const lobbyRefreshInterval = 2 * time.Second
+
+// lobbyGamesMsg carries the open games fetched from the lobby
+type lobbyGamesMsg struct {
+	games []lobby.Game
+	err   error
+}
+
+// lobbyTickMsg asks the lobby screen to refresh
+type lobbyTickMsg struct{}
+
+// Lobby is the screen for finding networked games: it lists the games open
+// on a lobby server, and hosts or joins one
+type Lobby struct {
+	client   *lobby.Client
+	name     string // the player's name, shown to others when hosting
+	settings *Settings
+	ctx      context.Context // ends the lobby's requests and the games started
+
+	games  []lobby.Game
+	cursor int
+	loaded bool
+	err    string
+
+	invite *textinput.Model // the invite code of a private game to join, while being typed
+
+	blurred bool // the terminal is in the background, so refreshes wait
+	stale   bool // a refresh came due while the terminal was in the background
+}
+
+// NewLobby creates the lobby screen for the lobby server behind client
+func NewLobby(client *lobby.Client, name string, settings *Settings) *Lobby {
+	return &Lobby{client: client, name: name, settings: settings, ctx: context.Background()}
+}
+
+// SetContext ends the lobby's requests, and the games started from it, with ctx
+func (l *Lobby) SetContext(ctx context.Context) {
+	l.ctx = ctx
+}
+
+// Init loads the open games
+func (l *Lobby) Init() tea.Cmd {
+	return tea.Batch(l.refresh(), appTitleCmd(l.settings))
+}
+
+// refresh fetches the open games
+func (l *Lobby) refresh() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(l.ctx, 5*time.Second)
+		defer cancel()
+		games, err := l.client.List(ctx)
+		return lobbyGamesMsg{games: games, err: err}
+	}
+}
+
+// Update handles the list of games and the player's choices
+func (l *Lobby) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case lobbyGamesMsg:
+		l.loaded = true
+		l.err = ""
+		if msg.err != nil {
+			l.err = msg.err.Error()
+		} else {
+			l.games = msg.games
+			l.cursor = min(l.cursor, max(len(l.games)-1, 0))
+		}
+		return l, tea.Tick(lobbyRefreshInterval, func(time.Time) tea.Msg { return lobbyTickMsg{} })
+	case lobbyTickMsg:
+		if l.blurred {
+			l.stale = true
+			return l, nil
+		}
+		return l, l.refresh()
+	case tea.BlurMsg:
+		l.blurred = true
+	case tea.FocusMsg:
+		l.blurred = false
+		if l.stale {
+			l.stale = false
+			return l, l.refresh()
+		}
+	case tea.KeyMsg:
+		if l.invite != nil {
+			return l.updateInvite(msg)
+		}
+		switch msg.String() {
+		case "up", "k":
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case "down", "j":
+			if l.cursor < len(l.games)-1 {
+				l.cursor++
+			}
+		case "enter":
+			if len(l.games) > 0 {
+				return l.join(l.games[l.cursor])
+			}
+		case "c":
+			return l.host(false)
+		case "p":
+			return l.host(true)
+		case "i":
+			input := textinput.New()
+			input.Prompt = "Invite code: "
+			input.CharLimit = 32
+			input.Width = 20
+			input.Focus()
+			l.invite = &input
+			return l, textinput.Blink
+		case "L":
+			board := NewLeaderboard(l.leaderboard, l)
+			return board, board.Init()
+		case "q", "ctrl+c":
+			return l, tea.Quit
+		}
+	}
+	return l, nil
+}
+
+// leaderboard loads the lobby's leaderboard for period
+func (l *Lobby) leaderboard(period string) (leaderboard.Board, error) {
+	ctx, cancel := context.WithTimeout(l.ctx, 5*time.Second)
+	defer cancel()
+	return l.client.Leaderboard(ctx, period)
+}
+
+// updateInvite handles the keys while an invite code is typed: enter
+// joins the private game, esc gives up
+func (l *Lobby) updateInvite(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		l.invite = nil
+		return l, nil
+	case "enter":
+		code := strings.TrimSpace(l.invite.Value())
+		l.invite = nil
+		if code == "" {
+			return l, nil
+		}
+		return l.join(lobby.Game{ID: code})
+	}
+	input, cmd := l.invite.Update(msg)
+	l.invite = &input
+	return l, cmd
+}
+
+// join claims an open game and connects to its host
+func (l *Lobby) join(open lobby.Game) (tea.Model, tea.Cmd) {
+	ctx, cancel := context.WithTimeout(l.ctx, 5*time.Second)
+	defer cancel()
+	claimed, err := l.client.Join(ctx, open.ID, l.name)
+	if err != nil {
+		l.err = err.Error()
+		return l, l.refresh()
+	}
+	peer, err := netplay.JoinPrivate(claimed.Addr, claimed.Password)
+	if err != nil {
+		l.err = err.Error()
+		return l, l.refresh()
+	}
+	game := NewNetworkGame(peer, l.settings)
+	game.SetContext(l.ctx)
+	return game, game.Init()
+}
+
+// host opens a game on this machine and lists it in the lobby until an
+// opponent joins. A private game isn't listed: the player passes its invite
+// code on to a friend, and the game takes no one without its password.
+func (l *Lobby) host(private bool) (tea.Model, tea.Cmd) {
+	password := ""
+	if private {
+		password = newGamePassword()
+	}
+	peer, err := netplay.HostPrivate(":0", password)
+	if err != nil {
+		l.err = err.Error()
+		return l, nil
+	}
+	_, portText, _ := net.SplitHostPort(peer.Addr())
+	port, _ := strconv.Atoi(portText)
+
+	ctx, cancel := context.WithTimeout(l.ctx, 5*time.Second)
+	defer cancel()
+	var open lobby.Game
+	if private {
+		open, err = l.client.CreatePrivate(ctx, l.name, port, password)
+	} else {
+		open, err = l.client.Create(ctx, l.name, port)
+	}
+	if err != nil {
+		peer.Close()
+		l.err = err.Error()
+		return l, nil
+	}
+	game := NewNetworkGame(peer, l.settings)
+	game.SetContext(l.ctx)
+	if private {
+		game.invite = open.ID
+	}
+	// Stop listing the game once the player leaves it
+	go l.client.KeepOpen(game.ctx, open.ID)
+	// The host reports the result for the lobby's leaderboard, with the
+	// moves and their times for its fair play review
+	game.Events().Subscribe(func(event events.Event) {
+		result := lobby.ResultRequest{Result: event.Result, Termination: game.termination(), Moves: game.sanMoves()}
+		for _, elapsed := range game.moveTimes {
+			result.MoveTimes = append(result.MoveTimes, elapsed.Milliseconds())
+		}
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := l.client.Report(ctx, open.ID, result); err != nil {
+				slog.Warn("Failed to report result to lobby", "id", open.ID, "error", err)
+			}
+		}()
+	}, events.GameEnded)
+	return game, game.Init()
+}
+
+// newGamePassword returns a random password for a private game
+func newGamePassword() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// View renders the open games
+func (l *Lobby) View() string {
+	var sb strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700")).Render("♔ Lobby ♛")
+	sb.WriteString(title + "\n\n")
+
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	switch {
+	case !l.loaded:
+		sb.WriteString(dim.Render("Loading open games...") + "\n")
+	case len(l.games) == 0:
+		sb.WriteString(dim.Render("No open games yet. Press c to host one.") + "\n")
+	}
+	for i, open := range l.games {
+		cursor := " "
+		style := dim
+		if i == l.cursor {
+			cursor = ">"
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Bold(true)
+		}
+		waiting := time.Since(open.Created).Round(time.Second)
+		sb.WriteString(style.Render(fmt.Sprintf("%s %s (waiting %s)", cursor, open.Host, waiting)) + "\n")
+	}
+	if l.err != "" {
+		sb.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+l.err) + "\n")
+	}
+	if l.invite != nil {
+		sb.WriteString("\n" + l.invite.View() + "\n" + dim.Render("Enter to join the private game, Esc to cancel"))
+		return sb.String()
+	}
+
+	sb.WriteString("\n" + dim.Render(fmt.Sprintf("Playing as %s. ↑/↓ to choose, Enter to join (as Black), c to host (as White), p to host privately, i to join with an invite code, L for the leaderboard, q to quit", l.name)))
+	return sb.String()
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"chess-tui/lobby"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func TestLobbyHostAndJoin(t *testing.T) {
+	ts := httptest.NewServer(lobby.NewServer(time.Minute).Handler())
+	defer ts.Close()
+
+	hostLobby := NewLobby(lobby.NewClient(ts.URL), "alex", DefaultSettings())
+	model, _ := hostLobby.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	host, ok := model.(*Game)
+	if !ok {
+		t.Fatalf("Expected hosting to start a game, got error %q", hostLobby.err)
+	}
+	defer host.peer.Close()
+
+	joinLobby := NewLobby(lobby.NewClient(ts.URL), "sam", DefaultSettings())
+	joinLobby.Update(joinLobby.refresh()())
+	if !strings.Contains(joinLobby.View(), "alex") {
+		t.Fatalf("Expected alex's game listed, got %q", joinLobby.View())
+	}
+	model, _ = joinLobby.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	joiner, ok := model.(*Game)
+	if !ok {
+		t.Fatalf("Expected joining to start a game, got error %q", joinLobby.err)
+	}
+	defer joiner.peer.Close()
+
+	if host.humanColor != chess.White || joiner.humanColor != chess.Black {
+		t.Error("Expected the host to play White and the joiner Black")
+	}
+	for !nextPeerEvent(t, joiner).Connected {
+	}
+
+	// The claimed game is no longer listed
+	joinLobby.Update(joinLobby.refresh()())
+	if len(joinLobby.games) != 0 {
+		t.Errorf("Expected no open games after joining, got %+v", joinLobby.games)
+	}
+}
+
+func TestLobbyPrivateGame(t *testing.T) {
+	ts := httptest.NewServer(lobby.NewServer(time.Minute).Handler())
+	defer ts.Close()
+
+	hostLobby := NewLobby(lobby.NewClient(ts.URL), "alex", DefaultSettings())
+	model, _ := hostLobby.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	host, ok := model.(*Game)
+	if !ok {
+		t.Fatalf("Expected hosting to start a game, got error %q", hostLobby.err)
+	}
+	defer host.peer.Close()
+	code := host.invite
+	if code == "" || !strings.Contains(host.netText(), code) {
+		t.Fatalf("Expected the host shown the invite code, got %q", host.netText())
+	}
+
+	// The game isn't listed, but the invite code finds it
+	joinLobby := NewLobby(lobby.NewClient(ts.URL), "sam", DefaultSettings())
+	joinLobby.Update(joinLobby.refresh()())
+	if strings.Contains(joinLobby.View(), "alex") {
+		t.Errorf("Expected the private game unlisted, got %q", joinLobby.View())
+	}
+	joinLobby.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	joinLobby.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(code)})
+	model, _ = joinLobby.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	joiner, ok := model.(*Game)
+	if !ok {
+		t.Fatalf("Expected the invite code to start a game, got error %q", joinLobby.err)
+	}
+	defer joiner.peer.Close()
+	for !nextPeerEvent(t, joiner).Connected {
+	}
+}
@@ -1,10 +1,24 @@
 package game
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sort"
 	"strings"
+	"time"
+
+	"chess-tui/ai_player"
+	"chess-tui/gamedb"
+	"chess-tui/leaderboard"
+	"chess-tui/positions"
+	"chess-tui/tournament"
+	"chess-tui/variant"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
 )
 
 // GameMode represents the type of game
@@ -18,24 +32,215 @@ const (
 
 // Menu represents the game mode selection menu
 type Menu struct {
-	cursor int
-	modes  []string
+	cursor    int
+	modes     []string
+	settings  *Settings
+	generator MoveGenerator
+	ratings   tournament.Ratings
+	glicko    tournament.GlickoRatings // ratings from the game log
+	hints     int                      // the hint budget of consultation games
+	kibitzer  Analyst                  // comments on Human vs Human games, if set
+
+	humanColor chess.Color  // side the human plays against the AI
+	prefs      *Preferences // remembers the choices made, if set
+
+	opponents  []ai_player.Opponent
+	records    map[string]gamedb.Score // the human's score against each opponent
+	opponentAI func(ai_player.Opponent) (MoveGenerator, error)
+	db         *gamedb.DB
+	archive    *gamedb.Archive
+	dataset    *gamedb.Dataset
+	err        string
+
+	dailyPath string // where daily puzzle stats are saved, "" for none
+
+	tutorialPath string // where tutorial progress is saved, "" for none
+	profile      string // whose tutorial progress is shown
+
+	player string             // who is playing, if known
+	rating *tournament.Rating // the player's estimated strength, if rated
+
+	leaderboard func(period string) (leaderboard.Board, error) // loads the instance's leaderboard, if hosted
+
+	startFEN string                 // the position games start from, if not the standard one
+	scramble func() (string, error) // generates the start of Scramble vs AI games
+
+	ctx context.Context // the program's, handed to the games started
 }
 
+// instantBotMode is the menu index of the game against the instant bot
+const instantBotMode = 7
+
+// notationTrainerMode is the menu index of the notation trainer
+const notationTrainerMode = 8
+
+// crazyhouseMode is the menu index of the Crazyhouse game against the AI
+const crazyhouseMode = 9
+
+// atomicMode is the menu index of the Atomic game against the AI
+const atomicMode = 10
+
 // NewMenu creates a new menu
 func NewMenu() *Menu {
+	return NewMenuWithSettings(DefaultSettings())
+}
+
+// NewMenuWithSettings creates a new menu whose games use the given display settings
+func NewMenuWithSettings(settings *Settings) *Menu {
 	return &Menu{
-		cursor: 0,
+		cursor:     0,
+		settings:   settings,
+		ctx:        context.Background(),
+		humanColor: chess.White,
+		hints:      DefaultHintBudget,
 		modes: []string{
 			"Human vs Human",
 			"Human vs AI",
+			"Daily puzzle",
+			"Tutorial",
+			"Statistics",
+			"Scramble vs AI",
+			"Consultation (2 humans + AI advisor)",
+			NewInstantBot(settings.InstantBot).Label(),
+			"Notation trainer",
+			"Crazyhouse vs AI",
+			"Atomic vs AI",
 		},
+		scramble: func() (string, error) {
+			return positions.Scramble(rand.New(rand.NewSource(time.Now().UnixNano())), positions.ScrambleOptions{})
+		},
+	}
+}
+
+// SetPreferences preselects the mode, opponent and color remembered from
+// the last launch, and records the choices made this time in prefs. Call it
+// after SetOpponents.
+func (m *Menu) SetPreferences(prefs *Preferences) {
+	m.prefs = prefs
+	m.humanColor = prefs.HumanColor()
+	if prefs.GameMode() == ModeHumanVsAI {
+		m.cursor = 1
+	} else {
+		m.cursor = 0
+	}
+	if prefs.Opponent == InstantBotName {
+		m.cursor = instantBotMode
+	}
+	for i, opponent := range m.opponents {
+		if opponent.Name == prefs.Opponent {
+			m.cursor = len(m.modes) + i
+		}
+	}
+}
+
+// SetOpponents lists named AI opponents below the game modes, with the
+// human's record against each. newAI creates the move generator for an
+// opponent when a game against it starts.
+func (m *Menu) SetOpponents(opponents []ai_player.Opponent, records map[string]gamedb.Score, newAI func(ai_player.Opponent) (MoveGenerator, error)) {
+	m.opponents = opponents
+	m.records = records
+	m.opponentAI = newAI
+}
+
+// SetDailyPath saves daily puzzle streaks to path
+func (m *Menu) SetDailyPath(path string) {
+	m.dailyPath = path
+}
+
+// SetTutorial saves the tutorial progress of profile to path
+func (m *Menu) SetTutorial(path, profile string) {
+	m.tutorialPath = path
+	m.profile = profile
+}
+
+// SetGameDB records the games started from the menu in db
+func (m *Menu) SetGameDB(db *gamedb.DB) {
+	m.db = db
+}
+
+// SetArchive saves the PGN of the games started from the menu in archive
+func (m *Menu) SetArchive(archive *gamedb.Archive) {
+	m.archive = archive
+}
+
+// SetDataset collects the AI's moves in games started from the menu in
+// dataset as training data
+func (m *Menu) SetDataset(dataset *gamedb.Dataset) {
+	m.dataset = dataset
+}
+
+// SetMoveGenerator makes Human vs AI games use generator instead of the A2A server
+func (m *Menu) SetMoveGenerator(generator MoveGenerator) {
+	m.generator = generator
+}
+
+// SetHintBudget sets the hints the players of a consultation game share
+func (m *Menu) SetHintBudget(hints int) {
+	m.hints = hints
+}
+
+// SetKibitzer has analyst comment on the Human vs Human games started from
+// the menu
+func (m *Menu) SetKibitzer(analyst Analyst) {
+	m.kibitzer = analyst
+}
+
+// SetContext ends the games started from the menu, and their AI requests,
+// with ctx
+func (m *Menu) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetRatings shows benchmarked Elo estimates for the AI models
+func (m *Menu) SetRatings(ratings tournament.Ratings) {
+	m.ratings = ratings
+}
+
+// SetGlicko shows the Glicko-2 ratings from the game log next to the
+// opponents and models
+func (m *Menu) SetGlicko(ratings tournament.GlickoRatings) {
+	m.glicko = ratings
+}
+
+// SetPlayer shows who is playing, with their rating from games against the
+// AI, or nil if they have none yet
+func (m *Menu) SetPlayer(name string, rating *tournament.Rating) {
+	m.player = name
+	m.rating = rating
+}
+
+// SetLeaderboard lists a leaderboard of the instance's players among the
+// modes, loaded with load. Call it before SetPreferences.
+func (m *Menu) SetLeaderboard(load func(period string) (leaderboard.Board, error)) {
+	m.leaderboard = load
+	m.modes = append(m.modes, "Leaderboard")
+}
+
+// SetStartPosition starts the games played from the menu at the position
+// given as FEN instead of the standard one
+func (m *Menu) SetStartPosition(fen string) {
+	m.startFEN = fen
+}
+
+// SetScrambler generates the starting positions of Scramble vs AI games with
+// scramble, for instance to have an engine check they are level
+func (m *Menu) SetScrambler(scramble func() (string, error)) {
+	m.scramble = scramble
+}
+
+// setUp starts a new game at the menu's start position, if there is one
+func (m *Menu) setUp(game *Game) {
+	if m.startFEN == "" {
+		return
+	}
+	if err := game.SetStartPosition(m.startFEN); err != nil {
+		game.err = err.Error()
 	}
 }
 
 // Init initializes the menu
 func (m *Menu) Init() tea.Cmd {
-	return nil
+	return appTitleCmd(m.settings)
 }
 
 // Update handles menu updates
@@ -48,15 +253,96 @@ func (m *Menu) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cursor--
 			}
 		case "down", "j":
-			if m.cursor < len(m.modes)-1 {
+			if m.cursor < len(m.modes)+len(m.opponents)-1 {
 				m.cursor++
 			}
+		case "right", "l", "tab":
+			m.settings.Personality = cyclePersonality(m.settings.Personality, 1)
+		case "left", "shift+tab":
+			m.settings.Personality = cyclePersonality(m.settings.Personality, -1)
+		case "c":
+			m.humanColor = m.humanColor.Other()
 		case "enter":
 			switch m.cursor {
 			case 0:
-				return NewGameWithMode(ModeHumanVsHuman), nil
+				m.remember(ModeHumanVsHuman, "")
+				game := NewGameWithSettings(ModeHumanVsHuman, m.settings)
+				game.SetContext(m.ctx)
+				m.setUp(game)
+				game.SetKibitzer(m.kibitzer)
+				game.SetGameDB(m.db)
+				game.SetArchive(m.archive)
+				return game, tea.Batch(game.titleCmd(), game.clockCmd(), game.kibitzCmd())
 			case 1:
-				return NewGameWithMode(ModeHumanVsAI), nil
+				m.remember(ModeHumanVsAI, "")
+				game := m.newAIGame()
+				m.setUp(game)
+				return game, tea.Batch(game.titleCmd(), game.clockCmd())
+			case 2:
+				daily, err := NewDaily(time.Now(), m.settings, m.dailyPath)
+				if err != nil {
+					m.err = err.Error()
+					return m, nil
+				}
+				return daily, daily.Init()
+			case 3:
+				tutorial, err := NewTutorial(m.settings, m.profile, m.tutorialPath)
+				if err != nil {
+					m.err = err.Error()
+					return m, nil
+				}
+				return tutorial, tutorial.Init()
+			case 4:
+				if m.db == nil {
+					m.err = "no game log to summarize"
+					return m, nil
+				}
+				stats := NewStats(m.db, m.settings.Formatting())
+				return stats, stats.Init()
+			case 5:
+				fen, err := m.scramble()
+				if err != nil {
+					m.err = fmt.Sprintf("failed to scramble a position: %v", err)
+					return m, nil
+				}
+				m.remember(ModeHumanVsAI, "")
+				game := m.newAIGame()
+				if err := game.SetStartPosition(fen); err != nil {
+					game.err = err.Error()
+				}
+				return game, tea.Batch(game.titleCmd(), game.clockCmd())
+			case 6:
+				m.remember(ModeHumanVsHuman, "")
+				game := NewGameWithSettings(ModeHumanVsHuman, m.settings)
+				game.SetContext(m.ctx)
+				m.setUp(game)
+				game.SetAdvisor(m.advisor(), m.hints)
+				game.SetKibitzer(m.kibitzer)
+				game.SetGameDB(m.db)
+				game.SetArchive(m.archive)
+				return game, tea.Batch(game.titleCmd(), game.clockCmd(), game.kibitzCmd())
+			case instantBotMode:
+				m.remember(ModeHumanVsAI, InstantBotName)
+				game := m.newAIGame()
+				game.SetOpponent(ai_player.Opponent{Name: InstantBotName, Avatar: "⚡"}, NewInstantBot(m.settings.InstantBot))
+				m.setUp(game)
+				return game, tea.Batch(game.titleCmd(), game.clockCmd())
+			case notationTrainerMode:
+				trainer := NewNotationTrainer(m.settings)
+				return trainer, trainer.Init()
+			case crazyhouseMode:
+				game := NewVariantGame(variant.Crazyhouse{}, m.advisor(), m.humanColor, m.settings)
+				return game, game.Init()
+			case atomicMode:
+				game := NewVariantGame(variant.Atomic{}, m.advisor(), m.humanColor, m.settings)
+				return game, game.Init()
+			default:
+				if m.cursor < len(m.modes) {
+					// The leaderboard, listed by SetLeaderboard
+					board := NewLeaderboard(m.leaderboard, m)
+					return board, board.Init()
+				}
+				return m.startOpponentGame(m.opponents[m.cursor-len(m.modes)])
 			}
 		case "q", "ctrl+c":
 			return m, tea.Quit
@@ -65,6 +351,29 @@ func (m *Menu) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// advisor returns the AI that consultation games ask for hints: the menu's
+// AI, or the A2A server
+func (m *Menu) advisor() MoveGenerator {
+	if m.generator != nil {
+		return m.generator
+	}
+	return NewAIClient(m.settings.AIServer)
+}
+
+// newAIGame creates a Human vs AI game with the menu's AI and color
+func (m *Menu) newAIGame() *Game {
+	game := NewGameWithSettings(ModeHumanVsAI, m.settings)
+	game.SetContext(m.ctx)
+	if m.generator != nil {
+		game.SetMoveGenerator(m.generator)
+	}
+	game.SetGameDB(m.db)
+	game.SetArchive(m.archive)
+	game.SetDataset(m.dataset)
+	game.SetHumanColor(m.humanColor)
+	return game
+}
+
 // View renders the menu
 func (m *Menu) View() string {
 	var sb strings.Builder
@@ -82,6 +391,10 @@ func (m *Menu) View() string {
 		Render("Select Game Mode")
 	sb.WriteString(subtitle + "\n\n")
 
+	if m.player != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF")).Render(m.playerLine()) + "\n\n")
+	}
+
 	// Menu options
 	for i, mode := range m.modes {
 		cursor := " "
@@ -99,12 +412,180 @@ func (m *Menu) View() string {
 		sb.WriteString(style.Render(cursor+" "+mode) + "\n")
 	}
 
+	// Named opponents with the human's record against each
+	if len(m.opponents) > 0 {
+		sb.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render("Opponents") + "\n")
+	}
+	for i, opponent := range m.opponents {
+		index := len(m.modes) + i
+		cursor := " "
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+		if m.cursor == index {
+			cursor = ">"
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Bold(true)
+		}
+		sb.WriteString(style.Render(cursor+" "+m.opponentLine(opponent)) + "\n")
+	}
+	if m.err != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+m.err) + "\n")
+	}
+
+	// AI personality
+	sb.WriteString("\n")
+	personalityStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF"))
+	sb.WriteString(personalityStyle.Render("AI personality: ◂ "+personalityLabel(m.settings.Personality)+" ▸") + "\n")
+	sb.WriteString(personalityStyle.Render("Play as (vs AI): "+m.humanColor.Name()) + "\n")
+
+	// Estimated strength of benchmarked models
+	if ratings := m.renderRatings(); ratings != "" {
+		sb.WriteString("\n" + ratings)
+	}
+	if ratings := m.renderGlicko(); ratings != "" {
+		sb.WriteString("\n" + ratings)
+	}
+
 	// Instructions
 	sb.WriteString("\n")
 	instructions := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#888888")).
-		Render("Use ↑/↓ or j/k to navigate, Enter to select, q to quit")
+		Render("Use ↑/↓ or j/k to navigate, ←/→ to pick a personality, c to switch color, Enter to select, q to quit")
 	sb.WriteString(instructions)
 
 	return sb.String()
 }
+
+// playerLine names the player and their rating
+func (m *Menu) playerLine() string {
+	if m.rating == nil {
+		return "Playing as " + m.player + " (unrated)"
+	}
+	return fmt.Sprintf("Playing as %s, Elo %d over %d games", m.player, m.rating.Elo, m.rating.Games)
+}
+
+// remember records the chosen mode, opponent and color in the preferences
+func (m *Menu) remember(mode GameMode, opponent string) {
+	if m.prefs != nil {
+		m.prefs.remember(mode, m.humanColor)
+		m.prefs.Opponent = opponent
+	}
+}
+
+// startOpponentGame starts a game against a named opponent, staying on the
+// menu with an error if its AI can't be started
+func (m *Menu) startOpponentGame(opponent ai_player.Opponent) (tea.Model, tea.Cmd) {
+	if m.opponentAI == nil {
+		m.err = "no AI available for " + opponent.Name
+		return m, nil
+	}
+	game := NewGameWithSettings(ModeHumanVsAI, m.settings)
+	game.SetContext(m.ctx)
+	if m.settings.FixedDifficulty {
+		generator, err := m.opponentAI(opponent)
+		if err != nil {
+			m.err = fmt.Sprintf("couldn't start %s: %v", opponent.Name, err)
+			return m, nil
+		}
+		game.SetOpponent(opponent, generator)
+	} else if err := game.SetAdaptiveOpponent(opponent, m.humanScores(opponent.Name), m.opponentAI); err != nil {
+		m.err = fmt.Sprintf("couldn't start %s: %v", opponent.Name, err)
+		return m, nil
+	}
+	m.err = ""
+
+	m.remember(ModeHumanVsAI, opponent.Name)
+	game.SetGameDB(m.db)
+	game.SetArchive(m.archive)
+	game.SetDataset(m.dataset)
+	game.SetHumanColor(m.humanColor)
+	m.setUp(game)
+	return game, tea.Batch(game.titleCmd(), game.clockCmd())
+}
+
+// humanScores returns the human's results against the named opponent from
+// the game database, oldest first
+func (m *Menu) humanScores(opponent string) []float64 {
+	if m.db == nil {
+		return nil
+	}
+	games, err := m.db.Games()
+	if err != nil {
+		slog.Warn("Failed to read game log", "error", err)
+		return nil
+	}
+	return gamedb.HumanScores(games, opponent)
+}
+
+// opponentLine describes an opponent for the menu, e.g.
+// "🦊 Gambit Gus (easy) — 3W 1L 0D — rated 1612 ± 85 — adaptive"
+func (m *Menu) opponentLine(opponent ai_player.Opponent) string {
+	line := opponent.Label()
+	if opponent.Difficulty != "" {
+		line += " (" + opponent.Difficulty + ")"
+	}
+	if score, ok := m.records[opponent.Name]; ok && score.Games() > 0 {
+		line += " — " + score.String()
+	} else {
+		line += " — no games yet"
+	}
+	if rating, ok := m.glicko[opponent.Name]; ok && rating.Games > 0 {
+		line += " — rated " + rating.String()
+	}
+	if !m.settings.FixedDifficulty {
+		line += " — adaptive"
+	}
+	return line
+}
+
+// renderRatings lists the bench Elo estimates, strongest first
+func (m *Menu) renderRatings() string {
+	names := make([]string, 0, len(m.ratings))
+	for name, rating := range m.ratings {
+		if rating.Elo != 0 { // not benched on a dataset only
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return m.ratings[names[i]].Elo > m.ratings[names[j]].Elo
+	})
+
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	var sb strings.Builder
+	sb.WriteString(style.Render("Estimated Elo (bench):") + "\n")
+	for _, name := range names {
+		rating := m.ratings[name]
+		sb.WriteString(style.Render(fmt.Sprintf("  %-5d %s (%d games)", rating.Elo, name, rating.Games)) + "\n")
+	}
+	return sb.String()
+}
+
+// renderGlicko lists the Glicko-2 ratings of the AI players in the game
+// log, strongest first, leaving out the human
+func (m *Menu) renderGlicko() string {
+	names := make([]string, 0, len(m.glicko))
+	for name, rating := range m.glicko {
+		if rating.Games > 0 && name != "Human" && name != m.player {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if m.glicko[names[i]].Rating != m.glicko[names[j]].Rating {
+			return m.glicko[names[i]].Rating > m.glicko[names[j]].Rating
+		}
+		return names[i] < names[j]
+	})
+
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	var sb strings.Builder
+	sb.WriteString(style.Render("Ratings (Glicko-2, from the game log):") + "\n")
+	for _, name := range names {
+		rating := m.glicko[name]
+		sb.WriteString(style.Render(fmt.Sprintf("  %-11s %s (%d games)", rating.String(), name, rating.Games)) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,136 @@
+package game
+
+import (
+	"context"
+	"fmt"
+
+	"chess-tui/ai_player"
+	"chess-tui/variant"
+)
+
+// MoveGenerator produces AI moves for a game. The AIClient asks the A2A
+// server; LocalAI runs an AI player in-process.
+type MoveGenerator interface {
+	GetAIMoveResult(boardState string, gameHistory []string, errorMsg string, playerColor string) (*AIMoveResult, error)
+
+	// SetPersonality selects an ai_player personality preset for the game
+	SetPersonality(name string)
+
+	// SuggestMoves lists candidate moves for the human in teach mode
+	SuggestMoves(boardState string, gameHistory []string, playerColor string) ([]CandidateMove, error)
+}
+
+// LocalAI generates moves in-process with an AI player, for example one
+// backed by a local GGUF model, so no server is needed
+type LocalAI struct {
+	player      *ai_player.AIPlayer
+	drawOffered bool
+}
+
+// NewLocalAI creates a move generator from an AI player configuration
+func NewLocalAI(config *ai_player.Config) (*LocalAI, error) {
+	player, err := ai_player.NewAIPlayerFromConfig(config, "black", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local AI player: %w", err)
+	}
+	if err := player.TestConnection(); err != nil {
+		return nil, fmt.Errorf("failed to start local AI player: %w", err)
+	}
+	return &LocalAI{player: player}, nil
+}
+
+// SetPersonality selects the personality preset the local player uses
+func (l *LocalAI) SetPersonality(name string) {
+	l.player.Personality = name
+}
+
+// SetBullet makes the local player play for speed, as ai_player.Config.Bullet does
+func (l *LocalAI) SetBullet(on bool) {
+	l.player.Bullet = on || l.player.Config().Bullet
+}
+
+// SetContext cancels the local player's backend calls once ctx ends
+func (l *LocalAI) SetContext(ctx context.Context) {
+	l.player.Context = ctx
+}
+
+// SetDrawOffered tells the local player whether it has been offered a draw
+func (l *LocalAI) SetDrawOffered(offered bool) {
+	l.drawOffered = offered
+}
+
+// GetAIMoveResult asks the local AI player for a move, unless it resigns or
+// takes a draw instead. Retries simply ask again, as the player has no way
+// to take the previous error into account.
+func (l *LocalAI) GetAIMoveResult(boardState string, gameHistory []string, errorMsg string, playerColor string) (*AIMoveResult, error) {
+	l.player.Color = playerColor
+
+	decision := l.player.Decide(boardState, aiActions, l.drawOffered)
+	if decision.Action == ai_player.ActionResign || decision.Action == ai_player.ActionAcceptDraw {
+		return &AIMoveResult{Action: decision.Action, ActionReason: decision.Reason}, nil
+	}
+
+	move, err := l.player.GetMove(boardState, gameHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AIMoveResult{
+		Move:             move.Notation,
+		Reasoning:        move.Reasoning,
+		PromptTokens:     move.PromptTokens,
+		CompletionTokens: move.CompletionTokens,
+		Provider:         move.Provider,
+		Votes:            localVotes(move.Votes),
+		Confidence:       move.Confidence,
+		Spare:            move.Spare,
+		Action:           decision.Action,
+		ActionReason:     decision.Reason,
+	}, nil
+}
+
+// GetVariantMove asks the local AI player for a move in a chess variant
+func (l *LocalAI) GetVariantMove(variantName, fen string, gameHistory []string, playerColor string) (*AIMoveResult, error) {
+	v, err := variant.Lookup(variantName)
+	if err != nil {
+		return nil, err
+	}
+	l.player.Color = playerColor
+
+	move, err := l.player.VariantMove(v, fen, gameHistory)
+	if err != nil {
+		return nil, err
+	}
+	return &AIMoveResult{
+		Move:             move.Notation,
+		Reasoning:        move.Reasoning,
+		PromptTokens:     move.PromptTokens,
+		CompletionTokens: move.CompletionTokens,
+		Provider:         move.Provider,
+	}, nil
+}
+
+// SuggestMoves asks the local AI player for candidate moves for the human
+func (l *LocalAI) SuggestMoves(boardState string, gameHistory []string, playerColor string) ([]CandidateMove, error) {
+	l.player.Color = playerColor
+
+	suggestions, err := l.player.SuggestMoves(boardState, gameHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]CandidateMove, len(suggestions))
+	for i, suggestion := range suggestions {
+		candidates[i] = CandidateMove{Move: suggestion.Move, Explanation: suggestion.Explanation}
+	}
+	return candidates, nil
+}
+
+// localVotes copies a vote breakdown from the local AI player
+func localVotes(votes []ai_player.Vote) []Vote {
+	var copied []Vote
+	for _, vote := range votes {
+		copied = append(copied, Vote{Voter: vote.Voter, Move: vote.Move, Weight: vote.Weight, Error: vote.Error})
+	}
+	return copied
+}
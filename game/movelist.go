@@ -0,0 +1,123 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"chess-tui/notation"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// moveListRows is the number of full moves shown in the move panel
+const moveListRows = 10
+
+// sanMoves returns the game's moves in standard algebraic notation
+func (g *Game) sanMoves() []string {
+	return sanMovesOf(g.chessGame)
+}
+
+// sanMovesOf returns a game's moves in standard algebraic notation
+func sanMovesOf(game *chess.Game) []string {
+	positions := game.Positions()
+	moves := game.Moves()
+	sans := make([]string, len(moves))
+	for i, move := range moves {
+		sans[i] = notation.Encode(positions[i], move)
+	}
+	return sans
+}
+
+// formatMove renders a SAN move for display, honoring the figurine setting
+func (g *Game) formatMove(san string, color chess.Color) string {
+	if g.settings.Figurine {
+		return notation.Figurine(san, color)
+	}
+	return san
+}
+
+// formatText renders the moves in a panel's text, such as the AI's
+// reasoning, honoring the figurine setting; the first move is color's
+func (g *Game) formatText(text string, color chess.Color) string {
+	if g.settings.Figurine {
+		return notation.FigurineText(text, color)
+	}
+	return text
+}
+
+// spareMark is shown beside the moves the AI server's spare model answered
+const spareMark = "†"
+
+// recordSpare remembers that the AI's last move came from its server's
+// spare model, if it did
+func (g *Game) recordSpare(result *AIMoveResult) {
+	if !result.Spare {
+		return
+	}
+	if g.spareMoves == nil {
+		g.spareMoves = make(map[int]string)
+	}
+	g.spareMoves[len(g.chessGame.Moves())-1] = result.Provider
+}
+
+// moveMarks returns what is shown beside the move at ply: the AI's
+// confidence and whether the spare model answered
+func (g *Game) moveMarks(ply int) string {
+	marks := g.confidenceMark(ply)
+	if _, ok := g.spareMoves[ply]; ok {
+		marks += spareMark
+	}
+	return marks
+}
+
+// spareNames lists the spare models that answered moves, e.g. "ollama llama3.2:1b"
+func (g *Game) spareNames() string {
+	var names []string
+	for _, name := range g.spareMoves {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// renderMoveList renders the most recent moves as numbered rows
+func (g *Game) renderMoveList() string {
+	sans := g.sanMoves()
+
+	// Make room for the AI's confidence beside its moves
+	width := 8
+	if len(g.confidence) > 0 {
+		width = 13
+	}
+	if len(g.spareMoves) > 0 {
+		width++
+	}
+
+	var rows []string
+	for i := 0; i < len(sans); i += 2 {
+		row := fmt.Sprintf("%3d. %-*s", i/2+1, width, g.formatMove(sans[i], chess.White)+g.moveMarks(i))
+		if i+1 < len(sans) {
+			row += " " + g.formatMove(sans[i+1], chess.Black) + g.moveMarks(i+1)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) > moveListRows {
+		rows = rows[len(rows)-moveListRows:]
+	}
+	if len(rows) == 0 {
+		rows = append(rows, "  No moves yet")
+	}
+	if spares := g.spareNames(); spares != "" {
+		rows = append(rows, lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render(spareMark+" answered by the spare "+spares))
+	}
+
+	title := lipgloss.NewStyle().Bold(true).Render("Moves")
+	return lipgloss.NewStyle().
+		PaddingLeft(3).
+		Render(title + "\n" + strings.Join(rows, "\n"))
+}
@@ -0,0 +1,150 @@
+package game
+
+import (
+	"fmt"
+
+	"chess-tui/netplay"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// peerEventMsg carries a move or connection change from a networked opponent
+type peerEventMsg struct {
+	event netplay.Event
+}
+
+// NewNetworkGame creates a Human vs Human game against the player at the
+// other end of peer
+func NewNetworkGame(peer *netplay.Peer, settings *Settings) *Game {
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+	g.peer = peer
+	g.humanColor = chess.White
+	if peer.Color() == "black" {
+		g.humanColor = chess.Black
+	}
+	g.netStatus = "Connecting…"
+	if settings.Clock != nil && settings.Clock.Limited() {
+		peer.SetClock(settings.Clock)
+	}
+	return g
+}
+
+// NewSpectatorGame creates a game that follows a networked game from the
+// sidelines: the board shows the moves as they are played, and no moves
+// can be made
+func NewSpectatorGame(peer *netplay.Peer, settings *Settings) *Game {
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+	g.peer = peer
+	g.humanColor = chess.NoColor
+	g.netStatus = "Connecting…"
+	return g
+}
+
+// spectating reports whether the game is only watched
+func (g *Game) spectating() bool {
+	return g.peer != nil && g.humanColor == chess.NoColor
+}
+
+// netText describes the connection and who is watching, e.g.
+// "Opponent connected · 👀 3 watching", with a private game's invite code
+// until the opponent has joined
+func (g *Game) netText() string {
+	text := g.netStatus
+	if g.invite != "" && !g.netConnected && len(g.chessGame.Moves()) == 0 {
+		text += " · invite code " + g.invite
+	}
+	if g.watchers == 0 {
+		return text
+	}
+	return fmt.Sprintf("%s · 👀 %d watching", text, g.watchers)
+}
+
+// waitForPeer waits for the next event from the networked opponent
+func (g *Game) waitForPeer() tea.Cmd {
+	if g.peer == nil {
+		return nil
+	}
+	events := g.peer.Events()
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return nil
+		}
+		return peerEventMsg{event: event}
+	}
+}
+
+// applyPeerEvent shows an event from the networked opponent
+func (g *Game) applyPeerEvent(event netplay.Event) {
+	switch event.Kind {
+	case netplay.EventStatus:
+		g.netStatus = event.Status
+		g.netConnected = event.Connected
+		if event.Abandoned {
+			g.adjudicateAbandonment()
+		}
+	case netplay.EventMove:
+		move, err := chess.UCINotation{}.Decode(g.chessGame.Position(), event.Move)
+		if err == nil {
+			err = g.chessGame.Move(move)
+		}
+		if err != nil {
+			g.log.Warn("Opponent's move doesn't fit the board", "move", event.Move, "error", err)
+			g.err = "opponent sent an illegal move " + event.Move + "; the boards will resync on reconnection"
+			return
+		}
+		g.gameHistory = append(g.gameHistory, event.Move)
+		g.clearExplanation()
+		g.publishMove(true, "")
+		// Count the time the opponent reports rather than the time the move
+		// took to arrive; our own clock starts now
+		g.moveTimes[len(g.moveTimes)-1] = event.Elapsed
+		g.updateStatus()
+	case netplay.EventResync:
+		g.resyncFromPeer(event.Moves)
+	case netplay.EventHint:
+		g.applyHintEvent(event)
+	case netplay.EventClock:
+		g.applyClockEvent(event)
+	case netplay.EventPresence:
+		g.watchers = event.Watchers
+	}
+}
+
+// applyClockEvent takes the thinking times the host counts for the moves
+// played so far
+func (g *Game) applyClockEvent(event netplay.Event) {
+	plies := len(g.chessGame.Moves())
+	times := event.Times[:min(len(event.Times), plies)]
+	if len(g.moveTimes) > len(times) {
+		times = append(times, g.moveTimes[len(times):]...)
+	}
+	g.moveTimes = times[:min(len(times), plies)]
+}
+
+// resyncFromPeer replaces the game with the host's moves after the boards
+// disagreed
+func (g *Game) resyncFromPeer(moves []string) {
+	game := chess.NewGame(chess.UseNotation(chess.AlgebraicNotation{}))
+	for _, uci := range moves {
+		move, err := chess.UCINotation{}.Decode(game.Position(), uci)
+		if err == nil {
+			err = game.Move(move)
+		}
+		if err != nil {
+			g.err = "failed to resync with the host: " + err.Error()
+			return
+		}
+	}
+	g.chessGame = game
+	g.gameHistory = append([]string{}, moves...)
+	g.clearExplanation()
+	g.updateStatus()
+	g.status = "Board resynced with the host — " + g.status
+}
+
+// awaitingPeer reports whether it's the networked opponent's turn
+func (g *Game) awaitingPeer() bool {
+	return g.peer != nil && g.chessGame.Position().Turn() != g.humanColor
+}
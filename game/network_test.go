@@ -0,0 +1,180 @@
+package game
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"chess-tui/gamedb"
+	"chess-tui/netplay"
+	"chess-tui/tournament"
+
+	"github.com/notnil/chess"
+)
+
+// nextPeerEvent runs the game's wait for its opponent and applies the result
+func nextPeerEvent(t *testing.T, g *Game) netplay.Event {
+	t.Helper()
+	msg, ok := g.waitForPeer()().(peerEventMsg)
+	if !ok {
+		t.Fatal("Expected an event from the opponent")
+	}
+	g.Update(msg)
+	return msg.event
+}
+
+func TestNetworkGameExchangesMoves(t *testing.T) {
+	hostPeer, err := netplay.Host("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to host: %v", err)
+	}
+	defer hostPeer.Close()
+	joinPeer, err := netplay.Join(hostPeer.Addr())
+	if err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	defer joinPeer.Close()
+
+	host := NewNetworkGame(hostPeer, DefaultSettings())
+	joiner := NewNetworkGame(joinPeer, DefaultSettings())
+	if joiner.humanColor != chess.Black || !joiner.flipped() {
+		t.Error("Expected the joiner to play Black with the board flipped")
+	}
+	for !nextPeerEvent(t, joiner).Connected {
+	}
+
+	joiner.makeMove("e5")
+	if joiner.err == "" || len(joiner.chessGame.Moves()) != 0 {
+		t.Error("Expected the joiner to wait for White's move")
+	}
+
+	host.makeMove("e4")
+	for nextPeerEvent(t, joiner).Kind != netplay.EventMove {
+	}
+	if got := joiner.lastMoveSAN(); got != "e4" {
+		t.Errorf("Expected e4 on the joiner's board, got %q", got)
+	}
+	if !joiner.netConnected {
+		t.Error("Expected the joiner to show the opponent connected")
+	}
+}
+
+func TestNetworkGameResync(t *testing.T) {
+	g := NewGame()
+	g.chessGame.Move(g.chessGame.ValidMoves()[0])
+	g.resyncFromPeer([]string{"d2d4", "d7d5"})
+	if got := g.sanMoves(); len(got) != 2 || got[0] != "d4" || got[1] != "d5" {
+		t.Errorf("Expected the board reset to d4 d5, got %v", got)
+	}
+}
+
+func TestNetworkGamePlaysUnderHostClock(t *testing.T) {
+	hostPeer, err := netplay.Host("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to host: %v", err)
+	}
+	defer hostPeer.Close()
+	settings := DefaultSettings()
+	settings.Clock = &tournament.TimeControl{PerGame: 5 * time.Minute}
+	host := NewNetworkGame(hostPeer, settings)
+
+	joinPeer, err := netplay.Join(hostPeer.Addr())
+	if err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	defer joinPeer.Close()
+	joiner := NewNetworkGame(joinPeer, DefaultSettings())
+	for nextPeerEvent(t, joiner).Kind != netplay.EventClock {
+	}
+	if control, ok := joiner.clockControl(); !ok || control.PerGame != 5*time.Minute {
+		t.Fatalf("Expected the joiner to take the host's 5m clock, got %+v", control)
+	}
+
+	// The joiner counts the time the host reports, not the move's trip. A
+	// little under 3s, so the clock doesn't tick past 4:57 on a slow run.
+	host.lastMoveAt = time.Now().Add(-2700 * time.Millisecond)
+	host.makeMove("e4")
+	for nextPeerEvent(t, joiner).Kind != netplay.EventMove {
+	}
+	if len(joiner.moveTimes) != 1 || joiner.moveTimes[0].Round(time.Second) != 3*time.Second {
+		t.Errorf("Expected the host's 3s for e4, got %v", joiner.moveTimes)
+	}
+	if text := joiner.clockText(); !strings.Contains(text, "White 4:57") {
+		t.Errorf("Expected White's clock down to 4:57, got %q", text)
+	}
+}
+
+func TestNetworkGameAdjudicatesAbandonment(t *testing.T) {
+	for _, test := range []struct {
+		moves       []string // in UCI, the host playing White
+		result      string
+		termination string
+		pgnTag      string
+	}{
+		{[]string{"e2e4"}, "*", "Aborted", "abandoned"},
+		{[]string{"e2e4", "e7e5"}, "1-0", "Abandoned", "abandoned"},
+	} {
+		hostPeer, err := netplay.Host("127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to host: %v", err)
+		}
+		defer hostPeer.Close()
+		host := NewNetworkGame(hostPeer, DefaultSettings())
+		db := gamedb.Open(filepath.Join(t.TempDir(), "games.jsonl"))
+		host.SetGameDB(db)
+		for i, move := range test.moves {
+			if i%2 == 0 {
+				host.makeMove(move[2:])
+			} else {
+				host.applyPeerEvent(netplay.Event{Kind: netplay.EventMove, Move: move})
+			}
+		}
+
+		host.applyPeerEvent(netplay.Event{Kind: netplay.EventStatus, Abandoned: true})
+		records, _ := db.Games()
+		if len(records) != 1 {
+			t.Fatalf("Expected the game recorded after %d moves, got %d records", len(test.moves), len(records))
+		}
+		if got := records[0]; got.Result != test.result || got.Termination != test.termination {
+			t.Errorf("Expected %s by %s after %d moves, got %s by %s", test.result, test.termination, len(test.moves), got.Result, got.Termination)
+		}
+		if pgn := host.PGN(); !strings.Contains(pgn, `[Termination "`+test.pgnTag+`"]`) || !strings.Contains(pgn, `[Result "`+test.result+`"]`) {
+			t.Errorf("Expected the PGN to end %s by %s, got %q", test.result, test.pgnTag, pgn)
+		}
+
+		// Nothing more can be played
+		host.makeMove("d4")
+		if got := len(host.chessGame.Moves()); got != len(test.moves) {
+			t.Errorf("Expected no moves after the game was settled, got %d", got)
+		}
+	}
+}
+
+func TestSpectatorGame(t *testing.T) {
+	hostPeer, err := netplay.Host("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to host: %v", err)
+	}
+	defer hostPeer.Close()
+	watchPeer, err := netplay.Watch(hostPeer.Addr())
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+	defer watchPeer.Close()
+
+	g := NewSpectatorGame(watchPeer, DefaultSettings())
+	g.makeMove("e4")
+	if g.err != "you are watching this game" || len(g.chessGame.Moves()) != 0 {
+		t.Errorf("Expected the spectator not to move, got %q", g.err)
+	}
+	if g.flipped() || !strings.Contains(g.modeText(), "watching") {
+		t.Errorf("Expected an unflipped board and a watching mode, got %q", g.modeText())
+	}
+
+	g.netStatus = "Watching the game"
+	g.applyPeerEvent(netplay.Event{Kind: netplay.EventPresence, Watchers: 3})
+	if got := g.netText(); got != "Watching the game · 👀 3 watching" {
+		t.Errorf("Expected the presence in the connection line, got %q", got)
+	}
+}
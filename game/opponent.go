@@ -0,0 +1,100 @@
+package game
+
+import (
+	"chess-tui/ai_player"
+	"chess-tui/gamedb"
+
+	"github.com/notnil/chess"
+)
+
+// SetOpponent makes the game a match against a named AI opponent, whose
+// moves come from generator. The opponent's own prompt replaces the menu's
+// personality choice.
+func (g *Game) SetOpponent(opponent ai_player.Opponent, generator MoveGenerator) {
+	g.opponent = &opponent
+	g.ai = generator
+	g.bindContext()
+}
+
+// SetGameDB records each finished game in db
+func (g *Game) SetGameDB(db *gamedb.DB) {
+	g.db = db
+}
+
+// SetArchive saves the PGN of each finished game in archive
+func (g *Game) SetArchive(archive *gamedb.Archive) {
+	g.archive = archive
+}
+
+// aiName returns the name the AI plays under
+func (g *Game) aiName() string {
+	if g.opponent != nil {
+		return g.opponent.Name
+	}
+	return "AI"
+}
+
+// playerNames returns the names of the white and black players
+func (g *Game) playerNames() (white, black string) {
+	white, black = "Human", "Human"
+	if g.gameMode == ModeHumanVsAI {
+		if g.settings.PlayerName != "" {
+			white, black = g.settings.PlayerName, g.settings.PlayerName
+		}
+		if g.humanColor == chess.White {
+			black = g.aiName()
+		} else {
+			white = g.aiName()
+		}
+	}
+	return white, black
+}
+
+// recordResult adds the finished game to the game database, on GameEnded
+func (g *Game) recordResult() {
+	if g.db == nil || !g.over() {
+		return
+	}
+
+	white, black := g.playerNames()
+	record := gamedb.Record{
+		ID:          g.id,
+		White:       white,
+		Black:       black,
+		Result:      g.chessGame.Outcome().String(),
+		Termination: g.termination(),
+		StartFEN:    g.startFEN,
+		Moves:       g.sanMoves(),
+		Bookmarks:   g.Bookmarks(),
+	}
+	if g.gameMode == ModeHumanVsAI {
+		record.HumanColor = colorName(g.humanColor)
+		record.Opponent = g.aiName()
+	}
+	if err := g.db.Add(record); err != nil {
+		g.log.Warn("Failed to record game", "error", err)
+	}
+}
+
+// archiveGame saves the finished game's PGN in the archive, on GameEnded
+func (g *Game) archiveGame() {
+	if g.archive == nil || !g.over() {
+		return
+	}
+
+	white, black := g.playerNames()
+	entry := gamedb.ArchiveEntry{White: white, Black: black, Result: g.chessGame.Outcome().String()}
+	if path, err := g.archive.Save(entry, g.PGN()); err != nil {
+		g.log.Warn("Failed to archive game", "error", err)
+	} else {
+		g.log.Debug("Game archived", "path", path)
+	}
+}
+
+// colorName returns "white" or "black"
+func colorName(color chess.Color) string {
+	if color == chess.Black {
+		return "black"
+	}
+	return "white"
+}
@@ -0,0 +1,140 @@
+package game
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chess-tui/ai_player"
+	"chess-tui/gamedb"
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func TestMenuStartsOpponentGame(t *testing.T) {
+	gus := ai_player.Opponent{Name: "Gus", Avatar: "🦊", Difficulty: "easy"}
+	generator := &historyCheckingGenerator{}
+	menu := NewMenu()
+	menu.SetOpponents([]ai_player.Opponent{gus}, map[string]gamedb.Score{"Gus": {Wins: 2, Losses: 1}},
+		func(opponent ai_player.Opponent) (MoveGenerator, error) { return generator, nil })
+	prefs := &Preferences{Opponent: "Gus"}
+	menu.SetPreferences(prefs)
+
+	if view := menu.View(); !strings.Contains(view, "🦊 Gus (easy) — 2W 1L 0D") {
+		t.Errorf("Expected opponent with record in menu, got:\n%s", view)
+	}
+
+	model, _ := menu.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	g, ok := model.(*Game)
+	if !ok {
+		t.Fatalf("Expected Enter to start a game against the preselected opponent, got %T", model)
+	}
+	if g.ai != generator || g.aiName() != "Gus" {
+		t.Errorf("Expected a game against Gus, got %s", g.aiName())
+	}
+	if prefs.Opponent != "Gus" || prefs.Mode != "human-vs-ai" {
+		t.Errorf("Expected Gus to be remembered, got %+v", prefs)
+	}
+}
+
+func TestMenuReportsOpponentError(t *testing.T) {
+	menu := NewMenu()
+	menu.SetOpponents([]ai_player.Opponent{{Name: "Gus"}}, nil,
+		func(opponent ai_player.Opponent) (MoveGenerator, error) { return nil, errors.New("model not found") })
+	for range menu.modes {
+		menu.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+
+	model, _ := menu.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model != menu {
+		t.Fatalf("Expected to stay on the menu, got %T", model)
+	}
+	if view := menu.View(); !strings.Contains(view, "model not found") {
+		t.Errorf("Expected error in menu, got:\n%s", view)
+	}
+}
+
+func TestMenuShowsGlickoRatings(t *testing.T) {
+	menu := NewMenu()
+	menu.SetOpponents([]ai_player.Opponent{{Name: "Gus"}}, nil, nil)
+	menu.SetPlayer("alice", nil)
+	menu.SetGlicko(tournament.GlickoRatings{
+		"Gus":   {Rating: 1612, Deviation: 85, Volatility: 0.06, Games: 12},
+		"llama": {Rating: 1420, Deviation: 120, Volatility: 0.06, Games: 5},
+		"alice": {Rating: 1700, Deviation: 90, Volatility: 0.06, Games: 17},
+	})
+
+	view := menu.View()
+	if !strings.Contains(view, "Gus — no games yet — rated 1612 ± 85") {
+		t.Errorf("Expected Gus's rating next to him, got:\n%s", view)
+	}
+	gus, llama := strings.Index(view, "1612 ± 85   Gus"), strings.Index(view, "1420 ± 120  llama")
+	if gus < 0 || llama < gus {
+		t.Errorf("Expected the AI players listed strongest first, got:\n%s", view)
+	}
+	if strings.Contains(view, "alice (17 games)") {
+		t.Errorf("Expected the player left out of the AI ratings, got:\n%s", view)
+	}
+}
+
+func TestFinishedGameIsRecorded(t *testing.T) {
+	db := gamedb.Open(filepath.Join(t.TempDir(), "games.jsonl"))
+	g := NewGameWithMode(ModeHumanVsAI)
+	g.SetOpponent(ai_player.Opponent{Name: "Gus"}, &historyCheckingGenerator{})
+	g.SetGameDB(db)
+	g.SetHumanColor(chess.Black)
+
+	for _, move := range []string{"f3", "e5", "g4", "Qh4#"} {
+		if err := g.applyMove(move); err != nil {
+			t.Fatalf("Expected %s to be legal, got %v", move, err)
+		}
+		g.updateStatus()
+	}
+	g.updateStatus() // a finished game is only recorded once
+
+	games, err := db.Games()
+	if err != nil {
+		t.Fatalf("Failed to read games: %v", err)
+	}
+	if len(games) != 1 {
+		t.Fatalf("Expected 1 recorded game, got %d", len(games))
+	}
+	game := games[0]
+	if game.White != "Gus" || game.Black != "Human" || game.Result != gamedb.BlackWon {
+		t.Errorf("Expected Gus vs Human 0-1, got %s vs %s %s", game.White, game.Black, game.Result)
+	}
+	if score := gamedb.HeadToHead(games)["Gus"]; score.Wins != 1 {
+		t.Errorf("Expected a win against Gus, got %s", score)
+	}
+	if strings.Join(game.Moves, " ") != "f3 e5 g4 Qh4#" {
+		t.Errorf("Expected the moves in SAN, got %v", game.Moves)
+	}
+}
+
+func TestFinishedGameIsArchived(t *testing.T) {
+	archive := gamedb.OpenArchive(gamedb.ArchivePolicy{Dir: t.TempDir()})
+	g := NewGame()
+	g.SetArchive(archive)
+	for _, move := range []string{"f3", "e5", "g4", "Qh4#"} {
+		g.makeMove(move)
+	}
+
+	entries, err := archive.Entries()
+	if err != nil {
+		t.Fatalf("Failed to read the archive: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Result != gamedb.BlackWon {
+		t.Fatalf("Expected the game in the archive index, got %v", entries)
+	}
+	pgn, err := os.ReadFile(filepath.Join(archive.Dir(), entries[0].Path))
+	if err != nil {
+		t.Fatalf("Expected the archived PGN, got %v", err)
+	}
+	if !strings.Contains(string(pgn), "2. g4 Qh4# 0-1") {
+		t.Errorf("Expected the game's moves in the PGN, got %s", pgn)
+	}
+}
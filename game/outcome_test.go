@@ -0,0 +1,24 @@
+package game
+
+import "testing"
+
+func TestExtractMoveDataOutcome(t *testing.T) {
+	parts := []interface{}{
+		map[string]interface{}{"kind": "text", "text": "Generated move: Qxf7#"},
+		map[string]interface{}{"kind": "data", "data": map[string]interface{}{
+			"move":    "Qxf7#",
+			"outcome": map[string]interface{}{"result": "1-0", "reason": "checkmate", "fen": "r1bqkbnr/pppp1Qpp/2n5/4p3/2B1P3/8/PPPP1PPP/RNB1K1NR b KQkq - 0 4"},
+		}},
+	}
+	result := &AIMoveResult{}
+	extractMoveData(parts, result)
+	if result.Outcome == nil || result.Outcome.Result != "1-0" || result.Outcome.Reason != "checkmate" || result.Outcome.FEN == "" {
+		t.Errorf("Expected the checkmate outcome, got %+v", result.Outcome)
+	}
+
+	result = &AIMoveResult{}
+	extractMoveData(parts[:1], result)
+	if result.Outcome != nil {
+		t.Errorf("Expected no outcome without a data part, got %+v", result.Outcome)
+	}
+}
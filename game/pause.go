@@ -0,0 +1,50 @@
+package game
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pause freezes the game: the move timer stops, the board is hidden so the
+// position can't be studied, and the AI's request in flight is abandoned
+// to be asked again on resume
+func (g *Game) pause() {
+	if g.peer != nil {
+		g.status = "A networked game can't be paused"
+		return
+	}
+	g.paused = true
+	g.pausedAt = time.Now()
+	if g.isAITurn && !g.aiMovePending {
+		g.restartContext()
+	}
+}
+
+// resume picks the game up where pause left it, without counting the time
+// spent paused against the player to move
+func (g *Game) resume() tea.Cmd {
+	g.paused = false
+	g.lastMoveAt = g.lastMoveAt.Add(time.Since(g.pausedAt))
+	switch {
+	case g.aiMovePending:
+		return g.takeAITurn()
+	case g.isAITurn:
+		return g.getAIMove()
+	}
+	return nil
+}
+
+// pauseView is shown instead of the board while the game is paused
+func (g *Game) pauseView() string {
+	var sb strings.Builder
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700")).Render("♔ Chess TUI ♛")
+	sb.WriteString(title + "\n\n")
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00AAFF")).
+		Render("⏸ Paused — press any key to resume") + "\n\n")
+	sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).
+		Render("Paused for "+time.Since(g.pausedAt).Round(time.Second).String()+"; the clock is stopped") + "\n")
+	return sb.String()
+}
@@ -0,0 +1,60 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPauseHidesBoardAndStopsClock(t *testing.T) {
+	g := NewGame()
+	g.lastMoveAt = time.Now().Add(-10 * time.Second)
+
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")})
+	if !g.paused {
+		t.Fatal("Expected the game paused")
+	}
+	view := g.render()
+	if !strings.Contains(view, "Paused") || strings.Contains(view, "Moves") {
+		t.Errorf("Expected the board hidden while paused, got %q", view)
+	}
+
+	// An hour away doesn't count against the player to move
+	g.pausedAt = g.pausedAt.Add(-time.Hour)
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if g.paused {
+		t.Fatal("Expected any key to resume")
+	}
+	if waited := time.Since(g.lastMoveAt); waited > time.Minute {
+		t.Errorf("Expected the pause left off the move's time, got %v", waited)
+	}
+}
+
+func TestPauseSuspendsAIRequest(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	g.SetMoveGenerator(&fakeGenerator{})
+	g.makeMove("e4")
+	if !g.aiMovePending {
+		t.Fatal("Expected the AI's move pending")
+	}
+
+	g.pause()
+	if _, cmd := g.Update(struct{}{}); cmd != nil || !g.aiMovePending {
+		t.Error("Expected no AI request while paused")
+	}
+	if g.resume() == nil || g.aiMovePending {
+		t.Error("Expected the AI asked for its move on resume")
+	}
+
+	// A request in flight is abandoned, and asked again on resume
+	ctx := g.ctx
+	g.pause()
+	if g.ctx == ctx || ctx.Err() == nil {
+		t.Error("Expected the request in flight cancelled")
+	}
+	if g.resume() == nil {
+		t.Error("Expected the AI asked again on resume")
+	}
+}
@@ -0,0 +1,28 @@
+package game
+
+import "chess-tui/ai_player"
+
+// cyclePersonality steps through "no preset" and the ai_player personality
+// presets in menu order
+func cyclePersonality(current string, step int) string {
+	names := []string{""}
+	for _, p := range ai_player.Personalities {
+		names = append(names, p.Name)
+	}
+
+	index := 0
+	for i, name := range names {
+		if name == current {
+			index = i
+		}
+	}
+	return names[(index+step+len(names))%len(names)]
+}
+
+// personalityLabel describes a personality preset for the menu
+func personalityLabel(name string) string {
+	if p, ok := ai_player.PersonalityByName(name); ok {
+		return p.Label + " — " + p.Description
+	}
+	return "Default"
+}
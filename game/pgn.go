@@ -0,0 +1,154 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"chess-tui/locale"
+	"chess-tui/tournament"
+)
+
+// moveNumber returns the full-move number of a ply, counted from zero
+func moveNumber(ply int) string {
+	return strconv.Itoa(ply/2 + 1)
+}
+
+// PGN exports the game with any saved analysis as PGN variations, and
+// bookmarks, annotations and, on the clock, the time left after each move
+// as comments
+func (g *Game) PGN() string {
+	live := g.chessGame
+	if g.analysis != nil {
+		live = g.analysis.live
+	}
+
+	white, black := g.playerNames()
+	result := live.Outcome().String()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[Event \"Casual game\"]\n")
+	fmt.Fprintf(&sb, "[Site \"bubblechess\"]\n")
+	fmt.Fprintf(&sb, "[Date \"%s\"]\n", locale.PGNDate(g.startedAt))
+	fmt.Fprintf(&sb, "[Round \"%s\"]\n", locale.PGNRound(0))
+	fmt.Fprintf(&sb, "[White \"%s\"]\n", white)
+	fmt.Fprintf(&sb, "[Black \"%s\"]\n", black)
+	fmt.Fprintf(&sb, "[Result \"%s\"]\n", result)
+	if termination := g.pgnTermination(); termination != "" {
+		fmt.Fprintf(&sb, "[Termination \"%s\"]\n", termination)
+	}
+	fmt.Fprintf(&sb, "[GameId \"%s\"]\n", g.id)
+	if g.startFEN != "" {
+		fmt.Fprintf(&sb, "[SetUp \"1\"]\n")
+		fmt.Fprintf(&sb, "[FEN \"%s\"]\n", g.startFEN)
+	}
+	sb.WriteString("\n")
+
+	sans := sanMovesOf(live)
+	var clocks []time.Duration
+	if control, ok := g.clockControl(); ok {
+		clocks = g.moveTimes.Clocks(control)
+	}
+	var tokens []string
+	var cursors []*variationNode // analysis nodes at the current main-line position
+	needNumber := true
+	for ply, san := range sans {
+		tokens = append(tokens, numberToken(ply, needNumber)...)
+		tokens = append(tokens, san)
+		needNumber = false
+		if ply < len(clocks) && clocks[ply] >= 0 {
+			tokens = append(tokens, "{"+tournament.ClockComment(clocks[ply])+"}")
+			needNumber = true
+		}
+		for _, bookmark := range g.bookmarks {
+			if bookmark.Ply == ply+1 {
+				tokens = append(tokens, bookmarkComment(bookmark))
+				needNumber = true
+			}
+		}
+		if list := g.annotations[ply+1]; len(list) > 0 {
+			tokens = append(tokens, annotationComment(list))
+			needNumber = true
+		}
+
+		// Saved analysis from this position becomes alternatives to the move played
+		if root, ok := g.variations[ply]; ok {
+			cursors = append(cursors, root)
+		}
+		var next []*variationNode
+		for _, node := range cursors {
+			for _, child := range node.children {
+				if child.move == san {
+					next = append(next, child)
+					continue
+				}
+				tokens = append(tokens, "("+strings.Join(lineTokens(child, ply), " ")+")")
+				needNumber = true
+			}
+		}
+		cursors = next
+	}
+
+	// Analysis of the position the game has reached can't be a variation yet
+	if root, ok := g.variations[len(sans)]; ok {
+		cursors = append(cursors, root)
+	}
+	for _, node := range cursors {
+		for _, child := range node.children {
+			tokens = append(tokens, "{Analysis: "+strings.Join(lineTokens(child, len(sans)), " ")+"}")
+		}
+	}
+
+	tokens = append(tokens, result)
+	sb.WriteString(strings.Join(tokens, " "))
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// numberToken returns the move number to write before the move at ply
+func numberToken(ply int, needNumber bool) []string {
+	if ply%2 == 0 {
+		return []string{moveNumber(ply) + "."}
+	}
+	if needNumber {
+		return []string{moveNumber(ply) + "..."}
+	}
+	return nil
+}
+
+// lineTokens writes the line starting with node, played at ply, nesting
+// alternatives to each later move in parentheses after it
+func lineTokens(node *variationNode, ply int) []string {
+	tokens := append(numberToken(ply, true), node.move)
+	needNumber := false
+	for len(node.children) > 0 {
+		ply++
+		main := node.children[0]
+		tokens = append(tokens, numberToken(ply, needNumber)...)
+		tokens = append(tokens, main.move)
+		needNumber = false
+
+		for _, alternative := range node.children[1:] {
+			tokens = append(tokens, "("+strings.Join(lineTokens(alternative, ply), " ")+")")
+			needNumber = true
+		}
+		node = main
+	}
+	return tokens
+}
+
+// savePGN writes the PGN to a timestamped file in the working directory
+func (g *Game) savePGN() {
+	if g.settings.Remote {
+		g.status = "Saving the PGN isn't available over SSH"
+		return
+	}
+	path := fmt.Sprintf("bubblechess-%s.pgn", time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(path, []byte(g.PGN()), 0644); err != nil {
+		g.err = fmt.Sprintf("failed to save PGN: %v", err)
+		return
+	}
+	g.status = "Saved PGN to " + path
+}
@@ -0,0 +1,165 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// phoneWidth is the width the phone layout fits in
+const phoneWidth = 40
+
+// phoneColumns is the terminal width below which the side-by-side layout
+// doesn't fit and the phone layout is used instead
+const phoneColumns = 60
+
+// DetectPhone reports whether the TUI seems to be running on a phone, in
+// Termux or a terminal too narrow for the board and its panels side by side
+func DetectPhone(columns int) bool {
+	return detectPhone(os.Getenv, columns)
+}
+
+// detectPhone tells from the environment and the terminal's width whether
+// to lay the game out for a phone. A width of 0 means it isn't known.
+func detectPhone(getenv func(string) string, columns int) bool {
+	if getenv("TERMUX_VERSION") != "" || strings.Contains(getenv("PREFIX"), "com.termux") {
+		return true
+	}
+	return columns > 0 && columns < phoneColumns
+}
+
+// phoneView renders the game for a narrow portrait screen: the compact board
+// on top, one page of the panels that sit beside it on a wide screen, a
+// single status line and the input at the bottom
+func (g *Game) phoneView() string {
+	pages := g.phonePages()
+	g.phonePage = (g.phonePage%len(pages) + len(pages)) % len(pages)
+
+	pagerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	var sb strings.Builder
+	sb.WriteString(g.renderCompactBoard() + "\n\n")
+	sb.WriteString(pagerStyle.Render(fmt.Sprintf("[ %d/%d ] — press [ or ] for more", g.phonePage+1, len(pages))) + "\n")
+	sb.WriteString(pages[g.phonePage] + "\n\n")
+	sb.WriteString(g.phoneStatusLine() + "\n")
+	sb.WriteString(g.phoneInputLine())
+	return sb.String()
+}
+
+// phoneInputLine is the input line with shorter prompts
+func (g *Game) phoneInputLine() string {
+	switch {
+	case g.bookmarkInput != nil:
+		return "Note: " + g.bookmarkInput.View()
+	case g.isAITurn:
+		return g.inputLine()
+	}
+	return "Move: " + g.input.View()
+}
+
+// turnPhonePage shows the next page of panels, or the previous one when by
+// is negative; the pages wrap around
+func (g *Game) turnPhonePage(by int) {
+	g.phonePage += by
+}
+
+// phonePages returns the panels of the phone layout, one per page: the move
+// list, what the wide layout shows below the board, whichever side panels
+// have something to show, and the keys
+func (g *Game) phonePages() []string {
+	pages := []string{g.renderMoveList(), g.phoneInfoPage()}
+	chances := g.renderWinProbabilityPanel(phoneWidth - len(" 100%"))
+	if chances != "" {
+		chances = lipgloss.NewStyle().Width(phoneWidth).Render(chances)
+	}
+	for _, panel := range []string{
+		g.renderBookmarkPanel(),
+		g.renderAnnotationPanel(),
+		g.renderTeachPanel(),
+		g.renderKibitzPanel(),
+		g.renderVotePanel(),
+		g.renderExplainPanel(),
+		g.renderHeatmapPanel(),
+		chances,
+		g.renderTimePanel(),
+		g.renderUncertaintyPanel(),
+		g.renderReasoningPanel(),
+	} {
+		if panel != "" {
+			pages = append(pages, panel)
+		}
+	}
+	keysStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Width(phoneWidth)
+	return append(pages, keysStyle.Render(g.helpText()+", [ and ] to page"))
+}
+
+// phoneInfoPage shows the mode, connection, hints and token usage, and the
+// status, accuracy and error in full
+func (g *Game) phoneInfoPage() string {
+	style := lipgloss.NewStyle().Width(phoneWidth)
+	modeStyle := style.Foreground(lipgloss.Color("#00AAFF"))
+	lines := []string{lipgloss.NewStyle().Bold(true).Render("Game"), modeStyle.Render("Mode: " + g.modeText())}
+	if g.peer != nil {
+		lines = append(lines, modeStyle.Render(g.netText()))
+	}
+	if g.consult != nil {
+		lines = append(lines, modeStyle.Render(g.hintsText()))
+	}
+	if clock := g.clockText(); clock != "" {
+		lines = append(lines, modeStyle.Render(clock))
+	}
+	if g.tokenUsage.Moves > 0 {
+		lines = append(lines, modeStyle.Render(g.tokenUsage.Summary(g.settings)))
+	}
+	lines = append(lines, style.Foreground(lipgloss.Color("#00FF00")).Render(g.status))
+	if accuracy := g.renderAccuracy(); accuracy != "" {
+		lines = append(lines, style.Render(accuracy))
+	}
+	if g.err != "" {
+		lines = append(lines, style.Foreground(lipgloss.Color("#FF0000")).Render("Error: "+g.err))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// phoneStatusLine shows the error, or else the status, cut to one line;
+// the info page has them in full
+func (g *Game) phoneStatusLine() string {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
+	text := g.status
+	if g.err != "" {
+		style = style.Foreground(lipgloss.Color("#FF0000"))
+		text = "Error: " + g.err
+	}
+	if len([]rune(text)) > phoneWidth {
+		text = string([]rune(text)[:phoneWidth-1]) + "…"
+	}
+	return style.Render(text)
+}
+
+// renderCompactBoard draws the board two columns a square, with the ranks
+// on the left only, whatever the board style. Highlighted squares keep the
+// first of their bracket cues.
+func (g *Game) renderCompactBoard() string {
+	board := g.chessGame.Position().Board()
+	palette := paletteFor(g.settings.Palette)
+	marks := g.squareMarks()
+	colored := g.boardColored()
+
+	var sb strings.Builder
+	for _, rank := range g.boardRanks() {
+		sb.WriteString(fmt.Sprintf("%d", rank+1))
+		for _, file := range g.boardFiles() {
+			square := chess.Square(rank*8 + file)
+			style, symbol := g.squareStyle(square, board.Piece(square), palette, marks[square], colored)
+			sb.WriteString(style.Render(marks[square].decorate(symbol)[:1] + symbol))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(" ")
+	for _, file := range g.boardFiles() {
+		sb.WriteString(fmt.Sprintf(" %c", 'a'+file))
+	}
+	return sb.String()
+}
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestDetectPhone(t *testing.T) {
+	tests := []struct {
+		env     map[string]string
+		columns int
+		want    bool
+	}{
+		{map[string]string{"TERM": "xterm-256color"}, 120, false},
+		{map[string]string{"TERMUX_VERSION": "0.118.0"}, 120, true},
+		{map[string]string{"PREFIX": "/data/data/com.termux/files/usr"}, 0, true},
+		{map[string]string{}, 45, true},
+		{map[string]string{}, 0, false},
+	}
+	for _, tt := range tests {
+		if got := detectPhone(func(key string) string { return tt.env[key] }, tt.columns); got != tt.want {
+			t.Errorf("%v at %d columns: expected %v, got %v", tt.env, tt.columns, tt.want, got)
+		}
+	}
+}
+
+func TestPhoneView(t *testing.T) {
+	g := NewGameWithSettings(ModeHumanVsHuman, &Settings{Phone: true, BoardStyle: BoardStyleLarge})
+	g.makeMove("e4")
+	g.err = strings.Repeat("a very long error message ", 5)
+
+	view := g.View()
+	lines := strings.Split(view, "\n")
+	if !strings.HasPrefix(lines[0], "8") || !strings.HasPrefix(lines[8], "  a b c") {
+		t.Errorf("Expected the compact board on top, got:\n%s", view)
+	}
+	for _, line := range lines {
+		if width := lipgloss.Width(line); width > phoneWidth {
+			t.Errorf("Expected lines at most %d wide, got %d: %q", phoneWidth, width, line)
+		}
+	}
+	status := lines[len(lines)-2]
+	if !strings.HasPrefix(status, "Error: a very long") || !strings.HasSuffix(status, "…") {
+		t.Errorf("Expected the error cut to one line, got %q", status)
+	}
+	if !strings.HasPrefix(lines[len(lines)-1], "Move: ") {
+		t.Errorf("Expected the input at the bottom, got %q", lines[len(lines)-1])
+	}
+	if !strings.Contains(view, "1. e4") {
+		t.Errorf("Expected the move list on the first page, got:\n%s", view)
+	}
+
+	// ] turns to the game info page, which has the error in full, and [
+	// wraps around to the keys, past White's winning chances
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")})
+	if view := g.View(); !strings.Contains(view, "Mode: Human vs Human") || !strings.Contains(view, "[ 2/4 ]") {
+		t.Errorf("Expected the info page second, got:\n%s", view)
+	}
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("[")})
+	g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("[")})
+	if view := g.View(); !strings.Contains(view, "[q]uit") || !strings.Contains(view, "[ 4/4 ]") {
+		t.Errorf("Expected paging back from the first page to reach the keys, got:\n%s", view)
+	}
+}
@@ -0,0 +1,46 @@
+package game
+
+import (
+	"maps"
+	"slices"
+
+	"chess-tui/events"
+	"chess-tui/plugins"
+)
+
+// The plugins registry, as the game reads it; tests swap in their own
+var (
+	lookupBoardRenderer = plugins.LookupBoardRenderer
+	boardRenderers      = plugins.BoardRenderers
+	commentators        = func() map[string]plugins.Commentator {
+		named := make(map[string]plugins.Commentator)
+		for _, name := range plugins.Commentators() {
+			named[name], _ = plugins.LookupCommentator(name)
+		}
+		return named
+	}
+)
+
+// renderPluginBoard draws the board with the renderer registered as the
+// board style, if there is one
+func (g *Game) renderPluginBoard() (string, bool) {
+	renderer, ok := lookupBoardRenderer(g.settings.BoardStyle)
+	if !ok {
+		return "", false
+	}
+	return renderer.RenderBoard(g.chessGame.Position(), g.flipped()), true
+}
+
+// subscribeCommentators has the registered commentators remark on each
+// move and the end of the game
+func (g *Game) subscribeCommentators() {
+	named := commentators()
+	for _, name := range slices.Sorted(maps.Keys(named)) {
+		commentator := named[name]
+		g.bus.Subscribe(func(event events.Event) {
+			if remark := commentator.Comment(event); remark != "" {
+				g.commentary = "💬 " + name + ": " + remark
+			}
+		}, events.MoveMade, events.GameEnded)
+	}
+}
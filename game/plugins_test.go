@@ -0,0 +1,71 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"chess-tui/events"
+	"chess-tui/plugins"
+
+	"github.com/notnil/chess"
+)
+
+type testRenderer struct{}
+
+func (testRenderer) RenderBoard(position *chess.Position, flipped bool) string {
+	return "test board of " + position.Board().String()
+}
+
+type testCommentator struct{}
+
+func (testCommentator) Comment(event events.Event) string {
+	if event.Kind != events.MoveMade {
+		return ""
+	}
+	return "nice " + event.Move
+}
+
+func TestPluginBoardRenderer(t *testing.T) {
+	defer func(lookup func(string) (plugins.BoardRenderer, bool), names func() []string) {
+		lookupBoardRenderer, boardRenderers = lookup, names
+	}(lookupBoardRenderer, boardRenderers)
+	lookupBoardRenderer = func(name string) (plugins.BoardRenderer, bool) {
+		return testRenderer{}, name == "test-board"
+	}
+	boardRenderers = func() []string { return []string{"test-board"} }
+
+	g := NewGameWithSettings(ModeHumanVsHuman, DefaultSettings())
+	g.settings.BoardStyle = "test-board"
+	if board := g.renderBoard(); !strings.HasPrefix(board, "test board of rnbqkbnr") {
+		t.Errorf("Expected the plugin to draw the board, got %q", board)
+	}
+	if got := nextBoardStyle(BoardStyleLarge, GraphicsNone); got != "test-board" {
+		t.Errorf("Expected the plugin's style after the built-in ones, got %s", got)
+	}
+	if got := nextBoardStyle("test-board", GraphicsNone); got != BoardStyleStandard {
+		t.Errorf("Expected the styles to wrap around, got %s", got)
+	}
+}
+
+func TestPluginCommentator(t *testing.T) {
+	defer func(named func() map[string]plugins.Commentator) { commentators = named }(commentators)
+	commentators = func() map[string]plugins.Commentator {
+		return map[string]plugins.Commentator{"test-commentator": testCommentator{}}
+	}
+
+	g := NewGameWithSettings(ModeHumanVsHuman, DefaultSettings())
+	g.makeMove("e4")
+	if g.commentary != "💬 test-commentator: nice e4" {
+		t.Errorf("Expected the commentator's remark, got %q", g.commentary)
+	}
+	if !strings.Contains(g.View(), "nice e4") {
+		t.Error("Expected the remark to be shown")
+	}
+
+	// The remark's moves follow the figurine setting
+	g.settings.Figurine = true
+	g.makeMove("Nf6")
+	if !strings.Contains(g.View(), "nice ♞f6") {
+		t.Errorf("Expected the remark in figurines, got %q", g.commentary)
+	}
+}
@@ -0,0 +1,102 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/notnil/chess"
+)
+
+// Preferences holds the choices made on the last launch, so the menu can
+// preselect them next time
+type Preferences struct {
+	Mode     string `json:"mode,omitempty"`     // "human-vs-human" or "human-vs-ai"
+	Opponent string `json:"opponent,omitempty"` // named AI opponent last played, if any
+	Color    string `json:"color,omitempty"`    // side the human plays against the AI: "white" or "black"
+	Model    string `json:"model,omitempty"`    // local GGUF model last played against, if any
+	Palette  string `json:"palette,omitempty"`  // board palette last used
+}
+
+// Mode names stored in the preferences file
+const (
+	prefModeHumanVsHuman = "human-vs-human"
+	prefModeHumanVsAI    = "human-vs-ai"
+)
+
+// DefaultPreferencesPath returns the preferences file location in the user's config directory
+func DefaultPreferencesPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "preferences.json"
+	}
+	return filepath.Join(home, ".bubblechess", "preferences.json")
+}
+
+// LoadPreferences loads preferences from a file, returning empty preferences if it doesn't exist
+func LoadPreferences(path string) (*Preferences, error) {
+	if path == "" {
+		path = DefaultPreferencesPath()
+	}
+
+	prefs := &Preferences{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return prefs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preferences file: %w", err)
+	}
+	if err := json.Unmarshal(data, prefs); err != nil {
+		return nil, fmt.Errorf("failed to decode preferences file: %w", err)
+	}
+	return prefs, nil
+}
+
+// SavePreferences saves preferences to a file
+func SavePreferences(prefs *Preferences, path string) error {
+	if path == "" {
+		path = DefaultPreferencesPath()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create preferences directory: %w", err)
+	}
+	data, err := json.MarshalIndent(prefs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode preferences: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write preferences file: %w", err)
+	}
+	return nil
+}
+
+// GameMode returns the remembered game mode, or Human vs Human
+func (p *Preferences) GameMode() GameMode {
+	if p.Mode == prefModeHumanVsAI {
+		return ModeHumanVsAI
+	}
+	return ModeHumanVsHuman
+}
+
+// HumanColor returns the remembered side for the human, or White
+func (p *Preferences) HumanColor() chess.Color {
+	if p.Color == "black" {
+		return chess.Black
+	}
+	return chess.White
+}
+
+// remember records the mode and color of a game started from the menu
+func (p *Preferences) remember(mode GameMode, color chess.Color) {
+	p.Mode = prefModeHumanVsHuman
+	if mode == ModeHumanVsAI {
+		p.Mode = prefModeHumanVsAI
+	}
+	p.Color = "white"
+	if color == chess.Black {
+		p.Color = "black"
+	}
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func TestPreferencesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preferences.json")
+
+	prefs, err := LoadPreferences(path)
+	if err != nil {
+		t.Fatalf("Expected missing file to load as empty preferences, got %v", err)
+	}
+	if prefs.GameMode() != ModeHumanVsHuman || prefs.HumanColor() != chess.White {
+		t.Errorf("Expected Human vs Human as White by default, got %v as %v", prefs.GameMode(), prefs.HumanColor())
+	}
+
+	prefs.remember(ModeHumanVsAI, chess.Black)
+	prefs.Palette = "colorblind"
+	if err := SavePreferences(prefs, path); err != nil {
+		t.Fatalf("Failed to save preferences: %v", err)
+	}
+
+	loaded, err := LoadPreferences(path)
+	if err != nil {
+		t.Fatalf("Failed to load preferences: %v", err)
+	}
+	if loaded.GameMode() != ModeHumanVsAI {
+		t.Errorf("Expected Human vs AI, got %v", loaded.GameMode())
+	}
+	if loaded.HumanColor() != chess.Black {
+		t.Errorf("Expected Black, got %v", loaded.HumanColor())
+	}
+	if loaded.Palette != "colorblind" {
+		t.Errorf("Expected colorblind palette, got %s", loaded.Palette)
+	}
+}
+
+func TestMenuPreselectsPreferences(t *testing.T) {
+	prefs := &Preferences{Mode: "human-vs-ai", Color: "black"}
+	menu := NewMenu()
+	menu.SetPreferences(prefs)
+
+	model, _ := menu.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	g, ok := model.(*Game)
+	if !ok {
+		t.Fatalf("Expected Enter to start a game, got %T", model)
+	}
+	if g.gameMode != ModeHumanVsAI {
+		t.Errorf("Expected Human vs AI, got %v", g.gameMode)
+	}
+	if g.humanColor != chess.Black {
+		t.Errorf("Expected human to play Black, got %v", g.humanColor)
+	}
+	if !g.isAITurn || !g.aiMovePending {
+		t.Error("Expected the AI to move first when the human plays Black")
+	}
+}
+
+func TestMenuRemembersChoices(t *testing.T) {
+	prefs := &Preferences{}
+	menu := NewMenu()
+	menu.SetPreferences(prefs)
+
+	menu.Update(tea.KeyMsg{Type: tea.KeyDown})
+	menu.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	menu.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if prefs.Mode != "human-vs-ai" || prefs.Color != "black" {
+		t.Errorf("Expected human-vs-ai as black to be remembered, got %s as %s", prefs.Mode, prefs.Color)
+	}
+}
@@ -0,0 +1,73 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"chess-tui/notation"
+	"chess-tui/positions"
+
+	"github.com/notnil/chess"
+)
+
+// Puzzle is a position with a forced winning line
+type Puzzle struct {
+	Title    string
+	FEN      string
+	Solution []string // SAN; the solver's moves alternate with the opponent's replies
+}
+
+// puzzles is the bundled daily puzzle set. Every line ends in checkmate, and
+// replies are forced, so the solver never faces a choice of defence.
+var puzzles = []Puzzle{
+	{Title: "Back-rank mate", FEN: positions.MustGet("back-rank").FEN, Solution: positions.MustGet("back-rank").Line},
+	{Title: "Scholar's mate", FEN: "r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", Solution: []string{"Qxf7#"}},
+	{Title: "Fool's mate", FEN: "rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq g3 0 2", Solution: []string{"Qh4#"}},
+	{Title: "Smothered mate", FEN: positions.MustGet("smothered").FEN, Solution: positions.MustGet("smothered").Line},
+	{Title: "Lawnmower", FEN: "7k/R7/8/8/8/8/8/1R4K1 w - - 0 1", Solution: []string{"Rb8#"}},
+	{Title: "Remove the guard", FEN: "2r3k1/5ppp/8/8/8/8/5PPP/2R1R1K1 w - - 0 1", Solution: []string{"Rxc8#"}},
+	{Title: "Queen to the back rank", FEN: "6k1/pp4pp/8/8/8/8/5PPP/4Q1K1 w - - 0 1", Solution: []string{"Qe8#"}},
+	{Title: "Arabian mate", FEN: "7k/7p/5N2/8/8/8/8/6RK w - - 0 1", Solution: []string{"Rg8#"}},
+	{Title: "King and rook", FEN: "5k2/8/5K2/8/8/8/8/7R w - - 0 1", Solution: []string{"Rh8#"}},
+	{Title: "Knight check, bishop mate", FEN: "r2qkb1r/pp2nppp/3p4/2pNN1B1/2BnP3/3P4/PPP2PPP/R2bK2R w KQkq - 1 10", Solution: []string{"Nf6+", "gxf6", "Bxf7#"}},
+	{Title: "Doubled rooks", FEN: "6k1/5ppp/4r3/8/8/8/5PPP/3RR1K1 w - - 0 1", Solution: []string{"Rd8+", "Re8", "Rdxe8#"}},
+	{Title: "Philidor's legacy", FEN: "5r1k/6pp/7N/3Q4/8/8/6PP/6K1 w - - 0 1", Solution: []string{"Qg8+", "Rxg8", "Nf7#"}},
+}
+
+// dailyEpoch is the date of puzzle #1
+var dailyEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// DailyPuzzle returns the puzzle for a date and its number, counting from
+// puzzle #1 on 1 January 2024. Everyone gets the same puzzle on the same day.
+func DailyPuzzle(date time.Time) (Puzzle, int) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	n := int(day.Sub(dailyEpoch).Hours() / 24)
+	index := ((n % len(puzzles)) + len(puzzles)) % len(puzzles)
+	return puzzles[index], n + 1
+}
+
+// newGame returns a game at the puzzle's position
+func (p Puzzle) newGame() (*chess.Game, error) {
+	fen, err := chess.FEN(p.FEN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid puzzle position: %w", err)
+	}
+	return chess.NewGame(fen, chess.UseNotation(chess.AlgebraicNotation{})), nil
+}
+
+// solves reports whether a move is the solution's move at ply. On the last
+// move any checkmate is accepted, since there may be more than one.
+func (p Puzzle) solves(game *chess.Game, ply int, move *chess.Move) bool {
+	expected, err := notation.Decode(game.Position(), p.Solution[ply])
+	if err == nil && expected.String() == move.String() {
+		return true
+	}
+	if ply != len(p.Solution)-1 {
+		return false
+	}
+	after := game.Clone()
+	if err := after.Move(move); err != nil {
+		return false
+	}
+	return after.Method() == chess.Checkmate
+}
@@ -0,0 +1,52 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+func TestPuzzleSolutionsMate(t *testing.T) {
+	for _, puzzle := range puzzles {
+		game, err := puzzle.newGame()
+		if err != nil {
+			t.Errorf("%s: %v", puzzle.Title, err)
+			continue
+		}
+		for ply, san := range puzzle.Solution {
+			move, err := notation.Decode(game.Position(), san)
+			if err != nil {
+				t.Errorf("%s: move %d %s is illegal: %v", puzzle.Title, ply+1, san, err)
+				break
+			}
+			if ply%2 == 1 && len(game.ValidMoves()) != 1 {
+				t.Errorf("%s: reply %s isn't forced", puzzle.Title, san)
+			}
+			game.Move(move)
+		}
+		if game.Method() != chess.Checkmate {
+			t.Errorf("%s: solution doesn't end in checkmate", puzzle.Title)
+		}
+	}
+}
+
+func TestDailyPuzzleIsStablePerDay(t *testing.T) {
+	morning := time.Date(2026, time.October, 17, 8, 0, 0, 0, time.Local)
+	evening := time.Date(2026, time.October, 17, 23, 0, 0, 0, time.Local)
+	first, n := DailyPuzzle(morning)
+	second, m := DailyPuzzle(evening)
+	if first.FEN != second.FEN || n != m {
+		t.Errorf("Expected the same puzzle all day, got #%d and #%d", n, m)
+	}
+
+	next, k := DailyPuzzle(morning.AddDate(0, 0, 1))
+	if k != n+1 || next.FEN == first.FEN {
+		t.Errorf("Expected a new puzzle the next day, got #%d", k)
+	}
+	if _, number := DailyPuzzle(dailyEpoch); number != 1 {
+		t.Errorf("Expected puzzle #1 on the epoch, got #%d", number)
+	}
+}
@@ -0,0 +1,70 @@
+package game
+
+import (
+	"fmt"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// aiStatusMsg carries a status update from the AI server to the TUI
+type aiStatusMsg struct {
+	status AIStatus
+	feed   *aiStatusFeed
+}
+
+// aiStatusFeed passes the server's status updates for one AI move from the
+// request goroutine to the TUI. Only the latest update is kept.
+type aiStatusFeed struct {
+	mu      sync.Mutex
+	updates chan AIStatus
+	closed  bool
+}
+
+// newAIStatusFeed creates a feed for one AI move
+func newAIStatusFeed() *aiStatusFeed {
+	return &aiStatusFeed{updates: make(chan AIStatus, 1)}
+}
+
+// send delivers an update, replacing one the TUI hasn't picked up yet
+func (f *aiStatusFeed) send(status AIStatus) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	select {
+	case <-f.updates:
+	default:
+	}
+	f.updates <- status
+}
+
+// close ends the feed once the move is done
+func (f *aiStatusFeed) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.closed {
+		f.closed = true
+		close(f.updates)
+	}
+}
+
+// wait returns a command that delivers the feed's next update
+func (f *aiStatusFeed) wait() tea.Cmd {
+	return func() tea.Msg {
+		status, ok := <-f.updates
+		if !ok {
+			return nil
+		}
+		return aiStatusMsg{status: status, feed: f}
+	}
+}
+
+// aiWaitingText describes what the AI is doing while the human waits
+func (g *Game) aiWaitingText() string {
+	if g.aiStatus.Queued() {
+		return fmt.Sprintf("AI server busy, queued at position %d...", g.aiStatus.QueuePosition)
+	}
+	return "AI is thinking..."
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAIClientReportsQueuePosition(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"jsonrpc":"2.0","id":1,"result":{"kind":"status-update","status":{"state":"submitted"},"metadata":{"queue_position":2},"final":false}}` + "\n\n"))
+		w.Write([]byte(`data: {"jsonrpc":"2.0","id":1,"result":{"kind":"status-update","status":{"state":"working"},"final":false}}` + "\n\n"))
+		w.Write([]byte(`data: {"jsonrpc":"2.0","id":1,"result":{"kind":"message","parts":[{"kind":"text","text":"Generated move: e5"}]}}` + "\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewAIClient(server.URL)
+	var statuses []AIStatus
+	client.SetStatusHandler(func(status AIStatus) { statuses = append(statuses, status) })
+
+	move, err := client.GetAIMove("board", []string{"e4"}, "black")
+	if err != nil {
+		t.Fatalf("Expected move, got error %v", err)
+	}
+	if move != "e5" {
+		t.Errorf("Expected e5, got %s", move)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 status updates, got %d", len(statuses))
+	}
+	if !statuses[0].Queued() || statuses[0].QueuePosition != 2 {
+		t.Errorf("Expected queued at position 2, got %+v", statuses[0])
+	}
+	if statuses[1].Queued() || statuses[1].State != "working" {
+		t.Errorf("Expected working status, got %+v", statuses[1])
+	}
+}
+
+func TestAIClientFallsBackWithoutStreaming(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request JSONRPCRequest
+		json.Unmarshal(body, &request)
+		methods = append(methods, request.Method)
+		if request.Method != "message/send" {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"parts":[{"kind":"text","text":"Generated move: e5"}]}}`))
+	}))
+	defer server.Close()
+
+	client := NewAIClient(server.URL)
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetAIMove("board", []string{"e4"}, "black"); err != nil {
+			t.Fatalf("Expected move, got error %v", err)
+		}
+	}
+	expected := []string{"message/stream", "message/send", "message/send"}
+	if len(methods) != len(expected) {
+		t.Fatalf("Expected methods %v, got %v", expected, methods)
+	}
+	for i := range expected {
+		if methods[i] != expected[i] {
+			t.Errorf("Expected methods %v, got %v", expected, methods)
+			break
+		}
+	}
+}
+
+func TestAIWaitingTextShowsQueue(t *testing.T) {
+	g := NewGame()
+	if got := g.aiWaitingText(); got != "AI is thinking..." {
+		t.Errorf("Expected thinking text, got %q", got)
+	}
+	g.aiStatus = AIStatus{State: "submitted", QueuePosition: 3}
+	if got := g.aiWaitingText(); got != "AI server busy, queued at position 3..." {
+		t.Errorf("Expected queue position text, got %q", got)
+	}
+}
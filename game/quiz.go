@@ -0,0 +1,252 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"chess-tui/notation"
+	"chess-tui/tournament"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// quizMoments is how many critical positions the key move quiz asks about
+const quizMoments = 3
+
+// KeyMoment is a critical position of a game: the one before a move that
+// swung the winning chances the most
+type KeyMoment struct {
+	Ply    int // the number of moves played to reach the position
+	FEN    string
+	Played string // the move played in the game, in SAN
+	Best   string // the built-in engine's move, in SAN
+
+	// White's chances before and after the move played
+	Before, After float64
+}
+
+// keyMoments finds the positions before the moves of game that swung the
+// winning chances the most, at most quizMoments of them in game order
+func keyMoments(game *chess.Game) []KeyMoment {
+	chances := winProbabilities(game)
+	var plies []int
+	for ply := range game.Moves() {
+		if chances[ply+1] != chances[ply] {
+			plies = append(plies, ply)
+		}
+	}
+	sort.SliceStable(plies, func(i, j int) bool {
+		return math.Abs(chances[plies[i]+1]-chances[plies[i]]) > math.Abs(chances[plies[j]+1]-chances[plies[j]])
+	})
+	if len(plies) > quizMoments {
+		plies = plies[:quizMoments]
+	}
+	sort.Ints(plies)
+
+	positions := game.Positions()
+	var moments []KeyMoment
+	for _, ply := range plies {
+		position := positions[ply]
+		best, err := tournament.FallbackMove(position)
+		if err != nil {
+			continue
+		}
+		moments = append(moments, KeyMoment{
+			Ply:    ply,
+			FEN:    position.String(),
+			Played: notation.Encode(position, game.Moves()[ply]),
+			Best:   notation.Encode(position, best),
+			Before: chances[ply],
+			After:  chances[ply+1],
+		})
+	}
+	return moments
+}
+
+// Quiz is the screen that asks what the player would play in the critical
+// positions of a finished game
+type Quiz struct {
+	game    *Game // shows the position asked about
+	input   textinput.Model
+	moments []KeyMoment
+	current int
+	parent  tea.Model // the screen to go back to, or nil to quit
+
+	answered bool // the current position has been answered
+	correct  int
+	message  string
+	done     bool
+	err      string
+}
+
+// NewQuiz quizzes the player on moments. Leaving it returns to parent, or
+// quits if it is nil.
+func NewQuiz(moments []KeyMoment, settings *Settings, parent tea.Model) *Quiz {
+	input := textinput.New()
+	input.Placeholder = "e.g. Nf3"
+	input.Focus()
+	input.CharLimit = 10
+	input.Width = 20
+
+	q := &Quiz{
+		game:    NewGameWithSettings(ModeHumanVsHuman, settings),
+		input:   input,
+		moments: moments,
+		parent:  parent,
+	}
+	q.show()
+	return q
+}
+
+// show sets up the board for the current position
+func (q *Quiz) show() {
+	option, err := chess.FEN(q.moments[q.current].FEN)
+	if err != nil {
+		q.err = err.Error()
+		return
+	}
+	q.game.chessGame = chess.NewGame(option)
+	q.game.humanColor = q.game.chessGame.Position().Turn()
+}
+
+// Init initializes the quiz screen
+func (q *Quiz) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles the player's answers
+func (q *Quiz) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "ctrl+c":
+			return q, tea.Quit
+		case "esc":
+			return q.leave()
+		case "q":
+			if q.done || q.answered {
+				return q.leave()
+			}
+		case "enter":
+			switch {
+			case q.answered:
+				q.next()
+			case !q.done && q.input.Value() != "":
+				q.answer(strings.TrimSpace(q.input.Value()))
+				q.input.SetValue("")
+			}
+			return q, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	if !q.done && !q.answered {
+		q.input, cmd = q.input.Update(msg)
+	}
+	return q, cmd
+}
+
+// leave goes back to the game, or quits if the quiz was opened on its own
+func (q *Quiz) leave() (tea.Model, tea.Cmd) {
+	if q.parent != nil {
+		return q.parent, nil
+	}
+	return q, tea.Quit
+}
+
+// answer compares the player's move with the best one
+func (q *Quiz) answer(input string) {
+	q.err = ""
+	position := q.game.chessGame.Position()
+	move, err := notation.Decode(position, input)
+	if err != nil {
+		q.err = notation.Explain(err)
+		return
+	}
+
+	moment := q.moments[q.current]
+	san := notation.Encode(position, move)
+	switch {
+	case san == moment.Best:
+		q.correct++
+		q.message = "✓ " + san + " is the best move."
+	case san == moment.Played:
+		q.message = fmt.Sprintf("✗ %s is what was played; %s was better.", san, moment.Best)
+	default:
+		q.message = fmt.Sprintf("✗ The best move was %s.", moment.Best)
+	}
+	q.answered = true
+}
+
+// next moves on to the next position, or ends the quiz after the last
+func (q *Quiz) next() {
+	q.answered = false
+	q.message = ""
+	if q.current+1 >= len(q.moments) {
+		q.done = true
+		return
+	}
+	q.current++
+	q.show()
+}
+
+// Score returns how many of the positions the player found the best move in
+func (q *Quiz) Score() (correct, total int) {
+	return q.correct, len(q.moments)
+}
+
+// View renders the quiz
+func (q *Quiz) View() string {
+	var sb strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700")).
+		Render(fmt.Sprintf("♔ Key Moves — %d of %d ♛", q.current+1, len(q.moments)))
+	sb.WriteString(title + "\n\n")
+	sb.WriteString(q.game.renderBoard() + "\n\n")
+
+	infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	if q.done {
+		sb.WriteString(infoStyle.Render(fmt.Sprintf("You found %d of %d key moves.", q.correct, len(q.moments))) + "\n\n")
+		sb.WriteString(helpStyle.Render("Press q to go back"))
+		return sb.String()
+	}
+
+	moment := q.moments[q.current]
+	number := fmt.Sprintf("%d.", moment.Ply/2+1)
+	if moment.Ply%2 == 1 {
+		number += ".."
+	}
+	sb.WriteString(infoStyle.Render(fmt.Sprintf("Move %s %s to play — what would you play here?", number, q.game.humanColor.Name())) + "\n")
+	if q.answered {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Render(q.message) + "\n")
+		sb.WriteString(infoStyle.Render(fmt.Sprintf("In the game: %s %s, White's chances %.0f%% → %.0f%%",
+			number, moment.Played, 100*moment.Before, 100*moment.After)) + "\n")
+	}
+	if q.err != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+q.err) + "\n")
+	}
+
+	if q.answered {
+		sb.WriteString("\n" + helpStyle.Render("Press Enter for the next position, q to go back"))
+	} else {
+		sb.WriteString("\nYour move: " + q.input.View() + "\n\n")
+		sb.WriteString(helpStyle.Render("Enter a move in algebraic notation, esc to go back"))
+	}
+	return sb.String()
+}
+
+// startQuiz opens the key move quiz on the finished game
+func (g *Game) startQuiz() (tea.Model, tea.Cmd) {
+	moments := keyMoments(g.chessGame)
+	if len(moments) == 0 {
+		g.status = "No key moments in this game: the chances never changed"
+		return g, nil
+	}
+	quiz := NewQuiz(moments, g.settings, g)
+	return quiz, quiz.Init()
+}
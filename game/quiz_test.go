@@ -0,0 +1,80 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeyMoments(t *testing.T) {
+	g := NewGame()
+	for _, move := range []string{"e4", "Nf6", "d3", "Nxe4", "dxe4", "e5", "f3", "Qh4+", "g3", "Qxg3+", "hxg3", "d6"} {
+		g.makeMove(move)
+	}
+
+	moments := keyMoments(g.chessGame)
+	if len(moments) != quizMoments {
+		t.Fatalf("Expected %d key moments, got %+v", quizMoments, moments)
+	}
+	var played []string
+	for _, moment := range moments {
+		played = append(played, moment.Played)
+	}
+	// The captures, in game order; the quiet moves don't change the chances
+	if strings.Join(played, " ") != "Nxe4 dxe4 hxg3" {
+		t.Errorf("Expected the knight trade and the queen capture, got %v", played)
+	}
+	if moments[2].Ply != 10 || moments[2].Best != "hxg3" || moments[2].After <= moments[2].Before {
+		t.Errorf("Expected taking the queen to be best and to help White, got %+v", moments[2])
+	}
+}
+
+func TestQuizAfterGame(t *testing.T) {
+	g := NewGame()
+	for _, move := range []string{"f3", "e5", "g4"} {
+		g.makeMove(move)
+	}
+	if model, _ := g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")}); model != g {
+		t.Fatal("Expected no quiz while the game is going")
+	}
+	g.makeMove("Qh4#")
+
+	model, _ := g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	quiz, ok := model.(*Quiz)
+	if !ok {
+		t.Fatalf("Expected k to open the quiz, got %T", model)
+	}
+	if view := quiz.View(); !strings.Contains(view, "Key Moves — 1 of 1") || !strings.Contains(view, "Move 2... Black to play") {
+		t.Errorf("Expected the position before the mate, got:\n%s", view)
+	}
+
+	quiz.input.SetValue("Qh4")
+	quiz.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if view := quiz.View(); !strings.Contains(view, "✓ Qh4# is the best move") || !strings.Contains(view, "In the game: 2... Qh4#") {
+		t.Errorf("Expected the answer marked right, got:\n%s", view)
+	}
+	quiz.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if correct, total := quiz.Score(); correct != 1 || total != 1 || !strings.Contains(quiz.View(), "You found 1 of 1 key moves") {
+		t.Errorf("Expected 1 of 1, got %d of %d", correct, total)
+	}
+	if model, _ := quiz.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); model != g {
+		t.Errorf("Expected q to go back to the game, got %T", model)
+	}
+}
+
+func TestQuizWrongAnswer(t *testing.T) {
+	quiz := NewQuiz([]KeyMoment{{Ply: 3, FEN: "rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq - 0 2", Played: "Nc6", Best: "Qh4#"}}, DefaultSettings(), nil)
+	quiz.input.SetValue("Nc6")
+	quiz.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if view := quiz.View(); !strings.Contains(view, "✗ Nc6 is what was played; Qh4# was better") {
+		t.Errorf("Expected the better move shown, got:\n%s", view)
+	}
+
+	quiz.answered = false
+	quiz.input.SetValue("Kd7")
+	quiz.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if quiz.err == "" || quiz.answered {
+		t.Errorf("Expected an illegal move to be refused, got %q", quiz.message)
+	}
+}
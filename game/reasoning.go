@@ -0,0 +1,27 @@
+package game
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// reasoningPanelWidth is the wrap width of the expanded reasoning panel
+const reasoningPanelWidth = 60
+
+// renderReasoningPanel renders the collapsible "why" panel for the AI's last move
+func (g *Game) renderReasoningPanel() string {
+	if g.aiReasoning == "" {
+		return ""
+	}
+
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF"))
+	if !g.showReasoning {
+		return headerStyle.Render("▸ Why did the AI play that? [w]")
+	}
+
+	body := lipgloss.NewStyle().
+		Width(reasoningPanelWidth).
+		PaddingLeft(2).
+		Foreground(lipgloss.Color("#AAAAAA")).
+		Render(g.formatText(g.aiReasoning, g.chessGame.Position().Turn().Other()))
+	return headerStyle.Render("▾ Why did the AI play that? [w]") + "\n" + body
+}
@@ -0,0 +1,24 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReasoningPanelFigurines(t *testing.T) {
+	g := NewGameWithSettings(ModeHumanVsAI, &Settings{Figurine: true})
+	g.makeMove("e4")
+	g.makeMove("Nc6")
+	g.aiReasoning = "Nc6 develops and eyes d4; Bb4 comes next."
+	g.showReasoning = true
+
+	panel := g.renderReasoningPanel()
+	if !strings.Contains(panel, "♞c6 develops") || !strings.Contains(panel, "♝b4 comes") {
+		t.Errorf("Expected the reasoning in Black's figurines, got %q", panel)
+	}
+
+	g.settings.Figurine = false
+	if panel := g.renderReasoningPanel(); !strings.Contains(panel, "Nc6 develops") {
+		t.Errorf("Expected the reasoning in letters, got %q", panel)
+	}
+}
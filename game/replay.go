@@ -0,0 +1,617 @@
+package game
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"chess-tui/gamedb"
+	"chess-tui/notation"
+	"chess-tui/tournament"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// replayInterval is the time between moves at 1x speed
+const replayInterval = time.Second
+
+// replaySpeeds are the playback speeds, as multiples of one move a second
+var replaySpeeds = []float64{0.5, 1, 2, 4, 8}
+
+// replayTickMsg advances auto-play. Ticks from before a pause or a change of
+// speed carry an old generation and are ignored.
+type replayTickMsg struct {
+	generation int
+}
+
+// replayMoveMsg carries a move played in a game being watched live
+type replayMoveMsg struct {
+	move string
+	ok   bool // false once the game being watched has no more moves
+}
+
+// Replay steps through a finished game, or follows one being played, with
+// auto-play at a choice of speeds
+type Replay struct {
+	title string
+	start string   // the FEN the game started from, "" for the standard position
+	moves []string // the game's moves in SAN
+	ply   int      // how many moves are shown on the board
+	game  *Game    // draws the board at the current ply
+
+	playing    bool
+	speed      int // index into replaySpeeds
+	generation int // bumped to stop the running ticker
+	ticking    bool
+	blurred    bool // the terminal is in the background, so auto-play holds
+
+	live <-chan string // moves of a game being watched, if any
+
+	jumping bool // the move-number prompt is open
+	input   textinput.Model
+	search  search // finds moves by SAN or positions by FEN fragment
+	notice  string // what the last key did, e.g. where a screenshot went
+	err     string
+
+	bookmarks []gamedb.Bookmark // positions marked while the game was played
+
+	clocks map[int]time.Duration // time left after each move, by ply, from the PGN's %clk comments
+
+	analysis replayAnalysis // the analyst's evaluations of the positions shown
+
+	parent tea.Model // shown again on quit, if the replay was opened from it
+}
+
+// NewReplay opens a game given as its moves in SAN, at the start
+func NewReplay(title string, moves []string, settings *Settings) (*Replay, error) {
+	return newReplay(title, "", moves, settings)
+}
+
+// newReplay opens a game played from the position start, or the standard
+// position if it is empty
+func newReplay(title, start string, moves []string, settings *Settings) (*Replay, error) {
+	board, err := startingBoard(start)
+	if err != nil {
+		return nil, err
+	}
+	for i, san := range moves {
+		move, err := notation.Decode(board.Position(), san)
+		if err == nil {
+			err = board.Move(move)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay move %d (%s): %w", i+1, san, err)
+		}
+	}
+
+	input := textinput.New()
+	input.Placeholder = "move number"
+	input.CharLimit = 4
+	input.Width = 12
+
+	r := &Replay{
+		title:  title,
+		start:  start,
+		moves:  append([]string(nil), moves...),
+		game:   NewGameWithSettings(ModeHumanVsHuman, settings),
+		speed:  1,
+		input:  input,
+		search: newSearch("move or FEN fragment"),
+		analysis: replayAnalysis{
+			analyst: EngineAnalyst(),
+			results: make(map[string]Evaluation),
+			errors:  make(map[string]string),
+		},
+	}
+	r.seek(0)
+	return r, nil
+}
+
+// NewReplayFromPGN opens the first game in a PGN file
+func NewReplayFromPGN(r io.Reader, settings *Settings) (*Replay, error) {
+	option, err := chess.PGN(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PGN: %w", err)
+	}
+	played := chess.NewGame(option)
+	title := "Replay"
+	if white, black := played.GetTagPair("White"), played.GetTagPair("Black"); white != nil && black != nil {
+		title = white.Value + " vs " + black.Value
+	}
+	replay, err := NewReplay(title, sanMovesOf(played), settings)
+	if err != nil {
+		return nil, err
+	}
+	replay.bookmarks = bookmarksFromComments(played.Comments())
+	replay.game.annotations = annotationsFromComments(played.Comments())
+	replay.clocks = clocksFromComments(played.Comments())
+	return replay, nil
+}
+
+// NewReplayFromRecord opens a game from the game log
+func NewReplayFromRecord(record gamedb.Record, settings *Settings) (*Replay, error) {
+	title := fmt.Sprintf("%s vs %s, %s", record.White, record.Black, record.Played.Format("Jan 2, 2006"))
+	replay, err := newReplay(title, record.StartFEN, record.Moves, settings)
+	if err != nil {
+		return nil, err
+	}
+	replay.bookmarks = record.Bookmarks
+	return replay, nil
+}
+
+// Follow watches a game as it is played: moves received on moves are added
+// to the replay, and auto-play keeps up with them. Call it before Init.
+func (r *Replay) Follow(moves <-chan string) {
+	r.live = moves
+	r.playing = true
+}
+
+// SetSpeed picks the playback speed closest to multiplier
+func (r *Replay) SetSpeed(multiplier float64) {
+	for i, speed := range replaySpeeds {
+		if math.Abs(speed-multiplier) < math.Abs(replaySpeeds[r.speed]-multiplier) {
+			r.speed = i
+		}
+	}
+}
+
+// Play starts auto-play when the replay opens. Call it before Init.
+func (r *Replay) Play() {
+	r.playing = true
+}
+
+// Init starts auto-play, waits for live moves and analyzes the first
+// position, as set up
+func (r *Replay) Init() tea.Cmd {
+	return tea.Batch(r.waitForMove(), r.startTicker(), r.analyzeLater())
+}
+
+// waitForMove waits for the next move of the game being watched
+func (r *Replay) waitForMove() tea.Cmd {
+	if r.live == nil {
+		return nil
+	}
+	live := r.live
+	return func() tea.Msg {
+		move, ok := <-live
+		return replayMoveMsg{move: move, ok: ok}
+	}
+}
+
+// seek shows the board after ply moves
+func (r *Replay) seek(ply int) {
+	r.ply = max(0, min(ply, len(r.moves)))
+	board, _ := startingBoard(r.start)
+	for _, san := range r.moves[:r.ply] {
+		move, _ := notation.Decode(board.Position(), san)
+		board.Move(move)
+	}
+	r.game.chessGame = board
+	r.boardChanged()
+}
+
+// startingBoard returns a board at the position given as FEN, or the
+// standard position if it is empty
+func startingBoard(fen string) (*chess.Game, error) {
+	if fen == "" {
+		return chess.NewGame(chess.UseNotation(chess.AlgebraicNotation{})), nil
+	}
+	option, err := chess.FEN(fen)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start position: %w", err)
+	}
+	return chess.NewGame(option, chess.UseNotation(chess.AlgebraicNotation{})), nil
+}
+
+// interval returns the time between moves at the current speed
+func (r *Replay) interval() time.Duration {
+	return time.Duration(float64(replayInterval) / replaySpeeds[r.speed])
+}
+
+// startTicker schedules the next auto-play step, unless one is pending
+func (r *Replay) startTicker() tea.Cmd {
+	if !r.playing || r.ticking || r.blurred || r.ply >= len(r.moves) {
+		return nil
+	}
+	r.ticking = true
+	generation := r.generation
+	return tea.Tick(r.interval(), func(time.Time) tea.Msg { return replayTickMsg{generation: generation} })
+}
+
+// restartTicker drops the pending step and schedules a new one, for when
+// play is paused or the speed changes
+func (r *Replay) restartTicker() tea.Cmd {
+	r.generation++
+	r.ticking = false
+	return r.startTicker()
+}
+
+// Update handles auto-play, live moves, the analysis and the replay
+// controls. A position the replay moves to is analyzed once it rests there.
+func (r *Replay) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := r.update(msg)
+	if model != r {
+		return model, cmd
+	}
+	return r, tea.Batch(cmd, r.analyzeLater())
+}
+
+func (r *Replay) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case replayAnalyzeMsg, replayAnalysisMsg:
+		return r, r.updateAnalysis(msg)
+	case replayTickMsg:
+		if msg.generation != r.generation {
+			return r, nil
+		}
+		r.ticking = false
+		if r.playing && r.ply < len(r.moves) {
+			r.seek(r.ply + 1)
+		}
+		if r.ply >= len(r.moves) && r.live == nil {
+			r.playing = false
+		}
+		return r, r.startTicker()
+	case replayMoveMsg:
+		if !msg.ok {
+			r.live = nil
+			return r, nil
+		}
+		if err := r.append(msg.move); err != nil {
+			r.err = err.Error()
+		}
+		return r, tea.Batch(r.waitForMove(), r.startTicker())
+	case tea.BlurMsg:
+		r.blurred = true
+		return r, r.restartTicker()
+	case tea.FocusMsg:
+		r.blurred = false
+		return r, r.startTicker()
+	case tea.KeyMsg:
+		if r.jumping {
+			return r, r.updateJump(msg)
+		}
+		if r.search.active {
+			entered, cmd := r.search.update(msg)
+			if entered {
+				r.findMoves()
+			}
+			return r, cmd
+		}
+		return r.handleKey(msg)
+	}
+	return r, nil
+}
+
+// append adds a move of the game being watched
+func (r *Replay) append(san string) error {
+	board := chess.NewGame()
+	for _, played := range r.moves {
+		move, _ := notation.Decode(board.Position(), played)
+		board.Move(move)
+	}
+	move, err := notation.Decode(board.Position(), san)
+	if err != nil {
+		return fmt.Errorf("watched game sent an illegal move %s: %w", san, err)
+	}
+	r.moves = append(r.moves, notation.Encode(board.Position(), move))
+	return nil
+}
+
+// handleKey handles the replay controls
+func (r *Replay) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	r.err = ""
+	r.notice = ""
+	switch msg.String() {
+	case " ":
+		r.playing = !r.playing
+		if r.playing && r.ply >= len(r.moves) && r.live == nil {
+			// Playing from the end starts over
+			r.seek(0)
+		}
+		return r, r.restartTicker()
+	case "+", "=", "]":
+		r.speed = min(r.speed+1, len(replaySpeeds)-1)
+		return r, r.restartTicker()
+	case "-", "[":
+		r.speed = max(r.speed-1, 0)
+		return r, r.restartTicker()
+	case "right", "l":
+		r.seek(r.ply + 1)
+		return r, r.restartTicker()
+	case "left", "h":
+		r.playing = false
+		r.seek(r.ply - 1)
+	case "home", "g":
+		r.playing = false
+		r.seek(0)
+	case "end", "G":
+		r.seek(len(r.moves))
+	case "b", "B":
+		if ply, ok := r.nextBookmark(msg.String() == "B"); ok {
+			r.playing = false
+			r.seek(ply)
+		}
+	case "a":
+		return r, r.toggleAnalysis()
+	case "ctrl+p":
+		ansPath, txtPath, err := saveScreenshot(r.View())
+		if err != nil {
+			r.err = err.Error()
+			return r, nil
+		}
+		r.notice = fmt.Sprintf("Saved screenshot to %s and %s", ansPath, txtPath)
+	case "/":
+		r.playing = false
+		return r, r.search.open()
+	case "n", "N":
+		dir := 1
+		if msg.String() == "N" {
+			dir = -1
+		}
+		if i, ok := r.search.step(dir); ok {
+			r.playing = false
+			r.seek(i + 1)
+		}
+	case ":", "#":
+		r.playing = false
+		r.jumping = true
+		r.input.SetValue("")
+		r.input.Focus()
+		return r, textinput.Blink
+	case "q", "esc":
+		if r.parent != nil {
+			// Stop auto-play and go back to where the replay was opened
+			r.generation++
+			return r.parent, nil
+		}
+		return r, tea.Quit
+	case "ctrl+c":
+		return r, tea.Quit
+	}
+	return r, nil
+}
+
+// findMoves runs the search over the moves, showing the first match after
+// the move shown. A move matches if its SAN contains the query, or the
+// board after it contains the query as part of its FEN.
+func (r *Replay) findMoves() {
+	query := r.search.query
+	placements := r.placements()
+	i, ok := r.search.find(len(r.moves), r.ply, func(i int) bool {
+		return strings.Contains(r.moves[i], query) || (len(query) >= 3 && strings.Contains(placements[i], query))
+	})
+	if ok {
+		r.seek(i + 1)
+	}
+}
+
+// placements returns the piece placement part of the FEN after each move
+func (r *Replay) placements() []string {
+	board := chess.NewGame()
+	placements := make([]string, 0, len(r.moves))
+	for _, san := range r.moves {
+		move, _ := notation.Decode(board.Position(), san)
+		board.Move(move)
+		placements = append(placements, board.Position().Board().String())
+	}
+	return placements
+}
+
+// updateJump handles the move-number prompt
+func (r *Replay) updateJump(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		r.jumping = false
+		r.input.Blur()
+		number, err := strconv.Atoi(strings.TrimSpace(r.input.Value()))
+		if err != nil || number < 1 {
+			r.err = fmt.Sprintf("not a move number: %q", r.input.Value())
+			return nil
+		}
+		r.jumpToMove(number)
+		return nil
+	case "esc":
+		r.jumping = false
+		r.input.Blur()
+		return nil
+	case "ctrl+c":
+		return tea.Quit
+	}
+	var cmd tea.Cmd
+	r.input, cmd = r.input.Update(msg)
+	return cmd
+}
+
+// jumpToMove shows the board after White's move number, or the end of the
+// game if it is shorter
+func (r *Replay) jumpToMove(number int) {
+	r.seek(2*number - 1)
+}
+
+// View renders the board, the moves and the playback state
+func (r *Replay) View() string {
+	var sb strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700")).Render("♔ " + r.title + " ♛")
+	sb.WriteString(title + "\n\n")
+	sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, r.game.renderBoard(), r.renderMoveList()) + "\n\n")
+
+	infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF"))
+	sb.WriteString(infoStyle.Render(r.progress()) + "\n")
+	if clocks := r.clockText(); clocks != "" {
+		sb.WriteString(infoStyle.Render(clocks) + "\n")
+	}
+	if analysis := r.analysisText(); analysis != "" {
+		sb.WriteString(infoStyle.Render(analysis) + "\n")
+	}
+	if status := r.search.status(); status != "" {
+		sb.WriteString(infoStyle.Render(status) + "\n")
+	}
+	if r.notice != "" {
+		sb.WriteString(infoStyle.Render(r.notice) + "\n")
+	}
+	if r.err != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+r.err) + "\n")
+	}
+
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	if r.search.active {
+		sb.WriteString("\n" + r.search.input.View() + "\n\n")
+		sb.WriteString(helpStyle.Render("Enter to search, esc to cancel"))
+		return sb.String()
+	}
+	if r.jumping {
+		sb.WriteString("\nGo to move: " + r.input.View() + "\n\n")
+		sb.WriteString(helpStyle.Render("Enter to jump, esc to cancel"))
+		return sb.String()
+	}
+	sb.WriteString("\n" + helpStyle.Render("space play/pause, +/- speed, ←/→ step, g/G start/end, : go to move, / search, b/B bookmarks, a analysis, ctrl+p screenshot, q quit"))
+	return sb.String()
+}
+
+// clocksFromComments reads the %clk clock readings from a game's comments,
+// indexed by move as chess.Game.Comments returns them
+func clocksFromComments(comments [][]string) map[int]time.Duration {
+	clocks := make(map[int]time.Duration)
+	for i, moveComments := range comments {
+		for _, comment := range moveComments {
+			if left, ok := tournament.ParseClockComment(comment); ok {
+				clocks[i+1] = left
+			}
+		}
+	}
+	return clocks
+}
+
+// clockText shows each side's clock after its last move up to the one
+// shown, as the PGN recorded them, e.g. "⏱ White 9:42 — Black 8:10"
+func (r *Replay) clockText() string {
+	var parts []string
+	for _, color := range []chess.Color{chess.White, chess.Black} {
+		for ply := r.ply; ply > 0; ply-- {
+			if (ply%2 == 1) != (color == chess.White) {
+				continue
+			}
+			if left, ok := r.clocks[ply]; ok {
+				parts = append(parts, color.Name()+" "+formatClock(left))
+				break
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "⏱ " + strings.Join(parts, " — ")
+}
+
+// progress describes where the replay is, e.g. "▶ 2x — ply 27 of 80, 14. Nf3"
+func (r *Replay) progress() string {
+	state := "⏸"
+	if r.playing {
+		state = "▶"
+	}
+	line := fmt.Sprintf("%s %sx — ply %d of %d", state, strconv.FormatFloat(replaySpeeds[r.speed], 'f', -1, 64), r.ply, len(r.moves))
+	if r.ply > 0 {
+		ply := r.ply - 1
+		separator := ". "
+		if ply%2 == 1 {
+			separator = "... "
+		}
+		line += ", " + moveNumber(ply) + separator + r.moves[ply]
+	}
+	if bookmark, ok := r.bookmarkAt(r.ply); ok {
+		line += " 🔖"
+		if bookmark.Note != "" {
+			line += " " + bookmark.Note
+		}
+	}
+	if r.live != nil {
+		line += " (live)"
+	} else if r.ply == len(r.moves) {
+		if outcome := r.game.chessGame.Outcome(); outcome != chess.NoOutcome {
+			line += " — " + outcome.String()
+		}
+	}
+	return line
+}
+
+// renderMoveList lists the moves around the one shown, highlighting it and
+// the moves matching the search
+func (r *Replay) renderMoveList() string {
+	current := lipgloss.NewStyle().Reverse(true)
+	match := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700"))
+	cell := func(i int, color chess.Color) string {
+		text := fmt.Sprintf("%-8s", r.game.formatMove(r.moves[i], color))
+		switch {
+		case i == r.ply-1:
+			return current.Render(text)
+		case r.search.isMatch(i):
+			return match.Render(text)
+		}
+		return text
+	}
+	if len(r.bookmarks) > 0 {
+		// Mark the bookmarked moves, leaving room for the marks
+		plain := cell
+		cell = func(i int, color chess.Color) string {
+			if _, ok := r.bookmarkAt(i + 1); ok {
+				return "*" + plain(i, color)
+			}
+			return " " + plain(i, color)
+		}
+	}
+
+	var rows []string
+	for i := 0; i < len(r.moves); i += 2 {
+		row := fmt.Sprintf("%3d. ", i/2+1) + cell(i, chess.White)
+		if i+1 < len(r.moves) {
+			row += " " + cell(i+1, chess.Black)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) > moveListRows {
+		// Keep the move shown in view
+		first := min(max(0, (r.ply-1)/2-moveListRows/2), len(rows)-moveListRows)
+		rows = rows[first : first+moveListRows]
+	}
+	if len(rows) == 0 {
+		rows = append(rows, "  No moves yet")
+	}
+
+	title := lipgloss.NewStyle().Bold(true).Render("Moves")
+	return lipgloss.NewStyle().PaddingLeft(3).Render(title + "\n" + strings.Join(rows, "\n"))
+}
+
+// bookmarkAt returns the bookmark on the position after ply moves, if any
+func (r *Replay) bookmarkAt(ply int) (gamedb.Bookmark, bool) {
+	for _, bookmark := range r.bookmarks {
+		if bookmark.Ply == ply {
+			return bookmark, true
+		}
+	}
+	return gamedb.Bookmark{}, false
+}
+
+// nextBookmark returns the ply of the first bookmark after the position
+// shown, or the last one before it when back is set
+func (r *Replay) nextBookmark(back bool) (int, bool) {
+	best, found := 0, false
+	for _, bookmark := range r.bookmarks {
+		if bookmark.Ply > len(r.moves) {
+			continue
+		}
+		switch {
+		case back && bookmark.Ply < r.ply && (!found || bookmark.Ply > best),
+			!back && bookmark.Ply > r.ply && (!found || bookmark.Ply < best):
+			best, found = bookmark.Ply, true
+		}
+	}
+	return best, found
+}
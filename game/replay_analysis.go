@@ -0,0 +1,259 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"chess-tui/ai_player"
+	"chess-tui/notation"
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// replayAnalysisDelay is how long the replay must rest on a position before
+// it is analyzed, so that stepping quickly through the moves doesn't send the
+// analyst a request for every position passed
+const replayAnalysisDelay = 300 * time.Millisecond
+
+// mateScore is the score of a forced mate, as UCI engines report it
+const mateScore = 10000
+
+// Evaluation is an analyst's verdict on a position
+type Evaluation struct {
+	Score   int    // centipawns, from White's side
+	Best    string // the best move in SAN, if the analyst names one
+	Comment string // a word on the position, if the analyst gives one
+}
+
+// Analyst evaluates positions in the background of a replay
+type Analyst interface {
+	// Name identifies the analyst in the replay, e.g. "engine"
+	Name() string
+
+	// Analyze evaluates the position given as FEN
+	Analyze(fen string) (Evaluation, error)
+}
+
+// EngineAnalyst returns the built-in engine as an analyst: it counts
+// material and names the move the engine would play
+func EngineAnalyst() Analyst {
+	return engineAnalyst{}
+}
+
+type engineAnalyst struct{}
+
+func (engineAnalyst) Name() string {
+	return ai_player.ProviderEngine
+}
+
+func (engineAnalyst) Analyze(fen string) (Evaluation, error) {
+	position, err := positionOf(fen)
+	if err != nil {
+		return Evaluation{}, err
+	}
+	evaluation := Evaluation{Score: ai_player.MaterialBalance(position, chess.White)}
+	if position.Status() != chess.NoMethod {
+		return evaluation, nil
+	}
+	move, err := tournament.FallbackMove(position)
+	if err != nil {
+		return Evaluation{}, err
+	}
+	evaluation.Best = notation.Encode(position, move)
+	return evaluation, nil
+}
+
+// NewSuggestionAnalyst returns an analyst that asks an AI for its candidate
+// moves, taking the first as the best and its explanation as the comment.
+// The score is the material balance.
+func NewSuggestionAnalyst(generator MoveGenerator) Analyst {
+	return suggestionAnalyst{generator: generator}
+}
+
+type suggestionAnalyst struct {
+	generator MoveGenerator
+}
+
+func (s suggestionAnalyst) Name() string {
+	return "AI"
+}
+
+func (s suggestionAnalyst) Analyze(fen string) (Evaluation, error) {
+	position, err := positionOf(fen)
+	if err != nil {
+		return Evaluation{}, err
+	}
+	evaluation := Evaluation{Score: ai_player.MaterialBalance(position, chess.White)}
+	if position.Status() != chess.NoMethod {
+		return evaluation, nil
+	}
+	candidates, err := s.generator.SuggestMoves(fen, nil, colorName(position.Turn()))
+	if err != nil {
+		return Evaluation{}, fmt.Errorf("failed to get suggestions: %w", err)
+	}
+	if len(candidates) > 0 {
+		if san, err := notation.Normalize(fen, candidates[0].Move); err == nil {
+			evaluation.Best = san
+			evaluation.Comment = candidates[0].Explanation
+		}
+	}
+	return evaluation, nil
+}
+
+// positionOf parses a FEN position
+func positionOf(fen string) (*chess.Position, error) {
+	option, err := chess.FEN(fen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FEN: %w", err)
+	}
+	return chess.NewGame(option).Position(), nil
+}
+
+// replayAnalysis keeps the analyst's evaluations of the replayed positions
+type replayAnalysis struct {
+	analyst    Analyst
+	off        bool
+	generation int                   // bumped when the board changes, so that only the last wait analyzes
+	changed    bool                  // the board changed and the wait before analyzing hasn't started
+	waiting    bool                  // the wait before analyzing the position shown has started
+	running    string                // the FEN being analyzed, if any
+	results    map[string]Evaluation // by FEN
+	errors     map[string]string     // by FEN
+}
+
+// replayAnalyzeMsg ends the wait before analyzing the position shown
+type replayAnalyzeMsg struct {
+	generation int
+}
+
+// replayAnalysisMsg carries the analyst's evaluation of a position
+type replayAnalysisMsg struct {
+	fen        string
+	evaluation Evaluation
+	err        error
+}
+
+// SetAnalyst picks who analyzes the positions shown, or turns the analysis
+// off if analyst is nil. Call it before Init.
+func (r *Replay) SetAnalyst(analyst Analyst) {
+	r.analysis.analyst = analyst
+	r.analysis.off = analyst == nil
+}
+
+// boardChanged notes that the board shows another position, to be analyzed
+// once the replay rests on it
+func (r *Replay) boardChanged() {
+	r.analysis.generation++
+	r.analysis.changed = true
+}
+
+// analyzeLater waits for the replay to rest on the position shown before
+// analyzing it
+func (r *Replay) analyzeLater() tea.Cmd {
+	if !r.analysis.changed || r.analysis.off || r.analysis.analyst == nil {
+		return nil
+	}
+	r.analysis.changed = false
+	r.analysis.waiting = true
+	generation := r.analysis.generation
+	return tea.Tick(replayAnalysisDelay, func(time.Time) tea.Msg { return replayAnalyzeMsg{generation: generation} })
+}
+
+// analyze asks the analyst about the position shown, unless it has been
+// analyzed or the analyst is still busy with another; in that case the
+// position is analyzed when the analyst answers
+func (r *Replay) analyze() tea.Cmd {
+	fen := r.game.getBoardState()
+	if r.analysis.off || r.analysis.running != "" || r.analyzed(fen) {
+		return nil
+	}
+	r.analysis.running = fen
+	analyst := r.analysis.analyst
+	return func() tea.Msg {
+		evaluation, err := analyst.Analyze(fen)
+		return replayAnalysisMsg{fen: fen, evaluation: evaluation, err: err}
+	}
+}
+
+// analyzed reports whether the analyst has answered about a position
+func (r *Replay) analyzed(fen string) bool {
+	_, ok := r.analysis.results[fen]
+	_, failed := r.analysis.errors[fen]
+	return ok || failed
+}
+
+// updateAnalysis handles the analysis messages
+func (r *Replay) updateAnalysis(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case replayAnalyzeMsg:
+		if msg.generation != r.analysis.generation {
+			return nil
+		}
+		r.analysis.waiting = false
+		return r.analyze()
+	case replayAnalysisMsg:
+		r.analysis.running = ""
+		if msg.err != nil {
+			r.analysis.errors[msg.fen] = msg.err.Error()
+		} else {
+			r.analysis.results[msg.fen] = msg.evaluation
+		}
+		// The board may have moved on while the analyst was busy
+		if !r.analysis.changed && !r.analysis.waiting {
+			return r.analyze()
+		}
+	}
+	return nil
+}
+
+// toggleAnalysis turns the analysis on or off
+func (r *Replay) toggleAnalysis() tea.Cmd {
+	if r.analysis.analyst == nil {
+		r.err = "no analyst is set up"
+		return nil
+	}
+	r.analysis.off = !r.analysis.off
+	if r.analysis.off {
+		return nil
+	}
+	return r.analyze()
+}
+
+// analysisText describes the evaluation of the position shown, e.g.
+// "Analysis (engine): +1.0, best Nf3"
+func (r *Replay) analysisText() string {
+	if r.analysis.analyst == nil || r.analysis.off {
+		return ""
+	}
+	label := "Analysis (" + r.analysis.analyst.Name() + "): "
+	fen := r.game.getBoardState()
+	if err, failed := r.analysis.errors[fen]; failed {
+		return label + "failed, " + err
+	}
+	evaluation, ok := r.analysis.results[fen]
+	if !ok {
+		return label + "thinking…"
+	}
+	text := label + formatScore(evaluation.Score)
+	if evaluation.Best != "" {
+		text += ", best " + evaluation.Best
+	}
+	if evaluation.Comment != "" {
+		text += " — " + evaluation.Comment
+	}
+	return text
+}
+
+// formatScore shows a score from White's side in pawns, e.g. "+1.3", or
+// the side with a forced mate
+func formatScore(score int) string {
+	switch {
+	case score >= mateScore:
+		return "White mates"
+	case score <= -mateScore:
+		return "Black mates"
+	}
+	return fmt.Sprintf("%+.1f", float64(score)/100)
+}
@@ -0,0 +1,229 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"chess-tui/gamedb"
+	"chess-tui/positions"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var replayMoves = []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "a6"}
+
+func replayKey(r *Replay, key string) tea.Cmd {
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case " ":
+		msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	}
+	_, cmd := r.Update(msg)
+	return cmd
+}
+
+func TestReplayRejectsIllegalMoves(t *testing.T) {
+	if _, err := NewReplay("bad", []string{"e4", "e4"}, DefaultSettings()); err == nil {
+		t.Error("Expected an error for an illegal move")
+	}
+}
+
+func TestReplayFromStartPosition(t *testing.T) {
+	fork := positions.MustGet("knight-fork")
+	r, err := NewReplayFromRecord(gamedb.Record{White: "A", Black: "B", StartFEN: fork.FEN, Moves: fork.Line}, DefaultSettings())
+	if err != nil {
+		t.Fatalf("Expected the game from its start position to replay, got %v", err)
+	}
+	if r.game.getBoardState() != fork.FEN {
+		t.Errorf("Expected the replay to open at %s, got %s", fork.FEN, r.game.getBoardState())
+	}
+	r.seek(len(fork.Line))
+	if got := r.game.lastMoveSAN(); got != "Nxa8" {
+		t.Errorf("Expected the last move Nxa8, got %s", got)
+	}
+}
+
+func TestReplayAutoPlay(t *testing.T) {
+	r, err := NewReplay("test", replayMoves, DefaultSettings())
+	if err != nil {
+		t.Fatalf("Expected the game to load, got %v", err)
+	}
+	if cmd := replayKey(r, " "); cmd == nil || !r.playing {
+		t.Fatal("Expected space to start auto-play")
+	}
+
+	r.Update(replayTickMsg{generation: r.generation})
+	if r.ply != 1 {
+		t.Errorf("Expected a tick to play a move, got ply %d", r.ply)
+	}
+
+	// Changing speed drops the pending tick
+	stale := r.generation
+	replayKey(r, "+")
+	if r.interval() != replayInterval/2 {
+		t.Errorf("Expected 2x speed, got %v between moves", r.interval())
+	}
+	r.Update(replayTickMsg{generation: stale})
+	if r.ply != 1 {
+		t.Errorf("Expected a stale tick to be ignored, got ply %d", r.ply)
+	}
+
+	replayKey(r, " ")
+	if r.playing {
+		t.Error("Expected space to pause")
+	}
+}
+
+func TestReplayJumps(t *testing.T) {
+	r, _ := NewReplay("test", replayMoves, DefaultSettings())
+
+	replayKey(r, "G")
+	if r.ply != len(replayMoves) {
+		t.Errorf("Expected G to jump to the end, got ply %d", r.ply)
+	}
+
+	replayKey(r, ":")
+	for _, key := range []string{"2", "enter"} {
+		replayKey(r, key)
+	}
+	if r.ply != 3 || r.game.lastMoveSAN() != "Nf3" {
+		t.Errorf("Expected to jump to 2. Nf3, got ply %d", r.ply)
+	}
+
+	replayKey(r, "h")
+	if r.ply != 2 {
+		t.Errorf("Expected to step back, got ply %d", r.ply)
+	}
+	if !strings.Contains(r.View(), "ply 2 of 6, 1... e5") {
+		t.Errorf("Expected the position in the view, got %q", r.progress())
+	}
+}
+
+func TestReplayFollowsLiveGame(t *testing.T) {
+	r, _ := NewReplay("live", nil, DefaultSettings())
+	moves := make(chan string, 1)
+	r.Follow(moves)
+	r.Init()
+
+	moves <- "d4"
+	msg := r.waitForMove()()
+	r.Update(msg)
+	if len(r.moves) != 1 {
+		t.Fatalf("Expected the live move to be added, got %v", r.moves)
+	}
+	r.Update(replayTickMsg{generation: r.generation})
+	if r.ply != 1 {
+		t.Errorf("Expected auto-play to keep up with the live game, got ply %d", r.ply)
+	}
+
+	close(moves)
+	r.Update(r.waitForMove()())
+	if r.live != nil {
+		t.Error("Expected the replay to stop following once the game ends")
+	}
+}
+
+func TestReplayFromPGN(t *testing.T) {
+	pgn := "[White \"Gus\"]\n[Black \"Ana\"]\n\n1. e4 e5 2. Nf3 *\n"
+	r, err := NewReplayFromPGN(strings.NewReader(pgn), DefaultSettings())
+	if err != nil {
+		t.Fatalf("Expected the PGN to load, got %v", err)
+	}
+	if r.title != "Gus vs Ana" || len(r.moves) != 3 {
+		t.Errorf("Expected Gus vs Ana with 3 moves, got %q with %v", r.title, r.moves)
+	}
+}
+
+func TestReplaySetSpeed(t *testing.T) {
+	r, _ := NewReplay("test", replayMoves, DefaultSettings())
+	r.SetSpeed(3.5)
+	if r.interval() != time.Second/4 {
+		t.Errorf("Expected the closest speed, 4x, got %v between moves", r.interval())
+	}
+}
+
+// countingAnalyst records the positions it is asked about
+type countingAnalyst struct {
+	asked []string
+}
+
+func (a *countingAnalyst) Name() string {
+	return "counting"
+}
+
+func (a *countingAnalyst) Analyze(fen string) (Evaluation, error) {
+	a.asked = append(a.asked, fen)
+	return Evaluation{Score: 150, Best: "Nf3"}, nil
+}
+
+func TestReplayAnalysisDebouncesSteps(t *testing.T) {
+	r, _ := NewReplay("test", replayMoves, DefaultSettings())
+	analyst := &countingAnalyst{}
+	r.SetAnalyst(analyst)
+	r.Init()
+
+	// Step quickly through three moves: only the last wait analyzes
+	stale := r.analysis.generation
+	for range 3 {
+		replayKey(r, "l")
+	}
+	if _, cmd := r.Update(replayAnalyzeMsg{generation: stale}); cmd != nil {
+		t.Error("Expected a wait from a position stepped past not to analyze")
+	}
+	_, cmd := r.Update(replayAnalyzeMsg{generation: r.analysis.generation})
+	if cmd == nil {
+		t.Fatal("Expected the position the replay rests on to be analyzed")
+	}
+	r.Update(cmd())
+	if len(analyst.asked) != 1 || analyst.asked[0] != r.game.getBoardState() {
+		t.Fatalf("Expected one analysis of the position shown, got %v", analyst.asked)
+	}
+	if got := r.analysisText(); got != "Analysis (counting): +1.5, best Nf3" {
+		t.Errorf("Expected the evaluation to be shown, got %q", got)
+	}
+
+	// Coming back to an analyzed position doesn't ask again
+	replayKey(r, "h")
+	replayKey(r, "l")
+	if _, cmd := r.Update(replayAnalyzeMsg{generation: r.analysis.generation}); cmd != nil {
+		t.Error("Expected an analyzed position not to be analyzed again")
+	}
+}
+
+func TestReplayAnalysisWaitsForBusyAnalyst(t *testing.T) {
+	r, _ := NewReplay("test", replayMoves, DefaultSettings())
+	analyst := &countingAnalyst{}
+	r.SetAnalyst(analyst)
+	r.Init()
+
+	_, cmd := r.Update(replayAnalyzeMsg{generation: r.analysis.generation})
+	first := cmd
+	replayKey(r, "l")
+	if _, cmd := r.Update(replayAnalyzeMsg{generation: r.analysis.generation}); cmd != nil {
+		t.Error("Expected no second request while the analyst is busy")
+	}
+	_, cmd = r.Update(first())
+	if cmd == nil {
+		t.Fatal("Expected the position shown to be analyzed once the analyst answers")
+	}
+	r.Update(cmd())
+	if len(analyst.asked) != 2 {
+		t.Errorf("Expected the start and the position shown to be analyzed, got %v", analyst.asked)
+	}
+}
+
+func TestEngineAnalyst(t *testing.T) {
+	evaluation, err := EngineAnalyst().Analyze("rnbqkbnr/pppp1ppp/8/4p3/3P4/8/PPP1PPPP/RNBQKBNR w KQkq e6 0 2")
+	if err != nil {
+		t.Fatalf("Expected the engine to analyze, got %v", err)
+	}
+	if evaluation.Score != 0 || evaluation.Best != "dxe5" {
+		t.Errorf("Expected a level score with dxe5 best, got %+v", evaluation)
+	}
+	if formatScore(-mateScore) != "Black mates" {
+		t.Errorf("Expected a mate score to name the side, got %s", formatScore(-mateScore))
+	}
+}
@@ -0,0 +1,38 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// saveScreenshot writes a view exactly as shown, colors and all, to a .ans
+// file in the current directory, and without its escape codes to a .txt
+// file beside it. It returns the paths written.
+func saveScreenshot(view string) (string, string, error) {
+	base := fmt.Sprintf("bubblechess-%s", time.Now().Format("20060102-150405"))
+	ansPath, txtPath := base+".ans", base+".txt"
+	if err := os.WriteFile(ansPath, []byte(view+"\n"), 0644); err != nil {
+		return "", "", fmt.Errorf("failed to save screenshot: %w", err)
+	}
+	if err := os.WriteFile(txtPath, []byte(ansi.Strip(view)+"\n"), 0644); err != nil {
+		return "", "", fmt.Errorf("failed to save screenshot: %w", err)
+	}
+	return ansPath, txtPath, nil
+}
+
+// screenshot saves the game's screen as it is shown
+func (g *Game) screenshot() {
+	if g.settings.Remote {
+		g.status = "Screenshots aren't available over SSH"
+		return
+	}
+	ansPath, txtPath, err := saveScreenshot(g.View())
+	if err != nil {
+		g.err = err.Error()
+		return
+	}
+	g.status = fmt.Sprintf("Saved screenshot to %s and %s", ansPath, txtPath)
+}
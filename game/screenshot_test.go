@@ -0,0 +1,32 @@
+package game
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestScreenshotSavesStyledAndPlainView(t *testing.T) {
+	t.Chdir(t.TempDir())
+	g := NewGameWithSettings(ModeHumanVsHuman, DefaultSettings())
+	view := g.View()
+
+	g.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	if !strings.HasPrefix(g.status, "Saved screenshot to ") {
+		t.Fatalf("Expected the screenshot to be saved, got status %q and error %q", g.status, g.err)
+	}
+
+	ans, _ := os.ReadFile(strings.Fields(g.status)[3])
+	if string(ans) != view+"\n" {
+		t.Error("Expected the .ans file to hold the view exactly as shown")
+	}
+	txt, _ := os.ReadFile(strings.Fields(g.status)[5])
+	if strings.Contains(string(txt), "\x1b[") {
+		t.Error("Expected the .txt file to have no escape codes")
+	}
+	if !strings.Contains(string(txt), "♜  ♞  ♝  ♛  ♚") {
+		t.Errorf("Expected the .txt file to show the board, got:\n%s", txt)
+	}
+}
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// search is the / prompt of a list: it keeps the query typed and the
+// matching items, and steps through them with n and N
+type search struct {
+	input   textinput.Model
+	active  bool // the prompt is open
+	query   string
+	matches []int // the matching items, in order
+	current int   // index into matches of the match shown
+}
+
+// newSearch creates a closed search prompt
+func newSearch(placeholder string) search {
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = placeholder
+	input.CharLimit = 80
+	input.Width = 30
+	return search{input: input}
+}
+
+// open shows the prompt
+func (s *search) open() tea.Cmd {
+	s.active = true
+	s.input.SetValue("")
+	s.input.Focus()
+	return textinput.Blink
+}
+
+// update handles a key while the prompt is open, and reports whether a
+// query was entered
+func (s *search) update(msg tea.KeyMsg) (entered bool, cmd tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		s.active = false
+		s.input.Blur()
+		s.query = strings.TrimSpace(s.input.Value())
+		return true, nil
+	case "esc":
+		s.active = false
+		s.input.Blur()
+		return false, nil
+	}
+	s.input, cmd = s.input.Update(msg)
+	return false, cmd
+}
+
+// find collects the items of count for which match is true and returns the
+// first match at or after from, wrapping around
+func (s *search) find(count, from int, match func(i int) bool) (int, bool) {
+	s.matches = nil
+	s.current = 0
+	if s.query == "" {
+		return 0, false
+	}
+	for i := 0; i < count; i++ {
+		if match(i) {
+			s.matches = append(s.matches, i)
+		}
+	}
+	if len(s.matches) == 0 {
+		return 0, false
+	}
+	for j, i := range s.matches {
+		if i >= from {
+			s.current = j
+			break
+		}
+	}
+	return s.matches[s.current], true
+}
+
+// step moves to the next match, or the previous one for dir -1, wrapping around
+func (s *search) step(dir int) (int, bool) {
+	if len(s.matches) == 0 {
+		return 0, false
+	}
+	s.current = (s.current + dir + len(s.matches)) % len(s.matches)
+	return s.matches[s.current], true
+}
+
+// isMatch reports whether item i matches the query
+func (s *search) isMatch(i int) bool {
+	return slices.Contains(s.matches, i)
+}
+
+// status describes the search, e.g. "/Nxf7 — match 2 of 3"
+func (s *search) status() string {
+	switch {
+	case s.query == "":
+		return ""
+	case len(s.matches) == 0:
+		return fmt.Sprintf("/%s — no matches", s.query)
+	default:
+		return fmt.Sprintf("/%s — match %d of %d (n/N for next/previous)", s.query, s.current+1, len(s.matches))
+	}
+}
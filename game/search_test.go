@@ -0,0 +1,104 @@
+package game
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chess-tui/gamedb"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// typeSearch opens the / prompt of m, types query and enters it
+func typeSearch(m tea.Model, query string) {
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(query)})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestReplaySearchMoves(t *testing.T) {
+	r, err := NewReplay("test", []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "Nf6", "Nc3"}, DefaultSettings())
+	if err != nil {
+		t.Fatalf("Expected the game to load, got %v", err)
+	}
+
+	typeSearch(r, "N")
+	if len(r.search.matches) != 4 {
+		t.Fatalf("Expected 4 knight moves to match, got %v", r.search.matches)
+	}
+	if r.ply != 3 {
+		t.Errorf("Expected the first match (Nf3) to be shown, got ply %d", r.ply)
+	}
+	if !strings.Contains(r.View(), "match 1 of 4") {
+		t.Error("Expected the view to show the match count")
+	}
+
+	replayKey(r, "n")
+	if r.ply != 4 {
+		t.Errorf("Expected n to show Nc6, got ply %d", r.ply)
+	}
+	replayKey(r, "N")
+	replayKey(r, "N")
+	if r.ply != 7 {
+		t.Errorf("Expected N to wrap around to Nc3, got ply %d", r.ply)
+	}
+
+	typeSearch(r, "Qh5")
+	if len(r.search.matches) != 0 || !strings.Contains(r.View(), "no matches") {
+		t.Error("Expected no matches for a move not played")
+	}
+}
+
+func TestReplaySearchFEN(t *testing.T) {
+	r, err := NewReplay("test", replayMoves, DefaultSettings())
+	if err != nil {
+		t.Fatalf("Expected the game to load, got %v", err)
+	}
+
+	// The rank of black pieces after ...Nc6
+	typeSearch(r, "r1bqkbnr")
+	if len(r.search.matches) == 0 || r.moves[r.search.matches[0]] != "Nc6" {
+		t.Errorf("Expected the position after Nc6 to match, got %v", r.search.matches)
+	}
+}
+
+func TestGameBrowserSearch(t *testing.T) {
+	db := gamedb.Open(filepath.Join(t.TempDir(), "games.jsonl"))
+	for _, record := range []gamedb.Record{
+		{White: "Alice", Black: "AI", Opponent: "Magnus", Result: "1-0", Moves: []string{"e4", "e5"}},
+		{White: "AI", Black: "Bob", Opponent: "Tal", Result: "0-1", Moves: []string{"d4"}},
+		{White: "Carol", Black: "AI", Opponent: "magnus", Result: "1/2-1/2", Moves: []string{"c4"}},
+	} {
+		if err := db.Add(record); err != nil {
+			t.Fatalf("Failed to add game: %v", err)
+		}
+	}
+
+	b := NewGameBrowser(db, DefaultSettings())
+	if b.records[0].White != "Carol" {
+		t.Errorf("Expected the newest game first, got %s", b.records[0].White)
+	}
+
+	typeSearch(b, "MAGNUS")
+	if len(b.search.matches) != 2 || b.cursor != 0 {
+		t.Errorf("Expected both games against Magnus to match, got %v with cursor %d", b.search.matches, b.cursor)
+	}
+	b.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if b.records[b.cursor].White != "Alice" {
+		t.Errorf("Expected n to move to Alice's game, got %s", b.records[b.cursor].White)
+	}
+
+	// Enter opens the game, and q comes back to the list
+	model, _ := b.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	replay, ok := model.(*Replay)
+	if !ok {
+		t.Fatalf("Expected enter to open a replay, got %T", model)
+	}
+	if len(replay.moves) != 2 {
+		t.Errorf("Expected Alice's game to be replayed, got %v", replay.moves)
+	}
+	if model, _ = replay.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); model != b {
+		t.Errorf("Expected q to return to the browser, got %T", model)
+	}
+}
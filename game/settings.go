@@ -0,0 +1,256 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"chess-tui/events"
+	"chess-tui/gamedb"
+	"chess-tui/locale"
+	"chess-tui/rules"
+	"chess-tui/share"
+	"chess-tui/tournament"
+)
+
+// Settings holds the user's display preferences for the TUI
+type Settings struct {
+	Accessible bool   `json:"accessible"`
+	Palette    string `json:"palette"`
+	Figurine   bool   `json:"figurine"`
+	BoardStyle string `json:"board_style"`
+	Zen        bool   `json:"zen,omitempty"`
+
+	// LowBandwidth draws less, and less often, for slow links such as SSH
+	LowBandwidth bool `json:"low_bandwidth,omitempty"`
+
+	// The TUI runs in the terminal's alternate screen, leaving the
+	// scrollback as it was on exit, and shows whose move it is in the
+	// terminal title; these turn either off
+	NoAltScreen bool `json:"no_alt_screen,omitempty"`
+	NoTitle     bool `json:"no_title,omitempty"`
+
+	// Phone lays the game out for narrow portrait screens such as Termux
+	Phone bool `json:"phone,omitempty"`
+
+	// Hot-seat conveniences for two players at one terminal
+	AutoFlip      bool `json:"auto_flip,omitempty"`      // draw the board from the side to move
+	PrivacyScreen bool `json:"privacy_screen,omitempty"` // hide the board between turns
+
+	// Personality is the ai_player preset used in Human vs AI games
+	Personality string `json:"personality,omitempty"`
+
+	// FixedDifficulty keeps named opponents at the difficulty they are
+	// defined with, instead of adapting it to the human's results
+	FixedDifficulty bool `json:"fixed_difficulty,omitempty"`
+
+	// PlayerName is the human's name in recorded games, "Human" if unset
+	PlayerName string `json:"player_name,omitempty"`
+
+	// AIServer is the A2A server Human vs AI games play against, by
+	// default http://localhost:8080
+	AIServer string `json:"ai_server,omitempty"`
+
+	// Bell rings the terminal bell after the opponent's move, on check and
+	// when the game ends
+	Bell bool `json:"bell,omitempty"`
+
+	// Webhooks receive every game event as a JSON POST
+	Webhooks []string `json:"webhooks,omitempty"`
+
+	// Hooks are shell commands run on moves, the end of the game and the AI
+	// starting to think, given the event as JSON on stdin
+	Hooks events.Hooks `json:"hooks,omitempty"`
+
+	// Share is where ctrl+g uploads the game's PGN
+	Share share.Config `json:"share,omitempty"`
+
+	// Archive is where every finished game's PGN is kept, and for how long
+	Archive gamedb.ArchivePolicy `json:"archive,omitempty"`
+
+	// Clock puts Human vs Human and Human vs AI games on a clock, with
+	// different limits per side for a handicap. Set with --game-time and
+	// --move-time and their per-side variants.
+	Clock *tournament.TimeControl `json:"clock,omitempty"`
+
+	// AutoQueen promotes a pawn to a queen when no piece is typed; submit
+	// the move with alt+enter to choose the piece instead
+	AutoQueen bool `json:"auto_queen,omitempty"`
+
+	// Confirm asks before playing the player's move in serious games, those
+	// against a networked opponent or on the clock: ConfirmCaptures for
+	// captures, ConfirmAll for every move, "" never
+	Confirm string `json:"confirm,omitempty"`
+
+	// TouchMove plays by tournament rules: a piece picked up with the
+	// cursor must be moved if it has a legal move
+	TouchMove bool `json:"touch_move,omitempty"`
+
+	// BeginnerHints shows how the piece under the cursor moves, dimming
+	// the rest of the board
+	BeginnerHints bool `json:"beginner_hints,omitempty"`
+
+	// Bullet plays for speed against the AI: the AI's book, response cache,
+	// short answers and a single voter, pondering on the player's time, and
+	// moves played as soon as what is typed matches only one legal move
+	Bullet bool `json:"bullet,omitempty"`
+
+	// Transcript is a JSON Lines file every event of the session's games is
+	// appended to, with the AI's thinking, rejected moves, failed requests
+	// and timings, for bug reports about the AI. Set with --transcript.
+	Transcript string `json:"transcript,omitempty"`
+
+	// InstantBot configures the menu's instant bot, which plays without any
+	// AI: its style, a delay and how often it answers illegally
+	InstantBot *InstantBotSettings `json:"instant_bot,omitempty"`
+
+	// AdjournedDir is where adjourned games are saved, by default
+	// ~/.bubblechess/adjourned
+	AdjournedDir string `json:"adjourned_dir,omitempty"`
+
+	// Prices in dollars per million tokens, used to estimate AI cost
+	PromptTokenCost     float64 `json:"prompt_token_cost,omitempty"`
+	CompletionTokenCost float64 `json:"completion_token_cost,omitempty"`
+
+	// Rules are house rules for local games, such as compulsory captures
+	// or a move limit. Set with --max-moves, --must-capture and
+	// --material-move.
+	Rules *rules.Rules `json:"rules,omitempty"`
+
+	// Locale is how dates and numbers are written on screen and in match
+	// reports, e.g. "de_DE"; by default $LC_ALL or $LANG says
+	Locale string `json:"locale,omitempty"`
+
+	// Remote marks a game played over SSH on the operator's machine, where
+	// saving files, screenshots, adjourning and sharing are turned off
+	Remote bool `json:"-"`
+}
+
+// Clone returns a deep copy of s, so changing one leaves the other as it is
+func (s *Settings) Clone() *Settings {
+	clone := *s
+	clone.Webhooks = slices.Clone(s.Webhooks)
+	if s.Clock != nil {
+		clock := *s.Clock
+		if clock.White != nil {
+			white := *clock.White
+			clock.White = &white
+		}
+		if clock.Black != nil {
+			black := *clock.Black
+			clock.Black = &black
+		}
+		clone.Clock = &clock
+	}
+	if s.InstantBot != nil {
+		bot := *s.InstantBot
+		clone.InstantBot = &bot
+	}
+	if s.Rules != nil {
+		houseRules := *s.Rules
+		clone.Rules = &houseRules
+	}
+	return &clone
+}
+
+// ForRemote returns a copy of s for a player connected over SSH: marked
+// Remote, and without the operator's hooks, webhooks, transcript, share
+// service or adjournment directory
+func (s *Settings) ForRemote() *Settings {
+	remote := s.Clone()
+	remote.Hooks = events.Hooks{}
+	remote.Webhooks = nil
+	remote.Transcript = ""
+	remote.Share = share.Config{}
+	remote.AdjournedDir = ""
+	remote.Remote = true
+	return remote
+}
+
+// Formatting returns the locale dates and numbers are written in
+func (s *Settings) Formatting() locale.Locale {
+	if s == nil || s.Locale == "" {
+		return locale.FromEnv()
+	}
+	loc, _ := locale.Parse(s.Locale)
+	return loc
+}
+
+// DefaultSettings returns the default display settings
+func DefaultSettings() *Settings {
+	return &Settings{
+		Accessible: false,
+		Palette:    "classic",
+		BoardStyle: BoardStyleStandard,
+	}
+}
+
+// DefaultSettingsPath returns the settings file location in the user's config directory
+func DefaultSettingsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "settings.json"
+	}
+	return filepath.Join(home, ".bubblechess", "settings.json")
+}
+
+// LoadSettings loads settings from a file, falling back to defaults if it doesn't exist
+func LoadSettings(path string) (*Settings, error) {
+	if path == "" {
+		path = DefaultSettingsPath()
+	}
+
+	settings := DefaultSettings()
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open settings file: %w", err)
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(settings); err != nil {
+		return nil, fmt.Errorf("failed to decode settings file: %w", err)
+	}
+	if settings.Rules != nil {
+		if err := settings.Rules.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid settings file: %w", err)
+		}
+	}
+	if settings.Locale != "" {
+		if _, err := locale.Parse(settings.Locale); err != nil {
+			return nil, fmt.Errorf("invalid settings file: %w", err)
+		}
+	}
+
+	return settings, nil
+}
+
+// SaveSettings saves settings to a file
+func SaveSettings(settings *Settings, path string) error {
+	if path == "" {
+		path = DefaultSettingsPath()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create settings directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create settings file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(settings); err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	return nil
+}
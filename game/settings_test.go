@@ -0,0 +1,72 @@
+package game
+
+import (
+	"os"
+	"testing"
+
+	"chess-tui/events"
+	"chess-tui/share"
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestForRemoteDropsTheOperatorsHooksAndCredentials(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Webhooks = []string{"https://hooks.example/move"}
+	settings.Hooks = events.Hooks{OnMove: "notify-send move"}
+	settings.Share = share.Config{Gist: true}
+	settings.Transcript = "transcript.log"
+	settings.AdjournedDir = "/home/operator/adjourned"
+	settings.Clock = &tournament.TimeControl{PerMove: 5}
+
+	remote := settings.ForRemote()
+	if !remote.Remote {
+		t.Error("Expected the copy to be marked remote")
+	}
+	if remote.Webhooks != nil || remote.Hooks != (events.Hooks{}) || remote.Share != (share.Config{}) {
+		t.Errorf("Expected no webhooks, hooks or share settings, got %v, %+v and %+v", remote.Webhooks, remote.Hooks, remote.Share)
+	}
+	if remote.Transcript != "" || remote.AdjournedDir != "" {
+		t.Errorf("Expected no transcript or adjournment directory, got %q and %q", remote.Transcript, remote.AdjournedDir)
+	}
+
+	remote.Clock.PerMove = 10
+	if settings.Clock.PerMove != 5 {
+		t.Errorf("Expected the operator's clock to be left alone, got %v", settings.Clock.PerMove)
+	}
+	if settings.Remote || len(settings.Webhooks) != 1 {
+		t.Error("Expected the operator's settings to be left alone")
+	}
+}
+
+func TestCloneCopiesSlices(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Webhooks = []string{"https://hooks.example/a"}
+	clone := settings.Clone()
+	clone.Webhooks[0] = "https://hooks.example/b"
+	if settings.Webhooks[0] != "https://hooks.example/a" {
+		t.Errorf("Expected the original webhooks to be left alone, got %v", settings.Webhooks)
+	}
+}
+
+func TestRemoteGamesWriteAndUploadNothing(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	settings := DefaultSettings().ForRemote()
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+	g.makeMove("e4")
+
+	for _, key := range []tea.KeyType{tea.KeyCtrlS, tea.KeyCtrlP, tea.KeyCtrlA, tea.KeyCtrlG} {
+		g.status = ""
+		if _, cmd := g.Update(tea.KeyMsg{Type: key}); cmd != nil {
+			t.Errorf("Expected nothing to run for %v", key)
+		}
+		if g.status == "" {
+			t.Errorf("Expected a status saying %v isn't available, got none", key)
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no files written, got %d", len(entries))
+	}
+}
@@ -0,0 +1,52 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chess-tui/share"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// shareResultMsg is the link to an uploaded game, or why the upload failed
+type shareResultMsg struct {
+	url string
+	err error
+}
+
+// sharePGN uploads the game's PGN to the configured paste service or gist
+func (g *Game) sharePGN() tea.Cmd {
+	if g.settings.Remote {
+		g.status = "Sharing isn't available over SSH"
+		return nil
+	}
+	uploader, err := share.New(g.settings.Share)
+	if err != nil {
+		g.err = err.Error()
+		return nil
+	}
+	g.err = ""
+	g.status = "Uploading PGN..."
+	pgn := g.PGN()
+	name := fmt.Sprintf("bubblechess-%s.pgn", time.Now().Format("20060102-150405"))
+	// The upload outlives a reset, as the game shared is already captured
+	root := g.root
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(root, 30*time.Second)
+		defer cancel()
+		url, err := uploader.Upload(ctx, name, pgn)
+		return shareResultMsg{url: url, err: err}
+	}
+}
+
+// applyShareResult shows the link to the shared game
+func (g *Game) applyShareResult(msg shareResultMsg) {
+	if msg.err != nil {
+		g.status = ""
+		g.err = msg.err.Error()
+		return
+	}
+	g.status = "Shared: " + msg.url
+}
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSharePGN(t *testing.T) {
+	var uploaded string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		uploaded = string(body)
+		io.WriteString(w, "https://paste.example/game")
+	}))
+	defer server.Close()
+
+	g := NewGame()
+	g.makeMove("e4")
+	g.settings.Share.Endpoint = server.URL
+	_, cmd := g.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	if cmd == nil {
+		t.Fatalf("Expected an upload, got error %q", g.err)
+	}
+	g.Update(cmd())
+
+	if g.status != "Shared: https://paste.example/game" {
+		t.Errorf("Expected the link in the status, got %q", g.status)
+	}
+	if !strings.Contains(uploaded, "1. e4") {
+		t.Errorf("Expected the PGN to be uploaded, got %q", uploaded)
+	}
+}
+
+func TestShareWithoutConfiguration(t *testing.T) {
+	g := NewGame()
+	if _, cmd := g.Update(tea.KeyMsg{Type: tea.KeyCtrlG}); cmd != nil {
+		t.Error("Expected no upload without a share configuration")
+	}
+	if !strings.Contains(g.err, "settings file") {
+		t.Errorf("Expected a hint to configure sharing, got %q", g.err)
+	}
+}
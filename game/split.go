@@ -0,0 +1,223 @@
+package game
+
+import (
+	"fmt"
+
+	"chess-tui/ai_player"
+	"chess-tui/notation"
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// splitEnginePlies is how far ahead the engine line on the analysis board
+// looks
+const splitEnginePlies = 6
+
+// splitView is an analysis board shown beside the live game, for exploring
+// variations while the game goes on. Unlike the analysis mode of [v], the
+// live game keeps running: the AI moves and the clock runs on the left.
+type splitView struct {
+	base    string // FEN of the live position the analysis started from
+	ply     int    // number of live moves then
+	root    *variationNode
+	current *variationNode
+	board   *chess.Game
+
+	// focused sends typed moves to the analysis board instead of the game
+	focused bool
+
+	engineLine []string // the engine's line from the analysis position, in SAN
+	engineEval int      // material after the engine line, from White's side
+}
+
+// toggleSplit opens the analysis board beside the game at the live
+// position, or closes it, saving its lines as variations of the game
+func (g *Game) toggleSplit() {
+	if g.split != nil {
+		g.closeSplit()
+		return
+	}
+	if g.analysis != nil {
+		return
+	}
+	ply := len(g.chessGame.Moves())
+	root := &variationNode{}
+	if saved, ok := g.variations[ply]; ok {
+		root.merge(saved)
+	}
+	g.split = &splitView{
+		base:    g.chessGame.Position().String(),
+		ply:     ply,
+		root:    root,
+		current: root,
+		focused: true,
+	}
+	g.rebuildSplitBoard()
+	g.status = "Analysis board open — ctrl+w switches between it and the game"
+}
+
+// closeSplit closes the analysis board, keeping its lines
+func (g *Game) closeSplit() {
+	s := g.split
+	if len(s.root.children) > 0 {
+		if g.variations == nil {
+			g.variations = make(map[int]*variationNode)
+		}
+		if saved, ok := g.variations[s.ply]; ok {
+			saved.merge(s.root)
+		} else {
+			g.variations[s.ply] = s.root
+		}
+	}
+	if s.focused {
+		g.input.SetValue("")
+	}
+	g.split = nil
+	g.err = ""
+}
+
+// syncSplit restarts the analysis board from the live position
+func (g *Game) syncSplit() {
+	focused := g.split.focused
+	g.closeSplit()
+	g.toggleSplit()
+	g.split.focused = focused
+	g.status = "Analysis board synced to the game"
+}
+
+// focusSplit moves the focus between the game and the analysis board.
+// Typed text stays with the side it was typed for.
+func (g *Game) focusSplit() {
+	s := g.split
+	s.focused = !s.focused
+	g.input.SetValue("")
+	g.err = ""
+	if s.focused {
+		g.status = "Analysis board focused — moves you type are explored, not played"
+	} else {
+		g.status = "Game focused"
+	}
+}
+
+// rebuildSplitBoard replays the current line onto the analysis board and
+// works out the engine's line from there
+func (g *Game) rebuildSplitBoard() {
+	s := g.split
+	option, err := chess.FEN(s.base)
+	if err != nil {
+		return
+	}
+	board := chess.NewGame(option, chess.UseNotation(chess.AlgebraicNotation{}))
+	for _, san := range s.current.path() {
+		if move, err := notation.Decode(board.Position(), san); err == nil {
+			board.Move(move)
+		}
+	}
+	s.board = board
+
+	s.engineLine = nil
+	position := board.Position()
+	for i := 0; i < splitEnginePlies && position.Status() == chess.NoMethod; i++ {
+		move, err := tournament.FallbackMove(position)
+		if err != nil {
+			break
+		}
+		s.engineLine = append(s.engineLine, notation.Encode(position, move))
+		position = position.Update(move)
+	}
+	s.engineEval = ai_player.MaterialBalance(position, chess.White)
+}
+
+// splitMove plays a move on the analysis board, extending its lines
+func (g *Game) splitMove(moveStr string) {
+	s := g.split
+	position := s.board.Position()
+	move, err := notation.Decode(position, moveStr)
+	if err != nil {
+		g.err = "can't play " + moveStr + " on the analysis board: " + notation.Explain(err)
+		return
+	}
+	g.err = ""
+	g.input.SetValue("")
+	s.current = s.current.child(notation.Encode(position, move))
+	g.rebuildSplitBoard()
+}
+
+// splitBack steps the analysis board back a move
+func (g *Game) splitBack() {
+	s := g.split
+	if s.current.parent != nil {
+		s.current = s.current.parent
+		g.rebuildSplitBoard()
+	}
+}
+
+// updateSplit handles the analysis board's keys; it reports whether the
+// key was one of them
+func (g *Game) updateSplit(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "ctrl+w":
+		g.focusSplit()
+		return true
+	}
+	if !g.split.focused {
+		return false
+	}
+	switch msg.String() {
+	case "enter":
+		if g.input.Value() != "" {
+			g.splitMove(g.input.Value())
+		}
+		return true
+	case "u":
+		g.splitBack()
+		return true
+	case "ctrl+r":
+		g.syncSplit()
+		return true
+	}
+	return false
+}
+
+// renderSplitPanel draws the analysis board beside the game, with its line
+// and the engine's
+func (g *Game) renderSplitPanel() string {
+	s := g.split
+	if s == nil {
+		return ""
+	}
+
+	// The board renderers draw g.chessGame, so lend them the analysis board
+	live := g.chessGame
+	g.chessGame = s.board
+	board := g.drawBoard()
+	g.chessGame = live
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#888888"))
+	title := "Analysis"
+	if s.focused {
+		titleStyle = titleStyle.Foreground(lipgloss.Color("#FFD700"))
+		title = "▶ Analysis"
+	}
+	line := formatLine(s.current.path(), s.ply)
+	if line == "" {
+		line = "from move " + moveNumber(s.ply)
+	}
+	engine := "Engine: game over"
+	if len(s.engineLine) > 0 {
+		engine = fmt.Sprintf("Engine: %s (%+.1f)", formatLine(s.engineLine, s.ply+len(s.current.path())), float64(s.engineEval)/100)
+	}
+
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	width := lipgloss.Width(board)
+	return lipgloss.NewStyle().MarginLeft(4).Render(lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(title),
+		board,
+		lipgloss.NewStyle().Width(width).Render("Line: "+line),
+		dim.Width(width).Render(engine),
+		dim.Render("[u] back, ctrl+r sync, [|] close"),
+	))
+}
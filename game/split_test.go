@@ -0,0 +1,72 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSplitViewExploresBesideTheGame(t *testing.T) {
+	g := NewGame()
+	g.makeMove("e4")
+
+	pressKey(g, "|")
+	if g.split == nil || !g.split.focused {
+		t.Fatalf("Expected the analysis board open and focused")
+	}
+	analyse(g, "c5")
+	analyse(g, "Nf3")
+	if g.err != "" {
+		t.Fatalf("Expected no error, got %s", g.err)
+	}
+	if len(g.chessGame.Moves()) != 1 || len(g.split.board.Moves()) != 2 {
+		t.Errorf("Expected the live game at 1 move and the analysis board 2 further, got %d and %d",
+			len(g.chessGame.Moves()), len(g.split.board.Moves()))
+	}
+	if len(g.split.engineLine) == 0 {
+		t.Errorf("Expected an engine line from the analysis position")
+	}
+	view := g.View()
+	if !strings.Contains(view, "▶ Analysis") || !strings.Contains(view, "Line: 1... c5 2. Nf3") {
+		t.Errorf("Expected the focused analysis board and its line in the view, got %s", view)
+	}
+
+	// The game takes typed moves again once focused
+	g.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	if g.split.focused {
+		t.Fatalf("Expected the game focused")
+	}
+	g.input.SetValue("e5")
+	pressKey(g, "enter")
+	if len(g.chessGame.Moves()) != 2 || len(g.split.board.Moves()) != 2 {
+		t.Errorf("Expected e5 played in the game only, got %d live moves", len(g.chessGame.Moves()))
+	}
+
+	// Closing keeps the lines as variations of the game
+	pressKey(g, "|")
+	if g.split != nil {
+		t.Fatalf("Expected the analysis board closed")
+	}
+	if pgn := g.PGN(); !strings.Contains(pgn, "(1... c5 2. Nf3)") {
+		t.Errorf("Expected the analysis as a variation in the PGN, got %s", pgn)
+	}
+}
+
+func TestSplitViewSync(t *testing.T) {
+	g := NewGame()
+	pressKey(g, "|")
+	analyse(g, "d4")
+	g.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	g.input.SetValue("e4")
+	pressKey(g, "enter")
+
+	g.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	g.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if g.split.ply != 1 || len(g.split.current.path()) != 0 || !g.split.focused {
+		t.Errorf("Expected the analysis board restarted, focused, from the live position after e4, got ply %d", g.split.ply)
+	}
+	if g.variations[0] == nil {
+		t.Errorf("Expected the line explored before the sync kept")
+	}
+}
@@ -0,0 +1,119 @@
+package game
+
+import "github.com/notnil/chess"
+
+// spriteSize is the width and height of a piece sprite in pixels
+const spriteSize = 16
+
+// pieceSprites are the image board's pieces. '#' is the piece's color, 'o'
+// its outline and '.' the square showing through.
+var pieceSprites = map[chess.PieceType][spriteSize]string{
+	chess.Pawn: {
+		"................",
+		"................",
+		"................",
+		"......oooo......",
+		".....o####o.....",
+		".....o####o.....",
+		"......o##o......",
+		".....o####o.....",
+		"......o##o......",
+		"......o##o......",
+		".....o####o.....",
+		"....o######o....",
+		"...o########o...",
+		"...oooooooooo...",
+		"................",
+		"................",
+	},
+	chess.Knight: {
+		"................",
+		"................",
+		"......oo.o......",
+		".....o##o#o.....",
+		"....o######o....",
+		"...o###o####o...",
+		"..o#########o...",
+		"..o###oo####o...",
+		"...ooo.o####o...",
+		"......o#####o...",
+		".....o######o...",
+		"....o#######o...",
+		"...o#########o..",
+		"...ooooooooooo..",
+		"................",
+		"................",
+	},
+	chess.Bishop: {
+		"................",
+		".......oo.......",
+		"......o##o......",
+		".......oo.......",
+		"......o##o......",
+		".....o##o#o.....",
+		"....o##o###o....",
+		"....o######o....",
+		".....o####o.....",
+		"......o##o......",
+		".....o####o.....",
+		"....o######o....",
+		"...o########o...",
+		"...oooooooooo...",
+		"................",
+		"................",
+	},
+	chess.Rook: {
+		"................",
+		"................",
+		"..ooo.oooo.ooo..",
+		"..o#o.o##o.o#o..",
+		"..o#ooo##ooo#o..",
+		"..o##########o..",
+		"...oo######oo...",
+		"....o######o....",
+		"....o######o....",
+		"....o######o....",
+		"....o######o....",
+		"...o########o...",
+		"..o##########o..",
+		"..oooooooooooo..",
+		"................",
+		"................",
+	},
+	chess.Queen: {
+		"................",
+		"..o...o..o...o..",
+		".o#o.o#oo#o.o#o.",
+		"..o#o.o##o.o#o..",
+		"..o##o####o##o..",
+		"..o##########o..",
+		"...o########o...",
+		"....o######o....",
+		"....o######o....",
+		"....o######o....",
+		"...o########o...",
+		"..o##########o..",
+		"..o##########o..",
+		"..oooooooooooo..",
+		"................",
+		"................",
+	},
+	chess.King: {
+		".......oo.......",
+		"......o##o......",
+		".....o####o.....",
+		"......o##o......",
+		"...ooo.oo.ooo...",
+		"..o###o##o###o..",
+		"..o##########o..",
+		"..o##########o..",
+		"...o########o...",
+		"....o######o....",
+		"....o######o....",
+		"....o######o....",
+		"...o########o...",
+		"..o##########o..",
+		"..oooooooooooo..",
+		"................",
+	},
+}
@@ -0,0 +1,153 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"chess-tui/gamedb"
+	"chess-tui/locale"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// statsOpenings is how many openings the stats screen lists
+const statsOpenings = 10
+
+// statsBarWidth is the width of a full bar on the stats screen
+const statsBarWidth = 20
+
+// Stats is the screen summarizing the human's games against the AI: the
+// score with each color and opening, the average game length and the most
+// common ways of losing, and heatmaps of every recorded game
+type Stats struct {
+	summary gamedb.Summary
+	games   [][]string // the moves of every recorded game
+	err     string
+
+	showHeatmap bool
+	heatmapKind gamedb.HeatmapKind
+
+	loc locale.Locale // how numbers are written
+}
+
+// NewStats creates the stats screen for the games recorded in db, with
+// numbers written for loc
+func NewStats(db *gamedb.DB, loc locale.Locale) *Stats {
+	records, err := db.Games()
+	if err != nil {
+		return &Stats{err: err.Error(), loc: loc}
+	}
+	return &Stats{summary: gamedb.Summarize(records), games: gamedb.RecordMoves(records), loc: loc}
+}
+
+// Init does nothing; the games are loaded when the screen is created
+func (s *Stats) Init() tea.Cmd {
+	return nil
+}
+
+// Update cycles the heatmaps on m and quits on q, esc or ctrl+c
+func (s *Stats) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "m":
+			nextHeatmap(&s.showHeatmap, &s.heatmapKind)
+		case "q", "esc", "ctrl+c":
+			return s, tea.Quit
+		}
+	}
+	return s, nil
+}
+
+// View renders the statistics as tables with bars
+func (s *Stats) View() string {
+	var sb strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700"))
+	headingStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00AAFF"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	sb.WriteString(titleStyle.Render("♔ Statistics ♛") + "\n\n")
+
+	switch {
+	case s.err != "":
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+s.err) + "\n")
+	case s.showHeatmap:
+		heatmap := gamedb.BuildHeatmap(s.heatmapKind, s.games...)
+		sb.WriteString(fmt.Sprintf("All %d recorded games\n\n", len(s.games)))
+		sb.WriteString(renderHeatmap(s.heatmapKind, heatmap, []int{7, 6, 5, 4, 3, 2, 1, 0}, []int{0, 1, 2, 3, 4, 5, 6, 7}) + "\n")
+	case s.summary.Games == 0:
+		sb.WriteString(helpStyle.Render("No games against the AI recorded yet.") + "\n")
+	default:
+		summary := s.summary
+		sb.WriteString(fmt.Sprintf("%s games, %s moves on average\n\n", s.loc.Int(summary.Games), s.loc.Float(summary.AverageLength, 1)))
+
+		sb.WriteString(headingStyle.Render("By color") + "\n")
+		for _, color := range []string{"white", "black"} {
+			score := summary.ByColor[color]
+			sb.WriteString(fmt.Sprintf("  %-5s %s %s\n", colorSymbol(color), scoreBar(score), scoreText(score, s.loc)))
+		}
+
+		sb.WriteString("\n" + headingStyle.Render("By opening") + "\n")
+		for i, stats := range summary.Openings {
+			if i == statsOpenings {
+				sb.WriteString(helpStyle.Render(fmt.Sprintf("  … and %d more", len(summary.Openings)-statsOpenings)) + "\n")
+				break
+			}
+			name := stats.Name
+			if len([]rune(name)) > 28 {
+				name = string([]rune(name)[:27]) + "…"
+			}
+			sb.WriteString(fmt.Sprintf("  %-3s %-28s %-5s %s %s\n", stats.ECO, name, colorSymbol(stats.Color), scoreBar(stats.Score), scoreText(stats.Score, s.loc)))
+		}
+
+		sb.WriteString("\n" + headingStyle.Render("How games were lost") + "\n")
+		if len(summary.LossPatterns) == 0 {
+			sb.WriteString(helpStyle.Render("  No losses yet") + "\n")
+		}
+		most := 0
+		for _, pattern := range summary.LossPatterns {
+			most = max(most, pattern.Count)
+		}
+		for _, pattern := range summary.LossPatterns {
+			bar := strings.Repeat("█", max(1, pattern.Count*statsBarWidth/most))
+			sb.WriteString(fmt.Sprintf("  %-30s %-*s %d\n", pattern.Description, statsBarWidth, bar, pattern.Count))
+		}
+	}
+
+	sb.WriteString("\n" + helpStyle.Render("Press m to cycle heatmaps, q to quit"))
+	return sb.String()
+}
+
+// scoreBar draws a score as a bar of wins, draws and losses
+func scoreBar(score gamedb.Score) string {
+	games := score.Games()
+	if games == 0 {
+		return strings.Repeat("·", statsBarWidth)
+	}
+	wins := score.Wins * statsBarWidth / games
+	draws := score.Draws * statsBarWidth / games
+	losses := statsBarWidth - wins - draws
+
+	win := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
+	draw := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	loss := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+	return win.Render(strings.Repeat("█", wins)) + draw.Render(strings.Repeat("▒", draws)) + loss.Render(strings.Repeat("░", losses))
+}
+
+// scoreText formats a score with its percentage, e.g. "3W 1L 2D (67%)"
+func scoreText(score gamedb.Score, loc locale.Locale) string {
+	if score.Games() == 0 {
+		return "no games"
+	}
+	percent := (float64(score.Wins) + float64(score.Draws)/2) * 100 / float64(score.Games())
+	return fmt.Sprintf("%s (%s)", score, loc.Percent(percent, 0))
+}
+
+// colorSymbol returns a king of the given color name with its initial
+func colorSymbol(color string) string {
+	if color == "black" {
+		return "♚ B"
+	}
+	return "♔ W"
+}
@@ -0,0 +1,39 @@
+package game
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chess-tui/gamedb"
+	"chess-tui/locale"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestStatsScreen(t *testing.T) {
+	db := gamedb.Open(filepath.Join(t.TempDir(), "games.jsonl"))
+
+	if view := NewStats(db, locale.C).View(); !strings.Contains(view, "No games") {
+		t.Errorf("Expected an empty summary, got:\n%s", view)
+	}
+
+	db.Add(gamedb.Record{HumanColor: "white", Opponent: "AI", Result: gamedb.BlackWon, Termination: "Checkmate", Moves: []string{"f3", "e5", "g4", "Qh4#"}})
+	db.Add(gamedb.Record{HumanColor: "black", Opponent: "AI", Result: gamedb.BlackWon, Moves: []string{"e4", "c5"}})
+
+	menu := NewMenu()
+	menu.SetGameDB(db)
+	menu.cursor = 4
+	model, _ := menu.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	stats, ok := model.(*Stats)
+	if !ok {
+		t.Fatalf("Expected the stats screen, got %T", model)
+	}
+
+	view := stats.View()
+	for _, want := range []string{"2 games", "By color", "By opening", "Checkmated in the opening"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the stats screen, got:\n%s", want, view)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package game
+
+import (
+	"errors"
+	"strings"
+
+	"chess-tui/ai_player"
+	"chess-tui/notation"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// methodNames maps game-ending methods to readable names
+var methodNames = map[chess.Method]string{
+	chess.Checkmate:            "checkmate",
+	chess.Resignation:          "resignation",
+	chess.DrawOffer:            "agreement",
+	chess.Stalemate:            "stalemate",
+	chess.ThreefoldRepetition:  "threefold repetition",
+	chess.FivefoldRepetition:   "fivefold repetition",
+	chess.FiftyMoveRule:        "fifty-move rule",
+	chess.SeventyFiveMoveRule:  "seventy-five-move rule",
+	chess.InsufficientMaterial: "insufficient material",
+}
+
+// methodName returns a readable name for how the game ended
+func methodName(method chess.Method) string {
+	if name, ok := methodNames[method]; ok {
+		return name
+	}
+	return "unknown means"
+}
+
+// inCheck reports whether the side to move is in check
+func (g *Game) inCheck() bool {
+	moves := g.chessGame.Moves()
+	return len(moves) > 0 && moves[len(moves)-1].HasTag(chess.Check)
+}
+
+// lastMoveSAN returns the last move in standard algebraic notation
+func (g *Game) lastMoveSAN() string {
+	sans := g.sanMoves()
+	if len(sans) == 0 {
+		return ""
+	}
+	return sans[len(sans)-1]
+}
+
+// needsPromotionPiece reports whether moveStr is a legal pawn move to the
+// last rank that only lacks the promotion piece
+func (g *Game) needsPromotionPiece(moveStr string) bool {
+	_, err := notation.Decode(g.chessGame.Position(), moveStr)
+	return errors.Is(err, notation.ErrPromotionMissing)
+}
+
+// promotionPiece parses the piece chosen for a pending promotion
+func promotionPiece(input string) (string, bool) {
+	piece := strings.ToUpper(strings.TrimSpace(input))
+	switch piece {
+	case "Q", "R", "B", "N":
+		return piece, true
+	}
+	return "", false
+}
+
+// offerDraw offers a draw for the side to move, or accepts the opponent's offer
+func (g *Game) offerDraw() {
+	if g.chessGame.Outcome() != chess.NoOutcome {
+		return
+	}
+
+	turn := g.chessGame.Position().Turn()
+	switch g.drawOffer {
+	case chess.NoColor:
+		g.drawOffer = turn
+	case turn.Other():
+		if err := g.chessGame.Draw(chess.DrawOffer); err != nil {
+			g.err = err.Error()
+			return
+		}
+		g.drawOffer = chess.NoColor
+	}
+
+	g.updateStatus()
+}
+
+// declineDrawOffer clears an offer made by the opponent of mover, since
+// moving instead of accepting declines it
+func (g *Game) declineDrawOffer(mover chess.Color) {
+	if g.drawOffer == mover.Other() {
+		g.drawOffer = chess.NoColor
+	}
+}
+
+// drawOfferReceiver is a MoveGenerator that can be told of the player's
+// draw offer, so the AI can accept it
+type drawOfferReceiver interface {
+	SetDrawOffered(offered bool)
+}
+
+// applyAIDecision ends the game on the AI's resignation or its acceptance
+// of the player's draw offer. An acceptance of an offer since withdrawn
+// asks the AI for a move instead.
+func (g *Game) applyAIDecision(result *AIMoveResult) tea.Cmd {
+	aiColor := g.chessGame.Position().Turn()
+	switch result.Action {
+	case ai_player.ActionResign:
+		g.log.Debug("AI resigns", "reason", result.ActionReason)
+		g.chessGame.Resign(aiColor)
+	case ai_player.ActionAcceptDraw:
+		if g.drawOffer != aiColor.Other() {
+			g.log.Debug("AI accepted a draw that is no longer offered")
+			return g.getAIMove()
+		}
+		g.log.Debug("AI accepts the draw", "reason", result.ActionReason)
+		if err := g.chessGame.Draw(chess.DrawOffer); err != nil {
+			g.err = err.Error()
+			return nil
+		}
+	}
+
+	g.aiReasoning = result.ActionReason
+	g.drawOffer = chess.NoColor
+	g.isAITurn = false
+	g.aiMovePending = false
+	g.updateStatus()
+	return nil
+}
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// teachPanelWidth is the width of the teach mode side panel
+const teachPanelWidth = 34
+
+// teachResultMsg carries the AI's candidate moves for the position with the given FEN
+type teachResultMsg struct {
+	fen        string
+	candidates []CandidateMove
+	err        error
+}
+
+// requestCandidates asks the AI for candidate moves for the human to move
+func (g *Game) requestCandidates() tea.Cmd {
+	if g.ai == nil || g.isAITurn || g.chessGame.Outcome() != chess.NoOutcome {
+		return nil
+	}
+	if reason := g.refuseHint(); reason != "" {
+		g.status = reason
+		return nil
+	}
+
+	g.teachPending = true
+	g.teachErr = ""
+
+	fen := g.getBoardState()
+	history := append([]string(nil), g.gameHistory...)
+	color := "white"
+	if g.chessGame.Position().Turn() == chess.Black {
+		color = "black"
+	}
+
+	generator := g.ai
+	return func() tea.Msg {
+		candidates, err := generator.SuggestMoves(fen, history, color)
+		return teachResultMsg{fen: fen, candidates: candidates, err: err}
+	}
+}
+
+// applyCandidates shows the candidates unless the position has moved on
+func (g *Game) applyCandidates(msg teachResultMsg) {
+	if msg.fen != g.getBoardState() {
+		return
+	}
+	g.teachPending = false
+	if msg.err != nil {
+		g.teachErr = msg.err.Error()
+		return
+	}
+	g.teachCandidates = msg.candidates
+	g.spendHint()
+}
+
+// clearCandidates hides the teach panel once the position changes
+func (g *Game) clearCandidates() {
+	g.teachCandidates = nil
+	g.teachPending = false
+	g.teachErr = ""
+}
+
+// renderTeachPanel renders the candidate moves side panel
+func (g *Game) renderTeachPanel() string {
+	if !g.teachPending && g.teachErr == "" && len(g.teachCandidates) == 0 {
+		return ""
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00AAFF"))
+	moveStyle := lipgloss.NewStyle().Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA")).Width(teachPanelWidth - 2)
+
+	var sb strings.Builder
+	header := "Coach"
+	if g.consult != nil {
+		header = "Advisor"
+	}
+	sb.WriteString(headerStyle.Render(header) + "\n")
+
+	switch {
+	case g.teachPending:
+		sb.WriteString(textStyle.Render("Looking for ideas..."))
+	case g.teachErr != "":
+		sb.WriteString(textStyle.Render("No suggestions: " + g.teachErr))
+	default:
+		for i, candidate := range g.teachCandidates {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(moveStyle.Render(g.formatMove(candidate.Move, g.chessGame.Position().Turn())) + "\n")
+			sb.WriteString(textStyle.Render(candidate.Explanation))
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Width(teachPanelWidth).
+		MarginLeft(2).
+		Render(sb.String())
+}
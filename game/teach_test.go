@@ -0,0 +1,64 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeGenerator is a MoveGenerator with canned answers
+type fakeGenerator struct {
+	candidates []CandidateMove
+	err        error
+}
+
+func (f *fakeGenerator) GetAIMoveResult(boardState string, gameHistory []string, errorMsg string, playerColor string) (*AIMoveResult, error) {
+	return nil, errors.New("not used")
+}
+
+func (f *fakeGenerator) SetPersonality(name string) {}
+
+func (f *fakeGenerator) SuggestMoves(boardState string, gameHistory []string, playerColor string) ([]CandidateMove, error) {
+	return f.candidates, f.err
+}
+
+func TestTeachPanel(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	g.SetMoveGenerator(&fakeGenerator{candidates: []CandidateMove{
+		{Move: "e4", Explanation: "Claims the center."},
+		{Move: "Nf3", Explanation: "Develops a knight."},
+	}})
+
+	cmd := g.requestCandidates()
+	if cmd == nil {
+		t.Fatal("Expected a command requesting candidates")
+	}
+	if !strings.Contains(g.renderTeachPanel(), "Looking for ideas") {
+		t.Errorf("Expected a pending panel while waiting")
+	}
+
+	g.Update(cmd())
+	panel := g.renderTeachPanel()
+	if !strings.Contains(panel, "Nf3") || !strings.Contains(panel, "Claims the center.") {
+		t.Errorf("Expected candidates in the panel, got %q", panel)
+	}
+
+	// Moving clears the suggestions
+	g.makeMove("e4")
+	if g.renderTeachPanel() != "" {
+		t.Errorf("Expected the panel to clear after a move")
+	}
+}
+
+func TestTeachResultForStalePosition(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	g.SetMoveGenerator(&fakeGenerator{candidates: []CandidateMove{{Move: "e4"}}})
+
+	msg := g.requestCandidates()()
+	g.makeMove("d4")
+	g.Update(msg)
+
+	if len(g.teachCandidates) != 0 {
+		t.Errorf("Expected candidates for an old position to be ignored")
+	}
+}
@@ -0,0 +1,193 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/notnil/chess"
+)
+
+// Palette defines the colors used to draw the board
+type Palette struct {
+	LightSquare string
+	DarkSquare  string
+	WhitePiece  string
+	BlackPiece  string
+	LastMove    string
+	Check       string
+	Selected    string
+}
+
+// palettes holds the built-in board palettes by name
+var palettes = map[string]Palette{
+	"classic": {
+		LightSquare: "#F0D9B5",
+		DarkSquare:  "#B58863",
+		WhitePiece:  "#FFFFFF",
+		BlackPiece:  "#000000",
+		LastMove:    "#CDD26A",
+		Check:       "#FF5555",
+		Selected:    "#7FA650",
+	},
+	// Okabe-Ito based palette; squares differ in lightness, not just hue,
+	// and highlights avoid red/green pairs
+	"colorblind": {
+		LightSquare: "#E8E8E8",
+		DarkSquare:  "#5F87AF",
+		WhitePiece:  "#FFFFFF",
+		BlackPiece:  "#000000",
+		LastMove:    "#E69F00",
+		Check:       "#CC79A7",
+		Selected:    "#56B4E9",
+	},
+	"high-contrast": {
+		LightSquare: "#FFFFFF",
+		DarkSquare:  "#444444",
+		WhitePiece:  "#FFD700",
+		BlackPiece:  "#000000",
+		LastMove:    "#00AAFF",
+		Check:       "#FF00FF",
+		Selected:    "#00FF00",
+	},
+}
+
+// PaletteNames returns the names of the built-in palettes in sorted order
+func PaletteNames() []string {
+	names := make([]string, 0, len(palettes))
+	for name := range palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// paletteFor returns the named palette, falling back to classic
+func paletteFor(name string) Palette {
+	if palette, ok := palettes[name]; ok {
+		return palette
+	}
+	return palettes["classic"]
+}
+
+// nextPalette returns the palette name following the given one
+func nextPalette(name string) string {
+	names := PaletteNames()
+	for i, n := range names {
+		if n == name {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return names[0]
+}
+
+// colorEnabled reports whether the terminal shows colors. Lipgloss already
+// honors NO_COLOR and terminal capability detection when picking a profile.
+func colorEnabled() bool {
+	return lipgloss.ColorProfile() != termenv.Ascii
+}
+
+// squareMark is a non-color cue drawn around a highlighted square
+type squareMark int
+
+const (
+	markNone squareMark = iota
+	markLastMove
+	markSelected
+	markCheck
+	markTarget
+	markAnnotated
+	markPattern
+	markExploded
+)
+
+// decorate wraps a piece symbol in the bracket characters for a mark, so
+// highlights remain visible without color
+func (m squareMark) decorate(symbol string) string {
+	switch m {
+	case markLastMove:
+		return "[" + symbol + "]"
+	case markSelected:
+		return "{" + symbol + "}"
+	case markCheck:
+		return "!" + symbol + "!"
+	case markTarget:
+		return "(" + symbol + ")"
+	case markAnnotated:
+		return "<" + symbol + ">"
+	case markPattern:
+		return "+" + symbol + "+"
+	case markExploded:
+		return "*" + symbol + "*"
+	default:
+		return " " + symbol + " "
+	}
+}
+
+// squareMarks returns the highlighted squares for the current position
+func (g *Game) squareMarks() map[chess.Square]squareMark {
+	marks := make(map[chess.Square]squareMark)
+
+	moves := g.chessGame.Moves()
+	if len(moves) > 0 {
+		last := moves[len(moves)-1]
+		marks[last.S1()] = markLastMove
+		marks[last.S2()] = markLastMove
+
+		if last.HasTag(chess.Check) {
+			board := g.chessGame.Position().Board()
+			king := chess.NewPiece(chess.King, g.chessGame.Position().Turn())
+			for sq := 0; sq < 64; sq++ {
+				if board.Piece(chess.Square(sq)) == king {
+					marks[chess.Square(sq)] = markCheck
+				}
+			}
+		}
+	}
+
+	// Squares to move to, such as a tutorial's targets
+	for _, square := range g.targets {
+		marks[square] = markTarget
+	}
+	for _, square := range g.exploded {
+		marks[square] = markExploded
+	}
+
+	// Squares tinted by the annotations, and the drawing mode's cursor
+	for _, a := range g.currentAnnotations() {
+		for _, square := range []chess.Square{a.from, a.to} {
+			if marks[square] == markNone {
+				marks[square] = markAnnotated
+			}
+		}
+	}
+	if g.annotating != nil {
+		marks[g.annotating.cursor] = markSelected
+		if g.annotating.anchored {
+			marks[g.annotating.from] = markSelected
+		}
+	}
+
+	if g.picking != nil {
+		for _, square := range g.pickerTargets() {
+			marks[square] = markTarget
+		}
+		if g.picking.holding {
+			marks[g.picking.from] = markSelected
+		}
+		for _, square := range g.hintPattern() {
+			marks[square] = markPattern
+		}
+		marks[g.picking.cursor] = markSelected
+	}
+
+	if g.selected != "" {
+		for sq := 0; sq < 64; sq++ {
+			if chess.Square(sq).String() == g.selected {
+				marks[chess.Square(sq)] = markSelected
+			}
+		}
+	}
+
+	return marks
+}
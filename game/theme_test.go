@@ -0,0 +1,95 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestSquareMarks(t *testing.T) {
+	g := NewGame()
+
+	for _, move := range []string{"e4", "f5", "Qh5+"} {
+		if err := g.chessGame.MoveStr(move); err != nil {
+			t.Fatalf("Failed to make move '%s': %v", move, err)
+		}
+	}
+
+	marks := g.squareMarks()
+	if marks[chess.D1] != markLastMove || marks[chess.H5] != markLastMove {
+		t.Errorf("Expected last move squares d1 and h5 to be marked, got %v", marks)
+	}
+	if marks[chess.E8] != markCheck {
+		t.Errorf("Expected black king on e8 to be marked as in check, got %v", marks[chess.E8])
+	}
+	if markCheck.decorate("♚") != "!♚!" {
+		t.Errorf("Expected check cue '!♚!', got '%s'", markCheck.decorate("♚"))
+	}
+}
+
+func TestNextPalette(t *testing.T) {
+	seen := map[string]bool{}
+	name := "classic"
+	for range PaletteNames() {
+		seen[name] = true
+		name = nextPalette(name)
+	}
+	if len(seen) != len(PaletteNames()) {
+		t.Errorf("Expected to cycle through all palettes, saw %v", seen)
+	}
+	if paletteFor("unknown") != paletteFor("classic") {
+		t.Error("Expected unknown palette to fall back to classic")
+	}
+}
+
+func TestRenderMoveListFigurine(t *testing.T) {
+	g := NewGameWithSettings(ModeHumanVsHuman, &Settings{Figurine: true})
+
+	for _, move := range []string{"e4", "e5", "Nf3", "Nc6"} {
+		if err := g.chessGame.MoveStr(move); err != nil {
+			t.Fatalf("Failed to make move '%s': %v", move, err)
+		}
+	}
+
+	list := g.renderMoveList()
+	if !strings.Contains(list, "♘f3") || !strings.Contains(list, "♞c6") {
+		t.Errorf("Expected figurine moves in move list, got:\n%s", list)
+	}
+
+	g.settings.Figurine = false
+	list = g.renderMoveList()
+	if !strings.Contains(list, "Nf3") {
+		t.Errorf("Expected letter moves in move list, got:\n%s", list)
+	}
+}
+
+func TestRenderLargeBoard(t *testing.T) {
+	g := NewGameWithSettings(ModeHumanVsHuman, &Settings{BoardStyle: BoardStyleLarge})
+
+	lines := strings.Split(g.renderBoard(), "\n")
+	if len(lines) != 16 {
+		t.Fatalf("Expected two rows per rank (16 lines), got %d", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "a8") {
+		t.Errorf("Expected first square coordinate 'a8' in the corner, got '%s'", lines[0])
+	}
+	if !strings.Contains(lines[1], "♜") {
+		t.Errorf("Expected black rook on the piece row, got '%s'", lines[1])
+	}
+}
+
+func TestZenView(t *testing.T) {
+	g := NewGame()
+	g.settings.Zen = true
+
+	view := g.View()
+	for _, hidden := range []string{"Chess TUI", "Mode:", "Commands:", "DEBUG"} {
+		if strings.Contains(view, hidden) {
+			t.Errorf("Expected zen view to hide %q", hidden)
+		}
+	}
+	if !strings.Contains(view, g.input.View()) {
+		t.Errorf("Expected zen view to keep the input line")
+	}
+}
@@ -0,0 +1,35 @@
+package game
+
+import (
+	"time"
+
+	"chess-tui/events"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// recordMoveTime adds the time taken over the move just made to the game's
+// time usage, counted from the move before
+func (g *Game) recordMoveTime(event events.Event) {
+	g.moveTimes = append(g.moveTimes, event.Time.Sub(g.lastMoveAt))
+	g.lastMoveAt = event.Time
+}
+
+// startClock starts timing the first move of a game
+func (g *Game) startClock() {
+	g.moveTimes = nil
+	g.lastMoveAt = time.Now()
+	g.startedAt = g.lastMoveAt
+}
+
+// renderTimePanel charts where each side spent its time, once the game is over
+func (g *Game) renderTimePanel() string {
+	if g.chessGame.Outcome() == chess.NoOutcome || len(g.moveTimes) == 0 {
+		return ""
+	}
+	title := lipgloss.NewStyle().Bold(true).Render("Time per move")
+	control, _ := g.clockControl()
+	chart := g.moveTimes.Chart(control)
+	return title + "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF")).Render(chart)
+}
@@ -0,0 +1,30 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTimeChartAfterGame(t *testing.T) {
+	g := NewGame()
+	for _, move := range []string{"f3", "e5", "g4"} {
+		g.makeMove(move)
+	}
+	if len(g.moveTimes) != 3 {
+		t.Fatalf("Expected the time of 3 moves, got %v", g.moveTimes)
+	}
+	if strings.Contains(g.View(), "Time per move") {
+		t.Error("Expected no time chart before the game ends")
+	}
+
+	g.makeMove("Qh4#")
+	view := g.View()
+	if !strings.Contains(view, "Time per move") || !strings.Contains(view, "avg") {
+		t.Error("Expected the time chart once the game is over")
+	}
+
+	g.resetGame()
+	if len(g.moveTimes) != 0 {
+		t.Errorf("Expected reset to clear the move times, got %v", g.moveTimes)
+	}
+}
@@ -0,0 +1,45 @@
+package game
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// appTitle is the terminal title outside a game
+const appTitle = "bubblechess"
+
+// windowTitle is the terminal title for the game: whose move it is, or how
+// the game ended
+func (g *Game) windowTitle() string {
+	switch {
+	case g.paused:
+		return appTitle + " — paused"
+	case g.chessGame.Outcome() == chess.WhiteWon:
+		return appTitle + " — White wins"
+	case g.chessGame.Outcome() == chess.BlackWon:
+		return appTitle + " — Black wins"
+	case g.chessGame.Outcome() == chess.Draw:
+		return appTitle + " — Draw"
+	}
+	return appTitle + " — " + g.chessGame.Position().Turn().Name() + " to move"
+}
+
+// titleCmd sets the terminal title when it has changed, unless the
+// settings turn titles off. Once the game has set it, Update keeps it up to
+// date.
+func (g *Game) titleCmd() tea.Cmd {
+	title := g.windowTitle()
+	if g.settings.NoTitle || title == g.title {
+		return nil
+	}
+	g.title = title
+	return tea.SetWindowTitle(title)
+}
+
+// appTitleCmd sets the terminal title shown outside a game
+func appTitleCmd(settings *Settings) tea.Cmd {
+	if settings.NoTitle {
+		return nil
+	}
+	return tea.SetWindowTitle(appTitle)
+}
@@ -0,0 +1,45 @@
+package game
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestWindowTitle(t *testing.T) {
+	g := NewGameWithSettings(ModeHumanVsHuman, DefaultSettings())
+	if got := g.windowTitle(); got != "bubblechess — White to move" {
+		t.Errorf("Expected white to move in the title, got %q", got)
+	}
+	if g.titleCmd() == nil {
+		t.Error("Expected the title set at the start")
+	}
+	if g.titleCmd() != nil {
+		t.Error("Expected the title left alone while it hasn't changed")
+	}
+
+	g.input.SetValue("e4")
+	if _, cmd := g.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil {
+		t.Error("Expected a move to update the title")
+	}
+	if g.title != "bubblechess — Black to move" {
+		t.Errorf("Expected black to move in the title, got %q", g.title)
+	}
+
+	for _, move := range []string{"e5", "Qh5", "Nc6", "Bc4", "Nf6", "Qxf7#"} {
+		g.makeMove(move)
+	}
+	if got := g.windowTitle(); got != "bubblechess — White wins" {
+		t.Errorf("Expected the result in the title, got %q", got)
+	}
+}
+
+func TestWindowTitleOptOut(t *testing.T) {
+	g := NewGameWithSettings(ModeHumanVsHuman, &Settings{NoTitle: true})
+	if g.titleCmd() != nil {
+		t.Error("Expected no title with titles turned off")
+	}
+	if appTitleCmd(g.settings) != nil {
+		t.Error("Expected no menu title with titles turned off")
+	}
+}
@@ -0,0 +1,168 @@
+package game
+
+import (
+	"fmt"
+
+	"chess-tui/notation"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// picker is the keyboard-driven move mode: a cursor moves over the board,
+// picking up a piece on one square and putting it down on another
+type picker struct {
+	cursor  chess.Square
+	from    chess.Square // the square of the piece picked up
+	holding bool         // a piece is picked up
+	touched bool         // under the touch-move rule, the piece must be moved
+}
+
+// startPicking enters the move mode on the side to move's pieces
+func (g *Game) startPicking() {
+	switch {
+	case g.chessGame.Outcome() != chess.NoOutcome:
+		g.status = "The game is over"
+		return
+	case g.isAITurn || g.awaitingPeer():
+		g.status = "Wait for your opponent's move"
+		return
+	case g.analysis != nil:
+		g.status = "Pieces are moved with the cursor on the game, not the analysis board"
+		return
+	}
+	g.clearExplanation()
+	cursor := chess.E2
+	if g.chessGame.Position().Turn() == chess.Black {
+		cursor = chess.E7
+	}
+	g.picking = &picker{cursor: cursor}
+	g.updatePickerStatus()
+}
+
+// updatePicker handles a key in the move mode
+func (g *Game) updatePicker(msg tea.KeyMsg) tea.Cmd {
+	p := g.picking
+	g.err = ""
+	switch key := msg.String(); key {
+	case "up", "k", "down", "j", "left", "h", "right", "l":
+		p.cursor = g.moveCursor(p.cursor, key)
+	case " ", "enter":
+		if p.holding && p.cursor != p.from {
+			return g.putDown()
+		}
+		g.pickUp()
+	case "esc", "tab":
+		if p.touched {
+			g.err = fmt.Sprintf("touch-move: the piece on %s must be moved", p.from)
+			break
+		}
+		if p.holding {
+			p.holding = false
+			break
+		}
+		g.picking = nil
+		g.updateStatus()
+		return nil
+	case "?":
+		g.settings.BeginnerHints = !g.settings.BeginnerHints
+	case "ctrl+c":
+		g.shutdown()
+		return tea.Quit
+	}
+	g.updatePickerStatus()
+	return nil
+}
+
+// pickUp picks up the piece under the cursor, or puts the held piece back.
+// Under the touch-move rule a piece with a legal move, once picked up, has
+// to be moved.
+func (g *Game) pickUp() {
+	p := g.picking
+	position := g.chessGame.Position()
+	piece := position.Board().Piece(p.cursor)
+	switch {
+	case p.holding && p.touched:
+		g.err = fmt.Sprintf("touch-move: the piece on %s must be moved", p.from)
+		return
+	case p.holding:
+		p.holding = false
+		return
+	case piece == chess.NoPiece:
+		g.err = fmt.Sprintf("no piece on %s", p.cursor)
+		return
+	case piece.Color() != position.Turn():
+		g.err = fmt.Sprintf("the piece on %s is %s's", p.cursor, piece.Color().Name())
+		return
+	}
+
+	p.from, p.holding = p.cursor, true
+	if g.settings.TouchMove && len(g.pickerTargets()) > 0 {
+		p.touched = true
+		g.log.Info("Piece touched", "square", p.from.String())
+	}
+}
+
+// putDown moves the held piece to the square under the cursor
+func (g *Game) putDown() tea.Cmd {
+	p := g.picking
+	position := g.chessGame.Position()
+	if piece := position.Board().Piece(p.cursor); piece != chess.NoPiece && piece.Color() == position.Turn() && !p.touched {
+		// Pick up another piece instead
+		p.holding = false
+		g.pickUp()
+		g.updatePickerStatus()
+		return nil
+	}
+	var move *chess.Move
+	for _, candidate := range position.ValidMoves() {
+		if candidate.S1() == p.from && candidate.S2() == p.cursor {
+			move = candidate
+			break
+		}
+	}
+	if move == nil {
+		g.err = fmt.Sprintf("the piece on %s can't move to %s", p.from, p.cursor)
+		g.updatePickerStatus()
+		return nil
+	}
+
+	// A promotion is played without its piece, to be completed as a typed
+	// one would be
+	uci := p.from.String() + p.cursor.String()
+	if move.Promo() == chess.NoPieceType {
+		uci = notation.EncodeUCI(move)
+	}
+	g.picking = nil
+	return g.submitMove(uci, false)
+}
+
+// pickerTargets returns the squares the held piece can move to
+func (g *Game) pickerTargets() []chess.Square {
+	p := g.picking
+	if p == nil || !p.holding {
+		return nil
+	}
+	var targets []chess.Square
+	for _, move := range g.chessGame.Position().ValidMoves() {
+		if move.S1() == p.from {
+			targets = append(targets, move.S2())
+		}
+	}
+	return targets
+}
+
+// updatePickerStatus explains the move mode in the status line
+func (g *Game) updatePickerStatus() {
+	p := g.picking
+	switch {
+	case p.touched:
+		g.status = fmt.Sprintf("Touch-move — the piece on %s must be moved: arrows/hjkl choose a square, space moves", p.from)
+	case p.holding:
+		g.status = fmt.Sprintf("Move the piece on %s — arrows/hjkl choose a square, space moves, esc puts it back", p.from)
+	case g.hinting():
+		g.status = g.hintStatus()
+	default:
+		g.status = fmt.Sprintf("Move — cursor at %s: arrows/hjkl move, space picks up a piece, ? piece hints, esc done", p.cursor)
+	}
+}
@@ -0,0 +1,70 @@
+package game
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pressKeys sends keys to the game one at a time
+func pressKeys(g *Game, keys ...tea.KeyMsg) {
+	for _, key := range keys {
+		g.Update(key)
+	}
+}
+
+var (
+	keyUp    = tea.KeyMsg{Type: tea.KeyUp}
+	keyDown  = tea.KeyMsg{Type: tea.KeyDown}
+	keyLeft  = tea.KeyMsg{Type: tea.KeyLeft}
+	keyRight = tea.KeyMsg{Type: tea.KeyRight}
+	keySpace = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	keyEsc   = tea.KeyMsg{Type: tea.KeyEsc}
+	keyTab   = tea.KeyMsg{Type: tea.KeyTab}
+)
+
+func TestPickerMovesPiece(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsHuman)
+	pressKeys(g, keyTab, keyRight, keyRight, keyDown, keySpace)
+	if g.picking == nil || !g.picking.holding || g.picking.from.String() != "g1" {
+		t.Fatalf("Expected the knight on g1 picked up, got %+v", g.picking)
+	}
+
+	// Without tournament rules the piece can be put back
+	pressKeys(g, keyEsc, keyEsc)
+	if g.picking != nil || len(g.gameHistory) != 0 {
+		t.Fatalf("Expected the move mode left without a move, got %v", g.gameHistory)
+	}
+
+	pressKeys(g, keyTab, keyRight, keyRight, keyDown, keySpace, keyUp, keyUp, keyLeft, keySpace)
+	if last := g.lastMoveSAN(); last != "Nf3" || g.picking != nil {
+		t.Errorf("Expected Nf3 played with the cursor, got %q", last)
+	}
+}
+
+func TestTouchMove(t *testing.T) {
+	settings := DefaultSettings()
+	settings.TouchMove = true
+	g := NewGameWithSettings(ModeHumanVsHuman, settings)
+
+	// A piece without a legal move isn't binding
+	pressKeys(g, keyTab, keyLeft, keyLeft, keyLeft, keyLeft, keyDown, keySpace)
+	if g.picking.touched {
+		t.Fatal("Expected the rook on a1 not to be binding")
+	}
+	pressKeys(g, keySpace)
+
+	pressKeys(g, keyRight, keySpace)
+	if !g.picking.touched || g.picking.from.String() != "b1" {
+		t.Fatalf("Expected the knight on b1 to be touched, got %+v", g.picking)
+	}
+	pressKeys(g, keyEsc, keyEsc, keyRight, keyRight, keyRight, keySpace)
+	if g.picking == nil || g.picking.from.String() != "b1" || g.err == "" {
+		t.Fatalf("Expected the knight to stay in hand, got %+v and error %q", g.picking, g.err)
+	}
+
+	pressKeys(g, keyLeft, keyLeft, keyUp, keyUp, keySpace)
+	if last := g.lastMoveSAN(); last != "Nc3" {
+		t.Errorf("Expected the touched knight moved to c3, got %q", last)
+	}
+}
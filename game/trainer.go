@@ -0,0 +1,338 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"chess-tui/notation"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// trainerRounds is how many moves a notation training session asks about
+const trainerRounds = 10
+
+// trainerQuestion is a move in a position: shown on the board to be named
+// in SAN, or named in SAN to be found on the board
+type trainerQuestion struct {
+	fen      string
+	from, to chess.Square
+	san      string
+	find     bool // the SAN is shown and the squares asked for
+}
+
+// NotationTrainer is the screen that drills algebraic notation: it
+// highlights a move and asks for its SAN, or gives the SAN and asks for
+// the move's squares, alternating, and keeps score
+type NotationTrainer struct {
+	game      *Game // shows the position asked about
+	input     textinput.Model
+	questions []trainerQuestion
+	current   int
+
+	cursor chess.Square // where the cursor is, when finding a move
+	from   chess.Square // the square picked to move from, or NoSquare
+
+	answered bool // the current question has been answered
+	correct  int
+	message  string
+	done     bool
+	err      string
+}
+
+// NewNotationTrainer creates a notation training session on random
+// positions
+func NewNotationTrainer(settings *Settings) *NotationTrainer {
+	return newNotationTrainer(settings, rand.New(rand.NewSource(time.Now().UnixNano())))
+}
+
+// newNotationTrainer creates a session with questions drawn from rng
+func newNotationTrainer(settings *Settings, rng *rand.Rand) *NotationTrainer {
+	input := textinput.New()
+	input.Placeholder = "e.g. Nbd2"
+	input.CharLimit = 10
+	input.Width = 20
+
+	t := &NotationTrainer{
+		game:  NewGameWithSettings(ModeHumanVsHuman, settings),
+		input: input,
+	}
+	for i := range trainerRounds {
+		t.questions = append(t.questions, trainerQuestionFrom(rng, i%2 == 1))
+	}
+	t.show()
+	return t
+}
+
+// trainerQuestionFrom plays random moves from the starting position and
+// asks about one of the moves there, preferring half the time those whose
+// notation takes care: captures, checks, castling, promotions and moves
+// that need their origin written
+func trainerQuestionFrom(rng *rand.Rand, find bool) trainerQuestion {
+	for {
+		game := chess.NewGame()
+		plies := 4 + rng.Intn(40)
+		for i := 0; i < plies && game.Outcome() == chess.NoOutcome; i++ {
+			moves := game.ValidMoves()
+			game.Move(moves[rng.Intn(len(moves))])
+		}
+		if game.Outcome() != chess.NoOutcome {
+			continue
+		}
+
+		position := game.Position()
+		moves := position.ValidMoves()
+		var tricky []*chess.Move
+		for _, move := range moves {
+			if isTrickyMove(position, move) {
+				tricky = append(tricky, move)
+			}
+		}
+		move := moves[rng.Intn(len(moves))]
+		if len(tricky) > 0 && rng.Intn(2) == 0 {
+			move = tricky[rng.Intn(len(tricky))]
+		}
+		return trainerQuestion{
+			fen:  position.String(),
+			from: move.S1(),
+			to:   move.S2(),
+			san:  notation.Encode(position, move),
+			find: find,
+		}
+	}
+}
+
+// isTrickyMove reports whether move's SAN is more than a piece and a square
+func isTrickyMove(position *chess.Position, move *chess.Move) bool {
+	san := notation.Encode(position, move)
+	if strings.ContainsAny(san, "x+#=O") {
+		return true
+	}
+	piece := position.Board().Piece(move.S1())
+	for _, other := range position.ValidMoves() {
+		if other.S2() == move.S2() && other.S1() != move.S1() && position.Board().Piece(other.S1()) == piece {
+			return true
+		}
+	}
+	return false
+}
+
+// show sets up the board and the input for the current question
+func (t *NotationTrainer) show() {
+	question := t.questions[t.current]
+	option, err := chess.FEN(question.fen)
+	if err != nil {
+		t.err = err.Error()
+		return
+	}
+	t.game.chessGame = chess.NewGame(option)
+	t.game.humanColor = t.game.chessGame.Position().Turn()
+	t.game.targets = nil
+	t.from = chess.NoSquare
+	t.game.picking = nil
+
+	if !question.find {
+		t.game.targets = []chess.Square{question.from, question.to}
+		t.input.Focus()
+		return
+	}
+	t.input.Blur()
+	t.cursor = chess.E2
+	if t.game.humanColor == chess.Black {
+		t.cursor = chess.E7
+	}
+	t.game.picking = &picker{cursor: t.cursor}
+}
+
+// Init initializes the trainer screen
+func (t *NotationTrainer) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles the answers: typed SAN, or squares picked with the cursor
+func (t *NotationTrainer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		t.input, cmd = t.input.Update(msg)
+		return t, cmd
+	}
+
+	switch key.String() {
+	case "ctrl+c":
+		return t, tea.Quit
+	case "esc":
+		if t.from != chess.NoSquare && !t.answered {
+			// Put the picked piece back
+			t.from = chess.NoSquare
+			t.game.targets = nil
+			return t, nil
+		}
+		return t, tea.Quit
+	}
+
+	switch {
+	case t.done:
+		if key.String() == "q" {
+			return t, tea.Quit
+		}
+		return t, nil
+	case t.answered:
+		switch key.String() {
+		case "q":
+			return t, tea.Quit
+		case "enter", " ":
+			t.next()
+		}
+		return t, nil
+	case t.questions[t.current].find:
+		switch key.String() {
+		case "enter", " ":
+			t.pick()
+		default:
+			t.cursor = t.game.moveCursor(t.cursor, key.String())
+			t.game.picking.cursor = t.cursor
+		}
+		return t, nil
+	}
+
+	if key.String() == "enter" {
+		if input := strings.TrimSpace(t.input.Value()); input != "" {
+			t.name(input)
+			t.input.SetValue("")
+		}
+		return t, nil
+	}
+	var cmd tea.Cmd
+	t.input, cmd = t.input.Update(msg)
+	return t, cmd
+}
+
+// name checks the SAN typed for the highlighted move
+func (t *NotationTrainer) name(input string) {
+	question := t.questions[t.current]
+	position := t.game.chessGame.Position()
+	switch move, err := notation.Decode(position, input); {
+	case input == question.san:
+		t.correct++
+		t.message = "✓ " + question.san
+	case err == nil && move.S1() == question.from && move.S2() == question.to:
+		t.message = fmt.Sprintf("✗ That's the move, but in SAN it's written %s", question.san)
+	case err == nil:
+		t.message = fmt.Sprintf("✗ %s is %s to %s; the highlighted move is %s", input, move.S1(), move.S2(), question.san)
+	default:
+		t.message = fmt.Sprintf("✗ %s — the highlighted move is %s", notation.Explain(err), question.san)
+	}
+	t.answered = true
+}
+
+// pick takes the square under the cursor as the move's origin, or its
+// destination once the origin is picked, and then checks the move
+func (t *NotationTrainer) pick() {
+	t.err = ""
+	position := t.game.chessGame.Position()
+	if t.from == chess.NoSquare {
+		piece := position.Board().Piece(t.cursor)
+		if piece == chess.NoPiece || piece.Color() != position.Turn() {
+			t.err = fmt.Sprintf("pick one of %s's pieces first", position.Turn().Name())
+			return
+		}
+		t.from = t.cursor
+		t.game.targets = []chess.Square{t.from}
+		return
+	}
+
+	if t.cursor == t.from {
+		// Picking the piece again puts it back
+		t.from = chess.NoSquare
+		t.game.targets = nil
+		return
+	}
+
+	question := t.questions[t.current]
+	if t.from == question.from && t.cursor == question.to {
+		t.correct++
+		t.message = fmt.Sprintf("✓ %s is %s to %s", question.san, question.from, question.to)
+	} else {
+		t.message = fmt.Sprintf("✗ %s is %s to %s, not %s to %s", question.san, question.from, question.to, t.from, t.cursor)
+	}
+	t.game.targets = []chess.Square{question.from, question.to}
+	t.game.picking = nil
+	t.answered = true
+}
+
+// next moves on to the next question, or ends the session after the last
+func (t *NotationTrainer) next() {
+	t.answered = false
+	t.message = ""
+	t.err = ""
+	if t.current+1 >= len(t.questions) {
+		t.done = true
+		return
+	}
+	t.current++
+	t.show()
+}
+
+// Score returns how many questions were answered correctly, of how many
+func (t *NotationTrainer) Score() (correct, total int) {
+	return t.correct, len(t.questions)
+}
+
+// View renders the question, or the score once the session is over
+func (t *NotationTrainer) View() string {
+	var sb strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700")).
+		Render(fmt.Sprintf("♔ Notation Trainer — %d of %d ♛", t.current+1, len(t.questions)))
+	sb.WriteString(title + "\n\n")
+	sb.WriteString(t.game.renderBoard() + "\n\n")
+
+	infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	if t.done {
+		sb.WriteString(infoStyle.Render(fmt.Sprintf("You got %d of %d right — %.0f%% accuracy.",
+			t.correct, len(t.questions), 100*float64(t.correct)/float64(len(t.questions)))) + "\n\n")
+		sb.WriteString(helpStyle.Render("Press q to quit"))
+		return sb.String()
+	}
+
+	question := t.questions[t.current]
+	mover := t.game.humanColor.Name()
+	if question.find {
+		sb.WriteString(infoStyle.Render(fmt.Sprintf("%s to play %s — show it on the board", mover, question.san)) + "\n")
+	} else {
+		sb.WriteString(infoStyle.Render(fmt.Sprintf("%s plays the highlighted move — how is it written?", mover)) + "\n")
+	}
+	if t.answered {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Render(t.message) + "\n")
+	}
+	if t.err != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+t.err) + "\n")
+	}
+	asked := t.current
+	if t.answered {
+		asked++
+	}
+	sb.WriteString(helpStyle.Render(fmt.Sprintf("Score: %d of %d", t.correct, asked)) + "\n")
+
+	switch {
+	case t.answered:
+		sb.WriteString("\n" + helpStyle.Render("Press Enter for the next move, q to quit"))
+	case question.find:
+		step := "the piece to move"
+		if t.from != chess.NoSquare {
+			step = "where it goes"
+		}
+		sb.WriteString("\n" + helpStyle.Render(fmt.Sprintf("Arrows/hjkl move the cursor to %s, space picks it, esc to quit", step)))
+	default:
+		sb.WriteString("\nSAN: " + t.input.View() + "\n\n")
+		sb.WriteString(helpStyle.Render("Enter the move in standard algebraic notation, esc to quit"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package game
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// typeText sends text to a model as typed runes
+func typeText(model tea.Model, text string) {
+	for _, r := range text {
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestNotationTrainerScoresBothDirections(t *testing.T) {
+	trainer := newNotationTrainer(DefaultSettings(), rand.New(rand.NewSource(1)))
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	// Name the highlighted move
+	question := trainer.questions[0]
+	if question.find || !strings.Contains(trainer.View(), "how is it written?") {
+		t.Fatalf("Expected the first question to ask for SAN, got:\n%s", trainer.View())
+	}
+	typeText(trainer, question.san)
+	trainer.Update(enter)
+	if trainer.correct != 1 || !strings.Contains(trainer.View(), "✓ "+question.san) {
+		t.Errorf("Expected %s to be right, got:\n%s", question.san, trainer.View())
+	}
+	trainer.Update(enter)
+
+	// Find the named move, walking the cursor to its squares
+	question = trainer.questions[1]
+	if !question.find || !strings.Contains(trainer.View(), question.san+" — show it on the board") {
+		t.Fatalf("Expected the second question to give SAN, got:\n%s", trainer.View())
+	}
+	walkCursor(trainer, question.from)
+	trainer.Update(keySpace)
+	walkCursor(trainer, question.to)
+	trainer.Update(keySpace)
+	if trainer.correct != 2 || !trainer.answered {
+		t.Errorf("Expected %s found on the board, got %q", question.san, trainer.message)
+	}
+	trainer.Update(enter)
+
+	// A wrong name is marked with the right one
+	question = trainer.questions[2]
+	typeText(trainer, "Zz9")
+	trainer.Update(enter)
+	if trainer.correct != 2 || !strings.Contains(trainer.message, question.san) {
+		t.Errorf("Expected a wrong answer corrected with %s, got %q", question.san, trainer.message)
+	}
+
+	// Get the rest wrong
+	trainer.Update(enter)
+	for !trainer.done {
+		question := trainer.questions[trainer.current]
+		if question.find {
+			walkCursor(trainer, question.from)
+			trainer.Update(keySpace)
+			for _, wrong := range []chess.Square{chess.A1, chess.H8, chess.A8} {
+				if wrong != question.from && wrong != question.to {
+					walkCursor(trainer, wrong)
+					break
+				}
+			}
+			trainer.Update(keySpace)
+		} else {
+			typeText(trainer, "Zz9")
+			trainer.Update(enter)
+		}
+		if !trainer.answered {
+			t.Fatalf("Expected question %d answered", trainer.current+1)
+		}
+		trainer.Update(enter)
+	}
+	if correct, total := trainer.Score(); !trainer.done || correct != 2 || total != trainerRounds {
+		t.Errorf("Expected the session over with 2 of %d, got %d of %d", trainerRounds, correct, total)
+	}
+	if !strings.Contains(trainer.View(), "20% accuracy") {
+		t.Errorf("Expected the accuracy shown, got:\n%s", trainer.View())
+	}
+}
+
+// walkCursor moves the trainer's cursor to square with the arrow keys
+func walkCursor(trainer *NotationTrainer, square chess.Square) {
+	for trainer.cursor != square {
+		switch {
+		case trainer.cursor.Rank() < square.Rank():
+			trainer.Update(keyUp)
+		case trainer.cursor.Rank() > square.Rank():
+			trainer.Update(keyDown)
+		case trainer.cursor.File() < square.File():
+			trainer.Update(keyRight)
+		default:
+			trainer.Update(keyLeft)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"chess-tui/events"
+)
+
+// transcriptGameStarted is the kind of the transcript entry describing a
+// game, written before its first event
+const transcriptGameStarted events.Kind = "game_started"
+
+// DefaultTranscriptPath returns where the TUI keeps the last session's
+// transcript when no other is set, for chess report-bug to bundle
+func DefaultTranscriptPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "last-session.jsonl"
+	}
+	return filepath.Join(home, ".bubblechess", "last-session.jsonl")
+}
+
+// TranscriptEntry is one line of a session transcript: a game event, with
+// the time it took, or the setup of a game before its first event
+type TranscriptEntry struct {
+	events.Event
+	ElapsedMS int64            `json:"elapsed_ms,omitempty"` // since the last move or request for the AI's move
+	Setup     *TranscriptSetup `json:"setup,omitempty"`
+}
+
+// TranscriptSetup describes a game in a transcript
+type TranscriptSetup struct {
+	Mode        string `json:"mode"`
+	White       string `json:"white"`
+	Black       string `json:"black"`
+	AIServer    string `json:"ai_server,omitempty"`
+	Personality string `json:"personality,omitempty"`
+	StartFEN    string `json:"start_fen,omitempty"`
+}
+
+// transcript appends every event of a session's games to a JSON Lines
+// file, for attaching to bug reports about the AI: the moves with the AI's
+// thinking, its rejected moves and failed requests, and how long each took
+type transcript struct {
+	path string
+
+	mu   sync.Mutex
+	game string    // the game whose setup was written last
+	last time.Time // the last move or request for the AI's move
+}
+
+// transcribe writes an event to the transcript set in the settings,
+// preceded by the game's setup if it is the game's first
+func (g *Game) transcribe(event events.Event) {
+	t := g.transcript
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var entries []TranscriptEntry
+	if event.GameID != t.game {
+		t.game = event.GameID
+		t.last = time.Time{}
+		white, black := g.playerNames()
+		setup := &TranscriptSetup{
+			Mode:     g.modeText(),
+			White:    white,
+			Black:    black,
+			StartFEN: g.startFEN,
+		}
+		if g.gameMode == ModeHumanVsAI {
+			setup.AIServer = g.settings.AIServer
+			setup.Personality = g.settings.Personality
+		}
+		entries = append(entries, TranscriptEntry{Event: events.Event{Kind: transcriptGameStarted, Time: event.Time, GameID: event.GameID}, Setup: setup})
+	}
+
+	entry := TranscriptEntry{Event: event}
+	if !t.last.IsZero() {
+		entry.ElapsedMS = event.Time.Sub(t.last).Milliseconds()
+	}
+	if event.Kind == events.MoveMade || event.Kind == events.AIThinkingStarted {
+		t.last = event.Time
+	}
+	entries = append(entries, entry)
+
+	if err := appendTranscript(t.path, entries); err != nil {
+		g.log.Warn("Failed to write transcript", "path", t.path, "error", err)
+	}
+}
+
+// appendTranscript adds entries to the transcript file at path
+func appendTranscript(path string, entries []TranscriptEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create transcript directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write transcript: %w", err)
+		}
+	}
+	return nil
+}
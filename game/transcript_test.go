@@ -0,0 +1,65 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chess-tui/events"
+)
+
+func TestTranscriptRecordsRetriesAndErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	settings := DefaultSettings()
+	settings.Transcript = path
+	settings.AIServer = "http://ai.example"
+	g := NewGameWithSettings(ModeHumanVsAI, settings)
+	g.SetMoveGenerator(NewInstantBot(&InstantBotSettings{Illegal: 1, FailEvery: 3}))
+
+	// The bot's first answer is illegal and its retry is played
+	g.makeMove("e4")
+	retry := g.applyAIMove(g.takeAITurn()().(aiMoveMsg))
+	g.applyAIMove(retry().(aiMoveMsg))
+	// Its third request fails
+	g.makeMove("d4")
+	g.applyAIMove(g.takeAITurn()().(aiMoveMsg))
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Expected a transcript, got %v", err)
+	}
+	defer file.Close()
+	var entries []TranscriptEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry TranscriptEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Expected JSON lines, got %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	var kinds []string
+	for _, entry := range entries {
+		kinds = append(kinds, string(entry.Kind))
+	}
+	want := "game_started move_made ai_thinking_started ai_move_rejected move_made move_made ai_thinking_started ai_error"
+	if strings.Join(kinds, " ") != want {
+		t.Fatalf("Expected %s, got %s", want, strings.Join(kinds, " "))
+	}
+	if setup := entries[0].Setup; setup == nil || setup.Mode == "" || setup.AIServer != "http://ai.example" || setup.White != "Human" {
+		t.Errorf("Expected the game's setup first, got %+v", setup)
+	}
+	if rejected := entries[3]; rejected.Move != "a1a1" || rejected.Error == "" {
+		t.Errorf("Expected the rejected move and why, got %+v", rejected)
+	}
+	if played := entries[4]; !played.Remote || played.Reasoning == "" {
+		t.Errorf("Expected the AI's move with its reasoning, got %+v", played)
+	}
+	if failed := entries[7]; failed.Kind != events.AIError || !strings.Contains(failed.Error, "simulated failure") {
+		t.Errorf("Expected the failed request, got %+v", failed)
+	}
+}
@@ -0,0 +1,329 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"chess-tui/notation"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultProfile is the tutorial profile used when none is chosen
+const DefaultProfile = "default"
+
+// TutorialProgress records the lessons each profile has completed
+type TutorialProgress struct {
+	Profiles map[string][]string `json:"profiles,omitempty"` // profile → completed lesson IDs
+}
+
+// DefaultTutorialPath returns the tutorial progress location in the user's config directory
+func DefaultTutorialPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "tutorial.json"
+	}
+	return filepath.Join(home, ".bubblechess", "tutorial.json")
+}
+
+// LoadTutorialProgress loads tutorial progress, returning empty progress if the file doesn't exist
+func LoadTutorialProgress(path string) (*TutorialProgress, error) {
+	progress := &TutorialProgress{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tutorial progress: %w", err)
+	}
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, fmt.Errorf("failed to decode tutorial progress: %w", err)
+	}
+	return progress, nil
+}
+
+// SaveTutorialProgress saves tutorial progress
+func SaveTutorialProgress(progress *TutorialProgress, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create tutorial progress directory: %w", err)
+	}
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tutorial progress: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write tutorial progress: %w", err)
+	}
+	return nil
+}
+
+// Completed reports whether a profile has completed a lesson
+func (p *TutorialProgress) Completed(profile, lessonID string) bool {
+	return slices.Contains(p.Profiles[profile], lessonID)
+}
+
+// complete marks a lesson completed for a profile
+func (p *TutorialProgress) complete(profile, lessonID string) {
+	if p.Completed(profile, lessonID) {
+		return
+	}
+	if p.Profiles == nil {
+		p.Profiles = make(map[string][]string)
+	}
+	p.Profiles[profile] = append(p.Profiles[profile], lessonID)
+}
+
+// Tutorial is the screen for the guided lessons: a list of lessons, and the
+// board for the one being played
+type Tutorial struct {
+	settings *Settings
+	profile  string
+	cursor   int
+
+	progress     *TutorialProgress
+	progressPath string // "" keeps progress in memory only
+
+	lesson  *Lesson // the lesson being played, nil on the list
+	game    *Game   // shows the lesson's board
+	input   textinput.Model
+	step    int
+	message string
+	done    bool // the lesson's steps are all complete
+	err     string
+}
+
+// NewTutorial opens the lesson list for profile. Progress is read from and
+// saved to progressPath, or kept in memory if it is empty.
+func NewTutorial(settings *Settings, profile, progressPath string) (*Tutorial, error) {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	progress := &TutorialProgress{}
+	if progressPath != "" {
+		var err error
+		if progress, err = LoadTutorialProgress(progressPath); err != nil {
+			return nil, err
+		}
+	}
+
+	input := textinput.New()
+	input.Placeholder = "e.g. e4"
+	input.CharLimit = 10
+	input.Width = 20
+
+	t := &Tutorial{
+		settings:     settings,
+		profile:      profile,
+		progress:     progress,
+		progressPath: progressPath,
+		input:        input,
+	}
+	// Start on the first lesson not yet completed
+	for t.cursor < len(lessons)-1 && progress.Completed(profile, lessons[t.cursor].ID) {
+		t.cursor++
+	}
+	return t, nil
+}
+
+// Init initializes the tutorial screen
+func (t *Tutorial) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles lesson selection and the learner's moves
+func (t *Tutorial) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if t.lesson == nil {
+		if !ok {
+			return t, nil
+		}
+		switch key.String() {
+		case "up", "k":
+			if t.cursor > 0 {
+				t.cursor--
+			}
+		case "down", "j":
+			if t.cursor < len(lessons)-1 {
+				t.cursor++
+			}
+		case "enter":
+			return t, t.start(t.cursor)
+		case "q", "ctrl+c":
+			return t, tea.Quit
+		}
+		return t, nil
+	}
+
+	if ok {
+		switch key.String() {
+		case "ctrl+c":
+			return t, tea.Quit
+		case "esc":
+			t.lesson = nil
+			return t, nil
+		case "enter":
+			if t.done {
+				if t.cursor < len(lessons)-1 {
+					t.cursor++
+					return t, t.start(t.cursor)
+				}
+				t.lesson = nil
+				return t, nil
+			}
+			if t.input.Value() != "" {
+				t.tryMove(strings.TrimSpace(t.input.Value()))
+				t.input.SetValue("")
+			}
+			return t, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	if !t.done {
+		t.input, cmd = t.input.Update(msg)
+	}
+	return t, cmd
+}
+
+// start begins a lesson
+func (t *Tutorial) start(index int) tea.Cmd {
+	lesson := lessons[index]
+	position, err := lesson.newGame()
+	if err != nil {
+		t.err = err.Error()
+		return nil
+	}
+
+	t.lesson = &lesson
+	t.game = NewGameWithSettings(ModeHumanVsHuman, t.settings)
+	t.game.chessGame = position
+	t.game.humanColor = position.Position().Turn()
+	t.step = 0
+	t.done = false
+	t.message = ""
+	t.err = ""
+	t.showStep()
+	t.input.SetValue("")
+	t.input.Focus()
+	return textinput.Blink
+}
+
+// showStep highlights the current step's target squares
+func (t *Tutorial) showStep() {
+	t.game.targets = nil
+	if t.step < len(t.lesson.Steps) {
+		t.game.targets = t.lesson.Steps[t.step].targetSquares()
+	}
+}
+
+// tryMove checks the learner's move against the current step, playing the
+// opponent's reply when it is right
+func (t *Tutorial) tryMove(input string) {
+	t.err = ""
+	position := t.game.chessGame
+	move, err := notation.Decode(position.Position(), input)
+	if err != nil {
+		t.err = notation.Explain(err)
+		return
+	}
+
+	step := t.lesson.Steps[t.step]
+	if !step.accepts(position, move) {
+		t.message = fmt.Sprintf("%s is legal, but not what this step asks for. Try again.", input)
+		return
+	}
+
+	position.Move(move)
+	if step.Reply != "" {
+		if err := playSAN(position, step.Reply); err != nil {
+			t.err = err.Error()
+		}
+	}
+	t.step++
+	t.showStep()
+	if t.step < len(t.lesson.Steps) {
+		t.message = "Well done!"
+		return
+	}
+
+	t.done = true
+	t.message = "Lesson complete!"
+	t.progress.complete(t.profile, t.lesson.ID)
+	if t.progressPath != "" {
+		if err := SaveTutorialProgress(t.progress, t.progressPath); err != nil {
+			t.err = err.Error()
+		}
+	}
+}
+
+// completedCount returns how many lessons the profile has completed
+func (t *Tutorial) completedCount() int {
+	count := 0
+	for _, lesson := range lessons {
+		if t.progress.Completed(t.profile, lesson.ID) {
+			count++
+		}
+	}
+	return count
+}
+
+// View renders the lesson list or the lesson being played
+func (t *Tutorial) View() string {
+	var sb strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700"))
+	infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+
+	if t.lesson == nil {
+		sb.WriteString(titleStyle.Render("♔ Tutorial ♛") + "\n\n")
+		sb.WriteString(infoStyle.Render(fmt.Sprintf("Profile: %s — %d of %d lessons complete", t.profile, t.completedCount(), len(lessons))) + "\n\n")
+		for i, lesson := range lessons {
+			cursor := " "
+			if t.cursor == i {
+				cursor = ">"
+			}
+			check := "  "
+			if t.progress.Completed(t.profile, lesson.ID) {
+				check = "✓ "
+			}
+			sb.WriteString(fmt.Sprintf("%s %s%d. %s\n", cursor, check, i+1, lesson.Title))
+		}
+		if t.err != "" {
+			sb.WriteString("\n" + errorStyle.Render("Error: "+t.err) + "\n")
+		}
+		sb.WriteString("\n" + helpStyle.Render("Use arrow keys to choose a lesson, Enter to start, q to quit"))
+		return sb.String()
+	}
+
+	sb.WriteString(titleStyle.Render(fmt.Sprintf("♔ Lesson %d: %s ♛", t.cursor+1, t.lesson.Title)) + "\n\n")
+	sb.WriteString(t.game.renderBoard() + "\n\n")
+
+	if t.done {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Render(t.message) + "\n\n")
+		if t.cursor < len(lessons)-1 {
+			sb.WriteString(helpStyle.Render("Press Enter for the next lesson, esc for the lesson list, ctrl+c to quit"))
+		} else {
+			sb.WriteString(helpStyle.Render("That was the last lesson. Press Enter for the lesson list, ctrl+c to quit"))
+		}
+		return sb.String()
+	}
+
+	sb.WriteString(infoStyle.Render(fmt.Sprintf("Step %d of %d: %s", t.step+1, len(t.lesson.Steps), t.lesson.Steps[t.step].Prompt)) + "\n")
+	if t.message != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Render(t.message) + "\n")
+	}
+	if t.err != "" {
+		sb.WriteString(errorStyle.Render("Error: "+t.err) + "\n")
+	}
+	sb.WriteString("\nYour move: " + t.input.View() + "\n\n")
+	sb.WriteString(helpStyle.Render("Enter a move in algebraic notation, esc for the lesson list, ctrl+c to quit"))
+	return sb.String()
+}
@@ -0,0 +1,127 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func TestLessonStepsArePlayable(t *testing.T) {
+	for _, lesson := range lessons {
+		game, err := lesson.newGame()
+		if err != nil {
+			t.Errorf("%s: %v", lesson.Title, err)
+			continue
+		}
+		for i, step := range lesson.Steps {
+			if len(step.Targets) == 0 || len(step.targetSquares()) != len(step.Targets) {
+				t.Errorf("%s: step %d has invalid targets %v", lesson.Title, i+1, step.Targets)
+			}
+			if len(step.Accept) == 0 && !step.Mate {
+				t.Errorf("%s: step %d accepts no moves", lesson.Title, i+1)
+			}
+
+			// Every highlighted square is reached by an accepted move
+			var accepted []*chess.Move
+			for _, target := range step.targetSquares() {
+				var reached bool
+				for _, move := range game.ValidMoves() {
+					if move.S2() == target && step.accepts(game, move) {
+						reached = true
+						accepted = append(accepted, move)
+						break
+					}
+				}
+				if !reached {
+					t.Errorf("%s: step %d has no accepted move to %s", lesson.Title, i+1, target)
+				}
+			}
+			if len(accepted) == 0 {
+				break
+			}
+			game.Move(accepted[0])
+			if step.Reply != "" {
+				if err := playSAN(game, step.Reply); err != nil {
+					t.Errorf("%s: step %d reply %s: %v", lesson.Title, i+1, step.Reply, err)
+				}
+			}
+		}
+	}
+}
+
+// typeLessonMove enters a move on the tutorial screen
+func typeLessonMove(tutorial *Tutorial, move string) {
+	tutorial.input.SetValue(move)
+	tutorial.Update(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestTutorialSavesProgressPerProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tutorial.json")
+	tutorial, err := NewTutorial(DefaultSettings(), "alex", path)
+	if err != nil {
+		t.Fatalf("Failed to open tutorial: %v", err)
+	}
+	tutorial.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if tutorial.lesson == nil || tutorial.lesson.ID != "pawn-moves" {
+		t.Fatal("Expected Enter to start the first lesson")
+	}
+	if len(tutorial.game.targets) != 1 || tutorial.game.targets[0].String() != "e4" {
+		t.Errorf("Expected e4 to be highlighted, got %v", tutorial.game.targets)
+	}
+
+	typeLessonMove(tutorial, "d4")
+	if tutorial.step != 0 {
+		t.Error("Expected a move the step doesn't ask for to be rejected")
+	}
+	typeLessonMove(tutorial, "e4")
+	if tutorial.step != 1 || len(tutorial.game.sanMoves()) != 2 {
+		t.Errorf("Expected the reply to be played and the next step shown, got step %d after %v", tutorial.step, tutorial.game.sanMoves())
+	}
+	typeLessonMove(tutorial, "d3")
+	if !tutorial.done {
+		t.Fatal("Expected the lesson to be complete")
+	}
+
+	progress, err := LoadTutorialProgress(path)
+	if err != nil {
+		t.Fatalf("Failed to load progress: %v", err)
+	}
+	if !progress.Completed("alex", "pawn-moves") {
+		t.Error("Expected the lesson to be saved as completed for alex")
+	}
+	if progress.Completed(DefaultProfile, "pawn-moves") {
+		t.Error("Expected other profiles' progress to be unchanged")
+	}
+
+	// Reopening starts at the first lesson not yet completed
+	again, err := NewTutorial(DefaultSettings(), "alex", path)
+	if err != nil {
+		t.Fatalf("Failed to reopen tutorial: %v", err)
+	}
+	if again.cursor != 1 {
+		t.Errorf("Expected the cursor on lesson 2, got %d", again.cursor+1)
+	}
+}
+
+func TestTutorialEnPassantAndMate(t *testing.T) {
+	tutorial, err := NewTutorial(DefaultSettings(), "", "")
+	if err != nil {
+		t.Fatalf("Failed to open tutorial: %v", err)
+	}
+	for i, lesson := range lessons {
+		if lesson.ID != "en-passant" && lesson.ID != "back-rank-mate" {
+			continue
+		}
+		tutorial.start(i)
+		move := "exd6"
+		if lesson.ID == "back-rank-mate" {
+			move = "Rd8#"
+		}
+		typeLessonMove(tutorial, move)
+		if !tutorial.done {
+			t.Errorf("%s: expected %s to complete the lesson, got error %q", lesson.Title, move, tutorial.err)
+		}
+	}
+}
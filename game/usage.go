@@ -0,0 +1,41 @@
+package game
+
+import (
+	"fmt"
+)
+
+// TokenUsage accumulates the AI's token consumption over a game
+type TokenUsage struct {
+	Moves            int
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Add records the tokens used for one AI move
+func (u *TokenUsage) Add(result *AIMoveResult) {
+	u.Moves++
+	u.PromptTokens += result.PromptTokens
+	u.CompletionTokens += result.CompletionTokens
+}
+
+// Cost estimates the cost in dollars using the configured per-million-token
+// prices. ok is false when no price is configured.
+func (u *TokenUsage) Cost(settings *Settings) (cost float64, ok bool) {
+	if settings.PromptTokenCost == 0 && settings.CompletionTokenCost == 0 {
+		return 0, false
+	}
+	cost = float64(u.PromptTokens)/1e6*settings.PromptTokenCost +
+		float64(u.CompletionTokens)/1e6*settings.CompletionTokenCost
+	return cost, true
+}
+
+// Summary renders the cumulative usage for the status area
+func (u *TokenUsage) Summary(settings *Settings) string {
+	loc := settings.Formatting()
+	summary := fmt.Sprintf("Tokens: %s prompt / %s completion over %s AI moves",
+		loc.Int(u.PromptTokens), loc.Int(u.CompletionTokens), loc.Int(u.Moves))
+	if cost, ok := u.Cost(settings); ok {
+		summary += " (~$" + loc.Float(cost, 4) + ")"
+	}
+	return summary
+}
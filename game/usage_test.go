@@ -0,0 +1,40 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestTokenUsage(t *testing.T) {
+	var usage TokenUsage
+	usage.Add(&AIMoveResult{Move: "e5", PromptTokens: 600000, CompletionTokens: 100000})
+	usage.Add(&AIMoveResult{Move: "Nc6", PromptTokens: 400000, CompletionTokens: 100000})
+
+	if usage.Moves != 2 || usage.PromptTokens != 1000000 || usage.CompletionTokens != 200000 {
+		t.Errorf("Unexpected usage totals: %+v", usage)
+	}
+
+	if _, ok := usage.Cost(DefaultSettings()); ok {
+		t.Error("Expected no cost estimate without configured prices")
+	}
+
+	settings := &Settings{PromptTokenCost: 0.5, CompletionTokenCost: 2, Locale: "C"}
+	cost, ok := usage.Cost(settings)
+	if !ok || cost != 0.9 {
+		t.Errorf("Expected cost 0.9, got %v (ok=%t)", cost, ok)
+	}
+
+	expected := "Tokens: 1000000 prompt / 200000 completion over 2 AI moves (~$0.9000)"
+	if summary := usage.Summary(settings); summary != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, summary)
+	}
+}
+
+func TestTokenUsageInLocale(t *testing.T) {
+	usage := TokenUsage{PromptTokens: 1234567, CompletionTokens: 2500, Moves: 3}
+	settings := &Settings{PromptTokenCost: 1, Locale: "de_DE.UTF-8"}
+
+	expected := "Tokens: 1.234.567 prompt / 2.500 completion over 3 AI moves (~$1,2346)"
+	if summary := usage.Summary(settings); summary != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, summary)
+	}
+}
@@ -0,0 +1,322 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"chess-tui/notation"
+	"chess-tui/variant"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// variantMover is a MoveGenerator that can play chess variants
+type variantMover interface {
+	GetVariantMove(variantName, fen string, gameHistory []string, playerColor string) (*AIMoveResult, error)
+}
+
+// variantMoveMsg carries the AI's move in a variant game
+type variantMoveMsg struct {
+	result *AIMoveResult
+	err    error
+}
+
+// variantHistoryPlies is how many of the latest moves the variant screen lists
+const variantHistoryPlies = 12
+
+// VariantGame is the screen for a game of a chess variant such as
+// Crazyhouse or Atomic, against the AI or between two players at one
+// keyboard. The variant package keeps the game; the board is drawn as in
+// standard games, with each side's reserve beside it in variants that have
+// them, and the squares an explosion cleared marked in Atomic.
+type VariantGame struct {
+	game  *variant.Game
+	board *Game // draws the board
+	input textinput.Model
+
+	ai         MoveGenerator // nil when two players share the keyboard
+	humanColor chess.Color
+	thinking   bool
+
+	message string
+	err     string
+}
+
+// NewVariantGame starts a game of v. With an AI, the human plays
+// humanColor; without one both sides are played from the keyboard.
+func NewVariantGame(v variant.Variant, ai MoveGenerator, humanColor chess.Color, settings *Settings) *VariantGame {
+	input := textinput.New()
+	input.Placeholder = "e.g. Nf3"
+	if v.Start().Reserves != nil {
+		input.Placeholder += " or N@f3"
+	}
+	input.CharLimit = 10
+	input.Width = 20
+	input.Focus()
+
+	g := &VariantGame{
+		game:       variant.NewGame(v),
+		board:      NewGameWithSettings(ModeHumanVsHuman, settings),
+		input:      input,
+		ai:         ai,
+		humanColor: humanColor,
+	}
+	g.board.humanColor = humanColor
+	g.show(nil)
+	return g
+}
+
+// Init starts the cursor blinking, and asks for the AI's move if it plays White
+func (g *VariantGame) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, g.aiTurn())
+}
+
+// aiTurn asks for the AI's move when it is the AI's turn, or returns nil
+func (g *VariantGame) aiTurn() tea.Cmd {
+	if g.ai == nil || g.over() || g.game.Position().Turn() == g.humanColor {
+		return nil
+	}
+	mover, ok := g.ai.(variantMover)
+	if !ok {
+		g.err = fmt.Sprintf("this AI can't play %s", g.game.Variant().Name())
+		return nil
+	}
+
+	g.thinking = true
+	name := strings.ToLower(g.game.Variant().Name())
+	fen := g.game.Position().String()
+	history := g.game.History()
+	color := g.humanColor.Other().Name()
+	return func() tea.Msg {
+		result, err := mover.GetVariantMove(name, fen, history, strings.ToLower(color))
+		return variantMoveMsg{result: result, err: err}
+	}
+}
+
+// Update plays the moves typed, and the AI's
+func (g *VariantGame) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case variantMoveMsg:
+		g.thinking = false
+		if msg.err != nil {
+			g.err = describeAIError(msg.err)
+			return g, nil
+		}
+		if err := g.play(msg.result.Move); err != nil {
+			g.err = fmt.Sprintf("the AI played %s: %s; press enter to ask again", msg.result.Move, notation.Explain(err))
+			return g, nil
+		}
+		g.message = "AI played " + msg.result.Move
+		return g, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return g, tea.Quit
+		case "enter":
+			return g, g.submit()
+		}
+	}
+
+	var cmd tea.Cmd
+	g.input, cmd = g.input.Update(msg)
+	return g, cmd
+}
+
+// submit plays the typed move, or asks the AI again after it failed
+func (g *VariantGame) submit() tea.Cmd {
+	if g.over() || g.thinking {
+		return nil
+	}
+	if g.ai != nil && g.game.Position().Turn() != g.humanColor {
+		g.err = ""
+		return g.aiTurn()
+	}
+
+	text := strings.TrimSpace(g.input.Value())
+	if text == "" {
+		return nil
+	}
+	g.input.SetValue("")
+	if err := g.play(text); err != nil {
+		g.err = notation.Explain(err)
+		return nil
+	}
+	g.message = ""
+	return g.aiTurn()
+}
+
+// play makes a move and shows the position after it
+func (g *VariantGame) play(text string) error {
+	move, err := variant.Decode(g.game.Variant(), g.game.Position(), text)
+	if err != nil {
+		return err
+	}
+	var blast []chess.Square
+	if exploder, ok := g.game.Variant().(variant.Exploder); ok {
+		blast = exploder.Explosion(g.game.Position(), move)
+	}
+	if _, err := g.game.Move(text); err != nil {
+		return err
+	}
+	g.err = ""
+	g.show(&move)
+	g.board.exploded = blast
+	return nil
+}
+
+// show puts the current position on the board, highlighting the last move
+func (g *VariantGame) show(last *variant.Move) {
+	option, err := chess.FEN(g.game.Position().Board.String())
+	if err != nil {
+		g.err = err.Error()
+		return
+	}
+	g.board.chessGame = chess.NewGame(option)
+	g.board.targets = nil
+	g.board.exploded = nil
+	switch {
+	case last == nil:
+	case last.IsDrop():
+		g.board.targets = []chess.Square{last.To}
+	default:
+		g.board.targets = []chess.Square{last.Board.S1(), last.Board.S2()}
+	}
+}
+
+// over reports whether the game has ended
+func (g *VariantGame) over() bool {
+	outcome, _ := g.game.Outcome()
+	return outcome != chess.NoOutcome
+}
+
+// View renders the board with the reserves beside it, the latest moves and
+// the move input
+func (g *VariantGame) View() string {
+	var sb strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700")).
+		Render(fmt.Sprintf("♔ %s ♛", g.game.Variant().Name()))
+	sb.WriteString(title + "\n\n")
+	board := g.board.renderBoard()
+	if g.game.Position().Reserves != nil {
+		board = lipgloss.JoinHorizontal(lipgloss.Top, board,
+			lipgloss.NewStyle().MarginLeft(3).Render(g.renderReserves()))
+	}
+	sb.WriteString(board + "\n\n")
+
+	infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	if history := g.renderHistory(); history != "" {
+		sb.WriteString(helpStyle.Render(history) + "\n")
+	}
+	if len(g.board.exploded) > 0 {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF8800")).Render(describeExplosion(g.board.exploded)) + "\n")
+	}
+
+	outcome, method := g.game.Outcome()
+	switch {
+	case outcome != chess.NoOutcome:
+		sb.WriteString(infoStyle.Render(describeVariantOutcome(outcome, method)) + "\n")
+	case g.thinking:
+		sb.WriteString(infoStyle.Render("🤔 AI is thinking...") + "\n")
+	default:
+		turn := g.game.Position().Turn().Name() + " to move"
+		if g.game.Variant().InCheck(g.game.Position()) {
+			turn += " — check!"
+		}
+		sb.WriteString(infoStyle.Render(turn) + "\n")
+	}
+	if g.message != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Render(g.message) + "\n")
+	}
+	if g.err != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("Error: "+g.err) + "\n")
+	}
+
+	if outcome != chess.NoOutcome {
+		sb.WriteString("\n" + helpStyle.Render("Press esc to quit"))
+		return sb.String()
+	}
+	sb.WriteString("\nYour move: " + g.input.View() + "\n\n")
+	if g.game.Position().Reserves != nil {
+		sb.WriteString(helpStyle.Render("Moves in SAN, drops as N@f3 or P@e4; esc to quit"))
+	} else {
+		sb.WriteString(helpStyle.Render("Moves in SAN or UCI; esc to quit"))
+	}
+	return sb.String()
+}
+
+// renderReserves lists the pieces each side holds in hand, the side at the
+// top of the board first
+func (g *VariantGame) renderReserves() string {
+	reserves := g.game.Position().Reserves
+	var sb strings.Builder
+	for i, color := range []chess.Color{g.humanColor.Other(), g.humanColor} {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(lipgloss.NewStyle().Bold(true).Render(color.Name()+" in hand") + "\n")
+		sb.WriteString(renderReserve(reserves[color], color))
+	}
+	return sb.String()
+}
+
+// renderReserve draws a reserve's pieces with their counts, e.g. "♕ ♙×3",
+// or "—" when it is empty
+func renderReserve(reserve variant.Reserve, color chess.Color) string {
+	var pieces []string
+	counted := map[chess.PieceType]bool{}
+	for _, t := range reserve.Pieces() {
+		if counted[t] {
+			continue
+		}
+		counted[t] = true
+		piece := chess.NewPiece(t, color).String()
+		if reserve[t] > 1 {
+			piece += fmt.Sprintf("×%d", reserve[t])
+		}
+		pieces = append(pieces, piece)
+	}
+	if len(pieces) == 0 {
+		return "—"
+	}
+	return strings.Join(pieces, " ")
+}
+
+// renderHistory numbers the latest moves, e.g. "12. Nxf7 P@e6"
+func (g *VariantGame) renderHistory() string {
+	history := g.game.History()
+	start := max(len(history)-variantHistoryPlies, 0)
+	var tokens []string
+	for ply := start; ply < len(history); ply++ {
+		tokens = append(tokens, numberToken(ply, ply == start)...)
+		tokens = append(tokens, history[ply])
+	}
+	return strings.Join(tokens, " ")
+}
+
+// describeExplosion lists the squares whose pieces a capture blew up, e.g.
+// "💥 3 pieces blown up: f3 e5 d6"
+func describeExplosion(squares []chess.Square) string {
+	names := make([]string, len(squares))
+	for i, square := range squares {
+		names[i] = square.String()
+	}
+	return fmt.Sprintf("💥 %d pieces blown up: %s", len(squares), strings.Join(names, " "))
+}
+
+// describeVariantOutcome says how a variant game ended, e.g. "Checkmate —
+// White wins"
+func describeVariantOutcome(outcome chess.Outcome, method string) string {
+	how := strings.ToUpper(method[:1]) + method[1:]
+	switch outcome {
+	case chess.WhiteWon:
+		return how + " — White wins"
+	case chess.BlackWon:
+		return how + " — Black wins"
+	}
+	return how + " — draw"
+}
@@ -0,0 +1,127 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"chess-tui/variant"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// variantGenerator plays its replies in turn in variant games, keeping the
+// positions it was asked about
+type variantGenerator struct {
+	fakeGenerator
+	replies []string
+	fens    []string
+}
+
+func (v *variantGenerator) GetVariantMove(variantName, fen string, gameHistory []string, playerColor string) (*AIMoveResult, error) {
+	v.fens = append(v.fens, fen)
+	reply := v.replies[0]
+	v.replies = v.replies[1:]
+	return &AIMoveResult{Move: reply}, nil
+}
+
+// playVariantMove types a move into the variant screen and plays the AI's
+// reply, if it is asked for one
+func playVariantMove(t *testing.T, g *VariantGame, move string) {
+	t.Helper()
+	typeText(g, move)
+	_, cmd := g.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if g.err != "" {
+		t.Fatalf("Expected %s to be played, got %s", move, g.err)
+	}
+	if cmd != nil {
+		g.Update(cmd())
+	}
+}
+
+func TestCrazyhouseAgainstAI(t *testing.T) {
+	ai := &variantGenerator{replies: []string{"d5", "Qxd5", "P@e1"}}
+	g := NewVariantGame(variant.Crazyhouse{}, ai, chess.White, DefaultSettings())
+
+	playVariantMove(t, g, "e4")
+	playVariantMove(t, g, "exd5")
+	if len(ai.fens) != 2 || !strings.Contains(ai.fens[1], "[P]") {
+		t.Fatalf("Expected the AI to be sent White's reserve, got %v", ai.fens)
+	}
+
+	view := g.View()
+	for _, want := range []string{"Crazyhouse", "White in hand", "♙", "♟", "1. e4 d5 2. exd5 Qxd5"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the view to show %q, got:\n%s", want, view)
+		}
+	}
+
+	// An illegal reply is shown, and enter asks again
+	playVariantMove(t, g, "P@e4")
+	if !strings.Contains(g.err, "press enter to ask again") {
+		t.Fatalf("Expected the AI's illegal drop to be reported, got %q", g.err)
+	}
+	ai.replies = []string{"Nc6"}
+	_, cmd := g.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected enter to ask the AI again")
+	}
+	g.Update(cmd())
+	if g.err != "" || g.message != "AI played Nc6" {
+		t.Errorf("Expected the AI's second try to be played, got %q %q", g.err, g.message)
+	}
+	if history := g.game.History(); len(history) != 6 || history[4] != "P@e4" {
+		t.Errorf("Expected the drop in the history, got %v", history)
+	}
+}
+
+func TestVariantGameRefusesIllegalDrops(t *testing.T) {
+	g := NewVariantGame(variant.Crazyhouse{}, nil, chess.White, DefaultSettings())
+	typeText(g, "N@f3")
+	g.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(g.err, "no knight in hand") {
+		t.Errorf("Expected the drop to be refused, got %q", g.err)
+	}
+
+	// Without an AI both sides play from the keyboard
+	playVariantMove(t, g, "e4")
+	playVariantMove(t, g, "e5")
+	if len(g.game.History()) != 2 {
+		t.Errorf("Expected both moves played, got %v", g.game.History())
+	}
+}
+
+func TestAtomicExplosionsAreShown(t *testing.T) {
+	g := NewVariantGame(variant.Atomic{}, nil, chess.White, DefaultSettings())
+	for _, move := range []string{"Nf3", "a6", "Ne5", "a5", "Nxd7"} {
+		playVariantMove(t, g, move)
+	}
+
+	marks := g.board.squareMarks()
+	for _, square := range []chess.Square{chess.E5, chess.D7, chess.E8} {
+		if marks[square] != markExploded {
+			t.Errorf("Expected %s marked as exploded, got %v", square, marks[square])
+		}
+	}
+	view := g.View()
+	for _, want := range []string{"Atomic", "💥 5 pieces blown up", "King exploded — White wins"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the view to show %q, got:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "in hand") {
+		t.Errorf("Expected no reserves in Atomic, got:\n%s", view)
+	}
+}
+
+func TestRenderReserve(t *testing.T) {
+	reserve := variant.Reserve{}
+	if renderReserve(reserve, chess.White) != "—" {
+		t.Errorf("Expected an empty reserve to show a dash, got %q", renderReserve(reserve, chess.White))
+	}
+	reserve[chess.Pawn] = 3
+	reserve[chess.Queen] = 1
+	if got := renderReserve(reserve, chess.Black); got != "♛ ♟×3" {
+		t.Errorf("Expected ♛ ♟×3, got %q", got)
+	}
+}
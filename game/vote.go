@@ -0,0 +1,99 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// votePanelWidth is the width of the vote breakdown side panel
+const votePanelWidth = 34
+
+// Vote is one voter's proposal when a panel of AI models voted on the AI's
+// move
+type Vote struct {
+	Voter  string
+	Move   string // in SAN; empty if the voter abstained
+	Weight float64
+	Error  string // why the voter abstained
+}
+
+// voteTally is the weight behind one proposed move
+type voteTally struct {
+	move   string
+	weight float64
+	voters []string
+}
+
+// tallyVotes sums the votes per move, most weight first, keeping the order
+// voters proposed them in for ties
+func tallyVotes(votes []Vote) []voteTally {
+	var tallies []voteTally
+	index := make(map[string]int)
+	for _, vote := range votes {
+		if vote.Move == "" {
+			continue
+		}
+		i, ok := index[vote.Move]
+		if !ok {
+			i = len(tallies)
+			index[vote.Move] = i
+			tallies = append(tallies, voteTally{move: vote.Move})
+		}
+		tallies[i].weight += vote.Weight
+		tallies[i].voters = append(tallies[i].voters, vote.Voter)
+	}
+	sort.SliceStable(tallies, func(i, j int) bool {
+		return tallies[i].weight > tallies[j].weight
+	})
+	return tallies
+}
+
+// voteSummary describes the vote in one line, for the accessible view
+func voteSummary(votes []Vote) string {
+	var parts []string
+	for _, tally := range tallyVotes(votes) {
+		parts = append(parts, fmt.Sprintf("%s %g (%s)", tally.move, tally.weight, strings.Join(tally.voters, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// renderVotePanel renders the breakdown of the vote on the AI's last move
+func (g *Game) renderVotePanel() string {
+	if len(g.aiVotes) == 0 {
+		return ""
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00AAFF"))
+	moveStyle := lipgloss.NewStyle().Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA")).Width(votePanelWidth - 2)
+
+	total := 0.0
+	for _, vote := range g.aiVotes {
+		total += vote.Weight
+	}
+
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("Vote") + "\n")
+	for i, tally := range tallyVotes(g.aiVotes) {
+		bar := strings.Repeat("█", max(int(10*tally.weight/total+0.5), 1))
+		line := fmt.Sprintf("%-7s %s %g", tally.move, bar, tally.weight)
+		if i == 0 {
+			line = moveStyle.Render(line + " ✓")
+		}
+		sb.WriteString(line + "\n")
+		sb.WriteString(textStyle.Render(strings.Join(tally.voters, ", ")) + "\n")
+	}
+	for _, vote := range g.aiVotes {
+		if vote.Move == "" {
+			sb.WriteString(textStyle.Render(vote.Voter+" abstained: "+vote.Error) + "\n")
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Width(votePanelWidth).
+		MarginLeft(2).
+		Render(strings.TrimSuffix(sb.String(), "\n"))
+}
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVotePanel(t *testing.T) {
+	g := NewGameWithMode(ModeHumanVsAI)
+	if g.renderVotePanel() != "" {
+		t.Error("Expected no vote panel before a vote")
+	}
+
+	g.aiVotes = []Vote{
+		{Voter: "ollama llama3.2:3b", Move: "e5", Weight: 1},
+		{Voter: "openai gpt-4o", Move: "c5", Weight: 1},
+		{Voter: "anthropic claude", Move: "c5", Weight: 1.5},
+		{Voter: "gguf tiny.gguf", Weight: 1, Error: "timed out"},
+	}
+	panel := g.renderVotePanel()
+	if strings.Index(panel, "c5") > strings.Index(panel, "e5") || !strings.Contains(panel, "2.5 ✓") {
+		t.Errorf("Expected c5 first with 2.5 of the vote, got %q", panel)
+	}
+	if !strings.Contains(panel, "gguf tiny.gguf abstained") {
+		t.Errorf("Expected the abstention shown, got %q", panel)
+	}
+	if got := voteSummary(g.aiVotes); got != "c5 2.5 (openai gpt-4o, anthropic claude); e5 1 (ollama llama3.2:3b)" {
+		t.Errorf("Expected the vote summarized, got %q", got)
+	}
+}
+
+func TestExtractVotes(t *testing.T) {
+	parts := []interface{}{map[string]interface{}{
+		"kind": "data",
+		"data": map[string]interface{}{
+			"votes": []interface{}{
+				map[string]interface{}{"voter": "engine", "move": "Nf3", "weight": 2.0},
+				map[string]interface{}{"voter": "ollama", "weight": 1.0, "error": "refused"},
+			},
+		},
+	}}
+	result := &AIMoveResult{}
+	extractMoveData(parts, result)
+	if len(result.Votes) != 2 || result.Votes[0].Move != "Nf3" || result.Votes[0].Weight != 2 || result.Votes[1].Error != "refused" {
+		t.Errorf("Expected the votes parsed, got %+v", result.Votes)
+	}
+}
@@ -0,0 +1,101 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"chess-tui/ai_player"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// sparklineWidth is the most columns the win-probability graph takes in the
+// wide layout; in longer games each column stands for several moves
+const sparklineWidth = 60
+
+// sparklineLevels are the graph's bars, from a lost game to a won one
+var sparklineLevels = []rune("▁▂▃▄▅▆▇█")
+
+// winProbability converts a score in centipawns from White's side into
+// White's chance of winning, from 0 to 1, by the curve Lichess fits to its
+// players' games
+func winProbability(score int) float64 {
+	return 1 / (1 + math.Exp(-0.00368208*float64(score)))
+}
+
+// winProbabilities returns White's chance of winning in each position of
+// game, from the start, scored by material and by the result once the game
+// is over
+func winProbabilities(game *chess.Game) []float64 {
+	positions := game.Positions()
+	chances := make([]float64, len(positions))
+	for i, position := range positions {
+		chances[i] = winProbability(ai_player.MaterialBalance(position, chess.White))
+	}
+	switch game.Outcome() {
+	case chess.WhiteWon:
+		chances[len(chances)-1] = 1
+	case chess.BlackWon:
+		chances[len(chances)-1] = 0
+	case chess.Draw:
+		chances[len(chances)-1] = 0.5
+	}
+	return chances
+}
+
+// biggestSwing returns the ply of the move that changed White's chances the
+// most, and by how much, or -1 if no move changed them
+func biggestSwing(chances []float64) (int, float64) {
+	ply, swing := -1, 0.0
+	for i := 1; i < len(chances); i++ {
+		if change := chances[i] - chances[i-1]; math.Abs(change) > math.Abs(swing) {
+			ply, swing = i-1, change
+		}
+	}
+	return ply, swing
+}
+
+// sparkline draws White's chances as a row of at most width bars, with the
+// column holding the move at ply highlighted
+func sparkline(chances []float64, ply, width int) string {
+	per := (len(chances) + width - 1) / width
+	highlight := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAA00")).Bold(true)
+	var sb strings.Builder
+	for start := 0; start < len(chances); start += per {
+		end := min(start+per, len(chances))
+		level := int(chances[end-1] * float64(len(sparklineLevels)))
+		bar := string(sparklineLevels[min(level, len(sparklineLevels)-1)])
+		// The move at ply leads to position ply+1
+		if ply >= 0 && ply+1 >= start && ply+1 < end {
+			bar = highlight.Render(bar)
+		}
+		sb.WriteString(bar)
+	}
+	return sb.String()
+}
+
+// renderWinProbabilityPanel graphs White's chances move by move as the game
+// goes, in at most width columns, naming the move that swung them the most
+func (g *Game) renderWinProbabilityPanel(width int) string {
+	chances := winProbabilities(g.chessGame)
+	if len(chances) < 2 {
+		return ""
+	}
+	ply, swing := biggestSwing(chances)
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#00AAFF"))
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Render("White's winning chances"),
+		sparkline(chances, ply, width) + style.Render(fmt.Sprintf(" %.0f%%", 100*chances[len(chances)-1])),
+	}
+	if ply >= 0 {
+		number := fmt.Sprintf("%d.", ply/2+1)
+		if ply%2 == 1 {
+			number += ".."
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("Biggest swing: %s %s, %.0f%% → %.0f%% (%+.0f)",
+			number, g.sanMoves()[ply], 100*chances[ply], 100*chances[ply+1], 100*swing)))
+	}
+	return strings.Join(lines, "\n")
+}
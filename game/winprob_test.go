@@ -0,0 +1,55 @@
+package game
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWinProbability(t *testing.T) {
+	if p := winProbability(0); p != 0.5 {
+		t.Errorf("Expected even chances in a level position, got %.2f", p)
+	}
+	if p := winProbability(300); math.Abs(p-0.75) > 0.01 {
+		t.Errorf("Expected about 75%% a piece up, got %.2f", p)
+	}
+	if p, q := winProbability(-300), winProbability(300); math.Abs(p+q-1) > 1e-9 {
+		t.Errorf("Expected symmetric chances, got %.2f and %.2f", p, q)
+	}
+}
+
+func TestWinProbabilityPanel(t *testing.T) {
+	g := NewGame()
+	if g.renderWinProbabilityPanel(sparklineWidth) != "" {
+		t.Error("Expected no graph before the first move")
+	}
+
+	// Black wins a pawn, then throws the queen away
+	for _, move := range []string{"e4", "Nf6", "d3", "Nxe4", "dxe4", "e5", "f3", "Qh4+", "g3", "Qxg3+", "hxg3", "d6", "Kf2", "Bg4", "fxg4", "Nc6", "Nc3", "Nb4"} {
+		g.makeMove(move)
+		if g.err != "" {
+			t.Fatalf("Expected %s to be legal, got %s", move, g.err)
+		}
+	}
+	panel := g.renderWinProbabilityPanel(sparklineWidth)
+	if !strings.Contains(panel, "White's winning chances") || !strings.ContainsAny(panel, "▁▂▃▄▅▆▇█") {
+		t.Errorf("Expected a graph of White's chances, got %q", panel)
+	}
+	// Taking the queen swings it the most
+	if !strings.Contains(panel, "Biggest swing: 6. hxg3") {
+		t.Errorf("Expected the queen capture as the biggest swing, got %q", panel)
+	}
+}
+
+func TestSparklineFitsLongGames(t *testing.T) {
+	chances := make([]float64, 300)
+	for i := range chances {
+		chances[i] = float64(i) / 300
+	}
+	if width := len([]rune(sparkline(chances, -1, sparklineWidth))); width > sparklineWidth {
+		t.Errorf("Expected at most %d columns, got %d", sparklineWidth, width)
+	}
+	if ply, swing := biggestSwing([]float64{0.5, 0.5}); ply != -1 || swing != 0 {
+		t.Errorf("Expected no swing, got %d (%.2f)", ply, swing)
+	}
+}
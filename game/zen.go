@@ -0,0 +1,11 @@
+package game
+
+// zenView renders the distraction-free view: just the board and the input
+// line, for streaming and screenshots
+func (g *Game) zenView() string {
+	view := g.renderBoard() + "\n"
+	if g.isAITurn {
+		return view + "🤖 ..."
+	}
+	return view + g.input.View()
+}
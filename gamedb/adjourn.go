@@ -0,0 +1,179 @@
+package gamedb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Adjournment is a game adjourned with a sealed move, to be resumed later
+type Adjournment struct {
+	ID         string    `json:"id"`
+	Adjourned  time.Time `json:"adjourned"`
+	White      string    `json:"white"`
+	Black      string    `json:"black"`
+	HumanColor string    `json:"human_color,omitempty"` // "white" or "black" in games against the AI
+	StartFEN   string    `json:"start_fen,omitempty"`
+	Moves      []string  `json:"moves"`     // in SAN, before the sealed move
+	SealedBy   string    `json:"sealed_by"` // "white" or "black"
+	Seal       Seal      `json:"seal"`
+}
+
+// Seal is a sealed move: encrypted under a seal code so that it can't be
+// read before the game resumes, and committed to by hash so that it can't be
+// changed
+type Seal struct {
+	Envelope   string `json:"envelope"`   // the move encrypted with AES-GCM, in base64
+	Commitment string `json:"commitment"` // SHA-256 of the seal code and the move, in hex
+}
+
+// DefaultAdjournedDir returns where adjourned games are kept in the user's
+// config directory
+func DefaultAdjournedDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "adjourned"
+	}
+	return filepath.Join(home, ".bubblechess", "adjourned")
+}
+
+// SealMove seals a move, returning the seal and the code that opens it. The
+// code is for the player who sealed the move alone until the game resumes.
+func SealMove(move string) (Seal, string, error) {
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return Seal{}, "", fmt.Errorf("failed to generate seal code: %w", err)
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(raw))
+	code = code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16]
+
+	gcm, err := sealCipher(code)
+	if err != nil {
+		return Seal{}, "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return Seal{}, "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	envelope := gcm.Seal(nonce, nonce, []byte(move), nil)
+	return Seal{
+		Envelope:   base64.StdEncoding.EncodeToString(envelope),
+		Commitment: commitment(code, move),
+	}, code, nil
+}
+
+// Open reveals the sealed move with the seal code, checking it against the
+// commitment
+func (s Seal) Open(code string) (string, error) {
+	envelope, err := base64.StdEncoding.DecodeString(s.Envelope)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode sealed move: %w", err)
+	}
+	gcm, err := sealCipher(code)
+	if err != nil {
+		return "", err
+	}
+	if len(envelope) < gcm.NonceSize() {
+		return "", errors.New("sealed move is damaged")
+	}
+	nonce, sealed := envelope[:gcm.NonceSize()], envelope[gcm.NonceSize():]
+	move, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", errors.New("wrong seal code")
+	}
+	if commitment(code, string(move)) != s.Commitment {
+		return "", errors.New("sealed move doesn't match its commitment")
+	}
+	return string(move), nil
+}
+
+// normalizeCode lets a seal code be typed in any case, with or without dashes
+func normalizeCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
+
+// sealCipher returns the AES-GCM cipher keyed by a seal code
+func sealCipher(code string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte("bubblechess seal\x00" + normalizeCode(code)))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// commitment hashes a seal code and move
+func commitment(code, move string) string {
+	sum := sha256.Sum256([]byte(normalizeCode(code) + ":" + move))
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveAdjournment writes an adjourned game to dir, or the default directory
+// if empty, named by its ID. It returns the file's path.
+func SaveAdjournment(dir string, adjournment Adjournment) (string, error) {
+	if dir == "" {
+		dir = DefaultAdjournedDir()
+	}
+	if adjournment.Adjourned.IsZero() {
+		adjournment.Adjourned = time.Now()
+	}
+	data, err := json.MarshalIndent(adjournment, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode adjourned game: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create adjourned games directory: %w", err)
+	}
+	path := filepath.Join(dir, adjournment.ID+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to save adjourned game: %w", err)
+	}
+	return path, nil
+}
+
+// LoadAdjournment reads an adjourned game saved with SaveAdjournment
+func LoadAdjournment(path string) (Adjournment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Adjournment{}, fmt.Errorf("failed to read adjourned game: %w", err)
+	}
+	var adjournment Adjournment
+	if err := json.Unmarshal(data, &adjournment); err != nil {
+		return Adjournment{}, fmt.Errorf("failed to parse adjourned game: %w", err)
+	}
+	return adjournment, nil
+}
+
+// Adjournments lists the files of the games adjourned in dir, or the
+// default directory if empty, most recent first
+func Adjournments(dir string) ([]string, error) {
+	if dir == "" {
+		dir = DefaultAdjournedDir()
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list adjourned games: %w", err)
+	}
+	modified := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			modified[path] = info.ModTime()
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		return modified[paths[i]].After(modified[paths[j]])
+	})
+	return paths, nil
+}
@@ -0,0 +1,43 @@
+package gamedb
+
+import (
+	"testing"
+)
+
+func TestSealMove(t *testing.T) {
+	seal, code, err := SealMove("Nf3")
+	if err != nil {
+		t.Fatalf("Expected the move to be sealed, got %v", err)
+	}
+	if len(code) != 19 {
+		t.Errorf("Expected a code like xxxx-xxxx-xxxx-xxxx, got %q", code)
+	}
+	if _, err := seal.Open("aaaa-aaaa-aaaa-aaaa"); err == nil {
+		t.Error("Expected a wrong code to fail")
+	}
+	if move, err := seal.Open(" " + code[:9] + code[10:] + " "); err != nil || move != "Nf3" {
+		t.Errorf("Expected Nf3 with the code typed loosely, got %q, %v", move, err)
+	}
+
+	// A seal whose commitment was swapped for another move's is refused
+	other, _, _ := SealMove("Nc3")
+	seal.Commitment = other.Commitment
+	if _, err := seal.Open(code); err == nil {
+		t.Error("Expected a changed commitment to be refused")
+	}
+}
+
+func TestSaveAdjournment(t *testing.T) {
+	dir := t.TempDir()
+	path, err := SaveAdjournment(dir, Adjournment{ID: "abc", Moves: []string{"e4"}, SealedBy: "black"})
+	if err != nil {
+		t.Fatalf("Expected the game to be saved, got %v", err)
+	}
+	adjournment, err := LoadAdjournment(path)
+	if err != nil || adjournment.ID != "abc" || adjournment.SealedBy != "black" || adjournment.Adjourned.IsZero() {
+		t.Errorf("Expected the game back, got %+v, %v", adjournment, err)
+	}
+	if paths, err := Adjournments(dir); err != nil || len(paths) != 1 || paths[0] != path {
+		t.Errorf("Expected %s to be listed, got %v, %v", path, paths, err)
+	}
+}
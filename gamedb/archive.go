@@ -0,0 +1,275 @@
+package gamedb
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// archiveIndex is the name of the archive's index file, in its top directory
+const archiveIndex = "index.jsonl"
+
+// ArchivePolicy configures the automatic archive of finished games. Zero
+// limits keep every game.
+type ArchivePolicy struct {
+	Disabled  bool   `json:"disabled,omitempty"`
+	Dir       string `json:"dir,omitempty"`        // default ~/.bubblechess/archive
+	KeepGames int    `json:"keep_games,omitempty"` // keep only the newest games
+	KeepDays  int    `json:"keep_days,omitempty"`  // drop games older than this
+}
+
+// ArchiveEntry is a game in the archive's index
+type ArchiveEntry struct {
+	Path   string    `json:"path"` // the PGN file, relative to the archive directory
+	Played time.Time `json:"played"`
+	White  string    `json:"white"`
+	Black  string    `json:"black"`
+	Result string    `json:"result"`
+}
+
+// ID names the game in the archive: its PGN file's name without the
+// extension, e.g. "20250301-142210-alex-vs-gpt-4o"
+func (e ArchiveEntry) ID() string {
+	return strings.TrimSuffix(path.Base(e.Path), ".pgn")
+}
+
+// Archive keeps the PGN of every finished game in a directory per month,
+// e.g. archive/2025/03, with an index of the games, and drops old games as
+// its policy says
+type Archive struct {
+	dir    string
+	policy ArchivePolicy
+	mu     sync.Mutex
+	now    func() time.Time
+}
+
+// DefaultArchiveDir returns the archive location in the user's config directory
+func DefaultArchiveDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "archive"
+	}
+	return filepath.Join(home, ".bubblechess", "archive")
+}
+
+// OpenArchive returns the archive the policy describes. Directories are
+// created when the first game is saved.
+func OpenArchive(policy ArchivePolicy) *Archive {
+	dir := policy.Dir
+	if dir == "" {
+		dir = DefaultArchiveDir()
+	}
+	return &Archive{dir: dir, policy: policy, now: time.Now}
+}
+
+// Dir returns the archive's top directory
+func (a *Archive) Dir() string {
+	return a.dir
+}
+
+// Save writes a finished game's PGN to the month it was played in, adds it
+// to the index and applies the retention policy. It returns the PGN file's path.
+func (a *Archive) Save(entry ArchiveEntry, pgn string) (string, error) {
+	if entry.Played.IsZero() {
+		entry.Played = a.now()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	month := filepath.Join(entry.Played.Format("2006"), entry.Played.Format("01"))
+	if err := os.MkdirAll(filepath.Join(a.dir, month), 0755); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	// Games finished in the same second get a counter
+	base := entry.Played.Format("20060102-150405") + "-" + slug(entry.White) + "-vs-" + slug(entry.Black)
+	name := base + ".pgn"
+	for i := 2; fileExists(filepath.Join(a.dir, month, name)); i++ {
+		name = fmt.Sprintf("%s-%d.pgn", base, i)
+	}
+	entry.Path = filepath.ToSlash(filepath.Join(month, name))
+	path := filepath.Join(a.dir, month, name)
+	if err := os.WriteFile(path, []byte(pgn), 0644); err != nil {
+		return "", fmt.Errorf("failed to write archived game: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode archive entry: %w", err)
+	}
+	index, err := os.OpenFile(filepath.Join(a.dir, archiveIndex), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive index: %w", err)
+	}
+	defer index.Close()
+	if _, err := index.Write(append(data, '\n')); err != nil {
+		return "", fmt.Errorf("failed to write archive index: %w", err)
+	}
+
+	if err := a.rotate(); err != nil {
+		return path, err
+	}
+	return path, nil
+}
+
+// Entries returns the games in the index, oldest first
+func (a *Archive) Entries() ([]ArchiveEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.entries()
+}
+
+// Game returns the archived game with the ID and its PGN. An ID not in the
+// index is an os.ErrNotExist error, so only indexed files are ever read.
+func (a *Archive) Game(id string) (ArchiveEntry, string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entries, err := a.entries()
+	if err != nil {
+		return ArchiveEntry{}, "", err
+	}
+	for _, entry := range entries {
+		if entry.ID() != id {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(a.dir, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			return ArchiveEntry{}, "", fmt.Errorf("failed to read archived game: %w", err)
+		}
+		return entry, string(data), nil
+	}
+	return ArchiveEntry{}, "", fmt.Errorf("no archived game %q: %w", id, os.ErrNotExist)
+}
+
+// Prune applies the retention policy without saving a game, dropping those
+// that have grown too old since the last one was saved
+func (a *Archive) Prune() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.rotate()
+}
+
+// entries reads the index. The caller holds a.mu.
+func (a *Archive) entries() ([]ArchiveEntry, error) {
+	file, err := os.Open(filepath.Join(a.dir, archiveIndex))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive index: %w", err)
+	}
+	defer file.Close()
+
+	var entries []ArchiveEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry ArchiveEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archive index: %w", err)
+	}
+	return entries, nil
+}
+
+// rotate deletes the games the policy no longer keeps and rewrites the
+// index without them. The caller holds a.mu.
+func (a *Archive) rotate() error {
+	if a.policy.KeepGames <= 0 && a.policy.KeepDays <= 0 {
+		return nil
+	}
+	entries, err := a.entries()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Time{}
+	if a.policy.KeepDays > 0 {
+		cutoff = a.now().AddDate(0, 0, -a.policy.KeepDays)
+	}
+	var kept, dropped []ArchiveEntry
+	for i, entry := range entries {
+		tooMany := a.policy.KeepGames > 0 && len(entries)-i > a.policy.KeepGames
+		if tooMany || entry.Played.Before(cutoff) {
+			dropped = append(dropped, entry)
+		} else {
+			kept = append(kept, entry)
+		}
+	}
+	if len(dropped) == 0 {
+		return nil
+	}
+
+	if err := a.writeIndex(kept); err != nil {
+		return err
+	}
+	for _, entry := range dropped {
+		path := filepath.Join(a.dir, filepath.FromSlash(entry.Path))
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove archived game: %w", err)
+		}
+		// Drop the month's and year's directories once they are empty
+		month := filepath.Dir(path)
+		if os.Remove(month) == nil {
+			os.Remove(filepath.Dir(month))
+		}
+	}
+	return nil
+}
+
+// writeIndex replaces the index with entries, through a temporary file so
+// a crash can't leave it half written. The caller holds a.mu.
+func (a *Archive) writeIndex(entries []ArchiveEntry) error {
+	var sb strings.Builder
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode archive entry: %w", err)
+		}
+		sb.Write(append(data, '\n'))
+	}
+	path := filepath.Join(a.dir, archiveIndex)
+	if err := os.WriteFile(path+".tmp", []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write archive index: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to replace archive index: %w", err)
+	}
+	return nil
+}
+
+// slug turns a player's name into a file name part, e.g. "GPT-4o mini" into "gpt-4o-mini"
+func slug(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	s := strings.TrimSuffix(sb.String(), "-")
+	if s == "" {
+		return "player"
+	}
+	return s
+}
+
+// fileExists reports whether a file is at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,120 @@
+package gamedb
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchiveSavesByMonth(t *testing.T) {
+	archive := OpenArchive(ArchivePolicy{Dir: t.TempDir()})
+	played := time.Date(2025, 3, 14, 15, 9, 26, 0, time.Local)
+
+	path, err := archive.Save(ArchiveEntry{Played: played, White: "Human", Black: "GPT-4o mini", Result: WhiteWon}, "1. e4 1-0\n")
+	if err != nil {
+		t.Fatalf("Failed to archive game: %v", err)
+	}
+	want := filepath.Join(archive.Dir(), "2025", "03", "20250314-150926-human-vs-gpt-4o-mini.pgn")
+	if path != want {
+		t.Errorf("Expected %s, got %s", want, path)
+	}
+
+	// A second game in the same second doesn't overwrite the first
+	second, err := archive.Save(ArchiveEntry{Played: played, White: "Human", Black: "GPT-4o mini", Result: Draw}, "1. d4 1/2-1/2\n")
+	if err != nil {
+		t.Fatalf("Failed to archive game: %v", err)
+	}
+	if second == path {
+		t.Error("Expected a new file for the second game")
+	}
+
+	entries, err := archive.Entries()
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Path != "2025/03/20250314-150926-human-vs-gpt-4o-mini.pgn" || entries[1].Result != Draw {
+		t.Errorf("Expected both games in the index, got %v", entries)
+	}
+}
+
+func TestArchiveRotation(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	archive := OpenArchive(ArchivePolicy{Dir: t.TempDir(), KeepGames: 2, KeepDays: 60})
+	archive.now = func() time.Time { return now }
+
+	save := func(played time.Time) string {
+		path, err := archive.Save(ArchiveEntry{Played: played, White: "a", Black: "b", Result: WhiteWon}, "1-0\n")
+		if err != nil {
+			t.Fatalf("Failed to archive game: %v", err)
+		}
+		return path
+	}
+	old := save(now.AddDate(0, -3, 0)) // past KeepDays
+	first := save(now.AddDate(0, 0, -2))
+	second := save(now.AddDate(0, 0, -1))
+	third := save(now)
+
+	entries, err := archive.Entries()
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected the 2 newest games to be kept, got %v", entries)
+	}
+	for _, path := range []string{old, first} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be deleted", path)
+		}
+	}
+	for _, path := range []string{second, third} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be kept, got %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Dir(old)); !os.IsNotExist(err) {
+		t.Error("Expected the emptied month directory to be removed")
+	}
+}
+
+func TestArchiveGameByID(t *testing.T) {
+	archive := OpenArchive(ArchivePolicy{Dir: t.TempDir()})
+	played := time.Date(2025, 3, 14, 15, 9, 26, 0, time.Local)
+	if _, err := archive.Save(ArchiveEntry{Played: played, White: "Human", Black: "AI", Result: WhiteWon}, "1. e4 1-0\n"); err != nil {
+		t.Fatalf("Failed to archive game: %v", err)
+	}
+
+	entry, pgn, err := archive.Game("20250314-150926-human-vs-ai")
+	if err != nil {
+		t.Fatalf("Expected the game, got %v", err)
+	}
+	if entry.White != "Human" || pgn != "1. e4 1-0\n" {
+		t.Errorf("Expected the game's entry and PGN, got %+v %q", entry, pgn)
+	}
+
+	// Only games in the index are read
+	for _, id := range []string{"missing", "../index", archiveIndex} {
+		if _, _, err := archive.Game(id); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected %q not to be found, got %v", id, err)
+		}
+	}
+}
+
+func TestArchivePrune(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	archive := OpenArchive(ArchivePolicy{Dir: t.TempDir(), KeepDays: 30})
+	archive.now = func() time.Time { return now }
+	if _, err := archive.Save(ArchiveEntry{Played: now.AddDate(0, 0, -20), White: "a", Black: "b", Result: Draw}, "1/2-1/2\n"); err != nil {
+		t.Fatalf("Failed to archive game: %v", err)
+	}
+
+	// Weeks later, with no game saved since, the game has aged out
+	now = now.AddDate(0, 0, 15)
+	if err := archive.Prune(); err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	if entries, _ := archive.Entries(); len(entries) != 0 {
+		t.Errorf("Expected the old game to be dropped, got %v", entries)
+	}
+}
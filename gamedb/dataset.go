@@ -0,0 +1,182 @@
+package gamedb
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Outcomes of a game for the side that made a move
+const (
+	OutcomeWin  = "win"
+	OutcomeLoss = "loss"
+	OutcomeDraw = "draw"
+)
+
+// Sample is one AI move of a finished game, as training data for a
+// chess-move model: the position, the legal moves, the move chosen and how
+// the game ended for the side that chose it. Prompt and Completion hold the
+// same example in the prompt/completion form fine-tuning tools read.
+type Sample struct {
+	FEN        string   `json:"fen"`
+	LegalMoves []string `json:"legal_moves"` // in SAN
+	Move       string   `json:"move"`        // in SAN
+	MoveUCI    string   `json:"move_uci"`
+	Side       string   `json:"side"`    // "white" or "black"
+	Player     string   `json:"player"`  // the AI opponent's name
+	Outcome    string   `json:"outcome"` // OutcomeWin, OutcomeLoss or OutcomeDraw for Side
+	Result     string   `json:"result"`  // the game's result, as in PGN
+	GameID     string   `json:"game_id,omitempty"`
+	Prompt     string   `json:"prompt"`
+	Completion string   `json:"completion"`
+}
+
+// SamplePrompt is the prompt of a sample: the position and the moves to
+// choose from
+func SamplePrompt(fen string, legalMoves []string) string {
+	return fmt.Sprintf("Position (FEN): %s\nLegal moves: %s\nBest move:", fen, strings.Join(legalMoves, " "))
+}
+
+// OutcomeFor returns how a game with result ended for side, "white" or
+// "black"
+func OutcomeFor(result, side string) string {
+	switch {
+	case result == Draw:
+		return OutcomeDraw
+	case (result == WhiteWon) == (side == "white"):
+		return OutcomeWin
+	default:
+		return OutcomeLoss
+	}
+}
+
+// Dataset is a JSONL file of training samples that finished games are
+// appended to, for data collection players opt into
+type Dataset struct {
+	path string
+	mu   sync.Mutex
+}
+
+// OpenDataset returns the dataset at path. The file is created when the
+// first samples are added.
+func OpenDataset(path string) *Dataset {
+	return &Dataset{path: path}
+}
+
+// Path returns the file the dataset is stored in
+func (d *Dataset) Path() string {
+	return d.path
+}
+
+// Add appends samples to the dataset, one JSON object per line
+func (d *Dataset) Add(samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	data, err := encodeSamples(samples)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(d.path), 0755); err != nil {
+		return fmt.Errorf("failed to create dataset directory: %w", err)
+	}
+	file, err := os.OpenFile(d.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write training samples: %w", err)
+	}
+	return nil
+}
+
+// Replace rewrites the dataset to hold just samples
+func (d *Dataset) Replace(samples []Sample) error {
+	data, err := encodeSamples(samples)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := os.WriteFile(d.path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to write dataset: %w", err)
+	}
+	if err := os.Rename(d.path+".tmp", d.path); err != nil {
+		return fmt.Errorf("failed to replace dataset: %w", err)
+	}
+	return nil
+}
+
+// encodeSamples encodes samples as JSON lines
+func encodeSamples(samples []Sample) ([]byte, error) {
+	var data []byte
+	for _, sample := range samples {
+		line, err := json.Marshal(sample)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode training sample: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	return data, nil
+}
+
+// Samples returns every sample in the dataset, oldest first. Lines that
+// can't be decoded are skipped.
+func (d *Dataset) Samples() ([]Sample, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	file, err := os.Open(d.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer file.Close()
+
+	var samples []Sample
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var sample Sample
+		if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	return samples, nil
+}
+
+// SplitSamples divides samples into a training set and the fraction held
+// out for evaluation. The split goes by position, so the same position
+// always lands on the same side, however often it was played and whatever
+// else the dataset holds.
+func SplitSamples(samples []Sample, fraction float64) (training, heldOut []Sample) {
+	for _, sample := range samples {
+		hash := fnv.New32a()
+		hash.Write([]byte(sample.FEN))
+		if float64(hash.Sum32()%10000) < fraction*10000 {
+			heldOut = append(heldOut, sample)
+		} else {
+			training = append(training, sample)
+		}
+	}
+	return training, heldOut
+}
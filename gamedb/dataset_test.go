@@ -0,0 +1,91 @@
+package gamedb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDatasetAppends(t *testing.T) {
+	dataset := OpenDataset(filepath.Join(t.TempDir(), "data", "samples.jsonl"))
+	dataset.Add([]Sample{{FEN: "a", Move: "e4"}, {FEN: "b", Move: "e5"}})
+	dataset.Add([]Sample{{FEN: "c", Move: "Nf3"}})
+	dataset.Add(nil)
+
+	data, err := os.ReadFile(dataset.Path())
+	if err != nil {
+		t.Fatalf("Failed to read dataset: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[2], `"move":"Nf3"`) {
+		t.Errorf("Expected 3 JSON lines, got:\n%s", data)
+	}
+}
+
+func TestOutcomeFor(t *testing.T) {
+	tests := []struct{ result, side, want string }{
+		{WhiteWon, "white", OutcomeWin},
+		{WhiteWon, "black", OutcomeLoss},
+		{BlackWon, "black", OutcomeWin},
+		{Draw, "white", OutcomeDraw},
+	}
+	for _, tt := range tests {
+		if got := OutcomeFor(tt.result, tt.side); got != tt.want {
+			t.Errorf("OutcomeFor(%s, %s): expected %s, got %s", tt.result, tt.side, tt.want, got)
+		}
+	}
+}
+
+func TestSplitSamples(t *testing.T) {
+	var samples []Sample
+	for i := 0; i < 1000; i++ {
+		samples = append(samples, Sample{FEN: fmt.Sprintf("position %d", i)})
+	}
+	samples = append(samples, samples[0]) // the same position played twice
+
+	training, heldOut := SplitSamples(samples, 0.1)
+	if len(training)+len(heldOut) != len(samples) {
+		t.Fatalf("Expected every sample in one split, got %d and %d", len(training), len(heldOut))
+	}
+	if len(heldOut) < 60 || len(heldOut) > 140 {
+		t.Errorf("Expected about a tenth held out, got %d", len(heldOut))
+	}
+	count := 0
+	for _, sample := range heldOut {
+		if sample.FEN == samples[0].FEN {
+			count++
+		}
+	}
+	if count != 0 && count != 2 {
+		t.Errorf("Expected a position's samples on the same side of the split")
+	}
+
+	_, again := SplitSamples(samples, 0.1)
+	if len(again) != len(heldOut) {
+		t.Errorf("Expected the split to be stable")
+	}
+	if _, all := SplitSamples(samples, 1); len(all) != len(samples) {
+		t.Errorf("Expected a holdout of 1 to hold out everything, got %d", len(all))
+	}
+}
+
+func TestDatasetSamples(t *testing.T) {
+	dataset := OpenDataset(filepath.Join(t.TempDir(), "samples.jsonl"))
+	if samples, err := dataset.Samples(); err != nil || len(samples) != 0 {
+		t.Fatalf("Expected no samples yet, got %d and %v", len(samples), err)
+	}
+	dataset.Add([]Sample{{FEN: "a", Move: "e4"}, {FEN: "b", Move: "e5"}})
+	samples, err := dataset.Samples()
+	if err != nil || len(samples) != 2 || samples[1].Move != "e5" {
+		t.Errorf("Expected the 2 samples back, got %+v and %v", samples, err)
+	}
+
+	if err := dataset.Replace(samples[1:]); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if samples, _ := dataset.Samples(); len(samples) != 1 || samples[0].Move != "e5" {
+		t.Errorf("Expected just the sample kept, got %+v", samples)
+	}
+}
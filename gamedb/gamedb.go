@@ -0,0 +1,190 @@
+// Package gamedb keeps a log of finished games, one JSON record per line,
+// for records and statistics across sessions.
+package gamedb
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Results of a game, as in PGN
+const (
+	WhiteWon = "1-0"
+	BlackWon = "0-1"
+	Draw     = "1/2-1/2"
+	NoResult = "*" // an aborted game
+)
+
+// Record is one finished game
+type Record struct {
+	ID          string     `json:"id,omitempty"` // the game's ID, as in its logs, events and PGN
+	Played      time.Time  `json:"played"`
+	White       string     `json:"white"`
+	Black       string     `json:"black"`
+	HumanColor  string     `json:"human_color,omitempty"` // "white" or "black" in games against the AI
+	Opponent    string     `json:"opponent,omitempty"`    // the AI opponent's name in games against the AI
+	Result      string     `json:"result"`
+	Termination string     `json:"termination,omitempty"`   // e.g. "Checkmate", "TimeForfeit", "Abandoned"
+	StartFEN    string     `json:"start_fen,omitempty"`     // the position the game started from, if not the standard one
+	Moves       []string   `json:"moves"`                   // in SAN
+	MoveTimes   []int64    `json:"move_times_ms,omitempty"` // milliseconds spent on each move, where timed
+	Bookmarks   []Bookmark `json:"bookmarks,omitempty"`
+}
+
+// Bookmark marks a position of a game, with an optional note
+type Bookmark struct {
+	Ply  int    `json:"ply"` // the number of moves played to reach the position
+	Note string `json:"note,omitempty"`
+}
+
+// DB is a game log stored in a file
+type DB struct {
+	path string
+	mu   sync.Mutex
+}
+
+// DefaultPath returns the game log location in the user's config directory
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "games.jsonl"
+	}
+	return filepath.Join(home, ".bubblechess", "games.jsonl")
+}
+
+// Open returns the game log at path, or the default location if path is empty.
+// The file is created when the first game is added.
+func Open(path string) *DB {
+	if path == "" {
+		path = DefaultPath()
+	}
+	return &DB{path: path}
+}
+
+// Path returns the file the log is stored in
+func (db *DB) Path() string {
+	return db.path
+}
+
+// Add appends a finished game to the log
+func (db *DB) Add(record Record) error {
+	if record.Played.IsZero() {
+		record.Played = time.Now()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode game record: %w", err)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(db.path), 0755); err != nil {
+		return fmt.Errorf("failed to create game log directory: %w", err)
+	}
+	file, err := os.OpenFile(db.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open game log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write game record: %w", err)
+	}
+	return nil
+}
+
+// Games returns every game in the log, oldest first. Lines that can't be
+// decoded, such as one cut short by a crash, are skipped.
+func (db *DB) Games() ([]Record, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	file, err := os.Open(db.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open game log: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err == nil {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read game log: %w", err)
+	}
+	return records, nil
+}
+
+// Score is a win/loss/draw record
+type Score struct {
+	Wins   int
+	Losses int
+	Draws  int
+}
+
+// Games returns the number of games in the score
+func (s Score) Games() int {
+	return s.Wins + s.Losses + s.Draws
+}
+
+// String formats the score as e.g. "3W 1L 2D"
+func (s Score) String() string {
+	return fmt.Sprintf("%dW %dL %dD", s.Wins, s.Losses, s.Draws)
+}
+
+// HeadToHead returns the human's score against each AI opponent, by name
+func HeadToHead(records []Record) map[string]Score {
+	scores := make(map[string]Score)
+	for _, record := range records {
+		if record.Opponent == "" || record.HumanColor == "" {
+			continue
+		}
+		humanWon := (record.Result == WhiteWon && record.HumanColor == "white") ||
+			(record.Result == BlackWon && record.HumanColor == "black")
+
+		score := scores[record.Opponent]
+		switch record.Result {
+		case Draw:
+			score.Draws++
+		case WhiteWon, BlackWon:
+			if humanWon {
+				score.Wins++
+			} else {
+				score.Losses++
+			}
+		default:
+			continue
+		}
+		scores[record.Opponent] = score
+	}
+	return scores
+}
+
+// HumanScores returns the human's scores against the named AI opponent in
+// the order the games were played: 1 for a win, 0.5 for a draw, 0 for a loss
+func HumanScores(records []Record, opponent string) []float64 {
+	var scores []float64
+	for _, record := range records {
+		if record.Opponent != opponent {
+			continue
+		}
+		if outcome, ok := humanOutcome(record); ok {
+			scores = append(scores, float64(outcome+1)/2)
+		}
+	}
+	return scores
+}
@@ -0,0 +1,79 @@
+package gamedb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddAndReadGames(t *testing.T) {
+	db := Open(filepath.Join(t.TempDir(), "games.jsonl"))
+
+	games, err := db.Games()
+	if err != nil || len(games) != 0 {
+		t.Fatalf("Expected empty log, got %d games and %v", len(games), err)
+	}
+
+	db.Add(Record{White: "Human", Black: "Gus", HumanColor: "white", Opponent: "Gus", Result: WhiteWon, Moves: []string{"f3", "e5", "g4", "Qh4#"}})
+	db.Add(Record{White: "Gus", Black: "Human", HumanColor: "black", Opponent: "Gus", Result: WhiteWon})
+
+	// A line cut short by a crash is skipped
+	file, _ := os.OpenFile(db.Path(), os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString(`{"white":"Hum`)
+	file.Close()
+
+	games, err = db.Games()
+	if err != nil {
+		t.Fatalf("Failed to read games: %v", err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("Expected 2 games, got %d", len(games))
+	}
+	if games[0].Played.IsZero() {
+		t.Error("Expected the time played to be filled in")
+	}
+	if len(games[0].Moves) != 4 {
+		t.Errorf("Expected 4 moves, got %v", games[0].Moves)
+	}
+}
+
+func TestHeadToHead(t *testing.T) {
+	scores := HeadToHead([]Record{
+		{Opponent: "Gus", HumanColor: "white", Result: WhiteWon},
+		{Opponent: "Gus", HumanColor: "black", Result: WhiteWon},
+		{Opponent: "Gus", HumanColor: "black", Result: BlackWon},
+		{Opponent: "Gus", HumanColor: "white", Result: Draw},
+		{Opponent: "Ada", HumanColor: "white", Result: BlackWon},
+		{White: "Human", Black: "Human", Result: WhiteWon},
+	})
+
+	if got := scores["Gus"]; got != (Score{Wins: 2, Losses: 1, Draws: 1}) {
+		t.Errorf("Expected 2W 1L 1D against Gus, got %s", got)
+	}
+	if got := scores["Ada"]; got != (Score{Losses: 1}) {
+		t.Errorf("Expected 0W 1L 0D against Ada, got %s", got)
+	}
+	if len(scores) != 2 {
+		t.Errorf("Expected scores for 2 opponents, got %d", len(scores))
+	}
+}
+
+func TestHumanScores(t *testing.T) {
+	scores := HumanScores([]Record{
+		{Opponent: "Gus", HumanColor: "white", Result: WhiteWon},
+		{Opponent: "Ada", HumanColor: "white", Result: BlackWon},
+		{Opponent: "Gus", HumanColor: "black", Result: WhiteWon},
+		{Opponent: "Gus", HumanColor: "white", Result: "*"},
+		{Opponent: "Gus", HumanColor: "black", Result: Draw},
+	}, "Gus")
+
+	want := []float64{1, 0, 0.5}
+	if len(scores) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, scores)
+	}
+	for i := range want {
+		if scores[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, scores)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package gamedb
+
+import "github.com/notnil/chess"
+
+// HeatmapKind selects what a heatmap counts on each square
+type HeatmapKind int
+
+const (
+	// Destinations counts the squares pieces moved to
+	Destinations HeatmapKind = iota
+	// Captures counts the squares where pieces were captured
+	Captures
+	// KingSquares counts the squares the kings stood on after each move
+	KingSquares
+)
+
+// HeatmapKinds lists the kinds of heatmap in display order
+var HeatmapKinds = []HeatmapKind{Destinations, Captures, KingSquares}
+
+// String names the kind, e.g. "Move destinations"
+func (k HeatmapKind) String() string {
+	switch k {
+	case Captures:
+		return "Captures"
+	case KingSquares:
+		return "King positions"
+	default:
+		return "Move destinations"
+	}
+}
+
+// Heatmap counts events per square, indexed like chess.Square (a1 is 0, h8 is 63)
+type Heatmap [64]int
+
+// Max returns the highest count on any square
+func (h Heatmap) Max() int {
+	most := 0
+	for _, count := range h {
+		most = max(most, count)
+	}
+	return most
+}
+
+// Hottest returns the square with the highest count, and false for an empty heatmap
+func (h Heatmap) Hottest() (chess.Square, bool) {
+	best := 0
+	for i, count := range h {
+		if count > h[best] {
+			best = i
+		}
+	}
+	return chess.Square(best), h[best] > 0
+}
+
+// BuildHeatmap counts kind over games, each given as its moves in SAN. A
+// game that can't be replayed is counted up to the first bad move.
+func BuildHeatmap(kind HeatmapKind, games ...[]string) Heatmap {
+	var heatmap Heatmap
+	for _, moves := range games {
+		game := chess.NewGame()
+		for _, san := range moves {
+			move, err := chess.AlgebraicNotation{}.Decode(game.Position(), san)
+			if err != nil || game.Move(move) != nil {
+				break
+			}
+			switch kind {
+			case Destinations:
+				heatmap[move.S2()]++
+			case Captures:
+				if move.HasTag(chess.EnPassant) {
+					// The captured pawn stood beside the destination
+					heatmap[chess.NewSquare(move.S2().File(), move.S1().Rank())]++
+				} else if move.HasTag(chess.Capture) {
+					heatmap[move.S2()]++
+				}
+			case KingSquares:
+				for square, piece := range game.Position().Board().SquareMap() {
+					if piece.Type() == chess.King {
+						heatmap[square]++
+					}
+				}
+			}
+		}
+	}
+	return heatmap
+}
+
+// RecordMoves returns the moves of every game in records, for BuildHeatmap
+func RecordMoves(records []Record) [][]string {
+	games := make([][]string, 0, len(records))
+	for _, record := range records {
+		games = append(games, record.Moves)
+	}
+	return games
+}
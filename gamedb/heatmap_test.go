@@ -0,0 +1,37 @@
+package gamedb
+
+import (
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestBuildHeatmap(t *testing.T) {
+	// 1. e4 d5 2. exd5 c5 3. dxc6 (en passant)
+	game := []string{"e4", "d5", "exd5", "c5", "dxc6"}
+
+	destinations := BuildHeatmap(Destinations, game, []string{"e4"})
+	if destinations[chess.E4] != 2 || destinations[chess.D5] != 2 {
+		t.Errorf("Expected e4 and d5 reached twice, got e4=%d d5=%d", destinations[chess.E4], destinations[chess.D5])
+	}
+	if square, _ := destinations.Hottest(); square != chess.D5 && square != chess.E4 {
+		t.Errorf("Expected e4 or d5 to be hottest, got %s", square)
+	}
+
+	captures := BuildHeatmap(Captures, game)
+	if captures[chess.D5] != 1 || captures[chess.C5] != 1 || captures.Max() != 1 {
+		t.Errorf("Expected captures on d5 and c5 (en passant), got %v", captures)
+	}
+
+	kings := BuildHeatmap(KingSquares, game)
+	if kings[chess.E1] != 5 || kings[chess.E8] != 5 {
+		t.Errorf("Expected both kings on their home squares after each of 5 moves, got e1=%d e8=%d", kings[chess.E1], kings[chess.E8])
+	}
+}
+
+func TestBuildHeatmapStopsAtBadMove(t *testing.T) {
+	heatmap := BuildHeatmap(Destinations, []string{"e4", "Ke3", "e5"})
+	if heatmap[chess.E4] != 1 || heatmap[chess.E5] != 0 {
+		t.Errorf("Expected counting to stop at the illegal move, got %v", heatmap)
+	}
+}
@@ -0,0 +1,204 @@
+package gamedb
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/notnil/chess"
+	"github.com/notnil/chess/opening"
+)
+
+// Game phases, by the number of full moves played
+const (
+	openingMoves    = 15
+	middlegameMoves = 40
+)
+
+// OpeningStats is the human's score in one opening with one color
+type OpeningStats struct {
+	ECO   string // e.g. "C50"
+	Name  string // e.g. "Italian Game"
+	Color string // "white" or "black"
+	Score Score
+}
+
+// Pattern is a way of losing and how often it happened
+type Pattern struct {
+	Description string // e.g. "Checkmated in the middlegame"
+	Count       int
+}
+
+// Summary is the human's record across the games played against the AI
+type Summary struct {
+	Games         int
+	ByColor       map[string]Score // "white" and "black"
+	Openings      []OpeningStats   // most played first
+	AverageLength float64          // in full moves
+	LossPatterns  []Pattern        // most common first
+}
+
+// book classifies openings; building it parses the whole ECO table, so it
+// is only done once, when first needed
+var book = sync.OnceValue(opening.NewBookECO)
+
+// Opening names the opening played in moves, given in SAN. It returns empty
+// strings when the game left the book on the first move.
+func Opening(moves []string) (eco, name string) {
+	game := chess.NewGame()
+	var played []*chess.Move
+	for _, san := range moves {
+		move, err := chess.AlgebraicNotation{}.Decode(game.Position(), san)
+		if err != nil || game.Move(move) != nil {
+			break
+		}
+		played = append(played, move)
+	}
+	found := book().Find(played)
+	if found == nil {
+		return "", ""
+	}
+	return found.Code(), found.Title()
+}
+
+// Summarize computes the human's statistics from the games against the AI in
+// records; games between two humans have no side to score and are skipped
+func Summarize(records []Record) Summary {
+	summary := Summary{ByColor: make(map[string]Score)}
+	openings := make(map[[2]string]*OpeningStats)
+	patterns := make(map[string]int)
+	totalMoves := 0
+
+	for _, record := range records {
+		outcome, ok := humanOutcome(record)
+		if !ok {
+			continue
+		}
+		summary.Games++
+		totalMoves += (len(record.Moves) + 1) / 2
+
+		score := summary.ByColor[record.HumanColor]
+		score.add(outcome)
+		summary.ByColor[record.HumanColor] = score
+
+		eco, name := Opening(record.Moves)
+		if eco == "" {
+			eco, name = "—", "Unclassified"
+		}
+		key := [2]string{eco + " " + name, record.HumanColor}
+		if openings[key] == nil {
+			openings[key] = &OpeningStats{ECO: eco, Name: name, Color: record.HumanColor}
+		}
+		openings[key].Score.add(outcome)
+
+		if outcome < 0 {
+			patterns[lossPattern(record)]++
+		}
+	}
+
+	if summary.Games > 0 {
+		summary.AverageLength = float64(totalMoves) / float64(summary.Games)
+	}
+	for _, stats := range openings {
+		summary.Openings = append(summary.Openings, *stats)
+	}
+	sort.Slice(summary.Openings, func(i, j int) bool {
+		a, b := summary.Openings[i], summary.Openings[j]
+		if a.Score.Games() != b.Score.Games() {
+			return a.Score.Games() > b.Score.Games()
+		}
+		if a.ECO != b.ECO {
+			return a.ECO < b.ECO
+		}
+		return a.Color > b.Color // white first
+	})
+	for description, count := range patterns {
+		summary.LossPatterns = append(summary.LossPatterns, Pattern{Description: description, Count: count})
+	}
+	sort.Slice(summary.LossPatterns, func(i, j int) bool {
+		a, b := summary.LossPatterns[i], summary.LossPatterns[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Description < b.Description
+	})
+	return summary
+}
+
+// humanOutcome returns 1, 0 or -1 for a human win, draw or loss, and false
+// for games without a human side or result
+func humanOutcome(record Record) (int, bool) {
+	if record.HumanColor == "" {
+		return 0, false
+	}
+	switch record.Result {
+	case Draw:
+		return 0, true
+	case WhiteWon:
+		if record.HumanColor == "white" {
+			return 1, true
+		}
+		return -1, true
+	case BlackWon:
+		if record.HumanColor == "black" {
+			return 1, true
+		}
+		return -1, true
+	}
+	return 0, false
+}
+
+// add counts one game's outcome, as returned by humanOutcome
+func (s *Score) add(outcome int) {
+	switch {
+	case outcome > 0:
+		s.Wins++
+	case outcome < 0:
+		s.Losses++
+	default:
+		s.Draws++
+	}
+}
+
+// lossPattern describes how a lost game was lost, e.g. "Resigned in the endgame"
+func lossPattern(record Record) string {
+	if record.Termination == chess.Checkmate.String() && backRankMate(record) {
+		return "Back-rank mate"
+	}
+
+	var how string
+	switch record.Termination {
+	case chess.Checkmate.String():
+		how = "Checkmated"
+	case chess.Resignation.String():
+		how = "Resigned"
+	default:
+		how = "Lost"
+	}
+
+	switch fullMoves := (len(record.Moves) + 1) / 2; {
+	case fullMoves <= openingMoves:
+		return how + " in the opening"
+	case fullMoves <= middlegameMoves:
+		return how + " in the middlegame"
+	default:
+		return how + " in the endgame"
+	}
+}
+
+// backRankMate reports whether the mating move was a rook or queen landing
+// on the human's back rank
+func backRankMate(record Record) bool {
+	if len(record.Moves) == 0 {
+		return false
+	}
+	last := strings.TrimRight(record.Moves[len(record.Moves)-1], "#+")
+	if len(last) < 3 || (last[0] != 'R' && last[0] != 'Q') {
+		return false
+	}
+	backRank := byte('1')
+	if record.HumanColor == "black" {
+		backRank = '8'
+	}
+	return last[len(last)-1] == backRank
+}
@@ -0,0 +1,58 @@
+package gamedb
+
+import "testing"
+
+func TestOpening(t *testing.T) {
+	eco, name := Opening([]string{"e4", "e5", "Nf3", "Nc6", "Bc4", "Bc5", "c3"})
+	if eco == "" || eco[0] != 'C' {
+		t.Errorf("Expected an open game ECO code, got %q (%s)", eco, name)
+	}
+	if eco, _ := Opening(nil); eco != "" {
+		t.Errorf("Expected no opening for an empty game, got %q", eco)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	italian := []string{"e4", "e5", "Nf3", "Nc6", "Bc4"}
+	records := []Record{
+		{HumanColor: "white", Result: WhiteWon, Moves: italian},
+		{HumanColor: "white", Result: BlackWon, Termination: "Resignation", Moves: italian},
+		{HumanColor: "black", Result: Draw, Moves: []string{"d4", "d5"}},
+		// Fool's mate against the human playing White
+		{HumanColor: "white", Result: BlackWon, Termination: "Checkmate", Moves: []string{"f3", "e5", "g4", "Qh4#"}},
+		// Back-rank mate against the human playing Black
+		{HumanColor: "black", Result: WhiteWon, Termination: "Checkmate", Moves: []string{"e4", "e5", "Re8#"}},
+		// Hot-seat games have no human side
+		{Result: WhiteWon, Moves: italian},
+	}
+
+	summary := Summarize(records)
+	if summary.Games != 5 {
+		t.Fatalf("Expected 5 games, got %d", summary.Games)
+	}
+	if white := summary.ByColor["white"]; white.Wins != 1 || white.Losses != 2 {
+		t.Errorf("Expected 1W 2L with White, got %s", white)
+	}
+	if black := summary.ByColor["black"]; black.Draws != 1 || black.Losses != 1 {
+		t.Errorf("Expected 0W 1L 1D with Black, got %s", black)
+	}
+	// 3, 3, 1, 2 and 2 full moves
+	if summary.AverageLength < 2.19 || summary.AverageLength > 2.21 {
+		t.Errorf("Expected 2.2 moves on average, got %.2f", summary.AverageLength)
+	}
+
+	top := summary.Openings[0]
+	if top.Color != "white" || top.Score.Games() != 2 || top.ECO[0] != 'C' {
+		t.Errorf("Expected the Italian with White to be the most played opening, got %+v", top)
+	}
+
+	patterns := make(map[string]int)
+	for _, pattern := range summary.LossPatterns {
+		patterns[pattern.Description] = pattern.Count
+	}
+	for _, want := range []string{"Resigned in the opening", "Checkmated in the opening", "Back-rank mate"} {
+		if patterns[want] != 1 {
+			t.Errorf("Expected one %q loss, got %v", want, patterns)
+		}
+	}
+}
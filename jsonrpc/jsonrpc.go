@@ -0,0 +1,194 @@
+// Package jsonrpc implements the JSON-RPC 2.0 protocol over HTTP: parsing
+// single and batch requests, telling notifications from calls, and writing
+// responses with the standard error objects. Handlers only see one request
+// at a time and return its result or error.
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Version is the protocol version every message carries
+const Version = "2.0"
+
+// Standard error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Error is a JSON-RPC error object. Handlers return one to choose the code;
+// any other error is reported as an internal error.
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// NewError creates an error object. data is usually a string, or a value
+// for structured errors.
+func NewError(code int, message string, data interface{}) *Error {
+	return &Error{Code: code, Message: message, Data: data}
+}
+
+// Error describes the error
+func (e *Error) Error() string {
+	if e.Data != nil {
+		return fmt.Sprintf("%s (%d): %v", e.Message, e.Code, e.Data)
+	}
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// asError turns any error into an error object
+func asError(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	return NewError(CodeInternalError, "Internal error", err.Error())
+}
+
+// Request is one JSON-RPC request. ID is the raw id, kept as sent so it is
+// echoed back exactly; it is nil for notifications and "null" when the
+// client sent a null id.
+type Request struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// IsNotification reports whether the request has no id, so the client
+// expects no response
+func (r *Request) IsNotification() bool {
+	return r.ID == nil
+}
+
+// DecodeParams decodes the request's params into v, returning an invalid
+// params error when they don't fit
+func (r *Request) DecodeParams(v interface{}) error {
+	params := r.Params
+	if len(params) == 0 {
+		params = []byte("{}")
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return NewError(CodeInvalidParams, "Invalid params", fmt.Sprintf("Failed to parse params: %v", err))
+	}
+	return nil
+}
+
+// Response is one JSON-RPC response: a result or an error, for the id of
+// the request it answers
+type Response struct {
+	Result interface{}
+	Error  *Error
+	ID     json.RawMessage
+}
+
+// NewResponse creates a success response
+func NewResponse(id json.RawMessage, result interface{}) Response {
+	return Response{ID: id, Result: result}
+}
+
+// NewErrorResponse creates an error response. Errors other than *Error are
+// reported as internal errors.
+func NewErrorResponse(id json.RawMessage, err error) Response {
+	return Response{ID: id, Error: asError(err)}
+}
+
+// MarshalJSON writes exactly one of result and error, as the protocol
+// requires, with a null id when the request's couldn't be read
+func (r Response) MarshalJSON() ([]byte, error) {
+	id := r.ID
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	if r.Error != nil {
+		return json.Marshal(struct {
+			Jsonrpc string          `json:"jsonrpc"`
+			Error   *Error          `json:"error"`
+			ID      json.RawMessage `json:"id"`
+		}{Version, r.Error, id})
+	}
+	return json.Marshal(struct {
+		Jsonrpc string          `json:"jsonrpc"`
+		Result  interface{}     `json:"result"`
+		ID      json.RawMessage `json:"id"`
+	}{Version, r.Result, id})
+}
+
+// Parse reads a request body, either a single request or a batch. Each
+// entry of a batch is parsed on its own: requests holds nil where an entry
+// is invalid, and errs the error to answer it with. A body that isn't JSON,
+// or an empty batch, is a single error with no requests.
+func Parse(body []byte) (requests []*Request, errs []*Error, batch bool) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var entries []json.RawMessage
+		if err := json.Unmarshal(body, &entries); err != nil {
+			return nil, []*Error{NewError(CodeParseError, "Parse error", err.Error())}, false
+		}
+		if len(entries) == 0 {
+			return nil, []*Error{NewError(CodeInvalidRequest, "Invalid Request", "empty batch")}, false
+		}
+		requests, errs = make([]*Request, len(entries)), make([]*Error, len(entries))
+		for i, entry := range entries {
+			requests[i], errs[i] = parseRequest(entry)
+		}
+		return requests, errs, true
+	}
+
+	if !json.Valid(body) {
+		return nil, []*Error{NewError(CodeParseError, "Parse error", "invalid JSON")}, false
+	}
+	request, err := parseRequest(body)
+	return []*Request{request}, []*Error{err}, false
+}
+
+// parseRequest reads and checks one request object
+func parseRequest(data json.RawMessage) (*Request, *Error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, NewError(CodeInvalidRequest, "Invalid Request", "request must be an object")
+	}
+	var request Request
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, NewError(CodeInvalidRequest, "Invalid Request", err.Error())
+	}
+	// A present but null id is kept as "null", unlike a missing one
+	if id, ok := fields["id"]; ok {
+		request.ID = id
+		if !validID(id) {
+			return nil, NewError(CodeInvalidRequest, "Invalid Request", "id must be a string, number or null")
+		}
+	}
+	switch {
+	case request.Jsonrpc != Version:
+		return &request, NewError(CodeInvalidRequest, "Invalid Request", `jsonrpc must be "2.0"`)
+	case request.Method == "":
+		return &request, NewError(CodeInvalidRequest, "Invalid Request", "method is required")
+	}
+	if params := bytes.TrimSpace(request.Params); len(params) > 0 && params[0] != '{' && params[0] != '[' {
+		return &request, NewError(CodeInvalidRequest, "Invalid Request", "params must be an object or array")
+	}
+	return &request, nil
+}
+
+// validID reports whether a raw id is a string, number or null
+func validID(id json.RawMessage) bool {
+	var v interface{}
+	if err := json.Unmarshal(id, &v); err != nil {
+		return false
+	}
+	switch v.(type) {
+	case string, float64, nil:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxBodySize bounds a request body, batches included
+const maxBodySize = 4 * 1024 * 1024
+
+// HandlerFunc answers one request. It returns the result, or an error to
+// send instead; a *Error chooses the code. Notifications' results are dropped.
+type HandlerFunc func(call *Call) (interface{}, error)
+
+// Call is a request being handled, with the HTTP request it came in
+type Call struct {
+	*Request
+	HTTP *http.Request
+
+	w        http.ResponseWriter // set when the call may stream its reply
+	streamed bool
+}
+
+// Context returns the HTTP request's context, which ends when the client goes away
+func (c *Call) Context() context.Context {
+	return c.HTTP.Context()
+}
+
+// Stream hands the HTTP response over to a handler that writes its reply
+// itself, e.g. as server-sent events; what the handler returns is then
+// ignored. It returns nil inside a batch or for a notification, where the
+// handler must return its result instead.
+func (c *Call) Stream() http.ResponseWriter {
+	if c.w == nil {
+		return nil
+	}
+	c.streamed = true
+	return c.w
+}
+
+// Server dispatches JSON-RPC requests posted over HTTP to the handlers of
+// their methods
+type Server struct {
+	methods map[string]HandlerFunc
+}
+
+// NewServer creates a server with no methods
+func NewServer() *Server {
+	return &Server{methods: make(map[string]HandlerFunc)}
+}
+
+// Handle registers the handler of a method
+func (s *Server) Handle(method string, handler HandlerFunc) {
+	s.methods[method] = handler
+}
+
+// ServeHTTP answers a single request with one response and a batch with an
+// array of them, in order. Notifications get no response; a request, or a
+// batch, of notifications only gets an empty 204 reply.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, NewErrorResponse(nil, NewError(CodeInvalidRequest, "Method Not Allowed", "Only POST method is supported")))
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		writeJSON(w, NewErrorResponse(nil, NewError(CodeParseError, "Parse error", err.Error())))
+		return
+	}
+
+	requests, errs, batch := Parse(body)
+	if len(requests) == 0 {
+		writeJSON(w, NewErrorResponse(nil, errs[0]))
+		return
+	}
+
+	if !batch {
+		call := &Call{Request: requests[0], HTTP: r, w: w}
+		response, ok := s.answer(call, errs[0])
+		switch {
+		case call.streamed:
+		case !ok:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeJSON(w, response)
+		}
+		return
+	}
+
+	var responses []Response
+	for i, request := range requests {
+		if response, ok := s.answer(&Call{Request: request, HTTP: r}, errs[i]); ok {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, responses)
+}
+
+// answer runs a call's handler and returns its response, or false when the
+// call is a notification and gets none. invalid is set when the request
+// couldn't be read.
+func (s *Server) answer(call *Call, invalid *Error) (Response, bool) {
+	if invalid != nil {
+		var id json.RawMessage
+		if call.Request != nil {
+			id = call.ID
+		}
+		return NewErrorResponse(id, invalid), true
+	}
+	if call.IsNotification() {
+		call.w = nil
+	}
+
+	handler, ok := s.methods[call.Method]
+	if !ok {
+		err := NewError(CodeMethodNotFound, "Method not found", fmt.Sprintf("Method '%s' not found", call.Method))
+		return NewErrorResponse(call.ID, err), !call.IsNotification()
+	}
+	result, err := handler(call)
+	if call.IsNotification() {
+		return Response{}, false
+	}
+	if err != nil {
+		return NewErrorResponse(call.ID, err), true
+	}
+	return NewResponse(call.ID, result), true
+}
+
+// writeJSON writes a response, or a batch of them
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
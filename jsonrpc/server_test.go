@@ -0,0 +1,130 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testServer(t *testing.T) (*httptest.Server, *int) {
+	notified := 0
+	s := NewServer()
+	s.Handle("echo", func(call *Call) (interface{}, error) {
+		var params map[string]interface{}
+		if err := call.DecodeParams(&params); err != nil {
+			return nil, err
+		}
+		return params, nil
+	})
+	s.Handle("fail", func(call *Call) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	s.Handle("notify", func(call *Call) (interface{}, error) {
+		notified++
+		return "ignored", nil
+	})
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return server, &notified
+}
+
+func post(t *testing.T, url, body string) (int, string) {
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, strings.TrimSpace(string(data))
+}
+
+func TestSingleRequests(t *testing.T) {
+	server, _ := testServer(t)
+	tests := []struct {
+		name, body, want string
+	}{
+		{"result", `{"jsonrpc":"2.0","method":"echo","params":{"a":1},"id":"x"}`, `{"jsonrpc":"2.0","result":{"a":1},"id":"x"}`},
+		{"null id", `{"jsonrpc":"2.0","method":"echo","id":null}`, `{"jsonrpc":"2.0","result":{},"id":null}`},
+		{"unknown method", `{"jsonrpc":"2.0","method":"nope","id":1}`, `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found","data":"Method 'nope' not found"},"id":1}`},
+		{"internal error", `{"jsonrpc":"2.0","method":"fail","id":2}`, `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error","data":"boom"},"id":2}`},
+		{"bad params", `{"jsonrpc":"2.0","method":"echo","params":[1],"id":3}`, `"code":-32602`},
+		{"wrong version", `{"jsonrpc":"1.0","method":"echo","id":4}`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request","data":"jsonrpc must be \"2.0\""},"id":4}`},
+		{"parse error", `{"jsonrpc":`, `"code":-32700`},
+		{"bad id", `{"jsonrpc":"2.0","method":"echo","id":{}}`, `"id":null`},
+	}
+	for _, tt := range tests {
+		_, got := post(t, server.URL, tt.body)
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestNotificationsGetNoResponse(t *testing.T) {
+	server, notified := testServer(t)
+	status, body := post(t, server.URL, `{"jsonrpc":"2.0","method":"notify"}`)
+	if status != http.StatusNoContent || body != "" {
+		t.Errorf("Expected an empty 204 reply, got %d %q", status, body)
+	}
+	if *notified != 1 {
+		t.Errorf("Expected the notification to run, got %d calls", *notified)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	server, notified := testServer(t)
+	_, body := post(t, server.URL, `[
+		{"jsonrpc":"2.0","method":"echo","params":{"n":1},"id":1},
+		{"jsonrpc":"2.0","method":"notify"},
+		1,
+		{"jsonrpc":"2.0","method":"nope","id":"b"}
+	]`)
+	var responses []map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &responses); err != nil {
+		t.Fatalf("Expected an array of responses, got %s", body)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("Expected 3 responses without the notification's, got %s", body)
+	}
+	if responses[0]["id"] != float64(1) || responses[0]["result"] == nil {
+		t.Errorf("Expected the echo result first, got %v", responses[0])
+	}
+	if e, _ := responses[1]["error"].(map[string]interface{}); e["code"] != float64(CodeInvalidRequest) || responses[1]["id"] != nil {
+		t.Errorf("Expected an invalid request with a null id, got %v", responses[1])
+	}
+	if e, _ := responses[2]["error"].(map[string]interface{}); e["code"] != float64(CodeMethodNotFound) || responses[2]["id"] != "b" {
+		t.Errorf("Expected method not found for id b, got %v", responses[2])
+	}
+	if *notified != 1 {
+		t.Errorf("Expected the notification to run, got %d calls", *notified)
+	}
+
+	_, body = post(t, server.URL, `[]`)
+	if !strings.Contains(body, `"code":-32600`) || strings.HasPrefix(body, "[") {
+		t.Errorf("Expected a single invalid request for an empty batch, got %s", body)
+	}
+}
+
+func TestStreamOnlyOutsideBatches(t *testing.T) {
+	s := NewServer()
+	s.Handle("stream", func(call *Call) (interface{}, error) {
+		if w := call.Stream(); w != nil {
+			w.Write([]byte("data: streamed\n\n"))
+			return nil, nil
+		}
+		return "plain", nil
+	})
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	if _, body := post(t, server.URL, `{"jsonrpc":"2.0","method":"stream","id":1}`); body != "data: streamed" {
+		t.Errorf("Expected the handler's own reply, got %s", body)
+	}
+	if _, body := post(t, server.URL, `[{"jsonrpc":"2.0","method":"stream","id":1}]`); body != `[{"jsonrpc":"2.0","result":"plain","id":1}]` {
+		t.Errorf("Expected a plain result inside a batch, got %s", body)
+	}
+}
@@ -0,0 +1,199 @@
+// Package leaderboard ranks the players of a hosted instance, such as the
+// SSH server or a lobby, by rating and results, for all time or the past week
+package leaderboard
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"chess-tui/gamedb"
+	"chess-tui/tournament"
+)
+
+// Periods a leaderboard covers
+const (
+	PeriodWeek = "week"
+	PeriodAll  = "all"
+)
+
+// Player is someone on the leaderboard and their games, each recorded from
+// their side: HumanColor is the color they played and Opponent who against
+type Player struct {
+	Name  string
+	Games []gamedb.Record
+}
+
+// Entry is one player's line on the leaderboard
+type Entry struct {
+	Rank   int    `json:"rank"`
+	Name   string `json:"name"`
+	Elo    int    `json:"elo,omitempty"` // left out for players without a result yet
+	Games  int    `json:"games"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	Draws  int    `json:"draws"`
+}
+
+// Points returns the player's score, a point a win and half a draw
+func (e Entry) Points() float64 {
+	return float64(e.Wins) + float64(e.Draws)/2
+}
+
+// Board is the leaderboard for a period
+type Board struct {
+	Period  string    `json:"period"`
+	Since   time.Time `json:"since,omitzero"` // zero for all time
+	Entries []Entry   `json:"entries"`
+}
+
+// Since returns when period began, as of now: a week ago, or the zero time
+// for all time
+func Since(period string, now time.Time) (time.Time, error) {
+	switch period {
+	case PeriodWeek:
+		return now.AddDate(0, 0, -7), nil
+	case PeriodAll, "":
+		return time.Time{}, nil
+	}
+	return time.Time{}, fmt.Errorf("unknown period %q: use %q or %q", period, PeriodWeek, PeriodAll)
+}
+
+// Rank builds the leaderboard for period from the players' games. AI
+// opponents count at their bench rating in ratings, and other players at
+// their own rating over the same games, so beating a strong player is worth
+// more than beating a weak one.
+func Rank(players []Player, period string, now time.Time, ratings tournament.Ratings) (Board, error) {
+	since, err := Since(period, now)
+	if err != nil {
+		return Board{}, err
+	}
+	if period == "" {
+		period = PeriodAll
+	}
+
+	recent := make([]Player, 0, len(players))
+	for _, player := range players {
+		games := slices.DeleteFunc(slices.Clone(player.Games), func(record gamedb.Record) bool {
+			return record.HumanColor == "" || record.Played.Before(since)
+		})
+		if len(games) > 0 {
+			recent = append(recent, Player{Name: player.Name, Games: games})
+		}
+	}
+
+	// Rate everyone against AI opponents and a default for people, then
+	// again with the people at those first ratings
+	opponents := maps.Clone(ratings)
+	if opponents == nil {
+		opponents = make(tournament.Ratings)
+	}
+	first := make(map[string]tournament.Rating)
+	for _, player := range recent {
+		if rating, err := tournament.HumanRating(player.Games, ratings); err == nil {
+			first[player.Name] = rating
+		}
+	}
+	for name, rating := range first {
+		if _, ai := ratings[name]; !ai {
+			opponents[name] = rating
+		}
+	}
+
+	board := Board{Period: period, Since: since, Entries: []Entry{}}
+	for _, player := range recent {
+		entry := Entry{Name: player.Name}
+		if rating, err := tournament.HumanRating(player.Games, opponents); err == nil {
+			entry.Elo = rating.Elo
+		}
+		for _, record := range player.Games {
+			switch outcome(record) {
+			case 1:
+				entry.Wins++
+			case 0:
+				entry.Draws++
+			case -1:
+				entry.Losses++
+			default:
+				continue
+			}
+			entry.Games++
+		}
+		if entry.Games > 0 {
+			board.Entries = append(board.Entries, entry)
+		}
+	}
+
+	slices.SortFunc(board.Entries, func(a, b Entry) int {
+		return cmp.Or(
+			cmp.Compare(b.Elo, a.Elo),
+			cmp.Compare(b.Points(), a.Points()),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	for i := range board.Entries {
+		board.Entries[i].Rank = i + 1
+	}
+	return board, nil
+}
+
+// Players splits games between two people, named as White and Black, into
+// each one's games as seen from their side
+func Players(records []gamedb.Record) []Player {
+	games := make(map[string][]gamedb.Record)
+	for _, record := range records {
+		white, black := record, record
+		white.HumanColor, white.Opponent = "white", record.Black
+		black.HumanColor, black.Opponent = "black", record.White
+		games[record.White] = append(games[record.White], white)
+		games[record.Black] = append(games[record.Black], black)
+	}
+
+	players := make([]Player, 0, len(games))
+	for _, name := range slices.Sorted(maps.Keys(games)) {
+		players = append(players, Player{Name: name, Games: games[name]})
+	}
+	return players
+}
+
+// outcome returns 1, 0 or -1 for a win, draw or loss from the player's
+// side, and 2 for a game without a result
+func outcome(record gamedb.Record) int {
+	switch record.Result {
+	case gamedb.Draw:
+		return 0
+	case gamedb.WhiteWon, gamedb.BlackWon:
+		if (record.Result == gamedb.WhiteWon) == (record.HumanColor == "white") {
+			return 1
+		}
+		return -1
+	}
+	return 2
+}
+
+// Handler serves the leaderboard as JSON, for embedding elsewhere:
+//
+//	GET /leaderboard?period=week|all
+func Handler(load func(period string) (Board, error)) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /leaderboard", func(w http.ResponseWriter, r *http.Request) {
+		period := r.URL.Query().Get("period")
+		if _, err := Since(period, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		board, err := load(period)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		json.NewEncoder(w).Encode(board)
+	})
+	return mux
+}
@@ -0,0 +1,108 @@
+package leaderboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chess-tui/gamedb"
+	"chess-tui/tournament"
+)
+
+var now = time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+func TestRank(t *testing.T) {
+	old := now.AddDate(0, 0, -30)
+	players := []Player{
+		{Name: "ann", Games: []gamedb.Record{
+			{Played: now, HumanColor: "white", Opponent: "Strong", Result: gamedb.WhiteWon},
+			{Played: old, HumanColor: "black", Opponent: "Strong", Result: gamedb.WhiteWon},
+		}},
+		{Name: "bob", Games: []gamedb.Record{
+			{Played: now, HumanColor: "black", Opponent: "Weak", Result: gamedb.BlackWon},
+			{Played: now, White: "Human", Black: "Human", Result: gamedb.WhiteWon}, // at one terminal, not rated
+		}},
+		{Name: "cat"}, // no games
+	}
+	ratings := tournament.Ratings{"Strong": {Elo: 2000}, "Weak": {Elo: 1000}}
+
+	all, err := Rank(players, PeriodAll, now, ratings)
+	if err != nil {
+		t.Fatalf("Expected a leaderboard, got %v", err)
+	}
+	if len(all.Entries) != 2 {
+		t.Fatalf("Expected the 2 players with games, got %+v", all.Entries)
+	}
+	// Splitting two games with a 2000 outranks beating a 1000
+	if first := all.Entries[0]; first.Name != "ann" || first.Rank != 1 || first.Wins != 1 || first.Losses != 1 {
+		t.Errorf("Expected ann first with 1W 1L, got %+v", first)
+	}
+	if bob := all.Entries[1]; bob.Games != 1 || bob.Wins != 1 {
+		t.Errorf("Expected bob's one rated game, got %+v", bob)
+	}
+
+	week, err := Rank(players, PeriodWeek, now, ratings)
+	if err != nil {
+		t.Fatalf("Expected a leaderboard, got %v", err)
+	}
+	if !week.Since.Equal(now.AddDate(0, 0, -7)) {
+		t.Errorf("Expected the week to start 7 days ago, got %v", week.Since)
+	}
+	for _, entry := range week.Entries {
+		if entry.Name == "ann" && (entry.Games != 1 || entry.Wins != 1) {
+			t.Errorf("Expected only ann's win this week, got %+v", entry)
+		}
+	}
+
+	if _, err := Rank(players, "month", now, ratings); err == nil {
+		t.Error("Expected an error for an unknown period")
+	}
+}
+
+func TestPlayers(t *testing.T) {
+	players := Players([]gamedb.Record{
+		{White: "ann", Black: "bob", Result: gamedb.WhiteWon},
+		{White: "bob", Black: "cat", Result: gamedb.Draw},
+	})
+	if len(players) != 3 || players[1].Name != "bob" || len(players[1].Games) != 2 {
+		t.Fatalf("Expected ann, bob with 2 games and cat, got %+v", players)
+	}
+	board, _ := Rank(players, PeriodAll, now, nil)
+	if board.Entries[0].Name != "ann" || board.Entries[2].Name != "cat" {
+		t.Errorf("Expected ann first and cat last, got %+v", board.Entries)
+	}
+	if bob := board.Entries[1]; bob.Losses != 1 || bob.Draws != 1 {
+		t.Errorf("Expected bob's loss and draw, got %+v", bob)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(Handler(func(period string) (Board, error) {
+		return Board{Period: period, Entries: []Entry{{Rank: 1, Name: "ann", Elo: 1600, Games: 3, Wins: 2, Draws: 1}}}, nil
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/leaderboard?period=week")
+	if err != nil {
+		t.Fatalf("Expected a response, got %v", err)
+	}
+	defer resp.Body.Close()
+	var board Board
+	if err := json.NewDecoder(resp.Body).Decode(&board); err != nil {
+		t.Fatalf("Expected JSON, got %v", err)
+	}
+	if board.Period != PeriodWeek || board.Entries[0].Name != "ann" || board.Entries[0].Elo != 1600 {
+		t.Errorf("Expected ann on the weekly board, got %+v", board)
+	}
+
+	resp, err = http.Get(server.URL + "/leaderboard?period=decade")
+	if err != nil {
+		t.Fatalf("Expected a response, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown period, got %d", resp.StatusCode)
+	}
+}
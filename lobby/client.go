@@ -0,0 +1,158 @@
+package lobby
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"chess-tui/leaderboard"
+	"chess-tui/netconfig"
+)
+
+// ErrGone is returned for a game that is no longer open
+var ErrGone = errors.New("game is no longer open")
+
+// HeartbeatInterval is how often a host keeps its game listed
+const HeartbeatInterval = 10 * time.Second
+
+// Client talks to a lobby server
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+// NewClient creates a client for the lobby at url, e.g. "http://localhost:7070"
+func NewClient(url string) *Client {
+	return &Client{
+		url:  strings.TrimRight(url, "/"),
+		http: netconfig.NewClient(10 * time.Second),
+	}
+}
+
+// List returns the open games
+func (c *Client) List(ctx context.Context) ([]Game, error) {
+	var games []Game
+	if err := c.do(ctx, http.MethodGet, "/games", nil, &games); err != nil {
+		return nil, fmt.Errorf("failed to list games: %w", err)
+	}
+	return games, nil
+}
+
+// Create lists a game hosted by host on port of this machine
+func (c *Client) Create(ctx context.Context, host string, port int) (Game, error) {
+	var game Game
+	if err := c.do(ctx, http.MethodPost, "/games", CreateRequest{Host: host, Port: port}, &game); err != nil {
+		return Game{}, fmt.Errorf("failed to open game: %w", err)
+	}
+	return game, nil
+}
+
+// CreatePrivate registers an unlisted game hosted by host on port of this
+// machine, for whoever has its invite code, the returned game's ID, to join
+// with password
+func (c *Client) CreatePrivate(ctx context.Context, host string, port int, password string) (Game, error) {
+	var game Game
+	req := CreateRequest{Host: host, Port: port, Private: true, Password: password}
+	if err := c.do(ctx, http.MethodPost, "/games", req, &game); err != nil {
+		return Game{}, fmt.Errorf("failed to open game: %w", err)
+	}
+	return game, nil
+}
+
+// Join claims an open game for the player named and returns where to connect
+func (c *Client) Join(ctx context.Context, id, name string) (Game, error) {
+	var game Game
+	if err := c.do(ctx, http.MethodPost, "/games/"+id+"/join", JoinRequest{Name: name}, &game); err != nil {
+		return Game{}, fmt.Errorf("failed to join game: %w", err)
+	}
+	return game, nil
+}
+
+// Heartbeat keeps a game listed. It returns ErrGone once the game has been
+// joined or has expired.
+func (c *Client) Heartbeat(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/games/"+id+"/heartbeat", nil, nil)
+}
+
+// Report tells the lobby how a game hosted through it ended, for the
+// leaderboard and the fair play review
+func (c *Client) Report(ctx context.Context, id string, result ResultRequest) error {
+	if err := c.do(ctx, http.MethodPost, "/games/"+id+"/result", result, nil); err != nil {
+		return fmt.Errorf("failed to report result: %w", err)
+	}
+	return nil
+}
+
+// Leaderboard returns the lobby's leaderboard for period
+func (c *Client) Leaderboard(ctx context.Context, period string) (leaderboard.Board, error) {
+	var board leaderboard.Board
+	if err := c.do(ctx, http.MethodGet, "/leaderboard?period="+url.QueryEscape(period), nil, &board); err != nil {
+		return leaderboard.Board{}, fmt.Errorf("failed to load leaderboard: %w", err)
+	}
+	return board, nil
+}
+
+// Remove unlists a game
+func (c *Client) Remove(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/games/"+id, nil, nil)
+}
+
+// KeepOpen sends heartbeats for a game until ctx ends or the game is joined
+func (c *Client) KeepOpen(ctx context.Context, id string) {
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if errors.Is(c.Heartbeat(ctx, id), ErrGone) {
+				return
+			}
+		}
+	}
+}
+
+// do sends a request with an optional JSON body and decodes any JSON reply into out
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && strings.HasPrefix(path, "/games/"):
+		return ErrGone
+	case resp.StatusCode >= 300:
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("lobby returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
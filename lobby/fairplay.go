@@ -0,0 +1,88 @@
+package lobby
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"chess-tui/fairplay"
+	"chess-tui/gamedb"
+	"chess-tui/tournament"
+)
+
+// maxReports is how many fair play reviews the lobby keeps
+const maxReports = 1000
+
+// SetFairPlay reviews every finished game that reports its moves against
+// engine, for the admin endpoint
+func (s *Server) SetFairPlay(engine tournament.Player) {
+	s.engine = engine
+}
+
+// SetAdminToken enables the admin endpoints for requests carrying token as
+// a bearer token
+func (s *Server) SetAdminToken(token string) {
+	s.adminToken = token
+}
+
+// Reports returns the fair play reviews, newest first
+func (s *Server) Reports() []fairplay.Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reports := make([]fairplay.Report, 0, len(s.reports))
+	for i := len(s.reports) - 1; i >= 0; i-- {
+		reports = append(reports, s.reports[i])
+	}
+	return reports
+}
+
+// review checks a finished game for fair play and keeps the report
+func (s *Server) review(id string, record gamedb.Record) {
+	report, err := fairplay.Analyze(id, record, s.engine)
+	if err != nil {
+		slog.Warn("Failed to review game", "id", id, "error", err)
+		return
+	}
+	if report.Flagged() {
+		slog.Warn("Game flagged for review", "id", id,
+			"white", report.White.Player, "white_flags", report.White.Flags,
+			"black", report.Black.Player, "black_flags", report.Black.Flags)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports = append(s.reports, report)
+	if len(s.reports) > maxReports {
+		s.reports = s.reports[len(s.reports)-maxReports:]
+	}
+}
+
+// admin wraps an admin endpoint so it needs the admin token, and doesn't
+// exist without one
+func (s *Server) admin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.adminToken == "" {
+			http.Error(w, "admin endpoints disabled; start the lobby with an admin token", http.StatusNotFound)
+			return
+		}
+		given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(s.adminToken)) != 1 {
+			slog.Warn("Rejected unauthorized admin request", "remote", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="bubblechess admin"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleFairPlay lists the fair play reviews, newest first
+func (s *Server) handleFairPlay(w http.ResponseWriter, r *http.Request) {
+	reports := s.Reports()
+	if r.URL.Query().Get("flagged") == "true" {
+		reports = slices.DeleteFunc(reports, func(report fairplay.Report) bool { return !report.Flagged() })
+	}
+	writeJSON(w, http.StatusOK, reports)
+}
@@ -0,0 +1,348 @@
+// Package lobby is a matchmaking service for networked games. Hosts
+// register their open games, and players list them and claim one to join,
+// so nobody has to exchange host:port by hand.
+//
+// Private games aren't listed. Their ID is a longer invite code that the
+// host passes on to a friend, who joins with it and gets the game's
+// password along with where to connect.
+package lobby
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"chess-tui/fairplay"
+	"chess-tui/gamedb"
+	"chess-tui/leaderboard"
+	"chess-tui/tournament"
+)
+
+// DefaultTTL is how long an open game stays listed without a heartbeat
+const DefaultTTL = 30 * time.Second
+
+// Game is an open networked game waiting for an opponent
+type Game struct {
+	ID      string    `json:"id"`
+	Host    string    `json:"host"` // the hosting player's name
+	Addr    string    `json:"addr"` // where to connect, host:port
+	Created time.Time `json:"created"`
+
+	Private  bool   `json:"private,omitempty"`  // unlisted; its ID is the invite code
+	Password string `json:"password,omitempty"` // a private game's password, given to the host and the joiner only
+
+	seen time.Time // last heartbeat
+}
+
+// CreateRequest registers an open game. Addr may be left out, in which case
+// the lobby uses the address the request came from and Port. A private
+// game's host sets the password joiners must give it.
+type CreateRequest struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Addr     string `json:"addr,omitempty"`
+	Private  bool   `json:"private,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// DefaultResultsPath returns where a lobby server keeps results by default
+func DefaultResultsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "lobby-results.jsonl"
+	}
+	return filepath.Join(home, ".bubblechess", "lobby-results.jsonl")
+}
+
+// JoinRequest claims an open game for the player named
+type JoinRequest struct {
+	Name string `json:"name"`
+}
+
+// ResultRequest reports how a joined game ended, as in PGN, with its moves
+// and how long each took for the fair play review. An aborted game's result
+// is "*".
+type ResultRequest struct {
+	Result      string   `json:"result"`
+	Termination string   `json:"termination,omitempty"`   // as in the game database, e.g. "Abandoned"
+	Moves       []string `json:"moves,omitempty"`         // in SAN
+	MoveTimes   []int64  `json:"move_times_ms,omitempty"` // as the host timed them
+}
+
+// matchTTL is how long a joined game waits for its result
+const matchTTL = 24 * time.Hour
+
+// match is a joined game, waiting for its result
+type match struct {
+	host, joiner string
+	joined       time.Time
+}
+
+// Server keeps the list of open games, and the results of the games played
+// through it for the leaderboard
+type Server struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	games   map[string]*Game
+	matches map[string]*match
+	results *gamedb.DB      // where results are kept, if set
+	played  []gamedb.Record // results, when they aren't kept
+
+	engine     tournament.Player // reviews finished games for fair play, if set
+	adminToken string            // guards the admin endpoints; "" disables them
+	reports    []fairplay.Report // the fair play reviews, newest last
+}
+
+// NewServer creates a lobby whose games are dropped after ttl without a heartbeat
+func NewServer(ttl time.Duration) *Server {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Server{ttl: ttl, now: time.Now, games: make(map[string]*Game), matches: make(map[string]*match)}
+}
+
+// SetResults keeps the results of games in db, so the leaderboard survives
+// restarts. Without it they're kept in memory.
+func (s *Server) SetResults(db *gamedb.DB) {
+	s.results = db
+}
+
+// Handler returns the lobby's HTTP API:
+//
+//	GET    /games                list open games
+//	POST   /games                register an open game
+//	POST   /games/{id}/heartbeat keep a game listed
+//	POST   /games/{id}/join      claim a game, unlisting it
+//	DELETE /games/{id}           unlist a game
+//	POST   /games/{id}/result    report how a joined game ended
+//	GET    /leaderboard          rank the players, ?period=week or all
+//	GET    /admin/fairplay       fair play reviews, ?flagged=true for the flagged only
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/fairplay", s.admin(s.handleFairPlay))
+	mux.Handle("GET /leaderboard", leaderboard.Handler(s.Leaderboard))
+	mux.HandleFunc("POST /games/{id}/result", s.handleResult)
+	mux.HandleFunc("GET /games", s.handleList)
+	mux.HandleFunc("POST /games", s.handleCreate)
+	mux.HandleFunc("POST /games/{id}/heartbeat", s.handleHeartbeat)
+	mux.HandleFunc("POST /games/{id}/join", s.handleJoin)
+	mux.HandleFunc("DELETE /games/{id}", s.handleDelete)
+	return mux
+}
+
+// List returns the open public games, oldest first
+func (s *Server) List() []Game {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+
+	games := make([]Game, 0, len(s.games))
+	for _, game := range s.games {
+		if !game.Private {
+			games = append(games, *game)
+		}
+	}
+	slices.SortFunc(games, func(a, b Game) int { return a.Created.Compare(b.Created) })
+	return games
+}
+
+// expire drops games whose host stopped sending heartbeats. The caller holds s.mu.
+func (s *Server) expire() {
+	for id, game := range s.games {
+		if s.now().Sub(game.seen) > s.ttl {
+			delete(s.games, id)
+		}
+	}
+}
+
+// handleList lists the open games
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.List())
+}
+
+// handleCreate registers an open game
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	addr := req.Addr
+	if addr == "" {
+		if req.Port <= 0 || req.Port > 65535 {
+			http.Error(w, "port or addr required", http.StatusBadRequest)
+			return
+		}
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			http.Error(w, "can't tell your address; send addr", http.StatusBadRequest)
+			return
+		}
+		addr = net.JoinHostPort(ip, strconv.Itoa(req.Port))
+	}
+	host := strings.TrimSpace(req.Host)
+	if host == "" {
+		host = "anonymous"
+	}
+
+	now := s.now()
+	game := &Game{ID: newID(), Host: host, Addr: addr, Created: now, seen: now}
+	if req.Private {
+		game.ID = newInviteCode()
+		game.Private = true
+		game.Password = req.Password
+	}
+	s.mu.Lock()
+	s.games[game.ID] = game
+	s.mu.Unlock()
+
+	slog.Info("Game opened", "id", game.ID, "host", game.Host, "addr", game.Addr, "private", game.Private)
+	writeJSON(w, http.StatusCreated, game)
+}
+
+// handleHeartbeat keeps a game listed
+func (s *Server) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	game, ok := s.games[r.PathValue("id")]
+	if !ok {
+		http.Error(w, "no such open game", http.StatusNotFound)
+		return
+	}
+	game.seen = s.now()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleJoin claims a game for the caller and unlists it. A private game
+// is claimed with its invite code as the ID.
+func (s *Server) handleJoin(w http.ResponseWriter, r *http.Request) {
+	// The name is optional, for clients from before the leaderboard
+	var req JoinRequest
+	json.NewDecoder(r.Body).Decode(&req)
+	joiner := strings.TrimSpace(req.Name)
+	if joiner == "" {
+		joiner = "anonymous"
+	}
+
+	s.mu.Lock()
+	s.expire()
+	game, ok := s.games[r.PathValue("id")]
+	if ok {
+		delete(s.games, game.ID)
+		for id, m := range s.matches {
+			if s.now().Sub(m.joined) > matchTTL {
+				delete(s.matches, id)
+			}
+		}
+		s.matches[game.ID] = &match{host: game.Host, joiner: joiner, joined: s.now()}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		http.Error(w, "game is no longer open", http.StatusNotFound)
+		return
+	}
+	slog.Info("Game joined", "id", game.ID, "host", game.Host, "joiner", joiner, "addr", r.RemoteAddr)
+	writeJSON(w, http.StatusOK, game)
+}
+
+// handleResult records how a joined game ended. The host plays White. An
+// aborted game closes the match without counting for the leaderboard.
+func (s *Server) handleResult(w http.ResponseWriter, r *http.Request) {
+	var req ResultRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch req.Result {
+	case gamedb.WhiteWon, gamedb.BlackWon, gamedb.Draw, gamedb.NoResult:
+	default:
+		http.Error(w, `result must be "1-0", "0-1", "1/2-1/2" or "*"`, http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.matches[r.PathValue("id")]
+	if !ok {
+		http.Error(w, "no such game waiting for a result", http.StatusNotFound)
+		return
+	}
+	delete(s.matches, r.PathValue("id"))
+	if req.Result == gamedb.NoResult {
+		slog.Info("Game aborted", "id", r.PathValue("id"), "white", m.host, "black", m.joiner, "termination", req.Termination)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	record := gamedb.Record{Played: s.now(), White: m.host, Black: m.joiner, Result: req.Result, Termination: req.Termination, Moves: req.Moves, MoveTimes: req.MoveTimes}
+	if s.results != nil {
+		if err := s.results.Add(record); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		s.played = append(s.played, record)
+	}
+	slog.Info("Game result", "id", r.PathValue("id"), "white", m.host, "black", m.joiner, "result", req.Result, "termination", req.Termination)
+	if s.engine != nil && len(record.Moves) > 0 {
+		go s.review(r.PathValue("id"), record)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Leaderboard ranks the players by the results of their games for period
+func (s *Server) Leaderboard(period string) (leaderboard.Board, error) {
+	s.mu.Lock()
+	records := slices.Clone(s.played)
+	s.mu.Unlock()
+	if s.results != nil {
+		var err error
+		if records, err = s.results.Games(); err != nil {
+			return leaderboard.Board{}, err
+		}
+	}
+	return leaderboard.Rank(leaderboard.Players(records), period, s.now(), nil)
+}
+
+// handleDelete unlists a game
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	delete(s.games, r.PathValue("id"))
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// newID returns a short random game ID
+func newID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// newInviteCode returns a random private game ID, too long to guess
+func newInviteCode() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,220 @@
+package lobby
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"chess-tui/fairplay"
+	"chess-tui/gamedb"
+	"chess-tui/leaderboard"
+)
+
+// startLobby runs a lobby server and returns a client for it
+func startLobby(t *testing.T) (*Server, *Client) {
+	t.Helper()
+	server := NewServer(time.Minute)
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return server, NewClient(ts.URL)
+}
+
+func TestCreateListAndJoin(t *testing.T) {
+	_, client := startLobby(t)
+	ctx := context.Background()
+
+	open, err := client.Create(ctx, "alex", 7000)
+	if err != nil {
+		t.Fatalf("Failed to open game: %v", err)
+	}
+	if open.Addr != "127.0.0.1:7000" {
+		t.Errorf("Expected the host's address 127.0.0.1:7000, got %s", open.Addr)
+	}
+
+	games, err := client.List(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list games: %v", err)
+	}
+	if len(games) != 1 || games[0].Host != "alex" {
+		t.Fatalf("Expected alex's game listed, got %+v", games)
+	}
+
+	joined, err := client.Join(ctx, open.ID, "sam")
+	if err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	if joined.Addr != open.Addr {
+		t.Errorf("Expected to connect to %s, got %s", open.Addr, joined.Addr)
+	}
+
+	// A joined game is unlisted and can't be joined again
+	if games, _ := client.List(ctx); len(games) != 0 {
+		t.Errorf("Expected no open games after joining, got %+v", games)
+	}
+	if _, err := client.Join(ctx, open.ID, "sam"); !errors.Is(err, ErrGone) {
+		t.Errorf("Expected ErrGone joining twice, got %v", err)
+	}
+	if err := client.Heartbeat(ctx, open.ID); !errors.Is(err, ErrGone) {
+		t.Errorf("Expected ErrGone for the host's heartbeat, got %v", err)
+	}
+}
+
+func TestPrivateGamesAreUnlisted(t *testing.T) {
+	_, client := startLobby(t)
+	ctx := context.Background()
+
+	open, err := client.CreatePrivate(ctx, "alex", 7000, "s3cret")
+	if err != nil {
+		t.Fatalf("Failed to open game: %v", err)
+	}
+	if !open.Private || len(open.ID) != 16 {
+		t.Errorf("Expected a private game with a 16 character invite code, got %+v", open)
+	}
+	if games, _ := client.List(ctx); len(games) != 0 {
+		t.Errorf("Expected the private game unlisted, got %+v", games)
+	}
+
+	// The friend with the invite code joins, and gets the password
+	joined, err := client.Join(ctx, open.ID, "sam")
+	if err != nil {
+		t.Fatalf("Failed to join with the invite code: %v", err)
+	}
+	if joined.Addr != open.Addr || joined.Password != "s3cret" {
+		t.Errorf("Expected %s with the password, got %+v", open.Addr, joined)
+	}
+}
+
+func TestGamesExpireWithoutHeartbeat(t *testing.T) {
+	server, client := startLobby(t)
+	ctx := context.Background()
+	now := time.Now()
+	server.now = func() time.Time { return now }
+
+	stale, _ := client.Create(ctx, "stale", 7000)
+	alive, _ := client.Create(ctx, "alive", 7001)
+
+	now = now.Add(45 * time.Second)
+	if err := client.Heartbeat(ctx, alive.ID); err != nil {
+		t.Fatalf("Failed to send heartbeat: %v", err)
+	}
+	now = now.Add(30 * time.Second)
+
+	games, _ := client.List(ctx)
+	if len(games) != 1 || games[0].ID != alive.ID {
+		t.Errorf("Expected only the game with a heartbeat, got %+v", games)
+	}
+	if _, err := client.Join(ctx, stale.ID, "sam"); !errors.Is(err, ErrGone) {
+		t.Errorf("Expected ErrGone for an expired game, got %v", err)
+	}
+}
+
+func TestCreateNeedsAddress(t *testing.T) {
+	_, client := startLobby(t)
+	if _, err := client.Create(context.Background(), "alex", 0); err == nil {
+		t.Error("Expected an error without a port")
+	}
+}
+
+func TestReportAndLeaderboard(t *testing.T) {
+	server, client := startLobby(t)
+	ctx := context.Background()
+	db := gamedb.Open(filepath.Join(t.TempDir(), "results.jsonl"))
+	server.SetResults(db)
+
+	// An aborted game closes its match but doesn't count
+	for _, result := range []string{gamedb.WhiteWon, gamedb.Draw, gamedb.NoResult} {
+		open, _ := client.Create(ctx, "alex", 7000)
+		if _, err := client.Join(ctx, open.ID, "sam"); err != nil {
+			t.Fatalf("Failed to join: %v", err)
+		}
+		if err := client.Report(ctx, open.ID, ResultRequest{Result: result}); err != nil {
+			t.Fatalf("Failed to report %s: %v", result, err)
+		}
+		// A game's result is only taken once
+		if err := client.Report(ctx, open.ID, ResultRequest{Result: gamedb.BlackWon}); !errors.Is(err, ErrGone) {
+			t.Errorf("Expected ErrGone reporting twice, got %v", err)
+		}
+	}
+
+	// An open game hasn't been played, so has no result
+	open, _ := client.Create(ctx, "alex", 7000)
+	if err := client.Report(ctx, open.ID, ResultRequest{Result: gamedb.WhiteWon}); !errors.Is(err, ErrGone) {
+		t.Errorf("Expected ErrGone for an unjoined game, got %v", err)
+	}
+	if err := client.Report(ctx, open.ID, ResultRequest{Result: "white"}); err == nil {
+		t.Error("Expected an error for a malformed result")
+	}
+
+	board, err := client.Leaderboard(ctx, leaderboard.PeriodWeek)
+	if err != nil {
+		t.Fatalf("Failed to load leaderboard: %v", err)
+	}
+	if len(board.Entries) != 2 {
+		t.Fatalf("Expected alex and sam on the leaderboard, got %+v", board.Entries)
+	}
+	if first := board.Entries[0]; first.Name != "alex" || first.Wins != 1 || first.Draws != 1 {
+		t.Errorf("Expected alex first with a win and a draw, got %+v", first)
+	}
+	if records, _ := db.Games(); len(records) != 2 {
+		t.Errorf("Expected both results kept and the abort left out, got %d", len(records))
+	}
+	if _, err := client.Leaderboard(ctx, "month"); err == nil {
+		t.Error("Expected an error for an unknown period")
+	}
+}
+
+func TestFairPlayReviews(t *testing.T) {
+	server, client := startLobby(t)
+	ctx := context.Background()
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+
+	get := func(token string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/admin/fairplay", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to get reviews: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	if resp := get(""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the admin endpoint off without a token, got %d", resp.StatusCode)
+	}
+
+	server.SetFairPlay(fairplay.BuiltinEngine())
+	server.SetAdminToken("secret")
+	open, _ := client.Create(ctx, "alex", 7000)
+	client.Join(ctx, open.ID, "sam")
+	moves := []string{"e4", "e5", "Bc4", "Nc6", "Qh5", "Nf6", "Qxf7#"}
+	if err := client.Report(ctx, open.ID, ResultRequest{Result: gamedb.WhiteWon, Moves: moves, MoveTimes: []int64{900, 1200, 3000, 800, 4000, 2500, 1500}}); err != nil {
+		t.Fatalf("Failed to report: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(server.Reports()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if resp := get("wrong"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a wrong token refused, got %d", resp.StatusCode)
+	}
+	var reports []fairplay.Report
+	if err := json.NewDecoder(get("secret").Body).Decode(&reports); err != nil {
+		t.Fatalf("Failed to decode reviews: %v", err)
+	}
+	if len(reports) != 1 || reports[0].Game != open.ID || reports[0].White.Player != "alex" || reports[0].Black.Player != "sam" {
+		t.Fatalf("Expected the game reviewed, got %+v", reports)
+	}
+	// A miniature is over before the opening is, so there's nothing to judge
+	if reports[0].Flagged() {
+		t.Errorf("Expected a short game unflagged, got %+v", reports[0])
+	}
+}
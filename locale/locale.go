@@ -0,0 +1,173 @@
+// Package locale formats dates, numbers and durations the way the user's
+// locale writes them, for everything people read: the TUI, match reports
+// and exports. The C locale, the default, keeps the program's own formats,
+// ISO dates and plain numbers, and is what logs and machine-read output
+// such as PGN tags always use.
+package locale
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale is how one locale writes numbers and dates
+type Locale struct {
+	Name       string // e.g. "de_DE", or "C"
+	Decimal    string // the decimal separator
+	Group      string // the thousands separator, "" for none
+	DateLayout string // a time.Format layout
+	TimeLayout string
+}
+
+// C is the program's own formats: ISO dates, a 24-hour clock and numbers
+// without grouping
+var C = Locale{Name: "C", Decimal: ".", DateLayout: "2006-01-02", TimeLayout: "15:04"}
+
+// nbsp separates thousands where the locale uses a space, so a number
+// never breaks across lines
+const nbsp = "\u00a0"
+
+// locales are the known locales by language, or language and territory
+// where they differ
+var locales = map[string]Locale{
+	"en":    {Decimal: ".", Group: ",", DateLayout: "01/02/2006", TimeLayout: "3:04 PM"},
+	"en_GB": {Decimal: ".", Group: ",", DateLayout: "02/01/2006", TimeLayout: "15:04"},
+	"en_AU": {Decimal: ".", Group: ",", DateLayout: "02/01/2006", TimeLayout: "3:04 PM"},
+	"en_CA": {Decimal: ".", Group: ",", DateLayout: "2006-01-02", TimeLayout: "3:04 PM"},
+	"de":    {Decimal: ",", Group: ".", DateLayout: "02.01.2006", TimeLayout: "15:04"},
+	"de_CH": {Decimal: ".", Group: "'", DateLayout: "02.01.2006", TimeLayout: "15:04"},
+	"fr":    {Decimal: ",", Group: nbsp, DateLayout: "02/01/2006", TimeLayout: "15:04"},
+	"es":    {Decimal: ",", Group: ".", DateLayout: "02/01/2006", TimeLayout: "15:04"},
+	"it":    {Decimal: ",", Group: ".", DateLayout: "02/01/2006", TimeLayout: "15:04"},
+	"nl":    {Decimal: ",", Group: ".", DateLayout: "02-01-2006", TimeLayout: "15:04"},
+	"pt":    {Decimal: ",", Group: ".", DateLayout: "02/01/2006", TimeLayout: "15:04"},
+	"pl":    {Decimal: ",", Group: nbsp, DateLayout: "02.01.2006", TimeLayout: "15:04"},
+	"ru":    {Decimal: ",", Group: nbsp, DateLayout: "02.01.2006", TimeLayout: "15:04"},
+	"sv":    {Decimal: ",", Group: nbsp, DateLayout: "2006-01-02", TimeLayout: "15:04"},
+	"ja":    {Decimal: ".", Group: ",", DateLayout: "2006/01/02", TimeLayout: "15:04"},
+	"zh":    {Decimal: ".", Group: ",", DateLayout: "2006/01/02", TimeLayout: "15:04"},
+}
+
+// Parse looks up a locale by its POSIX name, e.g. "de_DE.UTF-8", "fr" or
+// "C". A territory it doesn't know falls back to the language's formats.
+func Parse(name string) (Locale, error) {
+	tag, _, _ := strings.Cut(name, ".") // the encoding
+	tag, _, _ = strings.Cut(tag, "@")   // a modifier, e.g. @euro
+	tag = strings.ReplaceAll(tag, "-", "_")
+	if tag == "" || tag == "C" || tag == "POSIX" {
+		return C, nil
+	}
+	language, territory, _ := strings.Cut(tag, "_")
+	language = strings.ToLower(language)
+	tag = language
+	if territory != "" {
+		tag += "_" + strings.ToUpper(territory)
+	}
+	locale, ok := locales[tag]
+	if !ok {
+		if locale, ok = locales[language]; !ok {
+			return C, fmt.Errorf("unknown locale %q", name)
+		}
+	}
+	locale.Name = tag
+	return locale, nil
+}
+
+// FromEnv returns the locale set by $LC_ALL or else $LANG, or C when
+// neither names a known locale
+func FromEnv() Locale {
+	for _, key := range []string{"LC_ALL", "LANG"} {
+		if name := os.Getenv(key); name != "" {
+			locale, _ := Parse(name)
+			return locale
+		}
+	}
+	return C
+}
+
+// Int writes n with the locale's thousands separators, e.g. "12,345"
+func (l Locale) Int(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	return sign + l.group(digits)
+}
+
+// Float writes f with prec decimals, or as few as it needs when prec is
+// negative, e.g. "1,234.5"
+func (l Locale) Float(f float64, prec int) string {
+	s := strconv.FormatFloat(f, 'f', prec, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, fraction, ok := strings.Cut(s, ".")
+	s = sign + l.group(whole)
+	if ok {
+		s += l.Decimal + fraction
+	}
+	return s
+}
+
+// Percent writes a percentage with prec decimals, e.g. "67%"
+func (l Locale) Percent(f float64, prec int) string {
+	return l.Float(f, prec) + "%"
+}
+
+// Duration writes d as time.Duration does, with the locale's decimal
+// separator, e.g. "1m2,5s"
+func (l Locale) Duration(d time.Duration) string {
+	return strings.ReplaceAll(d.String(), ".", l.Decimal)
+}
+
+// Date writes the day of t, e.g. "17.10.2026"
+func (l Locale) Date(t time.Time) string {
+	return t.Format(l.DateLayout)
+}
+
+// DateTime writes the day and time of t, e.g. "10/17/2026 3:04 PM"
+func (l Locale) DateTime(t time.Time) string {
+	return t.Format(l.DateLayout + " " + l.TimeLayout)
+}
+
+// group puts the thousands separator into a run of digits
+func (l Locale) group(digits string) string {
+	if l.Group == "" || len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	lead := len(digits) % 3
+	if lead > 0 {
+		b.WriteString(digits[:lead])
+	}
+	for i := lead; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(l.Group)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// PGNDate writes t as a PGN Date tag value, "YYYY.MM.DD" whatever the
+// locale, or "????.??.??" for the zero time when the date is unknown
+func PGNDate(t time.Time) string {
+	if t.IsZero() {
+		return "????.??.??"
+	}
+	return t.Format("2006.01.02")
+}
+
+// PGNRound writes a game's number in its event as a PGN Round tag value,
+// or "-" when the game isn't part of one
+func PGNRound(round int) string {
+	if round <= 0 {
+		return "-"
+	}
+	return strconv.Itoa(round)
+}
@@ -0,0 +1,94 @@
+package locale
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFallsBackToLanguage(t *testing.T) {
+	for name, want := range map[string]string{
+		"de_DE.UTF-8":     "de_DE",
+		"de_CH.UTF-8":     "de_CH",
+		"fr_FR@euro":      "fr_FR",
+		"en-GB":           "en_GB",
+		"C.UTF-8":         "C",
+		"POSIX":           "C",
+		"pt_BR.ISO8859-1": "pt_BR",
+	} {
+		locale, err := Parse(name)
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v", name, err)
+			continue
+		}
+		if locale.Name != want {
+			t.Errorf("Expected %s for %s, got %s", want, name, locale.Name)
+		}
+	}
+	if _, err := Parse("xx_YY"); err == nil {
+		t.Error("Expected an unknown locale to fail")
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+	if got := FromEnv().Name; got != "de_DE" {
+		t.Errorf("Expected de_DE from $LANG, got %s", got)
+	}
+	t.Setenv("LC_ALL", "fr_FR.UTF-8")
+	if got := FromEnv().Name; got != "fr_FR" {
+		t.Errorf("Expected $LC_ALL to win, got %s", got)
+	}
+	t.Setenv("LC_ALL", "klingon")
+	if got := FromEnv().Name; got != "C" {
+		t.Errorf("Expected C for an unknown locale, got %s", got)
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	german, _ := Parse("de_DE")
+	english, _ := Parse("en_US")
+	for _, c := range []struct {
+		locale Locale
+		got    string
+		want   string
+	}{
+		{C, C.Int(1234567), "1234567"},
+		{english, english.Int(1234567), "1,234,567"},
+		{german, german.Int(-1234), "-1.234"},
+		{german, german.Int(123), "123"},
+		{C, C.Float(2.5, -1), "2.5"},
+		{german, german.Float(1234.5, 1), "1.234,5"},
+		{german, german.Float(-0.25, 2), "-0,25"},
+		{english, english.Percent(66.666, 0), "67%"},
+		{german, german.Duration(1500 * time.Millisecond), "1,5s"},
+	} {
+		if c.got != c.want {
+			t.Errorf("Expected %q in %s, got %q", c.want, c.locale.Name, c.got)
+		}
+	}
+}
+
+func TestDates(t *testing.T) {
+	day := time.Date(2026, 3, 7, 15, 4, 0, 0, time.UTC)
+	german, _ := Parse("de_DE")
+	english, _ := Parse("en_US")
+	if got := C.DateTime(day); got != "2026-03-07 15:04" {
+		t.Errorf("Expected an ISO date in C, got %s", got)
+	}
+	if got := german.Date(day); got != "07.03.2026" {
+		t.Errorf("Expected 07.03.2026, got %s", got)
+	}
+	if got := english.DateTime(day); got != "03/07/2026 3:04 PM" {
+		t.Errorf("Expected 03/07/2026 3:04 PM, got %s", got)
+	}
+	if got := PGNDate(day); got != "2026.03.07" {
+		t.Errorf("Expected 2026.03.07, got %s", got)
+	}
+	if got := PGNDate(time.Time{}); got != "????.??.??" {
+		t.Errorf("Expected an unknown date, got %s", got)
+	}
+	if PGNRound(0) != "-" || PGNRound(3) != "3" {
+		t.Errorf("Expected rounds - and 3, got %s and %s", PGNRound(0), PGNRound(3))
+	}
+}
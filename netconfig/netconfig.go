@@ -0,0 +1,178 @@
+// Package netconfig is the network setup every client in the program
+// shares: the AI client, the networked play transport, the lobby and admin
+// clients, uploads and webhooks. Set once, it holds for all of them.
+//
+// Proxies come from the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables. HTTP requests go through them as usual, and TCP
+// connections for networked games are tunneled through the HTTPS proxy
+// with CONNECT. IPv6 addresses are written in brackets with a port, e.g.
+// [2001:db8::1]:7000, or bare where HostPort adds the port.
+//
+// In offline mode every connection is refused, so nothing reaches the
+// network by accident.
+package netconfig
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDialTimeout is how long a connection attempt waits unless
+// SetDialTimeout says otherwise
+const DefaultDialTimeout = 5 * time.Second
+
+// tcpKeepAlive is how often idle connections are probed
+const tcpKeepAlive = 30 * time.Second
+
+// ErrOffline is returned for connections attempted in offline mode
+var ErrOffline = errors.New("offline mode: no network connections")
+
+// dialTimeout is the configured dial timeout, 0 for the default
+var dialTimeout atomic.Int64
+
+// offline refuses every connection
+var offline atomic.Bool
+
+// SetOffline turns offline mode on or off
+func SetOffline(on bool) {
+	offline.Store(on)
+}
+
+// Offline reports whether connections are refused
+func Offline() bool {
+	return offline.Load()
+}
+
+// SetDialTimeout sets how long every client waits for a connection,
+// including the proxy's when there is one; 0 or less restores the default
+func SetDialTimeout(timeout time.Duration) {
+	dialTimeout.Store(int64(max(timeout, 0)))
+}
+
+// DialTimeout returns how long a connection attempt waits
+func DialTimeout() time.Duration {
+	if timeout := time.Duration(dialTimeout.Load()); timeout > 0 {
+		return timeout
+	}
+	return DefaultDialTimeout
+}
+
+// DialContext connects to addr on network directly, waiting up to
+// DialTimeout. Transports use it, leaving the proxy to their Proxy setting.
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if Offline() {
+		return nil, ErrOffline
+	}
+	dialer := &net.Dialer{Timeout: DialTimeout(), KeepAlive: tcpKeepAlive}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// NewTransport returns an HTTP transport that goes through the
+// environment's proxy and dials with DialTimeout
+func NewTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.DialContext = DialContext
+	return transport
+}
+
+// NewClient returns an HTTP client on a new transport that gives up on a
+// request after timeout, 0 for never
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: NewTransport(), Timeout: timeout}
+}
+
+// Dial opens a TCP connection to addr, through the environment's HTTPS
+// proxy when it has one for addr
+func Dial(ctx context.Context, addr string) (net.Conn, error) {
+	proxy, err := http.ProxyFromEnvironment(&http.Request{URL: &url.URL{Scheme: "https", Host: addr}})
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy setting: %w", err)
+	}
+	if proxy == nil {
+		return DialContext(ctx, "tcp", addr)
+	}
+	return dialThrough(ctx, proxy, addr)
+}
+
+// dialThrough opens a tunnel to addr with CONNECT through the HTTP or
+// HTTPS proxy
+func dialThrough(ctx context.Context, proxy *url.URL, addr string) (net.Conn, error) {
+	proxyAddr := proxy.Host
+	switch proxy.Scheme {
+	case "http":
+		proxyAddr = HostPort(proxyAddr, 80)
+	case "https":
+		proxyAddr = HostPort(proxyAddr, 443)
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q for a TCP connection", proxy.Scheme)
+	}
+	conn, err := DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy: %w", err)
+	}
+	if proxy.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: proxy.Hostname()})
+	}
+
+	// The CONNECT exchange gets the dial timeout too
+	conn.SetDeadline(time.Now().Add(DialTimeout()))
+	request := "CONNECT " + addr + " HTTP/1.1\r\nHost: " + addr + "\r\n"
+	if user := proxy.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		request += "Proxy-Authorization: Basic " + credentials + "\r\n"
+	}
+	if _, err := conn.Write([]byte(request + "\r\n")); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to ask proxy for a tunnel: %w", err)
+	}
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read proxy's answer: %w", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused tunnel to %s: %s", addr, response.Status)
+	}
+	conn.SetDeadline(time.Time{})
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a tunnel whose first bytes from the far end arrived with
+// the proxy's answer
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// HostPort returns addr with port added if it has none, bracketing a bare
+// IPv6 address, e.g. "2001:db8::1" becomes "[2001:db8::1]:7000"
+func HostPort(addr string, port int) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+	host := strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
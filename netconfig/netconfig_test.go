@@ -0,0 +1,109 @@
+package netconfig
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHostPort(t *testing.T) {
+	for addr, want := range map[string]string{
+		"192.168.1.20":      "192.168.1.20:7000",
+		"192.168.1.20:7001": "192.168.1.20:7001",
+		"example.com":       "example.com:7000",
+		"2001:db8::1":       "[2001:db8::1]:7000",
+		"[2001:db8::1]":     "[2001:db8::1]:7000",
+		"[::1]:7001":        "[::1]:7001",
+	} {
+		if got := HostPort(addr, 7000); got != want {
+			t.Errorf("Expected %s for %s, got %s", want, addr, got)
+		}
+	}
+}
+
+func TestDialTimeout(t *testing.T) {
+	defer SetDialTimeout(0)
+	SetDialTimeout(time.Second)
+	if got := DialTimeout(); got != time.Second {
+		t.Errorf("Expected a 1s dial timeout, got %s", got)
+	}
+	SetDialTimeout(0)
+	if got := DialTimeout(); got != DefaultDialTimeout {
+		t.Errorf("Expected the default dial timeout back, got %s", got)
+	}
+}
+
+func TestDialThroughProxy(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	// A proxy that checks the CONNECT request, then echoes the tunnel's
+	// bytes back, the first of them sent with its answer
+	requests := make(chan *http.Request, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		request, err := http.ReadRequest(reader)
+		if err != nil {
+			return
+		}
+		requests <- request
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\nhello ")
+		io.Copy(conn, reader)
+	}()
+
+	proxy := &url.URL{Scheme: "http", Host: listener.Addr().String(), User: url.UserPassword("alex", "s3cret")}
+	conn, err := dialThrough(context.Background(), proxy, "[2001:db8::1]:7000")
+	if err != nil {
+		t.Fatalf("Failed to dial through proxy: %v", err)
+	}
+	defer conn.Close()
+	request := <-requests
+	if request.Method != http.MethodConnect || request.Host != "[2001:db8::1]:7000" {
+		t.Errorf("Expected CONNECT to [2001:db8::1]:7000, got %s %s", request.Method, request.Host)
+	}
+	if auth := request.Header.Get("Proxy-Authorization"); !strings.HasPrefix(auth, "Basic ") {
+		t.Errorf("Expected the proxy's credentials, got %q", auth)
+	}
+
+	io.WriteString(conn, "world")
+	got := make([]byte, len("hello world"))
+	if _, err := io.ReadFull(conn, got); err != nil || string(got) != "hello world" {
+		t.Errorf("Expected the tunnel to carry %q, got %q (%v)", "hello world", got, err)
+	}
+
+	if _, err := dialThrough(context.Background(), &url.URL{Scheme: "socks5", Host: "127.0.0.1:1080"}, "example.com:7000"); err == nil {
+		t.Error("Expected an error for a SOCKS proxy")
+	}
+}
+
+func TestOfflineRefusesConnections(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	SetOffline(true)
+	defer SetOffline(false)
+	if _, err := Dial(context.Background(), listener.Addr().String()); !errors.Is(err, ErrOffline) {
+		t.Errorf("Expected ErrOffline dialing, got %v", err)
+	}
+	if _, err := NewClient(time.Second).Get("http://" + listener.Addr().String()); !errors.Is(err, ErrOffline) {
+		t.Errorf("Expected ErrOffline for an HTTP request, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package netplay
+
+import (
+	"reflect"
+	"slices"
+	"time"
+
+	"chess-tui/tournament"
+)
+
+// PingInterval is how often the round trip to the other side is measured
+const PingInterval = 5 * time.Second
+
+// SetClock sets the game's time control, or nil for an untimed game. The
+// host's is sent to the joiner; the joiner's own is ignored.
+func (p *Peer) SetClock(clock *tournament.TimeControl) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.host {
+		return
+	}
+	p.clock = clock
+	p.sendHello()
+	p.broadcast(Message{Clock: clock}, Message{})
+}
+
+// Clock returns the game's time control, or nil if the game is untimed
+func (p *Peer) Clock() *tournament.TimeControl {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.clock
+}
+
+// Times returns the thinking time of every move so far, as the host counts it
+func (p *Peer) Times() []time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.times)
+}
+
+// Latency returns the estimated time a message takes to reach the other
+// side, half the round trip, or 0 until it has been measured
+func (p *Peer) Latency() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rtt / 2
+}
+
+// sendPing starts measuring the round trip. The caller holds p.mu.
+func (p *Peer) sendPing() {
+	p.ping++
+	p.pingAt = time.Now()
+	p.write(Message{Type: TypePing, Ping: p.ping})
+}
+
+// measure takes the round trip of the last ping from its pong, smoothed
+// like TCP's so that one slow reply doesn't throw it. The caller holds p.mu.
+func (p *Peer) measure(pong Message) {
+	if pong.Ping != p.ping || p.pingAt.IsZero() {
+		return
+	}
+	sample := time.Since(p.pingAt)
+	p.pingAt = time.Time{}
+	if p.rtt == 0 {
+		p.rtt = sample
+		return
+	}
+	p.rtt = (7*p.rtt + sample) / 8
+}
+
+// countedTime returns the thinking time to count for a move the other side
+// reports taking reported over. The host counts at least the time since
+// the joiner acknowledged its move, less the move's trip back, so a joiner
+// can't claim a quicker move than it made. The caller holds p.mu.
+func (p *Peer) countedTime(reported time.Duration) time.Duration {
+	if !p.host || p.ackedAt.IsZero() {
+		return reported
+	}
+	return max(reported, time.Since(p.ackedAt)-p.rtt/2)
+}
+
+// ackedTime takes the time the host counts for our move from its
+// acknowledgement, reporting whether it changed. The caller holds p.mu.
+func (p *Peer) ackedTime(ack Message) (Event, bool) {
+	if p.host || ack.Elapsed == 0 || ack.Seq < 1 || ack.Seq > len(p.times) {
+		return Event{}, false
+	}
+	counted := time.Duration(ack.Elapsed) * time.Millisecond
+	if p.times[ack.Seq-1] == counted {
+		return Event{}, false
+	}
+	p.times[ack.Seq-1] = counted
+	return p.clockEvent(), true
+}
+
+// adoptClock takes the host's time control and times from its hello,
+// reporting whether either changed. The caller holds p.mu.
+func (p *Peer) adoptClock(hello Message, times []time.Duration) (Event, bool) {
+	if p.host {
+		return Event{}, false
+	}
+	changed := !reflect.DeepEqual(p.clock, hello.Clock)
+	p.clock = hello.Clock
+	for ply := range min(len(p.times), len(times)) {
+		if p.times[ply] != times[ply] {
+			p.times[ply] = times[ply]
+			changed = true
+		}
+	}
+	return p.clockEvent(), changed
+}
+
+// clockEvent reports the time control and times. The caller holds p.mu.
+func (p *Peer) clockEvent() Event {
+	return Event{Kind: EventClock, Times: slices.Clone(p.times), Clock: p.clock}
+}
+
+// durations converts thinking times in ms from the wire, padded to plies
+func durations(times []int64, plies int) []time.Duration {
+	result := make([]time.Duration, max(plies, len(times)))
+	for i, ms := range times {
+		result[i] = time.Duration(ms) * time.Millisecond
+	}
+	return result[:plies]
+}
+
+// milliseconds converts thinking times for the wire
+func milliseconds(times []time.Duration) []int64 {
+	result := make([]int64, len(times))
+	for i, elapsed := range times {
+		result[i] = elapsed.Milliseconds()
+	}
+	return result
+}
@@ -0,0 +1,770 @@
+// Package netplay connects two players' boards over TCP for networked Human
+// vs Human games.
+//
+// The protocol is one JSON message per line. Every move carries its ply
+// number as a sequence number and is acknowledged by the other side; moves
+// not acknowledged in time are sent again, and duplicates are ignored. On
+// every (re)connection both sides send a hello with their full move
+// history, so moves lost while the link was down are replayed, and boards
+// that disagree are reset to the host's.
+//
+// The host gives the first joiner a resume token in its hello. Only a
+// joiner with the token may take the seat after that, so a player whose
+// program closed can rejoin the game with it, while anyone else is turned
+// away. A seat left empty longer than the grace period is given up.
+//
+// A private game has a password. The host only takes joiners and
+// spectators whose hello carries it, and doesn't ask it of a player
+// rejoining with the resume token.
+//
+// Spectators connect to the host with a hello that only watches. The host
+// sends them the game and every move after, and tells everyone how many
+// are watching. Spectators whose hello asks for deltas are sent each
+// change, a move with its thinking time, a new time control, the number
+// watching or the players' connection, as one small numbered delta, and
+// the full game only every CheckpointInterval deltas or when a number is
+// skipped, so a host with many watchers sends little per move.
+//
+// In consultation games the players share a budget of hints from an AI
+// advisor. Each side reports the hints it spends, and hellos carry the
+// tally, so hints spent while disconnected are counted on reconnection.
+//
+// The host's clock is authoritative. It sends its time control in its
+// hellos, and every move carries the mover's thinking time, measured from
+// when the move before reached it, so the time a move spends in transit is
+// charged to neither player. The host checks the joiner's reports against
+// its own view less the round trip measured with pings, and its
+// acknowledgements and hellos carry the times it counts.
+package netplay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"chess-tui/netconfig"
+	"chess-tui/tournament"
+)
+
+// Message types
+const (
+	TypeHello    = "hello" // the sender's full history, sent on connecting
+	TypeSync     = "sync"  // asks the other side for a hello
+	TypeMove     = "move"
+	TypeAck      = "ack"
+	TypeHint     = "hint" // the sender's tally of hints, sent when it changes
+	TypePing     = "ping" // asks for a pong, to measure the round trip
+	TypePong     = "pong"
+	TypeReject   = "reject"   // the host turns a joiner away, with a reason
+	TypePresence = "presence" // from the host: how many spectators are watching
+	TypeDelta    = "delta"    // from the host to a spectator: what changed since the last delta
+)
+
+// Timing of acknowledgements and reconnection
+const (
+	AckTimeout       = 2 * time.Second  // resend unacknowledged moves after this long
+	DeadLinkTimeout  = 10 * time.Second // drop the connection when moves go unacknowledged this long
+	MaxReconnectWait = 5 * time.Second  // longest wait between reconnection attempts
+)
+
+// Message is one line of the protocol
+type Message struct {
+	Type  string   `json:"type"`
+	Seq   int      `json:"seq,omitempty"`   // move: its ply, from 1; ack: the plies received
+	Move  string   `json:"move,omitempty"`  // UCI, e.g. "e2e4"
+	Moves []string `json:"moves,omitempty"` // hello: every move of the game so far
+	Color string   `json:"color,omitempty"` // hello from the host: the color the joiner plays
+
+	Hints  map[string]int `json:"hints,omitempty"`  // hello, hint: hints spent by each color
+	Budget int            `json:"budget,omitempty"` // hello, hint from the host: the shared hint budget
+
+	Elapsed int64                   `json:"elapsed,omitempty"` // move: the mover's thinking time in ms; ack from the host: the time it counts
+	Times   []int64                 `json:"times,omitempty"`   // hello: every move's thinking time in ms
+	Clock   *tournament.TimeControl `json:"clock,omitempty"`   // hello from the host: the game's time control, if any
+	Ping    int                     `json:"ping,omitempty"`    // ping, pong: which ping
+
+	Token    string `json:"token,omitempty"`    // hello: the resume token, from the host or to rejoin with
+	Password string `json:"password,omitempty"` // hello to the host: the private game's password
+	Reason   string `json:"reason,omitempty"`   // reject: why the joiner was turned away
+
+	Watch    bool `json:"watch,omitempty"`    // hello: the sender only watches
+	Watchers int  `json:"watchers,omitempty"` // hello from the host, presence, delta: how many spectators are watching
+
+	Delta  bool   `json:"delta,omitempty"`  // hello from a spectator: it takes deltas
+	Update int    `json:"update,omitempty"` // delta: its number, from 1; hello to a spectator: the last delta it covers
+	Status string `json:"status,omitempty"` // delta, hello to a spectator: the players' connection, e.g. "Both players are connected"
+}
+
+// EventKind says what an Event reports
+type EventKind int
+
+const (
+	// EventMove is a move by the opponent
+	EventMove EventKind = iota
+	// EventResync replaces the whole game with Moves, after the boards disagreed
+	EventResync
+	// EventStatus is a change in the connection
+	EventStatus
+	// EventHint is a change in the hints spent or the hint budget
+	EventHint
+	// EventClock is a change in the time control or the times counted
+	EventClock
+	// EventPresence is a change in how many spectators are watching
+	EventPresence
+)
+
+// Event is something the TUI should show
+type Event struct {
+	Kind      EventKind
+	Move      string        // EventMove: UCI
+	Elapsed   time.Duration // EventMove: the mover's thinking time
+	Moves     []string      // EventResync: the game's moves in UCI
+	Status    string        // EventStatus: e.g. "Reconnecting (attempt 2)…"
+	Connected bool          // EventStatus: whether the opponent is connected
+	Abandoned bool          // EventStatus: the opponent didn't come back within the grace period; the game is over
+
+	Hints  map[string]int // EventHint: hints spent by each color
+	Budget int            // EventHint: the shared hint budget, 0 if not set
+
+	Times []time.Duration         // EventClock: every move's thinking time
+	Clock *tournament.TimeControl // EventClock: the host's time control, nil if untimed
+
+	Watchers int // EventPresence: how many spectators are watching
+}
+
+// Peer is one end of a networked game
+type Peer struct {
+	host  bool
+	color string // "white" or "black", the local player's color
+	addr  string
+
+	listener net.Listener // host only
+	events   chan Event
+	done     chan struct{}
+	once     sync.Once
+
+	emitting     sync.RWMutex // held to close events once nothing is sending
+	eventsClosed bool
+
+	mu      sync.Mutex
+	history []string  // every move of the game, in UCI
+	acked   int       // plies the other side has confirmed
+	sentAt  time.Time // when unacknowledged moves were last sent
+	conn    net.Conn
+	enc     *json.Encoder
+
+	hints  map[string]int // hints spent by each color
+	budget int            // the shared hint budget; the host's counts
+
+	times   []time.Duration         // every move's thinking time; the host's count
+	clock   *tournament.TimeControl // the game's time control; the host's counts
+	ackedAt time.Time               // when the other side acknowledged our last move, starting its clock
+	rtt     time.Duration           // smoothed round trip time, 0 until measured
+	ping    int                     // the last ping sent
+	pingAt  time.Time               // when it was sent
+
+	token     string        // the resume token, once known
+	seated    bool          // host: a joiner has taken the seat
+	grace     time.Duration // host: how long the seat is kept for a joiner who left
+	leftAt    time.Time     // host: when the joiner left, zero while connected
+	drops     int           // host: how many times the joiner has left
+	abandoned bool          // host: the joiner didn't come back in time
+	password  string        // the private game's password: the host's to check, the others' to give
+
+	players  chan *link              // host: connections from would-be joiners
+	watcher  bool                    // the local side only watches
+	watchers map[net.Conn]*spectator // host: the spectators' connections
+	watching int                     // how many spectators are watching
+	updates  int                     // host: deltas sent; spectator: the last delta applied
+	syncing  bool                    // spectator: the full game has been asked for after a skipped delta
+}
+
+// Host listens on addr for the opponent. The host plays White and is the
+// authority when the boards disagree.
+func Host(addr string) (*Peer, error) {
+	return HostPrivate(addr, "")
+}
+
+// HostPrivate hosts a game like Host that only takes joiners and spectators
+// with password; "" makes it open to all
+func HostPrivate(addr, password string) (*Peer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for opponent: %w", err)
+	}
+	p := newPeer(true, "white", listener.Addr().String())
+	p.listener = listener
+	p.token = newToken()
+	p.password = password
+	go p.accept()
+	go p.run()
+	return p, nil
+}
+
+// Join connects to a host at addr, playing Black. It returns once the first
+// connection succeeds; later drops are reconnected in the background.
+func Join(addr string) (*Peer, error) {
+	return JoinPrivate(addr, "")
+}
+
+// JoinPrivate joins a private game like Join, giving the host password
+func JoinPrivate(addr, password string) (*Peer, error) {
+	conn, err := dial(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to host: %w", err)
+	}
+	p := newPeer(false, "black", addr)
+	p.password = password
+	go func() {
+		p.serve(newLink(conn))
+		p.run()
+	}()
+	return p, nil
+}
+
+// dial connects to the host at addr, through the environment's proxy if it
+// has one
+func dial(addr string) (net.Conn, error) {
+	return netconfig.Dial(context.Background(), addr)
+}
+
+func newPeer(host bool, color, addr string) *Peer {
+	return &Peer{
+		host:   host,
+		color:  color,
+		addr:   addr,
+		events: make(chan Event, 64),
+		done:   make(chan struct{}),
+		hints:  make(map[string]int),
+		grace:  ResumeGrace,
+
+		players:  make(chan *link),
+		watchers: make(map[net.Conn]*spectator),
+	}
+}
+
+// Color returns the local player's color, "white" or "black"
+func (p *Peer) Color() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.color
+}
+
+// Addr returns the address the host listens on, or the joiner connects to
+func (p *Peer) Addr() string {
+	return p.addr
+}
+
+// Events returns the moves and connection changes to show. It is closed
+// when the peer is.
+func (p *Peer) Events() <-chan Event {
+	return p.events
+}
+
+// Send plays a local move, given in UCI, that the local player thought
+// about for elapsed. While disconnected it is kept and delivered on
+// reconnection.
+func (p *Peer) Send(move string, elapsed time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.history = append(p.history, move)
+	p.times = append(p.times, elapsed)
+	p.ackedAt = time.Time{}
+	if len(p.history)-p.acked == 1 {
+		p.sentAt = time.Now()
+	}
+	p.write(Message{Type: TypeMove, Seq: len(p.history), Move: move, Elapsed: elapsed.Milliseconds()})
+	p.forward(len(p.history) - 1)
+}
+
+// SetHintBudget sets the hints the players share in a consultation game.
+// The host's budget is sent to the joiner, whose own is replaced by it.
+func (p *Peer) SetHintBudget(budget int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.budget = budget
+	if p.host {
+		p.write(Message{Type: TypeHint, Hints: maps.Clone(p.hints), Budget: budget})
+	}
+}
+
+// SpendHint counts a hint taken by the local player and tells the other
+// side. While disconnected it is counted on reconnection.
+func (p *Peer) SpendHint() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hints[p.color]++
+	p.write(Message{Type: TypeHint, Hints: maps.Clone(p.hints)})
+}
+
+// Hints returns the hints spent by each color
+func (p *Peer) Hints() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return maps.Clone(p.hints)
+}
+
+// History returns the moves of the game so far, in UCI
+func (p *Peer) History() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.history)
+}
+
+// Close ends the game's connection
+func (p *Peer) Close() error {
+	p.once.Do(func() {
+		close(p.done)
+		if p.listener != nil {
+			p.listener.Close()
+		}
+		p.mu.Lock()
+		if p.conn != nil {
+			p.conn.Close()
+		}
+		for conn := range p.watchers {
+			conn.Close()
+		}
+		p.mu.Unlock()
+	})
+	return nil
+}
+
+// closed reports whether Close has been called
+func (p *Peer) closed() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// emit reports an event, unless the peer is closed
+func (p *Peer) emit(event Event) {
+	p.emitting.RLock()
+	defer p.emitting.RUnlock()
+	if p.eventsClosed {
+		return
+	}
+	select {
+	case p.events <- event:
+	case <-p.done:
+	}
+}
+
+// run keeps the game connected until Close, accepting (host) or redialling
+// (joiner) after every drop
+func (p *Peer) run() {
+	defer func() {
+		// Events may still come from the timers and spectators
+		p.emitting.Lock()
+		p.eventsClosed = true
+		close(p.events)
+		p.emitting.Unlock()
+	}()
+	for !p.closed() {
+		l := p.connect()
+		if l == nil {
+			return
+		}
+		p.serve(l)
+	}
+}
+
+// connect waits for the next connection, or returns nil once closed
+func (p *Peer) connect() *link {
+	if p.host {
+		p.mu.Lock()
+		waiting := "Waiting for an opponent to join " + p.addr
+		switch {
+		case p.password != "" && !p.seated:
+			waiting = "Waiting for an opponent with the password to join " + p.addr
+		case p.abandoned:
+			waiting = "Opponent did not reconnect within " + p.grace.String()
+		case !p.leftAt.IsZero():
+			waiting = fmt.Sprintf("Opponent reconnecting… (the seat is kept for %s)", p.grace)
+		}
+		p.mu.Unlock()
+		p.emit(Event{Kind: EventStatus, Status: waiting})
+
+		select {
+		case l := <-p.players:
+			return l
+		case <-p.done:
+			return nil
+		}
+	}
+
+	wait := 250 * time.Millisecond
+	lost := time.Now()
+	p.mu.Lock()
+	grace := p.grace
+	p.mu.Unlock()
+	for attempt := 1; ; attempt++ {
+		p.emit(Event{Kind: EventStatus, Status: fmt.Sprintf("Connection lost, reconnecting to %s (attempt %d)…", p.addr, attempt)})
+		conn, err := dial(p.addr)
+		if err == nil {
+			return newLink(conn)
+		}
+		// A player gives up on a host gone for longer than it would keep
+		// their seat; a spectator keeps trying
+		if !p.watcher && time.Since(lost) >= grace {
+			p.emit(Event{Kind: EventStatus, Status: "Host did not come back within " + grace.String(), Abandoned: true})
+			return nil
+		}
+		select {
+		case <-p.done:
+			return nil
+		case <-time.After(wait):
+		}
+		wait = min(2*wait, MaxReconnectWait)
+	}
+}
+
+// link is a connection to the other side, with its reader
+type link struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+	hello   *Message // host: the hello the connection opened with
+}
+
+// newLink wraps a connection for reading messages
+func newLink(conn net.Conn) *link {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &link{conn: conn, scanner: scanner}
+}
+
+// accept takes connections to the host until Close
+func (p *Peer) accept() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		go p.greet(conn)
+	}
+}
+
+// greet reads the hello a connection to the host opens with, and hands the
+// connection to the game, or to the spectators if it only watches
+func (p *Peer) greet(conn net.Conn) {
+	l := newLink(conn)
+	conn.SetReadDeadline(time.Now().Add(MaxReconnectWait))
+	var hello Message
+	if !l.scanner.Scan() || json.Unmarshal(l.scanner.Bytes(), &hello) != nil || hello.Type != TypeHello {
+		conn.Close()
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	l.hello = &hello
+
+	if hello.Watch {
+		if !p.knows(hello.Password) {
+			json.NewEncoder(conn).Encode(Message{Type: TypeReject, Reason: "the game is private; watch it with its password"})
+			conn.Close()
+			return
+		}
+		p.serveWatcher(l)
+		return
+	}
+	select {
+	case p.players <- l:
+	case <-p.done:
+		conn.Close()
+	}
+}
+
+// serve exchanges messages over a connection until it fails or the peer
+// is closed. The host first admits the joiner from its hello.
+func (p *Peer) serve(l *link) {
+	conn, scanner := l.conn, l.scanner
+	if p.host {
+		if !p.admit(l) {
+			conn.Close()
+			return
+		}
+		defer p.left()
+	}
+
+	p.mu.Lock()
+	p.conn = conn
+	p.enc = json.NewEncoder(conn)
+	p.sentAt = time.Now() // give the new connection a full timeout
+	p.sendHello()
+	p.sendPing()
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.conn, p.enc = nil, nil
+		p.mu.Unlock()
+		conn.Close()
+	}()
+
+	if l.hello != nil {
+		p.handle(*l.hello)
+	}
+
+	messages := make(chan Message)
+	go func() {
+		defer close(messages)
+		for scanner.Scan() {
+			var msg Message
+			if json.Unmarshal(scanner.Bytes(), &msg) != nil {
+				continue
+			}
+			select {
+			case messages <- msg:
+			case <-p.done:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(AckTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			p.handle(msg)
+		case <-ticker.C:
+			if !p.resendUnacked() {
+				return
+			}
+			p.mu.Lock()
+			if time.Since(p.pingAt) > PingInterval {
+				p.sendPing()
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// handle applies one message from the other side
+func (p *Peer) handle(msg Message) {
+	switch msg.Type {
+	case TypeHello:
+		p.reconcile(msg)
+	case TypeSync:
+		p.mu.Lock()
+		p.sendHello()
+		p.mu.Unlock()
+	case TypeAck:
+		p.mu.Lock()
+		if msg.Seq > p.acked && msg.Seq <= len(p.history) {
+			p.acked = msg.Seq
+			p.sentAt = time.Now()
+			if msg.Seq == len(p.history) {
+				p.ackedAt = time.Now()
+			}
+		}
+		event, changed := p.ackedTime(msg)
+		p.mu.Unlock()
+		if changed {
+			p.emit(event)
+		}
+	case TypePing:
+		p.mu.Lock()
+		p.write(Message{Type: TypePong, Ping: msg.Ping})
+		p.mu.Unlock()
+	case TypePong:
+		p.mu.Lock()
+		p.measure(msg)
+		p.mu.Unlock()
+	case TypePresence:
+		p.mu.Lock()
+		p.watching = msg.Watchers
+		p.mu.Unlock()
+		p.emit(Event{Kind: EventPresence, Watchers: msg.Watchers})
+	case TypeDelta:
+		p.applyDelta(msg)
+	case TypeReject:
+		p.emit(Event{Kind: EventStatus, Status: "The host turned us away: " + msg.Reason})
+		p.Close()
+	case TypeMove:
+		p.mu.Lock()
+		switch {
+		case msg.Seq == len(p.history)+1:
+			elapsed := p.countedTime(time.Duration(msg.Elapsed) * time.Millisecond)
+			p.history = append(p.history, msg.Move)
+			p.times = append(p.times, elapsed)
+			p.acked = len(p.history)
+			ack := Message{Type: TypeAck, Seq: len(p.history)}
+			if p.host {
+				ack.Elapsed = elapsed.Milliseconds()
+			}
+			p.write(ack)
+			p.forward(len(p.history) - 1)
+			p.mu.Unlock()
+			p.emit(Event{Kind: EventMove, Move: msg.Move, Elapsed: elapsed})
+			return
+		case msg.Seq <= len(p.history):
+			// A resent move we already have
+			p.write(Message{Type: TypeAck, Seq: len(p.history)})
+		default:
+			// We missed a move; ask for the whole game
+			p.write(Message{Type: TypeSync})
+		}
+		p.mu.Unlock()
+	case TypeHint:
+		p.mu.Lock()
+		event, changed := p.mergeHints(msg)
+		p.mu.Unlock()
+		if changed {
+			p.emit(event)
+		}
+	}
+}
+
+// mergeHints takes the larger of our tally and the other side's for each
+// color, and the host's budget, and reports whether anything changed. The
+// caller holds p.mu.
+func (p *Peer) mergeHints(msg Message) (Event, bool) {
+	changed := false
+	for color, spent := range msg.Hints {
+		if spent > p.hints[color] {
+			p.hints[color] = spent
+			changed = true
+		}
+	}
+	if !p.host && msg.Budget > 0 && msg.Budget != p.budget {
+		p.budget = msg.Budget
+		changed = true
+	}
+	return Event{Kind: EventHint, Hints: maps.Clone(p.hints), Budget: p.budget}, changed
+}
+
+// reconcile brings the local history in line with the other side's after
+// a hello: moves it has that we lack are played, and if the histories
+// disagree the joiner takes the host's
+func (p *Peer) reconcile(hello Message) {
+	p.mu.Lock()
+	if !p.host && hello.Color != "" {
+		p.color = hello.Color
+	}
+	if !p.host && hello.Token != "" {
+		p.token = hello.Token
+	}
+	connected := "Opponent connected"
+	switch {
+	case p.watcher:
+		connected = watchingStatus(hello.Status)
+		p.updates, p.syncing = hello.Update, false
+	case !p.host && p.token != "":
+		connected += " — rejoin with --resume " + p.token
+	}
+	presence := !p.host && hello.Watchers != p.watching
+	if presence {
+		p.watching = hello.Watchers
+	}
+	local, remote := p.history, hello.Moves
+	hint, hintChanged := p.mergeHints(hello)
+	remoteTimes := durations(hello.Times, len(remote))
+
+	var events []Event
+	resynced := false
+	switch {
+	case isPrefix(local, remote):
+		for ply, move := range remote[len(local):] {
+			events = append(events, Event{Kind: EventMove, Move: move, Elapsed: remoteTimes[len(local)+ply]})
+		}
+		p.history = slices.Clone(remote)
+		p.times = append(p.times[:len(local)], remoteTimes[len(local):]...)
+		p.acked = len(remote)
+		p.forward(len(local))
+	case isPrefix(remote, local):
+		// The other side adopts our extra moves from our hello
+		p.acked = len(local)
+	case p.host:
+		// The joiner resets to our history from our hello
+		p.acked = len(local)
+	default:
+		p.history = slices.Clone(remote)
+		p.times = slices.Clone(remoteTimes)
+		p.acked = len(remote)
+		resynced = true
+		events = append(events, Event{Kind: EventResync, Moves: slices.Clone(remote)})
+	}
+	clock, clockChanged := p.adoptClock(hello, remoteTimes)
+	// A resynced board takes all its times from the host
+	clockChanged = clockChanged || resynced
+	p.mu.Unlock()
+
+	p.emit(Event{Kind: EventStatus, Status: connected, Connected: true})
+	for _, event := range events {
+		p.emit(event)
+	}
+	if hintChanged {
+		p.emit(hint)
+	}
+	if clockChanged {
+		p.emit(clock)
+	}
+	if presence {
+		p.emit(Event{Kind: EventPresence, Watchers: hello.Watchers})
+	}
+}
+
+// resendUnacked sends unacknowledged moves again after AckTimeout. It
+// returns false when they have gone unacknowledged so long that the link
+// is presumed dead.
+func (p *Peer) resendUnacked() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.acked >= len(p.history) {
+		return true
+	}
+	waited := time.Since(p.sentAt)
+	if waited > DeadLinkTimeout {
+		return false
+	}
+	if waited > AckTimeout {
+		for seq := p.acked + 1; seq <= len(p.history); seq++ {
+			p.write(Message{Type: TypeMove, Seq: seq, Move: p.history[seq-1]})
+		}
+	}
+	return true
+}
+
+// sendHello sends our full history. The caller holds p.mu.
+func (p *Peer) sendHello() {
+	hello := Message{Type: TypeHello, Moves: p.history, Hints: p.hints, Times: milliseconds(p.times), Token: p.token, Watch: p.watcher, Delta: p.watcher}
+	if p.host {
+		hello.Color = "black"
+		hello.Budget = p.budget
+		hello.Clock = p.clock
+		hello.Watchers = p.watching
+	} else {
+		hello.Password = p.password
+	}
+	p.write(hello)
+}
+
+// write sends a message if connected. The caller holds p.mu.
+func (p *Peer) write(msg Message) {
+	if p.enc == nil {
+		return
+	}
+	if err := p.enc.Encode(msg); err != nil {
+		// The reader notices the broken connection and reconnects
+		p.conn.Close()
+	}
+}
+
+// isPrefix reports whether prefix is the start of moves
+func isPrefix(prefix, moves []string) bool {
+	return len(prefix) <= len(moves) && slices.Equal(prefix, moves[:len(prefix)])
+}
@@ -0,0 +1,499 @@
+package netplay
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"chess-tui/tournament"
+)
+
+// nextEvent returns the peer's next event of the given kind, skipping others
+func nextEvent(t *testing.T, p *Peer, kind EventKind) Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-p.Events():
+			if !ok {
+				t.Fatal("Events closed")
+			}
+			if event.Kind == kind {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for event kind %d", kind)
+		}
+	}
+}
+
+// waitConnected waits until the peer reports its opponent connected
+func waitConnected(t *testing.T, p *Peer) {
+	t.Helper()
+	for !nextEvent(t, p, EventStatus).Connected {
+	}
+}
+
+// connectedPair starts a host and a joiner connected to it
+func connectedPair(t *testing.T) (*Peer, *Peer) {
+	t.Helper()
+	host, err := Host("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to host: %v", err)
+	}
+	t.Cleanup(func() { host.Close() })
+	joiner, err := Join(host.Addr())
+	if err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	t.Cleanup(func() { joiner.Close() })
+	waitConnected(t, host)
+	waitConnected(t, joiner)
+	return host, joiner
+}
+
+func TestMovesAreExchanged(t *testing.T) {
+	host, joiner := connectedPair(t)
+	if host.Color() != "white" || joiner.Color() != "black" {
+		t.Errorf("Expected host white and joiner black, got %s and %s", host.Color(), joiner.Color())
+	}
+
+	host.Send("e2e4", 0)
+	if event := nextEvent(t, joiner, EventMove); event.Move != "e2e4" {
+		t.Errorf("Expected joiner to receive e2e4, got %s", event.Move)
+	}
+	joiner.Send("e7e5", 0)
+	if event := nextEvent(t, host, EventMove); event.Move != "e7e5" {
+		t.Errorf("Expected host to receive e7e5, got %s", event.Move)
+	}
+
+	want := []string{"e2e4", "e7e5"}
+	if !slices.Equal(host.History(), want) || !slices.Equal(joiner.History(), want) {
+		t.Errorf("Expected both histories %v, got %v and %v", want, host.History(), joiner.History())
+	}
+}
+
+func TestReconnectReplaysMissedMoves(t *testing.T) {
+	host, joiner := connectedPair(t)
+
+	// Drop the joiner's connection; the host's move is lost in transit
+	joiner.mu.Lock()
+	joiner.conn.Close()
+	joiner.mu.Unlock()
+	host.Send("d2d4", 0)
+
+	status := nextEvent(t, joiner, EventStatus)
+	if !strings.Contains(status.Status, "reconnecting") {
+		t.Errorf("Expected reconnection progress, got %q", status.Status)
+	}
+	if event := nextEvent(t, joiner, EventMove); event.Move != "d2d4" {
+		t.Errorf("Expected the missed move d2d4 after reconnecting, got %s", event.Move)
+	}
+
+	joiner.Send("d7d5", 0)
+	if event := nextEvent(t, host, EventMove); event.Move != "d7d5" {
+		t.Errorf("Expected host to receive d7d5, got %s", event.Move)
+	}
+}
+
+func TestDuplicateAndMissingMoves(t *testing.T) {
+	var out bytes.Buffer
+	p := newPeer(false, "black", "")
+	p.enc = json.NewEncoder(&out)
+
+	p.handle(Message{Type: TypeMove, Seq: 1, Move: "e2e4"})
+	p.handle(Message{Type: TypeMove, Seq: 1, Move: "e2e4"}) // resent
+	p.handle(Message{Type: TypeMove, Seq: 3, Move: "g1f3"}) // ply 2 went missing
+
+	if len(p.events) != 1 {
+		t.Errorf("Expected 1 move event, got %d", len(p.events))
+	}
+	if !slices.Equal(p.history, []string{"e2e4"}) {
+		t.Errorf("Expected history [e2e4], got %v", p.history)
+	}
+
+	var replies []Message
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var msg Message
+		decoder.Decode(&msg)
+		replies = append(replies, msg)
+	}
+	if len(replies) != 3 || replies[0].Type != TypeAck || replies[1].Type != TypeAck || replies[2].Type != TypeSync {
+		t.Errorf("Expected ack, ack, sync, got %+v", replies)
+	}
+}
+
+func TestDivergedJoinerTakesHostHistory(t *testing.T) {
+	p := newPeer(false, "black", "")
+	p.history = []string{"e2e4", "e7e5"}
+	p.reconcile(Message{Type: TypeHello, Moves: []string{"e2e4", "c7c5"}, Color: "black"})
+
+	var resync *Event
+	for len(p.events) > 0 {
+		event := <-p.events
+		if event.Kind == EventResync {
+			resync = &event
+		}
+	}
+	if resync == nil || !slices.Equal(resync.Moves, []string{"e2e4", "c7c5"}) {
+		t.Fatalf("Expected a resync to the host's moves, got %+v", resync)
+	}
+	if !slices.Equal(p.history, []string{"e2e4", "c7c5"}) {
+		t.Errorf("Expected the host's history, got %v", p.history)
+	}
+
+	// The host keeps its own history
+	host := newPeer(true, "white", "")
+	host.history = []string{"e2e4", "c7c5"}
+	host.reconcile(Message{Type: TypeHello, Moves: []string{"e2e4", "e7e5"}})
+	if !slices.Equal(host.history, []string{"e2e4", "c7c5"}) {
+		t.Errorf("Expected the host to keep its history, got %v", host.history)
+	}
+}
+
+func TestHintsAreShared(t *testing.T) {
+	host, err := Host("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to host: %v", err)
+	}
+	defer host.Close()
+	host.SetHintBudget(3)
+	joiner, err := Join(host.Addr())
+	if err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	defer joiner.Close()
+	joiner.SetHintBudget(10)
+
+	if event := nextEvent(t, joiner, EventHint); event.Budget != 3 {
+		t.Errorf("Expected the joiner to take the host's budget of 3, got %d", event.Budget)
+	}
+
+	host.SpendHint()
+	if event := nextEvent(t, joiner, EventHint); event.Hints["white"] != 1 {
+		t.Errorf("Expected the host's hint counted, got %v", event.Hints)
+	}
+	joiner.SpendHint()
+	if event := nextEvent(t, host, EventHint); event.Hints["white"] != 1 || event.Hints["black"] != 1 {
+		t.Errorf("Expected a hint each, got %v", event.Hints)
+	}
+}
+
+func TestHintsSpentOfflineCountOnReconnection(t *testing.T) {
+	p := newPeer(true, "white", "")
+	p.hints["white"] = 2
+	p.reconcile(Message{Type: TypeHello, Hints: map[string]int{"white": 1, "black": 2}, Budget: 9})
+
+	if p.hints["white"] != 2 || p.hints["black"] != 2 {
+		t.Errorf("Expected the larger tally for each color, got %v", p.hints)
+	}
+	if p.budget != 0 {
+		t.Errorf("Expected the host to keep its own budget, got %d", p.budget)
+	}
+}
+
+func TestClockIsHostAuthoritative(t *testing.T) {
+	host, joiner := connectedPair(t)
+	host.SetClock(&tournament.TimeControl{PerGame: 5 * time.Minute})
+	if event := nextEvent(t, joiner, EventClock); event.Clock == nil || event.Clock.PerGame != 5*time.Minute {
+		t.Fatalf("Expected the host's clock, got %+v", event.Clock)
+	}
+	joiner.SetClock(&tournament.TimeControl{PerMove: time.Second})
+	if clock := host.Clock(); clock.PerMove != 0 {
+		t.Errorf("Expected the joiner's clock to be ignored, got %+v", clock)
+	}
+
+	host.Send("e2e4", 3*time.Second)
+	if event := nextEvent(t, joiner, EventMove); event.Elapsed != 3*time.Second {
+		t.Errorf("Expected the host's 3s, got %s", event.Elapsed)
+	}
+
+	// A joiner claiming an instant move is charged the time it took
+	time.Sleep(300 * time.Millisecond)
+	joiner.Send("e7e5", 0)
+	counted := nextEvent(t, host, EventMove).Elapsed
+	if counted < 200*time.Millisecond {
+		t.Errorf("Expected the host to count at least 200ms, got %s", counted)
+	}
+	if times := nextEvent(t, joiner, EventClock).Times; len(times) != 2 || times[1] != counted.Truncate(time.Millisecond) {
+		t.Errorf("Expected the joiner to take the host's %s, got %v", counted, times)
+	}
+}
+
+func TestPingMeasuresLatency(t *testing.T) {
+	host, _ := connectedPair(t)
+	deadline := time.Now().Add(5 * time.Second)
+	for host.Latency() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the round trip to be measured")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestResumeToken(t *testing.T) {
+	host, joiner := connectedPair(t)
+	token := joiner.Token()
+	if token == "" || token != host.Token() {
+		t.Fatalf("Expected the joiner to get the host's token, got %q", token)
+	}
+	host.Send("e2e4", 0)
+	nextEvent(t, joiner, EventMove)
+
+	// The joiner's program closes; a stranger can't take the seat
+	joiner.Close()
+	nextEvent(t, host, EventStatus)
+	stranger, err := Join(host.Addr())
+	if err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	defer stranger.Close()
+	if event := nextEvent(t, stranger, EventStatus); !strings.Contains(event.Status, "turned us away") {
+		t.Errorf("Expected the stranger to be turned away, got %q", event.Status)
+	}
+
+	// The player comes back with the token and gets the game back
+	rejoined, err := Resume(host.Addr(), token)
+	if err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+	defer rejoined.Close()
+	waitConnected(t, rejoined)
+	if !slices.Equal(rejoined.History(), []string{"e2e4"}) {
+		t.Errorf("Expected the game's moves on rejoining, got %v", rejoined.History())
+	}
+}
+
+func TestSeatGivenUpAfterGrace(t *testing.T) {
+	host, err := Host("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to host: %v", err)
+	}
+	defer host.Close()
+	host.mu.Lock()
+	host.grace = 100 * time.Millisecond
+	host.mu.Unlock()
+	joiner, err := Join(host.Addr())
+	if err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	waitConnected(t, host)
+	waitConnected(t, joiner)
+	token := joiner.Token()
+	joiner.Close()
+
+	for !nextEvent(t, host, EventStatus).Abandoned {
+	}
+	late, err := Resume(host.Addr(), token)
+	if err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+	defer late.Close()
+	if event := nextEvent(t, late, EventStatus); !strings.Contains(event.Status, "given up") {
+		t.Errorf("Expected the late joiner to be turned away, got %q", event.Status)
+	}
+}
+
+func TestJoinerGivesUpOnHostAfterGrace(t *testing.T) {
+	host, joiner := connectedPair(t)
+	joiner.mu.Lock()
+	joiner.grace = 100 * time.Millisecond
+	joiner.mu.Unlock()
+	host.Close()
+
+	for !nextEvent(t, joiner, EventStatus).Abandoned {
+	}
+	select {
+	case _, ok := <-joiner.Events():
+		if ok {
+			t.Error("Expected the joiner to stop reconnecting once it gave up")
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the joiner's events to end once it gave up")
+	}
+}
+
+func TestSpectatorsFollowTheGameAndAreCounted(t *testing.T) {
+	host, joiner := connectedPair(t)
+	host.Send("e2e4", 0)
+	nextEvent(t, joiner, EventMove)
+
+	spectator, err := Watch(host.Addr())
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+	waitConnected(t, spectator)
+	if !slices.Equal(spectator.History(), []string{"e2e4"}) || spectator.Token() != "" {
+		t.Errorf("Expected the game's moves without its resume token, got %v and %q", spectator.History(), spectator.Token())
+	}
+	if event := nextEvent(t, host, EventPresence); event.Watchers != 1 {
+		t.Errorf("Expected the host to see 1 watching, got %d", event.Watchers)
+	}
+	if event := nextEvent(t, joiner, EventPresence); event.Watchers != 1 {
+		t.Errorf("Expected the joiner to see 1 watching, got %d", event.Watchers)
+	}
+
+	// The spectator replays the moves played before it came first
+	if event := nextEvent(t, spectator, EventMove); event.Move != "e2e4" {
+		t.Errorf("Expected the spectator to replay e2e4, got %s", event.Move)
+	}
+	joiner.Send("e7e5", 0)
+	if event := nextEvent(t, spectator, EventMove); event.Move != "e7e5" {
+		t.Errorf("Expected the spectator to see e7e5, got %s", event.Move)
+	}
+
+	spectator.Close()
+	if event := nextEvent(t, host, EventPresence); event.Watchers != 0 {
+		t.Errorf("Expected no one watching after the spectator left, got %d", event.Watchers)
+	}
+}
+
+func TestPrivateGameNeedsPassword(t *testing.T) {
+	host, err := HostPrivate("127.0.0.1:0", "s3cret")
+	if err != nil {
+		t.Fatalf("Failed to host: %v", err)
+	}
+	defer host.Close()
+
+	// Strangers can neither play nor watch
+	stranger, err := JoinPrivate(host.Addr(), "guess")
+	if err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	defer stranger.Close()
+	if event := nextEvent(t, stranger, EventStatus); !strings.Contains(event.Status, "private") {
+		t.Errorf("Expected a stranger turned away from a private game, got %q", event.Status)
+	}
+	lurker, err := Watch(host.Addr())
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+	defer lurker.Close()
+	if event := nextEvent(t, lurker, EventStatus); !strings.Contains(event.Status, "private") {
+		t.Errorf("Expected a spectator without the password turned away, got %q", event.Status)
+	}
+
+	// Friends with the password can
+	friend, err := JoinPrivate(host.Addr(), "s3cret")
+	if err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	defer friend.Close()
+	waitConnected(t, friend)
+	waitConnected(t, host)
+	spectator, err := WatchPrivate(host.Addr(), "s3cret")
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+	defer spectator.Close()
+	if event := nextEvent(t, host, EventPresence); event.Watchers != 1 {
+		t.Errorf("Expected the friend's spectator let in, got %d watching", event.Watchers)
+	}
+}
+
+// rawSpectator connects to the host with a spectator's hello and returns a
+// reader of the messages sent to it
+func rawSpectator(t *testing.T, host *Peer, delta bool) *json.Decoder {
+	t.Helper()
+	conn, err := net.Dial("tcp", host.Addr())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	json.NewEncoder(conn).Encode(Message{Type: TypeHello, Watch: true, Delta: delta})
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return json.NewDecoder(conn)
+}
+
+// nextMessage reads the next message sent
+func nextMessage(t *testing.T, decoder *json.Decoder) Message {
+	t.Helper()
+	var msg Message
+	if err := decoder.Decode(&msg); err != nil {
+		t.Fatalf("Failed to read a message: %v", err)
+	}
+	return msg
+}
+
+func TestSpectatorsTakeDeltasWithCheckpoints(t *testing.T) {
+	host, joiner := connectedPair(t)
+	host.Send("e2e4", 0)
+	nextEvent(t, joiner, EventMove)
+
+	deltas := rawSpectator(t, host, true)
+	if hello := nextMessage(t, deltas); hello.Type != TypeHello || len(hello.Moves) != 1 || hello.Status != "Both players are connected" {
+		t.Fatalf("Expected the full game first, got %+v", hello)
+	}
+	if presence := nextMessage(t, deltas); presence.Type != TypeDelta || presence.Watchers != 1 {
+		t.Errorf("Expected a delta counting the spectator, got %+v", presence)
+	}
+	legacy := rawSpectator(t, host, false)
+	nextMessage(t, legacy)
+
+	// Each move is one numbered delta, without the game before it
+	for ply := 2; ply <= CheckpointInterval; ply++ {
+		joiner.Send("e7e5", 1500*time.Millisecond)
+		delta := nextMessage(t, deltas)
+		if delta.Watchers == 2 {
+			delta = nextMessage(t, deltas)
+		}
+		if delta.Type != TypeDelta || delta.Seq != ply || delta.Move != "e7e5" || delta.Elapsed < 1500 || delta.Moves != nil {
+			t.Fatalf("Expected ply %d as a delta, got %+v", ply, delta)
+		}
+		if delta.Update == CheckpointInterval {
+			break
+		}
+	}
+	if checkpoint := nextMessage(t, deltas); checkpoint.Type != TypeHello || checkpoint.Update != CheckpointInterval {
+		t.Errorf("Expected a full checkpoint after %d deltas, got %+v", CheckpointInterval, checkpoint)
+	}
+
+	// Spectators that don't ask for deltas still get moves
+	for {
+		msg := nextMessage(t, legacy)
+		if msg.Type == TypeDelta {
+			t.Fatalf("Expected no deltas for a spectator that didn't ask, got %+v", msg)
+		}
+		if msg.Type == TypeMove {
+			break
+		}
+	}
+}
+
+func TestSpectatorAsksOnceForTheGameAfterASkippedDelta(t *testing.T) {
+	var out bytes.Buffer
+	p := newPeer(false, "", "")
+	p.watcher = true
+	p.enc = json.NewEncoder(&out)
+
+	p.handle(Message{Type: TypeDelta, Update: 1, Seq: 1, Move: "e2e4"})
+	p.handle(Message{Type: TypeDelta, Update: 2, Status: "Both players are connected"})
+	p.handle(Message{Type: TypeDelta, Update: 4, Seq: 3, Move: "g1f3"}) // 3 went missing
+	p.handle(Message{Type: TypeDelta, Update: 5, Seq: 4, Move: "b8c6"})
+
+	if !slices.Equal(p.history, []string{"e2e4"}) || p.updates != 2 {
+		t.Errorf("Expected the deltas up to the gap applied, got %v at %d", p.history, p.updates)
+	}
+	if event := nextEvent(t, p, EventMove); event.Move != "e2e4" {
+		t.Errorf("Expected e2e4, got %s", event.Move)
+	}
+	if event := nextEvent(t, p, EventStatus); event.Status != "Watching the game — Both players are connected" {
+		t.Errorf("Expected the players' connection, got %q", event.Status)
+	}
+	if sync := strings.Count(out.String(), `"type":"sync"`); sync != 1 {
+		t.Errorf("Expected one request for the game, got %d in %s", sync, out.String())
+	}
+
+	// The full game catches up, and the deltas it covers are ignored
+	p.handle(Message{Type: TypeHello, Moves: []string{"e2e4", "e7e5", "g1f3", "b8c6"}, Update: 5})
+	p.handle(Message{Type: TypeDelta, Update: 5, Seq: 4, Move: "b8c6"})
+	p.handle(Message{Type: TypeDelta, Update: 6, Seq: 5, Move: "f1b5"})
+	if len(p.history) != 5 || p.syncing {
+		t.Errorf("Expected the game caught up, got %v", p.history)
+	}
+}
@@ -0,0 +1,109 @@
+package netplay
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ResumeGrace is how long the host keeps the seat of a joiner who left,
+// and how long a joiner tries to reach a host that went away
+const ResumeGrace = 2 * time.Minute
+
+// AbortPlies settles a game whose opponent left for good or ran out of time
+// before moving: with fewer moves than this played the game is aborted,
+// without a result, and after that the opponent forfeits
+const AbortPlies = 2
+
+// Resume connects to a host at addr to rejoin a game in progress, with
+// the resume token the host gave when the game started
+func Resume(addr, token string) (*Peer, error) {
+	conn, err := dial(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to host: %w", err)
+	}
+	p := newPeer(false, "black", addr)
+	p.token = token
+	go func() {
+		p.serve(newLink(conn))
+		p.run()
+	}()
+	return p, nil
+}
+
+// Token returns the game's resume token, or "" until the host has sent it
+func (p *Peer) Token() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.token
+}
+
+// newToken returns a random resume token
+func newToken() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// admit reports whether a joiner may take the seat, from its hello: the
+// first to join takes it, with the password if the game is private, and
+// after that only a joiner with the resume token, until the seat is given
+// up. A joiner turned away is told why.
+func (p *Peer) admit(l *link) bool {
+	hello := l.hello
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	reason := ""
+	switch {
+	case p.abandoned:
+		reason = "the game was given up after its player left"
+	case p.seated && subtle.ConstantTimeCompare([]byte(hello.Token), []byte(p.token)) != 1:
+		reason = "the game is taken; rejoin with its resume token"
+	case !p.seated && !p.knows(hello.Password):
+		reason = "the game is private; join with its password"
+	}
+	if reason != "" {
+		json.NewEncoder(l.conn).Encode(Message{Type: TypeReject, Reason: reason})
+		return false
+	}
+	p.seated = true
+	p.leftAt = time.Time{}
+	p.seatChanged()
+	return true
+}
+
+// knows reports whether password lets a joiner or spectator into the
+// game. The password is set when the game is hosted and never changes.
+func (p *Peer) knows(password string) bool {
+	return p.password == "" || subtle.ConstantTimeCompare([]byte(password), []byte(p.password)) == 1
+}
+
+// left notes that the joiner's connection dropped, giving up the seat if
+// the joiner doesn't come back within the grace period
+func (p *Peer) left() {
+	p.mu.Lock()
+	p.leftAt = time.Now()
+	p.drops++
+	p.seatChanged()
+	drop, grace := p.drops, p.grace
+	p.mu.Unlock()
+	time.AfterFunc(grace, func() { p.abandon(drop) })
+}
+
+// abandon gives up the seat, unless the joiner has come back since the
+// drop
+func (p *Peer) abandon(drop int) {
+	p.mu.Lock()
+	if p.drops != drop || p.leftAt.IsZero() || p.abandoned {
+		p.mu.Unlock()
+		return
+	}
+	p.abandoned = true
+	p.seatChanged()
+	grace := p.grace
+	p.mu.Unlock()
+	p.emit(Event{Kind: EventStatus, Status: "Opponent did not reconnect within " + grace.String(), Abandoned: true})
+}
@@ -0,0 +1,226 @@
+package netplay
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// CheckpointInterval is how many deltas the host sends spectators between
+// full copies of the game
+const CheckpointInterval = 20
+
+// spectator is a connection watching the game on the host
+type spectator struct {
+	enc   *json.Encoder
+	delta bool // it takes deltas rather than moves and presence messages
+}
+
+// Watch connects to a host at addr as a spectator, following the game's
+// moves without playing. Like a joiner it reconnects after a drop.
+func Watch(addr string) (*Peer, error) {
+	return WatchPrivate(addr, "")
+}
+
+// WatchPrivate watches a private game like Watch, giving the host password
+func WatchPrivate(addr, password string) (*Peer, error) {
+	conn, err := dial(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to host: %w", err)
+	}
+	p := newPeer(false, "", addr)
+	p.watcher = true
+	p.password = password
+	go func() {
+		p.serve(newLink(conn))
+		p.run()
+	}()
+	return p, nil
+}
+
+// Watchers returns how many spectators are watching the game
+func (p *Peer) Watchers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.watching
+}
+
+// serveWatcher sends the game to a spectator and keeps it up to date until
+// the spectator leaves. Spectators can only ask for the game again after a
+// missed move, and measure the round trip.
+func (p *Peer) serveWatcher(l *link) {
+	enc := json.NewEncoder(l.conn)
+	p.mu.Lock()
+	p.watchers[l.conn] = &spectator{enc: enc, delta: l.hello.Delta}
+	enc.Encode(p.watcherHello())
+	presence := p.presenceChanged()
+	p.mu.Unlock()
+	p.emit(presence)
+
+	for l.scanner.Scan() {
+		var msg Message
+		if json.Unmarshal(l.scanner.Bytes(), &msg) != nil {
+			continue
+		}
+		p.mu.Lock()
+		switch msg.Type {
+		case TypeSync:
+			enc.Encode(p.watcherHello())
+		case TypePing:
+			enc.Encode(Message{Type: TypePong, Ping: msg.Ping})
+		}
+		p.mu.Unlock()
+	}
+
+	l.conn.Close()
+	p.mu.Lock()
+	delete(p.watchers, l.conn)
+	presence = p.presenceChanged()
+	p.mu.Unlock()
+	p.emit(presence)
+}
+
+// watcherHello is the host's hello to a spectator: the game without the
+// joiner's seat or resume token, numbered with the last delta it covers.
+// The caller holds p.mu.
+func (p *Peer) watcherHello() Message {
+	return Message{
+		Type:     TypeHello,
+		Moves:    slices.Clone(p.history),
+		Times:    milliseconds(p.times),
+		Clock:    p.clock,
+		Watchers: p.watching,
+		Update:   p.updates,
+		Status:   p.seatStatus(),
+	}
+}
+
+// seatStatus describes the players' connection for spectators. The caller
+// holds p.mu.
+func (p *Peer) seatStatus() string {
+	switch {
+	case p.abandoned:
+		return "Black did not reconnect within " + p.grace.String()
+	case !p.leftAt.IsZero():
+		return fmt.Sprintf("Black's connection dropped; the seat is kept for %s", p.grace)
+	case p.seated:
+		return "Both players are connected"
+	}
+	return "Waiting for Black to join"
+}
+
+// forward sends the moves from ply from on to the spectators. The caller
+// holds p.mu.
+func (p *Peer) forward(from int) {
+	if !p.host {
+		return
+	}
+	for seq := from + 1; seq <= len(p.history); seq++ {
+		move := Message{Seq: seq, Move: p.history[seq-1], Elapsed: p.times[seq-1].Milliseconds()}
+		legacy := move
+		legacy.Type = TypeMove
+		p.broadcast(move, legacy)
+	}
+}
+
+// broadcast sends a change to every spectator: as the next delta to those
+// that take deltas, followed by the full game every CheckpointInterval
+// deltas, and as legacy to the others, unless legacy has no type. The
+// caller holds p.mu.
+func (p *Peer) broadcast(delta, legacy Message) {
+	if !p.host {
+		return
+	}
+	p.updates++
+	delta.Type, delta.Update = TypeDelta, p.updates
+	checkpoint := p.updates%CheckpointInterval == 0
+	for conn, s := range p.watchers {
+		var err error
+		switch {
+		case s.delta:
+			err = s.enc.Encode(delta)
+			if err == nil && checkpoint {
+				err = s.enc.Encode(p.watcherHello())
+			}
+		case legacy.Type != "":
+			err = s.enc.Encode(legacy)
+		}
+		if err != nil {
+			// serveWatcher notices and lets the spectator go
+			conn.Close()
+		}
+	}
+}
+
+// seatChanged tells the spectators that the players' connection changed.
+// The caller holds p.mu.
+func (p *Peer) seatChanged() {
+	p.broadcast(Message{Status: p.seatStatus()}, Message{})
+}
+
+// presenceChanged tells the joiner and the spectators how many are
+// watching, returning the event that shows it to the host. The caller
+// holds p.mu.
+func (p *Peer) presenceChanged() Event {
+	p.watching = len(p.watchers)
+	presence := Message{Type: TypePresence, Watchers: p.watching}
+	p.write(presence)
+	p.broadcast(Message{Watchers: p.watching}, presence)
+	return Event{Kind: EventPresence, Watchers: p.watching}
+}
+
+// applyDelta applies the host's next delta to a spectator's game. After a
+// skipped delta, or a move that doesn't follow on, the spectator asks for
+// the full game once and ignores the deltas until it comes.
+func (p *Peer) applyDelta(delta Message) {
+	p.mu.Lock()
+	if delta.Update <= p.updates {
+		// Already covered by the full game
+		p.mu.Unlock()
+		return
+	}
+	if delta.Update != p.updates+1 || (delta.Move != "" && delta.Seq != len(p.history)+1) {
+		if !p.syncing {
+			p.syncing = true
+			p.write(Message{Type: TypeSync})
+		}
+		p.mu.Unlock()
+		return
+	}
+	p.updates = delta.Update
+
+	var events []Event
+	if delta.Move != "" {
+		elapsed := time.Duration(delta.Elapsed) * time.Millisecond
+		p.history = append(p.history, delta.Move)
+		p.times = append(p.times, elapsed)
+		p.acked = len(p.history)
+		events = append(events, Event{Kind: EventMove, Move: delta.Move, Elapsed: elapsed})
+	}
+	if delta.Clock != nil {
+		p.clock = delta.Clock
+		events = append(events, p.clockEvent())
+	}
+	if delta.Watchers > 0 && delta.Watchers != p.watching {
+		p.watching = delta.Watchers
+		events = append(events, Event{Kind: EventPresence, Watchers: delta.Watchers})
+	}
+	if delta.Status != "" {
+		events = append(events, Event{Kind: EventStatus, Status: watchingStatus(delta.Status), Connected: true})
+	}
+	p.mu.Unlock()
+
+	for _, event := range events {
+		p.emit(event)
+	}
+}
+
+// watchingStatus is a spectator's status line, with the players'
+// connection when the host sent it
+func watchingStatus(seat string) string {
+	if seat == "" {
+		return "Watching the game"
+	}
+	return "Watching the game — " + seat
+}
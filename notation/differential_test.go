@@ -0,0 +1,54 @@
+package notation
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+// TestDifferentialDecode plays seeded random games with notnil/chess as the
+// reference and checks that every legal move round-trips through Decode in
+// SAN and UCI, and that from-to pairs the reference rejects are refused
+func TestDifferentialDecode(t *testing.T) {
+	rng := rand.New(rand.NewSource(4932))
+
+	for i := 0; i < 6; i++ {
+		game := chess.NewGame()
+		for ply := 0; ply < 120 && game.Outcome() == chess.NoOutcome; ply++ {
+			position := game.Position()
+			legal := make(map[string]bool)
+
+			for _, move := range position.ValidMoves() {
+				san := Encode(position, move)
+				uci := EncodeUCI(move)
+				legal[uci] = true
+
+				for _, text := range []string{san, uci} {
+					decoded, err := Decode(position, text)
+					if err != nil {
+						t.Fatalf("Decode rejected legal move %s in %s: %v", text, position, err)
+					}
+					if EncodeUCI(decoded) != uci {
+						t.Fatalf("Decode(%q) = %s, expected %s in %s", text, EncodeUCI(decoded), uci, position)
+					}
+				}
+			}
+
+			for j := 0; j < 16; j++ {
+				uci := chess.Square(rng.Intn(64)).String() + chess.Square(rng.Intn(64)).String()
+				if legal[uci] || legal[uci+"q"] {
+					continue
+				}
+				if _, err := Decode(position, uci); err == nil {
+					t.Fatalf("Decode accepted illegal move %s in %s", uci, position)
+				}
+			}
+
+			moves := position.ValidMoves()
+			if err := game.Move(moves[rng.Intn(len(moves))]); err != nil {
+				t.Fatalf("Reference rejected its own move: %v", err)
+			}
+		}
+	}
+}
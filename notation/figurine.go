@@ -0,0 +1,117 @@
+package notation
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/notnil/chess"
+)
+
+// whiteFigurines maps SAN piece letters to White piece glyphs
+var whiteFigurines = map[rune]string{
+	'K': "♔",
+	'Q': "♕",
+	'R': "♖",
+	'B': "♗",
+	'N': "♘",
+}
+
+// blackFigurines maps SAN piece letters to Black piece glyphs
+var blackFigurines = map[rune]string{
+	'K': "♚",
+	'Q': "♛",
+	'R': "♜",
+	'B': "♝",
+	'N': "♞",
+}
+
+// Figurine converts a SAN move to figurine algebraic notation, e.g. Nf3 to ♘f3
+// and e8=Q to e8=♕. The glyph set follows the moving side's color.
+// Castling and pawn moves are left unchanged apart from promotions.
+func Figurine(san string, color chess.Color) string {
+	glyphs := whiteFigurines
+	if color == chess.Black {
+		glyphs = blackFigurines
+	}
+
+	if strings.HasPrefix(san, "O-O") || strings.HasPrefix(san, "0-0") {
+		return san
+	}
+
+	var sb strings.Builder
+	for i, r := range san {
+		// Piece letters appear as the first character or after a promotion sign
+		if glyph, ok := glyphs[r]; ok && (i == 0 || san[i-1] == '=') {
+			sb.WriteString(glyph)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// FigurineText converts every SAN move token in a free-form text, such as a
+// PGN movetext or an AI commentary line, to figurine notation, leaving the
+// spacing and punctuation around the moves as they are. The first move is
+// color's; move numbers like "12." switch the color: "12." is followed by
+// White and "12..." by Black.
+func FigurineText(text string, color chess.Color) string {
+	var sb strings.Builder
+	for len(text) > 0 {
+		// Copy the spacing, then take the next word
+		end := strings.IndexFunc(text, isNotSpace)
+		if end < 0 {
+			end = len(text)
+		}
+		sb.WriteString(text[:end])
+		text = text[end:]
+		end = strings.IndexFunc(text, unicode.IsSpace)
+		if end < 0 {
+			end = len(text)
+		}
+		word := text[:end]
+		text = text[end:]
+
+		// Moves may be quoted, bracketed or end a sentence
+		core := strings.TrimLeft(word, `("'`)
+		prefix := word[:len(word)-len(core)]
+		switch {
+		case strings.HasSuffix(core, "...") && isMoveNumber(strings.TrimSuffix(core, "...")):
+			color = chess.Black
+		case strings.HasSuffix(core, ".") && isMoveNumber(strings.TrimSuffix(core, ".")):
+			color = chess.White
+		default:
+			move := strings.TrimRight(core, `,.;:)"'`)
+			if isSANToken(move) {
+				word = prefix + Figurine(move, color) + core[len(move):]
+				color = color.Other()
+			}
+		}
+		sb.WriteString(word)
+	}
+	return sb.String()
+}
+
+// isNotSpace reports whether r is not white space
+func isNotSpace(r rune) bool {
+	return !unicode.IsSpace(r)
+}
+
+// isMoveNumber reports whether s is a move number such as "12"
+func isMoveNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isSANToken reports whether a token is a SAN move rather than prose
+func isSANToken(s string) bool {
+	move, err := Parse(s)
+	return err == nil && !move.UCI
+}
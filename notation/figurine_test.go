@@ -0,0 +1,49 @@
+package notation
+
+import (
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestFigurine(t *testing.T) {
+	tests := []struct {
+		san      string
+		color    chess.Color
+		expected string
+	}{
+		{"Nf3", chess.White, "♘f3"},
+		{"Nbd7", chess.Black, "♞bd7"},
+		{"exd5", chess.White, "exd5"},
+		{"e8=Q+", chess.White, "e8=♕+"},
+		{"Qxh7#", chess.Black, "♛xh7#"},
+		{"O-O-O", chess.White, "O-O-O"},
+	}
+
+	for _, tt := range tests {
+		if got := Figurine(tt.san, tt.color); got != tt.expected {
+			t.Errorf("Figurine(%q) = %q, expected %q", tt.san, got, tt.expected)
+		}
+	}
+}
+
+func TestFigurineText(t *testing.T) {
+	got := FigurineText("1. e4 e5 2. Nf3 Nc6 3. Bb5", chess.White)
+	expected := "1. e4 e5 2. ♘f3 ♞c6 3. ♗b5"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	got = FigurineText("After 12... Bxe4 the Knight is lost", chess.White)
+	expected = "After 12... ♝xe4 the Knight is lost"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	// Prose keeps its spacing and punctuation; the first move is color's
+	got = FigurineText("Nf6 defends.\nIf (Bg5, then) Qe7.", chess.Black)
+	expected = "♞f6 defends.\nIf (♗g5, then) ♛e7."
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
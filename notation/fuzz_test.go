@@ -0,0 +1,59 @@
+package notation
+
+import "testing"
+
+// moveSeeds are tricky replies seen from models: disambiguation, promotion
+// suffixes, check markers, castling spellings and near misses
+var moveSeeds = []string{
+	"e4", "Nf3", "Nbd7", "R1e2", "Qh4xe1", "exd8=Q+", "e8=N#", "e7e8q",
+	"O-O", "0-0-0", "O-O-O+", "Kxe5!", "", "=", "+", "e8=", "a", "x",
+	"♘f3", "e2-e4", "e8Q", "b7b8",
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range moveSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		move, err := Parse(text)
+		if err != nil {
+			return
+		}
+		if move.Castle == "" && (move.To < 0 || move.To > 63) {
+			t.Errorf("Parse(%q) returned an invalid square %d", text, move.To)
+		}
+	})
+}
+
+func FuzzNormalize(f *testing.F) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1",                                  // promotion
+		"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",                             // castling both ways
+		"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3", // two knights
+		"not a fen",
+	}
+	for _, fen := range fens {
+		for _, seed := range moveSeeds {
+			f.Add(fen, seed)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, fen, text string) {
+		san, err := Normalize(fen, text)
+		if err != nil {
+			return
+		}
+		legal, err := LegalMoves(fen)
+		if err != nil {
+			t.Fatalf("Normalize accepted %q in an unparseable FEN %q", text, fen)
+		}
+		for _, move := range legal {
+			if move == san {
+				return
+			}
+		}
+		t.Errorf("Normalize(%q, %q) = %q, which is not legal", fen, text, san)
+	})
+}
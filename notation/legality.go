@@ -0,0 +1,434 @@
+package notation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/notnil/chess"
+)
+
+// Reason says why a move was refused, for programs to act on
+type Reason string
+
+// Reasons a move is refused
+const (
+	ReasonSyntax      Reason = "syntax"        // not a move at all
+	ReasonGameOver    Reason = "game_over"     // no moves are left
+	ReasonWrongTurn   Reason = "wrong_turn"    // only the opponent could make it
+	ReasonNoSuchPiece Reason = "no_such_piece" // the mover has no such piece
+	ReasonUnreachable Reason = "unreachable"   // the piece doesn't move that way
+	ReasonBlocked     Reason = "blocked"       // another piece is in the way
+	ReasonOccupied    Reason = "occupied"      // the mover's own piece is on the square
+	ReasonKingInCheck Reason = "king_in_check" // it would leave the king in check
+	ReasonCastling    Reason = "castling"      // the castling rights are gone
+	ReasonPromotion   Reason = "promotion"     // the promotion piece is missing or misplaced
+	ReasonAmbiguous   Reason = "ambiguous"     // more than one piece can make it
+	ReasonMarker      Reason = "marker"        // a capture, check or mate marker is wrong
+	ReasonDrop        Reason = "drop"          // a piece dropped from the reserve, outside Crazyhouse
+)
+
+// MoveError is the error Decode returns for a move it refuses. It wraps the
+// ErrSyntax, ErrIllegal, ErrAmbiguous or ErrPromotionMissing error it
+// replaces, so errors.Is still works, and explains the refusal with a
+// Reason and a message for players.
+type MoveError struct {
+	Move    string // the move as given
+	Reason  Reason
+	Message string // friendly explanation, e.g. "the bishop on c1 is blocked by the pawn on d2"
+	err     error
+}
+
+// Error returns the underlying error's text
+func (e *MoveError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *MoveError) Unwrap() error {
+	return e.err
+}
+
+// Explain returns the friendly message of a refused move, or err's text for
+// other errors
+func Explain(err error) string {
+	var moveErr *MoveError
+	if errors.As(err, &moveErr) {
+		return moveErr.Message
+	}
+	return err.Error()
+}
+
+// refuse builds the MoveError for text
+func refuse(text string, reason Reason, err error, format string, args ...any) *MoveError {
+	return &MoveError{Move: text, Reason: reason, Message: fmt.Sprintf(format, args...), err: err}
+}
+
+// pieceNames are the names messages use for pieces
+var pieceNames = map[chess.PieceType]string{
+	chess.King:   "king",
+	chess.Queen:  "queen",
+	chess.Rook:   "rook",
+	chess.Bishop: "bishop",
+	chess.Knight: "knight",
+	chess.Pawn:   "pawn",
+}
+
+// pieceMoves says how each piece moves, for unreachable squares
+var pieceMoves = map[chess.PieceType]string{
+	chess.King:   "kings move one square at a time",
+	chess.Queen:  "queens move along ranks, files and diagonals",
+	chess.Rook:   "rooks move along ranks and files",
+	chess.Bishop: "bishops move diagonally",
+	chess.Knight: "knights jump in an L shape",
+	chess.Pawn:   "pawns move straight ahead and capture diagonally",
+}
+
+// explainIllegal works out why the parsed move matches no legal move in
+// position
+func explainIllegal(position *chess.Position, parsed Move, text string) *MoveError {
+	illegal := fmt.Errorf("%w: %s", ErrIllegal, text)
+	if position.Status() != chess.NoMethod {
+		return refuse(text, ReasonGameOver, illegal, "the game is over")
+	}
+
+	board, turn := position.Board(), position.Turn()
+	if parsed.Castle != "" {
+		to := chess.NewSquare(chess.FileG, homeKingSquare(turn).Rank())
+		if parsed.Castle == CastleQueenside {
+			to = chess.NewSquare(chess.FileC, homeKingSquare(turn).Rank())
+		}
+		reason, message := castlingReason(position, homeKingSquare(turn), turn, to)
+		return refuse(text, reason, illegal, "%s", message)
+	}
+
+	if parsed.UCI {
+		from := chess.NewSquare(chess.File(parsed.FromFile), chess.Rank(parsed.FromRank))
+		piece := board.Piece(from)
+		switch {
+		case piece == chess.NoPiece:
+			return refuse(text, ReasonNoSuchPiece, illegal, "there is no piece on %s", from)
+		case piece.Color() != turn:
+			return refuse(text, ReasonWrongTurn, illegal, "the %s on %s is %s's, and it's %s to move", pieceNames[piece.Type()], from, colorName(piece.Color()), colorName(turn))
+		}
+		reason, message := explainPieceMove(position, from, parsed)
+		return refuse(text, reason, illegal, "%s", message)
+	}
+
+	// The mover's pieces of the kind named that fit the disambiguation. A
+	// pawn push names no file, but comes from the destination's.
+	if parsed.Piece == chess.Pawn && !parsed.Capture {
+		parsed.FromFile = int(parsed.To.File())
+	}
+	var candidates []chess.Square
+	for square, piece := range board.SquareMap() {
+		if piece.Type() == parsed.Piece && piece.Color() == turn && parsed.fromFits(square) {
+			candidates = append(candidates, square)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+	// Report the most telling reason any candidate fails for
+	best, message := Reason(""), ""
+	for _, from := range candidates {
+		reason, msg := explainPieceMove(position, from, parsed)
+		if best == "" || reasonRank[reason] > reasonRank[best] {
+			best, message = reason, msg
+		}
+	}
+
+	// A move none of the mover's pieces could make may be the opponent's
+	if best == "" || best == ReasonUnreachable {
+		for square, piece := range board.SquareMap() {
+			if piece.Type() == parsed.Piece && piece.Color() != turn && parsed.fromFits(square) {
+				if reason, _ := Why(position, square, parsed.To); reason == "" {
+					return refuse(text, ReasonWrongTurn, illegal, "only %s's %s can go to %s, and it's %s to move", colorName(piece.Color()), pieceNames[piece.Type()], parsed.To, colorName(turn))
+				}
+			}
+		}
+	}
+	if best == "" {
+		return refuse(text, ReasonNoSuchPiece, illegal, "you have no %s%s", pieceNames[parsed.Piece], parsed.fromDescription())
+	}
+	return refuse(text, best, illegal, "%s", message)
+}
+
+// reasonRank orders the reasons a candidate piece fails for, most telling
+// last
+var reasonRank = map[Reason]int{
+	ReasonUnreachable: 1,
+	ReasonOccupied:    2,
+	ReasonBlocked:     3,
+	ReasonCastling:    4,
+	ReasonPromotion:   5,
+	ReasonKingInCheck: 6,
+}
+
+// explainPieceMove says why the mover's piece on from can't make the parsed
+// move
+func explainPieceMove(position *chess.Position, from chess.Square, parsed Move) (Reason, string) {
+	if reason, message := Why(position, from, parsed.To); reason != "" {
+		return reason, message
+	}
+	// The piece can get there, so only the promotion piece can be wrong
+	return ReasonPromotion, "only a pawn reaching the last rank can promote"
+}
+
+// Why explains why the piece on from can't move to to in position, as if it
+// were its side's turn, or returns "" if it can
+func Why(position *chess.Position, from, to chess.Square) (Reason, string) {
+	board := position.Board()
+	piece := board.Piece(from)
+	if piece == chess.NoPiece {
+		return ReasonNoSuchPiece, fmt.Sprintf("there is no piece on %s", from)
+	}
+	name := pieceNames[piece.Type()]
+	if piece.Type() == chess.King && from == homeKingSquare(piece.Color()) && from.Rank() == to.Rank() && abs(int(to.File())-int(from.File())) == 2 {
+		return castlingReason(position, from, piece.Color(), to)
+	}
+
+	occupant := board.Piece(to)
+	switch {
+	case occupant != chess.NoPiece && occupant.Color() == piece.Color():
+		return ReasonOccupied, fmt.Sprintf("your own %s is on %s", pieceNames[occupant.Type()], to)
+	case !shapeFits(piece, from, to):
+		return ReasonUnreachable, fmt.Sprintf("the %s on %s can't reach %s: %s", name, from, to, pieceMoves[piece.Type()])
+	case piece.Type() == chess.Pawn && to.File() == from.File() && occupant != chess.NoPiece:
+		return ReasonBlocked, fmt.Sprintf("pawns can't capture straight ahead, and the %s on %s is in the way", pieceNames[occupant.Type()], to)
+	case piece.Type() == chess.Pawn && to.File() != from.File() && occupant == chess.NoPiece && to != position.EnPassantSquare():
+		return ReasonUnreachable, "pawns move diagonally only to capture"
+	}
+	if blocker, ok := firstBlocker(board, from, to); ok {
+		return ReasonBlocked, fmt.Sprintf("the %s on %s is blocked by the %s on %s", name, from, pieceNames[board.Piece(blocker).Type()], blocker)
+	}
+
+	// The move fits the piece's pattern, so it must leave the king in check
+	after := board.SquareMap()
+	delete(after, from)
+	after[to] = piece
+	if piece.Type() == chess.Pawn && to == position.EnPassantSquare() {
+		delete(after, chess.NewSquare(to.File(), from.Rank()))
+	}
+	checkers := attackers(after, kingSquare(after, piece.Color()), piece.Color().Other())
+	if len(checkers) == 0 {
+		return "", ""
+	}
+	attacker := fmt.Sprintf("the %s on %s", pieceNames[after[checkers[0]].Type()], checkers[0])
+	switch {
+	case piece.Type() == chess.King:
+		return ReasonKingInCheck, fmt.Sprintf("%s is attacked by %s", to, attacker)
+	case len(attackers(board.SquareMap(), kingSquare(board.SquareMap(), piece.Color()), piece.Color().Other())) > 0:
+		return ReasonKingInCheck, fmt.Sprintf("your king is in check, and this doesn't stop %s", attacker)
+	default:
+		return ReasonKingInCheck, fmt.Sprintf("the %s on %s is pinned: moving it would expose your king to %s", name, from, attacker)
+	}
+}
+
+// castlingReason explains why color's king on from can't castle to to, or
+// returns "" if it can
+func castlingReason(position *chess.Position, from chess.Square, color chess.Color, to chess.Square) (Reason, string) {
+	side, rookFile := chess.KingSide, chess.FileH
+	if to.File() < from.File() {
+		side, rookFile = chess.QueenSide, chess.FileA
+	}
+	if !position.CastleRights().CanCastle(color, side) {
+		return ReasonCastling, "castling rights are gone, because the king or that rook has moved"
+	}
+
+	board := position.Board().SquareMap()
+	step := sign(int(rookFile) - int(from.File()))
+	for f := int(from.File()) + step; f != int(rookFile); f += step {
+		sq := chess.NewSquare(chess.File(f), from.Rank())
+		if occupant, ok := board[sq]; ok {
+			return ReasonBlocked, fmt.Sprintf("can't castle: the %s on %s is in the way", pieceNames[occupant.Type()], sq)
+		}
+	}
+
+	enemy := color.Other()
+	if checkers := attackers(board, from, enemy); len(checkers) > 0 {
+		return ReasonKingInCheck, fmt.Sprintf("can't castle out of check from the %s on %s", pieceNames[board[checkers[0]].Type()], checkers[0])
+	}
+	for f := int(from.File()) + step; f != int(to.File())+step; f += step {
+		sq := chess.NewSquare(chess.File(f), from.Rank())
+		if checkers := attackers(board, sq, enemy); len(checkers) > 0 {
+			return ReasonKingInCheck, fmt.Sprintf("can't castle through or into check: %s is attacked by the %s on %s",
+				sq, pieceNames[board[checkers[0]].Type()], checkers[0])
+		}
+	}
+	return "", ""
+}
+
+// shapeFits reports whether piece moves from from to to on an empty board,
+// counting a pawn's diagonal step as a capture
+func shapeFits(piece chess.Piece, from, to chess.Square) bool {
+	df := abs(int(to.File()) - int(from.File()))
+	dr := abs(int(to.Rank()) - int(from.Rank()))
+	if df == 0 && dr == 0 {
+		return false
+	}
+
+	switch piece.Type() {
+	case chess.King:
+		return df <= 1 && dr <= 1
+	case chess.Knight:
+		return df*dr == 2
+	case chess.Bishop:
+		return df == dr
+	case chess.Rook:
+		return df == 0 || dr == 0
+	case chess.Queen:
+		return df == 0 || dr == 0 || df == dr
+	case chess.Pawn:
+		advance := pawnAdvance(piece.Color(), from, to)
+		if df == 0 {
+			start := (piece.Color() == chess.White && from.Rank() == chess.Rank2) ||
+				(piece.Color() == chess.Black && from.Rank() == chess.Rank7)
+			return advance == 1 || advance == 2 && start
+		}
+		return df == 1 && advance == 1
+	}
+	return false
+}
+
+// firstBlocker returns the first occupied square strictly between from and
+// to, for moves along a line
+func firstBlocker(board *chess.Board, from, to chess.Square) (chess.Square, bool) {
+	df := int(to.File()) - int(from.File())
+	dr := int(to.Rank()) - int(from.Rank())
+	if !(df == 0 || dr == 0 || abs(df) == abs(dr)) {
+		return chess.NoSquare, false
+	}
+	for i := 1; i < max(abs(df), abs(dr)); i++ {
+		sq := chess.NewSquare(chess.File(int(from.File())+i*sign(df)), chess.Rank(int(from.Rank())+i*sign(dr)))
+		if board.Piece(sq) != chess.NoPiece {
+			return sq, true
+		}
+	}
+	return chess.NoSquare, false
+}
+
+// homeKingSquare returns the square a side's king starts on
+func homeKingSquare(color chess.Color) chess.Square {
+	if color == chess.Black {
+		return chess.E8
+	}
+	return chess.E1
+}
+
+// kingSquare returns where a side's king is
+func kingSquare(board map[chess.Square]chess.Piece, color chess.Color) chess.Square {
+	for sq, piece := range board {
+		if piece.Type() == chess.King && piece.Color() == color {
+			return sq
+		}
+	}
+	return chess.NoSquare
+}
+
+// attackers returns the squares of by's pieces that attack target, in square order
+func attackers(board map[chess.Square]chess.Piece, target chess.Square, by chess.Color) []chess.Square {
+	if target == chess.NoSquare {
+		return nil
+	}
+	var found []chess.Square
+	for sq := chess.A1; sq <= chess.H8; sq++ {
+		piece, ok := board[sq]
+		if ok && piece.Color() == by && attacks(board, sq, piece, target) {
+			found = append(found, sq)
+		}
+	}
+	return found
+}
+
+// attacks reports whether the piece on from attacks target
+func attacks(board map[chess.Square]chess.Piece, from chess.Square, piece chess.Piece, target chess.Square) bool {
+	df := int(target.File()) - int(from.File())
+	dr := int(target.Rank()) - int(from.Rank())
+	if df == 0 && dr == 0 {
+		return false
+	}
+
+	switch piece.Type() {
+	case chess.Pawn:
+		return pawnAdvance(piece.Color(), from, target) == 1 && abs(df) == 1
+	case chess.Knight:
+		return abs(df)*abs(dr) == 2
+	case chess.King:
+		return max(abs(df), abs(dr)) == 1
+	case chess.Bishop:
+		return abs(df) == abs(dr) && clearLine(board, from, df, dr)
+	case chess.Rook:
+		return (df == 0 || dr == 0) && clearLine(board, from, df, dr)
+	case chess.Queen:
+		return (abs(df) == abs(dr) || df == 0 || dr == 0) && clearLine(board, from, df, dr)
+	}
+	return false
+}
+
+// clearLine reports whether the squares strictly between from and from+(df, dr) are empty
+func clearLine(board map[chess.Square]chess.Piece, from chess.Square, df, dr int) bool {
+	steps := max(abs(df), abs(dr))
+	stepFile, stepRank := sign(df), sign(dr)
+	for i := 1; i < steps; i++ {
+		sq := chess.NewSquare(chess.File(int(from.File())+i*stepFile), chess.Rank(int(from.Rank())+i*stepRank))
+		if _, ok := board[sq]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// pawnAdvance is how many ranks a pawn of color moving from from to to goes
+// forward
+func pawnAdvance(color chess.Color, from, to chess.Square) int {
+	if color == chess.White {
+		return int(to.Rank()) - int(from.Rank())
+	}
+	return int(from.Rank()) - int(to.Rank())
+}
+
+// fromFits reports whether square fits the move's disambiguation
+func (m Move) fromFits(square chess.Square) bool {
+	return (m.FromFile == noSquare || int(square.File()) == m.FromFile) &&
+		(m.FromRank == noSquare || int(square.Rank()) == m.FromRank)
+}
+
+// fromDescription describes the move's disambiguation, e.g. " on the b file"
+func (m Move) fromDescription() string {
+	switch {
+	case m.FromFile != noSquare && m.FromRank != noSquare:
+		return fmt.Sprintf(" on %s", chess.NewSquare(chess.File(m.FromFile), chess.Rank(m.FromRank)))
+	case m.FromFile != noSquare:
+		return fmt.Sprintf(" on the %c file", 'a'+m.FromFile)
+	case m.FromRank != noSquare:
+		return fmt.Sprintf(" on rank %d", m.FromRank+1)
+	}
+	return ""
+}
+
+// colorName names a side
+func colorName(color chess.Color) string {
+	if color == chess.White {
+		return "White"
+	}
+	return "Black"
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// sign returns -1, 0 or 1 as n is negative, zero or positive
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package notation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeExplainsRefusals(t *testing.T) {
+	const (
+		start    = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+		knights  = "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1"
+		pinned   = "4k3/4r3/8/8/8/8/4B3/4K3 w - - 0 1"
+		checked  = "4k3/4r3/8/8/8/8/3B4/4K3 w - - 0 1"
+		guarded  = "4k3/3r4/8/8/8/8/8/4K3 w - - 0 1"
+		noRights = "r3k2r/8/8/8/8/8/8/R3K2R w Kq - 0 1"
+		through  = "r3k2r/8/8/8/8/8/5r2/R3K2R w KQkq - 0 1"
+		mated    = "R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1"
+	)
+
+	tests := []struct {
+		fen, text string
+		reason    Reason
+		message   string
+	}{
+		{start, "Nf9", ReasonSyntax, "isn't a move"},
+		{start, "Bc4", ReasonBlocked, "the bishop on f1 is blocked by the pawn on e2"},
+		{start, "Qd3", ReasonBlocked, "the queen on d1 is blocked by the pawn on d2"},
+		{start, "Nh4", ReasonUnreachable, "can't reach h4: knights jump in an L shape"},
+		{start, "e5", ReasonWrongTurn, "only Black's pawn can go to e5"},
+		{start, "exd3", ReasonUnreachable, "pawns move diagonally only to capture"},
+		{start, "Ke2", ReasonOccupied, "your own pawn is on e2"},
+		{start, "Nf6", ReasonWrongTurn, "only Black's knight can go to f6, and it's White to move"},
+		{start, "e7e5", ReasonWrongTurn, "the pawn on e7 is Black's, and it's White to move"},
+		{start, "e3e4", ReasonNoSuchPiece, "there is no piece on e3"},
+		{knights, "Bd3", ReasonNoSuchPiece, "you have no bishop"},
+		{knights, "Nad3", ReasonNoSuchPiece, "you have no knight on the a file"},
+		{start, "e1g1", ReasonBlocked, "the bishop on f1 is in the way"},
+		{pinned, "Bd3", ReasonKingInCheck, "the bishop on e2 is pinned: moving it would expose your king to the rook on e7"},
+		{checked, "Bc3", ReasonKingInCheck, "your king is in check, and this doesn't stop the rook on e7"},
+		{guarded, "Kd1", ReasonKingInCheck, "d1 is attacked by the rook on d7"},
+		{noRights, "O-O-O", ReasonCastling, "castling rights are gone"},
+		{through, "O-O", ReasonKingInCheck, "through or into check: f1 is attacked by the rook on f2"},
+		{mated, "Kh8", ReasonGameOver, "the game is over"},
+		{start, "Nxf3", ReasonMarker, "nothing to capture on f3"},
+		{start, "N@f3", ReasonDrop, "only played in Crazyhouse"},
+		{start, "e4+", ReasonMarker, "doesn't give check"},
+		{knights, "Nd2", ReasonAmbiguous, "e.g. Nbd2"},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8", ReasonPromotion, "b8=Q"},
+	}
+
+	for _, tt := range tests {
+		_, err := Decode(position(t, tt.fen), tt.text)
+		var moveErr *MoveError
+		if !errors.As(err, &moveErr) {
+			t.Errorf("%s: expected a MoveError, got %v", tt.text, err)
+			continue
+		}
+		if moveErr.Reason != tt.reason {
+			t.Errorf("%s: expected reason %s, got %s (%s)", tt.text, tt.reason, moveErr.Reason, moveErr.Message)
+		}
+		if !strings.Contains(moveErr.Message, tt.message) || Explain(err) != moveErr.Message {
+			t.Errorf("%s: expected a message containing %q, got %q", tt.text, tt.message, moveErr.Message)
+		}
+	}
+}
+
+func TestMoveErrorKeepsSentinels(t *testing.T) {
+	_, err := Decode(position(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"), "Bc4")
+	if !errors.Is(err, ErrIllegal) || err.Error() != "illegal move: Bc4" {
+		t.Errorf("Expected the illegal move error, got %v", err)
+	}
+	if Explain(errors.New("disk full")) != "disk full" {
+		t.Errorf("Expected other errors explained by their text")
+	}
+}
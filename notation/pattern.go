@@ -0,0 +1,31 @@
+package notation
+
+import "github.com/notnil/chess"
+
+// pieceRules says in a sentence how each piece moves, for beginners
+var pieceRules = map[chess.PieceType]string{
+	chess.King:   "The king moves one square in any direction, or castles two squares towards a rook, never into check.",
+	chess.Queen:  "The queen moves any number of squares along a rank, file or diagonal, but can't jump over pieces.",
+	chess.Rook:   "The rook moves any number of squares along a rank or file, but can't jump over pieces.",
+	chess.Bishop: "The bishop moves any number of squares diagonally, staying on its color, but can't jump over pieces.",
+	chess.Knight: "The knight jumps in an L, two squares one way and one to the side, over any pieces in between.",
+	chess.Pawn:   "The pawn moves one square forward (two from its starting square) and captures one square diagonally forward.",
+}
+
+// Rule describes how a piece type moves in one sentence
+func Rule(pieceType chess.PieceType) string {
+	return pieceRules[pieceType]
+}
+
+// Pattern returns the squares piece moves to from from on an empty board:
+// the shape of its moves, whatever is in the way. A pawn's diagonal
+// captures are included.
+func Pattern(piece chess.Piece, from chess.Square) []chess.Square {
+	var squares []chess.Square
+	for to := chess.A1; to <= chess.H8; to++ {
+		if shapeFits(piece, from, to) {
+			squares = append(squares, to)
+		}
+	}
+	return squares
+}
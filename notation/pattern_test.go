@@ -0,0 +1,31 @@
+package notation
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestPatternIgnoresBlockers(t *testing.T) {
+	// A rook in the corner reaches its whole rank and file, blocked or not
+	if got := Pattern(chess.WhiteRook, chess.A1); len(got) != 14 {
+		t.Errorf("Expected 14 squares for a rook on a1, got %d: %v", len(got), got)
+	}
+	knight := Pattern(chess.WhiteKnight, chess.G1)
+	if !slices.Equal(knight, []chess.Square{chess.E2, chess.F3, chess.H3}) {
+		t.Errorf("Expected e2, f3 and h3 for a knight on g1, got %v", knight)
+	}
+	pawn := Pattern(chess.BlackPawn, chess.E7)
+	if !slices.Equal(pawn, []chess.Square{chess.E5, chess.D6, chess.E6, chess.F6}) {
+		t.Errorf("Expected e5, d6, e6 and f6 for a pawn on e7, got %v", pawn)
+	}
+}
+
+func TestRuleCoversEveryPiece(t *testing.T) {
+	for _, pieceType := range []chess.PieceType{chess.King, chess.Queen, chess.Rook, chess.Bishop, chess.Knight, chess.Pawn} {
+		if Rule(pieceType) == "" {
+			t.Errorf("Expected a rule for the %s", pieceType)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package notation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"chess-tui/errkind"
+
+	"github.com/notnil/chess"
+)
+
+// Errors returned by Parse, Decode and Normalize, for use with errors.Is.
+// Those about the move are of kind errkind.IllegalMove.
+var (
+	ErrSyntax           = errkind.New(errkind.IllegalMove, "not a move")
+	ErrIllegal          = errkind.New(errkind.IllegalMove, "illegal move")
+	ErrAmbiguous        = errkind.New(errkind.IllegalMove, "ambiguous move")
+	ErrPromotionMissing = errkind.New(errkind.IllegalMove, "promotion piece required")
+	ErrFEN              = errors.New("invalid FEN")
+)
+
+// Castling spellings
+const (
+	CastleKingside  = "O-O"
+	CastleQueenside = "O-O-O"
+)
+
+// noSquare marks a file or rank that was not given
+const noSquare = -1
+
+// Move is the syntax of a move in SAN or UCI long algebraic notation,
+// before it is matched against a position
+type Move struct {
+	Piece     chess.PieceType // chess.NoPieceType for UCI, chess.Pawn for pawn moves
+	FromFile  int             // 0-7 for a-h, or -1 when not given
+	FromRank  int             // 0-7 for 1-8, or -1 when not given
+	To        chess.Square
+	Capture   bool
+	Promotion chess.PieceType
+	Castle    string // CastleKingside, CastleQueenside or ""
+	Check     bool
+	Mate      bool
+	UCI       bool
+	Drop      bool // Piece is dropped from the reserve onto To, as in Crazyhouse
+}
+
+// pieceLetters maps SAN piece letters to piece types
+var pieceLetters = map[byte]chess.PieceType{
+	'K': chess.King,
+	'Q': chess.Queen,
+	'R': chess.Rook,
+	'B': chess.Bishop,
+	'N': chess.Knight,
+}
+
+// Parse reads a move's syntax without a position. It accepts SAN such as
+// "Nbd7", "exd8=Q+" or "O-O-O" (also written with zeros) and UCI such as
+// "e2e4" or "e7e8q", and Crazyhouse drops such as "N@f3" or "P@e4". Trailing
+// annotations like "!" and "?!" are ignored.
+func Parse(text string) (Move, error) {
+	s := strings.TrimRight(strings.TrimSpace(text), "!?")
+	move := Move{FromFile: noSquare, FromRank: noSquare}
+
+	switch {
+	case strings.HasSuffix(s, "#"):
+		move.Mate = true
+		s = s[:len(s)-1]
+	case strings.HasSuffix(s, "+"):
+		move.Check = true
+		s = s[:len(s)-1]
+	}
+
+	switch strings.ReplaceAll(s, "0", "O") {
+	case CastleKingside:
+		move.Piece, move.Castle = chess.King, CastleKingside
+		return move, nil
+	case CastleQueenside:
+		move.Piece, move.Castle = chess.King, CastleQueenside
+		return move, nil
+	}
+
+	if strings.Contains(s, "@") {
+		return parseDrop(s, text, move)
+	}
+	if isUCI(s) {
+		return parseUCI(s, move)
+	}
+	return parseSAN(s, text, move)
+}
+
+// isUCI reports whether s has the shape of a UCI move
+func isUCI(s string) bool {
+	if len(s) != 4 && len(s) != 5 {
+		return false
+	}
+	if !isFile(s[0]) || !isRank(s[1]) || !isFile(s[2]) || !isRank(s[3]) {
+		return false
+	}
+	return len(s) == 4 || strings.IndexByte("qrbn", s[4]) >= 0
+}
+
+// parseUCI reads a move such as e7e8q
+func parseUCI(s string, move Move) (Move, error) {
+	move.UCI = true
+	move.FromFile, move.FromRank = int(s[0]-'a'), int(s[1]-'1')
+	move.To = square(s[2], s[3])
+	if len(s) == 5 {
+		move.Promotion = pieceLetters[s[4]-'a'+'A']
+	}
+	return move, nil
+}
+
+// parseDrop reads a drop such as N@f3, P@e4 or @e4, a pawn drop
+func parseDrop(s, text string, move Move) (Move, error) {
+	piece, to, _ := strings.Cut(s, "@")
+	move.Drop = true
+	switch {
+	case piece == "" || piece == "P":
+		move.Piece = chess.Pawn
+	case len(piece) == 1 && pieceLetters[piece[0]] != chess.NoPieceType && piece[0] != 'K':
+		move.Piece = pieceLetters[piece[0]]
+	default:
+		return move, fmt.Errorf("%w: %q", ErrSyntax, text)
+	}
+	if len(to) != 2 || !isFile(to[0]) || !isRank(to[1]) {
+		return move, fmt.Errorf("%w: %q", ErrSyntax, text)
+	}
+	move.To = square(to[0], to[1])
+	return move, nil
+}
+
+// EncodeDrop formats a drop, e.g. "N@f3" or "P@e4"
+func EncodeDrop(piece chess.PieceType, to chess.Square) string {
+	return strings.ToUpper(piece.String()) + "@" + to.String()
+}
+
+// parseSAN reads a move such as Nbd7, exd8=Q or R1e2
+func parseSAN(s, text string, move Move) (Move, error) {
+	syntaxError := fmt.Errorf("%w: %q", ErrSyntax, text)
+
+	move.Piece = chess.Pawn
+	if len(s) > 0 {
+		if piece, ok := pieceLetters[s[0]]; ok {
+			move.Piece = piece
+			s = s[1:]
+		}
+	}
+
+	// Promotion suffix, pawns only
+	if i := strings.IndexByte(s, '='); i >= 0 {
+		if move.Piece != chess.Pawn || i != len(s)-2 {
+			return move, syntaxError
+		}
+		piece, ok := pieceLetters[s[i+1]]
+		if !ok || piece == chess.King {
+			return move, syntaxError
+		}
+		move.Promotion = piece
+		s = s[:i]
+	}
+
+	// Destination square
+	if len(s) < 2 || !isFile(s[len(s)-2]) || !isRank(s[len(s)-1]) {
+		return move, syntaxError
+	}
+	move.To = square(s[len(s)-2], s[len(s)-1])
+	s = s[:len(s)-2]
+
+	if strings.HasSuffix(s, "x") {
+		move.Capture = true
+		s = s[:len(s)-1]
+	}
+
+	// Whatever is left disambiguates the origin square
+	if len(s) > 0 && isFile(s[0]) {
+		move.FromFile = int(s[0] - 'a')
+		s = s[1:]
+	}
+	if len(s) > 0 && isRank(s[0]) {
+		move.FromRank = int(s[0] - '1')
+		s = s[1:]
+	}
+	if s != "" {
+		return move, syntaxError
+	}
+
+	if move.Piece == chess.Pawn {
+		// Pawns name their file only when capturing, and promote on the last rank
+		if move.FromRank != noSquare || move.Capture != (move.FromFile != noSquare) {
+			return move, syntaxError
+		}
+		lastRank := move.To.Rank() == chess.Rank1 || move.To.Rank() == chess.Rank8
+		if move.Promotion != chess.NoPieceType && !lastRank {
+			return move, syntaxError
+		}
+	}
+	return move, nil
+}
+
+// IsMove reports whether text is syntactically a move in SAN or UCI
+func IsMove(text string) bool {
+	_, err := Parse(text)
+	return err == nil
+}
+
+// Decode resolves text in SAN or UCI to the legal move it names in position.
+// Capture, check and mate markers must be right when they are given, but may
+// be left out. A refused move's error is a *MoveError saying why.
+func Decode(position *chess.Position, text string) (*chess.Move, error) {
+	parsed, err := Parse(text)
+	if err != nil {
+		return nil, refuse(text, ReasonSyntax, err, "%q isn't a move; write moves like e4, Nf3, exd5, O-O or e2e4", strings.TrimSpace(text))
+	}
+	if parsed.Drop {
+		return nil, refuse(text, ReasonDrop, fmt.Errorf("%w: %s", ErrIllegal, text), "drops like %s are only played in Crazyhouse", strings.TrimSpace(text))
+	}
+
+	var matches []*chess.Move
+	for _, move := range position.ValidMoves() {
+		if parsed.Matches(position, move) {
+			matches = append(matches, move)
+		}
+	}
+
+	switch {
+	case len(matches) == 0:
+		return nil, explainIllegal(position, parsed, text)
+	case len(matches) > 1 && parsed.Promotion == chess.NoPieceType && matches[0].Promo() != chess.NoPieceType:
+		return nil, refuse(text, ReasonPromotion, fmt.Errorf("%w: %s", ErrPromotionMissing, text), "choose a piece to promote to, e.g. %s=Q", strings.TrimSpace(text))
+	case len(matches) > 1:
+		var from []string
+		for _, move := range matches {
+			from = append(from, move.S1().String())
+		}
+		return nil, refuse(text, ReasonAmbiguous, fmt.Errorf("%w: %s", ErrAmbiguous, text), "pieces on %s can all make that move; name the one you mean, e.g. %s", strings.Join(from, " and "), Encode(position, matches[0]))
+	}
+
+	move := matches[0]
+	if parsed.Capture && !move.HasTag(chess.Capture) && !move.HasTag(chess.EnPassant) {
+		return nil, refuse(text, ReasonMarker, fmt.Errorf("%w: %s is not a capture", ErrIllegal, text), "there is nothing to capture on %s", move.S2())
+	}
+	if parsed.Mate && position.Update(move).Status() != chess.Checkmate {
+		return nil, refuse(text, ReasonMarker, fmt.Errorf("%w: %s is not mate", ErrIllegal, text), "%s is legal but not checkmate", Encode(position, move))
+	}
+	if parsed.Check && !move.HasTag(chess.Check) {
+		return nil, refuse(text, ReasonMarker, fmt.Errorf("%w: %s is not check", ErrIllegal, text), "%s is legal but doesn't give check", Encode(position, move))
+	}
+	return move, nil
+}
+
+// Matches reports whether a move in the position fits the parsed syntax. A
+// missing promotion piece matches every promotion so Decode can report it.
+func (m Move) Matches(position *chess.Position, move *chess.Move) bool {
+	switch m.Castle {
+	case CastleKingside:
+		return move.HasTag(chess.KingSideCastle)
+	case CastleQueenside:
+		return move.HasTag(chess.QueenSideCastle)
+	}
+
+	if move.S2() != m.To {
+		return false
+	}
+	if m.Piece != chess.NoPieceType && position.Board().Piece(move.S1()).Type() != m.Piece {
+		return false
+	}
+	if m.FromFile != noSquare && int(move.S1().File()) != m.FromFile {
+		return false
+	}
+	if m.FromRank != noSquare && int(move.S1().Rank()) != m.FromRank {
+		return false
+	}
+	return m.Promotion == chess.NoPieceType || move.Promo() == m.Promotion
+}
+
+// Encode formats a legal move in SAN, with disambiguation and check markers
+func Encode(position *chess.Position, move *chess.Move) string {
+	return chess.AlgebraicNotation{}.Encode(position, move)
+}
+
+// EncodeUCI formats a move in UCI long algebraic notation
+func EncodeUCI(move *chess.Move) string {
+	return chess.UCINotation{}.Encode(nil, move)
+}
+
+// Normalize converts a move in SAN or UCI to SAN in the position given as FEN
+func Normalize(fen, text string) (string, error) {
+	fenOption, err := chess.FEN(fen)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrFEN, err)
+	}
+	position := chess.NewGame(fenOption).Position()
+
+	move, err := Decode(position, text)
+	if err != nil {
+		return "", err
+	}
+	return Encode(position, move), nil
+}
+
+// LegalMoves lists the legal moves in SAN for the position given as FEN
+func LegalMoves(fen string) ([]string, error) {
+	fenOption, err := chess.FEN(fen)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFEN, err)
+	}
+	position := chess.NewGame(fenOption).Position()
+
+	moves := position.ValidMoves()
+	sans := make([]string, len(moves))
+	for i, move := range moves {
+		sans[i] = Encode(position, move)
+	}
+	return sans, nil
+}
+
+// isFile reports whether c is a file letter a-h
+func isFile(c byte) bool {
+	return c >= 'a' && c <= 'h'
+}
+
+// isRank reports whether c is a rank digit 1-8
+func isRank(c byte) bool {
+	return c >= '1' && c <= '8'
+}
+
+// square returns the square for a file letter and rank digit
+func square(file, rank byte) chess.Square {
+	return chess.NewSquare(chess.File(file-'a'), chess.Rank(rank-'1'))
+}
@@ -0,0 +1,163 @@
+package notation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestParse(t *testing.T) {
+	valid := []string{
+		"e4", "exd5", "Nf3", "Nbd7", "R1e2", "Qh4xe1", "exd8=Q+", "e8=N#",
+		"O-O", "0-0-0", "O-O-O+", "e2e4", "e7e8q", "Kxe5!", "Nf3?!",
+		"N@f3", "P@e4", "@e4", "Q@h7#",
+	}
+	for _, text := range valid {
+		if _, err := Parse(text); err != nil {
+			t.Errorf("Expected %q to parse, got %v", text, err)
+		}
+	}
+
+	invalid := []string{
+		"", "e", "e9", "i4", "Pe4", "Ke8=Q", "e8=K", "e4=Q", "e8Q", "xd5", "ed5",
+		"e2d5x", "Nf3 e5", "e2e4k", "Zf3", "♘f3", "e2-e4", "O-O-O-O",
+		"K@e4", "N@", "N@f9", "NN@f3",
+	}
+	for _, text := range invalid {
+		if _, err := Parse(text); !errors.Is(err, ErrSyntax) {
+			t.Errorf("Expected %q to be a syntax error, got %v", text, err)
+		}
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	move, err := Parse("Nbxd7+")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if move.Piece != chess.Knight || move.FromFile != 1 || move.FromRank != noSquare ||
+		move.To != chess.D7 || !move.Capture || !move.Check {
+		t.Errorf("Unexpected parse of Nbxd7+: %+v", move)
+	}
+
+	move, err = Parse("e7e8n")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !move.UCI || move.To != chess.E8 || move.Promotion != chess.Knight {
+		t.Errorf("Unexpected parse of e7e8n: %+v", move)
+	}
+
+	move, err = Parse("P@e4+")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !move.Drop || move.Piece != chess.Pawn || move.To != chess.E4 || !move.Check {
+		t.Errorf("Unexpected parse of P@e4+: %+v", move)
+	}
+	if san := EncodeDrop(chess.Knight, chess.F3); san != "N@f3" {
+		t.Errorf("Expected N@f3, got %s", san)
+	}
+}
+
+// position sets up a position from FEN
+func position(t *testing.T, fen string) *chess.Position {
+	t.Helper()
+	option, err := chess.FEN(fen)
+	if err != nil {
+		t.Fatalf("Bad FEN %s: %v", fen, err)
+	}
+	return chess.NewGame(option).Position()
+}
+
+func TestDecode(t *testing.T) {
+	const (
+		start     = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+		knights   = "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1"
+		promotion = "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1"
+		castling  = "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+		enPassant = "4k3/8/8/3Pp3/8/8/8/4K3 w - e6 0 2"
+		mate      = "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1"
+	)
+
+	tests := []struct {
+		fen, text, want string
+		err             error
+	}{
+		{start, "e4", "e2e4", nil},
+		{start, "e2e4", "e2e4", nil},
+		{start, "Nf3", "g1f3", nil},
+		{start, "a1b1", "", ErrIllegal},
+		{start, "e5", "", ErrIllegal},
+		{start, "Nxf3", "", ErrIllegal},
+		{start, "e4+", "", ErrIllegal},
+		{knights, "Nd2", "", ErrAmbiguous},
+		{knights, "Nbd2", "b1d2", nil},
+		{knights, "Nfd2", "f1d2", nil},
+		{promotion, "b8", "", ErrPromotionMissing},
+		{promotion, "b7b8", "", ErrPromotionMissing},
+		{promotion, "b8=Q+", "b7b8q", nil},
+		{promotion, "b7b8r", "b7b8r", nil},
+		{castling, "O-O", "e1g1", nil},
+		{castling, "0-0-0", "e1c1", nil},
+		{castling, "e1g1", "e1g1", nil},
+		{enPassant, "dxe6", "d5e6", nil},
+		{mate, "Ra8#", "a1a8", nil},
+		{mate, "Ra7#", "", ErrIllegal},
+	}
+
+	for _, tt := range tests {
+		move, err := Decode(position(t, tt.fen), tt.text)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Decode(%q) in %s: expected %v, got %v", tt.text, tt.fen, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Decode(%q) in %s: expected %s, got %v", tt.text, tt.fen, tt.want, err)
+			continue
+		}
+		if got := EncodeUCI(move); got != tt.want {
+			t.Errorf("Decode(%q) in %s: expected %s, got %s", tt.text, tt.fen, tt.want, got)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	san, err := Normalize("rnbqk2r/pppppp1p/5n1b/1N4p1/8/5P2/PPPPP1PP/R1BQKBNR w KQkq - 3 4", "b5d6")
+	if err != nil || san != "Nd6+" {
+		t.Errorf("Expected Nd6+, got %q (%v)", san, err)
+	}
+	if _, err := Normalize("not a fen", "e4"); err == nil {
+		t.Error("Expected an error for a bad FEN")
+	}
+}
+
+// BenchmarkDecode measures checking a typed or AI move against the
+// position: a legal SAN move, the same in UCI, and an illegal move, which
+// also works out why it is refused
+func BenchmarkDecode(b *testing.B) {
+	option, _ := chess.FEN("r1bqk2r/1pppbppp/p1n2n2/4p3/B3P3/5N2/PPPP1PPP/RNBQ1RK1 w kq - 4 6")
+	position := chess.NewGame(option).Position()
+	for _, text := range []string{"Re1", "f1e1", "Bxf7"} {
+		b.Run(text, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Decode(position, text)
+			}
+		})
+	}
+}
+
+// BenchmarkLegalMoves measures listing the legal moves in SAN, as every
+// prompt and training sample does
+func BenchmarkLegalMoves(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := LegalMoves("r1bqk2r/1pppbppp/p1n2n2/4p3/B3P3/5N2/PPPP1PPP/RNBQ1RK1 w kq - 4 6"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
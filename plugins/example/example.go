@@ -0,0 +1,103 @@
+// Package example is a sample of the extensions the plugins package takes:
+// a plain ASCII board renderer, a commentator that keeps the material count
+// and a move provider that plays the first legal move. Build the chess
+// binary with -tags example_plugins to compile them in.
+package example
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"chess-tui/events"
+	"chess-tui/plugins"
+
+	"github.com/notnil/chess"
+)
+
+func init() {
+	plugins.RegisterBoardRenderer("ascii", ASCIIBoard{})
+	plugins.RegisterCommentator("material", MaterialCommentator{})
+	plugins.RegisterMoveProvider("first-move", FirstMove{})
+}
+
+// ASCIIBoard draws the board with letters for the pieces, for terminals
+// without Unicode chess symbols
+type ASCIIBoard struct{}
+
+// RenderBoard draws the position, from Black's side if flipped
+func (ASCIIBoard) RenderBoard(position *chess.Position, flipped bool) string {
+	ranks := []int{7, 6, 5, 4, 3, 2, 1, 0}
+	files := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	if flipped {
+		ranks, files = files, ranks
+	}
+	board := position.Board()
+	var sb strings.Builder
+	for _, rank := range ranks {
+		fmt.Fprintf(&sb, "%d ", rank+1)
+		for _, file := range files {
+			piece := board.Piece(chess.Square(rank*8 + file))
+			symbol := "."
+			if piece != chess.NoPiece {
+				symbol = piece.Type().String()
+				if piece.Color() == chess.White {
+					symbol = strings.ToUpper(symbol)
+				}
+			}
+			sb.WriteString(" " + symbol)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("  ")
+	for _, file := range files {
+		sb.WriteString(" " + string(rune('a'+file)))
+	}
+	return sb.String()
+}
+
+// MaterialCommentator says who is ahead in material after each capture
+type MaterialCommentator struct{}
+
+// pieceValues are the usual pawn-unit values of the pieces
+var pieceValues = map[chess.PieceType]int{
+	chess.Pawn: 1, chess.Knight: 3, chess.Bishop: 3, chess.Rook: 5, chess.Queen: 9,
+}
+
+// Comment remarks on the material balance after a capture
+func (MaterialCommentator) Comment(event events.Event) string {
+	if event.Kind != events.MoveMade || !strings.Contains(event.Move, "x") {
+		return ""
+	}
+	option, err := chess.FEN(event.FEN)
+	if err != nil {
+		return ""
+	}
+	balance := 0
+	for _, piece := range chess.NewGame(option).Position().Board().SquareMap() {
+		if piece.Color() == chess.White {
+			balance += pieceValues[piece.Type()]
+		} else {
+			balance -= pieceValues[piece.Type()]
+		}
+	}
+	switch {
+	case balance > 0:
+		return fmt.Sprintf("White is up %d after %s", balance, event.Move)
+	case balance < 0:
+		return fmt.Sprintf("Black is up %d after %s", -balance, event.Move)
+	}
+	return "Material is level after " + event.Move
+}
+
+// FirstMove plays the first legal move it is offered, as the smallest
+// possible engine
+type FirstMove struct{}
+
+// SelectMove returns the first legal move
+func (FirstMove) SelectMove(ctx context.Context, request plugins.MoveRequest) (string, error) {
+	if len(request.LegalMoves) == 0 {
+		return "", fmt.Errorf("no legal moves")
+	}
+	return request.LegalMoves[0], nil
+}
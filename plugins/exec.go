@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultDir returns where executable move providers are looked for in the
+// user's config directory
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "plugins"
+	}
+	return filepath.Join(home, ".bubblechess", "plugins")
+}
+
+// LoadDir registers every executable in dir, or the default directory if
+// empty, as a move provider named after the file without its extension. A
+// missing directory has no plugins. It returns the names registered.
+func LoadDir(dir string) ([]string, error) {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+
+	var loaded []string
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if _, taken := LookupMoveProvider(name); taken {
+			continue
+		}
+		RegisterMoveProvider(name, Executable(filepath.Join(dir, entry.Name())))
+		loaded = append(loaded, name)
+	}
+	return loaded, nil
+}
+
+// Executable returns a move provider that runs the program at path for each
+// move. The program reads the MoveRequest as JSON on stdin and writes its
+// move, in SAN or UCI, as the first line of its output.
+func Executable(path string) MoveProvider {
+	return executable{path: path}
+}
+
+type executable struct {
+	path string
+}
+
+func (e executable) SelectMove(ctx context.Context, request MoveRequest) (string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode move request: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, e.path)
+	cmd.Stdin = bytes.NewReader(append(body, '\n'))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("plugin %s failed: %w: %s", filepath.Base(e.path), err, message)
+		}
+		return "", fmt.Errorf("plugin %s failed: %w", filepath.Base(e.path), err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) == "" {
+		return "", fmt.Errorf("plugin %s returned no move", filepath.Base(e.path))
+	}
+	return strings.TrimSpace(scanner.Text()), nil
+}
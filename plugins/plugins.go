@@ -0,0 +1,147 @@
+// Package plugins holds the extension points that let the community add
+// engines, board renderers and commentators without forking.
+//
+// Extensions written in Go register themselves from an init function, and
+// are compiled into the binary by importing their package for its side
+// effects from a build-tagged file in cmd/chess (see plugins_example.go).
+// Move providers can also be any executable in the plugins directory, which
+// is handed the position as JSON on stdin and answers with its move.
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"chess-tui/events"
+
+	"github.com/notnil/chess"
+)
+
+// MoveProvider chooses moves, like the AI's model backends. A registered
+// provider is picked by its name in the "provider" field of an AI config.
+type MoveProvider interface {
+	// SelectMove returns one of request.LegalMoves, in SAN or UCI
+	SelectMove(ctx context.Context, request MoveRequest) (string, error)
+}
+
+// MoveRequest is the position a MoveProvider chooses a move in
+type MoveRequest struct {
+	FEN        string   `json:"fen"`
+	LegalMoves []string `json:"legal_moves"`       // in SAN
+	Phase      string   `json:"phase,omitempty"`   // "opening", "middlegame" or "endgame"
+	Variant    string   `json:"variant,omitempty"` // e.g. "atomic"; empty for standard chess
+}
+
+// BoardRenderer draws the board in the TUI. A registered renderer is picked
+// by its name as the board style, and s cycles through it with the built-in
+// styles.
+type BoardRenderer interface {
+	// RenderBoard draws the position, from Black's side if flipped
+	RenderBoard(position *chess.Position, flipped bool) string
+}
+
+// Commentator remarks on the game as it is played. Every registered
+// commentator is asked about each move and the end of the game; what it
+// says is shown below the status line. It is called from the TUI's update,
+// so it must answer quickly.
+type Commentator interface {
+	// Comment returns a remark on the event, or "" to say nothing
+	Comment(event events.Event) string
+}
+
+// registry holds the registered extensions by name
+type registry struct {
+	mu           sync.RWMutex
+	providers    map[string]MoveProvider
+	renderers    map[string]BoardRenderer
+	commentators map[string]Commentator
+}
+
+var extensions = registry{
+	providers:    make(map[string]MoveProvider),
+	renderers:    make(map[string]BoardRenderer),
+	commentators: make(map[string]Commentator),
+}
+
+// RegisterMoveProvider makes provider available under name. It panics if
+// the name is taken, as registering twice is a programming error.
+func RegisterMoveProvider(name string, provider MoveProvider) {
+	register(extensions.providers, "move provider", name, provider)
+}
+
+// RegisterBoardRenderer makes renderer available as the board style name.
+// It panics if the name is taken.
+func RegisterBoardRenderer(name string, renderer BoardRenderer) {
+	register(extensions.renderers, "board renderer", name, renderer)
+}
+
+// RegisterCommentator adds a commentator under name. It panics if the name
+// is taken.
+func RegisterCommentator(name string, commentator Commentator) {
+	register(extensions.commentators, "commentator", name, commentator)
+}
+
+// register adds an extension to one of the registry's maps
+func register[T any](extensionsOfKind map[string]T, kind, name string, extension T) {
+	extensions.mu.Lock()
+	defer extensions.mu.Unlock()
+	if name == "" {
+		panic(fmt.Sprintf("plugins: %s registered without a name", kind))
+	}
+	if _, taken := extensionsOfKind[name]; taken {
+		panic(fmt.Sprintf("plugins: %s %q registered twice", kind, name))
+	}
+	extensionsOfKind[name] = extension
+}
+
+// LookupMoveProvider returns the move provider registered as name
+func LookupMoveProvider(name string) (MoveProvider, bool) {
+	return lookup(extensions.providers, name)
+}
+
+// LookupBoardRenderer returns the board renderer registered as name
+func LookupBoardRenderer(name string) (BoardRenderer, bool) {
+	return lookup(extensions.renderers, name)
+}
+
+// LookupCommentator returns the commentator registered as name
+func LookupCommentator(name string) (Commentator, bool) {
+	return lookup(extensions.commentators, name)
+}
+
+// lookup finds an extension in one of the registry's maps
+func lookup[T any](extensionsOfKind map[string]T, name string) (T, bool) {
+	extensions.mu.RLock()
+	defer extensions.mu.RUnlock()
+	extension, ok := extensionsOfKind[name]
+	return extension, ok
+}
+
+// MoveProviders returns the names of the registered move providers, sorted
+func MoveProviders() []string {
+	return names(extensions.providers)
+}
+
+// BoardRenderers returns the names of the registered board renderers, sorted
+func BoardRenderers() []string {
+	return names(extensions.renderers)
+}
+
+// Commentators returns the names of the registered commentators, sorted
+func Commentators() []string {
+	return names(extensions.commentators)
+}
+
+// names lists the keys of one of the registry's maps, sorted
+func names[T any](extensionsOfKind map[string]T) []string {
+	extensions.mu.RLock()
+	defer extensions.mu.RUnlock()
+	list := make([]string, 0, len(extensionsOfKind))
+	for name := range extensionsOfKind {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,61 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+type fixedMove string
+
+func (f fixedMove) SelectMove(ctx context.Context, request MoveRequest) (string, error) {
+	return string(f), nil
+}
+
+func TestRegisterMoveProvider(t *testing.T) {
+	RegisterMoveProvider("test-fixed", fixedMove("e4"))
+	provider, ok := LookupMoveProvider("test-fixed")
+	if !ok {
+		t.Fatal("Expected the registered provider to be found")
+	}
+	if move, _ := provider.SelectMove(context.Background(), MoveRequest{}); move != "e4" {
+		t.Errorf("Expected e4, got %s", move)
+	}
+	if !slices.Contains(MoveProviders(), "test-fixed") {
+		t.Errorf("Expected test-fixed to be listed, got %v", MoveProviders())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a name twice to panic")
+		}
+	}()
+	RegisterMoveProvider("test-fixed", fixedMove("d4"))
+}
+
+func TestLoadDirRegistersExecutables(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\ngrep -q '\"legal_moves\":\\[\"e4\"' && echo e2e4\n"
+	if err := os.WriteFile(filepath.Join(dir, "test-script.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadDir(dir)
+	if err != nil || len(loaded) != 1 || loaded[0] != "test-script" {
+		t.Fatalf("Expected only the executable to load as test-script, got %v (%v)", loaded, err)
+	}
+	provider, _ := LookupMoveProvider("test-script")
+	move, err := provider.SelectMove(context.Background(), MoveRequest{FEN: "start", LegalMoves: []string{"e4", "d4"}})
+	if err != nil || move != "e2e4" {
+		t.Errorf("Expected the script's move e2e4, got %q (%v)", move, err)
+	}
+
+	if loaded, err := LoadDir(filepath.Join(dir, "missing")); err != nil || len(loaded) != 0 {
+		t.Errorf("Expected a missing directory to have no plugins, got %v (%v)", loaded, err)
+	}
+}
@@ -0,0 +1,151 @@
+// Package positions is a library of named test positions: endgame technique,
+// tactics and stalemate traps. Each is documented with the idea it shows and,
+// where there is one, a line that demonstrates it, so the same positions can
+// set up tests, puzzles and lessons, or a game with `chess --position`.
+package positions
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/notnil/chess"
+)
+
+// Categories of positions
+const (
+	CategoryEndgame   = "endgame"
+	CategoryTactic    = "tactic"
+	CategoryStalemate = "stalemate"
+)
+
+// Position is a named position and what it teaches
+type Position struct {
+	Name        string // lookup key, e.g. "lucena"
+	Title       string
+	Category    string
+	FEN         string
+	Description string
+	Line        []string // SAN moves demonstrating the idea, if any
+	Avoid       string   // SAN of a natural move that throws the result away, for traps
+}
+
+// library holds the positions, in the order All lists them
+var library = []Position{
+	{
+		Name:        "lucena",
+		Title:       "Lucena position",
+		Category:    CategoryEndgame,
+		FEN:         "1K1k4/1P6/8/8/8/8/r7/2R5 w - - 0 1",
+		Description: "Rook and pawn against rook with the pawn on the seventh. White cuts the black king off, then builds a bridge with the rook on the fourth rank to shelter the king from checks.",
+		Line:        []string{"Rd1+", "Ke7", "Rd4", "Ra1", "Kc7", "Rc1+", "Kb6", "Rb1+", "Kc6", "Rc1+", "Kb5", "Rb1+", "Rb4"},
+	},
+	{
+		Name:        "philidor",
+		Title:       "Philidor position",
+		Category:    CategoryEndgame,
+		FEN:         "4k3/7r/8/3KP3/8/8/8/R7 b - - 0 1",
+		Description: "The defending side's drawing method in rook and pawn against rook. Black holds the third rank until the pawn advances, then checks the king from behind.",
+		Line:        []string{"Rh6", "e6", "Rh1", "Kd6", "Rd1+"},
+	},
+	{
+		Name:        "king-sixth",
+		Title:       "King on the sixth",
+		Category:    CategoryEndgame,
+		FEN:         "4k3/8/4K3/4P3/8/8/8/8 w - - 0 1",
+		Description: "King and pawn against king. With the king on the sixth rank in front of its pawn, White wins whoever moves first.",
+		Line:        []string{"Kd6", "Kd8", "e6", "Ke8", "e7", "Kf7", "Kd7"},
+	},
+	{
+		Name:        "knight-fork",
+		Title:       "Royal fork",
+		Category:    CategoryTactic,
+		FEN:         "q3k3/8/8/1N6/8/8/8/4K3 w - - 0 1",
+		Description: "The knight checks the king and attacks the queen at once, winning it.",
+		Line:        []string{"Nc7+", "Kd7", "Nxa8"},
+	},
+	{
+		Name:        "skewer",
+		Title:       "Skewer",
+		Category:    CategoryTactic,
+		FEN:         "8/8/8/2k3q1/8/8/8/R3K3 w - - 0 1",
+		Description: "The rook checks along the rank; once the king steps aside, the queen behind it falls.",
+		Line:        []string{"Ra5+", "Kd4", "Rxg5"},
+	},
+	{
+		Name:        "back-rank",
+		Title:       "Back-rank mate",
+		Category:    CategoryTactic,
+		FEN:         "6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1",
+		Description: "The king is walled in by its own pawns, so a rook on the back rank mates.",
+		Line:        []string{"Rd8#"},
+	},
+	{
+		Name:        "smothered",
+		Title:       "Smothered mate",
+		Category:    CategoryTactic,
+		FEN:         "6rk/6pp/8/6N1/8/8/8/6K1 w - - 0 1",
+		Description: "The king is hemmed in by its own pieces, and a lone knight mates it.",
+		Line:        []string{"Nf7#"},
+	},
+	{
+		Name:        "queen-stalemate",
+		Title:       "Queen stalemate trap",
+		Category:    CategoryStalemate,
+		FEN:         "k7/8/8/2Q5/8/8/8/K7 w - - 0 1",
+		Description: "King and queen against king. Boxing the king in with Qb6 leaves it no move: stalemate. Bring the king up first, and mate follows.",
+		Line:        []string{"Kb2", "Kb7", "Kc3", "Ka6", "Kc4", "Kb7", "Kb5", "Ka8", "Kb6", "Kb8", "Qf8#"},
+		Avoid:       "Qb6",
+	},
+	{
+		Name:        "desperado",
+		Title:       "Desperado rook",
+		Category:    CategoryStalemate,
+		FEN:         "7k/5Q2/7K/8/8/8/8/6r1 b - - 0 1",
+		Description: "Black's king has no moves, so Black throws the rook at White's king. Taking it is stalemate; running from the checks lets Black keep giving them.",
+		Line:        []string{"Rg6+", "Kxg6"},
+	},
+}
+
+// All returns every position in the library
+func All() []Position {
+	return append([]Position(nil), library...)
+}
+
+// Get returns the position called name
+func Get(name string) (Position, bool) {
+	for _, position := range library {
+		if position.Name == name {
+			return position, true
+		}
+	}
+	return Position{}, false
+}
+
+// MustGet returns the position called name, panicking if there is none. It
+// is meant for tests and tables of bundled positions.
+func MustGet(name string) Position {
+	position, ok := Get(name)
+	if !ok {
+		panic(fmt.Sprintf("positions: unknown position %q", name))
+	}
+	return position
+}
+
+// Names returns the names of every position, sorted
+func Names() []string {
+	names := make([]string, len(library))
+	for i, position := range library {
+		names[i] = position.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Game returns a new game at the position
+func (p Position) Game() (*chess.Game, error) {
+	fen, err := chess.FEN(p.FEN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid position %s: %w", p.Name, err)
+	}
+	return chess.NewGame(fen, chess.UseNotation(chess.AlgebraicNotation{})), nil
+}
@@ -0,0 +1,80 @@
+package positions
+
+import (
+	"strings"
+	"testing"
+
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+func TestPositionsAreSound(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, position := range All() {
+		if seen[position.Name] {
+			t.Errorf("Duplicate position name %s", position.Name)
+		}
+		seen[position.Name] = true
+		if position.Title == "" || position.Description == "" {
+			t.Errorf("%s: expected a title and description", position.Name)
+		}
+
+		game, err := position.Game()
+		if err != nil {
+			t.Errorf("%s: %v", position.Name, err)
+			continue
+		}
+		if game.Outcome() != chess.NoOutcome {
+			t.Errorf("%s: expected a game in progress, got %s", position.Name, game.Outcome())
+		}
+
+		// The demonstration line is legal, and mates where it says so
+		for i, san := range position.Line {
+			move, err := notation.Decode(game.Position(), san)
+			if err != nil {
+				t.Errorf("%s: move %d %s is illegal: %v", position.Name, i+1, san, err)
+				break
+			}
+			game.Move(move)
+		}
+		if last := len(position.Line) - 1; last >= 0 && strings.HasSuffix(position.Line[last], "#") && game.Method() != chess.Checkmate {
+			t.Errorf("%s: expected the line to end in checkmate, got %s", position.Name, game.Method())
+		}
+	}
+}
+
+func TestStalemateTraps(t *testing.T) {
+	for _, position := range All() {
+		if position.Category != CategoryStalemate {
+			continue
+		}
+		game, _ := position.Game()
+		line := position.Line
+		if position.Avoid != "" {
+			line = []string{position.Avoid}
+		}
+		for _, san := range line {
+			move, err := notation.Decode(game.Position(), san)
+			if err != nil {
+				t.Fatalf("%s: %s is illegal: %v", position.Name, san, err)
+			}
+			game.Move(move)
+		}
+		if game.Method() != chess.Stalemate {
+			t.Errorf("%s: expected stalemate after %v, got %s", position.Name, line, game.Method())
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	if position, ok := Get("lucena"); !ok || position.Category != CategoryEndgame {
+		t.Errorf("Expected the Lucena position, got %+v", position)
+	}
+	if _, ok := Get("nonexistent"); ok {
+		t.Errorf("Expected no position for an unknown name")
+	}
+	if names := Names(); len(names) != len(All()) || names[0] != "back-rank" {
+		t.Errorf("Expected sorted names, got %v", names)
+	}
+}
@@ -0,0 +1,166 @@
+package positions
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/notnil/chess"
+)
+
+// Scramble defaults, used where ScrambleOptions leaves a field zero
+const (
+	defaultScrambleMinPlies     = 16
+	defaultScrambleMaxPlies     = 30
+	defaultScrambleMaxImbalance = 100 // centipawns
+	defaultScrambleAttempts     = 200
+)
+
+// ErrNoScramble is returned when no balanced position turned up within the
+// allowed attempts
+var ErrNoScramble = errors.New("no balanced position found")
+
+// scrambleValues are the material values, in centipawns, a scrambled
+// position is balanced by
+var scrambleValues = map[chess.PieceType]int{
+	chess.Pawn:   100,
+	chess.Knight: 300,
+	chess.Bishop: 300,
+	chess.Rook:   500,
+	chess.Queen:  900,
+}
+
+// ScrambleOptions tune the positions Scramble generates
+type ScrambleOptions struct {
+	MinPlies     int // random moves played from the start at least, 0 for 16
+	MaxPlies     int // and at most, 0 for 30
+	MaxImbalance int // largest material or engine advantage allowed, in centipawns, 0 for 100
+
+	// Evaluate, if set, scores a candidate position in centipawns for the
+	// side to move, so an engine can reject those that only look level
+	Evaluate func(fen string) (int, error)
+
+	Attempts int // candidates tried before giving up, 0 for 200
+}
+
+// withDefaults fills in the zero fields of options
+func (o ScrambleOptions) withDefaults() ScrambleOptions {
+	if o.MinPlies <= 0 {
+		o.MinPlies = defaultScrambleMinPlies
+	}
+	if o.MaxPlies <= 0 {
+		o.MaxPlies = defaultScrambleMaxPlies
+	}
+	if o.MaxPlies < o.MinPlies {
+		o.MaxPlies = o.MinPlies
+	}
+	if o.MaxImbalance <= 0 {
+		o.MaxImbalance = defaultScrambleMaxImbalance
+	}
+	if o.Attempts <= 0 {
+		o.Attempts = defaultScrambleAttempts
+	}
+	return o
+}
+
+// Scramble plays random legal moves from the starting position to reach a
+// middlegame-like position, and returns its FEN. The result always has
+// White to move, is not in check, has roughly level material with nothing
+// hanging, and, if options.Evaluate is set, is scored roughly level by it.
+func Scramble(rng *rand.Rand, options ScrambleOptions) (string, error) {
+	options = options.withDefaults()
+
+	for attempt := 0; attempt < options.Attempts; attempt++ {
+		plies := options.MinPlies + rng.Intn(options.MaxPlies-options.MinPlies+1)
+		if plies%2 == 1 {
+			plies++ // end with White to move
+		}
+		position, ok := randomWalk(rng, plies)
+		if !ok || !balanced(position, options.MaxImbalance) {
+			continue
+		}
+
+		fen := position.String()
+		if options.Evaluate != nil {
+			score, err := options.Evaluate(fen)
+			if err != nil {
+				return "", fmt.Errorf("failed to evaluate scrambled position: %w", err)
+			}
+			if score > options.MaxImbalance || score < -options.MaxImbalance {
+				continue
+			}
+		}
+		return fen, nil
+	}
+	return "", ErrNoScramble
+}
+
+// randomWalk plays plies random moves from the starting position, keeping
+// the kings home except to castle, and reports false if the game ended on
+// the way or the last move gave check
+func randomWalk(rng *rand.Rand, plies int) (*chess.Position, bool) {
+	position := chess.StartingPosition()
+	for i := 0; i < plies; i++ {
+		var moves []*chess.Move
+		for _, move := range position.ValidMoves() {
+			if position.Board().Piece(move.S1()).Type() == chess.King &&
+				!move.HasTag(chess.KingSideCastle) && !move.HasTag(chess.QueenSideCastle) {
+				continue
+			}
+			moves = append(moves, move)
+		}
+		if len(moves) == 0 {
+			return nil, false
+		}
+		move := moves[rng.Intn(len(moves))]
+		position = position.Update(move)
+		if i == plies-1 && move.HasTag(chess.Check) {
+			return nil, false
+		}
+	}
+	return position, position.Status() == chess.NoMethod
+}
+
+// balanced reports whether position is quiet and level: neither side is
+// ahead by more than maxImbalance in material, and no capture wins material
+// outright
+func balanced(position *chess.Position, maxImbalance int) bool {
+	if diff := material(position.Board(), chess.White) - material(position.Board(), chess.Black); diff > maxImbalance || diff < -maxImbalance {
+		return false
+	}
+	for _, move := range position.ValidMoves() {
+		if captureGain(position, move) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// captureGain estimates what move wins: the value captured, less the
+// capturing piece if it can be taken back
+func captureGain(position *chess.Position, move *chess.Move) int {
+	captured := position.Board().Piece(move.S2())
+	if captured == chess.NoPiece {
+		return 0
+	}
+	gain := scrambleValues[captured.Type()]
+	next := position.Update(move)
+	for _, reply := range next.ValidMoves() {
+		if reply.S2() == move.S2() {
+			gain -= scrambleValues[position.Board().Piece(move.S1()).Type()]
+			break
+		}
+	}
+	return gain
+}
+
+// material totals color's pieces
+func material(board *chess.Board, color chess.Color) int {
+	total := 0
+	for _, piece := range board.SquareMap() {
+		if piece.Color() == color {
+			total += scrambleValues[piece.Type()]
+		}
+	}
+	return total
+}
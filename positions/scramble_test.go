@@ -0,0 +1,80 @@
+package positions
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestScramble(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	seen := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		fen, err := Scramble(rng, ScrambleOptions{})
+		if err != nil {
+			t.Fatalf("Expected a scrambled position, got %v", err)
+		}
+		seen[fen] = true
+
+		option, err := chess.FEN(fen)
+		if err != nil {
+			t.Fatalf("Expected a valid FEN, got %q: %v", fen, err)
+		}
+		game := chess.NewGame(option)
+		position := game.Position()
+		if position.Turn() != chess.White {
+			t.Errorf("Expected White to move in %s", fen)
+		}
+		if game.Outcome() != chess.NoOutcome {
+			t.Errorf("Expected a game in progress in %s, got %s", fen, game.Outcome())
+		}
+		if diff := material(position.Board(), chess.White) - material(position.Board(), chess.Black); diff > defaultScrambleMaxImbalance || diff < -defaultScrambleMaxImbalance {
+			t.Errorf("Expected level material in %s, got %+d", fen, diff)
+		}
+		for _, move := range position.ValidMoves() {
+			if gain := captureGain(position, move); gain > 0 {
+				t.Errorf("Expected nothing hanging in %s, but %s wins %d", fen, move, gain)
+			}
+		}
+	}
+	if len(seen) < 15 {
+		t.Errorf("Expected varied positions, got %d distinct of 20", len(seen))
+	}
+}
+
+func TestScrambleEvaluate(t *testing.T) {
+	calls := 0
+	fen, err := Scramble(rand.New(rand.NewSource(2)), ScrambleOptions{
+		Evaluate: func(fen string) (int, error) {
+			calls++
+			if calls < 3 {
+				return 250, nil // the engine sees what material doesn't
+			}
+			return -40, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected a scrambled position, got %v", err)
+	}
+	if calls != 3 || fen == "" {
+		t.Errorf("Expected the first two candidates rejected, got %d calls", calls)
+	}
+
+	_, err = Scramble(rand.New(rand.NewSource(3)), ScrambleOptions{
+		Attempts: 5,
+		Evaluate: func(string) (int, error) { return -500, nil },
+	})
+	if !errors.Is(err, ErrNoScramble) {
+		t.Errorf("Expected ErrNoScramble, got %v", err)
+	}
+
+	failure := errors.New("engine crashed")
+	_, err = Scramble(rand.New(rand.NewSource(4)), ScrambleOptions{
+		Evaluate: func(string) (int, error) { return 0, failure },
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected the engine's error, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Package rules is house rules played on top of standard chess: a move
+// limit, compulsory captures and a win on material at a set move. The core
+// engine still decides which moves are legal; the rules narrow them down
+// and end the game early.
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// Rules are a game's house rules; the zero value is standard chess
+type Rules struct {
+	// MaxMoves draws the game after this many full moves
+	MaxMoves int `json:"max_moves,omitempty"`
+
+	// MustCapture makes captures compulsory: a side that can capture must
+	MustCapture bool `json:"must_capture,omitempty"`
+
+	// At the end of move MaterialMove, the side ahead by at least
+	// MaterialMargin points (pawn 1, knight and bishop 3, rook 5, queen 9)
+	// wins, and otherwise the game is drawn
+	MaterialMove   int `json:"material_move,omitempty"`
+	MaterialMargin int `json:"material_margin,omitempty"`
+}
+
+// points are the piece values material is counted in
+var points = map[chess.PieceType]int{
+	chess.Pawn:   1,
+	chess.Knight: 3,
+	chess.Bishop: 3,
+	chess.Rook:   5,
+	chess.Queen:  9,
+}
+
+// Validate checks the rules make sense
+func (r Rules) Validate() error {
+	if r.MaxMoves < 0 || r.MaterialMove < 0 || r.MaterialMargin < 0 {
+		return fmt.Errorf("house rule move numbers and margins cannot be negative")
+	}
+	if r.MaterialMargin > 0 && r.MaterialMove == 0 {
+		return fmt.Errorf("a material margin needs the move material is counted at")
+	}
+	return nil
+}
+
+// Active reports whether any house rule is set
+func (r Rules) Active() bool {
+	return r != Rules{}
+}
+
+// String describes the rules, e.g. "captures are compulsory, the game ends
+// after move 40", or "standard rules"
+func (r Rules) String() string {
+	var parts []string
+	if r.MustCapture {
+		parts = append(parts, "captures are compulsory")
+	}
+	if r.MaterialMove > 0 {
+		lead := "more material"
+		if r.MaterialMargin > 1 {
+			lead = fmt.Sprintf("%d points more material", r.MaterialMargin)
+		}
+		parts = append(parts, fmt.Sprintf("%s after move %d wins", lead, r.MaterialMove))
+	}
+	if r.MaxMoves > 0 {
+		parts = append(parts, fmt.Sprintf("the game ends after move %d", r.MaxMoves))
+	}
+	if len(parts) == 0 {
+		return "standard rules"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Check returns an error if the house rules forbid a legal move
+func (r Rules) Check(position *chess.Position, move *chess.Move) error {
+	if !r.MustCapture || isCapture(move) {
+		return nil
+	}
+	for _, other := range position.ValidMoves() {
+		if isCapture(other) {
+			return fmt.Errorf("captures are compulsory: %s can be taken", other.S2())
+		}
+	}
+	return nil
+}
+
+// Moves returns the legal moves the house rules allow in position
+func (r Rules) Moves(position *chess.Position) []*chess.Move {
+	var allowed []*chess.Move
+	for _, move := range position.ValidMoves() {
+		if r.Check(position, move) == nil {
+			allowed = append(allowed, move)
+		}
+	}
+	return allowed
+}
+
+// Verdict decides a game the house rules end after plies half-moves in
+// position, returning chess.NoOutcome to play on and otherwise the outcome
+// and how it came about, e.g. "material at move 30"
+func (r Rules) Verdict(position *chess.Position, plies int) (chess.Outcome, string) {
+	if plies%2 != 0 {
+		return chess.NoOutcome, "" // the move isn't over
+	}
+	move := plies / 2
+	if r.MaterialMove > 0 && move == r.MaterialMove {
+		return r.materialOutcome(position), fmt.Sprintf("material at move %d", move)
+	}
+	if r.MaxMoves > 0 && move >= r.MaxMoves {
+		return chess.Draw, fmt.Sprintf("the %d-move limit", r.MaxMoves)
+	}
+	return chess.NoOutcome, ""
+}
+
+// materialOutcome awards the game to the side ahead by the margin, or
+// draws it
+func (r Rules) materialOutcome(position *chess.Position) chess.Outcome {
+	margin := max(r.MaterialMargin, 1)
+	switch lead := Material(position, chess.White) - Material(position, chess.Black); {
+	case lead >= margin:
+		return chess.WhiteWon
+	case -lead >= margin:
+		return chess.BlackWon
+	}
+	return chess.Draw
+}
+
+// Material totals color's pieces in points
+func Material(position *chess.Position, color chess.Color) int {
+	total := 0
+	for _, piece := range position.Board().SquareMap() {
+		if piece.Color() == color {
+			total += points[piece.Type()]
+		}
+	}
+	return total
+}
+
+// isCapture reports whether move takes a piece
+func isCapture(move *chess.Move) bool {
+	return move.HasTag(chess.Capture) || move.HasTag(chess.EnPassant)
+}
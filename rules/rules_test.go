@@ -0,0 +1,84 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+// position returns the position after playing moves from the start
+func position(t *testing.T, moves ...string) (*chess.Position, int) {
+	t.Helper()
+	game := chess.NewGame(chess.UseNotation(chess.AlgebraicNotation{}))
+	for _, move := range moves {
+		if err := game.MoveStr(move); err != nil {
+			t.Fatalf("Failed to play %s: %v", move, err)
+		}
+	}
+	return game.Position(), len(moves)
+}
+
+func TestMustCapture(t *testing.T) {
+	rules := Rules{MustCapture: true}
+	pos, _ := position(t, "e4", "d5")
+
+	quiet, _ := chess.AlgebraicNotation{}.Decode(pos, "Nf3")
+	if err := rules.Check(pos, quiet); err == nil {
+		t.Error("Expected a quiet move to be refused while exd5 is possible")
+	}
+	capture, _ := chess.AlgebraicNotation{}.Decode(pos, "exd5")
+	if err := rules.Check(pos, capture); err != nil {
+		t.Errorf("Expected the capture to be allowed, got %v", err)
+	}
+	if moves := rules.Moves(pos); len(moves) != 1 || moves[0].String() != "e4d5" {
+		t.Errorf("Expected only exd5, got %v", moves)
+	}
+
+	// Without a capture on the board every move is allowed
+	start, _ := position(t)
+	if n := len(rules.Moves(start)); n != 20 {
+		t.Errorf("Expected 20 moves at the start, got %d", n)
+	}
+	if n := len(Rules{}.Moves(pos)); n != len(pos.ValidMoves()) {
+		t.Errorf("Expected standard rules to allow every move, got %d", n)
+	}
+}
+
+func TestVerdict(t *testing.T) {
+	// White is a pawn up after move 2
+	pos, plies := position(t, "e4", "d5", "exd5", "Nf6")
+	if outcome, why := (Rules{MaterialMove: 2}).Verdict(pos, plies); outcome != chess.WhiteWon || why != "material at move 2" {
+		t.Errorf("Expected White to win on material at move 2, got %s (%s)", outcome, why)
+	}
+	if outcome, _ := (Rules{MaterialMove: 2, MaterialMargin: 3}).Verdict(pos, plies); outcome != chess.Draw {
+		t.Errorf("Expected a draw short of the margin, got %s", outcome)
+	}
+	if outcome, _ := (Rules{MaterialMove: 3}).Verdict(pos, plies); outcome != chess.NoOutcome {
+		t.Errorf("Expected play to go on before the material move, got %s", outcome)
+	}
+	if outcome, why := (Rules{MaxMoves: 2}).Verdict(pos, plies); outcome != chess.Draw || why != "the 2-move limit" {
+		t.Errorf("Expected a draw at the move limit, got %s (%s)", outcome, why)
+	}
+
+	// Only complete moves count
+	mid, plies := position(t, "e4", "d5", "exd5")
+	if outcome, _ := (Rules{MaterialMove: 2}).Verdict(mid, plies); outcome != chess.NoOutcome {
+		t.Errorf("Expected Black to finish move 2 first, got %s", outcome)
+	}
+}
+
+func TestValidateAndDescribe(t *testing.T) {
+	if err := (Rules{MaterialMargin: 2}).Validate(); err == nil {
+		t.Error("Expected a margin without a move to be refused")
+	}
+	if err := (Rules{MaxMoves: -1}).Validate(); err == nil {
+		t.Error("Expected a negative move limit to be refused")
+	}
+	if got := (Rules{}).String(); got != "standard rules" {
+		t.Errorf("Expected standard rules, got %s", got)
+	}
+	want := "captures are compulsory, 3 points more material after move 30 wins, the game ends after move 40"
+	if got := (Rules{MustCapture: true, MaterialMove: 30, MaterialMargin: 3, MaxMoves: 40}).String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
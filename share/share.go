@@ -0,0 +1,153 @@
+// Package share uploads games to a paste service or a GitHub gist and
+// returns a link to them.
+package share
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"chess-tui/netconfig"
+)
+
+// DefaultGistAPI is the GitHub API endpoint for creating gists
+const DefaultGistAPI = "https://api.github.com/gists"
+
+// ErrNotConfigured is returned when no paste endpoint or gist is set up
+var ErrNotConfigured = errors.New(`sharing isn't set up; set "share": {"endpoint": ...} or {"gist": true} in the settings file`)
+
+// Config chooses where games are uploaded
+type Config struct {
+	// Endpoint is a paste service that takes the raw text as a POST body and
+	// answers with the paste's URL, e.g. "https://paste.rs/"
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Gist uploads to a GitHub gist instead, using the token in $GITHUB_TOKEN
+	Gist       bool `json:"gist,omitempty"`
+	GistPublic bool `json:"gist_public,omitempty"`
+}
+
+// Uploader uploads a file and returns a link to it
+type Uploader interface {
+	Upload(ctx context.Context, name, content string) (string, error)
+}
+
+// New creates the uploader a config describes
+func New(config Config) (Uploader, error) {
+	switch {
+	case config.Gist:
+		token := os.Getenv("GITHUB_TOKEN")
+		if token == "" {
+			return nil, errors.New("sharing to a gist needs a GitHub token in $GITHUB_TOKEN")
+		}
+		return &GistUploader{Token: token, Public: config.GistPublic}, nil
+	case config.Endpoint != "":
+		return &PasteUploader{Endpoint: config.Endpoint}, nil
+	default:
+		return nil, ErrNotConfigured
+	}
+}
+
+// client is used for uploads
+var client = netconfig.NewClient(30 * time.Second)
+
+// PasteUploader posts raw text to a paste service
+type PasteUploader struct {
+	Endpoint string
+}
+
+// Upload posts content and returns the URL the service answers with
+func (p *PasteUploader) Upload(ctx context.Context, name, content string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Endpoint, strings.NewReader(content))
+	if err != nil {
+		return "", fmt.Errorf("failed to create paste request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	body, resp, err := send(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload paste: %w", err)
+	}
+
+	// Services answer with the URL as the body, in a Location header, or by
+	// redirecting to the paste
+	if link := strings.TrimSpace(string(body)); strings.HasPrefix(link, "http") {
+		return strings.Fields(link)[0], nil
+	}
+	if location := resp.Header.Get("Location"); location != "" {
+		return location, nil
+	}
+	if final := resp.Request.URL.String(); final != p.Endpoint {
+		// Followed a redirect to the paste
+		return final, nil
+	}
+	return "", fmt.Errorf("paste service didn't return a URL: %.100s", body)
+}
+
+// GistUploader creates GitHub gists
+type GistUploader struct {
+	Token  string
+	Public bool
+	API    string // defaults to DefaultGistAPI
+}
+
+// Upload creates a gist with one file and returns its page
+func (g *GistUploader) Upload(ctx context.Context, name, content string) (string, error) {
+	api := g.API
+	if api == "" {
+		api = DefaultGistAPI
+	}
+	payload, err := json.Marshal(map[string]any{
+		"description": "bubblechess game",
+		"public":      g.Public,
+		"files":       map[string]any{name: map[string]string{"content": content}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode gist: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, api, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create gist request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+g.Token)
+
+	body, _, err := send(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create gist: %w", err)
+	}
+	var gist struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.Unmarshal(body, &gist); err != nil || gist.HTMLURL == "" {
+		return "", fmt.Errorf("GitHub didn't return the gist's URL")
+	}
+	return gist.HTMLURL, nil
+}
+
+// send sends a request and returns the body of a successful response
+func send(req *http.Request) ([]byte, *http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, nil, fmt.Errorf("server returned %s: %.200s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, resp, nil
+}
@@ -0,0 +1,96 @@
+package share
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPasteUploaderReturnsBodyURL(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		io.WriteString(w, "https://paste.example/abc\n")
+	}))
+	defer server.Close()
+
+	url, err := (&PasteUploader{Endpoint: server.URL}).Upload(context.Background(), "game.pgn", "1. e4 e5 *")
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if url != "https://paste.example/abc" {
+		t.Errorf("Expected the paste URL, got %q", url)
+	}
+	if received != "1. e4 e5 *" {
+		t.Errorf("Expected the PGN as the body, got %q", received)
+	}
+}
+
+func TestPasteUploaderLocationHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "https://paste.example/xyz")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	url, err := (&PasteUploader{Endpoint: server.URL}).Upload(context.Background(), "game.pgn", "*")
+	if err != nil || url != "https://paste.example/xyz" {
+		t.Errorf("Expected the Location URL, got %q (%v)", url, err)
+	}
+}
+
+func TestPasteUploaderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "too large", http.StatusRequestEntityTooLarge)
+	}))
+	defer server.Close()
+
+	if _, err := (&PasteUploader{Endpoint: server.URL}).Upload(context.Background(), "game.pgn", "*"); err == nil {
+		t.Error("Expected an error from a failing paste service")
+	}
+}
+
+func TestGistUploader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Expected the token, got %q", r.Header.Get("Authorization"))
+		}
+		var gist struct {
+			Public bool                         `json:"public"`
+			Files  map[string]map[string]string `json:"files"`
+		}
+		json.NewDecoder(r.Body).Decode(&gist)
+		if gist.Files["game.pgn"]["content"] != "1. d4 *" || gist.Public {
+			t.Errorf("Expected a secret gist with the PGN, got %+v", gist)
+		}
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"html_url": "https://gist.example/1"}`)
+	}))
+	defer server.Close()
+
+	uploader := &GistUploader{Token: "secret", API: server.URL}
+	url, err := uploader.Upload(context.Background(), "game.pgn", "1. d4 *")
+	if err != nil || url != "https://gist.example/1" {
+		t.Errorf("Expected the gist URL, got %q (%v)", url, err)
+	}
+}
+
+func TestNewNeedsConfiguration(t *testing.T) {
+	if _, err := New(Config{}); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("Expected ErrNotConfigured, got %v", err)
+	}
+	t.Setenv("GITHUB_TOKEN", "")
+	if _, err := New(Config{Gist: true}); err == nil {
+		t.Error("Expected an error for a gist without a token")
+	}
+	if uploader, err := New(Config{Endpoint: "https://paste.example"}); err != nil {
+		t.Errorf("Expected a paste uploader, got %v", err)
+	} else if _, ok := uploader.(*PasteUploader); !ok {
+		t.Errorf("Expected a paste uploader, got %T", uploader)
+	}
+}
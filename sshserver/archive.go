@@ -0,0 +1,111 @@
+package sshserver
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"chess-tui/gamedb"
+)
+
+// archiveDir returns where the server archives its games by default: beside
+// the player profiles, e.g. ~/.bubblechess/ssh-archive
+func archiveDir(dataDir string) string {
+	return filepath.Join(filepath.Dir(dataDir), "ssh-archive")
+}
+
+// ArchivedGame is a finished game as GET /archive lists it
+type ArchivedGame struct {
+	ID     string    `json:"id"`
+	Played time.Time `json:"played"`
+	White  string    `json:"white"`
+	Black  string    `json:"black"`
+	Result string    `json:"result"`
+	PGN    string    `json:"pgn"` // the download's path, e.g. /archive/20250301-142210-alex-vs-ai.pgn
+}
+
+// Archive lists the games every session has finished, newest first
+func (s *Server) Archive() ([]ArchivedGame, error) {
+	if s.archive == nil {
+		return nil, nil
+	}
+	entries, err := s.archive.Entries()
+	if err != nil {
+		return nil, err
+	}
+	games := make([]ArchivedGame, 0, len(entries))
+	for _, entry := range slices.Backward(entries) {
+		games = append(games, ArchivedGame{
+			ID:     entry.ID(),
+			Played: entry.Played,
+			White:  entry.White,
+			Black:  entry.Black,
+			Result: entry.Result,
+			PGN:    "/archive/" + entry.ID() + ".pgn",
+		})
+	}
+	return games, nil
+}
+
+// handleArchive serves the archive's games as JSON
+func (s *Server) handleArchive(w http.ResponseWriter, r *http.Request) {
+	if s.archive == nil {
+		http.Error(w, "the archive is disabled", http.StatusNotFound)
+		return
+	}
+	games, err := s.Archive()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(games)
+}
+
+// handleArchivedPGN serves one archived game's PGN as a download
+func (s *Server) handleArchivedPGN(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("file")
+	id, ok := strings.CutSuffix(name, ".pgn")
+	if !ok || s.archive == nil {
+		http.Error(w, "no such game", http.StatusNotFound)
+		return
+	}
+	entry, pgn, err := s.archive.Game(id)
+	if errors.Is(err, os.ErrNotExist) {
+		http.Error(w, "no such game", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-chess-pgn")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": entry.ID() + ".pgn"}))
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write([]byte(pgn))
+}
+
+// openArchive opens the archive the config describes and applies its
+// retention policy to the games already there, or returns nil when it is
+// disabled
+func openArchive(config Config) (*gamedb.Archive, error) {
+	if config.Archive.Disabled {
+		return nil, nil
+	}
+	policy := config.Archive
+	if policy.Dir == "" {
+		policy.Dir = archiveDir(config.DataDir)
+	}
+	archive := gamedb.OpenArchive(policy)
+	if err := archive.Prune(); err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
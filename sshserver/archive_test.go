@@ -0,0 +1,77 @@
+package sshserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"chess-tui/game"
+	"chess-tui/gamedb"
+)
+
+func TestArchiveEndpoints(t *testing.T) {
+	server, _ := startServer(t)
+	played := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+	server.archive.Save(gamedb.ArchiveEntry{Played: played, White: "alex", Black: "AI", Result: gamedb.WhiteWon}, "1. e4 1-0\n")
+	server.archive.Save(gamedb.ArchiveEntry{Played: played.Add(time.Hour), White: "AI", Black: "sam", Result: gamedb.Draw}, "1. d4 1/2-1/2\n")
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/archive", nil))
+	var games []ArchivedGame
+	if err := json.NewDecoder(rec.Body).Decode(&games); err != nil {
+		t.Fatalf("Expected the games as JSON, got %v", err)
+	}
+	if len(games) != 2 || games[0].Black != "sam" || games[1].PGN != "/archive/20250314-150926-alex-vs-ai.pgn" {
+		t.Fatalf("Expected both games, newest first, got %+v", games)
+	}
+
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest("GET", games[1].PGN, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "1. e4 1-0\n" {
+		t.Errorf("Expected alex's game, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != "application/x-chess-pgn" || rec.Header().Get("Content-Disposition") != "attachment; filename=20250314-150926-alex-vs-ai.pgn" {
+		t.Errorf("Expected a PGN download, got %v", rec.Header())
+	}
+
+	for _, path := range []string{"/archive/unknown.pgn", "/archive/20250314-150926-alex-vs-ai", "/archive/index.jsonl"} {
+		rec = httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected %s not to be found, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestArchiveRetentionAtStart(t *testing.T) {
+	dir := t.TempDir()
+	archive := gamedb.OpenArchive(gamedb.ArchivePolicy{Dir: filepath.Join(dir, "archive")})
+	archive.Save(gamedb.ArchiveEntry{Played: time.Now().AddDate(0, 0, -10), White: "a", Black: "b", Result: gamedb.Draw}, "1/2-1/2\n")
+	archive.Save(gamedb.ArchiveEntry{Played: time.Now(), White: "a", Black: "b", Result: gamedb.Draw}, "1/2-1/2\n")
+
+	server, err := New(Config{
+		HostKeyPath: filepath.Join(dir, "host_key"),
+		Settings:    game.DefaultSettings(),
+		DataDir:     filepath.Join(dir, "players"),
+		Archive:     gamedb.ArchivePolicy{Dir: filepath.Join(dir, "archive"), KeepDays: 7},
+	})
+	if err != nil {
+		t.Fatalf("Expected a server, got %v", err)
+	}
+	if games, _ := server.Archive(); len(games) != 1 {
+		t.Errorf("Expected the game past the retention to be dropped, got %+v", games)
+	}
+
+	disabled, err := New(Config{HostKeyPath: filepath.Join(dir, "host_key"), DataDir: filepath.Join(dir, "players"), Archive: gamedb.ArchivePolicy{Disabled: true}})
+	if err != nil {
+		t.Fatalf("Expected a server, got %v", err)
+	}
+	rec := httptest.NewRecorder()
+	disabled.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/archive", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected no archive when disabled, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,90 @@
+package sshserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"chess-tui/leaderboard"
+)
+
+// leaderboardRefresh is how long the leaderboard ranks the profiles as they
+// were read, so requests to it don't each reread every player's games
+const leaderboardRefresh = 30 * time.Second
+
+// playersCache holds the players the leaderboard last read from disk
+type playersCache struct {
+	mu      sync.Mutex
+	players []leaderboard.Player
+	read    time.Time
+}
+
+// Leaderboard ranks every player with a profile by their games against the
+// AI for period, as they were at most leaderboardRefresh ago
+func (s *Server) Leaderboard(period string) (leaderboard.Board, error) {
+	players, err := s.rankedPlayers()
+	if err != nil {
+		return leaderboard.Board{}, err
+	}
+	return leaderboard.Rank(players, period, time.Now(), s.config.Ratings)
+}
+
+// rankedPlayers returns every player with a profile and their games,
+// rereading them once those read are leaderboardRefresh old
+func (s *Server) rankedPlayers() ([]leaderboard.Player, error) {
+	s.players.mu.Lock()
+	defer s.players.mu.Unlock()
+	if !s.players.read.IsZero() && time.Since(s.players.read) < leaderboardRefresh {
+		return s.players.players, nil
+	}
+	players, err := readPlayers(s.config.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	s.players.players, s.players.read = players, time.Now()
+	return players, nil
+}
+
+// readPlayers reads every player's profile and games under dataDir
+func readPlayers(dataDir string) ([]leaderboard.Player, error) {
+	dirs, err := os.ReadDir(dataDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list player profiles: %w", err)
+	}
+
+	var players []leaderboard.Player
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		profile := &Profile{dir: filepath.Join(dataDir, dir.Name())}
+		data, err := os.ReadFile(profile.path("profile.json"))
+		if err != nil {
+			continue
+		}
+		if err := json.Unmarshal(data, profile); err != nil {
+			continue
+		}
+		records, err := profile.Games().Games()
+		if err != nil {
+			return nil, err
+		}
+		players = append(players, leaderboard.Player{Name: profile.Name, Games: records})
+	}
+	return players, nil
+}
+
+// Handler serves the leaderboard as JSON at GET /leaderboard, for embedding
+// on a web page, and the archive of finished games: GET /archive lists them
+// as JSON and GET /archive/{id}.pgn downloads one
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /leaderboard", leaderboard.Handler(s.Leaderboard))
+	mux.HandleFunc("GET /archive", s.handleArchive)
+	mux.HandleFunc("GET /archive/{file}", s.handleArchivedPGN)
+	return mux
+}
@@ -0,0 +1,70 @@
+package sshserver
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chess-tui/gamedb"
+	"chess-tui/leaderboard"
+)
+
+func TestLeaderboard(t *testing.T) {
+	server, _ := startServer(t)
+
+	alex, err := openProfile(server.config.DataDir, newKey(t).PublicKey(), "alex")
+	if err != nil {
+		t.Fatalf("Failed to open profile: %v", err)
+	}
+	sam, err := openProfile(server.config.DataDir, newKey(t).PublicKey(), "sam")
+	if err != nil {
+		t.Fatalf("Failed to open profile: %v", err)
+	}
+	now := time.Now()
+	alex.Games().Add(gamedb.Record{Played: now, HumanColor: "white", Opponent: "AI", Result: gamedb.WhiteWon})
+	alex.Games().Add(gamedb.Record{Played: now.AddDate(0, -1, 0), HumanColor: "white", Opponent: "AI", Result: gamedb.WhiteWon})
+	sam.Games().Add(gamedb.Record{Played: now, HumanColor: "black", Opponent: "AI", Result: gamedb.WhiteWon})
+	// Games between two people at one keyboard aren't rated
+	sam.Games().Add(gamedb.Record{Played: now, White: "Player 1", Black: "Player 2", Result: gamedb.BlackWon})
+
+	board, err := server.Leaderboard(leaderboard.PeriodAll)
+	if err != nil {
+		t.Fatalf("Failed to rank players: %v", err)
+	}
+	if len(board.Entries) != 2 || board.Entries[0].Name != "alex" || board.Entries[0].Wins != 2 || board.Entries[1].Games != 1 {
+		t.Errorf("Expected alex above sam with both wins, got %+v", board.Entries)
+	}
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/leaderboard?period=week", nil))
+	var week leaderboard.Board
+	if err := json.NewDecoder(rec.Body).Decode(&week); err != nil {
+		t.Fatalf("Expected a JSON leaderboard, got %v", err)
+	}
+	if week.Period != leaderboard.PeriodWeek || len(week.Entries) != 2 || week.Entries[0].Wins != 1 {
+		t.Errorf("Expected only this week's games, got %+v", week)
+	}
+}
+
+func TestLeaderboardRereadsProfilesOnlyAfterRefresh(t *testing.T) {
+	server, _ := startServer(t)
+	alex, err := openProfile(server.config.DataDir, newKey(t).PublicKey(), "alex")
+	if err != nil {
+		t.Fatalf("Failed to open profile: %v", err)
+	}
+	alex.Games().Add(gamedb.Record{Played: time.Now(), HumanColor: "white", Opponent: "AI", Result: gamedb.WhiteWon})
+	if board, _ := server.Leaderboard(leaderboard.PeriodAll); len(board.Entries) != 1 || board.Entries[0].Games != 1 {
+		t.Fatalf("Expected alex's game, got %+v", board.Entries)
+	}
+
+	alex.Games().Add(gamedb.Record{Played: time.Now(), HumanColor: "white", Opponent: "AI", Result: gamedb.Draw})
+	if board, _ := server.Leaderboard(leaderboard.PeriodAll); board.Entries[0].Games != 1 {
+		t.Errorf("Expected the profiles read a moment ago, got %+v", board.Entries)
+	}
+
+	server.players.read = server.players.read.Add(-leaderboardRefresh)
+	if board, _ := server.Leaderboard(leaderboard.PeriodAll); board.Entries[0].Games != 2 {
+		t.Errorf("Expected the profiles reread once stale, got %+v", board.Entries)
+	}
+}
@@ -0,0 +1,140 @@
+package sshserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"chess-tui/game"
+	"chess-tui/gamedb"
+	"chess-tui/tournament"
+
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// Profile is a player known by their SSH public key. Their games,
+// preferences and progress are kept in a directory of their own, so they
+// carry over between connections without a password.
+type Profile struct {
+	Name        string    `json:"name"`        // the SSH user name they last connected as
+	Fingerprint string    `json:"fingerprint"` // of their public key, as ssh-keygen -l shows it
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+
+	dir      string
+	prefs    *game.Preferences
+	settings *game.Settings // the session's, once set up
+}
+
+// DefaultDataDir returns where player profiles are kept by default
+func DefaultDataDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "ssh-players"
+	}
+	return filepath.Join(home, ".bubblechess", "ssh-players")
+}
+
+// profileID names a key's profile directory
+func profileID(key ssh.PublicKey) string {
+	sum := sha256.Sum256(key.Marshal())
+	return hex.EncodeToString(sum[:16])
+}
+
+// openProfile loads the profile of the player with key, creating it on
+// their first connection, and records that they connected as user
+func openProfile(dataDir string, key ssh.PublicKey, user string) (*Profile, error) {
+	dir := filepath.Join(dataDir, profileID(key))
+	profile := &Profile{dir: dir}
+
+	data, err := os.ReadFile(profile.path("profile.json"))
+	switch {
+	case os.IsNotExist(err):
+		profile.Fingerprint = gossh.FingerprintSHA256(key)
+		profile.FirstSeen = time.Now()
+	case err != nil:
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	default:
+		if err := json.Unmarshal(data, profile); err != nil {
+			return nil, fmt.Errorf("failed to decode profile: %w", err)
+		}
+	}
+	profile.Name = user
+	profile.LastSeen = time.Now()
+
+	profile.prefs, err = game.LoadPreferences(profile.path("preferences.json"))
+	if err != nil {
+		return nil, err
+	}
+	if err := profile.save(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// path returns the location of one of the profile's files
+func (p *Profile) path(name string) string {
+	return filepath.Join(p.dir, name)
+}
+
+// Games returns the player's game log
+func (p *Profile) Games() *gamedb.DB {
+	return gamedb.Open(p.path("games.jsonl"))
+}
+
+// Rating estimates the player's Elo from their games against the AI, or
+// returns nil if they have none
+func (p *Profile) Rating(ratings tournament.Ratings) *tournament.Rating {
+	records, err := p.Games().Games()
+	if err != nil {
+		return nil
+	}
+	rating, err := tournament.HumanRating(records, ratings)
+	if err != nil {
+		return nil
+	}
+	return &rating
+}
+
+// setUp points the menu at the player's files and shows who they are
+func (p *Profile) setUp(menu *game.Menu, settings *game.Settings, ratings tournament.Ratings) {
+	p.settings = settings
+	settings.PlayerName = p.Name
+	if p.prefs.Palette != "" {
+		settings.Palette = p.prefs.Palette
+	}
+	menu.SetGameDB(p.Games())
+	menu.SetPreferences(p.prefs)
+	menu.SetDailyPath(p.path("daily.json"))
+	menu.SetTutorial(p.path("tutorial.json"), game.DefaultProfile)
+	menu.SetPlayer(p.Name, p.Rating(ratings))
+}
+
+// close saves what the player chose this session, as they leave
+func (p *Profile) close() error {
+	p.LastSeen = time.Now()
+	if p.settings != nil {
+		p.prefs.Palette = p.settings.Palette
+	}
+	return p.save()
+}
+
+// save writes the profile and the player's preferences
+func (p *Profile) save() error {
+	if err := os.MkdirAll(p.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+	if err := os.WriteFile(p.path("profile.json"), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return game.SavePreferences(p.prefs, p.path("preferences.json"))
+}
@@ -0,0 +1,67 @@
+package sshserver
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"chess-tui/gamedb"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// newKey returns a fresh player key
+func newKey(t *testing.T) gossh.Signer {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate a key: %v", err)
+	}
+	signer, err := gossh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatalf("Failed to make a signer: %v", err)
+	}
+	return signer
+}
+
+func TestPlayersAreKnownByTheirKey(t *testing.T) {
+	server, addr := startServer(t)
+	key := newKey(t)
+
+	// A new player is unrated
+	session, output, keys := connect(t, addr, gossh.PublicKeys(key))
+	waitFor(t, "the menu", func() bool { return strings.Contains(output.String(), "Playing as tester (unrated)") })
+	keys("q")
+	session.Wait()
+	waitFor(t, "the session to end", func() bool { return server.Sessions() == 0 })
+
+	profile, err := openProfile(server.config.DataDir, key.PublicKey(), "tester")
+	if err != nil {
+		t.Fatalf("Expected the profile saved, got %v", err)
+	}
+	if profile.Fingerprint != gossh.FingerprintSHA256(key.PublicKey()) || time.Since(profile.FirstSeen) > time.Minute {
+		t.Errorf("Expected the key's fingerprint and first connection, got %+v", profile)
+	}
+
+	// Games they played are there the next time, with a rating
+	games := profile.Games()
+	for _, result := range []string{gamedb.WhiteWon, gamedb.Draw} {
+		games.Add(gamedb.Record{White: "tester", Black: "AI", HumanColor: "white", Opponent: "AI", Result: result})
+	}
+	_, output, _ = connect(t, addr, gossh.PublicKeys(key))
+	waitFor(t, "the rating", func() bool { return strings.Contains(output.String(), "Playing as tester, Elo") })
+	if !strings.Contains(output.String(), "over 2 games") {
+		t.Errorf("Expected the rating over both games, got %q", output.String())
+	}
+
+	// Another key is another player, and guests have no profile
+	_, output, _ = connect(t, addr, gossh.PublicKeys(newKey(t)))
+	waitFor(t, "the menu", func() bool { return strings.Contains(output.String(), "(unrated)") })
+	_, output, _ = connect(t, addr)
+	waitFor(t, "the menu", func() bool { return strings.Contains(output.String(), "Human vs Human") })
+	if strings.Contains(output.String(), "Playing as") {
+		t.Error("Expected a guest to play without a profile")
+	}
+}
@@ -0,0 +1,193 @@
+// Package sshserver serves the TUI over SSH: every connection gets a menu,
+// and games, of its own. Players who connect with a public key keep their
+// games, rating and preferences between connections; others play as guests.
+package sshserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"chess-tui/game"
+	"chess-tui/gamedb"
+	"chess-tui/tournament"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/activeterm"
+	"github.com/charmbracelet/wish/bubbletea"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// DefaultIdleTimeout is how long a session may go without input before it's closed
+const DefaultIdleTimeout = 30 * time.Minute
+
+// Config describes an SSH server
+type Config struct {
+	Addr        string         // host:port to listen on
+	HostKeyPath string         // the server's private key, created if missing
+	Settings    *game.Settings // display settings each session starts from
+	AIServer    string         // A2A server for Human vs AI games, "" for the default
+	IdleTimeout time.Duration  // 0 for DefaultIdleTimeout
+
+	DataDir string             // where player profiles are kept, "" for DefaultDataDir
+	Ratings tournament.Ratings // bench ratings of the AI opponents, to rate players against
+
+	// Archive is where every session's finished games are kept, and for how
+	// long; its Dir defaults to ssh-archive beside DataDir
+	Archive gamedb.ArchivePolicy
+}
+
+// profileKey finds a session's player profile in its context
+type profileKey struct{}
+
+// Server hosts the TUI for anyone who connects over SSH
+type Server struct {
+	config   Config
+	ssh      *ssh.Server
+	sessions atomic.Int64
+	archive  *gamedb.Archive // nil when archiving is disabled
+	players  playersCache    // the profiles the leaderboard ranks
+}
+
+// DefaultHostKeyPath returns where the server's key is kept by default
+func DefaultHostKeyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "ssh_host_ed25519"
+	}
+	return filepath.Join(home, ".bubblechess", "ssh_host_ed25519")
+}
+
+// New creates a server from config, generating its host key if there is none
+func New(config Config) (*Server, error) {
+	if config.HostKeyPath == "" {
+		config.HostKeyPath = DefaultHostKeyPath()
+	}
+	if config.Settings == nil {
+		config.Settings = game.DefaultSettings()
+	}
+	if config.IdleTimeout == 0 {
+		config.IdleTimeout = DefaultIdleTimeout
+	}
+	if config.DataDir == "" {
+		config.DataDir = DefaultDataDir()
+	}
+	if err := os.MkdirAll(filepath.Dir(config.HostKeyPath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create host key directory: %w", err)
+	}
+
+	archive, err := openArchive(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open game archive: %w", err)
+	}
+
+	s := &Server{config: config, archive: archive}
+	server, err := wish.NewServer(
+		wish.WithAddress(config.Addr),
+		wish.WithHostKeyPath(config.HostKeyPath),
+		wish.WithIdleTimeout(config.IdleTimeout),
+		// Any key identifies its player; without one, anyone may play as a guest
+		wish.WithPublicKeyAuth(func(ssh.Context, ssh.PublicKey) bool { return true }),
+		wish.WithKeyboardInteractiveAuth(func(ssh.Context, gossh.KeyboardInteractiveChallenge) bool { return true }),
+		wish.WithPasswordAuth(func(ssh.Context, string) bool { return true }),
+		wish.WithMiddleware(
+			bubbletea.Middleware(s.newSession),
+			activeterm.Middleware(), // a game needs a terminal
+			s.track,
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SSH server: %w", err)
+	}
+	s.ssh = server
+	return s, nil
+}
+
+// ListenAndServe listens on the configured address and serves sessions
+// until Shutdown
+func (s *Server) ListenAndServe() error {
+	if err := s.ssh.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return fmt.Errorf("failed to serve SSH: %w", err)
+	}
+	return nil
+}
+
+// Serve serves sessions on listener until Shutdown
+func (s *Server) Serve(listener net.Listener) error {
+	if err := s.ssh.Serve(listener); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return fmt.Errorf("failed to serve SSH: %w", err)
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for the sessions to end,
+// or ctx to
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.ssh.Shutdown(ctx)
+}
+
+// Sessions returns the number of players connected
+func (s *Server) Sessions() int {
+	return int(s.sessions.Load())
+}
+
+// track counts and logs the sessions
+func (s *Server) track(next ssh.Handler) ssh.Handler {
+	return func(sess ssh.Session) {
+		s.sessions.Add(1)
+		started := time.Now()
+		slog.Info("SSH session started", "user", sess.User(), "remote", sess.RemoteAddr())
+		defer func() {
+			s.sessions.Add(-1)
+			slog.Info("SSH session ended", "user", sess.User(), "remote", sess.RemoteAddr(), "duration", time.Since(started).Round(time.Second))
+		}()
+		next(sess)
+
+		if profile, ok := sess.Context().Value(profileKey{}).(*Profile); ok {
+			if err := profile.close(); err != nil {
+				slog.Warn("Failed to save player profile", "user", sess.User(), "error", err)
+			}
+		}
+	}
+}
+
+// newSession creates the menu a connection starts at. Each session has its
+// own copy of the settings, since the menu changes them as choices are made,
+// without the operator's hooks and credentials, and with the actions that
+// would write files or upload from the server turned off.
+func (s *Server) newSession(sess ssh.Session) (tea.Model, []tea.ProgramOption) {
+	settings := s.config.Settings.ForRemote()
+	if s.config.AIServer != "" {
+		settings.AIServer = s.config.AIServer
+	}
+
+	menu := game.NewMenuWithSettings(settings)
+	menu.SetContext(sess.Context())
+	menu.SetLeaderboard(s.Leaderboard)
+	if s.archive != nil {
+		menu.SetArchive(s.archive)
+	}
+	if key := sess.PublicKey(); key != nil {
+		profile, err := openProfile(s.config.DataDir, key, sess.User())
+		if err != nil {
+			slog.Warn("Failed to open player profile; playing as a guest", "user", sess.User(), "error", err)
+		} else {
+			profile.setUp(menu, settings, s.config.Ratings)
+			sess.Context().SetValue(profileKey{}, profile)
+		}
+	}
+
+	opts := []tea.ProgramOption{tea.WithReportFocus()}
+	if settings.LowBandwidth {
+		opts = append(opts, tea.WithFPS(game.LowBandwidthFPS))
+	}
+	return menu, opts
+}
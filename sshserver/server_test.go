@@ -0,0 +1,175 @@
+package sshserver
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"chess-tui/game"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// syncBuffer collects a session's output as it arrives
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitFor waits for check to pass, or fails the test after a few seconds
+func waitFor(t *testing.T, what string, check func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !check() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// readyListener closes ready on the first Accept, by which time Serve has
+// registered the listener and Shutdown can't race with it
+type readyListener struct {
+	net.Listener
+	once  sync.Once
+	ready chan struct{}
+}
+
+func (l *readyListener) Accept() (net.Conn, error) {
+	l.once.Do(func() { close(l.ready) })
+	return l.Listener.Accept()
+}
+
+// startServer serves on a free local port until the test ends
+func startServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	server, err := New(Config{
+		HostKeyPath: filepath.Join(dir, "host_key"),
+		Settings:    game.DefaultSettings(),
+		DataDir:     filepath.Join(dir, "players"),
+	})
+	if err != nil {
+		t.Fatalf("Expected a server, got %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	ready := &readyListener{Listener: listener, ready: make(chan struct{})}
+	go server.Serve(ready)
+	select {
+	case <-ready.ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the server to accept connections")
+	}
+	t.Cleanup(func() {
+		listener.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+		// Sessions save their profiles as they end, before TempDir goes
+		waitFor(t, "the sessions to end", func() bool { return server.Sessions() == 0 })
+	})
+	return server, listener.Addr().String()
+}
+
+// connect opens a terminal session on the server as a guest, or as the
+// player with the key given
+func connect(t *testing.T, addr string, auth ...gossh.AuthMethod) (*gossh.Session, *syncBuffer, func(string)) {
+	t.Helper()
+	if len(auth) == 0 {
+		auth = []gossh.AuthMethod{gossh.Password("")}
+	}
+	client, err := gossh.Dial("tcp", addr, &gossh.ClientConfig{
+		User:            "tester",
+		Auth:            auth,
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Expected to connect, got %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatalf("Expected a session, got %v", err)
+	}
+	output := &syncBuffer{}
+	session.Stdout = output
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		t.Fatalf("Expected stdin, got %v", err)
+	}
+	if err := session.RequestPty("xterm-256color", 40, 100, gossh.TerminalModes{}); err != nil {
+		t.Fatalf("Expected a terminal, got %v", err)
+	}
+	if err := session.Shell(); err != nil {
+		t.Fatalf("Expected a shell, got %v", err)
+	}
+	return session, output, func(keys string) { stdin.Write([]byte(keys)) }
+}
+
+func TestSessionsGetTheirOwnMenu(t *testing.T) {
+	server, addr := startServer(t)
+
+	first, firstOutput, firstKeys := connect(t, addr)
+	_, secondOutput, _ := connect(t, addr)
+	for _, output := range []*syncBuffer{firstOutput, secondOutput} {
+		waitFor(t, "the menu", func() bool { return strings.Contains(output.String(), "Human vs Human") })
+	}
+	if got := server.Sessions(); got != 2 {
+		t.Errorf("Expected 2 sessions, got %d", got)
+	}
+
+	// Starting a game in one session leaves the other at the menu
+	firstKeys("\r")
+	waitFor(t, "the game", func() bool { return strings.Contains(firstOutput.String(), "White to move") })
+	if strings.Contains(secondOutput.String(), "White to move") {
+		t.Error("Expected the second session still at the menu")
+	}
+
+	firstKeys("q")
+	first.Wait()
+	waitFor(t, "the session to end", func() bool { return server.Sessions() == 1 })
+}
+
+func TestSessionWithoutTerminalIsRefused(t *testing.T) {
+	_, addr := startServer(t)
+	client, err := gossh.Dial("tcp", addr, &gossh.ClientConfig{
+		User:            "tester",
+		Auth:            []gossh.AuthMethod{gossh.Password("")},
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+	})
+	if err != nil {
+		t.Fatalf("Expected to connect, got %v", err)
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatalf("Expected a session, got %v", err)
+	}
+	output, _ := session.CombinedOutput("")
+	if !strings.Contains(string(output), "PTY") {
+		t.Errorf("Expected to be told a terminal is needed, got %q", output)
+	}
+}
@@ -0,0 +1,100 @@
+package tournament
+
+import (
+	"fmt"
+
+	"github.com/notnil/chess"
+)
+
+// Adjudication configures when a game is ended early. Evaluations are in
+// centipawns from the mover's point of view; a zero field disables its rule.
+type Adjudication struct {
+	// NoProgressMoves draws the game after this many full moves without a
+	// capture or pawn move
+	NoProgressMoves int `json:"no_progress_moves,omitempty"`
+
+	// From move DrawAfterMove on, the game is drawn once both sides have
+	// reported an eval within DrawEval of zero for DrawEvalMoves moves
+	DrawAfterMove int `json:"draw_after_move,omitempty"`
+	DrawEval      int `json:"draw_eval,omitempty"`
+	DrawEvalMoves int `json:"draw_eval_moves,omitempty"`
+
+	// A side resigns after reporting an eval of -ResignEval or worse for
+	// ResignMoves consecutive moves
+	ResignEval  int `json:"resign_eval,omitempty"`
+	ResignMoves int `json:"resign_moves,omitempty"`
+}
+
+// DefaultAdjudication returns rules similar to common engine tournament settings
+func DefaultAdjudication() Adjudication {
+	return Adjudication{
+		NoProgressMoves: 50,
+		DrawAfterMove:   40,
+		DrawEval:        20,
+		DrawEvalMoves:   8,
+		ResignEval:      1000,
+		ResignMoves:     5,
+	}
+}
+
+// adjudicator applies Adjudication rules over the course of a game
+type adjudicator struct {
+	rules Adjudication
+	evals map[chess.Color][]int
+}
+
+// newAdjudicator creates an adjudicator for one game
+func newAdjudicator(rules Adjudication) *adjudicator {
+	return &adjudicator{
+		rules: rules,
+		evals: make(map[chess.Color][]int),
+	}
+}
+
+// check records the mover's eval after its move and returns a decided
+// outcome with the reason, or chess.NoOutcome to play on
+func (a *adjudicator) check(position *chess.Position, mover chess.Color, moveNumber int, eval int) (chess.Outcome, string) {
+	a.evals[mover] = append(a.evals[mover], eval)
+
+	if n := a.rules.NoProgressMoves; n > 0 && position.HalfMoveClock() >= 2*n {
+		return chess.Draw, fmt.Sprintf("no progress in %d moves", n)
+	}
+
+	if n := a.rules.ResignMoves; n > 0 && a.rules.ResignEval > 0 {
+		if recent := lastN(a.evals[mover], n); recent != nil && all(recent, func(e int) bool { return e <= -a.rules.ResignEval }) {
+			winner := chess.WhiteWon
+			if mover == chess.White {
+				winner = chess.BlackWon
+			}
+			return winner, fmt.Sprintf("%s's position is hopeless", mover.Name())
+		}
+	}
+
+	if n := a.rules.DrawEvalMoves; n > 0 && moveNumber >= a.rules.DrawAfterMove {
+		nearZero := func(e int) bool { return e >= -a.rules.DrawEval && e <= a.rules.DrawEval }
+		white, black := lastN(a.evals[chess.White], n), lastN(a.evals[chess.Black], n)
+		if white != nil && black != nil && all(white, nearZero) && all(black, nearZero) {
+			return chess.Draw, fmt.Sprintf("both sides report a level position for %d moves", n)
+		}
+	}
+
+	return chess.NoOutcome, ""
+}
+
+// lastN returns the last n values, or nil if there are fewer
+func lastN(values []int, n int) []int {
+	if len(values) < n {
+		return nil
+	}
+	return values[len(values)-n:]
+}
+
+// all reports whether every value satisfies the predicate
+func all(values []int, predicate func(int) bool) bool {
+	for _, v := range values {
+		if !predicate(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package tournament
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/notnil/chess"
+)
+
+// clockCommandRe finds a %clk command in a PGN comment, e.g. "[%clk 0:09:42]"
+// or, with tenths, "[%clk 0:00:07.3]"
+var clockCommandRe = regexp.MustCompile(`\[%clk\s+(\d+):(\d{1,2}):(\d{1,2}(?:\.\d+)?)\]`)
+
+// Clocks returns each side's time left after every move, indexed by ply, as
+// PGN %clk commands record it: what is left of the per-game limit, or for a
+// side with only a per-move limit, what was left of it when the move was
+// made. It is -1 for the moves of a side without a limit.
+func (u TimeUsage) Clocks(control TimeControl) []time.Duration {
+	clocks := make([]time.Duration, len(u))
+	used := map[chess.Color]time.Duration{}
+	for ply, elapsed := range u {
+		color := chess.White
+		if ply%2 == 1 {
+			color = chess.Black
+		}
+		used[color] += elapsed
+		limits := control.For(color)
+		switch {
+		case limits.PerGame > 0:
+			clocks[ply] = max(limits.PerGame-used[color], 0)
+		case limits.PerMove > 0:
+			clocks[ply] = max(limits.PerMove-elapsed, 0)
+		default:
+			clocks[ply] = -1
+		}
+	}
+	return clocks
+}
+
+// ClockComment writes a clock reading as a PGN %clk command, e.g.
+// "[%clk 0:09:42]", to whole seconds as most tools do
+func ClockComment(left time.Duration) string {
+	seconds := int(left / time.Second)
+	return fmt.Sprintf("[%%clk %d:%02d:%02d]", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// ParseClockComment reads the clock reading of the %clk command in a PGN
+// comment; ok is false if it has none
+func ParseClockComment(comment string) (left time.Duration, ok bool) {
+	match := clockCommandRe.FindStringSubmatch(comment)
+	if match == nil {
+		return 0, false
+	}
+	hours, _ := strconv.Atoi(match[1])
+	minutes, _ := strconv.Atoi(match[2])
+	seconds, err := strconv.ParseFloat(match[3], 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second)), true
+}
@@ -0,0 +1,100 @@
+package tournament
+
+import (
+	"chess-tui/gamedb"
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// DatasetScore is how a player did choosing moves in a dataset's positions
+type DatasetScore struct {
+	Positions int `json:"positions"`
+	Matched   int `json:"matched"` // chose the dataset's move
+	Illegal   int `json:"illegal"` // answered with a move that isn't legal
+	Failed    int `json:"failed"`  // gave no usable answer, e.g. timed out
+}
+
+// Accuracy is the fraction of positions in which the player chose the
+// dataset's move
+func (s DatasetScore) Accuracy() float64 {
+	if s.Positions == 0 {
+		return 0
+	}
+	return float64(s.Matched) / float64(s.Positions)
+}
+
+// IllegalRate is the fraction of answers that were illegal moves
+func (s DatasetScore) IllegalRate() float64 {
+	answered := s.Positions - s.Failed
+	if answered == 0 {
+		return 0
+	}
+	return float64(s.Illegal) / float64(answered)
+}
+
+// EvaluateDataset asks player for a move in each sample's position and
+// scores the answers against the moves recorded. progress, if set, is
+// called after each position.
+func EvaluateDataset(player Player, samples []gamedb.Sample, progress func(done int, score DatasetScore)) DatasetScore {
+	var score DatasetScore
+	for i, sample := range samples {
+		score.Positions++
+		move, err := player.GetMove(sample.FEN, nil)
+		if err != nil {
+			score.Failed++
+		} else if san, err := notation.Normalize(sample.FEN, move.Notation); err != nil {
+			score.Illegal++
+		} else if san == sample.Move {
+			score.Matched++
+		}
+		if progress != nil {
+			progress(i+1, score)
+		}
+	}
+	return score
+}
+
+// Samples returns a training sample for every move of the game, each
+// credited to the player who made it, so self-play games become training
+// data for both sides
+func (r *GameResult) Samples() []gamedb.Sample {
+	result := r.Outcome.String()
+	game := chess.NewGame()
+
+	var samples []gamedb.Sample
+	for _, san := range r.Moves {
+		position := game.Position()
+		move, err := notation.Decode(position, san)
+		if err != nil {
+			break
+		}
+		legal := position.ValidMoves()
+		legalMoves := make([]string, len(legal))
+		for i, m := range legal {
+			legalMoves[i] = notation.Encode(position, m)
+		}
+		side, player := "white", r.White
+		if position.Turn() == chess.Black {
+			side, player = "black", r.Black
+		}
+		fen := position.String()
+		samples = append(samples, gamedb.Sample{
+			FEN:        fen,
+			LegalMoves: legalMoves,
+			Move:       san,
+			MoveUCI:    notation.EncodeUCI(move),
+			Side:       side,
+			Player:     player,
+			Outcome:    gamedb.OutcomeFor(result, side),
+			Result:     result,
+			GameID:     r.ID,
+			Prompt:     gamedb.SamplePrompt(fen, legalMoves),
+			Completion: " " + san,
+		})
+		if err := game.Move(move); err != nil {
+			break
+		}
+	}
+	return samples
+}
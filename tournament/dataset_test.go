@@ -0,0 +1,60 @@
+package tournament
+
+import (
+	"testing"
+
+	"chess-tui/gamedb"
+)
+
+func TestEvaluateDataset(t *testing.T) {
+	const start = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	samples := []gamedb.Sample{
+		{FEN: start, Move: "e4"},
+		{FEN: start, Move: "Nf3"},
+		{FEN: start, Move: "d4"},
+		{FEN: start, Move: "c4"},
+	}
+	// Matches in UCI, misses, answers illegally, then runs out of moves
+	player := &scriptedPlayer{moves: []string{"e2e4", "d4", "e5"}}
+
+	calls := 0
+	score := EvaluateDataset(player, samples, func(done int, score DatasetScore) { calls++ })
+	if score.Positions != 4 || score.Matched != 1 || score.Illegal != 1 || score.Failed != 1 {
+		t.Errorf("Expected 4 positions, 1 matched, 1 illegal, 1 failed, got %+v", score)
+	}
+	if score.Accuracy() != 0.25 {
+		t.Errorf("Expected 25%% accuracy, got %v", score.Accuracy())
+	}
+	if rate := score.IllegalRate(); rate < 0.33 || rate > 0.34 {
+		t.Errorf("Expected a third of the answers illegal, got %v", rate)
+	}
+	if calls != 4 {
+		t.Errorf("Expected progress after each position, got %d calls", calls)
+	}
+	if (DatasetScore{}).Accuracy() != 0 || (DatasetScore{}).IllegalRate() != 0 {
+		t.Errorf("Expected an empty score to be zero")
+	}
+}
+
+func TestGameResultSamples(t *testing.T) {
+	white := Entrant{Name: "w", Player: &scriptedPlayer{moves: []string{"f3", "g4"}}}
+	black := Entrant{Name: "b", Player: &scriptedPlayer{moves: []string{"e5", "Qh4#"}}}
+	result, err := NewMatch(Adjudication{}).PlayGame(white, black)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	samples := result.Samples()
+	if len(samples) != 4 {
+		t.Fatalf("Expected a sample for each of the 4 moves, got %d", len(samples))
+	}
+	if samples[0].Player != "w" || samples[0].Side != "white" || samples[0].Outcome != gamedb.OutcomeLoss || samples[0].MoveUCI != "f2f3" {
+		t.Errorf("Expected white's losing f3 first, got %+v", samples[0])
+	}
+	if last := samples[3]; last.Player != "b" || last.Move != "Qh4#" || last.Outcome != gamedb.OutcomeWin || last.GameID != result.ID {
+		t.Errorf("Expected black's winning Qh4# last, got %+v", last)
+	}
+	if len(samples[0].LegalMoves) != 20 || samples[0].Completion != " f3" {
+		t.Errorf("Expected the 20 legal opening moves and the completion, got %+v", samples[0])
+	}
+}
@@ -0,0 +1,104 @@
+package tournament
+
+import (
+	"fmt"
+
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// Divergence is a position of a stored game in which the player now
+// chooses a different move than the one played
+type Divergence struct {
+	Ply    int    `json:"ply"` // moves played before the position
+	FEN    string `json:"fen"`
+	Color  string `json:"color"`
+	Played string `json:"played"`          // the move in the game, in SAN
+	Chosen string `json:"chosen"`          // the player's move now, in SAN if legal
+	Error  string `json:"error,omitempty"` // e.g. "illegal move"
+}
+
+// DivergenceReport is how closely a player repeats the moves of a stored game
+type DivergenceReport struct {
+	Positions   int          `json:"positions"`
+	Failed      int          `json:"failed"` // gave no answer, e.g. timed out
+	Divergences []Divergence `json:"divergences,omitempty"`
+}
+
+// Rate is the fraction of the player's answers that diverged
+func (r DivergenceReport) Rate() float64 {
+	answered := r.Positions - r.Failed
+	if answered == 0 {
+		return 0
+	}
+	return float64(len(r.Divergences)) / float64(answered)
+}
+
+// FindDivergences replays a game's moves from startFEN, or the standard
+// position if empty, asking player for a move in each position where side
+// is to move, or in every position if side is chess.NoColor. Answers that
+// differ from the move played or are illegal are divergences; positions the
+// player gives no answer in only count as failed.
+// progress, if set, is called after each position asked about.
+func FindDivergences(player Player, startFEN string, moves []string, side chess.Color, progress func(done int, report DivergenceReport)) (DivergenceReport, error) {
+	options := []func(*chess.Game){chess.UseNotation(chess.AlgebraicNotation{})}
+	if startFEN != "" {
+		fen, err := chess.FEN(startFEN)
+		if err != nil {
+			return DivergenceReport{}, fmt.Errorf("failed to parse start position: %w", err)
+		}
+		options = append(options, fen)
+	}
+	game := chess.NewGame(options...)
+
+	var report DivergenceReport
+	for ply, san := range moves {
+		position := game.Position()
+		move, err := notation.Decode(position, san)
+		if err != nil {
+			return report, fmt.Errorf("failed to replay move %d (%s): %w", ply+1, san, err)
+		}
+
+		if side == chess.NoColor || position.Turn() == side {
+			report.Positions++
+			divergence, err := divergesAt(player, position, moves[:ply], san)
+			switch {
+			case err != nil:
+				report.Failed++
+			case divergence != nil:
+				divergence.Ply = ply
+				report.Divergences = append(report.Divergences, *divergence)
+			}
+			if progress != nil {
+				progress(report.Positions, report)
+			}
+		}
+
+		if err := game.Move(move); err != nil {
+			return report, fmt.Errorf("failed to replay move %d (%s): %w", ply+1, san, err)
+		}
+	}
+	return report, nil
+}
+
+// divergesAt asks player for a move in position and returns the divergence
+// if it differs from played, or nil if it doesn't
+func divergesAt(player Player, position *chess.Position, history []string, played string) (*Divergence, error) {
+	fen := position.String()
+	reply, err := player.GetMove(fen, append([]string(nil), history...))
+	if err != nil {
+		return nil, err
+	}
+	divergence := &Divergence{FEN: fen, Color: position.Turn().Name(), Played: played, Chosen: reply.Notation}
+	san, err := notation.Normalize(fen, reply.Notation)
+	if err != nil {
+		divergence.Error = "illegal move"
+		return divergence, nil
+	}
+	if san == played {
+		return nil, nil
+	}
+	divergence.Chosen = san
+	return divergence, nil
+}
@@ -0,0 +1,45 @@
+package tournament
+
+import (
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestFindDivergences(t *testing.T) {
+	moves := []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "a6"}
+
+	// Black now repeats e5 in UCI, answers Nc6 with Nf6, then an illegal move
+	player := &scriptedPlayer{moves: []string{"e7e5", "Nf6", "O-O"}}
+	calls := 0
+	report, err := FindDivergences(player, "", moves, chess.Black, func(done int, report DivergenceReport) { calls++ })
+	if err != nil {
+		t.Fatalf("Expected the game to replay, got %v", err)
+	}
+	if report.Positions != 3 || calls != 3 {
+		t.Errorf("Expected Black's 3 positions to be asked about, got %d (%d calls)", report.Positions, calls)
+	}
+	if len(report.Divergences) != 2 {
+		t.Fatalf("Expected 2 divergences, got %+v", report.Divergences)
+	}
+	first, second := report.Divergences[0], report.Divergences[1]
+	if first.Ply != 3 || first.Played != "Nc6" || first.Chosen != "Nf6" || first.Error != "" {
+		t.Errorf("Expected Nf6 instead of Nc6 at ply 3, got %+v", first)
+	}
+	if second.Ply != 5 || second.Chosen != "O-O" || second.Error != "illegal move" {
+		t.Errorf("Expected an illegal O-O at ply 5, got %+v", second)
+	}
+	if rate := report.Rate(); rate < 0.66 || rate > 0.67 {
+		t.Errorf("Expected two thirds diverged, got %v", rate)
+	}
+
+	// Both sides, with a player that runs out of moves
+	report, err = FindDivergences(&scriptedPlayer{moves: moves[:2]}, "", moves, chess.NoColor, nil)
+	if err != nil || report.Positions != 6 || report.Failed != 4 || len(report.Divergences) != 0 || report.Rate() != 0 {
+		t.Errorf("Expected the last 4 of 6 positions to fail without diverging, got %+v, %v", report, err)
+	}
+
+	if _, err := FindDivergences(player, "", []string{"e4", "e4"}, chess.NoColor, nil); err == nil {
+		t.Error("Expected an error replaying an illegal game")
+	}
+}
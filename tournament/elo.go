@@ -0,0 +1,156 @@
+package tournament
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"chess-tui/gamedb"
+)
+
+// RatedGame is one game's score against an opponent of known strength
+type RatedGame struct {
+	OpponentElo int
+	Score       float64 // 1 for a win, 0.5 for a draw, 0 for a loss
+}
+
+// EstimateElo returns the performance rating that best explains the scores:
+// the rating whose expected score against the opponents equals the actual
+// score. Perfect and zero scores are treated as a quarter point short of them
+// so the estimate stays finite.
+func EstimateElo(games []RatedGame) (int, error) {
+	if len(games) == 0 {
+		return 0, fmt.Errorf("no rated games")
+	}
+
+	score := 0.0
+	for _, game := range games {
+		score += game.Score
+	}
+	n := float64(len(games))
+	score = math.Min(math.Max(score, 0.25), n-0.25)
+
+	expected := func(rating float64) float64 {
+		total := 0.0
+		for _, game := range games {
+			total += 1 / (1 + math.Pow(10, (float64(game.OpponentElo)-rating)/400))
+		}
+		return total
+	}
+
+	// The expected score rises with the rating, so bisect
+	low, high := -1000.0, 5000.0
+	for high-low > 0.5 {
+		mid := (low + high) / 2
+		if expected(mid) < score {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return int(math.Round((low + high) / 2)), nil
+}
+
+// Rating is a saved Elo estimate for one model and prompt
+type Rating struct {
+	Elo     int       `json:"elo"`
+	Games   int       `json:"games"`
+	Updated time.Time `json:"updated"`
+
+	// Dataset is the score on held-out positions of a collected dataset,
+	// for fine-tuned models benched with one
+	Dataset *DatasetScore `json:"dataset,omitempty"`
+}
+
+// Ratings maps a player name to its latest estimate
+type Ratings map[string]Rating
+
+// DefaultRatingsPath returns the ratings file location in the user's config directory
+func DefaultRatingsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "ratings.json"
+	}
+	return filepath.Join(home, ".bubblechess", "ratings.json")
+}
+
+// LoadRatings loads saved ratings, returning none if the file doesn't exist
+func LoadRatings(path string) (Ratings, error) {
+	if path == "" {
+		path = DefaultRatingsPath()
+	}
+
+	ratings := make(Ratings)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ratings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ratings file: %w", err)
+	}
+	if err := json.Unmarshal(data, &ratings); err != nil {
+		return nil, fmt.Errorf("failed to decode ratings file: %w", err)
+	}
+	return ratings, nil
+}
+
+// SaveRatings writes ratings to a file
+func SaveRatings(ratings Ratings, path string) error {
+	if path == "" {
+		path = DefaultRatingsPath()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create ratings directory: %w", err)
+	}
+	data, err := json.MarshalIndent(ratings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode ratings: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write ratings file: %w", err)
+	}
+	return nil
+}
+
+// DefaultOpponentElo is the strength assumed of an AI opponent without a
+// bench rating
+const DefaultOpponentElo = 1500
+
+// HumanRating estimates the human's Elo from their games against the AI in
+// a game log, taking each opponent's strength from its bench rating
+func HumanRating(records []gamedb.Record, ratings Ratings) (Rating, error) {
+	var games []RatedGame
+	var updated time.Time
+	for _, record := range records {
+		if record.HumanColor == "" {
+			continue
+		}
+		var score float64
+		switch record.Result {
+		case gamedb.Draw:
+			score = 0.5
+		case gamedb.WhiteWon, gamedb.BlackWon:
+			if (record.Result == gamedb.WhiteWon) == (record.HumanColor == "white") {
+				score = 1
+			}
+		default:
+			continue
+		}
+		opponentElo := DefaultOpponentElo
+		if rating, ok := ratings[record.Opponent]; ok && rating.Elo != 0 {
+			opponentElo = rating.Elo
+		}
+		games = append(games, RatedGame{OpponentElo: opponentElo, Score: score})
+		updated = record.Played
+	}
+
+	elo, err := EstimateElo(games)
+	if err != nil {
+		return Rating{}, err
+	}
+	return Rating{Elo: elo, Games: len(games), Updated: updated}, nil
+}
@@ -0,0 +1,175 @@
+package tournament
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chess-tui/gamedb"
+)
+
+func TestEstimateElo(t *testing.T) {
+	tests := []struct {
+		name     string
+		games    []RatedGame
+		min, max int
+	}{
+		{"even score", []RatedGame{{1500, 1}, {1500, 0}}, 1500, 1500},
+		{"beats weaker, loses to stronger", []RatedGame{{1300, 1}, {1700, 0}}, 1500, 1500},
+		{"perfect score stays finite", []RatedGame{{1500, 1}, {1500, 1}}, 1600, 2000},
+		{"zero score stays finite", []RatedGame{{1500, 0}, {1500, 0}}, 1000, 1400},
+	}
+
+	for _, tt := range tests {
+		elo, err := EstimateElo(tt.games)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.name, err)
+		}
+		if elo < tt.min || elo > tt.max {
+			t.Errorf("%s: expected Elo in [%d, %d], got %d", tt.name, tt.min, tt.max, elo)
+		}
+	}
+
+	if _, err := EstimateElo(nil); err == nil {
+		t.Error("Expected an error for no games")
+	}
+}
+
+func TestHumanRating(t *testing.T) {
+	records := []gamedb.Record{
+		{HumanColor: "white", Opponent: "Strong", Result: gamedb.WhiteWon},
+		{HumanColor: "black", Opponent: "Strong", Result: gamedb.WhiteWon},
+		{HumanColor: "black", Opponent: "Unrated", Result: gamedb.Draw},
+		{White: "Human", Black: "Human", Result: gamedb.WhiteWon}, // not against the AI
+		{HumanColor: "white", Opponent: "Unrated", Result: "*"},   // unfinished
+	}
+	rating, err := HumanRating(records, Ratings{"Strong": {Elo: 1900}})
+	if err != nil {
+		t.Fatalf("Expected a rating, got %v", err)
+	}
+	if rating.Games != 3 {
+		t.Errorf("Expected 3 rated games, got %d", rating.Games)
+	}
+	// Half the points against two 1900 opponents and one unrated at 1500
+	if rating.Elo < 1700 || rating.Elo > 1850 {
+		t.Errorf("Expected an Elo near the opponents' average, got %d", rating.Elo)
+	}
+
+	if _, err := HumanRating(records[3:], nil); err == nil {
+		t.Error("Expected an error without games against the AI")
+	}
+}
+
+func TestRatingsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratings.json")
+
+	ratings, err := LoadRatings(path)
+	if err != nil || len(ratings) != 0 {
+		t.Fatalf("Expected no ratings from a missing file, got %v (%v)", ratings, err)
+	}
+
+	ratings["ollama:llama3.2:3b"] = Rating{Elo: 1234, Games: 6}
+	if err := SaveRatings(ratings, path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	loaded, err := LoadRatings(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loaded["ollama:llama3.2:3b"].Elo != 1234 {
+		t.Errorf("Expected Elo 1234, got %v", loaded)
+	}
+}
+
+// TestMain lets the test binary stand in for a UCI engine
+func TestMain(m *testing.M) {
+	if os.Getenv("FAKE_UCI_ENGINE") == "1" {
+		fakeUCIEngine()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// fakeUCIEngine speaks just enough UCI to always play a3, then a4
+func fakeUCIEngine() {
+	scanner := bufio.NewScanner(os.Stdin)
+	moves := 0
+	for scanner.Scan() {
+		switch line := scanner.Text(); {
+		case line == "uci":
+			fmt.Println("id name fake")
+			fmt.Println("uciok")
+		case line == "isready":
+			fmt.Println("readyok")
+		case strings.HasPrefix(line, "go"):
+			fmt.Println("info depth 1 score cp 0")
+			fmt.Println("info depth 2 seldepth 3 score cp 35 nodes 120 pv e2e4")
+			fmt.Println([]string{"bestmove a2a3", "bestmove a3a4"}[moves%2])
+			moves++
+		case line == "quit":
+			return
+		}
+	}
+}
+
+func TestUCIEngine(t *testing.T) {
+	t.Setenv("FAKE_UCI_ENGINE", "1")
+	engine, err := NewUCIEngine(os.Args[0], 1500)
+	if err != nil {
+		t.Fatalf("Expected the engine to start, got %v", err)
+	}
+	defer engine.Close()
+
+	if err := engine.NewGame(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	move, err := engine.GetMove("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", nil)
+	if err != nil {
+		t.Fatalf("Expected a move, got %v", err)
+	}
+	if move.Notation != "a2a3" {
+		t.Errorf("Expected a2a3, got %s", move.Notation)
+	}
+}
+
+func TestUCIEngineEvaluate(t *testing.T) {
+	t.Setenv("FAKE_UCI_ENGINE", "1")
+	engine, err := NewUCIEngine(os.Args[0], 0)
+	if err != nil {
+		t.Fatalf("Expected the engine to start, got %v", err)
+	}
+	defer engine.Close()
+
+	score, err := engine.Evaluate("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	if err != nil {
+		t.Fatalf("Expected a score, got %v", err)
+	}
+	if score != 35 {
+		t.Errorf("Expected the deepest score 35, got %d", score)
+	}
+}
+
+func TestParseScore(t *testing.T) {
+	cases := []struct {
+		line  string
+		score int
+		ok    bool
+	}{
+		{"info depth 12 score cp -48 nodes 5000 pv e7e5", -48, true},
+		{"info depth 20 score mate 3 pv d1h5", mateScore, true},
+		{"info depth 20 score mate -2 pv g8f6", -mateScore, true},
+		{"info depth 5 score cp 20 lowerbound", 20, true},
+		{"info string NNUE enabled", 0, false},
+		{"bestmove e2e4", 0, false},
+	}
+	for _, c := range cases {
+		score, ok := parseScore(c.line)
+		if score != c.score || ok != c.ok {
+			t.Errorf("parseScore(%q): expected %d, %v, got %d, %v", c.line, c.score, c.ok, score, ok)
+		}
+	}
+}
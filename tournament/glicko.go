@@ -0,0 +1,265 @@
+package tournament
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"chess-tui/gamedb"
+)
+
+// Glicko-2 system constants, from Glickman's paper
+const (
+	glickoInitialRating     = 1500
+	glickoInitialDeviation  = 350
+	glickoInitialVolatility = 0.06
+	glickoTau               = 0.5 // how much the volatility may change
+	glickoScale             = 173.7178
+	glickoEpsilon           = 0.000001
+)
+
+// GlickoPeriod is the length of a rating period: every game of a period is
+// rated together, and a player's deviation grows with each period they sit
+// out
+const GlickoPeriod = 7 * 24 * time.Hour
+
+// Glicko is a player's Glicko-2 rating: a strength, how sure it is, and
+// how erratic the player's results are
+type Glicko struct {
+	Rating     float64   `json:"rating"`
+	Deviation  float64   `json:"deviation"` // about 95% of the time the true strength is within two deviations
+	Volatility float64   `json:"volatility"`
+	Games      int       `json:"games"`
+	Updated    time.Time `json:"updated"`
+}
+
+// NewGlicko returns the rating of a player with no games
+func NewGlicko() Glicko {
+	return Glicko{Rating: glickoInitialRating, Deviation: glickoInitialDeviation, Volatility: glickoInitialVolatility}
+}
+
+// String shows the rating with its deviation, e.g. "1612 ± 85"
+func (g Glicko) String() string {
+	return fmt.Sprintf("%.0f ± %.0f", g.Rating, g.Deviation)
+}
+
+// GlickoResult is one game of a rating period, from the rated player's side
+type GlickoResult struct {
+	Opponent Glicko
+	Score    float64 // 1 for a win, 0.5 for a draw, 0 for a loss
+}
+
+// Update returns the rating after a rating period with the given results.
+// A period without games leaves the rating and only grows the deviation.
+func (g Glicko) Update(results []GlickoResult) Glicko {
+	mu := (g.Rating - glickoInitialRating) / glickoScale
+	phi := g.Deviation / glickoScale
+	sigma := g.Volatility
+
+	if len(results) == 0 {
+		g.Deviation = math.Min(math.Sqrt(phi*phi+sigma*sigma)*glickoScale, glickoInitialDeviation)
+		return g
+	}
+
+	// The estimated variance of the rating from the results, and the
+	// improvement they suggest
+	var variance, improvement float64
+	for _, result := range results {
+		muJ := (result.Opponent.Rating - glickoInitialRating) / glickoScale
+		gJ := glickoG(result.Opponent.Deviation / glickoScale)
+		expected := 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+		variance += gJ * gJ * expected * (1 - expected)
+		improvement += gJ * (result.Score - expected)
+	}
+	variance = 1 / variance
+	delta := variance * improvement
+
+	sigma = glickoVolatility(phi, sigma, variance, delta)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/variance)
+	mu += phi * phi * improvement
+
+	g.Rating = mu*glickoScale + glickoInitialRating
+	g.Deviation = phi * glickoScale
+	g.Volatility = sigma
+	g.Games += len(results)
+	return g
+}
+
+// glickoG weighs a result by how sure the opponent's rating is
+func glickoG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// glickoVolatility finds the new volatility by the Illinois algorithm, as
+// in step 5 of Glickman's paper
+func glickoVolatility(phi, sigma, variance, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + variance + ex
+		return ex*(delta*delta-phi*phi-variance-ex)/(2*d*d) - (x-a)/(glickoTau*glickoTau)
+	}
+
+	low := a
+	var high float64
+	if delta*delta > phi*phi+variance {
+		high = math.Log(delta*delta - phi*phi - variance)
+	} else {
+		k := 1.0
+		for f(a-k*glickoTau) < 0 {
+			k++
+		}
+		high = a - k*glickoTau
+	}
+
+	fLow, fHigh := f(low), f(high)
+	for math.Abs(high-low) > glickoEpsilon {
+		c := low + (low-high)*fLow/(fHigh-fLow)
+		fC := f(c)
+		if fC*fHigh <= 0 {
+			low, fLow = high, fHigh
+		} else {
+			fLow /= 2
+		}
+		high, fHigh = c, fC
+	}
+	return math.Exp(low / 2)
+}
+
+// GlickoRatings maps a player name to their Glicko-2 rating
+type GlickoRatings map[string]Glicko
+
+// RateGames computes every player's Glicko-2 rating from a game log,
+// rating the games a period at a time from the first game on. The
+// deviation of a player who hasn't played since keeps growing until now.
+// Games against oneself, such as Human vs Human on one board, are left out.
+func RateGames(records []gamedb.Record, now time.Time) GlickoRatings {
+	var games []gamedb.Record
+	for _, record := range records {
+		if record.White == "" || record.Black == "" || record.White == record.Black {
+			continue
+		}
+		if _, ok := recordScore(record); ok {
+			games = append(games, record)
+		}
+	}
+	ratings := make(GlickoRatings)
+	if len(games) == 0 {
+		return ratings
+	}
+	sort.SliceStable(games, func(i, j int) bool { return games[i].Played.Before(games[j].Played) })
+
+	start := games[0].Played
+	for len(games) > 0 {
+		end := start.Add(GlickoPeriod)
+		results := make(map[string][]GlickoResult)
+		for len(games) > 0 && games[0].Played.Before(end) {
+			game := games[0]
+			games = games[1:]
+			// Ratings change only at the end of a period, so every game is
+			// rated against the opponent's rating from before it
+			score, _ := recordScore(game)
+			white, black := ratings.get(game.White), ratings.get(game.Black)
+			results[game.White] = append(results[game.White], GlickoResult{Opponent: black, Score: score})
+			results[game.Black] = append(results[game.Black], GlickoResult{Opponent: white, Score: 1 - score})
+			ratings[game.White] = white
+			ratings[game.Black] = black
+		}
+		ratings.ratePeriod(results, end)
+		start = end
+	}
+
+	// Periods since the last game, sat out by everyone
+	for ; !start.Add(GlickoPeriod).After(now); start = start.Add(GlickoPeriod) {
+		ratings.ratePeriod(nil, start)
+	}
+	return ratings
+}
+
+// get returns a player's rating, new if they have none
+func (r GlickoRatings) get(name string) Glicko {
+	if rating, ok := r[name]; ok {
+		return rating
+	}
+	return NewGlicko()
+}
+
+// ratePeriod updates every player's rating with their results in a period
+// ending at end
+func (r GlickoRatings) ratePeriod(results map[string][]GlickoResult, end time.Time) {
+	for name, rating := range r {
+		next := rating.Update(results[name])
+		if len(results[name]) > 0 {
+			next.Updated = end
+		}
+		r[name] = next
+	}
+}
+
+// recordScore returns White's score in a finished game
+func recordScore(record gamedb.Record) (float64, bool) {
+	switch record.Result {
+	case gamedb.WhiteWon:
+		return 1, true
+	case gamedb.BlackWon:
+		return 0, true
+	case gamedb.Draw:
+		return 0.5, true
+	}
+	return 0, false
+}
+
+// DefaultGlickoPath returns the Glicko-2 ratings file location in the
+// user's config directory
+func DefaultGlickoPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "glicko.json"
+	}
+	return filepath.Join(home, ".bubblechess", "glicko.json")
+}
+
+// LoadGlicko loads saved Glicko-2 ratings, returning none if the file
+// doesn't exist
+func LoadGlicko(path string) (GlickoRatings, error) {
+	if path == "" {
+		path = DefaultGlickoPath()
+	}
+
+	ratings := make(GlickoRatings)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ratings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Glicko ratings file: %w", err)
+	}
+	if err := json.Unmarshal(data, &ratings); err != nil {
+		return nil, fmt.Errorf("failed to decode Glicko ratings file: %w", err)
+	}
+	return ratings, nil
+}
+
+// SaveGlicko writes Glicko-2 ratings to a file
+func SaveGlicko(ratings GlickoRatings, path string) error {
+	if path == "" {
+		path = DefaultGlickoPath()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create ratings directory: %w", err)
+	}
+	data, err := json.MarshalIndent(ratings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode Glicko ratings: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write Glicko ratings file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package tournament
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"chess-tui/gamedb"
+)
+
+func TestGlickoUpdateMatchesGlickmansExample(t *testing.T) {
+	player := Glicko{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	updated := player.Update([]GlickoResult{
+		{Opponent: Glicko{Rating: 1400, Deviation: 30}, Score: 1},
+		{Opponent: Glicko{Rating: 1550, Deviation: 100}, Score: 0},
+		{Opponent: Glicko{Rating: 1700, Deviation: 300}, Score: 0},
+	})
+	if math.Abs(updated.Rating-1464.06) > 0.1 || math.Abs(updated.Deviation-151.52) > 0.1 || math.Abs(updated.Volatility-0.05999) > 0.0001 {
+		t.Errorf("Expected 1464.06 ± 151.52 with volatility 0.05999, got %.2f ± %.2f with %.5f", updated.Rating, updated.Deviation, updated.Volatility)
+	}
+	if updated.Games != 3 {
+		t.Errorf("Expected 3 games, got %d", updated.Games)
+	}
+}
+
+func TestGlickoDeviationGrowsWhenIdle(t *testing.T) {
+	player := Glicko{Rating: 1700, Deviation: 50, Volatility: 0.06}
+	idle := player.Update(nil)
+	if idle.Rating != 1700 || idle.Deviation <= 50 {
+		t.Errorf("Expected the same rating with a wider deviation, got %s", idle)
+	}
+	if capped := NewGlicko().Update(nil); capped.Deviation != 350 {
+		t.Errorf("Expected the deviation to stay at most 350, got %.1f", capped.Deviation)
+	}
+}
+
+func TestRateGames(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var records []gamedb.Record
+	for i := range 10 {
+		records = append(records, gamedb.Record{Played: start.Add(time.Duration(i) * time.Hour), White: "strong", Black: "weak", Result: gamedb.WhiteWon})
+	}
+	records = append(records,
+		gamedb.Record{Played: start, White: "Human", Black: "Human", Result: gamedb.Draw},
+		gamedb.Record{Played: start, White: "strong", Black: "weak", Result: "*"},
+	)
+
+	ratings := RateGames(records, start.Add(GlickoPeriod))
+	if len(ratings) != 2 {
+		t.Fatalf("Expected 2 rated players, got %v", ratings)
+	}
+	strong, weak := ratings["strong"], ratings["weak"]
+	if strong.Rating <= weak.Rating || strong.Games != 10 || weak.Games != 10 {
+		t.Errorf("Expected strong above weak after 10 games each, got %s and %s", strong, weak)
+	}
+	if strong.Deviation >= 350 {
+		t.Errorf("Expected the deviation to shrink with games, got %.1f", strong.Deviation)
+	}
+
+	// A year later nobody has played; the ratings are less sure
+	later := RateGames(records, start.Add(365*24*time.Hour))
+	if later["strong"].Deviation <= strong.Deviation || later["strong"].Rating != strong.Rating {
+		t.Errorf("Expected only the deviation to grow, got %s then %s", strong, later["strong"])
+	}
+}
+
+func TestSaveAndLoadGlicko(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glicko.json")
+	ratings := GlickoRatings{"llama3": {Rating: 1612, Deviation: 85, Volatility: 0.06, Games: 12}}
+	if err := SaveGlicko(ratings, path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	loaded, err := LoadGlicko(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := loaded["llama3"]; got.Rating != 1612 || got.Games != 12 {
+		t.Errorf("Expected the saved rating back, got %+v", got)
+	}
+}
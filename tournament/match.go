@@ -0,0 +1,380 @@
+package tournament
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"chess-tui/ai_player"
+	"chess-tui/events"
+	"chess-tui/gamedb"
+	"chess-tui/locale"
+	"chess-tui/notation"
+	"chess-tui/rules"
+
+	"github.com/notnil/chess"
+)
+
+// defaultMaxIllegalMoves is how many bad replies in a row forfeit a game
+const defaultMaxIllegalMoves = 3
+
+// ErrInterrupted is returned for a game abandoned because its match's
+// context ended, e.g. on Ctrl+C; it has no result
+var ErrInterrupted = errors.New("game interrupted")
+
+// Player chooses moves for one side. *ai_player.AIPlayer satisfies it.
+type Player interface {
+	GetMove(boardState string, gameHistory []string) (*ai_player.ChessMove, error)
+}
+
+// Entrant is a named player in a match or tournament
+type Entrant struct {
+	Name   string
+	Player Player
+	Limits *Limits // a handicap: the player's own time limits, whichever side it plays
+}
+
+// GameResult is the record of a finished game
+type GameResult struct {
+	ID      string // the game's UUID, also in its GameId tag
+	White   string
+	Black   string
+	Outcome chess.Outcome
+	Reason  string // e.g. "checkmate" or "no progress in 50 moves"
+	Moves   []string
+	PGN     string
+
+	// Violations lists every time the players overran their clocks
+	Violations []Violation
+
+	// MoveTimes is how long each move took, for the time chart
+	MoveTimes TimeUsage
+
+	// TimeControl is the one the game was played under, with any handicaps
+	TimeControl TimeControl
+}
+
+// ScoreFor returns the named player's score: 1 for a win, 0.5 for a draw
+func (r *GameResult) ScoreFor(name string) float64 {
+	switch {
+	case r.Outcome == chess.Draw:
+		return 0.5
+	case r.Outcome == chess.WhiteWon && r.White == name,
+		r.Outcome == chess.BlackWon && r.Black == name:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Record returns the game as a game log record, so that games between AI
+// players count towards their ratings
+func (r *GameResult) Record() gamedb.Record {
+	record := gamedb.Record{
+		ID:          r.ID,
+		White:       r.White,
+		Black:       r.Black,
+		Result:      r.Outcome.String(),
+		Termination: r.Reason,
+		Moves:       r.Moves,
+	}
+	for _, elapsed := range r.MoveTimes {
+		record.MoveTimes = append(record.MoveTimes, elapsed.Milliseconds())
+	}
+	return record
+}
+
+// Board is a game in progress, as a live view of the match shows it
+type Board struct {
+	Round    int // the game's number in the match, or 0 outside one
+	White    string
+	Black    string
+	FEN      string
+	LastMove string // in SAN, "" before the first move
+	Plies    int
+}
+
+// Match plays games between two entrants with a referee enforcing the rules
+type Match struct {
+	Adjudication    Adjudication
+	TimeControl     TimeControl
+	HouseRules      rules.Rules // a move that breaks them counts as illegal
+	MaxIllegalMoves int
+
+	// Context, when set, bounds the match: once it ends, the game in
+	// progress is abandoned with ErrInterrupted rather than forfeited
+	Context context.Context
+
+	// OnMove, when set, is called as each game starts and after every
+	// move. Games on a host pool call it from several goroutines at once.
+	OnMove func(Board)
+}
+
+// NewMatch creates a match with the given adjudication rules
+func NewMatch(adjudication Adjudication) *Match {
+	return &Match{
+		Adjudication:    adjudication,
+		MaxIllegalMoves: defaultMaxIllegalMoves,
+	}
+}
+
+// PlayGame plays one game to the end or until it is adjudicated
+func (m *Match) PlayGame(white, black Entrant) (*GameResult, error) {
+	return m.PlayRound(0, white, black)
+}
+
+// PlayRound plays the match's game number round, which its PGN records,
+// to the end or until it is adjudicated
+func (m *Match) PlayRound(round int, white, black Entrant) (*GameResult, error) {
+	game := chess.NewGame(chess.UseNotation(chess.AlgebraicNotation{}))
+	game.AddTagPair("Event", "bubblechess match")
+	game.AddTagPair("Site", "bubblechess")
+	game.AddTagPair("Date", locale.PGNDate(time.Now()))
+	game.AddTagPair("Round", locale.PGNRound(round))
+	game.AddTagPair("White", white.Name)
+	game.AddTagPair("Black", black.Name)
+	game.AddTagPair("Result", string(chess.NoOutcome))
+	id := events.NewGameID()
+	game.AddTagPair("GameId", id)
+	log := slog.With("game_id", id)
+
+	control := m.TimeControl
+	if white.Limits != nil {
+		control.White = white.Limits
+	}
+	if black.Limits != nil {
+		control.Black = black.Limits
+	}
+
+	judge := newAdjudicator(m.Adjudication)
+	clk := newClock(control)
+	comments := make(map[int][]string)
+	var violations []Violation
+	var history []string
+	var times TimeUsage
+	reason := ""
+	board := Board{Round: round, White: white.Name, Black: black.Name, FEN: game.Position().String()}
+	m.observe(board)
+
+	for game.Outcome() == chess.NoOutcome {
+		if err := m.interrupted(); err != nil {
+			return nil, err
+		}
+		mover := game.Position().Turn()
+		entrant := white
+		if mover == chess.Black {
+			entrant = black
+		}
+
+		start := time.Now()
+		move, reported, violation, err := m.timedMove(clk, entrant, mover, game.Position(), history)
+		if violation != nil {
+			log.Warn("Player exceeded time limit", "player", entrant.Name, "violation", violation.String())
+			violations = append(violations, *violation)
+		}
+		if violation != nil && violation.Action == TimeoutForfeit {
+			game.Resign(mover)
+			reason = fmt.Sprintf("%s forfeits on time", mover.Name())
+			if ply := len(history) - 1; ply >= 0 {
+				comments[ply] = append(comments[ply], violation.String())
+			}
+			break
+		}
+		if err := m.interrupted(); err != nil {
+			return nil, err // the player's request was cut short, not its fault
+		}
+		if err != nil {
+			log.Debug("Player forfeits", "player", entrant.Name, "error", err)
+			game.Resign(mover)
+			reason = fmt.Sprintf("%s forfeits: %v", mover.Name(), err)
+			break
+		}
+
+		san := notation.Encode(game.Position(), move)
+		if err := game.Move(move); err != nil {
+			return nil, fmt.Errorf("failed to apply move %s: %w", san, err)
+		}
+		if violation != nil {
+			comments[len(history)] = append(comments[len(history)], violation.String())
+		}
+		history = append(history, san)
+		times = append(times, time.Since(start))
+		board.FEN, board.LastMove, board.Plies = game.Position().String(), san, len(history)
+		m.observe(board)
+
+		if game.Outcome() != chess.NoOutcome {
+			break
+		}
+		if outcome, why := m.HouseRules.Verdict(game.Position(), len(history)); outcome != chess.NoOutcome {
+			endByAdjudication(game, outcome)
+			reason = "house rules: " + why
+			break
+		}
+
+		// Use the player's own eval if it reported one, else count material
+		eval := ai_player.MaterialBalance(game.Position(), mover)
+		if reported != nil && reported.Eval != nil {
+			eval = *reported.Eval
+		}
+
+		moveNumber := (len(history) + 1) / 2
+		if outcome, why := judge.check(game.Position(), mover, moveNumber, eval); outcome != chess.NoOutcome {
+			endByAdjudication(game, outcome)
+			reason = "adjudicated: " + why
+		}
+	}
+
+	if reason == "" {
+		reason = methodReason(game.Method())
+	}
+	game.AddTagPair("Termination", reason)
+
+	// Keep the clocks in the PGN, ahead of any violation noted for the move
+	if control.Limited() {
+		for ply, left := range times.Clocks(control) {
+			if left >= 0 {
+				comments[ply] = append([]string{ClockComment(left)}, comments[ply]...)
+			}
+		}
+	}
+
+	game.AddTagPair("Result", string(game.Outcome()))
+	return &GameResult{
+		ID:          id,
+		White:       white.Name,
+		Black:       black.Name,
+		Outcome:     game.Outcome(),
+		Reason:      reason,
+		Moves:       history,
+		PGN:         encodePGN(game, comments),
+		Violations:  violations,
+		MoveTimes:   times,
+		TimeControl: control,
+	}, nil
+}
+
+// timedMove requests a move within the mover's time budget. When the player
+// overruns it, the returned violation says whether the built-in engine's
+// fallback move was played or the player forfeits.
+func (m *Match) timedMove(clk *clock, entrant Entrant, mover chess.Color, position *chess.Position, history []string) (*chess.Move, *ai_player.ChessMove, *Violation, error) {
+	limit, budget, limited := clk.limit(mover)
+	if !limited {
+		move, reply, err := m.requestMove(context.Background(), entrant, position, history)
+		return move, reply, nil, err
+	}
+
+	type answer struct {
+		move  *chess.Move
+		reply *ai_player.ChessMove
+	}
+	// The request gets a board and history of its own, as it may run on
+	// past the limit while the fallback move is chosen on this board
+	board, moves := clonePosition(position), slices.Clone(history)
+	start := time.Now()
+	result, err := withTimeout(limit, func(ctx context.Context) (answer, error) {
+		move, reply, err := m.requestMove(ctx, entrant, board, moves)
+		return answer{move, reply}, err
+	})
+	overran := clk.charge(mover, start, limit)
+	if !errors.Is(err, errTimeout) && !(err == nil && overran) {
+		return result.move, result.reply, nil, err
+	}
+
+	violation := &Violation{
+		Player: entrant.Name,
+		Color:  mover,
+		Ply:    len(history),
+		Limit:  clk.control.For(mover).PerMove,
+		Budget: budget,
+		Action: TimeoutFallback,
+	}
+	if budget == "per-game" {
+		violation.Limit = clk.control.For(mover).PerGame
+	}
+	if m.TimeControl.OnTimeout == TimeoutForfeit {
+		violation.Action = TimeoutForfeit
+		return nil, nil, violation, nil
+	}
+
+	move, err := FallbackMove(position)
+	if err == nil && m.HouseRules.Check(position, move) != nil {
+		move = m.HouseRules.Moves(position)[0]
+	}
+	return move, nil, violation, err
+}
+
+// requestMove asks the entrant for a legal move, allowing a few bad replies
+// until ctx ends
+func (m *Match) requestMove(ctx context.Context, entrant Entrant, position *chess.Position, history []string) (*chess.Move, *ai_player.ChessMove, error) {
+	var lastErr error
+	for attempt := 0; attempt < m.MaxIllegalMoves && m.interrupted() == nil; attempt++ {
+		reply, err := entrant.Player.GetMove(position.String(), history)
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err() // nobody is waiting for the reply
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		move, err := notation.Decode(position, reply.Notation)
+		if err == nil {
+			err = m.HouseRules.Check(position, move)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return move, reply, nil
+	}
+	return nil, nil, fmt.Errorf("no legal move after %d attempts: %w", m.MaxIllegalMoves, lastErr)
+}
+
+// observe reports the game's board to OnMove, if set
+func (m *Match) observe(board Board) {
+	if m.OnMove != nil {
+		m.OnMove(board)
+	}
+}
+
+// interrupted returns ErrInterrupted, with the reason, once the match's
+// context has ended
+func (m *Match) interrupted() error {
+	if m.Context == nil || m.Context.Err() == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInterrupted, context.Cause(m.Context))
+}
+
+// endByAdjudication records an adjudicated outcome on the game
+func endByAdjudication(game *chess.Game, outcome chess.Outcome) {
+	switch outcome {
+	case chess.WhiteWon:
+		game.Resign(chess.Black)
+	case chess.BlackWon:
+		game.Resign(chess.White)
+	case chess.Draw:
+		game.Draw(chess.DrawOffer)
+	}
+}
+
+// methodReason describes how a game ended over the board
+func methodReason(method chess.Method) string {
+	switch method {
+	case chess.Checkmate:
+		return "checkmate"
+	case chess.Stalemate:
+		return "stalemate"
+	case chess.ThreefoldRepetition, chess.FivefoldRepetition:
+		return "repetition"
+	case chess.FiftyMoveRule, chess.SeventyFiveMoveRule:
+		return "fifty-move rule"
+	case chess.InsufficientMaterial:
+		return "insufficient material"
+	default:
+		return method.String()
+	}
+}
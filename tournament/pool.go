@@ -0,0 +1,273 @@
+package tournament
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"chess-tui/ai_player"
+)
+
+// Host health defaults
+const (
+	// defaultMaxHostFailures is how many failed calls in a row take a host
+	// out of rotation
+	defaultMaxHostFailures = 3
+
+	// defaultHostCooldown is how long a failing host is left out
+	defaultHostCooldown = time.Minute
+)
+
+// HostStats is the record of one backend host
+type HostStats struct {
+	Host     string
+	Games    int
+	Moves    int
+	Failures int
+	Latency  time.Duration // total time spent in successful calls
+	InFlight int           // games being played on the host now
+	Down     bool          // left out of rotation after repeated failures
+}
+
+// AverageLatency returns the mean time of a successful call
+func (s HostStats) AverageLatency() time.Duration {
+	if s.Moves == 0 {
+		return 0
+	}
+	return s.Latency / time.Duration(s.Moves)
+}
+
+// hostState is a host's record and health
+type hostState struct {
+	HostStats
+	failStreak int
+	downUntil  time.Time
+}
+
+// HostPool balances games across several backend hosts, such as the Ollama
+// servers of a small GPU cluster, and tracks each host's latency and failures.
+// A host that fails several calls in a row is left out for a cooldown.
+type HostPool struct {
+	MaxFailures int
+	Cooldown    time.Duration
+
+	mu    sync.Mutex
+	hosts []*hostState
+	now   func() time.Time
+}
+
+// NewHostPool creates a pool of hosts, given as base URLs
+func NewHostPool(hosts []string) *HostPool {
+	pool := &HostPool{
+		MaxFailures: defaultMaxHostFailures,
+		Cooldown:    defaultHostCooldown,
+		now:         time.Now,
+	}
+	for _, host := range hosts {
+		pool.hosts = append(pool.hosts, &hostState{HostStats: HostStats{Host: host}})
+	}
+	return pool
+}
+
+// Acquire picks the host for the next game: the healthy host with the fewest
+// games in flight, then the lowest latency. Hosts that are down are only
+// picked when every host is down. The caller must Release the host.
+func (p *HostPool) Acquire() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best *hostState
+	for _, host := range p.hosts {
+		if best == nil || p.better(host, best) {
+			best = host
+		}
+	}
+	best.InFlight++
+	return best.Host
+}
+
+// better reports whether host a should get the next game before host b. The
+// caller holds p.mu.
+func (p *HostPool) better(a, b *hostState) bool {
+	aDown, bDown := p.down(a), p.down(b)
+	if aDown != bDown {
+		return bDown
+	}
+	if a.InFlight != b.InFlight {
+		return a.InFlight < b.InFlight
+	}
+	return a.AverageLatency() < b.AverageLatency()
+}
+
+// down reports whether a host is cooling down. The caller holds p.mu.
+func (p *HostPool) down(host *hostState) bool {
+	return p.now().Before(host.downUntil)
+}
+
+// Release returns a host after a game, counting the game
+func (p *HostPool) Release(host string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if state := p.find(host); state != nil {
+		state.InFlight--
+		state.Games++
+	}
+}
+
+// Observe records the outcome of one call to a host
+func (p *HostPool) Observe(host string, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.find(host)
+	if state == nil {
+		return
+	}
+	if err == nil {
+		state.Moves++
+		state.Latency += latency
+		state.failStreak = 0
+		return
+	}
+
+	state.Failures++
+	state.failStreak++
+	if state.failStreak >= p.MaxFailures && !p.down(state) {
+		state.downUntil = p.now().Add(p.Cooldown)
+		slog.Warn("Host taken out of rotation", "host", host, "failures", state.failStreak, "cooldown", p.Cooldown)
+	}
+}
+
+// find returns a host's state. The caller holds p.mu.
+func (p *HostPool) find(host string) *hostState {
+	for _, state := range p.hosts {
+		if state.Host == host {
+			return state
+		}
+	}
+	return nil
+}
+
+// Stats returns every host's record, in the order the hosts were given
+func (p *HostPool) Stats() []HostStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make([]HostStats, 0, len(p.hosts))
+	for _, state := range p.hosts {
+		record := state.HostStats
+		record.Down = p.down(state)
+		stats = append(stats, record)
+	}
+	return stats
+}
+
+// Report formats the hosts' records as a table
+func (p *HostPool) Report() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "  %-32s %6s %6s %9s %9s\n", "Host", "Games", "Moves", "Avg move", "Failures")
+	for _, stats := range p.Stats() {
+		line := fmt.Sprintf("  %-32s %6d %6d %9s %9d", stats.Host, stats.Games, stats.Moves, stats.AverageLatency().Round(time.Millisecond), stats.Failures)
+		if stats.Down {
+			line += "  (down)"
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// Track wraps a player so its calls are recorded against host
+func (p *HostPool) Track(host string, player Player) Player {
+	return &trackedPlayer{pool: p, host: host, player: player}
+}
+
+// trackedPlayer times a player's calls for its host pool
+type trackedPlayer struct {
+	pool   *HostPool
+	host   string
+	player Player
+}
+
+// GetMove asks the wrapped player for a move and records how it went
+func (t *trackedPlayer) GetMove(boardState string, gameHistory []string) (*ai_player.ChessMove, error) {
+	start := time.Now()
+	move, err := t.player.GetMove(boardState, gameHistory)
+	t.pool.Observe(t.host, time.Since(start), err)
+	return move, err
+}
+
+// HostGame is a finished game of a distributed match
+type HostGame struct {
+	Index  int // the game's number in the match, from 0
+	Host   string
+	Result *GameResult
+	Err    error
+}
+
+// PlayOnPool plays games on the pool's hosts, perHost at a time on each.
+// entrants creates the players for game i on a host; results are sent to
+// done as games finish, which may be out of order. A game whose host fails
+// is replayed on another host, up to once per host in the pool.
+func (m *Match) PlayOnPool(pool *HostPool, games, perHost int, entrants func(i int, host string) (white, black Entrant, err error), done func(HostGame)) {
+	perHost = max(perHost, 1)
+	queue := make(chan int, games)
+	for i := 0; i < games; i++ {
+		queue <- i
+	}
+	close(queue)
+
+	var wg sync.WaitGroup
+	for range len(pool.hosts) * perHost {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				if m.interrupted() != nil {
+					return // the games not started yet are left unplayed
+				}
+				done(m.playOnHost(pool, i, entrants))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// playOnHost plays game i, moving it to another host when its host fails
+func (m *Match) playOnHost(pool *HostPool, i int, entrants func(int, string) (Entrant, Entrant, error)) HostGame {
+	var game HostGame
+	for attempt := 0; attempt < len(pool.hosts); attempt++ {
+		host := pool.Acquire()
+		game = HostGame{Index: i, Host: host}
+
+		white, black, err := entrants(i, host)
+		if err != nil {
+			pool.Release(host)
+			game.Err = err
+			return game
+		}
+		white.Player = pool.Track(host, white.Player)
+		black.Player = pool.Track(host, black.Player)
+
+		failures := pool.failures(host)
+		game.Result, game.Err = m.PlayRound(i+1, white, black)
+		pool.Release(host)
+
+		// A forfeit caused by the host failing isn't the players' fault
+		if game.Err == nil && strings.Contains(game.Result.Reason, "forfeits:") && pool.failures(host) > failures {
+			slog.Warn("Game lost to a failing host; replaying it", "game", i+1, "host", host)
+			continue
+		}
+		return game
+	}
+	return game
+}
+
+// failures returns how many calls to a host have failed
+func (p *HostPool) failures(host string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if state := p.find(host); state != nil {
+		return state.Failures
+	}
+	return 0
+}
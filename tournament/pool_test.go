@@ -0,0 +1,123 @@
+package tournament
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"chess-tui/ai_player"
+
+	"github.com/notnil/chess"
+)
+
+// downPlayer stands for a player whose host is unreachable
+type downPlayer struct{}
+
+func (downPlayer) GetMove(boardState string, gameHistory []string) (*ai_player.ChessMove, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestHostPoolBalancesAndCoolsDown(t *testing.T) {
+	pool := NewHostPool([]string{"http://a", "http://b"})
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+
+	first, second := pool.Acquire(), pool.Acquire()
+	if first == second {
+		t.Errorf("Expected games on both hosts, got %s twice", first)
+	}
+	pool.Release(first)
+	pool.Release(second)
+
+	// The slower host gets the next game only once the faster one is busy
+	pool.Observe("http://a", 3*time.Second, nil)
+	pool.Observe("http://b", time.Second, nil)
+	if host := pool.Acquire(); host != "http://b" {
+		t.Errorf("Expected the faster host, got %s", host)
+	}
+	if host := pool.Acquire(); host != "http://a" {
+		t.Errorf("Expected the idle host, got %s", host)
+	}
+	pool.Release("http://a")
+	pool.Release("http://b")
+
+	// Failing calls take a host out of rotation until the cooldown ends
+	for range pool.MaxFailures {
+		pool.Observe("http://b", 0, errors.New("timeout"))
+	}
+	if host := pool.Acquire(); host != "http://a" {
+		t.Errorf("Expected the healthy host, got %s", host)
+	}
+	if host := pool.Acquire(); host != "http://a" {
+		t.Errorf("Expected the healthy host even when busy, got %s", host)
+	}
+	stats := pool.Stats()
+	if !stats[1].Down || stats[1].Failures != 3 {
+		t.Errorf("Expected host b down after 3 failures, got %+v", stats[1])
+	}
+
+	now = now.Add(pool.Cooldown + time.Second)
+	if host := pool.Acquire(); host != "http://b" {
+		t.Errorf("Expected host b back after the cooldown, got %s", host)
+	}
+}
+
+func TestPlayOnPool(t *testing.T) {
+	pool := NewHostPool([]string{"http://a", "http://b"})
+
+	var mu sync.Mutex
+	results := make(map[int]HostGame)
+	NewMatch(Adjudication{}).PlayOnPool(pool, 4, 1, func(i int, host string) (Entrant, Entrant, error) {
+		white := Entrant{Name: "w", Player: &scriptedPlayer{moves: []string{"f3", "g4"}}}
+		black := Entrant{Name: "b", Player: &scriptedPlayer{moves: []string{"e5", "Qh4#"}}}
+		return white, black, nil
+	}, func(game HostGame) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[game.Index] = game
+	})
+
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 games, got %d", len(results))
+	}
+	for i, game := range results {
+		if game.Err != nil || game.Result.Outcome != chess.BlackWon {
+			t.Errorf("Expected game %d to end in mate, got %+v", i+1, game)
+		}
+	}
+	games, moves := 0, 0
+	for _, stats := range pool.Stats() {
+		games += stats.Games
+		moves += stats.Moves
+		if stats.InFlight != 0 {
+			t.Errorf("Expected no games in flight on %s, got %d", stats.Host, stats.InFlight)
+		}
+	}
+	if games != 4 || moves != 16 {
+		t.Errorf("Expected 4 games and 16 moves across the hosts, got %d and %d", games, moves)
+	}
+}
+
+func TestPlayOnPoolReplaysGamesLostToAFailingHost(t *testing.T) {
+	pool := NewHostPool([]string{"http://down", "http://up"})
+
+	var game HostGame
+	NewMatch(Adjudication{}).PlayOnPool(pool, 1, 1, func(i int, host string) (Entrant, Entrant, error) {
+		if host == "http://down" {
+			return Entrant{Name: "w", Player: downPlayer{}}, Entrant{Name: "b", Player: downPlayer{}}, nil
+		}
+		white := Entrant{Name: "w", Player: &scriptedPlayer{moves: []string{"f3", "g4"}}}
+		black := Entrant{Name: "b", Player: &scriptedPlayer{moves: []string{"e5", "Qh4#"}}}
+		return white, black, nil
+	}, func(finished HostGame) {
+		game = finished
+	})
+
+	if game.Host != "http://up" || game.Result == nil || game.Result.Reason != "checkmate" {
+		t.Errorf("Expected the game replayed to mate on the working host, got %+v", game)
+	}
+	if stats := pool.Stats(); stats[0].Failures == 0 {
+		t.Errorf("Expected failures recorded against the failing host, got %+v", stats[0])
+	}
+}
@@ -0,0 +1,330 @@
+package tournament
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"chess-tui/locale"
+
+	"github.com/notnil/chess"
+)
+
+// Chart dimensions of the report's time charts, in pixels
+const (
+	reportBarWidth    = 4
+	reportChartHeight = 60
+)
+
+// Standing is one player's line in the report's standings
+type Standing struct {
+	Name                string
+	Games               int
+	Wins, Draws, Losses int
+	Points              float64
+	Thinking            time.Duration // total over all games
+	Moves               int
+}
+
+// Percent returns the share of the points available the player scored
+func (s Standing) Percent() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return s.Points * 100 / float64(s.Games)
+}
+
+// AverageMove returns the player's average thinking time per move
+func (s Standing) AverageMove() time.Duration {
+	if s.Moves == 0 {
+		return 0
+	}
+	return roundTime(s.Thinking / time.Duration(s.Moves))
+}
+
+// Standings totals each player's results, best score first
+func Standings(games []*GameResult) []Standing {
+	byName := map[string]*Standing{}
+	for _, game := range games {
+		for _, color := range []chess.Color{chess.White, chess.Black} {
+			name := game.White
+			if color == chess.Black {
+				name = game.Black
+			}
+			standing, ok := byName[name]
+			if !ok {
+				standing = &Standing{Name: name}
+				byName[name] = standing
+			}
+			score := game.ScoreFor(name)
+			standing.Games++
+			standing.Points += score
+			switch {
+			case game.Outcome == chess.Draw:
+				standing.Draws++
+			case score == 1:
+				standing.Wins++
+			case game.Outcome != chess.NoOutcome:
+				standing.Losses++
+			}
+			standing.Thinking += game.MoveTimes.Total(color)
+			standing.Moves += len(game.MoveTimes.Moves(color))
+		}
+	}
+
+	standings := make([]Standing, 0, len(byName))
+	for _, standing := range byName {
+		standings = append(standings, *standing)
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Points != standings[j].Points {
+			return standings[i].Points > standings[j].Points
+		}
+		if standings[i].Wins != standings[j].Wins {
+			return standings[i].Wins > standings[j].Wins
+		}
+		return standings[i].Name < standings[j].Name
+	})
+	return standings
+}
+
+// WriteReport writes a static HTML report of a tournament's games to dir:
+// index.html with the standings, a crosstable and each game with its time
+// chart, each game's PGN as game-NNN.pgn and all of them in games.pgn. Its
+// dates, numbers and times are written the way the locale writes them.
+func WriteReport(dir, title string, games []*GameResult, loc locale.Locale) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	var all []string
+	for i, game := range games {
+		if err := os.WriteFile(filepath.Join(dir, reportPGNName(i)), []byte(game.PGN+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to write PGN: %w", err)
+		}
+		all = append(all, game.PGN)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "games.pgn"), []byte(strings.Join(all, "\n\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write PGN: %w", err)
+	}
+
+	file, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer file.Close()
+	page := template.Must(reportTemplate.Clone()).Funcs(reportFuncs(loc))
+	if err := page.Execute(file, newReportPage(title, games, loc)); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// reportPGNName is the file name of game i's PGN
+func reportPGNName(i int) string {
+	return fmt.Sprintf("game-%03d.pgn", i+1)
+}
+
+// reportPage is what the report template renders
+type reportPage struct {
+	Title     string
+	Generated string
+	Standings []Standing
+	Names     []string   // the players, in standings order
+	Cross     [][]string // Cross[i][j] is Names[i]'s score against Names[j]
+	Games     []reportGame
+}
+
+// reportGame is one game's row in the report
+type reportGame struct {
+	Number       int
+	White, Black string
+	Result       string
+	Reason       string
+	Moves        int
+	PGN          string // the file name
+	Chart        reportChart
+	WhiteTime    string
+	BlackTime    string
+	Violations   int
+}
+
+// reportChart is a game's time chart: White's moves as bars above the
+// axis and Black's below, scaled to the game's longest move
+type reportChart struct {
+	Width, Height int
+	Axis          int
+	Bars          []reportBar
+}
+
+// reportBar is one move's bar in a time chart
+type reportBar struct {
+	X, Y          int
+	Width, Height int
+	White         bool
+	Title         string
+}
+
+// newReportPage works out the standings, crosstable and game rows
+func newReportPage(title string, games []*GameResult, loc locale.Locale) reportPage {
+	page := reportPage{
+		Title:     title,
+		Generated: loc.DateTime(time.Now()),
+		Standings: Standings(games),
+	}
+
+	index := map[string]int{}
+	for i, standing := range page.Standings {
+		page.Names = append(page.Names, standing.Name)
+		index[standing.Name] = i
+	}
+	points := make([][]float64, len(page.Names))
+	played := make([][]int, len(page.Names))
+	for i := range page.Names {
+		points[i] = make([]float64, len(page.Names))
+		played[i] = make([]int, len(page.Names))
+	}
+	for _, game := range games {
+		white, black := index[game.White], index[game.Black]
+		points[white][black] += game.ScoreFor(game.White)
+		points[black][white] += game.ScoreFor(game.Black)
+		played[white][black]++
+		played[black][white]++
+	}
+	page.Cross = make([][]string, len(page.Names))
+	for i := range page.Names {
+		page.Cross[i] = make([]string, len(page.Names))
+		for j := range page.Names {
+			if played[i][j] > 0 {
+				page.Cross[i][j] = loc.Float(points[i][j], -1) + " / " + loc.Int(played[i][j])
+			}
+		}
+	}
+
+	for i, game := range games {
+		page.Games = append(page.Games, reportGame{
+			Number:     i + 1,
+			White:      game.White,
+			Black:      game.Black,
+			Result:     game.Outcome.String(),
+			Reason:     game.Reason,
+			Moves:      (len(game.Moves) + 1) / 2,
+			PGN:        reportPGNName(i),
+			Chart:      newReportChart(game.MoveTimes, loc),
+			WhiteTime:  loc.Duration(roundTime(game.MoveTimes.Total(chess.White))),
+			BlackTime:  loc.Duration(roundTime(game.MoveTimes.Total(chess.Black))),
+			Violations: len(game.Violations),
+		})
+	}
+	return page
+}
+
+// newReportChart lays out the bars of a game's time chart
+func newReportChart(usage TimeUsage, loc locale.Locale) reportChart {
+	half := reportChartHeight / 2
+	chart := reportChart{
+		Width:  max(1, (len(usage)+1)/2) * reportBarWidth,
+		Height: reportChartHeight,
+		Axis:   half,
+	}
+	longest := time.Duration(0)
+	for _, elapsed := range usage {
+		longest = max(longest, elapsed)
+	}
+	for ply, elapsed := range usage {
+		height := 0
+		if longest > 0 {
+			height = max(1, int(int64(elapsed)*int64(half)/int64(longest)))
+		}
+		bar := reportBar{
+			X:      ply / 2 * reportBarWidth,
+			Y:      half,
+			Width:  reportBarWidth - 1,
+			Height: height,
+			White:  ply%2 == 0,
+			Title:  fmt.Sprintf("%d… %s", ply/2+1, loc.Duration(roundTime(elapsed))),
+		}
+		if bar.White {
+			bar.Y = half - height
+			bar.Title = fmt.Sprintf("%d. %s", ply/2+1, loc.Duration(roundTime(elapsed)))
+		}
+		chart.Bars = append(chart.Bars, bar)
+	}
+	return chart
+}
+
+// reportFuncs are the report template's functions, writing numbers and
+// times for the locale
+func reportFuncs(loc locale.Locale) template.FuncMap {
+	return template.FuncMap{
+		"inc":      func(i int) int { return i + 1 },
+		"points":   func(points float64) string { return loc.Float(points, -1) },
+		"percent":  func(percent float64) string { return loc.Percent(percent, 0) },
+		"duration": loc.Duration,
+	}
+}
+
+// reportTemplate is the report's index.html
+var reportTemplate = template.Must(template.New("report").Funcs(reportFuncs(locale.C)).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em auto; max-width: 60em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+th { background: #f3f3f3; }
+td.num { text-align: right; }
+td.self { background: #eee; }
+rect.white { fill: #d9c9a3; stroke: #8a7a55; stroke-width: 0.5; }
+rect.black { fill: #555; }
+.muted { color: #888; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="muted">Generated {{.Generated}} · <a href="games.pgn">all games (PGN)</a></p>
+
+<h2>Standings</h2>
+<table>
+<tr><th>#</th><th>Player</th><th>Games</th><th>Won</th><th>Drawn</th><th>Lost</th><th>Points</th><th>Score</th><th>Avg move</th></tr>
+{{- range $i, $s := .Standings}}
+<tr><td class="num">{{inc $i}}</td><td>{{$s.Name}}</td><td class="num">{{$s.Games}}</td><td class="num">{{$s.Wins}}</td><td class="num">{{$s.Draws}}</td><td class="num">{{$s.Losses}}</td><td class="num">{{points $s.Points}}</td><td class="num">{{percent $s.Percent}}</td><td class="num">{{duration $s.AverageMove}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Crosstable</h2>
+<table>
+<tr><th></th>{{range .Names}}<th>{{.}}</th>{{end}}</tr>
+{{- range $i, $name := .Names}}
+<tr><th>{{$name}}</th>{{range $j, $cell := index $.Cross $i}}{{if eq $i $j}}<td class="self"></td>{{else}}<td class="num">{{$cell}}</td>{{end}}{{end}}</tr>
+{{- end}}
+</table>
+
+<h2>Games</h2>
+<table>
+<tr><th>#</th><th>White</th><th>Black</th><th>Result</th><th>Moves</th><th>Time (W / B)</th><th>Time chart</th><th>PGN</th></tr>
+{{- range .Games}}
+<tr>
+<td class="num">{{.Number}}</td><td>{{.White}}</td><td>{{.Black}}</td>
+<td>{{.Result}}{{if .Reason}} <span class="muted">({{.Reason}})</span>{{end}}</td>
+<td class="num">{{.Moves}}</td>
+<td>{{.WhiteTime}} / {{.BlackTime}}{{if .Violations}} <span title="time overruns">⏱{{.Violations}}</span>{{end}}</td>
+<td><svg width="{{.Chart.Width}}" height="{{.Chart.Height}}" viewBox="0 0 {{.Chart.Width}} {{.Chart.Height}}">
+<line x1="0" y1="{{.Chart.Axis}}" x2="{{.Chart.Width}}" y2="{{.Chart.Axis}}" stroke="#ccc"/>
+{{- range .Chart.Bars}}
+<rect class="{{if .White}}white{{else}}black{{end}}" x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}"><title>{{.Title}}</title></rect>
+{{- end}}
+</svg></td>
+<td><a href="{{.PGN}}">{{.PGN}}</a></td>
+</tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,84 @@
+package tournament
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"chess-tui/locale"
+
+	"github.com/notnil/chess"
+)
+
+func TestStandings(t *testing.T) {
+	games := []*GameResult{
+		{White: "a", Black: "b", Outcome: chess.WhiteWon},
+		{White: "b", Black: "a", Outcome: chess.Draw},
+		{White: "b", Black: "c", Outcome: chess.BlackWon},
+	}
+	standings := Standings(games)
+	if len(standings) != 3 {
+		t.Fatalf("Expected 3 players, got %d", len(standings))
+	}
+	// a has 1.5 points, c 1 and b 0.5
+	if standings[0].Name != "a" || standings[0].Points != 1.5 || standings[0].Wins != 1 || standings[0].Draws != 1 {
+		t.Errorf("Expected a first with 1.5 points, got %+v", standings[0])
+	}
+	if last := standings[2]; last.Name != "b" || last.Losses != 2 || last.Games != 3 {
+		t.Errorf("Expected b last with 2 losses in 3 games, got %+v", last)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "report")
+	games := []*GameResult{
+		{White: "llama", Black: "<gpt>", Outcome: chess.WhiteWon, Reason: "checkmate", Moves: []string{"e4", "e5"},
+			PGN: "[White \"llama\"]\n\n1. e4 e5 1-0", MoveTimes: TimeUsage{time.Second, 3 * time.Second}},
+		{White: "<gpt>", Black: "llama", Outcome: chess.Draw, PGN: "[White \"<gpt>\"]\n\n1/2-1/2"},
+	}
+	if err := WriteReport(dir, "Spring Arena", games, locale.C); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatalf("Expected index.html, got %v", err)
+	}
+	html := string(index)
+	for _, want := range []string{"<title>Spring Arena</title>", `href="game-001.pgn"`, `href="game-002.pgn"`, "1.5 / 2", "0.5 / 2", "&lt;gpt&gt;", "<rect"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected the report to contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<gpt>") {
+		t.Error("Expected player names to be escaped")
+	}
+
+	pgn, err := os.ReadFile(filepath.Join(dir, "game-001.pgn"))
+	if err != nil || !strings.Contains(string(pgn), "1. e4 e5") {
+		t.Errorf("Expected the first game's PGN, got %q (%v)", pgn, err)
+	}
+	all, err := os.ReadFile(filepath.Join(dir, "games.pgn"))
+	if err != nil || !strings.Contains(string(all), "1/2-1/2") {
+		t.Errorf("Expected every game in games.pgn, got %q (%v)", all, err)
+	}
+}
+
+func TestWriteReportInLocale(t *testing.T) {
+	dir := t.TempDir()
+	games := []*GameResult{
+		{White: "a", Black: "b", Outcome: chess.Draw, MoveTimes: TimeUsage{1500 * time.Millisecond, time.Second}},
+	}
+	german, _ := locale.Parse("de_DE.UTF-8")
+	if err := WriteReport(dir, "Herbstturnier", games, german); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	index, _ := os.ReadFile(filepath.Join(dir, "index.html"))
+	for _, want := range []string{"0,5 / 1", ">0,5<", ">50%<", "1,5s"} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("Expected the German report to contain %q", want)
+		}
+	}
+}
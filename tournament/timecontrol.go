@@ -0,0 +1,235 @@
+package tournament
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"chess-tui/ai_player"
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// What happens when a player runs out of time
+const (
+	TimeoutFallback = "fallback" // the built-in engine plays the move
+	TimeoutForfeit  = "forfeit"  // the player loses on time
+)
+
+// What a clock does with time the machine spent suspended, such as a
+// laptop asleep with its lid shut
+const (
+	SuspendForgive = "forgive" // the gap isn't counted against the player
+	SuspendCount   = "count"   // the gap counts like any other thinking time
+)
+
+// suspendThreshold is how far the wall clock must run ahead of the
+// monotonic clock before the difference is taken for a suspension rather
+// than the system clock being adjusted
+const suspendThreshold = 2 * time.Second
+
+// TimeControl limits how long each player may think. A zero duration
+// disables its limit.
+type TimeControl struct {
+	PerMove   time.Duration `json:"per_move,omitempty"`
+	PerGame   time.Duration `json:"per_game,omitempty"`
+	OnTimeout string        `json:"on_timeout,omitempty"`
+	OnSuspend string        `json:"on_suspend,omitempty"` // SuspendForgive (the default) or SuspendCount
+
+	// White and Black, if set, replace the limits above for one side, to
+	// give a handicap
+	White *Limits `json:"white,omitempty"`
+	Black *Limits `json:"black,omitempty"`
+}
+
+// Limits are how long one side may think. A zero duration disables its limit.
+type Limits struct {
+	PerMove time.Duration `json:"per_move,omitempty"`
+	PerGame time.Duration `json:"per_game,omitempty"`
+}
+
+// For returns the limits the side plays under
+func (c TimeControl) For(color chess.Color) Limits {
+	if color == chess.White && c.White != nil {
+		return *c.White
+	}
+	if color == chess.Black && c.Black != nil {
+		return *c.Black
+	}
+	return Limits{PerMove: c.PerMove, PerGame: c.PerGame}
+}
+
+// Limited reports whether either side has a limit
+func (c TimeControl) Limited() bool {
+	return c.For(chess.White) != (Limits{}) || c.For(chess.Black) != (Limits{})
+}
+
+// Limit returns how long the side may think about its next move, having
+// used used of its time so far, and which budget applies. ok is false when
+// the side has no limit.
+func (c TimeControl) Limit(color chess.Color, used time.Duration) (limit time.Duration, budget string, ok bool) {
+	limits := c.For(color)
+	if limits.PerMove > 0 {
+		limit, budget, ok = limits.PerMove, "per-move", true
+	}
+	if limits.PerGame > 0 {
+		remaining := max(limits.PerGame-used, 0)
+		if !ok || remaining < limit {
+			limit, budget, ok = remaining, "per-game", true
+		}
+	}
+	return limit, budget, ok
+}
+
+// Elapsed returns the thinking time from start to now, and any suspension
+// detected in between: the wall clock counts time the machine spent asleep
+// while the monotonic clock doesn't, so a jump in the one the other lacks
+// is a suspension. The gap is included in elapsed only when onSuspend is
+// SuspendCount.
+func Elapsed(start, now time.Time, onSuspend string) (elapsed, gap time.Duration) {
+	return suspended(now.Sub(start), now.Round(0).Sub(start.Round(0)), onSuspend)
+}
+
+// suspended compares the monotonic and wall clock readings of the same span
+func suspended(monotonic, wall time.Duration, onSuspend string) (elapsed, gap time.Duration) {
+	elapsed = monotonic
+	if wall-monotonic > suspendThreshold {
+		gap = wall - monotonic
+	}
+	if onSuspend == SuspendCount {
+		elapsed += gap
+	}
+	return elapsed, gap
+}
+
+// Violation records a player exceeding its time budget
+type Violation struct {
+	Player string
+	Color  chess.Color
+	Ply    int // index of the move that overran
+	Limit  time.Duration
+	Budget string // "per-move" or "per-game"
+	Action string // TimeoutFallback or TimeoutForfeit
+}
+
+// String describes the violation for logs and PGN comments
+func (v Violation) String() string {
+	action := "fallback move played"
+	if v.Action == TimeoutForfeit {
+		action = "forfeit"
+	}
+	return fmt.Sprintf("%s exceeded the %s limit of %s; %s", v.Color.Name(), v.Budget, v.Limit, action)
+}
+
+// errTimeout is returned when a player does not reply within its limit
+var errTimeout = fmt.Errorf("out of time")
+
+// clock tracks the time each side has used in a game
+type clock struct {
+	control TimeControl
+	used    map[chess.Color]time.Duration
+}
+
+// newClock creates a clock for one game
+func newClock(control TimeControl) *clock {
+	return &clock{
+		control: control,
+		used:    make(map[chess.Color]time.Duration),
+	}
+}
+
+// limit returns how long the side may think about its next move and which
+// budget applies. ok is false when the side has no limit.
+func (c *clock) limit(color chess.Color) (limit time.Duration, budget string, ok bool) {
+	return c.control.Limit(color, c.used[color])
+}
+
+// charge adds the thinking time since start to the side's total, capped
+// at limit. It reports whether a suspension counted with SuspendCount took
+// the side over its limit, which the timer, stopped while the machine
+// slept, doesn't notice.
+func (c *clock) charge(color chess.Color, start time.Time, limit time.Duration) bool {
+	elapsed, gap := Elapsed(start, time.Now(), c.control.OnSuspend)
+	if gap > 0 {
+		slog.Info("Machine was suspended during the move", "color", color.Name(), "gap", gap, "counted", c.control.OnSuspend == SuspendCount)
+	}
+	c.used[color] += min(elapsed, limit)
+	return gap > 0 && c.control.OnSuspend == SuspendCount && elapsed > limit
+}
+
+// withTimeout runs fn, giving up after limit. fn's context is cancelled
+// then; until fn notices, it keeps running in the background, so it must
+// not share state with the caller, and its result is dropped.
+func withTimeout[T any](limit time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if limit <= 0 {
+		return zero, errTimeout
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), limit)
+	defer cancel()
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn(ctx)
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return zero, errTimeout
+	}
+}
+
+// clonePosition copies position, for a request that may outlive its turn
+func clonePosition(position *chess.Position) *chess.Position {
+	clone := &chess.Position{}
+	if err := clone.UnmarshalText([]byte(position.String())); err != nil {
+		// A position always reads back its own FEN
+		panic(fmt.Sprintf("tournament: copying %s failed: %v", position, err))
+	}
+	return clone
+}
+
+// FallbackMove asks the built-in engine for a move, for a player out of time
+func FallbackMove(position *chess.Position) (*chess.Move, error) {
+	reply, err := ai_player.NewEngineProvider().SelectMove(context.Background(), ai_player.MoveRequest{
+		FEN: position.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to select fallback move: %w", err)
+	}
+	return notation.Decode(position, reply.Notation)
+}
+
+// encodePGN writes the game as PGN with comments after the given plies
+func encodePGN(game *chess.Game, comments map[int][]string) string {
+	var b strings.Builder
+	for _, tag := range game.TagPairs() {
+		fmt.Fprintf(&b, "[%s \"%s\"]\n", tag.Key, tag.Value)
+	}
+	b.WriteString("\n")
+
+	positions := game.Positions()
+	var tokens []string
+	for i, move := range game.Moves() {
+		if i%2 == 0 {
+			tokens = append(tokens, fmt.Sprintf("%d.", i/2+1))
+		}
+		tokens = append(tokens, notation.Encode(positions[i], move))
+		for _, comment := range comments[i] {
+			tokens = append(tokens, "{ "+comment+" }")
+		}
+	}
+	tokens = append(tokens, string(game.Outcome()))
+	b.WriteString(strings.Join(tokens, " "))
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package tournament
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/notnil/chess"
+)
+
+// timeBars are the bar heights of the time chart, from the quickest move to the longest
+var timeBars = []rune("▁▂▃▄▅▆▇█")
+
+// TimeUsage is the thinking time of each move of a game, indexed by ply
+type TimeUsage []time.Duration
+
+// Moves returns one side's thinking times, in order
+func (u TimeUsage) Moves(color chess.Color) []time.Duration {
+	var moves []time.Duration
+	for ply, elapsed := range u {
+		if (ply%2 == 0) == (color == chess.White) {
+			moves = append(moves, elapsed)
+		}
+	}
+	return moves
+}
+
+// Total returns the time one side spent thinking
+func (u TimeUsage) Total(color chess.Color) time.Duration {
+	var total time.Duration
+	for _, elapsed := range u.Moves(color) {
+		total += elapsed
+	}
+	return total
+}
+
+// Chart draws each side's moves as a row of bars, one per move and scaled
+// to the longest move of the game, followed by the side's total compared
+// against the time control, e.g.
+//
+//	White ▁▂▅█▃▁  1m12s, avg 3.6s — 24% of 5m0s
+func (u TimeUsage) Chart(control TimeControl) string {
+	longest := time.Duration(0)
+	for _, elapsed := range u {
+		longest = max(longest, elapsed)
+	}
+
+	var sb strings.Builder
+	for _, color := range []chess.Color{chess.White, chess.Black} {
+		moves := u.Moves(color)
+		fmt.Fprintf(&sb, "%-5s ", color.Name())
+		for _, elapsed := range moves {
+			level := 0
+			if longest > 0 {
+				level = int(int64(elapsed) * int64(len(timeBars)-1) / int64(longest))
+			}
+			sb.WriteRune(timeBars[level])
+		}
+		sb.WriteString("  " + u.summary(color, control) + "\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// summary describes one side's time: the total and average, and how much
+// of the time control it used
+func (u TimeUsage) summary(color chess.Color, control TimeControl) string {
+	moves := u.Moves(color)
+	if len(moves) == 0 {
+		return "no moves"
+	}
+	total := u.Total(color)
+	line := fmt.Sprintf("%s, avg %s", roundTime(total), roundTime(total/time.Duration(len(moves))))
+	limits := control.For(color)
+	if limits.PerGame > 0 {
+		line += fmt.Sprintf(" — %.0f%% of %s", float64(total)*100/float64(limits.PerGame), limits.PerGame)
+	}
+	if limits.PerMove > 0 {
+		longest := time.Duration(0)
+		for _, elapsed := range moves {
+			longest = max(longest, elapsed)
+		}
+		line += fmt.Sprintf(" — longest %s of %s per move", roundTime(longest), limits.PerMove)
+	}
+	return line
+}
+
+// roundTime rounds a duration for display: to tenths of a second under a
+// minute, and whole seconds above
+func roundTime(d time.Duration) time.Duration {
+	if d < time.Minute {
+		return d.Round(100 * time.Millisecond)
+	}
+	return d.Round(time.Second)
+}
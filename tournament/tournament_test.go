@@ -0,0 +1,378 @@
+package tournament
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"chess-tui/ai_player"
+	"chess-tui/rules"
+
+	"github.com/notnil/chess"
+)
+
+// scriptedPlayer plays moves from a list, then repeats the last reply
+type scriptedPlayer struct {
+	moves []string
+	eval  *int
+	next  int
+}
+
+func (p *scriptedPlayer) GetMove(boardState string, gameHistory []string) (*ai_player.ChessMove, error) {
+	if p.next >= len(p.moves) {
+		return nil, fmt.Errorf("out of moves")
+	}
+	move := p.moves[p.next]
+	p.next++
+	return &ai_player.ChessMove{Notation: move, Eval: p.eval}, nil
+}
+
+func TestPlayRoundWritesSevenTagRoster(t *testing.T) {
+	white := Entrant{Name: "w", Player: &scriptedPlayer{moves: []string{"f3", "g4"}}}
+	black := Entrant{Name: "b", Player: &scriptedPlayer{moves: []string{"e5", "Qh4#"}}}
+
+	result, err := NewMatch(Adjudication{}).PlayRound(3, white, black)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var tags []string
+	for _, line := range strings.Split(result.PGN, "\n") {
+		if key, _, ok := strings.Cut(strings.TrimPrefix(line, "["), " "); ok && strings.HasPrefix(line, "[") {
+			tags = append(tags, key)
+		}
+	}
+	if got := strings.Join(tags[:7], " "); got != "Event Site Date Round White Black Result" {
+		t.Errorf("Expected the Seven Tag Roster first, got %s", got)
+	}
+	for _, want := range []string{`[Round "3"]`, `[Result "0-1"]`, `[Date "` + time.Now().Format("2006.01.02")} {
+		if !strings.Contains(result.PGN, want) {
+			t.Errorf("Expected %s in the PGN, got %s", want, result.PGN)
+		}
+	}
+}
+
+func TestPlayGameCheckmate(t *testing.T) {
+	white := Entrant{Name: "w", Player: &scriptedPlayer{moves: []string{"f3", "g4"}}}
+	black := Entrant{Name: "b", Player: &scriptedPlayer{moves: []string{"e5", "Qh4#"}}}
+
+	result, err := NewMatch(Adjudication{}).PlayGame(white, black)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Outcome != chess.BlackWon || result.Reason != "checkmate" {
+		t.Errorf("Expected black to win by checkmate, got %s (%s)", result.Outcome, result.Reason)
+	}
+	if len(result.MoveTimes) != 4 {
+		t.Errorf("Expected the time of all 4 moves, got %v", result.MoveTimes)
+	}
+	if !strings.Contains(result.PGN, "Qh4#") {
+		t.Errorf("Expected the moves in the PGN, got %s", result.PGN)
+	}
+
+	if record := result.Record(); record.Result != "0-1" || len(record.Moves) != 4 || len(record.MoveTimes) != 4 || record.White != "w" {
+		t.Errorf("Expected the game as a game log record, got %+v", record)
+	}
+}
+
+func TestPlayGameForfeitOnIllegalMoves(t *testing.T) {
+	white := Entrant{Name: "w", Player: &scriptedPlayer{moves: []string{"e5", "Ke3", "zz"}}}
+	black := Entrant{Name: "b", Player: &scriptedPlayer{}}
+
+	result, err := NewMatch(Adjudication{}).PlayGame(white, black)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Outcome != chess.BlackWon || !strings.HasPrefix(result.Reason, "White forfeits") {
+		t.Errorf("Expected white to forfeit, got %s (%s)", result.Outcome, result.Reason)
+	}
+}
+
+func TestAdjudicateHopelessPosition(t *testing.T) {
+	hopeless, fine := -1500, 1500
+	rules := Adjudication{ResignEval: 1000, ResignMoves: 2}
+	white := Entrant{Name: "w", Player: &scriptedPlayer{moves: []string{"Nf3", "Ng1", "Nf3"}, eval: &hopeless}}
+	black := Entrant{Name: "b", Player: &scriptedPlayer{moves: []string{"Nf6", "Ng8", "Nf6"}, eval: &fine}}
+
+	result, err := NewMatch(rules).PlayGame(white, black)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Outcome != chess.BlackWon || !strings.Contains(result.Reason, "hopeless") {
+		t.Errorf("Expected white to be adjudicated lost, got %s (%s)", result.Outcome, result.Reason)
+	}
+	if len(result.Moves) != 3 {
+		t.Errorf("Expected adjudication after white's second move, got %v", result.Moves)
+	}
+}
+
+func TestAdjudicateLevelDraw(t *testing.T) {
+	judge := newAdjudicator(Adjudication{DrawAfterMove: 2, DrawEval: 20, DrawEvalMoves: 2})
+	position := chess.NewGame().Position()
+
+	moves := []struct {
+		mover  chess.Color
+		number int
+	}{{chess.White, 1}, {chess.Black, 1}, {chess.White, 2}}
+	for _, m := range moves {
+		if outcome, _ := judge.check(position, m.mover, m.number, 5); outcome != chess.NoOutcome {
+			t.Fatalf("Expected play to continue at move %d", m.number)
+		}
+	}
+
+	if outcome, reason := judge.check(position, chess.Black, 2, -10); outcome != chess.Draw {
+		t.Errorf("Expected a draw once both sides are level, got %s (%s)", outcome, reason)
+	}
+}
+
+func TestPlayGameHouseRules(t *testing.T) {
+	white := Entrant{Name: "w", Player: &scriptedPlayer{moves: []string{"e4", "Nf3", "exd5"}}}
+	black := Entrant{Name: "b", Player: &scriptedPlayer{moves: []string{"d5", "Nf6", "Qxd5"}}}
+
+	match := NewMatch(Adjudication{})
+	match.HouseRules = rules.Rules{MustCapture: true, MaterialMove: 2}
+	result, err := match.PlayGame(white, black)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Join(result.Moves, " ") != "e4 d5 exd5 Qxd5" {
+		t.Errorf("Expected Nf3 and Nf6 refused while captures were on, got %v", result.Moves)
+	}
+	if result.Outcome != chess.Draw || result.Reason != "house rules: material at move 2" {
+		t.Errorf("Expected a draw on level material, got %s (%s)", result.Outcome, result.Reason)
+	}
+}
+
+func TestPlayRoundReportsMoves(t *testing.T) {
+	white := Entrant{Name: "w", Player: &scriptedPlayer{moves: []string{"f3", "g4"}}}
+	black := Entrant{Name: "b", Player: &scriptedPlayer{moves: []string{"e5", "Qh4#"}}}
+
+	var boards []Board
+	match := NewMatch(Adjudication{})
+	match.OnMove = func(board Board) { boards = append(boards, board) }
+	if _, err := match.PlayRound(2, white, black); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(boards) != 5 {
+		t.Fatalf("Expected the start and 4 moves, got %d boards", len(boards))
+	}
+	if first := boards[0]; first.Round != 2 || first.Plies != 0 || first.LastMove != "" || first.White != "w" {
+		t.Errorf("Expected round 2 at the start, got %+v", first)
+	}
+	last := boards[4]
+	if last.LastMove != "Qh4#" || last.Plies != 4 || !strings.HasPrefix(last.FEN, "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/") {
+		t.Errorf("Expected the board after Qh4#, got %+v", last)
+	}
+}
+
+// cancelingPlayer cancels the match's context when asked for a move, as
+// Ctrl+C would, and fails like a request cut short
+type cancelingPlayer struct{ cancel context.CancelFunc }
+
+func (p cancelingPlayer) GetMove(boardState string, gameHistory []string) (*ai_player.ChessMove, error) {
+	p.cancel()
+	return nil, context.Canceled
+}
+
+func TestPlayGameInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	white := Entrant{Name: "w", Player: &scriptedPlayer{moves: []string{"e4"}}}
+	black := Entrant{Name: "b", Player: cancelingPlayer{cancel}}
+
+	match := NewMatch(Adjudication{})
+	match.Context = ctx
+	result, err := match.PlayGame(white, black)
+	if !errors.Is(err, ErrInterrupted) || result != nil {
+		t.Errorf("Expected the game to be interrupted without a result, got %v (%v)", result, err)
+	}
+	if _, err := match.PlayGame(white, black); !errors.Is(err, ErrInterrupted) {
+		t.Errorf("Expected no new game to start, got %v", err)
+	}
+}
+
+// slowPlayer takes longer than any test time limit to reply
+type slowPlayer struct{}
+
+func (slowPlayer) GetMove(boardState string, gameHistory []string) (*ai_player.ChessMove, error) {
+	time.Sleep(time.Second)
+	return &ai_player.ChessMove{Notation: "a3"}, nil
+}
+
+func TestPlayGameTimeoutFallback(t *testing.T) {
+	white := Entrant{Name: "w", Player: slowPlayer{}}
+	black := Entrant{Name: "b", Player: &scriptedPlayer{moves: []string{"e5"}}}
+
+	match := NewMatch(Adjudication{})
+	match.TimeControl = TimeControl{PerMove: 10 * time.Millisecond, OnTimeout: TimeoutFallback}
+	result, err := match.PlayGame(white, black)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Moves) != 3 {
+		t.Fatalf("Expected white's fallback moves to keep the game going, got %v", result.Moves)
+	}
+	if len(result.Violations) != 2 || result.Violations[0].Action != TimeoutFallback {
+		t.Errorf("Expected two fallback violations, got %v", result.Violations)
+	}
+	if !strings.Contains(result.PGN, "{ White exceeded the per-move limit of 10ms; fallback move played }") {
+		t.Errorf("Expected the violation as a PGN comment, got %s", result.PGN)
+	}
+}
+
+// latePlayer replies just after the test time limit, telling replied
+type latePlayer struct {
+	replied chan struct{}
+}
+
+func (p latePlayer) GetMove(boardState string, gameHistory []string) (*ai_player.ChessMove, error) {
+	time.Sleep(30 * time.Millisecond)
+	defer func() { p.replied <- struct{}{} }()
+	return &ai_player.ChessMove{Notation: "Nf3"}, nil
+}
+
+func TestPlayGameLateMoveAfterFallback(t *testing.T) {
+	late := latePlayer{replied: make(chan struct{}, 2)}
+	white := Entrant{Name: "w", Player: late}
+	black := Entrant{Name: "b", Player: &scriptedPlayer{moves: []string{"e5"}}}
+
+	match := NewMatch(Adjudication{})
+	match.TimeControl = TimeControl{PerMove: 10 * time.Millisecond, OnTimeout: TimeoutFallback}
+	result, err := match.PlayGame(white, black)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Violations) != 2 || result.Violations[0].Action != TimeoutFallback {
+		t.Errorf("Expected two fallback violations, got %v", result.Violations)
+	}
+
+	// The late replies arrive while the game goes on without them; under
+	// -race, touching the game's board from them would fail the test
+	for range 2 {
+		select {
+		case <-late.replied:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the late replies")
+		}
+	}
+}
+
+func TestPlayGameTimeoutForfeit(t *testing.T) {
+	white := Entrant{Name: "w", Player: &scriptedPlayer{moves: []string{"e4"}}}
+	black := Entrant{Name: "b", Player: slowPlayer{}}
+
+	match := NewMatch(Adjudication{})
+	match.TimeControl = TimeControl{PerGame: 10 * time.Millisecond, OnTimeout: TimeoutForfeit}
+	result, err := match.PlayGame(white, black)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Outcome != chess.WhiteWon || result.Reason != "Black forfeits on time" {
+		t.Errorf("Expected black to forfeit on time, got %s (%s)", result.Outcome, result.Reason)
+	}
+	if len(result.Violations) != 1 || result.Violations[0].Budget != "per-game" {
+		t.Errorf("Expected one per-game violation, got %v", result.Violations)
+	}
+	if !strings.Contains(result.PGN, "1. e4 { [%clk 0:00:00] } { Black exceeded the per-game limit of 10ms; forfeit }") {
+		t.Errorf("Expected the violation as a PGN comment, got %s", result.PGN)
+	}
+}
+
+func TestPlayGameHandicap(t *testing.T) {
+	// Only the slow player is held to a per-move limit, whichever side it plays
+	slow := Entrant{Name: "slow", Player: slowPlayer{}, Limits: &Limits{PerMove: 10 * time.Millisecond}}
+	fast := Entrant{Name: "fast", Player: &scriptedPlayer{moves: []string{"e4"}}}
+
+	match := NewMatch(Adjudication{})
+	match.TimeControl = TimeControl{PerGame: time.Minute, OnTimeout: TimeoutForfeit}
+	result, err := match.PlayGame(fast, slow)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Outcome != chess.WhiteWon || len(result.Violations) != 1 || result.Violations[0].Limit != 10*time.Millisecond {
+		t.Errorf("Expected black to forfeit on its own 10ms limit, got %s, %v", result.Outcome, result.Violations)
+	}
+	if limits := result.TimeControl.For(chess.White); limits.PerGame != time.Minute || limits.PerMove != 0 {
+		t.Errorf("Expected white to play under the match's limits, got %+v", limits)
+	}
+
+	control := TimeControl{PerMove: time.Minute, Black: &Limits{PerGame: 10 * time.Minute}}
+	if limit, budget, _ := control.Limit(chess.Black, 9*time.Minute); limit != time.Minute || budget != "per-game" {
+		t.Errorf("Expected black's last minute of its own budget, got %s %s", limit, budget)
+	}
+	if _, _, ok := (TimeControl{}).Limit(chess.White, 0); ok || (TimeControl{}).Limited() || !control.Limited() {
+		t.Error("Expected only the time control with limits to be limited")
+	}
+}
+
+func TestTimeUsageChart(t *testing.T) {
+	usage := TimeUsage{time.Second, 4 * time.Second, 3 * time.Second, 8 * time.Second, 2 * time.Second}
+	if total := usage.Total(chess.White); total != 6*time.Second {
+		t.Errorf("Expected White to use 6s, got %v", total)
+	}
+
+	chart := usage.Chart(TimeControl{PerGame: time.Minute, PerMove: 10 * time.Second})
+	lines := strings.Split(chart, "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a row per side, got %q", chart)
+	}
+	if want := "White ▁▃▂  6s, avg 2s — 10% of 1m0s — longest 3s of 10s per move"; lines[0] != want {
+		t.Errorf("Expected %q, got %q", want, lines[0])
+	}
+	if want := "Black ▄█  12s, avg 6s"; !strings.HasPrefix(lines[1], want) {
+		t.Errorf("Expected %q, got %q", want, lines[1])
+	}
+}
+
+func TestClockComments(t *testing.T) {
+	usage := TimeUsage{5 * time.Second, 2 * time.Second, 20 * time.Second}
+	control := TimeControl{PerGame: 10 * time.Minute, Black: &Limits{PerMove: 30 * time.Second}}
+	clocks := usage.Clocks(control)
+	want := []time.Duration{595 * time.Second, 28 * time.Second, 575 * time.Second}
+	for ply := range want {
+		if clocks[ply] != want[ply] {
+			t.Errorf("Expected %v left after ply %d, got %v", want[ply], ply+1, clocks[ply])
+		}
+	}
+	if clocks := usage.Clocks(TimeControl{Black: &Limits{PerMove: time.Minute}}); clocks[0] != -1 {
+		t.Errorf("Expected no clock for an unlimited side, got %v", clocks[0])
+	}
+
+	if got := ClockComment(575*time.Second + 900*time.Millisecond); got != "[%clk 0:09:35]" {
+		t.Errorf("Expected [%%clk 0:09:35], got %s", got)
+	}
+	for comment, want := range map[string]time.Duration{
+		"[%clk 0:09:35]":                   575 * time.Second,
+		"good move [%clk 1:00:07.5] [%emt": time.Hour + 7500*time.Millisecond,
+	} {
+		if left, ok := ParseClockComment(comment); !ok || left != want {
+			t.Errorf("Expected %v from %q, got %v", want, comment, left)
+		}
+	}
+	if _, ok := ParseClockComment("[%cal Ge2e4]"); ok {
+		t.Error("Expected no clock in a comment without a clock command")
+	}
+}
+
+func TestSuspendedGap(t *testing.T) {
+	elapsed, gap := suspended(3*time.Second, time.Hour+3*time.Second, SuspendForgive)
+	if elapsed != 3*time.Second || gap != time.Hour {
+		t.Errorf("Expected a forgiven hour's sleep, got %v elapsed and a %v gap", elapsed, gap)
+	}
+	elapsed, gap = suspended(3*time.Second, time.Hour+3*time.Second, SuspendCount)
+	if elapsed != time.Hour+3*time.Second || gap != time.Hour {
+		t.Errorf("Expected a counted hour's sleep, got %v elapsed and a %v gap", elapsed, gap)
+	}
+	// A small skew or the clock being set back isn't a suspension
+	for _, wall := range []time.Duration{4 * time.Second, -time.Minute} {
+		if elapsed, gap := suspended(3*time.Second, wall, SuspendCount); elapsed != 3*time.Second || gap != 0 {
+			t.Errorf("Expected no suspension for a wall clock span of %v, got %v elapsed and a %v gap", wall, elapsed, gap)
+		}
+	}
+
+	start := time.Now()
+	if elapsed, gap := Elapsed(start, start.Add(time.Second), SuspendCount); elapsed != time.Second || gap != 0 {
+		t.Errorf("Expected a second awake, got %v elapsed and a %v gap", elapsed, gap)
+	}
+}
@@ -0,0 +1,215 @@
+package tournament
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"chess-tui/ai_player"
+)
+
+// defaultEngineMoveTime is how long a UCI engine thinks per move
+const defaultEngineMoveTime = 100 * time.Millisecond
+
+// UCIEngine plays moves with an external UCI engine such as Stockfish,
+// optionally limited to a calibrated strength
+type UCIEngine struct {
+	Elo      int // 0 plays at full strength
+	MoveTime time.Duration
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Scanner
+	mu     sync.Mutex
+}
+
+// NewUCIEngine starts the engine at path and limits it to elo if nonzero
+func NewUCIEngine(path string, elo int) (*UCIEngine, error) {
+	cmd := exec.Command(path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open engine stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open engine stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start engine: %w", err)
+	}
+
+	engine := &UCIEngine{
+		Elo:      elo,
+		MoveTime: defaultEngineMoveTime,
+		cmd:      cmd,
+		stdin:    stdin,
+		stdout:   bufio.NewScanner(stdout),
+	}
+
+	if err := engine.handshake(); err != nil {
+		engine.Close()
+		return nil, err
+	}
+	return engine, nil
+}
+
+// handshake initializes the UCI session and applies the strength limit
+func (e *UCIEngine) handshake() error {
+	if err := e.send("uci"); err != nil {
+		return err
+	}
+	if _, err := e.waitFor("uciok"); err != nil {
+		return err
+	}
+	if e.Elo > 0 {
+		if err := e.send("setoption name UCI_LimitStrength value true"); err != nil {
+			return err
+		}
+		if err := e.send(fmt.Sprintf("setoption name UCI_Elo value %d", e.Elo)); err != nil {
+			return err
+		}
+	}
+	return e.ready()
+}
+
+// NewGame tells the engine a new game is starting
+func (e *UCIEngine) NewGame() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.send("ucinewgame"); err != nil {
+		return err
+	}
+	return e.ready()
+}
+
+// GetMove asks the engine for its best move in the FEN position
+func (e *UCIEngine) GetMove(boardState string, gameHistory []string) (*ai_player.ChessMove, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.send("position fen " + boardState); err != nil {
+		return nil, err
+	}
+	if err := e.send(fmt.Sprintf("go movetime %d", e.MoveTime.Milliseconds())); err != nil {
+		return nil, err
+	}
+
+	line, err := e.waitFor("bestmove")
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[1] == "(none)" {
+		return nil, fmt.Errorf("engine returned no move: %q", line)
+	}
+
+	return &ai_player.ChessMove{
+		Notation: fields[1],
+		Provider: "uci",
+	}, nil
+}
+
+// mateScore is what Evaluate reports for a forced mate, in centipawns
+const mateScore = 10000
+
+// Evaluate searches the FEN position and returns the engine's score in
+// centipawns for the side to move, with forced mates as ±10000
+func (e *UCIEngine) Evaluate(boardState string) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.send("position fen " + boardState); err != nil {
+		return 0, err
+	}
+	if err := e.send(fmt.Sprintf("go movetime %d", e.MoveTime.Milliseconds())); err != nil {
+		return 0, err
+	}
+
+	score, scored := 0, false
+	for e.stdout.Scan() {
+		line := strings.TrimSpace(e.stdout.Text())
+		if strings.HasPrefix(line, "bestmove") {
+			if !scored {
+				return 0, fmt.Errorf("engine reported no score for %s", boardState)
+			}
+			return score, nil
+		}
+		if value, ok := parseScore(line); ok {
+			score, scored = value, true
+		}
+	}
+	if err := e.stdout.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read engine output: %w", err)
+	}
+	return 0, fmt.Errorf("engine exited before \"bestmove\"")
+}
+
+// parseScore reads the score from a UCI info line
+func parseScore(line string) (int, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != "info" {
+		return 0, false
+	}
+	for i := 0; i+2 < len(fields); i++ {
+		if fields[i] != "score" {
+			continue
+		}
+		value, err := strconv.Atoi(fields[i+2])
+		if err != nil {
+			return 0, false
+		}
+		switch fields[i+1] {
+		case "cp":
+			return value, true
+		case "mate":
+			if value < 0 {
+				return -mateScore, true
+			}
+			return mateScore, true
+		}
+	}
+	return 0, false
+}
+
+// Close stops the engine
+func (e *UCIEngine) Close() error {
+	e.send("quit")
+	e.stdin.Close()
+	return e.cmd.Wait()
+}
+
+// ready waits until the engine has processed every command sent so far
+func (e *UCIEngine) ready() error {
+	if err := e.send("isready"); err != nil {
+		return err
+	}
+	_, err := e.waitFor("readyok")
+	return err
+}
+
+// send writes one command line to the engine
+func (e *UCIEngine) send(command string) error {
+	if _, err := io.WriteString(e.stdin, command+"\n"); err != nil {
+		return fmt.Errorf("failed to send %q to engine: %w", command, err)
+	}
+	return nil
+}
+
+// waitFor reads engine output until a line starting with prefix
+func (e *UCIEngine) waitFor(prefix string) (string, error) {
+	for e.stdout.Scan() {
+		if line := strings.TrimSpace(e.stdout.Text()); strings.HasPrefix(line, prefix) {
+			return line, nil
+		}
+	}
+	if err := e.stdout.Err(); err != nil {
+		return "", fmt.Errorf("failed to read engine output: %w", err)
+	}
+	return "", fmt.Errorf("engine exited before %q", prefix)
+}
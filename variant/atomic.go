@@ -0,0 +1,279 @@
+package variant
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// Atomic is chess where every capture is an explosion: the capturing piece,
+// the captured piece and every piece but a pawn next to the capture square
+// are removed. Blowing up the enemy king wins, and so kings can't capture.
+type Atomic struct{}
+
+// Name returns "Atomic"
+func (Atomic) Name() string {
+	return "Atomic"
+}
+
+// Rules explains explosions and how the kings are won
+func (Atomic) Rules() string {
+	return "Atomic: every capture is an explosion. The capturing piece, the captured piece and every piece except pawns on the eight squares around the capture are removed from the board. " +
+		"A player wins by exploding the enemy king, or by checkmate. " +
+		"Kings can't capture, and a move may not explode the mover's own king. " +
+		"Blowing up the enemy king wins even when it leaves the mover's king in check. " +
+		"Kings standing next to each other can't be checked, as neither can be captured without the other exploding."
+}
+
+// Start returns the standard starting position
+func (Atomic) Start() *Position {
+	return &Position{Board: chess.StartingPosition(), Promoted: make(map[chess.Square]bool)}
+}
+
+// Moves returns the moves that don't blow up the mover's king and don't
+// leave it in check, unless they blow up the enemy king
+func (a Atomic) Moves(p *Position) []Move {
+	var moves []Move
+	for _, move := range pseudoMoves(p.Board) {
+		if atomicLegal(p.Board.Board(), move, p.Turn()) {
+			moves = append(moves, Move{Board: move})
+		}
+	}
+	return moves
+}
+
+// hasMove reports whether the side to move has a legal move, stopping at
+// the first one rather than trying them all
+func (a Atomic) hasMove(p *Position) bool {
+	for _, move := range pseudoMoves(p.Board) {
+		if atomicLegal(p.Board.Board(), move, p.Turn()) {
+			return true
+		}
+	}
+	return false
+}
+
+// atomicLegal reports whether mover's move keeps its king on the board and
+// out of check, or blows up the enemy king
+func atomicLegal(board *chess.Board, move *chess.Move, mover chess.Color) bool {
+	after := explode(board, move)
+	if kingSquare(after, mover) == chess.NoSquare {
+		return false
+	}
+	return kingSquare(after, mover.Other()) == chess.NoSquare || !atomicCheck(after, mover)
+}
+
+// pseudoMoves returns the side to move's moves with checks ignored. The
+// engine generates them for the board with a knight standing in for the
+// mover's king, so none of them can be refused for exposing it; the king
+// itself may step to any empty square, and castles as the engine allows it.
+func pseudoMoves(position *chess.Position) []*chess.Move {
+	mover := position.Turn()
+	board := position.Board()
+	king := kingSquare(board, mover)
+	if king == chess.NoSquare {
+		return position.ValidMoves()
+	}
+
+	fields := strings.Fields(position.String())
+	fields[0] = withPiece(board, chess.NewPiece(chess.Knight, mover), king).String()
+	fields[2] = "-"
+	kingless := &chess.Position{}
+	if err := kingless.UnmarshalText([]byte(strings.Join(fields, " "))); err != nil {
+		panic(fmt.Sprintf("variant: taking the king off %s made an invalid position: %v", position, err))
+	}
+
+	var moves []*chess.Move
+	for _, move := range kingless.ValidMoves() {
+		if move.S1() != king {
+			moves = append(moves, move)
+		}
+	}
+	for _, step := range kingSteps {
+		file, rank := int(king.File())+step[0], int(king.Rank())+step[1]
+		if file < 0 || file > 7 || rank < 0 || rank > 7 || pieceAt(board, file, rank) != chess.NoPiece {
+			continue
+		}
+		to := chess.NewSquare(chess.File(file), chess.Rank(rank))
+		move, err := chess.UCINotation{}.Decode(position, king.String()+to.String())
+		if err != nil {
+			panic(fmt.Sprintf("variant: the king's step %s%s can't be written: %v", king, to, err))
+		}
+		moves = append(moves, move)
+	}
+	for _, move := range position.ValidMoves() {
+		if move.HasTag(chess.KingSideCastle) || move.HasTag(chess.QueenSideCastle) {
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
+
+// isCapture reports whether move takes a piece
+func isCapture(board *chess.Board, move *chess.Move) bool {
+	return move.HasTag(chess.EnPassant) || board.Piece(move.S2()) != chess.NoPiece
+}
+
+// blast returns the squares of the pieces a capture by move destroys, as
+// they stand before it: the capturing piece's, the captured piece's, and
+// those of the pieces other than pawns next to the capture square
+func blast(board *chess.Board, move *chess.Move) []chess.Square {
+	if !isCapture(board, move) {
+		return nil
+	}
+	from, to := move.S1(), move.S2()
+	squares := []chess.Square{from, to}
+	if move.HasTag(chess.EnPassant) {
+		squares[1] = chess.NewSquare(to.File(), from.Rank())
+	}
+	for _, step := range kingSteps {
+		file, rank := int(to.File())+step[0], int(to.Rank())+step[1]
+		square := chess.NewSquare(chess.File(file), chess.Rank(rank))
+		if piece := pieceAt(board, file, rank); piece != chess.NoPiece && piece.Type() != chess.Pawn && square != from {
+			squares = append(squares, square)
+		}
+	}
+	return squares
+}
+
+// explode returns board after move, with a capture's explosion
+func explode(board *chess.Board, move *chess.Move) *chess.Board {
+	squares := board.SquareMap()
+	piece := squares[move.S1()]
+	delete(squares, move.S1())
+	if move.Promo() != chess.NoPieceType {
+		piece = chess.NewPiece(move.Promo(), piece.Color())
+	}
+
+	if !isCapture(board, move) {
+		squares[move.S2()] = piece
+		if move.HasTag(chess.KingSideCastle) || move.HasTag(chess.QueenSideCastle) {
+			rank := move.S1().Rank()
+			rookFrom, rookTo := chess.NewSquare(chess.FileH, rank), chess.NewSquare(chess.FileF, rank)
+			if move.HasTag(chess.QueenSideCastle) {
+				rookFrom, rookTo = chess.NewSquare(chess.FileA, rank), chess.NewSquare(chess.FileD, rank)
+			}
+			squares[rookTo] = squares[rookFrom]
+			delete(squares, rookFrom)
+		}
+		return chess.NewBoard(squares)
+	}
+	for _, square := range blast(board, move) {
+		delete(squares, square)
+	}
+	return chess.NewBoard(squares)
+}
+
+// atomicCheck reports whether color's king is attacked while not standing
+// next to the other king, which no capture can take without exploding
+func atomicCheck(board *chess.Board, color chess.Color) bool {
+	king, other := kingSquare(board, color), kingSquare(board, color.Other())
+	if king == chess.NoSquare {
+		return false
+	}
+	if other != chess.NoSquare && abs(int(king.File())-int(other.File())) <= 1 && abs(int(king.Rank())-int(other.Rank())) <= 1 {
+		return false
+	}
+	return attacked(board, king, color.Other())
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Explosion returns the squares a capture blows up
+func (Atomic) Explosion(p *Position, m Move) []chess.Square {
+	if m.IsDrop() {
+		return nil
+	}
+	return blast(p.Board.Board(), m.Board)
+}
+
+// Play makes a move, exploding the pieces around a capture
+func (Atomic) Play(p *Position, m Move) *Position {
+	if !isCapture(p.Board.Board(), m.Board) {
+		return p.clone(p.Board.Update(m.Board))
+	}
+	return p.clone(exploded(p.Board, explode(p.Board.Board(), m.Board)))
+}
+
+// InCheck reports whether the side to move's king is attacked, kings side
+// by side being safe
+func (Atomic) InCheck(p *Position) bool {
+	return atomicCheck(p.Board.Board(), p.Turn())
+}
+
+// Outcome is a win for the side whose opponent's king exploded, checkmate
+// or stalemate once the side to move has no move, or a draw by the
+// fifty-move rule
+func (a Atomic) Outcome(p *Position) (chess.Outcome, string) {
+	board := p.Board.Board()
+	switch {
+	case kingSquare(board, chess.White) == chess.NoSquare:
+		return chess.BlackWon, "king exploded"
+	case kingSquare(board, chess.Black) == chess.NoSquare:
+		return chess.WhiteWon, "king exploded"
+	case a.hasMove(p):
+		return fiftyMoveRule(p)
+	case !a.InCheck(p):
+		return chess.Draw, "stalemate"
+	case p.Turn() == chess.White:
+		return chess.BlackWon, "checkmate"
+	}
+	return chess.WhiteWon, "checkmate"
+}
+
+// exploded returns the engine's position with board after a capture: the
+// other side is to move, with no en passant square, the fifty-move count
+// reset and only the castling rights whose king and rook survived
+func exploded(position *chess.Position, board *chess.Board) *chess.Position {
+	fields := strings.Fields(position.String())
+	fields[0] = board.String()
+
+	var rights strings.Builder
+	for _, right := range fields[2] {
+		home, ok := castlingHomes[right]
+		color := chess.White
+		if right >= 'a' {
+			color = chess.Black
+		}
+		if ok && board.Piece(home[0]) == chess.NewPiece(chess.King, color) && board.Piece(home[1]) == chess.NewPiece(chess.Rook, color) {
+			rights.WriteRune(right)
+		}
+	}
+	fields[2] = rights.String()
+	if fields[2] == "" {
+		fields[2] = "-"
+	}
+	fields[3] = "-"
+	fields[4] = "0"
+	fields[1] = "b"
+	if position.Turn() == chess.Black {
+		fields[1] = "w"
+		if number, err := strconv.Atoi(fields[5]); err == nil {
+			fields[5] = strconv.Itoa(number + 1)
+		}
+	}
+
+	next := &chess.Position{}
+	if err := next.UnmarshalText([]byte(strings.Join(fields, " "))); err != nil {
+		// Explosions only ever empty squares
+		panic(fmt.Sprintf("variant: an explosion made an invalid position: %v", err))
+	}
+	return next
+}
+
+// castlingHomes are the king's and rook's squares each castling right in
+// FEN needs
+var castlingHomes = map[rune][2]chess.Square{
+	'K': {chess.E1, chess.H1},
+	'Q': {chess.E1, chess.A1},
+	'k': {chess.E8, chess.H8},
+	'q': {chess.E8, chess.A8},
+}
@@ -0,0 +1,119 @@
+package variant
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// atomicGame sets up an Atomic game from FEN
+func atomicGame(t *testing.T, fen string) *Game {
+	t.Helper()
+	game, err := NewGameFromFEN(Atomic{}, fen)
+	if err != nil {
+		t.Fatalf("Bad FEN %s: %v", fen, err)
+	}
+	return game
+}
+
+func TestAtomicCaptureExplodesTheKing(t *testing.T) {
+	game := NewGame(Atomic{})
+	for _, move := range []string{"Nf3", "a6", "Ne5", "a5"} {
+		if _, err := game.Move(move); err != nil {
+			t.Fatalf("Expected %s to be legal, got %v", move, err)
+		}
+	}
+
+	m, err := Decode(Atomic{}, game.Position(), "Nxd7")
+	if err != nil {
+		t.Fatalf("Expected Nxd7 to be legal, got %v", err)
+	}
+	blast := Atomic{}.Explosion(game.Position(), m)
+	if len(blast) != 5 || !slices.Contains(blast, chess.E8) || slices.Contains(blast, chess.E7) {
+		t.Errorf("Expected the knight, d7, c8, d8 and e8 to explode, got %v", blast)
+	}
+
+	if _, err := game.Move("Nxd7"); err != nil {
+		t.Fatalf("Expected Nxd7 to be legal, got %v", err)
+	}
+	board := game.Position().Board.Board()
+	for _, square := range []chess.Square{chess.D7, chess.C8, chess.D8, chess.E8} {
+		if board.Piece(square) != chess.NoPiece {
+			t.Errorf("Expected %s to be blown up, got %s", square, game.Position())
+		}
+	}
+	if board.Piece(chess.C7) != chess.BlackPawn || board.Piece(chess.E7) != chess.BlackPawn {
+		t.Errorf("Expected the pawns beside the blast to survive, got %s", game.Position())
+	}
+	if outcome, method := game.Outcome(); outcome != chess.WhiteWon || method != "king exploded" {
+		t.Errorf("Expected White to win by exploding the king, got %s %s", outcome, method)
+	}
+}
+
+func TestAtomicKingsDontCapture(t *testing.T) {
+	game := atomicGame(t, "4k3/8/8/8/8/8/3p4/3RK3 w - - 0 1")
+	for _, move := range []string{"Kxd2", "Rxd2"} {
+		if _, err := game.Move(move); !errors.Is(err, notation.ErrIllegal) || !strings.Contains(err.Error(), "not allowed in Atomic") {
+			t.Errorf("Expected %s to be refused, got %v", move, err)
+		}
+	}
+	if moves := game.LegalMoves(); !slices.Contains(moves, "Ke2") || slices.Contains(moves, "Rxd2") {
+		t.Errorf("Expected quiet king moves only, got %v", moves)
+	}
+}
+
+func TestAtomicExplodingTheKingBeatsCheck(t *testing.T) {
+	game := atomicGame(t, "k7/1n6/8/8/8/8/8/1R2K2r w - - 0 1")
+	if !(Atomic{}).InCheck(game.Position()) {
+		t.Fatalf("Expected White to be in check")
+	}
+	if moves := game.LegalMoves(); !slices.Contains(moves, "Rxb7") || slices.Contains(moves, "Rb2") {
+		t.Errorf("Expected Rxb7 to be legal and Rb2 not, got %v", moves)
+	}
+	if _, err := game.Move("Rxb7"); err != nil {
+		t.Fatalf("Expected Rxb7 to be legal, got %v", err)
+	}
+	if outcome, _ := game.Outcome(); outcome != chess.WhiteWon {
+		t.Errorf("Expected White to win, got %s", outcome)
+	}
+
+	// Kings side by side can't be checked
+	touching := atomicGame(t, "8/8/8/8/8/8/8/r2Kk3 w - - 0 1")
+	if (Atomic{}).InCheck(touching.Position()) {
+		t.Errorf("Expected the kings side by side to be safe from the rook")
+	}
+}
+
+func TestAtomicExplosionsTakeCastlingRights(t *testing.T) {
+	game := atomicGame(t, "r3k2r/8/8/8/8/8/6p1/R3K2R b KQkq - 0 1")
+	if _, err := game.Move("gxh1=Q"); err != nil {
+		t.Fatalf("Expected gxh1=Q to be legal, got %v", err)
+	}
+	if fen := game.Position().String(); fen != "r3k2r/8/8/8/8/8/8/R3K3 w Qkq - 0 2" {
+		t.Errorf("Expected the rook and pawn gone with White's kingside castling, got %s", fen)
+	}
+	if moves := game.LegalMoves(); !slices.Contains(moves, "O-O-O") {
+		t.Errorf("Expected White to keep queenside castling, got %v", moves)
+	}
+}
+
+func TestAtomicFiftyMoveRule(t *testing.T) {
+	game := atomicGame(t, "4k3/8/8/8/8/8/8/R3K3 w - - 99 80")
+	if outcome, _ := game.Outcome(); outcome != chess.NoOutcome {
+		t.Fatalf("Expected the game to go on, got %s", outcome)
+	}
+	if _, err := game.Move("Ra2"); err != nil {
+		t.Fatalf("Expected Ra2 to be legal, got %v", err)
+	}
+	if outcome, method := game.Outcome(); outcome != chess.Draw || method != "fifty-move rule" {
+		t.Errorf("Expected a draw by the fifty-move rule, got %s %s", outcome, method)
+	}
+	if _, err := game.Move("Kd7"); !errors.Is(err, notation.ErrIllegal) {
+		t.Errorf("Expected no moves after the draw, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package variant
+
+import "github.com/notnil/chess"
+
+// Directions pieces move in, as file and rank steps
+var (
+	knightSteps   = [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingSteps     = [][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+	straightLines = [][2]int{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+	diagonalLines = [][2]int{{1, 1}, {-1, 1}, {-1, -1}, {1, -1}}
+)
+
+// pieceAt returns the piece on the file and rank, or chess.NoPiece off the board
+func pieceAt(board *chess.Board, file, rank int) chess.Piece {
+	if file < 0 || file > 7 || rank < 0 || rank > 7 {
+		return chess.NoPiece
+	}
+	return board.Piece(chess.NewSquare(chess.File(file), chess.Rank(rank)))
+}
+
+// attacked reports whether any of by's pieces attacks the square. The
+// engine only answers this for the side to move's king, and variants need
+// it for positions the engine never reaches, such as after a drop.
+func attacked(board *chess.Board, square chess.Square, by chess.Color) bool {
+	file, rank := int(square.File()), int(square.Rank())
+	is := func(piece chess.Piece, types ...chess.PieceType) bool {
+		if piece == chess.NoPiece || piece.Color() != by {
+			return false
+		}
+		for _, t := range types {
+			if piece.Type() == t {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Pawns attack forwards, so an attacking pawn stands a rank behind
+	behind := -1
+	if by == chess.Black {
+		behind = 1
+	}
+	if is(pieceAt(board, file-1, rank+behind), chess.Pawn) || is(pieceAt(board, file+1, rank+behind), chess.Pawn) {
+		return true
+	}
+	for _, step := range knightSteps {
+		if is(pieceAt(board, file+step[0], rank+step[1]), chess.Knight) {
+			return true
+		}
+	}
+	for _, step := range kingSteps {
+		if is(pieceAt(board, file+step[0], rank+step[1]), chess.King) {
+			return true
+		}
+	}
+
+	// Sliders attack along their lines up to the first piece in the way
+	slide := func(lines [][2]int, types ...chess.PieceType) bool {
+		for _, line := range lines {
+			for f, r := file+line[0], rank+line[1]; f >= 0 && f <= 7 && r >= 0 && r <= 7; f, r = f+line[0], r+line[1] {
+				if piece := pieceAt(board, f, r); piece != chess.NoPiece {
+					if is(piece, types...) {
+						return true
+					}
+					break
+				}
+			}
+		}
+		return false
+	}
+	return slide(straightLines, chess.Rook, chess.Queen) || slide(diagonalLines, chess.Bishop, chess.Queen)
+}
+
+// kingSquare returns where color's king is, or chess.NoSquare if it has none
+func kingSquare(board *chess.Board, color chess.Color) chess.Square {
+	king := chess.NewPiece(chess.King, color)
+	for square, piece := range board.SquareMap() {
+		if piece == king {
+			return square
+		}
+	}
+	return chess.NoSquare
+}
+
+// inCheck reports whether color's king is attacked
+func inCheck(board *chess.Board, color chess.Color) bool {
+	king := kingSquare(board, color)
+	return king != chess.NoSquare && attacked(board, king, color.Other())
+}
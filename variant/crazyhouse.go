@@ -0,0 +1,166 @@
+package variant
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// Crazyhouse is chess where captured pieces change sides: they join the
+// capturer's reserve, and instead of moving a player may drop one of them
+// onto an empty square
+type Crazyhouse struct{}
+
+// Name returns "Crazyhouse"
+func (Crazyhouse) Name() string {
+	return "Crazyhouse"
+}
+
+// Rules explains drops and the reserve
+func (Crazyhouse) Rules() string {
+	return "Crazyhouse: a captured piece changes sides and joins the capturer's reserve. " +
+		"Instead of moving, a player may drop a piece from their reserve onto any empty square, written like N@f3 or P@e4. " +
+		"Pawns can't be dropped on the first or last rank. " +
+		"A promoted piece goes back to the reserve as a pawn when it is captured. " +
+		"A drop may block a check, so a mate must leave no drop that stops it."
+}
+
+// Start returns the standard starting position with empty reserves
+func (Crazyhouse) Start() *Position {
+	return &Position{
+		Board:    chess.StartingPosition(),
+		Reserves: map[chess.Color]Reserve{chess.White: {}, chess.Black: {}},
+		Promoted: make(map[chess.Square]bool),
+	}
+}
+
+// Moves returns the engine's moves and the legal drops
+func (Crazyhouse) Moves(p *Position) []Move {
+	var moves []Move
+	for _, move := range p.Board.ValidMoves() {
+		moves = append(moves, Move{Board: move})
+	}
+	return append(moves, drops(p)...)
+}
+
+// drops returns the side to move's legal drops: a piece from its reserve
+// onto an empty square, pawns off the first and last ranks, and when in
+// check only onto a square that blocks it
+func drops(p *Position) []Move {
+	mover := p.Turn()
+	reserve := p.Reserves[mover]
+	if reserve.Empty() {
+		return nil
+	}
+	board := p.Board.Board()
+	checked := inCheck(board, mover)
+
+	var moves []Move
+	for _, t := range reserveOrder {
+		if reserve[t] == 0 {
+			continue
+		}
+		for square := chess.A1; square <= chess.H8; square++ {
+			if board.Piece(square) != chess.NoPiece {
+				continue
+			}
+			if t == chess.Pawn && (square.Rank() == chess.Rank1 || square.Rank() == chess.Rank8) {
+				continue
+			}
+			if checked && inCheck(withPiece(board, chess.NewPiece(t, mover), square), mover) {
+				continue
+			}
+			moves = append(moves, Move{Drop: t, To: square})
+		}
+	}
+	return moves
+}
+
+// withPiece returns board with piece put on the square
+func withPiece(board *chess.Board, piece chess.Piece, square chess.Square) *chess.Board {
+	squares := board.SquareMap()
+	squares[square] = piece
+	return chess.NewBoard(squares)
+}
+
+// Play makes a move or a drop, putting a captured piece in the capturer's
+// reserve as the piece it started the game as
+func (Crazyhouse) Play(p *Position, m Move) *Position {
+	mover := p.Turn()
+	if m.IsDrop() {
+		next := p.clone(dropped(p.Board, chess.NewPiece(m.Drop, mover), m.To))
+		next.addToReserve(mover, m.Drop, -1)
+		return next
+	}
+
+	next := p.clone(p.Board.Update(m.Board))
+	from, to := m.Board.S1(), m.Board.S2()
+	captured := p.Board.Board().Piece(to)
+	if m.Board.HasTag(chess.EnPassant) {
+		captured = chess.NewPiece(chess.Pawn, mover.Other())
+	}
+	if captured != chess.NoPiece {
+		t := captured.Type()
+		if p.Promoted[to] {
+			t = chess.Pawn
+		}
+		next.addToReserve(mover, t, 1)
+	}
+
+	delete(next.Promoted, to)
+	if next.Promoted[from] || m.Board.Promo() != chess.NoPieceType {
+		next.Promoted[to] = true
+	}
+	delete(next.Promoted, from)
+	return next
+}
+
+// Outcome is checkmate or stalemate once the side to move has neither a
+// move nor a drop, or a draw by the fifty-move rule; the engine's draws by
+// material don't apply, as a piece can always come back
+func (Crazyhouse) Outcome(p *Position) (chess.Outcome, string) {
+	if len(p.Board.ValidMoves()) > 0 || len(drops(p)) > 0 {
+		return fiftyMoveRule(p)
+	}
+	if !inCheck(p.Board.Board(), p.Turn()) {
+		return chess.Draw, "stalemate"
+	}
+	if p.Turn() == chess.White {
+		return chess.BlackWon, "checkmate"
+	}
+	return chess.WhiteWon, "checkmate"
+}
+
+// InCheck reports whether the side to move's king is attacked
+func (Crazyhouse) InCheck(p *Position) bool {
+	return inCheck(p.Board.Board(), p.Turn())
+}
+
+// dropped returns the engine's position after piece is dropped on the
+// square: the other side is to move, with no en passant square, and only
+// a pawn drop resets the fifty-move count
+func dropped(position *chess.Position, piece chess.Piece, square chess.Square) *chess.Position {
+	fields := strings.Fields(position.String())
+	fields[0] = withPiece(position.Board(), piece, square).String()
+	fields[3] = "-"
+	fields[4] = strconv.Itoa(position.HalfMoveClock() + 1)
+	if piece.Type() == chess.Pawn {
+		fields[4] = "0"
+	}
+	fields[1] = "b"
+	if piece.Color() == chess.Black {
+		fields[1] = "w"
+		if number, err := strconv.Atoi(fields[5]); err == nil {
+			fields[5] = strconv.Itoa(number + 1)
+		}
+	}
+
+	next := &chess.Position{}
+	if err := next.UnmarshalText([]byte(strings.Join(fields, " "))); err != nil {
+		// A legal drop only ever fills an empty square
+		panic(fmt.Sprintf("variant: dropping %s on %s made an invalid position: %v", piece, square, err))
+	}
+	return next
+}
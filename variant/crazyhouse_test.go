@@ -0,0 +1,179 @@
+package variant
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// crazyhouseGame sets up a Crazyhouse game from FEN
+func crazyhouseGame(t *testing.T, fen string) *Game {
+	t.Helper()
+	game, err := NewGameFromFEN(Crazyhouse{}, fen)
+	if err != nil {
+		t.Fatalf("Bad FEN %s: %v", fen, err)
+	}
+	return game
+}
+
+func TestParseFENRoundTrip(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[] w KQkq - 0 1",
+		"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R[QNpp] b KQkq - 2 3",
+		"4k3/8/8/3Q~4/8/8/8/4K3[] w - - 0 1",
+		"4k3/8/8/8/8/8/8/4K3 w - - 0 1",
+	}
+	for _, fen := range fens {
+		p, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf("Expected %s to parse, got %v", fen, err)
+		}
+		if p.String() != fen {
+			t.Errorf("Expected %s, got %s", fen, p.String())
+		}
+	}
+
+	for _, fen := range []string{"", "8/8/8/8/8/8/8/8[K] w - - 0 1", "4k3/8/8/8/8/8/8/4K3[Q w - - 0 1", "~4k3/8/8/8/8/8/8/4K3 w - - 0 1"} {
+		if _, err := ParseFEN(fen); err == nil {
+			t.Errorf("Expected %q to be refused", fen)
+		}
+	}
+}
+
+func TestCapturesJoinTheReserve(t *testing.T) {
+	game := NewGame(Crazyhouse{})
+	for _, move := range []string{"e4", "d5", "exd5", "Qxd5"} {
+		if _, err := game.Move(move); err != nil {
+			t.Fatalf("Expected %s to be legal, got %v", move, err)
+		}
+	}
+
+	p := game.Position()
+	if p.Reserves[chess.White][chess.Pawn] != 1 || p.Reserves[chess.Black][chess.Pawn] != 1 {
+		t.Errorf("Expected each side to hold a pawn, got %s", p)
+	}
+	if !strings.Contains(p.String(), "[Pp]") {
+		t.Errorf("Expected the reserves in the FEN, got %s", p)
+	}
+
+	san, err := game.Move("P@e4")
+	if err != nil {
+		t.Fatalf("Expected P@e4 to be legal, got %v", err)
+	}
+	if san != "P@e4" {
+		t.Errorf("Expected P@e4, got %s", san)
+	}
+	if !game.Position().Reserves[chess.White].Empty() {
+		t.Errorf("Expected White's reserve to be empty after the drop, got %s", game.Position())
+	}
+	if game.Position().Board.Board().Piece(chess.E4) != chess.WhitePawn {
+		t.Errorf("Expected a white pawn on e4, got %s", game.Position())
+	}
+	if game.Position().Turn() != chess.Black {
+		t.Errorf("Expected Black to move after the drop")
+	}
+}
+
+func TestDropLegality(t *testing.T) {
+	game := crazyhouseGame(t, "4k3/8/8/8/8/8/4P3/4K3[Pb] w - - 0 1")
+
+	tests := []struct {
+		text   string
+		reason string
+	}{
+		{"P@a8", "first or last rank"},
+		{"P@e1", "occupied"},
+		{"N@f3", "no knight in hand"},
+		{"B@f3", "no bishop in hand"},
+	}
+	for _, test := range tests {
+		_, err := game.Move(test.text)
+		if !errors.Is(err, notation.ErrIllegal) || !strings.Contains(err.Error(), test.reason) {
+			t.Errorf("Expected %s to be refused with %q, got %v", test.text, test.reason, err)
+		}
+	}
+
+	moves := game.LegalMoves()
+	if !slices.Contains(moves, "P@d7+") || slices.Contains(moves, "P@a1") {
+		t.Errorf("Expected pawn drops off the back ranks, got %v", moves)
+	}
+}
+
+func TestDropsBlockMate(t *testing.T) {
+	// The rook mates on the back rank unless White can drop a piece in the way
+	mated := crazyhouseGame(t, "6k1/8/8/8/8/8/6PP/r6K[] w - - 0 1")
+	if outcome, method := mated.Outcome(); outcome != chess.BlackWon || method != "checkmate" {
+		t.Errorf("Expected Black to win by checkmate, got %s %s", outcome, method)
+	}
+
+	blocked := crazyhouseGame(t, "6k1/8/8/8/8/8/6PP/r6K[N] w - - 0 1")
+	if outcome, _ := blocked.Outcome(); outcome != chess.NoOutcome {
+		t.Errorf("Expected a knight drop to save White, got %s", outcome)
+	}
+	moves := blocked.LegalMoves()
+	if !slices.Contains(moves, "N@g1") || slices.Contains(moves, "N@c3") {
+		t.Errorf("Expected only blocking drops, got %v", moves)
+	}
+
+	// Giving that check is no mate while White holds a knight
+	before := crazyhouseGame(t, "r5k1/8/8/8/8/8/6PP/7K[N] b - - 0 1")
+	if san, err := before.Move("Ra1"); err != nil || san != "Ra1+" {
+		t.Errorf("Expected Ra1+, got %s %v", san, err)
+	}
+	before = crazyhouseGame(t, "r5k1/8/8/8/8/8/6PP/7K[] b - - 0 1")
+	if san, err := before.Move("Ra1"); err != nil || san != "Ra1#" {
+		t.Errorf("Expected Ra1#, got %s %v", san, err)
+	}
+}
+
+func TestPromotedPiecesReturnAsPawns(t *testing.T) {
+	game := crazyhouseGame(t, "4k3/8/4p3/3Q~4/8/8/8/4K3[] b - - 0 1")
+	if _, err := game.Move("exd5"); err != nil {
+		t.Fatalf("Expected exd5 to be legal, got %v", err)
+	}
+	reserve := game.Position().Reserves[chess.Black]
+	if reserve[chess.Pawn] != 1 || reserve[chess.Queen] != 0 {
+		t.Errorf("Expected the promoted queen to return as a pawn, got %s", game.Position())
+	}
+
+	promoting := crazyhouseGame(t, "4k3/P7/8/8/8/8/8/4K3[] w - - 0 1")
+	if _, err := promoting.Move("a8=Q+"); err != nil {
+		t.Fatalf("Expected a8=Q+ to be legal, got %v", err)
+	}
+	if !promoting.Position().Promoted[chess.A8] {
+		t.Errorf("Expected the new queen to be marked promoted, got %s", promoting.Position())
+	}
+}
+
+func TestLookup(t *testing.T) {
+	v, err := Lookup(" CrazyHouse ")
+	if err != nil || v.Name() != "Crazyhouse" {
+		t.Errorf("Expected Crazyhouse, got %v %v", v, err)
+	}
+	if _, err := Lookup("bughouse"); err == nil || !strings.Contains(err.Error(), "crazyhouse") {
+		t.Errorf("Expected an error listing the variants, got %v", err)
+	}
+}
+
+func TestThreefoldRepetition(t *testing.T) {
+	for _, v := range []Variant{Atomic{}, Crazyhouse{}} {
+		game := NewGame(v)
+		shuffle := []string{"Nf3", "Nf6", "Ng1", "Ng8"}
+		for i, move := range append(shuffle, shuffle...) {
+			if outcome, _ := game.Outcome(); outcome != chess.NoOutcome {
+				t.Fatalf("%s: expected the game to go on before move %d, got %s", v.Name(), i+1, outcome)
+			}
+			if _, err := game.Move(move); err != nil {
+				t.Fatalf("%s: expected %s to be legal, got %v", v.Name(), move, err)
+			}
+		}
+		if outcome, method := game.Outcome(); outcome != chess.Draw || method != "threefold repetition" {
+			t.Errorf("%s: expected a draw by threefold repetition, got %s %s", v.Name(), outcome, method)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package variant
+
+import (
+	"fmt"
+	"strings"
+
+	"chess-tui/notation"
+
+	"github.com/notnil/chess"
+)
+
+// Encode formats a legal move in SAN, drops as e.g. "N@f3", with check and
+// mate markers as the variant decides them rather than the engine
+func Encode(v Variant, p *Position, m Move) string {
+	var san string
+	if m.IsDrop() {
+		san = notation.EncodeDrop(m.Drop, m.To)
+	} else {
+		san = strings.TrimRight(notation.Encode(p.Board, m.Board), "+#")
+	}
+
+	// Only a check can be mate, so most moves are marked without looking
+	// for the reply to every one of them
+	next := v.Play(p, m)
+	if !v.InCheck(next) {
+		return san
+	}
+	if outcome, method := v.Outcome(next); outcome != chess.NoOutcome && method == "checkmate" {
+		return san + "#"
+	}
+	return san + "+"
+}
+
+// Decode resolves text in SAN or UCI, or a drop such as "P@e4", to the
+// legal move it names in p. Check and mate markers are not checked, as the
+// engine would judge them by standard chess.
+func Decode(v Variant, p *Position, text string) (Move, error) {
+	parsed, err := notation.Parse(text)
+	if err != nil {
+		return Move{}, err
+	}
+	moves := v.Moves(p)
+
+	if parsed.Drop {
+		for _, m := range moves {
+			if m.IsDrop() && m.Drop == parsed.Piece && m.To == parsed.To {
+				return m, nil
+			}
+		}
+		return Move{}, fmt.Errorf("%w: %s", notation.ErrIllegal, whyNotDrop(p, parsed))
+	}
+
+	text = strings.TrimSpace(text)
+	var matches []Move
+	for _, m := range moves {
+		if !m.IsDrop() && parsed.Matches(p.Board, m.Board) {
+			matches = append(matches, m)
+		}
+	}
+	switch {
+	case len(matches) == 0:
+		// The engine explains moves standard chess refuses too
+		if _, err := notation.Decode(p.Board, strings.TrimRight(text, "+#")); err != nil {
+			return Move{}, err
+		}
+		return Move{}, fmt.Errorf("%w: %s is not allowed in %s", notation.ErrIllegal, text, v.Name())
+	case len(matches) > 1 && parsed.Promotion == chess.NoPieceType && matches[0].Board.Promo() != chess.NoPieceType:
+		return Move{}, fmt.Errorf("%w: %s needs a piece to promote to, e.g. %s=Q", notation.ErrPromotionMissing, text, strings.TrimRight(text, "+#"))
+	case len(matches) > 1:
+		return Move{}, fmt.Errorf("%w: more than one piece can play %s", notation.ErrAmbiguous, text)
+	}
+
+	m := matches[0]
+	if parsed.Capture && !m.Board.HasTag(chess.Capture) && !m.Board.HasTag(chess.EnPassant) {
+		return Move{}, fmt.Errorf("%w: there is nothing to capture on %s", notation.ErrIllegal, m.Board.S2())
+	}
+	return m, nil
+}
+
+// whyNotDrop says why a drop isn't legal
+func whyNotDrop(p *Position, parsed notation.Move) string {
+	name := pieceNames[parsed.Piece]
+	switch {
+	case p.Reserves[p.Turn()][parsed.Piece] == 0:
+		return fmt.Sprintf("%s has no %s in hand", p.Turn().Name(), name)
+	case p.Board.Board().Piece(parsed.To) != chess.NoPiece:
+		return fmt.Sprintf("%s is occupied; pieces can only be dropped on empty squares", parsed.To)
+	case parsed.Piece == chess.Pawn && (parsed.To.Rank() == chess.Rank1 || parsed.To.Rank() == chess.Rank8):
+		return "pawns can't be dropped on the first or last rank"
+	case inCheck(p.Board.Board(), p.Turn()):
+		return fmt.Sprintf("the king is in check, and a %s on %s doesn't block it", name, parsed.To)
+	}
+	return fmt.Sprintf("%s can't be dropped on %s", name, parsed.To)
+}
+
+// pieceNames are the names drop errors use for pieces
+var pieceNames = map[chess.PieceType]string{
+	chess.Queen:  "queen",
+	chess.Rook:   "rook",
+	chess.Bishop: "bishop",
+	chess.Knight: "knight",
+	chess.Pawn:   "pawn",
+}
+
+// Game is a game of a variant, its positions and its moves in SAN
+type Game struct {
+	variant   Variant
+	positions []*Position
+	history   []string
+
+	// The outcome of the position judged last, which the TUI asks for
+	// on every render
+	judged  *Position
+	outcome chess.Outcome
+	method  string
+}
+
+// NewGame starts a game of v from its starting position
+func NewGame(v Variant) *Game {
+	return &Game{variant: v, positions: []*Position{v.Start()}}
+}
+
+// NewGameFromFEN starts a game of v from a position in FEN
+func NewGameFromFEN(v Variant, fen string) (*Game, error) {
+	p, err := ParseFEN(fen)
+	if err != nil {
+		return nil, err
+	}
+	return &Game{variant: v, positions: []*Position{p}}, nil
+}
+
+// Variant returns the variant the game is played by
+func (g *Game) Variant() Variant {
+	return g.variant
+}
+
+// Position returns the current position
+func (g *Game) Position() *Position {
+	return g.positions[len(g.positions)-1]
+}
+
+// History returns the moves played, in SAN
+func (g *Game) History() []string {
+	return append([]string(nil), g.history...)
+}
+
+// LegalMoves lists the legal moves in SAN
+func (g *Game) LegalMoves() []string {
+	p := g.Position()
+	var moves []string
+	for _, m := range g.variant.Moves(p) {
+		moves = append(moves, Encode(g.variant, p, m))
+	}
+	return moves
+}
+
+// Outcome returns the game's result and how it was decided: by the
+// variant's rules, or a draw once the same position comes up a third time
+func (g *Game) Outcome() (chess.Outcome, string) {
+	p := g.Position()
+	if g.judged != p {
+		g.judged = p
+		g.outcome, g.method = g.variant.Outcome(p)
+		if g.outcome == chess.NoOutcome && g.repetitions(p) >= 3 {
+			g.outcome, g.method = chess.Draw, "threefold repetition"
+		}
+	}
+	return g.outcome, g.method
+}
+
+// repetitions counts the times the current position p has come up in the
+// game, itself included. Only the positions since the last capture or pawn
+// move, with the same side to move, can be the same.
+func (g *Game) repetitions(p *Position) int {
+	key := p.repetitionKey()
+	count := 0
+	last := len(g.positions) - 1
+	for i := last; i >= 0 && last-i <= p.Board.HalfMoveClock(); i -= 2 {
+		if g.positions[i].repetitionKey() == key {
+			count++
+		}
+	}
+	return count
+}
+
+// Move plays the move text names and returns it in SAN
+func (g *Game) Move(text string) (string, error) {
+	if outcome, _ := g.Outcome(); outcome != chess.NoOutcome {
+		return "", fmt.Errorf("%w: the game is over", notation.ErrIllegal)
+	}
+	p := g.Position()
+	m, err := Decode(g.variant, p, text)
+	if err != nil {
+		return "", err
+	}
+	san := Encode(g.variant, p, m)
+	g.positions = append(g.positions, g.variant.Play(p, m))
+	g.history = append(g.history, san)
+	return san, nil
+}
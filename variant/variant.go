@@ -0,0 +1,313 @@
+// Package variant plays chess variants. The core engine knows only standard
+// chess, so a variant keeps its own state next to the engine's position,
+// such as the pieces Crazyhouse players hold in reserve, and adds its own
+// moves to the ones the engine generates, or takes some away.
+package variant
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// Variant is a set of rules chess is played by
+type Variant interface {
+	// Name is the variant's name, e.g. "Crazyhouse"
+	Name() string
+
+	// Rules explains how the variant differs from standard chess, for
+	// players and for the AI's prompt
+	Rules() string
+
+	// Start returns the variant's starting position
+	Start() *Position
+
+	// Moves returns the legal moves in p
+	Moves(p *Position) []Move
+
+	// Play returns the position after m, which must be one of Moves(p)
+	Play(p *Position, m Move) *Position
+
+	// Outcome returns the result of p, chess.NoOutcome while the game goes
+	// on, and how the game was decided, e.g. "checkmate". A position alone
+	// can't show a repetition; Game judges threefold repetition itself.
+	Outcome(p *Position) (chess.Outcome, string)
+
+	// InCheck reports whether the side to move is in check, as the
+	// variant counts it
+	InCheck(p *Position) bool
+}
+
+// Exploder is a variant whose captures blow up more than the captured
+// piece, as in Atomic
+type Exploder interface {
+	// Explosion returns the squares whose pieces m, a legal move in p,
+	// destroys, or nil when it captures nothing
+	Explosion(p *Position, m Move) []chess.Square
+}
+
+// variants are the variants by lowercase name
+var variants = map[string]Variant{
+	"atomic":     Atomic{},
+	"crazyhouse": Crazyhouse{},
+}
+
+// Lookup returns the variant with the name, in any case
+func Lookup(name string) (Variant, error) {
+	if v, ok := variants[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("unknown variant %q, expected one of %s", name, strings.Join(Names(), ", "))
+}
+
+// Names lists the variants' lowercase names in order
+func Names() []string {
+	names := make([]string, 0, len(variants))
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Move is a move in a variant: a piece moving on the board, or a piece
+// dropped from the reserve
+type Move struct {
+	Board *chess.Move     // the move on the board, or nil for a drop
+	Drop  chess.PieceType // the piece dropped
+	To    chess.Square    // the square it is dropped on
+}
+
+// IsDrop reports whether m drops a piece from the reserve
+func (m Move) IsDrop() bool {
+	return m.Board == nil
+}
+
+// Square returns the square m moves or drops a piece to
+func (m Move) Square() chess.Square {
+	if m.IsDrop() {
+		return m.To
+	}
+	return m.Board.S2()
+}
+
+// Reserve counts the pieces a side holds in hand, by piece type
+type Reserve [chess.Pawn + 1]int
+
+// reserveOrder is the order reserves list their pieces in
+var reserveOrder = []chess.PieceType{chess.Queen, chess.Rook, chess.Bishop, chess.Knight, chess.Pawn}
+
+// Pieces lists the pieces in the reserve, most valuable first, e.g.
+// [Queen Pawn Pawn]
+func (r Reserve) Pieces() []chess.PieceType {
+	var pieces []chess.PieceType
+	for _, t := range reserveOrder {
+		for range r[t] {
+			pieces = append(pieces, t)
+		}
+	}
+	return pieces
+}
+
+// String lists the reserve's pieces by name, e.g. "queen, pawn, pawn", or
+// says "nothing"
+func (r Reserve) String() string {
+	var names []string
+	for _, t := range r.Pieces() {
+		names = append(names, pieceNames[t])
+	}
+	if len(names) == 0 {
+		return "nothing"
+	}
+	return strings.Join(names, ", ")
+}
+
+// Empty reports whether the reserve holds no pieces
+func (r Reserve) Empty() bool {
+	return r == Reserve{}
+}
+
+// Position is a position in a variant: the engine's position, and the state
+// the variant keeps beside it
+type Position struct {
+	Board *chess.Position
+
+	// Reserves are the pieces each side holds in hand, in variants that
+	// have them, and nil in the others
+	Reserves map[chess.Color]Reserve
+
+	// Promoted marks the pieces that promoted from pawns, which go back
+	// to the reserve as pawns when captured
+	Promoted map[chess.Square]bool
+}
+
+// ParseFEN reads a position in FEN, with the reserves in brackets after the
+// board and promoted pieces marked "~", as Crazyhouse FEN has them, e.g.
+// "rnb1kbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNB1KBNR[Qq] w KQkq - 0 3"
+func ParseFEN(fen string) (*Position, error) {
+	fields := strings.Fields(fen)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid FEN %q: it is empty", fen)
+	}
+	p := &Position{Promoted: make(map[chess.Square]bool)}
+
+	placement := fields[0]
+	if i := strings.IndexByte(placement, '['); i >= 0 {
+		if !strings.HasSuffix(placement, "]") {
+			return nil, fmt.Errorf("invalid FEN %q: the reserves need a closing ]", fen)
+		}
+		reserves, err := parseReserves(placement[i+1 : len(placement)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid FEN %q: %w", fen, err)
+		}
+		p.Reserves = reserves
+		placement = placement[:i]
+	}
+
+	// Note the promoted pieces while taking their marks out
+	var board strings.Builder
+	rank, file := 7, 0
+	for _, c := range placement {
+		switch {
+		case c == '/':
+			rank, file = rank-1, 0
+		case c >= '1' && c <= '8':
+			file += int(c - '0')
+		case c == '~':
+			if file == 0 || rank < 0 {
+				return nil, fmt.Errorf("invalid FEN %q: ~ must follow a piece", fen)
+			}
+			p.Promoted[chess.NewSquare(chess.File(file-1), chess.Rank(rank))] = true
+			continue
+		default:
+			file++
+		}
+		board.WriteRune(c)
+	}
+	fields[0] = board.String()
+
+	position := &chess.Position{}
+	if err := position.UnmarshalText([]byte(strings.Join(fields, " "))); err != nil {
+		return nil, fmt.Errorf("invalid FEN %q: %w", fen, err)
+	}
+	p.Board = position
+	return p, nil
+}
+
+// parseReserves reads reserves such as "QRpp": White's pieces in capitals
+// and Black's in lowercase
+func parseReserves(s string) (map[chess.Color]Reserve, error) {
+	white, black := Reserve{}, Reserve{}
+	for _, c := range s {
+		t, ok := reserveLetters[c|0x20]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a piece a reserve can hold", c)
+		}
+		if c >= 'a' {
+			black[t]++
+		} else {
+			white[t]++
+		}
+	}
+	return map[chess.Color]Reserve{chess.White: white, chess.Black: black}, nil
+}
+
+// reserveLetters are the lowercase letters of the pieces a reserve holds
+var reserveLetters = map[rune]chess.PieceType{
+	'q': chess.Queen,
+	'r': chess.Rook,
+	'b': chess.Bishop,
+	'n': chess.Knight,
+	'p': chess.Pawn,
+}
+
+// String returns the position in FEN, with the reserves and promoted
+// pieces written as ParseFEN reads them
+func (p *Position) String() string {
+	fields := strings.Fields(p.Board.String())
+	if len(p.Promoted) > 0 {
+		fields[0] = markPromoted(fields[0], p.Promoted)
+	}
+	if p.Reserves != nil {
+		var reserves strings.Builder
+		for _, color := range []chess.Color{chess.White, chess.Black} {
+			for _, t := range p.Reserves[color].Pieces() {
+				letter := t.String()
+				if color == chess.White {
+					letter = strings.ToUpper(letter)
+				}
+				reserves.WriteString(letter)
+			}
+		}
+		fields[0] += "[" + reserves.String() + "]"
+	}
+	return strings.Join(fields, " ")
+}
+
+// markPromoted writes "~" after the promoted pieces of a FEN board
+func markPromoted(placement string, promoted map[chess.Square]bool) string {
+	var marked strings.Builder
+	rank, file := 7, 0
+	for _, c := range placement {
+		marked.WriteRune(c)
+		switch {
+		case c == '/':
+			rank, file = rank-1, 0
+		case c >= '1' && c <= '8':
+			file += int(c - '0')
+		default:
+			if promoted[chess.NewSquare(chess.File(file), chess.Rank(rank))] {
+				marked.WriteRune('~')
+			}
+			file++
+		}
+	}
+	return marked.String()
+}
+
+// Turn returns the side to move
+func (p *Position) Turn() chess.Color {
+	return p.Board.Turn()
+}
+
+// repetitionKey identifies p for repetitions: the board, reserves and
+// promoted pieces, the side to move and the castling and en passant rights
+func (p *Position) repetitionKey() string {
+	return strings.Join(strings.Fields(p.String())[:4], " ")
+}
+
+// fiftyMoveRule returns a draw once fifty moves have passed without a
+// capture or a pawn moving, or chess.NoOutcome before. It is for positions
+// where the side to move has a move, as a mate on the fiftieth move stands.
+func fiftyMoveRule(p *Position) (chess.Outcome, string) {
+	if p.Board.HalfMoveClock() >= 100 {
+		return chess.Draw, "fifty-move rule"
+	}
+	return chess.NoOutcome, ""
+}
+
+// addToReserve adds n of a piece type to color's reserve, or takes them
+// away when n is negative
+func (p *Position) addToReserve(color chess.Color, t chess.PieceType, n int) {
+	if p.Reserves == nil {
+		p.Reserves = make(map[chess.Color]Reserve)
+	}
+	reserve := p.Reserves[color]
+	reserve[t] += n
+	p.Reserves[color] = reserve
+}
+
+// clone copies p's variant state around a new engine position
+func (p *Position) clone(board *chess.Position) *Position {
+	next := &Position{Board: board, Promoted: maps.Clone(p.Promoted)}
+	if p.Reserves != nil {
+		next.Reserves = maps.Clone(p.Reserves)
+	}
+	if next.Promoted == nil {
+		next.Promoted = make(map[chess.Square]bool)
+	}
+	return next
+}